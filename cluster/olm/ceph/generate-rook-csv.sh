@@ -97,6 +97,9 @@ CEPH_FILESYSTEMS_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephfilesystems.cep
 CEPH_NFS_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephnfses.ceph.rook.io.crd.yaml"
 CEPH_CLIENT_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephclients.ceph.rook.io.crd.yaml"
 CEPH_RBD_MIRROR_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephrbdmirrors.ceph.rook.io.crd.yaml"
+CEPH_COMMAND_JOB_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephcommandjobs.ceph.rook.io.crd.yaml"
+CEPH_BLOCK_POOL_RADOS_NAMESPACE_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephblockpoolradosnamespaces.ceph.rook.io.crd.yaml"
+CEPH_DEVICE_INVENTORY_CRD_YAML_FILE="$OLM_CATALOG_DIR/deploy/crds/cephdeviceinventories.ceph.rook.io.crd.yaml"
 CEPH_EXTERNAL_SCRIPT_FILE="cluster/examples/kubernetes/ceph/create-external-cluster-resources.py"
 
 if [[ -d "$CSV_BUNDLE_PATH" ]]; then
@@ -219,6 +222,9 @@ function generate_crds_yaml() {
     sed -n '/^# OLM: BEGIN CEPH NFS CRD$/,/# OLM: END CEPH NFS CRD$/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_NFS_CRD_YAML_FILE"
     sed -n '/^# OLM: BEGIN CEPH CLIENT CRD$/,/# OLM: END CEPH CLIENT CRD$/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_CLIENT_CRD_YAML_FILE"
     sed -n '/^# OLM: BEGIN CEPH RBD MIRROR CRD$/,/# OLM: END CEPH RBD MIRROR CRD$/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_RBD_MIRROR_CRD_YAML_FILE"
+    sed -n '/^# OLM: BEGIN CEPH COMMAND JOB CRD$/,/# OLM: END CEPH COMMAND JOB CRD$/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_COMMAND_JOB_CRD_YAML_FILE"
+    sed -n '/^# OLM: BEGIN CEPH BLOCK POOL RADOS NAMESPACE CRD$/,/# OLM: END CEPH BLOCK POOL RADOS NAMESPACE CRD$/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_BLOCK_POOL_RADOS_NAMESPACE_CRD_YAML_FILE"
+    sed -n '/^# OLM: BEGIN CEPH DEVICE INVENTORY CRD$/,/# OLM: END CEPH DEVICE INVENTORY CRD$/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_DEVICE_INVENTORY_CRD_YAML_FILE"
 
     if [ -n "$OLM_INCLUDE_CEPHFS_CSI" ]; then
         sed -n '/^# OLM: BEGIN CEPH FS CRD$/,/# OLM: END CEPH FS CRD/p' "$COMMON_YAML_FILE" | grep -v '^#' > "$CEPH_FILESYSTEMS_CRD_YAML_FILE"
@@ -21,9 +21,11 @@ import (
 	"github.com/rook/rook/cmd/rook/rook"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/agent/flexvolume/attachment"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	operator "github.com/rook/rook/pkg/operator/ceph"
 	cluster "github.com/rook/rook/pkg/operator/ceph/cluster"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/csi"
 
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
@@ -46,9 +48,23 @@ https://github.com/rook/rook`,
 func init() {
 	operatorCmd.Flags().DurationVar(&mon.HealthCheckInterval, "mon-healthcheck-interval", mon.HealthCheckInterval, "mon health check interval (duration)")
 	operatorCmd.Flags().DurationVar(&mon.MonOutTimeout, "mon-out-timeout", mon.MonOutTimeout, "mon out timeout (duration)")
+	operatorCmd.Flags().DurationVar(&mon.ClusterInfoCacheTTL, "cluster-info-cache-ttl", mon.ClusterInfoCacheTTL, "duration the controllers cache the mon endpoints and credentials of the clusters, 0 disables the cache (duration)")
 
 	operatorCmd.Flags().BoolVar(&operator.EnableFlexDriver, "enable-flex-driver", true, "enable the rook flex driver")
 	operatorCmd.Flags().BoolVar(&operator.EnableDiscoveryDaemon, "enable-discovery-daemon", true, "enable the rook discovery daemon")
+	operatorCmd.Flags().StringVar(&operator.MetricsBindAddress, "metrics-bind-address", operator.MetricsBindAddress, "address of the operator metrics endpoint, \"0\" disables the endpoint")
+	operatorCmd.Flags().IntVar(&opcontroller.MaxConcurrentReconciles, "max-concurrent-reconciles", opcontroller.MaxConcurrentReconciles, "number of CRs each controller reconciles in parallel")
+	operatorCmd.Flags().BoolVar(&k8sutil.ServerSideApply, "server-side-apply", k8sutil.ServerSideApply, "update the deployments, services and config maps generated by the operator with server-side apply, keeping the fields added by other managers")
+	operatorCmd.Flags().BoolVar(&opconfig.ConfigStoreDryRun, "config-store-dry-run", opconfig.ConfigStoreDryRun, "log the changes of the options of the object store, filesystem and nfs daemons in the mon configuration database instead of applying them")
+	operatorCmd.Flags().BoolVar(&cephclient.UseCommandConnection, "ceph-command-connection", cephclient.UseCommandConnection, "run the ceph status, health and map commands on a librados connection kept open per cluster instead of the ceph CLI, requires an operator built with the rados tag")
+	operatorCmd.Flags().BoolVar(&opcontroller.ExportEffectiveSpecs, "export-effective-specs", opcontroller.ExportEffectiveSpecs, "set the effective spec of the CRs, with the defaults of the operator and of the cluster, in their ceph.rook.io/effective-spec annotation")
+	operatorCmd.Flags().StringSliceVar(&opcontroller.ReconcileIgnorePaths, "reconcile-ignore-paths", opcontroller.ReconcileIgnorePaths, "JSON pointers of the fields of the objects owned by the CRs whose changes do not trigger a reconcile (e.g. /spec/template/spec/containers/istio-proxy)")
+	operatorCmd.Flags().StringVar(&opcontroller.CacheLabelSelector, "cache-label-selector", opcontroller.CacheLabelSelector, "label selector of the secrets, config maps and deployments cached by the controllers, all are cached if empty (e.g. app)")
+	operatorCmd.Flags().StringToIntVar(&opcontroller.ControllerMaxConcurrentReconciles, "controller-max-concurrent-reconciles", opcontroller.ControllerMaxConcurrentReconciles, "number of CRs reconciled in parallel by controller name, overriding max-concurrent-reconciles (e.g. ceph-cluster-controller=3,ceph-block-pool-controller=5)")
+	operatorCmd.Flags().BoolVar(&operator.LeaderElection, "leader-elect", operator.LeaderElection, "elect a leader among the operator replicas, only the leader manages the clusters")
+	operatorCmd.Flags().DurationVar(&operator.LeaderElectionLeaseDuration, "leader-elect-lease-duration", operator.LeaderElectionLeaseDuration, "duration the standby operators wait before taking over the lease of the leader (duration)")
+	operatorCmd.Flags().DurationVar(&operator.LeaderElectionRenewDeadline, "leader-elect-renew-deadline", operator.LeaderElectionRenewDeadline, "duration the leader retries to renew its lease before giving up leading (duration)")
+	operatorCmd.Flags().DurationVar(&operator.LeaderElectionRetryPeriod, "leader-elect-retry-period", operator.LeaderElectionRetryPeriod, "duration between the attempts to acquire or renew the lease (duration)")
 
 	// csi deployment templates
 	operatorCmd.Flags().StringVar(&csi.RBDPluginTemplatePath, "csi-rbd-plugin-template-path", csi.DefaultRBDPluginTemplatePath, "path to ceph-csi rbd plugin template")
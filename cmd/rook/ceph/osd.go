@@ -24,7 +24,10 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rook/rook/cmd/rook/rook"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	osddaemon "github.com/rook/rook/pkg/daemon/ceph/osd"
+	"github.com/rook/rook/pkg/daemon/ceph/osd/kms"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	oposd "github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	osdcfg "github.com/rook/rook/pkg/operator/ceph/cluster/osd/config"
@@ -50,6 +53,15 @@ var osdStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Starts the osd daemon", // OSDs that were provisioned by ceph-volume
 }
+var osdRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Rotates the dmcrypt key of an encrypted osd",
+}
+
+var osdOpenEncryptedDeviceCmd = &cobra.Command{
+	Use:   "open-encrypted-device",
+	Short: "Opens the device of an osd encrypted with a key of the key management service",
+}
 
 var (
 	osdDataDeviceFilter     string
@@ -67,6 +79,8 @@ var (
 	blockPath               string
 	lvBackedPV              bool
 	driveGroups             string
+	replaceOSDIDs           []int
+	encryptionKeyID         string
 )
 
 func addOSDFlags(command *cobra.Command) {
@@ -82,6 +96,8 @@ func addOSDFlags(command *cobra.Command) {
 	provisionCmd.Flags().BoolVar(&cfg.forceFormat, "force-format", false,
 		"true to force the format of any specified devices, even if they already have a filesystem.  BE CAREFUL!")
 	provisionCmd.Flags().BoolVar(&cfg.pvcBacked, "pvc-backed-osd", false, "true to specify a block mode pvc is backing the OSD")
+	provisionCmd.Flags().IntSliceVar(&replaceOSDIDs, "replace-osd-ids", nil, "comma separated list of the destroyed osds to recreate with the same ids")
+	provisionCmd.Flags().StringToIntVar(&cfg.storeConfig.OSDsPerDeviceClass, "osds-per-device-class", nil, "the number of OSDs per device by device class (e.g. nvme=4,ssd=2)")
 	// flags for generating the osd config
 	osdConfigCmd.Flags().IntVar(&osdID, "osd-id", -1, "osd id for which to generate config")
 	osdConfigCmd.Flags().BoolVar(&osdIsDevice, "is-device", false, "whether the osd is a device")
@@ -94,10 +110,20 @@ func addOSDFlags(command *cobra.Command) {
 	osdStartCmd.Flags().StringVar(&blockPath, "block-path", "", "Block path for the OSD created by ceph-volume")
 	osdStartCmd.Flags().BoolVar(&lvBackedPV, "lv-backed-pv", false, "Whether the PV located on LV")
 
+	// flags for rotating the key of an encrypted osd
+	osdRotateKeyCmd.Flags().StringVar(&osdUUID, "osd-uuid", "", "the osd UUID")
+	osdRotateKeyCmd.Flags().StringVar(&blockPath, "block-path", "", "Block path of the encrypted device of the OSD")
+	osdRotateKeyCmd.Flags().StringVar(&encryptionKeyID, "encryption-key-id", "", "the LUKS uuid of a device encrypted with a key of the key management service")
+
+	// flags for opening the encrypted device of an osd
+	osdOpenEncryptedDeviceCmd.Flags().StringVar(&encryptionKeyID, "encryption-key-id", "", "the LUKS uuid of the encrypted device")
+
 	// add the subcommands to the parent osd command
 	osdCmd.AddCommand(osdConfigCmd,
 		provisionCmd,
-		osdStartCmd)
+		osdStartCmd,
+		osdRotateKeyCmd,
+		osdOpenEncryptedDeviceCmd)
 }
 
 func addOSDConfigFlags(command *cobra.Command) {
@@ -120,10 +146,80 @@ func init() {
 	flags.SetFlagsFromEnv(osdConfigCmd.Flags(), rook.RookEnvVarPrefix)
 	flags.SetFlagsFromEnv(provisionCmd.Flags(), rook.RookEnvVarPrefix)
 	flags.SetFlagsFromEnv(osdStartCmd.Flags(), rook.RookEnvVarPrefix)
+	flags.SetFlagsFromEnv(osdRotateKeyCmd.Flags(), rook.RookEnvVarPrefix)
+	flags.SetFlagsFromEnv(osdOpenEncryptedDeviceCmd.Flags(), rook.RookEnvVarPrefix)
 
 	osdConfigCmd.RunE = writeOSDConfig
 	provisionCmd.RunE = prepareOSD
 	osdStartCmd.RunE = startOSD
+	osdRotateKeyCmd.RunE = rotateOSDKey
+	osdOpenEncryptedDeviceCmd.RunE = openEncryptedDevice
+}
+
+// Rotate the dmcrypt key of an osd encrypted by ceph-volume
+func rotateOSDKey(cmd *cobra.Command, args []string) error {
+	required := []string{"osd-uuid", "block-path"}
+	if err := flags.VerifyRequiredFlags(osdRotateKeyCmd, required); err != nil {
+		return err
+	}
+	required = []string{"mon-endpoints", "ceph-username", "ceph-secret"}
+	if err := flags.VerifyRequiredFlags(osdCmd, required); err != nil {
+		return err
+	}
+
+	commonOSDInit(osdRotateKeyCmd)
+
+	context := createContext()
+	// the keys of the key management service are saved in the header of the device rather than by the mons
+	if encryptionKeyID != "" {
+		provider, err := newKMSProvider()
+		if err != nil {
+			rook.TerminateFatal(err)
+		}
+		if err := osddaemon.RotateKMSEncryptionKey(context, provider, encryptionKeyID); err != nil {
+			rook.TerminateFatal(err)
+		}
+		return nil
+	}
+	if _, err := cephclient.GenerateConnectionConfig(context, &clusterInfo); err != nil {
+		rook.TerminateFatal(errors.Wrap(err, "failed to write connection config"))
+	}
+	if err := osddaemon.RotateEncryptionKey(context, &clusterInfo, osdUUID, blockPath); err != nil {
+		rook.TerminateFatal(err)
+	}
+	return nil
+}
+
+// Open the device of an osd encrypted with a key of the key management service before ceph-volume activates the osd
+func openEncryptedDevice(cmd *cobra.Command, args []string) error {
+	required := []string{"encryption-key-id"}
+	if err := flags.VerifyRequiredFlags(osdOpenEncryptedDeviceCmd, required); err != nil {
+		return err
+	}
+
+	commonOSDInit(osdOpenEncryptedDeviceCmd)
+
+	provider, err := newKMSProvider()
+	if err != nil {
+		rook.TerminateFatal(err)
+	}
+	context := createContext()
+	if err := osddaemon.OpenEncryptedDevice(context, provider, encryptionKeyID); err != nil {
+		rook.TerminateFatal(err)
+	}
+	return nil
+}
+
+// newKMSProvider creates the provider of the key management service configured in the environment by the operator
+func newKMSProvider() (kms.Provider, error) {
+	provider, err := kms.NewProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, errors.New("no key management service configured")
+	}
+	return provider, nil
 }
 
 // Start the osd daemon if provisioned by ceph-volume
@@ -220,8 +316,17 @@ func prepareOSD(cmd *cobra.Command, args []string) error {
 	forceFormat := false
 	ownerRef := opcontroller.ClusterOwnerRef(clusterInfo.Namespace, ownerRefID)
 	kv := k8sutil.NewConfigMapKVStore(clusterInfo.Namespace, context.Clientset, ownerRef)
+
+	// the devices are encrypted with the keys of the key management service if one is configured
+	var provider kms.Provider
+	if cfg.storeConfig.EncryptedDevice {
+		provider, err = kms.NewProviderFromEnv()
+		if err != nil {
+			rook.TerminateFatal(err)
+		}
+	}
 	agent := osddaemon.NewAgent(context, dgs, dataDevices, cfg.metadataDevice, forceFormat,
-		cfg.storeConfig, &clusterInfo, cfg.nodeName, kv, cfg.pvcBacked)
+		cfg.storeConfig, &clusterInfo, cfg.nodeName, kv, cfg.pvcBacked, replaceOSDIDs, provider)
 
 	err = osddaemon.Provision(context, agent, crushLocation)
 	if err != nil {
@@ -250,16 +355,21 @@ func commonOSDInit(cmd *cobra.Command) {
 func getLocation(clientset kubernetes.Interface) (string, error) {
 	// get the value the operator instructed to use as the host name in the CRUSH map
 	hostNameLabel := os.Getenv("ROOK_CRUSHMAP_HOSTNAME")
+	// the custom node labels of the crush topology of the cluster
+	topologyLabels, err := oposd.ParseCrushTopologyLabels(os.Getenv(oposd.CrushTopologyLabelsVarName))
+	if err != nil {
+		return "", err
+	}
 
-	loc, err := oposd.GetLocationWithNode(clientset, os.Getenv(k8sutil.NodeNameEnvVar), hostNameLabel)
+	loc, err := oposd.GetLocationWithNode(clientset, os.Getenv(k8sutil.NodeNameEnvVar), hostNameLabel, topologyLabels)
 	if err != nil {
 		return "", err
 	}
 	return loc, nil
 }
 
-func updateLocationWithNodeLabels(location *[]string, nodeLabels map[string]string) {
-	oposd.UpdateLocationWithNodeLabels(location, nodeLabels)
+func updateLocationWithNodeLabels(location *[]string, nodeLabels map[string]string, topologyLabels []cephv1.CrushTopologyLabelSpec) {
+	oposd.UpdateLocationWithNodeLabels(location, nodeLabels, topologyLabels)
 }
 
 // Parse the devices, which are sent as a JSON-marshalled list of device IDs with a StorageConfig spec
@@ -279,6 +389,7 @@ func parseDevices(devices string) ([]osddaemon.DesiredDevice, error) {
 		d.DatabaseSizeMB = cd.StoreConfig.DatabaseSizeMB
 		d.DeviceClass = cd.StoreConfig.DeviceClass
 		d.MetadataDevice = cd.StoreConfig.MetadataDevice
+		d.WalDevice = cd.StoreConfig.WalDevice
 
 		if d.OSDsPerDevice < 1 {
 			return nil, errors.Errorf("osds per device should be greater than 0 (%q)", d.OSDsPerDevice)
@@ -129,6 +129,7 @@ func TestParseDesiredDevices(t *testing.T) {
 				OSDsPerDevice:  1,
 				DeviceClass:    "tst",
 				MetadataDevice: "sdc",
+				WalDevice:      "nvme0n1p2",
 			},
 		},
 	}
@@ -157,6 +158,8 @@ func TestParseDesiredDevices(t *testing.T) {
 	assert.Equal(t, "sdb", result[1].MetadataDevice)
 	assert.Equal(t, "sdc", result[2].MetadataDevice)
 	assert.Equal(t, "sdc", result[3].MetadataDevice)
+	assert.Equal(t, "", result[2].WalDevice)
+	assert.Equal(t, "nvme0n1p2", result[3].WalDevice)
 	assert.False(t, result[0].IsFilter)
 	assert.False(t, result[1].IsFilter)
 	assert.False(t, result[2].IsFilter)
@@ -173,7 +176,7 @@ func TestDetectCrushLocation(t *testing.T) {
 	nodeLabels := map[string]string{}
 
 	// no change to the location if there are no labels
-	updateLocationWithNodeLabels(&location, nodeLabels)
+	updateLocationWithNodeLabels(&location, nodeLabels, nil)
 	assert.Equal(t, 1, len(location))
 	assert.Equal(t, "host=foo", location[0])
 
@@ -183,7 +186,7 @@ func TestDetectCrushLocation(t *testing.T) {
 		"invalid.topology.rook.io/rack": "r1",
 		"topology.rook.io/zone":         "z1",
 	}
-	updateLocationWithNodeLabels(&location, nodeLabels)
+	updateLocationWithNodeLabels(&location, nodeLabels, nil)
 	assert.Equal(t, 1, len(location))
 	assert.Equal(t, "host=foo", location[0])
 
@@ -202,7 +205,7 @@ func TestDetectCrushLocation(t *testing.T) {
 		"row=row1",
 		"zone=zone1",
 	}
-	updateLocationWithNodeLabels(&location, nodeLabels)
+	updateLocationWithNodeLabels(&location, nodeLabels, nil)
 
 	assert.Equal(t, 5, len(location))
 	for i, locString := range location {
@@ -16,10 +16,16 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"time"
 
+	"github.com/pkg/errors"
 	rook "github.com/rook/rook/cmd/rook/rook"
 	"github.com/rook/rook/pkg/daemon/discover"
+	opdiscover "github.com/rook/rook/pkg/operator/discover"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/util/flags"
 	"github.com/spf13/cobra"
 )
@@ -35,14 +41,36 @@ var (
 
 	// Uses ceph-volume inventory to extend devices information
 	usesCVInventory bool
+
+	// Collects the SMART health of the devices with smartctl
+	collectSmartData bool
+
+	discoverReportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Report the devices found by the discover daemons, like `ceph orch device ls`",
+	}
+
+	// the node to report the devices of, all the nodes if empty
+	reportNode string
+	// the namespace of the discover daemons
+	reportNamespace string
+	// the output format of the report: plain or json
+	reportFormat string
 )
 
 func init() {
 	discoverCmd.Flags().DurationVar(&discoverDevicesInterval, "discover-interval", 60*time.Minute, "interval between discovering devices (default 60m)")
 	discoverCmd.Flags().BoolVar(&usesCVInventory, "use-ceph-volume", false, "Use ceph-volume inventory to extend storage devices information (default false)")
+	discoverCmd.Flags().BoolVar(&collectSmartData, "collect-smart-data", false, "Collect the SMART health of the storage devices with smartctl (default false)")
 
 	flags.SetFlagsFromEnv(discoverCmd.Flags(), rook.RookEnvVarPrefix)
 	discoverCmd.RunE = startDiscover
+
+	discoverReportCmd.Flags().StringVar(&reportNode, "node", "", "report the devices of this node only")
+	discoverReportCmd.Flags().StringVar(&reportNamespace, "namespace", os.Getenv(k8sutil.PodNamespaceEnvVar), "namespace of the discover daemons")
+	discoverReportCmd.Flags().StringVar(&reportFormat, "format", "plain", "output format: plain or json (same format as `ceph orch device ls --format json`)")
+	discoverReportCmd.RunE = reportDevices
+	discoverCmd.AddCommand(discoverReportCmd)
 }
 
 func startDiscover(cmd *cobra.Command, args []string) error {
@@ -52,10 +80,34 @@ func startDiscover(cmd *cobra.Command, args []string) error {
 
 	context := rook.NewContext()
 
-	err := discover.Run(context, discoverDevicesInterval, usesCVInventory)
+	err := discover.Run(context, discoverDevicesInterval, usesCVInventory, collectSmartData)
 	if err != nil {
 		rook.TerminateFatal(err)
 	}
 
 	return nil
 }
+
+func reportDevices(cmd *cobra.Command, args []string) error {
+	rook.SetLogLevel()
+
+	context := rook.NewContext()
+	hosts, err := opdiscover.DeviceReport(context, reportNamespace, reportNode)
+	if err != nil {
+		return err
+	}
+
+	switch reportFormat {
+	case "json":
+		output, err := json.Marshal(hosts)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the device report")
+		}
+		fmt.Println(string(output))
+	case "plain":
+		fmt.Print(opdiscover.FormatDeviceReport(hosts))
+	default:
+		return errors.Errorf("unknown format %q", reportFormat)
+	}
+	return nil
+}
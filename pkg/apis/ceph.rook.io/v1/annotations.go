@@ -40,10 +40,32 @@ func GetCleanupAnnotations(a rook.AnnotationsSpec) rook.Annotations {
 	return mergeAllAnnotationsWithKey(a, KeyCleanup)
 }
 
+// GetMDSAnnotations returns the cluster annotations of the metadata servers
+func GetMDSAnnotations(a rook.AnnotationsSpec) rook.Annotations {
+	return mergeAllAnnotationsWithKey(a, KeyMDS)
+}
+
+// GetRGWAnnotations returns the cluster annotations of the object store gateways
+func GetRGWAnnotations(a rook.AnnotationsSpec) rook.Annotations {
+	return mergeAllAnnotationsWithKey(a, KeyRGW)
+}
+
+// GetNFSAnnotations returns the cluster annotations of the NFS servers
+func GetNFSAnnotations(a rook.AnnotationsSpec) rook.Annotations {
+	return mergeAllAnnotationsWithKey(a, KeyNFS)
+}
+
+// GetRBDMirrorAnnotations returns the cluster annotations of the rbd mirror daemons
+func GetRBDMirrorAnnotations(a rook.AnnotationsSpec) rook.Annotations {
+	return mergeAllAnnotationsWithKey(a, KeyRBDMirror)
+}
+
+// mergeAllAnnotationsWithKey merges the annotations of "all" into the annotations of the daemon, the annotations of
+// the daemon take precedence
 func mergeAllAnnotationsWithKey(a rook.AnnotationsSpec, name rook.KeyType) rook.Annotations {
 	all := a.All()
-	if all != nil {
-		return all.Merge(a[name])
+	if all == nil {
+		return a[name]
 	}
-	return a[name]
+	return a[name].Merge(all)
 }
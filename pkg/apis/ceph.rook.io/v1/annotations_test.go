@@ -68,4 +68,15 @@ func TestAnnotationMerge(t *testing.T) {
 	assert.Equal(t, "allval1", a["allkey1"])
 	assert.Equal(t, "allval2", a["allkey2"])
 	assert.Equal(t, 3, len(a))
+	// the annotations of "all" are not changed by the merge
+	a = GetMonAnnotations(testAnnotations)
+	assert.Equal(t, 2, len(a))
+
+	// the annotations of the daemon take precedence over "all"
+	testAnnotations = rook.AnnotationsSpec{
+		"all": {"key": "allval"},
+		"mon": {"key": "monval"},
+	}
+	assert.Equal(t, "monval", GetMonAnnotations(testAnnotations)["key"])
+	assert.Equal(t, "allval", GetMgrAnnotations(testAnnotations)["key"])
 }
@@ -28,6 +28,44 @@ func (p *PoolSpec) IsCompressionEnabled() bool {
 	return p.CompressionMode != ""
 }
 
+// EffectiveTargetSizeRatio returns the target size ratio of the pool, or of its replication settings if the pool has
+// none
+func (p *PoolSpec) EffectiveTargetSizeRatio() float64 {
+	if p.TargetSizeRatio != 0 {
+		return p.TargetSizeRatio
+	}
+	return p.Replicated.TargetSizeRatio
+}
+
+// ErasureCodeFailureDomain returns the failure domain of the erasure code profile of the pool
+func (p *PoolSpec) ErasureCodeFailureDomain() string {
+	if p.ErasureCoded.FailureDomain != "" {
+		return p.ErasureCoded.FailureDomain
+	}
+	return p.FailureDomain
+}
+
+// ErasureCodeCrushRoot returns the crush root of the erasure code profile of the pool
+func (p *PoolSpec) ErasureCodeCrushRoot() string {
+	if p.ErasureCoded.CrushRoot != "" {
+		return p.ErasureCoded.CrushRoot
+	}
+	return p.CrushRoot
+}
+
+// ErasureCodeDeviceClass returns the device class of the erasure code profile of the pool
+func (p *PoolSpec) ErasureCodeDeviceClass() string {
+	if p.ErasureCoded.DeviceClass != "" {
+		return p.ErasureCoded.DeviceClass
+	}
+	return p.DeviceClass
+}
+
 func (p *ReplicatedSpec) IsTargetRatioEnabled() bool {
 	return p.TargetSizeRatio != 0
 }
+
+// IsHybridStoragePool returns whether the copies of the objects are placed on two device classes
+func (p *ReplicatedSpec) IsHybridStoragePool() bool {
+	return p.HybridStorage != nil
+}
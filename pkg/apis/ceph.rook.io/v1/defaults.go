@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// The settings of the daemons of the child CRs are merged over the defaults of the cluster: the settings of the CR
+// take precedence over the settings of the daemon type in the cluster, which take precedence over the settings of "all".
+
+// WithClusterDefaults returns the settings of the metadata servers merged over the defaults of the cluster
+func (s MetadataServerSpec) WithClusterDefaults(c *ClusterSpec) MetadataServerSpec {
+	if c == nil {
+		return s
+	}
+	s.Placement = GetMDSPlacement(c.Placement).Merge(s.Placement)
+	s.Annotations = s.Annotations.Merge(GetMDSAnnotations(c.Annotations))
+	s.Labels = s.Labels.Merge(GetMDSLabels(c.Labels))
+	s.Resources = MergeResources(s.Resources, GetMDSResources(c.Resources))
+	if s.PriorityClassName == "" {
+		s.PriorityClassName = GetMDSPriorityClassName(c.PriorityClassNames)
+	}
+	return s
+}
+
+// WithClusterDefaults returns the settings of the object store gateways merged over the defaults of the cluster
+func (s GatewaySpec) WithClusterDefaults(c *ClusterSpec) GatewaySpec {
+	if c == nil {
+		return s
+	}
+	s.Placement = GetRGWPlacement(c.Placement).Merge(s.Placement)
+	s.Annotations = s.Annotations.Merge(GetRGWAnnotations(c.Annotations))
+	s.Labels = s.Labels.Merge(GetRGWLabels(c.Labels))
+	s.Resources = MergeResources(s.Resources, GetRGWResources(c.Resources))
+	if s.PriorityClassName == "" {
+		s.PriorityClassName = GetRGWPriorityClassName(c.PriorityClassNames)
+	}
+	return s
+}
+
+// WithClusterDefaults returns the settings of the NFS servers merged over the defaults of the cluster
+func (s GaneshaServerSpec) WithClusterDefaults(c *ClusterSpec) GaneshaServerSpec {
+	if c == nil {
+		return s
+	}
+	s.Placement = GetNFSPlacement(c.Placement).Merge(s.Placement)
+	s.Annotations = s.Annotations.Merge(GetNFSAnnotations(c.Annotations))
+	s.Labels = s.Labels.Merge(GetNFSLabels(c.Labels))
+	s.Resources = MergeResources(s.Resources, GetNFSResources(c.Resources))
+	if s.PriorityClassName == "" {
+		s.PriorityClassName = GetNFSPriorityClassName(c.PriorityClassNames)
+	}
+	return s
+}
+
+// WithClusterDefaults returns the settings of the rbd mirror daemons merged over the defaults of the cluster
+func (s RBDMirroringSpec) WithClusterDefaults(c *ClusterSpec) RBDMirroringSpec {
+	if c == nil {
+		return s
+	}
+	s.Placement = GetRBDMirrorPlacement(c.Placement).Merge(s.Placement)
+	s.Annotations = s.Annotations.Merge(GetRBDMirrorAnnotations(c.Annotations))
+	s.Resources = MergeResources(s.Resources, GetRBDMirrorResources(c.Resources))
+	if s.PriorityClassName == "" {
+		s.PriorityClassName = GetRBDMirrorPriorityClassName(c.PriorityClassNames)
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	rook "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMergeResources(t *testing.T) {
+	defaults := v1.ResourceRequirements{
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+	}
+	assert.Equal(t, defaults, MergeResources(v1.ResourceRequirements{}, defaults))
+	assert.Equal(t, defaults, MergeResources(defaults, v1.ResourceRequirements{}))
+
+	// a resource set in the requirements takes none of the defaults
+	r := v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("8Gi")}}
+	merged := MergeResources(r, defaults)
+	assert.Equal(t, v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}, merged.Limits)
+	assert.Equal(t, v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("8Gi")}, merged.Requests)
+	assert.Equal(t, 1, len(r.Requests))
+
+	// the resources of all are merged with the resources of the daemon
+	spec := rook.ResourceSpec{"all": defaults, "mgr": r}
+	assert.Equal(t, merged, GetMgrResources(spec))
+	assert.Equal(t, defaults, GetMonResources(spec))
+}
+
+func TestMetadataServerWithClusterDefaults(t *testing.T) {
+	toleration := func(key string) v1.Toleration {
+		return v1.Toleration{Key: key, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}
+	}
+	cluster := &ClusterSpec{
+		Placement: rook.PlacementSpec{
+			"all": {Tolerations: []v1.Toleration{toleration("storage")}},
+			"mds": {TopologySpreadConstraints: []v1.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "zone"}}},
+		},
+		Annotations: rook.AnnotationsSpec{"all": {"team": "storage", "owner": "ops"}, "mds": {"owner": "fs"}},
+		Labels:      rook.LabelsSpec{"mds": {"tier": "gold"}},
+		Resources:   rook.ResourceSpec{"mds": {Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")}}},
+	}
+	spec := MetadataServerSpec{
+		ActiveCount: 1,
+		Placement:   rook.Placement{Tolerations: []v1.Toleration{toleration("fs")}},
+		Annotations: rook.Annotations{"owner": "team-a"},
+	}
+
+	merged := spec.WithClusterDefaults(cluster)
+	assert.Equal(t, int32(1), merged.ActiveCount)
+	assert.Equal(t, []v1.Toleration{toleration("storage"), toleration("fs")}, merged.Placement.Tolerations)
+	assert.Equal(t, "zone", merged.Placement.TopologySpreadConstraints[0].TopologyKey)
+	assert.Equal(t, rook.Annotations{"team": "storage", "owner": "team-a"}, merged.Annotations)
+	assert.Equal(t, rook.Labels{"tier": "gold"}, merged.Labels)
+	assert.Equal(t, resource.MustParse("4Gi"), merged.Resources.Limits[v1.ResourceMemory])
+	// the spec of the CR is not modified
+	assert.Equal(t, rook.Annotations{"owner": "team-a"}, spec.Annotations)
+	assert.Nil(t, spec.Resources.Limits)
+
+	assert.Equal(t, spec, spec.WithClusterDefaults(nil))
+}
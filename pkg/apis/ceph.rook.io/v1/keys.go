@@ -25,4 +25,15 @@ const (
 	KeyMgr     rook.KeyType = "mgr"
 	KeyOSD     rook.KeyType = "osd"
 	KeyCleanup rook.KeyType = "cleanup"
+	KeyToolbox rook.KeyType = "toolbox"
+	// KeyPrepareOSD, KeyCrashCollector and KeyExporter are the osd prepare jobs, the crash collectors and pruner, and
+	// the ceph exporters
+	KeyPrepareOSD     rook.KeyType = "prepareosd"
+	KeyCrashCollector rook.KeyType = "crashcollector"
+	KeyExporter       rook.KeyType = "exporter"
+	// KeyMDS, KeyRGW, KeyNFS and KeyRBDMirror are the cluster defaults of the daemons of the child CRs
+	KeyMDS       rook.KeyType = "mds"
+	KeyRGW       rook.KeyType = "rgw"
+	KeyNFS       rook.KeyType = "nfs"
+	KeyRBDMirror rook.KeyType = "rbdmirror"
 )
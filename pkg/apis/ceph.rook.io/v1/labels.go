@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	rook "github.com/rook/rook/pkg/apis/rook.io/v1"
+)
+
+// GetMgrLabels returns the Labels for the MGR service
+func GetMgrLabels(a rook.LabelsSpec) rook.Labels {
+	return mergeAllLabelsWithKey(a, KeyMgr)
+}
+
+// GetMonLabels returns the Labels for the MON service
+func GetMonLabels(a rook.LabelsSpec) rook.Labels {
+	return mergeAllLabelsWithKey(a, KeyMon)
+}
+
+// GetOSDLabels returns the Labels for the OSD service
+func GetOSDLabels(a rook.LabelsSpec) rook.Labels {
+	return mergeAllLabelsWithKey(a, KeyOSD)
+}
+
+// GetMDSLabels returns the cluster labels of the metadata servers
+func GetMDSLabels(a rook.LabelsSpec) rook.Labels {
+	return mergeAllLabelsWithKey(a, KeyMDS)
+}
+
+// GetRGWLabels returns the cluster labels of the object store gateways
+func GetRGWLabels(a rook.LabelsSpec) rook.Labels {
+	return mergeAllLabelsWithKey(a, KeyRGW)
+}
+
+// GetNFSLabels returns the cluster labels of the NFS servers
+func GetNFSLabels(a rook.LabelsSpec) rook.Labels {
+	return mergeAllLabelsWithKey(a, KeyNFS)
+}
+
+// mergeAllLabelsWithKey merges the labels of "all" into the labels of the daemon, the labels of the daemon take
+// precedence
+func mergeAllLabelsWithKey(a rook.LabelsSpec, name rook.KeyType) rook.Labels {
+	all := a.All()
+	if all == nil {
+		return a[name]
+	}
+	return a[name].Merge(all)
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	rook "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelMerge(t *testing.T) {
+	assert.Nil(t, GetOSDLabels(rook.LabelsSpec{}))
+
+	testLabels := rook.LabelsSpec{
+		"all": {"team": "storage", "mesh": "enabled"},
+		"mon": {"mesh": "disabled"},
+	}
+	assert.Equal(t, rook.Labels{"team": "storage", "mesh": "disabled"}, GetMonLabels(testLabels))
+	assert.Equal(t, rook.Labels{"team": "storage", "mesh": "enabled"}, GetMgrLabels(testLabels))
+	assert.Equal(t, rook.Labels{"mesh": "disabled"}, GetMonLabels(rook.LabelsSpec{"mon": {"mesh": "disabled"}}))
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+const (
+	// DefaultPerfCountersPrioLimit is the priority limit of the performance counters exported when none is set
+	DefaultPerfCountersPrioLimit = 5
+	// DefaultStatsPeriodSeconds is the interval of the collections of the performance counters when none is set
+	DefaultStatsPeriodSeconds = 5
+)
+
+// IsExporterEnabled returns whether the ceph-exporter daemons are deployed
+func (m *MonitoringSpec) IsExporterEnabled() bool {
+	return m.Exporter != nil && m.Exporter.Enabled
+}
+
+// GetPerfCountersPrioLimit returns the priority limit of the exported performance counters, or the default limit if
+// none is set
+func (e *CephExporterSpec) GetPerfCountersPrioLimit() int64 {
+	if e.PerfCountersPrioLimit <= 0 {
+		return DefaultPerfCountersPrioLimit
+	}
+	return e.PerfCountersPrioLimit
+}
+
+// GetStatsPeriodSeconds returns the interval of the collections of the performance counters, or the default interval
+// if none is set
+func (e *CephExporterSpec) GetStatsPeriodSeconds() int64 {
+	if e.StatsPeriodSeconds <= 0 {
+		return DefaultStatsPeriodSeconds
+	}
+	return e.StatsPeriodSeconds
+}
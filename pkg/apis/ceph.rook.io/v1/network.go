@@ -22,3 +22,50 @@ func (net *NetworkSpec) IsHost() bool {
 	rookNet := net.NetworkSpec
 	return (net.HostNetwork && net.Provider == "") || rookNet.IsHost()
 }
+
+// IsEncryptionEnabled returns whether the connections of the cluster use the secure mode of msgr2
+func (net *NetworkSpec) IsEncryptionEnabled() bool {
+	return net.Connections != nil && net.Connections.Encryption != nil && net.Connections.Encryption.Enabled
+}
+
+// IsCompressionEnabled returns whether the connections between the OSDs are compressed
+func (net *NetworkSpec) IsCompressionEnabled() bool {
+	return net.Connections != nil && net.Connections.Compression != nil && net.Connections.Compression.Enabled
+}
+
+// IsIPv6 returns whether the daemons advertise IPv6 addresses
+func (net *NetworkSpec) IsIPv6() bool {
+	return net.IPFamily == IPv6
+}
+
+// GetMsgrPortRange returns the custom range of the ports of the daemons, or nil for the default range of ceph
+func (net *NetworkSpec) GetMsgrPortRange() *PortRangeSpec {
+	if net.HostPorts == nil {
+		return nil
+	}
+	return net.HostPorts.MsgrPortRange
+}
+
+// GetNFSPort returns the custom port of the nfs servers on the host network, or 0 for the default port
+func (net *NetworkSpec) GetNFSPort() int32 {
+	if net.HostPorts == nil || !net.IsHost() {
+		return 0
+	}
+	return net.HostPorts.NFSPort
+}
+
+// AreNetworkPoliciesEnabled returns whether the operator creates the network policies of the daemons, which only
+// apply to the pod network
+func (net *NetworkSpec) AreNetworkPoliciesEnabled() bool {
+	return net.NetworkPolicies != nil && net.NetworkPolicies.Enabled && !net.IsHost()
+}
+
+// Contains returns whether the port is in the range
+func (r *PortRangeSpec) Contains(port int32) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+// Overlaps returns whether the ranges have a port in common
+func (r *PortRangeSpec) Overlaps(other *PortRangeSpec) bool {
+	return r.Min <= other.Max && other.Min <= r.Max
+}
@@ -21,5 +21,10 @@ func (s *ObjectStoreSpec) IsMultisite() bool {
 }
 
 func (s *ObjectRealmSpec) IsPullRealm() bool {
-	return s.Pull.Endpoint != ""
+	return s.Pull.Endpoint != "" || s.IsExternalRealm()
+}
+
+// IsExternalRealm returns true if the master zone of the realm lives in an external cluster
+func (s *ObjectRealmSpec) IsExternalRealm() bool {
+	return s.Pull.External != nil
 }
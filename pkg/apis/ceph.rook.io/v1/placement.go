@@ -38,3 +38,28 @@ func GetOSDPlacement(p rookv1.PlacementSpec) rookv1.Placement {
 func GetCleanupPlacement(p rookv1.PlacementSpec) rookv1.Placement {
 	return p.All().Merge(p[KeyCleanup])
 }
+
+// GetToolboxPlacement returns the placement for the toolbox
+func GetToolboxPlacement(p rookv1.PlacementSpec) rookv1.Placement {
+	return p.All().Merge(p[KeyToolbox])
+}
+
+// GetMDSPlacement returns the cluster placement of the metadata servers
+func GetMDSPlacement(p rookv1.PlacementSpec) rookv1.Placement {
+	return p.All().Merge(p[KeyMDS])
+}
+
+// GetRGWPlacement returns the cluster placement of the object store gateways
+func GetRGWPlacement(p rookv1.PlacementSpec) rookv1.Placement {
+	return p.All().Merge(p[KeyRGW])
+}
+
+// GetNFSPlacement returns the cluster placement of the NFS servers
+func GetNFSPlacement(p rookv1.PlacementSpec) rookv1.Placement {
+	return p.All().Merge(p[KeyNFS])
+}
+
+// GetRBDMirrorPlacement returns the cluster placement of the rbd mirror daemons
+func GetRBDMirrorPlacement(p rookv1.PlacementSpec) rookv1.Placement {
+	return p.All().Merge(p[KeyRBDMirror])
+}
@@ -22,32 +22,71 @@ import (
 
 // GetMgrPriorityClassName returns the priority class name for the MGR service
 func GetMgrPriorityClassName(p rook.PriorityClassNamesSpec) string {
-	if _, ok := p[KeyMgr]; !ok {
-		return p.All()
-	}
-	return p[KeyMgr]
+	return getPriorityClassName(p, KeyMgr)
 }
 
 // GetMonPriorityClassName returns the priority class name for the monitors
 func GetMonPriorityClassName(p rook.PriorityClassNamesSpec) string {
-	if _, ok := p[KeyMon]; !ok {
-		return p.All()
-	}
-	return p[KeyMon]
+	return getPriorityClassName(p, KeyMon)
 }
 
 // GetOSDPriorityClassName returns the priority class name for the OSDs
 func GetOSDPriorityClassName(p rook.PriorityClassNamesSpec) string {
-	if _, ok := p[KeyOSD]; !ok {
-		return p.All()
-	}
-	return p[KeyOSD]
+	return getPriorityClassName(p, KeyOSD)
 }
 
 // GetCleanupPriorityClassName returns the priority class name for the cleanup job
 func GetCleanupPriorityClassName(p rook.PriorityClassNamesSpec) string {
-	if _, ok := p[KeyCleanup]; !ok {
+	return getPriorityClassName(p, KeyCleanup)
+}
+
+// GetPrepareOSDPriorityClassName returns the priority class name for the osd prepare jobs, which default to the
+// priority class of the OSDs
+func GetPrepareOSDPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	if _, ok := p[KeyPrepareOSD]; !ok {
+		return GetOSDPriorityClassName(p)
+	}
+	return p[KeyPrepareOSD]
+}
+
+// GetCrashCollectorPriorityClassName returns the priority class name for the crash collectors and the crash pruner
+func GetCrashCollectorPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyCrashCollector)
+}
+
+// GetExporterPriorityClassName returns the priority class name for the ceph exporters
+func GetExporterPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyExporter)
+}
+
+// GetToolboxPriorityClassName returns the priority class name for the toolbox and the command jobs
+func GetToolboxPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyToolbox)
+}
+
+// GetMDSPriorityClassName returns the cluster priority class name for the metadata servers
+func GetMDSPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyMDS)
+}
+
+// GetRGWPriorityClassName returns the cluster priority class name for the object store gateways
+func GetRGWPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyRGW)
+}
+
+// GetNFSPriorityClassName returns the cluster priority class name for the NFS servers
+func GetNFSPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyNFS)
+}
+
+// GetRBDMirrorPriorityClassName returns the cluster priority class name for the rbd mirror daemons
+func GetRBDMirrorPriorityClassName(p rook.PriorityClassNamesSpec) string {
+	return getPriorityClassName(p, KeyRBDMirror)
+}
+
+func getPriorityClassName(p rook.PriorityClassNamesSpec, key rook.KeyType) string {
+	if _, ok := p[key]; !ok {
 		return p.All()
 	}
-	return p[KeyCleanup]
+	return p[key]
 }
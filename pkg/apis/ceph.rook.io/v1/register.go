@@ -60,6 +60,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CephClusterList{},
 		&CephBlockPool{},
 		&CephBlockPoolList{},
+		&CephBlockPoolRadosNamespace{},
+		&CephBlockPoolRadosNamespaceList{},
+		&CephDeviceInventory{},
+		&CephDeviceInventoryList{},
 		&CephFilesystem{},
 		&CephFilesystemList{},
 		&CephNFS{},
@@ -76,6 +80,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CephObjectZoneList{},
 		&CephRBDMirror{},
 		&CephRBDMirrorList{},
+		&CephCommandJob{},
+		&CephCommandJobList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -32,36 +32,123 @@ const (
 	ResourcesKeyPrepareOSD = "prepareosd"
 	// ResourcesKeyCrashCollector represents the name of resource in the CR for the crash
 	ResourcesKeyCrashCollector = "crashcollector"
+	// ResourcesKeyLogCollector represents the name of resource in the CR for the log collector sidecars
+	ResourcesKeyLogCollector = "logcollector"
+	// ResourcesKeyLogShipper represents the name of resource in the CR for the log shipper sidecars
+	ResourcesKeyLogShipper = "logshipper"
+	// ResourcesKeyToolbox represents the name of resource in the CR for the toolbox
+	ResourcesKeyToolbox = "toolbox"
 	// ResourcesKeyCleanup represents the name of resource in the CR for the cleanup
 	ResourcesKeyCleanup = "cleanup"
+	// ResourcesKeyMDS represents the name of resource in the CR for the default resources of the metadata servers
+	ResourcesKeyMDS = "mds"
+	// ResourcesKeyRGW represents the name of resource in the CR for the default resources of the object store gateways
+	ResourcesKeyRGW = "rgw"
+	// ResourcesKeyNFS represents the name of resource in the CR for the default resources of the NFS servers
+	ResourcesKeyNFS = "nfs"
+	// ResourcesKeyRBDMirror represents the name of resource in the CR for the default resources of the rbd mirror daemons
+	ResourcesKeyRBDMirror = "rbdmirror"
 )
 
 // GetMgrResources returns the placement for the MGR service
 func GetMgrResources(p rook.ResourceSpec) v1.ResourceRequirements {
-	return p[ResourcesKeyMgr]
+	return mergeAllResourcesWithKey(p, ResourcesKeyMgr)
 }
 
 // GetMonResources returns the placement for the monitors
 func GetMonResources(p rook.ResourceSpec) v1.ResourceRequirements {
-	return p[ResourcesKeyMon]
+	return mergeAllResourcesWithKey(p, ResourcesKeyMon)
 }
 
 // GetOSDResources returns the placement for the OSDs
 func GetOSDResources(p rook.ResourceSpec) v1.ResourceRequirements {
-	return p[ResourcesKeyOSD]
+	return mergeAllResourcesWithKey(p, ResourcesKeyOSD)
 }
 
 // GetPrepareOSDResources returns the placement for the OSDs prepare job
 func GetPrepareOSDResources(p rook.ResourceSpec) v1.ResourceRequirements {
-	return p[ResourcesKeyPrepareOSD]
+	return mergeAllResourcesWithKey(p, ResourcesKeyPrepareOSD)
 }
 
 // GetCrashCollectorResources returns the placement for the crash daemon
 func GetCrashCollectorResources(p rook.ResourceSpec) v1.ResourceRequirements {
-	return p[ResourcesKeyCrashCollector]
+	return mergeAllResourcesWithKey(p, ResourcesKeyCrashCollector)
+}
+
+// GetLogCollectorResources returns the placement for the log collector sidecars
+func GetLogCollectorResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyLogCollector)
+}
+
+// GetLogShipperResources returns the placement for the log shipper sidecars
+func GetLogShipperResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyLogShipper)
+}
+
+// GetToolboxResources returns the placement for the toolbox
+func GetToolboxResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyToolbox)
 }
 
 // GetCleanupResources returns the placement for the cleanup job
 func GetCleanupResources(p rook.ResourceSpec) v1.ResourceRequirements {
-	return p[ResourcesKeyCleanup]
+	return mergeAllResourcesWithKey(p, ResourcesKeyCleanup)
+}
+
+// GetMDSResources returns the cluster resources of the metadata servers
+func GetMDSResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyMDS)
+}
+
+// GetRGWResources returns the cluster resources of the object store gateways
+func GetRGWResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyRGW)
+}
+
+// GetNFSResources returns the cluster resources of the NFS servers
+func GetNFSResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyNFS)
+}
+
+// GetRBDMirrorResources returns the cluster resources of the rbd mirror daemons
+func GetRBDMirrorResources(p rook.ResourceSpec) v1.ResourceRequirements {
+	return mergeAllResourcesWithKey(p, ResourcesKeyRBDMirror)
+}
+
+// mergeAllResourcesWithKey merges the resources of "all" into the resources of the daemon, the resources of the daemon
+// take precedence
+func mergeAllResourcesWithKey(p rook.ResourceSpec, name string) v1.ResourceRequirements {
+	return MergeResources(p[name], p[string(rook.KeyAll)])
+}
+
+// MergeResources returns the resource requirements merging the defaults into the given requirements. A resource with a
+// request or a limit in the given requirements takes none of the defaults, so that a request never exceeds a default
+// limit.
+func MergeResources(r, defaults v1.ResourceRequirements) v1.ResourceRequirements {
+	if len(defaults.Limits) == 0 && len(defaults.Requests) == 0 {
+		return r
+	}
+	ret := *r.DeepCopy()
+	isSet := func(name v1.ResourceName) bool {
+		_, limit := r.Limits[name]
+		_, request := r.Requests[name]
+		return limit || request
+	}
+	for name, quantity := range defaults.Limits {
+		if !isSet(name) {
+			if ret.Limits == nil {
+				ret.Limits = v1.ResourceList{}
+			}
+			ret.Limits[name] = quantity.DeepCopy()
+		}
+	}
+	for name, quantity := range defaults.Requests {
+		if !isSet(name) {
+			if ret.Requests == nil {
+				ret.Requests = v1.ResourceList{}
+			}
+			ret.Requests[name] = quantity.DeepCopy()
+		}
+	}
+	return ret
 }
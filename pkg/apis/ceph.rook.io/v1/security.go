@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IsEnabled returns whether a key management service is configured
+func (kms *KeyManagementServiceSpec) IsEnabled() bool {
+	return len(kms.ConnectionDetails) > 0
+}
+
+// DefaultKeyRotationInterval is the interval of the rotation of the encryption keys of the OSDs when none is set
+const DefaultKeyRotationInterval = 30 * 24 * time.Hour
+
+// GetInterval returns the interval of the rotation of the keys, or the default interval if none is set
+func (k *KeyRotationSpec) GetInterval() (time.Duration, error) {
+	if k.Interval == "" {
+		return DefaultKeyRotationInterval, nil
+	}
+	interval, err := time.ParseDuration(k.Interval)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse key rotation interval %q", k.Interval)
+	}
+	if interval <= 0 {
+		return 0, errors.Errorf("invalid key rotation interval %q, it must be positive", k.Interval)
+	}
+	return interval, nil
+}
+
+const (
+	// TLSIssuerSelfSigned issues the certificates with the CA of the operator
+	TLSIssuerSelfSigned = "self-signed"
+	// TLSIssuerCertManager issues the certificates with cert-manager
+	TLSIssuerCertManager = "cert-manager"
+	// DefaultCertificateDuration is the validity of the certificates when none is set
+	DefaultCertificateDuration = 90 * 24 * time.Hour
+)
+
+// IsEnabled returns whether the operator manages the certificates
+func (t *TLSSpec) IsEnabled() bool {
+	return t.Issuer != ""
+}
+
+// GetDuration returns the validity of the certificates, or the default validity if none is set
+func (t *TLSSpec) GetDuration() (time.Duration, error) {
+	if t.Duration == "" {
+		return DefaultCertificateDuration, nil
+	}
+	duration, err := time.ParseDuration(t.Duration)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse certificate duration %q", t.Duration)
+	}
+	if duration <= 0 {
+		return 0, errors.Errorf("invalid certificate duration %q, it must be positive", t.Duration)
+	}
+	return duration, nil
+}
+
+// GetRenewBefore returns the remaining validity of the certificates when they are renewed, a third of the duration
+// if none is set
+func (t *TLSSpec) GetRenewBefore() (time.Duration, error) {
+	duration, err := t.GetDuration()
+	if err != nil {
+		return 0, err
+	}
+	if t.RenewBefore == "" {
+		return duration / 3, nil
+	}
+	renewBefore, err := time.ParseDuration(t.RenewBefore)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse certificate renewBefore %q", t.RenewBefore)
+	}
+	if renewBefore <= 0 || renewBefore >= duration {
+		return 0, errors.Errorf("invalid certificate renewBefore %q, it must be positive and less than the duration", t.RenewBefore)
+	}
+	return renewBefore, nil
+}
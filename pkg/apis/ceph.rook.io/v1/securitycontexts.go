@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	rook "github.com/rook/rook/pkg/apis/rook.io/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// All returns the pod security context defined for 'all' daemons
+func (p PodSecurityContextsSpec) All() v1.PodSecurityContext {
+	return p[rook.KeyAll]
+}
+
+// GetPodSecurityContext returns the pod security context of a daemon type, the settings of the daemon type take
+// precedence over the settings of "all". Nil is returned if neither is defined.
+func GetPodSecurityContext(p PodSecurityContextsSpec, key rook.KeyType) *v1.PodSecurityContext {
+	all, allOK := p[rook.KeyAll]
+	daemon, daemonOK := p[key]
+	if !allOK && !daemonOK {
+		return nil
+	}
+
+	ret := all.DeepCopy()
+	daemon = *daemon.DeepCopy()
+	if daemon.SELinuxOptions != nil {
+		ret.SELinuxOptions = daemon.SELinuxOptions
+	}
+	if daemon.WindowsOptions != nil {
+		ret.WindowsOptions = daemon.WindowsOptions
+	}
+	if daemon.RunAsUser != nil {
+		ret.RunAsUser = daemon.RunAsUser
+	}
+	if daemon.RunAsGroup != nil {
+		ret.RunAsGroup = daemon.RunAsGroup
+	}
+	if daemon.RunAsNonRoot != nil {
+		ret.RunAsNonRoot = daemon.RunAsNonRoot
+	}
+	if daemon.SupplementalGroups != nil {
+		ret.SupplementalGroups = daemon.SupplementalGroups
+	}
+	if daemon.FSGroup != nil {
+		ret.FSGroup = daemon.FSGroup
+	}
+	if daemon.Sysctls != nil {
+		ret.Sysctls = daemon.Sysctls
+	}
+	return ret
+}
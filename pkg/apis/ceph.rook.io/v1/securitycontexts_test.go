@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	rook "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetPodSecurityContext(t *testing.T) {
+	assert.Nil(t, GetPodSecurityContext(nil, KeyMon))
+
+	group := int64(167)
+	user := int64(2000)
+	nonRoot := true
+	contexts := PodSecurityContextsSpec{
+		"all": {FSGroup: &group, SupplementalGroups: []int64{10}},
+		"rgw": {RunAsUser: &user, RunAsNonRoot: &nonRoot, SupplementalGroups: []int64{20}},
+	}
+
+	// the settings of the daemon take precedence over the settings of all
+	rgw := GetPodSecurityContext(contexts, KeyRGW)
+	assert.Equal(t, &v1.PodSecurityContext{FSGroup: &group, RunAsUser: &user, RunAsNonRoot: &nonRoot, SupplementalGroups: []int64{20}}, rgw)
+	assert.Equal(t, &v1.PodSecurityContext{FSGroup: &group, SupplementalGroups: []int64{10}}, GetPodSecurityContext(contexts, KeyMon))
+
+	// only the daemon is set
+	delete(contexts, "all")
+	assert.Nil(t, GetPodSecurityContext(contexts, KeyMon))
+	assert.Equal(t, &user, GetPodSecurityContext(contexts, KeyRGW).RunAsUser)
+}
+
+func TestGetPriorityClassNames(t *testing.T) {
+	p := rook.PriorityClassNamesSpec{"all": "all-class", "osd": "osd-class", "exporter": "exporter-class"}
+	assert.Equal(t, "osd-class", GetPrepareOSDPriorityClassName(p))
+	assert.Equal(t, "exporter-class", GetExporterPriorityClassName(p))
+	assert.Equal(t, "all-class", GetCrashCollectorPriorityClassName(p))
+
+	p["prepareosd"] = "prepare-class"
+	assert.Equal(t, "prepare-class", GetPrepareOSDPriorityClassName(p))
+	assert.Equal(t, "", GetToolboxPriorityClassName(rook.PriorityClassNamesSpec{}))
+
+	// the priority class of the CR takes precedence over the cluster default
+	cluster := &ClusterSpec{PriorityClassNames: rook.PriorityClassNamesSpec{"rgw": "rgw-class"}}
+	assert.Equal(t, "rgw-class", GatewaySpec{}.WithClusterDefaults(cluster).PriorityClassName)
+	assert.Equal(t, "store-class", GatewaySpec{PriorityClassName: "store-class"}.WithClusterDefaults(cluster).PriorityClassName)
+}
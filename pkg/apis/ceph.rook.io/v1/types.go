@@ -21,6 +21,7 @@ import (
 
 	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -44,12 +45,24 @@ type CephCluster struct {
 type CephClusterHealthCheckSpec struct {
 	DaemonHealth  DaemonHealthSpec                     `json:"daemonHealth,omitempty"`
 	LivenessProbe map[rookv1.KeyType]*rookv1.ProbeSpec `json:"livenessProbe,omitempty"`
+	// StartupProbe is the startup probe of the mon, mgr, osd and mds daemons, which holds their liveness probe until
+	// it succeeds
+	StartupProbe map[rookv1.KeyType]*rookv1.ProbeSpec `json:"startupProbe,omitempty"`
+	// ReadinessProbe is the readiness probe of the mon, mgr, osd and mds daemons
+	ReadinessProbe map[rookv1.KeyType]*rookv1.ProbeSpec `json:"readinessProbe,omitempty"`
 }
 
 type DaemonHealthSpec struct {
-	Status              HealthCheckSpec `json:"status,omitempty"`
-	Monitor             HealthCheckSpec `json:"mon,omitempty"`
-	ObjectStorageDaemon HealthCheckSpec `json:"osd,omitempty"`
+	Status              HealthCheckSpec    `json:"status,omitempty"`
+	Monitor             HealthCheckSpec    `json:"mon,omitempty"`
+	ObjectStorageDaemon OSDHealthCheckSpec `json:"osd,omitempty"`
+	// Pool is the check of the usage and the placement groups of the pools reported in the status of the pool CRs
+	Pool HealthCheckSpec `json:"pool,omitempty"`
+	// Crash is the check of the new crash reports of the daemons and of the failed mgr modules
+	Crash CrashHealthCheckSpec `json:"crash,omitempty"`
+	// FilesystemMirror is the check of the snapshot mirroring of the filesystems reported in the status of the
+	// filesystem CRs
+	FilesystemMirror HealthCheckSpec `json:"filesystemMirror,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -74,6 +87,14 @@ type ClusterSpec struct {
 	// The annotations-related configuration to add/set on each Pod related object.
 	Annotations rookv1.AnnotationsSpec `json:"annotations,omitempty"`
 
+	// The labels-related configuration to add/set on each Pod related object.
+	Labels rookv1.LabelsSpec `json:"labels,omitempty"`
+
+	// InheritedMetadata are the labels and annotations added to all the resources generated by the operator in the
+	// namespace of the cluster, including the resources of the other CRs like the object stores and the filesystems
+	// +optional
+	InheritedMetadata InheritedMetadataSpec `json:"inheritedMetadata,omitempty"`
+
 	// The placement-related configuration to pass to kubernetes (affinity, node selector, tolerations).
 	Placement rookv1.PlacementSpec `json:"placement,omitempty"`
 
@@ -83,6 +104,35 @@ type ClusterSpec struct {
 	// Resources set resource requests and limits
 	Resources rookv1.ResourceSpec `json:"resources,omitempty"`
 
+	// ResourceAutoscaling adjusts the resource requests of the daemons based on their observed usage
+	// +optional
+	ResourceAutoscaling ResourceAutoscalingSpec `json:"resourceAutoscaling,omitempty"`
+
+	// OSDPerformance tunes the OSD pods for the CPU manager and the topology manager of the kubelet
+	// +optional
+	OSDPerformance OSDPerformanceSpec `json:"osdPerformance,omitempty"`
+
+	// Scrubbing is the schedule and the throttling of the scrubs of the placement groups by the OSDs
+	// +optional
+	Scrubbing ScrubbingSpec `json:"scrubbing,omitempty"`
+
+	// Capacity is the full ratios of the OSDs and the reweight of the OSDs by their utilization
+	// +optional
+	Capacity CapacitySpec `json:"capacity,omitempty"`
+
+	// Balancer is the policy of the balancer module of the mgr
+	// +optional
+	Balancer BalancerSpec `json:"balancer,omitempty"`
+
+	// NewDevices is the provisioning of the OSDs on the devices appearing on the nodes which already have OSDs
+	// +optional
+	NewDevices NewDevicesSpec `json:"newDevices,omitempty"`
+
+	// CrushTopology maps custom node labels to the buckets of the CRUSH map and controls the moves of the nodes in
+	// the CRUSH map when their labels change
+	// +optional
+	CrushTopology CrushTopologySpec `json:"crushTopology,omitempty"`
+
 	// PriorityClassNames sets priority classes on components
 	PriorityClassNames rookv1.PriorityClassNamesSpec `json:"priorityClassNames,omitempty"`
 
@@ -95,6 +145,9 @@ type ClusterSpec struct {
 	// ContinueUpgradeAfterChecksEvenIfNotHealthy defines if an upgrade should continue even if PGs are not clean
 	ContinueUpgradeAfterChecksEvenIfNotHealthy bool `json:"continueUpgradeAfterChecksEvenIfNotHealthy,omitempty"`
 
+	// UpgradeChecks overrides the upgrade checks per daemon type and sets how long the checks wait for the daemons
+	UpgradeChecks *UpgradeChecksSpec `json:"upgradeChecks,omitempty"`
+
 	// A spec for configuring disruption management.
 	DisruptionManagement DisruptionManagementSpec `json:"disruptionManagement,omitempty"`
 
@@ -104,6 +157,12 @@ type ClusterSpec struct {
 	// A spec for the crash controller
 	CrashCollector CrashCollectorSpec `json:"crashCollector"`
 
+	// A spec for the log collector of the daemons
+	LogCollector LogCollectorSpec `json:"logCollector,omitempty"`
+
+	// A spec for the toolbox deployed by the operator
+	Toolbox ToolboxSpec `json:"toolbox,omitempty"`
+
 	// Dashboard settings
 	Dashboard DashboardSpec `json:"dashboard,omitempty"`
 
@@ -126,6 +185,316 @@ type ClusterSpec struct {
 
 	// Internal daemon healthchecks and liveness probe
 	HealthCheck CephClusterHealthCheckSpec `json:"healthCheck"`
+
+	// CSI driver settings for this cluster
+	// +optional
+	CSI CSIDriverSpec `json:"csi,omitempty"`
+
+	// Security represents security settings
+	// +optional
+	Security SecuritySpec `json:"security,omitempty"`
+}
+
+// InheritedMetadataSpec are the labels and annotations inherited by the resources generated in the namespace of the
+// cluster. The labels and annotations set by the operator or in the specs of the CRs take precedence.
+type InheritedMetadataSpec struct {
+	// Labels are added to the resources and to the templates of their pods
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the resources and to the templates of their pods
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SecuritySpec is security spec to include various security items such as the distribution of the admin key
+type SecuritySpec struct {
+	// RestrictAdminKey stops mounting the admin key in the pods of the daemons, they use purpose-scoped keys instead
+	RestrictAdminKey bool `json:"restrictAdminKey,omitempty"`
+	// KeyManagementService is the key management service protecting the encryption keys of the OSDs
+	// +optional
+	KeyManagementService KeyManagementServiceSpec `json:"kms,omitempty"`
+	// KeyRotation is the policy of rotating the encryption keys of the OSDs
+	// +optional
+	KeyRotation KeyRotationSpec `json:"keyRotation,omitempty"`
+	// CephxKeyRotation is the policy of rotating the cephx keys of the daemons and the CSI users
+	// +optional
+	CephxKeyRotation CephxKeyRotationSpec `json:"cephxKeyRotation,omitempty"`
+	// TLS configures the certificates issued by the operator to the rgw gateways and the dashboard
+	// +optional
+	TLS TLSSpec `json:"tls,omitempty"`
+	// PodSecurityContexts sets the pod security contexts of the daemons by daemon type
+	// +optional
+	PodSecurityContexts PodSecurityContextsSpec `json:"podSecurityContexts,omitempty"`
+}
+
+// PodSecurityContextsSpec is the pod security contexts by daemon type, the settings of "all" apply to the daemon
+// types without the setting
+type PodSecurityContextsSpec map[rookv1.KeyType]v1.PodSecurityContext
+
+// TLSSpec represents the issuer and the validity of the certificates managed by the operator
+type TLSSpec struct {
+	// Issuer issues the certificates, "self-signed" for the CA of the operator or "cert-manager". The certificates
+	// are not managed by the operator if empty.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+	// CertManagerIssuerRef is the cert-manager issuer of the certificates when the issuer is cert-manager
+	// +optional
+	CertManagerIssuerRef *CertManagerIssuerRef `json:"certManagerIssuerRef,omitempty"`
+	// Duration is the validity of the certificates, for example "2160h". Defaults to 90 days.
+	// +optional
+	Duration string `json:"duration,omitempty"`
+	// RenewBefore is the remaining validity of the certificates when they are renewed. Defaults to a third of the
+	// duration.
+	// +optional
+	RenewBefore string `json:"renewBefore,omitempty"`
+}
+
+// CertManagerIssuerRef references the cert-manager issuer of the certificates
+type CertManagerIssuerRef struct {
+	// Name is the name of the issuer
+	Name string `json:"name"`
+	// Kind is the kind of the issuer, Issuer or ClusterIssuer. Defaults to Issuer.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// KeyManagementServiceSpec represent various details of the KMS server
+type KeyManagementServiceSpec struct {
+	// ConnectionDetails contains the KMS_PROVIDER selecting the provider and the settings of the provider
+	// +optional
+	ConnectionDetails map[string]string `json:"connectionDetails,omitempty"`
+	// TokenSecretName is the name of the secret holding the credentials of the provider, its keys are merged into the
+	// connection details
+	// +optional
+	TokenSecretName string `json:"tokenSecretName,omitempty"`
+}
+
+// KeyRotationSpec represents the policy of rotating the dmcrypt keys of the encrypted OSDs
+type KeyRotationSpec struct {
+	// Enabled turns on the rotation of the keys
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval is the minimum duration between two rotations of the keys, for example "720h". Defaults to 30 days.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
+// CephxKeyRotationSpec represents the policy of rotating the cephx keys of the daemons and the CSI users
+type CephxKeyRotationSpec struct {
+	// KeyRotationSpec rotates the keys periodically when enabled
+	KeyRotationSpec `json:",inline"`
+	// KeyGeneration rotates the keys on demand each time it is increased
+	// +optional
+	KeyGeneration int `json:"keyGeneration,omitempty"`
+	// Daemons are the types of the daemons whose keys are rotated: mgr, osd, mds, rgw and csi. Defaults to all of them.
+	// +optional
+	Daemons []string `json:"daemons,omitempty"`
+}
+
+// ResourceAutoscalingSpec defines the daemons whose resource requests are adjusted to their usage
+type ResourceAutoscalingSpec struct {
+	// OSD defines the resource autoscaling of the OSDs
+	// +optional
+	OSD DaemonResourceAutoscalingSpec `json:"osd,omitempty"`
+}
+
+// OSDPerformanceSpec tunes the OSD pods of high-performance deployments. The hugepages requested in the resources
+// of the OSDs are mounted in the OSD pods in any case.
+type OSDPerformanceSpec struct {
+	// StaticCPUs rounds up the CPU requests of the OSDs to integer CPUs and sets the limits to the requests, so that
+	// the OSD pods are in the Guaranteed QoS class and get exclusive CPUs from the static policy of the CPU manager
+	// +optional
+	StaticCPUs bool `json:"staticCPUs,omitempty"`
+	// NUMANode is the NUMA node whose CPUs run the threads of the OSDs, for the topology manager to align the
+	// exclusive CPUs and the devices of the OSDs on the node
+	// +optional
+	NUMANode *int `json:"numaNode,omitempty"`
+	// BluestoreCacheSize is the fixed size of the bluestore cache of each OSD, the cache is autotuned from the
+	// memory target of the OSD if not set
+	// +optional
+	BluestoreCacheSize *resource.Quantity `json:"bluestoreCacheSize,omitempty"`
+	// DeviceClasses tunes the OSDs of the device classes, like running several OSDs on each NVMe device
+	// +optional
+	DeviceClasses []OSDDeviceClassSpec `json:"deviceClasses,omitempty"`
+}
+
+// OSDDeviceClassSpec tunes the OSDs on the devices of a class. The class of a device is its deviceClass setting, or
+// nvme, ssd or hdd detected from the device.
+type OSDDeviceClassSpec struct {
+	// Name is the device class, like "nvme"
+	Name string `json:"name"`
+	// OSDsPerDevice is the number of OSDs created on each new device of the class. The osdsPerDevice setting of a
+	// device takes precedence.
+	// +optional
+	OSDsPerDevice int `json:"osdsPerDevice,omitempty"`
+	// ScaleResources divides the resource requests and limits of the OSDs by the number of OSDs sharing their device,
+	// so that the resources of the OSDs are set for a whole device
+	// +optional
+	ScaleResources bool `json:"scaleResources,omitempty"`
+	// AntiAffinity prefers scheduling the OSDs of the class on different nodes. Only the portable OSDs can move, the
+	// other OSDs run on the node of their device.
+	// +optional
+	AntiAffinity bool `json:"antiAffinity,omitempty"`
+}
+
+// NewDevicesSpec holds the OSD provisioning on the empty devices appearing on the nodes which already have OSDs,
+// like hot-plugged disks, when the devices of the nodes are selected with useAllDevices or a device filter. The new
+// devices are provisioned at the next orchestration when no setting is set.
+type NewDevicesSpec struct {
+	// QuarantinePeriod is how long a new device must have been found on a node before an OSD is provisioned on it,
+	// like "24h"
+	// +optional
+	QuarantinePeriod string `json:"quarantinePeriod,omitempty"`
+	// RequireApproval requires the new devices be listed in the allow annotation of their node or match the device
+	// filter before an OSD is provisioned on them
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+	// DeviceFilter is a regular expression of the names of the new devices approved without the annotation
+	// +optional
+	DeviceFilter string `json:"deviceFilter,omitempty"`
+}
+
+// CrushTopologySpec is the CRUSH location of the OSDs of the nodes built from custom node labels, in addition to the
+// zone, region and topology.rook.io labels
+type CrushTopologySpec struct {
+	// Labels are the node labels translated into CRUSH buckets, ordered from the lowest to the highest CRUSH level
+	// +optional
+	Labels []CrushTopologyLabelSpec `json:"labels,omitempty"`
+	// ConfirmMoves requires the new CRUSH location of a node whose labels changed be confirmed with an annotation of
+	// the node before the OSDs of the node are moved
+	// +optional
+	ConfirmMoves bool `json:"confirmMoves,omitempty"`
+}
+
+// CrushTopologyLabelSpec maps a node label to a CRUSH bucket type
+type CrushTopologyLabelSpec struct {
+	// Key is the key of the node label, like "example.com/rack"
+	Key string `json:"key"`
+	// Type is the CRUSH bucket type of the values of the label, like "rack"
+	Type string `json:"type"`
+}
+
+// ScrubbingSpec is the window and the throttling of the scrubs and the deep scrubs of the OSDs. The settings not set
+// keep the defaults of Ceph.
+type ScrubbingSpec struct {
+	// BeginHour is the hour of the day from which the scrubs are scheduled, between 0 and 23
+	// +optional
+	BeginHour *int `json:"beginHour,omitempty"`
+	// EndHour is the hour of the day from which the scrubs are not scheduled, between 0 and 23
+	// +optional
+	EndHour *int `json:"endHour,omitempty"`
+	// BeginWeekDay is the day of the week from which the scrubs are scheduled, between 0 for Sunday and 6
+	// +optional
+	BeginWeekDay *int `json:"beginWeekDay,omitempty"`
+	// EndWeekDay is the day of the week from which the scrubs are not scheduled, between 0 for Sunday and 6
+	// +optional
+	EndWeekDay *int `json:"endWeekDay,omitempty"`
+	// MaxScrubsPerOSD is the maximum number of concurrent scrubs of an OSD
+	// +optional
+	MaxScrubsPerOSD *int `json:"maxScrubsPerOSD,omitempty"`
+	// Sleep is the pause between two chunks of a scrub, for example "100ms"
+	// +optional
+	Sleep string `json:"sleep,omitempty"`
+	// DeepScrubInterval is the interval between two deep scrubs of a placement group, for example "168h"
+	// +optional
+	DeepScrubInterval string `json:"deepScrubInterval,omitempty"`
+}
+
+// CapacitySpec is the usage ratios of the OSDs from which the cluster warns, refuses the backfills and refuses the
+// writes, and the reweight of the OSDs by their utilization. The ratios not set keep the ratios of the cluster.
+type CapacitySpec struct {
+	// NearFullRatio is the usage ratio of an OSD from which the health of the cluster is warned, e.g. 0.85
+	// +optional
+	NearFullRatio *float64 `json:"nearFullRatio,omitempty"`
+	// BackfillFullRatio is the usage ratio of an OSD from which the backfills to the OSD are refused, e.g. 0.90
+	// +optional
+	BackfillFullRatio *float64 `json:"backfillFullRatio,omitempty"`
+	// FullRatio is the usage ratio of an OSD from which the writes to the cluster are refused, e.g. 0.95
+	// +optional
+	FullRatio *float64 `json:"fullRatio,omitempty"`
+	// ReweightByUtilization periodically lowers the weight of the OSDs used above the average utilization
+	// +optional
+	ReweightByUtilization ReweightByUtilizationSpec `json:"reweightByUtilization,omitempty"`
+}
+
+// BalancerSpec is the policy of the balancer module of the mgr, which moves the placement groups to balance the OSDs.
+// The balancer is on in the upmap mode by default from Octopus.
+type BalancerSpec struct {
+	// Enabled determines whether the balancer is on, true by default
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Mode is the mode of the balancer, "upmap" or "crush-compat", "upmap" by default
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// Interval is the interval between two optimizations of the balancer, 60s by default
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
+// ReweightByUtilizationSpec is the periodic "ceph osd reweight-by-utilization" of the OSDs, which only lowers the
+// weight of a few OSDs above the average utilization at each run, and only when the placement groups are clean
+type ReweightByUtilizationSpec struct {
+	// Enabled determines whether the OSDs are reweighted
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval is the interval between two reweights, 24h by default
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Threshold is the utilization of an OSD from which it is reweighted, in percent of the average utilization of
+	// the OSDs, between 110 and 200, 120 by default
+	// +optional
+	Threshold int `json:"threshold,omitempty"`
+	// MaxChange is the maximum change of the weight of an OSD at each reweight, between 0.01 and 0.1, 0.05 by default
+	// +optional
+	MaxChange float64 `json:"maxChange,omitempty"`
+	// MaxOSDs is the maximum number of OSDs reweighted at each reweight, between 1 and 10, 4 by default
+	// +optional
+	MaxOSDs int `json:"maxOSDs,omitempty"`
+}
+
+// DaemonResourceAutoscalingSpec defines how the resource requests of the daemons are adjusted
+// based on their cpu and memory usage reported by the metrics API
+type DaemonResourceAutoscalingSpec struct {
+	// Enabled determines whether the resource requests are adjusted
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval is the interval between two adjustments, 10m by default
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// MinAllowed is the lower bound of the resource requests
+	// +optional
+	MinAllowed v1.ResourceList `json:"minAllowed,omitempty"`
+	// MaxAllowed is the upper bound of the resource requests
+	// +optional
+	MaxAllowed v1.ResourceList `json:"maxAllowed,omitempty"`
+	// Headroom is the percentage added to the observed usage to compute the requests, 20 by default
+	// +optional
+	Headroom int `json:"headroom,omitempty"`
+	// Tolerance is the minimal difference in percent between the current and the computed requests
+	// to update a daemon, 10 by default
+	// +optional
+	Tolerance int `json:"tolerance,omitempty"`
+	// BatchSize is the number of daemons updated in one adjustment, 1 by default
+	// +optional
+	BatchSize int `json:"batchSize,omitempty"`
+}
+
+// CSIDriverSpec defines the CSI driver settings applied to the cluster
+type CSIDriverSpec struct {
+	// ReadAffinity defines the read affinity settings for the RBD volumes
+	// +optional
+	ReadAffinity ReadAffinitySpec `json:"readAffinity,omitempty"`
+}
+
+// ReadAffinitySpec defines the read affinity settings for CSI driver.
+type ReadAffinitySpec struct {
+	// Enables read affinity so the RBD volumes read from the OSD closest to the client (read from replica)
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// CrushLocationLabels are the node labels used to build the CRUSH location of the client on each node.
+	// Defaults to the topology labels Rook uses to build the CRUSH map of the OSDs.
+	// +optional
+	CrushLocationLabels []string `json:"crushLocationLabels,omitempty"`
 }
 
 // VersionSpec represents the settings for the Ceph version that Rook is orchestrating.
@@ -166,6 +535,63 @@ type DashboardSpec struct {
 	Port int `json:"port,omitempty"`
 	// Whether SSL should be used
 	SSL bool `json:"ssl,omitempty"`
+	// ExternalURL is the URL of the dashboard for the users, for example the URL of the ingress. It is the base URL
+	// of the single sign-on and the default host of the ingress.
+	// +optional
+	ExternalURL string `json:"externalURL,omitempty"`
+	// SSO configures the single sign-on of the users of the dashboard
+	// +optional
+	SSO *DashboardSSOSpec `json:"sso,omitempty"`
+	// Ingress exposes the dashboard out of the cluster with an ingress created by the operator
+	// +optional
+	Ingress *DashboardIngressSpec `json:"ingress,omitempty"`
+	// ObjectStore is the object store managed in the dashboard, the first object store of the namespace by name if
+	// empty
+	// +optional
+	ObjectStore string `json:"objectStore,omitempty"`
+	// GrafanaURL is the URL of the Grafana of the dashboards of Ceph, embedded in the dashboard
+	// +optional
+	GrafanaURL string `json:"grafanaURL,omitempty"`
+	// PrometheusURL is the URL of the Prometheus scraping the cluster, for the alerts in the dashboard
+	// +optional
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+}
+
+// DashboardSSOSpec is the single sign-on of the users of the dashboard
+type DashboardSSOSpec struct {
+	// SAML2 authenticates the users with a SAML 2.0 identity provider
+	// +optional
+	SAML2 *DashboardSAML2Spec `json:"saml2,omitempty"`
+}
+
+// DashboardSAML2Spec is the SAML 2.0 identity provider of the dashboard
+type DashboardSAML2Spec struct {
+	// IdPMetadata is the URL or the XML of the metadata of the identity provider
+	IdPMetadata string `json:"idpMetadata"`
+	// UsernameAttribute is the attribute of the username in the assertions, the subject by default
+	// +optional
+	UsernameAttribute string `json:"usernameAttribute,omitempty"`
+	// EntityID is the entity of the identity provider when its metadata has several entities
+	// +optional
+	EntityID string `json:"entityID,omitempty"`
+}
+
+// DashboardIngressSpec is the ingress of the dashboard
+type DashboardIngressSpec struct {
+	// Enabled creates the ingress of the dashboard
+	Enabled bool `json:"enabled,omitempty"`
+	// Host is the host name of the ingress, the host of the external URL by default
+	// +optional
+	Host string `json:"host,omitempty"`
+	// ClassName is the class of the ingress controller of the ingress
+	// +optional
+	ClassName string `json:"className,omitempty"`
+	// TLSSecretName is the secret of the TLS certificate of the host, the ingress does not terminate TLS if empty
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// Annotations are the annotations of the ingress, for example the settings of the ingress controller
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // MonitoringSpec represents the settings for Prometheus based Ceph monitoring
@@ -180,6 +606,57 @@ type MonitoringSpec struct {
 
 	// ExternalMgrEndpoints points to an existing Ceph prometheus exporter endpoint
 	ExternalMgrEndpoints []v1.EndpointAddress `json:"externalMgrEndpoints,omitempty"`
+
+	// Exporter configures the ceph-exporter daemons which export the performance counters of the daemons of each node
+	Exporter *CephExporterSpec `json:"exporter,omitempty"`
+
+	// Rules customizes the recommended prometheus rules deployed in the rules namespace
+	Rules *PrometheusRulesSpec `json:"rules,omitempty"`
+}
+
+// PrometheusRulesSpec customizes the recommended prometheus rules deployed by the operator
+type PrometheusRulesSpec struct {
+	// Whether to remove the recommended rules instead of deploying them
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Labels of the PrometheusRule, for example to match the rule selector of the prometheus
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Overrides of the settings of the recommended alerts, matched by the name of the alert
+	Overrides []PrometheusAlertOverride `json:"overrides,omitempty"`
+
+	// Names of the recommended alerts which are not deployed
+	Suppressed []string `json:"suppressed,omitempty"`
+}
+
+// PrometheusAlertOverride overrides the settings of a recommended alert
+type PrometheusAlertOverride struct {
+	// The name of the alert
+	Alert string `json:"alert"`
+
+	// The expression of the alert, the expression of the recommended alert if empty
+	Expr string `json:"expr,omitempty"`
+
+	// The duration the expression must be true before the alert fires
+	For string `json:"for,omitempty"`
+
+	// The labels merged in the labels of the alert
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// The annotations merged in the annotations of the alert
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CephExporterSpec configures the ceph-exporter daemons
+type CephExporterSpec struct {
+	// Whether to deploy a ceph-exporter daemon on each node running ceph daemons. Requires ceph 17.2.6 or newer.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Only the performance counters with a priority greater or equal to this limit are exported
+	PerfCountersPrioLimit int64 `json:"perfCountersPrioLimit,omitempty"`
+
+	// The interval in seconds between the collections of the performance counters
+	StatsPeriodSeconds int64 `json:"statsPeriodSeconds,omitempty"`
 }
 
 type ClusterStatus struct {
@@ -190,6 +667,136 @@ type ClusterStatus struct {
 	CephStatus  *CephStatus     `json:"ceph,omitempty"`
 	CephStorage *CephStorage    `json:"storage,omitempty"`
 	CephVersion *ClusterVersion `json:"version,omitempty"`
+	// AdminKeyConsumers are the pods of the cluster namespace still mounting the admin key
+	AdminKeyConsumers []string `json:"adminKeyConsumers,omitempty"`
+	// KeyRotation is the status of the rotation of the encryption keys of the OSDs
+	KeyRotation *KeyRotationStatus `json:"keyRotation,omitempty"`
+	// CephxKeyRotation is the status of the rotation of the cephx keys
+	CephxKeyRotation *CephxKeyRotationStatus `json:"cephxKeyRotation,omitempty"`
+	// Resources summarizes the phases of the other CRs of the cluster namespace
+	Resources *ResourcesStatus `json:"resources,omitempty"`
+	// Network reports the addresses of the daemons on the multus networks
+	Network *NetworkStatus `json:"network,omitempty"`
+	// Upgrade is the progress of the last upgrade of the daemons to a new ceph image
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+	// CrashPruning is the status of the last pruning of the crash reports
+	CrashPruning *CrashPruningStatus `json:"crashPruning,omitempty"`
+}
+
+// UpgradeChecksSpec represents the settings of the checks of the daemons during an upgrade
+type UpgradeChecksSpec struct {
+	// WaitTimeout is how long the operator waits for a daemon to be ok to continue after its update, such as "20m".
+	// Defaults to 10 minutes for the osds and 150 seconds for the mdss.
+	WaitTimeout string `json:"waitTimeout,omitempty"`
+	// Daemons overrides the checks of the mon, mgr, osd, mds, rgw, rbd-mirror and nfs daemons
+	Daemons map[string]DaemonUpgradeChecksSpec `json:"daemons,omitempty"`
+}
+
+// DaemonUpgradeChecksSpec overrides the upgrade checks of a daemon type
+type DaemonUpgradeChecksSpec struct {
+	// SkipUpgradeChecks overrides skipUpgradeChecks for the daemons of the type
+	SkipUpgradeChecks *bool `json:"skipUpgradeChecks,omitempty"`
+	// ContinueUpgradeAfterChecksEvenIfNotHealthy overrides continueUpgradeAfterChecksEvenIfNotHealthy for the daemons
+	// of the type
+	ContinueUpgradeAfterChecksEvenIfNotHealthy *bool `json:"continueUpgradeAfterChecksEvenIfNotHealthy,omitempty"`
+	// WaitTimeout overrides upgradeChecks.waitTimeout for the daemons of the type
+	WaitTimeout string `json:"waitTimeout,omitempty"`
+}
+
+// UpgradeStatus represents the progress of the upgrade of the daemons to a new ceph image
+type UpgradeStatus struct {
+	// State is the state of the upgrade
+	State UpgradeState `json:"state,omitempty"`
+	// Image is the ceph image the daemons are upgraded to
+	Image string `json:"image,omitempty"`
+	// PreviousImage is the ceph image the cluster ran before the upgrade
+	PreviousImage string `json:"previousImage,omitempty"`
+	// Rollback is whether the upgrade rolls back a cancelled upgrade to its previous image
+	Rollback bool `json:"rollback,omitempty"`
+	// DaemonType is the type of the daemon currently upgrading
+	DaemonType string `json:"daemonType,omitempty"`
+	// DaemonsDone is the number of daemons running the image
+	DaemonsDone int `json:"daemonsDone"`
+	// DaemonsRemaining is the number of daemons still to upgrade
+	DaemonsRemaining int `json:"daemonsRemaining"`
+	// LastError is the last error of the upgrade of a daemon
+	LastError string `json:"lastError,omitempty"`
+	// StartTime is the time the upgrade started
+	StartTime string `json:"startTime,omitempty"`
+	// LastUpdated is the time the progress was last updated
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+// CrashPruningStatus reports the runs of the CronJob pruning the crash reports
+type CrashPruningStatus struct {
+	// LastScheduleTime is the time the last pruning was scheduled
+	LastScheduleTime string `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulTime is the time the last successful pruning completed
+	LastSuccessfulTime string `json:"lastSuccessfulTime,omitempty"`
+	// LastFailed is whether the last completed pruning failed
+	LastFailed bool `json:"lastFailed"`
+}
+
+// UpgradeState is the state of an upgrade
+type UpgradeState string
+
+const (
+	// UpgradeInProgress is the state of the upgrade while the daemons are upgraded
+	UpgradeInProgress UpgradeState = "InProgress"
+	// UpgradeCompleted is the state of the upgrade once all the daemons were upgraded
+	UpgradeCompleted UpgradeState = "Completed"
+	// UpgradeCancelled is the state of an upgrade stopped because the image of the cluster changed
+	UpgradeCancelled UpgradeState = "Cancelled"
+)
+
+// NetworkStatus reports the addresses of the daemons on the multus public and cluster networks
+type NetworkStatus struct {
+	// Daemons are the addresses of the running daemons
+	Daemons []DaemonNetworkStatus `json:"daemons,omitempty"`
+	// Detached are the daemons which are not attached to all the multus networks
+	Detached []string `json:"detached,omitempty"`
+	// LastChecked is the time the addresses were last checked
+	LastChecked string `json:"lastChecked,omitempty"`
+}
+
+// DaemonNetworkStatus is the addresses of a daemon on the multus networks
+type DaemonNetworkStatus struct {
+	// Daemon is the name of the daemon, for example "mon.a"
+	Daemon string `json:"daemon"`
+	// Pod is the name of the pod of the daemon
+	Pod string `json:"pod"`
+	// PublicIPs are the addresses of the daemon on the public network
+	PublicIPs []string `json:"publicIPs,omitempty"`
+	// ClusterIPs are the addresses of the daemon on the cluster network
+	ClusterIPs []string `json:"clusterIPs,omitempty"`
+}
+
+// ResourcesStatus summarizes the phases of the CRs of the cluster namespace
+type ResourcesStatus struct {
+	// Ready is the number of ready CRs out of all the CRs, for example "4/5"
+	Ready string `json:"ready,omitempty"`
+	// NotReady are the CRs which are not ready with their phase, for example "CephObjectStore/my-store: Failure"
+	NotReady []string `json:"notReady,omitempty"`
+	// LastChecked is the time the phases of the CRs were last checked
+	LastChecked string `json:"lastChecked,omitempty"`
+}
+
+// KeyRotationStatus represents the status of the rotation of the encryption keys of the OSDs
+type KeyRotationStatus struct {
+	// LastRotationTime is the time the keys of all the encrypted OSDs were last rotated
+	LastRotationTime string `json:"lastRotationTime,omitempty"`
+	// RotatedOSDs is the number of OSDs whose key was rotated at the last rotation
+	RotatedOSDs int `json:"rotatedOSDs,omitempty"`
+}
+
+// CephxKeyRotationStatus represents the status of the rotation of the cephx keys
+type CephxKeyRotationStatus struct {
+	// LastRotationTime is the time the cephx keys were last rotated
+	LastRotationTime string `json:"lastRotationTime,omitempty"`
+	// KeyGeneration is the key generation of the last rotation
+	KeyGeneration int `json:"keyGeneration,omitempty"`
+	// RotatedKeys is the number of keys rotated at the last rotation
+	RotatedKeys int `json:"rotatedKeys,omitempty"`
 }
 
 type CephStatus struct {
@@ -198,10 +805,98 @@ type CephStatus struct {
 	LastChecked    string                       `json:"lastChecked,omitempty"`
 	LastChanged    string                       `json:"lastChanged,omitempty"`
 	PreviousHealth string                       `json:"previousHealth,omitempty"`
+	Capacity       Capacity                     `json:"capacity,omitempty"`
+	// History is the last transitions of the health, the most recent last
+	History []CephHealthTransition `json:"history,omitempty"`
+	// Balancer is the status of the balancer module of the mgr
+	Balancer *BalancerStatus `json:"balancer,omitempty"`
+	// Progress is the long-running operations of the cluster tracked by the progress mgr module, like the recovery
+	// of the data after an OSD is marked out
+	// +optional
+	Progress []ProgressEventStatus `json:"progress,omitempty"`
+}
+
+// BalancerStatus is the status of the balancer module of the mgr
+type BalancerStatus struct {
+	Active bool   `json:"active"`
+	Mode   string `json:"mode,omitempty"`
+	// LastOptimizeStarted is the time of the last optimization of the balancer
+	LastOptimizeStarted string `json:"lastOptimizeStarted,omitempty"`
+	// OptimizeResult is the result of the last optimization of the balancer
+	OptimizeResult string `json:"optimizeResult,omitempty"`
+}
+
+// ProgressEventStatus is a long-running operation tracked by the progress mgr module
+type ProgressEventStatus struct {
+	ID      string `json:"id"`
+	Message string `json:"message,omitempty"`
+	// Progress is the completion of the operation, for example "42%"
+	Progress string `json:"progress,omitempty"`
+}
+
+// CephHealthTransition is a change of the health of the cluster
+type CephHealthTransition struct {
+	Time           string `json:"time,omitempty"`
+	Health         string `json:"health,omitempty"`
+	PreviousHealth string `json:"previousHealth,omitempty"`
+	// Checks are the names of the health checks reported with the new health, for example "OSD_DOWN"
+	Checks []string `json:"checks,omitempty"`
+}
+
+// Capacity is the raw capacity of the cluster reported by Ceph
+type Capacity struct {
+	BytesTotal     uint64 `json:"bytesTotal,omitempty"`
+	BytesUsed      uint64 `json:"bytesUsed,omitempty"`
+	BytesAvailable uint64 `json:"bytesAvailable,omitempty"`
+	// Usage is the percentage of the used raw capacity, for example "12.5%"
+	Usage       string `json:"usage,omitempty"`
+	LastUpdated string `json:"lastUpdated,omitempty"`
+	// DeviceClasses is the raw capacity of each device class
+	DeviceClasses []DeviceClassCapacity `json:"deviceClasses,omitempty"`
+	// Pools is the usage of each pool
+	Pools []PoolCapacity `json:"pools,omitempty"`
+}
+
+// DeviceClassCapacity is the raw capacity of the OSDs of a device class
+type DeviceClassCapacity struct {
+	Name           string `json:"name"`
+	BytesTotal     uint64 `json:"bytesTotal,omitempty"`
+	BytesUsed      uint64 `json:"bytesUsed,omitempty"`
+	BytesAvailable uint64 `json:"bytesAvailable,omitempty"`
+	Usage          string `json:"usage,omitempty"`
+}
+
+// PoolCapacity is the usage of a pool
+type PoolCapacity struct {
+	Name           string `json:"name"`
+	BytesUsed      uint64 `json:"bytesUsed,omitempty"`
+	BytesAvailable uint64 `json:"bytesAvailable,omitempty"`
+	Objects        uint64 `json:"objects,omitempty"`
 }
 
 type CephStorage struct {
 	DeviceClasses []DeviceClasses `json:"deviceClasses,omitempty"`
+	OSD           *OSDStatus      `json:"osd,omitempty"`
+}
+
+// OSDStatus represents the object stores of the OSDs and the progress of their migration to the store of the spec
+type OSDStatus struct {
+	// StoreType is the number of OSDs by object store
+	StoreType map[string]int `json:"storeType,omitempty"`
+	// MigrationStatus is the progress of the re-provisioning of the OSDs whose store differs from the spec
+	MigrationStatus *OSDMigrationStatus `json:"migrationStatus,omitempty"`
+}
+
+// OSDMigrationStatus represents the progress of the re-provisioning of the OSDs to the store of the spec
+type OSDMigrationStatus struct {
+	// Pending is the number of OSDs which still need to be re-provisioned
+	Pending int `json:"pending,omitempty"`
+	// Migrated is the number of OSDs re-provisioned by the current migration
+	Migrated int `json:"migrated,omitempty"`
+	// FailureDomain is the CRUSH bucket whose OSDs are being re-provisioned
+	FailureDomain string `json:"failureDomain,omitempty"`
+	// Message explains what the migration is waiting for
+	Message string `json:"message,omitempty"`
 }
 
 type DeviceClasses struct {
@@ -239,8 +934,21 @@ const (
 	ConditionFailure     ConditionType = "Failure"
 	ConditionUpgrading   ConditionType = "Upgrading"
 	ConditionDeleting    ConditionType = "Deleting"
+	// ConditionInsufficientCaps is true when the user of an external cluster lacks caps required by the operator
+	ConditionInsufficientCaps ConditionType = "InsufficientCaps"
+	// ConditionDeprecatedFields is true when deprecated fields are set in the spec of the cluster
+	ConditionDeprecatedFields ConditionType = "DeprecatedFields"
+	// ConditionDegraded is true when daemons reported new crashes or mgr modules failed
+	ConditionDegraded ConditionType = "Degraded"
+	// ConditionQuotaNearFull is true when the usage of a quota of a pool is above its warning threshold
+	ConditionQuotaNearFull ConditionType = "QuotaNearFull"
+	// ConditionConfigOverrideConflict is true when options of the rook-config-override config map conflict with the
+	// options generated by the operator
+	ConditionConfigOverrideConflict ConditionType = "ConfigOverrideConflict"
 	// DefaultFailureDomain for PoolSpec
 	DefaultFailureDomain = "host"
+	// DefaultCRUSHRoot for PoolSpec
+	DefaultCRUSHRoot = "default"
 )
 
 type ClusterState string
@@ -258,6 +966,66 @@ type MonSpec struct {
 	Count                int                       `json:"count,omitempty"`
 	AllowMultiplePerNode bool                      `json:"allowMultiplePerNode,omitempty"`
 	VolumeClaimTemplate  *v1.PersistentVolumeClaim `json:"volumeClaimTemplate,omitempty"`
+
+	// Backup is the scheduled export of the mon store and of the critical secrets and configmaps of the cluster,
+	// there is no backup if not set
+	Backup *MonBackupSpec `json:"backup,omitempty"`
+
+	// FailureDomain spreads the mons across the failure domains of a node label, like the zones
+	// +optional
+	FailureDomain *MonFailureDomainSpec `json:"failureDomain,omitempty"`
+}
+
+// MonFailureDomainSpec is the spread of the mons across the failure domains of the nodes
+type MonFailureDomainSpec struct {
+	// TopologyKey is the node label whose values are the failure domains, like "topology.kubernetes.io/zone"
+	TopologyKey string `json:"topologyKey"`
+
+	// Mode is "hard" to refuse placing two mons in the same failure domain, or "soft" to spread the mons when
+	// possible. "soft" by default.
+	// +optional
+	Mode MonSpreadMode `json:"mode,omitempty"`
+}
+
+// MonSpreadMode is how strictly the mons are spread across the failure domains
+type MonSpreadMode string
+
+const (
+	// MonSpreadHard refuses placing two mons in the same failure domain
+	MonSpreadHard MonSpreadMode = "hard"
+	// MonSpreadSoft places the mons in different failure domains when possible
+	MonSpreadSoft MonSpreadMode = "soft"
+)
+
+// MonBackupSpec is the CronJob exporting the mon store to a PVC or to a S3 bucket
+type MonBackupSpec struct {
+	// Schedule is the cron schedule of the backups, daily at 1am by default
+	Schedule string `json:"schedule,omitempty"`
+
+	// PersistentVolumeClaim is the name of the PVC the backups are written to
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+
+	// Keep is the number of backups kept in the PVC, the older backups are removed. 7 by default.
+	Keep uint `json:"keep,omitempty"`
+
+	// S3 is the bucket the backups are uploaded to
+	S3 *MonBackupS3Spec `json:"s3,omitempty"`
+}
+
+// MonBackupS3Spec is the S3 bucket of the mon backups
+type MonBackupS3Spec struct {
+	// Endpoint is the url of the S3 endpoint, such as "https://s3.us-east-1.amazonaws.com"
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the existing bucket the backups are uploaded to
+	Bucket string `json:"bucket"`
+
+	// Region is the region of the signature of the requests, "us-east-1" by default
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret is the name of the secret with the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys, such as
+	// the secret of an object bucket claim
+	CredentialsSecret string `json:"credentialsSecret"`
 }
 
 // MgrSpec represents options to configure a ceph mgr
@@ -274,11 +1042,86 @@ type Module struct {
 // ExternalSpec represents the options supported by an external cluster
 type ExternalSpec struct {
 	Enable bool `json:"enable"`
+	// Discovery imports the connection details of the external cluster from a keyring secret
+	// +optional
+	Discovery ExternalDiscoverySpec `json:"discovery,omitempty"`
+	// RestrictUsers creates least-privilege healthchecker and CSI users when connected as client.admin,
+	// the operator then connects with the healthchecker instead of the admin
+	// +optional
+	RestrictUsers bool `json:"restrictUsers,omitempty"`
+}
+
+// ExternalDiscoverySpec represents the settings to discover the resources of an external cluster
+type ExternalDiscoverySpec struct {
+	// Enabled determines whether the operator discovers the mons, users, pools and filesystems of the external cluster
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// SecretName is the name of the secret with the "monHost", "userID" and "userKey" used to connect to the external cluster
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
 }
 
 // CrashCollectorSpec represents options to configure the crash controller
 type CrashCollectorSpec struct {
 	Disable bool `json:"disable"`
+
+	// DaysToRetain is the number of days the crash reports are kept, the older crash reports are pruned
+	DaysToRetain uint `json:"daysToRetain,omitempty"`
+
+	// MaxCount is the number of crash reports kept, the oldest crash reports beyond it are pruned
+	MaxCount uint `json:"maxCount,omitempty"`
+
+	// MaxSize is the total size of the crash reports kept, such as "100Mi", the oldest crash reports beyond it are pruned
+	MaxSize string `json:"maxSize,omitempty"`
+
+	// Schedule is the cron schedule of the CronJob pruning the crash reports, daily at midnight by default
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// ToolboxSpec represents the rook-ceph-tools deployment managed by the operator
+type ToolboxSpec struct {
+	// Enabled deploys the toolbox, it is updated along with the ceph image of the cluster
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Image is the image of the toolbox, the ceph image of the cluster by default
+	Image string `json:"image,omitempty"`
+}
+
+// LogCollectorSpec represents the collection of the logs of the daemons to files rotated by a sidecar
+type LogCollectorSpec struct {
+	// Enabled makes the daemons log to files in the log dir of the host, along with their logs to stderr
+	Enabled bool `json:"enabled,omitempty"`
+
+	// LogRotationSpec is the rotation of the log files of all the daemons
+	LogRotationSpec `json:",inline"`
+
+	// Daemons overrides the rotation of the log files per daemon type: mon, mgr, osd, mds, rgw or rbd-mirror
+	Daemons map[string]LogRotationSpec `json:"daemons,omitempty"`
+
+	// Shipper is the optional fluent-bit sidecar shipping the log files to an endpoint
+	Shipper *LogShipperSpec `json:"shipper,omitempty"`
+}
+
+// LogRotationSpec represents the rotation of the log files of the daemons
+type LogRotationSpec struct {
+	// Periodicity is how often the log files are rotated: hourly, daily, weekly or monthly
+	Periodicity string `json:"periodicity,omitempty"`
+
+	// MaxLogSize is the size beyond which a log file is rotated before its periodicity, such as "500M"
+	MaxLogSize string `json:"maxLogSize,omitempty"`
+
+	// RotateCount is the number of rotated log files kept
+	RotateCount int `json:"rotateCount,omitempty"`
+}
+
+// LogShipperSpec represents the fluent-bit sidecar shipping the log files of the daemons
+type LogShipperSpec struct {
+	// Image is the fluent-bit image
+	Image string `json:"image,omitempty"`
+
+	// Output is the plugin and the properties of the fluent-bit output the logs are shipped to,
+	// such as {"name": "forward", "host": "fluentd.logging", "port": "24224"}
+	Output map[string]string `json:"output"`
 }
 
 // +genclient
@@ -288,8 +1131,77 @@ type CrashCollectorSpec struct {
 type CephBlockPool struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
-	Spec              PoolSpec `json:"spec"`
-	Status            *Status  `json:"status"`
+	Spec              PoolSpec             `json:"spec"`
+	Status            *CephBlockPoolStatus `json:"status"`
+}
+
+// CephBlockPoolStatus represents the status of a Ceph Storage Pool
+type CephBlockPoolStatus struct {
+	Phase string `json:"phase,omitempty"`
+	// Deletion is the progress of the removal of the data of the pool by the OSDs once the pool is deleted
+	// +optional
+	Deletion *PoolDeletionStatus `json:"deletion,omitempty"`
+	// Usage is the usage, the placement groups and the data protection of the pool, refreshed at the interval of
+	// the pool health check of the cluster
+	// +optional
+	Usage *PoolUsageStatus `json:"usage,omitempty"`
+	// Conditions are the conditions of the pool, like the usage of its quotas above the warning threshold
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Progress is the long-running operations of the pool tracked by the progress mgr module, like the change of
+	// the number of its placement groups
+	// +optional
+	Progress []ProgressEventStatus `json:"progress,omitempty"`
+}
+
+// PoolUsageStatus represents the usage, the placement groups and the effective data protection of a pool
+type PoolUsageStatus struct {
+	// BytesStored is the number of bytes of the data stored in the pool, before replication or erasure coding
+	BytesStored    uint64 `json:"bytesStored,omitempty"`
+	BytesUsed      uint64 `json:"bytesUsed,omitempty"`
+	BytesAvailable uint64 `json:"bytesAvailable,omitempty"`
+	Objects        uint64 `json:"objects,omitempty"`
+	// PGCount is the number of placement groups of the pool
+	PGCount int `json:"pgCount,omitempty"`
+	// PGStates is the number of placement groups in each state, for example "active+clean"
+	PGStates map[string]int `json:"pgStates,omitempty"`
+	// CrushRule is the crush rule of the pool
+	CrushRule string `json:"crushRule,omitempty"`
+	// Replicated is the effective replication of a replicated pool
+	// +optional
+	Replicated *PoolReplicationStatus `json:"replicated,omitempty"`
+	// ErasureCoded is the effective erasure code profile of an erasure coded pool
+	// +optional
+	ErasureCoded *PoolErasureCodeStatus `json:"erasureCoded,omitempty"`
+	LastUpdated  string                 `json:"lastUpdated,omitempty"`
+}
+
+// PoolReplicationStatus is the replication of a pool set in Ceph
+type PoolReplicationStatus struct {
+	Size    uint `json:"size"`
+	MinSize uint `json:"minSize,omitempty"`
+}
+
+// PoolErasureCodeStatus is the erasure code profile of a pool set in Ceph
+type PoolErasureCodeStatus struct {
+	Profile      string `json:"profile"`
+	DataChunks   uint   `json:"dataChunks"`
+	CodingChunks uint   `json:"codingChunks"`
+	Plugin       string `json:"plugin,omitempty"`
+}
+
+// PoolDeletionStatus represents the progress of the removal of the data of a deleted pool
+type PoolDeletionStatus struct {
+	// StartTime is the time the pool was deleted
+	StartTime metav1.Time `json:"startTime,omitempty"`
+	// LastProgressTime is the last time the OSDs released space
+	LastProgressTime metav1.Time `json:"lastProgressTime,omitempty"`
+	// ClusterRawBytesUsed is the raw space used in the cluster when the pool was deleted
+	ClusterRawBytesUsed uint64 `json:"clusterRawBytesUsed,omitempty"`
+	// RawBytesToRemove is the raw space used by the pool when it was deleted
+	RawBytesToRemove uint64 `json:"rawBytesToRemove,omitempty"`
+	// RawBytesRemoved is the raw space released since the pool was deleted
+	RawBytesRemoved uint64 `json:"rawBytesRemoved,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -299,6 +1211,125 @@ type CephBlockPoolList struct {
 	Items           []CephBlockPool `json:"items"`
 }
 
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolRadosNamespace represents a RADOS namespace of a CephBlockPool, isolating the images of a tenant
+// in the pool of several tenants
+type CephBlockPoolRadosNamespace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              RadosNamespaceSpec    `json:"spec"`
+	Status            *RadosNamespaceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolRadosNamespaceList represents a list of RADOS namespaces
+type CephBlockPoolRadosNamespaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephBlockPoolRadosNamespace `json:"items"`
+}
+
+// RadosNamespaceSpec represents the spec of a RADOS namespace
+type RadosNamespaceSpec struct {
+	// BlockPoolName is the name of the CephBlockPool of the namespace, in the namespace of the CR
+	BlockPoolName string `json:"blockPoolName"`
+	// Mirroring is the RBD mirroring of the images of the namespace
+	// +optional
+	Mirroring *RadosNamespaceMirroringSpec `json:"mirroring,omitempty"`
+}
+
+// RadosNamespaceMirroringSpec represents the RBD mirroring of the images of a RADOS namespace
+type RadosNamespaceMirroringSpec struct {
+	// Mode is "image" to mirror the images with mirroring enabled or "pool" to mirror all the journaled images
+	// of the namespace
+	Mode string `json:"mode"`
+}
+
+// RadosNamespaceStatus represents the status of a RADOS namespace
+type RadosNamespaceStatus struct {
+	Phase string `json:"phase,omitempty"`
+	// ClusterID is the clusterID of the namespace in the ceph-csi config, set in the parameters of the storage
+	// classes of the images of the namespace
+	ClusterID string `json:"clusterID,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephDeviceInventory represents the inventory of the devices of a node, maintained by the discover daemon of the
+// node
+type CephDeviceInventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              DeviceInventorySpec    `json:"spec"`
+	Status            *DeviceInventoryStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephDeviceInventoryList represents a list of device inventories
+type CephDeviceInventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephDeviceInventory `json:"items"`
+}
+
+// DeviceInventorySpec represents the spec of a device inventory
+type DeviceInventorySpec struct {
+	// NodeName is the name of the node of the devices
+	NodeName string `json:"nodeName"`
+}
+
+// DeviceInventoryStatus represents the devices found on a node
+type DeviceInventoryStatus struct {
+	Devices []InventoryDevice `json:"devices"`
+}
+
+// InventoryDevice represents a device found on a node
+type InventoryDevice struct {
+	// Name is the name of the device, like sda
+	Name string `json:"name"`
+	// Path is the path of the device, like /dev/sda
+	Path string `json:"path"`
+	// DevLinks are the persistent paths of the device
+	// +optional
+	DevLinks []string `json:"devLinks,omitempty"`
+	// Type is the type of the device: disk, lvm, crypt...
+	Type string `json:"type"`
+	// WWN is the world wide name of the device
+	// +optional
+	WWN string `json:"wwn,omitempty"`
+	// Serial is the serial number of the device
+	// +optional
+	Serial string `json:"serial,omitempty"`
+	// Vendor is the vendor of the device
+	// +optional
+	Vendor string `json:"vendor,omitempty"`
+	// Model is the model of the device
+	// +optional
+	Model string `json:"model,omitempty"`
+	// Size is the capacity of the device in bytes
+	Size uint64 `json:"size"`
+	// Rotational is whether the device is a hdd
+	Rotational bool `json:"rotational"`
+	// Empty is whether the device has no partition and no filesystem
+	Empty bool `json:"empty"`
+	// InUseByOSD is whether the device is used by an OSD, as reported by ceph-volume
+	InUseByOSD bool `json:"inUseByOSD"`
+	// OSDIDs are the ids of the OSDs on the logical volumes of the device
+	// +optional
+	OSDIDs []int `json:"osdIDs,omitempty"`
+	// Health is the SMART health of the device: Good, Failing or Unknown, set when the discover daemons collect the
+	// SMART data
+	// +optional
+	Health string `json:"health,omitempty"`
+}
+
 // PoolSpec represents the spec of ceph pool
 type PoolSpec struct {
 	// The failure domain: osd/host/(region or zone if available) - technically also any type in the crush map
@@ -313,6 +1344,20 @@ type PoolSpec struct {
 	// The inline compression mode in Bluestore OSD to set to (options are: none, passive, aggressive, force)
 	CompressionMode string `json:"compressionMode"`
 
+	// The inline compression algorithm of the pool (options are: snappy, zlib, zstd, lz4), the default of the OSDs if
+	// empty
+	// +optional
+	CompressionAlgorithm string `json:"compressionAlgorithm,omitempty"`
+
+	// The mode of the pg autoscaler for the pool (options are: on, off, warn)
+	// +optional
+	PgAutoscaleMode string `json:"pgAutoscaleMode,omitempty"`
+
+	// TargetSizeRatio gives a hint to the pg autoscaler of the expected consumption of the total cluster capacity by
+	// the pool, replicated or erasure coded. It takes precedence over the target size ratio of the replication settings.
+	// +optional
+	TargetSizeRatio float64 `json:"targetSizeRatio,omitempty"`
+
 	// The replication settings
 	Replicated ReplicatedSpec `json:"replicated"`
 
@@ -321,6 +1366,46 @@ type PoolSpec struct {
 
 	// Parameters is a list of properties to enable on a given pool
 	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Quotas are the maximum number of bytes and objects of the pool
+	// +optional
+	Quotas QuotaSpec `json:"quotas,omitempty"`
+
+	// SnapshotSchedules are the schedules of the mirror snapshots of the RBD images of a block pool with snapshot-based
+	// mirroring
+	// +optional
+	SnapshotSchedules []RBDScheduleSpec `json:"snapshotSchedules,omitempty"`
+
+	// TrashPurgeSchedules are the schedules of the removal of the RBD images of the trash of a block pool whose
+	// deferment ended
+	// +optional
+	TrashPurgeSchedules []RBDScheduleSpec `json:"trashPurgeSchedules,omitempty"`
+}
+
+// RBDScheduleSpec represents a schedule of the rbd_support mgr module
+type RBDScheduleSpec struct {
+	// Interval of the schedule, in minutes, hours or days with the m, h or d suffix, like 12h
+	Interval string `json:"interval"`
+
+	// StartTime of the schedule in the ISO 8601 format, like 14:00:00-05:00
+	// +optional
+	StartTime string `json:"startTime,omitempty"`
+}
+
+// QuotaSpec represents the quotas of a pool, the quotas not set are not changed
+type QuotaSpec struct {
+	// MaxBytes is the maximum number of bytes stored in the pool, 0 removes the quota
+	// +optional
+	MaxBytes *uint64 `json:"maxBytes,omitempty"`
+
+	// MaxObjects is the maximum number of objects in the pool, 0 removes the quota
+	// +optional
+	MaxObjects *uint64 `json:"maxObjects,omitempty"`
+
+	// WarningThresholdPercent is the usage of a quota in percent from which the pool reports that it is near full,
+	// 80 by default
+	// +optional
+	WarningThresholdPercent *int `json:"warningThresholdPercent,omitempty"`
 }
 
 type Status struct {
@@ -337,6 +1422,20 @@ type ReplicatedSpec struct {
 
 	// RequireSafeReplicaSize if false allows you to set replica 1
 	RequireSafeReplicaSize bool `json:"requireSafeReplicaSize"`
+
+	// HybridStorage places the primary copy of the objects on the OSDs of a device class and the other copies on the
+	// OSDs of another device class
+	// +optional
+	HybridStorage *HybridStorageSpec `json:"hybridStorage,omitempty"`
+}
+
+// HybridStorageSpec represents the device classes of a hybrid storage pool
+type HybridStorageSpec struct {
+	// PrimaryDeviceClass is the device class of the OSDs of the primary copy of the objects, read by the clients
+	PrimaryDeviceClass string `json:"primaryDeviceClass"`
+
+	// SecondaryDeviceClass is the device class of the OSDs of the other copies of the objects
+	SecondaryDeviceClass string `json:"secondaryDeviceClass"`
 }
 
 // ErasureCodeSpec represents the spec for erasure code in a pool
@@ -349,6 +1448,18 @@ type ErasureCodedSpec struct {
 
 	// The algorithm for erasure coding
 	Algorithm string `json:"algorithm"`
+
+	// The failure domain of the erasure code profile, the failure domain of the pool by default
+	// +optional
+	FailureDomain string `json:"failureDomain,omitempty"`
+
+	// The root of the crush hierarchy of the erasure code profile, the crush root of the pool by default
+	// +optional
+	CrushRoot string `json:"crushRoot,omitempty"`
+
+	// The device class of the OSDs of the erasure code profile, the device class of the pool by default
+	// +optional
+	DeviceClass string `json:"deviceClass,omitempty"`
 }
 
 // +genclient
@@ -358,8 +1469,54 @@ type ErasureCodedSpec struct {
 type CephFilesystem struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
-	Spec              FilesystemSpec `json:"spec"`
-	Status            *Status        `json:"status"`
+	Spec              FilesystemSpec        `json:"spec"`
+	Status            *CephFilesystemStatus `json:"status"`
+}
+
+// CephFilesystemStatus represents the status of a CephFilesystem
+type CephFilesystemStatus struct {
+	Phase string `json:"phase,omitempty"`
+	// Mirroring is the status of the snapshot mirroring of the filesystem, refreshed at the interval of the
+	// filesystem mirror health check of the cluster
+	// +optional
+	Mirroring *FilesystemMirroringStatus `json:"mirroring,omitempty"`
+}
+
+// FilesystemMirroringStatus is the status of the snapshot mirroring of a filesystem
+type FilesystemMirroringStatus struct {
+	// Peers is the sync status of the peers of the filesystem reported by each cephfs-mirror daemon
+	Peers []FilesystemMirrorPeerStatus `json:"peers,omitempty"`
+	// Directories is the status of the mirrored directories of the spec
+	Directories []FilesystemMirrorDirectoryStatus `json:"directories,omitempty"`
+	LastChecked string                            `json:"lastChecked,omitempty"`
+	// Details are the errors of the last check, such as no cephfs-mirror daemon mirroring the filesystem
+	Details string `json:"details,omitempty"`
+}
+
+// FilesystemMirrorPeerStatus is the sync status of a peer of a filesystem reported by a cephfs-mirror daemon
+type FilesystemMirrorPeerStatus struct {
+	DaemonID int    `json:"daemonID"`
+	UUID     string `json:"uuid"`
+	// Remote is the remote filesystem of the peer, as "<client name>@<cluster name>:<filesystem name>"
+	Remote string `json:"remote,omitempty"`
+	// FailureCount is the number of directories failing to sync to the peer
+	FailureCount int `json:"failureCount"`
+	// RecoveryCount is the number of directories recovering from a failure to sync to the peer
+	RecoveryCount int `json:"recoveryCount"`
+}
+
+// FilesystemMirrorDirectoryStatus is the status of a mirrored directory of a filesystem
+type FilesystemMirrorDirectoryStatus struct {
+	Path string `json:"path"`
+	// State is the state of the mapping of the directory to a cephfs-mirror daemon, for example "mapped"
+	State string `json:"state,omitempty"`
+	// InstanceID is the id of the cephfs-mirror daemon syncing the snapshots of the directory
+	InstanceID string `json:"instanceID,omitempty"`
+	// Schedules are the snapshot schedules of the directory, for example "1h"
+	Schedules []string `json:"schedules,omitempty"`
+	// LastScheduledSnapshot is the time of the last snapshot taken by the schedules of the directory. The snapshot
+	// may not be synced to the peers yet.
+	LastScheduledSnapshot string `json:"lastScheduledSnapshot,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -383,6 +1540,20 @@ type FilesystemSpec struct {
 
 	// The mds pod info
 	MetadataServer MetadataServerSpec `json:"metadataServer"`
+
+	// Mirroring is the snapshot mirroring of the filesystem to its peers
+	// +optional
+	Mirroring *FilesystemMirroringSpec `json:"mirroring,omitempty"`
+}
+
+// FilesystemMirroringSpec represents the snapshot mirroring of a filesystem, synced by cephfs-mirror daemons
+type FilesystemMirroringSpec struct {
+	// Enabled enables the snapshot mirroring of the filesystem, the mirroring is disabled when false
+	Enabled bool `json:"enabled"`
+	// Directories are the absolute paths of the directories whose snapshots are mirrored. The directories removed
+	// from the list are still mirrored.
+	// +optional
+	Directories []string `json:"directories,omitempty"`
 }
 
 type MetadataServerSpec struct {
@@ -399,6 +1570,9 @@ type MetadataServerSpec struct {
 	// The annotations-related configuration to add/set on each Pod related object.
 	Annotations rookv1.Annotations `json:"annotations,omitempty"`
 
+	// The labels-related configuration to add/set on each Pod related object.
+	Labels rookv1.Labels `json:"labels,omitempty"`
+
 	// The resource requirements for the rgw pods
 	Resources v1.ResourceRequirements `json:"resources"`
 
@@ -444,11 +1618,207 @@ type ObjectStoreSpec struct {
 
 	// The rgw Bucket healthchecks and liveness probe
 	HealthCheck BucketHealthCheckSpec `json:"healthCheck"`
+
+	// Security represents the server-side encryption settings of the rgw
+	// +optional
+	Security *ObjectStoreSecuritySpec `json:"security,omitempty"`
+
+	// Auth represents the authentication of the requests of the rgw with external services
+	// +optional
+	Auth AuthSpec `json:"auth,omitempty"`
+
+	// Protocols are the settings of the S3 and Swift APIs of the rgw
+	// +optional
+	Protocols ProtocolSpec `json:"protocols,omitempty"`
+
+	// PlacementTargets are the placement targets and storage classes of the buckets in addition to the default
+	// placement target of the data pool
+	// +optional
+	PlacementTargets []PlacementTargetSpec `json:"placementTargets,omitempty"`
+
+	// UsageMetrics is the periodic collection of the usage of the buckets of the store
+	// +optional
+	UsageMetrics *BucketUsageMetricsSpec `json:"usageMetrics,omitempty"`
+}
+
+// BucketUsageMetricsSpec represents the periodic collection of the size and the number of objects of the buckets of
+// an object store, exported as metrics of the operator or written in a config map
+type BucketUsageMetricsSpec struct {
+	// Enabled enables the collection of the usage of the buckets
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval is the interval of the collection, 5m by default
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Output is where the usage is exported, "prometheus" for the metrics of the operator (the default) or
+	// "configmap" for the config map of the usage of the store
+	// +optional
+	Output string `json:"output,omitempty"`
+	// IncludeBuckets are the patterns of the names of the collected buckets, all the buckets by default, for example
+	// "team-a-*"
+	// +optional
+	IncludeBuckets []string `json:"includeBuckets,omitempty"`
+	// ExcludeBuckets are the patterns of the names of the buckets not collected, even if they match an included pattern
+	// +optional
+	ExcludeBuckets []string `json:"excludeBuckets,omitempty"`
+}
+
+// PlacementTargetSpec represents a placement target of the zone of the object store, with its own pools
+type PlacementTargetSpec struct {
+	// Name of the placement target. The storage classes of the "default-placement" target are added to the default
+	// placement target of the data pool of the store.
+	Name string `json:"name"`
+
+	// DataPool is the pool of the objects of the STANDARD storage class of the placement target. The index pool of the
+	// placement target has the settings of the metadata pool of the store.
+	// +optional
+	DataPool PoolSpec `json:"dataPool,omitempty"`
+
+	// StorageClasses are the additional storage classes of the objects of the placement target
+	// +optional
+	StorageClasses []PlacementStorageClassSpec `json:"storageClasses,omitempty"`
+}
+
+// PlacementStorageClassSpec represents a storage class of the objects of a placement target, like a cold tier of an
+// erasure coded pool
+type PlacementStorageClassSpec struct {
+	// Name of the storage class in upper case, like "COLD"
+	Name string `json:"name"`
+
+	// DataPool is the pool of the objects of the storage class
+	DataPool PoolSpec `json:"dataPool"`
+}
+
+// AuthSpec represents the external authentication services of the rgw
+type AuthSpec struct {
+	// Keystone authenticates the requests with the tokens of OpenStack Keystone
+	// +optional
+	Keystone *KeystoneSpec `json:"keystone,omitempty"`
+
+	// STS enables the Security Token Service of the rgw, so that the users and the web identities can assume roles
+	// +optional
+	STS *STSSpec `json:"sts,omitempty"`
+}
+
+// STSSpec represents the Security Token Service of the rgw
+type STSSpec struct {
+	// KeySecretName is the name of the secret holding the key encrypting the session tokens in its "key" key, 16
+	// characters long. By default the operator generates the key in the rook-ceph-rgw-<store>-sts-key secret.
+	// +optional
+	KeySecretName string `json:"keySecretName,omitempty"`
+
+	// Roles are the roles created in the rgw that the users and the web identities are allowed to assume
+	// +optional
+	Roles []STSRoleSpec `json:"roles,omitempty"`
+
+	// OIDCProvider is the OpenID Connect provider registered in the rgw to assume the roles with web identities
+	// +optional
+	OIDCProvider *OIDCProviderSpec `json:"oidcProvider,omitempty"`
+}
+
+// STSRoleSpec represents a role of the rgw assumed with the STS
+type STSRoleSpec struct {
+	// Name is the name of the role
+	Name string `json:"name"`
+
+	// Path is the path of the role, "/" by default
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// AssumeRolePolicy is the JSON trust policy document of the principals allowed to assume the role
+	AssumeRolePolicy string `json:"assumeRolePolicy"`
+
+	// Policies are the JSON permission policy documents of the role, by policy name
+	// +optional
+	Policies map[string]string `json:"policies,omitempty"`
+}
+
+// OIDCProviderSpec represents an OpenID Connect provider of the web identities assuming the roles of the rgw
+type OIDCProviderSpec struct {
+	// URL is the url of the issuer of the tokens of the provider
+	URL string `json:"url"`
+
+	// ClientIDs are the client IDs of the applications in the audience of the tokens
+	ClientIDs []string `json:"clientIDs"`
+
+	// Thumbprints are the SHA-1 thumbprints of the certificates of the provider
+	Thumbprints []string `json:"thumbprints"`
+}
+
+// KeystoneSpec represents the OpenStack Keystone service authenticating the requests of the rgw
+type KeystoneSpec struct {
+	// URL is the url of the Keystone API
+	URL string `json:"url"`
+
+	// ServiceUserSecretName is the name of the secret holding the credentials of the service user of the rgw in
+	// Keystone, with the OS_USERNAME, OS_PASSWORD, OS_PROJECT_NAME and OS_USER_DOMAIN_NAME keys
+	ServiceUserSecretName string `json:"serviceUserSecretName"`
+
+	// AcceptedRoles are the Keystone roles of the users allowed to access the store
+	AcceptedRoles []string `json:"acceptedRoles"`
+
+	// ImplicitTenants creates a tenant for each Keystone project, "true", "false", "swift" or "s3"
+	// +optional
+	ImplicitTenants string `json:"implicitTenants,omitempty"`
+
+	// TokenCacheSize is the max number of Keystone tokens cached by the rgw
+	// +optional
+	TokenCacheSize *int `json:"tokenCacheSize,omitempty"`
+}
+
+// ProtocolSpec represents the settings of the APIs of the rgw
+type ProtocolSpec struct {
+	// S3 are the settings of the S3 API
+	// +optional
+	S3 *S3Spec `json:"s3,omitempty"`
+
+	// Swift are the settings of the Swift API
+	// +optional
+	Swift *SwiftSpec `json:"swift,omitempty"`
+}
+
+// S3Spec represents the settings of the S3 API of the rgw
+type S3Spec struct {
+	// AuthUseKeystone authenticates the S3 requests with the EC2 credentials of Keystone
+	// +optional
+	AuthUseKeystone *bool `json:"authUseKeystone,omitempty"`
+}
+
+// SwiftSpec represents the settings of the Swift API of the rgw
+type SwiftSpec struct {
+	// AccountInURL puts the account of the requests in the urls of the Swift API, as Keystone expects it
+	// +optional
+	AccountInURL *bool `json:"accountInUrl,omitempty"`
+
+	// URLPrefix is the prefix of the urls of the Swift API, "swift" by default
+	// +optional
+	URLPrefix *string `json:"urlPrefix,omitempty"`
+
+	// VersioningEnabled enables the object versioning of the Swift API
+	// +optional
+	VersioningEnabled *bool `json:"versioningEnabled,omitempty"`
+}
+
+// ObjectStoreSecuritySpec is the server-side encryption configuration of the rgw
+type ObjectStoreSecuritySpec struct {
+	// KeyManagementService is the vault backend serving the keys of the SSE-KMS requests
+	// +optional
+	KeyManagementService KeyManagementServiceSpec `json:"kms,omitempty"`
+	// ServerSideEncryptionS3 is the vault backend managing the keys of the SSE-S3 requests
+	// +optional
+	ServerSideEncryptionS3 KeyManagementServiceSpec `json:"s3,omitempty"`
 }
 
 type BucketHealthCheckSpec struct {
-	Bucket        HealthCheckSpec   `json:"rgw,omitempty"`
+	Bucket HealthCheckSpec `json:"rgw,omitempty"`
+	// DataPath is the check of the data path of the object store with a synthetic transaction, a small object put,
+	// read and deleted in the health check bucket. Only the endpoint of the object store is probed when it is
+	// disabled. Its interval is the interval of the rgw check by default.
+	DataPath      HealthCheckSpec   `json:"dataPath,omitempty"`
 	LivenessProbe *rookv1.ProbeSpec `json:"livenessProbe,omitempty"`
+	// StartupProbe is the startup probe of the rgw daemons, which holds their liveness probe until it succeeds
+	StartupProbe *rookv1.ProbeSpec `json:"startupProbe,omitempty"`
+	// ReadinessProbe is the readiness probe of the rgw daemons
+	ReadinessProbe *rookv1.ProbeSpec `json:"readinessProbe,omitempty"`
 }
 
 type HealthCheckSpec struct {
@@ -457,6 +1827,31 @@ type HealthCheckSpec struct {
 	Timeout  string `json:"timeout,omitempty"`
 }
 
+// OSDHealthCheckSpec is the check of the status of the osds
+type OSDHealthCheckSpec struct {
+	HealthCheckSpec `json:",inline"`
+	// AutoOut marks out the osds down for longer than a timeout instead of the mons
+	AutoOut OSDAutoOutSpec `json:"autoOut,omitempty"`
+}
+
+// OSDAutoOutSpec configures the operator marking out the osds which stay down, so that their data is recovered on the
+// other osds. The mon_osd_down_out_interval of the mons is disabled while it is enabled.
+type OSDAutoOutSpec struct {
+	// Enabled lets the operator mark out the osds instead of the mons
+	Enabled bool `json:"enabled,omitempty"`
+	// DownTimeout is how long an osd stays down before it is marked out, such as "30m". The default is 10m, like
+	// the default mon_osd_down_out_interval.
+	DownTimeout string `json:"downTimeout,omitempty"`
+}
+
+// CrashHealthCheckSpec is the check of the new crash reports of the daemons and of the failed mgr modules
+type CrashHealthCheckSpec struct {
+	HealthCheckSpec `json:",inline"`
+	// ArchiveAfter is the age after which the operator archives the new crash reports, such as "168h". The crash
+	// reports are not archived by the operator when it is not set.
+	ArchiveAfter string `json:"archiveAfter,omitempty"`
+}
+
 type GatewaySpec struct {
 	// The port the rgw service will be listening on (http)
 	Port int32 `json:"port"`
@@ -479,6 +1874,9 @@ type GatewaySpec struct {
 	// The annotations-related configuration to add/set on each Pod related object.
 	Annotations rookv1.Annotations `json:"annotations,omitempty"`
 
+	// The labels-related configuration to add/set on each Pod related object.
+	Labels rookv1.Labels `json:"labels,omitempty"`
+
 	// The resource requirements for the rgw pods
 	Resources v1.ResourceRequirements `json:"resources"`
 
@@ -506,6 +1904,18 @@ type BucketStatus struct {
 	Details     string        `json:"details,omitempty"`
 	LastChecked string        `json:"lastChecked,omitempty"`
 	LastChanged string        `json:"lastChanged,omitempty"`
+	// EndpointLatency is the latency of the last probe of the endpoint of the object store, for example "2.4ms"
+	EndpointLatency string `json:"endpointLatency,omitempty"`
+	// DataPath is the latency of the last successful synthetic transaction of the data path check
+	DataPath *BucketDataPathStatus `json:"dataPath,omitempty"`
+}
+
+// BucketDataPathStatus is the latency of the synthetic transaction of the data path check of an object store
+type BucketDataPathStatus struct {
+	LastChecked   string `json:"lastChecked,omitempty"`
+	PutLatency    string `json:"putLatency,omitempty"`
+	GetLatency    string `json:"getLatency,omitempty"`
+	DeleteLatency string `json:"deleteLatency,omitempty"`
 }
 
 // +genclient
@@ -522,6 +1932,23 @@ type CephObjectStoreUser struct {
 type ObjectStoreUserStatus struct {
 	Phase string            `json:"phase,omitempty"`
 	Info  map[string]string `json:"info"`
+
+	// ObservedKeyGeneration is the key generation of the spec the keys of the user were rotated for
+	// +optional
+	ObservedKeyGeneration int64 `json:"observedKeyGeneration,omitempty"`
+
+	// PreviousKeys are the keys replaced by a rotation, which remain valid until their expiration
+	// +optional
+	PreviousKeys []ObjectStoreUserKey `json:"previousKeys,omitempty"`
+}
+
+// ObjectStoreUserKey represents an S3 key of a user replaced by a rotation
+type ObjectStoreUserKey struct {
+	// AccessKey is the access key of the S3 key
+	AccessKey string `json:"accessKey"`
+
+	// ExpirationTime is the time the key is removed from the user
+	ExpirationTime metav1.Time `json:"expirationTime"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -538,6 +1965,43 @@ type ObjectStoreUserSpec struct {
 	Store string `json:"store,omitempty"`
 	//The display name for the ceph users
 	DisplayName string `json:"displayName,omitempty"`
+
+	// KeyGeneration rotates the S3 keys of the user when it is incremented
+	// +optional
+	KeyGeneration int64 `json:"keyGeneration,omitempty"`
+
+	// PreviousKeyGracePeriodSeconds is the period the previous key remains valid after a rotation. The previous key
+	// is removed as soon as the secret has the new key by default.
+	// +optional
+	PreviousKeyGracePeriodSeconds int64 `json:"previousKeyGracePeriodSeconds,omitempty"`
+
+	// Tenant is the tenant of the user, the user ID is then "<tenant>$<name>" and the buckets of the user are in the
+	// namespace of the tenant. The tenant cannot be changed once the user is created.
+	// +optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// SubUsers are the sub-users of the user, each with an S3 key in its own secret
+	// +optional
+	SubUsers []ObjectStoreSubUserSpec `json:"subUsers,omitempty"`
+
+	// KeyCount is the number of S3 keys of the user, each in its own secret, 1 by default
+	// +optional
+	KeyCount int `json:"keyCount,omitempty"`
+
+	// SecretFormats are the formats of the keys generated in the secrets of the user in addition to the raw keys:
+	// "aws-credentials", "rclone" or "netrc"
+	// +optional
+	SecretFormats []string `json:"secretFormats,omitempty"`
+}
+
+// ObjectStoreSubUserSpec represents a sub-user of an object store user
+type ObjectStoreSubUserSpec struct {
+	// Name of the sub-user, the ID of the sub-user is "<user ID>:<name>"
+	Name string `json:"name"`
+
+	// Access of the sub-user to the buckets of the user: "read", "write", "readwrite" or "full" (the default)
+	// +optional
+	Access string `json:"access,omitempty"`
 }
 
 // +genclient
@@ -566,6 +2030,20 @@ type ObjectRealmSpec struct {
 
 type PullSpec struct {
 	Endpoint string `json:"endpoint"`
+
+	// External pulls the realm from the master zone of an external cluster connected to Rook by a CephCluster.
+	// The endpoint of the master zone and the keys of the system user of the realm are read from the external cluster.
+	// +optional
+	External *ExternalRealmSpec `json:"external,omitempty"`
+}
+
+// ExternalRealmSpec represents the external cluster hosting the master zone of a realm
+type ExternalRealmSpec struct {
+	// ClusterNamespace is the namespace of the CephCluster connected to the external cluster
+	ClusterNamespace string `json:"clusterNamespace"`
+	// SystemUserID is the uid of the system user of the realm in the external cluster. Defaults to "<realm>-system-user".
+	// +optional
+	SystemUserID string `json:"systemUserID,omitempty"`
 }
 
 // +genclient
@@ -591,6 +2069,87 @@ type CephObjectZoneGroupList struct {
 type ObjectZoneGroupSpec struct {
 	//The display name for the ceph users
 	Realm string `json:"realm"`
+
+	// SyncPolicy replicates only selected buckets between the zones of the zone group instead of full-zone sync
+	// +optional
+	SyncPolicy *ObjectSyncPolicySpec `json:"syncPolicy,omitempty"`
+}
+
+// ObjectSyncPolicySpec represents the sync policy groups of a zone group
+type ObjectSyncPolicySpec struct {
+	// Groups are the sync policy groups. Groups in the zone group that are not listed are removed.
+	Groups []ObjectSyncGroupSpec `json:"groups,omitempty"`
+}
+
+// ObjectSyncGroupSpec represents a sync policy group with its data flows and pipes
+type ObjectSyncGroupSpec struct {
+	// ID is the unique id of the group
+	ID string `json:"id"`
+	// Status is the status of the group: "enabled", "allowed" or "forbidden"
+	Status ObjectSyncGroupStatus `json:"status"`
+	// Flows define which zones data may flow between
+	// +optional
+	Flows []ObjectSyncFlowSpec `json:"flows,omitempty"`
+	// Pipes define which buckets are synced between zones
+	// +optional
+	Pipes []ObjectSyncPipeSpec `json:"pipes,omitempty"`
+}
+
+// ObjectSyncGroupStatus is the status of a sync policy group
+type ObjectSyncGroupStatus string
+
+const (
+	// ObjectSyncGroupEnabled means the sync is allowed and enabled
+	ObjectSyncGroupEnabled ObjectSyncGroupStatus = "enabled"
+	// ObjectSyncGroupAllowed means the sync is allowed, but must be enabled at a lower level (e.g. bucket)
+	ObjectSyncGroupAllowed ObjectSyncGroupStatus = "allowed"
+	// ObjectSyncGroupForbidden means the sync is not allowed
+	ObjectSyncGroupForbidden ObjectSyncGroupStatus = "forbidden"
+)
+
+// ObjectSyncFlowType is the type of a sync policy data flow
+type ObjectSyncFlowType string
+
+const (
+	// ObjectSyncFlowSymmetrical means data flows in both directions between all the zones of the flow
+	ObjectSyncFlowSymmetrical ObjectSyncFlowType = "symmetrical"
+	// ObjectSyncFlowDirectional means data flows only from the source zone to the destination zone
+	ObjectSyncFlowDirectional ObjectSyncFlowType = "directional"
+)
+
+// ObjectSyncFlowSpec represents a data flow of a sync policy group
+type ObjectSyncFlowSpec struct {
+	// ID is the unique id of the flow in the group
+	ID string `json:"id"`
+	// Type is the type of the flow: "symmetrical" or "directional"
+	Type ObjectSyncFlowType `json:"type"`
+	// Zones are the zones of a symmetrical flow
+	// +optional
+	Zones []string `json:"zones,omitempty"`
+	// SourceZone is the source zone of a directional flow
+	// +optional
+	SourceZone string `json:"sourceZone,omitempty"`
+	// DestZone is the destination zone of a directional flow
+	// +optional
+	DestZone string `json:"destZone,omitempty"`
+}
+
+// ObjectSyncPipeSpec represents a pipe of a sync policy group
+type ObjectSyncPipeSpec struct {
+	// ID is the unique id of the pipe in the group
+	ID string `json:"id"`
+	// SourceZones are the source zones of the pipe, "*" for all the zones. Defaults to all the zones.
+	// +optional
+	SourceZones []string `json:"sourceZones,omitempty"`
+	// SourceBucket is the source bucket of the pipe, "*" for all the buckets. Defaults to all the buckets.
+	// +optional
+	SourceBucket string `json:"sourceBucket,omitempty"`
+	// DestZones are the destination zones of the pipe, "*" for all the zones. Defaults to all the zones.
+	// +optional
+	DestZones []string `json:"destZones,omitempty"`
+	// DestBucket is the destination bucket of the pipe, "*" for all the buckets. Defaults to all the buckets.
+	// +optional
+	DestBucket string `json:"destBucket,omitempty"`
 }
 
 // +genclient
@@ -648,6 +2207,50 @@ type NFSGaneshaSpec struct {
 	RADOS GaneshaRADOSSpec `json:"rados"`
 
 	Server GaneshaServerSpec `json:"server"`
+
+	// Exports are the CephFS subvolume groups and the object store buckets exported by the ganesha servers
+	Exports []NFSExportSpec `json:"exports,omitempty"`
+}
+
+// NFSExportSpec represents the export of a CephFS subvolume group or of the buckets of an object store user under a
+// pseudo root
+type NFSExportSpec struct {
+	// Name of the export, unique in the CephNFS
+	Name string `json:"name"`
+
+	// Filesystem is the name of the CephFS the subvolume group belongs to
+	// +optional
+	Filesystem string `json:"filesystem,omitempty"`
+
+	// SubvolumeGroup is the subvolume group to export, it is created if it does not exist
+	// +optional
+	SubvolumeGroup string `json:"subvolumeGroup,omitempty"`
+
+	// ObjectStore exports the buckets of an object store user with the RGW FSAL instead of a subvolume group
+	// +optional
+	ObjectStore *NFSObjectStoreExportSpec `json:"objectStore,omitempty"`
+
+	// PseudoPath is the root of the export in the NFSv4 pseudo filesystem, e.g. /tenant-a
+	PseudoPath string `json:"pseudoPath"`
+
+	// Squash is the squash policy of the export: none, root, all or rootid. Defaults to none.
+	Squash string `json:"squash,omitempty"`
+
+	// AccessType is the access of the clients to the export: RW or RO. Defaults to RW.
+	AccessType string `json:"accessType,omitempty"`
+}
+
+// NFSObjectStoreExportSpec represents the export of the buckets of an object store user
+type NFSObjectStoreExportSpec struct {
+	// Store is the name of the CephObjectStore, in the namespace of the CephNFS
+	Store string `json:"store"`
+
+	// User is the name of the CephObjectStoreUser whose buckets are exported with its keys
+	User string `json:"user"`
+
+	// Bucket is the bucket to export, all the buckets of the user by default
+	// +optional
+	Bucket string `json:"bucket,omitempty"`
 }
 
 type GaneshaRADOSSpec struct {
@@ -668,6 +2271,9 @@ type GaneshaServerSpec struct {
 	// The annotations-related configuration to add/set on each Pod related object.
 	Annotations rookv1.Annotations `json:"annotations,omitempty"`
 
+	// The labels-related configuration to add/set on each Pod related object.
+	Labels rookv1.Labels `json:"labels,omitempty"`
+
 	// Resources set resource requests and limits
 	Resources v1.ResourceRequirements `json:"resources,omitempty"`
 
@@ -680,7 +2286,102 @@ type NetworkSpec struct {
 	rookv1.NetworkSpec `json:",inline"`
 
 	// HostNetwork to enable host network
+	// Deprecated: use the "host" provider instead
 	HostNetwork bool `json:"hostNetwork"`
+
+	// Connections are the settings of the connections between the daemons and with the clients
+	// +optional
+	Connections *ConnectionsSpec `json:"connections,omitempty"`
+
+	// IPFamily is the IP family of the addresses of the daemons, "IPv4" or "IPv6". The default is IPv4.
+	// +optional
+	IPFamily IPFamilyType `json:"ipFamily,omitempty"`
+
+	// DualStack binds the daemons to both their IPv4 and IPv6 addresses, the IPFamily is then the family of the
+	// addresses the daemons advertise
+	// +optional
+	DualStack bool `json:"dualStack,omitempty"`
+
+	// HostPorts are the ports of the daemons on the host network, so that several clusters can run on the same nodes
+	// +optional
+	HostPorts *HostPortsSpec `json:"hostPorts,omitempty"`
+
+	// NetworkPolicies are the network policies created by the operator to allow the traffic of the daemons in
+	// namespaces denying it by default
+	// +optional
+	NetworkPolicies *NetworkPoliciesSpec `json:"networkPolicies,omitempty"`
+}
+
+// NetworkPoliciesSpec represents the network policies allowing the traffic between the daemons, the operator and the
+// clients of the cluster
+type NetworkPoliciesSpec struct {
+	// Enabled creates the network policies of the daemons
+	Enabled bool `json:"enabled,omitempty"`
+
+	// AllowedNamespaces are the namespaces of the clients allowed to connect to the daemons, besides the namespaces of
+	// the cluster and of the operator
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// AllowedCIDRs are the networks of the clients allowed to connect to the daemons outside of the pod network, such
+	// as the nodes running the CSI plugins on the host network
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+}
+
+// HostPortsSpec represents the ports the daemons bind to on the host network
+type HostPortsSpec struct {
+	// MsgrPortRange is the range of the ports the osd, mgr, mds and rbd-mirror daemons bind to. The default is
+	// 6800-7300.
+	// +optional
+	MsgrPortRange *PortRangeSpec `json:"msgrPortRange,omitempty"`
+
+	// NFSPort is the port of the nfs servers. The default is 2049.
+	// +optional
+	NFSPort int32 `json:"nfsPort,omitempty"`
+}
+
+// PortRangeSpec represents a range of ports
+type PortRangeSpec struct {
+	// Min is the first port of the range
+	Min int32 `json:"min"`
+
+	// Max is the last port of the range
+	Max int32 `json:"max"`
+}
+
+// IPFamilyType is the IP family of the addresses of the daemons
+type IPFamilyType string
+
+const (
+	// IPv4 is the IPv4 family
+	IPv4 IPFamilyType = "IPv4"
+	// IPv6 is the IPv6 family
+	IPv6 IPFamilyType = "IPv6"
+)
+
+// ConnectionsSpec represents the settings of the msgr2 connections of the cluster
+type ConnectionsSpec struct {
+	// Encryption encrypts the msgr2 connections of the daemons and the clients with the secure mode
+	// +optional
+	Encryption *EncryptionSpec `json:"encryption,omitempty"`
+	// Compression compresses the msgr2 connections between the OSDs
+	// +optional
+	Compression *CompressionSpec `json:"compression,omitempty"`
+}
+
+// EncryptionSpec represents the encryption of the connections
+type EncryptionSpec struct {
+	// Enabled enables the secure mode of the connections
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// CompressionSpec represents the compression of the connections
+type CompressionSpec struct {
+	// Enabled enables the compression of the connections
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
 }
 
 // DisruptionManagementSpec configures management of daemon disruptions
@@ -699,6 +2400,25 @@ type DisruptionManagementSpec struct {
 
 	// Namespace to look for MDBs by the machineDisruptionBudgetController
 	MachineDisruptionBudgetNamespace string `json:"machineDisruptionBudgetNamespace,omitempty"`
+
+	// MDS configures the poddisruptionbudgets of the mds daemons of each filesystem
+	MDS DaemonPodBudgetSpec `json:"mds,omitempty"`
+
+	// RGW configures the poddisruptionbudgets of the rgw daemons of each object store
+	RGW DaemonPodBudgetSpec `json:"rgw,omitempty"`
+
+	// NFS configures the poddisruptionbudgets of the ganesha servers of each CephNFS
+	NFS DaemonPodBudgetSpec `json:"nfs,omitempty"`
+}
+
+// DaemonPodBudgetSpec configures the poddisruptionbudgets of a type of daemons
+type DaemonPodBudgetSpec struct {
+	// Disabled skips the poddisruptionbudgets of the daemons
+	Disabled bool `json:"disabled,omitempty"`
+
+	// MaxUnavailable is how many daemons of the same filesystem, object store or CephNFS can be evicted at a time.
+	// The default keeps the active mds daemons and all the rgw and ganesha daemons but one available.
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
 }
 
 // +genclient
@@ -776,3 +2496,55 @@ type RBDMirroringSpec struct {
 	// PriorityClassName sets priority classes on the rgw pods
 	PriorityClassName string `json:"priorityClassName,omitempty"`
 }
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephCommandJob represents a command of the ceph tools run once in a pod with the admin keyring, its output is
+// stored in its status
+type CephCommandJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              CommandJobSpec    `json:"spec"`
+	Status            *CommandJobStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephCommandJobList represents a list of command jobs
+type CephCommandJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephCommandJob `json:"items"`
+}
+
+// CommandJobSpec represents the command run by a command job
+type CommandJobSpec struct {
+	// Command is the tool run: ceph, radosgw-admin, rados or rbd
+	Command string `json:"command"`
+
+	// Args are the arguments of the command, such as ["osd", "pool", "ls", "detail"]
+	Args []string `json:"args"`
+
+	// TimeoutSeconds is how long the command may run before it is stopped, 300 seconds by default
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// CommandJobStatus represents the result of a command job
+type CommandJobStatus struct {
+	// Phase is Pending, Running, Succeeded or Failed
+	Phase string `json:"phase,omitempty"`
+	// Output is the standard output of the command
+	Output string `json:"output,omitempty"`
+	// Truncated is whether the output was truncated to its maximum size
+	Truncated bool `json:"truncated,omitempty"`
+	// ExitCode is the exit code of the command
+	ExitCode int32 `json:"exitCode,omitempty"`
+	// Message is the error output of the command, or why it could not run
+	Message string `json:"message,omitempty"`
+	// StartTime is the time the pod of the command was created
+	StartTime string `json:"startTime,omitempty"`
+	// CompletionTime is the time the command completed
+	CompletionTime string `json:"completionTime,omitempty"`
+}
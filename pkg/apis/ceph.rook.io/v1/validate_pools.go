@@ -84,6 +84,13 @@ func (p *CephBlockPool) ValidateUpdate(old runtime.Object) error {
 			return errors.New("invalid update: erasurecoded field is set already in previous object. cannot be changed to use replicated")
 		}
 	}
+
+	// the erasure code profile of a pool cannot be changed
+	if ocbp.Spec.IsErasureCoded() && (p.Spec.ErasureCoded.FailureDomain != ocbp.Spec.ErasureCoded.FailureDomain ||
+		p.Spec.ErasureCoded.CrushRoot != ocbp.Spec.ErasureCoded.CrushRoot ||
+		p.Spec.ErasureCoded.DeviceClass != ocbp.Spec.ErasureCoded.DeviceClass) {
+		return errors.New("invalid update: the crush settings of the erasure code profile of the pool cannot be changed")
+	}
 	return nil
 }
 
@@ -77,6 +77,14 @@ func TestCephBlockPoolValidateUpdate(t *testing.T) {
 	up.Spec.ErasureCoded.CodingChunks = 1
 	err := up.ValidateUpdate(p)
 	assert.Error(t, err)
+
+	// the crush settings of the erasure code profile cannot be changed
+	p.Spec = PoolSpec{ErasureCoded: ErasureCodedSpec{DataChunks: 2, CodingChunks: 1, FailureDomain: "host"}}
+	up = p.DeepCopy()
+	up.Spec.CompressionMode = "passive"
+	assert.NoError(t, up.ValidateUpdate(p))
+	up.Spec.ErasureCoded.FailureDomain = "osd"
+	assert.Error(t, up.ValidateUpdate(p))
 }
 
 func TestCephClusterValidateUpdate(t *testing.T) {
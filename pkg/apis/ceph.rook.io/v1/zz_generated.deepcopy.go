@@ -27,15 +27,105 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthSpec) DeepCopyInto(out *AuthSpec) {
+	*out = *in
+	if in.Keystone != nil {
+		in, out := &in.Keystone, &out.Keystone
+		*out = new(KeystoneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.STS != nil {
+		in, out := &in.STS, &out.STS
+		*out = new(STSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthSpec.
+func (in *AuthSpec) DeepCopy() *AuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BalancerSpec) DeepCopyInto(out *BalancerSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BalancerSpec.
+func (in *BalancerSpec) DeepCopy() *BalancerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BalancerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BalancerStatus) DeepCopyInto(out *BalancerStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BalancerStatus.
+func (in *BalancerStatus) DeepCopy() *BalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketDataPathStatus) DeepCopyInto(out *BucketDataPathStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketDataPathStatus.
+func (in *BucketDataPathStatus) DeepCopy() *BucketDataPathStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketDataPathStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BucketHealthCheckSpec) DeepCopyInto(out *BucketHealthCheckSpec) {
 	*out = *in
 	out.Bucket = in.Bucket
+	out.DataPath = in.DataPath
 	if in.LivenessProbe != nil {
 		in, out := &in.LivenessProbe, &out.LivenessProbe
 		*out = new(rookiov1.ProbeSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(rookiov1.ProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(rookiov1.ProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -52,6 +142,11 @@ func (in *BucketHealthCheckSpec) DeepCopy() *BucketHealthCheckSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BucketStatus) DeepCopyInto(out *BucketStatus) {
 	*out = *in
+	if in.DataPath != nil {
+		in, out := &in.DataPath, &out.DataPath
+		*out = new(BucketDataPathStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -65,6 +160,107 @@ func (in *BucketStatus) DeepCopy() *BucketStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketUsageMetricsSpec) DeepCopyInto(out *BucketUsageMetricsSpec) {
+	*out = *in
+	if in.IncludeBuckets != nil {
+		in, out := &in.IncludeBuckets, &out.IncludeBuckets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeBuckets != nil {
+		in, out := &in.ExcludeBuckets, &out.ExcludeBuckets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketUsageMetricsSpec.
+func (in *BucketUsageMetricsSpec) DeepCopy() *BucketUsageMetricsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketUsageMetricsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIDriverSpec) DeepCopyInto(out *CSIDriverSpec) {
+	*out = *in
+	in.ReadAffinity.DeepCopyInto(&out.ReadAffinity)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIDriverSpec.
+func (in *CSIDriverSpec) DeepCopy() *CSIDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CSIDriverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Capacity) DeepCopyInto(out *Capacity) {
+	*out = *in
+	if in.DeviceClasses != nil {
+		in, out := &in.DeviceClasses, &out.DeviceClasses
+		*out = make([]DeviceClassCapacity, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]PoolCapacity, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Capacity.
+func (in *Capacity) DeepCopy() *Capacity {
+	if in == nil {
+		return nil
+	}
+	out := new(Capacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySpec) DeepCopyInto(out *CapacitySpec) {
+	*out = *in
+	if in.NearFullRatio != nil {
+		in, out := &in.NearFullRatio, &out.NearFullRatio
+		*out = new(float64)
+		**out = **in
+	}
+	if in.BackfillFullRatio != nil {
+		in, out := &in.BackfillFullRatio, &out.BackfillFullRatio
+		*out = new(float64)
+		**out = **in
+	}
+	if in.FullRatio != nil {
+		in, out := &in.FullRatio, &out.FullRatio
+		*out = new(float64)
+		**out = **in
+	}
+	out.ReweightByUtilization = in.ReweightByUtilization
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySpec.
+func (in *CapacitySpec) DeepCopy() *CapacitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CephBlockPool) DeepCopyInto(out *CephBlockPool) {
 	*out = *in
@@ -73,8 +269,8 @@ func (in *CephBlockPool) DeepCopyInto(out *CephBlockPool) {
 	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
-		*out = new(Status)
-		**out = **in
+		*out = new(CephBlockPoolStatus)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -130,6 +326,109 @@ func (in *CephBlockPoolList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolRadosNamespace) DeepCopyInto(out *CephBlockPoolRadosNamespace) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(RadosNamespaceStatus)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolRadosNamespace.
+func (in *CephBlockPoolRadosNamespace) DeepCopy() *CephBlockPoolRadosNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolRadosNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephBlockPoolRadosNamespace) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolRadosNamespaceList) DeepCopyInto(out *CephBlockPoolRadosNamespaceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephBlockPoolRadosNamespace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolRadosNamespaceList.
+func (in *CephBlockPoolRadosNamespaceList) DeepCopy() *CephBlockPoolRadosNamespaceList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolRadosNamespaceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephBlockPoolRadosNamespaceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephBlockPoolStatus) DeepCopyInto(out *CephBlockPoolStatus) {
+	*out = *in
+	if in.Deletion != nil {
+		in, out := &in.Deletion, &out.Deletion
+		*out = new(PoolDeletionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(PoolUsageStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = make([]ProgressEventStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephBlockPoolStatus.
+func (in *CephBlockPoolStatus) DeepCopy() *CephBlockPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CephBlockPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CephClient) DeepCopyInto(out *CephClient) {
 	*out = *in
@@ -237,6 +536,36 @@ func (in *CephClusterHealthCheckSpec) DeepCopyInto(out *CephClusterHealthCheckSp
 			(*out)[key] = outVal
 		}
 	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = make(map[rookiov1.KeyType]*rookiov1.ProbeSpec, len(*in))
+		for key, val := range *in {
+			var outVal *rookiov1.ProbeSpec
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(rookiov1.ProbeSpec)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = make(map[rookiov1.KeyType]*rookiov1.ProbeSpec, len(*in))
+		for key, val := range *in {
+			var outVal *rookiov1.ProbeSpec
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(rookiov1.ProbeSpec)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -284,31 +613,31 @@ func (in *CephClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephFilesystem) DeepCopyInto(out *CephFilesystem) {
+func (in *CephCommandJob) DeepCopyInto(out *CephCommandJob) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
-		*out = new(Status)
+		*out = new(CommandJobStatus)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystem.
-func (in *CephFilesystem) DeepCopy() *CephFilesystem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephCommandJob.
+func (in *CephCommandJob) DeepCopy() *CephCommandJob {
 	if in == nil {
 		return nil
 	}
-	out := new(CephFilesystem)
+	out := new(CephCommandJob)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CephFilesystem) DeepCopyObject() runtime.Object {
+func (in *CephCommandJob) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -316,13 +645,13 @@ func (in *CephFilesystem) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephFilesystemList) DeepCopyInto(out *CephFilesystemList) {
+func (in *CephCommandJobList) DeepCopyInto(out *CephCommandJobList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CephFilesystem, len(*in))
+		*out = make([]CephCommandJob, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -330,18 +659,18 @@ func (in *CephFilesystemList) DeepCopyInto(out *CephFilesystemList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemList.
-func (in *CephFilesystemList) DeepCopy() *CephFilesystemList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephCommandJobList.
+func (in *CephCommandJobList) DeepCopy() *CephCommandJobList {
 	if in == nil {
 		return nil
 	}
-	out := new(CephFilesystemList)
+	out := new(CephCommandJobList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CephFilesystemList) DeepCopyObject() runtime.Object {
+func (in *CephCommandJobList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -349,17 +678,205 @@ func (in *CephFilesystemList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CephHealthMessage) DeepCopyInto(out *CephHealthMessage) {
+func (in *CephDeviceInventory) DeepCopyInto(out *CephDeviceInventory) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(DeviceInventoryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthMessage.
-func (in *CephHealthMessage) DeepCopy() *CephHealthMessage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephDeviceInventory.
+func (in *CephDeviceInventory) DeepCopy() *CephDeviceInventory {
 	if in == nil {
 		return nil
 	}
-	out := new(CephHealthMessage)
+	out := new(CephDeviceInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephDeviceInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephDeviceInventoryList) DeepCopyInto(out *CephDeviceInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephDeviceInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephDeviceInventoryList.
+func (in *CephDeviceInventoryList) DeepCopy() *CephDeviceInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephDeviceInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephDeviceInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephExporterSpec) DeepCopyInto(out *CephExporterSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephExporterSpec.
+func (in *CephExporterSpec) DeepCopy() *CephExporterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CephExporterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephFilesystem) DeepCopyInto(out *CephFilesystem) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(CephFilesystemStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystem.
+func (in *CephFilesystem) DeepCopy() *CephFilesystem {
+	if in == nil {
+		return nil
+	}
+	out := new(CephFilesystem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephFilesystem) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephFilesystemList) DeepCopyInto(out *CephFilesystemList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CephFilesystem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemList.
+func (in *CephFilesystemList) DeepCopy() *CephFilesystemList {
+	if in == nil {
+		return nil
+	}
+	out := new(CephFilesystemList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CephFilesystemList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephFilesystemStatus) DeepCopyInto(out *CephFilesystemStatus) {
+	*out = *in
+	if in.Mirroring != nil {
+		in, out := &in.Mirroring, &out.Mirroring
+		*out = new(FilesystemMirroringStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephFilesystemStatus.
+func (in *CephFilesystemStatus) DeepCopy() *CephFilesystemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CephFilesystemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephHealthMessage) DeepCopyInto(out *CephHealthMessage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthMessage.
+func (in *CephHealthMessage) DeepCopy() *CephHealthMessage {
+	if in == nil {
+		return nil
+	}
+	out := new(CephHealthMessage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephHealthTransition) DeepCopyInto(out *CephHealthTransition) {
+	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephHealthTransition.
+func (in *CephHealthTransition) DeepCopy() *CephHealthTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(CephHealthTransition)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -434,7 +951,7 @@ func (in *CephObjectRealm) DeepCopyInto(out *CephObjectRealm) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
 		*out = new(Status)
@@ -564,7 +1081,7 @@ func (in *CephObjectStoreUser) DeepCopyInto(out *CephObjectStoreUser) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
 		*out = new(ObjectStoreUserStatus)
@@ -661,7 +1178,7 @@ func (in *CephObjectZoneGroup) DeepCopyInto(out *CephObjectZoneGroup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
 		*out = new(Status)
@@ -829,6 +1346,24 @@ func (in *CephStatus) DeepCopyInto(out *CephStatus) {
 			(*out)[key] = val
 		}
 	}
+	in.Capacity.DeepCopyInto(&out.Capacity)
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]CephHealthTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Balancer != nil {
+		in, out := &in.Balancer, &out.Balancer
+		*out = new(BalancerStatus)
+		**out = **in
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = make([]ProgressEventStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -850,6 +1385,11 @@ func (in *CephStorage) DeepCopyInto(out *CephStorage) {
 		*out = make([]DeviceClasses, len(*in))
 		copy(*out, *in)
 	}
+	if in.OSD != nil {
+		in, out := &in.OSD, &out.OSD
+		*out = new(OSDStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -879,6 +1419,60 @@ func (in *CephVersionSpec) DeepCopy() *CephVersionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephxKeyRotationSpec) DeepCopyInto(out *CephxKeyRotationSpec) {
+	*out = *in
+	out.KeyRotationSpec = in.KeyRotationSpec
+	if in.Daemons != nil {
+		in, out := &in.Daemons, &out.Daemons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephxKeyRotationSpec.
+func (in *CephxKeyRotationSpec) DeepCopy() *CephxKeyRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CephxKeyRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CephxKeyRotationStatus) DeepCopyInto(out *CephxKeyRotationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CephxKeyRotationStatus.
+func (in *CephxKeyRotationStatus) DeepCopy() *CephxKeyRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CephxKeyRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CleanupPolicySpec) DeepCopyInto(out *CleanupPolicySpec) {
 	*out = *in
@@ -948,6 +1542,24 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(rookiov1.LabelsSpec, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(rookiov1.Labels, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	in.InheritedMetadata.DeepCopyInto(&out.InheritedMetadata)
 	if in.Placement != nil {
 		in, out := &in.Placement, &out.Placement
 		*out = make(rookiov1.PlacementSpec, len(*in))
@@ -963,6 +1575,13 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	in.ResourceAutoscaling.DeepCopyInto(&out.ResourceAutoscaling)
+	in.OSDPerformance.DeepCopyInto(&out.OSDPerformance)
+	in.Scrubbing.DeepCopyInto(&out.Scrubbing)
+	in.Capacity.DeepCopyInto(&out.Capacity)
+	in.Balancer.DeepCopyInto(&out.Balancer)
+	out.NewDevices = in.NewDevices
+	in.CrushTopology.DeepCopyInto(&out.CrushTopology)
 	if in.PriorityClassNames != nil {
 		in, out := &in.PriorityClassNames, &out.PriorityClassNames
 		*out = make(rookiov1.PriorityClassNamesSpec, len(*in))
@@ -970,15 +1589,24 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = val
 		}
 	}
-	out.DisruptionManagement = in.DisruptionManagement
+	if in.UpgradeChecks != nil {
+		in, out := &in.UpgradeChecks, &out.UpgradeChecks
+		*out = new(UpgradeChecksSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.DisruptionManagement.DeepCopyInto(&out.DisruptionManagement)
 	in.Mon.DeepCopyInto(&out.Mon)
 	out.CrashCollector = in.CrashCollector
-	out.Dashboard = in.Dashboard
+	in.LogCollector.DeepCopyInto(&out.LogCollector)
+	out.Toolbox = in.Toolbox
+	in.Dashboard.DeepCopyInto(&out.Dashboard)
 	in.Monitoring.DeepCopyInto(&out.Monitoring)
 	out.External = in.External
 	in.Mgr.DeepCopyInto(&out.Mgr)
 	out.CleanupPolicy = in.CleanupPolicy
 	in.HealthCheck.DeepCopyInto(&out.HealthCheck)
+	in.CSI.DeepCopyInto(&out.CSI)
+	in.Security.DeepCopyInto(&out.Security)
 	return
 }
 
@@ -1017,6 +1645,41 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		*out = new(ClusterVersion)
 		**out = **in
 	}
+	if in.AdminKeyConsumers != nil {
+		in, out := &in.AdminKeyConsumers, &out.AdminKeyConsumers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeyRotation != nil {
+		in, out := &in.KeyRotation, &out.KeyRotation
+		*out = new(KeyRotationStatus)
+		**out = **in
+	}
+	if in.CephxKeyRotation != nil {
+		in, out := &in.CephxKeyRotation, &out.CephxKeyRotation
+		*out = new(CephxKeyRotationStatus)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ResourcesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(NetworkStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeStatus)
+		**out = **in
+	}
+	if in.CrashPruning != nil {
+		in, out := &in.CrashPruning, &out.CrashPruning
+		*out = new(CrashPruningStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -1047,255 +1710,2287 @@ func (in *ClusterVersion) DeepCopy() *ClusterVersion {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Condition) DeepCopyInto(out *Condition) {
+func (in *CommandJobSpec) DeepCopyInto(out *CommandJobSpec) {
 	*out = *in
-	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
-func (in *Condition) DeepCopy() *Condition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandJobSpec.
+func (in *CommandJobSpec) DeepCopy() *CommandJobSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Condition)
+	out := new(CommandJobSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CrashCollectorSpec) DeepCopyInto(out *CrashCollectorSpec) {
+func (in *CommandJobStatus) DeepCopyInto(out *CommandJobStatus) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashCollectorSpec.
-func (in *CrashCollectorSpec) DeepCopy() *CrashCollectorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandJobStatus.
+func (in *CommandJobStatus) DeepCopy() *CommandJobStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CrashCollectorSpec)
+	out := new(CommandJobStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DaemonHealthSpec) DeepCopyInto(out *DaemonHealthSpec) {
+func (in *CompressionSpec) DeepCopyInto(out *CompressionSpec) {
 	*out = *in
-	out.Status = in.Status
-	out.Monitor = in.Monitor
-	out.ObjectStorageDaemon = in.ObjectStorageDaemon
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonHealthSpec.
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompressionSpec.
+func (in *CompressionSpec) DeepCopy() *CompressionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CompressionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionsSpec) DeepCopyInto(out *ConnectionsSpec) {
+	*out = *in
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(EncryptionSpec)
+		**out = **in
+	}
+	if in.Compression != nil {
+		in, out := &in.Compression, &out.Compression
+		*out = new(CompressionSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionsSpec.
+func (in *ConnectionsSpec) DeepCopy() *ConnectionsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashCollectorSpec) DeepCopyInto(out *CrashCollectorSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashCollectorSpec.
+func (in *CrashCollectorSpec) DeepCopy() *CrashCollectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashCollectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashHealthCheckSpec) DeepCopyInto(out *CrashHealthCheckSpec) {
+	*out = *in
+	out.HealthCheckSpec = in.HealthCheckSpec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashHealthCheckSpec.
+func (in *CrashHealthCheckSpec) DeepCopy() *CrashHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashPruningStatus) DeepCopyInto(out *CrashPruningStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashPruningStatus.
+func (in *CrashPruningStatus) DeepCopy() *CrashPruningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashPruningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrushTopologyLabelSpec) DeepCopyInto(out *CrushTopologyLabelSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrushTopologyLabelSpec.
+func (in *CrushTopologyLabelSpec) DeepCopy() *CrushTopologyLabelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrushTopologyLabelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrushTopologySpec) DeepCopyInto(out *CrushTopologySpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]CrushTopologyLabelSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrushTopologySpec.
+func (in *CrushTopologySpec) DeepCopy() *CrushTopologySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrushTopologySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonHealthSpec) DeepCopyInto(out *DaemonHealthSpec) {
+	*out = *in
+	out.Status = in.Status
+	out.Monitor = in.Monitor
+	out.ObjectStorageDaemon = in.ObjectStorageDaemon
+	out.Pool = in.Pool
+	out.Crash = in.Crash
+	out.FilesystemMirror = in.FilesystemMirror
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonHealthSpec.
 func (in *DaemonHealthSpec) DeepCopy() *DaemonHealthSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DaemonHealthSpec)
+	out := new(DaemonHealthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonNetworkStatus) DeepCopyInto(out *DaemonNetworkStatus) {
+	*out = *in
+	if in.PublicIPs != nil {
+		in, out := &in.PublicIPs, &out.PublicIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterIPs != nil {
+		in, out := &in.ClusterIPs, &out.ClusterIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonNetworkStatus.
+func (in *DaemonNetworkStatus) DeepCopy() *DaemonNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonPodBudgetSpec) DeepCopyInto(out *DaemonPodBudgetSpec) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonPodBudgetSpec.
+func (in *DaemonPodBudgetSpec) DeepCopy() *DaemonPodBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonPodBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonResourceAutoscalingSpec) DeepCopyInto(out *DaemonResourceAutoscalingSpec) {
+	*out = *in
+	if in.MinAllowed != nil {
+		in, out := &in.MinAllowed, &out.MinAllowed
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxAllowed != nil {
+		in, out := &in.MaxAllowed, &out.MaxAllowed
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonResourceAutoscalingSpec.
+func (in *DaemonResourceAutoscalingSpec) DeepCopy() *DaemonResourceAutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonResourceAutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonUpgradeChecksSpec) DeepCopyInto(out *DaemonUpgradeChecksSpec) {
+	*out = *in
+	if in.SkipUpgradeChecks != nil {
+		in, out := &in.SkipUpgradeChecks, &out.SkipUpgradeChecks
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ContinueUpgradeAfterChecksEvenIfNotHealthy != nil {
+		in, out := &in.ContinueUpgradeAfterChecksEvenIfNotHealthy, &out.ContinueUpgradeAfterChecksEvenIfNotHealthy
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonUpgradeChecksSpec.
+func (in *DaemonUpgradeChecksSpec) DeepCopy() *DaemonUpgradeChecksSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonUpgradeChecksSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardIngressSpec) DeepCopyInto(out *DashboardIngressSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardIngressSpec.
+func (in *DashboardIngressSpec) DeepCopy() *DashboardIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSAML2Spec) DeepCopyInto(out *DashboardSAML2Spec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSAML2Spec.
+func (in *DashboardSAML2Spec) DeepCopy() *DashboardSAML2Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSAML2Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSSOSpec) DeepCopyInto(out *DashboardSSOSpec) {
+	*out = *in
+	if in.SAML2 != nil {
+		in, out := &in.SAML2, &out.SAML2
+		*out = new(DashboardSAML2Spec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSSOSpec.
+func (in *DashboardSSOSpec) DeepCopy() *DashboardSSOSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSSOSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
+	*out = *in
+	if in.SSO != nil {
+		in, out := &in.SSO, &out.SSO
+		*out = new(DashboardSSOSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(DashboardIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSpec.
+func (in *DashboardSpec) DeepCopy() *DashboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClassCapacity) DeepCopyInto(out *DeviceClassCapacity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClassCapacity.
+func (in *DeviceClassCapacity) DeepCopy() *DeviceClassCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClassCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClasses) DeepCopyInto(out *DeviceClasses) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClasses.
+func (in *DeviceClasses) DeepCopy() *DeviceClasses {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClasses)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceInventorySpec) DeepCopyInto(out *DeviceInventorySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceInventorySpec.
+func (in *DeviceInventorySpec) DeepCopy() *DeviceInventorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceInventorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceInventoryStatus) DeepCopyInto(out *DeviceInventoryStatus) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]InventoryDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceInventoryStatus.
+func (in *DeviceInventoryStatus) DeepCopy() *DeviceInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionManagementSpec) DeepCopyInto(out *DisruptionManagementSpec) {
+	*out = *in
+	in.MDS.DeepCopyInto(&out.MDS)
+	in.RGW.DeepCopyInto(&out.RGW)
+	in.NFS.DeepCopyInto(&out.NFS)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionManagementSpec.
+func (in *DisruptionManagementSpec) DeepCopy() *DisruptionManagementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionManagementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriveGroup) DeepCopyInto(out *DriveGroup) {
+	*out = *in
+	out.Spec = in.Spec.DeepCopy()
+	in.Placement.DeepCopyInto(&out.Placement)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriveGroup.
+func (in *DriveGroup) DeepCopy() *DriveGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(DriveGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in DriveGroupSpec) DeepCopyInto(out *DriveGroupSpec) {
+	{
+		in := &in
+		*out = in.DeepCopy()
+		return
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in DriveGroupsSpec) DeepCopyInto(out *DriveGroupsSpec) {
+	{
+		in := &in
+		*out = make(DriveGroupsSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriveGroupsSpec.
+func (in DriveGroupsSpec) DeepCopy() DriveGroupsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DriveGroupsSpec)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionSpec) DeepCopyInto(out *EncryptionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionSpec.
+func (in *EncryptionSpec) DeepCopy() *EncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ErasureCodedSpec) DeepCopyInto(out *ErasureCodedSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErasureCodedSpec.
+func (in *ErasureCodedSpec) DeepCopy() *ErasureCodedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ErasureCodedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDiscoverySpec) DeepCopyInto(out *ExternalDiscoverySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDiscoverySpec.
+func (in *ExternalDiscoverySpec) DeepCopy() *ExternalDiscoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDiscoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalRealmSpec) DeepCopyInto(out *ExternalRealmSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalRealmSpec.
+func (in *ExternalRealmSpec) DeepCopy() *ExternalRealmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalRealmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSpec) DeepCopyInto(out *ExternalSpec) {
+	*out = *in
+	out.Discovery = in.Discovery
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSpec.
+func (in *ExternalSpec) DeepCopy() *ExternalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemMirrorDirectoryStatus) DeepCopyInto(out *FilesystemMirrorDirectoryStatus) {
+	*out = *in
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemMirrorDirectoryStatus.
+func (in *FilesystemMirrorDirectoryStatus) DeepCopy() *FilesystemMirrorDirectoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemMirrorDirectoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemMirrorPeerStatus) DeepCopyInto(out *FilesystemMirrorPeerStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemMirrorPeerStatus.
+func (in *FilesystemMirrorPeerStatus) DeepCopy() *FilesystemMirrorPeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemMirrorPeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemMirroringSpec) DeepCopyInto(out *FilesystemMirroringSpec) {
+	*out = *in
+	if in.Directories != nil {
+		in, out := &in.Directories, &out.Directories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemMirroringSpec.
+func (in *FilesystemMirroringSpec) DeepCopy() *FilesystemMirroringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemMirroringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemMirroringStatus) DeepCopyInto(out *FilesystemMirroringStatus) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]FilesystemMirrorPeerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Directories != nil {
+		in, out := &in.Directories, &out.Directories
+		*out = make([]FilesystemMirrorDirectoryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemMirroringStatus.
+func (in *FilesystemMirroringStatus) DeepCopy() *FilesystemMirroringStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemMirroringStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemSpec) DeepCopyInto(out *FilesystemSpec) {
+	*out = *in
+	in.MetadataPool.DeepCopyInto(&out.MetadataPool)
+	if in.DataPools != nil {
+		in, out := &in.DataPools, &out.DataPools
+		*out = make([]PoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.MetadataServer.DeepCopyInto(&out.MetadataServer)
+	if in.Mirroring != nil {
+		in, out := &in.Mirroring, &out.Mirroring
+		*out = new(FilesystemMirroringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSpec.
+func (in *FilesystemSpec) DeepCopy() *FilesystemSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GaneshaRADOSSpec) DeepCopyInto(out *GaneshaRADOSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GaneshaRADOSSpec.
+func (in *GaneshaRADOSSpec) DeepCopy() *GaneshaRADOSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GaneshaRADOSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GaneshaServerSpec) DeepCopyInto(out *GaneshaServerSpec) {
+	*out = *in
+	in.Placement.DeepCopyInto(&out.Placement)
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(rookiov1.Annotations, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(rookiov1.Labels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GaneshaServerSpec.
+func (in *GaneshaServerSpec) DeepCopy() *GaneshaServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GaneshaServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
+	*out = *in
+	in.Placement.DeepCopyInto(&out.Placement)
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(rookiov1.Annotations, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(rookiov1.Labels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ExternalRgwEndpoints != nil {
+		in, out := &in.ExternalRgwEndpoints, &out.ExternalRgwEndpoints
+		*out = make([]corev1.EndpointAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySpec.
+func (in *GatewaySpec) DeepCopy() *GatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckSpec) DeepCopyInto(out *HealthCheckSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckSpec.
+func (in *HealthCheckSpec) DeepCopy() *HealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostPortsSpec) DeepCopyInto(out *HostPortsSpec) {
+	*out = *in
+	if in.MsgrPortRange != nil {
+		in, out := &in.MsgrPortRange, &out.MsgrPortRange
+		*out = new(PortRangeSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostPortsSpec.
+func (in *HostPortsSpec) DeepCopy() *HostPortsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HostPortsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HybridStorageSpec) DeepCopyInto(out *HybridStorageSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HybridStorageSpec.
+func (in *HybridStorageSpec) DeepCopy() *HybridStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HybridStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InheritedMetadataSpec) DeepCopyInto(out *InheritedMetadataSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InheritedMetadataSpec.
+func (in *InheritedMetadataSpec) DeepCopy() *InheritedMetadataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InheritedMetadataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventoryDevice) DeepCopyInto(out *InventoryDevice) {
+	*out = *in
+	if in.DevLinks != nil {
+		in, out := &in.DevLinks, &out.DevLinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OSDIDs != nil {
+		in, out := &in.OSDIDs, &out.OSDIDs
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventoryDevice.
+func (in *InventoryDevice) DeepCopy() *InventoryDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(InventoryDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyManagementServiceSpec) DeepCopyInto(out *KeyManagementServiceSpec) {
+	*out = *in
+	if in.ConnectionDetails != nil {
+		in, out := &in.ConnectionDetails, &out.ConnectionDetails
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyManagementServiceSpec.
+func (in *KeyManagementServiceSpec) DeepCopy() *KeyManagementServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyManagementServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyRotationSpec) DeepCopyInto(out *KeyRotationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyRotationSpec.
+func (in *KeyRotationSpec) DeepCopy() *KeyRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyRotationStatus) DeepCopyInto(out *KeyRotationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyRotationStatus.
+func (in *KeyRotationStatus) DeepCopy() *KeyRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeystoneSpec) DeepCopyInto(out *KeystoneSpec) {
+	*out = *in
+	if in.AcceptedRoles != nil {
+		in, out := &in.AcceptedRoles, &out.AcceptedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TokenCacheSize != nil {
+		in, out := &in.TokenCacheSize, &out.TokenCacheSize
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeystoneSpec.
+func (in *KeystoneSpec) DeepCopy() *KeystoneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeystoneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorSpec) DeepCopyInto(out *LogCollectorSpec) {
+	*out = *in
+	out.LogRotationSpec = in.LogRotationSpec
+	if in.Daemons != nil {
+		in, out := &in.Daemons, &out.Daemons
+		*out = make(map[string]LogRotationSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Shipper != nil {
+		in, out := &in.Shipper, &out.Shipper
+		*out = new(LogShipperSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorSpec.
+func (in *LogCollectorSpec) DeepCopy() *LogCollectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogRotationSpec) DeepCopyInto(out *LogRotationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogRotationSpec.
+func (in *LogRotationSpec) DeepCopy() *LogRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogShipperSpec) DeepCopyInto(out *LogShipperSpec) {
+	*out = *in
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogShipperSpec.
+func (in *LogShipperSpec) DeepCopy() *LogShipperSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogShipperSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataServerSpec) DeepCopyInto(out *MetadataServerSpec) {
+	*out = *in
+	in.Placement.DeepCopyInto(&out.Placement)
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(rookiov1.Annotations, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(rookiov1.Labels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataServerSpec.
+func (in *MetadataServerSpec) DeepCopy() *MetadataServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MgrSpec) DeepCopyInto(out *MgrSpec) {
+	*out = *in
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]Module, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MgrSpec.
+func (in *MgrSpec) DeepCopy() *MgrSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MgrSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Module) DeepCopyInto(out *Module) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Module.
+func (in *Module) DeepCopy() *Module {
+	if in == nil {
+		return nil
+	}
+	out := new(Module)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonBackupS3Spec) DeepCopyInto(out *MonBackupS3Spec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonBackupS3Spec.
+func (in *MonBackupS3Spec) DeepCopy() *MonBackupS3Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonBackupS3Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonBackupSpec) DeepCopyInto(out *MonBackupSpec) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(MonBackupS3Spec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonBackupSpec.
+func (in *MonBackupSpec) DeepCopy() *MonBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonFailureDomainSpec) DeepCopyInto(out *MonFailureDomainSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonFailureDomainSpec.
+func (in *MonFailureDomainSpec) DeepCopy() *MonFailureDomainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonFailureDomainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonSpec) DeepCopyInto(out *MonSpec) {
+	*out = *in
+	if in.VolumeClaimTemplate != nil {
+		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
+		*out = new(corev1.PersistentVolumeClaim)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(MonBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureDomain != nil {
+		in, out := &in.FailureDomain, &out.FailureDomain
+		*out = new(MonFailureDomainSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonSpec.
+func (in *MonSpec) DeepCopy() *MonSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.ExternalMgrEndpoints != nil {
+		in, out := &in.ExternalMgrEndpoints, &out.ExternalMgrEndpoints
+		*out = make([]corev1.EndpointAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(CephExporterSpec)
+		**out = **in
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = new(PrometheusRulesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSExportSpec) DeepCopyInto(out *NFSExportSpec) {
+	*out = *in
+	if in.ObjectStore != nil {
+		in, out := &in.ObjectStore, &out.ObjectStore
+		*out = new(NFSObjectStoreExportSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSExportSpec.
+func (in *NFSExportSpec) DeepCopy() *NFSExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSGaneshaSpec) DeepCopyInto(out *NFSGaneshaSpec) {
+	*out = *in
+	out.RADOS = in.RADOS
+	in.Server.DeepCopyInto(&out.Server)
+	if in.Exports != nil {
+		in, out := &in.Exports, &out.Exports
+		*out = make([]NFSExportSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSGaneshaSpec.
+func (in *NFSGaneshaSpec) DeepCopy() *NFSGaneshaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSGaneshaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSObjectStoreExportSpec) DeepCopyInto(out *NFSObjectStoreExportSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSObjectStoreExportSpec.
+func (in *NFSObjectStoreExportSpec) DeepCopy() *NFSObjectStoreExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSObjectStoreExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPoliciesSpec) DeepCopyInto(out *NetworkPoliciesSpec) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPoliciesSpec.
+func (in *NetworkPoliciesSpec) DeepCopy() *NetworkPoliciesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPoliciesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+	*out = *in
+	in.NetworkSpec.DeepCopyInto(&out.NetworkSpec)
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = new(ConnectionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostPorts != nil {
+		in, out := &in.HostPorts, &out.HostPorts
+		*out = new(HostPortsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = new(NetworkPoliciesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
+func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkStatus) DeepCopyInto(out *NetworkStatus) {
+	*out = *in
+	if in.Daemons != nil {
+		in, out := &in.Daemons, &out.Daemons
+		*out = make([]DaemonNetworkStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Detached != nil {
+		in, out := &in.Detached, &out.Detached
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkStatus.
+func (in *NetworkStatus) DeepCopy() *NetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NewDevicesSpec) DeepCopyInto(out *NewDevicesSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NewDevicesSpec.
+func (in *NewDevicesSpec) DeepCopy() *NewDevicesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NewDevicesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCProviderSpec) DeepCopyInto(out *OIDCProviderSpec) {
+	*out = *in
+	if in.ClientIDs != nil {
+		in, out := &in.ClientIDs, &out.ClientIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Thumbprints != nil {
+		in, out := &in.Thumbprints, &out.Thumbprints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCProviderSpec.
+func (in *OIDCProviderSpec) DeepCopy() *OIDCProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSDAutoOutSpec) DeepCopyInto(out *OSDAutoOutSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSDAutoOutSpec.
+func (in *OSDAutoOutSpec) DeepCopy() *OSDAutoOutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OSDAutoOutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSDDeviceClassSpec) DeepCopyInto(out *OSDDeviceClassSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSDDeviceClassSpec.
+func (in *OSDDeviceClassSpec) DeepCopy() *OSDDeviceClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OSDDeviceClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSDHealthCheckSpec) DeepCopyInto(out *OSDHealthCheckSpec) {
+	*out = *in
+	out.HealthCheckSpec = in.HealthCheckSpec
+	out.AutoOut = in.AutoOut
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSDHealthCheckSpec.
+func (in *OSDHealthCheckSpec) DeepCopy() *OSDHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OSDHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSDMigrationStatus) DeepCopyInto(out *OSDMigrationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSDMigrationStatus.
+func (in *OSDMigrationStatus) DeepCopy() *OSDMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OSDMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSDPerformanceSpec) DeepCopyInto(out *OSDPerformanceSpec) {
+	*out = *in
+	if in.NUMANode != nil {
+		in, out := &in.NUMANode, &out.NUMANode
+		*out = new(int)
+		**out = **in
+	}
+	if in.BluestoreCacheSize != nil {
+		in, out := &in.BluestoreCacheSize, &out.BluestoreCacheSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DeviceClasses != nil {
+		in, out := &in.DeviceClasses, &out.DeviceClasses
+		*out = make([]OSDDeviceClassSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSDPerformanceSpec.
+func (in *OSDPerformanceSpec) DeepCopy() *OSDPerformanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OSDPerformanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSDStatus) DeepCopyInto(out *OSDStatus) {
+	*out = *in
+	if in.StoreType != nil {
+		in, out := &in.StoreType, &out.StoreType
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MigrationStatus != nil {
+		in, out := &in.MigrationStatus, &out.MigrationStatus
+		*out = new(OSDMigrationStatus)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSDStatus.
+func (in *OSDStatus) DeepCopy() *OSDStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OSDStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectRealmSpec) DeepCopyInto(out *ObjectRealmSpec) {
+	*out = *in
+	in.Pull.DeepCopyInto(&out.Pull)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectRealmSpec.
+func (in *ObjectRealmSpec) DeepCopy() *ObjectRealmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectRealmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreSecuritySpec) DeepCopyInto(out *ObjectStoreSecuritySpec) {
+	*out = *in
+	in.KeyManagementService.DeepCopyInto(&out.KeyManagementService)
+	in.ServerSideEncryptionS3.DeepCopyInto(&out.ServerSideEncryptionS3)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreSecuritySpec.
+func (in *ObjectStoreSecuritySpec) DeepCopy() *ObjectStoreSecuritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreSecuritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreSpec) DeepCopyInto(out *ObjectStoreSpec) {
+	*out = *in
+	in.MetadataPool.DeepCopyInto(&out.MetadataPool)
+	in.DataPool.DeepCopyInto(&out.DataPool)
+	in.Gateway.DeepCopyInto(&out.Gateway)
+	out.Zone = in.Zone
+	in.HealthCheck.DeepCopyInto(&out.HealthCheck)
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(ObjectStoreSecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Auth.DeepCopyInto(&out.Auth)
+	in.Protocols.DeepCopyInto(&out.Protocols)
+	if in.PlacementTargets != nil {
+		in, out := &in.PlacementTargets, &out.PlacementTargets
+		*out = make([]PlacementTargetSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UsageMetrics != nil {
+		in, out := &in.UsageMetrics, &out.UsageMetrics
+		*out = new(BucketUsageMetricsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreSpec.
+func (in *ObjectStoreSpec) DeepCopy() *ObjectStoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreStatus) DeepCopyInto(out *ObjectStoreStatus) {
+	*out = *in
+	if in.BucketStatus != nil {
+		in, out := &in.BucketStatus, &out.BucketStatus
+		*out = new(BucketStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreStatus.
+func (in *ObjectStoreStatus) DeepCopy() *ObjectStoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreSubUserSpec) DeepCopyInto(out *ObjectStoreSubUserSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreSubUserSpec.
+func (in *ObjectStoreSubUserSpec) DeepCopy() *ObjectStoreSubUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreSubUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreUserKey) DeepCopyInto(out *ObjectStoreUserKey) {
+	*out = *in
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreUserKey.
+func (in *ObjectStoreUserKey) DeepCopy() *ObjectStoreUserKey {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreUserKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreUserSpec) DeepCopyInto(out *ObjectStoreUserSpec) {
+	*out = *in
+	if in.SubUsers != nil {
+		in, out := &in.SubUsers, &out.SubUsers
+		*out = make([]ObjectStoreSubUserSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretFormats != nil {
+		in, out := &in.SecretFormats, &out.SecretFormats
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreUserSpec.
+func (in *ObjectStoreUserSpec) DeepCopy() *ObjectStoreUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStoreUserStatus) DeepCopyInto(out *ObjectStoreUserStatus) {
+	*out = *in
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PreviousKeys != nil {
+		in, out := &in.PreviousKeys, &out.PreviousKeys
+		*out = make([]ObjectStoreUserKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreUserStatus.
+func (in *ObjectStoreUserStatus) DeepCopy() *ObjectStoreUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStoreUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectSyncFlowSpec) DeepCopyInto(out *ObjectSyncFlowSpec) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectSyncFlowSpec.
+func (in *ObjectSyncFlowSpec) DeepCopy() *ObjectSyncFlowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectSyncFlowSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
+func (in *ObjectSyncGroupSpec) DeepCopyInto(out *ObjectSyncGroupSpec) {
 	*out = *in
+	if in.Flows != nil {
+		in, out := &in.Flows, &out.Flows
+		*out = make([]ObjectSyncFlowSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pipes != nil {
+		in, out := &in.Pipes, &out.Pipes
+		*out = make([]ObjectSyncPipeSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSpec.
-func (in *DashboardSpec) DeepCopy() *DashboardSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectSyncGroupSpec.
+func (in *ObjectSyncGroupSpec) DeepCopy() *ObjectSyncGroupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DashboardSpec)
+	out := new(ObjectSyncGroupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeviceClasses) DeepCopyInto(out *DeviceClasses) {
+func (in *ObjectSyncPipeSpec) DeepCopyInto(out *ObjectSyncPipeSpec) {
 	*out = *in
+	if in.SourceZones != nil {
+		in, out := &in.SourceZones, &out.SourceZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestZones != nil {
+		in, out := &in.DestZones, &out.DestZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClasses.
-func (in *DeviceClasses) DeepCopy() *DeviceClasses {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectSyncPipeSpec.
+func (in *ObjectSyncPipeSpec) DeepCopy() *ObjectSyncPipeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DeviceClasses)
+	out := new(ObjectSyncPipeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DisruptionManagementSpec) DeepCopyInto(out *DisruptionManagementSpec) {
+func (in *ObjectSyncPolicySpec) DeepCopyInto(out *ObjectSyncPolicySpec) {
 	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]ObjectSyncGroupSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionManagementSpec.
-func (in *DisruptionManagementSpec) DeepCopy() *DisruptionManagementSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectSyncPolicySpec.
+func (in *ObjectSyncPolicySpec) DeepCopy() *ObjectSyncPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DisruptionManagementSpec)
+	out := new(ObjectSyncPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DriveGroup) DeepCopyInto(out *DriveGroup) {
+func (in *ObjectZoneGroupSpec) DeepCopyInto(out *ObjectZoneGroupSpec) {
 	*out = *in
-	out.Spec = in.Spec.DeepCopy()
-	in.Placement.DeepCopyInto(&out.Placement)
+	if in.SyncPolicy != nil {
+		in, out := &in.SyncPolicy, &out.SyncPolicy
+		*out = new(ObjectSyncPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriveGroup.
-func (in *DriveGroup) DeepCopy() *DriveGroup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectZoneGroupSpec.
+func (in *ObjectZoneGroupSpec) DeepCopy() *ObjectZoneGroupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DriveGroup)
+	out := new(ObjectZoneGroupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in DriveGroupSpec) DeepCopyInto(out *DriveGroupSpec) {
-	{
-		in := &in
-		*out = in.DeepCopy()
-		return
+func (in *ObjectZoneSpec) DeepCopyInto(out *ObjectZoneSpec) {
+	*out = *in
+	in.MetadataPool.DeepCopyInto(&out.MetadataPool)
+	in.DataPool.DeepCopyInto(&out.DataPool)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectZoneSpec.
+func (in *ObjectZoneSpec) DeepCopy() *ObjectZoneSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(ObjectZoneSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in DriveGroupsSpec) DeepCopyInto(out *DriveGroupsSpec) {
-	{
-		in := &in
-		*out = make(DriveGroupsSpec, len(*in))
+func (in *PlacementStorageClassSpec) DeepCopyInto(out *PlacementStorageClassSpec) {
+	*out = *in
+	in.DataPool.DeepCopyInto(&out.DataPool)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementStorageClassSpec.
+func (in *PlacementStorageClassSpec) DeepCopy() *PlacementStorageClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementStorageClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementTargetSpec) DeepCopyInto(out *PlacementTargetSpec) {
+	*out = *in
+	in.DataPool.DeepCopyInto(&out.DataPool)
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]PlacementStorageClassSpec, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementTargetSpec.
+func (in *PlacementTargetSpec) DeepCopy() *PlacementTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in PodSecurityContextsSpec) DeepCopyInto(out *PodSecurityContextsSpec) {
+	{
+		in := &in
+		*out = make(PodSecurityContextsSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
 		return
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriveGroupsSpec.
-func (in DriveGroupsSpec) DeepCopy() DriveGroupsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSecurityContextsSpec.
+func (in PodSecurityContextsSpec) DeepCopy() PodSecurityContextsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DriveGroupsSpec)
+	out := new(PodSecurityContextsSpec)
 	in.DeepCopyInto(out)
 	return *out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ErasureCodedSpec) DeepCopyInto(out *ErasureCodedSpec) {
+func (in *PoolCapacity) DeepCopyInto(out *PoolCapacity) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErasureCodedSpec.
-func (in *ErasureCodedSpec) DeepCopy() *ErasureCodedSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolCapacity.
+func (in *PoolCapacity) DeepCopy() *PoolCapacity {
 	if in == nil {
 		return nil
 	}
-	out := new(ErasureCodedSpec)
+	out := new(PoolCapacity)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalSpec) DeepCopyInto(out *ExternalSpec) {
+func (in *PoolDeletionStatus) DeepCopyInto(out *PoolDeletionStatus) {
 	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastProgressTime.DeepCopyInto(&out.LastProgressTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSpec.
-func (in *ExternalSpec) DeepCopy() *ExternalSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolDeletionStatus.
+func (in *PoolDeletionStatus) DeepCopy() *PoolDeletionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolDeletionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolErasureCodeStatus) DeepCopyInto(out *PoolErasureCodeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolErasureCodeStatus.
+func (in *PoolErasureCodeStatus) DeepCopy() *PoolErasureCodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolErasureCodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolReplicationStatus) DeepCopyInto(out *PoolReplicationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolReplicationStatus.
+func (in *PoolReplicationStatus) DeepCopy() *PoolReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
+	*out = *in
+	in.Replicated.DeepCopyInto(&out.Replicated)
+	out.ErasureCoded = in.ErasureCoded
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Quotas.DeepCopyInto(&out.Quotas)
+	if in.SnapshotSchedules != nil {
+		in, out := &in.SnapshotSchedules, &out.SnapshotSchedules
+		*out = make([]RBDScheduleSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrashPurgeSchedules != nil {
+		in, out := &in.TrashPurgeSchedules, &out.TrashPurgeSchedules
+		*out = make([]RBDScheduleSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolSpec.
+func (in *PoolSpec) DeepCopy() *PoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolUsageStatus) DeepCopyInto(out *PoolUsageStatus) {
+	*out = *in
+	if in.PGStates != nil {
+		in, out := &in.PGStates, &out.PGStates
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Replicated != nil {
+		in, out := &in.Replicated, &out.Replicated
+		*out = new(PoolReplicationStatus)
+		**out = **in
+	}
+	if in.ErasureCoded != nil {
+		in, out := &in.ErasureCoded, &out.ErasureCoded
+		*out = new(PoolErasureCodeStatus)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolUsageStatus.
+func (in *PoolUsageStatus) DeepCopy() *PoolUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PortRangeSpec) DeepCopyInto(out *PortRangeSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PortRangeSpec.
+func (in *PortRangeSpec) DeepCopy() *PortRangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PortRangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgressEventStatus) DeepCopyInto(out *ProgressEventStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgressEventStatus.
+func (in *ProgressEventStatus) DeepCopy() *ProgressEventStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgressEventStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusAlertOverride) DeepCopyInto(out *PrometheusAlertOverride) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusAlertOverride.
+func (in *PrometheusAlertOverride) DeepCopy() *PrometheusAlertOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusAlertOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusRulesSpec) DeepCopyInto(out *PrometheusRulesSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]PrometheusAlertOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Suppressed != nil {
+		in, out := &in.Suppressed, &out.Suppressed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusRulesSpec.
+func (in *PrometheusRulesSpec) DeepCopy() *PrometheusRulesSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalSpec)
+	out := new(PrometheusRulesSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FilesystemSpec) DeepCopyInto(out *FilesystemSpec) {
+func (in *ProtocolSpec) DeepCopyInto(out *ProtocolSpec) {
 	*out = *in
-	in.MetadataPool.DeepCopyInto(&out.MetadataPool)
-	if in.DataPools != nil {
-		in, out := &in.DataPools, &out.DataPools
-		*out = make([]PoolSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Spec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Swift != nil {
+		in, out := &in.Swift, &out.Swift
+		*out = new(SwiftSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	in.MetadataServer.DeepCopyInto(&out.MetadataServer)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSpec.
-func (in *FilesystemSpec) DeepCopy() *FilesystemSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtocolSpec.
+func (in *ProtocolSpec) DeepCopy() *ProtocolSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FilesystemSpec)
+	out := new(ProtocolSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GaneshaRADOSSpec) DeepCopyInto(out *GaneshaRADOSSpec) {
+func (in *PullSpec) DeepCopyInto(out *PullSpec) {
 	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalRealmSpec)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GaneshaRADOSSpec.
-func (in *GaneshaRADOSSpec) DeepCopy() *GaneshaRADOSSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullSpec.
+func (in *PullSpec) DeepCopy() *PullSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GaneshaRADOSSpec)
+	out := new(PullSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GaneshaServerSpec) DeepCopyInto(out *GaneshaServerSpec) {
+func (in *QuotaSpec) DeepCopyInto(out *QuotaSpec) {
 	*out = *in
-	in.Placement.DeepCopyInto(&out.Placement)
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(rookiov1.Annotations, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.MaxBytes != nil {
+		in, out := &in.MaxBytes, &out.MaxBytes
+		*out = new(uint64)
+		**out = **in
+	}
+	if in.MaxObjects != nil {
+		in, out := &in.MaxObjects, &out.MaxObjects
+		*out = new(uint64)
+		**out = **in
+	}
+	if in.WarningThresholdPercent != nil {
+		in, out := &in.WarningThresholdPercent, &out.WarningThresholdPercent
+		*out = new(int)
+		**out = **in
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GaneshaServerSpec.
-func (in *GaneshaServerSpec) DeepCopy() *GaneshaServerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaSpec.
+func (in *QuotaSpec) DeepCopy() *QuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GaneshaServerSpec)
+	out := new(QuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
+func (in *RBDMirroringSpec) DeepCopyInto(out *RBDMirroringSpec) {
 	*out = *in
 	in.Placement.DeepCopyInto(&out.Placement)
 	if in.Annotations != nil {
@@ -1306,231 +4001,210 @@ func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
 		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
-	if in.ExternalRgwEndpoints != nil {
-		in, out := &in.ExternalRgwEndpoints, &out.ExternalRgwEndpoints
-		*out = make([]corev1.EndpointAddress, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySpec.
-func (in *GatewaySpec) DeepCopy() *GatewaySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBDMirroringSpec.
+func (in *RBDMirroringSpec) DeepCopy() *RBDMirroringSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewaySpec)
+	out := new(RBDMirroringSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HealthCheckSpec) DeepCopyInto(out *HealthCheckSpec) {
+func (in *RBDScheduleSpec) DeepCopyInto(out *RBDScheduleSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckSpec.
-func (in *HealthCheckSpec) DeepCopy() *HealthCheckSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBDScheduleSpec.
+func (in *RBDScheduleSpec) DeepCopy() *RBDScheduleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HealthCheckSpec)
+	out := new(RBDScheduleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetadataServerSpec) DeepCopyInto(out *MetadataServerSpec) {
+func (in *RadosNamespaceMirroringSpec) DeepCopyInto(out *RadosNamespaceMirroringSpec) {
 	*out = *in
-	in.Placement.DeepCopyInto(&out.Placement)
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(rookiov1.Annotations, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	in.Resources.DeepCopyInto(&out.Resources)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataServerSpec.
-func (in *MetadataServerSpec) DeepCopy() *MetadataServerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RadosNamespaceMirroringSpec.
+func (in *RadosNamespaceMirroringSpec) DeepCopy() *RadosNamespaceMirroringSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MetadataServerSpec)
+	out := new(RadosNamespaceMirroringSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MgrSpec) DeepCopyInto(out *MgrSpec) {
+func (in *RadosNamespaceSpec) DeepCopyInto(out *RadosNamespaceSpec) {
 	*out = *in
-	if in.Modules != nil {
-		in, out := &in.Modules, &out.Modules
-		*out = make([]Module, len(*in))
-		copy(*out, *in)
+	if in.Mirroring != nil {
+		in, out := &in.Mirroring, &out.Mirroring
+		*out = new(RadosNamespaceMirroringSpec)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MgrSpec.
-func (in *MgrSpec) DeepCopy() *MgrSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RadosNamespaceSpec.
+func (in *RadosNamespaceSpec) DeepCopy() *RadosNamespaceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MgrSpec)
+	out := new(RadosNamespaceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Module) DeepCopyInto(out *Module) {
+func (in *RadosNamespaceStatus) DeepCopyInto(out *RadosNamespaceStatus) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Module.
-func (in *Module) DeepCopy() *Module {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RadosNamespaceStatus.
+func (in *RadosNamespaceStatus) DeepCopy() *RadosNamespaceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Module)
+	out := new(RadosNamespaceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonSpec) DeepCopyInto(out *MonSpec) {
+func (in *ReadAffinitySpec) DeepCopyInto(out *ReadAffinitySpec) {
 	*out = *in
-	if in.VolumeClaimTemplate != nil {
-		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
-		*out = new(corev1.PersistentVolumeClaim)
-		(*in).DeepCopyInto(*out)
+	if in.CrushLocationLabels != nil {
+		in, out := &in.CrushLocationLabels, &out.CrushLocationLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonSpec.
-func (in *MonSpec) DeepCopy() *MonSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadAffinitySpec.
+func (in *ReadAffinitySpec) DeepCopy() *ReadAffinitySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MonSpec)
+	out := new(ReadAffinitySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+func (in *ReplicatedSpec) DeepCopyInto(out *ReplicatedSpec) {
 	*out = *in
-	if in.ExternalMgrEndpoints != nil {
-		in, out := &in.ExternalMgrEndpoints, &out.ExternalMgrEndpoints
-		*out = make([]corev1.EndpointAddress, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.HybridStorage != nil {
+		in, out := &in.HybridStorage, &out.HybridStorage
+		*out = new(HybridStorageSpec)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
-func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicatedSpec.
+func (in *ReplicatedSpec) DeepCopy() *ReplicatedSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringSpec)
+	out := new(ReplicatedSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NFSGaneshaSpec) DeepCopyInto(out *NFSGaneshaSpec) {
+func (in *ResourceAutoscalingSpec) DeepCopyInto(out *ResourceAutoscalingSpec) {
 	*out = *in
-	out.RADOS = in.RADOS
-	in.Server.DeepCopyInto(&out.Server)
+	in.OSD.DeepCopyInto(&out.OSD)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSGaneshaSpec.
-func (in *NFSGaneshaSpec) DeepCopy() *NFSGaneshaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAutoscalingSpec.
+func (in *ResourceAutoscalingSpec) DeepCopy() *ResourceAutoscalingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NFSGaneshaSpec)
+	out := new(ResourceAutoscalingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+func (in *ResourcesStatus) DeepCopyInto(out *ResourcesStatus) {
 	*out = *in
-	in.NetworkSpec.DeepCopyInto(&out.NetworkSpec)
+	if in.NotReady != nil {
+		in, out := &in.NotReady, &out.NotReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
-func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcesStatus.
+func (in *ResourcesStatus) DeepCopy() *ResourcesStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkSpec)
+	out := new(ResourcesStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectRealmSpec) DeepCopyInto(out *ObjectRealmSpec) {
+func (in *ReweightByUtilizationSpec) DeepCopyInto(out *ReweightByUtilizationSpec) {
 	*out = *in
-	out.Pull = in.Pull
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectRealmSpec.
-func (in *ObjectRealmSpec) DeepCopy() *ObjectRealmSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReweightByUtilizationSpec.
+func (in *ReweightByUtilizationSpec) DeepCopy() *ReweightByUtilizationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectRealmSpec)
+	out := new(ReweightByUtilizationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStoreSpec) DeepCopyInto(out *ObjectStoreSpec) {
+func (in *S3Spec) DeepCopyInto(out *S3Spec) {
 	*out = *in
-	in.MetadataPool.DeepCopyInto(&out.MetadataPool)
-	in.DataPool.DeepCopyInto(&out.DataPool)
-	in.Gateway.DeepCopyInto(&out.Gateway)
-	out.Zone = in.Zone
-	in.HealthCheck.DeepCopyInto(&out.HealthCheck)
+	if in.AuthUseKeystone != nil {
+		in, out := &in.AuthUseKeystone, &out.AuthUseKeystone
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreSpec.
-func (in *ObjectStoreSpec) DeepCopy() *ObjectStoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Spec.
+func (in *S3Spec) DeepCopy() *S3Spec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStoreSpec)
+	out := new(S3Spec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStoreStatus) DeepCopyInto(out *ObjectStoreStatus) {
+func (in *STSRoleSpec) DeepCopyInto(out *STSRoleSpec) {
 	*out = *in
-	if in.BucketStatus != nil {
-		in, out := &in.BucketStatus, &out.BucketStatus
-		*out = new(BucketStatus)
-		**out = **in
-	}
-	if in.Info != nil {
-		in, out := &in.Info, &out.Info
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -1539,199 +4213,247 @@ func (in *ObjectStoreStatus) DeepCopyInto(out *ObjectStoreStatus) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreStatus.
-func (in *ObjectStoreStatus) DeepCopy() *ObjectStoreStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new STSRoleSpec.
+func (in *STSRoleSpec) DeepCopy() *STSRoleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStoreStatus)
+	out := new(STSRoleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStoreUserSpec) DeepCopyInto(out *ObjectStoreUserSpec) {
+func (in *STSSpec) DeepCopyInto(out *STSSpec) {
 	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]STSRoleSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OIDCProvider != nil {
+		in, out := &in.OIDCProvider, &out.OIDCProvider
+		*out = new(OIDCProviderSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreUserSpec.
-func (in *ObjectStoreUserSpec) DeepCopy() *ObjectStoreUserSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new STSSpec.
+func (in *STSSpec) DeepCopy() *STSSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStoreUserSpec)
+	out := new(STSSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStoreUserStatus) DeepCopyInto(out *ObjectStoreUserStatus) {
+func (in *SanitizeDisksSpec) DeepCopyInto(out *SanitizeDisksSpec) {
 	*out = *in
-	if in.Info != nil {
-		in, out := &in.Info, &out.Info
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStoreUserStatus.
-func (in *ObjectStoreUserStatus) DeepCopy() *ObjectStoreUserStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SanitizeDisksSpec.
+func (in *SanitizeDisksSpec) DeepCopy() *SanitizeDisksSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStoreUserStatus)
+	out := new(SanitizeDisksSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectZoneGroupSpec) DeepCopyInto(out *ObjectZoneGroupSpec) {
+func (in *ScrubbingSpec) DeepCopyInto(out *ScrubbingSpec) {
 	*out = *in
+	if in.BeginHour != nil {
+		in, out := &in.BeginHour, &out.BeginHour
+		*out = new(int)
+		**out = **in
+	}
+	if in.EndHour != nil {
+		in, out := &in.EndHour, &out.EndHour
+		*out = new(int)
+		**out = **in
+	}
+	if in.BeginWeekDay != nil {
+		in, out := &in.BeginWeekDay, &out.BeginWeekDay
+		*out = new(int)
+		**out = **in
+	}
+	if in.EndWeekDay != nil {
+		in, out := &in.EndWeekDay, &out.EndWeekDay
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxScrubsPerOSD != nil {
+		in, out := &in.MaxScrubsPerOSD, &out.MaxScrubsPerOSD
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectZoneGroupSpec.
-func (in *ObjectZoneGroupSpec) DeepCopy() *ObjectZoneGroupSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrubbingSpec.
+func (in *ScrubbingSpec) DeepCopy() *ScrubbingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectZoneGroupSpec)
+	out := new(ScrubbingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectZoneSpec) DeepCopyInto(out *ObjectZoneSpec) {
+func (in *SecuritySpec) DeepCopyInto(out *SecuritySpec) {
 	*out = *in
-	in.MetadataPool.DeepCopyInto(&out.MetadataPool)
-	in.DataPool.DeepCopyInto(&out.DataPool)
+	in.KeyManagementService.DeepCopyInto(&out.KeyManagementService)
+	out.KeyRotation = in.KeyRotation
+	in.CephxKeyRotation.DeepCopyInto(&out.CephxKeyRotation)
+	in.TLS.DeepCopyInto(&out.TLS)
+	if in.PodSecurityContexts != nil {
+		in, out := &in.PodSecurityContexts, &out.PodSecurityContexts
+		*out = make(PodSecurityContextsSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectZoneSpec.
-func (in *ObjectZoneSpec) DeepCopy() *ObjectZoneSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecuritySpec.
+func (in *SecuritySpec) DeepCopy() *SecuritySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectZoneSpec)
+	out := new(SecuritySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
+func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
-	out.Replicated = in.Replicated
-	out.ErasureCoded = in.ErasureCoded
-	if in.Parameters != nil {
-		in, out := &in.Parameters, &out.Parameters
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolSpec.
-func (in *PoolSpec) DeepCopy() *PoolSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
+func (in *Status) DeepCopy() *Status {
 	if in == nil {
 		return nil
 	}
-	out := new(PoolSpec)
+	out := new(Status)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullSpec) DeepCopyInto(out *PullSpec) {
+func (in *SwiftSpec) DeepCopyInto(out *SwiftSpec) {
 	*out = *in
+	if in.AccountInURL != nil {
+		in, out := &in.AccountInURL, &out.AccountInURL
+		*out = new(bool)
+		**out = **in
+	}
+	if in.URLPrefix != nil {
+		in, out := &in.URLPrefix, &out.URLPrefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.VersioningEnabled != nil {
+		in, out := &in.VersioningEnabled, &out.VersioningEnabled
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullSpec.
-func (in *PullSpec) DeepCopy() *PullSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwiftSpec.
+func (in *SwiftSpec) DeepCopy() *SwiftSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PullSpec)
+	out := new(SwiftSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RBDMirroringSpec) DeepCopyInto(out *RBDMirroringSpec) {
+func (in *TLSSpec) DeepCopyInto(out *TLSSpec) {
 	*out = *in
-	in.Placement.DeepCopyInto(&out.Placement)
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(rookiov1.Annotations, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.CertManagerIssuerRef != nil {
+		in, out := &in.CertManagerIssuerRef, &out.CertManagerIssuerRef
+		*out = new(CertManagerIssuerRef)
+		**out = **in
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RBDMirroringSpec.
-func (in *RBDMirroringSpec) DeepCopy() *RBDMirroringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSSpec.
+func (in *TLSSpec) DeepCopy() *TLSSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RBDMirroringSpec)
+	out := new(TLSSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReplicatedSpec) DeepCopyInto(out *ReplicatedSpec) {
+func (in *ToolboxSpec) DeepCopyInto(out *ToolboxSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicatedSpec.
-func (in *ReplicatedSpec) DeepCopy() *ReplicatedSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolboxSpec.
+func (in *ToolboxSpec) DeepCopy() *ToolboxSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ReplicatedSpec)
+	out := new(ToolboxSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SanitizeDisksSpec) DeepCopyInto(out *SanitizeDisksSpec) {
+func (in *UpgradeChecksSpec) DeepCopyInto(out *UpgradeChecksSpec) {
 	*out = *in
+	if in.Daemons != nil {
+		in, out := &in.Daemons, &out.Daemons
+		*out = make(map[string]DaemonUpgradeChecksSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SanitizeDisksSpec.
-func (in *SanitizeDisksSpec) DeepCopy() *SanitizeDisksSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeChecksSpec.
+func (in *UpgradeChecksSpec) DeepCopy() *UpgradeChecksSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SanitizeDisksSpec)
+	out := new(UpgradeChecksSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Status) DeepCopyInto(out *Status) {
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
-func (in *Status) DeepCopy() *Status {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Status)
+	out := new(UpgradeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
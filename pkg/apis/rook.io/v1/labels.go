@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (a LabelsSpec) All() Labels {
+	return a[KeyAll]
+}
+
+// ApplyToObjectMeta adds labels to object meta unless the keys are already set. The labels of the object are copied
+// so that the selectors sharing the same map are not changed.
+func (a Labels) ApplyToObjectMeta(t *metav1.ObjectMeta) {
+	if len(a) == 0 {
+		return
+	}
+	labels := map[string]string{}
+	for k, v := range t.Labels {
+		labels[k] = v
+	}
+	for k, v := range a {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	t.Labels = labels
+}
+
+// Merge returns the labels with the labels of the supplied Labels whose keys are not set yet
+func (a Labels) Merge(with Labels) Labels {
+	ret := Labels{}
+	for k, v := range a {
+		ret[k] = v
+	}
+	for k, v := range with {
+		if _, ok := ret[k]; !ok {
+			ret[k] = v
+		}
+	}
+	return ret
+}
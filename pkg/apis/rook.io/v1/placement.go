@@ -46,24 +46,103 @@ func (p Placement) ApplyToPodSpec(t *v1.PodSpec) {
 }
 
 // Merge returns a Placement which results from merging the attributes of the
-// original Placement with the attributes of the supplied one. The supplied
-// Placement's attributes will override the original ones if defined.
+// original Placement with the attributes of the supplied one, so that the
+// supplied Placement takes precedence. The nodes must match the required node
+// selector terms of both placements, the preferred node terms and the pod
+// (anti) affinity terms are combined. A toleration or a topology spread
+// constraint of the supplied Placement replaces the original one with the same
+// key and effect, or with the same topology key.
 func (p Placement) Merge(with Placement) Placement {
 	ret := p
-	if with.NodeAffinity != nil {
-		ret.NodeAffinity = with.NodeAffinity
-	}
+	ret.NodeAffinity = mergeNodeAffinity(p.NodeAffinity, with.NodeAffinity)
 	if with.PodAffinity != nil {
-		ret.PodAffinity = with.PodAffinity
+		ret.PodAffinity = &v1.PodAffinity{}
+		if p.PodAffinity != nil {
+			ret.PodAffinity = p.PodAffinity.DeepCopy()
+		}
+		ret.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(ret.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			with.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		ret.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(ret.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			with.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
 	}
 	if with.PodAntiAffinity != nil {
-		ret.PodAntiAffinity = with.PodAntiAffinity
+		ret.PodAntiAffinity = &v1.PodAntiAffinity{}
+		if p.PodAntiAffinity != nil {
+			ret.PodAntiAffinity = p.PodAntiAffinity.DeepCopy()
+		}
+		ret.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(ret.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			with.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+		ret.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(ret.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			with.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
 	}
 	if with.Tolerations != nil {
-		ret.Tolerations = with.Tolerations
+		ret.Tolerations = []v1.Toleration{}
+		for _, t := range p.Tolerations {
+			if !hasToleration(with.Tolerations, t.Key, t.Effect) {
+				ret.Tolerations = append(ret.Tolerations, t)
+			}
+		}
+		ret.Tolerations = append(ret.Tolerations, with.Tolerations...)
 	}
 	if with.TopologySpreadConstraints != nil {
-		ret.TopologySpreadConstraints = with.TopologySpreadConstraints
+		ret.TopologySpreadConstraints = []v1.TopologySpreadConstraint{}
+		for _, c := range p.TopologySpreadConstraints {
+			if !hasTopologySpreadConstraint(with.TopologySpreadConstraints, c.TopologyKey) {
+				ret.TopologySpreadConstraints = append(ret.TopologySpreadConstraints, c)
+			}
+		}
+		ret.TopologySpreadConstraints = append(ret.TopologySpreadConstraints, with.TopologySpreadConstraints...)
 	}
 	return ret
 }
+
+// mergeNodeAffinity returns the node affinity requiring the nodes to match the required terms of both affinities.
+// Since the terms of a node selector are ORed, each term of the result is the union of a term of each affinity.
+func mergeNodeAffinity(a, with *v1.NodeAffinity) *v1.NodeAffinity {
+	if with == nil {
+		return a
+	}
+	if a == nil {
+		return with
+	}
+	ret := a.DeepCopy()
+	ret.PreferredDuringSchedulingIgnoredDuringExecution = append(ret.PreferredDuringSchedulingIgnoredDuringExecution,
+		with.PreferredDuringSchedulingIgnoredDuringExecution...)
+	required := with.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		return ret
+	}
+	if ret.RequiredDuringSchedulingIgnoredDuringExecution == nil || len(ret.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
+		ret.RequiredDuringSchedulingIgnoredDuringExecution = required.DeepCopy()
+		return ret
+	}
+	terms := []v1.NodeSelectorTerm{}
+	for _, term := range ret.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, withTerm := range required.NodeSelectorTerms {
+			merged := *term.DeepCopy()
+			merged.MatchExpressions = append(merged.MatchExpressions, withTerm.MatchExpressions...)
+			merged.MatchFields = append(merged.MatchFields, withTerm.MatchFields...)
+			terms = append(terms, merged)
+		}
+	}
+	ret.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = terms
+	return ret
+}
+
+func hasToleration(tolerations []v1.Toleration, key string, effect v1.TaintEffect) bool {
+	for _, t := range tolerations {
+		if t.Key == key && t.Effect == effect {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTopologySpreadConstraint(constraints []v1.TopologySpreadConstraint, topologyKey string) bool {
+	for _, c := range constraints {
+		if c.TopologyKey == topologyKey {
+			return true
+		}
+	}
+	return false
+}
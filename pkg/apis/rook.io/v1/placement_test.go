@@ -191,11 +191,51 @@ func TestPlacementMerge(t *testing.T) {
 	}
 	expected = Placement{
 		NodeAffinity:              na,
-		Tolerations:               to,
-		TopologySpreadConstraints: tc,
+		Tolerations:               append(placementTestGetTolerations("bar", "baz"), to...),
+		TopologySpreadConstraints: append(placementTestGetTopologySpreadConstraints("rack"), tc...),
+	}
+	merged = original.Merge(with)
+	assert.Equal(t, expected, merged)
+
+	// the tolerations and constraints with the same key are overridden
+	original = Placement{
+		Tolerations:               placementTestGetTolerations("foo", "baz"),
+		TopologySpreadConstraints: placementTestGetTopologySpreadConstraints("zone"),
 	}
+	with = Placement{Tolerations: to, TopologySpreadConstraints: tc}
+	expected = Placement{Tolerations: to, TopologySpreadConstraints: tc}
 	merged = original.Merge(with)
 	assert.Equal(t, expected, merged)
+
+	// the pod anti affinity terms are combined
+	original = Placement{PodAntiAffinity: placementAntiAffinity("mon")}
+	with = Placement{PodAntiAffinity: placementAntiAffinity("mgr")}
+	merged = original.Merge(with)
+	assert.Equal(t, 2, len(merged.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution))
+	assert.Equal(t, 1, len(original.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution))
+}
+
+func TestPlacementMergeNodeAffinity(t *testing.T) {
+	term := func(key string, values ...string) v1.NodeSelectorTerm {
+		return v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: key, Operator: v1.NodeSelectorOpIn, Values: values}}}
+	}
+	affinity := func(terms ...v1.NodeSelectorTerm) *v1.NodeAffinity {
+		return &v1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms}}
+	}
+	original := Placement{NodeAffinity: affinity(term("role", "storage"), term("role", "ceph"))}
+	with := Placement{NodeAffinity: affinity(term("zone", "a"))}
+
+	// the nodes must match the terms of both placements
+	merged := original.Merge(with)
+	terms := merged.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, 2, len(terms))
+	assert.Equal(t, []v1.NodeSelectorRequirement{term("role", "storage").MatchExpressions[0], term("zone", "a").MatchExpressions[0]}, terms[0].MatchExpressions)
+	assert.Equal(t, []v1.NodeSelectorRequirement{term("role", "ceph").MatchExpressions[0], term("zone", "a").MatchExpressions[0]}, terms[1].MatchExpressions)
+	// the original placement is not modified
+	assert.Equal(t, 1, len(original.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions))
+
+	merged = Placement{}.Merge(with)
+	assert.Equal(t, with.NodeAffinity, merged.NodeAffinity)
 }
 
 func placementTestGetTolerations(key, value string) []v1.Toleration {
@@ -34,6 +34,21 @@ type StorageScopeSpec struct {
 	Selection
 	VolumeSources          []VolumeSource          `json:"volumeSources,omitempty"`
 	StorageClassDeviceSets []StorageClassDeviceSet `json:"storageClassDeviceSets"`
+	// Store is the object store of the OSDs, which are re-provisioned when it changes
+	Store OSDStore `json:"store,omitempty"`
+}
+
+// OSDStore is the object store and the bluestore settings of the OSDs
+type OSDStore struct {
+	// Type is the object store of the OSDs, bluestore or bluestore-rdr. Defaults to bluestore.
+	Type string `json:"type,omitempty"`
+	// Settings are the bluestore settings applied when the OSDs are created, such as bluestore_min_alloc_size
+	Settings map[string]string `json:"settings,omitempty"`
+	// UpdateStore must be set to "yes-really-update-store" for the OSDs which do not have the type or the settings of
+	// the store to be re-provisioned
+	UpdateStore string `json:"updateStore,omitempty"`
+	// FailureDomain is the CRUSH type of the buckets whose OSDs are re-provisioned together. Defaults to host.
+	FailureDomain string `json:"failureDomain,omitempty"`
 }
 
 type Node struct {
@@ -107,6 +122,12 @@ type AnnotationsSpec map[KeyType]Annotations
 
 type Annotations map[string]string
 
+// LabelsSpec is the main spec label for all daemons
+type LabelsSpec map[KeyType]Labels
+
+// Labels are the labels of the objects of a daemon
+type Labels map[string]string
+
 type StorageClassDeviceSet struct {
 	Name                 string                     `json:"name,omitempty"`                 // A unique identifier for the set
 	Count                int                        `json:"count,omitempty"`                // Number of devices in this set
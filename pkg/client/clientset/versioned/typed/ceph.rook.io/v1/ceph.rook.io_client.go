@@ -27,8 +27,11 @@ import (
 type CephV1Interface interface {
 	RESTClient() rest.Interface
 	CephBlockPoolsGetter
+	CephBlockPoolRadosNamespacesGetter
 	CephClientsGetter
 	CephClustersGetter
+	CephCommandJobsGetter
+	CephDeviceInventoriesGetter
 	CephFilesystemsGetter
 	CephNFSesGetter
 	CephObjectRealmsGetter
@@ -48,6 +51,10 @@ func (c *CephV1Client) CephBlockPools(namespace string) CephBlockPoolInterface {
 	return newCephBlockPools(c, namespace)
 }
 
+func (c *CephV1Client) CephBlockPoolRadosNamespaces(namespace string) CephBlockPoolRadosNamespaceInterface {
+	return newCephBlockPoolRadosNamespaces(c, namespace)
+}
+
 func (c *CephV1Client) CephClients(namespace string) CephClientInterface {
 	return newCephClients(c, namespace)
 }
@@ -56,6 +63,14 @@ func (c *CephV1Client) CephClusters(namespace string) CephClusterInterface {
 	return newCephClusters(c, namespace)
 }
 
+func (c *CephV1Client) CephCommandJobs(namespace string) CephCommandJobInterface {
+	return newCephCommandJobs(c, namespace)
+}
+
+func (c *CephV1Client) CephDeviceInventories(namespace string) CephDeviceInventoryInterface {
+	return newCephDeviceInventories(c, namespace)
+}
+
 func (c *CephV1Client) CephFilesystems(namespace string) CephFilesystemInterface {
 	return newCephFilesystems(c, namespace)
 }
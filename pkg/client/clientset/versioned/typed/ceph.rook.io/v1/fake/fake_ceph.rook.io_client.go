@@ -32,6 +32,10 @@ func (c *FakeCephV1) CephBlockPools(namespace string) v1.CephBlockPoolInterface
 	return &FakeCephBlockPools{c, namespace}
 }
 
+func (c *FakeCephV1) CephBlockPoolRadosNamespaces(namespace string) v1.CephBlockPoolRadosNamespaceInterface {
+	return &FakeCephBlockPoolRadosNamespaces{c, namespace}
+}
+
 func (c *FakeCephV1) CephClients(namespace string) v1.CephClientInterface {
 	return &FakeCephClients{c, namespace}
 }
@@ -40,6 +44,14 @@ func (c *FakeCephV1) CephClusters(namespace string) v1.CephClusterInterface {
 	return &FakeCephClusters{c, namespace}
 }
 
+func (c *FakeCephV1) CephCommandJobs(namespace string) v1.CephCommandJobInterface {
+	return &FakeCephCommandJobs{c, namespace}
+}
+
+func (c *FakeCephV1) CephDeviceInventories(namespace string) v1.CephDeviceInventoryInterface {
+	return &FakeCephDeviceInventories{c, namespace}
+}
+
 func (c *FakeCephV1) CephFilesystems(namespace string) v1.CephFilesystemInterface {
 	return &FakeCephFilesystems{c, namespace}
 }
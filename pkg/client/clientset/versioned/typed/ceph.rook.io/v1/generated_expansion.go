@@ -20,10 +20,16 @@ package v1
 
 type CephBlockPoolExpansion interface{}
 
+type CephBlockPoolRadosNamespaceExpansion interface{}
+
 type CephClientExpansion interface{}
 
 type CephClusterExpansion interface{}
 
+type CephCommandJobExpansion interface{}
+
+type CephDeviceInventoryExpansion interface{}
+
 type CephFilesystemExpansion interface{}
 
 type CephNFSExpansion interface{}
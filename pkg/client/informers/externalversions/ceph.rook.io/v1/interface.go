@@ -26,10 +26,16 @@ import (
 type Interface interface {
 	// CephBlockPools returns a CephBlockPoolInformer.
 	CephBlockPools() CephBlockPoolInformer
+	// CephBlockPoolRadosNamespaces returns a CephBlockPoolRadosNamespaceInformer.
+	CephBlockPoolRadosNamespaces() CephBlockPoolRadosNamespaceInformer
 	// CephClients returns a CephClientInformer.
 	CephClients() CephClientInformer
 	// CephClusters returns a CephClusterInformer.
 	CephClusters() CephClusterInformer
+	// CephCommandJobs returns a CephCommandJobInformer.
+	CephCommandJobs() CephCommandJobInformer
+	// CephDeviceInventories returns a CephDeviceInventoryInformer.
+	CephDeviceInventories() CephDeviceInventoryInformer
 	// CephFilesystems returns a CephFilesystemInformer.
 	CephFilesystems() CephFilesystemInformer
 	// CephNFSes returns a CephNFSInformer.
@@ -64,6 +70,11 @@ func (v *version) CephBlockPools() CephBlockPoolInformer {
 	return &cephBlockPoolInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephBlockPoolRadosNamespaces returns a CephBlockPoolRadosNamespaceInformer.
+func (v *version) CephBlockPoolRadosNamespaces() CephBlockPoolRadosNamespaceInformer {
+	return &cephBlockPoolRadosNamespaceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephClients returns a CephClientInformer.
 func (v *version) CephClients() CephClientInformer {
 	return &cephClientInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -74,6 +85,16 @@ func (v *version) CephClusters() CephClusterInformer {
 	return &cephClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// CephCommandJobs returns a CephCommandJobInformer.
+func (v *version) CephCommandJobs() CephCommandJobInformer {
+	return &cephCommandJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// CephDeviceInventories returns a CephDeviceInventoryInformer.
+func (v *version) CephDeviceInventories() CephDeviceInventoryInformer {
+	return &cephDeviceInventoryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CephFilesystems returns a CephFilesystemInformer.
 func (v *version) CephFilesystems() CephFilesystemInformer {
 	return &cephFilesystemInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -65,10 +65,16 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		// Group=ceph.rook.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("cephblockpools"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephBlockPools().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephblockpoolradosnamespaces"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephBlockPoolRadosNamespaces().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephclients"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephClients().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephclusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephClusters().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephcommandjobs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephCommandJobs().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("cephdeviceinventories"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephDeviceInventories().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephfilesystems"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Ceph().V1().CephFilesystems().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("cephnfses"):
@@ -26,6 +26,14 @@ type CephBlockPoolListerExpansion interface{}
 // CephBlockPoolNamespaceLister.
 type CephBlockPoolNamespaceListerExpansion interface{}
 
+// CephBlockPoolRadosNamespaceListerExpansion allows custom methods to be added to
+// CephBlockPoolRadosNamespaceLister.
+type CephBlockPoolRadosNamespaceListerExpansion interface{}
+
+// CephBlockPoolRadosNamespaceNamespaceListerExpansion allows custom methods to be added to
+// CephBlockPoolRadosNamespaceNamespaceLister.
+type CephBlockPoolRadosNamespaceNamespaceListerExpansion interface{}
+
 // CephClientListerExpansion allows custom methods to be added to
 // CephClientLister.
 type CephClientListerExpansion interface{}
@@ -42,6 +50,22 @@ type CephClusterListerExpansion interface{}
 // CephClusterNamespaceLister.
 type CephClusterNamespaceListerExpansion interface{}
 
+// CephCommandJobListerExpansion allows custom methods to be added to
+// CephCommandJobLister.
+type CephCommandJobListerExpansion interface{}
+
+// CephCommandJobNamespaceListerExpansion allows custom methods to be added to
+// CephCommandJobNamespaceLister.
+type CephCommandJobNamespaceListerExpansion interface{}
+
+// CephDeviceInventoryListerExpansion allows custom methods to be added to
+// CephDeviceInventoryLister.
+type CephDeviceInventoryListerExpansion interface{}
+
+// CephDeviceInventoryNamespaceListerExpansion allows custom methods to be added to
+// CephDeviceInventoryNamespaceLister.
+type CephDeviceInventoryNamespaceListerExpansion interface{}
+
 // CephFilesystemListerExpansion allows custom methods to be added to
 // CephFilesystemLister.
 type CephFilesystemListerExpansion interface{}
@@ -16,10 +16,16 @@ limitations under the License.
 package client
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
 )
 
 // AuthGetOrCreate will either get or create a user with the given capabilities.  The keyring for the
@@ -105,6 +111,66 @@ func AuthGetCaps(context *clusterd.Context, clusterInfo *ClusterInfo, name strin
 	return caps, err
 }
 
+// AuthEntity is a Ceph user with its key and its capabilities
+type AuthEntity struct {
+	Entity string            `json:"entity"`
+	Key    string            `json:"key"`
+	Caps   map[string]string `json:"caps"`
+}
+
+// AuthGet gets the key and the capabilities of the given user.
+func AuthGet(context *clusterd.Context, clusterInfo *ClusterInfo, name string) (*AuthEntity, error) {
+	logger.Infof("getting ceph auth %q", name)
+	args := []string{"auth", "get", name}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get auth for %q", name)
+	}
+
+	var entities []AuthEntity
+	if err := json.Unmarshal(output, &entities); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal auth get response")
+	}
+	if len(entities) != 1 {
+		return nil, errors.Errorf("expected one user %q in auth get response, found %d", name, len(entities))
+	}
+	return &entities[0], nil
+}
+
+// AuthImport imports the users of the given keyring file. The keys and the capabilities of the existing users are
+// replaced by the ones of the keyring.
+func AuthImport(context *clusterd.Context, clusterInfo *ClusterInfo, keyringPath string) error {
+	logger.Infof("importing ceph auth keyring %q", keyringPath)
+	args := []string{"auth", "import", "-i", keyringPath}
+	_, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to import auth keyring %q", keyringPath)
+	}
+	return nil
+}
+
+// GenerateAuthKey generates a new cephx key the way `ceph-authtool --gen-print-key` does. The key is the base64
+// encoding of the key type, the creation time, the length and the random bytes of the secret.
+func GenerateAuthKey() (string, error) {
+	const (
+		cryptoAES    = 1
+		secretLength = 16
+	)
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "failed to generate cephx secret")
+	}
+
+	now := time.Now()
+	key := make([]byte, 12, 12+secretLength)
+	binary.LittleEndian.PutUint16(key[0:], cryptoAES)
+	binary.LittleEndian.PutUint32(key[2:], uint32(now.Unix()))
+	binary.LittleEndian.PutUint32(key[6:], uint32(now.Nanosecond()))
+	binary.LittleEndian.PutUint16(key[10:], secretLength)
+	key = append(key, secret...)
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
 // AuthDelete will delete the given user.
 func AuthDelete(context *clusterd.Context, clusterInfo *ClusterInfo, name string) error {
 	logger.Infof("deleting ceph auth %q", name)
@@ -116,6 +182,12 @@ func AuthDelete(context *clusterd.Context, clusterInfo *ClusterInfo, name string
 	return nil
 }
 
+// IsPermissionDenied returns whether a ceph command failed because the caps of the user do not allow it
+func IsPermissionDenied(err error) bool {
+	code, ok := exec.ExitStatus(errors.Cause(err))
+	return ok && code == int(syscall.EACCES)
+}
+
 func parseAuthKey(buf []byte) (string, error) {
 	var resp map[string]interface{}
 	if err := json.Unmarshal(buf, &resp); err != nil {
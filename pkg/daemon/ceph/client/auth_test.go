@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAuthKey(t *testing.T) {
+	key, err := GenerateAuthKey()
+	assert.NoError(t, err)
+	// the keys generated by ceph-authtool are 40 characters starting with "AQ"
+	assert.Equal(t, 40, len(key))
+	assert.True(t, strings.HasPrefix(key, "AQ"))
+	raw, err := base64.StdEncoding.DecodeString(key)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(16), raw[10])
+
+	other, err := GenerateAuthKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestAuthGet(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			assert.Equal(t, []string{"auth", "get", "mgr.a"}, args[0:3])
+			return `[{"entity":"mgr.a","key":"AQBkey==","caps":{"mds":"allow *","mon":"allow profile mgr","osd":"allow *"}}]`, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	entity, err := AuthGet(context, AdminClusterInfo("ns"), "mgr.a")
+	assert.NoError(t, err)
+	assert.Equal(t, "mgr.a", entity.Entity)
+	assert.Equal(t, "AQBkey==", entity.Key)
+	assert.Equal(t, "allow profile mgr", entity.Caps["mon"])
+}
@@ -115,6 +115,12 @@ func NewRBDCommand(context *clusterd.Context, clusterInfo *ClusterInfo, args []s
 }
 
 func (c *CephToolCommand) run() ([]byte, error) {
+	start := time.Now()
+	if output, ok, err := c.runOnConn(); ok {
+		recordCommandDuration(c.tool, c.args, start, err)
+		return output, err
+	}
+
 	command, args := FinalizeCephCommandArgs(c.tool, c.clusterInfo, c.args, c.context.ConfigDir)
 	if c.JsonOutput {
 		args = append(args, "--format", "json")
@@ -128,6 +134,7 @@ func (c *CephToolCommand) run() ([]byte, error) {
 	var output string
 	var err error
 
+	defer func() { recordCommandDuration(c.tool, c.args, start, err) }()
 	if c.OutputFile {
 		if command == Kubectl {
 			// Kubectl commands targeting the toolbox container generate a temp
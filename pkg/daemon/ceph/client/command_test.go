@@ -19,6 +19,10 @@ package client
 import (
 	"testing"
 
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -95,3 +99,21 @@ func TestFinalizeCephCommandArgsToolBox(t *testing.T) {
 	assert.Exactly(t, expectedArgs, args)
 	RunAllCephCommandsInToolbox = false
 }
+
+func TestCommandDurationMetric(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			return "", errors.New("failed")
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	commandDuration.Reset()
+
+	// the commands are counted by their first argument
+	_, err := NewCephCommand(context, AdminClusterInfo("rook-ceph"), []string{"osd", "dump"}).Run()
+	assert.Error(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(commandDuration))
+	_, err = NewCephCommand(context, AdminClusterInfo("rook-ceph"), []string{"osd", "tree"}).Run()
+	assert.Error(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(commandDuration))
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/coreos/pkg/capnslog"
 	"github.com/go-ini/ini"
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephutil "github.com/rook/rook/pkg/daemon/ceph/util"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
@@ -63,6 +64,8 @@ type GlobalConfig struct {
 	PublicNetwork  string `ini:"public network,omitempty"`
 	ClusterAddr    string `ini:"cluster addr,omitempty"`
 	ClusterNetwork string `ini:"cluster network,omitempty"`
+	MsBindIPv4     string `ini:"ms bind ipv4,omitempty"`
+	MsBindIPv6     string `ini:"ms bind ipv6,omitempty"`
 }
 
 // CephConfig represents an entire Ceph config including all sections.
@@ -157,6 +160,7 @@ func CreateDefaultCephConfig(context *clusterd.Context, clusterInfo *ClusterInfo
 	// extract a list of just the monitor names, which will populate the "mon initial members"
 	// and "mon hosts" global config field
 	monMembers, monHosts := PopulateMonHostMembers(clusterInfo.Monitors)
+	msBindIPv4, msBindIPv6 := MsBindSettings(clusterInfo.NetworkSpec)
 
 	conf := &CephConfig{
 		GlobalConfig: &GlobalConfig{
@@ -167,12 +171,26 @@ func CreateDefaultCephConfig(context *clusterd.Context, clusterInfo *ClusterInfo
 			PublicNetwork:  context.NetworkInfo.PublicNetwork,
 			ClusterAddr:    context.NetworkInfo.ClusterAddr,
 			ClusterNetwork: context.NetworkInfo.ClusterNetwork,
+			MsBindIPv4:     msBindIPv4,
+			MsBindIPv6:     msBindIPv6,
 		},
 	}
 
 	return conf, nil
 }
 
+// MsBindSettings returns the values of "ms bind ipv4" and "ms bind ipv6" for the IP family of the network. They are
+// empty for the IPv4 single stack, which is the default of ceph.
+func MsBindSettings(networkSpec cephv1.NetworkSpec) (string, string) {
+	switch {
+	case networkSpec.DualStack:
+		return "true", "true"
+	case networkSpec.IsIPv6():
+		return "false", "true"
+	}
+	return "", ""
+}
+
 // create a config file with global settings configured, and return an ini file
 func createGlobalConfigFileSection(context *clusterd.Context, clusterInfo *ClusterInfo, userConfig *CephConfig) (*ini.File, error) {
 
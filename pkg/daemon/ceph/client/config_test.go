@@ -25,6 +25,7 @@ import (
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/go-ini/ini"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/stretchr/testify/assert"
@@ -73,6 +74,19 @@ func TestCreateDefaultCephConfig(t *testing.T) {
 	assert.Equal(t, "10.1.1.0/24", cephConfig.PublicNetwork)
 	assert.Equal(t, "10.1.2.2", cephConfig.ClusterAddr)
 	assert.Equal(t, "10.1.2.0/24", cephConfig.ClusterNetwork)
+	assert.Equal(t, "", cephConfig.MsBindIPv6)
+
+	// the daemons bind to the ip family of the network
+	clusterInfo.NetworkSpec = cephv1.NetworkSpec{IPFamily: cephv1.IPv6}
+	cephConfig, err = CreateDefaultCephConfig(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "false", cephConfig.MsBindIPv4)
+	assert.Equal(t, "true", cephConfig.MsBindIPv6)
+	clusterInfo.NetworkSpec.DualStack = true
+	cephConfig, err = CreateDefaultCephConfig(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", cephConfig.MsBindIPv4)
+	assert.Equal(t, "true", cephConfig.MsBindIPv6)
 }
 
 func TestGenerateConfigFile(t *testing.T) {
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// ConfigKeyList lists the keys of the config-key store of the mons
+func ConfigKeyList(context *clusterd.Context, clusterInfo *ClusterInfo) ([]string, error) {
+	args := []string{"config-key", "ls"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list config keys")
+	}
+
+	var keys []string
+	if err := json.Unmarshal(buf, &keys); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal config-key ls response. %s", string(buf))
+	}
+	return keys, nil
+}
+
+// ConfigKeyGet gets the value of a key of the config-key store of the mons
+func ConfigKeyGet(context *clusterd.Context, clusterInfo *ClusterInfo, key string) (string, error) {
+	args := []string{"config-key", "get", key}
+	cmd := NewCephCommand(context, clusterInfo, args)
+	cmd.JsonOutput = false
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get config key %q", key)
+	}
+	return string(buf), nil
+}
+
+// ConfigKeySet sets the value of a key of the config-key store of the mons
+func ConfigKeySet(context *clusterd.Context, clusterInfo *ClusterInfo, key, value string) error {
+	args := []string{"config-key", "set", key, value}
+	cmd := NewCephCommand(context, clusterInfo, args)
+	cmd.JsonOutput = false
+	if _, err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to set config key %q", key)
+	}
+	return nil
+}
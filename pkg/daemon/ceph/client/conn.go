@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+var (
+	// UseCommandConnection runs the ceph commands of each cluster on a connection kept open between the commands when
+	// rook is built with a backend of the connections
+	UseCommandConnection = false
+
+	// NewCommandConn opens the connection of a cluster with the credentials of the cluster info. It is set by the
+	// librados backend when rook is built with the "rados" tag, the ceph commands are run with the CLI otherwise.
+	NewCommandConn func(context *clusterd.Context, clusterInfo *ClusterInfo) (CommandConn, error)
+
+	// ErrConnectionLost is returned by a connection which is not connected to the cluster anymore. The command is run
+	// with the CLI and the connection is reopened by the next command.
+	ErrConnectionLost = errors.New("connection to the cluster lost")
+)
+
+// CommandConn runs the ceph commands of a cluster on a connection to the mons and the mgr kept open between the
+// commands, instead of spawning the ceph CLI which connects to the cluster for each command
+type CommandConn interface {
+	// MonCommand runs a command of the mons given in json and returns its output
+	MonCommand(command []byte) ([]byte, error)
+	// MgrCommand runs a command of the mgr given in json and returns its output
+	MgrCommand(command []byte) ([]byte, error)
+	// Close closes the connection
+	Close()
+}
+
+// connCommand is a command run on the connection, the other commands are run with the CLI
+type connCommand struct {
+	prefix string
+	// mgr is whether the command is served by the mgr instead of the mons
+	mgr bool
+	// args are the arguments of the command besides its prefix
+	args map[string]string
+}
+
+// connCommands are the commands run on the connection by their CLI arguments. They are the read-only commands polled
+// by the operator, which take no argument from the caller.
+var connCommands = map[string]connCommand{
+	"status":                      {prefix: "status"},
+	"health":                      {prefix: "health"},
+	"health detail":               {prefix: "health", args: map[string]string{"detail": "detail"}},
+	"quorum_status":               {prefix: "quorum_status"},
+	"version":                     {prefix: "version"},
+	"versions":                    {prefix: "versions"},
+	"mon dump":                    {prefix: "mon dump"},
+	"mgr dump":                    {prefix: "mgr dump"},
+	"mgr services":                {prefix: "mgr services"},
+	"fs ls":                       {prefix: "fs ls"},
+	"osd dump":                    {prefix: "osd dump"},
+	"osd ls":                      {prefix: "osd ls"},
+	"osd lspools":                 {prefix: "osd lspools"},
+	"osd tree":                    {prefix: "osd tree"},
+	"osd metadata":                {prefix: "osd metadata"},
+	"osd crush dump":              {prefix: "osd crush dump"},
+	"osd crush class ls":          {prefix: "osd crush class ls"},
+	"osd erasure-code-profile ls": {prefix: "osd erasure-code-profile ls"},
+	"osd df":                      {prefix: "osd df", mgr: true},
+	"osd perf":                    {prefix: "osd perf", mgr: true},
+}
+
+// the connections of the clusters, by namespace and user
+var (
+	commandConns      = map[string]CommandConn{}
+	commandConnsMutex sync.Mutex
+)
+
+func commandConnKey(clusterInfo *ClusterInfo) string {
+	return clusterInfo.Namespace + "/" + clusterInfo.CephCred.Username
+}
+
+// getCommandConn returns the connection of a cluster, opened by the first command of the cluster. It returns nil if
+// the connections are disabled or the connection fails, the commands falling back to the CLI.
+func getCommandConn(context *clusterd.Context, clusterInfo *ClusterInfo) CommandConn {
+	if !UseCommandConnection || NewCommandConn == nil || clusterInfo == nil || RunAllCephCommandsInToolbox {
+		return nil
+	}
+	commandConnsMutex.Lock()
+	defer commandConnsMutex.Unlock()
+	key := commandConnKey(clusterInfo)
+	if conn, ok := commandConns[key]; ok {
+		return conn
+	}
+	conn, err := NewCommandConn(context, clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to connect to cluster %q, running the ceph commands with the CLI. %v", clusterInfo.Namespace, err)
+		return nil
+	}
+	logger.Infof("connected to cluster %q as %q to run the ceph commands", clusterInfo.Namespace, clusterInfo.CephCred.Username)
+	commandConns[key] = conn
+	return conn
+}
+
+// CloseCommandConns closes the connections of a cluster when the cluster is deleted
+func CloseCommandConns(namespace string) {
+	commandConnsMutex.Lock()
+	defer commandConnsMutex.Unlock()
+	for key, conn := range commandConns {
+		if strings.HasPrefix(key, namespace+"/") {
+			conn.Close()
+			delete(commandConns, key)
+		}
+	}
+}
+
+// closeCommandConn closes a connection which failed, unless it was already replaced
+func closeCommandConn(clusterInfo *ClusterInfo, conn CommandConn) {
+	commandConnsMutex.Lock()
+	defer commandConnsMutex.Unlock()
+	key := commandConnKey(clusterInfo)
+	if commandConns[key] == conn {
+		conn.Close()
+		delete(commandConns, key)
+	}
+}
+
+// runOnConn runs a ceph command with json output on the connection of the cluster. It returns false when the command
+// must be run with the CLI.
+func (c *CephToolCommand) runOnConn() ([]byte, bool, error) {
+	if c.tool != CephTool || !c.JsonOutput || !c.OutputFile {
+		return nil, false, nil
+	}
+	command, ok := connCommands[strings.Join(c.args, " ")]
+	if !ok {
+		return nil, false, nil
+	}
+	conn := getCommandConn(c.context, c.clusterInfo)
+	if conn == nil {
+		return nil, false, nil
+	}
+
+	request := map[string]string{"prefix": command.prefix, "format": "json"}
+	for key, value := range command.args {
+		request[key] = value
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "failed to encode command %q", command.prefix)
+	}
+	send := conn.MonCommand
+	if command.mgr {
+		send = conn.MgrCommand
+	}
+
+	output, err := runWithTimeout(func() ([]byte, error) { return send(body) }, c.timeout)
+	if errors.Cause(err) == ErrConnectionLost {
+		logger.Warningf("lost the connection to cluster %q, running the ceph commands with the CLI until it reconnects", c.clusterInfo.Namespace)
+		closeCommandConn(c.clusterInfo, conn)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "failed to run command %q", command.prefix)
+	}
+	return output, true, nil
+}
+
+// runWithTimeout runs a command of the connection, which has no timeout of its own, within the timeout of the command
+func runWithTimeout(send func() ([]byte, error), timeout time.Duration) ([]byte, error) {
+	if timeout == 0 {
+		return send()
+	}
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := send()
+		done <- result{output, err}
+	}()
+	select {
+	case r := <-done:
+		return r.output, r.err
+	case <-time.After(timeout):
+		return nil, errors.Errorf("timed out after %s", timeout.String())
+	}
+}
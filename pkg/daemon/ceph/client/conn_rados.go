@@ -0,0 +1,91 @@
+// +build rados
+
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"path"
+	"syscall"
+
+	"github.com/ceph/go-ceph/rados"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+func init() {
+	NewCommandConn = newRadosCommandConn
+}
+
+// radosCommandConn runs the ceph commands on a librados connection
+type radosCommandConn struct {
+	conn *rados.Conn
+}
+
+// newRadosCommandConn connects to a cluster with the same config and keyring as the CLI
+func newRadosCommandConn(context *clusterd.Context, clusterInfo *ClusterInfo) (CommandConn, error) {
+	conn, err := rados.NewConnWithClusterAndUser(clusterInfo.Namespace, clusterInfo.CephCred.Username)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create connection")
+	}
+	if err := conn.ReadConfigFile(CephConfFilePath(context.ConfigDir, clusterInfo.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file")
+	}
+	keyringFile := fmt.Sprintf("%s.keyring", clusterInfo.CephCred.Username)
+	if err := conn.SetConfigOption("keyring", path.Join(context.ConfigDir, clusterInfo.Namespace, keyringFile)); err != nil {
+		return nil, errors.Wrapf(err, "failed to set keyring")
+	}
+	if err := conn.SetConfigOption("client_mount_timeout", cephConnectionTimeout); err != nil {
+		return nil, errors.Wrapf(err, "failed to set connection timeout")
+	}
+	if err := conn.Connect(); err != nil {
+		return nil, errors.Wrapf(err, "failed to connect")
+	}
+	return &radosCommandConn{conn: conn}, nil
+}
+
+func (c *radosCommandConn) MonCommand(command []byte) ([]byte, error) {
+	output, info, err := c.conn.MonCommand(command)
+	return output, commandError(err, info)
+}
+
+func (c *radosCommandConn) MgrCommand(command []byte) ([]byte, error) {
+	output, info, err := c.conn.MgrCommand([][]byte{command})
+	return output, commandError(err, info)
+}
+
+func (c *radosCommandConn) Close() {
+	c.conn.Shutdown()
+}
+
+// commandError returns ErrConnectionLost for the errors of a connection which is not connected to the cluster anymore
+func commandError(err error, info string) error {
+	if err == nil {
+		return nil
+	}
+	if err == rados.ErrNotConnected {
+		return ErrConnectionLost
+	}
+	if code, ok := err.(interface{ ErrorCode() int }); ok {
+		switch syscall.Errno(-code.ErrorCode()) {
+		case syscall.ETIMEDOUT, syscall.ENOTCONN, syscall.ESHUTDOWN:
+			return errors.Wrapf(ErrConnectionLost, "%v", err)
+		}
+	}
+	return errors.Wrapf(err, "%s", info)
+}
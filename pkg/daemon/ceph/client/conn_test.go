@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCommandConn struct {
+	monCommands []map[string]string
+	mgrCommands []map[string]string
+	err         error
+	closed      bool
+}
+
+func (c *fakeCommandConn) MonCommand(command []byte) ([]byte, error) {
+	var request map[string]string
+	if err := json.Unmarshal(command, &request); err != nil {
+		return nil, err
+	}
+	c.monCommands = append(c.monCommands, request)
+	return []byte(`{"from":"mon"}`), c.err
+}
+
+func (c *fakeCommandConn) MgrCommand(command []byte) ([]byte, error) {
+	var request map[string]string
+	if err := json.Unmarshal(command, &request); err != nil {
+		return nil, err
+	}
+	c.mgrCommands = append(c.mgrCommands, request)
+	return []byte(`{"from":"mgr"}`), c.err
+}
+
+func (c *fakeCommandConn) Close() {
+	c.closed = true
+}
+
+func TestCommandConn(t *testing.T) {
+	cliCommands := 0
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			cliCommands++
+			return `{"from":"cli"}`, nil
+		},
+		MockExecuteCommandWithOutputFileTimeout: func(timeout time.Duration, command string, outFileArg string, args ...string) (string, error) {
+			cliCommands++
+			return `{"from":"cli"}`, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor, ConfigDir: "/var/lib/rook"}
+	clusterInfo := AdminClusterInfo("rook-ceph")
+
+	var conns []*fakeCommandConn
+	var connectErr error
+	NewCommandConn = func(context *clusterd.Context, clusterInfo *ClusterInfo) (CommandConn, error) {
+		if connectErr != nil {
+			return nil, connectErr
+		}
+		conn := &fakeCommandConn{}
+		conns = append(conns, conn)
+		return conn, nil
+	}
+	defer func() {
+		NewCommandConn = nil
+		UseCommandConnection = false
+		CloseCommandConns("rook-ceph")
+	}()
+
+	t.Run("the commands run with the CLI by default", func(t *testing.T) {
+		output, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"cli"}`, string(output))
+		assert.Equal(t, 1, cliCommands)
+		assert.Equal(t, 0, len(conns))
+	})
+
+	UseCommandConnection = true
+	t.Run("the polled commands run on the connection", func(t *testing.T) {
+		output, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"mon"}`, string(output))
+		output, err = NewCephCommand(context, clusterInfo, []string{"health", "detail"}).RunWithTimeout(time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"mon"}`, string(output))
+		output, err = NewCephCommand(context, clusterInfo, []string{"osd", "df"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"mgr"}`, string(output))
+
+		// the connection is opened once for the cluster
+		assert.Equal(t, 1, len(conns))
+		assert.Equal(t, []map[string]string{
+			{"prefix": "status", "format": "json"},
+			{"prefix": "health", "detail": "detail", "format": "json"},
+		}, conns[0].monCommands)
+		assert.Equal(t, []map[string]string{{"prefix": "osd df", "format": "json"}}, conns[0].mgrCommands)
+		assert.Equal(t, 1, cliCommands)
+	})
+
+	t.Run("the other commands run with the CLI", func(t *testing.T) {
+		output, err := NewCephCommand(context, clusterInfo, []string{"osd", "pool", "create", "mypool"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"cli"}`, string(output))
+		_, err = NewRBDCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(conns[0].monCommands))
+	})
+
+	t.Run("the errors of the commands are returned", func(t *testing.T) {
+		cliCommands = 0
+		conns[0].err = errors.New("failed")
+		_, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.Error(t, err)
+		assert.Equal(t, 0, cliCommands)
+		assert.False(t, conns[0].closed)
+		conns[0].err = nil
+	})
+
+	t.Run("the command runs with the CLI when the connection is lost", func(t *testing.T) {
+		cliCommands = 0
+		conns[0].err = errors.Wrap(ErrConnectionLost, "timed out")
+		output, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"cli"}`, string(output))
+		assert.Equal(t, 1, cliCommands)
+		assert.True(t, conns[0].closed)
+
+		// the next command reconnects
+		output, err = NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"mon"}`, string(output))
+		assert.Equal(t, 2, len(conns))
+	})
+
+	t.Run("the connections are closed with the cluster", func(t *testing.T) {
+		CloseCommandConns("rook-ceph")
+		assert.True(t, conns[1].closed)
+	})
+
+	t.Run("the commands run with the CLI when the connection fails", func(t *testing.T) {
+		cliCommands = 0
+		connectErr = errors.New("failed to connect")
+		output, err := NewCephCommand(context, clusterInfo, []string{"status"}).Run()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"from":"cli"}`, string(output))
+		assert.Equal(t, 1, cliCommands)
+		assert.Equal(t, 2, len(conns))
+	})
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// CrashInfo is a crash report of a daemon collected by the crash mgr module
+type CrashInfo struct {
+	ID        string `json:"crash_id"`
+	Entity    string `json:"entity_name"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Time returns the time of the crash, the timestamp is formatted like "2020-07-30 12:36:24.593564Z" or
+// "2020-07-30T12:36:24.593564Z" depending on the version of ceph
+func (c CrashInfo) Time() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, strings.Replace(strings.TrimSpace(c.Timestamp), " ", "T", 1))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to parse the timestamp of crash %q", c.ID)
+	}
+	return t, nil
+}
+
+// ListNewCrashes returns the crash reports which were not archived yet
+func ListNewCrashes(context *clusterd.Context, clusterInfo *ClusterInfo) ([]CrashInfo, error) {
+	args := []string{"crash", "ls-new"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the new crashes")
+	}
+
+	var crashes []CrashInfo
+	if err := json.Unmarshal(buf, &crashes); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal crash ls-new response. %s", string(buf))
+	}
+	return crashes, nil
+}
+
+// ArchiveCrash archives a crash report, which is then no longer reported in the health of the cluster
+func ArchiveCrash(context *clusterd.Context, clusterInfo *ClusterInfo, crashID string) error {
+	args := []string{"crash", "archive", crashID}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to archive crash %q", crashID)
+	}
+	return nil
+}
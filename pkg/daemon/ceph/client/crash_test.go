@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListNewCrashes(t *testing.T) {
+	archived := []string{}
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "crash" && args[1] == "ls-new" {
+			return `[{"crash_id":"2020-07-30_12:36:24.593564Z_0b2d8f6c","entity_name":"osd.1","timestamp":"2020-07-30 12:36:24.593564Z"},
+				{"crash_id":"2020-08-02T08:01:02.000001Z_73e5ab1e","entity_name":"mgr.a","timestamp":"2020-08-02T08:01:02.000001Z"}]`, nil
+		}
+		if args[0] == "crash" && args[1] == "archive" {
+			archived = append(archived, args[2])
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	crashes, err := ListNewCrashes(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(crashes))
+	assert.Equal(t, "osd.1", crashes[0].Entity)
+
+	// both formats of the timestamp are parsed
+	crashTime, err := crashes[0].Time()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 7, 30, 12, 36, 24, 593564000, time.UTC), crashTime)
+	crashTime, err = crashes[1].Time()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 8, 2, 8, 1, 2, 1000, time.UTC), crashTime)
+	_, err = CrashInfo{ID: "invalid", Timestamp: "yesterday"}.Time()
+	assert.Error(t, err)
+
+	assert.NoError(t, ArchiveCrash(context, clusterInfo, crashes[0].ID))
+	assert.Equal(t, []string{crashes[0].ID}, archived)
+}
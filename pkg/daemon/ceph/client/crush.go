@@ -97,6 +97,65 @@ func GetCrushMap(context *clusterd.Context, clusterInfo *ClusterInfo) (CrushMap,
 	return c, nil
 }
 
+// CountFailureDomains returns the number of buckets of the failure domain type under the crush root which hold OSDs
+// of the device class, or of any class if the device class is empty. The OSDs are counted for the "osd" failure
+// domain.
+func (c *CrushMap) CountFailureDomains(root, failureDomain, deviceClass string) int {
+	buckets := map[int]int{}
+	rootIndex := -1
+	for i, bucket := range c.Buckets {
+		buckets[bucket.ID] = i
+		if bucket.Name == root {
+			rootIndex = i
+		}
+	}
+	classes := map[int]string{}
+	for _, device := range c.Devices {
+		classes[device.ID] = device.Class
+	}
+	if rootIndex == -1 {
+		return 0
+	}
+
+	// the OSDs have positive ids, the buckets negative ids
+	isOSD := func(id int) bool {
+		return id >= 0 && (deviceClass == "" || classes[id] == deviceClass)
+	}
+	var hasOSD func(index int) bool
+	hasOSD = func(index int) bool {
+		for _, item := range c.Buckets[index].Items {
+			if isOSD(item.ID) {
+				return true
+			}
+			if child, ok := buckets[item.ID]; ok && hasOSD(child) {
+				return true
+			}
+		}
+		return false
+	}
+
+	count := 0
+	var walk func(index int)
+	walk = func(index int) {
+		if c.Buckets[index].TypeName == failureDomain {
+			if hasOSD(index) {
+				count++
+			}
+			return
+		}
+		for _, item := range c.Buckets[index].Items {
+			if failureDomain == "osd" && isOSD(item.ID) {
+				count++
+			}
+			if child, ok := buckets[item.ID]; ok {
+				walk(child)
+			}
+		}
+	}
+	walk(rootIndex)
+	return count
+}
+
 // FindOSDInCrushMap finds an OSD in the CRUSH map
 func FindOSDInCrushMap(context *clusterd.Context, clusterInfo *ClusterInfo, osdID int) (*CrushFindResult, error) {
 	args := []string{"osd", "find", strconv.Itoa(osdID)}
@@ -184,3 +243,14 @@ func GetOSDOnHost(context *clusterd.Context, clusterInfo *ClusterInfo, node stri
 
 	return string(buf), nil
 }
+
+// MoveCrushBucket moves a bucket of the crush map with its items to a new location, creating the missing buckets of
+// the location
+func MoveCrushBucket(context *clusterd.Context, clusterInfo *ClusterInfo, name string, location []string) error {
+	args := append([]string{"osd", "crush", "move", name}, location...)
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to move crush bucket %q to %q. %s", name, strings.Join(location, " "), string(buf))
+	}
+	return nil
+}
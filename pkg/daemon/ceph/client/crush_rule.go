@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+const (
+	crushRulePoolProperty = "crush_rule"
+
+	// the primary copy is on an OSD of the primary device class, the other copies on OSDs of the secondary device
+	// class in other failure domains
+	hybridCrushRuleTemplate = `rule %s {
+	id %d
+	type replicated
+	min_size 1
+	max_size 10
+	step take %s class %s
+	step chooseleaf firstn 1 type %s
+	step emit
+	step take %s class %s
+	step chooseleaf firstn -1 type %s
+	step emit
+}
+`
+)
+
+// the decompiled crush map ends with the optional choose args of the balancer and the end marker, the rules are
+// added before them
+var crushMapRulesEndMarkers = []string{"# choose_args", "# end crush map"}
+
+// hybridCrushRuleName returns the name of the crush rule of a hybrid pool, a new rule is created when the device
+// classes change
+func hybridCrushRuleName(poolName string, hybrid *cephv1.HybridStorageSpec) string {
+	return fmt.Sprintf("%s_hybrid_%s_%s", poolName, hybrid.PrimaryDeviceClass, hybrid.SecondaryDeviceClass)
+}
+
+// hybridCrushRule returns the definition of the crush rule of a hybrid pool in the crush map
+func hybridCrushRule(ruleName string, ruleID int, pool cephv1.PoolSpec) string {
+	failureDomain := pool.FailureDomain
+	if failureDomain == "" {
+		failureDomain = cephv1.DefaultFailureDomain
+	}
+	crushRoot := cephv1.DefaultCRUSHRoot
+	if pool.CrushRoot != "" {
+		crushRoot = pool.CrushRoot
+	}
+	hybrid := pool.Replicated.HybridStorage
+	return fmt.Sprintf(hybridCrushRuleTemplate, ruleName, ruleID,
+		crushRoot, hybrid.PrimaryDeviceClass, failureDomain,
+		crushRoot, hybrid.SecondaryDeviceClass, failureDomain)
+}
+
+// createHybridCrushRule creates the crush rule of a hybrid pool if it does not exist. There is no ceph command
+// creating a rule with several take steps, the rule is added to the crush map with crushtool.
+func createHybridCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, ruleName string, pool cephv1.PoolSpec) error {
+	crush, err := GetCrushMap(context, clusterInfo)
+	if err != nil {
+		return err
+	}
+	ruleID := 0
+	for _, rule := range crush.Rules {
+		if rule.Name == ruleName {
+			logger.Debugf("crush rule %q already exists", ruleName)
+			return nil
+		}
+		if rule.ID >= ruleID {
+			ruleID = rule.ID + 1
+		}
+	}
+
+	if err := addCrushRule(context, clusterInfo, hybridCrushRule(ruleName, ruleID, pool)); err != nil {
+		return errors.Wrapf(err, "failed to create crush rule %s", ruleName)
+	}
+	logger.Infof("created hybrid crush rule %q", ruleName)
+	return nil
+}
+
+// addCrushRule decompiles the crush map, adds the rule to it, and sets the compiled crush map
+func addCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, rule string) error {
+	dir, err := ioutil.TempDir("", "crushmap")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(dir)
+	compiledPath := path.Join(dir, "crushmap")
+	decompiledPath := path.Join(dir, "crushmap.txt")
+	updatedPath := path.Join(dir, "crushmap.new")
+
+	cmd := NewCephCommand(context, clusterInfo, []string{"osd", "getcrushmap"})
+	cmd.JsonOutput = false
+	compiled, err := cmd.Run()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the compiled crush map")
+	}
+	if err := ioutil.WriteFile(compiledPath, compiled, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write crush map file %q", compiledPath)
+	}
+	if output, err := context.Executor.ExecuteCommandWithCombinedOutput(CrushTool, "--decompile", compiledPath, "--outfn", decompiledPath); err != nil {
+		return errors.Wrapf(err, "failed to decompile the crush map. %s", output)
+	}
+	decompiled, err := ioutil.ReadFile(decompiledPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read crush map file %q", decompiledPath)
+	}
+
+	updated, err := insertCrushRule(string(decompiled), rule)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(decompiledPath, []byte(updated), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write crush map file %q", decompiledPath)
+	}
+	if output, err := context.Executor.ExecuteCommandWithCombinedOutput(CrushTool, "--compile", decompiledPath, "--outfn", updatedPath); err != nil {
+		return errors.Wrapf(err, "failed to compile the crush map. %s", output)
+	}
+
+	if output, err := NewCephCommand(context, clusterInfo, []string{"osd", "setcrushmap", "-i", updatedPath}).Run(); err != nil {
+		return errors.Wrapf(err, "failed to set the crush map. %s", string(output))
+	}
+	return nil
+}
+
+// insertCrushRule adds a rule after the rules of a decompiled crush map
+func insertCrushRule(crushMap, rule string) (string, error) {
+	for _, marker := range crushMapRulesEndMarkers {
+		if i := strings.Index(crushMap, marker); i != -1 {
+			return crushMap[:i] + rule + "\n" + crushMap[i:], nil
+		}
+	}
+	return "", errors.New("failed to find the end of the rules of the decompiled crush map")
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const decompiledCrushMap = `# begin crush map
+tunable choose_total_tries 50
+
+# devices
+device 0 osd.0 class ssd
+device 1 osd.1 class hdd
+
+# types
+type 0 osd
+type 1 host
+type 11 root
+
+# rules
+rule replicated_rule {
+	id 0
+	type replicated
+	min_size 1
+	max_size 10
+	step take default
+	step chooseleaf firstn 0 type host
+	step emit
+}
+
+# end crush map
+`
+
+func TestCreateHybridCrushRule(t *testing.T) {
+	setCrushMap := ""
+	poolRule := ""
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outfile string, args ...string) (string, error) {
+		switch {
+		case args[0] == "osd" && args[1] == "crush" && args[2] == "dump":
+			return `{"rules":[{"rule_id":0,"rule_name":"replicated_rule"},{"rule_id":3,"rule_name":"mypool"}]}`, nil
+		case args[0] == "osd" && args[1] == "getcrushmap":
+			return "compiled", nil
+		case args[0] == "osd" && args[1] == "setcrushmap":
+			assert.Equal(t, "-i", args[2])
+			crushMap, err := ioutil.ReadFile(args[3])
+			assert.NoError(t, err)
+			setCrushMap = string(crushMap)
+			return "", nil
+		case args[0] == "osd" && args[1] == "pool" && args[2] == "create":
+			// the pool is created with the hybrid rule
+			assert.Equal(t, "mypool_hybrid_ssd_hdd", args[6])
+			return "", nil
+		case args[0] == "osd" && args[1] == "pool" && args[2] == "set":
+			if args[4] == "crush_rule" {
+				poolRule = args[5]
+			}
+			return "", nil
+		case args[0] == "osd" && args[1] == "pool" && args[2] == "application":
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	executor.MockExecuteCommandWithCombinedOutput = func(command string, args ...string) (string, error) {
+		assert.Equal(t, CrushTool, command)
+		input, err := ioutil.ReadFile(args[1])
+		assert.NoError(t, err)
+		switch args[0] {
+		case "--decompile":
+			assert.Equal(t, "compiled", string(input))
+			return "", ioutil.WriteFile(args[3], []byte(decompiledCrushMap), 0600)
+		case "--compile":
+			return "", ioutil.WriteFile(args[3], input, 0600)
+		}
+		return "", errors.Errorf("unexpected crushtool command %q", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	p := cephv1.PoolSpec{
+		FailureDomain: "osd",
+		Replicated: cephv1.ReplicatedSpec{
+			Size:          3,
+			HybridStorage: &cephv1.HybridStorageSpec{PrimaryDeviceClass: "ssd", SecondaryDeviceClass: "hdd"},
+		},
+	}
+	err := CreateReplicatedPoolForApp(context, AdminClusterInfo("mycluster"), "mypool", p, DefaultPGCount, "myapp")
+	assert.NoError(t, err)
+	assert.Equal(t, "mypool_hybrid_ssd_hdd", poolRule)
+
+	// the rule is added after the existing rules, with the next id
+	assert.True(t, strings.HasPrefix(setCrushMap, decompiledCrushMap[:strings.Index(decompiledCrushMap, "# end crush map")]))
+	assert.Contains(t, setCrushMap, `rule mypool_hybrid_ssd_hdd {
+	id 4
+	type replicated
+	min_size 1
+	max_size 10
+	step take default class ssd
+	step chooseleaf firstn 1 type osd
+	step emit
+	step take default class hdd
+	step chooseleaf firstn -1 type osd
+	step emit
+}
+
+# end crush map
+`)
+
+	// the crush map is not updated when the rule exists
+	setCrushMap = ""
+	err = createHybridCrushRule(context, AdminClusterInfo("mycluster"), "mypool", p)
+	assert.NoError(t, err)
+	assert.Empty(t, setCrushMap)
+}
+
+func TestInsertCrushRule(t *testing.T) {
+	rule := "rule r {\n}\n"
+
+	// the rules are before the choose args of the balancer
+	crushMap, err := insertCrushRule("# rules\n# choose_args\nchoose_args 1 {\n}\n\n# end crush map\n", rule)
+	assert.NoError(t, err)
+	assert.Equal(t, "# rules\nrule r {\n}\n\n# choose_args\nchoose_args 1 {\n}\n\n# end crush map\n", crushMap)
+
+	_, err = insertCrushRule("# rules\n", rule)
+	assert.Error(t, err)
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -294,3 +295,14 @@ func TestCrushName(t *testing.T) {
 		}
 	}
 }
+
+func TestCountFailureDomains(t *testing.T) {
+	crush := CrushMap{}
+	assert.NoError(t, json.Unmarshal([]byte(testCrushMap), &crush))
+	assert.Equal(t, 1, crush.CountFailureDomains("default", "host", ""))
+	assert.Equal(t, 1, crush.CountFailureDomains("default", "osd", ""))
+	assert.Equal(t, 1, crush.CountFailureDomains("default", "host", "hdd"))
+	assert.Equal(t, 0, crush.CountFailureDomains("default", "host", "ssd"))
+	assert.Equal(t, 0, crush.CountFailureDomains("default", "rack", ""))
+	assert.Equal(t, 0, crush.CountFailureDomains("fast", "host", ""))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// GetDashboardSetting returns the value of a setting of the dashboard module, like "grafana-api-url"
+func GetDashboardSetting(context *clusterd.Context, clusterInfo *ClusterInfo, setting string) (string, error) {
+	args := []string{"dashboard", "get-" + setting}
+	output, err := NewCephCommand(context, clusterInfo, args).RunWithTimeout(CmdExecuteTimeout)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get dashboard setting %q", setting)
+	}
+	return strings.Trim(strings.TrimSpace(string(output)), `"`), nil
+}
+
+// SetDashboardSetting sets a setting of the dashboard module if it changed, or resets it to its default if the value
+// is empty. It returns whether the setting changed.
+func SetDashboardSetting(context *clusterd.Context, clusterInfo *ClusterInfo, setting, value string) (bool, error) {
+	current, err := GetDashboardSetting(context, clusterInfo, setting)
+	if err == nil && current == value {
+		return false, nil
+	}
+	args := []string{"dashboard", "set-" + setting, value}
+	if value == "" {
+		if err == nil && current == "" {
+			return false, nil
+		}
+		args = []string{"dashboard", "reset-" + setting}
+	}
+	if _, err := NewCephCommand(context, clusterInfo, args).RunWithTimeout(CmdExecuteTimeout); err != nil {
+		return false, errors.Wrapf(err, "failed to set dashboard setting %q", setting)
+	}
+	return true, nil
+}
+
+// SetDashboardSettingFromFile runs a set command of the dashboard module which reads the value from a file, like the
+// certificates and the keys, so that the value is not in the arguments of the command
+func SetDashboardSettingFromFile(context *clusterd.Context, clusterInfo *ClusterInfo, command string, content []byte) error {
+	file, err := ioutil.TempFile("", "dashboard")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp file")
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to write temp file %q", file.Name())
+	}
+	file.Close()
+
+	args := []string{"dashboard", command, "-i", file.Name()}
+	if _, err := NewCephCommand(context, clusterInfo, args).RunWithTimeout(CmdExecuteTimeout); err != nil {
+		return errors.Wrapf(err, "failed to run dashboard %s", command)
+	}
+	return nil
+}
@@ -79,14 +79,14 @@ func CreateErasureCodeProfile(context *clusterd.Context, clusterInfo *ClusterInf
 		fmt.Sprintf("plugin=%s", defaultProfile.Plugin),
 		fmt.Sprintf("technique=%s", defaultProfile.Technique),
 	}
-	if pool.FailureDomain != "" {
-		profilePairs = append(profilePairs, fmt.Sprintf("crush-failure-domain=%s", pool.FailureDomain))
+	if failureDomain := pool.ErasureCodeFailureDomain(); failureDomain != "" {
+		profilePairs = append(profilePairs, fmt.Sprintf("crush-failure-domain=%s", failureDomain))
 	}
-	if pool.CrushRoot != "" {
-		profilePairs = append(profilePairs, fmt.Sprintf("crush-root=%s", pool.CrushRoot))
+	if crushRoot := pool.ErasureCodeCrushRoot(); crushRoot != "" {
+		profilePairs = append(profilePairs, fmt.Sprintf("crush-root=%s", crushRoot))
 	}
-	if pool.DeviceClass != "" {
-		profilePairs = append(profilePairs, fmt.Sprintf("crush-device-class=%s", pool.DeviceClass))
+	if deviceClass := pool.ErasureCodeDeviceClass(); deviceClass != "" {
+		profilePairs = append(profilePairs, fmt.Sprintf("crush-device-class=%s", deviceClass))
 	}
 
 	args := []string{"osd", "erasure-code-profile", "set", profileName}
@@ -87,3 +87,38 @@ func testCreateProfile(t *testing.T, failureDomain, crushRoot, deviceClass strin
 	err := CreateErasureCodeProfile(context, AdminClusterInfo("mycluster"), "myapp", spec)
 	assert.Nil(t, err)
 }
+
+func TestCreateProfileWithErasureCodeCrushSettings(t *testing.T) {
+	// the crush settings of the erasure code override the crush settings of the pool
+	spec := cephv1.PoolSpec{
+		FailureDomain: "host",
+		CrushRoot:     "default",
+		ErasureCoded: cephv1.ErasureCodedSpec{
+			DataChunks:    2,
+			CodingChunks:  1,
+			FailureDomain: "rack",
+			DeviceClass:   "ssd",
+		},
+	}
+
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	profile := []string{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		if args[1] == "erasure-code-profile" {
+			if args[2] == "get" {
+				return `{"plugin":"jerasure","technique":"reed_sol_van"}`, nil
+			}
+			if args[2] == "set" {
+				profile = args[4:11]
+				return "", nil
+			}
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	err := CreateErasureCodeProfile(context, AdminClusterInfo("mycluster"), "myapp", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"k=2", "m=1", "plugin=jerasure", "technique=reed_sol_van",
+		"crush-failure-domain=rack", "crush-root=default", "crush-device-class=ssd"}, profile)
+}
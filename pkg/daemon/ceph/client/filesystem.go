@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -322,3 +323,29 @@ func deleteFSPool(context *clusterd.Context, clusterInfo *ClusterInfo, poolNames
 	}
 	return DeletePool(context, clusterInfo, name)
 }
+
+// CreateSubvolumeGroup creates a subvolume group in a filesystem, nothing is done if the group already exists
+func CreateSubvolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, groupName string) error {
+	args := []string{"fs", "subvolumegroup", "create", fsName, groupName}
+	_, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create subvolume group %q in filesystem %q", groupName, fsName)
+	}
+
+	return nil
+}
+
+// GetSubvolumeGroupPath returns the path of a subvolume group in a filesystem, e.g. /volumes/<group>
+func GetSubvolumeGroupPath(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, groupName string) (string, error) {
+	args := []string{"fs", "subvolumegroup", "getpath", fsName, groupName}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the path of subvolume group %q in filesystem %q", groupName, fsName)
+	}
+
+	path := strings.TrimSpace(string(buf))
+	if path == "" {
+		return "", errors.Errorf("empty path for subvolume group %q in filesystem %q", groupName, fsName)
+	}
+	return path, nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// FilesystemMirrorPeer is a peer of a filesystem reported by a cephfs-mirror daemon
+type FilesystemMirrorPeer struct {
+	UUID   string `json:"uuid"`
+	Remote struct {
+		ClientName  string `json:"client_name"`
+		ClusterName string `json:"cluster_name"`
+		FSName      string `json:"fs_name"`
+	} `json:"remote"`
+	Stats struct {
+		FailureCount  int `json:"failure_count"`
+		RecoveryCount int `json:"recovery_count"`
+	} `json:"stats"`
+}
+
+// FilesystemMirrorDaemonStatus is the status of a cephfs-mirror daemon reported by the mirroring mgr module
+type FilesystemMirrorDaemonStatus struct {
+	DaemonID    int `json:"daemon_id"`
+	Filesystems []struct {
+		FilesystemID   int                    `json:"filesystem_id"`
+		Name           string                 `json:"name"`
+		DirectoryCount int                    `json:"directory_count"`
+		Peers          []FilesystemMirrorPeer `json:"peers"`
+	} `json:"filesystems"`
+}
+
+// FilesystemMirrorDirectory is the mapping of a mirrored directory to the cephfs-mirror daemon syncing its snapshots
+type FilesystemMirrorDirectory struct {
+	InstanceID string `json:"instance_id"`
+	State      string `json:"state"`
+}
+
+// SnapshotSchedule is a snapshot schedule of a directory reported by the snap_schedule mgr module
+type SnapshotSchedule struct {
+	Path     string `json:"path"`
+	Schedule string `json:"schedule"`
+	// Last is the time of the last snapshot of the schedule, empty before the first snapshot
+	Last         string `json:"last"`
+	CreatedCount int    `json:"created_count"`
+	PrunedCount  int    `json:"pruned_count"`
+	Active       bool   `json:"active"`
+}
+
+// EnableFilesystemMirroring enables the snapshot mirroring of a filesystem
+func EnableFilesystemMirroring(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string) error {
+	args := []string{"fs", "snapshot", "mirror", "enable", fsName}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to enable the snapshot mirroring of filesystem %q", fsName)
+	}
+	return nil
+}
+
+// DisableFilesystemMirroring disables the snapshot mirroring of a filesystem
+func DisableFilesystemMirroring(context *clusterd.Context, clusterInfo *ClusterInfo, fsName string) error {
+	args := []string{"fs", "snapshot", "mirror", "disable", fsName}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to disable the snapshot mirroring of filesystem %q", fsName)
+	}
+	return nil
+}
+
+// AddFilesystemMirrorDirectory adds a directory to the snapshot mirroring of a filesystem, the directories already
+// mirrored are ignored
+func AddFilesystemMirrorDirectory(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string) error {
+	args := []string{"fs", "snapshot", "mirror", "add", fsName, path}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.EEXIST) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to add directory %q to the snapshot mirroring of filesystem %q", path, fsName)
+	}
+	return nil
+}
+
+// GetFilesystemMirrorDaemonStatus returns the status of the cephfs-mirror daemons
+func GetFilesystemMirrorDaemonStatus(context *clusterd.Context, clusterInfo *ClusterInfo) ([]FilesystemMirrorDaemonStatus, error) {
+	args := []string{"fs", "snapshot", "mirror", "daemon", "status"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the status of the cephfs-mirror daemons")
+	}
+
+	var status []FilesystemMirrorDaemonStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal fs snapshot mirror daemon status response. %s", string(buf))
+	}
+	return status, nil
+}
+
+// GetFilesystemMirrorDirectory returns the mapping of a mirrored directory of a filesystem
+func GetFilesystemMirrorDirectory(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string) (*FilesystemMirrorDirectory, error) {
+	args := []string{"fs", "snapshot", "mirror", "dirmap", fsName, path}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the mapping of mirrored directory %q of filesystem %q", path, fsName)
+	}
+
+	var directory FilesystemMirrorDirectory
+	if err := json.Unmarshal(buf, &directory); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal fs snapshot mirror dirmap response. %s", string(buf))
+	}
+	return &directory, nil
+}
+
+// GetSnapshotSchedules returns the snapshot schedules of a directory of a filesystem, none when the directory has no
+// schedule
+func GetSnapshotSchedules(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, path string) ([]SnapshotSchedule, error) {
+	args := []string{"fs", "snap-schedule", "status", path, fmt.Sprintf("--fs=%s", fsName)}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the snapshot schedules of directory %q of filesystem %q", path, fsName)
+	}
+
+	var schedules []SnapshotSchedule
+	if err := json.Unmarshal(buf, &schedules); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal fs snap-schedule status response. %s", string(buf))
+	}
+	return schedules, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilesystemMirrorStatus(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		switch {
+		case args[0] == "fs" && args[1] == "snapshot" && args[3] == "daemon":
+			return `[{"daemon_id":4115,"filesystems":[{"filesystem_id":1,"name":"myfs","directory_count":1,
+				"peers":[{"uuid":"a2dc7784-e7a1-4723-b103-03ee8d8768f8","remote":{"client_name":"client.mirror","cluster_name":"site-b","fs_name":"backup"},
+				"stats":{"failure_count":1,"recovery_count":0}}]}]}]`, nil
+		case args[0] == "fs" && args[1] == "snapshot" && args[3] == "dirmap":
+			assert.Equal(t, []string{"myfs", "/volumes"}, args[4:6])
+			return `{"instance_id":"25184","last_shuffled":1618367512.0,"state":"mapped"}`, nil
+		case args[0] == "fs" && args[1] == "snap-schedule":
+			assert.Equal(t, []string{"status", "/volumes", "--fs=myfs"}, args[2:5])
+			return `[{"fs":"myfs","subvol":null,"path":"/volumes","rel_path":"/volumes","schedule":"1h","retention":{},
+				"start":"2021-04-14T00:00:00","created":"2021-04-14T10:12:06","first":"2021-04-14T11:00:00",
+				"last":"2021-04-14T12:00:00","last_pruned":null,"created_count":2,"pruned_count":0,"active":true}]`, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	daemons, err := GetFilesystemMirrorDaemonStatus(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(daemons))
+	assert.Equal(t, 4115, daemons[0].DaemonID)
+	assert.Equal(t, "myfs", daemons[0].Filesystems[0].Name)
+	peer := daemons[0].Filesystems[0].Peers[0]
+	assert.Equal(t, "site-b", peer.Remote.ClusterName)
+	assert.Equal(t, 1, peer.Stats.FailureCount)
+
+	directory, err := GetFilesystemMirrorDirectory(context, clusterInfo, "myfs", "/volumes")
+	assert.NoError(t, err)
+	assert.Equal(t, &FilesystemMirrorDirectory{InstanceID: "25184", State: "mapped"}, directory)
+
+	schedules, err := GetSnapshotSchedules(context, clusterInfo, "myfs", "/volumes")
+	assert.NoError(t, err)
+	assert.Equal(t, []SnapshotSchedule{{Path: "/volumes", Schedule: "1h", Last: "2021-04-14T12:00:00", CreatedCount: 2, Active: true}}, schedules)
+}
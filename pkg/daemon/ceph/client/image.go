@@ -42,7 +42,10 @@ type CephBlockImage struct {
 }
 
 func ListImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) ([]CephBlockImage, error) {
-	args := []string{"ls", "-l", poolName}
+	return listImages(context, clusterInfo, poolName, []string{"ls", "-l", poolName})
+}
+
+func listImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string, args []string) ([]CephBlockImage, error) {
 	cmd := NewRBDCommand(context, clusterInfo, args)
 	cmd.JsonOutput = true
 	buf, err := cmd.Run()
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var commandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "rook_ceph_command_duration_seconds",
+	Help:    "Duration of the ceph and rbd commands run by the operator by tool, command and result",
+	Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+}, []string{"tool", "command", "result"})
+
+func init() {
+	// the metrics are served by the metrics endpoint of the operator
+	metrics.Registry.MustRegister(commandDuration)
+}
+
+// recordCommandDuration exports the duration of a command. Only the first argument is kept
+// in the labels, like "osd" for "ceph osd dump", to keep a small number of series.
+func recordCommandDuration(tool string, args []string, start time.Time, err error) {
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	commandDuration.WithLabelValues(tool, command, result).Observe(time.Since(start).Seconds())
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package client
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -27,8 +29,26 @@ import (
 
 var (
 	moduleEnableWaitTime = 5 * time.Second
+
+	// the health checks of the mgr modules which failed or whose dependencies are missing
+	mgrModuleHealthChecks = []string{"MGR_MODULE_ERROR", "MGR_MODULE_DEPENDENCY"}
+	failedModuleRegex     = regexp.MustCompile(`Module '([^']+)' has failed`)
 )
 
+// BalancerStatus is the status of the balancer module
+type BalancerStatus struct {
+	Active              bool   `json:"active"`
+	Mode                string `json:"mode"`
+	LastOptimizeStarted string `json:"last_optimize_started"`
+	OptimizeResult      string `json:"optimize_result"`
+}
+
+type healthDetail struct {
+	Checks map[string]struct {
+		Detail []Summary `json:"detail"`
+	} `json:"checks"`
+}
+
 // MgrEnableModule enables a mgr module
 func MgrEnableModule(context *clusterd.Context, clusterInfo *ClusterInfo, name string, force bool) error {
 	retryCount := 5
@@ -61,6 +81,48 @@ func MgrDisableModule(context *clusterd.Context, clusterInfo *ClusterInfo, name
 	return enableModule(context, clusterInfo, name, false, "disable")
 }
 
+// MgrServices returns the endpoints of the services served by the mgr modules, such as the dashboard
+func MgrServices(context *clusterd.Context, clusterInfo *ClusterInfo) (map[string]string, error) {
+	args := []string{"mgr", "services"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get mgr services")
+	}
+
+	var services map[string]string
+	if err := json.Unmarshal(buf, &services); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal mgr services. %s", string(buf))
+	}
+	return services, nil
+}
+
+// FailedMgrModules returns the reason of the failure of the mgr modules which failed or whose dependencies are
+// missing, by the name of the module
+func FailedMgrModules(context *clusterd.Context, clusterInfo *ClusterInfo) (map[string]string, error) {
+	args := []string{"health", "detail"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get health detail")
+	}
+
+	var detail healthDetail
+	if err := json.Unmarshal(buf, &detail); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal health detail response. %s", string(buf))
+	}
+
+	modules := map[string]string{}
+	for _, check := range mgrModuleHealthChecks {
+		for _, message := range detail.Checks[check].Detail {
+			match := failedModuleRegex.FindStringSubmatch(message.Message)
+			if match == nil {
+				continue
+			}
+			modules[match[1]] = message.Message
+		}
+	}
+	return modules, nil
+}
+
 // MgrSetConfig applies a setting for a single mgr daemon
 func MgrSetConfig(context *clusterd.Context, clusterInfo *ClusterInfo, mgrName string, key, val string, force bool) (bool, error) {
 	var getArgs, setArgs []string
@@ -125,6 +187,21 @@ func setBalancerMode(context *clusterd.Context, clusterInfo *ClusterInfo, mode s
 	return nil
 }
 
+// GetBalancerStatus returns the status of the balancer module
+func GetBalancerStatus(context *clusterd.Context, clusterInfo *ClusterInfo) (*BalancerStatus, error) {
+	args := []string{"balancer", "status"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get balancer status")
+	}
+
+	var status BalancerStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal balancer status. %s", string(buf))
+	}
+	return &status, nil
+}
+
 // setMinCompatClientLuminous set the minimum compatibility for clients to Luminous
 func setMinCompatClientLuminous(context *clusterd.Context, clusterInfo *ClusterInfo) error {
 	args := []string{"osd", "set-require-min-compat-client", "luminous", "--yes-i-really-mean-it"}
@@ -159,13 +236,15 @@ func mgrSetBalancerMode(context *clusterd.Context, clusterInfo *ClusterInfo, bal
 // ConfigureBalancerModule configures the balancer module
 func ConfigureBalancerModule(context *clusterd.Context, clusterInfo *ClusterInfo, balancerModuleMode string) error {
 	// Set min compat client to luminous before enabling the balancer mode "upmap"
-	err := setMinCompatClientLuminous(context, clusterInfo)
-	if err != nil {
-		return errors.Wrap(err, "failed to set minimum compatibility client")
+	if balancerModuleMode == "upmap" {
+		err := setMinCompatClientLuminous(context, clusterInfo)
+		if err != nil {
+			return errors.Wrap(err, "failed to set minimum compatibility client")
+		}
 	}
 
 	// Set balancer module mode
-	err = mgrSetBalancerMode(context, clusterInfo, balancerModuleMode)
+	err := mgrSetBalancerMode(context, clusterInfo, balancerModuleMode)
 	if err != nil {
 		return errors.Wrapf(err, "failed to set balancer module mode to %q", balancerModuleMode)
 	}
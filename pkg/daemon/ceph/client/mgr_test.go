@@ -136,3 +136,32 @@ func TestSetBalancerMode(t *testing.T) {
 	err := setBalancerMode(&clusterd.Context{Executor: executor}, AdminClusterInfo("mycluster"), "upmap")
 	assert.NoError(t, err)
 }
+
+func TestFailedMgrModules(t *testing.T) {
+	healthDetail := `{"status":"HEALTH_OK","checks":{}}`
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		if args[0] == "health" && args[1] == "detail" {
+			return healthDetail, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	modules, err := FailedMgrModules(context, AdminClusterInfo("mycluster"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(modules))
+
+	healthDetail = `{"status":"HEALTH_ERR","checks":{
+		"MGR_MODULE_ERROR":{"severity":"HEALTH_ERR","summary":{"message":"Module 'dashboard' has failed: timeout"},
+			"detail":[{"message":"Module 'dashboard' has failed: timeout"}]},
+		"MGR_MODULE_DEPENDENCY":{"severity":"HEALTH_WARN","summary":{"message":"Module 'k8sevents' has failed dependency: No module named 'kubernetes'"},
+			"detail":[{"message":"Module 'k8sevents' has failed dependency: No module named 'kubernetes'"}]},
+		"OSD_DOWN":{"severity":"HEALTH_WARN","summary":{"message":"1 osds down"},"detail":[{"message":"osd.1 is down"}]}}}`
+	modules, err = FailedMgrModules(context, AdminClusterInfo("mycluster"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"dashboard": "Module 'dashboard' has failed: timeout",
+		"k8sevents": "Module 'k8sevents' has failed dependency: No module named 'kubernetes'",
+	}, modules)
+}
@@ -27,6 +27,7 @@ import (
 type MonStatusResponse struct {
 	Quorum []int `json:"quorum"`
 	MonMap struct {
+		FSID string        `json:"fsid"`
 		Mons []MonMapEntry `json:"mons"`
 	} `json:"monmap"`
 }
@@ -17,6 +17,7 @@ package client
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -66,6 +67,26 @@ type OSDDump struct {
 	} `json:"osds"`
 	Flags          string              `json:"flags"`
 	CrushNodeFlags map[string][]string `json:"crush_node_flags"`
+	Pools          []struct {
+		Pool      int    `json:"pool"`
+		Name      string `json:"pool_name"`
+		Size      int    `json:"size"`
+		CrushRule int    `json:"crush_rule"`
+	} `json:"pools"`
+	FullRatio         float64 `json:"full_ratio"`
+	BackfillFullRatio float64 `json:"backfillfull_ratio"`
+	NearFullRatio     float64 `json:"nearfull_ratio"`
+}
+
+// OSDReweights is the result of a reweight of the osds by utilization
+type OSDReweights struct {
+	AverageUtilization  float64 `json:"average_utilization"`
+	OverloadUtilization float64 `json:"overload_utilization"`
+	Reweights           []struct {
+		OSD       int     `json:"osd"`
+		Weight    float64 `json:"weight"`
+		NewWeight float64 `json:"new_weight"`
+	} `json:"reweights"`
 }
 
 // IsFlagSet checks if an OSD flag is set
@@ -174,6 +195,13 @@ type OsdTree struct {
 // OsdList returns the list of OSD by their IDs
 type OsdList []int
 
+// OSDMetadata is the metadata reported by an OSD
+type OSDMetadata struct {
+	ID          int    `json:"id"`
+	Hostname    string `json:"hostname"`
+	ObjectStore string `json:"osd_objectstore"`
+}
+
 // StatusByID returns status and inCluster states for given OSD id
 func (dump *OSDDump) StatusByID(id int64) (int64, int64, error) {
 	for _, d := range dump.OSDs {
@@ -246,12 +274,88 @@ func GetOSDDump(context *clusterd.Context, clusterInfo *ClusterInfo) (*OSDDump,
 	return &osdDump, nil
 }
 
+// GetOSDMetadata returns the metadata of all the OSDs
+func GetOSDMetadata(context *clusterd.Context, clusterInfo *ClusterInfo) ([]OSDMetadata, error) {
+	args := []string{"osd", "metadata"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get osd metadata")
+	}
+
+	var metadata []OSDMetadata
+	if err := json.Unmarshal(buf, &metadata); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal osd metadata response")
+	}
+	return metadata, nil
+}
+
+// DestroyOSD marks an osd destroyed so that its id can be reused by a new osd
+func DestroyOSD(context *clusterd.Context, clusterInfo *ClusterInfo, osdID int) error {
+	args := []string{"osd", "destroy", strconv.Itoa(osdID), "--yes-i-really-mean-it"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to destroy osd.%d. %s", osdID, string(buf))
+	}
+	return nil
+}
+
 func OSDOut(context *clusterd.Context, clusterInfo *ClusterInfo, osdID int) (string, error) {
 	args := []string{"osd", "out", strconv.Itoa(osdID)}
 	buf, err := NewCephCommand(context, clusterInfo, args).Run()
 	return string(buf), err
 }
 
+// OSDIn marks in an osd
+func OSDIn(context *clusterd.Context, clusterInfo *ClusterInfo, osdID int) error {
+	args := []string{"osd", "in", strconv.Itoa(osdID)}
+	if _, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to mark in osd.%d", osdID)
+	}
+	return nil
+}
+
+// SetOSDFullRatio sets the "full", "backfillfull" or "nearfull" ratio of the osds
+func SetOSDFullRatio(context *clusterd.Context, clusterInfo *ClusterInfo, name string, ratio float64) error {
+	args := []string{"osd", fmt.Sprintf("set-%s-ratio", name), strconv.FormatFloat(ratio, 'f', -1, 64)}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to set the %s ratio of the osds to %v. %s", name, ratio, string(buf))
+	}
+	return nil
+}
+
+// ReweightOSDsByUtilization lowers the weight of at most maxOSDs osds whose utilization is above the threshold, in
+// percent of the average utilization, by at most maxChange. The weights are never increased.
+func ReweightOSDsByUtilization(context *clusterd.Context, clusterInfo *ClusterInfo, threshold int, maxChange float64, maxOSDs int) (*OSDReweights, error) {
+	args := []string{"osd", "reweight-by-utilization", strconv.Itoa(threshold), strconv.FormatFloat(maxChange, 'f', -1, 64), strconv.Itoa(maxOSDs), "--no-increasing"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to reweight the osds by utilization. %s", string(buf))
+	}
+	var reweights OSDReweights
+	// ceph only answers "no change" when no osd is above the threshold
+	if len(strings.TrimSpace(string(buf))) == 0 {
+		return &reweights, nil
+	}
+	if err := json.Unmarshal(buf, &reweights); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the reweights of the osds")
+	}
+	return &reweights, nil
+}
+
+// OSDOkToStop checks whether the given osds can be stopped at the same time without making any pg unavailable
+func OSDOkToStop(context *clusterd.Context, clusterInfo *ClusterInfo, osdIDs []int) error {
+	args := []string{"osd", "ok-to-stop"}
+	for _, id := range osdIDs {
+		args = append(args, strconv.Itoa(id))
+	}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "osds %v are not ok to stop. %s", osdIDs, string(buf))
+	}
+	return nil
+}
+
 func OsdSafeToDestroy(context *clusterd.Context, clusterInfo *ClusterInfo, osdID int) (bool, error) {
 	args := []string{"osd", "safe-to-destroy", strconv.Itoa(osdID)}
 	cmd := NewCephCommand(context, clusterInfo, args)
@@ -19,6 +19,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -28,12 +29,13 @@ import (
 )
 
 const (
-	confirmFlag             = "--yes-i-really-mean-it"
-	reallyConfirmFlag       = "--yes-i-really-really-mean-it"
-	targetSizeRatioProperty = "target_size_ratio"
-	compressionModeProperty = "compression_mode"
-	PgAutoscaleModeProperty = "pg_autoscale_mode"
-	PgAutoscaleModeOn       = "on"
+	confirmFlag                  = "--yes-i-really-mean-it"
+	reallyConfirmFlag            = "--yes-i-really-really-mean-it"
+	targetSizeRatioProperty      = "target_size_ratio"
+	compressionModeProperty      = "compression_mode"
+	compressionAlgorithmProperty = "compression_algorithm"
+	PgAutoscaleModeProperty      = "pg_autoscale_mode"
+	PgAutoscaleModeOn            = "on"
 )
 
 type CephStoragePoolSummary struct {
@@ -45,20 +47,34 @@ type CephStoragePoolDetails struct {
 	Name                   string  `json:"pool"`
 	Number                 int     `json:"pool_id"`
 	Size                   uint    `json:"size"`
+	MinSize                uint    `json:"min_size"`
+	PgNum                  int     `json:"pg_num"`
+	CrushRule              string  `json:"crush_rule"`
 	ErasureCodeProfile     string  `json:"erasure_code_profile"`
 	FailureDomain          string  `json:"failureDomain"`
 	CrushRoot              string  `json:"crushRoot"`
 	DeviceClass            string  `json:"deviceClass"`
 	CompressionMode        string  `json:"compression_mode"`
+	CompressionAlgorithm   string  `json:"compression_algorithm"`
+	PgAutoscaleMode        string  `json:"pg_autoscale_mode"`
 	TargetSizeRatio        float64 `json:"target_size_ratio,omitempty"`
 	RequireSafeReplicaSize bool    `json:"requireSafeReplicaSize,omitempty"`
 }
 
 type CephStoragePoolStats struct {
+	Stats struct {
+		TotalUsedRawBytes float64 `json:"total_used_raw_bytes"`
+	} `json:"stats"`
+	StatsByClass map[string]struct {
+		TotalBytes        uint64 `json:"total_bytes"`
+		TotalAvailBytes   uint64 `json:"total_avail_bytes"`
+		TotalUsedRawBytes uint64 `json:"total_used_raw_bytes"`
+	} `json:"stats_by_class"`
 	Pools []struct {
 		Name  string `json:"name"`
 		ID    int    `json:"id"`
 		Stats struct {
+			Stored       float64 `json:"stored"`
 			BytesUsed    float64 `json:"bytes_used"`
 			RawBytesUsed float64 `json:"raw_bytes_used"`
 			MaxAvail     float64 `json:"max_avail"`
@@ -164,6 +180,7 @@ func CreatePoolWithProfile(context *clusterd.Context, clusterInfo *ClusterInfo,
 	}
 
 	// If the pool is not a replicated pool, then the only other option is an erasure coded pool.
+	// The erasure coded pools of the RBD images and of the CephFS data must allow overwrites.
 	return CreateECPoolForApp(
 		context,
 		clusterInfo,
@@ -235,27 +252,79 @@ func givePoolAppTag(context *clusterd.Context, clusterInfo *ClusterInfo, poolNam
 	return nil
 }
 
-func setCommonPoolProperties(context *clusterd.Context, clusterInfo *ClusterInfo, pool cephv1.PoolSpec, poolName, appName string) error {
-	if len(pool.Parameters) == 0 {
-		pool.Parameters = make(map[string]string)
+// poolProperties returns the properties of a pool set from its spec, the settings of the spec override the parameters
+func poolProperties(pool cephv1.PoolSpec) map[string]string {
+	properties := make(map[string]string)
+	for propName, propValue := range pool.Parameters {
+		properties[propName] = propValue
 	}
 
-	if pool.Replicated.IsTargetRatioEnabled() {
-		pool.Parameters[targetSizeRatioProperty] = strconv.FormatFloat(pool.Replicated.TargetSizeRatio, 'f', -1, 32)
+	if targetSizeRatio := pool.EffectiveTargetSizeRatio(); targetSizeRatio != 0 {
+		properties[targetSizeRatioProperty] = formatTargetSizeRatio(targetSizeRatio)
 	}
 
 	if pool.IsCompressionEnabled() {
-		pool.Parameters[compressionModeProperty] = pool.CompressionMode
+		properties[compressionModeProperty] = pool.CompressionMode
+	}
+
+	if pool.CompressionAlgorithm != "" {
+		properties[compressionAlgorithmProperty] = pool.CompressionAlgorithm
+	}
+
+	if pool.PgAutoscaleMode != "" {
+		properties[PgAutoscaleModeProperty] = pool.PgAutoscaleMode
 	}
+	return properties
+}
+
+func formatTargetSizeRatio(ratio float64) string {
+	return strconv.FormatFloat(ratio, 'f', -1, 32)
+}
 
+// UpdatePoolProperties sets the compression, the pg autoscale mode and the target size ratio of an existing pool when
+// they differ from its spec, like when they were changed out-of-band, and returns the names of the properties reset
+func UpdatePoolProperties(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string, pool cephv1.PoolSpec) ([]string, error) {
+	details, err := GetPoolDetails(context, clusterInfo, poolName)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]string{
+		targetSizeRatioProperty:      formatTargetSizeRatio(details.TargetSizeRatio),
+		compressionModeProperty:      details.CompressionMode,
+		compressionAlgorithmProperty: details.CompressionAlgorithm,
+		PgAutoscaleModeProperty:      details.PgAutoscaleMode,
+	}
+
+	updated := []string{}
+	for propName, propValue := range poolProperties(pool) {
+		// the other parameters are not reported in the details of the pool
+		currentValue, ok := current[propName]
+		if !ok || currentValue == propValue {
+			continue
+		}
+		logger.Infof("resetting property %q of pool %q from %q to %q", propName, poolName, currentValue, propValue)
+		if err := SetPoolProperty(context, clusterInfo, poolName, propName, propValue); err != nil {
+			return updated, err
+		}
+		updated = append(updated, propName)
+	}
+	sort.Strings(updated)
+	return updated, nil
+}
+
+func setCommonPoolProperties(context *clusterd.Context, clusterInfo *ClusterInfo, pool cephv1.PoolSpec, poolName, appName string) error {
 	// Apply properties
-	for propName, propValue := range pool.Parameters {
+	for propName, propValue := range poolProperties(pool) {
 		err := SetPoolProperty(context, clusterInfo, poolName, propName, propValue)
 		if err != nil {
 			logger.Errorf("failed to set property %q to pool %q to %q. %v", propName, poolName, propValue, err)
 		}
 	}
 
+	if err := SetPoolQuotas(context, clusterInfo, poolName, pool.Quotas); err != nil {
+		return err
+	}
+
 	// ensure that the newly created pool gets an application tag
 	if appName != "" {
 		err := givePoolAppTag(context, clusterInfo, poolName, appName)
@@ -294,11 +363,17 @@ func CreateECPoolForApp(context *clusterd.Context, clusterInfo *ClusterInfo, poo
 
 func CreateReplicatedPoolForApp(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string, pool cephv1.PoolSpec, pgCount, appName string) error {
 	// create a crush rule for a replicated pool, if a failure domain is specified
-	if err := createReplicationCrushRule(context, clusterInfo, poolName, pool); err != nil {
+	crushRuleName := poolName
+	if pool.Replicated.IsHybridStoragePool() {
+		crushRuleName = hybridCrushRuleName(poolName, pool.Replicated.HybridStorage)
+		if err := createHybridCrushRule(context, clusterInfo, crushRuleName, pool); err != nil {
+			return err
+		}
+	} else if err := createReplicationCrushRule(context, clusterInfo, poolName, pool); err != nil {
 		return err
 	}
 
-	args := []string{"osd", "pool", "create", poolName, pgCount, "replicated", poolName, "--size", strconv.FormatUint(uint64(pool.Replicated.Size), 10)}
+	args := []string{"osd", "pool", "create", poolName, pgCount, "replicated", crushRuleName, "--size", strconv.FormatUint(uint64(pool.Replicated.Size), 10)}
 	output, err := NewCephCommand(context, clusterInfo, args).Run()
 	if err != nil {
 		return errors.Wrapf(err, "failed to create replicated pool %s. %s", poolName, string(output))
@@ -309,6 +384,13 @@ func CreateReplicatedPoolForApp(context *clusterd.Context, clusterInfo *ClusterI
 		return errors.Wrapf(err, "failed to set size property to replicated pool %q to %d", poolName, pool.Replicated.Size)
 	}
 
+	// the existing pools are moved to the crush rule of their device classes
+	if pool.Replicated.IsHybridStoragePool() {
+		if err := SetPoolProperty(context, clusterInfo, poolName, crushRulePoolProperty, crushRuleName); err != nil {
+			return err
+		}
+	}
+
 	if err = setCommonPoolProperties(context, clusterInfo, pool, poolName, appName); err != nil {
 		return err
 	}
@@ -324,7 +406,7 @@ func createReplicationCrushRule(context *clusterd.Context, clusterInfo *ClusterI
 	}
 
 	// set the crush root to the default if not already specified
-	crushRoot := "default"
+	crushRoot := cephv1.DefaultCRUSHRoot
 	if pool.CrushRoot != "" {
 		crushRoot = pool.CrushRoot
 	}
@@ -355,6 +437,21 @@ func SetPoolProperty(context *clusterd.Context, clusterInfo *ClusterInfo, name,
 	return nil
 }
 
+// SetPoolQuotas sets the quotas of a pool set in its spec, a quota of 0 removes the quota
+func SetPoolQuotas(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string, quotas cephv1.QuotaSpec) error {
+	values := []*uint64{quotas.MaxBytes, quotas.MaxObjects}
+	for i, quota := range []string{"max_bytes", "max_objects"} {
+		if values[i] == nil {
+			continue
+		}
+		args := []string{"osd", "pool", "set-quota", poolName, quota, strconv.FormatUint(*values[i], 10)}
+		if output, err := NewCephCommand(context, clusterInfo, args).Run(); err != nil {
+			return errors.Wrapf(err, "failed to set quota %q of pool %q. %s", quota, poolName, string(output))
+		}
+	}
+	return nil
+}
+
 // SetPoolReplicatedSizeProperty sets the replica size of a pool
 func SetPoolReplicatedSizeProperty(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, size string) error {
 	propName := "size"
@@ -386,6 +483,34 @@ func GetPoolStats(context *clusterd.Context, clusterInfo *ClusterInfo) (*CephSto
 	return &poolStats, nil
 }
 
+// GetPoolPGStates returns the number of placement groups of a pool in each state
+func GetPoolPGStates(context *clusterd.Context, clusterInfo *ClusterInfo, name string) (map[string]int, error) {
+	args := []string{"pg", "ls-by-pool", name}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the placement groups of pool %q", name)
+	}
+
+	type pgStat struct {
+		State string `json:"state"`
+	}
+	// from nautilus 14.2.5 the placement groups are listed in "pg_stats"
+	var pgs struct {
+		PGStats []pgStat `json:"pg_stats"`
+	}
+	if err := json.Unmarshal(output, &pgs); err != nil {
+		if err := json.Unmarshal(output, &pgs.PGStats); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal the placement groups")
+		}
+	}
+
+	states := map[string]int{}
+	for _, pg := range pgs.PGStats {
+		states[pg.State]++
+	}
+	return states, nil
+}
+
 func GetPoolStatistics(context *clusterd.Context, clusterInfo *ClusterInfo, name string) (*PoolStatistics, error) {
 	args := []string{"pool", "stats", name}
 	cmd := NewRBDCommand(context, clusterInfo, args)
@@ -193,6 +193,32 @@ func testIsStringInSlice(a string, list []string) bool {
 	return false
 }
 
+func TestSetPoolQuotas(t *testing.T) {
+	quotas := map[string]string{}
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		if args[0] == "osd" && args[1] == "pool" && args[2] == "set-quota" {
+			assert.Equal(t, "mypool", args[3])
+			quotas[args[4]] = args[5]
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	// the quotas not set are not changed
+	maxBytes := uint64(1024)
+	err := SetPoolQuotas(context, AdminClusterInfo("mycluster"), "mypool", cephv1.QuotaSpec{MaxBytes: &maxBytes})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"max_bytes": "1024"}, quotas)
+
+	// a quota of 0 is removed
+	maxObjects := uint64(0)
+	err = SetPoolQuotas(context, AdminClusterInfo("mycluster"), "mypool", cephv1.QuotaSpec{MaxBytes: &maxBytes, MaxObjects: &maxObjects})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"max_bytes": "1024", "max_objects": "0"}, quotas)
+}
+
 func TestGetPoolStatistics(t *testing.T) {
 	p := PoolStatistics{}
 	p.Images.Count = 1
@@ -267,3 +293,64 @@ func TestSetPoolReplicatedSizeProperty(t *testing.T) {
 	err = SetPoolReplicatedSizeProperty(context, AdminClusterInfo("mycluster"), poolName, "1")
 	assert.NoError(t, err)
 }
+
+func TestGetPoolPGStates(t *testing.T) {
+	output := `{"pg_ready":true,"pg_stats":[{"pgid":"1.0","state":"active+clean"},{"pgid":"1.1","state":"peering"}]}`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			assert.Equal(t, []string{"pg", "ls-by-pool", "mypool"}, args[:3])
+			return output, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	states, err := GetPoolPGStates(context, AdminClusterInfo("mycluster"), "mypool")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"active+clean": 1, "peering": 1}, states)
+
+	// the placement groups are listed in an array before nautilus 14.2.5
+	output = `[{"pgid":"1.0","state":"active+clean"},{"pgid":"1.1","state":"active+clean"}]`
+	states, err = GetPoolPGStates(context, AdminClusterInfo("mycluster"), "mypool")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"active+clean": 2}, states)
+}
+
+func TestUpdatePoolProperties(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	properties := map[string]string{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		if args[1] == "pool" && args[2] == "get" {
+			// the compression algorithm and the target size ratio were changed out-of-band
+			return `{"pool":"mypool","size":3}{"pool":"mypool","compression_mode":"aggressive"}{"pool":"mypool","compression_algorithm":"zlib"}` +
+				`{"pool":"mypool","pg_autoscale_mode":"on"}{"pool":"mypool","target_size_ratio":0.1}`, nil
+		}
+		if args[1] == "pool" && args[2] == "set" {
+			properties[args[4]] = args[5]
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	p := cephv1.PoolSpec{
+		CompressionMode:      "aggressive",
+		CompressionAlgorithm: "snappy",
+		PgAutoscaleMode:      "on",
+		TargetSizeRatio:      0.5,
+		Replicated:           cephv1.ReplicatedSpec{Size: 3, TargetSizeRatio: 0.2},
+		Parameters:           map[string]string{"min_size": "2"},
+	}
+	updated, err := UpdatePoolProperties(context, AdminClusterInfo("mycluster"), "mypool", p)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"compression_algorithm", "target_size_ratio"}, updated)
+	assert.Equal(t, map[string]string{"compression_algorithm": "snappy", "target_size_ratio": "0.5"}, properties)
+
+	// the target size ratio of the replication settings applies when the pool has none
+	p.TargetSizeRatio = 0
+	p.CompressionAlgorithm = "zlib"
+	properties = map[string]string{}
+	updated, err = UpdatePoolProperties(context, AdminClusterInfo("mycluster"), "mypool", p)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"target_size_ratio"}, updated)
+	assert.Equal(t, "0.2", properties["target_size_ratio"])
+}
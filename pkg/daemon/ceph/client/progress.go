@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// ProgressEvent is a long-running operation tracked by the progress mgr module, like the recovery of the data of an
+// OSD marked out or the change of the number of placement groups of a pool
+type ProgressEvent struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	// Progress is the completion of the operation, between 0 and 1
+	Progress float64 `json:"progress"`
+	// Refs are the objects concerned by the operation as pairs of a type and an id, like ["pool", 2] or ["osd", 1]
+	Refs           [][]interface{} `json:"refs"`
+	Failed         bool            `json:"failed"`
+	FailureMessage string          `json:"failure_message"`
+}
+
+// ProgressEvents are the operations in progress and the recently completed operations of the progress mgr module
+type ProgressEvents struct {
+	Events    []ProgressEvent `json:"events"`
+	Completed []ProgressEvent `json:"completed"`
+}
+
+// References returns whether the operation concerns the object of the type and id
+func (e ProgressEvent) References(refType string, id int) bool {
+	for _, ref := range e.Refs {
+		if len(ref) != 2 {
+			continue
+		}
+		// the ids are decoded as json numbers
+		if t, ok := ref[0].(string); ok && t == refType {
+			if refID, ok := ref[1].(float64); ok && int(refID) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PoolEvents returns the operations of the pool with the id
+func (p *ProgressEvents) PoolEvents(poolID int) *ProgressEvents {
+	filter := func(events []ProgressEvent) []ProgressEvent {
+		result := []ProgressEvent{}
+		for _, e := range events {
+			if e.References("pool", poolID) {
+				result = append(result, e)
+			}
+		}
+		return result
+	}
+	return &ProgressEvents{Events: filter(p.Events), Completed: filter(p.Completed)}
+}
+
+// GetProgressEvents returns the operations tracked by the progress mgr module
+func GetProgressEvents(context *clusterd.Context, clusterInfo *ClusterInfo) (*ProgressEvents, error) {
+	args := []string{"progress", "json"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the progress events")
+	}
+
+	var events ProgressEvents
+	if err := json.Unmarshal(buf, &events); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal progress json response. %s", string(buf))
+	}
+	return &events, nil
+}
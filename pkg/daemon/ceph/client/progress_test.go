@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProgressEvents(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "progress" && args[1] == "json" {
+			return `{"events":[{"id":"a1b2","message":"PG autoscaler increasing pool 2 PGs from 32 to 128","refs":[["pool",2]],"progress":0.25},
+				{"id":"c3d4","message":"Rebalancing after osd.1 marked out","refs":[["osd",1]],"progress":0.5}],
+				"completed":[{"id":"e5f6","message":"PG autoscaler decreasing pool 3 PGs from 64 to 32","refs":[["pool",3]],"progress":1.0,
+				"failed":false,"failure_message":null}]}`, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	events, err := GetProgressEvents(context, AdminClusterInfo("mycluster"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(events.Events))
+	assert.Equal(t, 0.25, events.Events[0].Progress)
+	assert.True(t, events.Events[0].References("pool", 2))
+	assert.False(t, events.Events[0].References("pool", 1))
+	assert.False(t, events.Events[1].References("pool", 1))
+	assert.True(t, events.Events[1].References("osd", 1))
+
+	// the operations of a pool
+	poolEvents := events.PoolEvents(2)
+	assert.Equal(t, 1, len(poolEvents.Events))
+	assert.Equal(t, "a1b2", poolEvents.Events[0].ID)
+	assert.Empty(t, poolEvents.Completed)
+	poolEvents = events.PoolEvents(3)
+	assert.Empty(t, poolEvents.Events)
+	assert.Equal(t, "e5f6", poolEvents.Completed[0].ID)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+const (
+	// RBDMirroringModeDisabled is the mirroring mode of the pools and namespaces not mirrored
+	RBDMirroringModeDisabled = "disabled"
+	// RBDMirroringModeImage mirrors the images with mirroring enabled
+	RBDMirroringModeImage = "image"
+	// RBDMirroringModePool mirrors all the images with the journaling feature
+	RBDMirroringModePool = "pool"
+)
+
+type radosNamespace struct {
+	Name string `json:"name"`
+}
+
+type rbdMirroringInfo struct {
+	Mode string `json:"mode"`
+}
+
+func radosNamespaceArgs(poolName, namespace string) []string {
+	return []string{fmt.Sprintf("--pool=%s", poolName), fmt.Sprintf("--namespace=%s", namespace)}
+}
+
+// ListRadosNamespaces returns the names of the RADOS namespaces of an RBD pool
+func ListRadosNamespaces(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) ([]string, error) {
+	cmd := NewRBDCommand(context, clusterInfo, []string{"namespace", "ls", fmt.Sprintf("--pool=%s", poolName)})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the rados namespaces of pool %q. %s", poolName, string(buf))
+	}
+	var namespaces []radosNamespace
+	if err := json.Unmarshal(buf, &namespaces); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the rados namespaces of pool %q, raw buffer response: %s", poolName, string(buf))
+	}
+	names := []string{}
+	for _, namespace := range namespaces {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
+
+// CreateRadosNamespace creates a RADOS namespace in an RBD pool if it does not exist
+func CreateRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) error {
+	namespaces, err := ListRadosNamespaces(context, clusterInfo, poolName)
+	if err != nil {
+		return err
+	}
+	for _, name := range namespaces {
+		if name == namespace {
+			logger.Debugf("rados namespace %q already exists in pool %q", namespace, poolName)
+			return nil
+		}
+	}
+
+	logger.Infof("creating rados namespace %q in pool %q", namespace, poolName)
+	args := append([]string{"namespace", "create"}, radosNamespaceArgs(poolName, namespace)...)
+	if buf, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to create rados namespace %q in pool %q. %s", namespace, poolName, string(buf))
+	}
+	return nil
+}
+
+// DeleteRadosNamespace removes a RADOS namespace from an RBD pool if it exists. The namespace must have no images.
+func DeleteRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) error {
+	namespaces, err := ListRadosNamespaces(context, clusterInfo, poolName)
+	if err != nil {
+		return err
+	}
+	for _, name := range namespaces {
+		if name != namespace {
+			continue
+		}
+		logger.Infof("removing rados namespace %q from pool %q", namespace, poolName)
+		args := append([]string{"namespace", "remove"}, radosNamespaceArgs(poolName, namespace)...)
+		if buf, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+			return errors.Wrapf(err, "failed to remove rados namespace %q from pool %q. %s", namespace, poolName, string(buf))
+		}
+	}
+	return nil
+}
+
+// ListRadosNamespaceImages returns the images of a RADOS namespace of an RBD pool
+func ListRadosNamespaceImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]CephBlockImage, error) {
+	return listImages(context, clusterInfo, poolName, append([]string{"ls", "-l"}, radosNamespaceArgs(poolName, namespace)...))
+}
+
+// GetRadosNamespaceMirroringMode returns the RBD mirroring mode of a RADOS namespace
+func GetRadosNamespaceMirroringMode(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) (string, error) {
+	args := append([]string{"mirror", "pool", "info"}, radosNamespaceArgs(poolName, namespace)...)
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the mirroring info of rados namespace %q of pool %q. %s", namespace, poolName, string(buf))
+	}
+	var info rbdMirroringInfo
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal the mirroring info of rados namespace %q of pool %q, raw buffer response: %s", namespace, poolName, string(buf))
+	}
+	return info.Mode, nil
+}
+
+// SetRadosNamespaceMirroringMode enables the RBD mirroring of a RADOS namespace in the given mode, or disables it
+// with the "disabled" mode
+func SetRadosNamespaceMirroringMode(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, mode string) error {
+	current, err := GetRadosNamespaceMirroringMode(context, clusterInfo, poolName, namespace)
+	if err != nil {
+		return err
+	}
+	if current == mode {
+		return nil
+	}
+
+	var args []string
+	if mode == RBDMirroringModeDisabled {
+		args = append([]string{"mirror", "pool", "disable"}, radosNamespaceArgs(poolName, namespace)...)
+	} else {
+		args = append(append([]string{"mirror", "pool", "enable"}, radosNamespaceArgs(poolName, namespace)...), mode)
+	}
+	logger.Infof("setting the mirroring mode of rados namespace %q of pool %q to %q", namespace, poolName, mode)
+	if buf, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+		return errors.Wrapf(err, "failed to set the mirroring mode of rados namespace %q of pool %q to %q. %s", namespace, poolName, mode, string(buf))
+	}
+	return nil
+}
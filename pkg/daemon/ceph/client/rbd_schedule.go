@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// RBDScheduleType is a type of the schedules of the rbd_support mgr module
+type RBDScheduleType string
+
+const (
+	// RBDMirrorSnapshotSchedule creates the mirror snapshots of the images with snapshot-based mirroring
+	RBDMirrorSnapshotSchedule RBDScheduleType = "mirror snapshot"
+	// RBDTrashPurgeSchedule removes the images of the trash whose deferment ended
+	RBDTrashPurgeSchedule RBDScheduleType = "trash purge"
+)
+
+type rbdSchedule struct {
+	Interval  string `json:"interval"`
+	StartTime string `json:"start_time"`
+}
+
+func rbdScheduleArgs(scheduleType RBDScheduleType, command, poolName string) []string {
+	args := append(strings.Fields(string(scheduleType)), "schedule", command)
+	return append(args, fmt.Sprintf("--pool=%s", poolName))
+}
+
+func rbdScheduleSpecArgs(schedule cephv1.RBDScheduleSpec) []string {
+	if schedule.StartTime == "" {
+		return []string{schedule.Interval}
+	}
+	return []string{schedule.Interval, schedule.StartTime}
+}
+
+// ListRBDSchedules returns the schedules of a type set on an RBD pool
+func ListRBDSchedules(context *clusterd.Context, clusterInfo *ClusterInfo, scheduleType RBDScheduleType, poolName string) ([]cephv1.RBDScheduleSpec, error) {
+	cmd := NewRBDCommand(context, clusterInfo, rbdScheduleArgs(scheduleType, "ls", poolName))
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the %s schedules of pool %q. %s", scheduleType, poolName, string(buf))
+	}
+	schedules := []cephv1.RBDScheduleSpec{}
+	if strings.TrimSpace(string(buf)) == "" {
+		return schedules, nil
+	}
+	var list []rbdSchedule
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the %s schedules of pool %q, raw buffer response: %s", scheduleType, poolName, string(buf))
+	}
+	for _, schedule := range list {
+		schedules = append(schedules, cephv1.RBDScheduleSpec{Interval: schedule.Interval, StartTime: schedule.StartTime})
+	}
+	return schedules, nil
+}
+
+// SetRBDSchedules sets the schedules of a type of an RBD pool, the schedules of the pool which are not in the list are
+// removed
+func SetRBDSchedules(context *clusterd.Context, clusterInfo *ClusterInfo, scheduleType RBDScheduleType, poolName string, schedules []cephv1.RBDScheduleSpec) error {
+	current, err := ListRBDSchedules(context, clusterInfo, scheduleType, poolName)
+	if err != nil {
+		return err
+	}
+	contains := func(list []cephv1.RBDScheduleSpec, schedule cephv1.RBDScheduleSpec) bool {
+		for _, s := range list {
+			if s == schedule {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, schedule := range current {
+		if contains(schedules, schedule) {
+			continue
+		}
+		logger.Infof("removing %s schedule %v from pool %q", scheduleType, rbdScheduleSpecArgs(schedule), poolName)
+		args := append(rbdScheduleArgs(scheduleType, "remove", poolName), rbdScheduleSpecArgs(schedule)...)
+		if buf, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+			return errors.Wrapf(err, "failed to remove %s schedule %v from pool %q. %s", scheduleType, rbdScheduleSpecArgs(schedule), poolName, string(buf))
+		}
+	}
+	for _, schedule := range schedules {
+		if contains(current, schedule) {
+			continue
+		}
+		logger.Infof("adding %s schedule %v to pool %q", scheduleType, rbdScheduleSpecArgs(schedule), poolName)
+		args := append(rbdScheduleArgs(scheduleType, "add", poolName), rbdScheduleSpecArgs(schedule)...)
+		if buf, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+			return errors.Wrapf(err, "failed to add %s schedule %v to pool %q. %s", scheduleType, rbdScheduleSpecArgs(schedule), poolName, string(buf))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRBDSchedules(t *testing.T) {
+	list := `[{"interval":"1d","start_time":""},{"interval":"12h","start_time":"14:00:00-05:00"}]`
+	commands := []string{}
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		assert.Equal(t, RBDTool, command)
+		// the command without the connection args
+		c := []string{}
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "--cluster") {
+				break
+			}
+			c = append(c, arg)
+		}
+		if c[3] == "ls" {
+			return list, nil
+		}
+		commands = append(commands, strings.Join(c, " "))
+		return "", nil
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	schedules, err := ListRBDSchedules(context, AdminClusterInfo("mycluster"), RBDTrashPurgeSchedule, "mypool")
+	assert.NoError(t, err)
+	assert.Equal(t, []cephv1.RBDScheduleSpec{{Interval: "1d"}, {Interval: "12h", StartTime: "14:00:00-05:00"}}, schedules)
+
+	// the schedules not in the spec are removed, the missing schedules are added
+	err = SetRBDSchedules(context, AdminClusterInfo("mycluster"), RBDMirrorSnapshotSchedule, "mypool", []cephv1.RBDScheduleSpec{{Interval: "1d"}, {Interval: "30m"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"mirror snapshot schedule remove --pool=mypool 12h 14:00:00-05:00",
+		"mirror snapshot schedule add --pool=mypool 30m",
+	}, commands)
+
+	// no schedules
+	list = ""
+	commands = []string{}
+	err = SetRBDSchedules(context, AdminClusterInfo("mycluster"), RBDTrashPurgeSchedule, "mypool", []cephv1.RBDScheduleSpec{{Interval: "1d", StartTime: "02:00"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trash purge schedule add --pool=mypool 1d 02:00"}, commands)
+}
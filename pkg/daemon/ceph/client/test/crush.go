@@ -0,0 +1,37 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package test
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CrushDumpResponse returns the crush map of a cluster with one hdd OSD on each host under the default root
+func CrushDumpResponse(hostCount int) string {
+	devices := []string{}
+	hosts := []string{}
+	rootItems := []string{}
+	for i := 0; i < hostCount; i++ {
+		hostID := -2 - i
+		devices = append(devices, fmt.Sprintf(`{"id":%d,"name":"osd.%d","class":"hdd"}`, i, i))
+		hosts = append(hosts, fmt.Sprintf(`{"id":%d,"name":"host-%d","type_id":1,"type_name":"host","items":[{"id":%d}]}`, hostID, i, i))
+		rootItems = append(rootItems, fmt.Sprintf(`{"id":%d}`, hostID))
+	}
+	root := fmt.Sprintf(`{"id":-1,"name":"default","type_id":11,"type_name":"root","items":[%s]}`, strings.Join(rootItems, ","))
+	return fmt.Sprintf(`{"devices":[%s],"types":[{"type_id":0,"name":"osd"},{"type_id":1,"name":"host"},{"type_id":3,"name":"rack"},{"type_id":11,"name":"root"}],"buckets":[%s]}`,
+		strings.Join(devices, ","), strings.Join(append([]string{root}, hosts...), ","))
+}
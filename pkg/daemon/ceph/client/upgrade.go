@@ -175,18 +175,24 @@ func OkToStop(context *clusterd.Context, clusterInfo *ClusterInfo, deployment, d
 
 // OkToContinue determines if it's ok to continue an upgrade
 func OkToContinue(context *clusterd.Context, clusterInfo *ClusterInfo, deployment, daemonType, daemonName string) error {
+	return OkToContinueWithTimeout(context, clusterInfo, deployment, daemonType, daemonName, 0)
+}
+
+// OkToContinueWithTimeout determines if it's ok to continue an upgrade, waiting up to the timeout for the daemon.
+// The default timeouts of the daemon types are used when the timeout is 0.
+func OkToContinueWithTimeout(context *clusterd.Context, clusterInfo *ClusterInfo, deployment, daemonType, daemonName string, waitTimeout time.Duration) error {
 	// the mon case is handled directly in the deployment where the mon checks for quorum
 	switch daemonType {
 	case "osd":
 		if osdDoNothing(context, clusterInfo) {
 			return nil
 		}
-		err := okToContinueOSDDaemon(context, clusterInfo)
+		err := okToContinueOSDDaemon(context, clusterInfo, waitTimeout)
 		if err != nil {
 			return errors.Wrapf(err, "failed to check if %s was ok to continue", deployment)
 		}
 	case "mds":
-		err := okToContinueMDSDaemon(context, clusterInfo, deployment, daemonType, daemonName)
+		err := okToContinueMDSDaemon(context, clusterInfo, deployment, waitTimeout)
 		if err != nil {
 			return errors.Wrapf(err, "failed to check if %s was ok to continue", deployment)
 		}
@@ -195,6 +201,22 @@ func OkToContinue(context *clusterd.Context, clusterInfo *ClusterInfo, deploymen
 	return nil
 }
 
+// OkToContinueNoWait determines if it's ok to continue with the next daemon after a restarted daemon, without waiting
+// for the PGs to be clean or the filesystem to be active again
+func OkToContinueNoWait(context *clusterd.Context, clusterInfo *ClusterInfo, deployment, daemonType string) error {
+	switch daemonType {
+	case "osd":
+		if osdDoNothing(context, clusterInfo) {
+			return nil
+		}
+		return IsClusterCleanError(context, clusterInfo)
+	case "mds":
+		return MdsActiveOrStandbyReplay(context, clusterInfo, findFSName(deployment))
+	}
+
+	return nil
+}
+
 func okToStopDaemon(context *clusterd.Context, clusterInfo *ClusterInfo, deployment, daemonType, daemonName string) error {
 	if !stringInSlice(daemonType, daemonNoCheck) {
 		args := []string{daemonType, "ok-to-stop", daemonName}
@@ -216,9 +238,9 @@ func okToStopDaemon(context *clusterd.Context, clusterInfo *ClusterInfo, deploym
 
 // okToContinueOSDDaemon determines whether it's fine to go to the next osd during an upgrade
 // This basically makes sure all the PGs have settled
-func okToContinueOSDDaemon(context *clusterd.Context, clusterInfo *ClusterInfo) error {
-	// Reconciliating PGs should not take too long so let's wait up to 10 minutes
-	err := util.Retry(10, 60*time.Second, func() error {
+func okToContinueOSDDaemon(context *clusterd.Context, clusterInfo *ClusterInfo, waitTimeout time.Duration) error {
+	// Reconciliating PGs should not take too long so let's wait up to 10 minutes by default
+	err := util.Retry(retryCount(waitTimeout, 60*time.Second, 10), 60*time.Second, func() error {
 		return IsClusterCleanError(context, clusterInfo)
 	})
 	if err != nil {
@@ -230,9 +252,9 @@ func okToContinueOSDDaemon(context *clusterd.Context, clusterInfo *ClusterInfo)
 
 // okToContinueMDSDaemon determines whether it's fine to go to the next mds during an upgrade
 // mostly a placeholder function for the future but since we have standby mds this shouldn't be needed
-func okToContinueMDSDaemon(context *clusterd.Context, clusterInfo *ClusterInfo, deployment, daemonType, daemonName string) error {
+func okToContinueMDSDaemon(context *clusterd.Context, clusterInfo *ClusterInfo, deployment string, waitTimeout time.Duration) error {
 	// wait for the MDS to be active again or in standby-replay
-	err := util.Retry(10, 15*time.Second, func() error {
+	err := util.Retry(retryCount(waitTimeout, 15*time.Second, 10), 15*time.Second, func() error {
 		return MdsActiveOrStandbyReplay(context, clusterInfo, findFSName(deployment))
 	})
 	if err != nil {
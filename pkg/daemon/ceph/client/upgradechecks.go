@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+)
+
+// continueUpgradeDaemonTypes are the daemon types whose upgrade continues after failed checks when the
+// continueUpgradeAfterChecksEvenIfNotHealthy setting of the cluster is set, the other types need an override
+var continueUpgradeDaemonTypes = []string{"osd", "mds", "rgw"}
+
+// UpgradeChecks are the checks of the daemons of a type during an upgrade
+type UpgradeChecks struct {
+	// Skip is whether the daemons are updated without checking that they are ok to stop and to continue
+	Skip bool
+	// ContinueIfNotHealthy is whether the upgrade continues when a daemon is not ok to stop or to continue
+	ContinueIfNotHealthy bool
+	// WaitTimeout is how long to wait for a daemon to be ok to continue, the default timeouts are used when it is 0
+	WaitTimeout time.Duration
+}
+
+// DaemonUpgradeChecks returns the upgrade checks of the daemons of the type, the settings of the type in
+// upgradeChecks take precedence over the settings of the cluster
+func DaemonUpgradeChecks(spec *cephv1.ClusterSpec, daemonType string) UpgradeChecks {
+	checks := UpgradeChecks{
+		Skip:                 spec.SkipUpgradeChecks,
+		ContinueIfNotHealthy: spec.ContinueUpgradeAfterChecksEvenIfNotHealthy && stringInSlice(daemonType, continueUpgradeDaemonTypes),
+	}
+	if spec.UpgradeChecks == nil {
+		return checks
+	}
+
+	waitTimeout := spec.UpgradeChecks.WaitTimeout
+	if daemonChecks, ok := spec.UpgradeChecks.Daemons[daemonType]; ok {
+		if daemonChecks.SkipUpgradeChecks != nil {
+			checks.Skip = *daemonChecks.SkipUpgradeChecks
+		}
+		if daemonChecks.ContinueUpgradeAfterChecksEvenIfNotHealthy != nil {
+			checks.ContinueIfNotHealthy = *daemonChecks.ContinueUpgradeAfterChecksEvenIfNotHealthy
+		}
+		if daemonChecks.WaitTimeout != "" {
+			waitTimeout = daemonChecks.WaitTimeout
+		}
+	}
+	if waitTimeout != "" {
+		// the timeouts are validated with the cluster spec
+		timeout, err := time.ParseDuration(waitTimeout)
+		if err != nil {
+			logger.Warningf("invalid upgrade wait timeout %q for the %s daemons, using the default timeout. %v", waitTimeout, daemonType, err)
+		} else {
+			checks.WaitTimeout = timeout
+		}
+	}
+	return checks
+}
+
+// retryCount returns the number of retries at the interval to wait up to the timeout, or the default count when
+// there is no timeout
+func retryCount(waitTimeout, interval time.Duration, defaultCount int) int {
+	if waitTimeout <= 0 {
+		return defaultCount
+	}
+	count := int(waitTimeout / interval)
+	if count < 1 {
+		return 1
+	}
+	return count
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonUpgradeChecks(t *testing.T) {
+	spec := &cephv1.ClusterSpec{ContinueUpgradeAfterChecksEvenIfNotHealthy: true}
+
+	// the cluster setting to continue only applies to the osds, mdss and rgws
+	assert.Equal(t, UpgradeChecks{ContinueIfNotHealthy: true}, DaemonUpgradeChecks(spec, "osd"))
+	assert.Equal(t, UpgradeChecks{}, DaemonUpgradeChecks(spec, "mon"))
+
+	// the settings of the daemon type take precedence
+	skip, noContinue := true, false
+	spec.UpgradeChecks = &cephv1.UpgradeChecksSpec{
+		WaitTimeout: "20m",
+		Daemons: map[string]cephv1.DaemonUpgradeChecksSpec{
+			"osd": {ContinueUpgradeAfterChecksEvenIfNotHealthy: &noContinue, WaitTimeout: "1h"},
+			"mgr": {SkipUpgradeChecks: &skip},
+		},
+	}
+	assert.Equal(t, UpgradeChecks{WaitTimeout: time.Hour}, DaemonUpgradeChecks(spec, "osd"))
+	assert.Equal(t, UpgradeChecks{Skip: true, WaitTimeout: 20 * time.Minute}, DaemonUpgradeChecks(spec, "mgr"))
+	assert.Equal(t, UpgradeChecks{ContinueIfNotHealthy: true, WaitTimeout: 20 * time.Minute}, DaemonUpgradeChecks(spec, "mds"))
+
+	// the default timeouts are used when the timeout is invalid
+	spec.UpgradeChecks.WaitTimeout = "20 minutes"
+	assert.Equal(t, time.Duration(0), DaemonUpgradeChecks(spec, "mds").WaitTimeout)
+}
+
+func TestRetryCount(t *testing.T) {
+	assert.Equal(t, 10, retryCount(0, time.Minute, 10))
+	assert.Equal(t, 30, retryCount(30*time.Minute, time.Minute, 10))
+	assert.Equal(t, 1, retryCount(time.Second, time.Minute, 10))
+}
@@ -19,6 +19,7 @@ package osd
 import (
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/daemon/ceph/osd/kms"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd/config"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 )
@@ -40,6 +41,11 @@ type OsdAgent struct {
 	pvcBacked      bool
 	configCounter  int32
 	osdsCompleted  chan struct{}
+	// the destroyed osds recreated with the same ids, and the ids of the zapped osds by device
+	replaceOSDIDs []int
+	replacedOSDs  map[string][]int
+	// kmsProvider encrypts the devices with the keys of the key management service instead of ceph-volume
+	kmsProvider kms.Provider
 }
 
 type device struct {
@@ -49,7 +55,7 @@ type device struct {
 
 // NewAgent is the instantiation of the OSD agent
 func NewAgent(context *clusterd.Context, driveGroups config.DriveGroupBlobs, devices []DesiredDevice, metadataDevice string, forceFormat bool,
-	storeConfig config.StoreConfig, clusterInfo *cephclient.ClusterInfo, nodeName string, kv *k8sutil.ConfigMapKVStore, pvcBacked bool) *OsdAgent {
+	storeConfig config.StoreConfig, clusterInfo *cephclient.ClusterInfo, nodeName string, kv *k8sutil.ConfigMapKVStore, pvcBacked bool, replaceOSDIDs []int, kmsProvider kms.Provider) *OsdAgent {
 
 	return &OsdAgent{
 		driveGroups:    driveGroups,
@@ -61,6 +67,8 @@ func NewAgent(context *clusterd.Context, driveGroups config.DriveGroupBlobs, dev
 		nodeName:       nodeName,
 		kv:             kv,
 		pvcBacked:      pvcBacked,
+		replaceOSDIDs:  replaceOSDIDs,
+		kmsProvider:    kmsProvider,
 	}
 }
 
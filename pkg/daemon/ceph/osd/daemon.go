@@ -187,6 +187,11 @@ func Provision(context *clusterd.Context, agent *OsdAgent, crushLocation string)
 		logger.Warningf("wrote and copied config file but failed to read it back from %s for logging. %v", cephclient.DefaultConfigFilePath(), err)
 	}
 
+	// the devices of the osds re-provisioned with a new store must be zapped before they are discovered
+	if err := agent.zapReplacedOSDs(context); err != nil {
+		return errors.Wrap(err, "failed to zap the osds to re-provision")
+	}
+
 	logger.Infof("discovering hardware")
 
 	var rawDevices []*sys.LocalDisk
@@ -375,8 +380,8 @@ func getAvailableDevices(context *clusterd.Context, agent *OsdAgent) (*DeviceOsd
 		}
 
 		var deviceInfo *DeviceOsdIDEntry
-		if agent.metadataDevice != "" && agent.metadataDevice == device.Name {
-			// current device is desired as the metadata device
+		if (agent.metadataDevice != "" && agent.metadataDevice == device.Name) || usedAsMetadataDevice(desiredDevices, device) {
+			// current device is desired as the metadata device, or as the metadata or wal device of desired devices
 			deviceInfo = &DeviceOsdIDEntry{Data: unassignedOSDID, Metadata: []int{}}
 		} else if len(desiredDevices) == 1 && desiredDevices[0].Name == "all" {
 			// user has specified all devices, use the current one for data
@@ -447,6 +452,10 @@ func getAvailableDevices(context *clusterd.Context, agent *OsdAgent) (*DeviceOsd
 		}
 
 		if deviceInfo != nil {
+			deviceInfo.PersistentDevicePaths = strings.Fields(device.DevLinks)
+			deviceInfo.DeviceType = device.Type
+			deviceInfo.Rotational = device.Rotational
+
 			// When running on PVC, we typically have a single device only
 			// So it's fine to name the first entry of the map "data" instead of the PVC name
 			// It is particularly useful when a metadata PVC is used because we need to identify it in the map
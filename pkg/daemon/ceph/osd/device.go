@@ -67,6 +67,7 @@ type DesiredDevice struct {
 	Name               string
 	OSDsPerDevice      int
 	MetadataDevice     string
+	WalDevice          string
 	DatabaseSizeMB     int
 	DeviceClass        string
 	IsFilter           bool
@@ -84,6 +85,8 @@ type DeviceOsdIDEntry struct {
 	Metadata              []int         // OSD IDs (multiple) that have metadata stored here
 	Config                DesiredDevice // Device specific config options
 	PersistentDevicePaths []string
+	DeviceType            string // Type of the device as reported by lsblk
+	Rotational            bool   // Whether the device is rotational as reported by lsblk
 }
 
 type devicePartInfo struct {
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/sys"
+)
+
+const (
+	devMapperDir = "/dev/mapper/"
+	diskByIDDir  = "/dev/disk/by-id/"
+	// the prefix of the by-id links built from the world wide name of the devices
+	wwnLinkPrefix = "wwn-"
+	// a label value is at most 63 characters
+	maxDeviceIDLength = 63
+)
+
+var (
+	// the by-id links of the device mapper and the lvm volumes are not names of the hardware
+	ignoredByIDLinkPrefixes = []string{"dm-name-", "dm-uuid-", "lvm-pv-uuid-"}
+	invalidLabelCharsRegex  = regexp.MustCompile(`[^-A-Za-z0-9_.]`)
+)
+
+// usePersistentDevicePath returns whether the device is given to ceph-volume by its persistent path, the names of the
+// partitions and of the multipath devices change with the order the disks and the paths are found after a reboot
+func usePersistentDevicePath(deviceType string) bool {
+	return deviceType == sys.PartType || deviceType == sys.MultiPath
+}
+
+// persistentDevicePath returns the path of a device which does not change when the devices are renamed: the
+// /dev/mapper alias of the multipath devices, else the by-id link of the world wide name of the device, else its
+// other by-id links, else the /dev path of its kernel name
+func persistentDevicePath(name string, devLinks []string) string {
+	var mapper, wwn, byID []string
+	for _, link := range devLinks {
+		switch {
+		case strings.HasPrefix(link, devMapperDir):
+			mapper = append(mapper, link)
+		case strings.HasPrefix(link, diskByIDDir+wwnLinkPrefix):
+			wwn = append(wwn, link)
+		case strings.HasPrefix(link, diskByIDDir) && !ignoredByIDLink(link):
+			byID = append(byID, link)
+		}
+	}
+	// udev does not list the links in a stable order
+	for _, links := range [][]string{mapper, wwn, byID} {
+		if len(links) > 0 {
+			sort.Strings(links)
+			return links[0]
+		}
+	}
+	return devicePath(name)
+}
+
+// devicePath returns the path of a device given by its name, like "nvme0n1p1", or by its path
+func devicePath(name string) string {
+	if strings.HasPrefix(name, "/dev/") {
+		return name
+	}
+	return path.Join("/dev", name)
+}
+
+// usedAsMetadataDevice returns whether the device is the metadata or the wal device of a desired device, given by
+// name or by path
+func usedAsMetadataDevice(desiredDevices []DesiredDevice, device *sys.LocalDisk) bool {
+	paths := append(strings.Fields(device.DevLinks), path.Join("/dev", device.Name))
+	for _, desiredDevice := range desiredDevices {
+		for _, md := range []string{desiredDevice.MetadataDevice, desiredDevice.WalDevice} {
+			if md != "" && (md == device.Name || contains(paths, md)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ignoredByIDLink(link string) bool {
+	for _, prefix := range ignoredByIDLinkPrefixes {
+		if strings.HasPrefix(link, diskByIDDir+prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceID returns the label value identifying the device of an osd, the name of its persistent path. The device
+// path as reported by ceph-volume is resolved with the devices of the node.
+func deviceID(context *clusterd.Context, devicePath string) string {
+	if devicePath == "" {
+		return ""
+	}
+	persistentPath := devicePath
+	for _, device := range context.Devices {
+		devLinks := strings.Fields(device.DevLinks)
+		if devicePath == path.Join("/dev", device.Name) || devicePath == device.RealPath || contains(devLinks, devicePath) {
+			persistentPath = persistentDevicePath(device.Name, devLinks)
+			break
+		}
+	}
+	return labelValue(filepath.Base(persistentPath))
+}
+
+// labelValue replaces the characters not allowed in a label value and truncates the value to its end, where the
+// serial and the partition number are
+func labelValue(value string) string {
+	value = invalidLabelCharsRegex.ReplaceAllString(value, "_")
+	if len(value) > maxDeviceIDLength {
+		value = value[len(value)-maxDeviceIDLength:]
+	}
+	return strings.Trim(value, "-_.")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceClass returns the class of a device matched by the device classes of the performance settings: its
+// deviceClass setting, else the class detected from the device
+func deviceClass(name string, device *DeviceOsdIDEntry) string {
+	if device.Config.DeviceClass != "" {
+		return device.Config.DeviceClass
+	}
+	return detectedDeviceClass(name, device.Rotational)
+}
+
+// detectedDeviceClass returns nvme for the NVMe devices, ssd for the other non-rotational devices and hdd for the
+// rotational devices. Ceph sets the ssd class on the NVMe devices, the nvme class only distinguishes them in the
+// settings of the OSDs.
+func detectedDeviceClass(name string, rotational bool) string {
+	switch {
+	case strings.HasPrefix(filepath.Base(name), "nvme"):
+		return "nvme"
+	case rotational:
+		return "hdd"
+	default:
+		return "ssd"
+	}
+}
+
+// osdDeviceClass returns the class of the device of an osd as reported by ceph-volume: the crush device class set
+// when the osd was created, else the class detected from the device found on the node
+func osdDeviceClass(context *clusterd.Context, devicePath, crushDeviceClass string) string {
+	if crushDeviceClass != "" {
+		return crushDeviceClass
+	}
+	if devicePath == "" {
+		return ""
+	}
+	for _, device := range context.Devices {
+		devLinks := strings.Fields(device.DevLinks)
+		if devicePath == path.Join("/dev", device.Name) || devicePath == device.RealPath || contains(devLinks, devicePath) {
+			return detectedDeviceClass(device.Name, device.Rotational)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd/config"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/rook/rook/pkg/util/sys"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentDevicePath(t *testing.T) {
+	assert.Equal(t, "/dev/sdb1", persistentDevicePath("sdb1", nil))
+	assert.Equal(t, "/dev/sdb1", persistentDevicePath("sdb1", []string{"/dev/disk/by-path/pci-0000:00:10.0-scsi-0:0:1:0-part1"}))
+	assert.Equal(t, "/dev/disk/by-id/scsi-0QEMU_disk1-part1", persistentDevicePath("sdb1", []string{
+		"/dev/disk/by-path/pci-0000:00:10.0-scsi-0:0:1:0-part1", "/dev/disk/by-id/scsi-0QEMU_disk1-part1", "/dev/disk/by-id/lvm-pv-uuid-abc"}))
+	assert.Equal(t, "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1", persistentDevicePath("sdb1", []string{
+		"/dev/disk/by-id/scsi-35000c500a1b2c3d4-part1", "/dev/disk/by-id/wwn-0x5000c500a1b2c3d4-part1"}))
+	assert.Equal(t, "/dev/mapper/mpatha", persistentDevicePath("dm-0", []string{
+		"/dev/disk/by-id/dm-name-mpatha", "/dev/disk/by-id/dm-uuid-mpath-3600a098038303053", "/dev/mapper/mpatha"}))
+	assert.True(t, usePersistentDevicePath(sys.PartType))
+	assert.True(t, usePersistentDevicePath(sys.MultiPath))
+	assert.False(t, usePersistentDevicePath(sys.DiskType))
+}
+
+func TestDeviceID(t *testing.T) {
+	context := &clusterd.Context{Devices: []*sys.LocalDisk{
+		{Name: "sdb", RealPath: "/dev/sdb", DevLinks: "/dev/disk/by-path/pci-0:1:2:3-scsi-1 /dev/disk/by-id/wwn-0x5000c500a1b2c3d4"},
+		{Name: "dm-0", RealPath: "/dev/mapper/mpatha", DevLinks: "/dev/mapper/mpatha /dev/disk/by-id/dm-name-mpatha"},
+		{Name: "sdd", RealPath: "/dev/sdd", DevLinks: "/dev/disk/by-id/usb-Generic_Flash_Disk_1234-0:0"},
+	}}
+	assert.Equal(t, "", deviceID(context, ""))
+	assert.Equal(t, "wwn-0x5000c500a1b2c3d4", deviceID(context, "/dev/sdb"))
+	assert.Equal(t, "mpatha", deviceID(context, "/dev/mapper/mpatha"))
+	assert.Equal(t, "usb-Generic_Flash_Disk_1234-0_0", deviceID(context, "/dev/sdd"))
+	// the devices not found are identified by their name
+	assert.Equal(t, "sde", deviceID(context, "/dev/sde"))
+	assert.Equal(t, maxDeviceIDLength, len(labelValue(strings.Repeat("a", 70))))
+	assert.Equal(t, "a", labelValue("_a-"))
+
+	// the osds listed by ceph-volume have the id of their device
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return cephVolumeLVMTestResult, nil
+		},
+	}
+	context.Executor = executor
+	osds, err := GetCephVolumeLVMOSDs(context, &cephclient.ClusterInfo{Namespace: "name"}, "4bfe8b72-5e69-4330-b6c0-4d914db8ab89", "", false, false)
+	assert.NoError(t, err)
+	ids := map[int]string{}
+	for _, osd := range osds {
+		ids[osd.ID] = osd.DeviceID
+	}
+	assert.Equal(t, map[int]string{0: "wwn-0x5000c500a1b2c3d4", 1: "sdc"}, ids)
+}
+
+func TestInitializeDevicesPersistentPath(t *testing.T) {
+	prepared := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommand: func(command string, args ...string) error {
+			if args[len(args)-1] != "--report" {
+				prepared = append(prepared, strings.Join(args[len(args)-3:], " "))
+			}
+			return nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	agent := &OsdAgent{
+		storeConfig:  config.StoreConfig{StoreType: config.Bluestore},
+		replacedOSDs: map[string][]int{"/dev/sdc1": {3}},
+	}
+	devices := &DeviceOsdMapping{Entries: map[string]*DeviceOsdIDEntry{
+		"sdb":  {Data: -1, DeviceType: sys.DiskType, PersistentDevicePaths: []string{"/dev/disk/by-id/wwn-0x1"}},
+		"sdc1": {Data: -1, DeviceType: sys.PartType, PersistentDevicePaths: []string{"/dev/disk/by-id/wwn-0x2-part1"}},
+	}}
+
+	// the disks keep their name, the partitions are prepared with their persistent path and the ids of the replaced
+	// osds are found by the path listed by ceph-volume
+	assert.NoError(t, agent.initializeDevices(context, devices))
+	assert.ElementsMatch(t, []string{"--osds-per-device 1 /dev/sdb", "/dev/disk/by-id/wwn-0x2-part1 --osd-ids 3"}, prepared)
+}
+
+func TestInitializeDevicesMetadataAndWalDevices(t *testing.T) {
+	batches := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommand: func(command string, args ...string) error {
+			if args[len(args)-1] != "--report" {
+				batches = append(batches, strings.Join(args[9:], " "))
+			}
+			return nil
+		},
+		MockExecuteCommandWithCombinedOutput: func(command string, args ...string) (string, error) {
+			for i, arg := range args {
+				if arg == dbDeviceFlag {
+					return `{"changed":true,"vg":{"devices":"` + args[i+1] + `"}}`, nil
+				}
+			}
+			return `{"changed":true,"vg":{"devices":""}}`, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	agent := &OsdAgent{storeConfig: config.StoreConfig{StoreType: config.Bluestore}}
+	devices := &DeviceOsdMapping{Entries: map[string]*DeviceOsdIDEntry{
+		"sdb":       {Data: -1, Config: DesiredDevice{Name: "sdb", MetadataDevice: "nvme0n1p1", WalDevice: "/dev/disk/by-id/nvme-eui.01-part3"}},
+		"sdc":       {Data: -1, Config: DesiredDevice{Name: "sdc", MetadataDevice: "nvme0n1p2"}},
+		"sdd":       {Data: -1, Config: DesiredDevice{Name: "sdd", WalDevice: "nvme0n1p4"}},
+		"nvme0n1p1": {Data: -1, Metadata: []int{}},
+	}}
+
+	// each device is batched with its own metadata and wal devices
+	assert.NoError(t, agent.initializeDevices(context, devices))
+	assert.ElementsMatch(t, []string{
+		"/dev/sdb --db-devices /dev/nvme0n1p1 --wal-devices /dev/disk/by-id/nvme-eui.01-part3",
+		"/dev/sdc --db-devices /dev/nvme0n1p2",
+		"/dev/sdd --wal-devices /dev/nvme0n1p4",
+	}, batches)
+
+	// the metadata and wal devices of the desired devices are not data devices
+	desiredDevices := devices.Entries["sdb"].Config
+	assert.True(t, usedAsMetadataDevice([]DesiredDevice{desiredDevices}, &sys.LocalDisk{Name: "nvme0n1p1"}))
+	assert.True(t, usedAsMetadataDevice([]DesiredDevice{desiredDevices}, &sys.LocalDisk{Name: "nvme0n1p3", DevLinks: "/dev/disk/by-id/nvme-eui.01-part3"}))
+	assert.False(t, usedAsMetadataDevice([]DesiredDevice{desiredDevices}, &sys.LocalDisk{Name: "sdb"}))
+}
+
+func TestInitializeDevicesOSDsPerDeviceClass(t *testing.T) {
+	prepared := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommand: func(command string, args ...string) error {
+			if args[len(args)-1] != "--report" {
+				prepared = append(prepared, strings.Join(args[len(args)-3:], " "))
+			}
+			return nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	agent := &OsdAgent{storeConfig: config.StoreConfig{
+		StoreType:          config.Bluestore,
+		OSDsPerDevice:      1,
+		OSDsPerDeviceClass: map[string]int{"nvme": 4, "ssd": 3},
+	}}
+	devices := &DeviceOsdMapping{Entries: map[string]*DeviceOsdIDEntry{
+		"nvme0n1": {Data: -1, DeviceType: sys.DiskType},
+		"sdb":     {Data: -1, DeviceType: sys.DiskType, Rotational: true},
+		"sdc":     {Data: -1, DeviceType: sys.DiskType, Config: DesiredDevice{Name: "sdc", OSDsPerDevice: 2}},
+	}}
+
+	// the osds per device of a device take precedence over the ones of its class
+	assert.NoError(t, agent.initializeDevices(context, devices))
+	assert.ElementsMatch(t, []string{"--osds-per-device 4 /dev/nvme0n1", "--osds-per-device 1 /dev/sdb", "--osds-per-device 2 /dev/sdc"}, prepared)
+
+	assert.Equal(t, "nvme", detectedDeviceClass("/dev/nvme1n1", false))
+	assert.Equal(t, "ssd", detectedDeviceClass("sdb", false))
+	assert.Equal(t, "hdd", detectedDeviceClass("sdb", true))
+	assert.Equal(t, "fast", deviceClass("nvme0n1", &DeviceOsdIDEntry{Config: DesiredDevice{DeviceClass: "fast"}}))
+	ctx := &clusterd.Context{Devices: []*sys.LocalDisk{{Name: "sdb", Rotational: true}}}
+	assert.Equal(t, "hdd", osdDeviceClass(ctx, "/dev/sdb", ""))
+	assert.Equal(t, "fast", osdDeviceClass(ctx, "/dev/sdb", "fast"))
+	assert.Equal(t, "", osdDeviceClass(ctx, "/dev/sdz", ""))
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/osd/kms"
+)
+
+const (
+	// the devices encrypted with a key of the key management service are opened as /dev/mapper/ceph-crypt-<luks uuid>
+	kmsMapperPrefix = "ceph-crypt-"
+	mapperDir       = "/dev/mapper"
+	// kmsTokenType is the type of the LUKS2 tokens holding the keys of a device wrapped by the key management service
+	kmsTokenType = "rook-kms"
+)
+
+// the ids of the tokens of the key management service in the output of luksDump
+var kmsTokenIDRegex = regexp.MustCompile(`(?m)^\s+(\d+): ` + kmsTokenType + `\s*$`)
+
+// kmsToken is a LUKS2 token of the header of an encrypted device holding a key of the device wrapped by the key
+// management service. The token is not assigned to a key slot, its key is tested on the device instead.
+type kmsToken struct {
+	Type     string   `json:"type"`
+	Keyslots []string `json:"keyslots"`
+	// WrappedKey is the wrapped key encoded in base64
+	WrappedKey string `json:"rook_wrapped_key"`
+}
+
+// kmsKey is a key of an encrypted device unwrapped from a token of its header
+type kmsKey struct {
+	tokenID string
+	keyFile string
+}
+
+// EncryptionKeyID returns the LUKS uuid of the device of an OSD encrypted with a key of the key management service
+// from the path of the opened device listed by ceph-volume, or an empty string if the device is not encrypted by rook
+func EncryptionKeyID(devicePath string) string {
+	name := strings.TrimPrefix(devicePath, mapperDir+"/")
+	if name == devicePath || !strings.HasPrefix(name, kmsMapperPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(name, kmsMapperPrefix)
+}
+
+func kmsMapperName(keyID string) string {
+	return kmsMapperPrefix + keyID
+}
+
+// encryptDevice formats a device with LUKS2 with a new key and opens it. The key is generated by the key management
+// service and is only saved wrapped by the key management service, in a token of the LUKS header of the device. It
+// returns the path of the opened device on which ceph-volume creates the OSD.
+func encryptDevice(context *clusterd.Context, provider kms.Provider, device string) (string, error) {
+	keyID := uuid.New().String()
+	key, wrapped, err := kms.NewKey(provider, dmcryptKeySize)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to generate the encryption key of device %q", device)
+	}
+
+	dir, err := ioutil.TempDir("", "osd-encryption")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the directory of the key files")
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write the key file")
+	}
+
+	if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksFormat", "--batch-mode", "--type", "luks2", "--uuid", keyID, "--key-file", keyFile, device); err != nil {
+		return "", errors.Wrapf(err, "failed to encrypt device %q", device)
+	}
+	if err := importKMSToken(context, dir, device, wrapped); err != nil {
+		return "", err
+	}
+	if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksOpen", "--key-file", keyFile, device, kmsMapperName(keyID)); err != nil {
+		return "", errors.Wrapf(err, "failed to open encrypted device %q", device)
+	}
+
+	logger.Infof("encrypted device %q with key %q of the key management service", device, keyID)
+	return path.Join(mapperDir, kmsMapperName(keyID)), nil
+}
+
+// OpenEncryptedDevice opens the device of an OSD encrypted with a key of the key management service, and activates
+// the LVM volume group created by ceph-volume on the opened device. The OSD is activated by ceph-volume afterwards.
+func OpenEncryptedDevice(context *clusterd.Context, provider kms.Provider, keyID string) error {
+	mapperPath := path.Join(mapperDir, kmsMapperName(keyID))
+	if err := context.Executor.ExecuteCommand(cryptsetupBinary, "status", kmsMapperName(keyID)); err == nil {
+		logger.Infof("encrypted device %q is already open", mapperPath)
+	} else {
+		device, err := encryptedDevicePath(context, keyID)
+		if err != nil {
+			return err
+		}
+		dir, err := ioutil.TempDir("", "osd-encryption")
+		if err != nil {
+			return errors.Wrap(err, "failed to create the directory of the key files")
+		}
+		defer os.RemoveAll(dir)
+
+		keys, err := readKMSKeys(context, provider, dir, device)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return errors.Errorf("none of the keys of the key management service unlocks device %q", device)
+		}
+		if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksOpen", "--key-file", keys[0].keyFile, device, kmsMapperName(keyID)); err != nil {
+			return errors.Wrapf(err, "failed to open encrypted device %q", device)
+		}
+		logger.Infof("opened encrypted device %q as %q", device, mapperPath)
+	}
+
+	// the host does not activate the volume group of the osd since it does not open the encrypted device
+	vg, err := context.Executor.ExecuteCommandWithOutput("pvs", "--noheadings", "--options", "vg_name", mapperPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the volume group of encrypted device %q", mapperPath)
+	}
+	if err := context.Executor.ExecuteCommand("vgchange", "--activate", "y", strings.TrimSpace(vg)); err != nil {
+		return errors.Wrapf(err, "failed to activate volume group %q", strings.TrimSpace(vg))
+	}
+	return nil
+}
+
+// encryptedDevicePath returns the path of the device with the given LUKS uuid
+func encryptedDevicePath(context *clusterd.Context, keyID string) (string, error) {
+	device, err := context.Executor.ExecuteCommandWithOutput("blkid", "--uuid", keyID)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the encrypted device with uuid %q", keyID)
+	}
+	return strings.TrimSpace(device), nil
+}
+
+// importKMSToken saves a wrapped key in a new token of the LUKS header of a device
+func importKMSToken(context *clusterd.Context, dir, device string, wrapped []byte) error {
+	token, err := json.Marshal(kmsToken{
+		Type:       kmsTokenType,
+		Keyslots:   []string{},
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the token of the wrapped key")
+	}
+	tokenFile := filepath.Join(dir, "token.json")
+	if err := ioutil.WriteFile(tokenFile, token, 0600); err != nil {
+		return errors.Wrap(err, "failed to write the token file")
+	}
+	if err := context.Executor.ExecuteCommand(cryptsetupBinary, "token", "import", "--json-file", tokenFile, device); err != nil {
+		return errors.Wrapf(err, "failed to save the wrapped key in the header of device %q", device)
+	}
+	return nil
+}
+
+// readKMSKeys unwraps the keys of the tokens of the key management service of a device to key files of the given
+// directory. It returns the keys unlocking the device, the tokens whose key does not unlock the device are skipped.
+func readKMSKeys(context *clusterd.Context, provider kms.Provider, dir, device string) ([]kmsKey, error) {
+	header, err := context.Executor.ExecuteCommandWithOutput(cryptsetupBinary, "luksDump", device)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the header of encrypted device %q", device)
+	}
+
+	keys := []kmsKey{}
+	for _, match := range kmsTokenIDRegex.FindAllStringSubmatch(header, -1) {
+		tokenID := match[1]
+		output, err := context.Executor.ExecuteCommandWithOutput(cryptsetupBinary, "token", "export", "--token-id", tokenID, device)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read token %s of encrypted device %q", tokenID, device)
+		}
+		var token kmsToken
+		if err := json.Unmarshal([]byte(output), &token); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal token %s of encrypted device %q", tokenID, device)
+		}
+		wrapped, err := base64.StdEncoding.DecodeString(token.WrappedKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the wrapped key of token %s of encrypted device %q", tokenID, device)
+		}
+		key, err := provider.UnwrapKey(wrapped)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unwrap the key of token %s of encrypted device %q", tokenID, device)
+		}
+
+		keyFile := filepath.Join(dir, fmt.Sprintf("key-%s", tokenID))
+		if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+			return nil, errors.Wrap(err, "failed to write the key file")
+		}
+		if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksOpen", "--test-passphrase", "--key-file", keyFile, device); err != nil {
+			logger.Warningf("the key of token %s does not unlock encrypted device %q. %v", tokenID, device, err)
+			continue
+		}
+		keys = append(keys, kmsKey{tokenID: tokenID, keyFile: keyFile})
+	}
+	return keys, nil
+}
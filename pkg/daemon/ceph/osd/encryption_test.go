@@ -0,0 +1,295 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd/config"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/rook/rook/pkg/util/sys"
+	"github.com/stretchr/testify/assert"
+)
+
+// reverseKMS wraps the keys by reversing them
+type reverseKMS struct{}
+
+func (p *reverseKMS) WrapKey(key []byte) ([]byte, error) {
+	wrapped := make([]byte, len(key))
+	for i := range key {
+		wrapped[len(key)-1-i] = key[i]
+	}
+	return wrapped, nil
+}
+
+func (p *reverseKMS) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return p.WrapKey(wrapped)
+}
+
+// fakeLUKSDevice emulates the cryptsetup commands on an encrypted device
+type fakeLUKSDevice struct {
+	t    *testing.T
+	uuid string
+	// keys are the key slots of the keys
+	keys      map[string]int
+	tokens    map[int]string
+	nextToken int
+	opened    map[string]bool
+	commands  []string
+	// failCommand fails the given cryptsetup command
+	failCommand string
+}
+
+func newFakeLUKSDevice(t *testing.T) *fakeLUKSDevice {
+	return &fakeLUKSDevice{t: t, keys: map[string]int{}, tokens: map[int]string{}, opened: map[string]bool{}}
+}
+
+func (d *fakeLUKSDevice) hasKey(key string) bool {
+	_, ok := d.keys[key]
+	return ok
+}
+
+// addKey adds a key to the first free key slot
+func (d *fakeLUKSDevice) addKey(key string) {
+	slots := map[int]bool{}
+	for _, slot := range d.keys {
+		slots[slot] = true
+	}
+	slot := 0
+	for slots[slot] {
+		slot++
+	}
+	d.keys[key] = slot
+}
+
+// keySlots returns the sorted key slots
+func (d *fakeLUKSDevice) keySlots() []int {
+	slots := []int{}
+	for _, slot := range d.keys {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+	return slots
+}
+
+func (d *fakeLUKSDevice) readFile(path string) string {
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(d.t, err)
+	return string(content)
+}
+
+func (d *fakeLUKSDevice) executor() *exectest.MockExecutor {
+	return &exectest.MockExecutor{
+		MockExecuteCommand: func(command string, args ...string) error {
+			if command == "vgchange" {
+				d.commands = append(d.commands, "vgchange "+strings.Join(args, " "))
+				return nil
+			}
+			assert.Equal(d.t, "cryptsetup", command)
+			name := args[0]
+			if args[0] == "token" {
+				name = "token " + args[1]
+			}
+			d.commands = append(d.commands, name)
+			if name == d.failCommand {
+				return errors.New("failed")
+			}
+			switch name {
+			case "luksFormat":
+				d.uuid = args[5]
+				d.keys = map[string]int{d.readFile(args[7]): 0}
+			case "token import":
+				d.tokens[d.nextToken] = d.readFile(args[3])
+				d.nextToken++
+			case "token remove":
+				id, err := strconv.Atoi(args[3])
+				assert.NoError(d.t, err)
+				delete(d.tokens, id)
+			case "luksOpen":
+				if args[1] == "--test-passphrase" {
+					if !d.hasKey(d.readFile(args[3])) {
+						return errors.New("no key available with this passphrase")
+					}
+					return nil
+				}
+				if !d.hasKey(d.readFile(args[2])) {
+					return errors.New("no key available with this passphrase")
+				}
+				d.opened[args[4]] = true
+			case "luksAddKey":
+				if !d.hasKey(d.readFile(args[2])) {
+					return errors.New("no key available with this passphrase")
+				}
+				d.addKey(d.readFile(args[4]))
+			case "luksKillSlot":
+				key := d.readFile(args[2])
+				slot, err := strconv.Atoi(args[4])
+				assert.NoError(d.t, err)
+				// the key of a remaining slot unlocks the device to remove a slot
+				if !d.hasKey(key) || d.keys[key] == slot {
+					return errors.New("no key available with this passphrase")
+				}
+				for key := range d.keys {
+					if d.keys[key] == slot {
+						delete(d.keys, key)
+					}
+				}
+			case "status":
+				if !d.opened[args[1]] {
+					return errors.New("inactive")
+				}
+			}
+			return nil
+		},
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			switch command {
+			case "blkid":
+				assert.Equal(d.t, []string{"--uuid", "luks-uuid"}, args)
+				return "/dev/sdb", nil
+			case "pvs":
+				return "  ceph-block-vg", nil
+			}
+			assert.Equal(d.t, "cryptsetup", command)
+			if args[0] == "luksDump" {
+				ids := []int{}
+				for id := range d.tokens {
+					ids = append(ids, id)
+				}
+				sort.Ints(ids)
+				header := "LUKS header information\nVersion:       \t2\n\nKeyslots:\n"
+				for _, slot := range d.keySlots() {
+					header += fmt.Sprintf("  %d: luks2\n\tKey:        512 bits\n", slot)
+				}
+				header += "Tokens:\n"
+				for _, id := range ids {
+					header += fmt.Sprintf("  %d: %s\n", id, kmsTokenType)
+				}
+				return header + "Digests:\n  0: pbkdf2\n", nil
+			}
+			assert.Equal(d.t, []string{"token", "export", "--token-id"}, args[:3])
+			id, err := strconv.Atoi(args[3])
+			assert.NoError(d.t, err)
+			return d.tokens[id], nil
+		},
+		MockExecuteCommandWithCombinedOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(d.t, "cryptsetup", command)
+			assert.Equal(d.t, []string{"luksOpen", "--test-passphrase", "--verbose", "--key-file"}, args[:4])
+			d.commands = append(d.commands, "luksOpen --verbose")
+			slot, ok := d.keys[d.readFile(args[4])]
+			if !ok {
+				return "No key available with this passphrase.", errors.New("failed")
+			}
+			return fmt.Sprintf("Key slot %d unlocked.\nCommand successful.", slot), nil
+		},
+	}
+}
+
+func TestEncryptionKeyID(t *testing.T) {
+	assert.Equal(t, "luks-uuid", EncryptionKeyID("/dev/mapper/ceph-crypt-luks-uuid"))
+	assert.Equal(t, "", EncryptionKeyID("/dev/mapper/ceph--block--vg-osd--block"))
+	assert.Equal(t, "", EncryptionKeyID("/dev/sdb"))
+}
+
+func TestEncryptDevice(t *testing.T) {
+	device := newFakeLUKSDevice(t)
+	context := &clusterd.Context{Executor: device.executor()}
+
+	// the device is formatted with a key saved wrapped in its header and opened for ceph-volume
+	path, err := encryptDevice(context, &reverseKMS{}, "/dev/sdb")
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/mapper/ceph-crypt-"+device.uuid, path)
+	assert.Equal(t, []string{"luksFormat", "token import", "luksOpen"}, device.commands)
+	assert.True(t, device.opened["ceph-crypt-"+device.uuid])
+	assert.Equal(t, 1, len(device.keys))
+	assert.Contains(t, device.tokens[0], `"type":"rook-kms"`)
+	// the key is only saved wrapped
+	for key := range device.keys {
+		assert.Equal(t, dmcryptKeySize, len(key))
+		assert.NotContains(t, device.tokens[0], key)
+	}
+
+	// the device is not opened when its key cannot be saved
+	device = newFakeLUKSDevice(t)
+	device.failCommand = "token import"
+	_, err = encryptDevice(&clusterd.Context{Executor: device.executor()}, &reverseKMS{}, "/dev/sdb")
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(device.opened))
+}
+
+func TestOpenEncryptedDevice(t *testing.T) {
+	device := newFakeLUKSDevice(t)
+	context := &clusterd.Context{Executor: device.executor()}
+	_, err := encryptDevice(context, &reverseKMS{}, "/dev/sdb")
+	assert.NoError(t, err)
+	// the device is found by its uuid after a reboot
+	device.opened = map[string]bool{}
+	device.commands = nil
+
+	assert.NoError(t, OpenEncryptedDevice(context, &reverseKMS{}, "luks-uuid"))
+	assert.True(t, device.opened["ceph-crypt-luks-uuid"])
+	assert.Equal(t, []string{"status", "luksOpen", "luksOpen", "vgchange --activate y ceph-block-vg"}, device.commands)
+
+	// an open device is not opened again
+	device.commands = nil
+	assert.NoError(t, OpenEncryptedDevice(context, &reverseKMS{}, "luks-uuid"))
+	assert.Equal(t, []string{"status", "vgchange --activate y ceph-block-vg"}, device.commands)
+
+	// the device is not opened without a valid key
+	device.opened = map[string]bool{}
+	device.keys = map[string]int{}
+	assert.Error(t, OpenEncryptedDevice(context, &reverseKMS{}, "luks-uuid"))
+}
+
+func TestInitializeDevicesWithKMS(t *testing.T) {
+	device := newFakeLUKSDevice(t)
+	executor := device.executor()
+	prepared := []string{}
+	mockCryptsetup := executor.MockExecuteCommand
+	executor.MockExecuteCommand = func(command string, args ...string) error {
+		if command == "stdbuf" {
+			assert.NotContains(t, args, "--dmcrypt")
+			if args[len(args)-1] != "--report" {
+				prepared = append(prepared, args[len(args)-1])
+			}
+			return nil
+		}
+		return mockCryptsetup(command, args...)
+	}
+	context := &clusterd.Context{Executor: executor}
+	agent := &OsdAgent{
+		storeConfig: config.StoreConfig{StoreType: config.Bluestore, EncryptedDevice: true},
+		kmsProvider: &reverseKMS{},
+	}
+	devices := &DeviceOsdMapping{Entries: map[string]*DeviceOsdIDEntry{
+		"sdb": {Data: -1, DeviceType: sys.DiskType},
+	}}
+
+	// ceph-volume creates the osd on the device opened by rook rather than encrypting it
+	assert.NoError(t, agent.initializeDevices(context, devices))
+	assert.Equal(t, []string{"/dev/mapper/ceph-crypt-" + device.uuid}, prepared)
+
+	// the metadata devices are not encrypted with the key management service
+	devices.Entries["sdb"].Config = DesiredDevice{Name: "sdb", MetadataDevice: "nvme0n1"}
+	assert.Error(t, agent.initializeDevices(context, devices))
+}
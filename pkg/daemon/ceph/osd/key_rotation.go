@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/daemon/ceph/osd/kms"
+)
+
+const (
+	cryptsetupBinary = "cryptsetup"
+	// ceph-volume creates the dmcrypt keys from 128 random bytes
+	dmcryptKeySize = 128
+)
+
+var (
+	// the enabled key slots in the output of luksDump of the LUKS1 and the LUKS2 headers
+	keySlotRegex = regexp.MustCompile(`(?m)^(?:Key Slot (\d+): ENABLED|\s+(\d+): luks2)\s*$`)
+	// the key slot unlocked by a key in the verbose output of luksOpen
+	unlockedKeySlotRegex = regexp.MustCompile(`Key slot (\d+) unlocked`)
+)
+
+// RotateEncryptionKey replaces the dmcrypt key of an encrypted OSD. The new key is added to a free LUKS key slot and
+// saved in the config-key store of the mons before all the other key slots are removed, so that one of the keys
+// stored by the mons always unlocks the OSD. The key slots left by a failed rotation are removed by the next one.
+func RotateEncryptionKey(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, osdUUID, blockPath string) error {
+	configKey := fmt.Sprintf("dm-crypt/osd/%s/luks", osdUUID)
+	oldKey, err := cephclient.ConfigKeyGet(context, clusterInfo, configKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the encryption key of osd %q", osdUUID)
+	}
+	newKey, err := generateDmcryptKey()
+	if err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir("", "osd-key-rotation")
+	if err != nil {
+		return errors.Wrap(err, "failed to create the directory of the key files")
+	}
+	defer os.RemoveAll(dir)
+	oldKeyFile := filepath.Join(dir, "old-key")
+	if err := ioutil.WriteFile(oldKeyFile, []byte(oldKey), 0600); err != nil {
+		return errors.Wrap(err, "failed to write the old key file")
+	}
+	newKeyFile := filepath.Join(dir, "new-key")
+	if err := ioutil.WriteFile(newKeyFile, []byte(newKey), 0600); err != nil {
+		return errors.Wrap(err, "failed to write the new key file")
+	}
+
+	if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksAddKey", "--key-file", oldKeyFile, blockPath, newKeyFile); err != nil {
+		return errors.Wrapf(err, "failed to add the new key of osd %q to %q", osdUUID, blockPath)
+	}
+	newKeySlot, err := keySlot(context, blockPath, newKeyFile)
+	if err != nil {
+		return err
+	}
+	if err := cephclient.ConfigKeySet(context, clusterInfo, configKey, newKey); err != nil {
+		return errors.Wrapf(err, "failed to save the new key of osd %q, the old key still unlocks the osd", osdUUID)
+	}
+	if err := removeOtherKeySlots(context, blockPath, newKeyFile, newKeySlot); err != nil {
+		return errors.Wrapf(err, "failed to remove the old keys of osd %q", osdUUID)
+	}
+
+	logger.Infof("rotated the encryption key of osd %q", osdUUID)
+	return nil
+}
+
+// RotateKMSEncryptionKey replaces the key of a device encrypted with a key of the key management service. The new key
+// is added to a free LUKS key slot and saved wrapped in a new token of the LUKS header before the tokens of the
+// previous keys and all the other key slots are removed, so that a token of the header always unlocks the device.
+func RotateKMSEncryptionKey(context *clusterd.Context, provider kms.Provider, keyID string) error {
+	device, err := encryptedDevicePath(context, keyID)
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "osd-key-rotation")
+	if err != nil {
+		return errors.Wrap(err, "failed to create the directory of the key files")
+	}
+	defer os.RemoveAll(dir)
+
+	oldKeys, err := readKMSKeys(context, provider, dir, device)
+	if err != nil {
+		return err
+	}
+	if len(oldKeys) == 0 {
+		return errors.Errorf("none of the keys of the key management service unlocks device %q", device)
+	}
+	newKey, wrapped, err := kms.NewKey(provider, dmcryptKeySize)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate the new key of device %q", device)
+	}
+	newKeyFile := filepath.Join(dir, "new-key")
+	if err := ioutil.WriteFile(newKeyFile, newKey, 0600); err != nil {
+		return errors.Wrap(err, "failed to write the new key file")
+	}
+
+	if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksAddKey", "--key-file", oldKeys[0].keyFile, device, newKeyFile); err != nil {
+		return errors.Wrapf(err, "failed to add the new key to %q", device)
+	}
+	newKeySlot, err := keySlot(context, device, newKeyFile)
+	if err != nil {
+		return err
+	}
+	if err := importKMSToken(context, dir, device, wrapped); err != nil {
+		return errors.Wrapf(err, "failed to save the new key of %q, the old key still unlocks the device", device)
+	}
+	for _, oldKey := range oldKeys {
+		if err := context.Executor.ExecuteCommand(cryptsetupBinary, "token", "remove", "--token-id", oldKey.tokenID, device); err != nil {
+			return errors.Wrapf(err, "failed to remove token %s of the old key from %q", oldKey.tokenID, device)
+		}
+	}
+	if err := removeOtherKeySlots(context, device, newKeyFile, newKeySlot); err != nil {
+		return errors.Wrapf(err, "failed to remove the old keys of %q", device)
+	}
+
+	logger.Infof("rotated the key %q of the key management service of device %q", keyID, device)
+	return nil
+}
+
+// keySlot returns the key slot of a device unlocked by a key
+func keySlot(context *clusterd.Context, device, keyFile string) (int, error) {
+	output, err := context.Executor.ExecuteCommandWithCombinedOutput(cryptsetupBinary, "luksOpen", "--test-passphrase", "--verbose", "--key-file", keyFile, device)
+	if err != nil {
+		return -1, errors.Wrapf(err, "failed to find the key slot of the new key of %q. %s", device, output)
+	}
+	match := unlockedKeySlotRegex.FindStringSubmatch(output)
+	if match == nil {
+		return -1, errors.Errorf("failed to find the key slot of the new key of %q in %q", device, output)
+	}
+	return strconv.Atoi(match[1])
+}
+
+// removeOtherKeySlots removes all the key slots of a device but the slot of its new key, including the slots of the
+// keys added by the failed rotations, whose key was not saved
+func removeOtherKeySlots(context *clusterd.Context, device, keyFile string, keepSlot int) error {
+	header, err := context.Executor.ExecuteCommandWithOutput(cryptsetupBinary, "luksDump", device)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the header of encrypted device %q", device)
+	}
+	for _, match := range keySlotRegex.FindAllStringSubmatch(header, -1) {
+		slot := match[1] + match[2]
+		if slot == strconv.Itoa(keepSlot) {
+			continue
+		}
+		if err := context.Executor.ExecuteCommand(cryptsetupBinary, "luksKillSlot", "--key-file", keyFile, device, slot); err != nil {
+			return errors.Wrapf(err, "failed to remove key slot %s of %q", slot, device)
+		}
+	}
+	return nil
+}
+
+// generateDmcryptKey generates a key like ceph-volume does
+func generateDmcryptKey() (string, error) {
+	key := make([]byte, dmcryptKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.Wrap(err, "failed to generate a new encryption key")
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateEncryptionKey(t *testing.T) {
+	device := newFakeLUKSDevice(t)
+	device.keys = map[string]int{"old": 0}
+	monKeys := map[string]string{"dm-crypt/osd/osd-uuid/luks": "old"}
+	setFails := false
+	executor := device.executor()
+	executor.MockExecuteCommandWithOutputFile = func(command, outfile string, args ...string) (string, error) {
+		assert.Equal(t, "config-key", args[0])
+		if args[1] == "set" {
+			if setFails {
+				return "", errors.New("failed")
+			}
+			monKeys[args[2]] = args[3]
+			return "", nil
+		}
+		return monKeys[args[2]], nil
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	// the key saved by the mons is the only key of the device
+	assertRotated := func() {
+		rotated := monKeys["dm-crypt/osd/osd-uuid/luks"]
+		assert.Equal(t, 172, len(rotated))
+		assert.Equal(t, map[string]int{rotated: device.keys[rotated]}, device.keys)
+	}
+
+	// the new key is saved before the old key is removed
+	assert.NoError(t, RotateEncryptionKey(context, clusterInfo, "osd-uuid", "/dev/vg/osd-block"))
+	assert.Equal(t, []string{"luksAddKey", "luksOpen --verbose", "luksKillSlot"}, device.commands)
+	assertRotated()
+	rotated := monKeys["dm-crypt/osd/osd-uuid/luks"]
+
+	// the keys are different at each rotation, the old keys are removed by the next rotation when they cannot be removed
+	device.failCommand = "luksKillSlot"
+	assert.Error(t, RotateEncryptionKey(context, clusterInfo, "osd-uuid", "/dev/vg/osd-block"))
+	assert.NotEqual(t, rotated, monKeys["dm-crypt/osd/osd-uuid/luks"])
+	assert.Equal(t, 2, len(device.keys))
+	device.failCommand = ""
+	assert.NoError(t, RotateEncryptionKey(context, clusterInfo, "osd-uuid", "/dev/vg/osd-block"))
+	assertRotated()
+
+	// the key added by a rotation whose key cannot be saved is removed by the next rotation
+	rotated = monKeys["dm-crypt/osd/osd-uuid/luks"]
+	setFails = true
+	assert.Error(t, RotateEncryptionKey(context, clusterInfo, "osd-uuid", "/dev/vg/osd-block"))
+	assert.Equal(t, rotated, monKeys["dm-crypt/osd/osd-uuid/luks"])
+	assert.True(t, device.hasKey(rotated))
+	assert.Equal(t, 2, len(device.keys))
+	setFails = false
+	assert.NoError(t, RotateEncryptionKey(context, clusterInfo, "osd-uuid", "/dev/vg/osd-block"))
+	assertRotated()
+}
+
+func TestRotateKMSEncryptionKey(t *testing.T) {
+	device := newFakeLUKSDevice(t)
+	context := &clusterd.Context{Executor: device.executor()}
+	_, err := encryptDevice(context, &reverseKMS{}, "/dev/sdb")
+	assert.NoError(t, err)
+	oldKeys := []string{}
+	for key := range device.keys {
+		oldKeys = append(oldKeys, key)
+	}
+	device.commands = nil
+
+	// the new key is saved in a new token before the old key and its token are removed
+	assert.NoError(t, RotateKMSEncryptionKey(context, &reverseKMS{}, "luks-uuid"))
+	assert.Equal(t, []string{"luksOpen", "luksAddKey", "luksOpen --verbose", "token import", "token remove", "luksKillSlot"}, device.commands)
+	assert.Equal(t, 1, len(device.keys))
+	for _, key := range oldKeys {
+		assert.False(t, device.hasKey(key))
+	}
+	assert.Equal(t, 1, len(device.tokens))
+	assert.NotEqual(t, "", device.tokens[1])
+
+	// the old key still unlocks the device if the new key cannot be saved
+	device.failCommand = "token import"
+	rotatedKeys := map[string]bool{}
+	for key := range device.keys {
+		rotatedKeys[key] = true
+	}
+	assert.Error(t, RotateKMSEncryptionKey(context, &reverseKMS{}, "luks-uuid"))
+	for key := range rotatedKeys {
+		assert.True(t, device.hasKey(key))
+	}
+	device.opened = map[string]bool{}
+	assert.NoError(t, OpenEncryptedDevice(context, &reverseKMS{}, "luks-uuid"))
+
+	// the key which could not be saved is removed by the next rotation
+	device.failCommand = ""
+	assert.NoError(t, RotateKMSEncryptionKey(context, &reverseKMS{}, "luks-uuid"))
+	assert.Equal(t, 1, len(device.keys))
+	assert.Equal(t, 1, len(device.tokens))
+}
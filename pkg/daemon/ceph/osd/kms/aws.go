@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AWSKMSProvider is the name of the AWS KMS provider
+	AWSKMSProvider = "aws-kms"
+
+	// AWSKeyIDKey is the id, the ARN or the alias of the KMS key wrapping the keys
+	AWSKeyIDKey = "AWS_KMS_KEY_ID"
+	// AWSRegionKey is the region of the KMS key
+	AWSRegionKey = "AWS_REGION"
+	// AWSAccessKeyIDKey is the access key of the IAM user accessing the key
+	AWSAccessKeyIDKey = "AWS_ACCESS_KEY_ID"
+	// AWSSecretAccessKeyKey is the secret key of the IAM user
+	AWSSecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	// AWSSessionTokenKey is the token of temporary credentials
+	AWSSessionTokenKey = "AWS_SESSION_TOKEN"
+	// AWSEndpointKey overrides the endpoint of the KMS service, e.g. for a VPC endpoint
+	AWSEndpointKey = "AWS_ENDPOINT"
+)
+
+func init() {
+	RegisterProvider(AWSKMSProvider, newAWSKMS)
+}
+
+type awsKMS struct {
+	client *kms.KMS
+	keyID  string
+}
+
+func newAWSKMS(config map[string]string) (Provider, error) {
+	values, err := requiredConfig(config, AWSKeyIDKey, AWSRegionKey, AWSAccessKeyIDKey, AWSSecretAccessKeyKey)
+	if err != nil {
+		return nil, err
+	}
+	awsConfig := aws.NewConfig().
+		WithRegion(values[1]).
+		WithCredentials(credentials.NewStaticCredentials(values[2], values[3], config[AWSSessionTokenKey])).
+		WithHTTPClient(newHTTPClient())
+	if endpoint := config[AWSEndpointKey]; endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(endpoint)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create aws session")
+	}
+	return &awsKMS{
+		client: kms.New(sess),
+		keyID:  values[0],
+	}, nil
+}
+
+func (a *awsKMS) WrapKey(key []byte) ([]byte, error) {
+	out, err := a.client.Encrypt(&kms.EncryptInput{
+		KeyId:     aws.String(a.keyID),
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to wrap key with %q", a.keyID)
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (a *awsKMS) UnwrapKey(wrapped []byte) ([]byte, error) {
+	out, err := a.client.Decrypt(&kms.DecryptInput{
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unwrap key with %q", a.keyID)
+	}
+	return out.Plaintext, nil
+}
+
+// GenerateKey generates a data key with KMS, which returns the key along with the key wrapped by the KMS key
+func (a *awsKMS) GenerateKey(size int) ([]byte, []byte, error) {
+	out, err := a.client.GenerateDataKey(&kms.GenerateDataKeyInput{
+		KeyId:         aws.String(a.keyID),
+		NumberOfBytes: aws.Int64(int64(size)),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to generate key with %q", a.keyID)
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AzureKeyVaultProvider is the name of the Azure Key Vault provider
+	AzureKeyVaultProvider = "azurekv"
+
+	// AzureVaultURLKey is the URL of the key vault
+	AzureVaultURLKey = "AZURE_VAULT_URL"
+	// AzureKeyNameKey is the name of the RSA key wrapping the keys
+	AzureKeyNameKey = "AZURE_KEY_NAME"
+	// AzureKeyVersionKey is the version of the key, the current version is used if not set
+	AzureKeyVersionKey = "AZURE_KEY_VERSION"
+	// AzureTenantIDKey is the tenant of the service principal accessing the vault
+	AzureTenantIDKey = "AZURE_TENANT_ID"
+	// AzureClientIDKey is the client id of the service principal
+	AzureClientIDKey = "AZURE_CLIENT_ID"
+	// AzureClientSecretKey is the secret of the service principal
+	AzureClientSecretKey = "AZURE_CLIENT_SECRET"
+	// AzureTokenURLKey overrides the endpoint issuing the tokens, e.g. for sovereign clouds
+	AzureTokenURLKey = "AZURE_TOKEN_URL"
+
+	azureKeyVaultAPIVersion = "7.1"
+	azureKeyVaultScope      = "https://vault.azure.net/.default"
+	azureWrapAlgorithm      = "RSA-OAEP-256"
+)
+
+func init() {
+	RegisterProvider(AzureKeyVaultProvider, newAzureKeyVault)
+}
+
+type azureKeyVault struct {
+	client       *http.Client
+	keyBaseURL   string
+	keyURL       string
+	clientID     string
+	clientSecret string
+	tokenURL     string
+}
+
+// azureWrappedKey is the wrapped key with the id of the key version that wrapped it, the key can be unwrapped after the
+// rotation of the key
+type azureWrappedKey struct {
+	KeyID string `json:"kid"`
+	Value string `json:"value"`
+}
+
+func newAzureKeyVault(config map[string]string) (Provider, error) {
+	values, err := requiredConfig(config, AzureVaultURLKey, AzureKeyNameKey, AzureTenantIDKey, AzureClientIDKey, AzureClientSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	keyBaseURL := fmt.Sprintf("%s/keys/%s", strings.TrimSuffix(values[0], "/"), values[1])
+	keyURL := keyBaseURL
+	if version := config[AzureKeyVersionKey]; version != "" {
+		keyURL = fmt.Sprintf("%s/%s", keyURL, version)
+	}
+	return &azureKeyVault{
+		client:       newHTTPClient(),
+		keyBaseURL:   keyBaseURL,
+		keyURL:       keyURL,
+		clientID:     values[3],
+		clientSecret: values[4],
+		tokenURL:     configOrDefault(config, AzureTokenURLKey, fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", values[2])),
+	}, nil
+}
+
+func (a *azureKeyVault) WrapKey(key []byte) ([]byte, error) {
+	var resp azureWrappedKey
+	err := a.keyOperation(a.keyURL+"/wrapkey", base64.RawURLEncoding.EncodeToString(key), &resp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to wrap key with %q", a.keyURL)
+	}
+	return json.Marshal(resp)
+}
+
+func (a *azureKeyVault) UnwrapKey(wrapped []byte) ([]byte, error) {
+	var wrappedKey azureWrappedKey
+	if err := json.Unmarshal(wrapped, &wrappedKey); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal wrapped key")
+	}
+	// the token of the vault is only sent to a version of the configured key
+	if !strings.HasPrefix(wrappedKey.KeyID, a.keyBaseURL+"/") {
+		return nil, errors.Errorf("key %q was not wrapped by %q", wrappedKey.KeyID, a.keyBaseURL)
+	}
+	var resp azureWrappedKey
+	if err := a.keyOperation(wrappedKey.KeyID+"/unwrapkey", wrappedKey.Value, &resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to unwrap key with %q", wrappedKey.KeyID)
+	}
+	key, err := base64.RawURLEncoding.DecodeString(resp.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode unwrapped key")
+	}
+	return key, nil
+}
+
+func (a *azureKeyVault) keyOperation(endpoint, value string, out interface{}) error {
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+	req, err := newJSONRequest(http.MethodPost, fmt.Sprintf("%s?api-version=%s", endpoint, azureKeyVaultAPIVersion), map[string]string{"alg": azureWrapAlgorithm, "value": value})
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return doJSONRequest(a.client, req, out)
+}
+
+// token gets an access token for the vault with the credentials of the service principal
+func (a *azureKeyVault) token() (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", a.clientID)
+	form.Set("client_secret", a.clientSecret)
+	form.Set("scope", azureKeyVaultScope)
+	req, err := http.NewRequest(http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSONRequest(a.client, req, &resp); err != nil {
+		return "", errors.Wrap(err, "failed to get an azure token")
+	}
+	logger.Debugf("got an azure token for client %q", a.clientID)
+	return resp.AccessToken, nil
+}
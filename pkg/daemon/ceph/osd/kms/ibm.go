@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// IBMKeyProtectProvider is the name of the IBM Key Protect provider
+	IBMKeyProtectProvider = "ibmkeyprotect"
+
+	// IBMKeyProtectInstanceIDKey is the id of the Key Protect instance
+	IBMKeyProtectInstanceIDKey = "IBM_KP_SERVICE_INSTANCE_ID"
+	// IBMKeyProtectRootKeyIDKey is the id of the root key wrapping the keys
+	IBMKeyProtectRootKeyIDKey = "IBM_KP_ROOT_KEY_ID"
+	// IBMKeyProtectAPIKeyKey is the API key of the service ID accessing the instance
+	IBMKeyProtectAPIKeyKey = "IBM_KP_SERVICE_API_KEY"
+	// IBMKeyProtectBaseURLKey is the endpoint of the Key Protect region
+	IBMKeyProtectBaseURLKey = "IBM_KP_BASE_URL"
+	// IBMKeyProtectTokenURLKey is the endpoint of the IAM service issuing the tokens
+	IBMKeyProtectTokenURLKey = "IBM_KP_TOKEN_URL"
+
+	defaultIBMKeyProtectBaseURL  = "https://us-south.kms.cloud.ibm.com"
+	defaultIBMKeyProtectTokenURL = "https://iam.cloud.ibm.com/identity/token"
+)
+
+func init() {
+	RegisterProvider(IBMKeyProtectProvider, newIBMKeyProtect)
+}
+
+type ibmKeyProtect struct {
+	client     *http.Client
+	instanceID string
+	rootKeyID  string
+	apiKey     string
+	baseURL    string
+	tokenURL   string
+}
+
+func newIBMKeyProtect(config map[string]string) (Provider, error) {
+	values, err := requiredConfig(config, IBMKeyProtectInstanceIDKey, IBMKeyProtectRootKeyIDKey, IBMKeyProtectAPIKeyKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ibmKeyProtect{
+		client:     newHTTPClient(),
+		instanceID: values[0],
+		rootKeyID:  values[1],
+		apiKey:     values[2],
+		baseURL:    strings.TrimSuffix(configOrDefault(config, IBMKeyProtectBaseURLKey, defaultIBMKeyProtectBaseURL), "/"),
+		tokenURL:   configOrDefault(config, IBMKeyProtectTokenURLKey, defaultIBMKeyProtectTokenURL),
+	}, nil
+}
+
+func (k *ibmKeyProtect) WrapKey(key []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	err := k.keyAction("wrap", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)}, &resp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to wrap key with root key %q", k.rootKeyID)
+	}
+	return []byte(resp.Ciphertext), nil
+}
+
+func (k *ibmKeyProtect) UnwrapKey(wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext string `json:"plaintext"`
+	}
+	err := k.keyAction("unwrap", map[string]string{"ciphertext": string(wrapped)}, &resp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unwrap key with root key %q", k.rootKeyID)
+	}
+	key, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode unwrapped key")
+	}
+	return key, nil
+}
+
+// keyAction runs an action of the root key
+func (k *ibmKeyProtect) keyAction(action string, in, out interface{}) error {
+	token, err := k.token()
+	if err != nil {
+		return err
+	}
+	req, err := newJSONRequest(http.MethodPost, fmt.Sprintf("%s/api/v2/keys/%s?action=%s", k.baseURL, k.rootKeyID, action), in)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.ibm.kms.key_action+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("bluemix-instance", k.instanceID)
+	return doJSONRequest(k.client, req, out)
+}
+
+// token exchanges the API key for an IAM access token
+func (k *ibmKeyProtect) token() (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "urn:ibm:params:oauth:grant-type:apikey")
+	form.Set("apikey", k.apiKey)
+	req, err := http.NewRequest(http.MethodPost, k.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSONRequest(k.client, req, &resp); err != nil {
+		return "", errors.Wrap(err, "failed to get an IAM token")
+	}
+	logger.Debugf("got an IAM token for key protect instance %q", k.instanceID)
+	return resp.AccessToken, nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kms protects the encryption keys of the OSDs with a key management service
+package kms
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", "kms")
+
+const (
+	// ProviderKey is the key of the connection details selecting the provider
+	ProviderKey = "KMS_PROVIDER"
+
+	requestTimeout = 30 * time.Second
+)
+
+// Provider wraps and unwraps the encryption keys with a key held by a key management service. The wrapped keys are
+// opaque to the caller and can be stored outside of the key management service.
+type Provider interface {
+	// WrapKey encrypts a key with the key of the key management service
+	WrapKey(key []byte) ([]byte, error)
+	// UnwrapKey decrypts a key wrapped by WrapKey
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// keyGenerator is implemented by the providers generating the keys with the key management service
+type keyGenerator interface {
+	// GenerateKey returns a new key of the given size and the key wrapped by the key management service
+	GenerateKey(size int) ([]byte, []byte, error)
+}
+
+// NewProviderFunc creates a provider from the connection details of the key management service
+type NewProviderFunc func(config map[string]string) (Provider, error)
+
+var (
+	providersLock sync.Mutex
+	providers     = map[string]NewProviderFunc{}
+)
+
+// RegisterProvider registers a key management service. The providers register themselves in their init() function.
+func RegisterProvider(name string, newProvider NewProviderFunc) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers[name] = newProvider
+}
+
+// Providers returns the names of the registered providers
+func Providers() []string {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	names := []string{}
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider creates the provider selected by the KMS_PROVIDER key of the connection details
+func NewProvider(config map[string]string) (Provider, error) {
+	name := config[ProviderKey]
+	providersLock.Lock()
+	newProvider, ok := providers[name]
+	providersLock.Unlock()
+	if !ok {
+		return nil, errors.Errorf("unknown kms provider %q, supported providers are %s", name, strings.Join(Providers(), ", "))
+	}
+	provider, err := newProvider(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to configure kms provider %q", name)
+	}
+	return provider, nil
+}
+
+// NewProviderFromEnv creates the provider from the connection details and the credentials set in the environment of
+// the OSD pods by the operator. It returns nil if no key management service is configured.
+func NewProviderFromEnv() (Provider, error) {
+	if os.Getenv(ProviderKey) == "" {
+		return nil, nil
+	}
+	config := map[string]string{}
+	for _, env := range os.Environ() {
+		if i := strings.Index(env, "="); i > 0 {
+			config[env[:i]] = env[i+1:]
+		}
+	}
+	return NewProvider(config)
+}
+
+// NewKey generates a key of the given size and wraps it with the key management service. It returns the key and the
+// wrapped key.
+func NewKey(provider Provider, size int) ([]byte, []byte, error) {
+	if generator, ok := provider.(keyGenerator); ok {
+		return generator.GenerateKey(size)
+	}
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate key")
+	}
+	wrapped, err := provider.WrapKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, wrapped, nil
+}
+
+// requiredConfig returns the values of the given keys of the connection details, it fails if any is missing
+func requiredConfig(config map[string]string, keys ...string) ([]string, error) {
+	values := []string{}
+	missing := []string{}
+	for _, key := range keys {
+		if config[key] == "" {
+			missing = append(missing, key)
+		}
+		values = append(values, config[key])
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("missing %s in the kms connection details", strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// configOrDefault returns the value of a key of the connection details or its default value
+func configOrDefault(config map[string]string, key, defaultValue string) string {
+	if value := config[key]; value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// doJSONRequest sends a request and decodes the JSON response in out
+func doJSONRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send request to %q", req.URL.Host)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response from %q", req.URL.Host)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("request to %q failed with status %q. %s", req.URL.Host, resp.Status, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal response from %q", req.URL.Host)
+	}
+	return nil
+}
+
+func newJSONRequest(method, url string, in interface{}) (*http.Request, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request")
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request to %q", url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
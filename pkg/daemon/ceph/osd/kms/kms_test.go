@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	assert.Equal(t, []string{AWSKMSProvider, AzureKeyVaultProvider, IBMKeyProtectProvider}, Providers())
+
+	_, err := NewProvider(map[string]string{ProviderKey: "vault"})
+	assert.Error(t, err)
+
+	_, err = NewProvider(map[string]string{ProviderKey: IBMKeyProtectProvider, IBMKeyProtectInstanceIDKey: "instance"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "IBM_KP_ROOT_KEY_ID, IBM_KP_SERVICE_API_KEY")
+}
+
+// reverseProvider wraps the keys by reversing them
+type reverseProvider struct {
+	generated bool
+}
+
+func (p *reverseProvider) WrapKey(key []byte) ([]byte, error) {
+	return []byte(reverse(string(key))), nil
+}
+
+func (p *reverseProvider) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return []byte(reverse(string(wrapped))), nil
+}
+
+type generatingProvider struct {
+	reverseProvider
+}
+
+func (p *generatingProvider) GenerateKey(size int) ([]byte, []byte, error) {
+	p.generated = true
+	key := []byte(strings.Repeat("k", size-1) + "0")
+	return key, []byte(reverse(string(key))), nil
+}
+
+func TestNewKey(t *testing.T) {
+	// the key is generated locally and wrapped by the key management service
+	key, wrapped, err := NewKey(&reverseProvider{}, 16)
+	assert.NoError(t, err)
+	assert.Equal(t, 16, len(key))
+	assert.Equal(t, reverse(string(key)), string(wrapped))
+	otherKey, _, err := NewKey(&reverseProvider{}, 16)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey)
+
+	// the key is generated by the key management service
+	provider := &generatingProvider{}
+	key, wrapped, err = NewKey(provider, 16)
+	assert.NoError(t, err)
+	assert.True(t, provider.generated)
+	assert.Equal(t, "kkkkkkkkkkkkkkk0", string(key))
+	assert.Equal(t, "0kkkkkkkkkkkkkkk", string(wrapped))
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	provider, err := NewProviderFromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+
+	os.Setenv(ProviderKey, IBMKeyProtectProvider)
+	os.Setenv(IBMKeyProtectInstanceIDKey, "instance")
+	os.Setenv(IBMKeyProtectRootKeyIDKey, "root-key")
+	os.Setenv(IBMKeyProtectAPIKeyKey, "api-key")
+	defer func() {
+		for _, key := range []string{ProviderKey, IBMKeyProtectInstanceIDKey, IBMKeyProtectRootKeyIDKey, IBMKeyProtectAPIKeyKey} {
+			os.Unsetenv(key)
+		}
+	}()
+	provider, err = NewProviderFromEnv()
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	os.Unsetenv(IBMKeyProtectAPIKeyKey)
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err)
+}
+
+// fakeKeyService reverses the keys it wraps and records the requests it receives
+func fakeKeyService(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, in map[string]string)) (*httptest.Server, *[]*http.Request) {
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if strings.HasSuffix(r.URL.Path, "/token") {
+			assert.NoError(t, r.ParseForm())
+			_, _ = w.Write([]byte(`{"access_token":"token-` + r.PostForm.Get("grant_type") + `"}`))
+			return
+		}
+		in := map[string]string{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		handle(w, r, in)
+	}))
+	return server, &requests
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func writeJSON(w http.ResponseWriter, out interface{}) {
+	b, _ := json.Marshal(out)
+	_, _ = w.Write(b)
+}
+
+func TestIBMKeyProtect(t *testing.T) {
+	server, requests := fakeKeyService(t, func(w http.ResponseWriter, r *http.Request, in map[string]string) {
+		assert.Equal(t, "/api/v2/keys/root-key", r.URL.Path)
+		assert.Equal(t, "Bearer token-urn:ibm:params:oauth:grant-type:apikey", r.Header.Get("Authorization"))
+		assert.Equal(t, "instance", r.Header.Get("bluemix-instance"))
+		if r.URL.Query().Get("action") == "wrap" {
+			writeJSON(w, map[string]string{"ciphertext": reverse(in["plaintext"])})
+			return
+		}
+		writeJSON(w, map[string]string{"plaintext": reverse(in["ciphertext"])})
+	})
+	defer server.Close()
+
+	provider, err := NewProvider(map[string]string{
+		ProviderKey:                IBMKeyProtectProvider,
+		IBMKeyProtectInstanceIDKey: "instance",
+		IBMKeyProtectRootKeyIDKey:  "root-key",
+		IBMKeyProtectAPIKeyKey:     "api-key",
+		IBMKeyProtectBaseURLKey:    server.URL,
+		IBMKeyProtectTokenURLKey:   server.URL + "/token",
+	})
+	assert.NoError(t, err)
+
+	wrapped, err := provider.WrapKey([]byte("dmcrypt-key"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, "dmcrypt-key", string(wrapped))
+	key, err := provider.UnwrapKey(wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmcrypt-key", string(key))
+	assert.Equal(t, 4, len(*requests))
+}
+
+func TestAzureKeyVault(t *testing.T) {
+	var server *httptest.Server
+	server, _ = fakeKeyService(t, func(w http.ResponseWriter, r *http.Request, in map[string]string) {
+		assert.Equal(t, "token-client_credentials", strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		assert.Equal(t, azureKeyVaultAPIVersion, r.URL.Query().Get("api-version"))
+		assert.Equal(t, azureWrapAlgorithm, in["alg"])
+		switch r.URL.Path {
+		case "/keys/osd-key/wrapkey":
+			writeJSON(w, azureWrappedKey{KeyID: server.URL + "/keys/osd-key/v1", Value: reverse(in["value"])})
+		case "/keys/osd-key/v1/unwrapkey":
+			writeJSON(w, azureWrappedKey{KeyID: server.URL + "/keys/osd-key/v1", Value: reverse(in["value"])})
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	config := map[string]string{
+		ProviderKey:          AzureKeyVaultProvider,
+		AzureVaultURLKey:     server.URL,
+		AzureKeyNameKey:      "osd-key",
+		AzureTenantIDKey:     "tenant",
+		AzureClientIDKey:     "client",
+		AzureClientSecretKey: "secret",
+		AzureTokenURLKey:     server.URL + "/token",
+	}
+	provider, err := NewProvider(config)
+	assert.NoError(t, err)
+
+	wrapped, err := provider.WrapKey([]byte("dmcrypt-key"))
+	assert.NoError(t, err)
+	key, err := provider.UnwrapKey(wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmcrypt-key", string(key))
+
+	// the keys wrapped by another key are not sent to the vault
+	_, err = provider.UnwrapKey([]byte(`{"kid":"https://attacker/keys/osd-key/v1","value":"x"}`))
+	assert.Error(t, err)
+}
+
+func TestAWSKMS(t *testing.T) {
+	actions := []string{}
+	// the fake KMS wraps the keys by reversing them
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		in := map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "TrentService.")
+		actions = append(actions, action)
+		switch action {
+		case "Encrypt":
+			assert.Equal(t, "alias/osd", in["KeyId"])
+			writeJSON(w, map[string]string{"CiphertextBlob": reverseBase64(t, in["Plaintext"])})
+		case "Decrypt":
+			writeJSON(w, map[string]string{"Plaintext": reverseBase64(t, in["CiphertextBlob"])})
+		case "GenerateDataKey":
+			assert.Equal(t, "alias/osd", in["KeyId"])
+			assert.Equal(t, float64(4), in["NumberOfBytes"])
+			writeJSON(w, map[string]string{
+				"Plaintext":      base64.StdEncoding.EncodeToString([]byte("key0")),
+				"CiphertextBlob": base64.StdEncoding.EncodeToString([]byte("0yek")),
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(map[string]string{
+		ProviderKey:           AWSKMSProvider,
+		AWSKeyIDKey:           "alias/osd",
+		AWSRegionKey:          "us-east-1",
+		AWSAccessKeyIDKey:     "AKID",
+		AWSSecretAccessKeyKey: "secret",
+		AWSSessionTokenKey:    "session",
+		AWSEndpointKey:        server.URL,
+	})
+	assert.NoError(t, err)
+
+	wrapped, err := provider.WrapKey([]byte("dmcrypt-key"))
+	assert.NoError(t, err)
+	assert.Equal(t, "yek-tpyrcmd", string(wrapped))
+	key, err := provider.UnwrapKey(wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "dmcrypt-key", string(key))
+
+	// the keys are generated by KMS
+	key, wrapped, err = NewKey(provider, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "key0", string(key))
+	assert.Equal(t, "0yek", string(wrapped))
+	assert.Equal(t, []string{"Encrypt", "Decrypt", "GenerateDataKey"}, actions)
+}
+
+func reverseBase64(t *testing.T, value interface{}) string {
+	decoded, err := base64.StdEncoding.DecodeString(value.(string))
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString([]byte(reverse(string(decoded))))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+const (
+	defaultStoreType = "bluestore"
+	osdIDsFlag       = "--osd-ids"
+)
+
+// storeFlags returns the ceph-volume flags creating the osds with the given object store
+func storeFlags(storeType string) []string {
+	if storeType == "" || storeType == defaultStoreType {
+		return []string{"--bluestore"}
+	}
+	return []string{"--objectstore", storeType}
+}
+
+// prepareArgs returns the ceph-volume args with the flags of the store before the trailing args. The args have no spare
+// capacity since the args of each device are appended to them.
+func prepareArgs(storeType string, args []string, trailing ...string) []string {
+	flags := storeFlags(storeType)
+	result := make([]string, 0, len(args)+len(flags)+len(trailing))
+	result = append(result, args...)
+	result = append(result, flags...)
+	return append(result, trailing...)
+}
+
+func osdIDsArgs(ids []int) []string {
+	args := []string{osdIDsFlag}
+	for _, id := range ids {
+		args = append(args, strconv.Itoa(id))
+	}
+	return args
+}
+
+// zapReplacedOSDs zaps the devices of the destroyed osds which are recreated with the same ids, so that the devices
+// are available again for the provisioning. The ids of the osds are kept by device to be reused by ceph-volume.
+func (a *OsdAgent) zapReplacedOSDs(context *clusterd.Context) error {
+	if len(a.replaceOSDIDs) == 0 || a.pvcBacked {
+		return nil
+	}
+
+	result, err := context.Executor.ExecuteCommandWithOutput(cephVolumeCmd, "lvm", "list", "--format", "json")
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve ceph-volume lvm list results")
+	}
+	var cephVolumeResult map[string][]osdInfo
+	if err := json.Unmarshal([]byte(result), &cephVolumeResult); err != nil {
+		return errors.Wrap(err, "failed to unmarshal ceph-volume lvm list results")
+	}
+
+	a.replacedOSDs = map[string][]int{}
+	for _, id := range a.replaceOSDIDs {
+		volumes, ok := cephVolumeResult[strconv.Itoa(id)]
+		if !ok {
+			// the osd was zapped by a previous run of the provisioning
+			logger.Warningf("no volume found for osd %d to re-provision, a new id will be allocated to its device", id)
+			continue
+		}
+		for _, volume := range volumes {
+			if volume.Type != "block" {
+				continue
+			}
+			for _, device := range volume.Devices {
+				a.replacedOSDs[device] = append(a.replacedOSDs[device], id)
+			}
+		}
+
+		logger.Infof("zapping osd %d to re-provision it", id)
+		if err := context.Executor.ExecuteCommand(cephVolumeCmd, "lvm", "zap", "--osd-id", strconv.Itoa(id), "--destroy"); err != nil {
+			return errors.Wrapf(err, "failed to zap osd %d", id)
+		}
+	}
+	return nil
+}
+
+// replacedOSDIDs returns the ids of the zapped osds of a device
+func (a *OsdAgent) replacedOSDIDs(device string) []int {
+	return a.replacedOSDs[device]
+}
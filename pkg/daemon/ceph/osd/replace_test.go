@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeReplaceLVMList = `{
+  "1": [{"devices": ["/dev/sdb"], "type": "block"}, {"devices": ["/dev/nvme0n1"], "type": "db"}],
+  "2": [{"devices": ["/dev/sdb"], "type": "block"}],
+  "3": [{"devices": ["/dev/sdc"], "type": "block"}]
+}`
+
+func TestStoreFlags(t *testing.T) {
+	assert.Equal(t, []string{"--bluestore"}, storeFlags(""))
+	assert.Equal(t, []string{"--bluestore"}, storeFlags("bluestore"))
+	assert.Equal(t, []string{"--objectstore", "bluestore-rdr"}, storeFlags("bluestore-rdr"))
+}
+
+func TestZapReplacedOSDs(t *testing.T) {
+	zapped := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(t, []string{"lvm", "list", "--format", "json"}, args)
+			return fakeReplaceLVMList, nil
+		},
+		MockExecuteCommand: func(command string, args ...string) error {
+			zapped = append(zapped, strings.Join(args, " "))
+			return nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	// nothing is zapped without osds to replace
+	agent := &OsdAgent{}
+	assert.NoError(t, agent.zapReplacedOSDs(context))
+	assert.Empty(t, zapped)
+
+	// the ids of the zapped osds are kept by data device, an osd zapped previously is skipped
+	agent = &OsdAgent{replaceOSDIDs: []int{1, 2, 4}}
+	assert.NoError(t, agent.zapReplacedOSDs(context))
+	assert.Equal(t, []string{"lvm zap --osd-id 1 --destroy", "lvm zap --osd-id 2 --destroy"}, zapped)
+	assert.Equal(t, []int{1, 2}, agent.replacedOSDIDs("/dev/sdb"))
+	assert.Nil(t, agent.replacedOSDIDs("/dev/sdc"))
+	assert.Nil(t, agent.replacedOSDIDs("/dev/nvme0n1"))
+	assert.Equal(t, []string{"--osd-ids", "1", "2"}, osdIDsArgs(agent.replacedOSDIDs("/dev/sdb")))
+}
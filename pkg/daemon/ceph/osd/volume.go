@@ -42,6 +42,7 @@ const (
 	encryptedFlag        = "--dmcrypt"
 	databaseSizeFlag     = "--block-db-size"
 	dbDeviceFlag         = "--db-devices"
+	walDeviceFlag        = "--wal-devices"
 	cephVolumeCmd        = "ceph-volume"
 	cephVolumeMinDBSize  = 1024 // 1GB
 )
@@ -65,17 +66,19 @@ type osdInfoBlock struct {
 }
 
 type osdInfo struct {
-	Name string  `json:"name"`
-	Path string  `json:"path"`
-	Tags osdTags `json:"tags"`
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Devices []string `json:"devices"`
+	Tags    osdTags  `json:"tags"`
 	// "data" or "journal" for filestore and "block" for bluestore
 	Type string `json:"type"`
 }
 
 type osdTags struct {
-	OSDFSID     string `json:"ceph.osd_fsid"`
-	Encrypted   string `json:"ceph.encrypted"`
-	ClusterFSID string `json:"ceph.cluster_fsid"`
+	OSDFSID          string `json:"ceph.osd_fsid"`
+	Encrypted        string `json:"ceph.encrypted"`
+	ClusterFSID      string `json:"ceph.cluster_fsid"`
+	CrushDeviceClass string `json:"ceph.crush_device_class"`
 }
 
 type cephVolReport struct {
@@ -233,10 +236,9 @@ func (a *OsdAgent) initializeBlockPVC(context *clusterd.Context, devices *Device
 	err := os.MkdirAll(cvLogDir, 0750)
 	if err != nil {
 		logger.Errorf("failed to create ceph-volume log directory %q, continue with default %q. %v", cvLogDir, cephLogDir, err)
-		baseArgs = []string{"-oL", cephVolumeCmd, cephVolumeMode, "prepare", "--bluestore"}
+		baseArgs = prepareArgs(a.storeConfig.StoreType, []string{"-oL", cephVolumeCmd, cephVolumeMode, "prepare"})
 	} else {
-		// Always force Bluestore!
-		baseArgs = []string{"-oL", cephVolumeCmd, "--log-path", cvLogDir, cephVolumeMode, "prepare", "--bluestore"}
+		baseArgs = prepareArgs(a.storeConfig.StoreType, []string{"-oL", cephVolumeCmd, "--log-path", cvLogDir, cephVolumeMode, "prepare"})
 	}
 
 	var metadataArg []string
@@ -385,13 +387,14 @@ func UpdateLVMConfig(context *clusterd.Context, onPVC, lvBackedPV bool) error {
 }
 
 func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceOsdMapping) error {
-	storeFlag := "--bluestore"
-
 	// Use stdbuf to capture the python output buffer such that we can write to the pod log as the logging happens
 	// instead of using the default buffering that will log everything after ceph-volume exits
 	baseCommand := "stdbuf"
-	baseArgs := []string{"-oL", cephVolumeCmd, "lvm", "batch", "--prepare", storeFlag, "--yes"}
-	if a.storeConfig.EncryptedDevice {
+	baseArgs := prepareArgs(a.storeConfig.StoreType, []string{"-oL", cephVolumeCmd, "lvm", "batch", "--prepare"}, "--yes")
+	// the devices are encrypted by rook rather than by ceph-volume when a key management service is configured, since
+	// ceph-volume saves the dmcrypt keys in the config-key store of the mons
+	encryptWithKMS := a.storeConfig.EncryptedDevice && a.kmsProvider != nil
+	if a.storeConfig.EncryptedDevice && !encryptWithKMS {
 		baseArgs = append(baseArgs, encryptedFlag)
 	}
 
@@ -399,6 +402,8 @@ func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceO
 	batchArgs := baseArgs
 
 	metadataDevices := make(map[string]map[string]string)
+	// the paths of the devices as listed by ceph-volume, by device arg
+	listedPaths := make(map[string]string)
 	for name, device := range devices.Entries {
 		if device.Data == -1 {
 			if device.Metadata != nil {
@@ -414,47 +419,76 @@ func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceO
 					deviceArg = devlink
 				}
 			}
+			listedPath := deviceArg
+			if usePersistentDevicePath(device.DeviceType) {
+				deviceArg = persistentDevicePath(name, device.PersistentDevicePaths)
+				logger.Infof("using persistent path %q of device %s", deviceArg, name)
+			}
+			listedPaths[deviceArg] = listedPath
 
 			deviceOSDCount := osdsPerDeviceCount
 			if device.Config.OSDsPerDevice > 1 {
 				deviceOSDCount = sanitizeOSDsPerDevice(device.Config.OSDsPerDevice)
+			} else if count, ok := a.storeConfig.OSDsPerDeviceClass[deviceClass(name, device)]; ok && a.storeConfig.OSDsPerDevice <= 1 {
+				// the osds per device of the class apply when the storage settings do not set more than one
+				logger.Infof("using %d osds per device for device %s of class %q", count, name, deviceClass(name, device))
+				deviceOSDCount = sanitizeOSDsPerDevice(count)
 			}
 
-			if a.metadataDevice != "" || device.Config.MetadataDevice != "" {
+			if encryptWithKMS {
+				if a.metadataDevice != "" || device.Config.MetadataDevice != "" || device.Config.WalDevice != "" {
+					return errors.Errorf("device %s cannot be encrypted with the key management service with a metadata or wal device", name)
+				}
+				encryptedPath, err := encryptDevice(context, a.kmsProvider, deviceArg)
+				if err != nil {
+					return err
+				}
+				deviceArg = encryptedPath
+			}
+
+			if a.metadataDevice != "" || device.Config.MetadataDevice != "" || device.Config.WalDevice != "" {
 				// When mixed hdd/ssd devices are given, ceph-volume configures db lv on the ssd.
 				// the device will be configured as a batch at the end of the method
 				md := a.metadataDevice
 				if device.Config.MetadataDevice != "" {
 					md = device.Config.MetadataDevice
 				}
+				// the devices are batched by metadata and wal devices
+				group := md
+				if device.Config.WalDevice != "" {
+					group = md + "," + device.Config.WalDevice
+					logger.Infof("using %s as walDevice for device %s", device.Config.WalDevice, deviceArg)
+				}
 				logger.Infof("using %s as metadataDevice for device %s and let ceph-volume lvm batch decide how to create volumes", md, deviceArg)
-				if _, ok := metadataDevices[md]; ok {
+				if _, ok := metadataDevices[group]; ok {
 					// Fail when two devices using the same metadata device have different values for osdsPerDevice
-					metadataDevices[md]["devices"] += " " + deviceArg
-					if deviceOSDCount != metadataDevices[md]["osdsperdevice"] {
-						return errors.Errorf("metadataDevice (%s) has more than 1 osdsPerDevice value set: %s != %s", md, deviceOSDCount, metadataDevices[md]["osdsperdevice"])
+					metadataDevices[group]["devices"] += " " + deviceArg
+					if deviceOSDCount != metadataDevices[group]["osdsperdevice"] {
+						return errors.Errorf("metadataDevice (%s) has more than 1 osdsPerDevice value set: %s != %s", md, deviceOSDCount, metadataDevices[group]["osdsperdevice"])
 					}
 				} else {
-					metadataDevices[md] = make(map[string]string)
-					metadataDevices[md]["osdsperdevice"] = deviceOSDCount
+					metadataDevices[group] = make(map[string]string)
+					metadataDevices[group]["metadatadevice"] = md
+					metadataDevices[group]["waldevice"] = device.Config.WalDevice
+					metadataDevices[group]["osdsperdevice"] = deviceOSDCount
 					if device.Config.DeviceClass != "" {
-						metadataDevices[md]["deviceclass"] = device.Config.DeviceClass
+						metadataDevices[group]["deviceclass"] = device.Config.DeviceClass
 					}
-					metadataDevices[md]["devices"] = deviceArg
+					metadataDevices[group]["devices"] = deviceArg
 				}
 				deviceDBSizeMB := getDatabaseSize(a.storeConfig.DatabaseSizeMB, device.Config.DatabaseSizeMB)
-				if storeFlag == "--bluestore" && deviceDBSizeMB > 0 {
+				if md != "" && deviceDBSizeMB > 0 {
 					if deviceDBSizeMB < cephVolumeMinDBSize {
 						// ceph-volume will convert this value to ?G. It needs to be > 1G to invoke lvcreate.
 						logger.Infof("skipping databaseSizeMB setting (%d). For it should be larger than %dMB.", deviceDBSizeMB, cephVolumeMinDBSize)
 					} else {
 						dbSizeString := strconv.FormatUint(display.MbTob(uint64(deviceDBSizeMB)), 10)
-						if _, ok := metadataDevices[md]["databasesizemb"]; ok {
-							if metadataDevices[md]["databasesizemb"] != dbSizeString {
-								return errors.Errorf("metadataDevice (%s) has more than 1 databaseSizeMB value set: %s != %s", md, metadataDevices[md]["databasesizemb"], dbSizeString)
+						if _, ok := metadataDevices[group]["databasesizemb"]; ok {
+							if metadataDevices[group]["databasesizemb"] != dbSizeString {
+								return errors.Errorf("metadataDevice (%s) has more than 1 databaseSizeMB value set: %s != %s", md, metadataDevices[group]["databasesizemb"], dbSizeString)
 							}
 						} else {
-							metadataDevices[md]["databasesizemb"] = dbSizeString
+							metadataDevices[group]["databasesizemb"] = dbSizeString
 						}
 					}
 				}
@@ -472,6 +506,11 @@ func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceO
 					}...)
 				}
 
+				// the destroyed osds of the device are recreated with the same ids
+				if ids := a.replacedOSDIDs(listedPath); len(ids) > 0 {
+					immediateExecuteArgs = append(immediateExecuteArgs, osdIDsArgs(ids)...)
+				}
+
 				// Reporting
 				immediateReportArgs := append(immediateExecuteArgs, []string{
 					"--report",
@@ -495,7 +534,8 @@ func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceO
 		}
 	}
 
-	for md, conf := range metadataDevices {
+	for _, conf := range metadataDevices {
+		md := conf["metadatadevice"]
 
 		mdArgs := batchArgs
 		if _, ok := conf["osdsperdevice"]; ok {
@@ -518,10 +558,26 @@ func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceO
 		}
 		mdArgs = append(mdArgs, strings.Split(conf["devices"], " ")...)
 
-		mdArgs = append(mdArgs, []string{
-			dbDeviceFlag,
-			path.Join("/dev", md),
-		}...)
+		ids := []int{}
+		for _, device := range strings.Split(conf["devices"], " ") {
+			ids = append(ids, a.replacedOSDIDs(listedPaths[device])...)
+		}
+		if len(ids) > 0 {
+			mdArgs = append(mdArgs, osdIDsArgs(ids)...)
+		}
+
+		if md != "" {
+			mdArgs = append(mdArgs, []string{
+				dbDeviceFlag,
+				devicePath(md),
+			}...)
+		}
+		if conf["waldevice"] != "" {
+			mdArgs = append(mdArgs, []string{
+				walDeviceFlag,
+				devicePath(conf["waldevice"]),
+			}...)
+		}
 
 		// Reporting
 		reportArgs := append(mdArgs, []string{
@@ -549,7 +605,7 @@ func (a *OsdAgent) initializeDevices(context *clusterd.Context, devices *DeviceO
 			return errors.Wrap(err, "failed to unmarshal ceph-volume report json")
 		}
 
-		if path.Join("/dev", md) != cvReport.Vg.Devices {
+		if md != "" && devicePath(md) != cvReport.Vg.Devices {
 			return errors.Errorf("ceph-volume did not use the expected metadataDevice [%s]", md)
 		}
 
@@ -601,7 +657,7 @@ func GetCephVolumeLVMOSDs(context *clusterd.Context, clusterInfo *client.Cluster
 			logger.Errorf("bad osd returned from ceph-volume %q", name)
 			continue
 		}
-		var osdFSID string
+		var osdFSID, devicePath, crushDeviceClass string
 		store := "bluestore"
 		for _, osd := range osdInfo {
 			if osd.Tags.ClusterFSID != cephfsid {
@@ -612,6 +668,10 @@ func GetCephVolumeLVMOSDs(context *clusterd.Context, clusterInfo *client.Cluster
 			if osd.Type == "journal" {
 				store = "filestore"
 			}
+			if (osd.Type == "block" || osd.Type == "data") && len(osd.Devices) > 0 {
+				devicePath = osd.Devices[0]
+				crushDeviceClass = osd.Tags.CrushDeviceClass
+			}
 
 			// If no lv is specified let's take the one we discovered
 			if lv == "" {
@@ -640,6 +700,10 @@ func GetCephVolumeLVMOSDs(context *clusterd.Context, clusterInfo *client.Cluster
 			LVBackedPV:    lvBackedPV,
 			CVMode:        cvMode,
 			Store:         store,
+			DeviceID:      deviceID(context, devicePath),
+			DeviceClass:   osdDeviceClass(context, devicePath, crushDeviceClass),
+			// the device is opened with the key of the key management service before the osd is activated
+			EncryptionKeyID: EncryptionKeyID(devicePath),
 		}
 		osds = append(osds, osd)
 	}
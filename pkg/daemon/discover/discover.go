@@ -73,16 +73,18 @@ type CephVolumeInventory struct {
 }
 
 // Run is the entry point of that package execution
-func Run(context *clusterd.Context, probeInterval time.Duration, useCV bool) error {
+func Run(context *clusterd.Context, probeInterval time.Duration, useCV, collectSmart bool) error {
 	if context == nil {
 		return fmt.Errorf("nil context")
 	}
 	logger.Debugf("device discovery interval is %q", probeInterval.String())
 	logger.Debugf("use ceph-volume inventory is %t", useCV)
+	logger.Debugf("collect SMART data is %t", collectSmart)
 	nodeName = os.Getenv(k8sutil.NodeNameEnvVar)
 	namespace = os.Getenv(k8sutil.PodNamespaceEnvVar)
 	cmName = k8sutil.TruncateNodeName(LocalDiskCMName, nodeName)
 	useCVInventory = useCV
+	collectSmartData = collectSmart
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, syscall.SIGTERM)
 
@@ -92,17 +94,29 @@ func Run(context *clusterd.Context, probeInterval time.Duration, useCV bool) err
 		return err
 	}
 
+	if err := updateDeviceLocate(context); err != nil {
+		logger.Errorf("failed to update the locate LEDs of the devices. %v", err)
+	}
+
 	udevEvents := make(chan string)
 	go udevBlockMonitor(udevEvents, udevEventPeriod)
+	probeTicker := time.NewTicker(probeInterval)
+	defer probeTicker.Stop()
+	locateTicker := time.NewTicker(locatePeriod)
+	defer locateTicker.Stop()
 	for {
 		select {
 		case <-sigc:
 			logger.Infof("shutdown signal received, exiting...")
 			return nil
-		case <-time.After(probeInterval):
+		case <-probeTicker.C:
 			if err := updateDeviceCM(context); err != nil {
 				logger.Errorf("failed to update device configmap during probe interval. %v", err)
 			}
+		case <-locateTicker.C:
+			if err := updateDeviceLocate(context); err != nil {
+				logger.Errorf("failed to update the locate LEDs of the devices. %v", err)
+			}
 		case _, ok := <-udevEvents:
 			if ok {
 				logger.Info("trigger probe from udev event")
@@ -335,11 +349,27 @@ func updateDeviceCM(context *clusterd.Context) error {
 	}
 
 	deviceStr := string(deviceJSON)
+	var health map[string]DeviceHealth
+	healthStr := ""
+	if collectSmartData {
+		health = probeDevicesHealth(context, devices)
+		healthJSON, err := json.Marshal(health)
+		if err != nil {
+			logger.Infof("failed to marshal the health of the devices: %v", err)
+			return err
+		}
+		healthStr = string(healthJSON)
+	}
+	if err := updateDeviceInventory(context, devices, health); err != nil {
+		logger.Warningf("failed to update the device inventory. %v", err)
+	}
+	lastHealth := ""
 	if cm == nil {
 		cm, err = context.Clientset.CoreV1().ConfigMaps(namespace).Get(cmName, metav1.GetOptions{})
 	}
 	if err == nil {
 		lastDevice = cm.Data[LocalDiskCMData]
+		lastHealth = cm.Data[LocalDiskCMHealth]
 		logger.Debugf("last devices %s", lastDevice)
 	} else {
 		if !kerrors.IsNotFound(err) {
@@ -349,6 +379,9 @@ func updateDeviceCM(context *clusterd.Context) error {
 
 		data := make(map[string]string, 1)
 		data[LocalDiskCMData] = deviceStr
+		if healthStr != "" {
+			data[LocalDiskCMHealth] = healthStr
+		}
 
 		// the map doesn't exist yet, create it now
 		cm = &v1.ConfigMap{
@@ -377,15 +410,23 @@ func updateDeviceCM(context *clusterd.Context) error {
 			return fmt.Errorf("failed to create local device map %s: %+v", cmName, err)
 		}
 		lastDevice = deviceStr
+		lastHealth = healthStr
 	}
 	devicesEqual, err := DeviceListsEqual(lastDevice, deviceStr)
 	if err != nil {
 		return fmt.Errorf("failed to compare device lists: %v", err)
 	}
-	if !devicesEqual {
-		data := make(map[string]string, 1)
-		data[LocalDiskCMData] = deviceStr
-		cm.Data = data
+	if !devicesEqual || healthStr != lastHealth {
+		// the other data of the config map like the located devices is kept
+		if cm.Data == nil {
+			cm.Data = make(map[string]string, 1)
+		}
+		cm.Data[LocalDiskCMData] = deviceStr
+		if healthStr != "" {
+			cm.Data[LocalDiskCMHealth] = healthStr
+		} else {
+			delete(cm.Data, LocalDiskCMHealth)
+		}
 		cm, err = context.Clientset.CoreV1().ConfigMaps(namespace).Update(cm)
 		if err != nil {
 			logger.Infof("failed to update configmap %s: %v", cmName, err)
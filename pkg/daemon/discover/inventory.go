@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/sys"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the reason of ceph-volume for the devices prepared as raw OSDs
+const bluestoreLabelReason = "Has BlueStore device label"
+
+// cephVolumeLV is the part of the logical volumes reported by ceph-volume inventory used in the inventory
+type cephVolumeLV struct {
+	OSDID string `json:"osd_id"`
+}
+
+// updateDeviceInventory creates or updates the CephDeviceInventory of the node with the probed devices. The
+// inventory is named after the node, in the namespace of the discover daemon.
+func updateDeviceInventory(context *clusterd.Context, devices []sys.LocalDisk, health map[string]DeviceHealth) error {
+	if context.RookClientset == nil {
+		return nil
+	}
+	status := &cephv1.DeviceInventoryStatus{Devices: newInventoryDevices(devices, health)}
+	inventories := context.RookClientset.CephV1().CephDeviceInventories(namespace)
+	inventory, err := inventories.Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get the device inventory of node %q", nodeName)
+		}
+		inventory = &cephv1.CephDeviceInventory{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nodeName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					k8sutil.AppAttr: AppName,
+					NodeAttr:        nodeName,
+				},
+			},
+			Spec:   cephv1.DeviceInventorySpec{NodeName: nodeName},
+			Status: status,
+		}
+		// the inventory is removed with the discover daemon set like the device config map
+		discoverPod, err := k8sutil.GetRunningPod(context.Clientset)
+		if err != nil {
+			logger.Warningf("failed to get discover pod to set ownerref. %+v", err)
+		} else {
+			k8sutil.SetOwnerRefsWithoutBlockOwner(&inventory.ObjectMeta, discoverPod.OwnerReferences)
+		}
+		if _, err := inventories.Create(inventory); err != nil {
+			return errors.Wrapf(err, "failed to create the device inventory of node %q", nodeName)
+		}
+		logger.Infof("created the device inventory of node %q", nodeName)
+		return nil
+	}
+
+	if reflect.DeepEqual(inventory.Status, status) {
+		return nil
+	}
+	inventory.Status = status
+	if _, err := inventories.Update(inventory); err != nil {
+		return errors.Wrapf(err, "failed to update the device inventory of node %q", nodeName)
+	}
+	logger.Infof("updated the device inventory of node %q", nodeName)
+	return nil
+}
+
+// newInventoryDevices returns the devices of the inventory, sorted by name. The partitions are not reported, they
+// are in the partitions of the devices.
+func newInventoryDevices(devices []sys.LocalDisk, health map[string]DeviceHealth) []cephv1.InventoryDevice {
+	result := []cephv1.InventoryDevice{}
+	for _, device := range devices {
+		if device.Type == sys.PartType {
+			continue
+		}
+		inventoryDevice := cephv1.InventoryDevice{
+			Name:       device.Name,
+			Path:       path.Join("/dev", device.Name),
+			DevLinks:   strings.Fields(device.DevLinks),
+			Type:       device.Type,
+			WWN:        device.WWN,
+			Serial:     device.Serial,
+			Vendor:     device.Vendor,
+			Model:      device.Model,
+			Size:       device.Size,
+			Rotational: device.Rotational,
+			Empty:      device.Empty,
+		}
+		inventoryDevice.InUseByOSD, inventoryDevice.OSDIDs = deviceOSDs(device.CephVolumeData)
+		if h, ok := health[device.Name]; ok {
+			inventoryDevice.Health = h.Status()
+		}
+		result = append(result, inventoryDevice)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// deviceOSDs returns whether ceph-volume reports the device is used by an OSD, as a logical volume of an OSD or as a
+// raw OSD device, and the ids of the OSDs of its logical volumes
+func deviceOSDs(cephVolumeData string) (bool, []int) {
+	if cephVolumeData == "" {
+		return false, nil
+	}
+	var cv CephVolumeInventory
+	if err := json.Unmarshal([]byte(cephVolumeData), &cv); err != nil {
+		logger.Warningf("failed to parse the ceph-volume data %q. %v", cephVolumeData, err)
+		return false, nil
+	}
+
+	var osdIDs []int
+	var lvs []cephVolumeLV
+	if len(cv.LVS) > 0 {
+		if err := json.Unmarshal(cv.LVS, &lvs); err != nil {
+			logger.Warningf("failed to parse the logical volumes of ceph-volume %q. %v", string(cv.LVS), err)
+		}
+	}
+	for _, lv := range lvs {
+		if lv.OSDID == "" {
+			continue
+		}
+		id, err := strconv.Atoi(lv.OSDID)
+		if err != nil {
+			logger.Warningf("invalid osd id %q reported by ceph-volume", lv.OSDID)
+			continue
+		}
+		osdIDs = append(osdIDs, id)
+	}
+	sort.Ints(osdIDs)
+	if len(osdIDs) > 0 {
+		return true, osdIDs
+	}
+
+	var reasons []string
+	if len(cv.RejectedReasons) > 0 {
+		if err := json.Unmarshal(cv.RejectedReasons, &reasons); err != nil {
+			logger.Warningf("failed to parse the rejected reasons of ceph-volume %q. %v", string(cv.RejectedReasons), err)
+		}
+	}
+	for _, reason := range reasons {
+		if reason == bluestoreLabelReason {
+			return true, nil
+		}
+	}
+	return false, nil
+}
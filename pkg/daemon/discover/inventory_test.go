@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"testing"
+
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/sys"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeviceOSDs(t *testing.T) {
+	inUse, ids := deviceOSDs("")
+	assert.False(t, inUse)
+	assert.Nil(t, ids)
+
+	inUse, ids = deviceOSDs(`{"path":"/dev/sdb","available":false,"rejected_reasons":["locked"],"lvs":[{"name":"osd-block-1","osd_id":"3"},{"name":"osd-block-2","osd_id":"1"}]}`)
+	assert.True(t, inUse)
+	assert.Equal(t, []int{1, 3}, ids)
+
+	inUse, ids = deviceOSDs(`{"path":"/dev/sdc","available":false,"rejected_reasons":["Has BlueStore device label"],"lvs":[]}`)
+	assert.True(t, inUse)
+	assert.Nil(t, ids)
+
+	inUse, _ = deviceOSDs(`{"path":"/dev/sdd","available":true,"rejected_reasons":[],"lvs":[]}`)
+	assert.False(t, inUse)
+}
+
+func TestUpdateDeviceInventory(t *testing.T) {
+	nodeName, namespace = "node1", "rook-ceph"
+	rookClientset := rookfake.NewSimpleClientset()
+	context := &clusterd.Context{Clientset: fake.NewSimpleClientset(), RookClientset: rookClientset}
+	passed := true
+	devices := []sys.LocalDisk{
+		{Name: "sdb", Type: sys.DiskType, Size: 1024, WWN: "0x5000", DevLinks: "/dev/disk/by-id/wwn-0x5000 /dev/disk/by-path/pci-0", Rotational: true,
+			CephVolumeData: `{"path":"/dev/sdb","available":false,"lvs":[{"osd_id":"2"}]}`},
+		{Name: "sdb1", Type: sys.PartType},
+		{Name: "sda", Type: sys.DiskType, Size: 2048, Empty: true},
+	}
+
+	// the inventory is created
+	assert.NoError(t, updateDeviceInventory(context, devices, map[string]DeviceHealth{"sda": {Passed: &passed}}))
+	inventory, err := rookClientset.CephV1().CephDeviceInventories(namespace).Get(nodeName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "node1", inventory.Spec.NodeName)
+	assert.Equal(t, AppName, inventory.Labels["app"])
+	assert.Equal(t, 2, len(inventory.Status.Devices))
+	sda := inventory.Status.Devices[0]
+	assert.Equal(t, "/dev/sda", sda.Path)
+	assert.True(t, sda.Empty)
+	assert.False(t, sda.InUseByOSD)
+	assert.Equal(t, "Good", sda.Health)
+	sdb := inventory.Status.Devices[1]
+	assert.Equal(t, "0x5000", sdb.WWN)
+	assert.Equal(t, []string{"/dev/disk/by-id/wwn-0x5000", "/dev/disk/by-path/pci-0"}, sdb.DevLinks)
+	assert.True(t, sdb.Rotational)
+	assert.True(t, sdb.InUseByOSD)
+	assert.Equal(t, []int{2}, sdb.OSDIDs)
+	assert.Empty(t, sdb.Health)
+
+	// the inventory is updated when the devices change
+	assert.NoError(t, updateDeviceInventory(context, devices[:2], nil))
+	inventory, err = rookClientset.CephV1().CephDeviceInventories(namespace).Get(nodeName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(inventory.Status.Devices))
+	assert.Equal(t, "sdb", inventory.Status.Devices[0].Name)
+
+	// the inventory is not updated when the devices did not change
+	actions := len(rookClientset.Actions())
+	assert.NoError(t, updateDeviceInventory(context, devices[:2], nil))
+	assert.Equal(t, actions+1, len(rookClientset.Actions()))
+}
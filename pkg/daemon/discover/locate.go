@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LocateDevicesAnnotation is the annotation of the nodes listing the devices whose enclosure LED must blink, by
+	// name, path or persistent path, separated by commas
+	LocateDevicesAnnotation = "rook.io/locate-devices"
+	// LocalDiskCMLocated is the data name of the config map storing the devices whose LED is blinking
+	LocalDiskCMLocated = "locatedDevices"
+	// LocalDiskCMLocateErrors is the data name of the config map storing the errors of the last update of the LEDs,
+	// for example when ledctl is not installed
+	LocalDiskCMLocateErrors = "locateErrors"
+)
+
+// the interval between the checks of the locate annotation of the node
+var locatePeriod = 30 * time.Second
+
+// locateDevicePath returns the path of a device of the locate annotation
+func locateDevicePath(device string) string {
+	if strings.HasPrefix(device, "/") {
+		return path.Clean(device)
+	}
+	return path.Join("/dev", device)
+}
+
+// locatedDevices returns the paths of the devices listed in the locate annotation, sorted
+func locatedDevices(annotation string) []string {
+	devices := []string{}
+	seen := map[string]bool{}
+	for _, device := range strings.Split(annotation, ",") {
+		device = strings.TrimSpace(device)
+		if device == "" {
+			continue
+		}
+		devicePath := locateDevicePath(device)
+		if !seen[devicePath] {
+			seen[devicePath] = true
+			devices = append(devices, devicePath)
+		}
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+// updateDeviceLocate turns on the enclosure LED of the devices listed in the locate annotation of the node and turns off
+// the LED of the devices removed from the annotation, with ledctl. The devices whose LED is on are stored in the
+// device config map so the LEDs are turned off after a restart of the daemon, with the errors of the update.
+func updateDeviceLocate(context *clusterd.Context) error {
+	if cm == nil {
+		// the config map is created by the first probe of the devices
+		return nil
+	}
+	node, err := context.Clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get node %q", nodeName)
+	}
+	wanted := locatedDevices(node.Annotations[LocateDevicesAnnotation])
+
+	current := []string{}
+	if data := cm.Data[LocalDiskCMLocated]; data != "" {
+		if err := json.Unmarshal([]byte(data), &current); err != nil {
+			logger.Warningf("failed to parse the located devices %q. %v", data, err)
+		}
+	}
+
+	located := []string{}
+	locateErrors := []string{}
+	wantedSet := map[string]bool{}
+	for _, device := range wanted {
+		wantedSet[device] = true
+	}
+	currentSet := map[string]bool{}
+	for _, device := range current {
+		currentSet[device] = true
+		if wantedSet[device] {
+			located = append(located, device)
+			continue
+		}
+		if err := setDeviceLocate(context, device, false); err != nil {
+			logger.Errorf("failed to turn off the locate LED of device %q. %v", device, err)
+			locateErrors = append(locateErrors, fmt.Sprintf("%s: %v", device, err))
+			// the LED is turned off at the next check
+			located = append(located, device)
+			continue
+		}
+		logger.Infof("turned off the locate LED of device %q", device)
+	}
+	for _, device := range wanted {
+		if currentSet[device] {
+			continue
+		}
+		if err := setDeviceLocate(context, device, true); err != nil {
+			logger.Errorf("failed to turn on the locate LED of device %q. %v", device, err)
+			locateErrors = append(locateErrors, fmt.Sprintf("%s: %v", device, err))
+			continue
+		}
+		logger.Infof("turned on the locate LED of device %q", device)
+		located = append(located, device)
+	}
+	sort.Strings(located)
+
+	locatedJSON, err := json.Marshal(located)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the located devices")
+	}
+	locateError := strings.Join(locateErrors, "; ")
+	locatedUnchanged := string(locatedJSON) == cm.Data[LocalDiskCMLocated] || (len(located) == 0 && cm.Data[LocalDiskCMLocated] == "")
+	if locatedUnchanged && locateError == cm.Data[LocalDiskCMLocateErrors] {
+		return nil
+	}
+	updated := cm.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = map[string]string{}
+	}
+	updated.Data[LocalDiskCMLocated] = string(locatedJSON)
+	if locateError == "" {
+		delete(updated.Data, LocalDiskCMLocateErrors)
+	} else {
+		updated.Data[LocalDiskCMLocateErrors] = locateError
+	}
+	updated, err = context.Clientset.CoreV1().ConfigMaps(namespace).Update(updated)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the located devices of configmap %q", cmName)
+	}
+	cm = updated
+	return nil
+}
+
+// setDeviceLocate turns the locate LED of the enclosure of a device on or off
+func setDeviceLocate(context *clusterd.Context, device string, on bool) error {
+	pattern := "locate_off=%s"
+	if on {
+		pattern = "locate=%s"
+	}
+	output, err := context.Executor.ExecuteCommandWithCombinedOutput("ledctl", fmt.Sprintf(pattern, device))
+	if err != nil {
+		return errors.Wrapf(err, "failed to run ledctl. %s", output)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLocatedDevices(t *testing.T) {
+	assert.Equal(t, []string{}, locatedDevices(""))
+	assert.Equal(t, []string{"/dev/disk/by-id/wwn-0x5000", "/dev/sda", "/dev/sdb"},
+		locatedDevices("sdb, /dev/sda,,/dev/disk/by-id/wwn-0x5000,sda"))
+}
+
+func TestUpdateDeviceLocate(t *testing.T) {
+	nodeName, namespace, cmName = "node1", "rook-ceph", "local-device-node1"
+	defer func() { cm = nil }()
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	cm = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cmName, Namespace: namespace},
+		Data:       map[string]string{LocalDiskCMData: "[]"},
+	}
+	clientset := fake.NewSimpleClientset(node, cm)
+	commands := []string{}
+	failing := ""
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithCombinedOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(t, "ledctl", command)
+			if args[0] == failing {
+				return "ledctl: unsupported enclosure", errors.New("exit status 1")
+			}
+			commands = append(commands, args[0])
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Clientset: clientset, Executor: executor}
+	cmData := func(key string) string {
+		current, err := clientset.CoreV1().ConfigMaps(namespace).Get(cmName, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return current.Data[key]
+	}
+	located := func() string {
+		return cmData(LocalDiskCMLocated)
+	}
+	annotate := func(devices string) {
+		node.Annotations = map[string]string{LocateDevicesAnnotation: devices}
+		_, err := clientset.CoreV1().Nodes().Update(node)
+		assert.NoError(t, err)
+	}
+
+	// no device to locate
+	assert.NoError(t, updateDeviceLocate(context))
+	assert.Empty(t, commands)
+	assert.Empty(t, located())
+
+	// the LEDs of the annotated devices are turned on
+	annotate("sda,/dev/sdb")
+	assert.NoError(t, updateDeviceLocate(context))
+	assert.Equal(t, []string{"locate=/dev/sda", "locate=/dev/sdb"}, commands)
+	assert.Equal(t, `["/dev/sda","/dev/sdb"]`, located())
+	assert.Equal(t, "[]", cm.Data[LocalDiskCMData])
+
+	// the LEDs already on are not turned on again
+	commands = []string{}
+	assert.NoError(t, updateDeviceLocate(context))
+	assert.Empty(t, commands)
+
+	// the LED of a device removed from the annotation is turned off, a failure is retried at the next check
+	annotate("sdb,sdc")
+	failing = "locate=/dev/sdc"
+	assert.NoError(t, updateDeviceLocate(context))
+	assert.Equal(t, []string{"locate_off=/dev/sda"}, commands)
+	assert.Equal(t, `["/dev/sdb"]`, located())
+	assert.Contains(t, cmData(LocalDiskCMLocateErrors), "/dev/sdc: failed to run ledctl. ledctl: unsupported enclosure")
+	failing = ""
+	commands = []string{}
+	assert.NoError(t, updateDeviceLocate(context))
+	assert.Equal(t, []string{"locate=/dev/sdc"}, commands)
+	assert.Equal(t, `["/dev/sdb","/dev/sdc"]`, located())
+	assert.Empty(t, cmData(LocalDiskCMLocateErrors))
+
+	// all the LEDs are turned off when the annotation is removed
+	node.Annotations = nil
+	_, err := clientset.CoreV1().Nodes().Update(node)
+	assert.NoError(t, err)
+	commands = []string{}
+	assert.NoError(t, updateDeviceLocate(context))
+	assert.Equal(t, []string{"locate_off=/dev/sdb", "locate_off=/dev/sdc"}, commands)
+	assert.Equal(t, `[]`, located())
+}
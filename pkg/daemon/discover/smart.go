@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/sys"
+)
+
+const (
+	// LocalDiskCMHealth is the data name of the config map storing the SMART health of the devices
+	LocalDiskCMHealth = "health"
+
+	// the ids of the ATA SMART attributes reported in the health of the devices
+	reallocatedSectorsAttribute = 5
+	pendingSectorsAttribute     = 197
+)
+
+var collectSmartData bool
+
+// DeviceHealth is the SMART health of a device collected with smartctl
+type DeviceHealth struct {
+	// Passed is whether the device passed the SMART overall health self-assessment, nil if unknown
+	Passed *bool `json:"passed,omitempty"`
+	// Temperature is the current temperature of the device in Celsius
+	Temperature *int `json:"temperature,omitempty"`
+	// PowerOnHours is the number of hours the device has been powered on
+	PowerOnHours *int `json:"powerOnHours,omitempty"`
+	// ReallocatedSectors is the raw value of the reallocated sectors count of the ATA devices
+	ReallocatedSectors *int `json:"reallocatedSectors,omitempty"`
+	// PendingSectors is the raw value of the current pending sectors count of the ATA devices
+	PendingSectors *int `json:"pendingSectors,omitempty"`
+	// MediaErrors is the number of media and data integrity errors of the NVMe devices
+	MediaErrors *int `json:"mediaErrors,omitempty"`
+	// PercentageUsed is the estimated percentage of the life of the NVMe devices used
+	PercentageUsed *int `json:"percentageUsed,omitempty"`
+	// Error is the reason the health could not be collected
+	Error string `json:"error,omitempty"`
+}
+
+// Status returns the health of the device as reported by `ceph orch device ls`: Good, Failing or Unknown
+func (h DeviceHealth) Status() string {
+	if h.Passed == nil {
+		return "Unknown"
+	}
+	if *h.Passed {
+		return "Good"
+	}
+	return "Failing"
+}
+
+// smartctlOutput is the part of the json output of `smartctl --json` reported in the health of the devices
+type smartctlOutput struct {
+	Smartctl struct {
+		Messages []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours int `json:"hours"`
+	} `json:"power_on_time"`
+	ATASmartAttributes *struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value int `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeHealth *struct {
+		MediaErrors    int `json:"media_errors"`
+		PercentageUsed int `json:"percentage_used"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// probeDevicesHealth collects the SMART health of the disks, by device name. The devices without SMART support
+// are reported with the error of smartctl.
+func probeDevicesHealth(context *clusterd.Context, devices []sys.LocalDisk) map[string]DeviceHealth {
+	health := map[string]DeviceHealth{}
+	for _, device := range devices {
+		if device.Type != sys.DiskType {
+			continue
+		}
+		h, err := getDeviceHealth(context, path.Join("/dev", device.Name))
+		if err != nil {
+			logger.Warningf("failed to get the SMART health of device %q. %v", device.Name, err)
+			h.Error = err.Error()
+		}
+		health[device.Name] = h
+	}
+	return health
+}
+
+func getDeviceHealth(context *clusterd.Context, devicePath string) (DeviceHealth, error) {
+	// smartctl exits with a non-zero status when the device is failing, the json output is parsed anyway
+	output, cmdErr := context.Executor.ExecuteCommandWithOutput("smartctl", "--json", "--health", "--attributes", "--info", devicePath)
+	var result smartctlOutput
+	// the output may be followed by the stderr of the command, only the first json value is decoded
+	if err := json.NewDecoder(strings.NewReader(output)).Decode(&result); err != nil {
+		if cmdErr != nil {
+			return DeviceHealth{}, errors.Wrap(cmdErr, "failed to run smartctl")
+		}
+		return DeviceHealth{}, errors.Wrap(err, "failed to parse the output of smartctl")
+	}
+	return newDeviceHealth(result)
+}
+
+func newDeviceHealth(result smartctlOutput) (DeviceHealth, error) {
+	h := DeviceHealth{}
+	if result.SmartStatus == nil {
+		messages := []string{}
+		for _, message := range result.Smartctl.Messages {
+			messages = append(messages, message.String)
+		}
+		if len(messages) == 0 {
+			messages = append(messages, "no SMART status reported")
+		}
+		return h, errors.New(strings.Join(messages, ". "))
+	}
+	passed := result.SmartStatus.Passed
+	h.Passed = &passed
+	if result.Temperature != nil {
+		h.Temperature = intPtr(result.Temperature.Current)
+	}
+	if result.PowerOnTime != nil {
+		h.PowerOnHours = intPtr(result.PowerOnTime.Hours)
+	}
+	if result.ATASmartAttributes != nil {
+		for _, attribute := range result.ATASmartAttributes.Table {
+			switch attribute.ID {
+			case reallocatedSectorsAttribute:
+				h.ReallocatedSectors = intPtr(attribute.Raw.Value)
+			case pendingSectorsAttribute:
+				h.PendingSectors = intPtr(attribute.Raw.Value)
+			}
+		}
+	}
+	if result.NVMeHealth != nil {
+		h.MediaErrors = intPtr(result.NVMeHealth.MediaErrors)
+		h.PercentageUsed = intPtr(result.NVMeHealth.PercentageUsed)
+	}
+	return h, nil
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/rook/rook/pkg/util/sys"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ataSmartOutput = `{"smartctl":{"version":[7,0],"exit_status":0},"device":{"name":"/dev/sda","type":"sat"},
+"smart_status":{"passed":true},"temperature":{"current":34},"power_on_time":{"hours":12034},
+"ata_smart_attributes":{"table":[{"id":5,"name":"Reallocated_Sector_Ct","raw":{"value":8}},{"id":9,"name":"Power_On_Hours","raw":{"value":12034}},
+{"id":197,"name":"Current_Pending_Sector","raw":{"value":2}}]}}`
+	nvmeSmartOutput = `{"smartctl":{"version":[7,0],"exit_status":8},"smart_status":{"passed":false},"temperature":{"current":41},
+"nvme_smart_health_information_log":{"critical_warning":4,"percentage_used":97,"media_errors":12}}`
+	noSmartOutput = `{"smartctl":{"version":[7,0],"messages":[{"string":"/dev/vda: Unable to detect device type","severity":"error"}],"exit_status":1}}`
+)
+
+func TestProbeDevicesHealth(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(t, "smartctl", command)
+			switch args[len(args)-1] {
+			case "/dev/sda":
+				return ataSmartOutput, nil
+			case "/dev/nvme0n1":
+				// smartctl fails when the device is failing, the output is followed by the stderr
+				return nvmeSmartOutput + ". ", errors.New("exit status 8")
+			case "/dev/vda":
+				return noSmartOutput, errors.New("exit status 1")
+			}
+			return "", errors.Errorf("unexpected device %q", args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	devices := []sys.LocalDisk{
+		{Name: "sda", Type: sys.DiskType},
+		{Name: "sda1", Type: sys.PartType},
+		{Name: "nvme0n1", Type: sys.DiskType},
+		{Name: "vda", Type: sys.DiskType},
+	}
+
+	health := probeDevicesHealth(context, devices)
+	assert.Equal(t, 3, len(health))
+
+	sda := health["sda"]
+	assert.Equal(t, "Good", sda.Status())
+	assert.Equal(t, 34, *sda.Temperature)
+	assert.Equal(t, 12034, *sda.PowerOnHours)
+	assert.Equal(t, 8, *sda.ReallocatedSectors)
+	assert.Equal(t, 2, *sda.PendingSectors)
+	assert.Nil(t, sda.MediaErrors)
+	assert.Empty(t, sda.Error)
+
+	nvme := health["nvme0n1"]
+	assert.Equal(t, "Failing", nvme.Status())
+	assert.Equal(t, 12, *nvme.MediaErrors)
+	assert.Equal(t, 97, *nvme.PercentageUsed)
+	assert.Nil(t, nvme.ReallocatedSectors)
+
+	vda := health["vda"]
+	assert.Equal(t, "Unknown", vda.Status())
+	assert.Equal(t, "/dev/vda: Unable to detect device type", vda.Error)
+
+	// smartctl is not installed
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return "", errors.New("executable file not found")
+	}
+	health = probeDevicesHealth(context, devices[:1])
+	assert.Equal(t, "Unknown", health["sda"].Status())
+	assert.Contains(t, health["sda"].Error, "failed to run smartctl")
+}
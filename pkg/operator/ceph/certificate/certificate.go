@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certificate issues and renews the certificates of the endpoints of the Ceph daemons
+package certificate
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", "op-certificate")
+
+const (
+	// CAKey is the key of the certificate of the CA in the certificate secrets, like in the secrets of cert-manager
+	CAKey = "ca.crt"
+	// HashAnnotation is the annotation of the pods holding the hash of their certificate. The pods are restarted
+	// with the renewed certificate when the hash changes.
+	HashAnnotation = "rook.io/certificate-hash"
+	// CheckInterval is the interval between the checks of the certificates to renew
+	CheckInterval = time.Hour
+)
+
+// ErrNotReady is returned when the certificate requested to cert-manager is not issued yet
+var ErrNotReady = errors.New("the certificate is not issued yet")
+
+// Request is the request of a certificate
+type Request struct {
+	// SecretName is the name of the secret of the certificate
+	SecretName string
+	// DNSNames are the names of the certificate, the first one is its common name
+	DNSNames []string
+	// OwnerRef is the owner of the secret of the certificate
+	OwnerRef *metav1.OwnerReference
+}
+
+// Certificate is an issued certificate with its private key, in PEM format
+type Certificate struct {
+	Cert     []byte
+	Key      []byte
+	CA       []byte
+	NotAfter time.Time
+	DNSNames []string
+}
+
+// Hash returns the hash of the certificate
+func (c *Certificate) Hash() string {
+	sum := sha256.Sum256(c.Cert)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Manager issues the certificates of the cluster with the issuer of the TLS settings of the cluster
+type Manager struct {
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	spec        cephv1.TLSSpec
+	now         func() time.Time
+}
+
+// NewManager creates a manager issuing the certificates of the cluster
+func NewManager(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec cephv1.TLSSpec) *Manager {
+	return &Manager{context: context, clusterInfo: clusterInfo, spec: spec, now: time.Now}
+}
+
+// Issue returns the certificate of the request, which is issued when it does not exist yet, when its names changed
+// or when it must be renewed. It returns ErrNotReady while cert-manager did not issue the certificate.
+func (m *Manager) Issue(req Request) (*Certificate, error) {
+	duration, err := m.spec.GetDuration()
+	if err != nil {
+		return nil, err
+	}
+	renewBefore, err := m.spec.GetRenewBefore()
+	if err != nil {
+		return nil, err
+	}
+
+	switch m.spec.Issuer {
+	case cephv1.TLSIssuerSelfSigned:
+		return m.issueSelfSigned(req, duration, renewBefore)
+	case cephv1.TLSIssuerCertManager:
+		return m.requestCertManager(req, duration, renewBefore)
+	}
+	return nil, errors.Errorf("invalid certificate issuer %q, must be %q or %q", m.spec.Issuer, cephv1.TLSIssuerSelfSigned, cephv1.TLSIssuerCertManager)
+}
+
+// issueSelfSigned issues the certificate with the CA of the operator and saves it in the secret of the request
+func (m *Manager) issueSelfSigned(req Request, duration, renewBefore time.Duration) (*Certificate, error) {
+	existing, err := m.certificateSecret(req.SecretName)
+	if err != nil && !kerrors.IsNotFound(err) && err != ErrNotReady {
+		return nil, err
+	}
+	if err == nil && reflect.DeepEqual(existing.DNSNames, sortedNames(req.DNSNames)) && m.now().Before(existing.NotAfter.Add(-renewBefore)) {
+		logger.Debugf("certificate %q is valid until %s", req.SecretName, existing.NotAfter.Format(time.RFC3339))
+		return existing, nil
+	}
+
+	ca, err := m.getOrCreateCA()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := ca.sign(req.DNSNames, m.now(), duration)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to issue certificate %q", req.SecretName)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: req.SecretName, Namespace: m.clusterInfo.Namespace},
+		Type:       v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       cert.Cert,
+			v1.TLSPrivateKeyKey: cert.Key,
+			CAKey:               cert.CA,
+		},
+	}
+	if req.OwnerRef != nil {
+		secret.OwnerReferences = []metav1.OwnerReference{*req.OwnerRef}
+	}
+	if err := m.saveSecret(secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to save certificate %q", req.SecretName)
+	}
+	logger.Infof("issued certificate %q valid until %s", req.SecretName, cert.NotAfter.Format(time.RFC3339))
+	return cert, nil
+}
+
+// certificateSecret returns the certificate of a secret in the format of the secrets of cert-manager
+func (m *Manager) certificateSecret(name string) (*Certificate, error) {
+	secret, err := m.context.Clientset.CoreV1().Secrets(m.clusterInfo.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(secret.Data[v1.TLSCertKey]) == 0 || len(secret.Data[v1.TLSPrivateKeyKey]) == 0 {
+		return nil, ErrNotReady
+	}
+	cert, err := parseCertificate(secret.Data[v1.TLSCertKey])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the certificate of secret %q", name)
+	}
+	return &Certificate{
+		Cert:     secret.Data[v1.TLSCertKey],
+		Key:      secret.Data[v1.TLSPrivateKeyKey],
+		CA:       secret.Data[CAKey],
+		NotAfter: cert.NotAfter,
+		DNSNames: sortedNames(cert.DNSNames),
+	}, nil
+}
+
+func (m *Manager) saveSecret(secret *v1.Secret) error {
+	secrets := m.context.Clientset.CoreV1().Secrets(secret.Namespace)
+	if _, err := secrets.Create(secret); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return err
+		}
+		if _, err := secrets.Update(secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no pem encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func sortedNames(names []string) []string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return sorted
+}
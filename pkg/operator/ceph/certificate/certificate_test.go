@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIssueSelfSigned(t *testing.T) {
+	clientset := testop.New(t, 1)
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	m := NewManager(&clusterd.Context{Clientset: clientset}, clusterInfo, cephv1.TLSSpec{Issuer: cephv1.TLSIssuerSelfSigned, Duration: "24h"})
+	now := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	req := Request{SecretName: "rook-ceph-rgw-store-tls", DNSNames: []string{"rook-ceph-rgw-store.ns", "rook-ceph-rgw-store.ns.svc"}}
+
+	cert, err := m.Issue(req)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(24*time.Hour), cert.NotAfter)
+
+	// the certificate is signed by the ca of the operator
+	caSecret, err := clientset.CoreV1().Secrets("ns").Get(CASecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	ca, err := parseCertificate(caSecret.Data[v1.TLSCertKey])
+	assert.NoError(t, err)
+	x509Cert, err := parseCertificate(cert.Cert)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	_, err = x509Cert.Verify(x509.VerifyOptions{DNSName: "rook-ceph-rgw-store.ns.svc", Roots: roots, CurrentTime: now})
+	assert.NoError(t, err)
+	secret, err := clientset.CoreV1().Secrets("ns").Get(req.SecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, cert.Cert, secret.Data[v1.TLSCertKey])
+	assert.Equal(t, caSecret.Data[v1.TLSCertKey], secret.Data[CAKey])
+
+	// the certificate is kept until it must be renewed after two thirds of its validity
+	now = now.Add(15 * time.Hour)
+	same, err := m.Issue(req)
+	assert.NoError(t, err)
+	assert.Equal(t, cert.Hash(), same.Hash())
+	now = now.Add(2 * time.Hour)
+	renewed, err := m.Issue(req)
+	assert.NoError(t, err)
+	assert.NotEqual(t, cert.Hash(), renewed.Hash())
+
+	// the certificate is issued again when its names change
+	req.DNSNames = append(req.DNSNames, "rook-ceph-rgw-store.ns.svc.cluster.local")
+	renamed, err := m.Issue(req)
+	assert.NoError(t, err)
+	assert.NotEqual(t, renewed.Hash(), renamed.Hash())
+	assert.Equal(t, 3, len(renamed.DNSNames))
+}
+
+func TestTLSSpecDurations(t *testing.T) {
+	spec := cephv1.TLSSpec{}
+	duration, err := spec.GetDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.DefaultCertificateDuration, duration)
+	renewBefore, err := spec.GetRenewBefore()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, renewBefore)
+
+	spec.RenewBefore = "2500h"
+	_, err = spec.GetRenewBefore()
+	assert.Error(t, err)
+	spec.Duration = "-1h"
+	_, err = spec.GetDuration()
+	assert.Error(t, err)
+
+	m := NewManager(&clusterd.Context{}, cephclient.AdminClusterInfo("ns"), cephv1.TLSSpec{Issuer: "vault"})
+	_, err = m.Issue(Request{SecretName: "cert", DNSNames: []string{"name"}})
+	assert.Error(t, err)
+}
+
+func TestRequestCertManager(t *testing.T) {
+	clientset := testop.New(t, 1)
+	cl := fake.NewFakeClientWithScheme(runtime.NewScheme())
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	spec := cephv1.TLSSpec{Issuer: cephv1.TLSIssuerCertManager}
+	m := NewManager(&clusterd.Context{Clientset: clientset, Client: cl}, clusterInfo, spec)
+	req := Request{SecretName: "rook-ceph-dashboard-tls", DNSNames: []string{"rook-ceph-mgr-dashboard.ns.svc"}}
+
+	// the issuer is required
+	_, err := m.Issue(req)
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrNotReady, err)
+
+	m.spec.CertManagerIssuerRef = &cephv1.CertManagerIssuerRef{Name: "ca-issuer", Kind: "ClusterIssuer"}
+	_, err = m.Issue(req)
+	assert.Equal(t, ErrNotReady, err)
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: req.SecretName, Namespace: "ns"}, certificate))
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	assert.Equal(t, req.SecretName, secretName)
+	kind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind")
+	assert.Equal(t, "ClusterIssuer", kind)
+	renewBefore, _, _ := unstructured.NestedString(certificate.Object, "spec", "renewBefore")
+	assert.Equal(t, "720h0m0s", renewBefore)
+
+	// the certificate is returned once cert-manager saved it in the secret
+	ca, caKey, err := newAuthority(time.Now())
+	assert.NoError(t, err)
+	assert.NotNil(t, caKey)
+	issued, err := ca.sign(req.DNSNames, time.Now(), time.Hour)
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Secrets("ns").Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: req.SecretName, Namespace: "ns"},
+		Data:       map[string][]byte{v1.TLSCertKey: issued.Cert, v1.TLSPrivateKeyKey: issued.Key, CAKey: issued.CA},
+	})
+	assert.NoError(t, err)
+	cert, err := m.Issue(req)
+	assert.NoError(t, err)
+	assert.Equal(t, issued.Hash(), cert.Hash())
+	assert.Equal(t, issued.CA, cert.CA)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	certManagerGroup   = "cert-manager.io"
+	defaultIssuerKind  = "Issuer"
+	clusterIssuerKind  = "ClusterIssuer"
+	certificateVersion = "v1"
+)
+
+// certificateGVK is the kind of the certificates of cert-manager, which is not a dependency of the operator
+var certificateGVK = schema.GroupVersionKind{Group: certManagerGroup, Version: certificateVersion, Kind: "Certificate"}
+
+// requestCertManager creates or updates the cert-manager certificate of the request and returns the certificate once
+// cert-manager issued it in the secret of the request. cert-manager renews the certificate in the same secret.
+func (m *Manager) requestCertManager(req Request, duration, renewBefore time.Duration) (*Certificate, error) {
+	issuer := m.spec.CertManagerIssuerRef
+	if issuer == nil || issuer.Name == "" {
+		return nil, errors.New("missing the cert-manager issuer of the certificates")
+	}
+	kind := issuer.Kind
+	if kind == "" {
+		kind = defaultIssuerKind
+	}
+	if kind != defaultIssuerKind && kind != clusterIssuerKind {
+		return nil, errors.Errorf("invalid cert-manager issuer kind %q, must be %q or %q", kind, defaultIssuerKind, clusterIssuerKind)
+	}
+
+	dnsNames := []interface{}{}
+	for _, name := range req.DNSNames {
+		dnsNames = append(dnsNames, name)
+	}
+	spec := map[string]interface{}{
+		"secretName":  req.SecretName,
+		"commonName":  req.DNSNames[0],
+		"dnsNames":    dnsNames,
+		"duration":    duration.String(),
+		"renewBefore": renewBefore.String(),
+		"usages":      []interface{}{"server auth", "digital signature", "key encipherment"},
+		"issuerRef": map[string]interface{}{
+			"name":  issuer.Name,
+			"kind":  kind,
+			"group": certManagerGroup,
+		},
+	}
+	if err := m.applyCertManagerCertificate(req, spec); err != nil {
+		return nil, errors.Wrapf(err, "failed to request certificate %q to cert-manager", req.SecretName)
+	}
+
+	cert, err := m.certificateSecret(req.SecretName)
+	if err != nil {
+		if kerrors.IsNotFound(err) || err == ErrNotReady {
+			logger.Infof("waiting for cert-manager to issue certificate %q", req.SecretName)
+			return nil, ErrNotReady
+		}
+		return nil, err
+	}
+	if !reflect.DeepEqual(cert.DNSNames, sortedNames(req.DNSNames)) {
+		logger.Infof("waiting for cert-manager to issue certificate %q with the new names", req.SecretName)
+		return nil, ErrNotReady
+	}
+	return cert, nil
+}
+
+// applyCertManagerCertificate creates the certificate of the request or updates its spec if it changed
+func (m *Manager) applyCertManagerCertificate(req Request, spec map[string]interface{}) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(certificateGVK)
+	err := m.context.Client.Get(context.TODO(), types.NamespacedName{Name: req.SecretName, Namespace: m.clusterInfo.Namespace}, existing)
+	if err == nil {
+		// only the settings of the request are compared, the other settings are defaulted by cert-manager
+		existingSpec, _ := existing.Object["spec"].(map[string]interface{})
+		if existingSpec == nil {
+			existingSpec = map[string]interface{}{}
+		}
+		changed := false
+		for key, value := range spec {
+			if !reflect.DeepEqual(existingSpec[key], value) {
+				existingSpec[key] = value
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		existing.Object["spec"] = existingSpec
+		logger.Infof("updating cert-manager certificate %q", req.SecretName)
+		return m.context.Client.Update(context.TODO(), existing)
+	}
+	if !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(req.SecretName)
+	certificate.SetNamespace(m.clusterInfo.Namespace)
+	if req.OwnerRef != nil {
+		certificate.SetOwnerReferences([]metav1.OwnerReference{*req.OwnerRef})
+	}
+	logger.Infof("creating cert-manager certificate %q", req.SecretName)
+	return m.context.Client.Create(context.TODO(), certificate)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CASecretName is the secret of the CA of the operator issuing the self-signed certificates
+	CASecretName = "rook-ceph-ca"
+	caCommonName = "rook-ceph-ca"
+	caDuration   = 10 * 365 * 24 * time.Hour
+	// the certificates are valid a bit before they are issued in case the clocks of the clients are late
+	clockSkew = 5 * time.Minute
+)
+
+// authority is the CA issuing the self-signed certificates
+type authority struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     crypto.Signer
+}
+
+// getOrCreateCA returns the CA of the operator, which is created with the secret owned by the cluster the first time
+func (m *Manager) getOrCreateCA() (*authority, error) {
+	secret, err := m.context.Clientset.CoreV1().Secrets(m.clusterInfo.Namespace).Get(CASecretName, metav1.GetOptions{})
+	if err == nil {
+		ca, err := parseAuthority(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the ca of secret %q", CASecretName)
+		}
+		return ca, nil
+	}
+	if !kerrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get ca secret %q", CASecretName)
+	}
+
+	ca, keyPEM, err := newAuthority(m.now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the ca")
+	}
+	secret = &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            CASecretName,
+			Namespace:       m.clusterInfo.Namespace,
+			OwnerReferences: []metav1.OwnerReference{m.clusterInfo.OwnerRef},
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{v1.TLSCertKey: ca.certPEM, v1.TLSPrivateKeyKey: keyPEM},
+	}
+	if _, err := m.context.Clientset.CoreV1().Secrets(m.clusterInfo.Namespace).Create(secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to create ca secret %q", CASecretName)
+	}
+	logger.Infof("created the ca of the certificates in secret %q", CASecretName)
+	return ca, nil
+}
+
+// newAuthority creates a CA and returns it with its private key in PEM format
+func newAuthority(now time.Time) (*authority, []byte, error) {
+	key, keyPEM, err := newPrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: caCommonName},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(caDuration),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create the ca certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &authority{cert: cert, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key: key}, keyPEM, nil
+}
+
+func parseAuthority(certPEM, keyPEM []byte) (*authority, error) {
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no pem encoded private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the private key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("the private key cannot sign certificates")
+	}
+	return &authority{cert: cert, certPEM: certPEM, key: signer}, nil
+}
+
+// sign issues a server certificate for the given names
+func (ca *authority) sign(dnsNames []string, now time.Time, duration time.Duration) (*Certificate, error) {
+	key, keyPEM, err := newPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(duration),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{
+		Cert:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:      keyPEM,
+		CA:       ca.certPEM,
+		NotAfter: template.NotAfter,
+		DNSNames: sortedNames(dnsNames),
+	}, nil
+}
+
+func newPrivateKey() (crypto.Signer, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate private key")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to encode private key")
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial number")
+	}
+	return serial, nil
+}
@@ -19,24 +19,33 @@ package cluster
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	// defaultStatusCheckInterval is the interval to check the status of the ceph cluster
 	defaultStatusCheckInterval = 60 * time.Second
+	// healthHistorySize is the number of transitions of the health kept in the status
+	healthHistorySize = 10
 )
 
 // cephStatusChecker aggregates the mon/cluster info needed to check the health of the monitors
@@ -46,6 +55,7 @@ type cephStatusChecker struct {
 	interval    time.Duration
 	client      client.Client
 	isExternal  bool
+	recorder    record.EventRecorder
 }
 
 // newCephStatusChecker creates a new HealthChecker object
@@ -111,21 +121,68 @@ func (c *cephStatusChecker) checkStatus() {
 	if err != nil {
 		logger.Errorf("failed to get ceph status. %v", err)
 		condition, reason, message := c.conditionMessageReason(cephv1.ConditionFailure)
-		if err := c.updateCephStatus(cephStatusOnError(err.Error()), condition, reason, message); err != nil {
+		if err := c.updateCephStatus(cephStatusOnError(err.Error()), nil, nil, nil, condition, reason, message); err != nil {
 			logger.Errorf("failed to query cluster status in namespace %q. %v", c.clusterInfo.Namespace, err)
 		}
 		return
 	}
 
 	logger.Debugf("cluster status: %+v", status)
+
+	// the capacity of the pools and device classes is not refreshed if it cannot be retrieved
+	poolStats, err := cephclient.GetPoolStats(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to get the usage of the pools. %v", err)
+	}
+	balancer := c.checkBalancer()
+	// the operations in progress are not refreshed if they cannot be retrieved
+	progress, err := cephclient.GetProgressEvents(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to get the operations in progress. %v", err)
+	}
 	condition, reason, message := c.conditionMessageReason(cephv1.ConditionReady)
-	if err := c.updateCephStatus(&status, condition, reason, message); err != nil {
+	if err := c.updateCephStatus(&status, poolStats, balancer, progress, condition, reason, message); err != nil {
 		logger.Errorf("failed to query cluster status in namespace %q. %v", c.clusterInfo.Namespace, err)
 	}
 }
 
+// checkBalancer returns the status of the balancer module, after applying the balancer settings again when the
+// balancer was turned on or off or its mode was changed by hand. The settings are read from the CephCluster at each
+// check, the checker keeps running when they are changed.
+func (c *cephStatusChecker) checkBalancer() *cephv1.BalancerStatus {
+	if c.isExternal {
+		return nil
+	}
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.client.Get(context.TODO(), c.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		logger.Warningf("failed to get the balancer settings of cluster %q. %v", c.clusterInfo.NamespacedName().Name, err)
+		return nil
+	}
+	balancer := cephCluster.Spec.Balancer
+	status, err := cephclient.GetBalancerStatus(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to get the balancer status. %v", err)
+		return nil
+	}
+	if mgr.BalancerDrifted(balancer, status) {
+		logger.Infof("balancer active %t in mode %q differs from the balancer settings, configuring the balancer", status.Active, status.Mode)
+		if err := mgr.ConfigureBalancer(c.context, c.clusterInfo, balancer); err != nil {
+			logger.Warningf("failed to configure the balancer. %v", err)
+		} else if status, err = cephclient.GetBalancerStatus(c.context, c.clusterInfo); err != nil {
+			logger.Warningf("failed to get the balancer status. %v", err)
+			return nil
+		}
+	}
+	return &cephv1.BalancerStatus{
+		Active:              status.Active,
+		Mode:                status.Mode,
+		LastOptimizeStarted: status.LastOptimizeStarted,
+		OptimizeResult:      status.OptimizeResult,
+	}
+}
+
 // updateStatus updates an object with a given status
-func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, condition cephv1.ConditionType, reason, message string) error {
+func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, poolStats *cephclient.CephStoragePoolStats, balancer *cephv1.BalancerStatus, progress *cephclient.ProgressEvents, condition cephv1.ConditionType, reason, message string) error {
 	clusterName := c.clusterInfo.NamespacedName()
 	cephCluster := &cephv1.CephCluster{}
 	err := c.client.Get(context.TODO(), clusterName, cephCluster)
@@ -138,12 +195,42 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 	}
 
 	// Update with Ceph Status
+	previousHealth := ""
+	var previousProgress []cephv1.ProgressEventStatus
+	if cephCluster.Status.CephStatus != nil {
+		previousHealth = cephCluster.Status.CephStatus.Health
+		previousProgress = cephCluster.Status.CephStatus.Progress
+	}
 	cephCluster.Status.CephStatus = toCustomResourceStatus(cephCluster.Status, status)
+	if poolStats != nil {
+		setCapacityBreakdown(&cephCluster.Status.CephStatus.Capacity, poolStats)
+	}
+	if balancer != nil {
+		cephCluster.Status.CephStatus.Balancer = balancer
+	}
+	if progress != nil {
+		cephCluster.Status.CephStatus.Progress = opcontroller.ProgressStatus(progress)
+	}
 	cephCluster.Status.Phase = condition
+	consumers, err := adminKeyConsumers(c.context, c.clusterInfo.Namespace)
+	if err != nil {
+		logger.Warningf("failed to find the consumers of the admin key. %v", err)
+	} else {
+		cephCluster.Status.AdminKeyConsumers = consumers
+	}
+	resources, err := resourcesStatus(c.client, c.clusterInfo.Namespace)
+	if err != nil {
+		logger.Warningf("failed to summarize the phases of the resources of the cluster. %v", err)
+	} else {
+		cephCluster.Status.Resources = resources
+	}
 	if err := opcontroller.UpdateStatus(c.client, cephCluster); err != nil {
 		return errors.Wrapf(err, "failed to update cluster %q status", clusterName.Namespace)
 	}
 
+	c.reportHealthChange(cephCluster, previousHealth)
+	opcontroller.ReportProgress(c.recorder, cephCluster, previousProgress, progress)
+
 	// Update condition
 	config.ConditionExport(c.context, c.clusterInfo.NamespacedName(), condition, v1.ConditionTrue, reason, message)
 
@@ -151,6 +238,30 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 	return nil
 }
 
+// reportHealthChange emits an event on the CephCluster when the health of ceph degrades or recovers
+func (c *cephStatusChecker) reportHealthChange(cephCluster *cephv1.CephCluster, previousHealth string) {
+	if c.recorder == nil || cephCluster.Status.CephStatus == nil {
+		return
+	}
+	health := cephCluster.Status.CephStatus.Health
+	if health == previousHealth {
+		return
+	}
+	if health == "HEALTH_OK" {
+		if previousHealth != "" {
+			c.recorder.Eventf(cephCluster, v1.EventTypeNormal, opcontroller.EventReasonHealthRecovered, "ceph health is back to %s from %s", health, previousHealth)
+		}
+		return
+	}
+
+	checks := make([]string, 0, len(cephCluster.Status.CephStatus.Details))
+	for name := range cephCluster.Status.CephStatus.Details {
+		checks = append(checks, name)
+	}
+	sort.Strings(checks)
+	c.recorder.Eventf(cephCluster, v1.EventTypeWarning, opcontroller.EventReasonHealthDegraded, "ceph health is %s. checks: %v", health, checks)
+}
+
 // toCustomResourceStatus converts the ceph status to the struct expected for the CephCluster CR status
 func toCustomResourceStatus(currentStatus cephv1.ClusterStatus, newStatus *cephclient.CephStatus) *cephv1.CephStatus {
 	s := &cephv1.CephStatus{
@@ -164,32 +275,153 @@ func toCustomResourceStatus(currentStatus cephv1.ClusterStatus, newStatus *cephc
 			Message:  message.Summary.Message,
 		}
 	}
+	if newStatus.PgMap.TotalBytes != 0 {
+		s.Capacity = cephv1.Capacity{
+			BytesTotal:     newStatus.PgMap.TotalBytes,
+			BytesUsed:      newStatus.PgMap.UsedBytes,
+			BytesAvailable: newStatus.PgMap.AvailableBytes,
+			Usage:          usagePercent(newStatus.PgMap.UsedBytes, newStatus.PgMap.TotalBytes),
+			LastUpdated:    s.LastChecked,
+		}
+	}
+	previousHealth := ""
 	if currentStatus.CephStatus != nil {
+		if newStatus.PgMap.TotalBytes == 0 {
+			// keep the last known capacity when the status of ceph could not be retrieved
+			s.Capacity = currentStatus.CephStatus.Capacity
+		} else {
+			// the breakdown is refreshed separately, keep the last known until then
+			s.Capacity.DeviceClasses = currentStatus.CephStatus.Capacity.DeviceClasses
+			s.Capacity.Pools = currentStatus.CephStatus.Capacity.Pools
+		}
 		s.PreviousHealth = currentStatus.CephStatus.PreviousHealth
 		s.LastChanged = currentStatus.CephStatus.LastChanged
+		s.History = currentStatus.CephStatus.History
+		// the balancer status is refreshed separately, keep the last known until then
+		s.Balancer = currentStatus.CephStatus.Balancer
+		s.Progress = currentStatus.CephStatus.Progress
+		previousHealth = currentStatus.CephStatus.Health
 		if currentStatus.CephStatus.Health != s.Health {
 			s.PreviousHealth = currentStatus.CephStatus.Health
 			s.LastChanged = s.LastChecked
 		}
 	}
+	if previousHealth != s.Health {
+		s.History = addHealthTransition(s.History, cephv1.CephHealthTransition{
+			Time:           s.LastChecked,
+			Health:         s.Health,
+			PreviousHealth: previousHealth,
+			Checks:         healthCheckNames(s.Details),
+		})
+	}
 	return s
 }
 
+// addHealthTransition appends a transition of the health to the history, only the last transitions are kept
+func addHealthTransition(history []cephv1.CephHealthTransition, transition cephv1.CephHealthTransition) []cephv1.CephHealthTransition {
+	history = append(append([]cephv1.CephHealthTransition{}, history...), transition)
+	if len(history) > healthHistorySize {
+		history = history[len(history)-healthHistorySize:]
+	}
+	return history
+}
+
+func healthCheckNames(details map[string]cephv1.CephHealthMessage) []string {
+	names := []string{}
+	for name := range details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setCapacityBreakdown sets the capacity of each device class and each pool from the output of "ceph df detail"
+func setCapacityBreakdown(capacity *cephv1.Capacity, poolStats *cephclient.CephStoragePoolStats) {
+	capacity.DeviceClasses = []cephv1.DeviceClassCapacity{}
+	for name, stats := range poolStats.StatsByClass {
+		capacity.DeviceClasses = append(capacity.DeviceClasses, cephv1.DeviceClassCapacity{
+			Name:           name,
+			BytesTotal:     stats.TotalBytes,
+			BytesUsed:      stats.TotalUsedRawBytes,
+			BytesAvailable: stats.TotalAvailBytes,
+			Usage:          usagePercent(stats.TotalUsedRawBytes, stats.TotalBytes),
+		})
+	}
+	sort.Slice(capacity.DeviceClasses, func(i, j int) bool { return capacity.DeviceClasses[i].Name < capacity.DeviceClasses[j].Name })
+
+	capacity.Pools = []cephv1.PoolCapacity{}
+	for _, pool := range poolStats.Pools {
+		capacity.Pools = append(capacity.Pools, cephv1.PoolCapacity{
+			Name:           pool.Name,
+			BytesUsed:      uint64(pool.Stats.BytesUsed),
+			BytesAvailable: uint64(pool.Stats.MaxAvail),
+			Objects:        uint64(pool.Stats.Objects),
+		})
+	}
+	sort.Slice(capacity.Pools, func(i, j int) bool { return capacity.Pools[i].Name < capacity.Pools[j].Name })
+}
+
+func usagePercent(used, total uint64) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", float64(used)*100/float64(total))
+}
+
+// adminKeyConsumers returns the pods of the namespace mounting the admin keyring or the admin key of the mon secret
+func adminKeyConsumers(context *clusterd.Context, namespace string) ([]string, error) {
+	pods, err := context.Clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	consumers := []string{}
+	for _, pod := range pods.Items {
+		if podUsesAdminKey(pod.Spec) {
+			consumers = append(consumers, pod.Name)
+		}
+	}
+	sort.Strings(consumers)
+	return consumers, nil
+}
+
+func podUsesAdminKey(spec v1.PodSpec) bool {
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil && (volume.Secret.SecretName == keyring.AdminKeyringSecretName() || volume.Secret.SecretName == mon.AppName) {
+			return true
+		}
+	}
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil &&
+				env.ValueFrom.SecretKeyRef.Name == mon.AppName && env.ValueFrom.SecretKeyRef.Key == mon.AdminSecretNameKey {
+				return true
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == mon.AppName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func formatTime(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
 
-func (c *ClusterController) updateClusterCephVersion(image string, cephVersion cephver.CephVersion) {
-	logger.Infof("cluster %q: version %q detected for image %q", c.namespacedName.Namespace, cephVersion.String(), image)
+func (c *ClusterController) updateClusterCephVersion(cluster *cluster, image string, cephVersion cephver.CephVersion, isUpgrade bool) {
+	logger.Infof("cluster %q: version %q detected for image %q", cluster.Namespace, cephVersion.String(), image)
 
 	cephCluster := &cephv1.CephCluster{}
-	err := c.client.Get(context.TODO(), c.namespacedName, cephCluster)
+	err := c.client.Get(context.TODO(), cluster.namespacedName(), cephCluster)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
 			return
 		}
-		logger.Errorf("failed to retrieve ceph cluster %q to update ceph version to %+v. %v", c.namespacedName.Name, cephVersion, err)
+		logger.Errorf("failed to retrieve ceph cluster %q to update ceph version to %+v. %v", cluster.crdName, cephVersion, err)
 		return
 	}
 
@@ -197,11 +429,19 @@ func (c *ClusterController) updateClusterCephVersion(image string, cephVersion c
 		Image:   image,
 		Version: opcontroller.GetCephVersionLabel(cephVersion),
 	}
+	// the upgrade starts from the image of the cluster before it is replaced
+	if isUpgrade {
+		previousImage := ""
+		if cephCluster.Status.CephVersion != nil {
+			previousImage = cephCluster.Status.CephVersion.Image
+		}
+		cephCluster.Status.Upgrade = newUpgradeStatus(cephCluster.Status.Upgrade, previousImage, image)
+	}
 	// update the Ceph version on the retrieved cluster object
 	// do not overwrite the ceph status that is updated in a separate goroutine
 	cephCluster.Status.CephVersion = cephClusterVersion
 	if err := opcontroller.UpdateStatus(c.client, cephCluster); err != nil {
-		logger.Errorf("failed to update cluster %q version. %v", c.namespacedName.Name, err)
+		logger.Errorf("failed to update cluster %q version. %v", cluster.crdName, err)
 		return
 	}
 }
@@ -18,6 +18,9 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -26,8 +29,16 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	controllerclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestCephStatus(t *testing.T) {
@@ -109,9 +120,9 @@ func TestNewCephStatusChecker(t *testing.T) {
 		args args
 		want *cephStatusChecker
 	}{
-		{"default-interval", args{c, clusterInfo, &cephv1.ClusterSpec{}}, &cephStatusChecker{c, clusterInfo, defaultStatusCheckInterval, c.Client, false}},
-		{"10s-interval", args{c, clusterInfo, &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: "10s"}}}}}, &cephStatusChecker{c, clusterInfo, time10s, c.Client, false}},
-		{"10s-interval-external", args{c, clusterInfo, &cephv1.ClusterSpec{External: cephv1.ExternalSpec{Enable: true}, HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: "10s"}}}}}, &cephStatusChecker{c, clusterInfo, time10s, c.Client, true}},
+		{"default-interval", args{c, clusterInfo, &cephv1.ClusterSpec{}}, &cephStatusChecker{c, clusterInfo, defaultStatusCheckInterval, c.Client, false, nil, cephv1.BalancerSpec{}}},
+		{"10s-interval", args{c, clusterInfo, &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: "10s"}}}}}, &cephStatusChecker{c, clusterInfo, time10s, c.Client, false, nil, cephv1.BalancerSpec{}}},
+		{"10s-interval-external", args{c, clusterInfo, &cephv1.ClusterSpec{External: cephv1.ExternalSpec{Enable: true}, HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Status: cephv1.HealthCheckSpec{Interval: "10s"}}}}}, &cephStatusChecker{c, clusterInfo, time10s, c.Client, true, nil, cephv1.BalancerSpec{}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -170,3 +181,180 @@ func Test_cephStatusChecker_conditionMessageReason(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminKeyConsumers(t *testing.T) {
+	clientset := testop.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	newPod := func(name string, spec v1.PodSpec) {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}, Spec: spec}
+		_, err := clientset.CoreV1().Pods("ns").Create(pod)
+		assert.NoError(t, err)
+	}
+	newPod("rook-ceph-mgr-a", v1.PodSpec{Volumes: []v1.Volume{
+		{Name: "keyring", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "rook-ceph-admin-keyring"}}},
+	}})
+	newPod("rook-ceph-tools", v1.PodSpec{Containers: []v1.Container{{Env: []v1.EnvVar{{
+		Name: "ROOK_ADMIN_SECRET",
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "rook-ceph-mon"},
+			Key:                  "admin-secret",
+		}},
+	}}}}})
+	newPod("rook-ceph-mon-a", v1.PodSpec{Volumes: []v1.Volume{
+		{Name: "keyring", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "rook-ceph-mons-keyring"}}},
+	}, Containers: []v1.Container{{Env: []v1.EnvVar{{
+		Name: "ROOK_FSID",
+		ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "rook-ceph-mon"},
+			Key:                  "fsid",
+		}},
+	}}}}})
+
+	consumers, err := adminKeyConsumers(context, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rook-ceph-mgr-a", "rook-ceph-tools"}, consumers)
+}
+
+func TestCephStatusCapacity(t *testing.T) {
+	newStatus := &cephclient.CephStatus{Health: cephclient.HealthStatus{Status: "HEALTH_OK"}}
+	newStatus.PgMap.TotalBytes = 1000
+	newStatus.PgMap.UsedBytes = 125
+	newStatus.PgMap.AvailableBytes = 875
+
+	aggregateStatus := toCustomResourceStatus(cephv1.ClusterStatus{}, newStatus)
+	assert.Equal(t, uint64(1000), aggregateStatus.Capacity.BytesTotal)
+	assert.Equal(t, uint64(125), aggregateStatus.Capacity.BytesUsed)
+	assert.Equal(t, uint64(875), aggregateStatus.Capacity.BytesAvailable)
+	assert.Equal(t, "12.5%", aggregateStatus.Capacity.Usage)
+	assert.Equal(t, aggregateStatus.LastChecked, aggregateStatus.Capacity.LastUpdated)
+
+	// the last known capacity is kept when the status could not be retrieved
+	currentStatus := cephv1.ClusterStatus{CephStatus: aggregateStatus}
+	aggregateStatus = toCustomResourceStatus(currentStatus, cephStatusOnError("failed"))
+	assert.Equal(t, "HEALTH_ERR", aggregateStatus.Health)
+	assert.Equal(t, "12.5%", aggregateStatus.Capacity.Usage)
+}
+
+func TestReportHealthChange(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	c := &cephStatusChecker{recorder: recorder}
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "rook-ceph"}}
+
+	// no event for the first healthy status or an unchanged health
+	cephCluster.Status.CephStatus = &cephv1.CephStatus{Health: "HEALTH_OK"}
+	c.reportHealthChange(cephCluster, "")
+	c.reportHealthChange(cephCluster, "HEALTH_OK")
+	assert.Equal(t, 0, len(recorder.Events))
+
+	cephCluster.Status.CephStatus = &cephv1.CephStatus{Health: "HEALTH_WARN", Details: map[string]cephv1.CephHealthMessage{
+		"OSD_DOWN":     {Severity: "HEALTH_WARN"},
+		"MON_DISK_LOW": {Severity: "HEALTH_WARN"},
+	}}
+	c.reportHealthChange(cephCluster, "HEALTH_OK")
+	assert.Equal(t, "Warning HealthDegraded ceph health is HEALTH_WARN. checks: [MON_DISK_LOW OSD_DOWN]", <-recorder.Events)
+
+	cephCluster.Status.CephStatus = &cephv1.CephStatus{Health: "HEALTH_OK"}
+	c.reportHealthChange(cephCluster, "HEALTH_WARN")
+	assert.Equal(t, "Normal HealthRecovered ceph health is back to HEALTH_OK from HEALTH_WARN", <-recorder.Events)
+}
+
+func TestCephStatusHistory(t *testing.T) {
+	newStatus := &cephclient.CephStatus{Health: cephclient.HealthStatus{Status: "HEALTH_OK"}}
+
+	// the first health is recorded
+	aggregateStatus := toCustomResourceStatus(cephv1.ClusterStatus{}, newStatus)
+	assert.Equal(t, 1, len(aggregateStatus.History))
+	assert.Equal(t, "HEALTH_OK", aggregateStatus.History[0].Health)
+	assert.Equal(t, "", aggregateStatus.History[0].PreviousHealth)
+
+	// an unchanged health is not recorded
+	currentStatus := cephv1.ClusterStatus{CephStatus: aggregateStatus}
+	aggregateStatus = toCustomResourceStatus(currentStatus, newStatus)
+	assert.Equal(t, 1, len(aggregateStatus.History))
+
+	// the transition has the health checks
+	newStatus.Health.Status = "HEALTH_WARN"
+	newStatus.Health.Checks = map[string]cephclient.CheckMessage{"OSD_DOWN": {Severity: "HEALTH_WARN"}, "MON_DISK_LOW": {Severity: "HEALTH_WARN"}}
+	currentStatus = cephv1.ClusterStatus{CephStatus: aggregateStatus}
+	aggregateStatus = toCustomResourceStatus(currentStatus, newStatus)
+	assert.Equal(t, 2, len(aggregateStatus.History))
+	assert.Equal(t, cephv1.CephHealthTransition{Time: aggregateStatus.LastChecked, Health: "HEALTH_WARN", PreviousHealth: "HEALTH_OK", Checks: []string{"MON_DISK_LOW", "OSD_DOWN"}},
+		aggregateStatus.History[1])
+
+	// only the last transitions are kept
+	for i := 0; i < healthHistorySize; i++ {
+		newStatus.Health.Status = []string{"HEALTH_OK", "HEALTH_ERR"}[i%2]
+		currentStatus = cephv1.ClusterStatus{CephStatus: aggregateStatus}
+		aggregateStatus = toCustomResourceStatus(currentStatus, newStatus)
+	}
+	assert.Equal(t, healthHistorySize, len(aggregateStatus.History))
+	assert.Equal(t, "HEALTH_WARN", aggregateStatus.History[0].PreviousHealth)
+	assert.Equal(t, "HEALTH_ERR", aggregateStatus.History[healthHistorySize-1].Health)
+}
+
+func TestCapacityBreakdown(t *testing.T) {
+	poolStats := &cephclient.CephStoragePoolStats{}
+	err := json.Unmarshal([]byte(`{"stats_by_class":{"ssd":{"total_bytes":2000,"total_avail_bytes":1500,"total_used_raw_bytes":500},
+		"hdd":{"total_bytes":1000,"total_avail_bytes":1000,"total_used_raw_bytes":0}},
+		"pools":[{"name":"replicapool","id":1,"stats":{"bytes_used":300,"max_avail":700,"objects":12}}]}`), poolStats)
+	assert.NoError(t, err)
+
+	capacity := cephv1.Capacity{}
+	setCapacityBreakdown(&capacity, poolStats)
+	assert.Equal(t, []cephv1.DeviceClassCapacity{
+		{Name: "hdd", BytesTotal: 1000, BytesUsed: 0, BytesAvailable: 1000, Usage: "0.0%"},
+		{Name: "ssd", BytesTotal: 2000, BytesUsed: 500, BytesAvailable: 1500, Usage: "25.0%"},
+	}, capacity.DeviceClasses)
+	assert.Equal(t, []cephv1.PoolCapacity{{Name: "replicapool", BytesUsed: 300, BytesAvailable: 700, Objects: 12}}, capacity.Pools)
+
+	// the breakdown is kept until it is refreshed
+	newStatus := &cephclient.CephStatus{Health: cephclient.HealthStatus{Status: "HEALTH_OK"}}
+	newStatus.PgMap.TotalBytes = 3000
+	aggregateStatus := toCustomResourceStatus(cephv1.ClusterStatus{CephStatus: &cephv1.CephStatus{Capacity: capacity}}, newStatus)
+	assert.Equal(t, capacity.Pools, aggregateStatus.Capacity.Pools)
+	assert.Equal(t, capacity.DeviceClasses, aggregateStatus.Capacity.DeviceClasses)
+}
+
+func TestProgressStatus(t *testing.T) {
+	// the operations in progress are kept until they are refreshed
+	progress := []cephv1.ProgressEventStatus{{ID: "a", Message: "Rebalancing after osd.1 marked out", Progress: "10%"}}
+	newStatus := &cephclient.CephStatus{Health: cephclient.HealthStatus{Status: "HEALTH_OK"}}
+	aggregateStatus := toCustomResourceStatus(cephv1.ClusterStatus{CephStatus: &cephv1.CephStatus{Progress: progress}}, newStatus)
+	assert.Equal(t, progress, aggregateStatus.Progress)
+}
+
+func TestCheckBalancer(t *testing.T) {
+	active := false
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "balancer" && args[1] == "status" {
+				return fmt.Sprintf(`{"active":%t,"mode":"upmap","last_optimize_started":"Wed Oct 14 10:00:00 2020","optimize_result":"Optimization plan created successfully"}`, active), nil
+			}
+			if args[0] == "balancer" && args[1] == "on" {
+				active = true
+			}
+			return "", nil
+		},
+	}
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "ns"}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{})
+	cl := fake.NewFakeClientWithScheme(s, []runtime.Object{cephCluster}...)
+	clusterInfo := client.AdminClusterInfo("ns")
+	clusterInfo.SetName("rook-ceph")
+	c := &cephStatusChecker{context: &clusterd.Context{Executor: executor}, clusterInfo: clusterInfo, client: cl}
+
+	// the balancer turned off by hand is kept off without settings
+	status := c.checkBalancer()
+	assert.Equal(t, &cephv1.BalancerStatus{Mode: "upmap", LastOptimizeStarted: "Wed Oct 14 10:00:00 2020", OptimizeResult: "Optimization plan created successfully"}, status)
+
+	// the balancer is turned on again with the settings added to the CephCluster after the checker started
+	cephCluster.Spec.Balancer = cephv1.BalancerSpec{Mode: "upmap"}
+	assert.NoError(t, cl.Update(context.TODO(), cephCluster))
+	status = c.checkBalancer()
+	assert.True(t, status.Active)
+
+	// no status for the external clusters
+	c.isExternal = true
+	assert.Nil(t, c.checkBalancer())
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/file/mds"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util"
+	apps "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const csiCephxKeys = "csi"
+
+// cephxDaemon describes how to find the deployments of a daemon type and the cephx user of each deployment
+type cephxDaemon struct {
+	appName string
+	// idLabel is the label of the deployments holding the id of the daemon
+	idLabel string
+	user    func(d *apps.Deployment) string
+}
+
+// cephxDaemons are the daemons whose keys are rotated, in the order of the rotation. The mons are not part of them
+// since the key shared by the mons is saved in their stores when they are created.
+var cephxDaemons = map[string]cephxDaemon{
+	"mgr": {appName: mgr.AppName, idLabel: "mgr", user: func(d *apps.Deployment) string { return "mgr." + d.Labels["mgr"] }},
+	"osd": {appName: osd.AppName, idLabel: osd.OsdIdLabelKey, user: func(d *apps.Deployment) string { return "osd." + d.Labels[osd.OsdIdLabelKey] }},
+	"mds": {appName: mds.AppName, idLabel: "mds", user: func(d *apps.Deployment) string { return "mds." + d.Labels["mds"] }},
+	"rgw": {appName: object.AppName, idLabel: "rgw", user: func(d *apps.Deployment) string { return object.GenerateCephXUser(d.Name) }},
+}
+
+var cephxKeyRotationOrder = []string{"mgr", "osd", "mds", "rgw", csiCephxKeys}
+
+// rotateCephxKeys rotates the cephx keys of the daemons and the CSI users when the key generation of the rotation
+// policy is increased, or when the interval of the policy elapsed since the last rotation. Each daemon is restarted
+// right after the rotation of its key, one daemon at a time.
+func (k *keyRotationChecker) rotateCephxKeys() error {
+	cephCluster := &cephv1.CephCluster{}
+	if err := k.context.Client.Get(context.TODO(), k.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve ceph cluster %q to rotate the cephx keys", k.clusterInfo.NamespacedName().Name)
+	}
+	policy := cephCluster.Spec.Security.CephxKeyRotation
+	if !policy.Enabled && policy.KeyGeneration == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	due, err := cephxKeyRotationDue(policy, cephCluster.Status.CephxKeyRotation, now)
+	if err != nil {
+		return err
+	}
+	if !due {
+		return nil
+	}
+
+	daemonTypes, err := cephxKeyRotationDaemons(policy)
+	if err != nil {
+		return err
+	}
+	rotated := 0
+	for _, daemonType := range daemonTypes {
+		count, err := k.rotateDaemonKeys(daemonType)
+		rotated += count
+		if err != nil {
+			return errors.Wrapf(err, "failed to rotate the cephx keys of the %s daemons", daemonType)
+		}
+	}
+
+	cephCluster.Status.CephxKeyRotation = &cephv1.CephxKeyRotationStatus{
+		LastRotationTime: now.Format(time.RFC3339),
+		KeyGeneration:    policy.KeyGeneration,
+		RotatedKeys:      rotated,
+	}
+	if err := opcontroller.UpdateStatus(k.context.Client, cephCluster); err != nil {
+		return errors.Wrapf(err, "failed to update cluster %q cephx key rotation status", k.clusterInfo.NamespacedName().Name)
+	}
+	logger.Infof("rotated %d cephx keys", rotated)
+	return nil
+}
+
+// cephxKeyRotationDue returns whether the key generation was increased or the interval elapsed since the last rotation
+func cephxKeyRotationDue(policy cephv1.CephxKeyRotationSpec, status *cephv1.CephxKeyRotationStatus, now time.Time) (bool, error) {
+	if status == nil {
+		status = &cephv1.CephxKeyRotationStatus{}
+	}
+	if policy.KeyGeneration > status.KeyGeneration {
+		logger.Infof("cephx key generation increased to %d, rotating the keys", policy.KeyGeneration)
+		return true, nil
+	}
+	if !policy.Enabled {
+		return false, nil
+	}
+	interval, err := policy.GetInterval()
+	if err != nil {
+		return false, err
+	}
+	if status.LastRotationTime == "" {
+		return true, nil
+	}
+	last, err := time.Parse(time.RFC3339, status.LastRotationTime)
+	if err != nil {
+		logger.Warningf("failed to parse the last cephx key rotation time %q, rotating the keys. %v", status.LastRotationTime, err)
+		return true, nil
+	}
+	return !now.Before(last.Add(interval)), nil
+}
+
+// cephxKeyRotationDaemons returns the daemon types of the policy in the order of the rotation
+func cephxKeyRotationDaemons(policy cephv1.CephxKeyRotationSpec) ([]string, error) {
+	if len(policy.Daemons) == 0 {
+		return cephxKeyRotationOrder, nil
+	}
+	selected := map[string]bool{}
+	for _, daemonType := range policy.Daemons {
+		if _, ok := cephxDaemons[daemonType]; !ok && daemonType != csiCephxKeys {
+			return nil, errors.Errorf("invalid cephx key rotation daemon type %q", daemonType)
+		}
+		selected[daemonType] = true
+	}
+	daemonTypes := []string{}
+	for _, daemonType := range cephxKeyRotationOrder {
+		if selected[daemonType] {
+			daemonTypes = append(daemonTypes, daemonType)
+		}
+	}
+	return daemonTypes, nil
+}
+
+// rotateDaemonKeys rotates the keys of the daemons of a type and returns the number of rotated keys
+func (k *keyRotationChecker) rotateDaemonKeys(daemonType string) (int, error) {
+	store := keyring.GetSecretStore(k.context, k.clusterInfo, &k.clusterInfo.OwnerRef)
+	if daemonType == csiCephxKeys {
+		// the CSI drivers read the secrets when the volumes are provisioned and staged, they don't need a restart
+		secrets := csi.CSIUserSecrets()
+		users := []string{}
+		for user := range secrets {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+		for i, user := range users {
+			if _, err := store.RotateKey(user, secrets[user]); err != nil {
+				return i, err
+			}
+		}
+		return len(users), nil
+	}
+
+	daemon := cephxDaemons[daemonType]
+	deployments, err := k8sutil.GetDeployments(k.context.Clientset, k.clusterInfo.Namespace, fmt.Sprintf("%s=%s", k8sutil.AppAttr, daemon.appName))
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(deployments.Items, func(i, j int) bool { return deployments.Items[i].Name < deployments.Items[j].Name })
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		daemonID := d.Labels[daemon.idLabel]
+
+		// the key is rotated when the daemon can be stopped, so that the daemon runs with the old key only while
+		// it is restarted
+		err := util.Retry(5, 60*time.Second, func() error {
+			return client.OkToStop(k.context, k.clusterInfo, d.Name, daemonType, daemonID)
+		})
+		if err != nil {
+			return i, errors.Wrapf(err, "failed to check if deployment %q can be restarted", d.Name)
+		}
+		if _, err := store.RotateResourceKey(daemon.user(d), d.Name); err != nil {
+			return i, err
+		}
+		if err := k8sutil.RestartDeploymentAndWait(k.context.Clientset, d); err != nil {
+			return i + 1, errors.Wrapf(err, "failed to restart deployment %q with its new key", d.Name)
+		}
+		if err := client.OkToContinue(k.context, k.clusterInfo, d.Name, daemonType, daemonID); err != nil {
+			return i + 1, errors.Wrapf(err, "failed to check if deployment %q can continue", d.Name)
+		}
+	}
+	return len(deployments.Items), nil
+}
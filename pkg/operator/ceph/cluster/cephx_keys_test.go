@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCephxKeyRotationDue(t *testing.T) {
+	now := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	periodic := cephv1.CephxKeyRotationSpec{KeyRotationSpec: cephv1.KeyRotationSpec{Enabled: true, Interval: "1h"}}
+
+	due, err := cephxKeyRotationDue(cephv1.CephxKeyRotationSpec{}, nil, now)
+	assert.NoError(t, err)
+	assert.False(t, due)
+	due, _ = cephxKeyRotationDue(periodic, nil, now)
+	assert.True(t, due)
+	due, _ = cephxKeyRotationDue(periodic, &cephv1.CephxKeyRotationStatus{LastRotationTime: "2020-07-31T23:30:00Z"}, now)
+	assert.False(t, due)
+	due, _ = cephxKeyRotationDue(periodic, &cephv1.CephxKeyRotationStatus{LastRotationTime: "2020-07-31T23:00:00Z"}, now)
+	assert.True(t, due)
+
+	// the keys are rotated on demand when the key generation is increased
+	onDemand := cephv1.CephxKeyRotationSpec{KeyGeneration: 2}
+	due, _ = cephxKeyRotationDue(onDemand, &cephv1.CephxKeyRotationStatus{KeyGeneration: 1}, now)
+	assert.True(t, due)
+	due, _ = cephxKeyRotationDue(onDemand, &cephv1.CephxKeyRotationStatus{KeyGeneration: 2}, now)
+	assert.False(t, due)
+
+	periodic.Interval = "never"
+	_, err = cephxKeyRotationDue(periodic, nil, now)
+	assert.Error(t, err)
+}
+
+func TestCephxKeyRotationDaemons(t *testing.T) {
+	daemons, err := cephxKeyRotationDaemons(cephv1.CephxKeyRotationSpec{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mgr", "osd", "mds", "rgw", "csi"}, daemons)
+
+	daemons, err = cephxKeyRotationDaemons(cephv1.CephxKeyRotationSpec{Daemons: []string{"csi", "osd"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"osd", "csi"}, daemons)
+
+	_, err = cephxKeyRotationDaemons(cephv1.CephxKeyRotationSpec{Daemons: []string{"mon"}})
+	assert.Error(t, err)
+}
+
+func TestRotateCephxKeys(t *testing.T) {
+	namespace := "ns"
+	imported := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			switch {
+			case args[0] == "versions":
+				return `{"mgr":{"ceph version 15.2.4 (7447c15c6ff58d7fce91843b705a268a1917325c) octopus (stable)":1}}`, nil
+			case args[0] == "auth" && args[1] == "get":
+				return fmt.Sprintf(`[{"entity":"%s","key":"old-%s","caps":{"mon":"allow *"}}]`, args[2], args[2]), nil
+			case args[0] == "auth" && args[1] == "import":
+				imported = append(imported, args[3])
+				return "", nil
+			}
+			return "", nil
+		},
+	}
+	clientset := testop.New(t, 1)
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: namespace}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{})
+	cl := fake.NewFakeClientWithScheme(s, []runtime.Object{cephCluster}...)
+	clusterInfo := client.AdminClusterInfo(namespace)
+	clusterInfo.SetName("rook-ceph")
+	k := newKeyRotationChecker(&clusterd.Context{Clientset: clientset, Client: cl, Executor: executor}, clusterInfo, "rook/rook:myversion")
+
+	// nothing is rotated without a policy
+	assert.NoError(t, k.rotateCephxKeys())
+	assert.Empty(t, imported)
+	assert.NoError(t, cl.Get(context.TODO(), clusterInfo.NamespacedName(), cephCluster))
+	cephCluster.Spec.Security = cephv1.SecuritySpec{
+		CephxKeyRotation: cephv1.CephxKeyRotationSpec{KeyGeneration: 1, Daemons: []string{"mgr", "csi"}},
+	}
+	assert.NoError(t, cl.Update(context.TODO(), cephCluster))
+
+	labels := map[string]string{"app": "rook-ceph-mgr", "mgr": "a"}
+	_, err := clientset.AppsV1().Deployments(namespace).Create(&apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mgr-a", Namespace: namespace, Labels: labels},
+		Spec:       apps.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		Status:     apps.DeploymentStatus{ReadyReplicas: 1},
+	})
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Pods(namespace).Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mgr-a-1", Namespace: namespace, Labels: labels}})
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Secrets(namespace).Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mgr-a-keyring", Namespace: namespace},
+		Data:       map[string][]byte{"keyring": []byte("[mgr.a]\nkey = old-mgr.a\n")},
+	})
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Secrets(namespace).Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: csi.CsiRBDNodeSecret, Namespace: namespace},
+		Data:       map[string][]byte{"userID": []byte("csi-rbd-node"), "userKey": []byte("old-client.csi-rbd-node")},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, k.rotateCephxKeys())
+	assert.Equal(t, 5, len(imported))
+
+	// the secrets have the new keys and the mgr was restarted
+	secret, err := clientset.CoreV1().Secrets(namespace).Get("rook-ceph-mgr-a-keyring", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(secret.Data["keyring"]), "old-mgr.a")
+	secret, err = clientset.CoreV1().Secrets(namespace).Get(csi.CsiRBDNodeSecret, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "csi-rbd-node", string(secret.Data["userID"]))
+	assert.NotEqual(t, "old-client.csi-rbd-node", string(secret.Data["userKey"]))
+	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(pods.Items))
+
+	assert.NoError(t, cl.Get(context.TODO(), clusterInfo.NamespacedName(), cephCluster))
+	assert.Equal(t, 1, cephCluster.Status.CephxKeyRotation.KeyGeneration)
+	assert.Equal(t, 5, cephCluster.Status.CephxKeyRotation.RotatedKeys)
+
+	// the keys are not rotated again for the same key generation
+	assert.NoError(t, k.rotateCephxKeys())
+	assert.Equal(t, 5, len(imported))
+}
@@ -150,6 +150,7 @@ func (c *ClusterController) cleanUpJobTemplateSpec(cluster *cephv1.CephCluster,
 			Volumes:           volumes,
 			RestartPolicy:     v1.RestartPolicyOnFailure,
 			PriorityClassName: cephv1.GetCleanupPriorityClassName(cluster.Spec.PriorityClassNames),
+			SecurityContext:   cephv1.GetPodSecurityContext(cluster.Spec.Security.PodSecurityContexts, cephv1.KeyCleanup),
 		},
 	}
 
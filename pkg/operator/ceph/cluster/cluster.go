@@ -19,9 +19,11 @@ package cluster
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -29,16 +31,22 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/crash"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/exporter"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/netpolicy"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/toolbox"
 	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/notification"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -61,13 +69,19 @@ type cluster struct {
 	orchestrationNeeded  bool
 	orchMux              sync.Mutex
 	isUpgrade            bool
+	upgradeRollback      bool
 	watchersActivated    bool
 	monitoringChannels   map[string]*clusterHealth
+	recorder             record.EventRecorder
+	// requeueAfter requests another reconcile of the cluster for the work spread over several reconciles
+	requeueAfter time.Duration
 }
 
 type clusterHealth struct {
 	stopChan          chan struct{}
 	monitoringRunning bool
+	// spec is the settings the goroutine was started with, for the goroutines started again when they change
+	spec interface{}
 }
 
 func newCluster(c *cephv1.CephCluster, context *clusterd.Context, csiMutex *sync.Mutex, ownerRef *metav1.OwnerReference) *cluster {
@@ -87,6 +101,18 @@ func newCluster(c *cephv1.CephCluster, context *clusterd.Context, csiMutex *sync
 	}
 }
 
+// requeue requests another reconcile of the cluster after the given time, the shortest request wins
+func (c *cluster) requeue(after time.Duration) {
+	if c.requeueAfter == 0 || after < c.requeueAfter {
+		c.requeueAfter = after
+	}
+}
+
+// namespacedName returns the name and namespace of the CephCluster CR of the cluster
+func (c *cluster) namespacedName() types.NamespacedName {
+	return types.NamespacedName{Namespace: c.Namespace, Name: c.crdName}
+}
+
 func (c *cluster) createInstance(rookImage string, cephVersion cephver.CephVersion) error {
 	var err error
 
@@ -121,6 +147,11 @@ func (c *cluster) doOrchestration(rookImage string, cephVersion cephver.CephVers
 		return errors.Wrap(err, "failed to populate config override config map")
 	}
 
+	// Allow the traffic of the daemons before they start in a namespace denying it by default
+	if err := netpolicy.Reconcile(c.context, c.Namespace, c.ownerRef, spec, os.Getenv(k8sutil.PodNamespaceEnvVar)); err != nil {
+		return errors.Wrap(err, "failed to reconcile the network policies")
+	}
+
 	// Start the mon pods
 	clusterInfo, err := c.mons.Start(c.ClusterInfo, rookImage, cephVersion, *c.Spec)
 	if err != nil {
@@ -158,18 +189,31 @@ func (c *cluster) doOrchestration(rookImage string, cephVersion cephver.CephVers
 		return errors.Wrap(err, "failed to start ceph mgr")
 	}
 
+	// Deploy the toolbox with the image of the cluster
+	if err := toolbox.Reconcile(c.context, c.ClusterInfo, spec); err != nil {
+		return errors.Wrap(err, "failed to reconcile the toolbox")
+	}
+
 	// Start the OSDs
 	osds := osd.New(c.context, c.ClusterInfo, *spec, rookImage)
+	osds.Recorder = c.recorder
 	err = osds.Start()
 	if err != nil {
 		return errors.Wrap(err, "failed to start ceph osds")
 	}
+	if osds.StoreMigrationPending {
+		c.requeue(osd.StoreMigrationRequeueInterval)
+	}
 
 	logger.Infof("done reconciling ceph cluster in namespace %q", c.Namespace)
 
 	// We should be done updating by now
 	if c.isUpgrade {
 		c.printOverallCephVersion()
+		// the upgrade completes once the daemons of the child controllers are upgraded too
+		opcontroller.ReportUpgradeProgress(c.context, c.Namespace, "", nil)
+
+		opcontroller.RecordOwnerEvent(c.recorder, c.Namespace, c.ownerRef, v1.EventTypeNormal, opcontroller.EventReasonUpgradeCompleted, "upgraded ceph to %q", cephVersion.String())
 
 		// reset the isUpgrade flag
 		c.isUpgrade = false
@@ -198,6 +242,7 @@ func (c *ClusterController) initializeCluster(cluster *cluster, clusterObj *ceph
 		logger.Infof("clusterInfo not yet found, must be a new cluster")
 	} else {
 		clusterInfo.OwnerRef = cluster.ownerRef
+		clusterInfo.NetworkSpec = cluster.Spec.Network
 		clusterInfo.SetName(cluster.crdName)
 		cluster.ClusterInfo = clusterInfo
 	}
@@ -206,7 +251,7 @@ func (c *ClusterController) initializeCluster(cluster *cluster, clusterObj *ceph
 	if cluster.Spec.External.Enable {
 		err := c.configureExternalCephCluster(cluster)
 		if err != nil {
-			config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionFailure, v1.ConditionTrue, "ClusterFailure", "Failed to configure external ceph cluster")
+			config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionFailure, v1.ConditionTrue, "ClusterFailure", "Failed to configure external ceph cluster")
 			return errors.Wrap(err, "failed to configure external ceph cluster")
 		}
 	} else {
@@ -239,34 +284,63 @@ func (c *ClusterController) configureLocalCephCluster(cluster *cluster, clusterO
 	if err != nil {
 		return errors.Wrap(err, "failed to perform validation before cluster creation")
 	}
-
-	// Pass down the client to interact with Kubernetes objects
-	// This will be used later down by spec code to create objects like deployment, services etc
-	cluster.context.Client = c.client
+	c.reportDeprecatedFields(cluster)
 
 	// Run image validation job
+	cluster.upgradeRollback = isUpgradeRollback(clusterObj.Status.Upgrade, cluster.Spec.CephVersion.Image)
 	cephVersion, isUpgrade, err := c.detectAndValidateCephVersion(cluster)
 	if err != nil {
 		return errors.Wrap(err, "failed the ceph version check")
 	}
 
+	if err := validateConnections(c.context, cluster.Spec.Network, *cephVersion); err != nil {
+		return errors.Wrap(err, "invalid connections settings")
+	}
+	if err := validateIPFamily(cluster.Spec.Network, *cephVersion); err != nil {
+		return errors.Wrap(err, "invalid ip family settings")
+	}
+
 	// Set the value of isUpgrade based on the image discovery done by detectAndValidateCephVersion()
 	cluster.isUpgrade = isUpgrade
+	if isUpgrade {
+		opcontroller.RecordOwnerEvent(c.recorder, cluster.Namespace, cluster.ownerRef, v1.EventTypeNormal, opcontroller.EventReasonUpgradeStarted, "upgrading ceph to %q", cephVersion.String())
+	}
 
 	// Set the condition to the cluster object
-	message := config.CheckConditionReady(c.context, c.namespacedName)
-	config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionTrue, "ClusterProgressing", message)
+	message := config.CheckConditionReady(c.context, cluster.namespacedName())
+	config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionProgressing, v1.ConditionTrue, "ClusterProgressing", message)
 
 	// Run the orchestration
+	requestQuorumRestore(cluster, clusterObj)
 	err = cluster.createInstance(c.rookImage, *cephVersion)
+	c.reportQuorumRestore(cluster)
+	if err != nil && cluster.isUpgrade {
+		if cancelErr := opcontroller.CheckUpgradeCancelled(c.context, cluster.Namespace); cancelErr != nil {
+			c.cancelUpgrade(cluster, cancelErr)
+			config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionProgressing, v1.ConditionTrue, "UpgradeCancelled", cancelErr.Error())
+			return errors.Wrap(cancelErr, "failed to upgrade cluster")
+		}
+	}
 	if err != nil {
-		config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionFailure, v1.ConditionTrue, "ClusterFailure", "Failed to create cluster")
+		if cluster.isUpgrade {
+			notification.Warning(notification.ReasonUpgradeFailed, cluster.Namespace, cluster.crdName, "failed to upgrade cluster to %q. %v", cephVersion.String(), err)
+			opcontroller.RecordOwnerEvent(c.recorder, cluster.Namespace, cluster.ownerRef, v1.EventTypeWarning, opcontroller.EventReasonUpgradeFailed, "failed to upgrade ceph to %q. %v", cephVersion.String(), err)
+		}
+		config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionFailure, v1.ConditionTrue, "ClusterFailure", "Failed to create cluster")
 		return errors.Wrap(err, "failed to create cluster")
 	}
 
+	c.reportConfigOverrideConflicts(cluster)
+
 	// Set the condition to the cluster object
-	config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionReady, v1.ConditionTrue, "ClusterCreated", "Cluster created successfully")
+	config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionReady, v1.ConditionTrue, "ClusterCreated", "Cluster created successfully")
+
+	// the spec of the cluster has the defaults of the operator
+	opcontroller.ExportEffectiveSpec(c.client, clusterObj, cluster.Spec)
 
+	if err := c.restartDaemons(cluster, clusterObj); err != nil {
+		return errors.Wrap(err, "failed to restart the daemons")
+	}
 	return nil
 }
 
@@ -339,29 +413,16 @@ func (c *ClusterController) preClusterStartValidation(cluster *cluster, clusterO
 		logger.Warning("running osds on directory is not supported anymore, use devices instead.")
 	}
 	if cluster.Spec.Network.IsMultus() {
-		_, isPublic := cluster.Spec.Network.Selectors[config.PublicNetworkSelectorKeyName]
-		_, isCluster := cluster.Spec.Network.Selectors[config.ClusterNetworkSelectorKeyName]
-		if !isPublic && !isCluster {
-			return errors.New("both network selector values for public and cluster selector cannot be empty for multus provider")
-		}
-
-		for _, selector := range config.NetworkSelectors {
-			// If one selector is empty, we continue
-			// This means a single interface is used both public and cluster network
-			if _, ok := cluster.Spec.Network.Selectors[selector]; !ok {
-				continue
-			}
-
-			// Get network attachment definition
-			_, err := c.context.NetworkClient.NetworkAttachmentDefinitions(cluster.Namespace).Get(cluster.Spec.Network.Selectors[selector], metav1.GetOptions{})
-			if err != nil {
-				if kerrors.IsNotFound(err) {
-					return errors.Wrapf(err, "specified network attachment definition for selector %q does not exist", selector)
-				}
-				return errors.Wrapf(err, "failed to fetch network attachment definition for selector %q", selector)
-			}
+		if err := config.ValidateNetworkSelectors(c.context, cluster.Namespace, cluster.Spec.Network); err != nil {
+			return errors.Wrap(err, "failed to validate the multus networks")
 		}
 	}
+	if err := validateHostPorts(c.context, clusterObj); err != nil {
+		return errors.Wrap(err, "invalid host ports")
+	}
+	if err := validateUpgradeChecks(cluster.Spec.UpgradeChecks); err != nil {
+		return errors.Wrap(err, "invalid upgrade checks")
+	}
 
 	logger.Debug("cluster spec successfully validated")
 	return nil
@@ -383,6 +444,13 @@ func (c *cluster) postMonStartupActions() error {
 		return errors.Wrap(err, "failed to create crash collector kubernetes secret")
 	}
 
+	// Create ceph-exporter Kubernetes Secret
+	if c.Spec.Monitoring.IsExporterEnabled() {
+		if err := exporter.CreateExporterSecret(c.context, c.ClusterInfo); err != nil {
+			return errors.Wrap(err, "failed to create ceph-exporter kubernetes secret")
+		}
+	}
+
 	// Enable Ceph messenger 2 protocol on Nautilus
 	if err := client.EnableMessenger2(c.context, c.ClusterInfo); err != nil {
 		return errors.Wrap(err, "failed to enable Ceph messenger version 2")
@@ -18,6 +18,9 @@ limitations under the License.
 package cluster
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
@@ -40,11 +43,19 @@ func (c *ClusterController) configureExternalCephCluster(cluster *cluster) error
 		return errors.Wrap(err, "failed to validate external cluster specs")
 	}
 
-	config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionConnecting, v1.ConditionTrue, "ClusterConnecting", "Cluster is connecting")
+	config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionConnecting, v1.ConditionTrue, "ClusterConnecting", "Cluster is connecting")
+
+	// Discover the mons of the external cluster instead of waiting for the import script
+	if cluster.Spec.External.Discovery.Enabled {
+		err = mon.ImportExternalClusterInfo(c.context, cluster.Namespace, cluster.Spec.External.Discovery.SecretName, cluster.ownerRef)
+		if err != nil {
+			return errors.Wrap(err, "failed to import the external cluster")
+		}
+	}
 
 	// loop until we find the secret necessary to connect to the external cluster
 	// then populate clusterInfo
-	cluster.ClusterInfo = mon.PopulateExternalClusterInfo(c.context, c.namespacedName.Namespace, cluster.ownerRef)
+	cluster.ClusterInfo = mon.PopulateExternalClusterInfo(c.context, cluster.Namespace, cluster.ownerRef)
 	cluster.ClusterInfo.SetName(cluster.crdName)
 
 	if !client.IsKeyringBase64Encoded(cluster.ClusterInfo.CephCred.Secret) {
@@ -72,13 +83,13 @@ func (c *ClusterController) configureExternalCephCluster(cluster *cluster) error
 		//
 		// Only do this when doing a bit of management...
 		logger.Infof("creating %q configmap", k8sutil.ConfigOverrideName)
-		err = populateConfigOverrideConfigMap(c.context, c.namespacedName.Namespace, cluster.ClusterInfo.OwnerRef)
+		err = populateConfigOverrideConfigMap(c.context, cluster.Namespace, cluster.ClusterInfo.OwnerRef)
 		if err != nil {
 			return errors.Wrap(err, "failed to populate config override config map")
 		}
 
 		logger.Infof("creating %q secret", config.StoreName)
-		err = config.GetStore(c.context, c.namespacedName.Namespace, &cluster.ClusterInfo.OwnerRef).CreateOrUpdate(cluster.ClusterInfo)
+		err = config.GetStore(c.context, cluster.Namespace, &cluster.ClusterInfo.OwnerRef).CreateOrUpdate(cluster.ClusterInfo)
 		if err != nil {
 			return errors.Wrap(err, "failed to update the global config")
 		}
@@ -90,22 +101,55 @@ func (c *ClusterController) configureExternalCephCluster(cluster *cluster) error
 	}
 	logger.Info("external cluster identity established")
 
+	// Replace the admin key with least-privilege users, this also creates the CSI Secrets
+	if cluster.Spec.External.RestrictUsers && cluster.ClusterInfo.CephCred.Username == client.AdminUsername {
+		err = restrictExternalUsers(c.context, cluster.ClusterInfo, cluster.Spec)
+		if err != nil {
+			return errors.Wrap(err, "failed to restrict external cluster users")
+		}
+	}
+
+	// Validate the caps of a restricted user before using it
+	if cluster.ClusterInfo.CephCred.Username != client.AdminUsername {
+		missing, err := missingExternalUserCaps(c.context, cluster.ClusterInfo)
+		if err != nil {
+			return errors.Wrap(err, "failed to validate the caps of the external cluster user")
+		}
+		if len(missing) > 0 {
+			message := fmt.Sprintf("user %q lacks the caps %s", cluster.ClusterInfo.CephCred.Username, strings.Join(missing, ", "))
+			config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionInsufficientCaps, v1.ConditionTrue, "InsufficientCaps", message)
+			return errors.New(message)
+		}
+		config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionInsufficientCaps, v1.ConditionFalse, "CapsValidated", "User caps are validated")
+	}
+
 	// Create CSI Secrets only if the user has provided the admin key
 	if cluster.ClusterInfo.CephCred.Username == client.AdminUsername {
 		err = csi.CreateCSISecrets(c.context, cluster.ClusterInfo)
 		if err != nil {
 			return errors.Wrap(err, "failed to create csi kubernetes secrets")
 		}
+	} else if cluster.Spec.External.Discovery.Enabled {
+		// Otherwise the csi users must have been created in the external cluster, their keys
+		// are read from the discovery secret or from the external cluster
+		csiKeys, err := mon.GetExternalCSIKeys(c.context, cluster.Namespace, cluster.Spec.External.Discovery.SecretName)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the csi keys of the discovery secret")
+		}
+		err = csi.ImportCSISecrets(c.context, cluster.ClusterInfo, csiKeys)
+		if err != nil {
+			return errors.Wrap(err, "failed to import csi kubernetes secrets")
+		}
 	}
 
 	// Create CSI config map
-	err = csi.CreateCsiConfigMap(c.namespacedName.Namespace, c.context.Clientset, &cluster.ownerRef)
+	err = csi.CreateCsiConfigMap(cluster.Namespace, c.context.Clientset, &cluster.ownerRef)
 	if err != nil {
 		return errors.Wrap(err, "failed to create csi config map")
 	}
 
 	// Save CSI configmap
-	err = csi.SaveClusterConfig(c.context.Clientset, c.namespacedName.Namespace, cluster.ClusterInfo, c.csiConfigMutex)
+	err = csi.SaveClusterConfig(c.context.Clientset, cluster.Namespace, cluster.ClusterInfo, &cluster.Spec.CSI, c.csiConfigMutex)
 	if err != nil {
 		return errors.Wrap(err, "failed to update csi cluster config")
 	}
@@ -128,7 +172,15 @@ func (c *ClusterController) configureExternalCephCluster(cluster *cluster) error
 	cluster.ClusterInfo.CephVersion = *externalVersion
 
 	// Populate ceph version
-	c.updateClusterCephVersion("", *externalVersion)
+	c.updateClusterCephVersion(cluster, "", *externalVersion, false)
+
+	// Discover the pools, filesystems and dashboard of the external cluster
+	if cluster.Spec.External.Discovery.Enabled {
+		err = discoverExternalResources(c.context, cluster.ClusterInfo, cluster.Spec)
+		if err != nil {
+			return errors.Wrap(err, "failed to discover external cluster resources")
+		}
+	}
 
 	// enable monitoring if `monitoring: enabled: true`
 	// We need the Ceph version
@@ -150,6 +202,7 @@ func purgeExternalCluster(clientset kubernetes.Interface, namespace string) {
 	cmsToDelete := []string{
 		mon.EndpointConfigMapName,
 		k8sutil.ConfigOverrideName,
+		ExternalClusterDetailsName,
 	}
 	for _, cm := range cmsToDelete {
 		err := clientset.CoreV1().ConfigMaps(namespace).Delete(cm, &metav1.DeleteOptions{})
@@ -177,6 +230,10 @@ func purgeExternalCluster(clientset kubernetes.Interface, namespace string) {
 }
 
 func validateExternalClusterSpec(cluster *cluster) error {
+	if cluster.Spec.External.Discovery.Enabled && cluster.Spec.External.Discovery.SecretName == "" {
+		return errors.New("the discovery secretName must be specified")
+	}
+
 	if cluster.Spec.CephVersion.Image != "" {
 		if cluster.Spec.DataDirHostPath == "" {
 			return errors.New("dataDirHostPath must be specified")
@@ -198,7 +255,7 @@ func (c *ClusterController) configureExternalClusterMonitoring(cluster *cluster)
 	// Create external monitoring Service
 	service := manager.MakeMetricsService(mgr.ExternalMgrAppName, mgr.ServiceExternalMetricName)
 	logger.Info("creating mgr external monitoring service")
-	_, err := c.context.Clientset.CoreV1().Services(c.namespacedName.Namespace).Create(service)
+	_, err := c.context.Clientset.CoreV1().Services(cluster.Namespace).Create(service)
 	if err != nil {
 		if !kerrors.IsAlreadyExists(err) {
 			return errors.Wrap(err, "failed to create mgr service")
@@ -211,7 +268,7 @@ func (c *ClusterController) configureExternalClusterMonitoring(cluster *cluster)
 	// Create external monitoring Endpoints
 	endpoint := mgr.CreateExternalMetricsEndpoints(cluster.Namespace, cluster.Spec.Monitoring.ExternalMgrEndpoints, cluster.ownerRef)
 	logger.Info("creating mgr external monitoring endpoints")
-	_, err = k8sutil.CreateOrUpdateEndpoint(c.context.Clientset, c.namespacedName.Namespace, endpoint)
+	_, err = k8sutil.CreateOrUpdateEndpoint(c.context.Clientset, cluster.Namespace, endpoint)
 	if err != nil {
 		return errors.Wrap(err, "failed to create or update mgr endpoint")
 	}
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ExternalClusterDetailsName is the name of the config map with the resources discovered in the external cluster
+	ExternalClusterDetailsName = "rook-ceph-external-cluster-details"
+	externalDashboardKey       = "dashboard"
+	externalPrometheusKey      = "prometheus"
+	externalPoolsKey           = "pools"
+	externalFilesystemsKey     = "filesystems"
+)
+
+// discoverExternalResources saves the dashboard, pools and filesystems of the external cluster in a config map
+// so they can be used to create the storage classes, and fills the mgr endpoints of the monitoring spec.
+func discoverExternalResources(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec *cephv1.ClusterSpec) error {
+	details := map[string]string{}
+
+	services, err := client.MgrServices(context, clusterInfo)
+	if err != nil {
+		// a restricted user may not be allowed to list the mgr services
+		logger.Warningf("failed to discover the mgr services of the external cluster. %v", err)
+	} else {
+		details[externalDashboardKey] = services["dashboard"]
+		details[externalPrometheusKey] = services["prometheus"]
+	}
+
+	pools, err := client.ListPoolSummaries(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the pools of the external cluster")
+	}
+	poolNames := []string{}
+	for _, pool := range pools {
+		poolNames = append(poolNames, pool.Name)
+	}
+	sort.Strings(poolNames)
+	details[externalPoolsKey] = strings.Join(poolNames, ",")
+
+	filesystems, err := client.ListFilesystems(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the filesystems of the external cluster")
+	}
+	fsNames := []string{}
+	for _, fs := range filesystems {
+		fsNames = append(fsNames, fs.Name)
+	}
+	sort.Strings(fsNames)
+	details[externalFilesystemsKey] = strings.Join(fsNames, ",")
+	logger.Infof("discovered external cluster pools %v and filesystems %v", poolNames, fsNames)
+
+	if spec.Monitoring.Enabled && len(spec.Monitoring.ExternalMgrEndpoints) == 0 {
+		if ip := serviceEndpointIP(details[externalPrometheusKey]); ip != "" {
+			logger.Infof("using discovered prometheus exporter endpoint %q", ip)
+			spec.Monitoring.ExternalMgrEndpoints = []v1.EndpointAddress{{IP: ip}}
+		}
+	}
+
+	return saveExternalClusterDetails(context, clusterInfo, details)
+}
+
+// serviceEndpointIP returns the ip of the url of a mgr service, services listening on a host name are ignored
+func serviceEndpointIP(serviceURL string) string {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+func saveExternalClusterDetails(context *clusterd.Context, clusterInfo *client.ClusterInfo, details map[string]string) error {
+	cm, err := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Get(ExternalClusterDetailsName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get external cluster details")
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ExternalClusterDetailsName,
+				Namespace: clusterInfo.Namespace,
+			},
+			Data: details,
+		}
+		k8sutil.SetOwnerRef(&cm.ObjectMeta, &clusterInfo.OwnerRef)
+		if _, err := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Create(cm); err != nil {
+			return errors.Wrap(err, "failed to create external cluster details")
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(cm.Data, details) {
+		return nil
+	}
+	cm.Data = details
+	if _, err := k8sutil.UpdateConfigMap(context.Clientset, cm); err != nil {
+		return errors.Wrap(err, "failed to update external cluster details")
+	}
+	return nil
+}
@@ -20,8 +20,13 @@ import (
 	"testing"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestValidateExternalClusterSpec(t *testing.T) {
@@ -36,4 +41,43 @@ func TestValidateExternalClusterSpec(t *testing.T) {
 	c.Spec.DataDirHostPath = "path"
 	err = validateExternalClusterSpec(c)
 	assert.NoError(t, err, err)
+
+	c.Spec.External.Discovery.Enabled = true
+	err = validateExternalClusterSpec(c)
+	assert.Error(t, err)
+	c.Spec.External.Discovery.SecretName = "discovery"
+	err = validateExternalClusterSpec(c)
+	assert.NoError(t, err)
+}
+
+func TestDiscoverExternalResources(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			switch {
+			case args[0] == "mgr" && args[1] == "services":
+				return `{"dashboard":"https://10.0.0.5:8443/","prometheus":"http://10.0.0.5:9283/"}`, nil
+			case args[0] == "osd" && args[1] == "lspools":
+				return `[{"poolnum":2,"poolname":"replicapool"},{"poolnum":1,"poolname":"device_health_metrics"}]`, nil
+			case args[0] == "fs" && args[1] == "ls":
+				return `[{"name":"myfs","metadata_pool":"myfs-metadata","data_pools":["myfs-data0"]}]`, nil
+			}
+			return "", nil
+		},
+	}
+	clientset := testop.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset, Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	spec := &cephv1.ClusterSpec{Monitoring: cephv1.MonitoringSpec{Enabled: true}}
+
+	err := discoverExternalResources(context, clusterInfo, spec)
+	assert.NoError(t, err)
+	cm, err := clientset.CoreV1().ConfigMaps("ns").Get(ExternalClusterDetailsName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.5:8443/", cm.Data["dashboard"])
+	assert.Equal(t, "device_health_metrics,replicapool", cm.Data["pools"])
+	assert.Equal(t, "myfs", cm.Data["filesystems"])
+	assert.Equal(t, 1, len(spec.Monitoring.ExternalMgrEndpoints))
+	assert.Equal(t, "10.0.0.5", spec.Monitoring.ExternalMgrEndpoints[0].IP)
+
+	assert.Equal(t, "", serviceEndpointIP("http://mgr.example.com:9283/"))
 }
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+)
+
+const (
+	// the least-privilege user of the operator, named like the user of create-external-cluster-resources
+	externalHealthcheckerUsername = "client.healthchecker"
+	// the pools of the default object store are checked by the object store health checker
+	externalRGWPoolPrefix = "default"
+)
+
+// externalUserProbes are the commands run by the operator with the user of an external cluster and the caps they require
+var externalUserProbes = []struct {
+	args []string
+	caps string
+}{
+	{args: []string{"status"}, caps: `mon "allow r"`},
+	{args: []string{"quorum_status"}, caps: `mon "allow command quorum_status"`},
+	{args: []string{"version"}, caps: `mon "allow command version"`},
+}
+
+func externalHealthcheckerCaps() []string {
+	return []string{
+		"mon", "allow r, allow command quorum_status, allow command version",
+		"osd", fmt.Sprintf("allow rwx pool=%[1]s.rgw.meta, allow r pool=.rgw.root, allow rw pool=%[1]s.rgw.control, allow rx pool=%[1]s.rgw.log, allow x pool=%[1]s.rgw.buckets.index", externalRGWPoolPrefix),
+	}
+}
+
+// restrictExternalUsers creates the healthchecker and the csi users with the admin key, then replaces the admin user
+// with the healthchecker in the secrets of the cluster. The admin key is not used anymore once the users are created.
+func restrictExternalUsers(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec *cephv1.ClusterSpec) error {
+	key, err := client.AuthGetOrCreateKey(context, clusterInfo, externalHealthcheckerUsername, externalHealthcheckerCaps())
+	if err != nil {
+		return errors.Wrap(err, "failed to create the healthchecker user")
+	}
+	csiKeys, err := csi.CreateCSIKeys(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the csi users")
+	}
+
+	cred := client.CephCred{Username: externalHealthcheckerUsername, Secret: key}
+	if spec.External.Discovery.Enabled {
+		// the discovery secret is imported again on every reconcile
+		err = mon.SaveExternalDiscoveryUsers(context, clusterInfo.Namespace, spec.External.Discovery.SecretName, cred, csiKeys)
+		if err != nil {
+			return errors.Wrap(err, "failed to save the restricted users in the discovery secret")
+		}
+	}
+	clusterInfo.CephCred = cred
+	if err := mon.SaveExternalClusterAccessSecret(context, clusterInfo); err != nil {
+		return errors.Wrap(err, "failed to save the healthchecker user")
+	}
+	if err := mon.WriteConnectionConfig(context, clusterInfo); err != nil {
+		return err
+	}
+
+	logger.Infof("external cluster users restricted, connecting with user %q", externalHealthcheckerUsername)
+	return nil
+}
+
+// missingExternalUserCaps returns the caps of the commands the user of the external cluster is not allowed to run
+func missingExternalUserCaps(context *clusterd.Context, clusterInfo *client.ClusterInfo) ([]string, error) {
+	missing := []string{}
+	for _, probe := range externalUserProbes {
+		_, err := client.NewCephCommand(context, clusterInfo, probe.args).Run()
+		if err != nil {
+			if client.IsPermissionDenied(err) {
+				missing = append(missing, probe.caps)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to run %q", strings.Join(probe.args, " "))
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMissingExternalUserCaps(t *testing.T) {
+	// the ceph cli exits with EACCES when the caps of the user do not allow a command
+	permissionDenied := exec.Command("sh", "-c", "exit 13").Run()
+	denied := map[string]bool{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			if denied[args[0]] {
+				return "", permissionDenied
+			}
+			return "{}", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+
+	missing, err := missingExternalUserCaps(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(missing))
+
+	denied["quorum_status"] = true
+	denied["version"] = true
+	missing, err = missingExternalUserCaps(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`mon "allow command quorum_status"`, `mon "allow command version"`}, missing)
+
+	// other failures are not reported as missing caps
+	executor.MockExecuteCommandWithOutputFile = func(command string, outFileArg string, args ...string) (string, error) {
+		return "", errors.New("timed out")
+	}
+	_, err = missingExternalUserCaps(context, clusterInfo)
+	assert.Error(t, err)
+}
+
+func TestRestrictExternalUsers(t *testing.T) {
+	created := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			if args[0] == "auth" && args[1] == "get-or-create-key" {
+				created = append(created, args[2])
+				return `{"key":"AQB1ZdZeAAAAABAA2ur/LQNW96VErboRgydnGQ=="}`, nil
+			}
+			return "", nil
+		},
+	}
+	clientset := testop.New(t, 1)
+	configDir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(configDir)
+	context := &clusterd.Context{Clientset: clientset, ConfigDir: configDir, Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	clusterInfo.FSID = "613975f3-3025-4802-9de1-a2280b950e75"
+	clusterInfo.Monitors = map[string]*cephclient.MonInfo{"a": {Name: "a", Endpoint: "10.0.0.1:6789"}}
+
+	discovery := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery", Namespace: "ns"},
+		Data: map[string][]byte{
+			"monHost": []byte("10.0.0.1:6789"),
+			"userKey": []byte("AQB1ZdZeAAAAABAA2ur/LQNW96VErboRgydnGQ=="),
+		},
+	}
+	_, err := clientset.CoreV1().Secrets("ns").Create(discovery)
+	assert.NoError(t, err)
+	spec := &cephv1.ClusterSpec{External: cephv1.ExternalSpec{
+		Enable:        true,
+		RestrictUsers: true,
+		Discovery:     cephv1.ExternalDiscoverySpec{Enabled: true, SecretName: "discovery"},
+	}}
+
+	err = restrictExternalUsers(context, clusterInfo, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"client.healthchecker", "client.csi-rbd-provisioner", "client.csi-rbd-node", "client.csi-cephfs-provisioner", "client.csi-cephfs-node"}, created)
+	assert.Equal(t, "client.healthchecker", clusterInfo.CephCred.Username)
+
+	// the operator connects with the healthchecker from now on
+	info, _, _, err := mon.LoadClusterInfo(context, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, "client.healthchecker", info.CephCred.Username)
+	monSecret, err := clientset.CoreV1().Secrets("ns").Get(mon.AppName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, mon.AdminSecretNameKey, string(monSecret.Data[mon.AdminSecretNameKey]))
+
+	// the discovery secret does not hold the admin key anymore
+	discovery, err = clientset.CoreV1().Secrets("ns").Get("discovery", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "healthchecker", string(discovery.Data["userID"]))
+	keys, err := mon.GetExternalCSIKeys(context, "ns", "discovery")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(keys))
+	assert.Equal(t, "AQB1ZdZeAAAAABAA2ur/LQNW96VErboRgydnGQ==", keys[csi.CsiRBDNodeSecret])
+}
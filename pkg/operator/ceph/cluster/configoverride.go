@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	v1 "k8s.io/api/core/v1"
+)
+
+// reportConfigOverrideConflicts merges the config override with the options generated by the operator, and exports
+// the conflicting options in the ConfigOverrideConflict condition of the cluster. The conflicts are reported with an
+// event when they change.
+func (c *ClusterController) reportConfigOverrideConflicts(cluster *cluster) {
+	conflicts, changed, err := cephconfig.MergeConfigOverride(c.context, cluster.ClusterInfo)
+	if err != nil {
+		logger.Warningf("failed to merge the config override of cluster %q. %v", cluster.Namespace, err)
+		return
+	}
+	if len(conflicts) == 0 {
+		cephconfig.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionConfigOverrideConflict, v1.ConditionFalse, "NoConfigOverrideConflicts", "The config override does not conflict with the generated options")
+		return
+	}
+
+	descriptions := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		descriptions = append(descriptions, conflict.String())
+	}
+	message := "the config override conflicts with the generated options: " + strings.Join(descriptions, "; ")
+	cephconfig.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionConfigOverrideConflict, v1.ConditionTrue, "ConfigOverrideConflicts", message)
+	if changed {
+		logger.Warningf("cluster %q: %s", cluster.Namespace, message)
+		opcontroller.RecordOwnerEvent(c.recorder, cluster.Namespace, cluster.ownerRef, v1.EventTypeWarning, opcontroller.EventReasonConfigOverrideConflict, "%s", message)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the kernel clients support the secure mode of msgr2 from kernel 5.11
+const (
+	secureModeKernelMajor = 5
+	secureModeKernelMinor = 11
+)
+
+var kernelVersionRegex = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// validateConnections validates the settings of the connections of the cluster with the version of Ceph and warns
+// about the nodes whose kernel clients cannot use the secure mode
+func validateConnections(context *clusterd.Context, networkSpec cephv1.NetworkSpec, cephVersion cephver.CephVersion) error {
+	if networkSpec.IsCompressionEnabled() && !cephVersion.IsAtLeast(config.CompressionMinimumVersion) {
+		return errors.Errorf("compression of the connections requires ceph quincy or newer, found %q", cephVersion.String())
+	}
+	if !networkSpec.IsEncryptionEnabled() {
+		return nil
+	}
+
+	nodes, err := context.Clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		// the nodes are only checked to warn about the kernel clients
+		logger.Warningf("failed to list the nodes to check the kernel versions. %v", err)
+		return nil
+	}
+	oldKernelNodes := []string{}
+	for _, node := range nodes.Items {
+		if !supportsSecureMode(node.Status.NodeInfo.KernelVersion) {
+			oldKernelNodes = append(oldKernelNodes, node.Name)
+		}
+	}
+	if len(oldKernelNodes) > 0 {
+		logger.Warningf("the kernel of nodes %v is older than %d.%d, their kernel rbd and cephfs clients cannot encrypt their connections and keep using unencrypted msgr1 connections",
+			oldKernelNodes, secureModeKernelMajor, secureModeKernelMinor)
+	}
+	return nil
+}
+
+// validateIPFamily refuses the IP families which are unknown or unsupported by the version of Ceph
+func validateIPFamily(networkSpec cephv1.NetworkSpec, cephVersion cephver.CephVersion) error {
+	if networkSpec.IPFamily != "" && networkSpec.IPFamily != cephv1.IPv4 && networkSpec.IPFamily != cephv1.IPv6 {
+		return errors.Errorf("invalid ip family %q, must be %q or %q", networkSpec.IPFamily, cephv1.IPv4, cephv1.IPv6)
+	}
+	if networkSpec.DualStack && !cephVersion.IsAtLeast(config.DualStackMinimumVersion) {
+		return errors.Errorf("dual stack requires ceph pacific or newer, found %q", cephVersion.String())
+	}
+	return nil
+}
+
+// supportsSecureMode returns whether a kernel version like "5.4.0-42-generic" supports the secure mode
+func supportsSecureMode(kernelVersion string) bool {
+	match := kernelVersionRegex.FindStringSubmatch(strings.TrimSpace(kernelVersion))
+	if match == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return major > secureModeKernelMajor || (major == secureModeKernelMajor && minor >= secureModeKernelMinor)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportsSecureMode(t *testing.T) {
+	assert.False(t, supportsSecureMode("4.18.0-193.el8.x86_64"))
+	assert.False(t, supportsSecureMode("5.4.0-42-generic"))
+	assert.True(t, supportsSecureMode("5.11.0-1-amd64"))
+	assert.True(t, supportsSecureMode("6.1.12"))
+	assert.False(t, supportsSecureMode(""))
+}
+
+func TestValidateConnections(t *testing.T) {
+	context := &clusterd.Context{Clientset: testop.New(t, 2)}
+	networkSpec := cephv1.NetworkSpec{}
+	assert.NoError(t, validateConnections(context, networkSpec, cephver.Octopus))
+
+	// the nodes with an old kernel are only reported
+	networkSpec.Connections = &cephv1.ConnectionsSpec{Encryption: &cephv1.EncryptionSpec{Enabled: true}}
+	assert.NoError(t, validateConnections(context, networkSpec, cephver.Octopus))
+
+	// the compression requires quincy
+	networkSpec.Connections.Compression = &cephv1.CompressionSpec{Enabled: true}
+	assert.Error(t, validateConnections(context, networkSpec, cephver.Octopus))
+	assert.NoError(t, validateConnections(context, networkSpec, cephver.CephVersion{Major: 17}))
+}
+
+func TestValidateIPFamily(t *testing.T) {
+	networkSpec := cephv1.NetworkSpec{}
+	assert.NoError(t, validateIPFamily(networkSpec, cephver.Octopus))
+	networkSpec.IPFamily = cephv1.IPv6
+	assert.NoError(t, validateIPFamily(networkSpec, cephver.Octopus))
+	networkSpec.IPFamily = "IPv5"
+	assert.Error(t, validateIPFamily(networkSpec, cephver.Octopus))
+
+	// the dual stack requires pacific
+	networkSpec.IPFamily = cephv1.IPv4
+	networkSpec.DualStack = true
+	assert.Error(t, validateIPFamily(networkSpec, cephver.Octopus))
+	assert.NoError(t, validateIPFamily(networkSpec, cephver.CephVersion{Major: 16}))
+}
@@ -29,7 +29,8 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/agent/flexvolume/attachment"
-	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/csi"
@@ -42,6 +43,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -72,6 +74,8 @@ var (
 	finalizerName = fmt.Sprintf("%s.%s", opcontroller.ClusterResource.Name, opcontroller.ClusterResource.Group)
 	// disallowedHostDirectories directories which are not allowed to be used
 	disallowedHostDirectories = []string{"/etc/ceph", "/rook", "/var/log/ceph"}
+	// the rejected clusters are checked again in case the cluster owning their namespace is deleted
+	namespaceConflictRequeue = reconcile.Result{Requeue: true, RequeueAfter: time.Minute}
 )
 
 // List of object resources to watch by the controller
@@ -94,18 +98,19 @@ type ClusterController struct {
 	volumeAttachment        attachment.Attachment
 	rookImage               string
 	clusterMap              map[string]*cluster
+	clusterMapMutex         sync.Mutex
 	operatorConfigCallbacks []func() error
 	addClusterCallbacks     []func() error
 	csiConfigMutex          *sync.Mutex
 	nodeStore               cache.Store
-	osdChecker              *osd.OSDHealthMonitor
 	client                  client.Client
-	namespacedName          types.NamespacedName
+	recorder                record.EventRecorder
 }
 
 // ReconcileCephCluster reconciles a CephFilesystem object
 type ReconcileCephCluster struct {
 	client            client.Client
+	recorder          record.EventRecorder
 	scheme            *runtime.Scheme
 	context           *clusterd.Context
 	clusterController *ClusterController
@@ -125,8 +130,19 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context, clusterContro
 		panic(err)
 	}
 
+	// the events of the cluster health and the upgrades are emitted by the cluster controller
+	recorder := mgr.GetEventRecorderFor(controllerName)
+	clusterController.recorder = recorder
+
+	// Pass the client context to the ClusterController, before the reconciles of the clusters which may run in
+	// parallel. It is also used by functions not part of the ClusterController struct but are given the context to
+	// execute actions, like the spec code creating the deployments and services.
+	clusterController.client = mgr.GetClient()
+	clusterController.context.Client = mgr.GetClient()
+
 	return &ReconcileCephCluster{
 		client:            mgr.GetClient(),
+		recorder:          recorder,
 		scheme:            mgrScheme,
 		context:           context,
 		clusterController: clusterController,
@@ -135,7 +151,7 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context, clusterContro
 
 func add(mgr manager.Manager, r reconcile.Reconciler, context *clusterd.Context) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -164,7 +180,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler, context *clusterd.Context)
 				IsController: true,
 				OwnerType:    &cephv1.CephCluster{},
 			},
-			opcontroller.WatchPredicateForNonCRDObject(&cephv1.CephCluster{TypeMeta: ControllerTypeMeta}, mgr.GetScheme()))
+			opcontroller.WatchPredicateForNonCRDObject(&cephv1.CephCluster{TypeMeta: ControllerTypeMeta}, mgr.GetScheme(), detectVersionName))
 		if err != nil {
 			return err
 		}
@@ -225,7 +241,10 @@ func add(mgr manager.Manager, r reconcile.Reconciler, context *clusterd.Context)
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileCephCluster) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
+	start := time.Now()
 	reconcileResponse, err := r.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephCluster{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephCluster{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile. %v", err)
 	}
@@ -234,15 +253,6 @@ func (r *ReconcileCephCluster) Reconcile(request reconcile.Request) (reconcile.R
 }
 
 func (r *ReconcileCephCluster) reconcile(request reconcile.Request) (reconcile.Result, error) {
-	// Pass the client context to the ClusterController
-	r.clusterController.client = r.client
-
-	// Used by functions not part of the ClusterController struct but are given the context to execute actions
-	r.clusterController.context.Client = r.client
-
-	// Pass object name and namespace
-	r.clusterController.namespacedName = request.NamespacedName
-
 	// Fetch the cephCluster instance
 	cephCluster := &cephv1.CephCluster{}
 	err := r.client.Get(context.TODO(), request.NamespacedName, cephCluster)
@@ -255,6 +265,15 @@ func (r *ReconcileCephCluster) reconcile(request reconcile.Request) (reconcile.R
 		return reconcile.Result{}, errors.Wrap(err, "failed to get cephCluster")
 	}
 
+	// Only one CephCluster is supported in a namespace, the other clusters are rejected
+	rejected, err := r.rejectNamespaceConflict(cephCluster)
+	if err != nil || rejected {
+		return namespaceConflictRequeue, err
+	}
+	if err := k8sutil.SetInheritedMetadata(cephCluster.Namespace, cephCluster.Spec.InheritedMetadata.Labels, cephCluster.Spec.InheritedMetadata.Annotations); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to set the inherited metadata of cluster %q", cephCluster.Name)
+	}
+
 	// Set a finalizer so we can do cleanup before the object goes away
 	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephCluster)
 	if err != nil {
@@ -296,6 +315,9 @@ func (r *ReconcileCephCluster) reconcile(request reconcile.Request) (reconcile.R
 		if err != nil {
 			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalize")
 		}
+		config.ForgetConditions(request.NamespacedName)
+		config.ForgetGeneratedOptions(request.Namespace)
+		k8sutil.ForgetInheritedMetadata(request.Namespace)
 
 		// Return and do not requeue. Successful deletion.
 		return reconcile.Result{}, nil
@@ -308,12 +330,13 @@ func (r *ReconcileCephCluster) reconcile(request reconcile.Request) (reconcile.R
 	}
 
 	// Do reconcile here!
-	if err := r.clusterController.onAdd(cephCluster, ref); err != nil {
+	result, err := r.clusterController.onAdd(cephCluster, ref)
+	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "failed to reconcile cluster %q", cephCluster.Name)
 	}
 
-	// Return and do not requeue
-	return reconcile.Result{}, nil
+	// Return and only requeue for the work spread over several reconciles
+	return result, nil
 }
 
 // NewClusterController create controller for watching cluster custom resources created
@@ -325,27 +348,32 @@ func NewClusterController(context *clusterd.Context, rookImage string, volumeAtt
 		clusterMap:              make(map[string]*cluster),
 		operatorConfigCallbacks: operatorConfigCallbacks,
 		addClusterCallbacks:     addClusterCallbacks,
-		csiConfigMutex:          &sync.Mutex{},
+		csiConfigMutex:          csi.ConfigMutex,
 	}
 }
 
-func (c *ClusterController) onAdd(clusterObj *cephv1.CephCluster, ref *metav1.OwnerReference) error {
+func (c *ClusterController) onAdd(clusterObj *cephv1.CephCluster, ref *metav1.OwnerReference) (reconcile.Result, error) {
 	if clusterObj.Spec.CleanupPolicy.HasDataDirCleanPolicy() {
 		logger.Infof("skipping orchestration for cluster object %q in namespace %q because its cleanup policy is set", clusterObj.Name, clusterObj.Namespace)
-		return nil
+		return reconcile.Result{}, nil
 	}
 
+	c.clusterMapMutex.Lock()
 	cluster, ok := c.clusterMap[clusterObj.Namespace]
+	c.clusterMapMutex.Unlock()
 	if !ok {
 		// It's a new cluster so let's populate the struct
 		cluster = newCluster(clusterObj, c.context, c.csiConfigMutex, ref)
+		cluster.recorder = c.recorder
 	}
 
 	// Note that this lock is held through the callback process, as this creates CSI resources, but we must lock in
 	// this scope as the clusterMap is authoritative on cluster count and thus involved in the check for CSI resource
 	// deletion. If we ever add additional callback functions, we should tighten this lock.
 	c.csiConfigMutex.Lock()
+	c.clusterMapMutex.Lock()
 	c.clusterMap[cluster.Namespace] = cluster
+	c.clusterMapMutex.Unlock()
 	logger.Infof("reconciling ceph cluster in namespace %q", cluster.Namespace)
 
 	for _, callback := range c.addClusterCallbacks {
@@ -356,21 +384,30 @@ func (c *ClusterController) onAdd(clusterObj *cephv1.CephCluster, ref *metav1.Ow
 	c.csiConfigMutex.Unlock()
 
 	// Start the main ceph cluster orchestration
-	return c.initializeCluster(cluster, clusterObj)
+	cluster.requeueAfter = 0
+	if err := c.initializeCluster(cluster, clusterObj); err != nil {
+		return reconcile.Result{}, err
+	}
+	if cluster.requeueAfter > 0 {
+		return reconcile.Result{Requeue: true, RequeueAfter: cluster.requeueAfter}, nil
+	}
+	return reconcile.Result{}, nil
 }
 
 func (c *ClusterController) requestClusterDelete(cluster *cephv1.CephCluster) (reconcile.Result, bool) {
-	config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionDeleting, v1.ConditionTrue, "ClusterDeleting", "Cluster is deleting")
+	namespacedName := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	config.ConditionExport(c.context, namespacedName, cephv1.ConditionDeleting, v1.ConditionTrue, "ClusterDeleting", "Cluster is deleting")
 
-	if existing, ok := c.clusterMap[cluster.Namespace]; ok && existing.crdName != cluster.Name {
+	if existing, ok := c.getCluster(cluster.Namespace); ok && existing.crdName != cluster.Name {
 		logger.Errorf("skipping deletion of cluster cr %q in namespace %q. cluster CR %q already exists in this namespace. only one cluster cr per namespace is supported.",
 			cluster.Name, cluster.Namespace, existing.crdName)
 		return reconcile.Result{}, true
 	}
 
 	logger.Infof("delete event for cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	opcontroller.ClearDeprecatedFields(cephClusterKind, cluster.Namespace, cluster.Name)
 
-	if cluster, ok := c.clusterMap[cluster.Namespace]; ok {
+	if cluster, ok := c.getCluster(cluster.Namespace); ok {
 		// if not already stopped, stop clientcontroller and bucketController
 		if !cluster.closedStopCh {
 			close(cluster.stopCh)
@@ -391,14 +428,16 @@ func (c *ClusterController) requestClusterDelete(cluster *cephv1.CephCluster) (r
 
 	err := c.checkIfVolumesExist(cluster)
 	if err != nil {
-		config.ConditionExport(c.context, c.namespacedName, cephv1.ConditionDeleting, v1.ConditionTrue, "ClusterDeleting", "Failed to delete cluster")
+		config.ConditionExport(c.context, namespacedName, cephv1.ConditionDeleting, v1.ConditionTrue, "ClusterDeleting", "Failed to delete cluster")
 		logger.Errorf("cannot delete cluster. %v", err)
 		return opcontroller.WaitForRequeueIfFinalizerBlocked, false
 	}
 
-	if cluster, ok := c.clusterMap[cluster.Namespace]; ok {
-		delete(c.clusterMap, cluster.Namespace)
-	}
+	c.clusterMapMutex.Lock()
+	delete(c.clusterMap, cluster.Namespace)
+	c.clusterMapMutex.Unlock()
+	mon.InvalidateClusterInfo(cluster.Namespace)
+	cephclient.CloseCommandConns(cluster.Namespace)
 
 	// Only valid when the cluster is not external
 	if cluster.Spec.External.Enable {
@@ -515,6 +554,32 @@ func updateStatus(client client.Client, name types.NamespacedName, status cephv1
 	logger.Debugf("ceph cluster %q status updated to %q", name, status)
 }
 
+// rejectNamespaceConflict rejects the cluster when another CephCluster owns its namespace, since the resources of two
+// clusters in a namespace would have the same names. The rejected cluster is deleted without touching the resources
+// of the namespace. It returns whether the cluster was rejected.
+func (r *ReconcileCephCluster) rejectNamespaceConflict(cephCluster *cephv1.CephCluster) (bool, error) {
+	clusters := &cephv1.CephClusterList{}
+	if err := r.client.List(context.TODO(), clusters, client.InNamespace(cephCluster.Namespace)); err != nil {
+		return false, errors.Wrapf(err, "failed to list the ceph clusters in namespace %q", cephCluster.Namespace)
+	}
+	owner := opcontroller.NamespaceCephCluster(clusters.Items)
+	if owner == nil || owner.Name == cephCluster.Name {
+		return false, nil
+	}
+
+	namespacedName := types.NamespacedName{Namespace: cephCluster.Namespace, Name: cephCluster.Name}
+	if !cephCluster.GetDeletionTimestamp().IsZero() {
+		logger.Infof("deleting rejected ceph cluster %q, the resources of namespace %q belong to cluster %q", cephCluster.Name, cephCluster.Namespace, owner.Name)
+		config.ForgetConditions(namespacedName)
+		return true, removeFinalizer(r.client, namespacedName)
+	}
+
+	message := fmt.Sprintf("cluster %q already exists in namespace %q, only one CephCluster is supported per namespace", owner.Name, owner.Namespace)
+	logger.Errorf("rejecting ceph cluster %q. %s", cephCluster.Name, message)
+	config.ConditionExport(r.clusterController.context, namespacedName, cephv1.ConditionFailure, v1.ConditionTrue, "NamespaceConflict", message)
+	return true, nil
+}
+
 // removeFinalizer removes a finalizer
 func removeFinalizer(client client.Client, name types.NamespacedName) error {
 	cephCluster := &cephv1.CephCluster{}
@@ -534,3 +599,11 @@ func removeFinalizer(client client.Client, name types.NamespacedName) error {
 
 	return nil
 }
+
+// getCluster returns the cluster of a namespace, the clusters being reconciled in parallel
+func (c *ClusterController) getCluster(namespace string) (*cluster, bool) {
+	c.clusterMapMutex.Lock()
+	defer c.clusterMapMutex.Unlock()
+	cluster, ok := c.clusterMap[namespace]
+	return cluster, ok
+}
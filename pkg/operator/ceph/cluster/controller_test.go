@@ -17,8 +17,10 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	rookalpha "github.com/rook/rook/pkg/apis/rook.io/v1alpha2"
@@ -27,7 +29,11 @@ import (
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	testop "github.com/rook/rook/pkg/operator/test"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestClusterDeleteFlexEnabled(t *testing.T) {
@@ -140,3 +146,44 @@ func TestClusterDeleteFlexDisabled(t *testing.T) {
 	// Ensure that the listing of volume attachments was never called.
 	assert.Equal(t, 0, listCount)
 }
+
+func TestRejectNamespaceConflict(t *testing.T) {
+	namespace := "rook-ceph"
+	first := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}}
+	second := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now())}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{}, &cephv1.CephClusterList{})
+	cl := fake.NewFakeClientWithScheme(s, first, second)
+	r := &ReconcileCephCluster{client: cl, clusterController: &ClusterController{context: &clusterd.Context{Client: cl}}}
+
+	// the oldest cluster owns the namespace
+	rejected, err := r.rejectNamespaceConflict(first)
+	assert.NoError(t, err)
+	assert.False(t, rejected)
+
+	rejected, err = r.rejectNamespaceConflict(second)
+	assert.NoError(t, err)
+	assert.True(t, rejected)
+	updated := &cephv1.CephCluster{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "second"}, updated))
+	assert.Equal(t, cephv1.ConditionFailure, updated.Status.Phase)
+	assert.Equal(t, "NamespaceConflict", updated.Status.Conditions[0].Reason)
+	assert.Equal(t, v1.ConditionTrue, updated.Status.Conditions[0].Status)
+
+	// the conditions of the owner are not changed by the rejected cluster
+	owner := &cephv1.CephCluster{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "first"}, owner))
+	assert.Empty(t, owner.Status.Conditions)
+
+	// the rejected cluster is deleted without the cleanup of the namespace
+	deletionTime := metav1.Now()
+	updated.DeletionTimestamp = &deletionTime
+	updated.Finalizers = []string{finalizerName}
+	assert.NoError(t, cl.Update(context.TODO(), updated))
+	rejected, err = r.rejectNamespaceConflict(updated)
+	assert.NoError(t, err)
+	assert.True(t, rejected)
+	deleted := &cephv1.CephCluster{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "second"}, deleted))
+	assert.Empty(t, deleted.Finalizers)
+}
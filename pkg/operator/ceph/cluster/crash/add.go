@@ -22,6 +22,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/exporter"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -59,7 +61,7 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return errors.Wrapf(err, "failed to create a new %q", controllerName)
 	}
@@ -85,8 +87,8 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return errors.Wrap(err, "failed to watch for node changes")
 	}
 
-	// Watch for changes to the ceph-crash deployments
-	logger.Debugf("watch for changes to the ceph-crash deployments")
+	// Watch for changes to the ceph-crash and ceph-exporter deployments
+	logger.Debugf("watch for changes to the ceph-crash and ceph-exporter deployments")
 	err = c.Watch(
 		&source.Kind{Type: &appsv1.Deployment{}},
 		&handler.EnqueueRequestsFromMapFunc{
@@ -97,7 +99,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 				}
 				labels := deployment.GetLabels()
 				appName, ok := labels[k8sutil.AppAttr]
-				if !ok || appName != AppName {
+				if !ok || (appName != AppName && appName != exporter.AppName) {
 					return []reconcile.Request{}
 				}
 				nodeName, ok := deployment.Spec.Template.ObjectMeta.Labels[NodeNameLabel]
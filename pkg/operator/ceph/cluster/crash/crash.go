@@ -113,13 +113,21 @@ func (r *ReconcileNode) createOrUpdateCephCrash(node corev1.Node, tolerations []
 				Containers: []corev1.Container{
 					getCrashDaemonContainer(cephCluster, *cephVersion),
 				},
-				Tolerations:   tolerations,
-				RestartPolicy: corev1.RestartPolicyAlways,
-				HostNetwork:   cephCluster.Spec.Network.IsHost(),
-				Volumes:       volumes,
+				Tolerations:       tolerations,
+				RestartPolicy:     corev1.RestartPolicyAlways,
+				HostNetwork:       cephCluster.Spec.Network.IsHost(),
+				Volumes:           volumes,
+				PriorityClassName: cephv1.GetCrashCollectorPriorityClassName(cephCluster.Spec.PriorityClassNames),
+				SecurityContext:   cephv1.GetPodSecurityContext(cephCluster.Spec.Security.PodSecurityContexts, cephv1.KeyCrashCollector),
 			},
 		}
+		if cephCluster.Spec.Network.IsMultus() {
+			if err := k8sutil.ApplyMultus(cephCluster.Spec.Network.NetworkSpec, &deploy.Spec.Template.ObjectMeta); err != nil {
+				return err
+			}
+		}
 
+		k8sutil.ApplyInheritedMetadataToPodTemplate(deploy.Namespace, &deploy.ObjectMeta, &deploy.Spec.Template)
 		return nil
 	}
 
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crash
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// PrunerName is the name of the CronJob pruning the crash reports
+	PrunerName           = "rook-ceph-crashcollector-pruner"
+	defaultPruneSchedule = "0 0 * * *"
+)
+
+// the ids of the crash reports start with their time, "ceph crash ls" lists them from the oldest one
+var pruneScript = `
+set -o errexit
+set -o pipefail
+
+if [ "$ROOK_CRASH_ARCHIVE_AFTER" -gt 0 ]; then
+  now=$(date +%s)
+  for id in $(ceph crash ls-new | awk 'NR > 1 {print $1}'); do
+    if [ $((now - $(date -d "${id%%_*}" +%s))) -ge "$ROOK_CRASH_ARCHIVE_AFTER" ]; then
+      echo "archiving crash report $id"
+      ceph crash archive "$id"
+    fi
+  done
+fi
+
+if [ "$ROOK_CRASH_DAYS_TO_RETAIN" -gt 0 ]; then
+  echo "pruning the crash reports older than $ROOK_CRASH_DAYS_TO_RETAIN days"
+  ceph crash prune "$ROOK_CRASH_DAYS_TO_RETAIN"
+fi
+
+ids=$(ceph crash ls | awk 'NR > 1 {print $1}')
+if [ "$ROOK_CRASH_MAX_COUNT" -gt 0 ]; then
+  for id in $(echo "$ids" | head -n -"$ROOK_CRASH_MAX_COUNT"); do
+    echo "removing crash report $id beyond the $ROOK_CRASH_MAX_COUNT most recent ones"
+    ceph crash rm "$id"
+  done
+  ids=$(echo "$ids" | tail -n "$ROOK_CRASH_MAX_COUNT")
+fi
+
+if [ "$ROOK_CRASH_MAX_SIZE" -gt 0 ]; then
+  size=0
+  for id in $(echo "$ids" | tac); do
+    size=$((size + $(ceph crash info "$id" | wc -c)))
+    if [ "$size" -gt "$ROOK_CRASH_MAX_SIZE" ]; then
+      echo "removing crash report $id beyond $ROOK_CRASH_MAX_SIZE bytes of crash reports"
+      ceph crash rm "$id"
+    fi
+  done
+fi
+`
+
+// IsPruningEnabled returns whether the crash reports are pruned or archived by the pruner CronJob
+func IsPruningEnabled(spec *cephv1.ClusterSpec) bool {
+	crashSpec := spec.CrashCollector
+	if crashSpec.Disable || spec.External.Enable {
+		return false
+	}
+	return crashSpec.DaysToRetain > 0 || crashSpec.MaxCount > 0 || crashSpec.MaxSize != "" || spec.HealthCheck.DaemonHealth.Crash.ArchiveAfter != ""
+}
+
+// reconcilePruner creates or updates the CronJob pruning the crash reports, or deletes it when no pruning is configured
+func (r *ReconcileNode) reconcilePruner(cephCluster cephv1.CephCluster) error {
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PrunerName,
+			Namespace: cephCluster.GetNamespace(),
+		},
+	}
+	if !IsPruningEnabled(&cephCluster.Spec) || cephCluster.Spec.Security.RestrictAdminKey {
+		if IsPruningEnabled(&cephCluster.Spec) {
+			logger.Warningf("the crash reports are not pruned since the pruner needs the admin key which is restricted")
+		}
+		err := r.client.Delete(context.TODO(), cronJob)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete crash pruner cronjob %q", PrunerName)
+		}
+		return nil
+	}
+
+	mutateFunc := func() error {
+		labels := controller.AppLabels(PrunerName, cephCluster.GetNamespace())
+		deadlineSeconds := int64(60)
+		backoffLimit := int32(3)
+		schedule := cephCluster.Spec.CrashCollector.Schedule
+		if schedule == "" {
+			schedule = defaultPruneSchedule
+		}
+
+		cronJob.ObjectMeta.Labels = labels
+		cronJob.ObjectMeta.OwnerReferences = []metav1.OwnerReference{clusterOwnerRef(cephCluster.GetName(), string(cephCluster.GetUID()))}
+		cronJob.Spec = batchv1beta1.CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			// a pruning which could not start in time is skipped until the next one
+			StartingDeadlineSeconds: &deadlineSeconds,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							Containers:        []corev1.Container{getCrashPrunerContainer(cephCluster)},
+							RestartPolicy:     corev1.RestartPolicyNever,
+							HostNetwork:       cephCluster.Spec.Network.IsHost(),
+							PriorityClassName: cephv1.GetCrashCollectorPriorityClassName(cephCluster.Spec.PriorityClassNames),
+							SecurityContext:   cephv1.GetPodSecurityContext(cephCluster.Spec.Security.PodSecurityContexts, cephv1.KeyCrashCollector),
+							Volumes: append(
+								controller.DaemonVolumesBase(config.NewDatalessDaemonDataPathMap(cephCluster.GetNamespace(), cephCluster.Spec.DataDirHostPath), ""),
+								keyring.Volume().Admin()),
+						},
+					},
+				},
+			},
+		}
+		k8sutil.ApplyClusterInheritedMetadata(cephCluster.Spec.InheritedMetadata.Labels, cephCluster.Spec.InheritedMetadata.Annotations,
+			&cronJob.ObjectMeta, &cronJob.Spec.JobTemplate.ObjectMeta, &cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta)
+		if cephCluster.Spec.Network.IsMultus() {
+			if err := k8sutil.ApplyMultus(cephCluster.Spec.Network.NetworkSpec, &cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	op, err := controllerutil.CreateOrUpdate(context.TODO(), r.client, cronJob, mutateFunc)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reconcile crash pruner cronjob %q", PrunerName)
+	}
+	logger.Debugf("crash pruner cronjob successfully reconciled. operation: %q", op)
+	return nil
+}
+
+func getCrashPrunerContainer(cephCluster cephv1.CephCluster) corev1.Container {
+	cephImage := cephCluster.Spec.CephVersion.Image
+	crashSpec := cephCluster.Spec.CrashCollector
+	dataPathMap := config.NewDatalessDaemonDataPathMap(cephCluster.GetNamespace(), cephCluster.Spec.DataDirHostPath)
+
+	maxSize := int64(0)
+	if crashSpec.MaxSize != "" {
+		if quantity, err := resource.ParseQuantity(crashSpec.MaxSize); err == nil {
+			maxSize = quantity.Value()
+		} else {
+			logger.Errorf("invalid crash reports max size %q, the crash reports are not pruned by size. %v", crashSpec.MaxSize, err)
+		}
+	}
+	archiveAfter := int64(0)
+	if value := cephCluster.Spec.HealthCheck.DaemonHealth.Crash.ArchiveAfter; value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			archiveAfter = int64(duration.Seconds())
+		}
+	}
+
+	envVars := append(controller.DaemonEnvVars(cephImage),
+		corev1.EnvVar{Name: "CEPH_ARGS", Value: fmt.Sprintf("-m $(ROOK_CEPH_MON_HOST) -k %s", keyring.VolumeMount().AdminKeyringFilePath())},
+		corev1.EnvVar{Name: "ROOK_CRASH_ARCHIVE_AFTER", Value: strconv.FormatInt(archiveAfter, 10)},
+		corev1.EnvVar{Name: "ROOK_CRASH_DAYS_TO_RETAIN", Value: strconv.FormatUint(uint64(crashSpec.DaysToRetain), 10)},
+		corev1.EnvVar{Name: "ROOK_CRASH_MAX_COUNT", Value: strconv.FormatUint(uint64(crashSpec.MaxCount), 10)},
+		corev1.EnvVar{Name: "ROOK_CRASH_MAX_SIZE", Value: strconv.FormatInt(maxSize, 10)},
+	)
+
+	return corev1.Container{
+		Name:            "ceph-crash-pruner",
+		Command:         []string{"/bin/bash", "-c", pruneScript},
+		Image:           cephImage,
+		Env:             envVars,
+		VolumeMounts:    append(controller.DaemonVolumeMounts(dataPathMap, ""), keyring.VolumeMount().Admin()),
+		Resources:       cephv1.GetCrashCollectorResources(cephCluster.Spec.Resources),
+		SecurityContext: mon.PodSecurityContext(),
+	}
+}
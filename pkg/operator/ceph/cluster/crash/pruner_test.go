@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crash
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIsPruningEnabled(t *testing.T) {
+	spec := &cephv1.ClusterSpec{}
+	assert.False(t, IsPruningEnabled(spec))
+
+	spec.CrashCollector.DaysToRetain = 7
+	assert.True(t, IsPruningEnabled(spec))
+	spec.CrashCollector.DaysToRetain = 0
+	spec.HealthCheck.DaemonHealth.Crash.ArchiveAfter = "24h"
+	assert.True(t, IsPruningEnabled(spec))
+
+	// no pruning when the crash collector is disabled or for external clusters
+	spec.CrashCollector.Disable = true
+	assert.False(t, IsPruningEnabled(spec))
+	spec.CrashCollector.Disable = false
+	spec.External.Enable = true
+	assert.False(t, IsPruningEnabled(spec))
+}
+
+func TestCrashPrunerContainer(t *testing.T) {
+	cephCluster := cephv1.CephCluster{
+		Spec: cephv1.ClusterSpec{
+			CrashCollector: cephv1.CrashCollectorSpec{DaysToRetain: 7, MaxCount: 20, MaxSize: "1Mi"},
+			HealthCheck:    cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Crash: cephv1.CrashHealthCheckSpec{ArchiveAfter: "1h"}}},
+		},
+	}
+	cephCluster.Spec.CephVersion.Image = "ceph/ceph:v15"
+	container := getCrashPrunerContainer(cephCluster)
+	assert.Equal(t, "ceph/ceph:v15", container.Image)
+
+	env := map[string]string{}
+	for _, envVar := range container.Env {
+		env[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "-m $(ROOK_CEPH_MON_HOST) -k /etc/ceph/admin-keyring-store/keyring", env["CEPH_ARGS"])
+	assert.Equal(t, "3600", env["ROOK_CRASH_ARCHIVE_AFTER"])
+	assert.Equal(t, "7", env["ROOK_CRASH_DAYS_TO_RETAIN"])
+	assert.Equal(t, "20", env["ROOK_CRASH_MAX_COUNT"])
+	assert.Equal(t, "1048576", env["ROOK_CRASH_MAX_SIZE"])
+
+	// an invalid size does not prune by size
+	cephCluster.Spec.CrashCollector.MaxSize = "big"
+	container = getCrashPrunerContainer(cephCluster)
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "ROOK_CRASH_MAX_SIZE", Value: "0"})
+}
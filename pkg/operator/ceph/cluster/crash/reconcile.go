@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/exporter"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/rbd"
@@ -41,6 +42,7 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
+	"github.com/rook/rook/pkg/operator/ceph/version"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -94,6 +96,9 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 			}
 			logger.Infof("crash collector deployment %q successfully removed", d.Name)
 		}
+		if err := exporter.DeleteDeployments(r.client, request.Namespace, map[string]string{exporter.NodeNameLabel: request.Name}); err != nil {
+			logger.Errorf("failed to delete ceph-exporter deployments of deleted node %q, delete them manually. %v", request.Name, err)
+		}
 		return reconcile.Result{}, errors.Errorf("could not get node %q", request.NamespacedName)
 	}
 
@@ -131,9 +136,9 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 			return reconcile.Result{}, nil
 		}
 
-		cephCluster := cephClusters.Items[0]
+		cephCluster := *opcontroller.NamespaceCephCluster(cephClusters.Items)
 		if len(cephClusters.Items) > 1 {
-			logger.Errorf("more than one CephCluster found in the namespace %q, choosing the first one %q", namespace, cephCluster.GetName())
+			logger.Errorf("more than one CephCluster found in the namespace %q, choosing the oldest one %q", namespace, cephCluster.GetName())
 		}
 
 		// If the crash controller is disabled in the spec let's do a noop
@@ -157,8 +162,6 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 				}
 				logger.Infof("crash collector deployment %q successfully removed", d.Name)
 			}
-
-			return reconcile.Result{}, nil
 		}
 
 		clusterImage := cephCluster.Spec.CephVersion.Image
@@ -181,7 +184,7 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 			}
 		}
 
-		if hasCephPods {
+		if hasCephPods && !cephCluster.Spec.CrashCollector.Disable {
 			tolerations := uniqueTolerations.ToList()
 			op, err := r.createOrUpdateCephCrash(*node, tolerations, cephCluster, cephVersion)
 			if err != nil {
@@ -189,11 +192,41 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 			}
 			logger.Debugf("deployment successfully reconciled for node %q. operation: %q", request.Name, op)
 		}
+
+		// the ceph-exporter daemons run alongside the crash collectors on the nodes running ceph daemons
+		if err := r.reconcileExporter(*node, hasCephPods, uniqueTolerations.ToList(), cephCluster, *cephVersion); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		// the crash reports of the whole cluster are pruned by a single cronjob
+		if err := r.reconcilePruner(cephCluster); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
 	return reconcile.Result{}, nil
 }
 
+func (r *ReconcileNode) reconcileExporter(node corev1.Node, hasCephPods bool, tolerations []corev1.Toleration, cephCluster cephv1.CephCluster, cephVersion version.CephVersion) error {
+	if !exporter.IsEnabled(&cephCluster.Spec, cephVersion) {
+		if err := exporter.DeleteDeployments(r.client, cephCluster.GetNamespace(), nil); err != nil {
+			logger.Errorf("failed to delete ceph-exporter deployments, delete them manually. %v", err)
+		}
+		return nil
+	}
+	if !hasCephPods {
+		return nil
+	}
+
+	ownerRef := clusterOwnerRef(cephCluster.GetName(), string(cephCluster.GetUID()))
+	op, err := exporter.CreateOrUpdateDeployment(r.client, node, tolerations, cephCluster, cephVersion, ownerRef)
+	if err != nil {
+		return errors.Wrapf(err, "ceph-exporter reconcile failed on op %q", op)
+	}
+	logger.Debugf("ceph-exporter deployment successfully reconciled for node %q. operation: %q", node.GetName(), op)
+	return nil
+}
+
 func (r *ReconcileNode) cephPodList() ([]corev1.Pod, error) {
 	cephPods := make([]corev1.Pod, 0)
 	cephAppNames := []string{mon.AppName, mgr.AppName, osd.AppName, object.AppName, mds.AppName, rbd.AppName}
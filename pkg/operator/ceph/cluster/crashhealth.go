@@ -0,0 +1,297 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/crash"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// defaultCrashCheckInterval is the interval to check the crash reports and the mgr modules
+	defaultCrashCheckInterval = 60 * time.Second
+)
+
+// crashChecker reports the new crash reports of the daemons and the failed mgr modules with events and the
+// Degraded condition of the cluster, and archives the crash reports older than the retention
+type crashChecker struct {
+	context      *clusterd.Context
+	clusterInfo  *cephclient.ClusterInfo
+	interval     time.Duration
+	archiveAfter time.Duration
+	recorder     record.EventRecorder
+	// the crash reports and the failures of the modules which were already reported with an event
+	reportedCrashes map[string]bool
+	failedModules   map[string]string
+	// the message of the condition, which is only updated when the message changes
+	conditionMessage *string
+	// the status of the crash pruner cronjob, which is only updated when the status changes
+	pruningStatus *cephv1.CrashPruningStatus
+	pruningKnown  bool
+}
+
+// newCrashChecker creates a checker of the crash reports and of the mgr modules
+func newCrashChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, recorder record.EventRecorder) *crashChecker {
+	c := &crashChecker{
+		context:         context,
+		clusterInfo:     clusterInfo,
+		interval:        defaultCrashCheckInterval,
+		recorder:        recorder,
+		reportedCrashes: map[string]bool{},
+		failedModules:   map[string]string{},
+	}
+
+	crashSpec := clusterSpec.HealthCheck.DaemonHealth.Crash
+	if crashSpec.Interval != "" {
+		if duration, err := time.ParseDuration(crashSpec.Interval); err == nil {
+			logger.Infof("ceph crash check interval is %s", crashSpec.Interval)
+			c.interval = duration
+		} else {
+			logger.Warningf("invalid crash check interval %q, using the default interval. %v", crashSpec.Interval, err)
+		}
+	}
+	if crashSpec.ArchiveAfter != "" {
+		if duration, err := time.ParseDuration(crashSpec.ArchiveAfter); err == nil {
+			c.archiveAfter = duration
+		} else {
+			logger.Warningf("invalid crash archive retention %q, the crash reports are not archived. %v", crashSpec.ArchiveAfter, err)
+		}
+	}
+
+	return c
+}
+
+// checkCrashes periodically checks the crash reports and the mgr modules
+func (c *crashChecker) checkCrashes(stopCh chan struct{}) {
+	// check immediately before starting the loop
+	c.check()
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping monitoring of ceph crash reports")
+			return
+
+		case <-time.After(c.interval):
+			c.check()
+		}
+	}
+}
+
+func (c *crashChecker) check() {
+	crashes, err := cephclient.ListNewCrashes(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to check the crash reports. %v", err)
+		return
+	}
+	crashes = c.archiveOldCrashes(crashes, time.Now())
+	c.reportCrashes(crashes)
+
+	modules, err := cephclient.FailedMgrModules(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to check the mgr modules. %v", err)
+		return
+	}
+	c.reportFailedModules(modules)
+
+	c.updateCondition(degradedMessage(crashes, modules))
+
+	if err := c.updatePruningStatus(); err != nil {
+		logger.Warningf("failed to update the crash pruning status. %v", err)
+	}
+}
+
+// archiveOldCrashes archives the crash reports older than the retention and returns the remaining new crash reports
+func (c *crashChecker) archiveOldCrashes(crashes []cephclient.CrashInfo, now time.Time) []cephclient.CrashInfo {
+	if c.archiveAfter == 0 {
+		return crashes
+	}
+	remaining := []cephclient.CrashInfo{}
+	for _, crash := range crashes {
+		crashTime, err := crash.Time()
+		if err != nil {
+			logger.Warningf("failed to check the age of the crash report. %v", err)
+			remaining = append(remaining, crash)
+			continue
+		}
+		if now.Sub(crashTime) < c.archiveAfter {
+			remaining = append(remaining, crash)
+			continue
+		}
+		if err := cephclient.ArchiveCrash(c.context, c.clusterInfo, crash.ID); err != nil {
+			logger.Warningf("failed to archive the crash report of %q. %v", crash.Entity, err)
+			remaining = append(remaining, crash)
+			continue
+		}
+		logger.Infof("archived the crash report %q of %q older than %s", crash.ID, crash.Entity, c.archiveAfter)
+	}
+	return remaining
+}
+
+// reportCrashes emits an event on the CephCluster for each new crash report
+func (c *crashChecker) reportCrashes(crashes []cephclient.CrashInfo) {
+	reported := map[string]bool{}
+	for _, crash := range crashes {
+		reported[crash.ID] = true
+		if c.reportedCrashes[crash.ID] {
+			continue
+		}
+		logger.Warningf("daemon %q crashed at %s. crash id %q", crash.Entity, crash.Timestamp, crash.ID)
+		opcontroller.RecordOwnerEvent(c.recorder, c.clusterInfo.Namespace, c.clusterInfo.OwnerRef, v1.EventTypeWarning, opcontroller.EventReasonDaemonCrashed,
+			"daemon %s crashed at %s. run \"ceph crash info %s\" for the details", crash.Entity, crash.Timestamp, crash.ID)
+	}
+	// the archived crash reports are forgotten
+	c.reportedCrashes = reported
+}
+
+// reportFailedModules emits an event on the CephCluster for each mgr module which failed since the last check
+func (c *crashChecker) reportFailedModules(modules map[string]string) {
+	for _, name := range sortedKeys(modules) {
+		if c.failedModules[name] == modules[name] {
+			continue
+		}
+		logger.Warningf("mgr module %q failed. %s", name, modules[name])
+		opcontroller.RecordOwnerEvent(c.recorder, c.clusterInfo.Namespace, c.clusterInfo.OwnerRef, v1.EventTypeWarning, opcontroller.EventReasonMgrModuleFailed,
+			"%s", modules[name])
+	}
+	c.failedModules = modules
+}
+
+// updateCondition sets the Degraded condition of the cluster when its message changes
+func (c *crashChecker) updateCondition(message string) {
+	if c.conditionMessage != nil && *c.conditionMessage == message {
+		return
+	}
+	c.conditionMessage = &message
+	if message == "" {
+		config.ConditionExport(c.context, c.clusterInfo.NamespacedName(), cephv1.ConditionDegraded, v1.ConditionFalse, "DaemonsHealthy", "No new crash reports and no failed mgr modules")
+		return
+	}
+	config.ConditionExport(c.context, c.clusterInfo.NamespacedName(), cephv1.ConditionDegraded, v1.ConditionTrue, "DaemonsDegraded", message)
+}
+
+// updatePruningStatus reflects the last runs of the crash pruner cronjob in the status of the cluster
+func (c *crashChecker) updatePruningStatus() error {
+	status, err := c.pruningJobsStatus()
+	if err != nil {
+		return err
+	}
+	if c.pruningKnown && reflect.DeepEqual(c.pruningStatus, status) {
+		return nil
+	}
+
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.context.Client.Get(context.TODO(), c.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrap(err, "failed to get the cluster")
+	}
+	cephCluster.Status.CrashPruning = status
+	if err := opcontroller.UpdateStatus(c.context.Client, cephCluster); err != nil {
+		return err
+	}
+	c.pruningStatus = status
+	c.pruningKnown = true
+	return nil
+}
+
+// pruningJobsStatus returns the status of the crash pruner cronjob and of its last jobs, it is nil when the crash
+// reports are not pruned
+func (c *crashChecker) pruningJobsStatus() (*cephv1.CrashPruningStatus, error) {
+	namespace := c.clusterInfo.Namespace
+	cronJob, err := c.context.Clientset.BatchV1beta1().CronJobs(namespace).Get(crash.PrunerName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get crash pruner cronjob %q", crash.PrunerName)
+	}
+	status := &cephv1.CrashPruningStatus{}
+	if cronJob.Status.LastScheduleTime != nil {
+		status.LastScheduleTime = cronJob.Status.LastScheduleTime.UTC().Format(time.RFC3339)
+	}
+
+	jobs, err := c.context.Clientset.BatchV1().Jobs(namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, crash.PrunerName)})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the crash pruner jobs")
+	}
+	var lastSuccess, lastFinished time.Time
+	for _, job := range jobs.Items {
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != v1.ConditionTrue || (condition.Type != batchv1.JobComplete && condition.Type != batchv1.JobFailed) {
+				continue
+			}
+			finished := condition.LastTransitionTime.Time
+			if condition.Type == batchv1.JobComplete && finished.After(lastSuccess) {
+				lastSuccess = finished
+			}
+			if finished.After(lastFinished) {
+				lastFinished = finished
+				status.LastFailed = condition.Type == batchv1.JobFailed
+			}
+		}
+	}
+	if !lastSuccess.IsZero() {
+		status.LastSuccessfulTime = lastSuccess.UTC().Format(time.RFC3339)
+	}
+	return status, nil
+}
+
+// degradedMessage describes the daemons with new crash reports and the failed mgr modules, it is empty when there are none
+func degradedMessage(crashes []cephclient.CrashInfo, modules map[string]string) string {
+	messages := []string{}
+	if len(crashes) > 0 {
+		entities := map[string]string{}
+		for _, crash := range crashes {
+			entities[crash.Entity] = crash.ID
+		}
+		messages = append(messages, fmt.Sprintf("%d new crash reports of daemons %v", len(crashes), sortedKeys(entities)))
+	}
+	if len(modules) > 0 {
+		messages = append(messages, fmt.Sprintf("failed mgr modules %v", sortedKeys(modules)))
+	}
+	return strings.Join(messages, ". ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
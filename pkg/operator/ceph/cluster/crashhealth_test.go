@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/crash"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNewCrashChecker(t *testing.T) {
+	spec := &cephv1.ClusterSpec{}
+	c := newCrashChecker(&clusterd.Context{}, cephclient.AdminClusterInfo("rook-ceph"), spec, nil)
+	assert.Equal(t, defaultCrashCheckInterval, c.interval)
+	assert.Equal(t, time.Duration(0), c.archiveAfter)
+
+	spec.HealthCheck.DaemonHealth.Crash = cephv1.CrashHealthCheckSpec{HealthCheckSpec: cephv1.HealthCheckSpec{Interval: "5m"}, ArchiveAfter: "168h"}
+	c = newCrashChecker(&clusterd.Context{}, cephclient.AdminClusterInfo("rook-ceph"), spec, nil)
+	assert.Equal(t, 5*time.Minute, c.interval)
+	assert.Equal(t, 168*time.Hour, c.archiveAfter)
+
+	// invalid durations are ignored
+	spec.HealthCheck.DaemonHealth.Crash.ArchiveAfter = "a week"
+	c = newCrashChecker(&clusterd.Context{}, cephclient.AdminClusterInfo("rook-ceph"), spec, nil)
+	assert.Equal(t, time.Duration(0), c.archiveAfter)
+}
+
+func TestArchiveOldCrashes(t *testing.T) {
+	archived := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfileArg string, args ...string) (string, error) {
+			if args[0] == "crash" && args[1] == "archive" {
+				archived = append(archived, args[2])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	c := newCrashChecker(&clusterd.Context{Executor: executor}, cephclient.AdminClusterInfo("rook-ceph"), &cephv1.ClusterSpec{}, nil)
+	now := time.Date(2020, 8, 10, 0, 0, 0, 0, time.UTC)
+	crashes := []cephclient.CrashInfo{
+		{ID: "old", Entity: "osd.1", Timestamp: "2020-08-01 10:00:00.000000Z"},
+		{ID: "recent", Entity: "osd.2", Timestamp: "2020-08-09T10:00:00.000000Z"},
+		{ID: "invalid", Entity: "mgr.a", Timestamp: "unknown"},
+	}
+
+	// the crashes are not archived without a retention
+	assert.Equal(t, crashes, c.archiveOldCrashes(crashes, now))
+	assert.Equal(t, 0, len(archived))
+
+	c.archiveAfter = 72 * time.Hour
+	remaining := c.archiveOldCrashes(crashes, now)
+	assert.Equal(t, []string{"old"}, archived)
+	assert.Equal(t, []cephclient.CrashInfo{crashes[1], crashes[2]}, remaining)
+}
+
+func TestReportCrashesAndModules(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.OwnerRef = metav1.OwnerReference{APIVersion: "ceph.rook.io/v1", Kind: "CephCluster", Name: "rook-ceph"}
+	c := newCrashChecker(&clusterd.Context{}, clusterInfo, &cephv1.ClusterSpec{}, recorder)
+
+	crashes := []cephclient.CrashInfo{{ID: "2020-08-01_osd1", Entity: "osd.1", Timestamp: "2020-08-01 10:00:00.000000Z"}}
+	c.reportCrashes(crashes)
+	assert.Equal(t, `Warning DaemonCrashed daemon osd.1 crashed at 2020-08-01 10:00:00.000000Z. run "ceph crash info 2020-08-01_osd1" for the details`, <-recorder.Events)
+
+	// a crash is only reported once
+	crashes = append(crashes, cephclient.CrashInfo{ID: "2020-08-02_mgra", Entity: "mgr.a", Timestamp: "2020-08-02 10:00:00.000000Z"})
+	c.reportCrashes(crashes)
+	assert.Equal(t, `Warning DaemonCrashed daemon mgr.a crashed at 2020-08-02 10:00:00.000000Z. run "ceph crash info 2020-08-02_mgra" for the details`, <-recorder.Events)
+	assert.Equal(t, 0, len(recorder.Events))
+
+	modules := map[string]string{"dashboard": "Module 'dashboard' has failed: timeout"}
+	c.reportFailedModules(modules)
+	c.reportFailedModules(modules)
+	assert.Equal(t, "Warning MgrModuleFailed Module 'dashboard' has failed: timeout", <-recorder.Events)
+	assert.Equal(t, 0, len(recorder.Events))
+
+	assert.Equal(t, "2 new crash reports of daemons [mgr.a osd.1]. failed mgr modules [dashboard]", degradedMessage(crashes, modules))
+	assert.Equal(t, "failed mgr modules [dashboard]", degradedMessage(nil, modules))
+	assert.Equal(t, "", degradedMessage(nil, map[string]string{}))
+}
+
+func TestCrashPruningStatus(t *testing.T) {
+	clientset := test.New(t, 1)
+	c := newCrashChecker(&clusterd.Context{Clientset: clientset}, cephclient.AdminClusterInfo("rook-ceph"), &cephv1.ClusterSpec{}, nil)
+
+	// no status without the pruner cronjob
+	status, err := c.pruningJobsStatus()
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+
+	scheduled := metav1.NewTime(time.Date(2020, 8, 3, 0, 0, 0, 0, time.UTC))
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: crash.PrunerName, Namespace: "rook-ceph"},
+		Status:     batchv1beta1.CronJobStatus{LastScheduleTime: &scheduled},
+	}
+	_, err = clientset.BatchV1beta1().CronJobs("rook-ceph").Create(cronJob)
+	assert.NoError(t, err)
+	status, err = c.pruningJobsStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, &cephv1.CrashPruningStatus{LastScheduleTime: "2020-08-03T00:00:00Z"}, status)
+
+	job := func(name string, conditionType batchv1.JobConditionType, day int) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "rook-ceph", Labels: map[string]string{k8sutil.AppAttr: crash.PrunerName}},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: conditionType, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Date(2020, 8, day, 0, 1, 0, 0, time.UTC))},
+			}},
+		}
+	}
+	_, err = clientset.BatchV1().Jobs("rook-ceph").Create(job("pruner-1", batchv1.JobComplete, 1))
+	assert.NoError(t, err)
+	_, err = clientset.BatchV1().Jobs("rook-ceph").Create(job("pruner-2", batchv1.JobFailed, 2))
+	assert.NoError(t, err)
+	status, err = c.pruningJobsStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-08-01T00:01:00Z", status.LastSuccessfulTime)
+	assert.True(t, status.LastFailed)
+
+	// a successful run clears the failure
+	_, err = clientset.BatchV1().Jobs("rook-ceph").Create(job("pruner-3", batchv1.JobComplete, 3))
+	assert.NoError(t, err)
+	status, err = c.pruningJobsStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, "2020-08-03T00:01:00Z", status.LastSuccessfulTime)
+	assert.False(t, status.LastFailed)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd/config"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/notification"
+	v1 "k8s.io/api/core/v1"
+)
+
+const cephClusterKind = "CephCluster"
+
+// deprecatedClusterFields returns the deprecated fields set in the spec of a cluster
+func deprecatedClusterFields(spec *cephv1.ClusterSpec) []opcontroller.DeprecatedField {
+	fields := []opcontroller.DeprecatedField{}
+	if spec.Network.HostNetwork {
+		fields = append(fields, opcontroller.DeprecatedField{Path: "spec.network.hostNetwork", Replacement: `spec.network.provider "host"`})
+	}
+	if len(spec.Storage.Directories) > 0 {
+		fields = append(fields, opcontroller.DeprecatedField{Path: "spec.storage.directories", Replacement: "spec.storage.devices"})
+	}
+	if _, ok := spec.Storage.Config[config.JournalSizeMBKey]; ok {
+		fields = append(fields, opcontroller.DeprecatedField{Path: "spec.storage.config.journalSizeMB", Replacement: "bluestore osds"})
+	}
+	for _, node := range spec.Storage.Nodes {
+		if len(node.Directories) > 0 {
+			path := fmt.Sprintf("spec.storage.nodes[%s].directories", node.Name)
+			fields = append(fields, opcontroller.DeprecatedField{Path: path, Replacement: fmt.Sprintf("spec.storage.nodes[%s].devices", node.Name)})
+		}
+		if _, ok := node.Config[config.JournalSizeMBKey]; ok {
+			path := fmt.Sprintf("spec.storage.nodes[%s].config.journalSizeMB", node.Name)
+			fields = append(fields, opcontroller.DeprecatedField{Path: path, Replacement: "bluestore osds"})
+		}
+	}
+	return fields
+}
+
+// reportDeprecatedFields exports the deprecated fields of the cluster in the metrics and in the DeprecatedFields
+// condition of the cluster, and notifies them when they change
+func (c *ClusterController) reportDeprecatedFields(cluster *cluster) {
+	fields := deprecatedClusterFields(cluster.Spec)
+	changed := opcontroller.ReportDeprecatedFields(cephClusterKind, cluster.Namespace, cluster.crdName, fields)
+	if len(fields) == 0 {
+		cephconfig.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionDeprecatedFields, v1.ConditionFalse, "NoDeprecatedFields", "No deprecated fields are set")
+		return
+	}
+
+	message := opcontroller.DeprecatedFieldsMessage(fields)
+	cephconfig.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionDeprecatedFields, v1.ConditionTrue, "DeprecatedFieldsSet", message)
+	if changed {
+		logger.Warningf("cluster %q uses deprecated fields which will be removed in a future api version. %s", cluster.Namespace, message)
+		notification.Warning(notification.ReasonDeprecatedFields, cluster.Namespace, cluster.crdName, "deprecated fields are set: %s", message)
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecatedClusterFields(t *testing.T) {
+	spec := &cephv1.ClusterSpec{}
+	assert.Equal(t, 0, len(deprecatedClusterFields(spec)))
+
+	spec.Network.HostNetwork = true
+	spec.Storage.Config = map[string]string{"journalSizeMB": "1024"}
+	spec.Storage.Nodes = []rookv1.Node{
+		{Name: "node1", Selection: rookv1.Selection{Directories: []rookv1.Directory{{Path: "/var/lib/osd"}}}},
+		{Name: "node2", Config: map[string]string{"databaseSizeMB": "1024"}},
+	}
+	paths := []string{}
+	for _, f := range deprecatedClusterFields(spec) {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"spec.network.hostNetwork", "spec.storage.config.journalSizeMB", "spec.storage.nodes[node1].directories"}, paths)
+}
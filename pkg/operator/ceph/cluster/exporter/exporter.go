@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package exporter deploys the ceph-exporter daemons which export the performance counters of the daemons of each node
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// AppName is the value to the "app" label for the ceph-exporter pods
+	AppName = "rook-ceph-exporter"
+	// NodeNameLabel is the label of the node of the ceph-exporter pods
+	NodeNameLabel = "node_name"
+
+	metricsPort     = 9926
+	metricsPortName = "http-metrics"
+)
+
+var (
+	logger = capnslog.NewPackageLogger("github.com/rook/rook", "ceph-exporter")
+
+	// MinimumVersion is the minimum version of Ceph shipping the ceph-exporter daemon
+	MinimumVersion = version.CephVersion{Major: 17, Minor: 2, Extra: 6}
+)
+
+// IsEnabled returns whether the ceph-exporter daemons are deployed for the cluster
+func IsEnabled(spec *cephv1.ClusterSpec, cephVersion version.CephVersion) bool {
+	if !spec.Monitoring.IsExporterEnabled() || spec.External.Enable {
+		return false
+	}
+	if !cephVersion.IsAtLeast(MinimumVersion) {
+		logger.Debugf("ceph-exporter requires ceph %q or newer, found %q", MinimumVersion.String(), cephVersion.String())
+		return false
+	}
+	return true
+}
+
+// CreateOrUpdateDeployment creates or updates the ceph-exporter deployment of a node. The pods of the deployment read
+// the admin sockets of the daemons of the node from the socket directory shared by the daemons on the host.
+func CreateOrUpdateDeployment(c client.Client, node corev1.Node, tolerations []corev1.Toleration, cephCluster cephv1.CephCluster, cephVersion version.CephVersion, ownerRef metav1.OwnerReference) (controllerutil.OperationResult, error) {
+	nodeHostnameLabel, ok := node.ObjectMeta.Labels[corev1.LabelHostname]
+	if !ok {
+		return controllerutil.OperationResultNone, errors.Errorf("label key %q does not exist on node %q", corev1.LabelHostname, node.GetName())
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sutil.TruncateNodeName(fmt.Sprintf("%s-%%s", AppName), nodeHostnameLabel),
+			Namespace:       cephCluster.GetNamespace(),
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+	}
+
+	dataPathMap := config.NewDatalessDaemonDataPathMap(cephCluster.GetNamespace(), cephCluster.Spec.DataDirHostPath).WithHostSocketDir(true)
+	volumes := controller.DaemonVolumesBase(dataPathMap, keyringResourceName)
+
+	mutateFunc := func() error {
+		// the labels are the labels of the mgr prometheus module so that the metrics are scraped the same way
+		deploymentLabels := controller.AppLabels(AppName, cephCluster.GetNamespace())
+		deploymentLabels[corev1.LabelHostname] = nodeHostnameLabel
+		deploymentLabels[NodeNameLabel] = node.GetName()
+		deploymentLabels["ceph_daemon_id"] = "exporter"
+
+		selectorLabels := map[string]string{
+			corev1.LabelHostname: nodeHostnameLabel,
+			k8sutil.AppAttr:      AppName,
+			NodeNameLabel:        node.GetName(),
+		}
+
+		// Deployment selector is immutable so we set this value only if
+		// a new object is going to be created
+		if deploy.ObjectMeta.CreationTimestamp.IsZero() {
+			deploy.Spec.Selector = &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			}
+		}
+
+		deploy.ObjectMeta.Labels = deploymentLabels
+		k8sutil.AddRookVersionLabelToDeployment(deploy)
+		controller.AddCephVersionLabelToDeployment(cephVersion, deploy)
+		deploy.Spec.Template = corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: deploymentLabels,
+			},
+			Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{corev1.LabelHostname: nodeHostnameLabel},
+				InitContainers: []corev1.Container{
+					controller.ChownCephDataDirsInitContainer(
+						*dataPathMap,
+						cephCluster.Spec.CephVersion.Image,
+						controller.DaemonVolumeMounts(dataPathMap, keyringResourceName),
+						cephv1.GetCrashCollectorResources(cephCluster.Spec.Resources),
+						mon.PodSecurityContext(),
+					),
+				},
+				Containers: []corev1.Container{
+					exporterContainer(cephCluster, dataPathMap),
+				},
+				Tolerations:       tolerations,
+				RestartPolicy:     corev1.RestartPolicyAlways,
+				HostNetwork:       cephCluster.Spec.Network.IsHost(),
+				Volumes:           volumes,
+				PriorityClassName: cephv1.GetExporterPriorityClassName(cephCluster.Spec.PriorityClassNames),
+				SecurityContext:   cephv1.GetPodSecurityContext(cephCluster.Spec.Security.PodSecurityContexts, cephv1.KeyExporter),
+			},
+		}
+		config.ApplyConnectionsAnnotation(cephCluster.Spec.Network, &deploy.Spec.Template.ObjectMeta)
+		if cephCluster.Spec.Network.IsMultus() {
+			if err := k8sutil.ApplyMultus(cephCluster.Spec.Network.NetworkSpec, &deploy.Spec.Template.ObjectMeta); err != nil {
+				return err
+			}
+		}
+		k8sutil.ApplyInheritedMetadataToPodTemplate(deploy.Namespace, &deploy.ObjectMeta, &deploy.Spec.Template)
+		return nil
+	}
+
+	return controllerutil.CreateOrUpdate(context.TODO(), c, deploy, mutateFunc)
+}
+
+func exporterContainer(cephCluster cephv1.CephCluster, dataPathMap *config.DataPathMap) corev1.Container {
+	cephImage := cephCluster.Spec.CephVersion.Image
+	exporterSpec := cephCluster.Spec.Monitoring.Exporter
+	if exporterSpec == nil {
+		exporterSpec = &cephv1.CephExporterSpec{}
+	}
+	keyringEnvVar := corev1.EnvVar{
+		Name:  "CEPH_ARGS",
+		Value: fmt.Sprintf("-m $(ROOK_CEPH_MON_HOST) -n %s -k %s", keyringUsername, keyring.VolumeMount().KeyringFilePath()),
+	}
+
+	return corev1.Container{
+		Name:    "ceph-exporter",
+		Command: []string{"ceph-exporter"},
+		Args: []string{
+			"--sock-dir", controller.StoredSocketVolumeMount().MountPath,
+			"--port", strconv.Itoa(metricsPort),
+			"--prio-limit", strconv.FormatInt(exporterSpec.GetPerfCountersPrioLimit(), 10),
+			"--stats-period", strconv.FormatInt(exporterSpec.GetStatsPeriodSeconds(), 10),
+		},
+		Image:        cephImage,
+		Env:          append(controller.DaemonEnvVars(cephImage), keyringEnvVar),
+		VolumeMounts: controller.DaemonVolumeMounts(dataPathMap, keyringResourceName),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          metricsPortName,
+				ContainerPort: int32(metricsPort),
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		Resources:       cephv1.GetCrashCollectorResources(cephCluster.Spec.Resources),
+		SecurityContext: mon.PodSecurityContext(),
+	}
+}
+
+// DeleteDeployments deletes the ceph-exporter deployments of a namespace matching the labels, or of every node if no
+// labels are given
+func DeleteDeployments(c client.Client, namespace string, labels map[string]string) error {
+	matchingLabels := client.MatchingLabels{k8sutil.AppAttr: AppName}
+	for key, value := range labels {
+		matchingLabels[key] = value
+	}
+	deploymentList := &appsv1.DeploymentList{}
+	if err := c.List(context.TODO(), deploymentList, matchingLabels, client.InNamespace(namespace)); err != nil {
+		return errors.Wrap(err, "failed to list ceph-exporter deployments")
+	}
+	for i := range deploymentList.Items {
+		d := &deploymentList.Items[i]
+		if err := c.Delete(context.TODO(), d); err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete ceph-exporter deployment %q", d.Name)
+		}
+		logger.Infof("ceph-exporter deployment %q successfully removed", d.Name)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestIsEnabled(t *testing.T) {
+	quincy := version.CephVersion{Major: 17, Minor: 2, Extra: 6}
+	spec := &cephv1.ClusterSpec{}
+	assert.False(t, IsEnabled(spec, quincy))
+
+	spec.Monitoring.Exporter = &cephv1.CephExporterSpec{Enabled: true}
+	assert.True(t, IsEnabled(spec, quincy))
+	assert.False(t, IsEnabled(spec, version.CephVersion{Major: 17, Minor: 2, Extra: 5}))
+	assert.False(t, IsEnabled(spec, version.Octopus))
+
+	spec.External.Enable = true
+	assert.False(t, IsEnabled(spec, quincy))
+}
+
+func TestCreateOrUpdateDeployment(t *testing.T) {
+	cephCluster := cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"},
+		Spec: cephv1.ClusterSpec{
+			CephVersion:     cephv1.CephVersionSpec{Image: "ceph/ceph:v17.2.6"},
+			DataDirHostPath: "/var/lib/rook",
+			Monitoring:      cephv1.MonitoringSpec{Exporter: &cephv1.CephExporterSpec{Enabled: true, PerfCountersPrioLimit: 10}},
+		},
+	}
+	node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{corev1.LabelHostname: "node1"}}}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	op, err := CreateOrUpdateDeployment(c, node, nil, cephCluster, version.CephVersion{Major: 17, Minor: 2, Extra: 6}, metav1.OwnerReference{})
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultCreated, op)
+
+	deploy := &appsv1.Deployment{}
+	err = c.Get(context.TODO(), client.ObjectKey{Name: "rook-ceph-exporter-node1", Namespace: "rook-ceph"}, deploy)
+	assert.NoError(t, err)
+	labels := deploy.Spec.Template.Labels
+	assert.Equal(t, AppName, labels[k8sutil.AppAttr])
+	assert.Equal(t, "rook-ceph", labels[k8sutil.ClusterAttr])
+	assert.Equal(t, "node1", labels[NodeNameLabel])
+
+	container := deploy.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"--sock-dir", "/run/ceph", "--port", "9926", "--prio-limit", "10", "--stats-period", "5"}, container.Args)
+	socketMounted := false
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath == "/run/ceph" {
+			socketMounted = true
+		}
+	}
+	assert.True(t, socketMounted)
+
+	// the deployments of a node are deleted
+	err = DeleteDeployments(c, "rook-ceph", map[string]string{NodeNameLabel: "node2"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Name: deploy.Name, Namespace: "rook-ceph"}, deploy))
+	err = DeleteDeployments(c, "rook-ceph", nil)
+	assert.NoError(t, err)
+	assert.Error(t, c.Get(context.TODO(), client.ObjectKey{Name: deploy.Name, Namespace: "rook-ceph"}, deploy))
+}
+
+func TestMakeService(t *testing.T) {
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	spec := &cephv1.ClusterSpec{
+		Labels: rookv1.LabelsSpec{cephv1.KeyMgr: {"team": "rook"}},
+	}
+	svc := MakeService(clusterInfo, spec)
+	assert.Equal(t, AppName, svc.Name)
+	assert.Equal(t, "rook", svc.Labels["team"])
+	assert.Equal(t, AppName, svc.Spec.Selector[k8sutil.AppAttr])
+	assert.Equal(t, "rook-ceph", svc.Spec.Selector[k8sutil.ClusterAttr])
+	assert.Equal(t, "http-metrics", svc.Spec.Ports[0].Name)
+	assert.Equal(t, int32(9926), svc.Spec.Ports[0].Port)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+)
+
+const (
+	keyringUsername     = "client.ceph-exporter"
+	keyringResourceName = AppName
+
+	keyringTemplate = `
+[client.ceph-exporter]
+	key = %s
+	caps mon = "allow r"
+	caps mgr = "allow r"
+`
+)
+
+// CreateExporterSecret creates the keyring of the ceph-exporter daemons, which only read the configuration of the
+// cluster from the mons and the performance counters from the admin sockets
+func CreateExporterSecret(context *clusterd.Context, clusterInfo *client.ClusterInfo) error {
+	k := keyring.GetSecretStore(context, clusterInfo, &clusterInfo.OwnerRef)
+
+	key, err := k.GenerateKey(keyringUsername, exporterKeyringCaps())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %q ceph keyring", keyringUsername)
+	}
+
+	if err := k.CreateOrUpdate(keyringResourceName, fmt.Sprintf(keyringTemplate, key)); err != nil {
+		return errors.Wrap(err, "failed to create ceph-exporter kubernetes secret")
+	}
+	return nil
+}
+
+func exporterKeyringCaps() []string {
+	return []string{
+		"mon", "allow r",
+		"mgr", "allow r",
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporter
+
+import (
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/netpolicy"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceMonitorFile is the template of the servicemonitor of the ceph-exporter daemons
+const ServiceMonitorFile = "exporter-service-monitor.yaml"
+
+// ReconcileService creates or updates the metrics service of the ceph-exporter daemons of the cluster, or removes it
+// when the daemons are disabled
+func ReconcileService(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec *cephv1.ClusterSpec) (*v1.Service, error) {
+	if !IsEnabled(spec, clusterInfo.CephVersion) {
+		err := context.Clientset.CoreV1().Services(clusterInfo.Namespace).Delete(AppName, &metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to delete ceph-exporter service")
+		}
+		return nil, netpolicy.DeleteServicePolicy(context, clusterInfo.Namespace, AppName)
+	}
+
+	service := MakeService(clusterInfo, spec)
+	if _, err := k8sutil.CreateOrUpdateService(context.Clientset, clusterInfo.Namespace, service); err != nil {
+		return nil, errors.Wrap(err, "failed to create ceph-exporter service")
+	}
+	if err := netpolicy.ReconcileServicePolicy(context, spec, service); err != nil {
+		return nil, errors.Wrap(err, "failed to reconcile the network policy of the ceph-exporter service")
+	}
+	return service, nil
+}
+
+// MakeService returns the metrics service of the ceph-exporter daemons. Like the mgr metrics service, it takes the
+// mgr labels and annotations so that the same prometheus scrapes both.
+func MakeService(clusterInfo *client.ClusterInfo, spec *cephv1.ClusterSpec) *v1.Service {
+	labels := controller.AppLabels(AppName, clusterInfo.Namespace)
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AppName,
+			Namespace: clusterInfo.Namespace,
+			Labels:    labels,
+		},
+		Spec: v1.ServiceSpec{
+			Selector: labels,
+			Type:     v1.ServiceTypeClusterIP,
+			Ports: []v1.ServicePort{
+				{
+					Name:     metricsPortName,
+					Port:     int32(metricsPort),
+					Protocol: v1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	applyServiceMetadata(spec, &svc.ObjectMeta)
+	k8sutil.SetOwnerRef(&svc.ObjectMeta, &clusterInfo.OwnerRef)
+	return svc
+}
+
+// EnableServiceMonitor creates or updates the servicemonitor that allows prometheus to scrape the ceph-exporter
+// daemons through their metrics service
+func EnableServiceMonitor(service *v1.Service, filePath string, spec *cephv1.ClusterSpec) error {
+	serviceMonitor, err := k8sutil.GetServiceMonitor(filePath)
+	if err != nil {
+		return errors.Wrap(err, "ceph-exporter service monitor could not be loaded")
+	}
+	serviceMonitor.SetName(service.GetName())
+	serviceMonitor.SetNamespace(service.GetNamespace())
+	k8sutil.SetOwnerRefs(&serviceMonitor.ObjectMeta, service.GetOwnerReferences())
+	serviceMonitor.Spec.NamespaceSelector.MatchNames = []string{service.GetNamespace()}
+	serviceMonitor.Spec.Selector.MatchLabels = controller.AppLabels(AppName, service.GetNamespace())
+	applyServiceMetadata(spec, &serviceMonitor.ObjectMeta)
+	if _, err := k8sutil.CreateOrUpdateServiceMonitor(serviceMonitor); err != nil {
+		return errors.Wrap(err, "ceph-exporter service monitor could not be enabled")
+	}
+	return nil
+}
+
+func applyServiceMetadata(spec *cephv1.ClusterSpec, objectMeta *metav1.ObjectMeta) {
+	cephv1.GetMgrAnnotations(spec.Annotations).ApplyToObjectMeta(objectMeta)
+	cephv1.GetMgrLabels(spec.Labels).ApplyToObjectMeta(objectMeta)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateHostPorts validates the ports of the daemons on the host network and checks them against the other
+// clusters on the host network. The conflicts with the other clusters are refused when the cluster customizes its
+// ports to share the nodes with them, and are only reported otherwise since the clusters may run on different nodes.
+func validateHostPorts(context *clusterd.Context, clusterObj *cephv1.CephCluster) error {
+	networkSpec := clusterObj.Spec.Network
+	if err := config.ValidateHostPorts(networkSpec); err != nil {
+		return err
+	}
+	if !networkSpec.IsHost() {
+		return nil
+	}
+
+	clusters, err := listWatchedCephClusters(context)
+	if err != nil {
+		logger.Warningf("failed to list the ceph clusters to check the conflicts of the host ports. %v", err)
+		return nil
+	}
+	for _, other := range clusters {
+		if (other.Namespace == clusterObj.Namespace && other.Name == clusterObj.Name) || other.Spec.External.Enable || !other.Spec.Network.IsHost() {
+			continue
+		}
+		if err := hostPortsConflict(networkSpec, other.Spec.Network); err != nil {
+			if networkSpec.HostPorts != nil {
+				return errors.Wrapf(err, "host ports conflict with cluster %q in namespace %q", other.Name, other.Namespace)
+			}
+			logger.Warningf("host ports conflict with cluster %q in namespace %q, the clusters must not run daemons on the same nodes. %v", other.Name, other.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// listWatchedCephClusters lists the ceph clusters of the namespaces watched by the operator, which may not have the
+// RBAC to list the clusters of all the namespaces
+func listWatchedCephClusters(context *clusterd.Context) ([]cephv1.CephCluster, error) {
+	namespaces := opcontroller.WatchNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	clusters := []cephv1.CephCluster{}
+	for _, namespace := range namespaces {
+		list, err := context.RookClientset.CephV1().CephClusters(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, list.Items...)
+	}
+	return clusters, nil
+}
+
+// hostPortsConflict returns an error when the daemons of two clusters on the host network bind to the same ports
+func hostPortsConflict(networkSpec, otherSpec cephv1.NetworkSpec) error {
+	portRange := config.MsgrPortRange(networkSpec)
+	otherRange := config.MsgrPortRange(otherSpec)
+	if portRange.Overlaps(&otherRange) {
+		return errors.Errorf("msgr port range %d-%d overlaps the range %d-%d", portRange.Min, portRange.Max, otherRange.Min, otherRange.Max)
+	}
+	if port := config.NFSPort(networkSpec); port == config.NFSPort(otherSpec) {
+		return errors.Errorf("nfs port %d is the same", port)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateHostPorts(t *testing.T) {
+	hostNetwork := cephv1.NetworkSpec{NetworkSpec: rookv1.NetworkSpec{Provider: "host"}}
+	other := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other-ns"},
+		Spec:       cephv1.ClusterSpec{Network: hostNetwork},
+	}
+	context := &clusterd.Context{RookClientset: rookfake.NewSimpleClientset(other)}
+	cluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "ns"},
+		Spec:       cephv1.ClusterSpec{Network: hostNetwork},
+	}
+
+	// the conflicts with a cluster with the default ports are only reported
+	assert.NoError(t, validateHostPorts(context, cluster))
+
+	// the conflicts are refused when the ports are customized
+	cluster.Spec.Network.HostPorts = &cephv1.HostPortsSpec{MsgrPortRange: &cephv1.PortRangeSpec{Min: 7000, Max: 7500}}
+	assert.Error(t, validateHostPorts(context, cluster))
+	cluster.Spec.Network.HostPorts.MsgrPortRange = &cephv1.PortRangeSpec{Min: 7400, Max: 7900}
+	assert.Error(t, validateHostPorts(context, cluster))
+	cluster.Spec.Network.HostPorts.NFSPort = 12049
+	assert.NoError(t, validateHostPorts(context, cluster))
+
+	// the ports of the clusters which are not on the host network do not conflict
+	cluster.Spec.Network.HostPorts.NFSPort = 0
+	other.Spec.Network = cephv1.NetworkSpec{}
+	context.RookClientset = rookfake.NewSimpleClientset(other)
+	assert.NoError(t, validateHostPorts(context, cluster))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+)
+
+const (
+	// defaultKeyRotationCheckInterval is the interval to check if the keys are due for a rotation
+	defaultKeyRotationCheckInterval = time.Hour
+)
+
+// keyRotationChecker rotates the dmcrypt keys of the encrypted OSDs and the cephx keys of the daemons when their
+// rotation policies are due. The rotations run out of the reconcile of the cluster so that they happen while the
+// cluster is steady and do not block the reconcile while the daemons are restarted.
+type keyRotationChecker struct {
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	rookImage   string
+	interval    time.Duration
+}
+
+// newKeyRotationChecker creates a checker of the key rotation policies
+func newKeyRotationChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, rookImage string) *keyRotationChecker {
+	return &keyRotationChecker{
+		context:     context,
+		clusterInfo: clusterInfo,
+		rookImage:   rookImage,
+		interval:    defaultKeyRotationCheckInterval,
+	}
+}
+
+// checkKeyRotation periodically rotates the keys which are due
+func (k *keyRotationChecker) checkKeyRotation(stopCh chan struct{}) {
+	// check immediately before starting the loop, the checker is restarted when the policies change
+	k.check()
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping the key rotation of cluster %q", k.clusterInfo.Namespace)
+			return
+
+		case <-time.After(k.interval):
+			k.check()
+		}
+	}
+}
+
+func (k *keyRotationChecker) check() {
+	// the policies and the settings of the osd jobs are read at each check
+	cephCluster := &cephv1.CephCluster{}
+	if err := k.context.Client.Get(context.TODO(), k.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		logger.Warningf("failed to get cluster %q to rotate the keys. %v", k.clusterInfo.NamespacedName().Name, err)
+		return
+	}
+
+	if cephCluster.Spec.Security.KeyRotation.Enabled {
+		osds := osd.New(k.context, k.clusterInfo, cephCluster.Spec, k.rookImage)
+		if err := osds.RotateEncryptionKeys(); err != nil {
+			logger.Warningf("failed to rotate the encryption keys of the osds. %v", err)
+		}
+	}
+
+	if err := k.rotateCephxKeys(); err != nil {
+		logger.Warningf("failed to rotate the cephx keys. %v", err)
+	}
+}
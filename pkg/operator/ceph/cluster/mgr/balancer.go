@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+)
+
+const (
+	balancerSleepIntervalOption = "mgr/balancer/sleep_interval"
+)
+
+// hasBalancerSettings returns whether the balancer settings are set in the cluster spec
+func hasBalancerSettings(spec cephv1.BalancerSpec) bool {
+	return spec.Enabled != nil || spec.Mode != "" || spec.Interval != ""
+}
+
+// IsBalancerManaged returns whether the operator configures the balancer module, which is always on from Octopus
+func IsBalancerManaged(clusterInfo *client.ClusterInfo, spec cephv1.BalancerSpec) bool {
+	return clusterInfo.CephVersion.IsAtLeastOctopus() || hasBalancerSettings(spec)
+}
+
+// desiredBalancer returns whether the balancer must be on and its mode
+func desiredBalancer(spec cephv1.BalancerSpec) (bool, string) {
+	enabled := spec.Enabled == nil || *spec.Enabled
+	mode := spec.Mode
+	if mode == "" {
+		mode = balancerModuleMode
+	}
+	return enabled, mode
+}
+
+// validateBalancerSpec checks the mode and the interval of the balancer
+func validateBalancerSpec(spec cephv1.BalancerSpec) error {
+	if spec.Mode != "" && spec.Mode != "upmap" && spec.Mode != "crush-compat" {
+		return errors.Errorf("invalid balancer mode %q, expected \"upmap\" or \"crush-compat\"", spec.Mode)
+	}
+	if spec.Interval != "" {
+		if duration, err := time.ParseDuration(spec.Interval); err != nil || duration < time.Second {
+			return errors.Errorf("invalid balancer interval %q", spec.Interval)
+		}
+	}
+	return nil
+}
+
+// ConfigureBalancer applies the balancer settings to the balancer module: its mode, the interval between two
+// optimizations and whether it is on. The interval removed from the settings is reset to the default of Ceph.
+func ConfigureBalancer(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec cephv1.BalancerSpec) error {
+	if err := validateBalancerSpec(spec); err != nil {
+		return err
+	}
+	enabled, mode := desiredBalancer(spec)
+	if !enabled {
+		if err := client.MgrDisableModule(context, clusterInfo, balancerModuleName); err != nil {
+			return errors.Wrapf(err, "failed to turn off mgr %q module", balancerModuleName)
+		}
+		return nil
+	}
+
+	// The order MATTERS, always configure this module first, then turn it on
+
+	// This sets min compat client to luminous and the balancer module mode
+	err := client.ConfigureBalancerModule(context, clusterInfo, mode)
+	if err != nil {
+		return errors.Wrapf(err, "failed to configure module %q", balancerModuleName)
+	}
+
+	monStore := config.GetMonStore(context, clusterInfo)
+	if spec.Interval != "" {
+		// the sleep interval is in seconds
+		duration, _ := time.ParseDuration(spec.Interval)
+		if err := monStore.Set("mgr", balancerSleepIntervalOption, strconv.Itoa(int(duration.Seconds()))); err != nil {
+			return errors.Wrap(err, "failed to set the balancer interval")
+		}
+	} else if err := monStore.Delete("mgr", balancerSleepIntervalOption); err != nil {
+		return errors.Wrap(err, "failed to reset the balancer interval")
+	}
+
+	// This turns "on" the balancer
+	err = client.MgrEnableModule(context, clusterInfo, balancerModuleName, false)
+	if err != nil {
+		return errors.Wrapf(err, "failed to turn on mgr %q module", balancerModuleName)
+	}
+
+	return nil
+}
+
+// BalancerDrifted returns whether the balancer was turned on or off, or its mode changed, since the operator
+// applied the balancer settings. The balancer is not reconciled between two orchestrations without settings.
+func BalancerDrifted(spec cephv1.BalancerSpec, status *client.BalancerStatus) bool {
+	if !hasBalancerSettings(spec) {
+		return false
+	}
+	enabled, mode := desiredBalancer(spec)
+	if status.Active != enabled {
+		return true
+	}
+	return enabled && status.Mode != mode
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureBalancer(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			// the command without the connection args
+			command = ""
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "--connect-timeout") {
+					break
+				}
+				command = strings.TrimSpace(command + " " + arg)
+			}
+			commands = append(commands, command)
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := client.AdminClusterInfo("ns")
+	clusterInfo.CephVersion = cephver.Nautilus
+
+	// the balancer is only configured by default from octopus
+	assert.False(t, IsBalancerManaged(clusterInfo, cephv1.BalancerSpec{}))
+	assert.True(t, IsBalancerManaged(clusterInfo, cephv1.BalancerSpec{Mode: "crush-compat"}))
+	clusterInfo.CephVersion = cephver.Octopus
+	assert.True(t, IsBalancerManaged(clusterInfo, cephv1.BalancerSpec{}))
+
+	// default settings
+	assert.NoError(t, ConfigureBalancer(context, clusterInfo, cephv1.BalancerSpec{}))
+	assert.Equal(t, []string{
+		"osd set-require-min-compat-client luminous --yes-i-really-mean-it",
+		"balancer mode upmap",
+		"config rm mgr mgr/balancer/sleep_interval",
+		"balancer on",
+	}, commands)
+
+	// the crush-compat mode does not require luminous clients
+	commands = []string{}
+	assert.NoError(t, ConfigureBalancer(context, clusterInfo, cephv1.BalancerSpec{Mode: "crush-compat", Interval: "5m"}))
+	assert.Equal(t, []string{
+		"balancer mode crush-compat",
+		"config set mgr mgr/balancer/sleep_interval 300",
+		"balancer on",
+	}, commands)
+
+	// the balancer is turned off
+	commands = []string{}
+	disabled := false
+	assert.NoError(t, ConfigureBalancer(context, clusterInfo, cephv1.BalancerSpec{Enabled: &disabled}))
+	assert.Equal(t, []string{"balancer off"}, commands)
+
+	// invalid settings
+	assert.Error(t, ConfigureBalancer(context, clusterInfo, cephv1.BalancerSpec{Mode: "none"}))
+	assert.Error(t, ConfigureBalancer(context, clusterInfo, cephv1.BalancerSpec{Interval: "10ms"}))
+}
+
+func TestBalancerDrifted(t *testing.T) {
+	disabled := false
+	status := &client.BalancerStatus{Active: true, Mode: "upmap"}
+	assert.False(t, BalancerDrifted(cephv1.BalancerSpec{}, &client.BalancerStatus{}))
+	assert.False(t, BalancerDrifted(cephv1.BalancerSpec{Mode: "upmap"}, status))
+	assert.True(t, BalancerDrifted(cephv1.BalancerSpec{Mode: "crush-compat"}, status))
+	assert.True(t, BalancerDrifted(cephv1.BalancerSpec{Enabled: &disabled}, status))
+	status.Active = false
+	assert.False(t, BalancerDrifted(cephv1.BalancerSpec{Enabled: &disabled, Mode: "crush-compat"}, status))
+	assert.True(t, BalancerDrifted(cephv1.BalancerSpec{Interval: "60s"}, status))
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/certificate"
+)
+
+const (
+	dashboardCertificateSecretName = "rook-ceph-dashboard-tls"
+	dashboardCertificateKey        = "mgr/dashboard/crt"
+)
+
+// IsDashboardCertificateManaged returns whether the operator issues the certificate of the dashboard instead of the
+// self-signed certificate created by the dashboard module
+func IsDashboardCertificateManaged(spec *cephv1.ClusterSpec) bool {
+	return spec.Security.TLS.IsEnabled() && !spec.External.Enable && spec.Dashboard.Enabled && spec.Dashboard.SSL
+}
+
+// configureDashboardSSL configures the certificate of the dashboard and returns whether it was already configured
+func (c *Cluster) configureDashboardSSL() (bool, error) {
+	if IsDashboardCertificateManaged(&c.spec) {
+		changed, err := c.configureDashboardCertificate()
+		if errors.Cause(err) != certificate.ErrNotReady {
+			return !changed, err
+		}
+		// the certificate checker configures the certificate once cert-manager issued it
+		logger.Infof("dashboard certificate is not issued yet, using a self signed cert until then")
+	}
+	return c.createSelfSignedCert()
+}
+
+// configureDashboardCertificate issues the certificate of the dashboard and configures it in the dashboard module.
+// It returns whether the certificate changed, in which case the dashboard module must be restarted.
+func (c *Cluster) configureDashboardCertificate() (bool, error) {
+	serviceName := c.makeDashboardService(AppName).Name
+	domainName := fmt.Sprintf("%s.%s", serviceName, c.clusterInfo.Namespace)
+	ownerRef := c.clusterInfo.OwnerRef
+	req := certificate.Request{
+		SecretName: dashboardCertificateSecretName,
+		DNSNames:   []string{domainName + ".svc", serviceName, domainName},
+		OwnerRef:   &ownerRef,
+	}
+	cert, err := certificate.NewManager(c.context, c.clusterInfo, c.spec.Security.TLS).Issue(req)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to issue the certificate of the dashboard")
+	}
+
+	// the certificate is not configured again if the dashboard module already has it
+	current, err := client.ConfigKeyGet(c.context, c.clusterInfo, dashboardCertificateKey)
+	if err == nil && strings.TrimSpace(current) == strings.TrimSpace(string(cert.Cert)) {
+		logger.Debugf("dashboard certificate is up to date")
+		return false, nil
+	}
+
+	logger.Infof("configuring the dashboard certificate %q", dashboardCertificateSecretName)
+	if err := c.setDashboardCertificateFile("set-ssl-certificate", cert.Cert); err != nil {
+		return false, err
+	}
+	if err := c.setDashboardCertificateFile("set-ssl-certificate-key", cert.Key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setDashboardCertificateFile sets the certificate or the private key of the dashboard, which the dashboard module
+// reads from a file
+func (c *Cluster) setDashboardCertificateFile(command string, content []byte) error {
+	return client.SetDashboardSettingFromFile(c.context, c.clusterInfo, command, content)
+}
+
+// DashboardCertificateChecker renews the certificate of the dashboard when it is issued by the operator
+type DashboardCertificateChecker struct {
+	cluster  *Cluster
+	interval time.Duration
+}
+
+// NewDashboardCertificateChecker creates a checker of the certificate of the dashboard
+func NewDashboardCertificateChecker(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec cephv1.ClusterSpec) *DashboardCertificateChecker {
+	return &DashboardCertificateChecker{cluster: New(context, clusterInfo, spec, ""), interval: certificate.CheckInterval}
+}
+
+// Start checks the certificate of the dashboard at set intervals, the dashboard module is restarted when the
+// certificate is renewed
+func (d *DashboardCertificateChecker) Start(stopCh chan struct{}) {
+	for {
+		select {
+		case <-time.After(d.interval):
+			logger.Debug("checking the dashboard certificate")
+			if err := d.check(); err != nil {
+				logger.Warningf("failed to renew the dashboard certificate. %v", err)
+			}
+
+		case <-stopCh:
+			logger.Infof("stopping the dashboard certificate checker")
+			return
+		}
+	}
+}
+
+func (d *DashboardCertificateChecker) check() error {
+	changed, err := d.cluster.configureDashboardCertificate()
+	if errors.Cause(err) == certificate.ErrNotReady {
+		logger.Infof("dashboard certificate is not issued yet")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if changed {
+		logger.Infof("dashboard certificate was renewed. restarting the dashboard module.")
+		return d.cluster.restartDashboard()
+	}
+	return nil
+}
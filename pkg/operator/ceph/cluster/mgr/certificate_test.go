@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigureDashboardCertificate(t *testing.T) {
+	// the config-key store of the dashboard module
+	keys := map[string]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			if args[0] == "config-key" && args[1] == "get" {
+				value, ok := keys[args[2]]
+				if !ok {
+					return "", errors.New("key not found")
+				}
+				return value, nil
+			}
+			if args[0] == "dashboard" && args[2] == "-i" {
+				content, err := ioutil.ReadFile(args[3])
+				assert.NoError(t, err)
+				if args[1] == "set-ssl-certificate" {
+					keys["mgr/dashboard/crt"] = string(content)
+				} else {
+					keys["mgr/dashboard/key"] = string(content)
+				}
+			}
+			return "", nil
+		},
+	}
+	executor.MockExecuteCommandWithOutputFileTimeout = func(timeout time.Duration, command, outfileArg string, arg ...string) (string, error) {
+		return executor.MockExecuteCommandWithOutputFile(command, outfileArg, arg...)
+	}
+	clientset := test.New(t, 1)
+	spec := cephv1.ClusterSpec{
+		Dashboard: cephv1.DashboardSpec{Enabled: true, SSL: true},
+		Security:  cephv1.SecuritySpec{TLS: cephv1.TLSSpec{Issuer: cephv1.TLSIssuerSelfSigned}},
+	}
+	assert.True(t, IsDashboardCertificateManaged(&spec))
+	c := New(&clusterd.Context{Clientset: clientset, Executor: executor}, cephclient.AdminClusterInfo("myns"), spec, "")
+
+	// the certificate is issued and configured in the dashboard module
+	changed, err := c.configureDashboardCertificate()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	secret, err := clientset.CoreV1().Secrets("myns").Get(dashboardCertificateSecretName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, string(secret.Data[v1.TLSCertKey]), keys["mgr/dashboard/crt"])
+	assert.Equal(t, string(secret.Data[v1.TLSPrivateKeyKey]), keys["mgr/dashboard/key"])
+
+	// the dashboard is not restarted while the certificate is valid
+	alreadyConfigured, err := c.configureDashboardSSL()
+	assert.NoError(t, err)
+	assert.True(t, alreadyConfigured)
+
+	// a new certificate is configured when the secret is replaced
+	assert.NoError(t, clientset.CoreV1().Secrets("myns").Delete(dashboardCertificateSecretName, &metav1.DeleteOptions{}))
+	changed, err = c.configureDashboardCertificate()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// the certificate is not managed without ssl or for external clusters
+	spec.Dashboard.SSL = false
+	assert.False(t, IsDashboardCertificateManaged(&spec))
+	spec.Dashboard.SSL = true
+	spec.External.Enable = true
+	assert.False(t, IsDashboardCertificateManaged(&spec))
+}
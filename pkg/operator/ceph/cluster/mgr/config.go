@@ -19,6 +19,7 @@ package mgr
 import (
 	"fmt"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -76,5 +77,5 @@ func (c *Cluster) generateKeyring(m *mgrConfig) (string, error) {
 	}
 
 	keyring := fmt.Sprintf(keyringTemplate, m.DaemonID, key)
-	return keyring, s.CreateOrUpdate(m.ResourceName, keyring)
+	return keyring, s.CreateOrUpdateWithMeta(m.ResourceName, keyring, cephv1.GetMgrAnnotations(c.spec.Annotations), cephv1.GetMgrLabels(c.spec.Labels))
 }
@@ -26,6 +26,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/netpolicy"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -53,31 +54,29 @@ func (c *Cluster) configureDashboardService() error {
 	dashboardService := c.makeDashboardService(AppName)
 	if c.spec.Dashboard.Enabled {
 		// expose the dashboard service
-		if _, err := c.context.Clientset.CoreV1().Services(c.clusterInfo.Namespace).Create(dashboardService); err != nil {
-			if !kerrors.IsAlreadyExists(err) {
-				return errors.Wrap(err, "failed to create dashboard mgr service")
-			}
-			logger.Infof("dashboard service already exists")
-			original, err := c.context.Clientset.CoreV1().Services(c.clusterInfo.Namespace).Get(dashboardService.Name, metav1.GetOptions{})
-			if err != nil {
-				return errors.Wrap(err, "failed to get dashboard service")
-			}
-			if original.Spec.Ports[0].Port != int32(c.dashboardPort()) {
-				logger.Infof("dashboard port changed. updating service")
-				original.Spec.Ports[0].Port = int32(c.dashboardPort())
-				if _, err := c.context.Clientset.CoreV1().Services(c.clusterInfo.Namespace).Update(original); err != nil {
-					return errors.Wrap(err, "failed to update dashboard mgr service")
-				}
-			}
-		} else {
-			logger.Infof("dashboard service started")
+		// the service is updated so that the changes of the port, labels and annotations are applied
+		if _, err := k8sutil.CreateOrUpdateService(c.context.Clientset, c.clusterInfo.Namespace, dashboardService); err != nil {
+			return errors.Wrap(err, "failed to create dashboard mgr service")
+		}
+		if err := netpolicy.ReconcileServicePolicy(c.context, &c.spec, dashboardService); err != nil {
+			return errors.Wrap(err, "failed to reconcile the network policy of the dashboard service")
+		}
+		if err := c.reconcileDashboardIngress(dashboardService); err != nil {
+			return err
 		}
+		logger.Infof("dashboard service started")
 	} else {
 		// delete the dashboard service if it exists
 		err := c.context.Clientset.CoreV1().Services(c.clusterInfo.Namespace).Delete(dashboardService.Name, &metav1.DeleteOptions{})
 		if err != nil && !kerrors.IsNotFound(err) {
 			return errors.Wrap(err, "failed to delete dashboard service")
 		}
+		if err := netpolicy.DeleteServicePolicy(c.context, c.clusterInfo.Namespace, dashboardService.Name); err != nil {
+			return err
+		}
+		if err := c.reconcileDashboardIngress(dashboardService); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -112,7 +111,31 @@ func (c *Cluster) configureDashboardModules() error {
 	}
 	if hasChanged {
 		logger.Infof("dashboard config has changed. restarting the dashboard module.")
-		return c.restartDashboard()
+		if err := c.restartDashboard(); err != nil {
+			return err
+		}
+	}
+	if err := c.configureDashboardMonitoring(); err != nil {
+		return err
+	}
+	return c.configureDashboardSSO()
+}
+
+// configureDashboardMonitoring sets the URLs of the Grafana and the Prometheus of the dashboard, and resets them
+// when removed from the spec
+func (c *Cluster) configureDashboardMonitoring() error {
+	settings := []struct{ name, value string }{
+		{"grafana-api-url", c.spec.Dashboard.GrafanaURL},
+		{"prometheus-api-host", c.spec.Dashboard.PrometheusURL},
+	}
+	for _, setting := range settings {
+		changed, err := client.SetDashboardSetting(c.context, c.clusterInfo, setting.name, setting.value)
+		if err != nil {
+			return err
+		}
+		if changed {
+			logger.Infof("dashboard setting %q set to %q", setting.name, setting.value)
+		}
 	}
 	return nil
 }
@@ -162,9 +185,9 @@ func (c *Cluster) initializeSecureDashboard() (bool, error) {
 	}
 
 	if c.spec.Dashboard.SSL {
-		alreadyCreated, err := c.createSelfSignedCert()
+		alreadyCreated, err := c.configureDashboardSSL()
 		if err != nil {
-			return false, errors.Wrap(err, "failed to create a self signed cert for the ceph dashboard")
+			return false, errors.Wrap(err, "failed to configure the cert of the ceph dashboard")
 		}
 		if alreadyCreated {
 			return false, nil
@@ -16,6 +16,7 @@ limitations under the License.
 package mgr
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -151,3 +152,43 @@ func TestStartSecureDashboard(t *testing.T) {
 	assert.True(t, kerrors.IsNotFound(err))
 	assert.Nil(t, svc)
 }
+
+func TestConfigureDashboardMonitoring(t *testing.T) {
+	settings := map[string]string{}
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFileTimeout: func(timeout time.Duration, command, outfileArg string, args ...string) (string, error) {
+			switch {
+			case strings.HasPrefix(args[1], "get-"):
+				return settings[strings.TrimPrefix(args[1], "get-")], nil
+			case strings.HasPrefix(args[1], "set-"):
+				settings[strings.TrimPrefix(args[1], "set-")] = args[2]
+			case strings.HasPrefix(args[1], "reset-"):
+				delete(settings, strings.TrimPrefix(args[1], "reset-"))
+			}
+			commands = append(commands, args[1])
+			return "", nil
+		},
+	}
+	c := &Cluster{context: &clusterd.Context{Executor: executor}, clusterInfo: &cephclient.ClusterInfo{Namespace: "myns"}}
+
+	// nothing is reset if not set
+	assert.NoError(t, c.configureDashboardMonitoring())
+	assert.Empty(t, commands)
+
+	c.spec.Dashboard.GrafanaURL = "https://grafana.example.com"
+	c.spec.Dashboard.PrometheusURL = "http://prometheus.monitoring.svc:9090"
+	assert.NoError(t, c.configureDashboardMonitoring())
+	assert.Equal(t, "https://grafana.example.com", settings["grafana-api-url"])
+	assert.Equal(t, "http://prometheus.monitoring.svc:9090", settings["prometheus-api-host"])
+
+	// the settings are only set when they change
+	commands = []string{}
+	assert.NoError(t, c.configureDashboardMonitoring())
+	assert.Empty(t, commands)
+
+	c.spec.Dashboard.PrometheusURL = ""
+	assert.NoError(t, c.configureDashboardMonitoring())
+	assert.Equal(t, []string{"reset-prometheus-api-host"}, commands)
+	assert.Equal(t, 1, len(settings))
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// dashboardExternalURL returns the parsed external URL of the dashboard, nil if not set
+func (c *Cluster) dashboardExternalURL() (*url.URL, error) {
+	if c.spec.Dashboard.ExternalURL == "" {
+		return nil, nil
+	}
+	externalURL, err := url.Parse(c.spec.Dashboard.ExternalURL)
+	if err != nil || externalURL.Host == "" || (externalURL.Scheme != "http" && externalURL.Scheme != "https") {
+		return nil, errors.Errorf("invalid dashboard external url %q", c.spec.Dashboard.ExternalURL)
+	}
+	return externalURL, nil
+}
+
+// reconcileDashboardIngress creates or updates the ingress of the dashboard service when enabled, and deletes it
+// otherwise
+func (c *Cluster) reconcileDashboardIngress(service *v1.Service) error {
+	ingresses := c.context.Clientset.NetworkingV1beta1().Ingresses(c.clusterInfo.Namespace)
+	if !c.spec.Dashboard.Enabled || c.spec.Dashboard.Ingress == nil || !c.spec.Dashboard.Ingress.Enabled {
+		err := ingresses.Delete(service.Name, &metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to delete dashboard ingress")
+		}
+		return nil
+	}
+
+	ingress, err := c.makeDashboardIngress(service)
+	if err != nil {
+		return err
+	}
+	existing, err := ingresses.Get(ingress.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get dashboard ingress")
+		}
+		if _, err := ingresses.Create(ingress); err != nil {
+			return errors.Wrap(err, "failed to create dashboard ingress")
+		}
+		logger.Infof("dashboard ingress %q created for host %q", ingress.Name, ingress.Spec.Rules[0].Host)
+		return nil
+	}
+	ingress.ResourceVersion = existing.ResourceVersion
+	if _, err := ingresses.Update(ingress); err != nil {
+		return errors.Wrap(err, "failed to update dashboard ingress")
+	}
+	return nil
+}
+
+// makeDashboardIngress returns the ingress of the host of the dashboard to the dashboard service. OpenShift creates a
+// route for the ingress.
+func (c *Cluster) makeDashboardIngress(service *v1.Service) (*networking.Ingress, error) {
+	spec := c.spec.Dashboard.Ingress
+	host := spec.Host
+	if host == "" {
+		externalURL, err := c.dashboardExternalURL()
+		if err != nil {
+			return nil, err
+		}
+		if externalURL == nil {
+			return nil, errors.New("the dashboard ingress requires a host or the external url of the dashboard")
+		}
+		host = externalURL.Hostname()
+	}
+	path := c.spec.Dashboard.UrlPrefix
+	if path == "" {
+		path = "/"
+	}
+
+	ingress := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        service.Name,
+			Namespace:   c.clusterInfo.Namespace,
+			Labels:      service.Labels,
+			Annotations: map[string]string{},
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{
+								{
+									Path: path,
+									Backend: networking.IngressBackend{
+										ServiceName: service.Name,
+										ServicePort: intstr.FromInt(c.dashboardPort()),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for key, value := range spec.Annotations {
+		ingress.Annotations[key] = value
+	}
+	if spec.ClassName != "" {
+		ingress.Annotations[ingressClassAnnotation] = spec.ClassName
+	}
+	if spec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networking.IngressTLS{{Hosts: []string{host}, SecretName: spec.TLSSecretName}}
+	}
+	k8sutil.SetOwnerRef(&ingress.ObjectMeta, &c.clusterInfo.OwnerRef)
+	return ingress, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDashboardIngress(t *testing.T) {
+	clientset := test.New(t, 1)
+	c := &Cluster{
+		context:     &clusterd.Context{Clientset: clientset},
+		clusterInfo: &cephclient.ClusterInfo{Namespace: "ns"},
+		spec: cephv1.ClusterSpec{
+			Dashboard: cephv1.DashboardSpec{
+				Enabled:     true,
+				SSL:         true,
+				UrlPrefix:   "/ceph",
+				ExternalURL: "https://ceph.example.com/ceph",
+				Ingress: &cephv1.DashboardIngressSpec{
+					Enabled:       true,
+					ClassName:     "nginx",
+					TLSSecretName: "ceph-example-tls",
+					Annotations:   map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"},
+				},
+			},
+		},
+	}
+	service := c.makeDashboardService(AppName)
+	ingresses := clientset.NetworkingV1beta1().Ingresses("ns")
+
+	// the host of the external url routes to the dashboard service
+	assert.NoError(t, c.reconcileDashboardIngress(service))
+	ingress, err := ingresses.Get("rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ceph.example.com", ingress.Spec.Rules[0].Host)
+	path := ingress.Spec.Rules[0].HTTP.Paths[0]
+	assert.Equal(t, "/ceph", path.Path)
+	assert.Equal(t, "rook-ceph-mgr-dashboard", path.Backend.ServiceName)
+	assert.Equal(t, 8443, path.Backend.ServicePort.IntValue())
+	assert.Equal(t, "ceph-example-tls", ingress.Spec.TLS[0].SecretName)
+	assert.Equal(t, "nginx", ingress.Annotations[ingressClassAnnotation])
+	assert.Equal(t, "HTTPS", ingress.Annotations["nginx.ingress.kubernetes.io/backend-protocol"])
+
+	// the ingress is updated with the spec
+	c.spec.Dashboard.Ingress.Host = "dashboard.example.com"
+	assert.NoError(t, c.reconcileDashboardIngress(service))
+	ingress, err = ingresses.Get("rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "dashboard.example.com", ingress.Spec.Rules[0].Host)
+
+	// the ingress requires a host
+	c.spec.Dashboard.Ingress.Host = ""
+	c.spec.Dashboard.ExternalURL = ""
+	assert.Error(t, c.reconcileDashboardIngress(service))
+	c.spec.Dashboard.ExternalURL = "ceph.example.com"
+	assert.Error(t, c.reconcileDashboardIngress(service))
+
+	// the ingress is deleted when disabled
+	c.spec.Dashboard.Ingress.Enabled = false
+	assert.NoError(t, c.reconcileDashboardIngress(service))
+	_, err = ingresses.Get("rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+	assert.NoError(t, c.reconcileDashboardIngress(service))
+}
@@ -20,8 +20,6 @@ package mgr
 import (
 	"fmt"
 	"path"
-	"strconv"
-	"strings"
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/coreos/pkg/capnslog"
@@ -30,9 +28,12 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/exporter"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/netpolicy"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/util/exec"
@@ -115,7 +116,7 @@ func (c *Cluster) Start() error {
 		mgrConfig := &mgrConfig{
 			DaemonID:     daemonID,
 			ResourceName: resourceName,
-			DataPathMap:  config.NewStatelessDaemonDataPathMap(config.MgrType, daemonID, c.clusterInfo.Namespace, c.spec.DataDirHostPath),
+			DataPathMap:  config.NewStatelessDaemonDataPathMap(config.MgrType, daemonID, c.clusterInfo.Namespace, c.spec.DataDirHostPath).WithHostSocketDir(c.spec.Monitoring.IsExporterEnabled()),
 		}
 
 		// We set the owner reference of the Secret to the Object controller instead of the replicaset
@@ -144,7 +145,7 @@ func (c *Cluster) Start() error {
 			}
 			logger.Infof("deployment for mgr %s already exists. updating if needed", resourceName)
 
-			if err := updateDeploymentAndWait(c.context, c.clusterInfo, d, config.MgrType, mgrConfig.DaemonID, c.spec.SkipUpgradeChecks, false); err != nil {
+			if err := updateDeploymentAndWait(c.context, c.clusterInfo, d, config.MgrType, mgrConfig.DaemonID, client.DaemonUpgradeChecks(&c.spec, config.MgrType)); err != nil {
 				logger.Errorf("failed to update mgr deployment %q. %v", resourceName, err)
 			}
 		}
@@ -159,13 +160,18 @@ func (c *Cluster) Start() error {
 
 	// create the metrics service
 	service := c.MakeMetricsService(AppName, serviceMetricName)
-	if _, err := c.context.Clientset.CoreV1().Services(c.clusterInfo.Namespace).Create(service); err != nil {
-		if !kerrors.IsAlreadyExists(err) {
-			return errors.Wrap(err, "failed to create mgr service")
-		}
-		logger.Infof("mgr metrics service already exists")
-	} else {
-		logger.Infof("mgr metrics service started")
+	if _, err := k8sutil.CreateOrUpdateService(c.context.Clientset, c.clusterInfo.Namespace, service); err != nil {
+		return errors.Wrap(err, "failed to create mgr service")
+	}
+	if err := netpolicy.ReconcileServicePolicy(c.context, &c.spec, service); err != nil {
+		return errors.Wrap(err, "failed to reconcile the network policy of the mgr service")
+	}
+	logger.Infof("mgr metrics service started")
+
+	// create the metrics service of the ceph-exporter daemons, which are deployed on the nodes by the crash controller
+	exporterService, err := exporter.ReconcileService(c.context, c.clusterInfo, &c.spec)
+	if err != nil {
+		return err
 	}
 
 	// enable monitoring if `monitoring: enabled: true`
@@ -177,6 +183,19 @@ func (c *Cluster) Start() error {
 		} else {
 			logger.Infof("servicemonitor enabled")
 		}
+		// scrape the metrics of the csi drivers alongside the ceph metrics
+		if err := csi.EnableServiceMonitor(c.context.Clientset); err != nil {
+			logger.Errorf("failed to enable csi service monitor. %v", err)
+		} else {
+			logger.Infof("csi servicemonitor enabled")
+		}
+		if exporterService != nil {
+			if err := exporter.EnableServiceMonitor(exporterService, path.Join(monitoringPath, exporter.ServiceMonitorFile), &c.spec); err != nil {
+				logger.Errorf("failed to enable ceph-exporter service monitor. %v", err)
+			} else {
+				logger.Infof("ceph-exporter servicemonitor enabled")
+			}
+		}
 		// namespace in which the prometheusRule should be deployed
 		// if left empty, it will be deployed in current namespace
 		namespace := c.spec.Monitoring.RulesNamespace
@@ -201,10 +220,11 @@ func (c *Cluster) configureModules(daemonIDs []string) {
 	// "crash" is part of the "always_on_modules" list as of Octopus
 	if !c.clusterInfo.CephVersion.IsAtLeastOctopus() {
 		startModuleConfiguration("crash", c.enableCrashModule)
-	} else {
-		// The balancer module must be configured on Octopus
-		// It is a bit confusing but as of Octopus modules that are in the "always_on_modules" list
-		// are "just" enabled, but still they must be configured to work properly
+	}
+	// The balancer module must be configured on Octopus
+	// It is a bit confusing but as of Octopus modules that are in the "always_on_modules" list
+	// are "just" enabled, but still they must be configured to work properly
+	if IsBalancerManaged(c.clusterInfo, c.spec.Balancer) {
 		startModuleConfiguration("balancer", c.enableBalancerModule)
 	}
 	startModuleConfiguration("mgr module(s) from the spec", c.configureMgrModules)
@@ -238,21 +258,7 @@ func (c *Cluster) enableCrashModule() error {
 }
 
 func (c *Cluster) enableBalancerModule() error {
-	// The order MATTERS, always configure this module first, then turn it on
-
-	// This sets min compat client to luminous and the balancer module mode
-	err := client.ConfigureBalancerModule(c.context, c.clusterInfo, balancerModuleMode)
-	if err != nil {
-		return errors.Wrapf(err, "failed to configure module %q", balancerModuleName)
-	}
-
-	// This turns "on" the balancer
-	err = client.MgrEnableModule(c.context, c.clusterInfo, balancerModuleName, false)
-	if err != nil {
-		return errors.Wrapf(err, "failed to turn on mgr %q module", balancerModuleName)
-	}
-
-	return nil
+	return ConfigureBalancer(c.context, c.clusterInfo, c.spec.Balancer)
 }
 
 func (c *Cluster) configureMgrModules() error {
@@ -261,7 +267,7 @@ func (c *Cluster) configureMgrModules() error {
 		if module.Name == "" {
 			return errors.New("name not specified for the mgr module configuration")
 		}
-		if wellKnownModule(module.Name) {
+		if wellKnownModule(module.Name) || (module.Name == balancerModuleName && hasBalancerSettings(c.spec.Balancer)) {
 			return errors.Errorf("cannot configure mgr module %q that is configured with other cluster settings", module.Name)
 		}
 		minVersion, versionOK := c.moduleMeetsMinVersion(module.Name)
@@ -344,30 +350,51 @@ func (c *Cluster) EnableServiceMonitor(service *v1.Service) error {
 	serviceMonitor.SetNamespace(namespace)
 	k8sutil.SetOwnerRef(&serviceMonitor.ObjectMeta, &c.clusterInfo.OwnerRef)
 	serviceMonitor.Spec.NamespaceSelector.MatchNames = []string{namespace}
-	serviceMonitor.Spec.Selector.MatchLabels = service.GetLabels()
+	serviceMonitor.Spec.Selector.MatchLabels = controller.AppLabels(AppName, namespace)
+	// the labels of the mgr select the servicemonitor in the prometheus that scrapes it
+	c.applyServiceMetadata(&serviceMonitor.ObjectMeta)
 	if _, err := k8sutil.CreateOrUpdateServiceMonitor(serviceMonitor); err != nil {
 		return errors.Wrap(err, "service monitor could not be enabled")
 	}
 	return nil
 }
 
-// DeployPrometheusRule deploy prometheusRule that adds alerting and/or recording rules to the cluster
+// DeployPrometheusRule deploy prometheusRule that adds alerting and/or recording rules to the cluster.
+// The recommended rules are customized with the rules settings of the monitoring spec, they are removed when the
+// rules are disabled.
 func (c *Cluster) DeployPrometheusRule(name, namespace string) error {
-	version := strconv.Itoa(c.clusterInfo.CephVersion.Major)
-	name = strings.Replace(name, "VERSION", version, 1)
-	prometheusRuleFile := name + ".yaml"
-	prometheusRuleFile = path.Join(monitoringPath, prometheusRuleFile)
-	prometheusRule, err := k8sutil.GetPrometheusRule(prometheusRuleFile)
+	rulesSpec := c.spec.Monitoring.Rules
+	ruleName := versionedRuleName(name, c.clusterInfo.CephVersion.Major)
+	if rulesSpec != nil && rulesSpec.Disabled {
+		logger.Infof("prometheus rules are disabled, removing prometheus rule %q", ruleName)
+		return k8sutil.DeletePrometheusRule(namespace, ruleName)
+	}
+
+	ruleFile, err := prometheusRuleFile(monitoringPath, name, c.clusterInfo.CephVersion.Major)
+	if err != nil {
+		return errors.Wrap(err, "prometheus rule could not be deployed")
+	}
+	prometheusRule, err := k8sutil.GetPrometheusRule(ruleFile)
 	if err != nil {
 		return errors.Wrap(err, "prometheus rule could not be deployed")
 	}
-	prometheusRule.SetName(name)
+	prometheusRule.SetName(ruleName)
 	prometheusRule.SetNamespace(namespace)
 	owners := append(prometheusRule.GetOwnerReferences(), c.clusterInfo.OwnerRef)
 	k8sutil.SetOwnerRefs(&prometheusRule.ObjectMeta, owners)
+	if err := applyPrometheusRulesSpec(prometheusRule, rulesSpec); err != nil {
+		return errors.Wrap(err, "prometheus rule could not be deployed")
+	}
 	if _, err := k8sutil.CreateOrUpdatePrometheusRule(prometheusRule); err != nil {
 		return errors.Wrap(err, "prometheus rule could not be deployed")
 	}
+
+	// the rules of the previous versions of ceph would fire the same alerts
+	for _, outdatedName := range outdatedPrometheusRuleNames(name, c.clusterInfo.CephVersion.Major) {
+		if err := k8sutil.DeletePrometheusRule(namespace, outdatedName); err != nil {
+			logger.Warningf("failed to remove outdated prometheus rule %q. %v", outdatedName, err)
+		}
+	}
 	return nil
 }
 
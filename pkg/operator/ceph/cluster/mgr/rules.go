@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// the oldest major version of ceph with prometheus rules
+const oldestRulesMajorVersion = 14
+
+// versionedRuleName returns the name of the prometheus rules of a major version of ceph
+func versionedRuleName(name string, major int) string {
+	return strings.Replace(name, "VERSION", strconv.Itoa(major), 1)
+}
+
+// prometheusRuleFile returns the file of the recommended prometheus rules of a major version of ceph. The rules of
+// the newest previous version are used when the version has no rules of its own yet.
+func prometheusRuleFile(dir, name string, major int) (string, error) {
+	for version := major; version >= oldestRulesMajorVersion; version-- {
+		file := path.Join(dir, versionedRuleName(name, version)+".yaml")
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", errors.Errorf("no prometheus rules found for ceph version %d", major)
+}
+
+// outdatedPrometheusRuleNames returns the names of the prometheus rules of the previous major versions of ceph
+func outdatedPrometheusRuleNames(name string, major int) []string {
+	names := []string{}
+	for version := oldestRulesMajorVersion; version < major; version++ {
+		names = append(names, versionedRuleName(name, version))
+	}
+	return names
+}
+
+// applyPrometheusRulesSpec merges the labels of the rules and the overrides of the alerts in the recommended rules and
+// removes the suppressed alerts
+func applyPrometheusRulesSpec(prometheusRule *monitoringv1.PrometheusRule, rulesSpec *cephv1.PrometheusRulesSpec) error {
+	if rulesSpec == nil {
+		return nil
+	}
+
+	if len(rulesSpec.Labels) > 0 && prometheusRule.Labels == nil {
+		prometheusRule.Labels = map[string]string{}
+	}
+	for key, value := range rulesSpec.Labels {
+		prometheusRule.Labels[key] = value
+	}
+
+	suppressed := map[string]bool{}
+	for _, alert := range rulesSpec.Suppressed {
+		suppressed[alert] = false
+	}
+	overrides := map[string]*cephv1.PrometheusAlertOverride{}
+	for i, override := range rulesSpec.Overrides {
+		if override.Alert == "" {
+			return errors.Errorf("the name of the alert of prometheus rule override %d is not set", i)
+		}
+		overrides[override.Alert] = &rulesSpec.Overrides[i]
+	}
+
+	applied := map[string]bool{}
+	for i := range prometheusRule.Spec.Groups {
+		group := &prometheusRule.Spec.Groups[i]
+		rules := []monitoringv1.Rule{}
+		for _, rule := range group.Rules {
+			if _, ok := suppressed[rule.Alert]; ok && rule.Alert != "" {
+				suppressed[rule.Alert] = true
+				continue
+			}
+			if override, ok := overrides[rule.Alert]; ok && rule.Alert != "" {
+				applyAlertOverride(&rule, override)
+				applied[rule.Alert] = true
+			}
+			rules = append(rules, rule)
+		}
+		group.Rules = rules
+	}
+
+	// the alerts differ from one version of ceph to the other, the unknown alerts are not an error
+	for alert, found := range suppressed {
+		if !found {
+			logger.Warningf("suppressed alert %q is not a recommended alert", alert)
+		}
+	}
+	for alert := range overrides {
+		if !applied[alert] {
+			logger.Warningf("overridden alert %q is not a recommended alert", alert)
+		}
+	}
+	return nil
+}
+
+func applyAlertOverride(rule *monitoringv1.Rule, override *cephv1.PrometheusAlertOverride) {
+	if override.Expr != "" {
+		rule.Expr = intstr.FromString(override.Expr)
+	}
+	if override.For != "" {
+		rule.For = override.For
+	}
+	rule.Labels = mergeRuleMap(rule.Labels, override.Labels)
+	rule.Annotations = mergeRuleMap(rule.Annotations, override.Annotations)
+}
+
+func mergeRuleMap(current, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return current
+	}
+	merged := map[string]string{}
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
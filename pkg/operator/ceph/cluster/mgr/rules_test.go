@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"testing"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMonitoringPath = "../../../../../cluster/examples/kubernetes/ceph/monitoring"
+
+func findAlert(rule *monitoringv1.PrometheusRule, alert string) *monitoringv1.Rule {
+	for _, group := range rule.Spec.Groups {
+		for i := range group.Rules {
+			if group.Rules[i].Alert == alert {
+				return &group.Rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+func TestPrometheusRuleFile(t *testing.T) {
+	file, err := prometheusRuleFile(testMonitoringPath, prometheusRuleName, 14)
+	assert.NoError(t, err)
+	assert.Equal(t, testMonitoringPath+"/prometheus-ceph-v14-rules.yaml", file)
+
+	// the newest rules are used for the versions without rules
+	_, err = prometheusRuleFile(testMonitoringPath, prometheusRuleName, 17)
+	assert.NoError(t, err)
+
+	_, err = prometheusRuleFile(testMonitoringPath, prometheusRuleName, 13)
+	assert.Error(t, err)
+
+	assert.Equal(t, "prometheus-ceph-v17-rules", versionedRuleName(prometheusRuleName, 17))
+	assert.Equal(t, []string{"prometheus-ceph-v14-rules", "prometheus-ceph-v15-rules"}, outdatedPrometheusRuleNames(prometheusRuleName, 16))
+	assert.Equal(t, 0, len(outdatedPrometheusRuleNames(prometheusRuleName, 14)))
+}
+
+func TestApplyPrometheusRulesSpec(t *testing.T) {
+	load := func() *monitoringv1.PrometheusRule {
+		rule, err := k8sutil.GetPrometheusRule(testMonitoringPath + "/prometheus-ceph-v14-rules.yaml")
+		assert.NoError(t, err)
+		return rule
+	}
+
+	// the recommended rules are unchanged without settings
+	rule := load()
+	assert.NoError(t, applyPrometheusRulesSpec(rule, nil))
+	assert.Equal(t, load(), rule)
+
+	rulesSpec := &cephv1.PrometheusRulesSpec{
+		Labels:     map[string]string{"prometheus": "my-prometheus"},
+		Suppressed: []string{"CephMgrIsMissingReplicas", "UnknownAlert"},
+		Overrides: []cephv1.PrometheusAlertOverride{
+			{
+				Alert:       "CephMgrIsAbsent",
+				For:         "10m",
+				Labels:      map[string]string{"severity": "warning", "team": "storage"},
+				Annotations: map[string]string{"runbook_url": "https://example.com/runbook"},
+			},
+		},
+	}
+	rule = load()
+	assert.NoError(t, applyPrometheusRulesSpec(rule, rulesSpec))
+	assert.Equal(t, "my-prometheus", rule.Labels["prometheus"])
+	assert.Equal(t, "alert-rules", rule.Labels["role"])
+	assert.Nil(t, findAlert(rule, "CephMgrIsMissingReplicas"))
+
+	alert := findAlert(rule, "CephMgrIsAbsent")
+	assert.NotNil(t, alert)
+	assert.Equal(t, "10m", alert.For)
+	assert.Equal(t, "warning", alert.Labels["severity"])
+	assert.Equal(t, "storage", alert.Labels["team"])
+	assert.Equal(t, "https://example.com/runbook", alert.Annotations["runbook_url"])
+	assert.Equal(t, "ceph", alert.Annotations["storage_type"])
+	// the expression is kept when it is not overridden
+	assert.Equal(t, findAlert(load(), "CephMgrIsAbsent").Expr, alert.Expr)
+
+	rulesSpec.Overrides = []cephv1.PrometheusAlertOverride{{Alert: "CephMgrIsAbsent", Expr: "up == 0"}}
+	rule = load()
+	assert.NoError(t, applyPrometheusRulesSpec(rule, rulesSpec))
+	assert.Equal(t, "up == 0", findAlert(rule, "CephMgrIsAbsent").Expr.String())
+
+	// an override must name its alert
+	rulesSpec.Overrides = []cephv1.PrometheusAlertOverride{{For: "1m"}}
+	assert.Error(t, applyPrometheusRulesSpec(load(), rulesSpec))
+}
@@ -64,9 +64,12 @@ func (c *Cluster) makeDeployment(mgrConfig *mgrConfig) (*apps.Deployment, error)
 			Volumes:            controller.DaemonVolumes(mgrConfig.DataPathMap, mgrConfig.ResourceName),
 			HostNetwork:        c.spec.Network.IsHost(),
 			PriorityClassName:  cephv1.GetMgrPriorityClassName(c.spec.PriorityClassNames),
+			SecurityContext:    cephv1.GetPodSecurityContext(c.spec.Security.PodSecurityContexts, cephv1.KeyMgr),
 		},
 	}
 
+	controller.ApplyLogCollector(&podSpec.Spec, config.MgrType, "mgr."+mgrConfig.DaemonID, &c.spec, mgrConfig.DataPathMap)
+
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec.Spec)
 
@@ -75,9 +78,11 @@ func (c *Cluster) makeDeployment(mgrConfig *mgrConfig) (*apps.Deployment, error)
 		c.makeSetServerAddrInitContainer(mgrConfig, "prometheus"),
 	}...)
 
-	// ceph config set commands want admin keyring
-	podSpec.Spec.Volumes = append(podSpec.Spec.Volumes,
-		keyring.Volume().Admin())
+	// ceph config set commands want admin keyring, unless the mgr key is used instead
+	if !c.spec.Security.RestrictAdminKey {
+		podSpec.Spec.Volumes = append(podSpec.Spec.Volumes,
+			keyring.Volume().Admin())
+	}
 	if c.spec.Network.IsHost() {
 		podSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
 	} else if c.spec.Network.NetworkSpec.IsMultus() {
@@ -87,6 +92,8 @@ func (c *Cluster) makeDeployment(mgrConfig *mgrConfig) (*apps.Deployment, error)
 	}
 
 	cephv1.GetMgrAnnotations(c.spec.Annotations).ApplyToObjectMeta(&podSpec.ObjectMeta)
+	cephv1.GetMgrLabels(c.spec.Labels).ApplyToObjectMeta(&podSpec.ObjectMeta)
+	config.ApplyConnectionsAnnotation(c.spec.Network, &podSpec.ObjectMeta)
 	c.applyPrometheusAnnotations(&podSpec.ObjectMeta)
 	cephv1.GetMgrPlacement(c.spec.Placement).ApplyToPodSpec(&podSpec.Spec)
 
@@ -109,9 +116,11 @@ func (c *Cluster) makeDeployment(mgrConfig *mgrConfig) (*apps.Deployment, error)
 			},
 		},
 	}
+	cephv1.GetMgrLabels(c.spec.Labels).ApplyToObjectMeta(&d.ObjectMeta)
 	k8sutil.AddRookVersionLabelToDeployment(d)
 	controller.AddCephVersionLabelToDeployment(c.clusterInfo.CephVersion, d)
 	k8sutil.SetOwnerRef(&d.ObjectMeta, &c.clusterInfo.OwnerRef)
+	k8sutil.ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
 	return d, nil
 }
 
@@ -173,20 +182,31 @@ func (c *Cluster) makeSetServerAddrInitContainer(mgrConfig *mgrConfig, mgrModule
 	cfgSetArgs = append(cfgSetArgs, "--force")
 	cfgSetArgs = append(cfgSetArgs, "--verbose")
 
+	flags := controller.AdminFlags(c.clusterInfo)
+	volumeMounts := append(
+		controller.DaemonVolumeMounts(mgrConfig.DataPathMap, mgrConfig.ResourceName),
+		keyring.VolumeMount().Admin(),
+	)
+	if c.spec.Security.RestrictAdminKey {
+		// the mgr key is allowed to set the options of the mgr modules, its keyring is mounted with the daemon volumes
+		flags = append(
+			config.DefaultFlags(c.clusterInfo.FSID, keyring.VolumeMount().KeyringFilePath()),
+			config.NewFlag("name", fmt.Sprintf("mgr.%s", mgrConfig.DaemonID)),
+		)
+		volumeMounts = controller.DaemonVolumeMounts(mgrConfig.DataPathMap, mgrConfig.ResourceName)
+	}
+
 	container := v1.Container{
 		Name: "init-set-" + strings.ToLower(mgrModule) + "-server-addr",
 		Command: []string{
 			"ceph",
 		},
 		Args: append(
-			controller.AdminFlags(c.clusterInfo),
+			flags,
 			cfgSetArgs...,
 		),
-		Image: c.spec.CephVersion.Image,
-		VolumeMounts: append(
-			controller.DaemonVolumeMounts(mgrConfig.DataPathMap, mgrConfig.ResourceName),
-			keyring.VolumeMount().Admin(),
-		),
+		Image:        c.spec.CephVersion.Image,
+		VolumeMounts: volumeMounts,
 		Env: append(
 			append(
 				controller.DaemonEnvVars(c.spec.CephVersion.Image),
@@ -243,7 +263,7 @@ func (c *Cluster) makeMgrDaemonContainer(mgrConfig *mgrConfig) v1.Container {
 	}
 
 	// If the liveness probe is enabled
-	container = config.ConfigureLivenessProbe(rookcephv1.KeyMgr, container, c.spec.HealthCheck)
+	container = config.ConfigureProbes(rookcephv1.KeyMgr, container, c.spec.HealthCheck)
 
 	// If host networking is enabled, we don't need a bind addr that is different from the public addr
 	if !c.spec.Network.IsHost() {
@@ -290,6 +310,8 @@ func (c *Cluster) MakeMetricsService(name, servicePortMetricName string) *v1.Ser
 		},
 	}
 
+	c.applyServiceMetadata(&svc.ObjectMeta)
+	config.ApplyIPFamily(c.spec.Network, svc)
 	k8sutil.SetOwnerRef(&svc.ObjectMeta, &c.clusterInfo.OwnerRef)
 	return svc
 }
@@ -318,10 +340,18 @@ func (c *Cluster) makeDashboardService(name string) *v1.Service {
 			},
 		},
 	}
+	c.applyServiceMetadata(&svc.ObjectMeta)
+	config.ApplyIPFamily(c.spec.Network, svc)
 	k8sutil.SetOwnerRef(&svc.ObjectMeta, &c.clusterInfo.OwnerRef)
 	return svc
 }
 
+// applyServiceMetadata adds the annotations and labels of the mgr to the metadata of a service
+func (c *Cluster) applyServiceMetadata(objectMeta *metav1.ObjectMeta) {
+	cephv1.GetMgrAnnotations(c.spec.Annotations).ApplyToObjectMeta(objectMeta)
+	cephv1.GetMgrLabels(c.spec.Labels).ApplyToObjectMeta(objectMeta)
+}
+
 func (c *Cluster) getPodLabels(daemonName string) map[string]string {
 	labels := controller.PodLabels(AppName, c.clusterInfo.Namespace, "mgr", daemonName)
 	// leave "instance" key for legacy usage
@@ -88,6 +88,48 @@ func TestServiceSpec(t *testing.T) {
 	assert.NotNil(t, s)
 	assert.Equal(t, "rook-mgr", s.Name)
 	assert.Equal(t, 1, len(s.Spec.Ports))
+
+	// the annotations and labels of the mgr are added to the services but not to their selectors
+	c.spec.Annotations = rookv1.AnnotationsSpec{cephv1.KeyMgr: {"external-dns.alpha.kubernetes.io/hostname": "dashboard.example.com"}}
+	c.spec.Labels = rookv1.LabelsSpec{"all": {"app": "other", "mesh": "enabled"}}
+	s = c.makeDashboardService(AppName)
+	assert.Equal(t, "dashboard.example.com", s.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+	assert.Equal(t, "enabled", s.Labels["mesh"])
+	assert.Equal(t, AppName, s.Labels["app"])
+	assert.Equal(t, "", s.Spec.Selector["mesh"])
+}
+
+func TestPodSpecRestrictAdminKey(t *testing.T) {
+	clientset := optest.New(t, 1)
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", FSID: "myfsid"}
+	clusterSpec := cephv1.ClusterSpec{
+		CephVersion:     cephv1.CephVersionSpec{Image: "ceph/ceph:myceph"},
+		DataDirHostPath: "/var/lib/rook/",
+		Security:        cephv1.SecuritySpec{RestrictAdminKey: true},
+	}
+	c := New(&clusterd.Context{Clientset: clientset}, clusterInfo, clusterSpec, "rook/rook:myversion")
+
+	mgrTestConfig := mgrConfig{
+		DaemonID:     "a",
+		ResourceName: "rook-ceph-mgr-a",
+		DataPathMap:  config.NewStatelessDaemonDataPathMap(config.MgrType, "a", "rook-ceph", "/var/lib/rook/"),
+	}
+
+	d, err := c.makeDeployment(&mgrTestConfig)
+	assert.NoError(t, err)
+	for _, volume := range d.Spec.Template.Spec.Volumes {
+		if volume.Secret != nil {
+			assert.NotEqual(t, "rook-ceph-admin-keyring", volume.Secret.SecretName)
+		}
+	}
+	// the mgr key sets the server address of the modules
+	initContainer := d.Spec.Template.Spec.InitContainers[1]
+	assert.Equal(t, "init-set-dashboard-server-addr", initContainer.Name)
+	assert.Contains(t, initContainer.Args, "--name=mgr.a")
+	assert.Contains(t, initContainer.Args, "--keyring=/etc/ceph/keyring-store/keyring")
+
+	podTemplate := cephtest.NewPodTemplateSpecTester(t, &d.Spec.Template)
+	podTemplate.Spec().AssertVolumesMeetCephRequirements(config.MgrType, "a")
 }
 
 func TestHostNetwork(t *testing.T) {
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+)
+
+// configureDashboardSSO sets up the single sign-on of the dashboard with the identity provider of the spec, and
+// disables it when removed from the spec
+func (c *Cluster) configureDashboardSSO() error {
+	sso := c.spec.Dashboard.SSO
+	if sso == nil || sso.SAML2 == nil {
+		enabled, err := c.dashboardSSOEnabled()
+		if err != nil || !enabled {
+			return err
+		}
+		logger.Infof("disabling the single sign-on of the dashboard")
+		return c.runDashboardSSOCommand("disable")
+	}
+
+	externalURL, err := c.dashboardExternalURL()
+	if err != nil {
+		return err
+	}
+	if externalURL == nil {
+		return errors.New("the single sign-on of the dashboard requires the external url of the dashboard")
+	}
+	if sso.SAML2.IdPMetadata == "" {
+		return errors.New("the SAML2 single sign-on of the dashboard requires the metadata of the identity provider")
+	}
+
+	// the optional arguments are positional, the username attribute is passed with its default "uid" before the entity
+	args := []string{"setup", "saml2", strings.TrimSuffix(externalURL.String(), "/"), sso.SAML2.IdPMetadata}
+	if sso.SAML2.UsernameAttribute != "" || sso.SAML2.EntityID != "" {
+		usernameAttribute := sso.SAML2.UsernameAttribute
+		if usernameAttribute == "" {
+			usernameAttribute = "uid"
+		}
+		args = append(args, usernameAttribute)
+	}
+	if sso.SAML2.EntityID != "" {
+		args = append(args, sso.SAML2.EntityID)
+	}
+	if err := c.runDashboardSSOCommand(args...); err != nil {
+		return err
+	}
+	if err := c.runDashboardSSOCommand("enable", "saml2"); err != nil {
+		return err
+	}
+	logger.Infof("dashboard single sign-on enabled with the SAML2 identity provider")
+	return nil
+}
+
+func (c *Cluster) dashboardSSOEnabled() (bool, error) {
+	args := []string{"dashboard", "sso", "status"}
+	output, err := client.NewCephCommand(c.context, c.clusterInfo, args).RunWithTimeout(client.CmdExecuteTimeout)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get the single sign-on status of the dashboard")
+	}
+	// the status is `SSO is "enabled" with "SAML2" protocol.` or `SSO is "disabled".`
+	return strings.Contains(string(output), `"enabled"`), nil
+}
+
+func (c *Cluster) runDashboardSSOCommand(args ...string) error {
+	args = append([]string{"dashboard", "sso"}, args...)
+	// retry a few times in the case that the dashboard module restarted and is not ready to accept commands
+	output, err := client.ExecuteCephCommandWithRetry(func() (string, []byte, error) {
+		output, err := client.NewCephCommand(c.context, c.clusterInfo, args).RunWithTimeout(client.CmdExecuteTimeout)
+		return "dashboard sso " + args[2], output, err
+	}, c.exitCode, 5, invalidArgErrorCode, dashboardInitWaitTime)
+	if err != nil {
+		return errors.Wrapf(err, "failed to run dashboard sso %s", args[2])
+	}
+	// the output is only written to the debug log since the setup outputs the metadata of the provider
+	logger.Debugf("dashboard sso %s: %s", args[2], string(output))
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureDashboardSSO(t *testing.T) {
+	status := `SSO is "disabled".`
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFileTimeout: func(timeout time.Duration, command, outfileArg string, args ...string) (string, error) {
+			if args[0] == "dashboard" && args[1] == "sso" {
+				if args[2] == "status" {
+					return status, nil
+				}
+				// the command without the connection args
+				command := []string{}
+				for _, arg := range args[2:] {
+					if strings.HasPrefix(arg, "--connect-timeout") {
+						break
+					}
+					command = append(command, arg)
+				}
+				commands = append(commands, strings.Join(command, " "))
+			}
+			return "", nil
+		},
+	}
+	c := &Cluster{
+		context:     &clusterd.Context{Executor: executor},
+		clusterInfo: &cephclient.ClusterInfo{Namespace: "ns"},
+		exitCode:    func(err error) (int, bool) { return 0, false },
+	}
+
+	// the sso is not disabled if not enabled
+	assert.NoError(t, c.configureDashboardSSO())
+	assert.Empty(t, commands)
+
+	// the sso requires the external url and the metadata of the provider
+	c.spec.Dashboard.SSO = &cephv1.DashboardSSOSpec{SAML2: &cephv1.DashboardSAML2Spec{IdPMetadata: "https://idp.example.com/metadata"}}
+	assert.Error(t, c.configureDashboardSSO())
+	c.spec.Dashboard.ExternalURL = "https://ceph.example.com/"
+	c.spec.Dashboard.SSO.SAML2.IdPMetadata = ""
+	assert.Error(t, c.configureDashboardSSO())
+	assert.Empty(t, commands)
+
+	c.spec.Dashboard.SSO.SAML2.IdPMetadata = "https://idp.example.com/metadata"
+	assert.NoError(t, c.configureDashboardSSO())
+	assert.Equal(t, []string{
+		"setup saml2 https://ceph.example.com https://idp.example.com/metadata",
+		"enable saml2",
+	}, commands)
+
+	// the username attribute is set before the entity
+	commands = []string{}
+	c.spec.Dashboard.SSO.SAML2.EntityID = "https://idp.example.com"
+	assert.NoError(t, c.configureDashboardSSO())
+	assert.Equal(t, "setup saml2 https://ceph.example.com https://idp.example.com/metadata uid https://idp.example.com", commands[0])
+
+	// the sso is disabled when removed from the spec
+	commands = []string{}
+	status = `SSO is "enabled" with "SAML2" protocol.`
+	c.spec.Dashboard.SSO = nil
+	assert.NoError(t, c.configureDashboardSSO())
+	assert.Equal(t, []string{"disable"}, commands)
+}
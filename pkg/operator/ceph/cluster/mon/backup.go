@@ -0,0 +1,315 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackupName is the name of the CronJob exporting the mon store
+	BackupName            = "rook-ceph-mon-backup"
+	defaultBackupSchedule = "0 1 * * *"
+	defaultBackupKeep     = 7
+	backupMountPath       = "/backup"
+	backupResourcesPath   = "/etc/rook-backup"
+	// the S3 credentials keys of the credentials secret, the keys of the secrets of the object bucket claims
+	backupAccessKeyName = "AWS_ACCESS_KEY_ID"
+	backupSecretKeyName = "AWS_SECRET_ACCESS_KEY"
+)
+
+// the secrets and configmaps exported with the mon store, they are needed to connect to the mons again
+var (
+	backupSecrets    = []string{AppName}
+	backupConfigMaps = []string{EndpointConfigMapName, k8sutil.ConfigOverrideName}
+)
+
+// the mon store is exported with the ceph CLI since the store of a running mon cannot be copied consistently. The
+// kubernetes resources are mounted in the pod, the symlinks of the mounted files are followed to copy their content.
+var backupScript = `
+set -o errexit
+set -o pipefail
+shopt -s nullglob
+
+name="$ROOK_BACKUP_NAME-$(date -u +%Y%m%dT%H%M%SZ)"
+dir="/tmp/$name"
+mkdir -p "$dir/ceph" "$dir/kubernetes"
+
+echo "exporting the mon store of cluster $ROOK_BACKUP_CLUSTER"
+ceph mon getmap -o "$dir/ceph/monmap"
+ceph osd getmap -o "$dir/ceph/osdmap"
+ceph osd getcrushmap -o "$dir/ceph/crushmap"
+ceph fs dump --format json > "$dir/ceph/fsmap.json"
+ceph mgr dump --format json > "$dir/ceph/mgrmap.json"
+ceph auth export -o "$dir/ceph/auth.keyring"
+ceph config dump --format json > "$dir/ceph/config.json"
+ceph config-key dump > "$dir/ceph/config-key.json"
+
+for resource in ` + backupResourcesPath + `/*/*; do
+  files=("$resource"/*)
+  if [ ${#files[@]} -eq 0 ]; then
+    continue
+  fi
+  target="$dir/kubernetes/${resource#` + backupResourcesPath + `/}"
+  mkdir -p "$target"
+  cp -L "${files[@]}" "$target/"
+done
+
+archive="/tmp/$name.tar.gz"
+tar -czf "$archive" -C /tmp "$name"
+
+if [ -n "$ROOK_BACKUP_KEEP" ]; then
+  cp "$archive" ` + backupMountPath + `/
+  echo "backup $name written to the pvc"
+  for old in $(ls -1 ` + backupMountPath + `/"$ROOK_BACKUP_NAME"-*.tar.gz | head -n -"$ROOK_BACKUP_KEEP"); do
+    echo "removing backup $old beyond the $ROOK_BACKUP_KEEP most recent ones"
+    rm -f "$old"
+  done
+  exit 0
+fi
+
+python3 - "$archive" "$ROOK_BACKUP_CLUSTER/$name.tar.gz" <<'EOF'
+import datetime, hashlib, hmac, os, sys, urllib.parse, urllib.request
+
+archive, key = sys.argv[1], sys.argv[2]
+endpoint = os.environ["ROOK_BACKUP_S3_ENDPOINT"].rstrip("/")
+region = os.environ.get("ROOK_BACKUP_S3_REGION") or "us-east-1"
+with open(archive, "rb") as f:
+    body = f.read()
+
+# the upload is signed with the AWS signature version 4
+now = datetime.datetime.utcnow()
+date, datetime_ = now.strftime("%Y%m%d"), now.strftime("%Y%m%dT%H%M%SZ")
+uri = "/%s/%s" % (os.environ["ROOK_BACKUP_S3_BUCKET"], urllib.parse.quote(key))
+payload = hashlib.sha256(body).hexdigest()
+headers = {"host": urllib.parse.urlparse(endpoint).netloc, "x-amz-content-sha256": payload, "x-amz-date": datetime_}
+signed = ";".join(sorted(headers))
+canonical = "\n".join(["PUT", uri, "", "".join("%s:%s\n" % (h, headers[h]) for h in sorted(headers)), signed, payload])
+scope = "%s/%s/s3/aws4_request" % (date, region)
+to_sign = "\n".join(["AWS4-HMAC-SHA256", datetime_, scope, hashlib.sha256(canonical.encode()).hexdigest()])
+
+def sign(k, msg):
+    return hmac.new(k, msg.encode(), hashlib.sha256).digest()
+
+signing_key = sign(sign(sign(sign(("AWS4" + os.environ["` + backupSecretKeyName + `"]).encode(), date), region), "s3"), "aws4_request")
+signature = hmac.new(signing_key, to_sign.encode(), hashlib.sha256).hexdigest()
+headers["authorization"] = "AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s" % (
+    os.environ["` + backupAccessKeyName + `"], scope, signed, signature)
+urllib.request.urlopen(urllib.request.Request(endpoint + uri, data=body, method="PUT", headers=headers))
+print("backup uploaded to %s%s" % (endpoint, uri))
+EOF
+`
+
+// validateBackupSpec checks that the backups have a single target
+func validateBackupSpec(spec *cephv1.MonBackupSpec) error {
+	if (spec.PersistentVolumeClaim == "") == (spec.S3 == nil) {
+		return errors.New("the mon backups need either a persistentVolumeClaim or a s3 bucket")
+	}
+	if spec.S3 != nil && (spec.S3.Endpoint == "" || spec.S3.Bucket == "" || spec.S3.CredentialsSecret == "") {
+		return errors.New("the s3 bucket of the mon backups needs an endpoint, a bucket and a credentialsSecret")
+	}
+	return nil
+}
+
+// reconcileBackup creates or updates the CronJob exporting the mon store, or deletes it when the backups are not
+// configured
+func (c *Cluster) reconcileBackup() error {
+	cronJobs := c.context.Clientset.BatchV1beta1().CronJobs(c.Namespace)
+	backupSpec := c.spec.Mon.Backup
+	if backupSpec == nil || c.spec.Security.RestrictAdminKey {
+		if backupSpec != nil {
+			logger.Warningf("the mon store is not backed up since the backup needs the admin key which is restricted")
+		}
+		err := cronJobs.Delete(BackupName, &metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete mon backup cronjob %q", BackupName)
+		}
+		return nil
+	}
+	if err := validateBackupSpec(backupSpec); err != nil {
+		return err
+	}
+
+	cronJob := c.makeBackupCronJob()
+	existing, err := cronJobs.Get(BackupName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get mon backup cronjob %q", BackupName)
+		}
+		if _, err := cronJobs.Create(cronJob); err != nil {
+			return errors.Wrapf(err, "failed to create mon backup cronjob %q", BackupName)
+		}
+		logger.Infof("mon backup cronjob %q created", BackupName)
+		return nil
+	}
+	existing.Labels = cronJob.Labels
+	existing.Spec = cronJob.Spec
+	k8sutil.ApplyClusterInheritedMetadata(c.spec.InheritedMetadata.Labels, c.spec.InheritedMetadata.Annotations, existing)
+	if _, err := cronJobs.Update(existing); err != nil {
+		return errors.Wrapf(err, "failed to update mon backup cronjob %q", BackupName)
+	}
+	logger.Debugf("mon backup cronjob %q updated", BackupName)
+	return nil
+}
+
+func (c *Cluster) makeBackupCronJob() *batchv1beta1.CronJob {
+	labels := controller.AppLabels(BackupName, c.Namespace)
+	deadlineSeconds := int64(300)
+	backoffLimit := int32(3)
+	schedule := c.spec.Mon.Backup.Schedule
+	if schedule == "" {
+		schedule = defaultBackupSchedule
+	}
+
+	dataPathMap := config.NewDatalessDaemonDataPathMap(c.Namespace, c.spec.DataDirHostPath)
+	volumes := append(controller.DaemonVolumesBase(dataPathMap, ""), keyring.Volume().Admin())
+	volumes = append(volumes, backupResourceVolumes()...)
+	if claim := c.spec.Mon.Backup.PersistentVolumeClaim; claim != "" {
+		volumes = append(volumes, v1.Volume{
+			Name:         "backup",
+			VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		})
+	}
+
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BackupName,
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			// a backup which could not start in time is skipped until the next one
+			StartingDeadlineSeconds: &deadlineSeconds,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: v1.PodSpec{
+							Containers:        []v1.Container{c.makeBackupContainer()},
+							RestartPolicy:     v1.RestartPolicyNever,
+							HostNetwork:       c.spec.Network.IsHost(),
+							PriorityClassName: cephv1.GetMonPriorityClassName(c.spec.PriorityClassNames),
+							SecurityContext:   cephv1.GetPodSecurityContext(c.spec.Security.PodSecurityContexts, cephv1.KeyMon),
+							Volumes:           volumes,
+						},
+					},
+				},
+			},
+		},
+	}
+	k8sutil.SetOwnerRef(&cronJob.ObjectMeta, &c.ownerRef)
+	k8sutil.ApplyClusterInheritedMetadata(c.spec.InheritedMetadata.Labels, c.spec.InheritedMetadata.Annotations,
+		&cronJob.ObjectMeta, &cronJob.Spec.JobTemplate.ObjectMeta, &cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta)
+	if c.spec.Network.IsMultus() {
+		if err := k8sutil.ApplyMultus(c.spec.Network.NetworkSpec, &cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta); err != nil {
+			logger.Errorf("failed to apply the multus networks to the mon backup cronjob. %v", err)
+		}
+	}
+	return cronJob
+}
+
+func (c *Cluster) makeBackupContainer() v1.Container {
+	backupSpec := c.spec.Mon.Backup
+	cephImage := c.spec.CephVersion.Image
+	dataPathMap := config.NewDatalessDaemonDataPathMap(c.Namespace, c.spec.DataDirHostPath)
+
+	envVars := append(controller.DaemonEnvVars(cephImage),
+		v1.EnvVar{Name: "CEPH_ARGS", Value: fmt.Sprintf("-m $(ROOK_CEPH_MON_HOST) -k %s", keyring.VolumeMount().AdminKeyringFilePath())},
+		v1.EnvVar{Name: "ROOK_BACKUP_NAME", Value: BackupName},
+		v1.EnvVar{Name: "ROOK_BACKUP_CLUSTER", Value: c.Namespace},
+	)
+	volumeMounts := append(controller.DaemonVolumeMounts(dataPathMap, ""), keyring.VolumeMount().Admin())
+	volumeMounts = append(volumeMounts, backupResourceVolumeMounts()...)
+	if backupSpec.PersistentVolumeClaim != "" {
+		keep := backupSpec.Keep
+		if keep == 0 {
+			keep = defaultBackupKeep
+		}
+		envVars = append(envVars, v1.EnvVar{Name: "ROOK_BACKUP_KEEP", Value: strconv.FormatUint(uint64(keep), 10)})
+		volumeMounts = append(volumeMounts, v1.VolumeMount{Name: "backup", MountPath: backupMountPath})
+	} else {
+		credentials := v1.LocalObjectReference{Name: backupSpec.S3.CredentialsSecret}
+		envVars = append(envVars,
+			v1.EnvVar{Name: "ROOK_BACKUP_S3_ENDPOINT", Value: backupSpec.S3.Endpoint},
+			v1.EnvVar{Name: "ROOK_BACKUP_S3_BUCKET", Value: backupSpec.S3.Bucket},
+			v1.EnvVar{Name: "ROOK_BACKUP_S3_REGION", Value: backupSpec.S3.Region},
+			v1.EnvVar{Name: backupAccessKeyName, ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: credentials, Key: backupAccessKeyName}}},
+			v1.EnvVar{Name: backupSecretKeyName, ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: credentials, Key: backupSecretKeyName}}},
+		)
+	}
+
+	return v1.Container{
+		Name:            "mon-backup",
+		Command:         []string{"/bin/bash", "-c", backupScript},
+		Image:           cephImage,
+		Env:             envVars,
+		VolumeMounts:    volumeMounts,
+		SecurityContext: PodSecurityContext(),
+	}
+}
+
+// backupResourceVolumes returns the volumes of the exported secrets and configmaps, they are optional since the
+// override configmap may not exist
+func backupResourceVolumes() []v1.Volume {
+	optional := true
+	volumes := []v1.Volume{}
+	for _, name := range backupSecrets {
+		volumes = append(volumes, v1.Volume{
+			Name:         "backup-secret-" + name,
+			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: name, Optional: &optional}},
+		})
+	}
+	for _, name := range backupConfigMaps {
+		volumes = append(volumes, v1.Volume{
+			Name: "backup-configmap-" + name,
+			VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: name},
+				Optional:             &optional,
+			}},
+		})
+	}
+	return volumes
+}
+
+func backupResourceVolumeMounts() []v1.VolumeMount {
+	mounts := []v1.VolumeMount{}
+	for _, name := range backupSecrets {
+		mounts = append(mounts, v1.VolumeMount{Name: "backup-secret-" + name, MountPath: path.Join(backupResourcesPath, "secrets", name), ReadOnly: true})
+	}
+	for _, name := range backupConfigMaps {
+		mounts = append(mounts, v1.VolumeMount{Name: "backup-configmap-" + name, MountPath: path.Join(backupResourcesPath, "configmaps", name), ReadOnly: true})
+	}
+	return mounts
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateBackupSpec(t *testing.T) {
+	assert.Error(t, validateBackupSpec(&cephv1.MonBackupSpec{}))
+	assert.NoError(t, validateBackupSpec(&cephv1.MonBackupSpec{PersistentVolumeClaim: "backups"}))
+
+	s3 := &cephv1.MonBackupS3Spec{Endpoint: "http://rgw", Bucket: "backups", CredentialsSecret: "creds"}
+	assert.NoError(t, validateBackupSpec(&cephv1.MonBackupSpec{S3: s3}))
+	// a single target
+	assert.Error(t, validateBackupSpec(&cephv1.MonBackupSpec{PersistentVolumeClaim: "backups", S3: s3}))
+	// the bucket is required
+	assert.Error(t, validateBackupSpec(&cephv1.MonBackupSpec{S3: &cephv1.MonBackupS3Spec{Endpoint: "http://rgw", CredentialsSecret: "creds"}}))
+}
+
+func TestReconcileBackup(t *testing.T) {
+	clientset := test.New(t, 1)
+	c := newCluster(&clusterd.Context{Clientset: clientset}, "ns", false, v1.ResourceRequirements{})
+	c.spec.CephVersion.Image = "ceph/ceph:v15"
+	cronJobs := clientset.BatchV1beta1().CronJobs("ns")
+
+	// no backup by default
+	assert.NoError(t, c.reconcileBackup())
+	_, err := cronJobs.Get(BackupName, metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+
+	// the backups are written to the pvc
+	c.spec.Mon.Backup = &cephv1.MonBackupSpec{PersistentVolumeClaim: "backups"}
+	assert.NoError(t, c.reconcileBackup())
+	cronJob, err := cronJobs.Get(BackupName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultBackupSchedule, cronJob.Spec.Schedule)
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	container := pod.Containers[0]
+	assert.Equal(t, "ceph/ceph:v15", container.Image)
+	assert.Contains(t, container.Env, v1.EnvVar{Name: "ROOK_BACKUP_KEEP", Value: "7"})
+	assert.Contains(t, container.VolumeMounts, v1.VolumeMount{Name: "backup", MountPath: backupMountPath})
+	assert.Contains(t, container.VolumeMounts, v1.VolumeMount{Name: "backup-secret-rook-ceph-mon", MountPath: "/etc/rook-backup/secrets/rook-ceph-mon", ReadOnly: true})
+	assert.Contains(t, container.VolumeMounts, v1.VolumeMount{Name: "backup-configmap-rook-ceph-mon-endpoints", MountPath: "/etc/rook-backup/configmaps/rook-ceph-mon-endpoints", ReadOnly: true})
+	claims := []string{}
+	for _, volume := range pod.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	assert.Equal(t, []string{"backups"}, claims)
+
+	// the backups are uploaded to the bucket
+	c.spec.Mon.Backup = &cephv1.MonBackupSpec{
+		Schedule: "0 */6 * * *",
+		S3:       &cephv1.MonBackupS3Spec{Endpoint: "http://rgw", Bucket: "backups", CredentialsSecret: "creds"},
+	}
+	assert.NoError(t, c.reconcileBackup())
+	cronJob, err = cronJobs.Get(BackupName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "0 */6 * * *", cronJob.Spec.Schedule)
+	container = cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+	env := map[string]v1.EnvVar{}
+	for _, envVar := range container.Env {
+		env[envVar.Name] = envVar
+	}
+	assert.Equal(t, "http://rgw", env["ROOK_BACKUP_S3_ENDPOINT"].Value)
+	assert.Equal(t, "backups", env["ROOK_BACKUP_S3_BUCKET"].Value)
+	assert.Equal(t, "creds", env[backupAccessKeyName].ValueFrom.SecretKeyRef.Name)
+	_, keep := env["ROOK_BACKUP_KEEP"]
+	assert.False(t, keep)
+
+	// an invalid spec is refused
+	c.spec.Mon.Backup = &cephv1.MonBackupSpec{}
+	assert.Error(t, c.reconcileBackup())
+
+	// the cronjob is deleted when the admin key is restricted
+	c.spec.Mon.Backup = &cephv1.MonBackupSpec{PersistentVolumeClaim: "backups"}
+	c.spec.Security.RestrictAdminKey = true
+	assert.NoError(t, c.reconcileBackup())
+	_, err = cronJobs.Get(BackupName, metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+}
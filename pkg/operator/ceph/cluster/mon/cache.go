@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"sync"
+	"time"
+
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClusterInfoCacheTTL is the duration the cluster info loaded by the controllers is cached. The cache of a cluster is
+// invalidated when the operator updates the mon endpoints or the mon secret, the TTL bounds how long the controllers
+// use the cluster info after a change made out of the operator. Zero disables the cache.
+var ClusterInfoCacheTTL = 30 * time.Second
+
+type clusterInfoCacheKey struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+type clusterInfoCacheEntry struct {
+	clusterInfo *cephclient.ClusterInfo
+	maxMonID    int
+	mapping     *Mapping
+	expiration  time.Time
+}
+
+var (
+	clusterInfoCache      = map[clusterInfoCacheKey]*clusterInfoCacheEntry{}
+	clusterInfoCacheMutex sync.Mutex
+	// the invalidations of the clusters, so that a cluster info loaded before an invalidation is not cached
+	clusterInfoGenerations = map[string]int{}
+)
+
+// cachedClusterInfo returns a copy of the cached cluster info of a namespace, or the generation of the cache of the
+// namespace to cache the cluster info when it is not cached
+func cachedClusterInfo(clientset kubernetes.Interface, namespace string) (*clusterInfoCacheEntry, int) {
+	clusterInfoCacheMutex.Lock()
+	defer clusterInfoCacheMutex.Unlock()
+	key := clusterInfoCacheKey{clientset: clientset, namespace: namespace}
+	entry, ok := clusterInfoCache[key]
+	if !ok || time.Now().After(entry.expiration) {
+		delete(clusterInfoCache, key)
+		return nil, clusterInfoGenerations[namespace]
+	}
+	return entry.copy(), 0
+}
+
+func cacheClusterInfo(clientset kubernetes.Interface, namespace string, generation int, entry *clusterInfoCacheEntry) {
+	if ClusterInfoCacheTTL <= 0 {
+		return
+	}
+	clusterInfoCacheMutex.Lock()
+	defer clusterInfoCacheMutex.Unlock()
+	if clusterInfoGenerations[namespace] != generation {
+		// the cluster info was invalidated while it was loaded
+		return
+	}
+	entry = entry.copy()
+	entry.expiration = time.Now().Add(ClusterInfoCacheTTL)
+	clusterInfoCache[clusterInfoCacheKey{clientset: clientset, namespace: namespace}] = entry
+}
+
+// InvalidateClusterInfo removes the cluster info of a namespace from the cache, when the mon endpoints or the
+// credentials of the cluster change or when the cluster is deleted
+func InvalidateClusterInfo(namespace string) {
+	clusterInfoCacheMutex.Lock()
+	defer clusterInfoCacheMutex.Unlock()
+	clusterInfoGenerations[namespace]++
+	for key := range clusterInfoCache {
+		if key.namespace == namespace {
+			delete(clusterInfoCache, key)
+		}
+	}
+}
+
+// copy returns a copy of the entry which the controllers can change, like the ceph version of the cluster info
+func (e *clusterInfoCacheEntry) copy() *clusterInfoCacheEntry {
+	clusterInfo := *e.clusterInfo
+	clusterInfo.Monitors = make(map[string]*cephclient.MonInfo, len(e.clusterInfo.Monitors))
+	for name, monInfo := range e.clusterInfo.Monitors {
+		m := *monInfo
+		clusterInfo.Monitors[name] = &m
+	}
+	mapping := &Mapping{Node: make(map[string]*NodeInfo, len(e.mapping.Node))}
+	for name, node := range e.mapping.Node {
+		n := *node
+		mapping.Node[name] = &n
+	}
+	return &clusterInfoCacheEntry{clusterInfo: &clusterInfo, maxMonID: e.maxMonID, mapping: mapping, expiration: e.expiration}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestLoadClusterInfoCache(t *testing.T) {
+	clientset := test.New(t, 1)
+	namespace := "cache-ns"
+	gets := 0
+	clientset.PrependReactor("get", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+	context := &clusterd.Context{Clientset: clientset}
+	_, err := clientset.CoreV1().Secrets(namespace).Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: AppName, Namespace: namespace},
+		Data: map[string][]byte{
+			fsidSecretNameKey: []byte("fsid"),
+			cephUsernameKey:   []byte("client.admin"),
+			cephUserSecretKey: []byte("key"),
+		},
+	})
+	assert.NoError(t, err)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: EndpointConfigMapName, Namespace: namespace},
+		Data:       map[string]string{EndpointDataKey: "a=1.2.3.4:6789", MaxMonIDKey: "0"},
+	}
+	_, err = clientset.CoreV1().ConfigMaps(namespace).Create(cm)
+	assert.NoError(t, err)
+	defer InvalidateClusterInfo(namespace)
+
+	// the cluster info is read from the api server once
+	info, maxMonID, _, err := LoadClusterInfo(context, namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, "fsid", info.FSID)
+	assert.Equal(t, 0, maxMonID)
+	readGets := gets
+	assert.Equal(t, 2, readGets)
+
+	// the controllers get copies of the cached cluster info
+	info.CephVersion = cephver.Octopus
+	info.Monitors["a"].Endpoint = "changed"
+	cached, _, _, err := LoadClusterInfo(context, namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, readGets, gets)
+	assert.Equal(t, "1.2.3.4:6789", cached.Monitors["a"].Endpoint)
+	assert.Equal(t, cephver.CephVersion{}, cached.CephVersion)
+
+	// the cache is invalidated when the mon endpoints change
+	cm.Data[EndpointDataKey] = "b=1.2.3.5:6789"
+	_, err = clientset.CoreV1().ConfigMaps(namespace).Update(cm)
+	assert.NoError(t, err)
+	InvalidateClusterInfo(namespace)
+	info, _, _, err = LoadClusterInfo(context, namespace)
+	assert.NoError(t, err)
+	assert.Contains(t, info.Monitors, "b")
+	assert.Equal(t, 2*readGets, gets)
+
+	// a cluster info loaded before an invalidation is not cached
+	_, generation := cachedClusterInfo(clientset, "other-ns")
+	InvalidateClusterInfo("other-ns")
+	cacheClusterInfo(clientset, "other-ns", generation, &clusterInfoCacheEntry{clusterInfo: info, mapping: &Mapping{}})
+	entry, _ := cachedClusterInfo(clientset, "other-ns")
+	assert.Nil(t, entry)
+
+	// the cached cluster info expires
+	defer func(ttl time.Duration) { ClusterInfoCacheTTL = ttl }(ClusterInfoCacheTTL)
+	ClusterInfoCacheTTL = time.Nanosecond
+	InvalidateClusterInfo(namespace)
+	_, _, _, err = LoadClusterInfo(context, namespace)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, _, _, err = LoadClusterInfo(context, namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*readGets, gets)
+}
@@ -78,7 +78,17 @@ func dataDirRelativeHostPath(monName string) string {
 
 // LoadClusterInfo constructs or loads a clusterinfo and returns it along with the maxMonID
 func LoadClusterInfo(context *clusterd.Context, namespace string) (*cephclient.ClusterInfo, int, *Mapping, error) {
-	return CreateOrLoadClusterInfo(context, namespace, nil)
+	// the controllers load the cluster info at each reconcile, it is cached until the mon endpoints or secrets change
+	cached, generation := cachedClusterInfo(context.Clientset, namespace)
+	if cached != nil {
+		return cached.clusterInfo, cached.maxMonID, cached.mapping, nil
+	}
+	clusterInfo, maxMonID, monMapping, err := CreateOrLoadClusterInfo(context, namespace, nil)
+	if err != nil {
+		return clusterInfo, maxMonID, monMapping, err
+	}
+	cacheClusterInfo(context.Clientset, namespace, generation, &clusterInfoCacheEntry{clusterInfo: clusterInfo, maxMonID: maxMonID, mapping: monMapping})
+	return clusterInfo, maxMonID, monMapping, nil
 }
 
 // CreateOrLoadClusterInfo constructs or loads a clusterinfo and returns it along with the maxMonID
@@ -107,6 +117,7 @@ func CreateOrLoadClusterInfo(context *clusterd.Context, namespace string, ownerR
 		if err != nil {
 			return nil, maxMonID, monMapping, err
 		}
+		InvalidateClusterInfo(namespace)
 	} else {
 		clusterInfo = &cephclient.ClusterInfo{
 			Namespace:     namespace,
@@ -116,7 +127,7 @@ func CreateOrLoadClusterInfo(context *clusterd.Context, namespace string, ownerR
 		if cephUsername, ok := secrets.Data[cephUsernameKey]; ok {
 			clusterInfo.CephCred.Username = string(cephUsername)
 			clusterInfo.CephCred.Secret = string(secrets.Data[cephUserSecretKey])
-		} else if adminSecretKey, ok := secrets.Data[adminSecretNameKey]; ok {
+		} else if adminSecretKey, ok := secrets.Data[AdminSecretNameKey]; ok {
 			clusterInfo.CephCred.Username = client.AdminUsername
 			clusterInfo.CephCred.Secret = string(adminSecretKey)
 
@@ -125,6 +136,7 @@ func CreateOrLoadClusterInfo(context *clusterd.Context, namespace string, ownerR
 			if _, err = context.Clientset.CoreV1().Secrets(namespace).Update(secrets); err != nil {
 				return nil, maxMonID, monMapping, errors.Wrap(err, "failed to update mon secrets")
 			}
+			InvalidateClusterInfo(namespace)
 		} else {
 			return nil, maxMonID, monMapping, errors.New("failed to find either the cluster admin key or the username")
 		}
@@ -141,12 +153,12 @@ func CreateOrLoadClusterInfo(context *clusterd.Context, namespace string, ownerR
 	// Some people might want to give the admin key
 	// The necessary users/keys/secrets will be created by Rook
 	// This is also done to allow backward compatibility
-	if clusterInfo.CephCred.Username == client.AdminUsername && clusterInfo.CephCred.Secret != adminSecretNameKey {
+	if clusterInfo.CephCred.Username == client.AdminUsername && clusterInfo.CephCred.Secret != AdminSecretNameKey {
 		return clusterInfo, maxMonID, monMapping, nil
 	}
 
 	// If the admin secret is "admin-secret", look for the deprecated secret that has the external creds
-	if clusterInfo.CephCred.Secret == adminSecretNameKey {
+	if clusterInfo.CephCred.Secret == AdminSecretNameKey {
 		secret, err := context.Clientset.CoreV1().Secrets(namespace).Get(OperatorCreds, metav1.GetOptions{})
 		if err != nil {
 			return clusterInfo, maxMonID, monMapping, err
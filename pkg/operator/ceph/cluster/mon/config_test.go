@@ -71,7 +71,7 @@ func TestCreateClusterSecrets(t *testing.T) {
 	// Update the secret as if created in an old cluster
 	delete(secret.Data, cephUserSecretKey)
 	delete(secret.Data, cephUsernameKey)
-	secret.Data[adminSecretNameKey] = []byte(adminSecret)
+	secret.Data[AdminSecretNameKey] = []byte(adminSecret)
 	_, err = clientset.CoreV1().Secrets(namespace).Update(secret)
 	assert.NoError(t, err)
 
@@ -82,7 +82,7 @@ func TestCreateClusterSecrets(t *testing.T) {
 	assert.Equal(t, adminSecret, info.CephCred.Secret)
 
 	// Fail to load the external cluster if the admin placeholder is specified
-	secret.Data[adminSecretNameKey] = []byte(adminSecretNameKey)
+	secret.Data[AdminSecretNameKey] = []byte(AdminSecretNameKey)
 	_, err = clientset.CoreV1().Secrets(namespace).Update(secret)
 	assert.NoError(t, err)
 	info, _, _, err = CreateOrLoadClusterInfo(context, namespace, ownerRef)
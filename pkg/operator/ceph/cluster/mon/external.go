@@ -0,0 +1,269 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// key of the discovery secret with the comma separated endpoints of one or more mons of the external cluster
+	externalMonHostKey = "monHost"
+	// key of the discovery secret with the user connecting to the external cluster, admin by default
+	externalUserIDKey = "userID"
+	// key of the discovery secret with the key of the user
+	externalUserKeyKey = "userKey"
+	// the mon secret is not known when connecting to an external cluster
+	externalMonSecret = "mon-secret"
+)
+
+// externalCSIKeys maps the optional keys of the discovery secret with the keys of the csi users to the csi secrets
+var externalCSIKeys = map[string]string{
+	"csiRBDProvisionerKey":    csi.CsiRBDProvisionerSecret,
+	"csiRBDNodeKey":           csi.CsiRBDNodeSecret,
+	"csiCephFSProvisionerKey": csi.CsiCephFSProvisionerSecret,
+	"csiCephFSNodeKey":        csi.CsiCephFSNodeSecret,
+}
+
+// ImportExternalClusterInfo discovers the fsid and the mons of an external cluster with the credentials of the
+// discovery secret. It writes the mon secret and the mon endpoints config map otherwise created by the import script.
+func ImportExternalClusterInfo(context *clusterd.Context, namespace, secretName string, ownerRef metav1.OwnerReference) error {
+	secret, err := context.Clientset.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get external cluster discovery secret %q", secretName)
+	}
+	clusterInfo, err := bootstrapExternalClusterInfo(namespace, secret.Data)
+	if err != nil {
+		return errors.Wrapf(err, "invalid external cluster discovery secret %q", secretName)
+	}
+	clusterInfo.OwnerRef = ownerRef
+
+	// connect to the mons given in the secret to discover all the mons
+	if err := WriteConnectionConfig(context, clusterInfo); err != nil {
+		return err
+	}
+	status, err := cephclient.GetMonQuorumStatus(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to the external cluster")
+	}
+	clusterInfo.FSID = status.MonMap.FSID
+	clusterInfo.Monitors = monsFromMonMap(status.MonMap.Mons)
+	if len(clusterInfo.Monitors) == 0 {
+		return errors.New("no mon found in the mon map of the external cluster")
+	}
+	logger.Infof("discovered external cluster %q with mons %v", clusterInfo.FSID, FlattenMonEndpoints(clusterInfo.Monitors))
+
+	if err := SaveExternalClusterAccessSecret(context, clusterInfo); err != nil {
+		return err
+	}
+	if err := saveExternalMonEndpoints(context, clusterInfo); err != nil {
+		return err
+	}
+	InvalidateClusterInfo(namespace)
+	return nil
+}
+
+// bootstrapExternalClusterInfo returns the cluster info to connect to the mons listed in the discovery secret
+func bootstrapExternalClusterInfo(namespace string, data map[string][]byte) (*cephclient.ClusterInfo, error) {
+	monHost := strings.TrimSpace(string(data[externalMonHostKey]))
+	if monHost == "" {
+		return nil, errors.Errorf("%q is missing", externalMonHostKey)
+	}
+	userKey := strings.TrimSpace(string(data[externalUserKeyKey]))
+	if userKey == "" {
+		return nil, errors.Errorf("%q is missing", externalUserKeyKey)
+	}
+	username := strings.TrimSpace(string(data[externalUserIDKey]))
+	if username == "" {
+		username = cephclient.AdminUsername
+	} else if !strings.HasPrefix(username, "client.") {
+		username = "client." + username
+	}
+
+	clusterInfo := &cephclient.ClusterInfo{
+		Namespace:     namespace,
+		MonitorSecret: externalMonSecret,
+		CephCred:      cephclient.CephCred{Username: username, Secret: userKey},
+		Monitors:      map[string]*cephclient.MonInfo{},
+	}
+	for i, endpoint := range strings.Split(monHost, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		name := fmt.Sprintf("bootstrap-%d", i)
+		clusterInfo.Monitors[name] = &cephclient.MonInfo{Name: name, Endpoint: endpoint}
+	}
+	return clusterInfo, nil
+}
+
+// monsFromMonMap returns the msgr1 endpoint of the mons, like the mons of the import script
+func monsFromMonMap(entries []cephclient.MonMapEntry) map[string]*cephclient.MonInfo {
+	mons := map[string]*cephclient.MonInfo{}
+	for _, entry := range entries {
+		endpoint := ""
+		for _, addr := range entry.PublicAddrs.Addrvec {
+			if addr.Type == "v1" {
+				endpoint = addr.Addr
+			}
+		}
+		if endpoint == "" {
+			// the address is suffixed with the nonce, e.g. "10.0.0.1:6789/0"
+			endpoint = strings.Split(entry.PublicAddr, "/")[0]
+		}
+		if endpoint == "" {
+			continue
+		}
+		mons[entry.Name] = &cephclient.MonInfo{Name: entry.Name, Endpoint: endpoint}
+	}
+	return mons
+}
+
+// GetExternalCSIKeys returns the keys of the csi users given in the discovery secret, by csi secret name
+func GetExternalCSIKeys(context *clusterd.Context, namespace, secretName string) (map[string]string, error) {
+	secret, err := context.Clientset.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get external cluster discovery secret %q", secretName)
+	}
+	keys := map[string]string{}
+	for dataKey, csiSecret := range externalCSIKeys {
+		if key := strings.TrimSpace(string(secret.Data[dataKey])); key != "" {
+			keys[csiSecret] = key
+		}
+	}
+	return keys, nil
+}
+
+// SaveExternalDiscoveryUsers replaces the user of the discovery secret and saves the keys of the csi users, so the
+// operator does not need to read them from the external cluster anymore
+func SaveExternalDiscoveryUsers(context *clusterd.Context, namespace, secretName string, cred cephclient.CephCred, csiKeys map[string]string) error {
+	secret, err := context.Clientset.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get external cluster discovery secret %q", secretName)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[externalUserIDKey] = []byte(strings.TrimPrefix(cred.Username, "client."))
+	secret.Data[externalUserKeyKey] = []byte(cred.Secret)
+	for dataKey, csiSecret := range externalCSIKeys {
+		if key, ok := csiKeys[csiSecret]; ok {
+			secret.Data[dataKey] = []byte(key)
+		}
+	}
+	if _, err := context.Clientset.CoreV1().Secrets(namespace).Update(secret); err != nil {
+		return errors.Wrapf(err, "failed to update external cluster discovery secret %q", secretName)
+	}
+	return nil
+}
+
+// SaveExternalClusterAccessSecret writes the mon secret with the user connecting to the external cluster
+func SaveExternalClusterAccessSecret(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) error {
+	adminSecret := AdminSecretNameKey
+	if clusterInfo.CephCred.Username == cephclient.AdminUsername {
+		adminSecret = clusterInfo.CephCred.Secret
+	}
+	data := map[string][]byte{
+		fsidSecretNameKey:  []byte(clusterInfo.FSID),
+		monSecretNameKey:   []byte(clusterInfo.MonitorSecret),
+		AdminSecretNameKey: []byte(adminSecret),
+		cephUsernameKey:    []byte(clusterInfo.CephCred.Username),
+		cephUserSecretKey:  []byte(clusterInfo.CephCred.Secret),
+	}
+
+	secret, err := context.Clientset.CoreV1().Secrets(clusterInfo.Namespace).Get(AppName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get mon secrets")
+		}
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      AppName,
+				Namespace: clusterInfo.Namespace,
+			},
+			Data: data,
+			Type: k8sutil.RookType,
+		}
+		k8sutil.SetOwnerRef(&secret.ObjectMeta, &clusterInfo.OwnerRef)
+		if _, err := context.Clientset.CoreV1().Secrets(clusterInfo.Namespace).Create(secret); err != nil {
+			return errors.Wrap(err, "failed to create mon secrets")
+		}
+		InvalidateClusterInfo(clusterInfo.Namespace)
+		return nil
+	}
+
+	if reflect.DeepEqual(secret.Data, data) {
+		return nil
+	}
+	secret.Data = data
+	if _, err := context.Clientset.CoreV1().Secrets(clusterInfo.Namespace).Update(secret); err != nil {
+		return errors.Wrap(err, "failed to update mon secrets")
+	}
+	InvalidateClusterInfo(clusterInfo.Namespace)
+	return nil
+}
+
+func saveExternalMonEndpoints(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) error {
+	cm, err := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Get(EndpointConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get mon endpoints")
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      EndpointConfigMapName,
+				Namespace: clusterInfo.Namespace,
+			},
+			Data: map[string]string{
+				EndpointDataKey: FlattenMonEndpoints(clusterInfo.Monitors),
+				MappingKey:      "{}",
+				MaxMonIDKey:     strconv.Itoa(len(clusterInfo.Monitors) - 1),
+			},
+		}
+		k8sutil.SetOwnerRef(&cm.ObjectMeta, &clusterInfo.OwnerRef)
+		if _, err := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Create(cm); err != nil {
+			return errors.Wrap(err, "failed to create mon endpoints")
+		}
+		return nil
+	}
+
+	// the endpoints are flattened in a random order
+	if reflect.DeepEqual(ParseMonEndpoints(cm.Data[EndpointDataKey]), clusterInfo.Monitors) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{MappingKey: "{}"}
+	}
+	cm.Data[EndpointDataKey] = FlattenMonEndpoints(clusterInfo.Monitors)
+	cm.Data[MaxMonIDKey] = strconv.Itoa(len(clusterInfo.Monitors) - 1)
+	if _, err := k8sutil.UpdateConfigMap(context.Clientset, cm); err != nil {
+		return errors.Wrap(err, "failed to update mon endpoints")
+	}
+	return nil
+}
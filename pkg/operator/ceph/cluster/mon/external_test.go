@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBootstrapExternalClusterInfo(t *testing.T) {
+	_, err := bootstrapExternalClusterInfo("ns", map[string][]byte{externalUserKeyKey: []byte("key")})
+	assert.Error(t, err)
+	_, err = bootstrapExternalClusterInfo("ns", map[string][]byte{externalMonHostKey: []byte("10.0.0.1:6789")})
+	assert.Error(t, err)
+
+	info, err := bootstrapExternalClusterInfo("ns", map[string][]byte{
+		externalMonHostKey: []byte("10.0.0.1:6789, 10.0.0.2:6789"),
+		externalUserKeyKey: []byte("key"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cephclient.AdminUsername, info.CephCred.Username)
+	assert.Equal(t, "key", info.CephCred.Secret)
+	assert.Equal(t, 2, len(info.Monitors))
+	assert.Equal(t, "10.0.0.2:6789", info.Monitors["bootstrap-1"].Endpoint)
+
+	info, err = bootstrapExternalClusterInfo("ns", map[string][]byte{
+		externalMonHostKey: []byte("10.0.0.1:6789"),
+		externalUserIDKey:  []byte("healthchecker"),
+		externalUserKeyKey: []byte("key"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "client.healthchecker", info.CephCred.Username)
+}
+
+func TestImportExternalClusterInfo(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			if args[0] == "quorum_status" {
+				return `{"quorum":[0,1],"monmap":{"fsid":"613975f3-3025-4802-9de1-a2280b950e75","mons":[
+					{"name":"a","rank":0,"public_addrs":{"addrvec":[{"type":"v2","addr":"10.0.0.1:3300"},{"type":"v1","addr":"10.0.0.1:6789"}]}},
+					{"name":"b","rank":1,"public_addr":"10.0.0.2:6789/0"}]}}`, nil
+			}
+			return "", nil
+		},
+	}
+	clientset := test.New(t, 1)
+	configDir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(configDir)
+	context := &clusterd.Context{Clientset: clientset, ConfigDir: configDir, Executor: executor}
+
+	err := ImportExternalClusterInfo(context, "ns", "discovery", metav1.OwnerReference{})
+	assert.Error(t, err)
+
+	discovery := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery", Namespace: "ns"},
+		Data: map[string][]byte{
+			externalMonHostKey: []byte("10.0.0.1:6789"),
+			externalUserIDKey:  []byte("healthchecker"),
+			externalUserKeyKey: []byte("AQB1ZdZeAAAAABAA2ur/LQNW96VErboRgydnGQ=="),
+		},
+	}
+	_, err = clientset.CoreV1().Secrets("ns").Create(discovery)
+	assert.NoError(t, err)
+	err = ImportExternalClusterInfo(context, "ns", "discovery", metav1.OwnerReference{})
+	assert.NoError(t, err)
+
+	// the operator connects to the external cluster with the imported info
+	info, _, _, err := LoadClusterInfo(context, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, "613975f3-3025-4802-9de1-a2280b950e75", info.FSID)
+	assert.Equal(t, "client.healthchecker", info.CephCred.Username)
+	assert.Equal(t, 2, len(info.Monitors))
+	assert.Equal(t, "10.0.0.1:6789", info.Monitors["a"].Endpoint)
+	assert.Equal(t, "10.0.0.2:6789", info.Monitors["b"].Endpoint)
+
+	// importing again does not change the endpoints
+	cm, err := clientset.CoreV1().ConfigMaps("ns").Get(EndpointConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	cm.Data[MappingKey] = `{"node":{}}`
+	_, err = clientset.CoreV1().ConfigMaps("ns").Update(cm)
+	assert.NoError(t, err)
+	err = ImportExternalClusterInfo(context, "ns", "discovery", metav1.OwnerReference{})
+	assert.NoError(t, err)
+	cm, err = clientset.CoreV1().ConfigMaps("ns").Get(EndpointConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"node":{}}`, cm.Data[MappingKey])
+	assert.Equal(t, "1", cm.Data[MaxMonIDKey])
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// monRebalanceRetryInterval is the time to wait after a failed move of a mon to another failure domain, since the
+// nodes of the free failure domains may not be able to run a mon
+var monRebalanceRetryInterval = 15 * time.Minute
+
+// validateMonFailureDomain checks the spread of the mons across the failure domains
+func validateMonFailureDomain(spec cephv1.MonSpec) error {
+	failureDomain := spec.FailureDomain
+	if failureDomain == nil {
+		return nil
+	}
+	if errs := validation.IsQualifiedName(failureDomain.TopologyKey); len(errs) > 0 {
+		return errors.Errorf("invalid topology key %q of the mon failure domain. %v", failureDomain.TopologyKey, errs)
+	}
+	switch failureDomain.Mode {
+	case "", cephv1.MonSpreadHard, cephv1.MonSpreadSoft:
+	default:
+		return errors.Errorf("invalid mode %q of the mon failure domain, expected %q or %q", failureDomain.Mode, cephv1.MonSpreadHard, cephv1.MonSpreadSoft)
+	}
+	return nil
+}
+
+// monSpreadRequired returns whether two mons are refused in the same failure domain
+func (c *Cluster) monSpreadRequired() bool {
+	return c.spec.Mon.FailureDomain != nil && c.spec.Mon.FailureDomain.Mode == cephv1.MonSpreadHard
+}
+
+// applyFailureDomainAntiAffinity spreads the mon pods scheduled by kubernetes across the failure domains. The mons
+// with a node selector were spread when their node was assigned. The pod of the replaced mon is ignored, otherwise its
+// failure domain could not take the new mon while the pod stays on a down node.
+func (c *Cluster) applyFailureDomainAntiAffinity(podSpec *v1.PodSpec, replacedMon string) {
+	failureDomain := c.spec.Mon.FailureDomain
+	if failureDomain == nil || podSpec.NodeSelector != nil {
+		return
+	}
+	term := v1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{k8sutil.AppAttr: AppName},
+		},
+		TopologyKey: failureDomain.TopologyKey,
+	}
+	if replacedMon != "" {
+		term.LabelSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+			{Key: "mon", Operator: metav1.LabelSelectorOpNotIn, Values: []string{replacedMon}},
+		}
+	}
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &v1.Affinity{}
+	}
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+	}
+	antiAffinity := podSpec.Affinity.PodAntiAffinity
+	if c.monSpreadRequired() {
+		antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+	} else {
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			v1.WeightedPodAffinityTerm{Weight: 100, PodAffinityTerm: term})
+	}
+}
+
+// monNodes returns the nodes of the mons by mon name, from the node assignments and from the pods of the mons
+// scheduled by kubernetes
+func (c *Cluster) monNodes() (map[string]string, error) {
+	nodes := map[string]string{}
+	for name, node := range c.mapping.Node {
+		if node != nil {
+			nodes[name] = node.Name
+		}
+	}
+	pods, err := c.context.Clientset.CoreV1().Pods(c.Namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the mon pods")
+	}
+	for _, pod := range pods.Items {
+		name := pod.Labels["mon"]
+		if pod.Labels["mon_canary"] == "true" || name == "" || pod.Spec.NodeName == "" {
+			continue
+		}
+		if _, ok := nodes[name]; !ok {
+			nodes[name] = pod.Spec.NodeName
+		}
+	}
+	return nodes, nil
+}
+
+// failureDomainOfNode returns the failure domain of a node, or an empty string if the node is not labeled
+func (c *Cluster) failureDomainOfNode(nodeName string) (string, error) {
+	node, err := c.context.Clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get node %q", nodeName)
+	}
+	return node.Labels[c.spec.Mon.FailureDomain.TopologyKey], nil
+}
+
+// checkMonFailureDomain checks a mon can be placed on a node without sharing the failure domain of another mon. The
+// mon being replaced is not counted. When the spread is not required, a shared failure domain is only reported.
+func (c *Cluster) checkMonFailureDomain(monName string, node *v1.Node, monNodes map[string]string, replacedMon string, required bool) error {
+	failureDomain := c.spec.Mon.FailureDomain
+	if failureDomain == nil {
+		return nil
+	}
+	domain := node.Labels[failureDomain.TopologyKey]
+	if domain == "" {
+		if required {
+			return errors.Errorf("node %q has no %q label", node.Name, failureDomain.TopologyKey)
+		}
+		logger.Warningf("mon %q is placed on node %q without a %q label", monName, node.Name, failureDomain.TopologyKey)
+		return nil
+	}
+	for otherMon, nodeName := range monNodes {
+		if otherMon == monName || otherMon == replacedMon {
+			continue
+		}
+		otherDomain, err := c.failureDomainOfNode(nodeName)
+		if err != nil {
+			return err
+		}
+		if otherDomain != domain {
+			continue
+		}
+		if required {
+			return errors.Errorf("failure domain %q of node %q already has mon %q", domain, node.Name, otherMon)
+		}
+		logger.Warningf("mon %q shares the failure domain %q with mon %q, no other failure domain is available", monName, domain, otherMon)
+		return nil
+	}
+	return nil
+}
+
+// rebalanceMonFailureDomains moves a mon out of a failure domain with several mons when a failure domain without mons
+// is available for the mons, like when a new zone appears. Only one mon is moved per health check, and the moves are
+// paused for a while after a failed move.
+func (c *Cluster) rebalanceMonFailureDomains() error {
+	failureDomain := c.spec.Mon.FailureDomain
+	if failureDomain == nil {
+		return nil
+	}
+	if time.Now().Before(c.nextMonRebalance) {
+		logger.Debugf("waiting until %s to move the mons to the free failure domains", c.nextMonRebalance.Format(time.RFC3339))
+		return nil
+	}
+	monNodes, err := c.monNodes()
+	if err != nil {
+		return err
+	}
+	nodes, err := c.context.Clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the nodes")
+	}
+
+	placement := cephv1.GetMonPlacement(c.spec.Placement)
+	nodeDomains := map[string]string{}
+	availableDomains := map[string]bool{}
+	for _, node := range nodes.Items {
+		domain := node.Labels[failureDomain.TopologyKey]
+		nodeDomains[node.Name] = domain
+		if domain == "" {
+			continue
+		}
+		if valid, _ := k8sutil.ValidNode(node, placement); valid {
+			availableDomains[domain] = true
+		}
+	}
+
+	monsByDomain := map[string][]string{}
+	for name, nodeName := range monNodes {
+		if _, ok := c.ClusterInfo.Monitors[name]; !ok {
+			continue
+		}
+		domain := nodeDomains[nodeName]
+		monsByDomain[domain] = append(monsByDomain[domain], name)
+		delete(availableDomains, domain)
+	}
+	if len(availableDomains) == 0 {
+		return nil
+	}
+
+	// move a mon of the failure domain with the most mons, the mons on the nodes without failure domain first
+	crowded := ""
+	found := len(monsByDomain[""]) > 0
+	if !found {
+		for domain, mons := range monsByDomain {
+			if len(mons) < 2 {
+				continue
+			}
+			if !found || len(mons) > len(monsByDomain[crowded]) || (len(mons) == len(monsByDomain[crowded]) && domain < crowded) {
+				crowded = domain
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	mons := monsByDomain[crowded]
+	sort.Strings(mons)
+	name := mons[len(mons)-1]
+	logger.Infof("moving mon %q out of failure domain %q to one of the failure domains without mons", name, crowded)
+	if err := c.failoverMon(name, true); err != nil {
+		c.nextMonRebalance = time.Now().Add(monRebalanceRetryInterval)
+		return errors.Wrapf(err, "failed to move mon %q to another failure domain, retrying after %s", name, monRebalanceRetryInterval)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const zoneKey = "topology.kubernetes.io/zone"
+
+func TestValidateMonFailureDomain(t *testing.T) {
+	assert.NoError(t, validateMonFailureDomain(cephv1.MonSpec{}))
+	assert.NoError(t, validateMonFailureDomain(cephv1.MonSpec{FailureDomain: &cephv1.MonFailureDomainSpec{TopologyKey: zoneKey}}))
+	assert.NoError(t, validateMonFailureDomain(cephv1.MonSpec{FailureDomain: &cephv1.MonFailureDomainSpec{TopologyKey: "rack", Mode: cephv1.MonSpreadHard}}))
+	assert.Error(t, validateMonFailureDomain(cephv1.MonSpec{FailureDomain: &cephv1.MonFailureDomainSpec{}}))
+	assert.Error(t, validateMonFailureDomain(cephv1.MonSpec{FailureDomain: &cephv1.MonFailureDomainSpec{TopologyKey: "bad key"}}))
+	assert.Error(t, validateMonFailureDomain(cephv1.MonSpec{FailureDomain: &cephv1.MonFailureDomainSpec{TopologyKey: zoneKey, Mode: "strict"}}))
+}
+
+func TestApplyFailureDomainAntiAffinity(t *testing.T) {
+	c := New(&clusterd.Context{}, "ns", cephv1.ClusterSpec{}, metav1.OwnerReference{}, &sync.Mutex{})
+
+	// no failure domain
+	podSpec := &v1.PodSpec{}
+	c.applyFailureDomainAntiAffinity(podSpec, "")
+	assert.Nil(t, podSpec.Affinity)
+
+	// soft spread
+	c.spec.Mon.FailureDomain = &cephv1.MonFailureDomainSpec{TopologyKey: zoneKey}
+	c.applyFailureDomainAntiAffinity(podSpec, "")
+	preferred := podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, 1, len(preferred))
+	assert.Equal(t, int32(100), preferred[0].Weight)
+	assert.Equal(t, zoneKey, preferred[0].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, AppName, preferred[0].PodAffinityTerm.LabelSelector.MatchLabels[k8sutil.AppAttr])
+	assert.Empty(t, podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+
+	// hard spread
+	c.spec.Mon.FailureDomain.Mode = cephv1.MonSpreadHard
+	podSpec = &v1.PodSpec{}
+	c.applyFailureDomainAntiAffinity(podSpec, "")
+	required := podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, 1, len(required))
+	assert.Equal(t, zoneKey, required[0].TopologyKey)
+	assert.Empty(t, required[0].LabelSelector.MatchExpressions)
+
+	// the pod of the failed over mon does not hold its failure domain
+	podSpec = &v1.PodSpec{}
+	c.applyFailureDomainAntiAffinity(podSpec, "a")
+	required = podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, 1, len(required))
+	assert.Equal(t, []metav1.LabelSelectorRequirement{{Key: "mon", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"a"}}},
+		required[0].LabelSelector.MatchExpressions)
+
+	// the mons assigned to a node are not changed
+	podSpec = &v1.PodSpec{NodeSelector: map[string]string{v1.LabelHostname: "node0"}}
+	c.applyFailureDomainAntiAffinity(podSpec, "")
+	assert.Nil(t, podSpec.Affinity)
+}
+
+func labelZones(t *testing.T, clientset kubernetes.Interface, zones map[string]string) {
+	for name, zone := range zones {
+		node, err := clientset.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		node.Labels = map[string]string{zoneKey: zone}
+		_, err = clientset.CoreV1().Nodes().Update(node)
+		assert.NoError(t, err)
+	}
+}
+
+func TestCheckMonFailureDomain(t *testing.T) {
+	clientset := test.New(t, 4)
+	labelZones(t, clientset, map[string]string{"node0": "a", "node1": "b", "node2": "a"})
+	c := New(&clusterd.Context{Clientset: clientset}, "ns", cephv1.ClusterSpec{}, metav1.OwnerReference{}, &sync.Mutex{})
+	monNodes := map[string]string{"a": "node0", "b": "node1"}
+	node := func(name string) *v1.Node {
+		n, err := clientset.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return n
+	}
+
+	// no failure domain
+	assert.NoError(t, c.checkMonFailureDomain("c", node("node2"), monNodes, "", true))
+
+	c.spec.Mon.FailureDomain = &cephv1.MonFailureDomainSpec{TopologyKey: zoneKey, Mode: cephv1.MonSpreadHard}
+
+	// zone a already has mon a
+	assert.Error(t, c.checkMonFailureDomain("c", node("node2"), monNodes, "", true))
+	// the spread is not required
+	assert.NoError(t, c.checkMonFailureDomain("c", node("node2"), monNodes, "", false))
+	// mon a is replaced
+	assert.NoError(t, c.checkMonFailureDomain("c", node("node2"), monNodes, "a", true))
+	// the node has no zone
+	assert.Error(t, c.checkMonFailureDomain("c", node("node3"), monNodes, "", true))
+	assert.NoError(t, c.checkMonFailureDomain("c", node("node3"), monNodes, "", false))
+
+	// a new zone
+	labelZones(t, clientset, map[string]string{"node3": "c"})
+	assert.NoError(t, c.checkMonFailureDomain("c", node("node3"), monNodes, "", true))
+}
+
+func TestMonNodes(t *testing.T) {
+	clientset := test.New(t, 3)
+	c := New(&clusterd.Context{Clientset: clientset}, "ns", cephv1.ClusterSpec{}, metav1.OwnerReference{}, &sync.Mutex{})
+	c.mapping.Node["a"] = &NodeInfo{Name: "node0"}
+	for _, pod := range []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{k8sutil.AppAttr: AppName, "mon": "b"}}, Spec: v1.PodSpec{NodeName: "node1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c-canary", Labels: map[string]string{k8sutil.AppAttr: AppName, "mon": "c", "mon_canary": "true"}}, Spec: v1.PodSpec{NodeName: "node2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d", Labels: map[string]string{k8sutil.AppAttr: AppName, "mon": "d"}}},
+	} {
+		p := pod
+		_, err := clientset.CoreV1().Pods("ns").Create(&p)
+		assert.NoError(t, err)
+	}
+
+	nodes, err := c.monNodes()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "node0", "b": "node1"}, nodes)
+}
+
+func TestRebalanceMonFailureDomains(t *testing.T) {
+	clientset := test.New(t, 4)
+	labelZones(t, clientset, map[string]string{"node0": "a", "node1": "b", "node2": "c", "node3": "c"})
+	c := New(&clusterd.Context{Clientset: clientset}, "ns", cephv1.ClusterSpec{}, metav1.OwnerReference{}, &sync.Mutex{})
+	c.ClusterInfo = clienttest.CreateTestClusterInfo(3)
+	c.mapping.Node["a"] = &NodeInfo{Name: "node0"}
+	c.mapping.Node["b"] = &NodeInfo{Name: "node1"}
+	c.mapping.Node["c"] = &NodeInfo{Name: "node2"}
+
+	// no failure domain
+	assert.NoError(t, c.rebalanceMonFailureDomains())
+
+	// every zone already has a mon
+	c.spec.Mon.FailureDomain = &cephv1.MonFailureDomainSpec{TopologyKey: zoneKey}
+	assert.NoError(t, c.rebalanceMonFailureDomains())
+	assert.Equal(t, 3, len(c.ClusterInfo.Monitors))
+
+	// two mons share a zone but no zone is free
+	c.mapping.Node["c"] = &NodeInfo{Name: "node0"}
+	labelZones(t, clientset, map[string]string{"node2": "b", "node3": "b"})
+	assert.NoError(t, c.rebalanceMonFailureDomains())
+	assert.Equal(t, 3, len(c.ClusterInfo.Monitors))
+	assert.Equal(t, "node0", c.mapping.Node["c"].Name)
+
+	// a new zone is free but the last move failed recently
+	labelZones(t, clientset, map[string]string{"node3": "d"})
+	c.nextMonRebalance = time.Now().Add(time.Minute)
+	assert.NoError(t, c.rebalanceMonFailureDomains())
+	assert.Equal(t, 3, len(c.ClusterInfo.Monitors))
+	assert.Equal(t, "node0", c.mapping.Node["c"].Name)
+}
@@ -26,6 +26,7 @@ import (
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	cephutil "github.com/rook/rook/pkg/daemon/ceph/util"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/notification"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -92,7 +93,14 @@ func (c *Cluster) checkHealth() error {
 	c.acquireOrchestrationLock()
 	defer c.releaseOrchestrationLock()
 
-	if c.spec.Mon.Count == 0 || !c.ClusterInfo.IsInitialized(true) {
+	// the mons must not be failed over while the quorum is restored from a single mon by the orchestration
+	if c.RestoreQuorumMon != "" {
+		logger.Infof("skipping mon health check while the mon quorum is restored from mon %q", c.RestoreQuorumMon)
+		return nil
+	}
+
+	// The mon count of an external cluster is not set, its mon map is still refreshed
+	if (c.spec.Mon.Count == 0 && !c.spec.External.Enable) || !c.ClusterInfo.IsInitialized(true) {
 		logger.Warningf("skipping mon health check since cluster details are not initialized")
 		return nil
 	}
@@ -113,6 +121,7 @@ func (c *Cluster) checkHealth() error {
 	// get the status and check for quorum
 	quorumStatus, err := client.GetMonQuorumStatus(c.context, c.ClusterInfo)
 	if err != nil {
+		notification.Warning(notification.ReasonQuorumLost, c.Namespace, AppName, "failed to get mon quorum status. %v", err)
 		return errors.Wrap(err, "failed to get mon quorum status")
 	}
 	logger.Debugf("Mon quorum status: %+v", quorumStatus)
@@ -222,6 +231,11 @@ func (c *Cluster) checkHealth() error {
 	if allMonsInQuorum && len(quorumStatus.MonMap.Mons) == desiredMonCount {
 		logger.Debug("mon cluster is healthy, removing any existing canary deployment")
 		c.removeCanaryDeployments()
+
+		// spread the mons to the new failure domains once the quorum is healthy
+		if err := c.rebalanceMonFailureDomains(); err != nil {
+			logger.Warningf("failed to spread the mons across the failure domains. %v", err)
+		}
 	}
 
 	return nil
@@ -236,23 +250,25 @@ func (c *Cluster) failMon(monCount, desiredMonCount int, name string) {
 		}
 	} else {
 		// bring up a new mon to replace the unhealthy mon
-		if err := c.failoverMon(name); err != nil {
+		if err := c.failoverMon(name, c.monSpreadRequired()); err != nil {
 			logger.Errorf("failed to failover mon %q. %v", name, err)
 		}
 	}
 }
 
-func (c *Cluster) failoverMon(name string) error {
+// failoverMon replaces a mon with a new mon, refused in the failure domains of the other mons when the spread is required
+func (c *Cluster) failoverMon(name string, requireSpread bool) error {
 	logger.Infof("Failing over monitor %q", name)
 
 	// Start a new monitor
 	m := c.newMonConfig(c.maxMonID + 1)
+	m.ReplacedMon = name
 	logger.Infof("starting new mon: %+v", m)
 
 	mConf := []*monConfig{m}
 
 	// Assign the pod to a node
-	if err := c.assignMons(mConf); err != nil {
+	if err := c.assignMons(mConf, requireSpread); err != nil {
 		return errors.Wrap(err, "failed to place new mon on a node")
 	}
 
@@ -287,9 +303,25 @@ func (c *Cluster) failoverMon(name string) error {
 func (c *Cluster) removeMon(daemonName string) error {
 	logger.Infof("ensuring removal of unhealthy monitor %s", daemonName)
 
-	resourceName := resourceName(daemonName)
-
 	// Remove the mon pod if it is still there
+	c.deleteMonDeployment(daemonName)
+
+	// Remove the bad monitor from quorum
+	if err := c.removeMonitorFromQuorum(daemonName); err != nil {
+		logger.Errorf("failed to remove mon %q from quorum. %v", daemonName, err)
+	}
+	c.deleteMonResources(daemonName)
+
+	if err := c.saveMonConfig(); err != nil {
+		return errors.Wrapf(err, "failed to save mon config after failing over mon %s", daemonName)
+	}
+
+	return nil
+}
+
+// make a best effort to delete the deployment of a mon
+func (c *Cluster) deleteMonDeployment(daemonName string) {
+	resourceName := resourceName(daemonName)
 	var gracePeriod int64
 	propagation := metav1.DeletePropagationForeground
 	options := &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod, PropagationPolicy: &propagation}
@@ -300,11 +332,12 @@ func (c *Cluster) removeMon(daemonName string) error {
 			logger.Errorf("failed to remove dead mon deployment %q. %v", resourceName, err)
 		}
 	}
+}
 
-	// Remove the bad monitor from quorum
-	if err := c.removeMonitorFromQuorum(daemonName); err != nil {
-		logger.Errorf("failed to remove mon %q from quorum. %v", daemonName, err)
-	}
+// make a best effort to delete the service and the pvc of a mon, and forget its endpoint and node. The mon config must
+// be saved afterwards.
+func (c *Cluster) deleteMonResources(daemonName string) {
+	resourceName := resourceName(daemonName)
 	delete(c.ClusterInfo.Monitors, daemonName)
 	// check if a mapping exists for the mon
 	if _, ok := c.mapping.Node[daemonName]; ok {
@@ -312,6 +345,9 @@ func (c *Cluster) removeMon(daemonName string) error {
 	}
 
 	// Remove the service endpoint
+	var gracePeriod int64
+	propagation := metav1.DeletePropagationForeground
+	options := &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod, PropagationPolicy: &propagation}
 	if err := c.context.Clientset.CoreV1().Services(c.Namespace).Delete(resourceName, options); err != nil {
 		if kerrors.IsNotFound(err) {
 			logger.Infof("dead mon service %s was already gone", resourceName)
@@ -328,12 +364,6 @@ func (c *Cluster) removeMon(daemonName string) error {
 			logger.Errorf("failed to remove dead mon pvc %q. %v", resourceName, err)
 		}
 	}
-
-	if err := c.saveMonConfig(); err != nil {
-		return errors.Wrapf(err, "failed to save mon config after failing over mon %s", daemonName)
-	}
-
-	return nil
 }
 
 func (c *Cluster) removeMonitorFromQuorum(name string) error {
@@ -363,6 +393,7 @@ func (c *Cluster) handleExternalMonStatus(status client.MonStatusResponse) error
 
 	// let's save the monitor's config if anything happened
 	if changed {
+		logger.Infof("external mons changed to %q, updating mon endpoints and csi config", FlattenMonEndpoints(c.ClusterInfo.Monitors))
 		if err := c.saveMonConfig(); err != nil {
 			return errors.Wrap(err, "failed to save mon config after adding/removing external mon")
 		}
@@ -17,6 +17,7 @@ limitations under the License.
 package mon
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -25,10 +26,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	testopk8s "github.com/rook/rook/pkg/operator/k8sutil/test"
 	"github.com/rook/rook/pkg/operator/test"
@@ -83,7 +86,7 @@ func TestCheckHealth(t *testing.T) {
 	assert.ElementsMatch(t, []string{"rook-ceph-mon-a", "rook-ceph-mon-f"}, testopk8s.DeploymentNamesUpdated(deploymentsUpdated))
 	testopk8s.ClearDeploymentsUpdated(deploymentsUpdated)
 
-	err = c.failoverMon("f")
+	err = c.failoverMon("f", false)
 	assert.Nil(t, err)
 	assert.ElementsMatch(t, []string{}, testopk8s.DeploymentNamesUpdated(deploymentsUpdated))
 	testopk8s.ClearDeploymentsUpdated(deploymentsUpdated)
@@ -97,6 +100,23 @@ func TestCheckHealth(t *testing.T) {
 	}
 }
 
+func TestCheckHealthDuringQuorumRestore(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	c := New(&clusterd.Context{Clientset: test.New(t, 1), Executor: executor}, "ns", cephv1.ClusterSpec{}, metav1.OwnerReference{}, &sync.Mutex{})
+	setCommonMonProperties(c, 1, cephv1.MonSpec{Count: 3, AllowMultiplePerNode: true}, "myversion")
+
+	// the mons are not checked while the quorum is restored
+	c.RestoreQuorumMon = "a"
+	assert.NoError(t, c.checkHealth())
+
+	c.RestoreQuorumMon = ""
+	assert.Error(t, c.checkHealth())
+}
+
 func TestCheckHealthNotFound(t *testing.T) {
 	var deploymentsUpdated *[]*apps.Deployment
 	updateDeploymentAndWait, deploymentsUpdated = testopk8s.UpdateDeploymentAndWaitStub()
@@ -290,6 +310,51 @@ func TestAddOrRemoveExternalMonitor(t *testing.T) {
 	assert.Equal(t, 2, len(c.ClusterInfo.Monitors))
 }
 
+func TestCheckHealthExternal(t *testing.T) {
+	quorumStatus := client.MonStatusResponse{Quorum: []int{0, 1}}
+	quorumStatus.MonMap.Mons = []client.MonMapEntry{{Name: "a", Rank: 0}, {Name: "b", Rank: 1}}
+	quorumStatus.MonMap.Mons[0].PublicAddr = "1.2.3.1:6789/0"
+	quorumStatus.MonMap.Mons[1].PublicAddr = "1.2.3.5:6789/0"
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			if args[0] == "quorum_status" {
+				output, _ := json.Marshal(quorumStatus)
+				return string(output), nil
+			}
+			return "", nil
+		},
+	}
+	clientset := test.New(t, 1)
+	configDir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(configDir)
+	context := &clusterd.Context{Clientset: clientset, ConfigDir: configDir, Executor: executor}
+
+	// the mon count is not set for an external cluster
+	c := New(context, "ns", cephv1.ClusterSpec{External: cephv1.ExternalSpec{Enable: true}}, metav1.OwnerReference{}, &sync.Mutex{})
+	c.ClusterInfo = clienttest.CreateTestClusterInfo(1)
+	c.ClusterInfo.Namespace = "ns"
+
+	// a mon was added to the external cluster
+	err := c.checkHealth()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(c.ClusterInfo.Monitors))
+	cm, err := clientset.CoreV1().ConfigMaps("ns").Get(EndpointConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ParseMonEndpoints(cm.Data[EndpointDataKey])))
+	assert.Equal(t, "1.2.3.5:6789", ParseMonEndpoints(cm.Data[EndpointDataKey])["b"].Endpoint)
+	assert.Contains(t, cm.Data[csi.ConfigKey], "1.2.3.5:6789")
+
+	// the mon was removed from the external cluster
+	quorumStatus.Quorum = []int{0}
+	quorumStatus.MonMap.Mons = quorumStatus.MonMap.Mons[:1]
+	err = c.checkHealth()
+	assert.NoError(t, err)
+	cm, err = clientset.CoreV1().ConfigMaps("ns").Get(EndpointConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "a=1.2.3.1:6789", cm.Data[EndpointDataKey])
+	assert.NotContains(t, cm.Data[csi.ConfigKey], "1.2.3.5:6789")
+}
+
 func TestForceDeleteFailedMon(t *testing.T) {
 	clientset := test.New(t, 1)
 	context := &clusterd.Context{
@@ -22,6 +22,7 @@ package mon
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -67,8 +68,8 @@ const (
 	tprName           = "mon.rook.io"
 	fsidSecretNameKey = "fsid"
 	monSecretNameKey  = "mon-secret"
-	// AdminSecretName is the name of the admin secret
-	adminSecretNameKey = "admin-secret"
+	// AdminSecretNameKey is the key of the admin key in the mon secret
+	AdminSecretNameKey = "admin-secret"
 	cephUsernameKey    = "ceph-username"
 	cephUserSecretKey  = "ceph-secret"
 
@@ -124,10 +125,16 @@ type Cluster struct {
 	monPodRetryInterval time.Duration
 	monPodTimeout       time.Duration
 	monTimeoutList      map[string]time.Time
+	nextMonRebalance    time.Time
 	mapping             *Mapping
 	ownerRef            metav1.OwnerReference
 	csiConfigMutex      *sync.Mutex
 	isUpgrade           bool
+	// RestoreQuorumMon is the surviving mon to restore the quorum from at the next start of the mons, requested with
+	// the RestoreQuorumAnnotation. It is reset once the restore is done or refused.
+	RestoreQuorumMon string
+	// LastQuorumRestore is the outcome of the last restore of the quorum
+	LastQuorumRestore *QuorumRestore
 }
 
 // monConfig for a single monitor
@@ -143,6 +150,9 @@ type monConfig struct {
 	// DataPathMap is the mapping relationship between mon data stored on the host and mon data
 	// stored in containers.
 	DataPathMap *config.DataPathMap
+	// ReplacedMon is the mon failed over to this mon. Its pod may still run on a down node and is not counted in the
+	// failure domains.
+	ReplacedMon string
 }
 
 // Mapping is mon node and port mapping
@@ -205,6 +215,10 @@ func (c *Cluster) Start(clusterInfo *cephclient.ClusterInfo, rookVersion string,
 		return nil, errors.Wrap(err, "error checking pod memory")
 	}
 
+	if err := validateMonFailureDomain(c.spec.Mon); err != nil {
+		return nil, err
+	}
+
 	logger.Infof("start running mons")
 
 	logger.Debugf("establishing ceph cluster info")
@@ -212,10 +226,24 @@ func (c *Cluster) Start(clusterInfo *cephclient.ClusterInfo, rookVersion string,
 		return nil, errors.Wrap(err, "failed to initialize ceph cluster info")
 	}
 
+	if c.RestoreQuorumMon != "" {
+		if err := c.restoreQuorum(c.RestoreQuorumMon); err != nil {
+			return nil, errors.Wrapf(err, "failed to restore the mon quorum from mon %q", c.RestoreQuorumMon)
+		}
+	}
+
 	logger.Infof("targeting the mon count %d", c.spec.Mon.Count)
 
 	// create the mons for a new cluster or ensure mons are running in an existing cluster
-	return c.ClusterInfo, c.startMons(c.spec.Mon.Count)
+	if err := c.startMons(c.spec.Mon.Count); err != nil {
+		return c.ClusterInfo, err
+	}
+
+	// a failed backup configuration must not block the orchestration of the cluster
+	if err := c.reconcileBackup(); err != nil {
+		logger.Errorf("failed to reconcile the mon backups. %v", err)
+	}
+	return c.ClusterInfo, nil
 }
 
 func (c *Cluster) startMons(targetCount int) error {
@@ -223,7 +251,7 @@ func (c *Cluster) startMons(targetCount int) error {
 	existingCount, mons := c.initMonConfig(targetCount)
 
 	// Assign the mons to nodes
-	if err := c.assignMons(mons); err != nil {
+	if err := c.assignMons(mons, c.monSpreadRequired()); err != nil {
 		return errors.Wrap(err, "failed to assign pods to mons")
 	}
 
@@ -343,6 +371,7 @@ func (c *Cluster) initClusterInfo(cephVersion cephver.CephVersion) error {
 
 	c.ClusterInfo.CephVersion = cephVersion
 	c.ClusterInfo.OwnerRef = c.ownerRef
+	c.ClusterInfo.NetworkSpec = c.spec.Network
 
 	// save cluster monitor config
 	if err = c.saveMonConfig(); err != nil {
@@ -351,9 +380,17 @@ func (c *Cluster) initClusterInfo(cephVersion cephver.CephVersion) error {
 
 	k := keyring.GetSecretStore(c.context, c.ClusterInfo, &c.ownerRef)
 	// store the keyring which all mons share
-	if err := k.CreateOrUpdate(keyringStoreName, c.genMonSharedKeyring()); err != nil {
+	if err := k.CreateOrUpdateWithMeta(keyringStoreName, c.genMonSharedKeyring(), cephv1.GetMonAnnotations(c.spec.Annotations), cephv1.GetMonLabels(c.spec.Labels)); err != nil {
 		return errors.Wrap(err, "failed to save mon keyring secret")
 	}
+	// the daemons use their own keys when the admin key is restricted
+	if c.spec.Security.RestrictAdminKey {
+		logger.Info("admin key is restricted, removing the admin keyring secret")
+		if err := k.Admin().Delete(); err != nil {
+			return errors.Wrap(err, "failed to delete admin keyring secret")
+		}
+		return nil
+	}
 	// also store the admin keyring for other daemons that might need it during init
 	if err := k.Admin().CreateOrUpdate(c.ClusterInfo); err != nil {
 		return errors.Wrap(err, "failed to save admin keyring secret")
@@ -372,7 +409,7 @@ func (c *Cluster) initMonConfig(size int) (int, []*monConfig) {
 			DaemonName:   monitor.Name,
 			Port:         cephutil.GetPortFromEndpoint(monitor.Endpoint),
 			DataPathMap: config.NewStatefulDaemonDataPathMap(
-				c.spec.DataDirHostPath, dataDirRelativeHostPath(monitor.Name), config.MonType, monitor.Name, c.Namespace),
+				c.spec.DataDirHostPath, dataDirRelativeHostPath(monitor.Name), config.MonType, monitor.Name, c.Namespace).WithHostSocketDir(c.spec.Monitoring.IsExporterEnabled()),
 		})
 	}
 
@@ -394,7 +431,7 @@ func (c *Cluster) newMonConfig(monID int) *monConfig {
 		DaemonName:   daemonName,
 		Port:         DefaultMsgr1Port,
 		DataPathMap: config.NewStatefulDaemonDataPathMap(
-			c.spec.DataDirHostPath, dataDirRelativeHostPath(daemonName), config.MonType, daemonName, c.Namespace),
+			c.spec.DataDirHostPath, dataDirRelativeHostPath(daemonName), config.MonType, daemonName, c.Namespace).WithHostSocketDir(c.spec.Monitoring.IsExporterEnabled()),
 	}
 }
 
@@ -431,13 +468,16 @@ func realScheduleMonitor(c *Cluster, mon *monConfig) (SchedulingResult, error) {
 	d.Spec.Template.Spec.Containers[0].Image = c.rookVersion
 	d.Spec.Template.Spec.Containers[0].Command = []string{"/tini"}
 	d.Spec.Template.Spec.Containers[0].Args = []string{"--", "sleep", "3600"}
-	// remove the liveness probe on the canary pod
+	// remove the probes on the canary pod
 	d.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+	d.Spec.Template.Spec.Containers[0].StartupProbe = nil
+	d.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
 
 	// setup affinity settings for pod scheduling
 	p := cephv1.GetMonPlacement(c.spec.Placement)
 	k8sutil.SetNodeAntiAffinityForPod(&d.Spec.Template.Spec, p, requiredDuringScheduling(&c.spec), PreferredDuringScheduling,
 		map[string]string{k8sutil.AppAttr: AppName}, nil)
+	c.applyFailureDomainAntiAffinity(&d.Spec.Template.Spec, mon.ReplacedMon)
 
 	// setup storage on the canary since scheduling will be affected when
 	// monitors are configured to use persistent volumes. the pvcName is set to
@@ -591,7 +631,9 @@ func (c *Cluster) removeCanaryDeployments() {
 	}
 }
 
-func (c *Cluster) assignMons(mons []*monConfig) error {
+// assignMons schedules the mons without node yet. The new mons are refused in the failure domains of the other mons
+// when the spread is required.
+func (c *Cluster) assignMons(mons []*monConfig, requireSpread bool) error {
 	// when monitors are scheduling below by invoking scheduleMonitor() a canary
 	// deployment and optional canary PVC are created. In order for the
 	// anti-affinity rules to be effective, we leave the canary pods in place
@@ -600,6 +642,14 @@ func (c *Cluster) assignMons(mons []*monConfig) error {
 	// and pvcs removed here.
 	defer c.removeCanaryDeployments()
 
+	var monNodes map[string]string
+	if c.spec.Mon.FailureDomain != nil {
+		var err error
+		if monNodes, err = c.monNodes(); err != nil {
+			return errors.Wrap(err, "assignmon: failed to get the nodes of the mons")
+		}
+	}
+
 	// ensure that all monitors have either (1) a node assignment that will be
 	// enforced using a node selector, or (2) configuration permits k8s to handle
 	// scheduling for the monitor.
@@ -625,6 +675,12 @@ func (c *Cluster) assignMons(mons []*monConfig) error {
 		if nodeChoice == nil {
 			return errors.Errorf("assignmon: could not schedule monitor %s", mon.DaemonName)
 		}
+		if err := c.checkMonFailureDomain(mon.DaemonName, nodeChoice, monNodes, mon.ReplacedMon, requireSpread); err != nil {
+			return errors.Wrapf(err, "assignmon: refusing to place mon %s on node %s", mon.DaemonName, nodeChoice.Name)
+		}
+		if monNodes != nil {
+			monNodes[mon.DaemonName] = nodeChoice.Name
+		}
 
 		// store nil in the node mapping to indicate that an explicit node
 		// placement is not being made. otherwise, the node choice will map
@@ -632,7 +688,7 @@ func (c *Cluster) assignMons(mons []*monConfig) error {
 		var nodeInfo *NodeInfo = nil
 		if c.spec.Network.IsHost() || c.spec.Mon.VolumeClaimTemplate == nil {
 			logger.Infof("assignmon: mon %s assigned to node %s", mon.DaemonName, nodeChoice.Name)
-			nodeInfo, err = getNodeInfoFromNode(*nodeChoice)
+			nodeInfo, err = getNodeInfoFromNode(*nodeChoice, c.spec.Network.IPFamily)
 			if err != nil {
 				return errors.Wrapf(err, "assignmon: couldn't get node info for node %s", nodeChoice.Name)
 			}
@@ -779,12 +835,13 @@ func (c *Cluster) saveMonConfig() error {
 		}
 
 		logger.Debugf("updating config map %s that already exists", configMap.Name)
-		if _, err = c.context.Clientset.CoreV1().ConfigMaps(c.Namespace).Update(configMap); err != nil {
+		if _, err = k8sutil.UpdateConfigMap(c.context.Clientset, configMap); err != nil {
 			return errors.Wrap(err, "failed to update mon endpoint config map")
 		}
 	}
 
 	logger.Infof("saved mon endpoints to config map %+v", configMap.Data)
+	InvalidateClusterInfo(c.Namespace)
 
 	// Every time the mon config is updated, must also update the global config so that all daemons
 	// have the most updated version if they restart.
@@ -797,7 +854,7 @@ func (c *Cluster) saveMonConfig() error {
 		return errors.Wrap(err, "failed to write connection config for new mons")
 	}
 
-	if err := csi.SaveClusterConfig(c.context.Clientset, c.Namespace, c.ClusterInfo, c.csiConfigMutex); err != nil {
+	if err := csi.SaveClusterConfig(c.context.Clientset, c.Namespace, c.ClusterInfo, &c.spec.CSI, c.csiConfigMutex); err != nil {
 		return errors.Wrap(err, "failed to update csi cluster config")
 	}
 
@@ -806,6 +863,39 @@ func (c *Cluster) saveMonConfig() error {
 
 var updateDeploymentAndWait = UpdateCephDeploymentAndWait
 
+// updatePVCMetadata re-applies the labels and annotations of the mons to an existing mon pvc. The labels set by rook
+// are kept.
+func (c *Cluster) updatePVCMetadata(m *monConfig) error {
+	pvc, err := c.context.Clientset.CoreV1().PersistentVolumeClaims(c.Namespace).Get(m.ResourceName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get mon pvc %q", m.ResourceName)
+	}
+	updated := pvc.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	for k, v := range cephv1.GetMonAnnotations(c.spec.Annotations) {
+		updated.Annotations[k] = v
+	}
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	rookLabels := c.getLabels(m.DaemonName, false, m.ResourceName)
+	for k, v := range cephv1.GetMonLabels(c.spec.Labels) {
+		if _, ok := rookLabels[k]; !ok {
+			updated.Labels[k] = v
+		}
+	}
+	if reflect.DeepEqual(pvc.Annotations, updated.Annotations) && reflect.DeepEqual(pvc.Labels, updated.Labels) {
+		return nil
+	}
+	if _, err := c.context.Clientset.CoreV1().PersistentVolumeClaims(c.Namespace).Update(updated); err != nil {
+		return errors.Wrapf(err, "failed to update mon pvc %q", m.ResourceName)
+	}
+	logger.Infof("updated the labels and annotations of mon pvc %q", m.ResourceName)
+	return nil
+}
+
 func (c *Cluster) updateMon(m *monConfig, d *apps.Deployment) error {
 	logger.Infof("deployment for mon %s already exists. updating if needed",
 		d.Name)
@@ -815,7 +905,7 @@ func (c *Cluster) updateMon(m *monConfig, d *apps.Deployment) error {
 		logger.Error("failed to restart mon if it is stuck", err)
 	}
 
-	err := updateDeploymentAndWait(c.context, c.ClusterInfo, d, config.MonType, m.DaemonName, c.spec.SkipUpgradeChecks, false)
+	err := updateDeploymentAndWait(c.context, c.ClusterInfo, d, config.MonType, m.DaemonName, client.DaemonUpgradeChecks(&c.spec, config.MonType))
 	if err != nil {
 		return errors.Wrapf(err, "failed to update mon deployment %s", m.ResourceName)
 	}
@@ -889,6 +979,11 @@ func (c *Cluster) startMon(m *monConfig, node *NodeInfo) error {
 		d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, controller.DaemonVolumesDataPVC(pvcName))
 		controller.AddVolumeMountSubPath(&d.Spec.Template.Spec, "ceph-daemon-data")
 		logger.Debugf("adding pvc volume source %s to mon deployment %s", pvcName, d.Name)
+		if pvcExists {
+			if err := c.updatePVCMetadata(m); err != nil {
+				logger.Warningf("failed to update the labels and annotations of mon pvc %q. %v", pvcName, err)
+			}
+		}
 	} else {
 		d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, controller.DaemonVolumesDataHostPath(m.DataPathMap)...)
 		logger.Debugf("adding host path volume source to mon deployment %s", d.Name)
@@ -910,6 +1005,7 @@ func (c *Cluster) startMon(m *monConfig, node *NodeInfo) error {
 		} else {
 			k8sutil.SetNodeAntiAffinityForPod(&d.Spec.Template.Spec, p, requiredDuringScheduling(&c.spec), PreferredDuringScheduling,
 				map[string]string{k8sutil.AppAttr: AppName}, nil)
+			c.applyFailureDomainAntiAffinity(&d.Spec.Template.Spec, m.ReplacedMon)
 		}
 		return c.updateMon(m, d)
 	}
@@ -932,6 +1028,7 @@ func (c *Cluster) startMon(m *monConfig, node *NodeInfo) error {
 	if node == nil {
 		k8sutil.SetNodeAntiAffinityForPod(&d.Spec.Template.Spec, p, requiredDuringScheduling(&c.spec), PreferredDuringScheduling,
 			map[string]string{k8sutil.AppAttr: AppName}, nil)
+		c.applyFailureDomainAntiAffinity(&d.Spec.Template.Spec, m.ReplacedMon)
 	} else {
 		p.PodAffinity = nil
 		p.PodAntiAffinity = nil
@@ -17,7 +17,10 @@ limitations under the License.
 package mon
 
 import (
+	"net"
+
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -45,21 +48,36 @@ func getNodeNameFromHostname(nodes *v1.NodeList, hostname string) (string, bool)
 	return "", false
 }
 
-func getNodeInfoFromNode(n v1.Node) (*NodeInfo, error) {
+// getNodeInfoFromNode returns the first internal IP of the node, of the IP family when it is set
+func getNodeInfoFromNode(n v1.Node, ipFamily cephv1.IPFamilyType) (*NodeInfo, error) {
 	nr := &NodeInfo{
 		Name:     n.Name,
 		Hostname: n.Labels[v1.LabelHostname],
 	}
 
 	for _, ip := range n.Status.Addresses {
-		if ip.Type == v1.NodeInternalIP {
+		if ip.Type == v1.NodeInternalIP && matchesIPFamily(ip.Address, ipFamily) {
 			logger.Debugf("using internal IP %s for node %s", ip.Address, n.Name)
 			nr.Address = ip.Address
 			break
 		}
 	}
 	if nr.Address == "" {
+		if ipFamily != "" {
+			return nil, errors.Errorf("failed to find any internal %s IP on node %s", ipFamily, nr.Name)
+		}
 		return nil, errors.Errorf("failed to find any internal IP on node %s", nr.Name)
 	}
 	return nr, nil
 }
+
+func matchesIPFamily(address string, ipFamily cephv1.IPFamilyType) bool {
+	ip := net.ParseIP(address)
+	switch ipFamily {
+	case cephv1.IPv4:
+		return ip != nil && ip.To4() != nil
+	case cephv1.IPv6:
+		return ip != nil && ip.To4() == nil
+	}
+	return true
+}
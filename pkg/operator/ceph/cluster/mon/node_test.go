@@ -215,12 +215,25 @@ func TestGetNodeInfoFromNode(t *testing.T) {
 	}
 
 	var info *NodeInfo
-	info, err = getNodeInfoFromNode(*node)
+	info, err = getNodeInfoFromNode(*node, "")
 	assert.NotNil(t, err)
 
 	node.Status.Addresses[0].Type = v1.NodeInternalIP
 	node.Status.Addresses[0].Address = "172.17.0.1"
-	info, err = getNodeInfoFromNode(*node)
+	info, err = getNodeInfoFromNode(*node, "")
 	assert.Nil(t, err)
 	assert.Equal(t, "172.17.0.1", info.Address)
+
+	// the internal IP of the ip family is used
+	node.Status.Addresses = append(node.Status.Addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: "fd00:10::1"})
+	info, err = getNodeInfoFromNode(*node, cephv1.IPv6)
+	assert.Nil(t, err)
+	assert.Equal(t, "fd00:10::1", info.Address)
+	info, err = getNodeInfoFromNode(*node, cephv1.IPv4)
+	assert.Nil(t, err)
+	assert.Equal(t, "172.17.0.1", info.Address)
+
+	node.Status.Addresses = node.Status.Addresses[:1]
+	_, err = getNodeInfoFromNode(*node, cephv1.IPv6)
+	assert.NotNil(t, err)
 }
@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RestoreQuorumAnnotation on a CephCluster requests the restore of the quorum of its mons from the surviving mon
+	// named by the annotation. The annotation is removed by the operator once the restore is done or refused.
+	RestoreQuorumAnnotation = "ceph.rook.io/restore-quorum"
+
+	restoreMonmapContainerName = "restore-monmap"
+	restoreMonmapPath          = "/tmp/monmap"
+)
+
+// hook for tests to override
+var updateDeploymentWithoutChecks = k8sutil.UpdateDeploymentAndWait
+
+// QuorumRestore is the outcome of a restore of the quorum requested with RestoreQuorumMon
+type QuorumRestore struct {
+	// Restored is true when the monmap was rebuilt, false when the restore was refused
+	Restored bool
+	// Message describes the outcome of the restore
+	Message string
+}
+
+// restoreMonmapScript rebuilds the monmap of the mon with only the mon itself, with the arguments of the mon daemon.
+// The mons removed from the monmap are looked up in the monmap so the script can run again after a partial restore.
+var restoreMonmapScript = `
+set -xe
+` + cephMonCommand + ` "$@" --extract-monmap=` + restoreMonmapPath + `
+` + monmaptoolCommand + ` --print ` + restoreMonmapPath + `
+for mon in $(` + monmaptoolCommand + ` --print ` + restoreMonmapPath + ` | sed -n 's/.* mon\.\([^ ]*\)$/\1/p'); do
+  if [ "$mon" != "$ROOK_RESTORE_MON" ]; then
+    ` + monmaptoolCommand + ` ` + restoreMonmapPath + ` --rm "$mon"
+  fi
+done
+` + cephMonCommand + ` "$@" --inject-monmap=` + restoreMonmapPath + `
+`
+
+// restoreQuorum restores the quorum of the mons from a single surviving mon, like the manual disaster recovery: the
+// monmap of the surviving mon is rebuilt without the other mons, the other mons are removed, and the surviving mon is
+// restarted alone in quorum. The mons are then grown back to the desired count by the mon orchestration. The restore
+// is refused when the mons are in quorum or when the surviving mon does not exist.
+func (c *Cluster) restoreQuorum(goodMon string) error {
+	if _, ok := c.ClusterInfo.Monitors[goodMon]; !ok {
+		c.finishQuorumRestore(false, "mon %q to restore the quorum from does not exist", goodMon)
+		return nil
+	}
+	deployment, err := c.context.Clientset.AppsV1().Deployments(c.Namespace).Get(resourceName(goodMon), metav1.GetOptions{})
+	if err != nil {
+		c.finishQuorumRestore(false, "failed to get the deployment of mon %q to restore the quorum from. %v", goodMon, err)
+		return nil
+	}
+	if _, err := client.GetMonQuorumStatus(c.context, c.ClusterInfo); err == nil {
+		// the monmap of the mon may have been rebuilt by a previous attempt, its init container must not stay
+		if err := c.removeRestoreMonmapContainer(deployment); err != nil {
+			return err
+		}
+		c.finishQuorumRestore(false, "the mons are in quorum, not restoring the quorum from mon %q", goodMon)
+		return nil
+	}
+
+	badMons := []string{}
+	for name := range c.ClusterInfo.Monitors {
+		if name != goodMon {
+			badMons = append(badMons, name)
+		}
+	}
+	sort.Strings(badMons)
+	logger.Warningf("restoring the mon quorum from mon %q, removing mons %v", goodMon, badMons)
+
+	// the other mons must not run with the previous monmap, and the daemons must only connect to the surviving mon
+	for _, name := range badMons {
+		c.deleteMonDeployment(name)
+		c.deleteMonResources(name)
+	}
+	if err := c.saveMonConfig(); err != nil {
+		return errors.Wrap(err, "failed to save the mon config with the surviving mon")
+	}
+
+	// rebuild the monmap in an init container of the surviving mon before it starts, the init container of a
+	// previous attempt is replaced
+	restoring := deployment.DeepCopy()
+	container, err := restoreMonmapContainer(restoring, goodMon)
+	if err != nil {
+		return err
+	}
+	restoring.Spec.Template.Spec.InitContainers = append(withoutRestoreMonmapContainer(restoring.Spec.Template.Spec.InitContainers), container)
+	if err := c.updateMonDeploymentUnchecked(restoring); err != nil {
+		return errors.Wrapf(err, "failed to rebuild the monmap of mon %q", goodMon)
+	}
+	if err := c.removeRestoreMonmapContainer(restoring); err != nil {
+		return err
+	}
+
+	if err := waitForQuorumWithMons(c.context, c.ClusterInfo, []string{goodMon}, 10, true); err != nil {
+		return errors.Wrapf(err, "failed to wait for mon %q to form a quorum", goodMon)
+	}
+	c.finishQuorumRestore(true, "restored the mon quorum from mon %q, removed mons %v", goodMon, badMons)
+	return nil
+}
+
+// restoreMonmapContainer returns the init container rebuilding the monmap of the mon, with the image, the arguments,
+// the environment and the mounts of the mon container of its deployment
+func restoreMonmapContainer(d *apps.Deployment, monName string) (v1.Container, error) {
+	for _, container := range d.Spec.Template.Spec.Containers {
+		if container.Name != "mon" {
+			continue
+		}
+		args := []string{}
+		for _, arg := range container.Args {
+			if arg != "--foreground" {
+				args = append(args, arg)
+			}
+		}
+		return v1.Container{
+			Name:            restoreMonmapContainerName,
+			Image:           container.Image,
+			Command:         []string{"/bin/bash", "-c", restoreMonmapScript, "--"},
+			Args:            args,
+			Env:             append(append([]v1.EnvVar{}, container.Env...), v1.EnvVar{Name: "ROOK_RESTORE_MON", Value: monName}),
+			VolumeMounts:    container.VolumeMounts,
+			SecurityContext: container.SecurityContext,
+			Resources:       container.Resources,
+		}, nil
+	}
+	return v1.Container{}, errors.Errorf("mon container not found in deployment %q", d.Name)
+}
+
+// removeRestoreMonmapContainer restarts the mon without the init container rebuilding its monmap
+func (c *Cluster) removeRestoreMonmapContainer(d *apps.Deployment) error {
+	initContainers := withoutRestoreMonmapContainer(d.Spec.Template.Spec.InitContainers)
+	if len(initContainers) == len(d.Spec.Template.Spec.InitContainers) {
+		return nil
+	}
+	restored := d.DeepCopy()
+	restored.Spec.Template.Spec.InitContainers = initContainers
+	if err := c.updateMonDeploymentUnchecked(restored); err != nil {
+		return errors.Wrapf(err, "failed to remove the init container rebuilding the monmap of deployment %q", d.Name)
+	}
+	return nil
+}
+
+func withoutRestoreMonmapContainer(containers []v1.Container) []v1.Container {
+	result := []v1.Container{}
+	for _, container := range containers {
+		if container.Name != restoreMonmapContainerName {
+			result = append(result, container)
+		}
+	}
+	return result
+}
+
+// updateMonDeploymentUnchecked updates the deployment of a mon and waits for it without the ok-to-stop checks, which
+// need a quorum
+func (c *Cluster) updateMonDeploymentUnchecked(d *apps.Deployment) error {
+	current, err := c.context.Clientset.AppsV1().Deployments(c.Namespace).Get(d.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get deployment %q", d.Name)
+	}
+	d.ResourceVersion = current.ResourceVersion
+	_, err = updateDeploymentWithoutChecks(c.context, d, c.Namespace, func(action string) error { return nil })
+	return err
+}
+
+func (c *Cluster) finishQuorumRestore(restored bool, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if restored {
+		logger.Info(message)
+	} else {
+		logger.Warning(message)
+	}
+	c.RestoreQuorumMon = ""
+	c.LastQuorumRestore = &QuorumRestore{Restored: restored, Message: message}
+}
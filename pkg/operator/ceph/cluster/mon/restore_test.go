@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mon
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRestoreMonmapContainer(t *testing.T) {
+	d := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mon-b"}}
+	_, err := restoreMonmapContainer(d, "b")
+	assert.Error(t, err)
+
+	d.Spec.Template.Spec.Containers = []v1.Container{{
+		Name:  "mon",
+		Image: "ceph/ceph:v15",
+		Args:  []string{"--fsid=1234", "--mon-host=$(ROOK_CEPH_MON_HOST)", "--id=b", "--foreground"},
+		Env:   []v1.EnvVar{{Name: "ROOK_CEPH_MON_HOST"}},
+	}}
+	container, err := restoreMonmapContainer(d, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, restoreMonmapContainerName, container.Name)
+	assert.Equal(t, "ceph/ceph:v15", container.Image)
+	assert.Equal(t, []string{"/bin/bash", "-c", restoreMonmapScript, "--"}, container.Command)
+	assert.Equal(t, []string{"--fsid=1234", "--mon-host=$(ROOK_CEPH_MON_HOST)", "--id=b"}, container.Args)
+	assert.Equal(t, []v1.EnvVar{{Name: "ROOK_CEPH_MON_HOST"}, {Name: "ROOK_RESTORE_MON", Value: "b"}}, container.Env)
+	// the env of the mon container is not changed
+	assert.Len(t, d.Spec.Template.Spec.Containers[0].Env, 1)
+}
+
+func TestRestoreQuorum(t *testing.T) {
+	quorumLost := true
+	var c *Cluster
+	context, err := newTestStartClusterWithQuorumResponse(t, "ns", func() (string, error) {
+		if quorumLost {
+			return "", errors.New("timed out")
+		}
+		return clienttest.MonInQuorumResponseFromMons(c.ClusterInfo.Monitors), nil
+	})
+	assert.NoError(t, err)
+	c = newCluster(context, "ns", true, v1.ResourceRequirements{})
+	c.csiConfigMutex = &sync.Mutex{}
+	c.ClusterInfo = clienttest.CreateTestClusterInfo(3)
+	c.ClusterInfo.Namespace = "ns"
+	c.ClusterInfo.CephVersion = cephver.Octopus
+	for _, name := range []string{"a", "b", "c"} {
+		d, err := c.makeDeployment(c.newMonConfig(int(name[0]-'a')), false)
+		assert.NoError(t, err)
+		_, err = context.Clientset.AppsV1().Deployments("ns").Create(d)
+		assert.NoError(t, err)
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mon-b-1234", Namespace: "ns", Labels: map[string]string{"app": AppName, "mon": "b"}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	_, err = context.Clientset.CoreV1().Pods("ns").Create(pod)
+	assert.NoError(t, err)
+	defaultUpdate := updateDeploymentWithoutChecks
+	defer func() { updateDeploymentWithoutChecks = defaultUpdate }()
+	updated := []*apps.Deployment{}
+	updateDeploymentWithoutChecks = func(context *clusterd.Context, d *apps.Deployment, namespace string, verifyCallback func(action string) error) (*apps.Deployment, error) {
+		updated = append(updated, d.DeepCopy())
+		// the mon forms a quorum with the rebuilt monmap
+		quorumLost = false
+		_, err := context.Clientset.AppsV1().Deployments(namespace).Update(d)
+		return d, err
+	}
+
+	// unknown mon
+	assert.NoError(t, c.restoreQuorum("z"))
+	assert.False(t, c.LastQuorumRestore.Restored)
+	assert.Len(t, c.ClusterInfo.Monitors, 3)
+
+	// the monmap is rebuilt in an init container of the surviving mon, and the other mons are removed
+	assert.NoError(t, c.restoreQuorum("b"))
+	assert.True(t, c.LastQuorumRestore.Restored)
+	assert.Equal(t, []string{"b"}, monNames(c))
+	assert.Len(t, updated, 2)
+	initContainers := updated[0].Spec.Template.Spec.InitContainers
+	assert.Equal(t, restoreMonmapContainerName, initContainers[len(initContainers)-1].Name)
+	for _, container := range updated[1].Spec.Template.Spec.InitContainers {
+		assert.NotEqual(t, restoreMonmapContainerName, container.Name)
+	}
+	_, err = context.Clientset.AppsV1().Deployments("ns").Get("rook-ceph-mon-a", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = context.Clientset.AppsV1().Deployments("ns").Get("rook-ceph-mon-b", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// the restore is refused when the mons are in quorum
+	updated = []*apps.Deployment{}
+	assert.NoError(t, c.restoreQuorum("b"))
+	assert.False(t, c.LastQuorumRestore.Restored)
+	assert.Empty(t, updated)
+}
+
+func monNames(c *Cluster) []string {
+	names := []string{}
+	for name := range c.ClusterInfo.Monitors {
+		names = append(names, name)
+	}
+	return names
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package mon
 
 import (
+	"net"
 	"strconv"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -47,6 +50,9 @@ func (c *Cluster) createService(mon *monConfig) (string, error) {
 			Selector: labels,
 		},
 	}
+	cephv1.GetMonAnnotations(c.spec.Annotations).ApplyToObjectMeta(&svcDef.ObjectMeta)
+	cephv1.GetMonLabels(c.spec.Labels).ApplyToObjectMeta(&svcDef.ObjectMeta)
+	config.ApplyIPFamily(c.spec.Network, svcDef)
 	k8sutil.SetOwnerRef(&svcDef.ObjectMeta, &c.ownerRef)
 
 	// If deploying Nautilus or newer we need a new port for the monitor service
@@ -65,7 +71,7 @@ func (c *Cluster) createService(mon *monConfig) (string, error) {
 	// mon endpoint are not actually like, they remain with the mgrs1 format
 	// however it's interesting to show that monitors can be addressed via 2 different ports
 	// in the end the service has msgr1 and msgr2 ports configured so it's not entirely wrong
-	logger.Infof("mon %q endpoint are [v2:%s:%s,v1:%s:%d]", mon.DaemonName, s.Spec.ClusterIP, strconv.Itoa(int(DefaultMsgr2Port)), s.Spec.ClusterIP, mon.Port)
+	logger.Infof("mon %q endpoint are [v2:%s,v1:%s]", mon.DaemonName, net.JoinHostPort(s.Spec.ClusterIP, strconv.Itoa(int(DefaultMsgr2Port))), net.JoinHostPort(s.Spec.ClusterIP, strconv.Itoa(int(mon.Port))))
 
 	return s.Spec.ClusterIP, nil
 }
@@ -17,9 +17,10 @@ limitations under the License.
 package mon
 
 import (
-	"fmt"
+	"net"
 	"os"
 	"path"
+	"strconv"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
@@ -69,6 +70,7 @@ func (c *Cluster) makeDeployment(monConfig *monConfig, canary bool) (*apps.Deplo
 	}
 	k8sutil.AddRookVersionLabelToDeployment(d)
 	cephv1.GetMonAnnotations(c.spec.Annotations).ApplyToObjectMeta(&d.ObjectMeta)
+	cephv1.GetMonLabels(c.spec.Labels).ApplyToObjectMeta(&d.ObjectMeta)
 	controller.AddCephVersionLabelToDeployment(c.ClusterInfo.CephVersion, d)
 	k8sutil.SetOwnerRef(&d.ObjectMeta, &c.ownerRef)
 
@@ -90,6 +92,7 @@ func (c *Cluster) makeDeployment(monConfig *monConfig, canary bool) (*apps.Deplo
 			Type: apps.RecreateDeploymentStrategyType,
 		},
 	}
+	k8sutil.ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
 
 	return d, nil
 }
@@ -114,6 +117,7 @@ func (c *Cluster) makeDeploymentPVC(m *monConfig, canary bool) (*v1.PersistentVo
 	}
 	k8sutil.AddRookVersionLabelToObjectMeta(&pvc.ObjectMeta)
 	cephv1.GetMonAnnotations(c.spec.Annotations).ApplyToObjectMeta(&pvc.ObjectMeta)
+	cephv1.GetMonLabels(c.spec.Labels).ApplyToObjectMeta(&pvc.ObjectMeta)
 	controller.AddCephVersionLabelToObjectMeta(c.ClusterInfo.CephVersion, &pvc.ObjectMeta)
 	k8sutil.SetOwnerRef(&pvc.ObjectMeta, &c.ownerRef)
 
@@ -156,8 +160,11 @@ func (c *Cluster) makeMonPod(monConfig *monConfig, canary bool, PVCName string)
 		Volumes:           controller.DaemonVolumesBase(monConfig.DataPathMap, keyringStoreName),
 		HostNetwork:       c.spec.Network.IsHost(),
 		PriorityClassName: cephv1.GetMonPriorityClassName(c.spec.PriorityClassNames),
+		SecurityContext:   cephv1.GetPodSecurityContext(c.spec.Security.PodSecurityContexts, cephv1.KeyMon),
 	}
 
+	controller.ApplyLogCollector(&podSpec, config.MonType, "mon."+monConfig.DaemonName, &c.spec, monConfig.DataPathMap)
+
 	// Replace default unreachable node toleration
 	if c.spec.Mon.VolumeClaimTemplate != nil {
 		k8sutil.AddUnreachableNodeToleration(&podSpec)
@@ -172,6 +179,8 @@ func (c *Cluster) makeMonPod(monConfig *monConfig, canary bool, PVCName string)
 		Spec: podSpec,
 	}
 	cephv1.GetMonAnnotations(c.spec.Annotations).ApplyToObjectMeta(&pod.ObjectMeta)
+	cephv1.GetMonLabels(c.spec.Labels).ApplyToObjectMeta(&pod.ObjectMeta)
+	config.ApplyConnectionsAnnotation(c.spec.Network, &pod.ObjectMeta)
 
 	if c.spec.Network.IsHost() {
 		pod.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
@@ -218,13 +227,13 @@ func (c *Cluster) makeMonFSInitContainer(monConfig *monConfig) v1.Container {
 		Command: []string{
 			cephMonCommand,
 		},
-		Args: append(
+		Args: append(append(
 			controller.DaemonFlags(c.ClusterInfo, monConfig.DaemonName),
 			// needed so we can generate an initial monmap
 			// otherwise the mkfs will say: "0  no local addrs match monmap"
 			config.NewFlag("public-addr", monConfig.PublicIP),
 			"--mkfs",
-		),
+		), config.IPFamilyFlags(c.spec.Network)...),
 		Image:           c.spec.CephVersion.Image,
 		VolumeMounts:    controller.DaemonVolumeMounts(monConfig.DataPathMap, keyringStoreName),
 		SecurityContext: PodSecurityContext(),
@@ -243,7 +252,7 @@ func (c *Cluster) makeMonDaemonContainer(monConfig *monConfig) v1.Container {
 	if c.spec.Network.IsHost() && monConfig.Port != DefaultMsgr1Port {
 		logger.Warningf("Starting mon %s with host networking on a non-default port %d. The mon must be failed over before enabling msgr2.",
 			monConfig.DaemonName, monConfig.Port)
-		publicAddr = fmt.Sprintf("%s:%d", publicAddr, monConfig.Port)
+		publicAddr = net.JoinHostPort(publicAddr, strconv.Itoa(int(monConfig.Port)))
 	}
 
 	container := v1.Container{
@@ -284,7 +293,7 @@ func (c *Cluster) makeMonDaemonContainer(monConfig *monConfig) v1.Container {
 	}
 
 	// If the liveness probe is enabled
-	container = config.ConfigureLivenessProbe(cephv1.KeyMon, container, c.spec.HealthCheck)
+	container = config.ConfigureProbes(cephv1.KeyMon, container, c.spec.HealthCheck)
 
 	// If host networking is enabled, we don't need a bind addr that is different from the public addr
 	if !c.spec.Network.IsHost() {
@@ -294,19 +303,23 @@ func (c *Cluster) makeMonDaemonContainer(monConfig *monConfig) v1.Container {
 			config.NewFlag("public-bind-addr", controller.ContainerEnvVarReference(podIPEnvVar)))
 	}
 
+	// the mons bind to the ip family of the network before the settings are in the centralized configuration
+	container.Args = append(container.Args, config.IPFamilyFlags(c.spec.Network)...)
+
 	// Add messenger 2 port
 	addContainerPort(container, "tcp-msgr2", 3300)
 
 	return container
 }
 
-// UpdateCephDeploymentAndWait verifies a deployment can be stopped or continued
-func UpdateCephDeploymentAndWait(context *clusterd.Context, clusterInfo *client.ClusterInfo, deployment *apps.Deployment, daemonType, daemonName string, skipUpgradeChecks, continueUpgradeAfterChecksEvenIfNotHealthy bool) error {
+// UpdateCephDeploymentAndWait verifies a deployment can be stopped or continued with the upgrade checks of its
+// daemon type
+func UpdateCephDeploymentAndWait(context *clusterd.Context, clusterInfo *client.ClusterInfo, deployment *apps.Deployment, daemonType, daemonName string, checks client.UpgradeChecks) error {
 
 	callback := func(action string) error {
 		// At this point, we are in an upgrade
-		if skipUpgradeChecks {
-			logger.Warningf("this is a Ceph upgrade, not performing upgrade checks because skipUpgradeChecks is %t", skipUpgradeChecks)
+		if checks.Skip {
+			logger.Warningf("this is a Ceph upgrade, not performing upgrade checks because skipUpgradeChecks is %t for the %s daemons", checks.Skip, daemonType)
 			return nil
 		}
 
@@ -315,7 +328,7 @@ func UpdateCephDeploymentAndWait(context *clusterd.Context, clusterInfo *client.
 		if action == "stop" {
 			err := client.OkToStop(context, clusterInfo, deployment.Name, daemonType, daemonName)
 			if err != nil {
-				if continueUpgradeAfterChecksEvenIfNotHealthy {
+				if checks.ContinueIfNotHealthy {
 					logger.Infof("The %s daemon %s is not ok-to-stop but 'continueUpgradeAfterChecksEvenIfNotHealthy' is true, so proceeding to stop...", daemonType, daemonName)
 					return nil
 				}
@@ -324,9 +337,9 @@ func UpdateCephDeploymentAndWait(context *clusterd.Context, clusterInfo *client.
 		}
 
 		if action == "continue" {
-			err := client.OkToContinue(context, clusterInfo, deployment.Name, daemonType, daemonName)
+			err := client.OkToContinueWithTimeout(context, clusterInfo, deployment.Name, daemonType, daemonName, checks.WaitTimeout)
 			if err != nil {
-				if continueUpgradeAfterChecksEvenIfNotHealthy {
+				if checks.ContinueIfNotHealthy {
 					logger.Infof("The %s daemon %s is not ok-to-stop but 'continueUpgradeAfterChecksEvenIfNotHealthy' is true, so continuing...", daemonType, daemonName)
 					return nil
 				}
@@ -337,6 +350,11 @@ func UpdateCephDeploymentAndWait(context *clusterd.Context, clusterInfo *client.
 		return nil
 	}
 
+	// the upgrade is paused before the next daemon when the image of the cluster changed in the meantime
+	if err := controller.CheckUpgradeCancelled(context, clusterInfo.Namespace); err != nil {
+		return err
+	}
 	_, err := k8sutil.UpdateDeploymentAndWait(context, deployment, clusterInfo.Namespace, callback)
+	controller.ReportUpgradeProgress(context, clusterInfo.Namespace, daemonType, err)
 	return err
 }
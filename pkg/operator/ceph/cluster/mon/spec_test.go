@@ -89,6 +89,23 @@ func testPodSpec(t *testing.T, monID string, pvc bool) {
 		"my-priority-class")
 }
 
+func TestPodSecurityContext(t *testing.T) {
+	c := New(&clusterd.Context{Clientset: testop.New(t, 1), ConfigDir: "/var/lib/rook"}, "ns", cephv1.ClusterSpec{}, metav1.OwnerReference{}, &sync.Mutex{})
+	setCommonMonProperties(c, 0, cephv1.MonSpec{Count: 3, AllowMultiplePerNode: true}, "rook/rook:myversion")
+	monConfig := testGenMonConfig("a")
+
+	// no security context is set by default
+	d, err := c.makeDeployment(monConfig, false)
+	assert.NoError(t, err)
+	assert.Nil(t, d.Spec.Template.Spec.SecurityContext)
+
+	group := int64(167)
+	c.spec.Security.PodSecurityContexts = cephv1.PodSecurityContextsSpec{"all": {FSGroup: &group}}
+	d, err = c.makeDeployment(monConfig, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &group, d.Spec.Template.Spec.SecurityContext.FSGroup)
+}
+
 func TestDeploymentPVCSpec(t *testing.T) {
 	clientset := testop.New(t, 1)
 	c := New(
@@ -18,16 +18,19 @@ limitations under the License.
 package cluster
 
 import (
+	"reflect"
+
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	clientcontroller "github.com/rook/rook/pkg/operator/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	"github.com/rook/rook/pkg/operator/ceph/object/bucket"
 )
 
 var (
-	monitorDaemonList = []string{"mon", "osd", "status"}
+	monitorDaemonList = []string{"mon", "osd", "status", "osd-resources", "certificates", "crash", "network", "osd-reweight", "key-rotation"}
 )
 
 func (c *ClusterController) configureCephMonitoring(cluster *cluster, clusterInfo *cephclient.ClusterInfo) {
@@ -44,13 +47,20 @@ func (c *ClusterController) configureCephMonitoring(cluster *cluster, clusterInf
 					close(cluster.monitoringChannels[daemon].stopChan)
 					// Set monitoring to false since it's not running anymore
 					cluster.monitoringChannels[daemon].monitoringRunning = false
+				} else if monitoringSpecChanged(health, daemon, cluster.Spec) {
+					// the goroutine keeps the settings it was started with, restart it with the new settings
+					logger.Infof("ceph %s settings changed, restarting the goroutine for cluster %q", daemon, cluster.Namespace)
+					close(health.stopChan)
+					health.stopChan = make(chan struct{})
+					c.startMonitoringCheck(cluster, clusterInfo, daemon)
 				} else {
 					logger.Debugf("ceph %s health go routine is already running for cluster %q", daemon, cluster.Namespace)
 				}
 			} else {
 				// if not already running and not disabled, we run it
 				if !isDisabled {
-					// Run the go routine
+					// Run the go routine with a new channel since the previous one was closed
+					health.stopChan = make(chan struct{})
 					c.startMonitoringCheck(cluster, clusterInfo, daemon)
 
 					// Set the flag to indicate monitoring is running
@@ -103,12 +113,52 @@ func isMonitoringDisabled(daemon string, clusterSpec *cephv1.ClusterSpec) bool {
 
 	case "status":
 		return clusterSpec.HealthCheck.DaemonHealth.Status.Disabled
+
+	case "osd-resources":
+		return !clusterSpec.ResourceAutoscaling.OSD.Enabled
+
+	case "certificates":
+		return !mgr.IsDashboardCertificateManaged(clusterSpec)
+
+	case "crash":
+		return clusterSpec.HealthCheck.DaemonHealth.Crash.Disabled || clusterSpec.External.Enable
+
+	case "network":
+		return !clusterSpec.Network.IsMultus() || clusterSpec.External.Enable
+
+	case "osd-reweight":
+		return !clusterSpec.Capacity.ReweightByUtilization.Enabled || clusterSpec.External.Enable
+
+	case "key-rotation":
+		cephxPolicy := clusterSpec.Security.CephxKeyRotation
+		return (!clusterSpec.Security.KeyRotation.Enabled && !cephxPolicy.Enabled && cephxPolicy.KeyGeneration == 0) || clusterSpec.External.Enable
 	}
 
 	return false
 }
 
+// monitoringSpec returns the settings of the cluster a monitoring goroutine keeps from its start, nil if the goroutine
+// reads its settings at each check
+func monitoringSpec(daemon string, clusterSpec *cephv1.ClusterSpec) interface{} {
+	switch daemon {
+	case "osd-reweight":
+		return clusterSpec.Capacity.ReweightByUtilization
+
+	case "key-rotation":
+		// the keys are checked right away when the policies change, such as an increased cephx key generation
+		return clusterSpec.Security
+	}
+
+	return nil
+}
+
+// monitoringSpecChanged returns whether the settings of a running monitoring goroutine changed since its start
+func monitoringSpecChanged(health *clusterHealth, daemon string, clusterSpec *cephv1.ClusterSpec) bool {
+	return !reflect.DeepEqual(health.spec, monitoringSpec(daemon, clusterSpec))
+}
+
 func (c *ClusterController) startMonitoringCheck(cluster *cluster, clusterInfo *cephclient.ClusterInfo, daemon string) {
+	cluster.monitoringChannels[daemon].spec = monitoringSpec(daemon, cluster.Spec)
 	switch daemon {
 	case "mon":
 		healthChecker := mon.NewHealthChecker(cluster.mons)
@@ -117,14 +167,51 @@ func (c *ClusterController) startMonitoringCheck(cluster *cluster, clusterInfo *
 
 	case "osd":
 		if !cluster.Spec.External.Enable {
-			c.osdChecker = osd.NewOSDHealthMonitor(c.context, clusterInfo, cluster.Spec.RemoveOSDsIfOutAndSafeToRemove, cluster.Spec.HealthCheck)
+			osdChecker := osd.NewOSDHealthMonitor(c.context, clusterInfo, cluster.Spec.RemoveOSDsIfOutAndSafeToRemove, cluster.Spec.HealthCheck)
+			osdChecker.SetEventRecorder(c.recorder)
+			if err := osdChecker.ConfigureMonAutoOut(); err != nil {
+				logger.Warningf("failed to configure the mons marking out the down osds. %v", err)
+			}
 			logger.Infof("enabling ceph %s monitoring goroutine for cluster %q", daemon, cluster.Namespace)
-			go c.osdChecker.Start(cluster.monitoringChannels[daemon].stopChan)
+			go osdChecker.Start(cluster.monitoringChannels[daemon].stopChan)
 		}
 
+	case "osd-resources":
+		if !cluster.Spec.External.Enable {
+			autoscaler := osd.NewOSDResourceAutoscaler(c.context, clusterInfo, cluster.Spec.ResourceAutoscaling.OSD)
+			logger.Infof("enabling ceph %s autoscaling goroutine for cluster %q", daemon, cluster.Namespace)
+			go autoscaler.Start(cluster.monitoringChannels[daemon].stopChan)
+		}
+
+	case "certificates":
+		certificateChecker := mgr.NewDashboardCertificateChecker(c.context, clusterInfo, *cluster.Spec)
+		logger.Infof("enabling ceph %s renewal goroutine for cluster %q", daemon, cluster.Namespace)
+		go certificateChecker.Start(cluster.monitoringChannels[daemon].stopChan)
+
 	case "status":
 		cephChecker := newCephStatusChecker(c.context, clusterInfo, cluster.Spec)
+		cephChecker.recorder = c.recorder
 		logger.Infof("enabling ceph %s monitoring goroutine for cluster %q", daemon, cluster.Namespace)
 		go cephChecker.checkCephStatus(cluster.monitoringChannels[daemon].stopChan)
+
+	case "crash":
+		crashChecker := newCrashChecker(c.context, clusterInfo, cluster.Spec, c.recorder)
+		logger.Infof("enabling ceph %s monitoring goroutine for cluster %q", daemon, cluster.Namespace)
+		go crashChecker.checkCrashes(cluster.monitoringChannels[daemon].stopChan)
+
+	case "network":
+		networkChecker := newNetworkStatusChecker(c.context, clusterInfo, cluster.Spec)
+		logger.Infof("enabling ceph %s monitoring goroutine for cluster %q", daemon, cluster.Namespace)
+		go networkChecker.checkNetworkStatus(cluster.monitoringChannels[daemon].stopChan)
+
+	case "osd-reweight":
+		reweighter := osd.NewOSDReweighter(c.context, clusterInfo, cluster.Spec.Capacity.ReweightByUtilization, c.recorder)
+		logger.Infof("enabling ceph %s goroutine for cluster %q", daemon, cluster.Namespace)
+		go reweighter.Start(cluster.monitoringChannels[daemon].stopChan)
+
+	case "key-rotation":
+		keyRotationChecker := newKeyRotationChecker(c.context, clusterInfo, c.rookImage)
+		logger.Infof("enabling ceph %s goroutine for cluster %q", daemon, cluster.Namespace)
+		go keyRotationChecker.checkKeyRotation(cluster.monitoringChannels[daemon].stopChan)
 	}
 }
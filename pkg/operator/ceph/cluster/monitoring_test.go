@@ -21,6 +21,8 @@ import (
 	"testing"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestIsMonitoringDisabled(t *testing.T) {
@@ -35,6 +37,16 @@ func TestIsMonitoringDisabled(t *testing.T) {
 	}{
 		{"isDisabled", args{"mon", &cephv1.ClusterSpec{}}, false},
 		{"isEnabled", args{"mon", &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Monitor: cephv1.HealthCheckSpec{Disabled: true}}}}}, true},
+		{"osdResourcesDisabled", args{"osd-resources", &cephv1.ClusterSpec{}}, true},
+		{"osdResourcesEnabled", args{"osd-resources", &cephv1.ClusterSpec{ResourceAutoscaling: cephv1.ResourceAutoscalingSpec{OSD: cephv1.DaemonResourceAutoscalingSpec{Enabled: true}}}}, false},
+		{"crashEnabled", args{"crash", &cephv1.ClusterSpec{}}, false},
+		{"crashDisabled", args{"crash", &cephv1.ClusterSpec{HealthCheck: cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{Crash: cephv1.CrashHealthCheckSpec{HealthCheckSpec: cephv1.HealthCheckSpec{Disabled: true}}}}}}, true},
+		{"crashExternal", args{"crash", &cephv1.ClusterSpec{External: cephv1.ExternalSpec{Enable: true}}}, true},
+		{"networkDisabled", args{"network", &cephv1.ClusterSpec{}}, true},
+		{"networkMultus", args{"network", &cephv1.ClusterSpec{Network: cephv1.NetworkSpec{NetworkSpec: rookv1.NetworkSpec{Provider: "multus"}}}}, false},
+		{"keyRotationDisabled", args{"key-rotation", &cephv1.ClusterSpec{}}, true},
+		{"keyRotationEncryption", args{"key-rotation", &cephv1.ClusterSpec{Security: cephv1.SecuritySpec{KeyRotation: cephv1.KeyRotationSpec{Enabled: true}}}}, false},
+		{"keyRotationCephxGeneration", args{"key-rotation", &cephv1.ClusterSpec{Security: cephv1.SecuritySpec{CephxKeyRotation: cephv1.CephxKeyRotationSpec{KeyGeneration: 1}}}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -44,3 +56,16 @@ func TestIsMonitoringDisabled(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitoringSpecChanged(t *testing.T) {
+	spec := &cephv1.ClusterSpec{Capacity: cephv1.CapacitySpec{ReweightByUtilization: cephv1.ReweightByUtilizationSpec{Enabled: true, Interval: "1h"}}}
+	reweight := &clusterHealth{spec: monitoringSpec("osd-reweight", spec)}
+	status := &clusterHealth{spec: monitoringSpec("status", spec)}
+	assert.False(t, monitoringSpecChanged(reweight, "osd-reweight", spec))
+	assert.False(t, monitoringSpecChanged(status, "status", spec))
+
+	// the reweight goroutine is restarted when its settings change
+	spec.Capacity.ReweightByUtilization.Interval = "2h"
+	assert.True(t, monitoringSpecChanged(reweight, "osd-reweight", spec))
+	assert.False(t, monitoringSpecChanged(status, "status", spec))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package netpolicy creates the network policies allowing the traffic of the daemons of a cluster in the namespaces
+// denying it by default
+package netpolicy
+
+import (
+	"net"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// AppName is the value of the "app" label of the network policies created by the operator
+	AppName = "rook-ceph-network-policy"
+
+	// DaemonsPolicyName is the name of the network policy allowing the traffic between the daemons
+	DaemonsPolicyName = "rook-ceph-daemons"
+
+	// the label set by K8s on the namespaces with their name
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", "ceph-netpolicy")
+
+// Reconcile creates or updates the network policy allowing the connections to the daemons of the cluster from the
+// pods of the namespace, the operator and the allowed clients, or deletes all the network policies of the cluster
+// when they are disabled. The policies do not apply to the host network.
+func Reconcile(context *clusterd.Context, namespace string, ownerRef metav1.OwnerReference, spec *cephv1.ClusterSpec, operatorNamespace string) error {
+	if !spec.Network.AreNetworkPoliciesEnabled() {
+		if spec.Network.NetworkPolicies != nil && spec.Network.NetworkPolicies.Enabled {
+			logger.Infof("network policies are not created for the cluster in namespace %q since it runs on the host network", namespace)
+		}
+		return deletePolicies(context, namespace)
+	}
+
+	policy, err := makeDaemonsPolicy(namespace, ownerRef, spec.Network.NetworkPolicies, operatorNamespace)
+	if err != nil {
+		return err
+	}
+	if err := createOrUpdatePolicy(context, policy); err != nil {
+		return err
+	}
+	logger.Debugf("network policy %q reconciled", DaemonsPolicyName)
+	return nil
+}
+
+// ReconcileServicePolicy creates or updates the network policy allowing the connections of any client to the ports
+// of the service, when the network policies are enabled. The policy has the name and the owners of the service. The
+// services without selector, like the services of the external clusters, have no policy.
+func ReconcileServicePolicy(context *clusterd.Context, spec *cephv1.ClusterSpec, service *v1.Service) error {
+	if !spec.Network.AreNetworkPoliciesEnabled() || len(service.Spec.Selector) == 0 {
+		// the policies are deleted with the reconcile of the cluster
+		return nil
+	}
+	return createOrUpdatePolicy(context, makeServicePolicy(service))
+}
+
+// DeleteServicePolicy deletes the network policy of a service which is deleted
+func DeleteServicePolicy(context *clusterd.Context, namespace, name string) error {
+	return deletePolicy(context, namespace, name)
+}
+
+func deletePolicy(context *clusterd.Context, namespace, name string) error {
+	err := context.Clientset.NetworkingV1().NetworkPolicies(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete network policy %q", name)
+	}
+	return nil
+}
+
+func makeDaemonsPolicy(namespace string, ownerRef metav1.OwnerReference, spec *cephv1.NetworkPoliciesSpec, operatorNamespace string) (*networking.NetworkPolicy, error) {
+	// the daemons talk to each other and the clients in the namespace like the toolbox connect to them
+	peers := []networking.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	// the operator and the csi provisioners in the namespace of the operator connect to the daemons
+	namespaces := []string{}
+	if operatorNamespace != "" && operatorNamespace != namespace {
+		namespaces = append(namespaces, operatorNamespace)
+	}
+	for _, ns := range append(namespaces, spec.AllowedNamespaces...) {
+		peers = append(peers, networking.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: ns}},
+		})
+	}
+	for _, cidr := range spec.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, errors.Wrapf(err, "invalid allowed cidr %q of the network policies", cidr)
+		}
+		peers = append(peers, networking.NetworkPolicyPeer{IPBlock: &networking.IPBlock{CIDR: cidr}})
+	}
+
+	policy := newPolicy(DaemonsPolicyName, namespace)
+	// all the pods of the cluster have its label, the rules are on all the ports since the ports of the daemons are
+	// allocated by ceph
+	policy.Spec.PodSelector = metav1.LabelSelector{MatchLabels: map[string]string{k8sutil.ClusterAttr: namespace}}
+	policy.Spec.Ingress = []networking.NetworkPolicyIngressRule{{From: peers}}
+	k8sutil.SetOwnerRef(&policy.ObjectMeta, &ownerRef)
+	return policy, nil
+}
+
+func makeServicePolicy(service *v1.Service) *networking.NetworkPolicy {
+	ports := []networking.NetworkPolicyPort{}
+	for _, p := range service.Spec.Ports {
+		protocol := p.Protocol
+		if protocol == "" {
+			protocol = v1.ProtocolTCP
+		}
+		// the policy applies to the ports of the pods
+		port := p.TargetPort
+		if port.Type == intstr.Int && port.IntVal == 0 {
+			port = intstr.FromInt(int(p.Port))
+		}
+		ports = append(ports, networking.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+
+	policy := newPolicy(service.Name, service.Namespace)
+	policy.Spec.PodSelector = metav1.LabelSelector{MatchLabels: service.Spec.Selector}
+	policy.Spec.Ingress = []networking.NetworkPolicyIngressRule{{Ports: ports}}
+	policy.OwnerReferences = service.OwnerReferences
+	return policy
+}
+
+func newPolicy(name, namespace string) *networking.NetworkPolicy {
+	return &networking.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    controller.AppLabels(AppName, namespace),
+		},
+		Spec: networking.NetworkPolicySpec{
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeIngress},
+		},
+	}
+}
+
+func createOrUpdatePolicy(context *clusterd.Context, policy *networking.NetworkPolicy) error {
+	client := context.Clientset.NetworkingV1().NetworkPolicies(policy.Namespace)
+	existing, err := client.Get(policy.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get network policy %q", policy.Name)
+		}
+		if _, err := client.Create(policy); err != nil {
+			return errors.Wrapf(err, "failed to create network policy %q", policy.Name)
+		}
+		logger.Infof("network policy %q created", policy.Name)
+		return nil
+	}
+
+	policy.ResourceVersion = existing.ResourceVersion
+	if _, err := client.Update(policy); err != nil {
+		return errors.Wrapf(err, "failed to update network policy %q", policy.Name)
+	}
+	return nil
+}
+
+func deletePolicies(context *clusterd.Context, namespace string) error {
+	selector := metav1.ListOptions{LabelSelector: k8sutil.AppAttr + "=" + AppName}
+	policies, err := context.Clientset.NetworkingV1().NetworkPolicies(namespace).List(selector)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the network policies")
+	}
+	for _, policy := range policies.Items {
+		logger.Infof("deleting network policy %q since the network policies are disabled", policy.Name)
+		if err := deletePolicy(context, namespace, policy.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
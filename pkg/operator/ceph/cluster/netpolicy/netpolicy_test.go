@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpolicy
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestReconcileNetworkPolicies(t *testing.T) {
+	clientset := test.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	ownerRef := metav1.OwnerReference{Name: "my-cluster", UID: types.UID("cluster-uid")}
+	spec := &cephv1.ClusterSpec{}
+	spec.Network.NetworkPolicies = &cephv1.NetworkPoliciesSpec{
+		Enabled:           true,
+		AllowedNamespaces: []string{"monitoring"},
+		AllowedCIDRs:      []string{"10.0.0.0/24"},
+	}
+	getPolicy := func(name string) (*networking.NetworkPolicy, error) {
+		return clientset.NetworkingV1().NetworkPolicies("rook-ceph").Get(name, metav1.GetOptions{})
+	}
+
+	// the daemons accept the connections of the namespace, the operator and the allowed clients
+	assert.NoError(t, Reconcile(context, "rook-ceph", ownerRef, spec, "rook-operator"))
+	policy, err := getPolicy(DaemonsPolicyName)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"rook_cluster": "rook-ceph"}, policy.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, []networking.PolicyType{networking.PolicyTypeIngress}, policy.Spec.PolicyTypes)
+	assert.Equal(t, "cluster-uid", string(policy.OwnerReferences[0].UID))
+	peers := policy.Spec.Ingress[0].From
+	assert.Equal(t, 4, len(peers))
+	assert.NotNil(t, peers[0].PodSelector)
+	assert.Equal(t, "rook-operator", peers[1].NamespaceSelector.MatchLabels[namespaceNameLabel])
+	assert.Equal(t, "monitoring", peers[2].NamespaceSelector.MatchLabels[namespaceNameLabel])
+	assert.Equal(t, "10.0.0.0/24", peers[3].IPBlock.CIDR)
+	assert.Empty(t, policy.Spec.Ingress[0].Ports)
+
+	// the operator in the namespace of the cluster is allowed by the pod selector
+	assert.NoError(t, Reconcile(context, "rook-ceph", ownerRef, spec, "rook-ceph"))
+	policy, err = getPolicy(DaemonsPolicyName)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(policy.Spec.Ingress[0].From))
+
+	// any client connects to the ports of the services
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-rgw-my-store", Namespace: "rook-ceph"},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "rook-ceph-rgw", "rook_object_store": "my-store"},
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Name: "https", Port: 443},
+			},
+		},
+	}
+	assert.NoError(t, ReconcileServicePolicy(context, spec, service))
+	policy, err = getPolicy("rook-ceph-rgw-my-store")
+	assert.NoError(t, err)
+	assert.Equal(t, service.Spec.Selector, policy.Spec.PodSelector.MatchLabels)
+	assert.Empty(t, policy.Spec.Ingress[0].From)
+	ports := policy.Spec.Ingress[0].Ports
+	assert.Equal(t, 2, len(ports))
+	assert.Equal(t, 8080, ports[0].Port.IntValue())
+	assert.Equal(t, 443, ports[1].Port.IntValue())
+	assert.Equal(t, v1.ProtocolTCP, *ports[1].Protocol)
+
+	// an invalid cidr is refused
+	spec.Network.NetworkPolicies.AllowedCIDRs = []string{"10.0.0.1"}
+	assert.Error(t, Reconcile(context, "rook-ceph", ownerRef, spec, "rook-operator"))
+
+	// all the policies are deleted when disabled
+	spec.Network.NetworkPolicies.Enabled = false
+	assert.NoError(t, Reconcile(context, "rook-ceph", ownerRef, spec, "rook-operator"))
+	_, err = getPolicy(DaemonsPolicyName)
+	assert.True(t, kerrors.IsNotFound(err))
+	_, err = getPolicy("rook-ceph-rgw-my-store")
+	assert.True(t, kerrors.IsNotFound(err))
+
+	// no policies on the host network
+	spec.Network.NetworkPolicies.Enabled = true
+	spec.Network.NetworkPolicies.AllowedCIDRs = nil
+	spec.Network.Provider = "host"
+	assert.NoError(t, Reconcile(context, "rook-ceph", ownerRef, spec, "rook-operator"))
+	assert.NoError(t, ReconcileServicePolicy(context, spec, service))
+	policies, err := clientset.NetworkingV1().NetworkPolicies("rook-ceph").List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, policies.Items)
+}
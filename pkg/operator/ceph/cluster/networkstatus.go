@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// daemonAppPrefix is the prefix of the app label of the pods of the daemons, for example "rook-ceph-mon"
+	daemonAppPrefix = "rook-ceph-"
+)
+
+// networkStatusChecker reports the addresses of the daemons on the multus networks in the status of the cluster
+type networkStatusChecker struct {
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	selectors   map[string]string
+	interval    time.Duration
+}
+
+// newNetworkStatusChecker creates a checker of the addresses of the daemons on the multus networks
+func newNetworkStatusChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec) *networkStatusChecker {
+	c := &networkStatusChecker{
+		context:     context,
+		clusterInfo: clusterInfo,
+		selectors:   clusterSpec.Network.Selectors,
+		interval:    defaultStatusCheckInterval,
+	}
+	// the addresses are checked at the interval of the ceph status
+	if checkInterval := clusterSpec.HealthCheck.DaemonHealth.Status.Interval; checkInterval != "" {
+		if duration, err := time.ParseDuration(checkInterval); err == nil {
+			c.interval = duration
+		}
+	}
+	return c
+}
+
+// checkNetworkStatus periodically reports the addresses of the daemons
+func (c *networkStatusChecker) checkNetworkStatus(stopCh chan struct{}) {
+	// check immediately before starting the loop
+	c.check()
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping monitoring of the multus networks")
+			return
+
+		case <-time.After(c.interval):
+			c.check()
+		}
+	}
+}
+
+func (c *networkStatusChecker) check() {
+	status, err := c.networkStatus()
+	if err != nil {
+		logger.Warningf("failed to check the addresses of the daemons on the multus networks. %v", err)
+		return
+	}
+	if len(status.Detached) > 0 {
+		logger.Warningf("daemons %v are not attached to all the multus networks", status.Detached)
+	}
+	if err := c.updateStatus(status); err != nil {
+		logger.Errorf("failed to update the network status of cluster %q. %v", c.clusterInfo.Namespace, err)
+	}
+}
+
+// networkStatus returns the addresses of the running daemons on the public and cluster networks
+func (c *networkStatusChecker) networkStatus() (*cephv1.NetworkStatus, error) {
+	selector := fmt.Sprintf("%s=%s", k8sutil.ClusterAttr, c.clusterInfo.Namespace)
+	pods, err := c.context.Clientset.CoreV1().Pods(c.clusterInfo.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the pods of the daemons")
+	}
+
+	status := &cephv1.NetworkStatus{Daemons: []cephv1.DaemonNetworkStatus{}, LastChecked: formatTime(time.Now().UTC())}
+	for _, pod := range pods.Items {
+		daemon := daemonName(pod)
+		if daemon == "" || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		daemonStatus := cephv1.DaemonNetworkStatus{Daemon: daemon, Pod: pod.Name}
+		attached := true
+		for _, selectorKey := range config.NetworkSelectors {
+			networkSelector, ok := c.selectors[selectorKey]
+			if !ok {
+				continue
+			}
+			ips, err := k8sutil.GetMultusNetworkIPs(pod.ObjectMeta, networkSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get the network status of pod %q", pod.Name)
+			}
+			attached = attached && len(ips) > 0
+			if selectorKey == config.PublicNetworkSelectorKeyName {
+				daemonStatus.PublicIPs = ips
+			} else {
+				daemonStatus.ClusterIPs = ips
+			}
+		}
+		if !attached {
+			status.Detached = append(status.Detached, daemon)
+		}
+		status.Daemons = append(status.Daemons, daemonStatus)
+	}
+
+	sort.Slice(status.Daemons, func(i, j int) bool { return status.Daemons[i].Daemon < status.Daemons[j].Daemon })
+	sort.Strings(status.Detached)
+	return status, nil
+}
+
+// daemonName returns the name of the ceph daemon of a pod like "mon.a", it is empty for the pods which are not
+// daemons such as the jobs
+func daemonName(pod v1.Pod) string {
+	app := pod.Labels[k8sutil.AppAttr]
+	daemonID, ok := pod.Labels["ceph_daemon_id"]
+	if !ok || !strings.HasPrefix(app, daemonAppPrefix) {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", strings.TrimPrefix(app, daemonAppPrefix), daemonID)
+}
+
+func (c *networkStatusChecker) updateStatus(status *cephv1.NetworkStatus) error {
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.context.Client.Get(context.TODO(), c.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrap(err, "failed to get the cluster")
+	}
+	cephCluster.Status.Network = status
+	return opcontroller.UpdateStatus(c.context.Client, cephCluster)
+}
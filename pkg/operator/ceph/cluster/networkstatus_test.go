@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNetworkStatus(t *testing.T) {
+	clientset := test.New(t, 1)
+	newPod := func(name, app, daemonID, networkStatus string) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "rook-ceph",
+				Labels:      map[string]string{k8sutil.AppAttr: app, k8sutil.ClusterAttr: "rook-ceph"},
+				Annotations: map[string]string{k8sutil.MultusNetworkStatusAnnotation: networkStatus},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+		if daemonID != "" {
+			pod.Labels["ceph_daemon_id"] = daemonID
+		}
+		_, err := clientset.CoreV1().Pods("rook-ceph").Create(pod)
+		assert.NoError(t, err)
+		return pod
+	}
+	newPod("rook-ceph-osd-0-abc", "rook-ceph-osd", "0", `[{"name":"rook-ceph/public-net","ips":["192.168.20.10"]},
+		{"name":"rook-ceph/cluster-net","ips":["192.168.30.10"]}]`)
+	newPod("rook-ceph-mon-a-abc", "rook-ceph-mon", "a", `[{"name":"rook-ceph/public-net","ips":["192.168.20.5"]}]`)
+	// the jobs are not daemons
+	newPod("rook-ceph-osd-prepare-node1-abc", "rook-ceph-osd-prepare", "", `[]`)
+
+	spec := &cephv1.ClusterSpec{Network: cephv1.NetworkSpec{NetworkSpec: rookv1.NetworkSpec{
+		Provider:  "multus",
+		Selectors: map[string]string{"public": "public-net@net1", "cluster": "rook-ceph/cluster-net@net2"},
+	}}}
+	c := newNetworkStatusChecker(&clusterd.Context{Clientset: clientset}, cephclient.AdminClusterInfo("rook-ceph"), spec)
+	status, err := c.networkStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, []cephv1.DaemonNetworkStatus{
+		{Daemon: "mon.a", Pod: "rook-ceph-mon-a-abc", PublicIPs: []string{"192.168.20.5"}, ClusterIPs: []string{}},
+		{Daemon: "osd.0", Pod: "rook-ceph-osd-0-abc", PublicIPs: []string{"192.168.20.10"}, ClusterIPs: []string{"192.168.30.10"}},
+	}, status.Daemons)
+	// the mon is not attached to the cluster network
+	assert.Equal(t, []string{"mon.a"}, status.Detached)
+	assert.NotEqual(t, "", status.LastChecked)
+}
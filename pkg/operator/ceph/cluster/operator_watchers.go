@@ -43,6 +43,8 @@ func (c *ClusterController) StartOperatorSettingsWatch(namespace string, stopCh
 
 // StopWatch stop watchers
 func (c *ClusterController) StopWatch() {
+	c.clusterMapMutex.Lock()
+	defer c.clusterMapMutex.Unlock()
 	for _, cluster := range c.clusterMap {
 		close(cluster.stopCh)
 	}
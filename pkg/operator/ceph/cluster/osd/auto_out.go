@@ -0,0 +1,263 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// the config map of the osds marked out by the operator, which are marked in again once they are back up
+	autoOutStore             = "rook-ceph-osd-auto-out"
+	monDownOutIntervalOption = "mon_osd_down_out_interval"
+)
+
+var defaultAutoOutDownTimeout = 10 * time.Minute
+
+// osdAutoOut marks out the osds which stay down when their data can be recovered in the failure domains of the pools
+type osdAutoOut struct {
+	downTimeout time.Duration
+	kv          *k8sutil.ConfigMapKVStore
+	// the time each osd was first seen down by the operator
+	downSince map[int]time.Time
+	now       func() time.Time
+}
+
+func newOSDAutoOut(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec cephv1.OSDAutoOutSpec) *osdAutoOut {
+	if !spec.Enabled {
+		return nil
+	}
+	a := &osdAutoOut{
+		downTimeout: defaultAutoOutDownTimeout,
+		kv:          k8sutil.NewConfigMapKVStore(clusterInfo.Namespace, context.Clientset, clusterInfo.OwnerRef),
+		downSince:   map[int]time.Time{},
+		now:         time.Now,
+	}
+	if spec.DownTimeout != "" {
+		if duration, err := time.ParseDuration(spec.DownTimeout); err == nil {
+			a.downTimeout = duration
+		} else {
+			logger.Warningf("invalid osd auto out down timeout %q, using %s. %v", spec.DownTimeout, a.downTimeout, err)
+		}
+	}
+	return a
+}
+
+// ConfigureMonAutoOut disables marking out the down osds in the mons while the operator marks them out, and enables
+// it again when the operator stops
+func (m *OSDHealthMonitor) ConfigureMonAutoOut() error {
+	monStore := config.GetMonStore(m.context, m.clusterInfo)
+	if m.autoOut != nil {
+		return monStore.Set("mon", monDownOutIntervalOption, "0")
+	}
+	// only the interval disabled by the operator is removed
+	value, err := monStore.Get("mon", monDownOutIntervalOption)
+	if err != nil || strings.TrimSpace(value) != "0" {
+		return nil
+	}
+	return monStore.Delete("mon", monDownOutIntervalOption)
+}
+
+// autoOutOSDs marks out the osds down for longer than the down timeout, and marks in again the osds it marked out
+// once they are back up
+func (m *OSDHealthMonitor) autoOutOSDs(osdDump *client.OSDDump) error {
+	markedOut, err := m.autoOut.kv.GetStore(autoOutStore)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get the osds marked out")
+	}
+
+	now := m.autoOut.now()
+	candidates := []int{}
+	existing := map[int]bool{}
+	for _, osdStatus := range osdDump.OSDs {
+		id64, err := osdStatus.OSD.Int64()
+		if err != nil {
+			continue
+		}
+		id := int(id64)
+		existing[id] = true
+		status, in, err := osdDump.StatusByID(id64)
+		if err != nil {
+			return err
+		}
+
+		if status == upStatus {
+			delete(m.autoOut.downSince, id)
+			if _, ok := markedOut[strconv.Itoa(id)]; ok {
+				if err := m.markInOSD(id, in == inStatus); err != nil {
+					logger.Errorf("failed to mark in osd.%d. %v", id, err)
+				}
+			}
+			continue
+		}
+		if in != inStatus {
+			continue
+		}
+		since, ok := m.autoOut.downSince[id]
+		if !ok {
+			m.autoOut.downSince[id] = now
+			continue
+		}
+		if now.Sub(since) >= m.autoOut.downTimeout {
+			candidates = append(candidates, id)
+		}
+	}
+
+	// forget the osds which were removed
+	for key := range markedOut {
+		if id, err := strconv.Atoi(key); err == nil && !existing[id] {
+			if err := m.autoOut.kv.DeleteValue(autoOutStore, key); err != nil {
+				logger.Warningf("failed to forget removed osd.%d marked out. %v", id, err)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	if osdDump.IsFlagSet("noout") {
+		logger.Infof("noout flag is set, not marking out the osds %v down for more than %s", candidates, m.autoOut.downTimeout)
+		return nil
+	}
+	tree, err := client.HostTree(m.context, m.clusterInfo)
+	if err != nil {
+		return err
+	}
+	crushMap, err := client.GetCrushMap(m.context, m.clusterInfo)
+	if err != nil {
+		return err
+	}
+
+	// the osds marked out in this check are not counted in the failure domains of the next ones
+	markedNow := map[int]bool{}
+	for _, id := range candidates {
+		if reason := m.autoOutBlocked(id, osdDump, &tree, &crushMap, markedNow); reason != "" {
+			logger.Infof("not marking out osd.%d down for more than %s. %s", id, m.autoOut.downTimeout, reason)
+			continue
+		}
+
+		logger.Infof("marking out osd.%d down for more than %s", id, m.autoOut.downTimeout)
+		if _, err := client.OSDOut(m.context, m.clusterInfo, id); err != nil {
+			return errors.Wrapf(err, "failed to mark out osd.%d", id)
+		}
+		markedNow[id] = true
+		if err := m.autoOut.kv.SetValue(autoOutStore, strconv.Itoa(id), now.UTC().Format(time.RFC3339)); err != nil {
+			return errors.Wrapf(err, "failed to save osd.%d marked out", id)
+		}
+		opcontroller.RecordOwnerEvent(m.recorder, m.clusterInfo.Namespace, m.clusterInfo.OwnerRef, corev1.EventTypeWarning, opcontroller.EventReasonOSDMarkedOut,
+			"osd.%d was down for more than %s and was marked out", id, m.autoOut.downTimeout)
+	}
+	return nil
+}
+
+// markInOSD marks in again an osd marked out by the operator once it is back up
+func (m *OSDHealthMonitor) markInOSD(id int, alreadyIn bool) error {
+	if !alreadyIn {
+		logger.Infof("marking in osd.%d which is back up", id)
+		if err := client.OSDIn(m.context, m.clusterInfo, id); err != nil {
+			return err
+		}
+		opcontroller.RecordOwnerEvent(m.recorder, m.clusterInfo.Namespace, m.clusterInfo.OwnerRef, corev1.EventTypeNormal, opcontroller.EventReasonOSDMarkedIn,
+			"osd.%d is back up and was marked in", id)
+	}
+	return m.autoOut.kv.DeleteValue(autoOutStore, strconv.Itoa(id))
+}
+
+// autoOutBlocked returns why a down osd must not be marked out, or an empty string when it can be marked out
+func (m *OSDHealthMonitor) autoOutBlocked(id int, osdDump *client.OSDDump, tree *client.OsdTree, crushMap *client.CrushMap, markedNow map[int]bool) string {
+	parents := map[int]int{}
+	names := map[int]string{}
+	types := map[int]string{}
+	for _, node := range tree.Nodes {
+		names[node.ID] = node.Name
+		types[node.ID] = node.Type
+		for _, child := range node.Children {
+			parents[child] = node.ID
+		}
+	}
+
+	// the flag is set on the failure domains being drained or in maintenance
+	for node, ok := parents[id]; ok; node, ok = parents[node] {
+		if osdDump.IsFlagSetOnCrushUnit("noout", names[node]) {
+			return fmt.Sprintf("noout flag is set on %q", names[node])
+		}
+	}
+
+	// the osds stopped on purpose are not marked out
+	deployments, err := k8sutil.GetDeployments(m.context.Clientset, m.clusterInfo.Namespace, fmt.Sprintf("%s=%d", OsdIdLabelKey, id))
+	if err == nil && len(deployments.Items) > 0 {
+		replicas := deployments.Items[0].Spec.Replicas
+		if replicas != nil && *replicas == 0 {
+			return fmt.Sprintf("deployment %q is stopped", deployments.Items[0].Name)
+		}
+	}
+
+	// the data of the osd is only recovered if every pool still has enough failure domains with osds up and in
+	for _, pool := range osdDump.Pools {
+		failureDomain := ruleFailureDomain(crushMap, pool.CrushRule)
+		domains := map[string]bool{}
+		for _, node := range tree.Nodes {
+			if node.ID < 0 || node.ID == id || markedNow[node.ID] || node.Status != "up" || node.Reweight == 0 {
+				continue
+			}
+			domain := node.ID
+			for types[domain] != failureDomain {
+				parent, ok := parents[domain]
+				if !ok {
+					break
+				}
+				domain = parent
+			}
+			if types[domain] == failureDomain {
+				domains[names[domain]] = true
+			}
+		}
+		if len(domains) < pool.Size {
+			return fmt.Sprintf("only %d %s failure domains would be left for the %d replicas of pool %q", len(domains), failureDomain, pool.Size, pool.Name)
+		}
+	}
+	return ""
+}
+
+// ruleFailureDomain returns the type of the buckets a crush rule chooses, which is the failure domain of its pools
+func ruleFailureDomain(crushMap *client.CrushMap, ruleID int) string {
+	failureDomain := cephv1.DefaultFailureDomain
+	for _, rule := range crushMap.Rules {
+		if rule.ID != ruleID {
+			continue
+		}
+		for _, step := range rule.Steps {
+			if strings.HasPrefix(step.Operation, "choose") && step.Type != "" {
+				failureDomain = step.Type
+			}
+		}
+	}
+	return failureDomain
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	testexec "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+const autoOutTree = `{"nodes": [
+	{"id": -1, "name": "default", "type": "root", "children": [-2, -3, -4]},
+	{"id": -2, "name": "host1", "type": "host", "children": [0, 3]},
+	{"id": -3, "name": "host2", "type": "host", "children": [1]},
+	{"id": -4, "name": "host3", "type": "host", "children": [2]},
+	{"id": 0, "name": "osd.0", "type": "osd", "status": "down", "reweight": 1},
+	{"id": 1, "name": "osd.1", "type": "osd", "status": "up", "reweight": 1},
+	{"id": 2, "name": "osd.2", "type": "osd", "status": "up", "reweight": 1},
+	{"id": 3, "name": "osd.3", "type": "osd", "status": "%s", "reweight": 1}]}`
+
+func TestAutoOutOSDs(t *testing.T) {
+	osd0Up, osd0In, osd3Status := 0, 1, "up"
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			switch {
+			case args[0] == "osd" && args[1] == "dump":
+				return fmt.Sprintf(`{"osds": [{"osd": 0, "up": %d, "in": %d}, {"osd": 1, "up": 1, "in": 1}],
+					"pools": [{"pool": 1, "pool_name": "replicapool", "size": 3, "crush_rule": 1}]}`, osd0Up, osd0In), nil
+			case args[0] == "osd" && args[1] == "tree":
+				return fmt.Sprintf(autoOutTree, osd3Status), nil
+			case args[0] == "osd" && args[1] == "crush" && args[2] == "dump":
+				return `{"rules": [{"rule_id": 1, "steps": [{"op": "take", "item_name": "default"}, {"op": "chooseleaf_firstn", "type": "host"}]}]}`, nil
+			case args[0] == "osd" && (args[1] == "out" || args[1] == "in"):
+				commands = append(commands, strings.Join(args[:3], " "))
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor, Clientset: testexec.New(t, 1)}
+	clusterInfo := client.AdminClusterInfo("ns")
+	healthCheck := cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{
+		ObjectStorageDaemon: cephv1.OSDHealthCheckSpec{AutoOut: cephv1.OSDAutoOutSpec{Enabled: true, DownTimeout: "5m"}},
+	}}
+	m := NewOSDHealthMonitor(context, clusterInfo, false, healthCheck)
+	m.SetEventRecorder(record.NewFakeRecorder(10))
+	assert.Equal(t, 5*time.Minute, m.autoOut.downTimeout)
+	now := time.Now()
+	m.autoOut.now = func() time.Time { return now }
+
+	// the osd is not marked out before the timeout
+	assert.NoError(t, m.checkOSDDump())
+	now = now.Add(4 * time.Minute)
+	assert.NoError(t, m.checkOSDDump())
+	assert.Equal(t, 0, len(commands))
+
+	// the osd is not marked out when its pool would not have enough hosts left
+	osd3Status = "down"
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, m.checkOSDDump())
+	assert.Equal(t, 0, len(commands))
+
+	// the osd is marked out when its host still has an osd
+	osd3Status = "up"
+	assert.NoError(t, m.checkOSDDump())
+	assert.Equal(t, []string{"osd out 0"}, commands)
+	value, err := m.autoOut.kv.GetValue(autoOutStore, "0")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, value)
+
+	// the osd is marked in again once it is back up
+	osd0In = 0
+	assert.NoError(t, m.checkOSDDump())
+	osd0Up = 1
+	assert.NoError(t, m.checkOSDDump())
+	assert.Equal(t, []string{"osd out 0", "osd in 0"}, commands)
+	_, err = m.autoOut.kv.GetValue(autoOutStore, "0")
+	assert.Error(t, err)
+
+	// the failure domain of the pools is the type chosen by their crush rule
+	crushMap := &client.CrushMap{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"rules": [{"rule_id": 2, "steps": [{"op": "take", "item_name": "default"}, {"op": "chooseleaf_firstn", "type": "rack"}]}]}`), crushMap))
+	assert.Equal(t, "rack", ruleFailureDomain(crushMap, 2))
+	assert.Equal(t, "host", ruleFailureDomain(crushMap, 3))
+}
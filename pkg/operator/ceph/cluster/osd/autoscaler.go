@@ -0,0 +1,360 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/display"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the config map keeping the resource requests applied to each osd so they survive the orchestration
+	resourceRecommendationsStore = "rook-ceph-osd-resource-recommendations"
+	podMetricsPathFmt            = "/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods"
+	osdContainerName             = "osd"
+	defaultAutoscalingHeadroom   = 20
+	defaultAutoscalingTolerance  = 10
+	defaultAutoscalingBatchSize  = 1
+)
+
+var (
+	defaultAutoscalingInterval = 10 * time.Minute
+	autoscaledResources        = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+)
+
+// podMetrics is the subset of the metrics.k8s.io PodMetrics used by the autoscaler
+type podMetrics struct {
+	Metadata   metav1.ObjectMeta `json:"metadata"`
+	Containers []struct {
+		Name  string          `json:"name"`
+		Usage v1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+type podMetricsList struct {
+	Items []podMetrics `json:"items"`
+}
+
+// OSDResourceAutoscaler adjusts the resource requests of the OSDs to their observed usage
+type OSDResourceAutoscaler struct {
+	context     *clusterd.Context
+	clusterInfo *client.ClusterInfo
+	spec        cephv1.DaemonResourceAutoscalingSpec
+	interval    time.Duration
+	kv          *k8sutil.ConfigMapKVStore
+	// getUsage returns the usage of the osd container of each osd pod, by osd id
+	getUsage func() (map[int]v1.ResourceList, error)
+}
+
+// NewOSDResourceAutoscaler instantiates the OSD resource autoscaling
+func NewOSDResourceAutoscaler(context *clusterd.Context, clusterInfo *client.ClusterInfo, spec cephv1.DaemonResourceAutoscalingSpec) *OSDResourceAutoscaler {
+	a := &OSDResourceAutoscaler{
+		context:     context,
+		clusterInfo: clusterInfo,
+		spec:        spec,
+		interval:    defaultAutoscalingInterval,
+		kv:          k8sutil.NewConfigMapKVStore(clusterInfo.Namespace, context.Clientset, clusterInfo.OwnerRef),
+	}
+	a.getUsage = a.getOSDUsage
+
+	// allow overriding the interval
+	if spec.Interval != "" {
+		if duration, err := time.ParseDuration(spec.Interval); err == nil {
+			logger.Infof("osd resource autoscaling in namespace %q interval %q", clusterInfo.Namespace, spec.Interval)
+			a.interval = duration
+		}
+	}
+	if a.spec.Headroom <= 0 {
+		a.spec.Headroom = defaultAutoscalingHeadroom
+	}
+	if a.spec.Tolerance <= 0 {
+		a.spec.Tolerance = defaultAutoscalingTolerance
+	}
+	if a.spec.BatchSize <= 0 {
+		a.spec.BatchSize = defaultAutoscalingBatchSize
+	}
+
+	return a
+}
+
+// Start adjusts the osd resources at set intervals
+func (a *OSDResourceAutoscaler) Start(stopCh chan struct{}) {
+	for {
+		select {
+		case <-time.After(a.interval):
+			logger.Debug("checking osd resource usage.")
+			if err := a.autoscale(); err != nil {
+				logger.Warningf("failed to adjust osd resources. %v", err)
+			}
+
+		case <-stopCh:
+			logger.Infof("stopping osd resource autoscaling in namespace %s", a.clusterInfo.Namespace)
+			return
+		}
+	}
+}
+
+// autoscale updates the requests of the next batch of osds whose usage has drifted from their requests.
+// The batch is only updated when all its osds are ok to stop, and only one batch is updated at a time
+// so the pgs are clean again before the next batch.
+func (a *OSDResourceAutoscaler) autoscale() error {
+	usage, err := a.getUsage()
+	if err != nil {
+		return errors.Wrap(err, "failed to get osd resource usage")
+	}
+
+	ids := []int{}
+	for id := range usage {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	batch := []int{}
+	recommendations := map[int]v1.ResourceRequirements{}
+	for _, id := range ids {
+		deployment, err := a.context.Clientset.AppsV1().Deployments(a.clusterInfo.Namespace).Get(fmt.Sprintf(osdAppNameFmt, id), metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get osd.%d deployment", id)
+		}
+		container := getOSDContainer(deployment.Spec.Template.Spec.Containers)
+		if container == nil {
+			continue
+		}
+		recommended, changed := recommendResources(container.Resources, usage[id], a.spec)
+		if !changed {
+			continue
+		}
+		recommendations[id] = recommended
+		batch = append(batch, id)
+		if len(batch) == a.spec.BatchSize {
+			break
+		}
+	}
+	if len(batch) == 0 {
+		logger.Debug("osd resource requests match the usage")
+		return nil
+	}
+
+	if err := client.OSDOkToStop(a.context, a.clusterInfo, batch); err != nil {
+		logger.Infof("not adjusting the resources of osds %v. %v", batch, err)
+		return nil
+	}
+	for _, id := range batch {
+		if err := a.updateOSDResources(id, recommendations[id]); err != nil {
+			return errors.Wrapf(err, "failed to update osd.%d resources", id)
+		}
+	}
+	return nil
+}
+
+func (a *OSDResourceAutoscaler) updateOSDResources(id int, resources v1.ResourceRequirements) error {
+	// save the requests first so the orchestration keeps them when it updates the deployment
+	requests, err := json.Marshal(resources.Requests)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal resource requests")
+	}
+	if err := a.kv.SetValue(resourceRecommendationsStore, strconv.Itoa(id), string(requests)); err != nil {
+		return errors.Wrap(err, "failed to save resource requests")
+	}
+
+	deployment, err := a.context.Clientset.AppsV1().Deployments(a.clusterInfo.Namespace).Get(fmt.Sprintf(osdAppNameFmt, id), metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get deployment")
+	}
+	getOSDContainer(deployment.Spec.Template.Spec.Containers).Resources = resources
+	logger.Infof("updating osd.%d resource requests to %v", id, resources.Requests)
+
+	callback := func(action string) error {
+		// the batch was checked to be ok to stop
+		if action == "continue" {
+			return client.OkToContinue(a.context, a.clusterInfo, deployment.Name, "osd", strconv.Itoa(id))
+		}
+		return nil
+	}
+	_, err = k8sutil.UpdateDeploymentAndWait(a.context, deployment, a.clusterInfo.Namespace, callback)
+	return err
+}
+
+// getOSDUsage returns the usage of the running osd pods reported by the metrics API
+func (a *OSDResourceAutoscaler) getOSDUsage() (map[int]v1.ResourceList, error) {
+	selector := fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)
+	pods, err := a.context.Clientset.CoreV1().Pods(a.clusterInfo.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list osd pods")
+	}
+	buf, err := a.context.Clientset.Discovery().RESTClient().Get().
+		AbsPath(fmt.Sprintf(podMetricsPathFmt, a.clusterInfo.Namespace)).
+		Param("labelSelector", selector).
+		DoRaw()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get osd pod metrics, is the metrics server running?")
+	}
+	var metrics podMetricsList
+	if err := json.Unmarshal(buf, &metrics); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal pod metrics")
+	}
+	return osdUsage(pods.Items, metrics.Items), nil
+}
+
+// osdUsage matches the metrics of the osd container with the osd id of the running pods
+func osdUsage(pods []v1.Pod, metrics []podMetrics) map[int]v1.ResourceList {
+	ids := map[string]int{}
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		id, err := strconv.Atoi(pod.Labels[OsdIdLabelKey])
+		if err != nil {
+			continue
+		}
+		ids[pod.Name] = id
+	}
+
+	usage := map[int]v1.ResourceList{}
+	for _, m := range metrics {
+		id, ok := ids[m.Metadata.Name]
+		if !ok {
+			continue
+		}
+		for _, container := range m.Containers {
+			if container.Name == osdContainerName {
+				usage[id] = container.Usage
+			}
+		}
+	}
+	return usage
+}
+
+// recommendResources computes the requests from the usage plus the headroom, within the bounds of the spec
+// and the limits. The requests are only changed when they differ from the computed ones by more than the tolerance.
+func recommendResources(current v1.ResourceRequirements, usage v1.ResourceList, spec cephv1.DaemonResourceAutoscalingSpec) (v1.ResourceRequirements, bool) {
+	recommended := *current.DeepCopy()
+	if recommended.Requests == nil {
+		recommended.Requests = v1.ResourceList{}
+	}
+
+	changed := false
+	for _, name := range autoscaledResources {
+		used, ok := usage[name]
+		if !ok {
+			continue
+		}
+		target := scaleQuantity(name, used, int64(100+spec.Headroom))
+		if min, ok := spec.MinAllowed[name]; ok && target.Cmp(min) < 0 {
+			target = min.DeepCopy()
+		}
+		if max, ok := spec.MaxAllowed[name]; ok && target.Cmp(max) > 0 {
+			target = max.DeepCopy()
+		}
+		if name == v1.ResourceMemory {
+			minimum := resource.NewQuantity(int64(display.MbTob(cephOsdPodMinimumMemory)), resource.BinarySI)
+			if target.Cmp(*minimum) < 0 {
+				target = *minimum
+			}
+		}
+		if limit, ok := current.Limits[name]; ok && target.Cmp(limit) > 0 {
+			target = limit.DeepCopy()
+		}
+
+		if request, ok := current.Requests[name]; ok && !exceedsTolerance(request, target, spec.Tolerance) {
+			continue
+		}
+		recommended.Requests[name] = target
+		changed = true
+	}
+	return recommended, changed
+}
+
+// scaleQuantity returns the quantity scaled by the percent, with the memory rounded up to the next MiB
+func scaleQuantity(name v1.ResourceName, q resource.Quantity, percent int64) resource.Quantity {
+	if name == v1.ResourceCPU {
+		return *resource.NewMilliQuantity(q.MilliValue()*percent/100, resource.DecimalSI)
+	}
+	mib := int64(1024 * 1024)
+	value := (q.Value()*percent/100 + mib - 1) / mib * mib
+	return *resource.NewQuantity(value, resource.BinarySI)
+}
+
+func exceedsTolerance(current, target resource.Quantity, tolerance int) bool {
+	diff := target.MilliValue() - current.MilliValue()
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*100 > int64(tolerance)*current.MilliValue()
+}
+
+func getOSDContainer(containers []v1.Container) *v1.Container {
+	for i := range containers {
+		if containers[i].Name == osdContainerName {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// applyResourceRecommendation returns the resources with the requests computed by the autoscaler for the osd
+func (c *Cluster) applyResourceRecommendation(osdID int, resources v1.ResourceRequirements) v1.ResourceRequirements {
+	if !c.spec.ResourceAutoscaling.OSD.Enabled {
+		return resources
+	}
+	value, err := c.kv.GetValue(resourceRecommendationsStore, strconv.Itoa(osdID))
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Warningf("failed to get the resource requests of osd.%d. %v", osdID, err)
+		}
+		return resources
+	}
+	var requests v1.ResourceList
+	if err := json.Unmarshal([]byte(value), &requests); err != nil {
+		logger.Warningf("failed to unmarshal the resource requests of osd.%d. %v", osdID, err)
+		return resources
+	}
+
+	result := *resources.DeepCopy()
+	if result.Requests == nil {
+		result.Requests = v1.ResourceList{}
+	}
+	for _, name := range autoscaledResources {
+		request, ok := requests[name]
+		if !ok {
+			continue
+		}
+		// the limits may have been lowered since the requests were computed
+		if limit, ok := result.Limits[name]; ok && request.Cmp(limit) > 0 {
+			request = limit
+		}
+		result.Requests[name] = request
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	testexec "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecommendResources(t *testing.T) {
+	spec := cephv1.DaemonResourceAutoscalingSpec{Headroom: 20, Tolerance: 10}
+	current := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		},
+	}
+
+	// the usage plus the headroom is within the tolerance
+	usage := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("850m"),
+		v1.ResourceMemory: resource.MustParse("3500Mi"),
+	}
+	_, changed := recommendResources(current, usage, spec)
+	assert.False(t, changed)
+
+	// the cpu usage increased
+	usage[v1.ResourceCPU] = resource.MustParse("1500m")
+	recommended, changed := recommendResources(current, usage, spec)
+	assert.True(t, changed)
+	assert.Equal(t, "1800m", recommended.Requests.Cpu().String())
+	assert.Equal(t, "4Gi", recommended.Requests.Memory().String())
+	// the current requests are not modified
+	assert.Equal(t, "1", current.Requests.Cpu().String())
+
+	// the requests are bounded by the spec and the limits
+	spec.MaxAllowed = v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m")}
+	recommended, _ = recommendResources(current, usage, spec)
+	assert.Equal(t, "1500m", recommended.Requests.Cpu().String())
+	current.Limits = v1.ResourceList{v1.ResourceCPU: resource.MustParse("1200m")}
+	recommended, _ = recommendResources(current, usage, spec)
+	assert.Equal(t, "1200m", recommended.Requests.Cpu().String())
+
+	// the memory is never below the osd minimum
+	usage[v1.ResourceMemory] = resource.MustParse("500Mi")
+	recommended, changed = recommendResources(v1.ResourceRequirements{}, usage, spec)
+	assert.True(t, changed)
+	assert.Equal(t, "2Gi", recommended.Requests.Memory().String())
+}
+
+func TestOSDUsage(t *testing.T) {
+	newPod := func(name, id string, phase v1.PodPhase) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{OsdIdLabelKey: id}},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []v1.Pod{
+		newPod("rook-ceph-osd-0-abc", "0", v1.PodRunning),
+		newPod("rook-ceph-osd-1-abc", "1", v1.PodPending),
+	}
+
+	metrics := podMetricsList{}
+	err := json.Unmarshal([]byte(`{"items": [
+		{"metadata": {"name": "rook-ceph-osd-0-abc"}, "containers": [{"name": "osd", "usage": {"cpu": "250m", "memory": "3Gi"}}, {"name": "log-collector", "usage": {"cpu": "1m"}}]},
+		{"metadata": {"name": "rook-ceph-osd-1-abc"}, "containers": [{"name": "osd", "usage": {"cpu": "1"}}]},
+		{"metadata": {"name": "other"}, "containers": [{"name": "osd", "usage": {"cpu": "1"}}]}]}`), &metrics)
+	assert.NoError(t, err)
+
+	usage := osdUsage(pods, metrics.Items)
+	assert.Equal(t, 1, len(usage))
+	osd0 := usage[0]
+	assert.Equal(t, "250m", osd0.Cpu().String())
+	assert.Equal(t, "3Gi", osd0.Memory().String())
+}
+
+func TestAutoscaleNotOkToStop(t *testing.T) {
+	clientset := testexec.New(t, 1)
+	clusterInfo := client.AdminClusterInfo("ns")
+	okToStop := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outFileArg string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "ok-to-stop" {
+				okToStop = append(okToStop, args[2:4]...)
+				return "", errors.New("not ok to stop")
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor, Clientset: clientset}
+
+	for _, id := range []string{"0", "1"} {
+		d := &apps.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-osd-" + id, Namespace: "ns"},
+			Spec: apps.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "osd"}},
+			}}},
+		}
+		_, err := clientset.AppsV1().Deployments("ns").Create(d)
+		assert.NoError(t, err)
+	}
+
+	a := NewOSDResourceAutoscaler(context, clusterInfo, cephv1.DaemonResourceAutoscalingSpec{Enabled: true, BatchSize: 2})
+	a.getUsage = func() (map[int]v1.ResourceList, error) {
+		return map[int]v1.ResourceList{
+			0: {v1.ResourceCPU: resource.MustParse("1")},
+			1: {v1.ResourceCPU: resource.MustParse("2")},
+		}, nil
+	}
+	assert.NoError(t, a.autoscale())
+	// the whole batch is checked at once and nothing is updated
+	assert.Equal(t, []string{"0", "1"}, okToStop)
+	_, err := a.kv.GetStore(resourceRecommendationsStore)
+	assert.Error(t, err)
+	d, err := clientset.AppsV1().Deployments("ns").Get("rook-ceph-osd-0", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().IsZero())
+}
+
+func TestApplyResourceRecommendation(t *testing.T) {
+	clientset := testexec.New(t, 1)
+	clusterInfo := client.AdminClusterInfo("ns")
+	context := &clusterd.Context{Clientset: clientset}
+	c := New(context, clusterInfo, cephv1.ClusterSpec{}, "myversion")
+	resources := v1.ResourceRequirements{
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+	}
+
+	assert.NoError(t, c.kv.SetValue(resourceRecommendationsStore, "0", `{"cpu":"3","memory":"5Gi"}`))
+	// the recommendation is ignored when the autoscaling is disabled
+	assert.Equal(t, resources, c.applyResourceRecommendation(0, resources))
+
+	c.spec.ResourceAutoscaling.OSD.Enabled = true
+	result := c.applyResourceRecommendation(0, resources)
+	assert.Equal(t, "2", result.Requests.Cpu().String())
+	assert.Equal(t, "5Gi", result.Requests.Memory().String())
+	assert.Equal(t, "1", resources.Requests.Cpu().String())
+
+	// no recommendation for the osd yet
+	assert.Equal(t, resources, c.applyResourceRecommendation(1, resources))
+}
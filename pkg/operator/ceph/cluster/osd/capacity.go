@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	defaultReweightInterval  = 24 * time.Hour
+	defaultReweightThreshold = 120
+	defaultReweightMaxChange = 0.05
+	defaultReweightMaxOSDs   = 4
+	// fullRatioTolerance is the difference ignored between the ratios of the spec and the ratios of the cluster, which
+	// ceph stores as float32
+	fullRatioTolerance = 0.0001
+)
+
+// the names of the ratios of the osds in the ceph commands
+var fullRatioNames = []string{"nearfull", "backfillfull", "full"}
+
+// fullRatios returns the nearfull, backfillfull and full ratios of the spec, with the ratios of the cluster for the
+// ratios not set. The ratios must be increasing for the osds to stop the backfills before the writes.
+func fullRatios(spec cephv1.CapacitySpec, dump *cephclient.OSDDump) ([]float64, error) {
+	ratios := []float64{dump.NearFullRatio, dump.BackfillFullRatio, dump.FullRatio}
+	for i, ratio := range []*float64{spec.NearFullRatio, spec.BackfillFullRatio, spec.FullRatio} {
+		if ratio == nil {
+			continue
+		}
+		if *ratio <= 0 || *ratio > 1 {
+			return nil, errors.Errorf("invalid %s ratio %v, expected a ratio between 0 and 1", fullRatioNames[i], *ratio)
+		}
+		ratios[i] = *ratio
+	}
+	if ratios[0] > ratios[1] || ratios[1] > ratios[2] {
+		return nil, errors.Errorf("the nearfull ratio %v, the backfillfull ratio %v and the full ratio %v must be increasing", ratios[0], ratios[1], ratios[2])
+	}
+	return ratios, nil
+}
+
+// applyFullRatios sets the full ratios of the spec which differ from the ratios of the cluster. The ratios removed
+// from the spec keep their last value.
+func (c *Cluster) applyFullRatios() error {
+	spec := c.spec.Capacity
+	if spec.NearFullRatio == nil && spec.BackfillFullRatio == nil && spec.FullRatio == nil {
+		return nil
+	}
+	dump, err := cephclient.GetOSDDump(c.context, c.clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the full ratios of the osds")
+	}
+	ratios, err := fullRatios(spec, dump)
+	if err != nil {
+		return err
+	}
+	current := []float64{dump.NearFullRatio, dump.BackfillFullRatio, dump.FullRatio}
+	for i, name := range fullRatioNames {
+		if math.Abs(ratios[i]-current[i]) < fullRatioTolerance {
+			continue
+		}
+		if err := cephclient.SetOSDFullRatio(c.context, c.clusterInfo, name, ratios[i]); err != nil {
+			return err
+		}
+		logger.Infof("%s ratio of the osds set to %v", name, ratios[i])
+	}
+	return nil
+}
+
+// OSDReweighter periodically lowers the weight of the osds used above the average utilization of the osds
+type OSDReweighter struct {
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	spec        cephv1.ReweightByUtilizationSpec
+	interval    time.Duration
+	recorder    record.EventRecorder
+}
+
+// NewOSDReweighter instantiates the reweight of the osds by utilization. The settings out of their bounds are
+// replaced with their defaults. The reweighter is started again when the settings change.
+func NewOSDReweighter(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec cephv1.ReweightByUtilizationSpec, recorder record.EventRecorder) *OSDReweighter {
+	r := &OSDReweighter{
+		context:     context,
+		clusterInfo: clusterInfo,
+		spec:        spec,
+		interval:    defaultReweightInterval,
+		recorder:    recorder,
+	}
+
+	// allow overriding the interval
+	if spec.Interval != "" {
+		if duration, err := time.ParseDuration(spec.Interval); err == nil && duration > 0 {
+			logger.Infof("osd reweight in namespace %q interval %q", clusterInfo.Namespace, spec.Interval)
+			r.interval = duration
+		} else {
+			logger.Warningf("invalid osd reweight interval %q, using the default interval", spec.Interval)
+		}
+	}
+	if r.spec.Threshold == 0 {
+		r.spec.Threshold = defaultReweightThreshold
+	} else if r.spec.Threshold < 110 || r.spec.Threshold > 200 {
+		logger.Warningf("osd reweight threshold %d is not between 110 and 200, using %d", r.spec.Threshold, defaultReweightThreshold)
+		r.spec.Threshold = defaultReweightThreshold
+	}
+	if r.spec.MaxChange == 0 {
+		r.spec.MaxChange = defaultReweightMaxChange
+	} else if r.spec.MaxChange < 0.01 || r.spec.MaxChange > 0.1 {
+		logger.Warningf("osd reweight max change %v is not between 0.01 and 0.1, using %v", r.spec.MaxChange, defaultReweightMaxChange)
+		r.spec.MaxChange = defaultReweightMaxChange
+	}
+	if r.spec.MaxOSDs == 0 {
+		r.spec.MaxOSDs = defaultReweightMaxOSDs
+	} else if r.spec.MaxOSDs < 1 || r.spec.MaxOSDs > 10 {
+		logger.Warningf("osd reweight max osds %d is not between 1 and 10, using %d", r.spec.MaxOSDs, defaultReweightMaxOSDs)
+		r.spec.MaxOSDs = defaultReweightMaxOSDs
+	}
+
+	return r
+}
+
+// Start reweights the osds at set intervals
+func (r *OSDReweighter) Start(stopCh chan struct{}) {
+	for {
+		select {
+		case <-time.After(r.interval):
+			logger.Debug("checking the utilization of the osds.")
+			if err := r.reweight(); err != nil {
+				logger.Warningf("failed to reweight the osds. %v", err)
+			}
+
+		case <-stopCh:
+			logger.Infof("stopping osd reweight in namespace %s", r.clusterInfo.Namespace)
+			return
+		}
+	}
+}
+
+// reweight reweights the osds by utilization when the pgs are clean, so that the utilization reflects the placement
+// of the pgs and the previous reweight completed, and reports the changed weights with an event
+func (r *OSDReweighter) reweight() error {
+	msg, clean, err := cephclient.IsClusterClean(r.context, r.clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to check if the pgs are clean")
+	}
+	if !clean {
+		logger.Infof("skipping the reweight of the osds, the pgs are not clean. %s", msg)
+		return nil
+	}
+
+	reweights, err := cephclient.ReweightOSDsByUtilization(r.context, r.clusterInfo, r.spec.Threshold, r.spec.MaxChange, r.spec.MaxOSDs)
+	if err != nil {
+		return err
+	}
+	if len(reweights.Reweights) == 0 {
+		logger.Debugf("no osd above %d%% of the average utilization", r.spec.Threshold)
+		return nil
+	}
+	changes := []string{}
+	for _, reweight := range reweights.Reweights {
+		changes = append(changes, fmt.Sprintf("osd.%d %.4f -> %.4f", reweight.OSD, reweight.Weight, reweight.NewWeight))
+	}
+	logger.Infof("reweighted the osds by utilization: %s", strings.Join(changes, ", "))
+	opcontroller.RecordOwnerEvent(r.recorder, r.clusterInfo.Namespace, r.clusterInfo.OwnerRef, corev1.EventTypeNormal, opcontroller.EventReasonOSDsReweighted,
+		"reweighted the osds above %.1f%% utilization, %d%% of the average utilization %.1f%%: %s",
+		reweights.OverloadUtilization*100, r.spec.Threshold, reweights.AverageUtilization*100, strings.Join(changes, ", "))
+	return nil
+}
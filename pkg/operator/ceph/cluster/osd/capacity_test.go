@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestApplyFullRatios(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "dump" {
+				return `{"full_ratio":0.949999988079071,"backfillfull_ratio":0.8999999761581421,"nearfull_ratio":0.8500000238418579}`, nil
+			}
+			commands = append(commands, strings.Join(args[:3], " "))
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Clientset: fake.NewSimpleClientset(), Executor: executor}
+	c := New(context, &cephclient.ClusterInfo{Namespace: "ns"}, cephv1.ClusterSpec{}, "rook/rook:myversion")
+
+	// nothing is set without ratios
+	assert.NoError(t, c.applyFullRatios())
+	assert.Empty(t, commands)
+
+	// only the changed ratios are set
+	nearFull, full := 0.8, 0.95
+	c.spec.Capacity = cephv1.CapacitySpec{NearFullRatio: &nearFull, FullRatio: &full}
+	assert.NoError(t, c.applyFullRatios())
+	assert.Equal(t, []string{"osd set-nearfull-ratio 0.8"}, commands)
+
+	// the ratios must be increasing, with the ratios of the cluster
+	commands = []string{}
+	nearFull = 0.92
+	assert.Error(t, c.applyFullRatios())
+	nearFull = 1.2
+	assert.Error(t, c.applyFullRatios())
+	backfillFull := 0.93
+	nearFull = 0.92
+	c.spec.Capacity.BackfillFullRatio = &backfillFull
+	assert.NoError(t, c.applyFullRatios())
+	assert.Equal(t, []string{"osd set-nearfull-ratio 0.92", "osd set-backfillfull-ratio 0.93"}, commands)
+
+	// the ratios of the cluster stored as float32 are not set again
+	commands = []string{}
+	nearFull, backfillFull = 0.85, 0.9
+	assert.NoError(t, c.applyFullRatios())
+	assert.Empty(t, commands)
+}
+
+func TestReweightByUtilization(t *testing.T) {
+	clean := true
+	reweights := ""
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "status" {
+				if clean {
+					return `{"pgmap":{"num_pgs":8,"pgs_by_state":[{"state_name":"active+clean","count":8}]}}`, nil
+				}
+				return `{"pgmap":{"num_pgs":8,"pgs_by_state":[{"state_name":"active+clean","count":6},{"state_name":"active+remapped+backfilling","count":2}]}}`, nil
+			}
+			commands = append(commands, strings.Join(args[:6], " "))
+			return reweights, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	recorder := record.NewFakeRecorder(10)
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", OwnerRef: metav1.OwnerReference{Kind: "CephCluster", Name: "ceph"}}
+
+	// the settings out of bounds are replaced with the defaults
+	r := NewOSDReweighter(context, clusterInfo, cephv1.ReweightByUtilizationSpec{Enabled: true, Threshold: 101, MaxChange: 0.5, MaxOSDs: 3}, recorder)
+	assert.Equal(t, defaultReweightThreshold, r.spec.Threshold)
+	assert.Equal(t, defaultReweightMaxChange, r.spec.MaxChange)
+	assert.Equal(t, 3, r.spec.MaxOSDs)
+	assert.Equal(t, defaultReweightInterval, r.interval)
+
+	// no event without change
+	assert.NoError(t, r.reweight())
+	assert.Equal(t, []string{"osd reweight-by-utilization 120 0.05 3 --no-increasing"}, commands)
+	assert.Empty(t, recorder.Events)
+
+	// the changes are reported
+	reweights = `{"average_utilization":0.5,"overload_utilization":0.6,"reweights":[{"osd":2,"weight":1,"new_weight":0.95}]}`
+	assert.NoError(t, r.reweight())
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, opcontroller.EventReasonOSDsReweighted+" reweighted the osds above 60.0% utilization, 120% of the average utilization 50.0%: osd.2 1.0000 -> 0.9500")
+
+	// the osds are not reweighted while the pgs are not clean
+	commands = []string{}
+	clean = false
+	assert.NoError(t, r.reweight())
+	assert.Empty(t, commands)
+}
@@ -55,7 +55,7 @@ func (c *Cluster) generateKeyring(osdID int) (string, error) {
 	}
 
 	keyring := fmt.Sprintf(keyringTemplate, osdIDStr, key)
-	return keyring, s.CreateOrUpdate(deploymentName, keyring)
+	return keyring, s.CreateOrUpdateWithMeta(deploymentName, keyring, cephv1.GetOSDAnnotations(c.spec.Annotations), cephv1.GetOSDLabels(c.spec.Labels))
 }
 
 func (c *Cluster) osdRunFlagTuningOnPVC(osdID int) error {
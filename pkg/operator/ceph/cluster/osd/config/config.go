@@ -44,6 +44,7 @@ const (
 	OSDsPerDeviceKey   = "osdsPerDevice"
 	EncryptedDeviceKey = "encryptedDevice"
 	MetadataDeviceKey  = "metadataDevice"
+	WalDeviceKey       = "walDevice"
 	DeviceClassKey     = "deviceClass"
 )
 
@@ -55,7 +56,11 @@ type StoreConfig struct {
 	OSDsPerDevice   int    `json:"osdsPerDevice,omitempty"`
 	EncryptedDevice bool   `json:"encryptedDevice,omitempty"`
 	MetadataDevice  string `json:"metadataDevice,omitempty"`
+	WalDevice       string `json:"walDevice,omitempty"`
 	DeviceClass     string `json:"deviceClass,omitempty"`
+	// OSDsPerDeviceClass is the number of OSDs per device by device class, set from the performance settings of the
+	// cluster
+	OSDsPerDeviceClass map[string]int `json:"osdsPerDeviceClass,omitempty"`
 }
 
 // NewStoreConfig returns a StoreConfig with proper defaults set.
@@ -85,6 +90,8 @@ func ToStoreConfig(config map[string]string) StoreConfig {
 			storeConfig.EncryptedDevice = (v == "true")
 		case MetadataDeviceKey:
 			storeConfig.MetadataDevice = v
+		case WalDeviceKey:
+			storeConfig.WalDevice = v
 		case DeviceClassKey:
 			storeConfig.DeviceClass = v
 		}
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConfirmCrushLocationAnnotation is the annotation of the nodes confirming the move of their OSDs to a new CRUSH
+	// location, set to the pending location of the node
+	ConfirmCrushLocationAnnotation = "rook.io/confirm-crush-location"
+	// the config map recording the pending CRUSH location of the nodes whose labels changed, by node
+	crushMovesConfigMapName = "rook-ceph-osd-crush-moves"
+)
+
+// reconcileCrushLocation moves the host bucket of a node with its OSDs to the CRUSH location of the node labels once
+// the new location is confirmed with the annotation of the node. Until then the OSDs of the node keep their current
+// location, so they are not moved by their restart.
+func (c *Cluster) reconcileCrushLocation(hostname string, osds []OSDInfo) []OSDInfo {
+	if !c.spec.CrushTopology.ConfirmMoves || len(osds) == 0 {
+		return osds
+	}
+	desired := parseCrushLocation(osds[0].Location)
+	host := desired["host"]
+	if host == "" {
+		return osds
+	}
+	current := c.currentCrushLocation(host, osds)
+	if current == nil || reflect.DeepEqual(current, desired) {
+		// the osds are new or already at the location of the node labels
+		if err := c.setPendingCrushLocation(hostname, ""); err != nil {
+			logger.Warningf("%v", err)
+		}
+		return osds
+	}
+
+	target := crushMoveTarget(desired)
+	confirmed, err := c.crushLocationConfirmed(hostname, target)
+	if err != nil {
+		logger.Warningf("failed to check the confirmation of the crush location of node %q. %v", hostname, err)
+	}
+	if confirmed {
+		logger.Infof("moving crush host %q to the confirmed location %q", host, target)
+		if err := client.MoveCrushBucket(c.context, c.clusterInfo, host, strings.Fields(target)); err != nil {
+			logger.Errorf("failed to move the osds of node %q to their new crush location. %v", hostname, err)
+			return withCrushLocation(osds, formatCrushLocation(current))
+		}
+		if err := c.setPendingCrushLocation(hostname, ""); err != nil {
+			logger.Warningf("%v", err)
+		}
+		opcontroller.RecordOwnerEvent(c.Recorder, c.clusterInfo.Namespace, c.clusterInfo.OwnerRef, v1.EventTypeNormal,
+			opcontroller.EventReasonCrushMoved, "moved the osds of node %q to the crush location %q", hostname, target)
+		return osds
+	}
+
+	pending, err := c.pendingCrushLocation(hostname)
+	if err != nil {
+		logger.Warningf("%v", err)
+	}
+	if pending != target {
+		if err := c.setPendingCrushLocation(hostname, target); err != nil {
+			logger.Warningf("%v", err)
+		}
+		opcontroller.RecordOwnerEvent(c.Recorder, c.clusterInfo.Namespace, c.clusterInfo.OwnerRef, v1.EventTypeWarning,
+			opcontroller.EventReasonCrushMovePending, "the labels of node %q moved its osds to the crush location %q, annotate the node with %s=%q to confirm the move",
+			hostname, target, ConfirmCrushLocationAnnotation, target)
+	}
+	logger.Infof("the osds of node %q keep their crush location %q until the move to %q is confirmed with the %q annotation of the node",
+		hostname, formatCrushLocation(current), target, ConfirmCrushLocationAnnotation)
+	return withCrushLocation(osds, formatCrushLocation(current))
+}
+
+// currentCrushLocation returns the location of the host bucket of the osds in the CRUSH map, or nil if none of the
+// osds are in the CRUSH map
+func (c *Cluster) currentCrushLocation(host string, osds []OSDInfo) map[string]string {
+	for _, osd := range osds {
+		result, err := client.FindOSDInCrushMap(c.context, c.clusterInfo, osd.ID)
+		if err != nil {
+			logger.Debugf("osd %d is not in the crush map. %v", osd.ID, err)
+			continue
+		}
+		if result.Location["host"] == host {
+			return result.Location
+		}
+	}
+	return nil
+}
+
+// crushLocationConfirmed returns whether the move of the osds of a node to a location is confirmed
+func (c *Cluster) crushLocationConfirmed(hostname, target string) (bool, error) {
+	nodeName, err := k8sutil.GetNodeNameFromHostname(c.context.Clientset, hostname)
+	if err != nil {
+		nodeName = hostname
+	}
+	node, err := c.context.Clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get node %q", nodeName)
+	}
+	return node.Annotations[ConfirmCrushLocationAnnotation] == target, nil
+}
+
+func (c *Cluster) pendingCrushLocation(hostname string) (string, error) {
+	location, err := c.kv.GetValue(crushMovesConfigMapName, hostname)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return "", errors.Wrapf(err, "failed to get the pending crush location of node %q", hostname)
+	}
+	return location, nil
+}
+
+func (c *Cluster) setPendingCrushLocation(hostname, location string) error {
+	if location == "" {
+		if err := c.kv.DeleteValue(crushMovesConfigMapName, hostname); err != nil {
+			return errors.Wrapf(err, "failed to remove the pending crush location of node %q", hostname)
+		}
+		return nil
+	}
+	if err := c.kv.SetValue(crushMovesConfigMapName, hostname, location); err != nil {
+		return errors.Wrapf(err, "failed to record the pending crush location of node %q", hostname)
+	}
+	return nil
+}
+
+// crushMoveTarget returns the location of the host bucket of a node, without the host
+func crushMoveTarget(location map[string]string) string {
+	parents := map[string]string{}
+	for bucketType, name := range location {
+		if bucketType != "host" {
+			parents[bucketType] = name
+		}
+	}
+	return formatCrushLocation(parents)
+}
+
+func withCrushLocation(osds []OSDInfo, location string) []OSDInfo {
+	result := make([]OSDInfo, 0, len(osds))
+	for _, osd := range osds {
+		osd.Location = location
+		result = append(result, osd)
+	}
+	return result
+}
+
+// CrushLocationChanged returns whether the update of a node changed its CRUSH topology or confirmed a move, so the
+// CRUSH location of its osds must be reconciled
+func CrushLocationChanged(oldNode, newNode *v1.Node, spec cephv1.CrushTopologySpec) bool {
+	if !spec.ConfirmMoves {
+		return false
+	}
+	confirmation := newNode.Annotations[ConfirmCrushLocationAnnotation]
+	if confirmation != "" && confirmation != oldNode.Annotations[ConfirmCrushLocationAnnotation] {
+		return true
+	}
+	return !reflect.DeepEqual(ExtractCrushTopologyFromLabels(oldNode.Labels, spec.Labels), ExtractCrushTopologyFromLabels(newNode.Labels, spec.Labels))
+}
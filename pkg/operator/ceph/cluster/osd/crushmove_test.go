@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReconcileCrushLocation(t *testing.T) {
+	moves := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfileArg string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "find" {
+				if args[2] == "0" {
+					return `{"osd":0,"crush_location":{"host":"node1","rack":"rack1","root":"default"}}`, nil
+				}
+				return "", errors.New("osd not found")
+			}
+			if args[0] == "osd" && args[1] == "crush" && args[2] == "move" {
+				moves = append(moves, strings.Join(args[3:6], " "))
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected ceph command %v", args)
+		},
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{v1.LabelHostname: "node1"}}}
+	clientset := fake.NewSimpleClientset(node)
+	context := &clusterd.Context{Clientset: clientset, Executor: executor}
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", OwnerRef: metav1.OwnerReference{Name: "my-cluster"}}
+	c := New(context, clusterInfo, cephv1.ClusterSpec{}, "rook/rook:myversion")
+	recorder := record.NewFakeRecorder(10)
+	c.Recorder = recorder
+	osds := []OSDInfo{{ID: 0, Location: "root=default host=node1 rack=rack2"}, {ID: 1, Location: "root=default host=node1 rack=rack2"}}
+
+	// the osds move with their new location by default
+	assert.Equal(t, osds, c.reconcileCrushLocation("node1", osds))
+
+	// the move is pending until it is confirmed
+	c.spec.CrushTopology.ConfirmMoves = true
+	result := c.reconcileCrushLocation("node1", osds)
+	assert.Equal(t, "host=node1 rack=rack1 root=default", result[0].Location)
+	assert.Equal(t, "host=node1 rack=rack1 root=default", result[1].Location)
+	assert.Empty(t, moves)
+	pending, err := c.pendingCrushLocation("node1")
+	assert.NoError(t, err)
+	assert.Equal(t, "rack=rack2 root=default", pending)
+	assert.Equal(t, 1, len(recorder.Events))
+	<-recorder.Events
+
+	// a confirmation of another location does not move the osds
+	node.Annotations = map[string]string{ConfirmCrushLocationAnnotation: "rack=rack3 root=default"}
+	_, err = clientset.CoreV1().Nodes().Update(node)
+	assert.NoError(t, err)
+	result = c.reconcileCrushLocation("node1", osds)
+	assert.Equal(t, "host=node1 rack=rack1 root=default", result[0].Location)
+	assert.Empty(t, moves)
+	assert.Equal(t, 0, len(recorder.Events))
+
+	// the confirmed move moves the host bucket
+	node.Annotations = map[string]string{ConfirmCrushLocationAnnotation: "rack=rack2 root=default"}
+	_, err = clientset.CoreV1().Nodes().Update(node)
+	assert.NoError(t, err)
+	assert.Equal(t, osds, c.reconcileCrushLocation("node1", osds))
+	assert.Equal(t, []string{"node1 rack=rack2 root=default"}, moves)
+	pending, err = c.pendingCrushLocation("node1")
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// the new osds of a node are not checked
+	newOSDs := []OSDInfo{{ID: 1, Location: "root=default host=node1 rack=rack2"}}
+	assert.Equal(t, newOSDs, c.reconcileCrushLocation("node1", newOSDs))
+}
+
+func TestCrushLocationChanged(t *testing.T) {
+	spec := cephv1.CrushTopologySpec{Labels: []cephv1.CrushTopologyLabelSpec{{Key: "example.com/rack", Type: "rack"}}, ConfirmMoves: true}
+	oldNode := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"example.com/rack": "rack1"}}}
+	newNode := oldNode.DeepCopy()
+	assert.False(t, CrushLocationChanged(oldNode, newNode, spec))
+
+	newNode.Labels["other"] = "value"
+	assert.False(t, CrushLocationChanged(oldNode, newNode, spec))
+
+	newNode.Labels["example.com/rack"] = "rack2"
+	assert.True(t, CrushLocationChanged(oldNode, newNode, spec))
+	assert.False(t, CrushLocationChanged(oldNode, newNode, cephv1.CrushTopologySpec{Labels: spec.Labels}))
+
+	newNode = oldNode.DeepCopy()
+	newNode.Annotations = map[string]string{ConfirmCrushLocationAnnotation: "rack=rack2 root=default"}
+	assert.True(t, CrushLocationChanged(oldNode, newNode, spec))
+}
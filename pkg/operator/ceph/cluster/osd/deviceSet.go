@@ -18,9 +18,11 @@ package osd
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
 	v1 "k8s.io/api/core/v1"
@@ -93,6 +95,7 @@ func (c *Cluster) createStorageClassDeviceSetPVC(storageClassDeviceSetName strin
 	// old labels and PVC ID
 	pvcStorageClassDeviceSetPVCId, pvcStorageClassDeviceSetPVCIdLabelSelector := makeStorageClassDeviceSetPVCID(storageClassDeviceSetName, setIndex)
 	pvc := makeStorageClassDeviceSetPVC(storageClassDeviceSetName, pvcStorageClassDeviceSetPVCId, setIndex, pvcTemplate)
+	c.applyOSDMetadata(pvc)
 	oldPresentPVCs, err := c.context.Clientset.CoreV1().PersistentVolumeClaims(c.clusterInfo.Namespace).List(metav1.ListOptions{LabelSelector: pvcStorageClassDeviceSetPVCIdLabelSelector})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list pvc %s for storageClassDeviceSet %s", pvcStorageClassDeviceSetPVCIdLabelSelector, storageClassDeviceSetName)
@@ -102,12 +105,14 @@ func (c *Cluster) createStorageClassDeviceSetPVC(storageClassDeviceSetName strin
 	if len(oldPresentPVCs.Items) == 1 {
 		logger.Debugf("old labeled pvc %q found", oldPresentPVCs.Items[0].Name)
 		c.updatePVCIfChanged(pvc, &oldPresentPVCs.Items[0])
+		c.updatePVCMetadataIfChanged(pvc, &oldPresentPVCs.Items[0])
 		return &oldPresentPVCs.Items[0], nil
 	}
 
 	// check again with the new label for the presence of updated pvc
 	pvcStorageClassDeviceSetPVCId, pvcStorageClassDeviceSetPVCIdLabelSelector = makeStorageClassDeviceSetPVCIDNew(storageClassDeviceSetName, pvcTemplate.GetName(), setIndex)
 	pvc = makeStorageClassDeviceSetPVC(storageClassDeviceSetName, pvcStorageClassDeviceSetPVCId, setIndex, pvcTemplate)
+	c.applyOSDMetadata(pvc)
 	presentPVCs, err := c.context.Clientset.CoreV1().PersistentVolumeClaims(c.clusterInfo.Namespace).List(metav1.ListOptions{LabelSelector: pvcStorageClassDeviceSetPVCIdLabelSelector})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list pvc %s for storageClassDeviceSet %s", pvcStorageClassDeviceSetPVCIdLabelSelector, storageClassDeviceSetName)
@@ -126,6 +131,7 @@ func (c *Cluster) createStorageClassDeviceSetPVC(storageClassDeviceSetName strin
 	} else if presentPVCsNum == 1 {
 		logger.Debugf("already present pvc %q", presentPVCs.Items[0].Name)
 		c.updatePVCIfChanged(pvc, &presentPVCs.Items[0])
+		c.updatePVCMetadataIfChanged(pvc, &presentPVCs.Items[0])
 
 		// Updating with the new label
 		return &presentPVCs.Items[0], nil
@@ -155,6 +161,47 @@ func (c *Cluster) updatePVCIfChanged(desiredPVC *v1.PersistentVolumeClaim, curre
 	}
 }
 
+// applyOSDMetadata adds the annotations and labels of the osds to a pvc of a device set. The labels set by rook and the
+// annotations and labels of the pvc template take precedence.
+func (c *Cluster) applyOSDMetadata(pvc *v1.PersistentVolumeClaim) {
+	annotations := map[string]string{}
+	for k, v := range pvc.Annotations {
+		annotations[k] = v
+	}
+	pvc.Annotations = annotations
+	cephv1.GetOSDAnnotations(c.spec.Annotations).ApplyToObjectMeta(&pvc.ObjectMeta)
+	cephv1.GetOSDLabels(c.spec.Labels).ApplyToObjectMeta(&pvc.ObjectMeta)
+}
+
+// updatePVCMetadataIfChanged re-applies the annotations and labels of the desired pvc to an existing pvc of a device
+// set, the other annotations and labels of the pvc are kept
+func (c *Cluster) updatePVCMetadataIfChanged(desiredPVC *v1.PersistentVolumeClaim, currentPVC *v1.PersistentVolumeClaim) {
+	updated := currentPVC.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	for k, v := range desiredPVC.Annotations {
+		updated.Annotations[k] = v
+	}
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for k, v := range desiredPVC.Labels {
+		updated.Labels[k] = v
+	}
+	if reflect.DeepEqual(currentPVC.Annotations, updated.Annotations) && reflect.DeepEqual(currentPVC.Labels, updated.Labels) {
+		return
+	}
+	result, err := c.context.Clientset.CoreV1().PersistentVolumeClaims(c.clusterInfo.Namespace).Update(updated)
+	if err != nil {
+		// log the error, but don't fail the reconcile
+		logger.Errorf("failed to update the labels and annotations of pvc %q. %v", currentPVC.Name, err)
+		return
+	}
+	*currentPVC = *result
+	logger.Infof("updated the labels and annotations of pvc %q", currentPVC.Name)
+}
+
 func makeStorageClassDeviceSetPVC(storageClassDeviceSetName, pvcStorageClassDeviceSetPVCId string, setIndex int, pvcTemplate v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
 	pvcLabels := makeStorageClassDeviceSetPVCLabel(storageClassDeviceSetName, pvcStorageClassDeviceSetPVCId, setIndex)
 
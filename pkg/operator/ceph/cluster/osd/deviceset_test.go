@@ -107,3 +107,37 @@ func TestUpdatePVCSize(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "6Gi", result.String())
 }
+
+func TestUpdatePVCMetadata(t *testing.T) {
+	clientset := testexec.New(t, 1)
+	spec := cephv1.ClusterSpec{
+		Annotations: rookv1.AnnotationsSpec{"osd": {"team": "storage"}},
+		Labels:      rookv1.LabelsSpec{"all": {"cost-center": "ceph"}},
+	}
+	cluster := &Cluster{
+		context:     &clusterd.Context{Clientset: clientset},
+		clusterInfo: client.AdminClusterInfo("testns"),
+		spec:        spec,
+	}
+	template := v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"team": "set"}}}
+
+	// the annotations and labels of the osds are added, the template takes precedence
+	desired := makeStorageClassDeviceSetPVC("set1", "set1-0", 0, template)
+	cluster.applyOSDMetadata(desired)
+	assert.Equal(t, map[string]string{"team": "set"}, desired.Annotations)
+	assert.Equal(t, "ceph", desired.Labels["cost-center"])
+	assert.Equal(t, "set1", desired.Labels[CephDeviceSetLabelKey])
+	assert.Equal(t, map[string]string{"team": "set"}, template.Annotations)
+
+	// the metadata of an existing pvc is updated
+	current, err := clientset.CoreV1().PersistentVolumeClaims("testns").Create(&v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "set1-0-abcde", Namespace: "testns", Labels: map[string]string{"other": "label"}},
+	})
+	assert.NoError(t, err)
+	cluster.updatePVCMetadataIfChanged(desired, current)
+	pvc, err := clientset.CoreV1().PersistentVolumeClaims("testns").Get("set1-0-abcde", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ceph", pvc.Labels["cost-center"])
+	assert.Equal(t, "label", pvc.Labels["other"])
+	assert.Equal(t, "set", pvc.Annotations["team"])
+}
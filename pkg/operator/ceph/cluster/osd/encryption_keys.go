@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ceph-volume stores the dmcrypt key of an OSD under dm-crypt/osd/<osd-fsid>/luks in the config-key store
+	dmcryptKeyPrefix = "dm-crypt/osd/"
+	dmcryptKeySuffix = "/luks"
+)
+
+// encryptedOSDs returns the fsids of the OSDs encrypted by ceph-volume, whose dmcrypt key is in the config-key store
+// of the mons. The OSDs encrypted with the keys of the key management service have an EncryptionKeyID instead.
+func (c *Cluster) encryptedOSDs() (map[string]bool, error) {
+	configKeys, err := client.ConfigKeyList(c.context, c.clusterInfo)
+	if err != nil {
+		return nil, err
+	}
+	osds := map[string]bool{}
+	for _, key := range configKeys {
+		if strings.HasPrefix(key, dmcryptKeyPrefix) && strings.HasSuffix(key, dmcryptKeySuffix) {
+			osds[strings.TrimSuffix(strings.TrimPrefix(key, dmcryptKeyPrefix), dmcryptKeySuffix)] = true
+		}
+	}
+	return osds, nil
+}
+
+// kmsEnvVars returns the connection details of the key management service, which are read from the environment by
+// the pods encrypting, opening and rotating the keys of the devices of the OSDs
+func (c *Cluster) kmsEnvVars() []v1.EnvVar {
+	details := c.spec.Security.KeyManagementService.ConnectionDetails
+	keys := []string{}
+	for key := range details {
+		keys = append(keys, key)
+	}
+	// keep the order of the variables stable to not restart the osds
+	sort.Strings(keys)
+	envVars := []v1.EnvVar{}
+	for _, key := range keys {
+		envVars = append(envVars, v1.EnvVar{Name: key, Value: details[key]})
+	}
+	return envVars
+}
+
+// kmsEnvFrom loads the credentials of the key management service from the token secret in the environment
+func (c *Cluster) kmsEnvFrom() []v1.EnvFromSource {
+	name := c.spec.Security.KeyManagementService.TokenSecretName
+	if name == "" {
+		return nil
+	}
+	return []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: name}}}}
+}
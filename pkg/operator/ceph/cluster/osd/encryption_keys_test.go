@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/daemon/ceph/osd/kms"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestEncryptedOSDs(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			assert.Equal(t, []string{"config-key", "ls"}, args[:2])
+			return `["dm-crypt/osd/fsid-a/luks","dm-crypt/osd/fsid-b/luks","mgr/dashboard/key"]`, nil
+		},
+	}
+	c := New(&clusterd.Context{Executor: executor}, cephclient.AdminClusterInfo("ns"), cephv1.ClusterSpec{}, "myversion")
+
+	osds, err := c.encryptedOSDs()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"fsid-a": true, "fsid-b": true}, osds)
+}
+
+func TestKMSEnvVars(t *testing.T) {
+	c := New(&clusterd.Context{}, cephclient.AdminClusterInfo("ns"), cephv1.ClusterSpec{}, "myversion")
+	assert.Equal(t, []v1.EnvVar{}, c.kmsEnvVars())
+	assert.Nil(t, c.kmsEnvFrom())
+
+	c.spec.Security.KeyManagementService = cephv1.KeyManagementServiceSpec{
+		ConnectionDetails: map[string]string{
+			kms.ProviderKey:                kms.IBMKeyProtectProvider,
+			kms.IBMKeyProtectRootKeyIDKey:  "root-key",
+			kms.IBMKeyProtectInstanceIDKey: "instance",
+		},
+		TokenSecretName: "kms-token",
+	}
+	// the connection details are sorted and the credentials are loaded from the token secret
+	assert.Equal(t, []v1.EnvVar{
+		{Name: kms.IBMKeyProtectRootKeyIDKey, Value: "root-key"},
+		{Name: kms.IBMKeyProtectInstanceIDKey, Value: "instance"},
+		{Name: kms.ProviderKey, Value: kms.IBMKeyProtectProvider},
+	}, c.kmsEnvVars())
+	assert.Equal(t, "kms-token", c.kmsEnvFrom()[0].SecretRef.Name)
+}
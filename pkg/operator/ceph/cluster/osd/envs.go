@@ -31,12 +31,16 @@ const (
 	osdWalSizeEnvVarName        = "ROOK_OSD_WAL_SIZE"
 	osdJournalSizeEnvVarName    = "ROOK_OSD_JOURNAL_SIZE"
 	osdsPerDeviceEnvVarName     = "ROOK_OSDS_PER_DEVICE"
+	osdsPerDeviceClassVarName   = "ROOK_OSDS_PER_DEVICE_CLASS"
+	CrushTopologyLabelsVarName  = "ROOK_CRUSH_TOPOLOGY_LABELS"
 	osdMetadataDeviceEnvVarName = "ROOK_METADATA_DEVICE"
 	pvcBackedOSDVarName         = "ROOK_PVC_BACKED_OSD"
 	blockPathVarName            = "ROOK_BLOCK_PATH"
 	cvModeVarName               = "ROOK_CV_MODE"
 	lvBackedPVVarName           = "ROOK_LV_BACKED_PV"
 	CrushDeviceClassVarName     = "ROOK_OSD_CRUSH_DEVICE_CLASS"
+	replaceOSDIDsVarName        = "ROOK_REPLACE_OSD_IDS"
+	encryptionKeyIDVarName      = "ROOK_OSD_ENCRYPTION_KEY_ID"
 )
 
 func (c *Cluster) getConfigEnvVars(osdProps osdProperties, dataDir string) []v1.EnvVar {
@@ -135,10 +139,23 @@ func setDebugLogLevelEnvVar(debug bool) v1.EnvVar {
 	return v1.EnvVar{Name: "ROOK_LOG_LEVEL", Value: level}
 }
 
+func osdStoreEnvVar(storeType string) v1.EnvVar {
+	return v1.EnvVar{Name: osdStoreEnvVarName, Value: storeType}
+}
+
+// replaceOSDIDsEnvVar is the comma separated list of the destroyed osds the prepare job recreates with the same ids
+func replaceOSDIDsEnvVar(ids []int) v1.EnvVar {
+	return v1.EnvVar{Name: replaceOSDIDsVarName, Value: formatOSDIDs(ids)}
+}
+
 func blockPathEnvVariable(lvPath string) v1.EnvVar {
 	return v1.EnvVar{Name: blockPathVarName, Value: lvPath}
 }
 
+func encryptionKeyIDEnvVar(keyID string) v1.EnvVar {
+	return v1.EnvVar{Name: encryptionKeyIDVarName, Value: keyID}
+}
+
 func cvModeEnvVariable(cvMode string) v1.EnvVar {
 	return v1.EnvVar{Name: cvModeVarName, Value: cvMode}
 }
@@ -147,6 +164,14 @@ func lvBackedPVEnvVar(lvBackedPV string) v1.EnvVar {
 	return v1.EnvVar{Name: lvBackedPVVarName, Value: lvBackedPV}
 }
 
+func osdsPerDeviceClassEnvVar(osdsPerDeviceClass string) v1.EnvVar {
+	return v1.EnvVar{Name: osdsPerDeviceClassVarName, Value: osdsPerDeviceClass}
+}
+
+func crushTopologyLabelsEnvVar(topologyLabels string) v1.EnvVar {
+	return v1.EnvVar{Name: CrushTopologyLabelsVarName, Value: topologyLabels}
+}
+
 func crushDeviceClassEnvVar(crushDeviceClass string) v1.EnvVar {
 	return v1.EnvVar{Name: CrushDeviceClassVarName, Value: crushDeviceClass}
 }
@@ -27,9 +27,12 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/notification"
 	"github.com/rook/rook/pkg/operator/k8sutil"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -48,6 +51,11 @@ type OSDHealthMonitor struct {
 	clusterInfo                    *client.ClusterInfo
 	removeOSDsIfOUTAndSafeToRemove bool
 	interval                       time.Duration
+	recorder                       record.EventRecorder
+	// the osds already reported down, an event is emitted only when an osd goes down
+	downOSDs map[int]bool
+	// marks out the osds which stay down, nil when the mons mark them out
+	autoOut *osdAutoOut
 }
 
 // NewOSDHealthMonitor instantiates OSD monitoring
@@ -57,6 +65,7 @@ func NewOSDHealthMonitor(context *clusterd.Context, clusterInfo *client.ClusterI
 		clusterInfo:                    clusterInfo,
 		removeOSDsIfOUTAndSafeToRemove: removeOSDsIfOUTAndSafeToRemove,
 		interval:                       defaultHealthCheckInterval,
+		autoOut:                        newOSDAutoOut(context, clusterInfo, healthCheck.DaemonHealth.ObjectStorageDaemon.AutoOut),
 	}
 
 	// allow overriding the check interval
@@ -73,7 +82,6 @@ func NewOSDHealthMonitor(context *clusterd.Context, clusterInfo *client.ClusterI
 
 // Start runs monitoring logic for osds status at set intervals
 func (m *OSDHealthMonitor) Start(stopCh chan struct{}) {
-
 	for {
 		select {
 		case <-time.After(m.interval):
@@ -92,6 +100,11 @@ func (m *OSDHealthMonitor) Update(removeOSDsIfOUTAndSafeToRemove bool) {
 	m.removeOSDsIfOUTAndSafeToRemove = removeOSDsIfOUTAndSafeToRemove
 }
 
+// SetEventRecorder sets the recorder of the events emitted on the CephCluster when the osds go down or are removed
+func (m *OSDHealthMonitor) SetEventRecorder(recorder record.EventRecorder) {
+	m.recorder = recorder
+}
+
 // checkOSDHealth takes action when needed if the OSDs are not healthy
 func (m *OSDHealthMonitor) checkOSDHealth() {
 	err := m.checkOSDDump()
@@ -139,10 +152,12 @@ func (m *OSDHealthMonitor) checkOSDDump() error {
 
 		if status == upStatus {
 			logger.Debugf("osd.%d is healthy.", id)
+			delete(m.downOSDs, id)
 			continue
 		}
 
 		logger.Debugf("osd.%d is marked 'DOWN'", id)
+		m.reportOSDDown(id)
 
 		// check if the down osd is stuck terminating
 		if err := m.restartOSDIfStuck(id); err != nil {
@@ -159,6 +174,12 @@ func (m *OSDHealthMonitor) checkOSDDump() error {
 		}
 	}
 
+	if m.autoOut != nil {
+		if err := m.autoOutOSDs(osdDump); err != nil {
+			return errors.Wrap(err, "failed to mark out the down osds")
+		}
+	}
+
 	return nil
 }
 
@@ -186,6 +207,9 @@ func (m *OSDHealthMonitor) removeOSDDeploymentIfSafeToDestroy(outOSDid int) erro
 				if err := k8sutil.DeleteDeployment(m.context.Clientset, dp.Items[0].Namespace, dp.Items[0].Name); err != nil {
 					return errors.Wrapf(err, "failed to delete osd deployment %s", dp.Items[0].Name)
 				}
+				notification.Warning(notification.ReasonOSDRemoved, m.clusterInfo.Namespace, dp.Items[0].Name, "osd.%d was out and safe to destroy, removed osd deployment", outOSDid)
+				opcontroller.RecordOwnerEvent(m.recorder, m.clusterInfo.Namespace, m.clusterInfo.OwnerRef, corev1.EventTypeWarning, opcontroller.EventReasonOSDRemoved,
+					"osd.%d was out and safe to destroy, removed osd deployment %q", outOSDid, dp.Items[0].Name)
 			}
 		}
 	}
@@ -226,6 +250,10 @@ func (m *OSDHealthMonitor) updateCephStatus(devices []string) {
 		logger.Errorf("failed to retrieve ceph cluster %q to update ceph Storage. %v", m.clusterInfo.NamespacedName().Name, err)
 		return
 	}
+	// the store of the osds is reported by the orchestration of the osds
+	if cephCluster.Status.CephStorage != nil {
+		cephClusterStorage.OSD = cephCluster.Status.CephStorage.OSD
+	}
 	if !reflect.DeepEqual(cephCluster.Status.CephStorage, &cephClusterStorage) {
 		cephCluster.Status.CephStorage = &cephClusterStorage
 		if err := opcontroller.UpdateStatus(m.context.Client, cephCluster); err != nil {
@@ -234,3 +262,15 @@ func (m *OSDHealthMonitor) updateCephStatus(devices []string) {
 		}
 	}
 }
+
+// reportOSDDown emits an event on the CephCluster the first time an osd is seen down
+func (m *OSDHealthMonitor) reportOSDDown(id int) {
+	if m.downOSDs[id] {
+		return
+	}
+	if m.downOSDs == nil {
+		m.downOSDs = map[int]bool{}
+	}
+	m.downOSDs[id] = true
+	opcontroller.RecordOwnerEvent(m.recorder, m.clusterInfo.Namespace, m.clusterInfo.OwnerRef, corev1.EventTypeWarning, opcontroller.EventReasonOSDDown, "osd.%d is down", id)
+}
@@ -34,6 +34,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -94,6 +95,9 @@ func TestOSDHealthCheck(t *testing.T) {
 
 	// Initializing an OSD monitoring
 	osdMon := NewOSDHealthMonitor(context, clusterInfo, true, cephv1.CephClusterHealthCheckSpec{})
+	clusterInfo.OwnerRef = metav1.OwnerReference{APIVersion: "ceph.rook.io/v1", Kind: "CephCluster", Name: "fake"}
+	recorder := record.NewFakeRecorder(10)
+	osdMon.SetEventRecorder(recorder)
 
 	// Run OSD monitoring routine
 	err := osdMon.checkOSDDump()
@@ -104,6 +108,15 @@ func TestOSDHealthCheck(t *testing.T) {
 	// Check if the osd deployment was deleted
 	dp, _ = context.Clientset.AppsV1().Deployments(clusterInfo.Namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%v=%d", OsdIdLabelKey, 0)})
 	assert.Equal(t, 0, len(dp.Items))
+
+	// the osd down and its removal are reported on the cluster
+	assert.Equal(t, "Warning OSDDown osd.0 is down", <-recorder.Events)
+	assert.Equal(t, `Warning OSDRemoved osd.0 was out and safe to destroy, removed osd deployment "osd0"`, <-recorder.Events)
+
+	// the osd is reported down only once
+	err = osdMon.checkOSDDump()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(recorder.Events))
 }
 
 func TestMonitorStart(t *testing.T) {
@@ -188,8 +201,8 @@ func TestNewOSDHealthMonitor(t *testing.T) {
 		args args
 		want *OSDHealthMonitor
 	}{
-		{"default-interval", args{c, false, cephv1.CephClusterHealthCheckSpec{}}, &OSDHealthMonitor{c, clusterInfo, false, defaultHealthCheckInterval}},
-		{"10s-interval", args{c, false, cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{ObjectStorageDaemon: cephv1.HealthCheckSpec{Interval: "10s"}}}}, &OSDHealthMonitor{c, clusterInfo, false, time10s}},
+		{"default-interval", args{c, false, cephv1.CephClusterHealthCheckSpec{}}, &OSDHealthMonitor{c, clusterInfo, false, defaultHealthCheckInterval, nil, nil, nil}},
+		{"10s-interval", args{c, false, cephv1.CephClusterHealthCheckSpec{DaemonHealth: cephv1.DaemonHealthSpec{ObjectStorageDaemon: cephv1.OSDHealthCheckSpec{HealthCheckSpec: cephv1.HealthCheckSpec{Interval: "10s"}}}}}, &OSDHealthMonitor{c, clusterInfo, false, time10s, nil, nil, nil}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
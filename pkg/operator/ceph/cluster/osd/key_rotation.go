@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	keyRotationAppName    = "rook-ceph-osd-key-rotation"
+	keyRotationJobTimeout = 5 * time.Minute
+)
+
+// RotateEncryptionKeys rotates the dmcrypt keys of the encrypted OSDs when the interval of the key rotation policy
+// elapsed since the last rotation. The keys of the OSDs encrypted by ceph-volume are saved in the config-key store of
+// the mons, the keys of the OSDs encrypted with the key management service are saved wrapped in their device.
+func (c *Cluster) RotateEncryptionKeys() error {
+	policy := c.spec.Security.KeyRotation
+	if !policy.Enabled {
+		return nil
+	}
+	interval, err := policy.GetInterval()
+	if err != nil {
+		return err
+	}
+
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.context.Client.Get(context.TODO(), c.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve ceph cluster %q to rotate the encryption keys", c.clusterInfo.NamespacedName().Name)
+	}
+	now := time.Now().UTC()
+	if !keyRotationDue(cephCluster.Status.KeyRotation, interval, now) {
+		logger.Debugf("the encryption keys of the osds were rotated less than %s ago", interval.String())
+		return nil
+	}
+
+	encrypted, err := c.encryptedOSDs()
+	if err != nil {
+		return err
+	}
+
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)}
+	deployments, err := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).List(listOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to list osd deployments")
+	}
+	rotated := 0
+	// the osds of a device encrypted with the key management service share the key of the device
+	rotatedKeys := map[string]bool{}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		osds, err := c.getOSDInfo(d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the info of osd deployment %q", d.Name)
+		}
+		osd := osds[0]
+		if !encrypted[osd.UUID] && osd.EncryptionKeyID == "" {
+			continue
+		}
+		nodeName := d.Spec.Template.Spec.NodeSelector[v1.LabelHostname]
+		if _, ok := d.Labels[OSDOverPVCLabelKey]; ok || osd.CVMode != "lvm" || nodeName == "" {
+			logger.Infof("skipping the key rotation of osd %d, only the keys of lvm osds on nodes can be rotated", osd.ID)
+			continue
+		}
+		if osd.EncryptionKeyID != "" && rotatedKeys[osd.EncryptionKeyID] {
+			logger.Infof("rotated the encryption key of osd %d with the key of its device", osd.ID)
+			rotated++
+			continue
+		}
+
+		job := c.makeKeyRotationJob(osd, nodeName)
+		if err := k8sutil.RunReplaceableJob(c.context.Clientset, job, true); err != nil {
+			return errors.Wrapf(err, "failed to run the key rotation job of osd %d", osd.ID)
+		}
+		if err := k8sutil.WaitForJobCompletion(c.context.Clientset, job, keyRotationJobTimeout); err != nil {
+			return errors.Wrapf(err, "failed to rotate the key of osd %d", osd.ID)
+		}
+		if osd.EncryptionKeyID != "" {
+			rotatedKeys[osd.EncryptionKeyID] = true
+		}
+		logger.Infof("rotated the encryption key of osd %d", osd.ID)
+		rotated++
+	}
+
+	cephCluster.Status.KeyRotation = &cephv1.KeyRotationStatus{
+		LastRotationTime: now.Format(time.RFC3339),
+		RotatedOSDs:      rotated,
+	}
+	if err := opcontroller.UpdateStatus(c.context.Client, cephCluster); err != nil {
+		return errors.Wrapf(err, "failed to update cluster %q key rotation status", c.clusterInfo.NamespacedName().Name)
+	}
+	return nil
+}
+
+// keyRotationDue returns whether the interval elapsed since the last rotation of the keys
+func keyRotationDue(status *cephv1.KeyRotationStatus, interval time.Duration, now time.Time) bool {
+	if status == nil || status.LastRotationTime == "" {
+		return true
+	}
+	last, err := time.Parse(time.RFC3339, status.LastRotationTime)
+	if err != nil {
+		logger.Warningf("failed to parse the last key rotation time %q, rotating the keys. %v", status.LastRotationTime, err)
+		return true
+	}
+	return !now.Before(last.Add(interval))
+}
+
+// makeKeyRotationJob creates the job rotating the key of an osd on the node of the osd
+func (c *Cluster) makeKeyRotationJob(osd OSDInfo, nodeName string) *batch.Job {
+	copyBinariesVolume, copyBinariesContainer := c.getCopyBinariesContainer()
+	dataPathMap := opconfig.NewDatalessDaemonDataPathMap(c.clusterInfo.Namespace, c.spec.DataDirHostPath)
+
+	volumes := append(opcontroller.PodVolumes(dataPathMap, c.spec.DataDirHostPath, true), copyBinariesVolume,
+		v1.Volume{Name: "devices", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/dev"}}},
+		v1.Volume{Name: "udev", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/run/udev"}}})
+	volumeMounts := append(opcontroller.CephVolumeMounts(dataPathMap, true),
+		v1.VolumeMount{Name: "devices", MountPath: "/dev"},
+		v1.VolumeMount{Name: "udev", MountPath: "/run/udev"},
+		copyBinariesContainer.VolumeMounts[0])
+
+	// cryptsetup needs the devices of the host
+	privileged := true
+	runAsUser := int64(0)
+	args := []string{"--", path.Join(rookBinariesMountPath, "rook"), "ceph", "osd", "rotate-key",
+		"--osd-uuid", osd.UUID, "--block-path", osd.BlockPath}
+	envVars := c.getConfigEnvVars(osdProperties{crushHostname: nodeName}, k8sutil.DataDir)
+	var envFrom []v1.EnvFromSource
+	if osd.EncryptionKeyID != "" {
+		args = append(args, "--encryption-key-id", osd.EncryptionKeyID)
+		envVars = append(envVars, c.kmsEnvVars()...)
+		envFrom = c.kmsEnvFrom()
+	}
+	container := v1.Container{
+		Command:      []string{path.Join(rookBinariesMountPath, "tini")},
+		Args:         args,
+		Name:         "rotate-key",
+		Image:        c.spec.CephVersion.Image,
+		VolumeMounts: volumeMounts,
+		Env:          envVars,
+		EnvFrom:      envFrom,
+		SecurityContext: &v1.SecurityContext{
+			Privileged: &privileged,
+			RunAsUser:  &runAsUser,
+		},
+		Resources: cephv1.GetPrepareOSDResources(c.spec.Resources),
+	}
+
+	podSpec := v1.PodSpec{
+		ServiceAccountName: serviceAccountName,
+		InitContainers:     []v1.Container{*copyBinariesContainer},
+		Containers:         []v1.Container{container},
+		RestartPolicy:      v1.RestartPolicyOnFailure,
+		Volumes:            volumes,
+		HostNetwork:        c.spec.Network.IsHost(),
+		// cryptsetup synchronizes with udev on the host through semaphores
+		HostIPC:           true,
+		NodeSelector:      map[string]string{v1.LabelHostname: nodeName},
+		PriorityClassName: cephv1.GetOSDPriorityClassName(c.spec.PriorityClassNames),
+		SecurityContext:   cephv1.GetPodSecurityContext(c.spec.Security.PodSecurityContexts, cephv1.KeyOSD),
+	}
+	if c.spec.Network.IsHost() {
+		podSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
+	}
+	cephv1.GetOSDPlacement(c.spec.Placement).ApplyToPodSpec(&podSpec)
+
+	labels := map[string]string{
+		k8sutil.AppAttr:     keyRotationAppName,
+		k8sutil.ClusterAttr: c.clusterInfo.Namespace,
+		OsdIdLabelKey:       fmt.Sprintf("%d", osd.ID),
+	}
+	podMeta := metav1.ObjectMeta{Labels: labels}
+	if c.spec.Network.IsMultus() {
+		if err := k8sutil.ApplyMultus(c.spec.Network.NetworkSpec, &podMeta); err != nil {
+			logger.Errorf("failed to apply the multus networks to the key rotation job of osd %d. %v", osd.ID, err)
+		}
+	}
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", keyRotationAppName, osd.ID),
+			Namespace: c.clusterInfo.Namespace,
+			Labels:    labels,
+		},
+		Spec: batch.JobSpec{
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: podMeta,
+				Spec:       podSpec,
+			},
+		},
+	}
+	k8sutil.AddRookVersionLabelToJob(job)
+	opcontroller.AddCephVersionLabelToJob(c.clusterInfo.CephVersion, job)
+	k8sutil.SetOwnerRef(&job.ObjectMeta, &c.clusterInfo.OwnerRef)
+	return job
+}
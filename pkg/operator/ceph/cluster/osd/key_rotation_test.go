@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKeyRotationDue(t *testing.T) {
+	now := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, keyRotationDue(nil, time.Hour, now))
+	assert.True(t, keyRotationDue(&cephv1.KeyRotationStatus{LastRotationTime: "invalid"}, time.Hour, now))
+	assert.False(t, keyRotationDue(&cephv1.KeyRotationStatus{LastRotationTime: "2020-07-31T23:30:00Z"}, time.Hour, now))
+	assert.True(t, keyRotationDue(&cephv1.KeyRotationStatus{LastRotationTime: "2020-07-31T23:00:00Z"}, time.Hour, now))
+
+	interval, err := (&cephv1.KeyRotationSpec{}).GetInterval()
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.DefaultKeyRotationInterval, interval)
+	_, err = (&cephv1.KeyRotationSpec{Interval: "-1h"}).GetInterval()
+	assert.Error(t, err)
+}
+
+func TestMakeKeyRotationJob(t *testing.T) {
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	c := New(&clusterd.Context{}, clusterInfo, cephv1.ClusterSpec{DataDirHostPath: "/var/lib/rook"}, "rook/rook:myversion")
+	job := c.makeKeyRotationJob(OSDInfo{ID: 3, UUID: "osd-uuid", BlockPath: "/dev/vg/osd-block"}, "node1")
+
+	assert.Equal(t, "rook-ceph-osd-key-rotation-3", job.Name)
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, "node1", podSpec.NodeSelector[v1.LabelHostname])
+	assert.True(t, podSpec.HostIPC)
+	assert.Equal(t, []string{"--", "/rook/rook", "ceph", "osd", "rotate-key", "--osd-uuid", "osd-uuid", "--block-path", "/dev/vg/osd-block"},
+		podSpec.Containers[0].Args)
+	assert.True(t, *podSpec.Containers[0].SecurityContext.Privileged)
+	assert.Nil(t, podSpec.Containers[0].EnvFrom)
+
+	// the keys of the key management service are rotated with its credentials
+	c.spec.Security.KeyManagementService = cephv1.KeyManagementServiceSpec{
+		ConnectionDetails: map[string]string{"KMS_PROVIDER": "aws-kms"},
+		TokenSecretName:   "kms-token",
+	}
+	job = c.makeKeyRotationJob(OSDInfo{ID: 3, UUID: "osd-uuid", BlockPath: "/dev/vg/osd-block", EncryptionKeyID: "luks-uuid"}, "node1")
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"--encryption-key-id", "luks-uuid"}, container.Args[len(container.Args)-2:])
+	assert.Contains(t, container.Env, v1.EnvVar{Name: "KMS_PROVIDER", Value: "aws-kms"})
+	assert.Equal(t, "kms-token", container.EnvFrom[0].SecretRef.Name)
+}
+
+func TestRotateEncryptionKeys(t *testing.T) {
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	clusterInfo.SetName("rook-ceph")
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			return `["dm-crypt/osd/fsid-a/luks"]`, nil
+		},
+	}
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "ns"}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{})
+	cl := fake.NewFakeClientWithScheme(s, []runtime.Object{cephCluster}...)
+	clusterdContext := &clusterd.Context{Clientset: testop.New(t, 1), Client: cl, Executor: executor}
+	spec := cephv1.ClusterSpec{Security: cephv1.SecuritySpec{KeyRotation: cephv1.KeyRotationSpec{Enabled: true, Interval: "24h"}}}
+	c := New(clusterdContext, clusterInfo, spec, "myversion")
+
+	// the rotation is recorded even if no osd was rotated
+	assert.NoError(t, c.RotateEncryptionKeys())
+	assert.NoError(t, cl.Get(context.TODO(), clusterInfo.NamespacedName(), cephCluster))
+	assert.NotNil(t, cephCluster.Status.KeyRotation)
+	assert.Equal(t, 0, cephCluster.Status.KeyRotation.RotatedOSDs)
+	last := cephCluster.Status.KeyRotation.LastRotationTime
+
+	// the keys are not rotated before the interval elapsed
+	assert.NoError(t, c.RotateEncryptionKeys())
+	assert.NoError(t, cl.Get(context.TODO(), clusterInfo.NamespacedName(), cephCluster))
+	assert.Equal(t, last, cephCluster.Status.KeyRotation.LastRotationTime)
+
+	c.spec.Security.KeyRotation.Interval = "forever"
+	assert.Error(t, c.RotateEncryptionKeys())
+}
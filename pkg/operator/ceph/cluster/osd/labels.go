@@ -32,6 +32,10 @@ const (
 	CephDeviceSetPVCIDLabelKey = "ceph.rook.io/DeviceSetPVCId"
 	// OSDOverPVCLabelKey is the Rook PVC label key
 	OSDOverPVCLabelKey = "ceph.rook.io/pvc"
+	// OSDDeviceIDLabelKey is the label key of the persistent name of the device of an OSD on a node
+	OSDDeviceIDLabelKey = "ceph.rook.io/device-id"
+	// OSDDeviceClassLabelKey is the label key of the device class of the OSDs with an anti-affinity by device class
+	OSDDeviceClassLabelKey = "ceph.rook.io/device-class"
 )
 
 func makeStorageClassDeviceSetPVCLabel(storageClassDeviceSetName, pvcStorageClassDeviceSetPVCId string, setIndex int) map[string]string {
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// NodeMaintenanceAnnotation marks a node in maintenance when it is "true". The OSDs of the node are stopped with
+	// the noout flag set on their host until the annotation is removed.
+	NodeMaintenanceAnnotation = "ceph.rook.io/maintenance"
+	// MaintenanceScaledDownAnnotation is the annotation of the OSD deployments stopped during the maintenance of
+	// their node, its value is the name of the node
+	MaintenanceScaledDownAnnotation = "ceph.rook.io/maintenance-scaled-down"
+)
+
+// IsNodeInMaintenance returns whether the node is marked for maintenance
+func IsNodeInMaintenance(node *v1.Node) bool {
+	return node.GetAnnotations()[NodeMaintenanceAnnotation] == "true"
+}
+
+// isStoppedForMaintenance returns whether the deployment of the OSD is stopped during the maintenance of its node.
+// The deployment is not updated until the maintenance ends, its pod would not start.
+func (c *Cluster) isStoppedForMaintenance(deploymentName string) bool {
+	d, err := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).Get(deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	_, ok := d.GetAnnotations()[MaintenanceScaledDownAnnotation]
+	return ok
+}
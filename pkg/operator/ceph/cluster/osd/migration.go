@@ -0,0 +1,400 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// UpdateStoreConfirmation must be set in the updateStore setting of the store for the osds to be re-provisioned
+	UpdateStoreConfirmation = "yes-really-update-store"
+
+	defaultStoreType          = "bluestore"
+	defaultStoreFailureDomain = "host"
+
+	// the config map recording the hash of the store settings the osds were created with
+	storeSettingsConfigMapName = "rook-ceph-osd-store-settings"
+	// the config map recording the ids of the destroyed osds by node until they are re-provisioned
+	storeMigrationConfigMapName = "rook-ceph-osd-store-migration"
+)
+
+// StoreMigrationRequeueInterval is the time to wait before the next failure domain of a store migration
+var StoreMigrationRequeueInterval = 30 * time.Second
+
+// storeMigrationOSD is an osd whose store differs from the store of the spec
+type storeMigrationOSD struct {
+	id             int
+	deploymentName string
+	nodeName       string
+}
+
+// storeType returns the object store of the osds of the spec
+func storeType(spec *cephv1.ClusterSpec) string {
+	if spec.Storage.Store.Type == "" {
+		return defaultStoreType
+	}
+	return spec.Storage.Store.Type
+}
+
+// storeSettingsHash returns the hash of the store settings of the spec, which is empty when no setting is set
+func storeSettingsHash(settings map[string]string) string {
+	if len(settings) == 0 {
+		return ""
+	}
+	pairs := []string{}
+	for k, v := range settings {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return k8sutil.Hash(strings.Join(pairs, "\n"))
+}
+
+// applyStoreSettings sets the store settings of the spec in the config of the osds so that they are used when the
+// osds are created
+func (c *Cluster) applyStoreSettings() error {
+	if len(c.spec.Storage.Store.Settings) == 0 {
+		return nil
+	}
+	monStore := opconfig.GetMonStore(c.context, c.clusterInfo)
+	for option, value := range c.spec.Storage.Store.Settings {
+		if err := monStore.Set("osd", option, value); err != nil {
+			return errors.Wrapf(err, "failed to set the store setting %q", option)
+		}
+	}
+	return nil
+}
+
+// recordStoreSettings records the store settings of the spec for an osd which was just created
+func (c *Cluster) recordStoreSettings(osdID int) {
+	hash := storeSettingsHash(c.spec.Storage.Store.Settings)
+	if err := c.kv.SetValue(storeSettingsConfigMapName, strconv.Itoa(osdID), hash); err != nil {
+		logger.Warningf("failed to record the store settings of osd %d. %v", osdID, err)
+	}
+}
+
+// startStoreMigration re-provisions the osds of one failure domain when their store differs from the store of the
+// spec. The deployments of the osds are deleted and the osds are destroyed so that the next provisioning of their
+// nodes recreates them with the same ids. It returns whether osds are being re-provisioned. The next failure domain is
+// re-provisioned by a later orchestration once the pgs are clean, StoreMigrationPending requests it.
+func (c *Cluster) startStoreMigration() (bool, error) {
+	c.StoreMigrationPending = false
+	if c.spec.Storage.Store.Type == "" && len(c.spec.Storage.Store.Settings) == 0 {
+		// the osds keep the store they were created with
+		return false, nil
+	}
+	pending, storeTypes, err := c.storeMigrationCandidates()
+	if err != nil {
+		return false, err
+	}
+	status := &cephv1.OSDStatus{StoreType: storeTypes}
+	if len(pending) == 0 {
+		return false, c.updateStoreStatus(status)
+	}
+	if c.spec.Storage.Store.UpdateStore != UpdateStoreConfirmation {
+		logger.Infof("%d osds do not have the store of the spec, set updateStore to %q to re-provision them", len(pending), UpdateStoreConfirmation)
+		status.MigrationStatus = &cephv1.OSDMigrationStatus{
+			Pending: len(pending),
+			Message: fmt.Sprintf("set updateStore to %q to re-provision the osds", UpdateStoreConfirmation),
+		}
+		return false, c.updateStoreStatus(status)
+	}
+	c.StoreMigrationPending = true
+
+	// the osds destroyed previously must have been re-provisioned, and the pgs must have recovered from them
+	migrated := c.migratedOSDs()
+	status.MigrationStatus = &cephv1.OSDMigrationStatus{Pending: len(pending), Migrated: migrated}
+	inFlight, err := c.kv.GetStore(storeMigrationConfigMapName)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return false, errors.Wrap(err, "failed to get the osds being re-provisioned")
+	}
+	if len(inFlight) > 0 {
+		logger.Infof("waiting for the osds destroyed on nodes %v to be re-provisioned", inFlight)
+		status.MigrationStatus.Message = "waiting for the destroyed osds to be re-provisioned"
+		return false, c.updateStoreStatus(status)
+	}
+	if err := cephclient.IsClusterCleanError(c.context, c.clusterInfo); err != nil {
+		logger.Infof("waiting for the pgs to be clean before re-provisioning the osds. %v", err)
+		status.MigrationStatus.Message = "waiting for the pgs to be clean"
+		return false, c.updateStoreStatus(status)
+	}
+
+	domain, osds, err := c.nextStoreMigrationDomain(pending)
+	if err != nil {
+		return false, err
+	}
+	ids := []int{}
+	replaceOSDs := map[string][]int{}
+	for _, osd := range osds {
+		ids = append(ids, osd.id)
+		replaceOSDs[osd.nodeName] = append(replaceOSDs[osd.nodeName], osd.id)
+	}
+	if err := cephclient.OSDOkToStop(c.context, c.clusterInfo, ids); err != nil {
+		return false, errors.Wrapf(err, "failed to re-provision the osds of %q", domain)
+	}
+
+	// the ids are recorded before the osds are destroyed so that they are re-provisioned even if the operator restarts
+	for nodeName, nodeIDs := range replaceOSDs {
+		if err := c.kv.SetValue(storeMigrationConfigMapName, nodeName, formatOSDIDs(nodeIDs)); err != nil {
+			return false, errors.Wrapf(err, "failed to record the osds of node %q to re-provision", nodeName)
+		}
+	}
+	c.replaceOSDs = replaceOSDs
+
+	logger.Infof("re-provisioning osds %v of %q with the store of the spec", ids, domain)
+	for _, osd := range osds {
+		if err := k8sutil.DeleteDeployment(c.context.Clientset, c.clusterInfo.Namespace, osd.deploymentName); err != nil {
+			return false, errors.Wrapf(err, "failed to delete the deployment of osd %d", osd.id)
+		}
+		if err := cephclient.DestroyOSD(c.context, c.clusterInfo, osd.id); err != nil {
+			return false, err
+		}
+	}
+
+	status.MigrationStatus = &cephv1.OSDMigrationStatus{
+		Pending:       len(pending),
+		Migrated:      migrated + len(osds),
+		FailureDomain: domain,
+		Message:       fmt.Sprintf("re-provisioning osds %v", ids),
+	}
+	return true, c.updateStoreStatus(status)
+}
+
+// loadStoreMigration loads the ids of the osds destroyed by a store migration which were not re-provisioned yet, for
+// instance because the operator restarted or a prepare job failed
+func (c *Cluster) loadStoreMigration() error {
+	c.replaceOSDs = nil
+	inFlight, err := c.kv.GetStore(storeMigrationConfigMapName)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to get the osds being re-provisioned")
+	}
+	for nodeName, value := range inFlight {
+		ids, err := parseOSDIDs(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid osds to re-provision on node %q", nodeName)
+		}
+		if c.replaceOSDs == nil {
+			c.replaceOSDs = map[string][]int{}
+		}
+		c.replaceOSDs[nodeName] = ids
+		logger.Infof("resuming the re-provisioning of osds %v on node %q", ids, nodeName)
+	}
+	return nil
+}
+
+// completeStoreMigration forgets the destroyed osds of the nodes once their deployments exist again
+func (c *Cluster) completeStoreMigration() {
+	for nodeName, ids := range c.replaceOSDs {
+		provisioned := true
+		for _, id := range ids {
+			_, err := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).Get(fmt.Sprintf(osdAppNameFmt, id), metav1.GetOptions{})
+			if err != nil {
+				if !kerrors.IsNotFound(err) {
+					logger.Warningf("failed to get the deployment of osd %d. %v", id, err)
+				}
+				provisioned = false
+				break
+			}
+		}
+		if !provisioned {
+			logger.Warningf("osds %v of node %q are not re-provisioned yet", ids, nodeName)
+			continue
+		}
+		if err := c.kv.DeleteValue(storeMigrationConfigMapName, nodeName); err != nil {
+			logger.Warningf("failed to forget the re-provisioned osds of node %q. %v", nodeName, err)
+		}
+	}
+	c.replaceOSDs = nil
+}
+
+// formatOSDIDs formats the ids of osds as a comma separated list
+func formatOSDIDs(ids []int) string {
+	values := []string{}
+	for _, id := range ids {
+		values = append(values, strconv.Itoa(id))
+	}
+	return strings.Join(values, ",")
+}
+
+// parseOSDIDs parses a comma separated list of osd ids
+func parseOSDIDs(value string) ([]int, error) {
+	ids := []int{}
+	for _, v := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid osd id %q", v)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// storeMigrationCandidates returns the lvm osds on nodes whose store type or store settings differ from the spec, and
+// the number of osds by store type
+func (c *Cluster) storeMigrationCandidates() ([]storeMigrationOSD, map[string]int, error) {
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, AppName)}
+	deployments, err := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).List(listOpts)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list osd deployments")
+	}
+	if len(deployments.Items) == 0 {
+		return nil, nil, nil
+	}
+	metadata, err := cephclient.GetOSDMetadata(c.context, c.clusterInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	objectStores := map[int]string{}
+	for _, m := range metadata {
+		objectStores[m.ID] = m.ObjectStore
+	}
+	settings, err := c.kv.GetStore(storeSettingsConfigMapName)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, nil, errors.Wrap(err, "failed to get the store settings of the osds")
+	}
+
+	desiredType := storeType(&c.spec)
+	desiredSettings := storeSettingsHash(c.spec.Storage.Store.Settings)
+	storeTypes := map[string]int{}
+	pending := []storeMigrationOSD{}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		osds, err := c.getOSDInfo(d)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get the info of osd deployment %q", d.Name)
+		}
+		osd := osds[0]
+		objectStore, ok := objectStores[osd.ID]
+		if !ok {
+			logger.Debugf("no metadata reported by osd %d yet", osd.ID)
+			continue
+		}
+		storeTypes[objectStore]++
+		if objectStore == desiredType && settings[strconv.Itoa(osd.ID)] == desiredSettings {
+			continue
+		}
+		nodeName := d.Spec.Template.Spec.NodeSelector[v1.LabelHostname]
+		if _, ok := d.Labels[OSDOverPVCLabelKey]; ok || osd.CVMode != "lvm" || nodeName == "" {
+			logger.Debugf("skipping the store migration of osd %d, only the lvm osds on nodes can be re-provisioned", osd.ID)
+			continue
+		}
+		pending = append(pending, storeMigrationOSD{id: osd.ID, deploymentName: d.Name, nodeName: nodeName})
+	}
+	return pending, storeTypes, nil
+}
+
+// nextStoreMigrationDomain returns the first CRUSH bucket of the failure domain type with pending osds, and its
+// pending osds
+func (c *Cluster) nextStoreMigrationDomain(pending []storeMigrationOSD) (string, []storeMigrationOSD, error) {
+	failureDomain := c.spec.Storage.Store.FailureDomain
+	if failureDomain == "" {
+		failureDomain = defaultStoreFailureDomain
+	}
+	tree, err := cephclient.HostTree(c.context, c.clusterInfo)
+	if err != nil {
+		return "", nil, err
+	}
+	domains := map[string][]storeMigrationOSD{}
+	for _, osd := range pending {
+		domain := failureDomainOfOSD(tree, osd.id, failureDomain)
+		if domain == "" {
+			// the osds of a node which is not under a bucket of the failure domain are re-provisioned together
+			domain = osd.nodeName
+		}
+		domains[domain] = append(domains[domain], osd)
+	}
+	names := []string{}
+	for name := range domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names[0], domains[names[0]], nil
+}
+
+// failureDomainOfOSD returns the name of the CRUSH bucket of the given type containing an osd
+func failureDomainOfOSD(tree cephclient.OsdTree, osdID int, bucketType string) string {
+	parents := map[int]int{}
+	for _, node := range tree.Nodes {
+		for _, child := range node.Children {
+			parents[child] = node.ID
+		}
+	}
+	id := osdID
+	for {
+		parent, ok := parents[id]
+		if !ok {
+			return ""
+		}
+		for _, node := range tree.Nodes {
+			if node.ID == parent && node.Type == bucketType {
+				return node.Name
+			}
+		}
+		id = parent
+	}
+}
+
+// migratedOSDs returns the number of osds re-provisioned by the current migration
+func (c *Cluster) migratedOSDs() int {
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.context.Client.Get(context.TODO(), c.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		return 0
+	}
+	storage := cephCluster.Status.CephStorage
+	if storage == nil || storage.OSD == nil || storage.OSD.MigrationStatus == nil {
+		return 0
+	}
+	return storage.OSD.MigrationStatus.Migrated
+}
+
+// updateStoreStatus updates the store of the osds in the status of the CephCluster
+func (c *Cluster) updateStoreStatus(status *cephv1.OSDStatus) error {
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.context.Client.Get(context.TODO(), c.clusterInfo.NamespacedName(), cephCluster); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCluster resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve ceph cluster %q to update the osd store status", c.clusterInfo.NamespacedName().Name)
+	}
+	if cephCluster.Status.CephStorage == nil {
+		cephCluster.Status.CephStorage = &cephv1.CephStorage{}
+	}
+	cephCluster.Status.CephStorage.OSD = status
+	if err := opcontroller.UpdateStatus(c.context.Client, cephCluster); err != nil {
+		return errors.Wrapf(err, "failed to update cluster %q osd store status", c.clusterInfo.NamespacedName().Name)
+	}
+	return nil
+}
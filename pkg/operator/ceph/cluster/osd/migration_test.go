@@ -0,0 +1,237 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const fakeMigrationOSDTree = `{"nodes":[
+	{"id":-1,"name":"default","type":"root","children":[-2,-3]},
+	{"id":-2,"name":"node1","type":"host","children":[0,1]},
+	{"id":-3,"name":"node2","type":"host","children":[2]}]}`
+
+func newMigrationOSDDeployment(id int, nodeName string) *apps.Deployment {
+	return &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("rook-ceph-osd-%d", id),
+			Namespace: "ns",
+			Labels:    map[string]string{k8sutil.AppAttr: AppName, OsdIdLabelKey: fmt.Sprintf("%d", id)},
+		},
+		Spec: apps.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+			NodeSelector: map[string]string{v1.LabelHostname: nodeName},
+			Containers: []v1.Container{{
+				Args: []string{"--crush-location=root=default host=" + nodeName},
+				Env: []v1.EnvVar{
+					{Name: "ROOK_OSD_UUID", Value: fmt.Sprintf("osd-uuid-%d", id)},
+					{Name: "ROOK_BLOCK_PATH", Value: fmt.Sprintf("/dev/ceph-%d/osd-block", id)},
+				},
+			}},
+		}}},
+	}
+}
+
+func TestStoreSettingsHash(t *testing.T) {
+	assert.Equal(t, "", storeSettingsHash(nil))
+	hash := storeSettingsHash(map[string]string{"bluestore_min_alloc_size": "4096", "bluestore_compression_mode": "none"})
+	assert.NotEqual(t, "", hash)
+	assert.Equal(t, hash, storeSettingsHash(map[string]string{"bluestore_compression_mode": "none", "bluestore_min_alloc_size": "4096"}))
+	assert.NotEqual(t, hash, storeSettingsHash(map[string]string{"bluestore_min_alloc_size": "4096"}))
+}
+
+func TestFailureDomainOfOSD(t *testing.T) {
+	tree, err := cephclient.HostTree(&clusterd.Context{Executor: &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			return fakeMigrationOSDTree, nil
+		},
+	}}, cephclient.AdminClusterInfo("ns"))
+	assert.NoError(t, err)
+	assert.Equal(t, "node1", failureDomainOfOSD(tree, 1, "host"))
+	assert.Equal(t, "node2", failureDomainOfOSD(tree, 2, "host"))
+	assert.Equal(t, "default", failureDomainOfOSD(tree, 2, "root"))
+	assert.Equal(t, "", failureDomainOfOSD(tree, 2, "rack"))
+	assert.Equal(t, "", failureDomainOfOSD(tree, 5, "host"))
+}
+
+func TestStartStoreMigration(t *testing.T) {
+	clusterInfo := cephclient.AdminClusterInfo("ns")
+	clusterInfo.SetName("rook-ceph")
+	objectStores := map[int]string{0: "bluestore", 1: "bluestore", 2: "bluestore"}
+	destroyed := []string{}
+	clean := true
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			switch {
+			case args[0] == "status":
+				if !clean {
+					return `{"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":90},{"state_name":"active+recovering","count":10}]}}`, nil
+				}
+				return `{"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			case args[0] == "osd" && args[1] == "metadata":
+				metadata := []string{}
+				for id := 0; id < 3; id++ {
+					metadata = append(metadata, fmt.Sprintf(`{"id":%d,"osd_objectstore":%q}`, id, objectStores[id]))
+				}
+				return "[" + strings.Join(metadata, ",") + "]", nil
+			case args[0] == "osd" && args[1] == "tree":
+				return fakeMigrationOSDTree, nil
+			case args[0] == "osd" && args[1] == "ok-to-stop":
+				return "", nil
+			case args[0] == "osd" && args[1] == "destroy":
+				destroyed = append(destroyed, args[2])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "ns"}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{})
+	cl := fake.NewFakeClientWithScheme(s, []runtime.Object{cephCluster}...)
+	clientset := testop.New(t, 1)
+	for id, node := range []string{"node1", "node1", "node2"} {
+		_, err := clientset.AppsV1().Deployments("ns").Create(newMigrationOSDDeployment(id, node))
+		assert.NoError(t, err)
+	}
+	spec := cephv1.ClusterSpec{}
+	spec.Storage.Store.Type = "bluestore-rdr"
+	c := New(&clusterd.Context{Clientset: clientset, Client: cl, Executor: executor}, clusterInfo, spec, "myversion")
+	osdStatus := func() *cephv1.OSDStatus {
+		cephCluster := &cephv1.CephCluster{}
+		assert.NoError(t, cl.Get(context.TODO(), clusterInfo.NamespacedName(), cephCluster))
+		return cephCluster.Status.CephStorage.OSD
+	}
+
+	// the osds are not re-provisioned before the update of the store is confirmed
+	migrating, err := c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.False(t, migrating)
+	assert.Equal(t, map[string]int{"bluestore": 3}, osdStatus().StoreType)
+	assert.Equal(t, 3, osdStatus().MigrationStatus.Pending)
+	assert.Empty(t, destroyed)
+
+	// the osds of the first host are destroyed to be re-provisioned with the same ids
+	c.spec.Storage.Store.UpdateStore = UpdateStoreConfirmation
+	migrating, err = c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.True(t, migrating)
+	assert.True(t, c.StoreMigrationPending)
+	assert.Equal(t, map[string][]int{"node1": {0, 1}}, c.replaceOSDs)
+	assert.Equal(t, []string{"0", "1"}, destroyed)
+	assert.Equal(t, 2, osdStatus().MigrationStatus.Migrated)
+	assert.Equal(t, "node1", osdStatus().MigrationStatus.FailureDomain)
+	deployments, err := clientset.AppsV1().Deployments("ns").List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deployments.Items))
+	inFlight, err := c.kv.GetStore(storeMigrationConfigMapName)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"node1": "0,1"}, inFlight)
+
+	// the next host waits for the destroyed osds to be re-provisioned
+	migrating, err = c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.False(t, migrating)
+	assert.True(t, c.StoreMigrationPending)
+	assert.Equal(t, []string{"0", "1"}, destroyed)
+
+	// the destroyed osds are still re-provisioned after a restart of the operator
+	c = New(&clusterd.Context{Clientset: clientset, Client: cl, Executor: executor}, clusterInfo, c.spec, "myversion")
+	assert.NoError(t, c.loadStoreMigration())
+	assert.Equal(t, map[string][]int{"node1": {0, 1}}, c.replaceOSDs)
+	c.completeStoreMigration()
+	inFlight, err = c.kv.GetStore(storeMigrationConfigMapName)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"node1": "0,1"}, inFlight)
+
+	// the next host is re-provisioned once the osds of the first host are back with the new store
+	for id := 0; id < 2; id++ {
+		_, err := clientset.AppsV1().Deployments("ns").Create(newMigrationOSDDeployment(id, "node1"))
+		assert.NoError(t, err)
+		objectStores[id] = "bluestore-rdr"
+	}
+	assert.NoError(t, c.loadStoreMigration())
+	c.completeStoreMigration()
+	assert.Nil(t, c.replaceOSDs)
+	inFlight, err = c.kv.GetStore(storeMigrationConfigMapName)
+	assert.NoError(t, err)
+	assert.Empty(t, inFlight)
+	migrating, err = c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.True(t, migrating)
+	assert.Equal(t, map[string][]int{"node2": {2}}, c.replaceOSDs)
+	assert.Equal(t, 3, osdStatus().MigrationStatus.Migrated)
+	assert.Equal(t, map[string]int{"bluestore": 1, "bluestore-rdr": 2}, osdStatus().StoreType)
+
+	// the migration completes when all the osds have the new store
+	_, err = clientset.AppsV1().Deployments("ns").Create(newMigrationOSDDeployment(2, "node2"))
+	assert.NoError(t, err)
+	objectStores[2] = "bluestore-rdr"
+	c.completeStoreMigration()
+	migrating, err = c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.False(t, migrating)
+	assert.False(t, c.StoreMigrationPending)
+	assert.Nil(t, c.replaceOSDs)
+	assert.Nil(t, osdStatus().MigrationStatus)
+	assert.Equal(t, map[string]int{"bluestore-rdr": 3}, osdStatus().StoreType)
+
+	// the osds are re-provisioned when the store settings change, unless created with the settings, once the pgs
+	// are clean
+	c.spec.Storage.Store.Settings = map[string]string{"bluestore_min_alloc_size": "4096"}
+	c.recordStoreSettings(0)
+	c.recordStoreSettings(1)
+	clean = false
+	migrating, err = c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.False(t, migrating)
+	assert.True(t, c.StoreMigrationPending)
+	assert.Equal(t, "waiting for the pgs to be clean", osdStatus().MigrationStatus.Message)
+	assert.Equal(t, []string{"0", "1", "2"}, destroyed)
+
+	clean = true
+	migrating, err = c.startStoreMigration()
+	assert.NoError(t, err)
+	assert.True(t, migrating)
+	assert.Equal(t, map[string][]int{"node2": {2}}, c.replaceOSDs)
+}
+
+func TestParseOSDIDs(t *testing.T) {
+	ids, err := parseOSDIDs(formatOSDIDs([]int{3, 0, 12}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 0, 12}, ids)
+
+	_, err = parseOSDIDs("1,a")
+	assert.Error(t, err)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// AllowNewDevicesAnnotation is the annotation of the nodes listing the new devices approved for OSDs, by name or
+	// path separated by commas, or "all"
+	AllowNewDevicesAnnotation = "rook.io/allow-new-devices"
+	// the config map recording when the new devices of each node were found, by node
+	newDevicesConfigMapName = "rook-ceph-osd-new-devices"
+)
+
+// newDevice is a new empty device of a node which had OSDs when the device was found
+type newDevice struct {
+	FirstSeen time.Time `json:"firstSeen"`
+	// Released is whether the device was given to the prepare job of the node
+	Released bool `json:"released,omitempty"`
+}
+
+// newDevicesPolicy is the parsed spec of the new devices
+type newDevicesPolicy struct {
+	quarantine      time.Duration
+	requireApproval bool
+	filter          *regexp.Regexp
+}
+
+// newNewDevicesPolicy parses the spec of the new devices, it returns nil when the new devices are provisioned
+// without checks
+func newNewDevicesPolicy(spec cephv1.NewDevicesSpec) (*newDevicesPolicy, error) {
+	if spec.QuarantinePeriod == "" && !spec.RequireApproval {
+		return nil, nil
+	}
+	policy := &newDevicesPolicy{requireApproval: spec.RequireApproval}
+	if spec.QuarantinePeriod != "" {
+		quarantine, err := time.ParseDuration(spec.QuarantinePeriod)
+		if err != nil || quarantine < 0 {
+			return nil, errors.Errorf("invalid quarantine period %q of the new devices", spec.QuarantinePeriod)
+		}
+		policy.quarantine = quarantine
+	}
+	if spec.DeviceFilter != "" {
+		filter, err := regexp.Compile(spec.DeviceFilter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid device filter %q of the new devices", spec.DeviceFilter)
+		}
+		policy.filter = filter
+	}
+	return policy, nil
+}
+
+// approved returns whether an OSD can be provisioned on a new device of a node
+func (p *newDevicesPolicy) approved(node *v1.Node, device string, state newDevice, now time.Time) bool {
+	if state.Released {
+		return true
+	}
+	if now.Sub(state.FirstSeen) < p.quarantine {
+		return false
+	}
+	if !p.requireApproval {
+		return true
+	}
+	name := strings.TrimPrefix(device, "/dev/")
+	if p.filter != nil && p.filter.MatchString(name) {
+		return true
+	}
+	if node == nil {
+		return false
+	}
+	for _, allowed := range strings.Split(node.Annotations[AllowNewDevicesAnnotation], ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "all" || allowed == name || allowed == device {
+			return true
+		}
+	}
+	return false
+}
+
+// dynamicDeviceSelection returns whether the devices of a node are selected when the prepare job runs, rather than
+// listed in the spec
+func dynamicDeviceSelection(osdProps *osdProperties) bool {
+	return len(osdProps.devices) == 0 && (osdProps.selection.GetUseAllDevices() || osdProps.selection.DeviceFilter != "" ||
+		osdProps.selection.DevicePathFilter != "")
+}
+
+// selectedByNode returns whether a device of the inventory is selected by the device selection of the node
+func selectedByNode(selection rookv1.Selection, device cephv1.InventoryDevice) bool {
+	if selection.DeviceFilter != "" {
+		matched, err := regexp.MatchString(selection.DeviceFilter, device.Name)
+		return err == nil && matched
+	}
+	if selection.DevicePathFilter != "" {
+		for _, devicePath := range append([]string{device.Path}, device.DevLinks...) {
+			if matched, err := regexp.MatchString(selection.DevicePathFilter, devicePath); err == nil && matched {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// checkNewDevices restricts the devices a prepare job can use on a node which already has OSDs to the new devices
+// which passed the quarantine and were approved. The new empty devices of the node are found in the device inventory
+// maintained by the discover daemon of the node. The nodes without OSDs are provisioned with the devices of their
+// selection.
+func (c *Cluster) checkNewDevices(osdProps *osdProperties) error {
+	policy, err := newNewDevicesPolicy(c.spec.NewDevices)
+	if err != nil || policy == nil || !dynamicDeviceSelection(osdProps) {
+		return err
+	}
+
+	hostname := osdProps.crushHostname
+	nodeName, err := k8sutil.GetNodeNameFromHostname(c.context.Clientset, hostname)
+	if err != nil {
+		nodeName = hostname
+	}
+	node, err := c.context.Clientset.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get node %q", nodeName)
+	}
+	inventory, err := c.context.RookClientset.CephV1().CephDeviceInventories(os.Getenv(k8sutil.PodNamespaceEnvVar)).Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Warningf("no device inventory for node %q, the new devices of the node cannot be checked. the discover daemon must be enabled", nodeName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get the device inventory of node %q", nodeName)
+	}
+	if inventory.Status == nil {
+		return nil
+	}
+
+	hasOSDs := false
+	candidates := []cephv1.InventoryDevice{}
+	for _, device := range inventory.Status.Devices {
+		if device.InUseByOSD {
+			hasOSDs = true
+			continue
+		}
+		if device.Empty && device.Type == "disk" && selectedByNode(osdProps.selection, device) {
+			candidates = append(candidates, device)
+		}
+	}
+	if !hasOSDs {
+		// the first osds of a node are provisioned on the devices of its selection
+		return nil
+	}
+
+	states, err := c.newDevices(hostname)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	current := map[string]newDevice{}
+	approved := []string{}
+	added := []string{}
+	for _, device := range candidates {
+		state, ok := states[device.Path]
+		if !ok {
+			state = newDevice{FirstSeen: now}
+			logger.Infof("found new device %q on node %q", device.Path, nodeName)
+		}
+		if !policy.approved(node, device.Path, state, now) {
+			if now.Sub(state.FirstSeen) < policy.quarantine {
+				logger.Infof("new device %q of node %q is quarantined until %s", device.Path, nodeName, state.FirstSeen.Add(policy.quarantine).Format(time.RFC3339))
+			} else {
+				logger.Infof("new device %q of node %q is waiting for approval with the %q annotation of the node", device.Path, nodeName, AllowNewDevicesAnnotation)
+			}
+			current[device.Path] = state
+			continue
+		}
+		if !state.Released {
+			state.Released = true
+			added = append(added, device.Path)
+		}
+		current[device.Path] = state
+		approved = append(approved, device.Name)
+	}
+	if err := c.setNewDevices(hostname, current); err != nil {
+		return err
+	}
+
+	sort.Strings(approved)
+	osdProps.selection = rookv1.Selection{}
+	osdProps.devices = []rookv1.Device{}
+	for _, name := range approved {
+		osdProps.devices = append(osdProps.devices, rookv1.Device{Name: name})
+	}
+	if len(added) > 0 {
+		sort.Strings(added)
+		logger.Infof("provisioning osds on the new devices %v of node %q", added, nodeName)
+		opcontroller.RecordOwnerEvent(c.Recorder, c.clusterInfo.Namespace, c.clusterInfo.OwnerRef, v1.EventTypeNormal,
+			opcontroller.EventReasonNewDevicesAdded, "provisioning osds on the new devices %s of node %q", strings.Join(added, ", "), nodeName)
+	}
+	return nil
+}
+
+// newDevices returns the new devices of a node by path
+func (c *Cluster) newDevices(hostname string) (map[string]newDevice, error) {
+	return getNewDevices(c.context.Clientset, c.clusterInfo.Namespace, hostname)
+}
+
+func (c *Cluster) setNewDevices(hostname string, devices map[string]newDevice) error {
+	if len(devices) == 0 {
+		if err := c.kv.DeleteValue(newDevicesConfigMapName, hostname); err != nil {
+			return errors.Wrapf(err, "failed to remove the new devices of node %q", hostname)
+		}
+		return nil
+	}
+	data, err := json.Marshal(devices)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the new devices")
+	}
+	if err := c.kv.SetValue(newDevicesConfigMapName, hostname, string(data)); err != nil {
+		return errors.Wrapf(err, "failed to record the new devices of node %q", hostname)
+	}
+	return nil
+}
+
+func getNewDevices(clientset kubernetes.Interface, namespace, hostname string) (map[string]newDevice, error) {
+	devices := map[string]newDevice{}
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(newDevicesConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return devices, nil
+		}
+		return nil, errors.Wrap(err, "failed to get the new devices")
+	}
+	if data := cm.Data[hostname]; data != "" {
+		if err := json.Unmarshal([]byte(data), &devices); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the new devices of node %q", hostname)
+		}
+	}
+	return devices, nil
+}
+
+// NewDevicesReady returns whether a new device of the node passed its quarantine and was approved since the last
+// orchestration, so the osds of the node must be provisioned again
+func NewDevicesReady(clientset kubernetes.Interface, cluster *cephv1.CephCluster, node *v1.Node) bool {
+	policy, err := newNewDevicesPolicy(cluster.Spec.NewDevices)
+	if err != nil || policy == nil {
+		return false
+	}
+	devices, err := getNewDevices(clientset, cluster.Namespace, k8sutil.GetNormalizedHostname(*node))
+	if err != nil {
+		logger.Debugf("failed to get the new devices of node %q. %v", node.Name, err)
+		return false
+	}
+	now := time.Now().UTC()
+	for device, state := range devices {
+		if !state.Released && policy.approved(node, device, state, now) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNewDevicesPolicy(t *testing.T) {
+	policy, err := newNewDevicesPolicy(cephv1.NewDevicesSpec{})
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+
+	_, err = newNewDevicesPolicy(cephv1.NewDevicesSpec{QuarantinePeriod: "1 day"})
+	assert.Error(t, err)
+	_, err = newNewDevicesPolicy(cephv1.NewDevicesSpec{RequireApproval: true, DeviceFilter: "sd["})
+	assert.Error(t, err)
+
+	policy, err = newNewDevicesPolicy(cephv1.NewDevicesSpec{QuarantinePeriod: "24h", RequireApproval: true, DeviceFilter: "^nvme"})
+	assert.NoError(t, err)
+	now := time.Now()
+	node := &v1.Node{}
+	old := newDevice{FirstSeen: now.Add(-25 * time.Hour)}
+
+	// quarantined
+	assert.False(t, policy.approved(node, "/dev/nvme1n1", newDevice{FirstSeen: now.Add(-time.Hour)}, now))
+	// approved by the filter
+	assert.True(t, policy.approved(node, "/dev/nvme1n1", old, now))
+	// waiting for the annotation
+	assert.False(t, policy.approved(node, "/dev/sdc", old, now))
+	node.Annotations = map[string]string{AllowNewDevicesAnnotation: "sdb, /dev/sdc"}
+	assert.True(t, policy.approved(node, "/dev/sdc", old, now))
+	node.Annotations[AllowNewDevicesAnnotation] = "all"
+	assert.True(t, policy.approved(node, "/dev/sdd", old, now))
+	// released devices stay approved
+	assert.True(t, policy.approved(&v1.Node{}, "/dev/sde", newDevice{FirstSeen: now, Released: true}, now))
+}
+
+func TestCheckNewDevices(t *testing.T) {
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-ceph-system")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{v1.LabelHostname: "node1"}}}
+	clientset := fake.NewSimpleClientset(node)
+	inventory := &cephv1.CephDeviceInventory{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: "rook-ceph-system"},
+		Spec:       cephv1.DeviceInventorySpec{NodeName: "node1"},
+		Status: &cephv1.DeviceInventoryStatus{Devices: []cephv1.InventoryDevice{
+			{Name: "sda", Path: "/dev/sda", Type: "disk", InUseByOSD: true, OSDIDs: []int{0}},
+			{Name: "sdb", Path: "/dev/sdb", Type: "disk", Empty: true},
+			{Name: "sdc", Path: "/dev/sdc", Type: "disk", Empty: true},
+			{Name: "sdd", Path: "/dev/sdd", Type: "disk"},
+		}},
+	}
+	rookClientset := rookfake.NewSimpleClientset(inventory)
+	context := &clusterd.Context{Clientset: clientset, RookClientset: rookClientset}
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", OwnerRef: metav1.OwnerReference{Name: "my-cluster"}}
+	c := New(context, clusterInfo, cephv1.ClusterSpec{}, "rook/rook:myversion")
+	recorder := record.NewFakeRecorder(10)
+	c.Recorder = recorder
+	useAllDevices := true
+	props := func() *osdProperties {
+		return &osdProperties{crushHostname: "node1", selection: rookv1.Selection{UseAllDevices: &useAllDevices}}
+	}
+
+	// the devices are not checked by default
+	osdProps := props()
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.True(t, osdProps.selection.GetUseAllDevices())
+
+	// the new devices are quarantined
+	c.spec.NewDevices = cephv1.NewDevicesSpec{QuarantinePeriod: "1h", RequireApproval: true}
+	osdProps = props()
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.False(t, osdProps.selection.GetUseAllDevices())
+	assert.Empty(t, osdProps.devices)
+	devices, err := c.newDevices("node1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(devices))
+	assert.False(t, devices["/dev/sdb"].Released)
+	assert.False(t, NewDevicesReady(clientset, &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns"}, Spec: c.spec}, node))
+
+	// after the quarantine, the device allowed by the annotation is provisioned
+	devices["/dev/sdb"] = newDevice{FirstSeen: time.Now().Add(-2 * time.Hour)}
+	devices["/dev/sdc"] = newDevice{FirstSeen: time.Now().Add(-2 * time.Hour)}
+	assert.NoError(t, c.setNewDevices("node1", devices))
+	node.Annotations = map[string]string{AllowNewDevicesAnnotation: "sdc"}
+	_, err = clientset.CoreV1().Nodes().Update(node)
+	assert.NoError(t, err)
+	assert.True(t, NewDevicesReady(clientset, &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns"}, Spec: c.spec}, node))
+	osdProps = props()
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.Equal(t, []rookv1.Device{{Name: "sdc"}}, osdProps.devices)
+	assert.Contains(t, <-recorder.Events, "NewDevicesAdded provisioning osds on the new devices /dev/sdc of node \"node1\"")
+	devices, err = c.newDevices("node1")
+	assert.NoError(t, err)
+	assert.True(t, devices["/dev/sdc"].Released)
+	assert.False(t, NewDevicesReady(clientset, &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns"}, Spec: c.spec}, node))
+
+	// the released device is kept until it is used by an osd, without new event
+	osdProps = props()
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.Equal(t, []rookv1.Device{{Name: "sdc"}}, osdProps.devices)
+	assert.Empty(t, recorder.Events)
+	inventory.Status.Devices[2].InUseByOSD = true
+	_, err = rookClientset.CephV1().CephDeviceInventories("rook-ceph-system").Update(inventory)
+	assert.NoError(t, err)
+	osdProps = props()
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.Empty(t, osdProps.devices)
+	devices, err = c.newDevices("node1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(devices))
+
+	// a node without osds is provisioned with all its devices
+	for i := range inventory.Status.Devices {
+		inventory.Status.Devices[i].InUseByOSD = false
+	}
+	_, err = rookClientset.CephV1().CephDeviceInventories("rook-ceph-system").Update(inventory)
+	assert.NoError(t, err)
+	osdProps = props()
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.True(t, osdProps.selection.GetUseAllDevices())
+
+	// the devices listed in the spec are not checked
+	osdProps = &osdProperties{crushHostname: "node1", devices: []rookv1.Device{{Name: "sdd"}}}
+	assert.NoError(t, c.checkNewDevices(osdProps))
+	assert.Equal(t, []rookv1.Device{{Name: "sdd"}}, osdProps.devices)
+}
@@ -46,6 +46,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/tools/record"
 )
 
 var (
@@ -81,6 +82,12 @@ type Cluster struct {
 	spec         cephv1.ClusterSpec
 	ValidStorage rookv1.StorageScopeSpec // valid subset of `Storage`, computed at runtime
 	kv           *k8sutil.ConfigMapKVStore
+	// the ids of the osds re-provisioned by the prepare jobs of the nodes during a store migration
+	replaceOSDs map[string][]int
+	// StoreMigrationPending is set by Start when osds remain to be re-provisioned to the store of the spec
+	StoreMigrationPending bool
+	// Recorder emits the events of the osds on the CephCluster, like the provisioning of new devices
+	Recorder record.EventRecorder
 }
 
 // New creates an instance of the OSD manager
@@ -108,6 +115,13 @@ type OSDInfo struct {
 	LVBackedPV    bool   `json:"lv-backed-pv"`
 	CVMode        string `json:"lv-mode"`
 	Store         string `json:"store"`
+	// DeviceID is the name of the persistent path of the device of the OSD, like its by-id link
+	DeviceID string `json:"device-id"`
+	// DeviceClass is the class of the device of the OSD matched by the device classes of the performance settings
+	DeviceClass string `json:"device-class"`
+	// EncryptionKeyID is the LUKS uuid of the device of the OSD when it is encrypted with a key of the key management
+	// service, the device is opened by rook before the OSD is activated
+	EncryptionKeyID string `json:"encryption-key-id,omitempty"`
 }
 
 // OrchestrationStatus represents the status of an OSD orchestration
@@ -135,6 +149,8 @@ type osdProperties struct {
 	tuneSlowDeviceClass bool
 	schedulerName       string
 	crushDeviceClass    string
+	// the number of osds of each device of the node by the id of the device
+	osdsOnDevices map[string]int
 
 	// Drive Groups which apply to the node
 	driveGroups cephv1.DriveGroupsSpec
@@ -163,6 +179,27 @@ func (c *Cluster) Start() error {
 		logger.Warningf("useAllNodes is set to false and no nodes, driveGroups, storageClassDevicesets or volumeSources are specified, no OSD pods are going to be created")
 	}
 
+	if err := ValidateCrushTopology(c.spec.CrushTopology); err != nil {
+		return errors.Wrap(err, "invalid crush topology")
+	}
+
+	if err := c.applyStoreSettings(); err != nil {
+		return errors.Wrap(err, "failed to apply the store settings of the osds")
+	}
+
+	if err := c.applyScrubbingSettings(); err != nil {
+		return errors.Wrap(err, "failed to apply the scrubbing settings of the osds")
+	}
+
+	if err := c.applyFullRatios(); err != nil {
+		return errors.Wrap(err, "failed to apply the full ratios of the osds")
+	}
+
+	// the osds destroyed by a store migration are re-provisioned by the first provisioning of their nodes
+	if err := c.loadStoreMigration(); err != nil {
+		return errors.Wrap(err, "failed to load the osds to re-provision")
+	}
+
 	// start the jobs to provision the OSD devices
 	logger.Infof("start provisioning the osds on pvcs, if needed")
 	c.startProvisioningOverPVCs(config)
@@ -175,6 +212,24 @@ func (c *Cluster) Start() error {
 			len(config.errorMessages), c.clusterInfo.Namespace, strings.Join(config.errorMessages, "\n"))
 	}
 
+	c.completeStoreMigration()
+
+	// re-provision the osds of one failure domain when their store differs from the spec, the next failure domains
+	// are re-provisioned by the next orchestrations
+	migrating, err := c.startStoreMigration()
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate the store of the osds")
+	}
+	if migrating {
+		config := c.newProvisionConfig()
+		c.startProvisioningOverNodes(config)
+		if len(config.errorMessages) > 0 {
+			return errors.Errorf("%d failures encountered while re-provisioning osds in namespace %s: %+v",
+				len(config.errorMessages), c.clusterInfo.Namespace, strings.Join(config.errorMessages, "\n"))
+		}
+		c.completeStoreMigration()
+	}
+
 	// The following block is used to apply any command(s) required by an upgrade
 	// The block below handles the upgrade from Mimic to Nautilus.
 	// This should only run before Octopus
@@ -367,6 +422,10 @@ func (c *Cluster) startNodeStorageProvisioners(config *provisionConfig) {
 			storeConfig:    storeConfig,
 			metadataDevice: metadataDevice,
 		}
+		if err := c.checkNewDevices(&osdProps); err != nil {
+			config.addError("failed to check the new devices of node %q. %v", n.Name, err)
+			continue
+		}
 		c.makeAndRunJob(n.Name, "provision", osdProps, config)
 	}
 }
@@ -471,6 +530,10 @@ func (c *Cluster) startOSDDaemonsOnPVC(pvcName string, config *provisionConfig,
 		config.addError(fmt.Sprintf("%v", err))
 		return
 	}
+	if !osdProps.portable {
+		// the osds of a pvc that is not portable stay on their node and follow the crush location of its labels
+		osds = c.reconcileCrushLocation(osdProps.crushHostname, osds)
+	}
 
 	// start osds
 	for _, osd := range osds {
@@ -503,8 +566,12 @@ func (c *Cluster) startOSDDaemonsOnPVC(pvcName string, config *provisionConfig,
 		_, createErr := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).Create(dp)
 		if createErr != nil {
 			if kerrors.IsAlreadyExists(createErr) {
+				if c.isStoppedForMaintenance(dp.Name) {
+					logger.Infof("osd %d is stopped for the maintenance of its node, not updating its deployment", osd.ID)
+					continue
+				}
 				logger.Infof("deployment for osd %d already exists. updating if needed", osd.ID)
-				if err = updateDeploymentAndWait(c.context, c.clusterInfo, dp, opconfig.OsdType, strconv.Itoa(osd.ID), c.spec.SkipUpgradeChecks, c.spec.ContinueUpgradeAfterChecksEvenIfNotHealthy); err != nil {
+				if err = updateDeploymentAndWait(c.context, c.clusterInfo, dp, opconfig.OsdType, strconv.Itoa(osd.ID), client.DaemonUpgradeChecks(&c.spec, opconfig.OsdType)); err != nil {
 					logger.Errorf("failed to update osd deployment %d. %v", osd.ID, err)
 				}
 			} else {
@@ -515,7 +582,7 @@ func (c *Cluster) startOSDDaemonsOnPVC(pvcName string, config *provisionConfig,
 		}
 
 		if createErr != nil && kerrors.IsAlreadyExists(createErr) {
-			if err = updateDeploymentAndWait(c.context, c.clusterInfo, dp, opconfig.OsdType, strconv.Itoa(osd.ID), c.spec.SkipUpgradeChecks, c.spec.ContinueUpgradeAfterChecksEvenIfNotHealthy); err != nil {
+			if err = updateDeploymentAndWait(c.context, c.clusterInfo, dp, opconfig.OsdType, strconv.Itoa(osd.ID), client.DaemonUpgradeChecks(&c.spec, opconfig.OsdType)); err != nil {
 				logger.Errorf("failed to update osd deployment %d. %v", osd.ID, err)
 			}
 		}
@@ -525,7 +592,7 @@ func (c *Cluster) startOSDDaemonsOnPVC(pvcName string, config *provisionConfig,
 
 func (c *Cluster) startOSDDaemonsOnNode(nodeName string, config *provisionConfig, configMap *v1.ConfigMap, status *OrchestrationStatus) {
 
-	osds := status.OSDs
+	osds := c.reconcileCrushLocation(nodeName, status.OSDs)
 	logger.Infof("starting %d osd daemons on node %s", len(osds), nodeName)
 
 	// fully resolve the storage config and resources for this node
@@ -544,6 +611,7 @@ func (c *Cluster) startOSDDaemonsOnNode(nodeName string, config *provisionConfig
 		resources:      n.Resources,
 		storeConfig:    storeConfig,
 		metadataDevice: metadataDevice,
+		osdsOnDevices:  osdsOnDevices(osds),
 	}
 
 	// start osds
@@ -577,8 +645,12 @@ func (c *Cluster) startOSDDaemonsOnNode(nodeName string, config *provisionConfig
 		_, createErr := c.context.Clientset.AppsV1().Deployments(c.clusterInfo.Namespace).Create(dp)
 		if createErr != nil {
 			if kerrors.IsAlreadyExists(createErr) {
+				if c.isStoppedForMaintenance(dp.Name) {
+					logger.Infof("osd %d is stopped for the maintenance of node %q, not updating its deployment", osd.ID, n.Name)
+					continue
+				}
 				logger.Debugf("deployment for osd %d already exists. updating if needed", osd.ID)
-				if err = updateDeploymentAndWait(c.context, c.clusterInfo, dp, opconfig.OsdType, strconv.Itoa(osd.ID), c.spec.SkipUpgradeChecks, c.spec.ContinueUpgradeAfterChecksEvenIfNotHealthy); err != nil {
+				if err = updateDeploymentAndWait(c.context, c.clusterInfo, dp, opconfig.OsdType, strconv.Itoa(osd.ID), client.DaemonUpgradeChecks(&c.spec, opconfig.OsdType)); err != nil {
 					logger.Errorf("failed to update osd deployment %d. %v", osd.ID, err)
 				}
 			} else {
@@ -588,6 +660,7 @@ func (c *Cluster) startOSDDaemonsOnNode(nodeName string, config *provisionConfig
 			}
 		} else {
 			logger.Infof("created deployment for osd %d", osd.ID)
+			c.recordStoreSettings(osd.ID)
 		}
 	}
 }
@@ -730,6 +803,8 @@ func (c *Cluster) getOSDInfo(d *apps.Deployment) ([]OSDInfo, error) {
 		return []OSDInfo{}, errors.Wrap(err, "error parsing ceph-osd-id")
 	}
 	osd.ID = osdID
+	osd.DeviceID = d.Labels[OSDDeviceIDLabelKey]
+	osd.DeviceClass = d.Labels[OSDDeviceClassLabelKey]
 
 	for _, envVar := range d.Spec.Template.Spec.Containers[0].Env {
 		if envVar.Name == "ROOK_OSD_UUID" {
@@ -751,6 +826,9 @@ func (c *Cluster) getOSDInfo(d *apps.Deployment) ([]OSDInfo, error) {
 		if envVar.Name == osdMetadataDeviceEnvVarName {
 			osd.MetadataPath = envVar.Value
 		}
+		if envVar.Name == encryptionKeyIDVarName {
+			osd.EncryptionKeyID = envVar.Value
+		}
 	}
 
 	// If CVMode is empty, this likely means we upgraded Rook
@@ -771,7 +849,7 @@ func (c *Cluster) getOSDInfo(d *apps.Deployment) ([]OSDInfo, error) {
 	}
 
 	if !locationFound {
-		location, err := getLocationFromPod(c.context.Clientset, d)
+		location, err := getLocationFromPod(c.context.Clientset, d, c.spec.CrushTopology.Labels)
 		if err != nil {
 			logger.Errorf("failed to get location. %v", err)
 		} else {
@@ -786,7 +864,7 @@ func (c *Cluster) getOSDInfo(d *apps.Deployment) ([]OSDInfo, error) {
 	return []OSDInfo{osd}, nil
 }
 
-func getLocationFromPod(clientset kubernetes.Interface, d *apps.Deployment) (string, error) {
+func getLocationFromPod(clientset kubernetes.Interface, d *apps.Deployment, topologyLabels []cephv1.CrushTopologyLabelSpec) (string, error) {
 	pods, err := clientset.CoreV1().Pods(d.Namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", OsdIdLabelKey, d.Labels[OsdIdLabelKey])})
 	if err != nil || len(pods.Items) == 0 {
 		return "", err
@@ -803,10 +881,10 @@ func getLocationFromPod(clientset kubernetes.Interface, d *apps.Deployment) (str
 			hostName = pvcName
 		}
 	}
-	return GetLocationWithNode(clientset, nodeName, hostName)
+	return GetLocationWithNode(clientset, nodeName, hostName, topologyLabels)
 }
 
-func GetLocationWithNode(clientset kubernetes.Interface, nodeName string, crushHostname string) (string, error) {
+func GetLocationWithNode(clientset kubernetes.Interface, nodeName string, crushHostname string, topologyLabels []cephv1.CrushTopologyLabelSpec) (string, error) {
 
 	node, err := getNode(clientset, nodeName)
 	if err != nil {
@@ -828,7 +906,7 @@ func GetLocationWithNode(clientset kubernetes.Interface, nodeName string, crushH
 	locArgs := []string{"root=default", fmt.Sprintf("host=%s", hostName)}
 
 	nodeLabels := node.GetLabels()
-	UpdateLocationWithNodeLabels(&locArgs, nodeLabels)
+	UpdateLocationWithNodeLabels(&locArgs, nodeLabels, topologyLabels)
 
 	loc := strings.Join(locArgs, " ")
 	logger.Infof("CRUSH location=%s", loc)
@@ -856,9 +934,9 @@ func getNode(clientset kubernetes.Interface, nodeName string) (*corev1.Node, err
 	return node, nil
 }
 
-func UpdateLocationWithNodeLabels(location *[]string, nodeLabels map[string]string) {
+func UpdateLocationWithNodeLabels(location *[]string, nodeLabels map[string]string, topologyLabels []cephv1.CrushTopologyLabelSpec) {
 
-	topology := ExtractOSDTopologyFromLabels(nodeLabels)
+	topology := ExtractCrushTopologyFromLabels(nodeLabels, topologyLabels)
 
 	keys := make([]string, 0, len(topology))
 	for k := range topology {
@@ -0,0 +1,274 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	hugePagesVolumeName = "hugepages"
+	hugePagesMountPath  = "/dev/hugepages"
+	// the medium of the hugepages of a size, supported since kubernetes 1.19
+	hugePagesMediumPrefix = "HugePages-"
+)
+
+// validateOSDPerformance checks the performance settings against the resources of an osd
+func validateOSDPerformance(spec cephv1.OSDPerformanceSpec, resources v1.ResourceRequirements) error {
+	if spec.StaticCPUs {
+		if _, ok := cpuQuantity(resources); !ok {
+			return errors.New("static CPUs require the CPU requests or limits of the OSDs")
+		}
+		if _, ok := memoryQuantity(resources); !ok {
+			return errors.New("static CPUs require the memory requests or limits of the OSDs")
+		}
+	}
+	if spec.NUMANode != nil && *spec.NUMANode < 0 {
+		return errors.Errorf("invalid NUMA node %d", *spec.NUMANode)
+	}
+	if err := validateDeviceClasses(spec.DeviceClasses); err != nil {
+		return err
+	}
+	if spec.BluestoreCacheSize != nil {
+		if spec.BluestoreCacheSize.Sign() <= 0 {
+			return errors.Errorf("invalid bluestore cache size %q", spec.BluestoreCacheSize.String())
+		}
+		if memory, ok := resources.Limits[v1.ResourceMemory]; ok && spec.BluestoreCacheSize.Cmp(memory) >= 0 {
+			return errors.Errorf("bluestore cache size %q must be lower than the memory limit %q of the OSDs", spec.BluestoreCacheSize.String(), memory.String())
+		}
+	}
+	return nil
+}
+
+// applyOSDPerformance returns the resources of an osd with the requests and the limits of the Guaranteed QoS class
+// when the CPUs are static. The resources are not changed otherwise.
+func applyOSDPerformance(spec cephv1.OSDPerformanceSpec, resources v1.ResourceRequirements) v1.ResourceRequirements {
+	if !spec.StaticCPUs {
+		return resources
+	}
+	guaranteed := v1.ResourceRequirements{Requests: v1.ResourceList{}, Limits: v1.ResourceList{}}
+	if cpu, ok := cpuQuantity(resources); ok {
+		// the CPU manager only assigns exclusive CPUs to the containers requesting integer CPUs
+		cpus := resource.NewQuantity((cpu.MilliValue()+999)/1000, resource.DecimalSI)
+		guaranteed.Requests[v1.ResourceCPU] = *cpus
+		guaranteed.Limits[v1.ResourceCPU] = *cpus
+	}
+	if memory, ok := memoryQuantity(resources); ok {
+		guaranteed.Requests[v1.ResourceMemory] = memory
+		guaranteed.Limits[v1.ResourceMemory] = memory
+	}
+	// the other resources like the hugepages keep their own requests and limits
+	for name, quantity := range resources.Requests {
+		if name != v1.ResourceCPU && name != v1.ResourceMemory {
+			guaranteed.Requests[name] = quantity
+		}
+	}
+	for name, quantity := range resources.Limits {
+		if name != v1.ResourceCPU && name != v1.ResourceMemory {
+			guaranteed.Limits[name] = quantity
+		}
+	}
+	return guaranteed
+}
+
+// the CPUs of the guaranteed resources are the highest of the requests and the limits
+func cpuQuantity(resources v1.ResourceRequirements) (resource.Quantity, bool) {
+	return maxQuantity(resources, v1.ResourceCPU)
+}
+
+func memoryQuantity(resources v1.ResourceRequirements) (resource.Quantity, bool) {
+	return maxQuantity(resources, v1.ResourceMemory)
+}
+
+func maxQuantity(resources v1.ResourceRequirements, name v1.ResourceName) (resource.Quantity, bool) {
+	request, hasRequest := resources.Requests[name]
+	limit, hasLimit := resources.Limits[name]
+	if hasLimit && (!hasRequest || limit.Cmp(request) > 0) {
+		return limit, true
+	}
+	return request, hasRequest
+}
+
+// hugePagesVolumes returns the volumes and the mounts of the hugepages requested by an osd, one for each page size
+func hugePagesVolumes(resources v1.ResourceRequirements) ([]v1.Volume, []v1.VolumeMount) {
+	sizes := map[string]bool{}
+	for _, list := range []v1.ResourceList{resources.Requests, resources.Limits} {
+		for name := range list {
+			if strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix) {
+				sizes[strings.TrimPrefix(string(name), v1.ResourceHugePagesPrefix)] = true
+			}
+		}
+	}
+	if len(sizes) == 0 {
+		return nil, nil
+	}
+	if len(sizes) == 1 {
+		volume := v1.Volume{Name: hugePagesVolumeName, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumHugePages}}}
+		return []v1.Volume{volume}, []v1.VolumeMount{{Name: hugePagesVolumeName, MountPath: hugePagesMountPath}}
+	}
+
+	// the pages of several sizes are mounted in a directory by size
+	sortedSizes := []string{}
+	for size := range sizes {
+		sortedSizes = append(sortedSizes, size)
+	}
+	sort.Strings(sortedSizes)
+	volumes := []v1.Volume{}
+	mounts := []v1.VolumeMount{}
+	for _, size := range sortedSizes {
+		name := hugePagesVolumeName + "-" + strings.ToLower(size)
+		medium := v1.StorageMedium(hugePagesMediumPrefix + size)
+		volumes = append(volumes, v1.Volume{Name: name, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: medium}}})
+		mounts = append(mounts, v1.VolumeMount{Name: name, MountPath: hugePagesMountPath + "-" + size})
+	}
+	return volumes, mounts
+}
+
+// osdPerformanceFlags returns the flags of the osd daemon of the performance settings
+func osdPerformanceFlags(spec cephv1.OSDPerformanceSpec) []string {
+	flags := []string{}
+	if spec.NUMANode != nil {
+		// the osd pins its threads to the CPUs of the node instead of the node of its network and storage devices
+		flags = append(flags,
+			opconfig.NewFlag("osd numa auto affinity", "false"),
+			opconfig.NewFlag("osd numa node", strconv.Itoa(*spec.NUMANode)))
+	}
+	if spec.BluestoreCacheSize != nil {
+		flags = append(flags,
+			opconfig.NewFlag("bluestore cache autotune", "false"),
+			opconfig.NewFlag("bluestore cache size", strconv.FormatInt(spec.BluestoreCacheSize.Value(), 10)))
+	}
+	return flags
+}
+
+// validateDeviceClasses checks the settings of the device classes of the osds
+func validateDeviceClasses(classes []cephv1.OSDDeviceClassSpec) error {
+	names := map[string]bool{}
+	for _, class := range classes {
+		if class.Name == "" {
+			return errors.New("the name of the device classes is required")
+		}
+		// the classes are sent to the prepare jobs as a list of class=count
+		if strings.ContainsAny(class.Name, "=,") {
+			return errors.Errorf("invalid device class name %q", class.Name)
+		}
+		if names[class.Name] {
+			return errors.Errorf("device class %q is set more than once", class.Name)
+		}
+		names[class.Name] = true
+		if class.OSDsPerDevice < 0 {
+			return errors.Errorf("invalid number of osds per device %d of device class %q", class.OSDsPerDevice, class.Name)
+		}
+	}
+	return nil
+}
+
+// deviceClassSpec returns the settings of a device class, or nil if the class has no settings
+func deviceClassSpec(spec cephv1.OSDPerformanceSpec, class string) *cephv1.OSDDeviceClassSpec {
+	if class == "" {
+		return nil
+	}
+	for i := range spec.DeviceClasses {
+		if spec.DeviceClasses[i].Name == class {
+			return &spec.DeviceClasses[i]
+		}
+	}
+	return nil
+}
+
+// osdsPerDeviceClass returns the number of osds per device of the device classes, like "nvme=4,ssd=2"
+func osdsPerDeviceClass(spec cephv1.OSDPerformanceSpec) string {
+	counts := []string{}
+	for _, class := range spec.DeviceClasses {
+		if class.OSDsPerDevice > 0 && !strings.ContainsAny(class.Name, "=,") {
+			counts = append(counts, class.Name+"="+strconv.Itoa(class.OSDsPerDevice))
+		}
+	}
+	return strings.Join(counts, ",")
+}
+
+// osdsOnDevices counts the osds of each device of a node by the id of the device
+func osdsOnDevices(osds []OSDInfo) map[string]int {
+	counts := map[string]int{}
+	for _, osd := range osds {
+		if osd.DeviceID != "" {
+			counts[osd.DeviceID]++
+		}
+	}
+	return counts
+}
+
+// scaleResources divides the requests and the limits of an osd by the number of osds sharing its device. The
+// hugepages are not divided since they are allocated by page.
+func scaleResources(resources v1.ResourceRequirements, osdsOnDevice int) v1.ResourceRequirements {
+	if osdsOnDevice <= 1 {
+		return resources
+	}
+	return v1.ResourceRequirements{
+		Requests: scaleResourceList(resources.Requests, int64(osdsOnDevice)),
+		Limits:   scaleResourceList(resources.Limits, int64(osdsOnDevice)),
+	}
+}
+
+func scaleResourceList(list v1.ResourceList, divisor int64) v1.ResourceList {
+	if list == nil {
+		return nil
+	}
+	scaled := v1.ResourceList{}
+	for name, quantity := range list {
+		switch {
+		case strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix):
+			scaled[name] = quantity
+		case name == v1.ResourceCPU:
+			scaled[name] = *resource.NewMilliQuantity(quantity.MilliValue()/divisor, quantity.Format)
+		default:
+			scaled[name] = *resource.NewQuantity(quantity.Value()/divisor, quantity.Format)
+		}
+	}
+	return scaled
+}
+
+// applyDeviceClassAntiAffinity prefers scheduling the osd pods of a device class on different nodes
+func applyDeviceClassAntiAffinity(class string, podSpec *v1.PodSpec) {
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &v1.Affinity{}
+	}
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+	}
+	term := v1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: v1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{k8sutil.AppAttr: AppName, OSDDeviceClassLabelKey: class},
+			},
+			TopologyKey: v1.LabelHostname,
+		},
+	}
+	podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term)
+}
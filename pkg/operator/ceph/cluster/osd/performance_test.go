@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyOSDPerformance(t *testing.T) {
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("1500m"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+			"hugepages-2Mi":   resource.MustParse("1Gi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("6Gi"),
+			"hugepages-2Mi":   resource.MustParse("1Gi"),
+		},
+	}
+
+	// the resources are not changed by default
+	assert.Equal(t, resources, applyOSDPerformance(cephv1.OSDPerformanceSpec{}, resources))
+
+	// the CPUs are rounded up and the requests are the limits
+	spec := cephv1.OSDPerformanceSpec{StaticCPUs: true}
+	assert.NoError(t, validateOSDPerformance(spec, resources))
+	guaranteed := applyOSDPerformance(spec, resources)
+	assert.Equal(t, "2", guaranteed.Requests.Cpu().String())
+	assert.Equal(t, "2", guaranteed.Limits.Cpu().String())
+	assert.Equal(t, "6Gi", guaranteed.Requests.Memory().String())
+	assert.Equal(t, "6Gi", guaranteed.Limits.Memory().String())
+	hugePages := guaranteed.Limits["hugepages-2Mi"]
+	assert.Equal(t, "1Gi", hugePages.String())
+
+	// the static CPUs require the cpu and the memory
+	assert.Error(t, validateOSDPerformance(spec, v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")}}))
+	assert.Error(t, validateOSDPerformance(spec, v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}}))
+
+	numaNode := -1
+	assert.Error(t, validateOSDPerformance(cephv1.OSDPerformanceSpec{NUMANode: &numaNode}, resources))
+	cacheSize := resource.MustParse("8Gi")
+	assert.Error(t, validateOSDPerformance(cephv1.OSDPerformanceSpec{BluestoreCacheSize: &cacheSize}, resources))
+	cacheSize = resource.MustParse("2Gi")
+	assert.NoError(t, validateOSDPerformance(cephv1.OSDPerformanceSpec{BluestoreCacheSize: &cacheSize}, resources))
+}
+
+func TestHugePagesVolumes(t *testing.T) {
+	volumes, mounts := hugePagesVolumes(v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}})
+	assert.Empty(t, volumes)
+	assert.Empty(t, mounts)
+
+	volumes, mounts = hugePagesVolumes(v1.ResourceRequirements{Limits: v1.ResourceList{"hugepages-1Gi": resource.MustParse("2Gi")}})
+	assert.Equal(t, v1.StorageMediumHugePages, volumes[0].EmptyDir.Medium)
+	assert.Equal(t, "/dev/hugepages", mounts[0].MountPath)
+
+	// the pages of several sizes are mounted by size
+	volumes, mounts = hugePagesVolumes(v1.ResourceRequirements{Limits: v1.ResourceList{
+		"hugepages-1Gi": resource.MustParse("2Gi"),
+		"hugepages-2Mi": resource.MustParse("1Gi"),
+	}})
+	assert.Equal(t, 2, len(volumes))
+	assert.Equal(t, v1.StorageMedium("HugePages-1Gi"), volumes[0].EmptyDir.Medium)
+	assert.Equal(t, "hugepages-2mi", volumes[1].Name)
+	assert.Equal(t, "/dev/hugepages-2Mi", mounts[1].MountPath)
+}
+
+func TestOSDPerformanceDeployment(t *testing.T) {
+	numaNode := 1
+	cacheSize := resource.MustParse("1Gi")
+	spec := cephv1.ClusterSpec{
+		Storage: rookv1.StorageScopeSpec{Nodes: []rookv1.Node{{Name: "node1"}}},
+		OSDPerformance: cephv1.OSDPerformanceSpec{
+			StaticCPUs:         true,
+			NUMANode:           &numaNode,
+			BluestoreCacheSize: &cacheSize,
+		},
+	}
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", CephVersion: cephver.Nautilus}
+	context := &clusterd.Context{Clientset: fake.NewSimpleClientset(), ConfigDir: "/var/lib/rook", Executor: &exectest.MockExecutor{}}
+	c := New(context, clusterInfo, spec, "rook/rook:myversion")
+	osdProp := osdProperties{
+		crushHostname: "node1",
+		resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("4Gi")},
+			Limits:   v1.ResourceList{"hugepages-2Mi": resource.MustParse("1Gi")},
+		},
+	}
+	dataPathMap := &provisionConfig{DataPathMap: opconfig.NewDatalessDaemonDataPathMap(clusterInfo.Namespace, "/var/lib/rook")}
+
+	d, err := c.makeDeployment(osdProp, OSDInfo{ID: 0}, dataPathMap)
+	assert.NoError(t, err)
+	container := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "1", container.Resources.Limits.Cpu().String())
+	assert.Equal(t, "4Gi", container.Resources.Limits.Memory().String())
+	assert.Contains(t, container.Args, "--osd-numa-node=1")
+	assert.Contains(t, container.Args, "--bluestore-cache-size=1073741824")
+	assert.Contains(t, container.VolumeMounts, v1.VolumeMount{Name: "hugepages", MountPath: "/dev/hugepages"})
+
+	// the invalid settings are refused
+	osdProp.resources.Requests = nil
+	_, err = c.makeDeployment(osdProp, OSDInfo{ID: 0}, dataPathMap)
+	assert.Error(t, err)
+}
+
+func TestOSDDeviceClassSpecs(t *testing.T) {
+	spec := cephv1.OSDPerformanceSpec{DeviceClasses: []cephv1.OSDDeviceClassSpec{
+		{Name: "nvme", OSDsPerDevice: 4, ScaleResources: true},
+		{Name: "hdd", AntiAffinity: true},
+		{Name: "ssd", OSDsPerDevice: 2},
+	}}
+	assert.NoError(t, validateDeviceClasses(spec.DeviceClasses))
+	assert.Equal(t, "nvme=4,ssd=2", osdsPerDeviceClass(spec))
+	assert.Equal(t, 4, deviceClassSpec(spec, "nvme").OSDsPerDevice)
+	assert.Nil(t, deviceClassSpec(spec, "ssd2"))
+	assert.Nil(t, deviceClassSpec(spec, ""))
+
+	for _, classes := range [][]cephv1.OSDDeviceClassSpec{
+		{{OSDsPerDevice: 2}},
+		{{Name: "nvme"}, {Name: "nvme"}},
+		{{Name: "nvme=2"}},
+		{{Name: "nvme", OSDsPerDevice: -1}},
+	} {
+		assert.Error(t, validateDeviceClasses(classes))
+	}
+
+	assert.Equal(t, map[string]int{"disk1": 2, "disk2": 1}, osdsOnDevices([]OSDInfo{{ID: 0, DeviceID: "disk1"}, {ID: 1, DeviceID: "disk1"}, {ID: 2, DeviceID: "disk2"}, {ID: 3}}))
+
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("16Gi")},
+		Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("16Gi"), "hugepages-2Mi": resource.MustParse("1Gi")},
+	}
+	assert.Equal(t, resources, scaleResources(resources, 1))
+	scaled := scaleResources(resources, 4)
+	assert.Equal(t, "500m", scaled.Requests.Cpu().String())
+	assert.Equal(t, "4Gi", scaled.Requests.Memory().String())
+	assert.Equal(t, "4Gi", scaled.Limits.Memory().String())
+	hugePages := scaled.Limits["hugepages-2Mi"]
+	assert.Equal(t, "1Gi", hugePages.String())
+}
+
+func TestDeviceClassDeployment(t *testing.T) {
+	spec := cephv1.ClusterSpec{
+		Storage: rookv1.StorageScopeSpec{Nodes: []rookv1.Node{{Name: "node1"}}},
+		OSDPerformance: cephv1.OSDPerformanceSpec{DeviceClasses: []cephv1.OSDDeviceClassSpec{
+			{Name: "nvme", OSDsPerDevice: 2, ScaleResources: true, AntiAffinity: true},
+		}},
+	}
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", CephVersion: cephver.Nautilus}
+	context := &clusterd.Context{Clientset: fake.NewSimpleClientset(), ConfigDir: "/var/lib/rook", Executor: &exectest.MockExecutor{}}
+	c := New(context, clusterInfo, spec, "rook/rook:myversion")
+	osds := []OSDInfo{{ID: 0, DeviceID: "disk1", DeviceClass: "nvme"}, {ID: 1, DeviceID: "disk1", DeviceClass: "nvme"}}
+	osdProp := osdProperties{
+		crushHostname: "node1",
+		resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("8Gi")},
+		},
+		osdsOnDevices: osdsOnDevices(osds),
+	}
+	dataPathMap := &provisionConfig{DataPathMap: opconfig.NewDatalessDaemonDataPathMap(clusterInfo.Namespace, "/var/lib/rook")}
+
+	d, err := c.makeDeployment(osdProp, osds[0], dataPathMap)
+	assert.NoError(t, err)
+	container := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "2", container.Resources.Requests.Cpu().String())
+	assert.Equal(t, "4Gi", container.Resources.Requests.Memory().String())
+	assert.Equal(t, "nvme", d.Labels[OSDDeviceClassLabelKey])
+	assert.Equal(t, "nvme", d.Spec.Template.Labels[OSDDeviceClassLabelKey])
+	terms := d.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, 1, len(terms))
+	assert.Equal(t, v1.LabelHostname, terms[0].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, "nvme", terms[0].PodAffinityTerm.LabelSelector.MatchLabels[OSDDeviceClassLabelKey])
+
+	// the class of the device is read back from the deployment
+	infos, err := c.getOSDInfo(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "nvme", infos[0].DeviceClass)
+
+	// the osds of the other classes are not changed
+	osds[0].DeviceClass = "ssd"
+	d, err = c.makeDeployment(osdProp, osds[0], dataPathMap)
+	assert.NoError(t, err)
+	assert.Equal(t, "4", d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String())
+	assert.Empty(t, d.Labels[OSDDeviceClassLabelKey])
+}
@@ -116,7 +116,8 @@ func (c *Cluster) provisionPodTemplateSpec(osdProps osdProperties, restart v1.Re
 		RestartPolicy:     restart,
 		Volumes:           volumes,
 		HostNetwork:       c.spec.Network.IsHost(),
-		PriorityClassName: cephv1.GetOSDPriorityClassName(c.spec.PriorityClassNames),
+		PriorityClassName: cephv1.GetPrepareOSDPriorityClassName(c.spec.PriorityClassNames),
+		SecurityContext:   cephv1.GetPodSecurityContext(c.spec.Security.PodSecurityContexts, cephv1.KeyPrepareOSD),
 		SchedulerName:     osdProps.schedulerName,
 	}
 	if c.spec.Network.IsHost() {
@@ -139,6 +140,12 @@ func (c *Cluster) provisionPodTemplateSpec(osdProps osdProperties, restart v1.Re
 	}
 
 	cephv1.GetOSDAnnotations(c.spec.Annotations).ApplyToObjectMeta(&podMeta)
+	cephv1.GetOSDLabels(c.spec.Labels).ApplyToObjectMeta(&podMeta)
+	if c.spec.Network.IsMultus() {
+		if err := k8sutil.ApplyMultus(c.spec.Network.NetworkSpec, &podMeta); err != nil {
+			return nil, err
+		}
+	}
 
 	// ceph-volume --dmcrypt uses cryptsetup that synchronizes with udev on
 	// host through semaphore
@@ -155,6 +162,23 @@ func (c *Cluster) provisionOSDContainer(osdProps osdProperties, copyBinariesMoun
 	// enable debug logging in the prepare job
 	envVars = append(envVars, setDebugLogLevelEnvVar(true))
 
+	if c.spec.Storage.Store.Type != "" {
+		envVars = append(envVars, osdStoreEnvVar(c.spec.Storage.Store.Type))
+	}
+	if counts := osdsPerDeviceClass(c.spec.OSDPerformance); counts != "" && !osdProps.onPVC() {
+		envVars = append(envVars, osdsPerDeviceClassEnvVar(counts))
+	}
+	if len(c.spec.CrushTopology.Labels) > 0 {
+		topologyLabels, err := crushTopologyLabelsEnvVarValue(c.spec.CrushTopology.Labels)
+		if err != nil {
+			return v1.Container{}, err
+		}
+		envVars = append(envVars, crushTopologyLabelsEnvVar(topologyLabels))
+	}
+	if ids := c.replaceOSDs[osdProps.crushHostname]; len(ids) > 0 && !osdProps.onPVC() {
+		envVars = append(envVars, replaceOSDIDsEnvVar(ids))
+	}
+
 	// Drive Groups cannot be used to configure OSDs on PVCs, so ignore if this is a PVC config
 	// This shouldn't ever happen, but do the PVC check to be sure
 	if len(osdProps.driveGroups) > 0 && !osdProps.onPVC() {
@@ -204,6 +228,13 @@ func (c *Cluster) provisionOSDContainer(osdProps osdProperties, copyBinariesMoun
 		envVars = append(envVars, metadataDeviceEnvVar(osdProps.metadataDevice))
 	}
 
+	// the encrypted devices are encrypted with the keys of the key management service
+	var envFrom []v1.EnvFromSource
+	if osdProps.storeConfig.EncryptedDevice && c.spec.Security.KeyManagementService.IsEnabled() && !osdProps.onPVC() {
+		envVars = append(envVars, c.kmsEnvVars()...)
+		envFrom = c.kmsEnvFrom()
+	}
+
 	volumeMounts := append(controller.CephVolumeMounts(provisionConfig.DataPathMap, true), []v1.VolumeMount{
 		{Name: "devices", MountPath: "/dev"},
 		{Name: "udev", MountPath: "/run/udev"},
@@ -255,6 +286,7 @@ func (c *Cluster) provisionOSDContainer(osdProps osdProperties, copyBinariesMoun
 		Image:        c.spec.CephVersion.Image,
 		VolumeMounts: volumeMounts,
 		Env:          envVars,
+		EnvFrom:      envFrom,
 		SecurityContext: &v1.SecurityContext{
 			Privileged:             &privileged,
 			RunAsUser:              &runAsUser,
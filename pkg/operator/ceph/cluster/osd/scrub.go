@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// the config map recording the scrubbing settings set in the config of the osds
+	scrubbingConfigMapName = "rook-ceph-osd-scrubbing-settings"
+	scrubbingOptionsKey    = "options"
+)
+
+// scrubbingOption is a setting of the scrubbing spec in the config of the osds, removed from the config when not set
+type scrubbingOption struct {
+	name  string
+	value string
+	set   bool
+}
+
+// scrubbingOptions returns the config options of the scrubbing spec
+func scrubbingOptions(spec cephv1.ScrubbingSpec) ([]scrubbingOption, error) {
+	options := []scrubbingOption{}
+	addInt := func(name string, value *int, min, max int) error {
+		if value == nil {
+			options = append(options, scrubbingOption{name: name})
+			return nil
+		}
+		if *value < min || (max >= min && *value > max) {
+			return errors.Errorf("invalid scrubbing setting %q value %d", name, *value)
+		}
+		options = append(options, scrubbingOption{name: name, value: strconv.Itoa(*value), set: true})
+		return nil
+	}
+	addDuration := func(name, value string) error {
+		if value == "" {
+			options = append(options, scrubbingOption{name: name})
+			return nil
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return errors.Errorf("invalid scrubbing setting %q duration %q", name, value)
+		}
+		// the durations are in seconds in the config
+		seconds := strconv.FormatFloat(duration.Seconds(), 'f', -1, 64)
+		options = append(options, scrubbingOption{name: name, value: seconds, set: true})
+		return nil
+	}
+
+	if err := addInt("osd_scrub_begin_hour", spec.BeginHour, 0, 23); err != nil {
+		return nil, err
+	}
+	if err := addInt("osd_scrub_end_hour", spec.EndHour, 0, 23); err != nil {
+		return nil, err
+	}
+	if err := addInt("osd_scrub_begin_week_day", spec.BeginWeekDay, 0, 6); err != nil {
+		return nil, err
+	}
+	if err := addInt("osd_scrub_end_week_day", spec.EndWeekDay, 0, 6); err != nil {
+		return nil, err
+	}
+	if err := addInt("osd_max_scrubs", spec.MaxScrubsPerOSD, 1, 0); err != nil {
+		return nil, err
+	}
+	if err := addDuration("osd_scrub_sleep", spec.Sleep); err != nil {
+		return nil, err
+	}
+	if err := addDuration("osd_deep_scrub_interval", spec.DeepScrubInterval); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// applyScrubbingSettings sets the scrubbing settings of the spec in the config of the osds, and removes the settings
+// set previously which are not in the spec anymore. The osds apply the settings without restarting.
+func (c *Cluster) applyScrubbingSettings() error {
+	options, err := scrubbingOptions(c.spec.Scrubbing)
+	if err != nil {
+		return err
+	}
+	previous, err := c.kv.GetValue(scrubbingConfigMapName, scrubbingOptionsKey)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get the previous scrubbing settings")
+	}
+	wasSet := map[string]bool{}
+	for _, name := range strings.Split(previous, ",") {
+		wasSet[name] = true
+	}
+
+	monStore := opconfig.GetMonStore(c.context, c.clusterInfo)
+	set := []string{}
+	for _, option := range options {
+		if option.set {
+			if err := monStore.Set("osd", option.name, option.value); err != nil {
+				return errors.Wrapf(err, "failed to set the scrubbing setting %q", option.name)
+			}
+			set = append(set, option.name)
+			continue
+		}
+		if wasSet[option.name] {
+			if err := monStore.Delete("osd", option.name); err != nil {
+				return errors.Wrapf(err, "failed to reset the scrubbing setting %q", option.name)
+			}
+			logger.Infof("scrubbing setting %q reset to the ceph default", option.name)
+		}
+	}
+
+	sort.Strings(set)
+	current := strings.Join(set, ",")
+	if current == previous {
+		return nil
+	}
+	if err := c.kv.SetValue(scrubbingConfigMapName, scrubbingOptionsKey, current); err != nil {
+		return errors.Wrap(err, "failed to record the scrubbing settings")
+	}
+	return nil
+}
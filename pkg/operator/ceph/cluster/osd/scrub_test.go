@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osd
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyScrubbingSettings(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "config" && args[2] == "osd" {
+				// the command without the connection args
+				command := []string{}
+				for _, arg := range args {
+					if strings.HasPrefix(arg, "--connect-timeout") {
+						break
+					}
+					command = append(command, arg)
+				}
+				commands = append(commands, strings.Join(command, " "))
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Clientset: fake.NewSimpleClientset(), Executor: executor}
+	c := New(context, &cephclient.ClusterInfo{Namespace: "ns"}, cephv1.ClusterSpec{}, "rook/rook:myversion")
+
+	// nothing is reset when nothing was set
+	assert.NoError(t, c.applyScrubbingSettings())
+	assert.Empty(t, commands)
+
+	beginHour, endHour, maxScrubs := 22, 6, 2
+	c.spec.Scrubbing = cephv1.ScrubbingSpec{
+		BeginHour:       &beginHour,
+		EndHour:         &endHour,
+		MaxScrubsPerOSD: &maxScrubs,
+		Sleep:           "100ms",
+	}
+	assert.NoError(t, c.applyScrubbingSettings())
+	assert.Equal(t, []string{
+		"config set osd osd_scrub_begin_hour 22",
+		"config set osd osd_scrub_end_hour 6",
+		"config set osd osd_max_scrubs 2",
+		"config set osd osd_scrub_sleep 0.1",
+	}, commands)
+
+	// the settings removed from the spec are reset
+	commands = []string{}
+	c.spec.Scrubbing = cephv1.ScrubbingSpec{DeepScrubInterval: "168h"}
+	assert.NoError(t, c.applyScrubbingSettings())
+	assert.ElementsMatch(t, []string{
+		"config rm osd osd_scrub_begin_hour",
+		"config rm osd osd_scrub_end_hour",
+		"config rm osd osd_max_scrubs",
+		"config rm osd osd_scrub_sleep",
+		"config set osd osd_deep_scrub_interval 604800",
+	}, commands)
+
+	// the settings are validated
+	invalidHour := 24
+	c.spec.Scrubbing = cephv1.ScrubbingSpec{BeginHour: &invalidHour}
+	assert.Error(t, c.applyScrubbingSettings())
+	noScrubs := 0
+	c.spec.Scrubbing = cephv1.ScrubbingSpec{MaxScrubsPerOSD: &noScrubs}
+	assert.Error(t, c.applyScrubbingSettings())
+	c.spec.Scrubbing = cephv1.ScrubbingSpec{Sleep: "1 second"}
+	assert.Error(t, c.applyScrubbingSettings())
+}
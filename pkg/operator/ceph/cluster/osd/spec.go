@@ -27,6 +27,7 @@ import (
 	opmon "github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd/config"
 	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	apps "k8s.io/api/apps/v1"
@@ -105,6 +106,24 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 	}
 	volumes := controller.PodVolumes(provisionConfig.DataPathMap, c.spec.DataDirHostPath, false)
 	failureDomainValue := osdProps.crushHostname
+	deviceClass := osd.DeviceClass
+	if deviceClass == "" {
+		deviceClass = osdProps.crushDeviceClass
+	}
+	classSpec := deviceClassSpec(c.spec.OSDPerformance, deviceClass)
+	if classSpec != nil && classSpec.ScaleResources && osd.DeviceID != "" {
+		osdsOnDevice := osdProps.osdsOnDevices[osd.DeviceID]
+		osdProps.resources = scaleResources(osdProps.resources, osdsOnDevice)
+		if err := controller.CheckPodMemory(osdProps.resources, cephOsdPodMinimumMemory); err != nil {
+			logger.Warningf("the resources of osd %d are divided between the %d osds of its device. %v", osd.ID, osdsOnDevice, err)
+		}
+	}
+	// keep the requests adjusted by the resource autoscaling
+	osdProps.resources = c.applyResourceRecommendation(osd.ID, osdProps.resources)
+	if err := validateOSDPerformance(c.spec.OSDPerformance, osdProps.resources); err != nil {
+		return nil, errors.Wrapf(err, "invalid performance settings of osd %d", osd.ID)
+	}
+	osdProps.resources = applyOSDPerformance(c.spec.OSDPerformance, osdProps.resources)
 	doConfigInit := true       // initialize ceph.conf in init container?
 	doBinaryCopyInit := true   // copy tini and rook binaries in an init container?
 	doActivateOSDInit := false // run an init container to activate the osd?
@@ -244,6 +263,17 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 
 	args = append(args, opconfig.LoggingFlags()...)
 	args = append(args, osdOnSDNFlag(c.spec.Network)...)
+	args = append(args, osdPerformanceFlags(c.spec.OSDPerformance)...)
+
+	hugePagesVolumes, hugePagesMounts := hugePagesVolumes(osdProps.resources)
+	volumes = append(volumes, hugePagesVolumes...)
+	volumeMounts = append(volumeMounts, hugePagesMounts...)
+
+	// The osd authenticates with the key of its keyring secret rather than the key of the osd data dir, so that the
+	// rotated cephx keys are picked up when the osd restarts
+	volumes = append(volumes, keyring.Volume().Resource(deploymentName))
+	volumeMounts = append(volumeMounts, keyring.VolumeMount().Resource(deploymentName))
+	args = append(args, opconfig.NewFlag("keyring", keyring.VolumeMount().KeyringFilePath()))
 
 	osdDataDirPath := activateOSDMountPath + osdID
 	if osdProps.onPVC() && osd.CVMode == "lvm" {
@@ -255,6 +285,14 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 		envVars = append(envVars, lvBackedPVEnvVar(strconv.FormatBool(osd.LVBackedPV)))
 	}
 
+	// the key rotation of the encrypted osds on nodes reads the device of the osd from the deployment
+	if !osdProps.onPVC() && (osdProps.storeConfig.EncryptedDevice || osd.EncryptionKeyID != "") {
+		envVars = append(envVars, blockPathEnvVariable(osd.BlockPath))
+	}
+	if osd.EncryptionKeyID != "" {
+		envVars = append(envVars, encryptionKeyIDEnvVar(osd.EncryptionKeyID))
+	}
+
 	if osdProps.onPVC() && osd.CVMode == "raw" {
 		volumeMounts = append(volumeMounts, getPvcOSDBridgeMountActivate(osdDataDirPath, osdProps.pvc.ClaimName))
 		envVars = append(envVars, pvcBackedOSDEnvVar("true"))
@@ -306,6 +344,9 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 		initContainers = append(initContainers, c.getExpandPVCInitContainer(osdProps, osdID))
 	}
 	if doActivateOSDInit {
+		if osd.EncryptionKeyID != "" {
+			initContainers = append(initContainers, c.getOpenEncryptedDeviceInitContainer(osd, osdProps, udevVolumeMount))
+		}
 		initContainers = append(initContainers, *activateOSDContainer)
 	}
 
@@ -347,6 +388,7 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 			HostPID:            hostPID,
 			HostIPC:            hostIPC,
 			PriorityClassName:  cephv1.GetOSDPriorityClassName(c.spec.PriorityClassNames),
+			SecurityContext:    cephv1.GetPodSecurityContext(c.spec.Security.PodSecurityContexts, cephv1.KeyOSD),
 			InitContainers:     initContainers,
 			Containers: []v1.Container{
 				{
@@ -367,7 +409,8 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 	}
 
 	// If the liveness probe is enabled
-	podTemplateSpec.Spec.Containers[0] = opconfig.ConfigureLivenessProbe(cephv1.KeyOSD, podTemplateSpec.Spec.Containers[0], c.spec.HealthCheck)
+	podTemplateSpec.Spec.Containers[0] = opconfig.ConfigureProbes(cephv1.KeyOSD, podTemplateSpec.Spec.Containers[0], c.spec.HealthCheck)
+	controller.ApplyLogCollector(&podTemplateSpec.Spec, opconfig.OsdType, "osd."+osdID, &c.spec, provisionConfig.DataPathMap)
 
 	if c.spec.Network.IsHost() {
 		podTemplateSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
@@ -402,6 +445,14 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 		k8sutil.AddLabelToDeployment(OSDOverPVCLabelKey, osdProps.pvc.ClaimName, deployment)
 		k8sutil.AddLabelToPod(OSDOverPVCLabelKey, osdProps.pvc.ClaimName, &deployment.Spec.Template)
 	}
+	// the label is not added to the pod to not restart the existing osds
+	if osd.DeviceID != "" {
+		k8sutil.AddLabelToDeployment(OSDDeviceIDLabelKey, osd.DeviceID, deployment)
+	}
+	if classSpec != nil && classSpec.AntiAffinity {
+		k8sutil.AddLabelToDeployment(OSDDeviceClassLabelKey, deviceClass, deployment)
+		k8sutil.AddLabelToPod(OSDDeviceClassLabelKey, deviceClass, &deployment.Spec.Template)
+	}
 	if !osdProps.portable {
 		deployment.Spec.Template.Spec.NodeSelector = map[string]string{v1.LabelHostname: osdProps.crushHostname}
 	}
@@ -413,6 +464,9 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 	k8sutil.AddRookVersionLabelToDeployment(deployment)
 	cephv1.GetOSDAnnotations(c.spec.Annotations).ApplyToObjectMeta(&deployment.ObjectMeta)
 	cephv1.GetOSDAnnotations(c.spec.Annotations).ApplyToObjectMeta(&deployment.Spec.Template.ObjectMeta)
+	cephv1.GetOSDLabels(c.spec.Labels).ApplyToObjectMeta(&deployment.ObjectMeta)
+	cephv1.GetOSDLabels(c.spec.Labels).ApplyToObjectMeta(&deployment.Spec.Template.ObjectMeta)
+	opconfig.ApplyConnectionsAnnotation(c.spec.Network, &deployment.Spec.Template.ObjectMeta)
 	controller.AddCephVersionLabelToDeployment(c.clusterInfo.CephVersion, deployment)
 	controller.AddCephVersionLabelToDeployment(c.clusterInfo.CephVersion, deployment)
 	k8sutil.SetOwnerRef(&deployment.ObjectMeta, &c.clusterInfo.OwnerRef)
@@ -421,6 +475,10 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 	} else {
 		osdProps.placement.ApplyToPodSpec(&deployment.Spec.Template.Spec)
 	}
+	if classSpec != nil && classSpec.AntiAffinity {
+		applyDeviceClassAntiAffinity(deviceClass, &deployment.Spec.Template.Spec)
+	}
+	k8sutil.ApplyInheritedMetadataToPodTemplate(deployment.Namespace, &deployment.ObjectMeta, &deployment.Spec.Template)
 
 	return deployment, nil
 }
@@ -478,6 +536,24 @@ func (c *Cluster) getActivateOSDInitContainer(osdID string, osdInfo OSDInfo, osd
 	return volume, container
 }
 
+// getOpenEncryptedDeviceInitContainer opens the device of an OSD encrypted with a key of the key management service,
+// which ceph-volume cannot open, before the OSD is activated
+func (c *Cluster) getOpenEncryptedDeviceInitContainer(osd OSDInfo, osdProps osdProperties, udevVolumeMount v1.VolumeMount) v1.Container {
+	return v1.Container{
+		Args:  []string{"ceph", "osd", "open-encrypted-device", "--encryption-key-id", osd.EncryptionKeyID},
+		Name:  "open-encrypted-device",
+		Image: k8sutil.MakeRookImage(c.rookVersion),
+		VolumeMounts: []v1.VolumeMount{
+			{Name: "devices", MountPath: "/dev"},
+			udevVolumeMount,
+		},
+		Env:             c.kmsEnvVars(),
+		EnvFrom:         c.kmsEnvFrom(),
+		SecurityContext: PrivilegedContext(),
+		Resources:       osdProps.resources,
+	}
+}
+
 // Currently we can't mount a block mode pv directly to a privileged container
 // So we mount it to a non privileged init container and then copy it to a common directory mounted inside init container
 // and the privileged provision container.
@@ -118,9 +118,17 @@ func testPodDevices(t *testing.T, dataDir, deviceName string, allDevices bool) {
 		DataPathMap: opconfig.NewDatalessDaemonDataPathMap(c.clusterInfo.Namespace, "/var/lib/rook"),
 	}
 
-	// Test LVM based on OSD on bare metal
+	// the id of the device of the osd is a label of the deployment but not of the pod
+	osd.DeviceID = "wwn-0x5000c500a1b2c3d4"
 	deployment, err := c.makeDeployment(osdProp, osd, dataPathMap)
 	assert.Nil(t, err)
+	assert.Equal(t, "wwn-0x5000c500a1b2c3d4", deployment.Labels[OSDDeviceIDLabelKey])
+	assert.NotContains(t, deployment.Spec.Template.Labels, OSDDeviceIDLabelKey)
+	osd.DeviceID = ""
+
+	// Test LVM based on OSD on bare metal
+	deployment, err = c.makeDeployment(osdProp, osd, dataPathMap)
+	assert.Nil(t, err)
 	assert.NotNil(t, deployment)
 	assert.Equal(t, "rook-ceph-osd-0", deployment.Name)
 	assert.Equal(t, c.clusterInfo.Namespace, deployment.Namespace)
@@ -129,11 +137,12 @@ func testPodDevices(t *testing.T, dataDir, deviceName string, allDevices bool) {
 	assert.Equal(t, "node1", deployment.Spec.Template.Spec.NodeSelector[v1.LabelHostname])
 	assert.Equal(t, v1.RestartPolicyAlways, deployment.Spec.Template.Spec.RestartPolicy)
 	assert.Equal(t, "my-priority-class", deployment.Spec.Template.Spec.PriorityClassName)
+	assert.NotContains(t, deployment.Labels, OSDDeviceIDLabelKey)
 	if devMountNeeded && len(dataDir) > 0 {
-		assert.Equal(t, 7, len(deployment.Spec.Template.Spec.Volumes))
+		assert.Equal(t, 8, len(deployment.Spec.Template.Spec.Volumes))
 	}
 	if devMountNeeded && len(dataDir) == 0 {
-		assert.Equal(t, 7, len(deployment.Spec.Template.Spec.Volumes))
+		assert.Equal(t, 8, len(deployment.Spec.Template.Spec.Volumes))
 	}
 	if !devMountNeeded && len(dataDir) > 0 {
 		assert.Equal(t, 1, len(deployment.Spec.Template.Spec.Volumes))
@@ -156,8 +165,35 @@ func testPodDevices(t *testing.T, dataDir, deviceName string, allDevices bool) {
 	assert.Equal(t, 1, len(deployment.Spec.Template.Spec.Containers))
 	cont := deployment.Spec.Template.Spec.Containers[0]
 	assert.Equal(t, spec.CephVersion.Image, cont.Image)
-	assert.Equal(t, 7, len(cont.VolumeMounts))
+	assert.Equal(t, 8, len(cont.VolumeMounts))
 	assert.Equal(t, "ceph-osd", cont.Command[0])
+	assert.Contains(t, cont.Args, "--keyring=/etc/ceph/keyring-store/keyring")
+
+	// the device of an osd encrypted with the key management service is opened before the osd is activated
+	c.spec.Security.KeyManagementService = cephv1.KeyManagementServiceSpec{
+		ConnectionDetails: map[string]string{"KMS_PROVIDER": "aws-kms"},
+		TokenSecretName:   "kms-token",
+	}
+	osd.UUID = "osd-uuid"
+	osd.BlockPath = "/dev/vg/osd-block"
+	osd.EncryptionKeyID = "luks-uuid"
+	deployment, err = c.makeDeployment(osdProp, osd, dataPathMap)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(deployment.Spec.Template.Spec.InitContainers))
+	openCont := deployment.Spec.Template.Spec.InitContainers[0]
+	assert.Equal(t, "open-encrypted-device", openCont.Name)
+	assert.Equal(t, "rook/rook:myversion", openCont.Image)
+	assert.Equal(t, []string{"ceph", "osd", "open-encrypted-device", "--encryption-key-id", "luks-uuid"}, openCont.Args)
+	assert.Equal(t, []v1.EnvVar{{Name: "KMS_PROVIDER", Value: "aws-kms"}}, openCont.Env)
+	assert.Equal(t, "kms-token", openCont.EnvFrom[0].SecretRef.Name)
+	assert.Equal(t, "activate", deployment.Spec.Template.Spec.InitContainers[1].Name)
+	verifyEnvVar(t, deployment.Spec.Template.Spec.Containers[0].Env, "ROOK_OSD_ENCRYPTION_KEY_ID", "luks-uuid", true)
+	// the key of the device is rotated with the info of the deployment
+	osds, err := c.getOSDInfo(deployment)
+	assert.Nil(t, err)
+	assert.Equal(t, "luks-uuid", osds[0].EncryptionKeyID)
+	assert.Equal(t, "/dev/vg/osd-block", osds[0].BlockPath)
+	c.spec.Security.KeyManagementService = cephv1.KeyManagementServiceSpec{}
 
 	// Test OSD on PVC with LVM
 	osdProp = osdProperties{
@@ -187,7 +223,7 @@ func testPodDevices(t *testing.T, dataDir, deviceName string, allDevices bool) {
 	blkInitCont := deployment.Spec.Template.Spec.InitContainers[2]
 	assert.Equal(t, 1, len(blkInitCont.VolumeDevices))
 	cont = deployment.Spec.Template.Spec.Containers[0]
-	assert.Equal(t, 8, len(cont.VolumeMounts), cont.VolumeMounts)
+	assert.Equal(t, 9, len(cont.VolumeMounts), cont.VolumeMounts)
 
 	// Test OSD on PVC with RAW
 	osd = OSDInfo{
@@ -204,7 +240,7 @@ func testPodDevices(t *testing.T, dataDir, deviceName string, allDevices bool) {
 	assert.Equal(t, "chown-container-data-dir", deployment.Spec.Template.Spec.InitContainers[3].Name)
 	assert.Equal(t, 1, len(deployment.Spec.Template.Spec.Containers))
 	cont = deployment.Spec.Template.Spec.Containers[0]
-	assert.Equal(t, 6, len(cont.VolumeMounts), cont.VolumeMounts)
+	assert.Equal(t, 7, len(cont.VolumeMounts), cont.VolumeMounts)
 
 	// Test OSD on PVC with RAW and metadata device
 	osd = OSDInfo{
@@ -223,7 +259,7 @@ func testPodDevices(t *testing.T, dataDir, deviceName string, allDevices bool) {
 	assert.Equal(t, "chown-container-data-dir", deployment.Spec.Template.Spec.InitContainers[4].Name)
 	assert.Equal(t, 1, len(deployment.Spec.Template.Spec.Containers))
 	cont = deployment.Spec.Template.Spec.Containers[0]
-	assert.Equal(t, 6, len(cont.VolumeMounts), cont.VolumeMounts)
+	assert.Equal(t, 7, len(cont.VolumeMounts), cont.VolumeMounts)
 	blkInitCont = deployment.Spec.Template.Spec.InitContainers[1]
 	assert.Equal(t, 1, len(blkInitCont.VolumeDevices))
 	blkMetaInitCont := deployment.Spec.Template.Spec.InitContainers[2]
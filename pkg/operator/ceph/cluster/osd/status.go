@@ -52,7 +52,7 @@ type provisionConfig struct {
 
 func (c *Cluster) newProvisionConfig() *provisionConfig {
 	return &provisionConfig{
-		DataPathMap: config.NewDatalessDaemonDataPathMap(c.clusterInfo.Namespace, c.spec.DataDirHostPath),
+		DataPathMap: config.NewDatalessDaemonDataPathMap(c.clusterInfo.Namespace, c.spec.DataDirHostPath).WithHostSocketDir(c.spec.Monitoring.IsExporterEnabled()),
 	}
 }
 
@@ -20,8 +20,15 @@ limitations under the License.
 package osd
 
 import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/k8sutil"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var (
@@ -47,3 +54,107 @@ func ExtractOSDTopologyFromLabels(labels map[string]string) map[string]string {
 	}
 	return topology
 }
+
+// ExtractCrushTopologyFromLabels extracts the CRUSH topology of a node from its labels, with the custom topology
+// labels of the cluster taking precedence over the well-known labels of the same bucket type
+func ExtractCrushTopologyFromLabels(labels map[string]string, topologyLabels []cephv1.CrushTopologyLabelSpec) map[string]string {
+	topology := ExtractOSDTopologyFromLabels(labels)
+	for _, label := range topologyLabels {
+		if value := labels[label.Key]; value != "" {
+			topology[label.Type] = client.NormalizeCrushName(value)
+		}
+	}
+	return topology
+}
+
+// ValidateCrushTopology checks the custom topology labels are mapped to CRUSH bucket types above the host, from the
+// lowest to the highest level
+func ValidateCrushTopology(spec cephv1.CrushTopologySpec) error {
+	previousLevel := 0
+	for _, label := range spec.Labels {
+		if errs := validation.IsQualifiedName(label.Key); len(errs) > 0 {
+			return errors.Errorf("invalid topology label key %q. %s", label.Key, strings.Join(errs, ", "))
+		}
+		level := crushLevel(label.Type)
+		if level <= 0 {
+			return errors.Errorf("invalid crush bucket type %q of topology label %q, expected one of %v", label.Type, label.Key, CRUSHMapLevelsOrdered[1:])
+		}
+		if level <= previousLevel {
+			return errors.Errorf("crush bucket type %q of topology label %q is not above the type of the previous label", label.Type, label.Key)
+		}
+		previousLevel = level
+	}
+	return nil
+}
+
+// crushLevel returns the position of a bucket type in the CRUSH hierarchy from the host, or -1 if the type is unknown
+func crushLevel(bucketType string) int {
+	for i, level := range CRUSHMapLevelsOrdered {
+		if level == bucketType {
+			return i
+		}
+	}
+	return -1
+}
+
+// crushTopologyLabelsEnvVarValue serializes the custom topology labels for the prepare jobs
+func crushTopologyLabelsEnvVarValue(topologyLabels []cephv1.CrushTopologyLabelSpec) (string, error) {
+	value, err := json.Marshal(topologyLabels)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to serialize the topology labels")
+	}
+	return string(value), nil
+}
+
+// ParseCrushTopologyLabels parses the custom topology labels given to a prepare job
+func ParseCrushTopologyLabels(value string) ([]cephv1.CrushTopologyLabelSpec, error) {
+	topologyLabels := []cephv1.CrushTopologyLabelSpec{}
+	if value == "" {
+		return topologyLabels, nil
+	}
+	if err := json.Unmarshal([]byte(value), &topologyLabels); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the topology labels %q", value)
+	}
+	return topologyLabels, nil
+}
+
+// parseCrushLocation parses a CRUSH location like "root=default host=node1 rack=rack1"
+func parseCrushLocation(location string) map[string]string {
+	buckets := map[string]string{}
+	for _, pair := range strings.Fields(location) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			buckets[kv[0]] = kv[1]
+		}
+	}
+	return buckets
+}
+
+// formatCrushLocation formats the buckets of a CRUSH location from the lowest to the highest level, the unknown
+// bucket types before the root
+func formatCrushLocation(buckets map[string]string) string {
+	rank := func(bucketType string) int {
+		if bucketType == "root" {
+			return len(CRUSHMapLevelsOrdered) + 1
+		}
+		if level := crushLevel(bucketType); level >= 0 {
+			return level
+		}
+		return len(CRUSHMapLevelsOrdered)
+	}
+	types := make([]string, 0, len(buckets))
+	for bucketType := range buckets {
+		types = append(types, bucketType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if rank(types[i]) != rank(types[j]) {
+			return rank(types[i]) < rank(types[j])
+		}
+		return types[i] < types[j]
+	})
+	pairs := make([]string, 0, len(types))
+	for _, bucketType := range types {
+		pairs = append(pairs, bucketType+"="+buckets[bucketType])
+	}
+	return strings.Join(pairs, " ")
+}
@@ -22,6 +22,7 @@ package osd
 import (
 	"testing"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,3 +58,48 @@ func TestTopologyLabels(t *testing.T) {
 	assert.Equal(t, "r-row", topology["row"])
 	assert.Equal(t, "d-datacenter", topology["datacenter"])
 }
+
+func TestCrushTopologyLabels(t *testing.T) {
+	topologyLabels := []cephv1.CrushTopologyLabelSpec{{Key: "example.com/rack", Type: "rack"}, {Key: "example.com/room", Type: "room"}}
+	nodeLabels := map[string]string{
+		"kubernetes.io/hostname":      "node1",
+		"topology.kubernetes.io/zone": "zone1",
+		"topology.rook.io/rack":       "rack0",
+		"example.com/rack":            "rack.1",
+		"example.com/room":            "room1",
+	}
+	topology := ExtractCrushTopologyFromLabels(nodeLabels, topologyLabels)
+	assert.Equal(t, map[string]string{"host": "node1", "zone": "zone1", "rack": "rack-1", "room": "room1"}, topology)
+
+	location := []string{"root=default", "host=node1"}
+	UpdateLocationWithNodeLabels(&location, nodeLabels, topologyLabels)
+	assert.Equal(t, []string{"root=default", "host=node1", "rack=rack-1", "room=room1", "zone=zone1"}, location)
+
+	assert.NoError(t, ValidateCrushTopology(cephv1.CrushTopologySpec{Labels: topologyLabels}))
+	for _, labels := range [][]cephv1.CrushTopologyLabelSpec{
+		{{Key: "example.com/rack", Type: "shelf"}},
+		{{Key: "example.com/rack", Type: "host"}},
+		{{Key: "example.com/room", Type: "room"}, {Key: "example.com/rack", Type: "rack"}},
+		{{Key: "example.com/rack", Type: "rack"}, {Key: "example.com/rack2", Type: "rack"}},
+		{{Key: "example.com/", Type: "rack"}},
+	} {
+		assert.Error(t, ValidateCrushTopology(cephv1.CrushTopologySpec{Labels: labels}))
+	}
+
+	value, err := crushTopologyLabelsEnvVarValue(topologyLabels)
+	assert.NoError(t, err)
+	parsed, err := ParseCrushTopologyLabels(value)
+	assert.NoError(t, err)
+	assert.Equal(t, topologyLabels, parsed)
+	parsed, err = ParseCrushTopologyLabels("")
+	assert.NoError(t, err)
+	assert.Empty(t, parsed)
+}
+
+func TestFormatCrushLocation(t *testing.T) {
+	location := parseCrushLocation("root=default host=node1 zone=zone1 rack=rack1")
+	assert.Equal(t, map[string]string{"root": "default", "host": "node1", "zone": "zone1", "rack": "rack1"}, location)
+	assert.Equal(t, "host=node1 rack=rack1 zone=zone1 root=default", formatCrushLocation(location))
+	assert.Equal(t, "rack=rack1 zone=zone1 root=default", crushMoveTarget(location))
+	assert.Equal(t, "host=node1 shelf=s1 root=default", formatCrushLocation(map[string]string{"root": "default", "shelf": "s1", "host": "node1"}))
+}
@@ -38,7 +38,7 @@ func predicateForNodeWatcher(client client.Client, context *clusterd.Context) pr
 
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			clientCluster := newClientCluster(client, e.MetaNew.GetNamespace(), context)
-			return clientCluster.onK8sNode(e.ObjectNew)
+			return clientCluster.onK8sNodeCrushLocation(e.ObjectOld, e.ObjectNew) || clientCluster.onK8sNode(e.ObjectNew)
 		},
 
 		DeleteFunc: func(e event.DeleteEvent) bool {
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	v1 "k8s.io/api/core/v1"
+)
+
+// requestQuorumRestore passes the restore of the mon quorum requested with the annotation of the cluster to the mons,
+// the restore runs at the next start of the mons
+func requestQuorumRestore(cluster *cluster, clusterObj *cephv1.CephCluster) {
+	cluster.mons.LastQuorumRestore = nil
+	cluster.mons.RestoreQuorumMon = clusterObj.Annotations[mon.RestoreQuorumAnnotation]
+	if cluster.mons.RestoreQuorumMon != "" {
+		logger.Warningf("restore of the mon quorum of cluster %q from mon %q requested", cluster.Namespace, cluster.mons.RestoreQuorumMon)
+	}
+}
+
+// reportQuorumRestore reports the outcome of the restore of the mon quorum with an event, and removes the annotation
+// requesting the restore so that it is not repeated
+func (c *ClusterController) reportQuorumRestore(cluster *cluster) {
+	restore := cluster.mons.LastQuorumRestore
+	if restore == nil {
+		return
+	}
+	cluster.mons.LastQuorumRestore = nil
+	if restore.Restored {
+		opcontroller.RecordOwnerEvent(c.recorder, cluster.Namespace, cluster.ownerRef, v1.EventTypeNormal, opcontroller.EventReasonMonQuorumRestored, "%s", restore.Message)
+	} else {
+		opcontroller.RecordOwnerEvent(c.recorder, cluster.Namespace, cluster.ownerRef, v1.EventTypeWarning, opcontroller.EventReasonMonQuorumRestoreRefused, "%s", restore.Message)
+	}
+	if err := c.removeRestoreQuorumAnnotation(cluster); err != nil {
+		logger.Errorf("failed to remove the quorum restore request of cluster %q. %v", cluster.Namespace, err)
+	}
+}
+
+func (c *ClusterController) removeRestoreQuorumAnnotation(cluster *cluster) error {
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.client.Get(context.TODO(), cluster.namespacedName(), cephCluster); err != nil {
+		return errors.Wrapf(err, "failed to get ceph cluster %q", cluster.crdName)
+	}
+	if _, ok := cephCluster.Annotations[mon.RestoreQuorumAnnotation]; !ok {
+		return nil
+	}
+	delete(cephCluster.Annotations, mon.RestoreQuorumAnnotation)
+	if err := c.client.Update(context.TODO(), cephCluster); err != nil {
+		return errors.Wrapf(err, "failed to remove the %q annotation of ceph cluster %q", mon.RestoreQuorumAnnotation, cluster.crdName)
+	}
+	return nil
+}
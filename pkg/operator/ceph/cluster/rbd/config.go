@@ -19,6 +19,7 @@ package rbd
 import (
 	"fmt"
 
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
@@ -43,7 +44,7 @@ type daemonConfig struct {
 	ownerRef     metav1.OwnerReference
 }
 
-func (r *ReconcileCephRBDMirror) generateKeyring(clusterInfo *client.ClusterInfo, daemonConfig *daemonConfig) (string, error) {
+func (r *ReconcileCephRBDMirror) generateKeyring(clusterInfo *client.ClusterInfo, daemonConfig *daemonConfig, annotations rookv1.Annotations) (string, error) {
 	user := fullDaemonName(daemonConfig.DaemonID)
 	access := []string{"mon", "profile rbd-mirror", "osd", "profile rbd"}
 	s := keyring.GetSecretStore(r.context, clusterInfo, &daemonConfig.ownerRef)
@@ -64,7 +65,7 @@ func (r *ReconcileCephRBDMirror) generateKeyring(clusterInfo *client.ClusterInfo
 	}
 
 	keyring := fmt.Sprintf(keyringTemplate, daemonConfig.DaemonID, key)
-	return keyring, s.CreateOrUpdate(daemonConfig.ResourceName, keyring)
+	return keyring, s.CreateOrUpdateWithMeta(daemonConfig.ResourceName, keyring, annotations, nil)
 }
 
 func fullDaemonName(daemonID string) string {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
@@ -37,6 +38,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -71,6 +73,7 @@ type ReconcileCephRBDMirror struct {
 	context         *clusterd.Context
 	clusterInfo     *cephclient.ClusterInfo
 	client          client.Client
+	recorder        record.EventRecorder
 	scheme          *runtime.Scheme
 	cephClusterSpec *cephv1.ClusterSpec
 }
@@ -89,15 +92,16 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 		panic(err)
 	}
 	return &ReconcileCephRBDMirror{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -128,7 +132,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileCephRBDMirror) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephRBDMirror{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephRBDMirror{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -187,6 +196,7 @@ func (r *ReconcileCephRBDMirror) reconcile(request reconcile.Request) (reconcile
 
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephRBDMirror, cephRBDMirror.Spec.WithClusterDefaults(r.cephClusterSpec))
 
 	// Return and do not requeue
 	logger.Debug("done reconciling ceph rbd mirror")
@@ -204,7 +214,10 @@ func (r *ReconcileCephRBDMirror) reconcileCreateCephRBDMirror(cephRBDMirror *cep
 		}
 	}
 
-	err := r.start(cephRBDMirror)
+	// the daemons are deployed with the defaults of the cluster, the CR itself is left unchanged
+	rbdMirror := cephRBDMirror.DeepCopy()
+	rbdMirror.Spec = rbdMirror.Spec.WithClusterDefaults(r.cephClusterSpec)
+	err := r.start(rbdMirror)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to start rbd mirror")
 	}
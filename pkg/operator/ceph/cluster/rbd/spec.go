@@ -44,8 +44,11 @@ func (r *ReconcileCephRBDMirror) makeDeployment(daemonConfig *daemonConfig, rbdM
 			Volumes:           controller.DaemonVolumes(daemonConfig.DataPathMap, daemonConfig.ResourceName),
 			HostNetwork:       r.cephClusterSpec.Network.IsHost(),
 			PriorityClassName: rbdMirror.Spec.PriorityClassName,
+			SecurityContext:   cephv1.GetPodSecurityContext(r.cephClusterSpec.Security.PodSecurityContexts, cephv1.KeyRBDMirror),
 		},
 	}
+	controller.ApplyLogCollector(&podSpec.Spec, config.RbdMirrorType, fullDaemonName(daemonConfig.DaemonID), r.cephClusterSpec, daemonConfig.DataPathMap)
+
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec.Spec)
 
@@ -57,6 +60,7 @@ func (r *ReconcileCephRBDMirror) makeDeployment(daemonConfig *daemonConfig, rbdM
 		}
 	}
 	rbdMirror.Spec.Placement.ApplyToPodSpec(&podSpec.Spec)
+	config.ApplyConnectionsAnnotation(r.cephClusterSpec.Network, &podSpec.ObjectMeta)
 
 	replicas := int32(1)
 	d := &apps.Deployment{
@@ -76,6 +80,7 @@ func (r *ReconcileCephRBDMirror) makeDeployment(daemonConfig *daemonConfig, rbdM
 	}
 	k8sutil.AddRookVersionLabelToDeployment(d)
 	controller.AddCephVersionLabelToDeployment(r.clusterInfo.CephVersion, d)
+	k8sutil.ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
 
 	return d, nil
 }
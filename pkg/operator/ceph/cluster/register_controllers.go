@@ -21,6 +21,7 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/crash"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/rbd"
+	"github.com/rook/rook/pkg/operator/ceph/commandjob"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/clusterdisruption"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/machinedisruption"
@@ -34,6 +35,7 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/object/zone"
 	"github.com/rook/rook/pkg/operator/ceph/object/zonegroup"
 	"github.com/rook/rook/pkg/operator/ceph/pool"
+	"github.com/rook/rook/pkg/operator/ceph/pool/radosnamespace"
 
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -59,6 +61,7 @@ var MachineDisruptionBudgetAddToManagerFuncs = []func(manager.Manager, *controll
 var AddToManagerFuncs = []func(manager.Manager, *clusterd.Context) error{
 	crash.Add,
 	pool.Add,
+	radosnamespace.Add,
 	objectuser.Add,
 	realm.Add,
 	zonegroup.Add,
@@ -67,6 +70,7 @@ var AddToManagerFuncs = []func(manager.Manager, *clusterd.Context) error{
 	file.Add,
 	nfs.Add,
 	rbd.Add,
+	commandjob.Add,
 }
 
 // AddToManager adds all the registered controllers to the passed manager.
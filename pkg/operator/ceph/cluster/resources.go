@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resourcePhase is the phase of a CR of the cluster namespace
+type resourcePhase struct {
+	kind  string
+	name  string
+	phase string
+}
+
+// resourcesStatus summarizes the phases of the CRs of the cluster namespace
+func resourcesStatus(c client.Client, namespace string) (*cephv1.ResourcesStatus, error) {
+	phases, err := listResourcePhases(c, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &cephv1.ResourcesStatus{LastChecked: formatTime(time.Now().UTC())}
+	ready := 0
+	for _, r := range phases {
+		if r.phase == string(cephv1.ConditionReady) || r.phase == string(cephv1.ConditionConnected) {
+			ready++
+			continue
+		}
+		phase := r.phase
+		if phase == "" {
+			phase = "Unknown"
+		}
+		status.NotReady = append(status.NotReady, fmt.Sprintf("%s/%s: %s", r.kind, r.name, phase))
+	}
+	sort.Strings(status.NotReady)
+	status.Ready = fmt.Sprintf("%d/%d", ready, len(phases))
+	return status, nil
+}
+
+// listResourcePhases lists the phases of the CRs of the cluster namespace which report a phase
+func listResourcePhases(c client.Client, namespace string) ([]resourcePhase, error) {
+	phases := []resourcePhase{}
+	opts := []client.ListOption{client.InNamespace(namespace)}
+
+	pools := &cephv1.CephBlockPoolList{}
+	if err := c.List(context.TODO(), pools, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list block pools")
+	}
+	for _, pool := range pools.Items {
+		r := resourcePhase{kind: "CephBlockPool", name: pool.Name}
+		if pool.Status != nil {
+			r.phase = pool.Status.Phase
+		}
+		phases = append(phases, r)
+	}
+
+	filesystems := &cephv1.CephFilesystemList{}
+	if err := c.List(context.TODO(), filesystems, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list filesystems")
+	}
+	for _, fs := range filesystems.Items {
+		r := resourcePhase{kind: "CephFilesystem", name: fs.Name}
+		if fs.Status != nil {
+			r.phase = fs.Status.Phase
+		}
+		phases = append(phases, r)
+	}
+
+	objectStores := &cephv1.CephObjectStoreList{}
+	if err := c.List(context.TODO(), objectStores, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list object stores")
+	}
+	for _, store := range objectStores.Items {
+		r := resourcePhase{kind: "CephObjectStore", name: store.Name}
+		if store.Status != nil {
+			r.phase = string(store.Status.Phase)
+		}
+		phases = append(phases, r)
+	}
+
+	objectUsers := &cephv1.CephObjectStoreUserList{}
+	if err := c.List(context.TODO(), objectUsers, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list object store users")
+	}
+	for _, user := range objectUsers.Items {
+		r := resourcePhase{kind: "CephObjectStoreUser", name: user.Name}
+		if user.Status != nil {
+			r.phase = user.Status.Phase
+		}
+		phases = append(phases, r)
+	}
+
+	nfses := &cephv1.CephNFSList{}
+	if err := c.List(context.TODO(), nfses, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list nfs servers")
+	}
+	for _, nfs := range nfses.Items {
+		phases = append(phases, resourcePhase{kind: "CephNFS", name: nfs.Name, phase: statusPhase(nfs.Status)})
+	}
+
+	mirrors := &cephv1.CephRBDMirrorList{}
+	if err := c.List(context.TODO(), mirrors, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list rbd mirrors")
+	}
+	for _, mirror := range mirrors.Items {
+		phases = append(phases, resourcePhase{kind: "CephRBDMirror", name: mirror.Name, phase: statusPhase(mirror.Status)})
+	}
+
+	return phases, nil
+}
+
+func statusPhase(status *cephv1.Status) string {
+	if status == nil {
+		return ""
+	}
+	return status.Phase
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResourcesStatus(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(s))
+	objects := []runtime.Object{
+		&cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: "ns"}, Status: &cephv1.CephBlockPoolStatus{Phase: "Ready"}},
+		&cephv1.CephFilesystem{ObjectMeta: metav1.ObjectMeta{Name: "myfs", Namespace: "ns"}, Status: &cephv1.CephFilesystemStatus{Phase: "Ready"}},
+		&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: "ns"}, Status: &cephv1.ObjectStoreStatus{Phase: cephv1.ConditionFailure}},
+		&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "external-store", Namespace: "ns"}, Status: &cephv1.ObjectStoreStatus{Phase: cephv1.ConditionConnected}},
+		&cephv1.CephNFS{ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: "ns"}},
+		// the resources of other namespaces are not counted
+		&cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "otherpool", Namespace: "other"}},
+	}
+	cl := fake.NewFakeClientWithScheme(s, objects...)
+
+	status, err := resourcesStatus(cl, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, "3/5", status.Ready)
+	assert.Equal(t, []string{"CephNFS/my-nfs: Unknown", "CephObjectStore/my-store: Failure"}, status.NotReady)
+	assert.NotEqual(t, "", status.LastChecked)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/file/mds"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+)
+
+// clusterRestartDaemons are the daemons of the restart requests of the cluster, in the order of the upgrades
+var clusterRestartDaemons = []opcontroller.RestartDaemon{
+	{Type: "mon", Selector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, mon.AppName), IDLabel: "mon"},
+	{Type: "mgr", Selector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, mgr.AppName), IDLabel: "mgr"},
+	{Type: "osd", Selector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, osd.AppName), IDLabel: osd.OsdIdLabelKey},
+	{Type: "mds", Selector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, mds.AppName), IDLabel: "mds"},
+	{Type: "rgw", Selector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, object.AppName), IDLabel: "rgw"},
+}
+
+// restartDaemons continues the rolling restart of the daemons requested with the annotation of the cluster, once the
+// daemons are reconciled. A daemon is restarted at each reconcile, the cluster is requeued until all the daemons are
+// restarted.
+func (c *ClusterController) restartDaemons(cluster *cluster, clusterObj *cephv1.CephCluster) error {
+	daemons, err := opcontroller.PendingRestart(clusterObj, clusterRestartDaemons)
+	if err != nil || daemons == nil {
+		return err
+	}
+	logger.Infof("restarting the daemons of cluster %q for request %q", cluster.Namespace, clusterObj.Annotations[opcontroller.RestartAnnotation])
+	done, err := opcontroller.RestartNextDaemon(c.context, cluster.ClusterInfo, c.client, clusterObj, daemons)
+	if err != nil {
+		return err
+	}
+	if !done {
+		cluster.requeue(opcontroller.WaitForRequeueIfRestartInProgress.RequeueAfter)
+		return nil
+	}
+	return opcontroller.CompleteRestart(c.client, c.recorder, clusterObj)
+}
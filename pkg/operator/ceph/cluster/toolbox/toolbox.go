@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package toolbox deploys the rook-ceph-tools deployment running the ceph CLI with the admin keyring
+package toolbox
+
+import (
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AppName is the name of the toolbox deployment and the value of the "app" label of its pods
+	AppName = "rook-ceph-tools"
+
+	monConfigVolumeName = "rook-ceph-mon-config"
+	monConfigMountPath  = "/etc/rook-ceph-config"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", "ceph-toolbox")
+
+// the config is written again when the mon endpoints change in the mounted secret, since the ceph CLI reads it at
+// each command
+var toolboxScript = `
+set -o errexit
+write_config() {
+  cat > /etc/ceph/ceph.conf.tmp <<CONF
+[global]
+mon_host = $1
+
+[client.admin]
+keyring = $ROOK_KEYRING_FILE
+CONF
+  mv /etc/ceph/ceph.conf.tmp /etc/ceph/ceph.conf
+}
+
+mon_host=""
+while true; do
+  current=$(cat "$ROOK_MON_HOST_FILE")
+  if [ "$current" != "$mon_host" ]; then
+    mon_host="$current"
+    write_config "$mon_host"
+    echo "$(date) mon endpoints are $mon_host"
+  fi
+  sleep 10
+done
+`
+
+// IsEnabled returns whether the operator deploys the toolbox of the cluster
+func IsEnabled(spec *cephv1.ClusterSpec) bool {
+	return spec.Toolbox.Enabled && !spec.External.Enable
+}
+
+// Reconcile creates or updates the toolbox deployment with the image of the cluster, or deletes it when the toolbox
+// is disabled. A toolbox deployed from the manifest is replaced, but it is only deleted when it was deployed by the
+// operator.
+func Reconcile(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec *cephv1.ClusterSpec) error {
+	if !IsEnabled(spec) {
+		return deleteToolbox(context, clusterInfo)
+	}
+	if spec.Security.RestrictAdminKey {
+		// the admin keyring secret mounted by the toolbox is deleted when the admin key is restricted
+		logger.Warningf("the toolbox is not deployed since it needs the admin key which is restricted")
+		return deleteToolbox(context, clusterInfo)
+	}
+
+	d, err := makeDeployment(clusterInfo, spec)
+	if err != nil {
+		return err
+	}
+	if err := k8sutil.CreateDeployment(context.Clientset, AppName, clusterInfo.Namespace, d); err != nil {
+		return errors.Wrapf(err, "failed to create or update the %q deployment", AppName)
+	}
+	logger.Debugf("toolbox deployment %q reconciled", AppName)
+	return nil
+}
+
+func deleteToolbox(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) error {
+	d, err := context.Clientset.AppsV1().Deployments(clusterInfo.Namespace).Get(AppName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get the %q deployment", AppName)
+	}
+	if !isOwnedBy(d, clusterInfo.OwnerRef) {
+		logger.Debugf("toolbox deployment %q was not deployed by the operator, not deleting it", AppName)
+		return nil
+	}
+	logger.Infof("deleting the toolbox deployment %q since the toolbox is disabled", AppName)
+	if err := k8sutil.DeleteDeployment(context.Clientset, clusterInfo.Namespace, AppName); err != nil {
+		return errors.Wrapf(err, "failed to delete the %q deployment", AppName)
+	}
+	return nil
+}
+
+func isOwnedBy(d *apps.Deployment, ownerRef metav1.OwnerReference) bool {
+	for _, ref := range d.OwnerReferences {
+		if ref.UID == ownerRef.UID {
+			return true
+		}
+	}
+	return false
+}
+
+func makeDeployment(clusterInfo *cephclient.ClusterInfo, spec *cephv1.ClusterSpec) (*apps.Deployment, error) {
+	image := spec.Toolbox.Image
+	if image == "" {
+		image = spec.CephVersion.Image
+	}
+	// the selector of the toolbox manifest, so that a toolbox deployed from the manifest is updated
+	selector := map[string]string{k8sutil.AppAttr: AppName}
+	labels := controller.AppLabels(AppName, clusterInfo.Namespace)
+
+	container := v1.Container{
+		Name:    AppName,
+		Command: []string{"/bin/bash", "-c", toolboxScript},
+		Image:   image,
+		Env: []v1.EnvVar{
+			{Name: "ROOK_KEYRING_FILE", Value: keyring.VolumeMount().AdminKeyringFilePath()},
+			{Name: "ROOK_MON_HOST_FILE", Value: monConfigMountPath + "/mon_host"},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			keyring.VolumeMount().Admin(),
+			{Name: monConfigVolumeName, MountPath: monConfigMountPath, ReadOnly: true},
+		},
+		Resources: cephv1.GetToolboxResources(spec.Resources),
+	}
+
+	podSpec := v1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   AppName,
+			Labels: labels,
+		},
+		Spec: v1.PodSpec{
+			Containers:    []v1.Container{container},
+			RestartPolicy: v1.RestartPolicyAlways,
+			Volumes: []v1.Volume{
+				keyring.Volume().Admin(),
+				{Name: monConfigVolumeName, VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: config.StoreName}}},
+			},
+			HostNetwork:       spec.Network.IsHost(),
+			PriorityClassName: cephv1.GetToolboxPriorityClassName(spec.PriorityClassNames),
+			SecurityContext:   cephv1.GetPodSecurityContext(spec.Security.PodSecurityContexts, cephv1.KeyToolbox),
+		},
+	}
+	// Replace default unreachable node toleration
+	k8sutil.AddUnreachableNodeToleration(&podSpec.Spec)
+	if spec.Network.IsHost() {
+		podSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
+	} else if spec.Network.IsMultus() {
+		if err := k8sutil.ApplyMultus(spec.Network.NetworkSpec, &podSpec.ObjectMeta); err != nil {
+			return nil, err
+		}
+	}
+	cephv1.GetToolboxPlacement(spec.Placement).ApplyToPodSpec(&podSpec.Spec)
+
+	replicas := int32(1)
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AppName,
+			Namespace: clusterInfo.Namespace,
+			Labels:    labels,
+		},
+		Spec: apps.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: podSpec,
+			Replicas: &replicas,
+		},
+	}
+	k8sutil.AddRookVersionLabelToDeployment(d)
+	controller.AddCephVersionLabelToDeployment(clusterInfo.CephVersion, d)
+	k8sutil.SetOwnerRef(&d.ObjectMeta, &clusterInfo.OwnerRef)
+	k8sutil.ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
+	return d, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toolbox
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileToolbox(t *testing.T) {
+	clientset := test.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.OwnerRef = metav1.OwnerReference{Name: "my-cluster", UID: types.UID("cluster-uid")}
+	spec := &cephv1.ClusterSpec{Toolbox: cephv1.ToolboxSpec{Enabled: true}}
+	spec.CephVersion.Image = "ceph/ceph:v15.2.4"
+
+	// the toolbox runs the ceph image of the cluster
+	assert.NoError(t, Reconcile(context, clusterInfo, spec))
+	d, err := clientset.AppsV1().Deployments("rook-ceph").Get(AppName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ceph/ceph:v15.2.4", d.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, map[string]string{"app": AppName}, d.Spec.Selector.MatchLabels)
+
+	// the toolbox is updated along with the image of the cluster
+	spec.CephVersion.Image = "ceph/ceph:v15.2.5"
+	assert.NoError(t, Reconcile(context, clusterInfo, spec))
+	d, err = clientset.AppsV1().Deployments("rook-ceph").Get(AppName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ceph/ceph:v15.2.5", d.Spec.Template.Spec.Containers[0].Image)
+
+	// the toolbox deployed by the operator is deleted when disabled
+	spec.Toolbox.Enabled = false
+	assert.NoError(t, Reconcile(context, clusterInfo, spec))
+	_, err = clientset.AppsV1().Deployments("rook-ceph").Get(AppName, metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+
+	// a toolbox deployed from the manifest is kept
+	manifest := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: AppName, Namespace: "rook-ceph"}}
+	_, err = clientset.AppsV1().Deployments("rook-ceph").Create(manifest)
+	assert.NoError(t, err)
+	assert.NoError(t, Reconcile(context, clusterInfo, spec))
+	_, err = clientset.AppsV1().Deployments("rook-ceph").Get(AppName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// the toolbox is deleted when the admin key is restricted since it mounts the admin keyring
+	assert.NoError(t, clientset.AppsV1().Deployments("rook-ceph").Delete(AppName, &metav1.DeleteOptions{}))
+	spec.Toolbox.Enabled = true
+	assert.NoError(t, Reconcile(context, clusterInfo, spec))
+	_, err = clientset.AppsV1().Deployments("rook-ceph").Get(AppName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	spec.Security.RestrictAdminKey = true
+	assert.NoError(t, Reconcile(context, clusterInfo, spec))
+	_, err = clientset.AppsV1().Deployments("rook-ceph").Get(AppName, metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+	spec.Security.RestrictAdminKey = false
+
+	// no toolbox for external clusters
+	spec.Toolbox.Enabled = true
+	spec.External.Enable = true
+	assert.False(t, IsEnabled(spec))
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// upgradeChecksDaemonTypes are the daemon types whose upgrade checks may be overridden
+var upgradeChecksDaemonTypes = sets.NewString(config.MonType, config.MgrType, config.OsdType, config.MdsType, config.RgwType, config.RbdMirrorType, "nfs")
+
+// validateUpgradeChecks refuses the unknown daemon types and the invalid wait timeouts of the upgrade checks
+func validateUpgradeChecks(upgradeChecks *cephv1.UpgradeChecksSpec) error {
+	if upgradeChecks == nil {
+		return nil
+	}
+	if err := validateWaitTimeout(upgradeChecks.WaitTimeout); err != nil {
+		return err
+	}
+	for daemonType, daemonChecks := range upgradeChecks.Daemons {
+		if !upgradeChecksDaemonTypes.Has(daemonType) {
+			return errors.Errorf("unknown daemon type %q, must be one of %v", daemonType, upgradeChecksDaemonTypes.List())
+		}
+		if err := validateWaitTimeout(daemonChecks.WaitTimeout); err != nil {
+			return errors.Wrapf(err, "invalid upgrade checks of the %s daemons", daemonType)
+		}
+	}
+	return nil
+}
+
+func validateWaitTimeout(waitTimeout string) error {
+	if waitTimeout == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(waitTimeout)
+	if err != nil {
+		return errors.Wrapf(err, "invalid wait timeout %q", waitTimeout)
+	}
+	if timeout <= 0 {
+		return errors.Errorf("wait timeout %q must be positive", waitTimeout)
+	}
+	return nil
+}
+
+// newUpgradeStatus returns the status of the upgrade to the image. The upgrade in progress to the same image is
+// resumed, otherwise a new upgrade starts from the previous image of the cluster.
+func newUpgradeStatus(previous *cephv1.UpgradeStatus, previousImage, image string) *cephv1.UpgradeStatus {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if previous != nil && previous.State == cephv1.UpgradeInProgress && previous.Image == image {
+		upgrade := previous.DeepCopy()
+		upgrade.LastUpdated = now
+		return upgrade
+	}
+	return &cephv1.UpgradeStatus{
+		State:         cephv1.UpgradeInProgress,
+		Image:         image,
+		PreviousImage: previousImage,
+		Rollback:      isUpgradeRollback(previous, image),
+		StartTime:     now,
+		LastUpdated:   now,
+	}
+}
+
+// isUpgradeRollback returns whether the image reverts an upgrade which did not complete to its previous image
+func isUpgradeRollback(previous *cephv1.UpgradeStatus, image string) bool {
+	if previous == nil || previous.State == cephv1.UpgradeCompleted {
+		return false
+	}
+	return image != previous.Image && image == previous.PreviousImage
+}
+
+// validateRollback refuses to roll back the mons to a previous release once they run a newer release, since the
+// mons update the format of their store when they are upgraded. The other daemons may be rolled back.
+func validateRollback(imageSpecVersion cephver.CephVersion, runningVersions client.CephDaemonsVersions, allowUnsupported bool) error {
+	for v := range runningVersions.Mon {
+		runningVersion, err := cephver.ExtractCephVersion(v)
+		if err != nil {
+			logger.Warningf("failed to extract running mon version. %v", err)
+			continue
+		}
+		if runningVersion.Major <= imageSpecVersion.Major {
+			continue
+		}
+		changeErr := errors.Wrapf(errUnsupportedVersionChange, "the mons already run %s, rolling them back to the previous release %s is not supported",
+			runningVersion.String(), imageSpecVersion.String())
+		if !allowUnsupported {
+			return changeErr
+		}
+		logger.Warningf("allowUnsupported is set, proceeding anyway. %v", changeErr)
+	}
+	return nil
+}
+
+// cancelUpgrade marks the upgrade as cancelled when the image of the cluster changed during the upgrade, the next
+// orchestration upgrades the daemons to the new image
+func (c *ClusterController) cancelUpgrade(cluster *cluster, cancelErr error) {
+	logger.Infof("%v. stopping the upgrade before the next daemon", cancelErr)
+	opcontroller.RecordOwnerEvent(c.recorder, cluster.Namespace, cluster.ownerRef, v1.EventTypeNormal, opcontroller.EventReasonUpgradeCancelled, "%v", cancelErr)
+	cluster.isUpgrade = false
+
+	cephCluster := &cephv1.CephCluster{}
+	if err := c.client.Get(context.TODO(), cluster.namespacedName(), cephCluster); err != nil {
+		logger.Errorf("failed to retrieve ceph cluster %q to cancel the upgrade. %v", cluster.namespacedName().Name, err)
+		return
+	}
+	if cephCluster.Status.Upgrade == nil {
+		return
+	}
+	cephCluster.Status.Upgrade.State = cephv1.UpgradeCancelled
+	cephCluster.Status.Upgrade.LastError = cancelErr.Error()
+	cephCluster.Status.Upgrade.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+	if err := opcontroller.UpdateStatus(c.client, cephCluster); err != nil {
+		logger.Errorf("failed to update the upgrade status of cluster %q. %v", cluster.namespacedName().Name, err)
+	}
+}
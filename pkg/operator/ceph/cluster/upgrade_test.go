@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUpgradeStatus(t *testing.T) {
+	// a new upgrade starts from the previous image
+	upgrade := newUpgradeStatus(nil, "ceph/ceph:v14", "ceph/ceph:v15")
+	assert.Equal(t, cephv1.UpgradeInProgress, upgrade.State)
+	assert.Equal(t, "ceph/ceph:v15", upgrade.Image)
+	assert.Equal(t, "ceph/ceph:v14", upgrade.PreviousImage)
+	assert.False(t, upgrade.Rollback)
+	assert.NotEmpty(t, upgrade.StartTime)
+
+	// the upgrade in progress is resumed
+	upgrade.DaemonsDone = 3
+	resumed := newUpgradeStatus(upgrade, "ceph/ceph:v15", "ceph/ceph:v15")
+	assert.Equal(t, 3, resumed.DaemonsDone)
+	assert.Equal(t, "ceph/ceph:v14", resumed.PreviousImage)
+
+	// reverting to the previous image rolls back the daemons already upgraded
+	upgrade.State = cephv1.UpgradeCancelled
+	rollback := newUpgradeStatus(upgrade, "ceph/ceph:v15", "ceph/ceph:v14")
+	assert.True(t, rollback.Rollback)
+	assert.Equal(t, 0, rollback.DaemonsDone)
+	assert.Equal(t, "ceph/ceph:v15", rollback.PreviousImage)
+
+	// going back to the previous image once the upgrade completed is a downgrade
+	upgrade.State = cephv1.UpgradeCompleted
+	assert.False(t, newUpgradeStatus(upgrade, "ceph/ceph:v15", "ceph/ceph:v14").Rollback)
+	assert.False(t, isUpgradeRollback(upgrade, "ceph/ceph:v14"))
+}
+
+func TestValidateRollback(t *testing.T) {
+	runningVersions := client.CephDaemonsVersions{Mon: map[string]int{
+		"ceph version 15.2.4 (3a54b2b6d167d4a2a19e003a705696d4fe619afc) octopus (stable)": 3,
+	}}
+
+	// the daemons may be rolled back to a previous version of the release of the mons
+	assert.NoError(t, validateRollback(cephver.CephVersion{Major: 15, Minor: 2, Extra: 1}, runningVersions, false))
+
+	// the mons are not rolled back to a previous release
+	err := validateRollback(cephver.CephVersion{Major: 14, Minor: 2, Extra: 10}, runningVersions, false)
+	assert.Equal(t, errUnsupportedVersionChange, errors.Cause(err))
+	assert.NoError(t, validateRollback(cephver.CephVersion{Major: 14, Minor: 2, Extra: 10}, runningVersions, true))
+}
+
+func TestValidateUpgradeChecks(t *testing.T) {
+	assert.NoError(t, validateUpgradeChecks(nil))
+	upgradeChecks := &cephv1.UpgradeChecksSpec{
+		WaitTimeout: "20m",
+		Daemons:     map[string]cephv1.DaemonUpgradeChecksSpec{"osd": {WaitTimeout: "1h"}, "rbd-mirror": {}},
+	}
+	assert.NoError(t, validateUpgradeChecks(upgradeChecks))
+
+	// the wait timeouts must be positive durations
+	upgradeChecks.Daemons["osd"] = cephv1.DaemonUpgradeChecksSpec{WaitTimeout: "-5m"}
+	assert.Error(t, validateUpgradeChecks(upgradeChecks))
+	upgradeChecks.Daemons["osd"] = cephv1.DaemonUpgradeChecksSpec{}
+	upgradeChecks.WaitTimeout = "20"
+	assert.Error(t, validateUpgradeChecks(upgradeChecks))
+
+	// the daemon types must be known
+	upgradeChecks.WaitTimeout = ""
+	upgradeChecks.Daemons["osds"] = cephv1.DaemonUpgradeChecksSpec{}
+	assert.Error(t, validateUpgradeChecks(upgradeChecks))
+}
@@ -25,11 +25,20 @@ import (
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	daemonclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/notification"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil/cmdreporter"
+	v1 "k8s.io/api/core/v1"
 )
 
+// maxMajorVersionJump is the largest supported upgrade, from a release to the release after the next one
+const maxMajorVersionJump = 2
+
+// errUnsupportedVersionChange is returned when the version of the image is a downgrade or skips more than one release
+var errUnsupportedVersionChange = errors.New("unsupported ceph version change")
+
 func (c *ClusterController) detectAndValidateCephVersion(cluster *cluster) (*cephver.CephVersion, bool, error) {
 	version, err := cluster.detectCephVersion(c.rookImage, cluster.Spec.CephVersion.Image, detectCephVersionTimeout)
 	if err != nil {
@@ -38,11 +47,14 @@ func (c *ClusterController) detectAndValidateCephVersion(cluster *cluster) (*cep
 
 	logger.Info("validating ceph version from provided image")
 	if err := cluster.validateCephVersion(version); err != nil {
+		if errors.Cause(err) == errUnsupportedVersionChange {
+			config.ConditionExport(c.context, cluster.namespacedName(), cephv1.ConditionFailure, v1.ConditionTrue, "UnsupportedVersionChange", err.Error())
+		}
 		return nil, cluster.isUpgrade, err
 	}
 
 	// Update ceph version field in cluster object status
-	c.updateClusterCephVersion(cluster.Spec.CephVersion.Image, *version)
+	c.updateClusterCephVersion(cluster, cluster.Spec.CephVersion.Image, *version, cluster.isUpgrade)
 
 	return version, cluster.isUpgrade, nil
 }
@@ -70,6 +82,36 @@ func (c *cluster) printOverallCephVersion() {
 	}
 }
 
+// validateVersionChange refuses the image version which is lower than a running version or which skips more than one
+// release after the lowest running version, unless the unsupported versions are allowed
+func validateVersionChange(imageSpecVersion cephver.CephVersion, runningVersions client.CephDaemonsVersions, allowUnsupported bool) error {
+	for v := range runningVersions.Overall {
+		runningVersion, err := cephver.ExtractCephVersion(v)
+		if err != nil {
+			// the version of the image may still be compared to the other running versions
+			logger.Warningf("failed to extract running ceph version. %v", err)
+			continue
+		}
+
+		var changeErr error
+		if cephver.IsInferior(imageSpecVersion, *runningVersion) {
+			changeErr = errors.Wrapf(errUnsupportedVersionChange, "image spec version %s is lower than the running version %s, downgrading is not supported",
+				imageSpecVersion.String(), runningVersion.String())
+		} else if imageSpecVersion.Major-runningVersion.Major > maxMajorVersionJump {
+			changeErr = errors.Wrapf(errUnsupportedVersionChange, "image spec version %s skips more than one release after the running version %s, upgrade to an intermediate release first",
+				imageSpecVersion.String(), runningVersion.String())
+		}
+		if changeErr == nil {
+			continue
+		}
+		if !allowUnsupported {
+			return changeErr
+		}
+		logger.Warningf("allowUnsupported is set, proceeding anyway. %v", changeErr)
+	}
+	return nil
+}
+
 // This function compare the Ceph spec image and the cluster running version
 // It returns true if the image is different and false if identical
 func diffImageSpecAndClusterRunningVersion(imageSpecVersion cephver.CephVersion, runningVersions client.CephDaemonsVersions) (bool, error) {
@@ -210,7 +252,20 @@ func (c *cluster) validateCephVersion(version *cephver.CephVersion) error {
 	}
 
 	runningVersions := *versions
+	changeErr := validateVersionChange(*version, runningVersions, c.Spec.CephVersion.AllowUnsupported)
+	if c.upgradeRollback {
+		// the daemons already upgraded are rolled back to the previous image
+		changeErr = validateRollback(*version, runningVersions, c.Spec.CephVersion.AllowUnsupported)
+	}
+	if err := changeErr; err != nil {
+		notification.Warning(notification.ReasonUpgradeFailed, c.Namespace, c.crdName, "refusing to change the ceph version to %q. %v", version.String(), err)
+		controller.RecordOwnerEvent(c.recorder, c.Namespace, c.ownerRef, v1.EventTypeWarning, controller.EventReasonUpgradeFailed, "refusing to change the ceph version to %q. %v", version.String(), err)
+		return err
+	}
 	differentImages, err := diffImageSpecAndClusterRunningVersion(*version, runningVersions)
+	if c.upgradeRollback {
+		differentImages, err = true, nil
+	}
 	if err != nil {
 		logger.Errorf("failed to determine if we should upgrade or not. %v", err)
 		// we shouldn't block the orchestration if we can't determine the version of the image spec, we proceed anyway in best effort
@@ -227,11 +282,13 @@ func (c *cluster) validateCephVersion(version *cephver.CephVersion) error {
 			if c.Spec.SkipUpgradeChecks {
 				logger.Warning("ceph is not healthy but SkipUpgradeChecks is set, forcing upgrade.")
 			} else {
+				notification.Warning(notification.ReasonUpgradeFailed, c.Namespace, c.crdName, "ceph is not healthy, refusing to upgrade to %q", version.String())
+				controller.RecordOwnerEvent(c.recorder, c.Namespace, c.ownerRef, v1.EventTypeWarning, controller.EventReasonUpgradeFailed, "ceph is not healthy, refusing to upgrade to %q", version.String())
 				return errors.Errorf("ceph status in namespace %s is not healthy, refusing to upgrade. fix the cluster and re-edit the cluster CR to trigger a new orchestation update", c.Namespace)
 			}
 		}
 		// This is an upgrade
-		logger.Info("upgrading ceph cluster to %q", version.String())
+		logger.Infof("upgrading ceph cluster to %q", version.String())
 		c.isUpgrade = true
 	}
 
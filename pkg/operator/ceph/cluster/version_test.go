@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
@@ -112,6 +113,29 @@ func TestDiffImageSpecAndClusterRunningVersion(t *testing.T) {
 	assert.False(t, m)
 }
 
+func TestValidateVersionChange(t *testing.T) {
+	runningVersions := client.CephDaemonsVersions{Overall: map[string]int{
+		"ceph version 14.2.10 (3a54b2b6d167d4a2a19e003a705696d4fe619afc) nautilus (stable)": 2,
+		"ceph version 15.2.4 (3a54b2b6d167d4a2a19e003a705696d4fe619afc) octopus (stable)":   1,
+	}}
+
+	// an upgrade to the next release or the release after it is valid
+	assert.NoError(t, validateVersionChange(cephver.CephVersion{Major: 15, Minor: 2, Extra: 5}, runningVersions, false))
+	assert.NoError(t, validateVersionChange(cephver.CephVersion{Major: 16, Minor: 2, Extra: 0}, runningVersions, false))
+
+	// the downgrades are refused
+	err := validateVersionChange(cephver.CephVersion{Major: 15, Minor: 2, Extra: 1}, runningVersions, false)
+	assert.Error(t, err)
+	assert.Equal(t, errUnsupportedVersionChange, errors.Cause(err))
+
+	// the upgrades skipping more than one release after the lowest running version are refused
+	assert.Error(t, validateVersionChange(cephver.CephVersion{Major: 17, Minor: 2, Extra: 0}, runningVersions, false))
+
+	// unless the unsupported versions are allowed
+	assert.NoError(t, validateVersionChange(cephver.CephVersion{Major: 15, Minor: 2, Extra: 1}, runningVersions, true))
+	assert.NoError(t, validateVersionChange(cephver.CephVersion{Major: 17, Minor: 2, Extra: 0}, runningVersions, true))
+}
+
 func TestMinVersion(t *testing.T) {
 	c := testSpec(t)
 	c.Spec.CephVersion.AllowUnsupported = true
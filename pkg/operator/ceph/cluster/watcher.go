@@ -24,6 +24,8 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	discoverDaemon "github.com/rook/rook/pkg/daemon/discover"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -100,6 +102,11 @@ func (c *clientCluster) onK8sNode(object runtime.Object) bool {
 
 		// If they are OSDs in the CRUSH map and if the host exists in the CRUSH map, don't reconcile
 		if osds != "" {
+			// the new devices of the node are provisioned once their quarantine is over and they are approved
+			if osd.NewDevicesReady(c.context.Clientset, cluster, node) {
+				logger.Infof("node watcher: new devices of node %q are ready for osds in cluster %q", nodeName, cluster.Namespace)
+				return true
+			}
 			// This is Debug level because the node receives frequent updates and this will polute the logs
 			logger.Debugf("node watcher: node %q is already an OSD node with %q", nodeName, osds)
 		} else {
@@ -111,6 +118,29 @@ func (c *clientCluster) onK8sNode(object runtime.Object) bool {
 	return false
 }
 
+// onK8sNodeCrushLocation is triggered when the crush topology labels of a node change or the move of its osds to a
+// new crush location is confirmed
+func (c *clientCluster) onK8sNodeCrushLocation(oldObj, newObj runtime.Object) bool {
+	oldNode, ok := oldObj.(*v1.Node)
+	if !ok {
+		return false
+	}
+	newNode, ok := newObj.(*v1.Node)
+	if !ok {
+		return false
+	}
+
+	cluster := c.getCephCluster()
+	if cluster.Status.Phase != cephv1.ConditionReady {
+		return false
+	}
+	if osd.CrushLocationChanged(oldNode, newNode, cluster.Spec.CrushTopology) {
+		logger.Infof("node watcher: crush location of node %q changed in cluster %q", newNode.Name, cluster.Namespace)
+		return true
+	}
+	return false
+}
+
 // onDeviceCMUpdate is trigger when the hot plug config map is updated
 func (c *clientCluster) onDeviceCMUpdate(oldObj, newObj runtime.Object) bool {
 	oldCm, ok := oldObj.(*v1.ConfigMap)
@@ -178,5 +208,5 @@ func (c *clientCluster) getCephCluster() *cephv1.CephCluster {
 		return &cephv1.CephCluster{}
 	}
 
-	return &clusterList.Items[0]
+	return opcontroller.NamespaceCephCluster(clusterList.Items)
 }
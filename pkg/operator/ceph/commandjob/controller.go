@@ -0,0 +1,329 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package commandjob runs the commands of the CephCommandJob CRs once in a pod and stores their output in the status
+package commandjob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-command-job-controller"
+
+	// PhasePending is the phase of a command job whose pod is not running yet
+	PhasePending = "Pending"
+	// PhaseRunning is the phase of a command job whose command is running
+	PhaseRunning = "Running"
+	// PhaseSucceeded is the phase of a command job whose command exited with 0
+	PhaseSucceeded = "Succeeded"
+	// PhaseFailed is the phase of a command job whose command failed or could not run
+	PhaseFailed = "Failed"
+
+	// the output of the commands is truncated to keep the size of the CR reasonable
+	maxOutputBytes = 64 * 1024
+	// the time left to the kubelet to stop a pod which passed its deadline
+	deadlineGracePeriod = time.Minute
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+// allow the logs of the pods to be stubbed by the unit tests
+var getPodLogs = streamPodLogs
+
+var cephCommandJobKind = reflect.TypeOf(cephv1.CephCommandJob{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephCommandJobKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephCommandJob reconciles a CephCommandJob object
+type ReconcileCephCommandJob struct {
+	context  *clusterd.Context
+	client   client.Client
+	recorder record.EventRecorder
+	scheme   *runtime.Scheme
+}
+
+// Add creates a new CephCommandJob Controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	// Add the cephv1 scheme to the manager scheme so that the controller knows about it
+	mgrScheme := mgr.GetScheme()
+	if err := cephv1.AddToScheme(mgr.GetScheme()); err != nil {
+		panic(err)
+	}
+	return &ReconcileCephCommandJob{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes on the CephCommandJob CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephCommandJob{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	// Watch the pods of the commands, every change of their phase is reconciled
+	err = c.Watch(&source.Kind{Type: &v1.Pod{TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: v1.SchemeGroupVersion.String()}}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &cephv1.CephCommandJob{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephCommandJob object and runs its command once
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephCommandJob) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
+	start := time.Now()
+	reconcileResponse, err := r.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephCommandJob{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephCommandJob{}, request.NamespacedName, reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephCommandJob) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephCommandJob instance
+	job := &cephv1.CephCommandJob{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, job)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCommandJob resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get CephCommandJob")
+	}
+
+	// The command of a job only runs once
+	if job.Status != nil && (job.Status.Phase == PhaseSucceeded || job.Status.Phase == PhaseFailed) {
+		logger.Debugf("command job %q already completed", request.NamespacedName)
+		return reconcile.Result{}, nil
+	}
+	if job.Status == nil {
+		job.Status = &cephv1.CommandJobStatus{}
+	}
+
+	// The commands also run when the cluster is not healthy since they are used to troubleshoot it, only a
+	// CephCluster must be present
+	cephCluster, _, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !cephClusterExists {
+		logger.Debugf("CephCluster resource not found in namespace %q, retrying in %q.", request.NamespacedName.Namespace, reconcileResponse.RequeueAfter.String())
+		return reconcileResponse, nil
+	}
+	if cephCluster.Spec.Security.RestrictAdminKey {
+		return r.fail(job, "the command is not run since it needs the admin key which is restricted")
+	}
+	if err := validateSpec(&job.Spec); err != nil {
+		return r.fail(job, err.Error())
+	}
+
+	pod := &v1.Pod{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: podName(job), Namespace: job.Namespace}, pod)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to get the pod of command job %q", request.NamespacedName)
+		}
+		if job.Status.StartTime != "" {
+			return r.fail(job, "the pod of the command was deleted before the command completed")
+		}
+		return r.createPod(job, &cephCluster)
+	}
+
+	switch pod.Status.Phase {
+	case v1.PodSucceeded, v1.PodFailed:
+		return r.complete(job, pod)
+	case v1.PodRunning:
+		if job.Status.Phase != PhaseRunning {
+			job.Status.Phase = PhaseRunning
+			if err := opcontroller.UpdateStatus(r.client, job); err != nil {
+				return reconcile.Result{}, errors.Wrapf(err, "failed to update the status of command job %q", request.NamespacedName)
+			}
+		}
+	}
+
+	// the deadline of a pod only starts when it runs on a node, the pods which cannot be scheduled are stopped here
+	if remaining := deadline(job).Sub(time.Now()); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	return r.fail(job, fmt.Sprintf("the command did not complete in %d seconds", timeoutSeconds(&job.Spec)))
+}
+
+func (r *ReconcileCephCommandJob) createPod(job *cephv1.CephCommandJob, cephCluster *cephv1.CephCluster) (reconcile.Result, error) {
+	pod, err := makePod(job, cephCluster)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to make the pod of command job %q", job.Name)
+	}
+	if err := controllerutil.SetControllerReference(job, pod, r.scheme); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to set owner reference of the pod of command job %q", job.Name)
+	}
+	if err := r.client.Create(context.TODO(), pod); err != nil && !kerrors.IsAlreadyExists(err) {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create the pod of command job %q", job.Name)
+	}
+	logger.Infof("running command %q of command job %q", job.Spec.Command, job.Name)
+
+	job.Status.Phase = PhasePending
+	job.Status.StartTime = time.Now().UTC().Format(time.RFC3339)
+	if err := opcontroller.UpdateStatus(r.client, job); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to update the status of command job %q", job.Name)
+	}
+	return reconcile.Result{RequeueAfter: deadline(job).Sub(time.Now())}, nil
+}
+
+// complete stores the output and the exit code of the command in the status of the job and deletes its pod
+func (r *ReconcileCephCommandJob) complete(job *cephv1.CephCommandJob, pod *v1.Pod) (reconcile.Result, error) {
+	output, truncated, err := r.readOutput(pod)
+	if err != nil {
+		// the container did not start when the pod was stopped by its deadline
+		logger.Warningf("failed to read the output of command job %q. %v", job.Name, err)
+	}
+
+	status := job.Status
+	status.Output = output
+	status.Truncated = truncated
+	status.Phase = PhaseSucceeded
+	if pod.Status.Phase == v1.PodFailed {
+		status.Phase = PhaseFailed
+	}
+	status.Message = pod.Status.Message
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == containerName && containerStatus.State.Terminated != nil {
+			status.ExitCode = containerStatus.State.Terminated.ExitCode
+			if message := strings.TrimSpace(containerStatus.State.Terminated.Message); message != "" {
+				status.Message = message
+			}
+		}
+	}
+	status.CompletionTime = time.Now().UTC().Format(time.RFC3339)
+	if err := opcontroller.UpdateStatus(r.client, job); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to update the status of command job %q", job.Name)
+	}
+	logger.Infof("command job %q completed with phase %q and exit code %d", job.Name, status.Phase, status.ExitCode)
+
+	r.deletePod(job)
+	return reconcile.Result{}, nil
+}
+
+// fail stops a job whose command could not run or complete
+func (r *ReconcileCephCommandJob) fail(job *cephv1.CephCommandJob, message string) (reconcile.Result, error) {
+	logger.Errorf("command job %q failed. %s", job.Name, message)
+	job.Status.Phase = PhaseFailed
+	job.Status.Message = message
+	job.Status.CompletionTime = time.Now().UTC().Format(time.RFC3339)
+	if err := opcontroller.UpdateStatus(r.client, job); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to update the status of command job %q", job.Name)
+	}
+	r.deletePod(job)
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileCephCommandJob) deletePod(job *cephv1.CephCommandJob) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName(job), Namespace: job.Namespace}}
+	if err := r.client.Delete(context.TODO(), pod); err != nil && !kerrors.IsNotFound(err) {
+		logger.Warningf("failed to delete the pod of command job %q. %v", job.Name, err)
+	}
+}
+
+// readOutput returns the standard output of the command from the logs of its pod and whether it was truncated
+func (r *ReconcileCephCommandJob) readOutput(pod *v1.Pod) (string, bool, error) {
+	limit := int64(maxOutputBytes + 1)
+	readCloser, err := getPodLogs(r.context.Clientset, pod, limit)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to get the logs of pod %q", pod.Name)
+	}
+	defer readCloser.Close()
+	raw, err := ioutil.ReadAll(io.LimitReader(readCloser, limit))
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to read the logs of pod %q", pod.Name)
+	}
+	if len(raw) > maxOutputBytes {
+		return string(raw[:maxOutputBytes]), true, nil
+	}
+	return string(raw), false, nil
+}
+
+func streamPodLogs(clientset kubernetes.Interface, pod *v1.Pod, limitBytes int64) (io.ReadCloser, error) {
+	return clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{Container: containerName, LimitBytes: &limitBytes}).Stream()
+}
+
+func timeoutSeconds(spec *cephv1.CommandJobSpec) int64 {
+	if spec.TimeoutSeconds == 0 {
+		return defaultTimeoutSeconds
+	}
+	return spec.TimeoutSeconds
+}
+
+// deadline returns the time after which a job whose command did not complete is failed
+func deadline(job *cephv1.CephCommandJob) time.Time {
+	start, err := time.Parse(time.RFC3339, job.Status.StartTime)
+	if err != nil {
+		start = time.Now()
+	}
+	return start.Add(time.Duration(timeoutSeconds(&job.Spec))*time.Second + deadlineGracePeriod)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commandjob
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newCommandJob(name, command string, args ...string) *cephv1.CephCommandJob {
+	return &cephv1.CephCommandJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "rook-ceph"},
+		Spec:       cephv1.CommandJobSpec{Command: command, Args: args},
+		TypeMeta:   controllerTypeMeta,
+	}
+}
+
+func TestCommandJobController(t *testing.T) {
+	job := newCommandJob("pools", "ceph", "osd", "pool", "ls")
+	invalid := newCommandJob("invalid", "bash", "-c", "rm -rf /")
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "rook-ceph"},
+		Spec:       cephv1.ClusterSpec{CephVersion: cephv1.CephVersionSpec{Image: "ceph/ceph:v15.2.4"}},
+	}
+
+	s := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(s))
+	assert.NoError(t, v1.AddToScheme(s))
+	cl := fake.NewFakeClientWithScheme(s, job, invalid, cephCluster)
+	r := &ReconcileCephCommandJob{client: cl, scheme: s, context: &clusterd.Context{Clientset: test.New(t, 1)}}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "pools", Namespace: "rook-ceph"}}
+	podKey := types.NamespacedName{Name: "pools-command", Namespace: "rook-ceph"}
+	getPodLogs = func(clientset kubernetes.Interface, pod *v1.Pod, limitBytes int64) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("pool1\npool2\n")), nil
+	}
+	defer func() { getPodLogs = streamPodLogs }()
+
+	// the pod of the command is created with the image of the cluster
+	res, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.True(t, res.RequeueAfter > 0)
+	pod := &v1.Pod{}
+	assert.NoError(t, cl.Get(context.TODO(), podKey, pod))
+	assert.Equal(t, "ceph/ceph:v15.2.4", pod.Spec.Containers[0].Image)
+	assert.Equal(t, []string{"/bin/bash", "-c", commandScript, "ceph", "osd", "pool", "ls"}, pod.Spec.Containers[0].Command)
+	assert.Equal(t, v1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	assert.Equal(t, int64(300), *pod.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, "pools", pod.OwnerReferences[0].Name)
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, job))
+	assert.Equal(t, PhasePending, job.Status.Phase)
+	assert.NotEmpty(t, job.Status.StartTime)
+
+	// the job runs with its pod
+	pod.Status.Phase = v1.PodRunning
+	assert.NoError(t, cl.Update(context.TODO(), pod))
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, job))
+	assert.Equal(t, PhaseRunning, job.Status.Phase)
+
+	// the output is read from the logs of the pod, which is deleted
+	pod.Status.Phase = v1.PodFailed
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:  containerName,
+		State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 2, Message: "Error EINVAL: invalid command\n"}},
+	}}
+	assert.NoError(t, cl.Update(context.TODO(), pod))
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, job))
+	assert.Equal(t, PhaseFailed, job.Status.Phase)
+	assert.Equal(t, int32(2), job.Status.ExitCode)
+	assert.Equal(t, "Error EINVAL: invalid command", job.Status.Message)
+	assert.Equal(t, "pool1\npool2\n", job.Status.Output)
+	assert.NotEmpty(t, job.Status.CompletionTime)
+	assert.True(t, kerrors.IsNotFound(cl.Get(context.TODO(), podKey, pod)))
+
+	// a completed job is not run again
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.True(t, kerrors.IsNotFound(cl.Get(context.TODO(), podKey, pod)))
+
+	// the commands other than the ceph tools are refused
+	req.Name = "invalid"
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, invalid))
+	assert.Equal(t, PhaseFailed, invalid.Status.Phase)
+	assert.Contains(t, invalid.Status.Message, `invalid command "bash"`)
+	assert.True(t, kerrors.IsNotFound(cl.Get(context.TODO(), types.NamespacedName{Name: "invalid-command", Namespace: "rook-ceph"}, pod)))
+}
+
+func TestCommandJobRestrictedAdminKey(t *testing.T) {
+	job := newCommandJob("status", "ceph", "status")
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "rook-ceph"},
+		Spec:       cephv1.ClusterSpec{Security: cephv1.SecuritySpec{RestrictAdminKey: true}},
+	}
+	s := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(s))
+	assert.NoError(t, v1.AddToScheme(s))
+	cl := fake.NewFakeClientWithScheme(s, job, cephCluster)
+	r := &ReconcileCephCommandJob{client: cl, scheme: s, context: &clusterd.Context{Clientset: test.New(t, 1)}}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "status", Namespace: "rook-ceph"}}
+
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, job))
+	assert.Equal(t, PhaseFailed, job.Status.Phase)
+	assert.Contains(t, job.Status.Message, "admin key")
+}
+
+func TestReadOutput(t *testing.T) {
+	r := &ReconcileCephCommandJob{context: &clusterd.Context{Clientset: test.New(t, 1)}}
+	getPodLogs = func(clientset kubernetes.Interface, pod *v1.Pod, limitBytes int64) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(strings.Repeat("a", maxOutputBytes+10))), nil
+	}
+	defer func() { getPodLogs = streamPodLogs }()
+
+	// the output is truncated to its max size
+	output, truncated, err := r.readOutput(&v1.Pod{})
+	assert.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, maxOutputBytes, len(output))
+}
+
+func TestValidateSpec(t *testing.T) {
+	assert.NoError(t, validateSpec(&cephv1.CommandJobSpec{Command: "radosgw-admin", Args: []string{"user", "list"}}))
+	assert.Error(t, validateSpec(&cephv1.CommandJobSpec{Command: "ceph"}))
+	assert.Error(t, validateSpec(&cephv1.CommandJobSpec{Command: "sh", Args: []string{"-c", "true"}}))
+	assert.Error(t, validateSpec(&cephv1.CommandJobSpec{Command: "rbd", Args: []string{"ls"}, TimeoutSeconds: -1}))
+}
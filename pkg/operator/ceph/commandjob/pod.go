@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commandjob
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	appName       = "rook-ceph-command-job"
+	containerName = "command"
+
+	// the label of the pods with the name of their command job
+	commandJobLabel = "ceph_command_job"
+
+	defaultTimeoutSeconds = int64(300)
+)
+
+// the tools which can be run by a command job
+var allowedCommands = map[string]bool{"ceph": true, "radosgw-admin": true, "rados": true, "rbd": true}
+
+// the standard output of the command is read from the logs of the pod and its error output is kept in the
+// termination message of the container
+var commandScript = `"$0" "$@" 2>/dev/termination-log`
+
+func podName(job *cephv1.CephCommandJob) string {
+	return fmt.Sprintf("%s-command", job.Name)
+}
+
+func validateSpec(spec *cephv1.CommandJobSpec) error {
+	if !allowedCommands[spec.Command] {
+		return errors.Errorf("invalid command %q, must be one of ceph, radosgw-admin, rados or rbd", spec.Command)
+	}
+	if len(spec.Args) == 0 {
+		return errors.New("no arguments for the command")
+	}
+	if spec.TimeoutSeconds < 0 {
+		return errors.Errorf("invalid timeout of %d seconds", spec.TimeoutSeconds)
+	}
+	return nil
+}
+
+// makePod returns the pod running the command of a job once with the mon endpoints and the admin keyring of the
+// cluster
+func makePod(job *cephv1.CephCommandJob, cephCluster *cephv1.CephCluster) (*v1.Pod, error) {
+	timeout := timeoutSeconds(&job.Spec)
+	image := cephCluster.Spec.CephVersion.Image
+	labels := opcontroller.AppLabels(appName, job.Namespace)
+	labels[commandJobLabel] = job.Name
+	// no log or crash dir of the host is mounted for a one-shot command
+	dataPaths := &config.DataPathMap{}
+
+	container := v1.Container{
+		Name:    containerName,
+		Command: append([]string{"/bin/bash", "-c", commandScript, job.Spec.Command}, job.Spec.Args...),
+		Image:   image,
+		Env: append(opcontroller.DaemonEnvVars(image),
+			v1.EnvVar{Name: "CEPH_ARGS", Value: fmt.Sprintf("-m $(ROOK_CEPH_MON_HOST) -k %s", keyring.VolumeMount().AdminKeyringFilePath())},
+		),
+		VolumeMounts:             append(opcontroller.DaemonVolumeMounts(dataPaths, ""), keyring.VolumeMount().Admin()),
+		TerminationMessagePolicy: v1.TerminationMessageReadFile,
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName(job),
+			Namespace: job.Namespace,
+			Labels:    labels,
+		},
+		Spec: v1.PodSpec{
+			Containers:            []v1.Container{container},
+			RestartPolicy:         v1.RestartPolicyNever,
+			ActiveDeadlineSeconds: &timeout,
+			Volumes:               append(opcontroller.DaemonVolumesBase(dataPaths, ""), keyring.Volume().Admin()),
+			HostNetwork:           cephCluster.Spec.Network.IsHost(),
+			PriorityClassName:     cephv1.GetToolboxPriorityClassName(cephCluster.Spec.PriorityClassNames),
+			SecurityContext:       cephv1.GetPodSecurityContext(cephCluster.Spec.Security.PodSecurityContexts, cephv1.KeyToolbox),
+		},
+	}
+	k8sutil.ApplyClusterInheritedMetadata(cephCluster.Spec.InheritedMetadata.Labels, cephCluster.Spec.InheritedMetadata.Annotations, &pod.ObjectMeta)
+	// Replace default unreachable node toleration
+	k8sutil.AddUnreachableNodeToleration(&pod.Spec)
+	if cephCluster.Spec.Network.IsHost() {
+		pod.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
+	} else if cephCluster.Spec.Network.IsMultus() {
+		if err := k8sutil.ApplyMultus(cephCluster.Spec.Network.NetworkSpec, &pod.ObjectMeta); err != nil {
+			return nil, err
+		}
+	}
+	return pod, nil
+}
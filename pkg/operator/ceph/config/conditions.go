@@ -19,6 +19,7 @@ package config
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -31,8 +32,17 @@ import (
 )
 
 var (
-	conditions   *[]cephv1.Condition
-	conditionMap = make(map[cephv1.ConditionType]v1.ConditionStatus)
+	// the conditions and their status are kept for each cluster, and guarded by conditionsMutex since the clusters are
+	// reconciled and monitored in parallel
+	conditions      = make(map[types.NamespacedName]*[]cephv1.Condition)
+	conditionMap    = make(map[types.NamespacedName]map[cephv1.ConditionType]v1.ConditionStatus)
+	conditionsMutex sync.Mutex
+	// informationalConditions don't change the phase of the cluster when they are true
+	informationalConditions = map[cephv1.ConditionType]bool{
+		cephv1.ConditionDeprecatedFields:       true,
+		cephv1.ConditionDegraded:               true,
+		cephv1.ConditionConfigOverrideConflict: true,
+	}
 )
 
 // ConditionExport function will export each condition into the cluster custom resource
@@ -58,18 +68,22 @@ func setCondition(c *clusterd.Context, namespaceName types.NamespacedName, newCo
 		return
 	}
 
-	if conditions == nil {
-		conditions = &cluster.Status.Conditions
+	conditionsMutex.Lock()
+	clusterConditions, ok := conditions[namespaceName]
+	if !ok {
+		existingConditions := append([]cephv1.Condition(nil), cluster.Status.Conditions...)
+		clusterConditions = &existingConditions
+		conditions[namespaceName] = clusterConditions
 		if cluster.Status.Conditions != nil {
-			conditionMapping(*conditions)
+			conditionMapping(namespaceName, *clusterConditions)
 		}
 	}
-	conditionMap[newCondition.Type] = newCondition.Status
-	existingCondition := findStatusCondition(*conditions, newCondition.Type)
+	clusterConditionMap(namespaceName)[newCondition.Type] = newCondition.Status
+	existingCondition := findStatusCondition(*clusterConditions, newCondition.Type)
 	if existingCondition == nil {
 		newCondition.LastTransitionTime = metav1.NewTime(time.Now())
 		newCondition.LastHeartbeatTime = metav1.NewTime(time.Now())
-		*conditions = append(*conditions, newCondition)
+		*clusterConditions = append(*clusterConditions, newCondition)
 
 	} else if existingCondition.Status != newCondition.Status || existingCondition.Message != newCondition.Message {
 		newCondition.LastTransitionTime = metav1.NewTime(time.Now())
@@ -78,9 +92,11 @@ func setCondition(c *clusterd.Context, namespaceName types.NamespacedName, newCo
 		existingCondition.Message = newCondition.Message
 		existingCondition.LastHeartbeatTime = metav1.NewTime(time.Now())
 	}
-	cluster.Status.Conditions = *conditions
+	// the conditions are copied since they are updated by the other goroutines once unlocked
+	cluster.Status.Conditions = append([]cephv1.Condition(nil), *clusterConditions...)
+	conditionsMutex.Unlock()
 
-	if newCondition.Status == v1.ConditionTrue {
+	if newCondition.Status == v1.ConditionTrue && !informationalConditions[newCondition.Type] {
 		cluster.Status.Phase = newCondition.Type
 		if state := translatePhasetoState(newCondition.Type); state != "" {
 			cluster.Status.State = state
@@ -132,11 +148,13 @@ func translatePhasetoState(phase cephv1.ConditionType) cephv1.ClusterState {
 func checkConditionFalse(context *clusterd.Context, namespaceName types.NamespacedName) {
 	tempConditionList := []cephv1.ConditionType{cephv1.ConditionUpdating, cephv1.ConditionUpgrading, cephv1.ConditionProgressing}
 	var tempCondition cephv1.ConditionType
+	conditionsMutex.Lock()
 	for _, conditionType := range tempConditionList {
-		if conditionMap[conditionType] == v1.ConditionTrue {
+		if clusterConditionMap(namespaceName)[conditionType] == v1.ConditionTrue {
 			tempCondition = conditionType
 		}
 	}
+	conditionsMutex.Unlock()
 	reason := ""
 	message := ""
 	if tempCondition == cephv1.ConditionUpdating {
@@ -174,12 +192,28 @@ func ConditionInitialize(context *clusterd.Context, namespaceName types.Namespac
 	})
 }
 
-// conditionMapping maps the condition type to its status
-func conditionMapping(conditions []cephv1.Condition) {
+// conditionMapping maps the condition type to its status, with conditionsMutex locked
+func conditionMapping(namespaceName types.NamespacedName, conditions []cephv1.Condition) {
 	for i := range conditions {
 		conditionType := conditions[i].Type
-		conditionMap[conditionType] = conditions[i].Status
+		clusterConditionMap(namespaceName)[conditionType] = conditions[i].Status
+	}
+}
+
+// clusterConditionMap returns the status of the conditions of a cluster, with conditionsMutex locked
+func clusterConditionMap(namespaceName types.NamespacedName) map[cephv1.ConditionType]v1.ConditionStatus {
+	if _, ok := conditionMap[namespaceName]; !ok {
+		conditionMap[namespaceName] = make(map[cephv1.ConditionType]v1.ConditionStatus)
 	}
+	return conditionMap[namespaceName]
+}
+
+// ForgetConditions forgets the conditions of a deleted cluster
+func ForgetConditions(namespaceName types.NamespacedName) {
+	conditionsMutex.Lock()
+	defer conditionsMutex.Unlock()
+	delete(conditions, namespaceName)
+	delete(conditionMap, namespaceName)
 }
 
 // CheckConditionReady checks whether the cluster is Ready and returns the message for the Progressing ConditionType
@@ -189,18 +223,22 @@ func CheckConditionReady(c *clusterd.Context, namespaceName types.NamespacedName
 	if err != nil {
 		logger.Errorf("failed to get cluster %v", err)
 	}
-	if cluster.Status.Conditions != nil && len(conditionMap) == 0 {
-		conditionMapping(cluster.Status.Conditions)
+	conditionsMutex.Lock()
+	defer conditionsMutex.Unlock()
+	if cluster.Status.Conditions != nil && len(clusterConditionMap(namespaceName)) == 0 {
+		conditionMapping(namespaceName, cluster.Status.Conditions)
 	}
-	if conditionMap[cephv1.ConditionReady] == v1.ConditionTrue {
+	if clusterConditionMap(namespaceName)[cephv1.ConditionReady] == v1.ConditionTrue {
 		return "Cluster is checking if updates are needed"
 	}
 	return "Cluster is creating"
 }
 
-// ErrorMapping iterate through the Condition Map to see if Failure is True or False
-func ErrorMapping() error {
-	if conditionMap[cephv1.ConditionFailure] == v1.ConditionTrue {
+// ErrorMapping iterate through the Condition Map of a cluster to see if Failure is True or False
+func ErrorMapping(namespaceName types.NamespacedName) error {
+	conditionsMutex.Lock()
+	defer conditionsMutex.Unlock()
+	if clusterConditionMap(namespaceName)[cephv1.ConditionFailure] == v1.ConditionTrue {
 		return errors.New("failed to initialize the cluster")
 	}
 	return nil
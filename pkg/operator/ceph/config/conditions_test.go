@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sync"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// run with -race to detect the concurrent accesses to the conditions
+func TestConditionExportParallel(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{})
+	namespaces := []types.NamespacedName{{Namespace: "ns-a", Name: "cluster-a"}, {Namespace: "ns-b", Name: "cluster-b"}}
+	objects := []runtime.Object{}
+	for _, namespaceName := range namespaces {
+		objects = append(objects, &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: namespaceName.Name, Namespace: namespaceName.Namespace}})
+	}
+	c := &clusterd.Context{Client: fake.NewFakeClientWithScheme(s, objects...)}
+	defer func() {
+		for _, namespaceName := range namespaces {
+			ForgetConditions(namespaceName)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, namespaceName := range namespaces {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(namespaceName types.NamespacedName) {
+				defer wg.Done()
+				ConditionExport(c, namespaceName, cephv1.ConditionProgressing, v1.ConditionTrue, "ClusterProgressing", "Cluster is creating")
+				ConditionExport(c, namespaceName, cephv1.ConditionReady, v1.ConditionTrue, "ClusterCreated", "Cluster created successfully")
+				assert.NoError(t, ErrorMapping(namespaceName))
+				CheckConditionReady(c, namespaceName)
+			}(namespaceName)
+		}
+	}
+	wg.Wait()
+
+	// the conditions of each cluster are kept apart
+	for _, namespaceName := range namespaces {
+		assert.Equal(t, "Cluster is checking if updates are needed", CheckConditionReady(c, namespaceName))
+		conditionsMutex.Lock()
+		assert.Equal(t, 1, countConditions(*conditions[namespaceName], cephv1.ConditionReady))
+		assert.Equal(t, 1, countConditions(*conditions[namespaceName], cephv1.ConditionProgressing))
+		conditionsMutex.Unlock()
+	}
+}
+
+func countConditions(conditions []cephv1.Condition, conditionType cephv1.ConditionType) int {
+	count := 0
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			count++
+		}
+	}
+	return count
+}
@@ -117,6 +117,18 @@ func SetDefaultConfigs(
 		return errors.Wrapf(err, "failed to apply legacy config overrides")
 	}
 
+	if err := applyConnectionsSettings(monStore, clusterInfo.CephVersion, networkSpec); err != nil {
+		return errors.Wrap(err, "failed to apply the settings of the connections")
+	}
+
+	if err := applyIPFamilySettings(monStore, networkSpec); err != nil {
+		return errors.Wrap(err, "failed to apply the ip family settings")
+	}
+
+	if err := applyPortRangeSettings(monStore, networkSpec); err != nil {
+		return errors.Wrap(err, "failed to apply the port range settings")
+	}
+
 	// Apply Multus if needed
 	if networkSpec.IsMultus() {
 		logger.Info("configuring ceph network(s) with multus")
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConnectionsAnnotation is the annotation of the pods of the daemons with the enabled settings of the connections
+	ConnectionsAnnotation = "rook.io/connections"
+
+	secureMode         = "secure"
+	compressModeForce  = "force"
+	compressModeOption = "ms_osd_compress_mode"
+)
+
+var (
+	// CompressionMinimumVersion is the minimum version of Ceph compressing the msgr2 connections
+	CompressionMinimumVersion = version.CephVersion{Major: 17}
+
+	// the modes of the connections between the daemons, of the daemons with their clients and of the clients
+	encryptionModeOptions = []string{"ms_cluster_mode", "ms_service_mode", "ms_client_mode"}
+)
+
+// applyConnectionsSettings sets the modes of the msgr2 connections in the centralized mon configuration database.
+// The settings are removed when the encryption or the compression is disabled, the settings of the
+// rook-config-override ConfigMap still take precedence over the database.
+// The daemons read the settings when they start, they are restarted when the settings of the connections change.
+func applyConnectionsSettings(monStore *MonStore, cephVersion version.CephVersion, networkSpec cephv1.NetworkSpec) error {
+	for _, option := range encryptionModeOptions {
+		if err := applyConnectionsSetting(monStore, option, secureMode, networkSpec.IsEncryptionEnabled()); err != nil {
+			return err
+		}
+	}
+
+	// the compression option is unknown before Quincy
+	if !cephVersion.IsAtLeast(CompressionMinimumVersion) {
+		if networkSpec.IsCompressionEnabled() {
+			logger.Warning("compression of the connections requires ceph quincy or newer")
+		}
+		return nil
+	}
+	return applyConnectionsSetting(monStore, compressModeOption, compressModeForce, networkSpec.IsCompressionEnabled())
+}
+
+func applyConnectionsSetting(monStore *MonStore, option, value string, enabled bool) error {
+	if enabled {
+		if err := monStore.Set("global", option, value); err != nil {
+			return errors.Wrapf(err, "failed to set connections setting %q", option)
+		}
+		return nil
+	}
+
+	// removing a setting which is not set succeeds
+	if err := monStore.Delete("global", option); err != nil {
+		return errors.Wrapf(err, "failed to reset connections setting %q", option)
+	}
+	return nil
+}
+
+// ApplyConnectionsAnnotation sets the enabled settings of the connections in an annotation of the pods of the
+// daemons, so that the daemons are restarted one at a time by their usual update when the settings change
+func ApplyConnectionsAnnotation(networkSpec cephv1.NetworkSpec, meta *metav1.ObjectMeta) {
+	settings := []string{}
+	if networkSpec.IsEncryptionEnabled() {
+		settings = append(settings, "encryption")
+	}
+	if networkSpec.IsCompressionEnabled() {
+		settings = append(settings, "compression")
+	}
+	if len(settings) == 0 {
+		delete(meta.Annotations, ConnectionsAnnotation)
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[ConnectionsAnnotation] = strings.Join(settings, ",")
+}
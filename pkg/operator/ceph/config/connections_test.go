@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyConnectionsSettings(t *testing.T) {
+	execedCmds := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outfile string, args ...string) (string, error) {
+			execedCmds = append(execedCmds, strings.Join(args[:4], " "))
+			return "", nil
+		},
+	}
+	monStore := GetMonStore(&clusterd.Context{Executor: executor}, &client.ClusterInfo{Namespace: "ns"})
+	networkSpec := cephv1.NetworkSpec{Connections: &cephv1.ConnectionsSpec{
+		Encryption:  &cephv1.EncryptionSpec{Enabled: true},
+		Compression: &cephv1.CompressionSpec{Enabled: true},
+	}}
+
+	// the compression is not configured before quincy
+	assert.NoError(t, applyConnectionsSettings(monStore, version.Octopus, networkSpec))
+	assert.Equal(t, []string{
+		"config set global ms_cluster_mode",
+		"config set global ms_service_mode",
+		"config set global ms_client_mode",
+	}, execedCmds)
+
+	execedCmds = []string{}
+	assert.NoError(t, applyConnectionsSettings(monStore, version.CephVersion{Major: 17}, networkSpec))
+	assert.Equal(t, 4, len(execedCmds))
+	assert.Equal(t, "config set global ms_osd_compress_mode", execedCmds[3])
+
+	// the settings are reset when they are disabled
+	execedCmds = []string{}
+	networkSpec.Connections.Encryption.Enabled = false
+	assert.NoError(t, applyConnectionsSettings(monStore, version.CephVersion{Major: 17}, networkSpec))
+	assert.Equal(t, []string{
+		"config rm global ms_cluster_mode",
+		"config rm global ms_service_mode",
+		"config rm global ms_client_mode",
+		"config set global ms_osd_compress_mode",
+	}, execedCmds)
+}
+
+func TestApplyConnectionsAnnotation(t *testing.T) {
+	meta := metav1.ObjectMeta{}
+	networkSpec := cephv1.NetworkSpec{}
+	ApplyConnectionsAnnotation(networkSpec, &meta)
+	assert.Nil(t, meta.Annotations)
+
+	networkSpec.Connections = &cephv1.ConnectionsSpec{Encryption: &cephv1.EncryptionSpec{Enabled: true}}
+	ApplyConnectionsAnnotation(networkSpec, &meta)
+	assert.Equal(t, "encryption", meta.Annotations[ConnectionsAnnotation])
+
+	networkSpec.Connections.Compression = &cephv1.CompressionSpec{Enabled: true}
+	ApplyConnectionsAnnotation(networkSpec, &meta)
+	assert.Equal(t, "encryption,compression", meta.Annotations[ConnectionsAnnotation])
+
+	networkSpec.Connections = nil
+	ApplyConnectionsAnnotation(networkSpec, &meta)
+	_, ok := meta.Annotations[ConnectionsAnnotation]
+	assert.False(t, ok)
+}
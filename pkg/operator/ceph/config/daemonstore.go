@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+)
+
+// ConfigStoreDryRun logs the changes of the options of the daemons of the child controllers instead of applying them
+// to the centralized mon configuration database
+var ConfigStoreDryRun = false
+
+// OptionChange is a change of an option of a daemon in the centralized mon configuration database
+type OptionChange struct {
+	// Who is the entity the option applies to
+	Who string
+	// Option is the normalized name of the option
+	Option string
+	// Value is the desired value of the option, empty when the option is removed
+	Value string
+	// CurrentValue is the value of the option in the database, empty when the option is not set
+	CurrentValue string
+	// Removed is true when the option is removed from the database
+	Removed bool
+}
+
+func (c OptionChange) String() string {
+	if c.Removed {
+		return fmt.Sprintf("%s: remove %s (%q)", c.Who, c.Option, c.CurrentValue)
+	}
+	if c.CurrentValue == "" {
+		return fmt.Sprintf("%s: set %s=%q", c.Who, c.Option, c.Value)
+	}
+	return fmt.Sprintf("%s: set %s=%q (was %q)", c.Who, c.Option, c.Value, c.CurrentValue)
+}
+
+// DaemonConfigStore configures the daemons of the object stores, filesystems and NFS servers in the centralized mon
+// configuration database. Only the options which differ from the database are changed, and only logged in dry-run.
+type DaemonConfigStore struct {
+	monStore *MonStore
+	dryRun   bool
+}
+
+// GetDaemonConfigStore returns the daemon config store of the cluster, in dry-run if ConfigStoreDryRun is set
+func GetDaemonConfigStore(context *clusterd.Context, clusterInfo *client.ClusterInfo) *DaemonConfigStore {
+	return &DaemonConfigStore{monStore: GetMonStore(context, clusterInfo), dryRun: ConfigStoreDryRun}
+}
+
+// Diff returns the changes applying the options to the entity. The managed options are the options the caller
+// generates for the entity, they are removed when they are set in the database but not in the options.
+func (s *DaemonConfigStore) Diff(who string, options map[string]string, managed ...string) ([]OptionChange, error) {
+	current, err := s.monStore.GetDaemon(who)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the config of %q", who)
+	}
+	currentValues := map[string]string{}
+	for _, option := range current {
+		currentValues[normalizeKey(option.Option)] = option.Value
+	}
+
+	changes := []OptionChange{}
+	desired := map[string]bool{}
+	for option, value := range options {
+		option = normalizeKey(option)
+		desired[option] = true
+		if currentValue, ok := currentValues[option]; !ok || currentValue != value {
+			changes = append(changes, OptionChange{Who: who, Option: option, Value: value, CurrentValue: currentValues[option]})
+		}
+	}
+	for _, option := range managed {
+		option = normalizeKey(option)
+		if currentValue, ok := currentValues[option]; ok && !desired[option] {
+			changes = append(changes, OptionChange{Who: who, Option: option, CurrentValue: currentValue, Removed: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Option < changes[j].Option })
+	return changes, nil
+}
+
+// Apply sets the options of the entity and removes its managed options which are not in the options, and returns
+// the changes. All the options are set when the current config of the entity cannot be read. The changes are only
+// logged in dry-run.
+func (s *DaemonConfigStore) Apply(who string, options map[string]string, managed ...string) ([]OptionChange, error) {
+	changes, err := s.Diff(who, options, managed...)
+	if err != nil {
+		logger.Warningf("setting all the options of %q. %v", who, err)
+		changes = []OptionChange{}
+		for option, value := range options {
+			changes = append(changes, OptionChange{Who: who, Option: normalizeKey(option), Value: value})
+		}
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Option < changes[j].Option })
+	}
+	return changes, s.applyChanges(changes)
+}
+
+// Remove removes all the options of the entity, e.g. when the daemon or its CR is deleted, and returns the changes.
+// The changes are only logged in dry-run.
+func (s *DaemonConfigStore) Remove(who string) ([]OptionChange, error) {
+	current, err := s.monStore.GetDaemon(who)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the config of %q", who)
+	}
+	changes := []OptionChange{}
+	for _, option := range current {
+		changes = append(changes, OptionChange{Who: who, Option: normalizeKey(option.Option), CurrentValue: option.Value, Removed: true})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Option < changes[j].Option })
+	return changes, s.applyChanges(changes)
+}
+
+func (s *DaemonConfigStore) applyChanges(changes []OptionChange) error {
+	for _, change := range changes {
+		if s.dryRun {
+			logger.Infof("dry-run: %s", change.String())
+			continue
+		}
+		if change.Removed {
+			if err := s.monStore.Delete(change.Who, change.Option); err != nil {
+				return errors.Wrapf(err, "failed to remove %q of %q", change.Option, change.Who)
+			}
+			continue
+		}
+		if err := s.monStore.Set(change.Who, change.Option, change.Value); err != nil {
+			return errors.Wrapf(err, "failed to set %q to %q on %q", change.Option, change.Value, change.Who)
+		}
+	}
+	return nil
+}
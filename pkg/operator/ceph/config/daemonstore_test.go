@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonConfigStore(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	ctx := &clusterd.Context{Clientset: testop.New(t, 1), Executor: executor}
+	current := `{"rbd_default_features":{"value":"3","section":"global"},
+		"mds_join_fs":{"value":"myfs","section":"mds.myfs-a"},
+		"mds_cache_memory_limit":{"value":"1024","section":"mds.myfs-a"},
+		"debug_mds":{"value":"10","section":"mds.myfs-a"}}`
+	commands := []string{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outfile string, args ...string) (string, error) {
+		if args[0] == "config" && args[1] == "get" {
+			return current, nil
+		}
+		commands = append(commands, strings.Join(args[:4], " "))
+		return "", nil
+	}
+	store := GetDaemonConfigStore(ctx, &client.ClusterInfo{Namespace: "ns"})
+
+	// only the changed options are set, and the managed options not desired anymore are removed
+	options := map[string]string{"mds_join_fs": "myfs", "mds cache memory limit": "2048"}
+	changes, err := store.Diff("mds.myfs-a", options, "mds_cache_memory_limit", "mds_join_fs")
+	assert.NoError(t, err)
+	assert.Equal(t, []OptionChange{{Who: "mds.myfs-a", Option: "mds_cache_memory_limit", Value: "2048", CurrentValue: "1024"}}, changes)
+	assert.Empty(t, commands)
+
+	changes, err = store.Apply("mds.myfs-a", map[string]string{"mds_join_fs": "myfs"}, "mds_cache_memory_limit", "mds_join_fs")
+	assert.NoError(t, err)
+	assert.Equal(t, []OptionChange{{Who: "mds.myfs-a", Option: "mds_cache_memory_limit", CurrentValue: "1024", Removed: true}}, changes)
+	assert.Equal(t, []string{"config rm mds.myfs-a mds_cache_memory_limit"}, commands)
+
+	// the options of the entity are removed
+	commands = []string{}
+	changes, err = store.Remove("mds.myfs-a")
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+	assert.ElementsMatch(t, []string{"config rm mds.myfs-a debug_mds", "config rm mds.myfs-a mds_cache_memory_limit", "config rm mds.myfs-a mds_join_fs"}, commands)
+
+	// the changes are only logged in dry-run
+	commands = []string{}
+	store.dryRun = true
+	changes, err = store.Apply("mds.myfs-a", map[string]string{"mds_join_fs": "otherfs"})
+	assert.NoError(t, err)
+	assert.Equal(t, []OptionChange{{Who: "mds.myfs-a", Option: "mds_join_fs", Value: "otherfs", CurrentValue: "myfs"}}, changes)
+	assert.Empty(t, commands)
+}
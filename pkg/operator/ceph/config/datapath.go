@@ -43,6 +43,10 @@ type DataPathMap struct {
 	// The log dir is always /var/log/ceph. If logs are not persisted to the
 	// host, logs are not shared between containers via empty dir or any other mechanism.
 	HostLogAndCrashDir string
+
+	// HostSocketDir is the directory on the host where the admin sockets of the daemon are shared with the
+	// ceph-exporter daemon of the node. If this is empty, the admin sockets are not shared with the host.
+	HostSocketDir string
 }
 
 // NewStatefulDaemonDataPathMap returns a new DataPathMap for a daemon which requires a persistent
@@ -107,3 +111,12 @@ func (d *DataPathMap) HostLogDir() string {
 func (d *DataPathMap) HostCrashDir() string {
 	return path.Join(d.HostLogAndCrashDir, "crash")
 }
+
+// WithHostSocketDir shares the admin sockets of the daemon on the host when the ceph-exporter daemons, which read the
+// performance counters from the sockets, are enabled
+func (d *DataPathMap) WithHostSocketDir(exporterEnabled bool) *DataPathMap {
+	if exporterEnabled {
+		d.HostSocketDir = path.Join(d.HostLogAndCrashDir, "exporter")
+	}
+	return d
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+)
+
+var (
+	// DefaultMsgrPortRange is the default range of the ports of the osd, mgr, mds and rbd-mirror daemons
+	DefaultMsgrPortRange = cephv1.PortRangeSpec{Min: 6800, Max: 7300}
+
+	// DefaultNFSPort is the default port of the nfs servers
+	DefaultNFSPort int32 = 2049
+
+	// the msgr2 and msgr1 ports of the mons are the same for all the clusters
+	monHostPorts = []int32{cephclient.Msgr2port, 6789}
+)
+
+// MsgrPortRange returns the range of the ports of the daemons of the cluster
+func MsgrPortRange(networkSpec cephv1.NetworkSpec) cephv1.PortRangeSpec {
+	if portRange := networkSpec.GetMsgrPortRange(); portRange != nil {
+		return *portRange
+	}
+	return DefaultMsgrPortRange
+}
+
+// NFSPort returns the port of the nfs servers of the cluster
+func NFSPort(networkSpec cephv1.NetworkSpec) int32 {
+	if port := networkSpec.GetNFSPort(); port != 0 {
+		return port
+	}
+	return DefaultNFSPort
+}
+
+// applyPortRangeSettings sets the range of the ports of the daemons in the centralized mon configuration database,
+// the settings are removed for the default range
+func applyPortRangeSettings(monStore *MonStore, networkSpec cephv1.NetworkSpec) error {
+	portRange := networkSpec.GetMsgrPortRange()
+	if portRange == nil {
+		for _, option := range []string{"ms_bind_port_min", "ms_bind_port_max"} {
+			if err := monStore.Delete("global", option); err != nil {
+				return errors.Wrapf(err, "failed to reset port range setting %q", option)
+			}
+		}
+		return nil
+	}
+
+	if err := monStore.Set("global", "ms_bind_port_min", strconv.Itoa(int(portRange.Min))); err != nil {
+		return errors.Wrap(err, "failed to set port range setting \"ms_bind_port_min\"")
+	}
+	if err := monStore.Set("global", "ms_bind_port_max", strconv.Itoa(int(portRange.Max))); err != nil {
+		return errors.Wrap(err, "failed to set port range setting \"ms_bind_port_max\"")
+	}
+	return nil
+}
+
+// ValidateHostPorts validates the ports of the daemons on the host network, the range of the ports of the daemons
+// and the port of the nfs servers must not conflict with each other or with the ports of the mons
+func ValidateHostPorts(networkSpec cephv1.NetworkSpec) error {
+	if portRange := networkSpec.GetMsgrPortRange(); portRange != nil {
+		if portRange.Min < 1024 || portRange.Max > 65535 || portRange.Min > portRange.Max {
+			return errors.Errorf("invalid msgr port range %d-%d, the ports must be between 1024 and 65535", portRange.Min, portRange.Max)
+		}
+		for _, port := range monHostPorts {
+			if portRange.Contains(port) {
+				return errors.Errorf("msgr port range %d-%d contains the mon port %d", portRange.Min, portRange.Max, port)
+			}
+		}
+	}
+
+	if port := networkSpec.GetNFSPort(); port != 0 {
+		if port < 1 || port > 65535 {
+			return errors.Errorf("invalid nfs port %d, must be between 1 and 65535", port)
+		}
+		portRange := MsgrPortRange(networkSpec)
+		if portRange.Contains(port) || isMonHostPort(port) {
+			return errors.Errorf("nfs port %d conflicts with the mon ports or the msgr port range %d-%d", port, portRange.Min, portRange.Max)
+		}
+	}
+	return nil
+}
+
+// ValidateHostPort refuses the port of a daemon on the host network which is one of the ports of the mons, in the
+// range of the ports of the daemons or the port of the nfs servers
+func ValidateHostPort(networkSpec cephv1.NetworkSpec, port int32) error {
+	if !networkSpec.IsHost() || port == 0 {
+		return nil
+	}
+	if isMonHostPort(port) {
+		return errors.Errorf("port %d is a port of the mons", port)
+	}
+	if portRange := MsgrPortRange(networkSpec); portRange.Contains(port) {
+		return errors.Errorf("port %d is in the msgr port range %d-%d", port, portRange.Min, portRange.Max)
+	}
+	if port == NFSPort(networkSpec) {
+		return errors.Errorf("port %d is the port of the nfs servers", port)
+	}
+	return nil
+}
+
+func isMonHostPort(port int32) bool {
+	for _, monPort := range monHostPorts {
+		if port == monPort {
+			return true
+		}
+	}
+	return false
+}
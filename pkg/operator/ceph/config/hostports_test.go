@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPortRangeSettings(t *testing.T) {
+	execedCmds := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outfile string, args ...string) (string, error) {
+			execedCmds = append(execedCmds, strings.Join(args[:5], " "))
+			return "", nil
+		},
+	}
+	monStore := GetMonStore(&clusterd.Context{Executor: executor}, &client.ClusterInfo{Namespace: "ns"})
+
+	networkSpec := cephv1.NetworkSpec{HostPorts: &cephv1.HostPortsSpec{MsgrPortRange: &cephv1.PortRangeSpec{Min: 7400, Max: 7900}}}
+	assert.NoError(t, applyPortRangeSettings(monStore, networkSpec))
+	assert.Equal(t, []string{"config set global ms_bind_port_min 7400", "config set global ms_bind_port_max 7900"}, execedCmds)
+
+	// the settings are removed for the default range
+	execedCmds = []string{}
+	assert.NoError(t, applyPortRangeSettings(monStore, cephv1.NetworkSpec{}))
+	assert.Equal(t, 2, len(execedCmds))
+	assert.True(t, strings.HasPrefix(execedCmds[0], "config rm global ms_bind_port_min"))
+}
+
+func TestValidateHostPorts(t *testing.T) {
+	networkSpec := cephv1.NetworkSpec{NetworkSpec: rookv1.NetworkSpec{Provider: "host"}}
+	assert.NoError(t, ValidateHostPorts(networkSpec))
+
+	networkSpec.HostPorts = &cephv1.HostPortsSpec{MsgrPortRange: &cephv1.PortRangeSpec{Min: 7400, Max: 7300}}
+	assert.Error(t, ValidateHostPorts(networkSpec))
+	networkSpec.HostPorts.MsgrPortRange = &cephv1.PortRangeSpec{Min: 3000, Max: 4000}
+	assert.Error(t, ValidateHostPorts(networkSpec))
+	networkSpec.HostPorts.MsgrPortRange = &cephv1.PortRangeSpec{Min: 7400, Max: 7900}
+	assert.NoError(t, ValidateHostPorts(networkSpec))
+
+	// the nfs port must not conflict with the other ports
+	networkSpec.HostPorts.NFSPort = 7500
+	assert.Error(t, ValidateHostPorts(networkSpec))
+	networkSpec.HostPorts.NFSPort = 12049
+	assert.NoError(t, ValidateHostPorts(networkSpec))
+	assert.Equal(t, int32(12049), NFSPort(networkSpec))
+
+	// the gateway ports
+	assert.Error(t, ValidateHostPort(networkSpec, 6789))
+	assert.Error(t, ValidateHostPort(networkSpec, 7400))
+	assert.Error(t, ValidateHostPort(networkSpec, 12049))
+	assert.NoError(t, ValidateHostPort(networkSpec, 8080))
+
+	// the ports only conflict on the host network
+	networkSpec.Provider = ""
+	assert.NoError(t, ValidateHostPort(networkSpec, 6789))
+	assert.Equal(t, DefaultNFSPort, NFSPort(networkSpec))
+}
@@ -52,3 +52,13 @@ func (a *AdminStore) CreateOrUpdate(c *cephclient.ClusterInfo) error {
 	keyring := fmt.Sprintf(adminKeyringTemplate, c.CephCred.Secret)
 	return a.secretStore.CreateOrUpdate(adminKeyringResourceName, keyring)
 }
+
+// Delete deletes the admin keyring secret so the admin key is not available to the pods anymore.
+func (a *AdminStore) Delete() error {
+	return a.secretStore.Delete(adminKeyringResourceName)
+}
+
+// AdminKeyringSecretName returns the name of the secret with the admin keyring.
+func AdminKeyringSecretName() string {
+	return keyringSecretName(adminKeyringResourceName)
+}
@@ -20,8 +20,17 @@ limitations under the License.
 package keyring
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/k8sutil"
@@ -82,9 +91,93 @@ func (k *SecretStore) GenerateKey(user string, access []string) (string, error)
 	return key, nil
 }
 
+// RotateKey replaces the key of a Ceph user with a new key, the capabilities of the user are kept. The old key is
+// replaced by the new key in the given secrets, the daemons and clients mounting the secrets get the new key when they
+// restart. It returns the new key.
+func (k *SecretStore) RotateKey(user string, secretNames ...string) (string, error) {
+	entity, err := client.AuthGet(k.context, k.clusterInfo, user)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the current key of %q", user)
+	}
+	newKey, err := client.GenerateAuthKey()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir("", "cephx-key-rotation")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create the directory of the keyring file")
+	}
+	defer os.RemoveAll(dir)
+	keyringPath := filepath.Join(dir, keyringFileName)
+	if err := ioutil.WriteFile(keyringPath, []byte(importKeyring(user, newKey, entity.Caps)), 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write the keyring file")
+	}
+	if err := client.AuthImport(k.context, k.clusterInfo, keyringPath); err != nil {
+		return "", errors.Wrapf(err, "failed to import the new key of %q", user)
+	}
+
+	for _, secretName := range secretNames {
+		if err := k.replaceKey(secretName, entity.Key, newKey); err != nil {
+			return "", errors.Wrapf(err, "failed to save the new key of %q", user)
+		}
+	}
+	logger.Infof("rotated the cephx key of %q", user)
+	return newKey, nil
+}
+
+// RotateResourceKey replaces the key of the Ceph user of a resource with a new key and updates the keyring secret of
+// the resource. It returns the new key.
+func (k *SecretStore) RotateResourceKey(user, resourceName string) (string, error) {
+	return k.RotateKey(user, keyringSecretName(resourceName))
+}
+
+// importKeyring returns a keyring with the key and the capabilities of a user in the format of `ceph auth get`
+func importKeyring(user, key string, caps map[string]string) string {
+	daemonTypes := []string{}
+	for daemonType := range caps {
+		daemonTypes = append(daemonTypes, daemonType)
+	}
+	sort.Strings(daemonTypes)
+
+	keyring := fmt.Sprintf("[%s]\n\tkey = %s\n", user, key)
+	for _, daemonType := range daemonTypes {
+		keyring += fmt.Sprintf("\tcaps %s = %q\n", daemonType, caps[daemonType])
+	}
+	return keyring
+}
+
+// replaceKey replaces the old key with the new key in all the values of a secret
+func (k *SecretStore) replaceKey(secretName, oldKey, newKey string) error {
+	secret, err := k.context.Clientset.CoreV1().Secrets(k.clusterInfo.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debugf("secret %q not found, the new key will be saved when it is created", secretName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get secret %q", secretName)
+	}
+	for name, value := range secret.Data {
+		secret.Data[name] = bytes.Replace(value, []byte(oldKey), []byte(newKey), -1)
+	}
+	for name, value := range secret.StringData {
+		secret.StringData[name] = strings.Replace(value, oldKey, newKey, -1)
+	}
+	if _, err := k.context.Clientset.CoreV1().Secrets(k.clusterInfo.Namespace).Update(secret); err != nil {
+		return errors.Wrapf(err, "failed to update secret %q", secretName)
+	}
+	return nil
+}
+
 // CreateOrUpdate creates or updates the keyring secret for the resource with the keyring specified.
 // WARNING: Do not use "rook-ceph-admin" as the resource name; conflicts with the AdminStore.
 func (k *SecretStore) CreateOrUpdate(resourceName string, keyring string) error {
+	return k.CreateOrUpdateWithMeta(resourceName, keyring, nil, nil)
+}
+
+// CreateOrUpdateWithMeta creates or updates the keyring secret for the resource with the keyring specified, the
+// annotations and labels of the daemon are added to the secret.
+func (k *SecretStore) CreateOrUpdateWithMeta(resourceName string, keyring string, annotations rookv1.Annotations, labels rookv1.Labels) error {
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      keyringSecretName(resourceName),
@@ -95,6 +188,10 @@ func (k *SecretStore) CreateOrUpdate(resourceName string, keyring string) error
 		},
 		Type: k8sutil.RookType,
 	}
+	if len(annotations) > 0 {
+		annotations.ApplyToObjectMeta(&secret.ObjectMeta)
+	}
+	labels.ApplyToObjectMeta(&secret.ObjectMeta)
 	k8sutil.SetOwnerRef(&secret.ObjectMeta, k.ownerRef)
 
 	return k.CreateSecret(secret)
@@ -114,6 +211,7 @@ func (k *SecretStore) Delete(resourceName string) error {
 // CreateSecret creates or update a kubernetes secret
 func (k *SecretStore) CreateSecret(secret *v1.Secret) error {
 	secretName := secret.ObjectMeta.Name
+	k8sutil.ApplyInheritedMetadata(k.clusterInfo.Namespace, &secret.ObjectMeta)
 	_, err := k.context.Clientset.CoreV1().Secrets(k.clusterInfo.Namespace).Get(secretName, metav1.GetOptions{})
 	if err != nil {
 		if kerrors.IsNotFound(err) {
@@ -17,10 +17,12 @@ limitations under the License.
 package keyring
 
 import (
+	"io/ioutil"
 	"path"
 	"testing"
 
 	"github.com/pkg/errors"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/k8sutil"
@@ -110,6 +112,15 @@ func TestKeyringStore(t *testing.T) {
 	k.Delete("test-resource")
 	assertDoesNotExist("test-resource-keyring")
 	assertKeyringData("second-resource-keyring", "lkjhgfdsa")
+
+	// the annotations and labels of the daemon are added to the secret
+	err := k.CreateOrUpdateWithMeta("third-resource", "zxcvbnm", rookv1.Annotations{"team": "storage"}, rookv1.Labels{"cost-center": "ceph"})
+	assert.NoError(t, err)
+	assertKeyringData("third-resource-keyring", "zxcvbnm")
+	s, err := clientset.CoreV1().Secrets(ns).Get("third-resource-keyring", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "storage"}, s.Annotations)
+	assert.Equal(t, map[string]string{"cost-center": "ceph"}, s.Labels)
 }
 
 func TestResourceVolumeAndMount(t *testing.T) {
@@ -130,3 +141,34 @@ func TestResourceVolumeAndMount(t *testing.T) {
 	assert.Equal(t, "test-resource-keyring", v.VolumeSource.Secret.SecretName)
 	assert.Equal(t, VolumeMount().KeyringFilePath(), path.Join(m.MountPath, keyringFileName))
 }
+
+func TestRotateKey(t *testing.T) {
+	imported := ""
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outFileArg string, args ...string) (string, error) {
+			switch args[1] {
+			case "get":
+				return `[{"entity":"mgr.a","key":"oldkey","caps":{"mon":"allow profile mgr","osd":"allow *"}}]`, nil
+			case "import":
+				keyring, err := ioutil.ReadFile(args[3])
+				assert.NoError(t, err)
+				imported = string(keyring)
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected command %v", args)
+		},
+	}
+	ctx := &clusterd.Context{Clientset: testop.New(t, 1), Executor: executor}
+	k := GetSecretStore(ctx, &client.ClusterInfo{Namespace: "ns"}, &metav1.OwnerReference{})
+	assert.NoError(t, k.CreateOrUpdate("rook-ceph-mgr-a", "[mgr.a]\nkey = oldkey\n"))
+
+	newKey, err := k.RotateKey("mgr.a", "rook-ceph-mgr-a-keyring", "missing-secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "oldkey", newKey)
+	// the caps of the user are kept
+	assert.Equal(t, "[mgr.a]\n\tkey = "+newKey+"\n\tcaps mon = \"allow profile mgr\"\n\tcaps osd = \"allow *\"\n", imported)
+
+	secret, err := ctx.Clientset.CoreV1().Secrets("ns").Get("rook-ceph-mgr-a-keyring", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[mgr.a]\nkey = "+newKey+"\n", secret.StringData[keyringFileName])
+}
@@ -24,29 +24,74 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
-type fn func(cephv1.CephClusterHealthCheckSpec) *v1.Probe
-
 // ConfigureLivenessProbe returns the desired liveness probe for a given daemon
 func ConfigureLivenessProbe(daemon rookv1.KeyType, container v1.Container, healthCheck cephv1.CephClusterHealthCheckSpec) v1.Container {
-	// Map of functions
-	probeFnMap := map[rookv1.KeyType]fn{
-		cephv1.KeyMon: cephv1.GetMonLivenessProbe,
-		cephv1.KeyMgr: cephv1.GetMgrLivenessProbe,
-		cephv1.KeyOSD: cephv1.GetOSDLivenessProbe,
+	container.LivenessProbe = ConfigureProbe(container.LivenessProbe, healthCheck.LivenessProbe[daemon])
+	return container
+}
+
+// ConfigureProbes returns the container of a daemon with the startup, liveness and readiness probes of the health
+// check spec. The daemons have no default startup and readiness probes, their probes without handler use the handler
+// of the liveness probe of the daemon, or of its default liveness probe when the liveness probe is disabled.
+func ConfigureProbes(daemon rookv1.KeyType, container v1.Container, healthCheck cephv1.CephClusterHealthCheckSpec) v1.Container {
+	defaultLiveness := container.LivenessProbe
+	container = ConfigureLivenessProbe(daemon, container, healthCheck)
+	liveness := container.LivenessProbe
+	if liveness == nil {
+		liveness = defaultLiveness
 	}
+	container.StartupProbe = ConfigureExtraProbe(container.StartupProbe, liveness, healthCheck.StartupProbe[daemon])
+	container.ReadinessProbe = ConfigureExtraProbe(container.ReadinessProbe, liveness, healthCheck.ReadinessProbe[daemon])
+	return container
+}
 
-	if _, ok := healthCheck.LivenessProbe[daemon]; ok {
-		if !healthCheck.LivenessProbe[daemon].Disabled {
-			probe := probeFnMap[daemon](healthCheck)
+// ConfigureProbe returns the probe of a daemon with the override of the spec. The probe of the spec replaces the
+// default probe when it has a handler, otherwise only its thresholds, timeouts and delays override the default probe.
+func ConfigureProbe(defaultProbe *v1.Probe, spec *rookv1.ProbeSpec) *v1.Probe {
+	if spec == nil {
+		return defaultProbe
+	}
+	if spec.Disabled {
+		return nil
+	}
+	// If the spec value is empty, let's use a default
+	if spec.Probe == nil {
+		return defaultProbe
+	}
+	if hasHandler(spec.Probe) {
+		return spec.Probe
+	}
+	if defaultProbe == nil {
+		return nil
+	}
+	probe := defaultProbe.DeepCopy()
+	if spec.Probe.InitialDelaySeconds != 0 {
+		probe.InitialDelaySeconds = spec.Probe.InitialDelaySeconds
+	}
+	if spec.Probe.TimeoutSeconds != 0 {
+		probe.TimeoutSeconds = spec.Probe.TimeoutSeconds
+	}
+	if spec.Probe.PeriodSeconds != 0 {
+		probe.PeriodSeconds = spec.Probe.PeriodSeconds
+	}
+	if spec.Probe.SuccessThreshold != 0 {
+		probe.SuccessThreshold = spec.Probe.SuccessThreshold
+	}
+	if spec.Probe.FailureThreshold != 0 {
+		probe.FailureThreshold = spec.Probe.FailureThreshold
+	}
+	return probe
+}
 
-			// If the spec value is empty, let's use a default
-			if probe != nil {
-				container.LivenessProbe = probe
-			}
-		} else {
-			container.LivenessProbe = nil
-		}
+// ConfigureExtraProbe returns the startup or readiness probe of a daemon with the override of the spec. When the
+// daemon has no default probe, the override without handler is a probe with the handler of the liveness probe.
+func ConfigureExtraProbe(defaultProbe, liveness *v1.Probe, spec *rookv1.ProbeSpec) *v1.Probe {
+	if defaultProbe == nil && liveness != nil && spec != nil && spec.Probe != nil {
+		defaultProbe = &v1.Probe{Handler: *liveness.Handler.DeepCopy()}
 	}
+	return ConfigureProbe(defaultProbe, spec)
+}
 
-	return container
+func hasHandler(probe *v1.Probe) bool {
+	return probe.Exec != nil || probe.HTTPGet != nil || probe.TCPSocket != nil
 }
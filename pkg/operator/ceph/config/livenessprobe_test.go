@@ -24,6 +24,7 @@ import (
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -60,3 +61,56 @@ func TestConfigureLivenessProbe(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureProbes(t *testing.T) {
+	liveness := &v1.Probe{
+		Handler:          v1.Handler{Exec: &v1.ExecAction{Command: []string{"ceph", "--admin-daemon", "osd.0.asok", "status"}}},
+		PeriodSeconds:    10,
+		FailureThreshold: 3,
+	}
+	container := v1.Container{LivenessProbe: liveness}
+
+	// no override
+	got := ConfigureProbes(cephv1.KeyOSD, container, cephv1.CephClusterHealthCheckSpec{})
+	assert.Equal(t, liveness, got.LivenessProbe)
+	assert.Nil(t, got.StartupProbe)
+	assert.Nil(t, got.ReadinessProbe)
+
+	// the thresholds override the default probe, the startup probe uses the handler of the liveness probe
+	healthCheck := cephv1.CephClusterHealthCheckSpec{
+		LivenessProbe: map[rookv1.KeyType]*rookv1.ProbeSpec{cephv1.KeyOSD: {Probe: &v1.Probe{TimeoutSeconds: 5}}},
+		StartupProbe:  map[rookv1.KeyType]*rookv1.ProbeSpec{cephv1.KeyOSD: {Probe: &v1.Probe{FailureThreshold: 60}}},
+	}
+	got = ConfigureProbes(cephv1.KeyOSD, container, healthCheck)
+	assert.Equal(t, int32(5), got.LivenessProbe.TimeoutSeconds)
+	assert.Equal(t, int32(10), got.LivenessProbe.PeriodSeconds)
+	assert.Equal(t, liveness.Handler, got.LivenessProbe.Handler)
+	assert.Equal(t, int32(0), liveness.TimeoutSeconds)
+	assert.Equal(t, &v1.Probe{Handler: liveness.Handler, FailureThreshold: 60}, got.StartupProbe)
+	assert.Nil(t, got.ReadinessProbe)
+	got = ConfigureProbes(cephv1.KeyMon, container, healthCheck)
+	assert.Equal(t, liveness, got.LivenessProbe)
+	assert.Nil(t, got.StartupProbe)
+
+	// a probe with a handler replaces the default probe, the disabled liveness probe does not remove the handler of
+	// the other probes
+	readiness := &v1.Probe{Handler: v1.Handler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(6800)}}}
+	healthCheck = cephv1.CephClusterHealthCheckSpec{
+		LivenessProbe:  map[rookv1.KeyType]*rookv1.ProbeSpec{cephv1.KeyOSD: {Disabled: true}},
+		StartupProbe:   map[rookv1.KeyType]*rookv1.ProbeSpec{cephv1.KeyOSD: {Probe: &v1.Probe{FailureThreshold: 60}}},
+		ReadinessProbe: map[rookv1.KeyType]*rookv1.ProbeSpec{cephv1.KeyOSD: {Probe: readiness}},
+	}
+	got = ConfigureProbes(cephv1.KeyOSD, container, healthCheck)
+	assert.Nil(t, got.LivenessProbe)
+	assert.Equal(t, liveness.Handler, got.StartupProbe.Handler)
+	assert.Equal(t, readiness, got.ReadinessProbe)
+
+	// the disabled probes are removed
+	container.StartupProbe = liveness
+	healthCheck = cephv1.CephClusterHealthCheckSpec{
+		StartupProbe: map[rookv1.KeyType]*rookv1.ProbeSpec{cephv1.KeyOSD: {Disabled: true}},
+	}
+	got = ConfigureProbes(cephv1.KeyOSD, container, healthCheck)
+	assert.Nil(t, got.StartupProbe)
+	assert.Equal(t, liveness, got.LivenessProbe)
+}
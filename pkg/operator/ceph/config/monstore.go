@@ -62,6 +62,7 @@ func (m *MonStore) Set(who, option, value string) error {
 		return errors.Wrapf(err, "failed to set ceph config in the centralized mon configuration database; "+
 			"you may need to use the rook-config-override ConfigMap. output: %s", string(out))
 	}
+	recordGeneratedOption(m.clusterInfo.Namespace, who, option, &value)
 	return nil
 }
 
@@ -74,6 +75,7 @@ func (m *MonStore) Delete(who, option string) error {
 		return errors.Wrapf(err, "failed to delete ceph config in the centralized mon configuration database. output: %s",
 			string(out))
 	}
+	recordGeneratedOption(m.clusterInfo.Namespace, who, option, nil)
 	return nil
 }
 
@@ -104,11 +106,15 @@ func (m *MonStore) GetDaemon(who string) ([]Option, error) {
 	}
 	daemonOptions := []Option{}
 	for k := range result {
-		v := result[k].(map[string]interface{})
-		optionWho := v["section"].(string)
+		v, ok := result[k].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		optionWho, _ := v["section"].(string)
 		// Only get specialized options (don't take global one)
 		if optionWho == who {
-			daemonOptions = append(daemonOptions, Option{optionWho, k, v["value"].(string)})
+			value, _ := v["value"].(string)
+			daemonOptions = append(daemonOptions, Option{optionWho, k, value})
 		}
 	}
 	return daemonOptions, nil
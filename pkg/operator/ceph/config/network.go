@@ -19,10 +19,16 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -37,39 +43,152 @@ const (
 var (
 	// NetworkSelectors is a slice of ceph network selector key name
 	NetworkSelectors = []string{PublicNetworkSelectorKeyName, ClusterNetworkSelectorKeyName}
+
+	// DualStackMinimumVersion is the minimum version of Ceph binding the daemons to both IPv4 and IPv6 addresses
+	DualStackMinimumVersion = version.CephVersion{Major: 16}
 )
 
-func generateNetworkSettings(context *clusterd.Context, namespace string, networkSelectors map[string]string) ([]Option, error) {
-	cephNetworks := []Option{}
+// IPFamilyFlags returns the flags binding a daemon to the IP family of the network, they are needed by the mons
+// which start before the settings are set in the centralized configuration
+func IPFamilyFlags(networkSpec cephv1.NetworkSpec) []string {
+	msBindIPv4, msBindIPv6 := cephclient.MsBindSettings(networkSpec)
+	if msBindIPv4 == "" {
+		return []string{}
+	}
+	return []string{NewFlag("ms-bind-ipv4", msBindIPv4), NewFlag("ms-bind-ipv6", msBindIPv6)}
+}
+
+// applyIPFamilySettings binds the daemons to the IP family of the network in the centralized mon configuration
+// database, the settings are removed for the IPv4 single stack default
+func applyIPFamilySettings(monStore *MonStore, networkSpec cephv1.NetworkSpec) error {
+	msBindIPv4, msBindIPv6 := cephclient.MsBindSettings(networkSpec)
+	settings := map[string]string{"ms_bind_ipv4": msBindIPv4, "ms_bind_ipv6": msBindIPv6}
+	for _, option := range []string{"ms_bind_ipv4", "ms_bind_ipv6"} {
+		if settings[option] == "" {
+			if err := monStore.Delete("global", option); err != nil {
+				return errors.Wrapf(err, "failed to reset ip family setting %q", option)
+			}
+			continue
+		}
+		if err := monStore.Set("global", option, settings[option]); err != nil {
+			return errors.Wrapf(err, "failed to set ip family setting %q", option)
+		}
+	}
+	return nil
+}
+
+// ApplyIPFamily sets the IP family of a service to the family of the network. The family of an existing service
+// cannot change, it is kept when the service is updated.
+func ApplyIPFamily(networkSpec cephv1.NetworkSpec, service *v1.Service) {
+	if networkSpec.IPFamily == "" || networkSpec.IsHost() {
+		return
+	}
+	family := v1.IPFamily(networkSpec.IPFamily)
+	service.Spec.IPFamily = &family
+}
+
+// ValidateNetworkSelectors validates the network attachment definitions of the multus selectors of the ceph public
+// and cluster networks, the ipam of each network attachment must assign the addresses from a subnet of the IP
+// family of the cluster
+func ValidateNetworkSelectors(context *clusterd.Context, namespace string, networkSpec cephv1.NetworkSpec) error {
+	networkSelectors := networkSpec.Selectors
+	_, isPublic := networkSelectors[PublicNetworkSelectorKeyName]
+	_, isCluster := networkSelectors[ClusterNetworkSelectorKeyName]
+	if !isPublic && !isCluster {
+		return errors.New("both network selector values for public and cluster selector cannot be empty for multus provider")
+	}
 
 	for _, selectorKey := range NetworkSelectors {
-		// This means only "public" was specified and thus we use the same subnet for cluster too
-		if _, ok := networkSelectors[selectorKey]; !ok {
-			cephNetworks = append(cephNetworks, cephNetworks[0])
-			cephNetworks[1].Option = fmt.Sprintf("%s_network", selectorKey)
+		// If one selector is empty, we continue
+		// This means a single interface is used both public and cluster network
+		selector, ok := networkSelectors[selectorKey]
+		if !ok {
 			continue
 		}
+		subnets, err := networkSubnets(context, namespace, selectorKey, selector)
+		if err != nil {
+			return err
+		}
+		if err := validateSubnetsFamily(networkSpec, subnets); err != nil {
+			return errors.Wrapf(err, "invalid network attachment definition %q", selector)
+		}
+	}
+	return nil
+}
 
-		// Get network attachment definition
-		netDefinition, err := context.NetworkClient.NetworkAttachmentDefinitions(namespace).Get(networkSelectors[selectorKey], metav1.GetOptions{})
+// validateSubnetsFamily checks that the subnets are of the IP family of the single stack cluster
+func validateSubnetsFamily(networkSpec cephv1.NetworkSpec, subnets []string) error {
+	if networkSpec.IPFamily == "" || networkSpec.DualStack {
+		return nil
+	}
+	for _, subnet := range subnets {
+		ip, _, err := net.ParseCIDR(subnet)
 		if err != nil {
-			if kerrors.IsNotFound(err) {
-				return []Option{}, errors.Wrapf(err, "specified network attachment definition for selector %q does not exist", selectorKey)
+			return errors.Wrapf(err, "invalid subnet %q", subnet)
+		}
+		if (ip.To4() == nil) != networkSpec.IsIPv6() {
+			return errors.Errorf("subnet %q is not of the %s family of the cluster", subnet, networkSpec.IPFamily)
+		}
+	}
+	return nil
+}
+
+// networkSubnets returns the subnets of the network attachment definition of a multus selector
+func networkSubnets(context *clusterd.Context, namespace, selectorKey, selector string) ([]string, error) {
+	netNamespace, netName, err := k8sutil.GetMultusNetworkName(selector, namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid network selector %q for %q", selector, selectorKey)
+	}
+
+	// Get network attachment definition
+	netDefinition, err := context.NetworkClient.NetworkAttachmentDefinitions(netNamespace).Get(netName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "specified network attachment definition for selector %q does not exist", selectorKey)
+		}
+		return nil, errors.Wrapf(err, "failed to fetch network attachment definition for selector %q", selectorKey)
+	}
+
+	// Get network attachment definition configuration
+	netConfig, err := k8sutil.GetNetworkAttachmentConfig(*netDefinition)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get network attachment definition configuration for selector %q", selectorKey)
+	}
+
+	subnets := netConfig.Subnets()
+	if len(subnets) == 0 {
+		return nil, errors.Errorf("empty subnet from network attachment definition %q", selector)
+	}
+	return subnets, nil
+}
+
+// generateNetworkSettings sets the ceph public and cluster networks to the subnets of the network attachment
+// definitions. The cluster network is the public network when only the public selector is set, and the public
+// network is the pod network when only the cluster selector is set.
+func generateNetworkSettings(context *clusterd.Context, namespace string, networkSelectors map[string]string) ([]Option, error) {
+	cephNetworks := []Option{}
+	publicSubnets := ""
+
+	for _, selectorKey := range NetworkSelectors {
+		option := fmt.Sprintf("%s_network", selectorKey)
+		selector, ok := networkSelectors[selectorKey]
+		if !ok {
+			// This means only "public" was specified and thus we use the same subnet for cluster too
+			if selectorKey == ClusterNetworkSelectorKeyName && publicSubnets != "" {
+				cephNetworks = append(cephNetworks, configOverride("global", option, publicSubnets))
 			}
-			return []Option{}, errors.Wrapf(err, "failed to fetch network attachment definition for selector %q", selectorKey)
+			continue
 		}
 
-		// Get network attachment definition configuration
-		netConfig, err := k8sutil.GetNetworkAttachmentConfig(*netDefinition)
+		subnets, err := networkSubnets(context, namespace, selectorKey, selector)
 		if err != nil {
-			return []Option{}, errors.Wrapf(err, "failed to get network attachment definition configuration for selector %q", selectorKey)
+			return []Option{}, err
 		}
-
-		if netConfig.Ipam.Subnet != "" {
-			cephNetworks = append(cephNetworks, configOverride("global", fmt.Sprintf("%s_network", selectorKey), netConfig.Ipam.Subnet))
-		} else {
-			return []Option{}, errors.Errorf("empty subnet from network attachment definition %q", networkSelectors[selectorKey])
+		value := strings.Join(subnets, ",")
+		if selectorKey == PublicNetworkSelectorKeyName {
+			publicSubnets = value
 		}
+		cephNetworks = append(cephNetworks, configOverride("global", option, value))
 	}
 
 	return cephNetworks, nil
@@ -18,13 +18,19 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	networkv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	fakenetclient "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned/fake"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
 	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -135,4 +141,98 @@ func TestGenerateNetworkSettings(t *testing.T) {
 	cephNetwork, err = generateNetworkSettings(ctx, ns, netSelector)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, cephNetwork, expectedNetworks, fmt.Sprintf("networks: %+v", cephNetwork))
+
+	//
+	// TEST 4: selectors with the interface, a network attachment with the whereabouts range and only the cluster network
+	//
+	network3 := &networkv1.NetworkAttachmentDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "whereabouts-net",
+			Namespace: "other",
+		},
+		Spec: networkv1.NetworkAttachmentDefinitionSpec{
+			Config: `{"cniVersion": "0.3.0", "type": "macvlan", "ipam": {"type": "whereabouts", "range": "10.10.0.0/16"}}`,
+		},
+	}
+	ctx.NetworkClient.NetworkAttachmentDefinitions("other").Create(network3)
+
+	netSelector = map[string]string{"cluster": "other/whereabouts-net@net2"}
+	cephNetwork, err = generateNetworkSettings(ctx, ns, netSelector)
+	assert.NoError(t, err)
+	assert.Equal(t, []Option{{Who: "global", Option: "cluster_network", Value: "10.10.0.0/16"}}, cephNetwork)
+	assert.NoError(t, ValidateNetworkSelectors(ctx, ns, cephv1.NetworkSpec{NetworkSpec: rookv1.NetworkSpec{Selectors: netSelector}}))
+}
+
+func TestValidateNetworkSelectors(t *testing.T) {
+	ns := "rook-ceph"
+	ctx := &clusterd.Context{NetworkClient: fakenetclient.NewSimpleClientset().K8sCniCncfIoV1()}
+
+	// a selector is required
+	assert.Error(t, ValidateNetworkSelectors(ctx, ns, cephv1.NetworkSpec{}))
+
+	// the network attachment must exist
+	selectors := cephv1.NetworkSpec{NetworkSpec: rookv1.NetworkSpec{Selectors: map[string]string{"public": "public-net@net1"}}}
+	assert.Error(t, ValidateNetworkSelectors(ctx, ns, selectors))
+
+	// the network attachment must have a subnet
+	network := &networkv1.NetworkAttachmentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "public-net", Namespace: ns},
+		Spec:       networkv1.NetworkAttachmentDefinitionSpec{Config: `{"cniVersion": "0.3.0", "type": "macvlan", "ipam": {"type": "dhcp"}}`},
+	}
+	_, err := ctx.NetworkClient.NetworkAttachmentDefinitions(ns).Create(network)
+	assert.NoError(t, err)
+	assert.Error(t, ValidateNetworkSelectors(ctx, ns, selectors))
+
+	network.Spec.Config = `{"cniVersion": "0.3.0", "type": "macvlan", "ipam": {"type": "host-local", "ranges": [[{"subnet": "192.168.20.0/24"}]]}}`
+	_, err = ctx.NetworkClient.NetworkAttachmentDefinitions(ns).Update(network)
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateNetworkSelectors(ctx, ns, selectors))
+
+	// the subnets must be of the ip family of a single stack cluster
+	selectors.IPFamily = cephv1.IPv6
+	assert.Error(t, ValidateNetworkSelectors(ctx, ns, selectors))
+	selectors.DualStack = true
+	assert.NoError(t, ValidateNetworkSelectors(ctx, ns, selectors))
+	selectors.DualStack = false
+	network.Spec.Config = `{"cniVersion": "0.3.0", "type": "macvlan", "ipam": {"type": "whereabouts", "range": "fd00:20::/64"}}`
+	_, err = ctx.NetworkClient.NetworkAttachmentDefinitions(ns).Update(network)
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateNetworkSelectors(ctx, ns, selectors))
+}
+
+func TestIPFamilySettings(t *testing.T) {
+	execedCmds := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command string, outfile string, args ...string) (string, error) {
+			execedCmds = append(execedCmds, strings.Join(args[:4], " "))
+			return "", nil
+		},
+	}
+	monStore := GetMonStore(&clusterd.Context{Executor: executor}, &client.ClusterInfo{Namespace: "ns"})
+
+	// the settings are removed for the ipv4 default
+	networkSpec := cephv1.NetworkSpec{}
+	assert.NoError(t, applyIPFamilySettings(monStore, networkSpec))
+	assert.Equal(t, []string{"config rm global ms_bind_ipv4", "config rm global ms_bind_ipv6"}, execedCmds)
+	assert.Equal(t, []string{}, IPFamilyFlags(networkSpec))
+
+	execedCmds = []string{}
+	networkSpec.IPFamily = cephv1.IPv6
+	assert.NoError(t, applyIPFamilySettings(monStore, networkSpec))
+	assert.Equal(t, []string{"config set global ms_bind_ipv4", "config set global ms_bind_ipv6"}, execedCmds)
+	assert.Equal(t, []string{"--ms-bind-ipv4=false", "--ms-bind-ipv6=true"}, IPFamilyFlags(networkSpec))
+}
+
+func TestApplyIPFamily(t *testing.T) {
+	service := &v1.Service{}
+	ApplyIPFamily(cephv1.NetworkSpec{}, service)
+	assert.Nil(t, service.Spec.IPFamily)
+
+	ApplyIPFamily(cephv1.NetworkSpec{IPFamily: cephv1.IPv6}, service)
+	assert.Equal(t, v1.IPv6Protocol, *service.Spec.IPFamily)
+
+	// the services of the host network keep the family of the cluster
+	service = &v1.Service{}
+	ApplyIPFamily(cephv1.NetworkSpec{IPFamily: cephv1.IPv6, NetworkSpec: rookv1.NetworkSpec{Provider: "host"}}, service)
+	assert.Nil(t, service.Spec.IPFamily)
 }
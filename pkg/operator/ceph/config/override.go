@@ -0,0 +1,281 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-ini/ini"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the config map of the base of the merge of the config override with the generated options
+	overrideBaseConfigMapName = "rook-config-override-base"
+	overrideBaseKey           = "base"
+)
+
+var (
+	// the options set by the operator in the centralized mon configuration database of each cluster since the
+	// operator started, by "<who>/<option>", a nil value is an option deleted by the operator
+	generatedOptions    = map[string]map[string]*string{}
+	generatedOptionsMux sync.Mutex
+)
+
+// OverrideConflict is an option of the rook-config-override config map which conflicts with an option generated by
+// the operator
+type OverrideConflict struct {
+	// Section is the section of the option in the config override
+	Section string
+	// Option is the normalized name of the option
+	Option string
+	// Value is the value of the option in the config override
+	Value string
+	// Who is the entity the operator generated the option for
+	Who string
+	// GeneratedValue is the value generated by the operator, empty for the flags with an environment variable
+	GeneratedValue string
+	// Ignored is true when the daemons ignore the override since the operator sets the option on the command line,
+	// otherwise the override takes precedence over the generated value
+	Ignored bool
+}
+
+func (c OverrideConflict) String() string {
+	if c.Ignored {
+		return fmt.Sprintf("[%s] %s=%q is ignored since the operator sets it on the command line", c.Section, c.Option, c.Value)
+	}
+	return fmt.Sprintf("[%s] %s=%q overrides the value %q generated by the operator for %q", c.Section, c.Option, c.Value, c.GeneratedValue, c.Who)
+}
+
+// id identifies a conflicting pair of options of the override and of the operator
+func (c OverrideConflict) id() string {
+	return fmt.Sprintf("%s/%s:%s", c.Section, c.Option, c.Who)
+}
+
+// overrideBase is the state of the last merge of the config override, the base of the next three-way merge
+type overrideBase struct {
+	// Generated are the options generated by the operator, by "<who>/<option>"
+	Generated map[string]string `json:"generated"`
+	// Override are the options of the config override, by "<section>/<option>"
+	Override map[string]string `json:"override"`
+	// Accepted are the conflicts the user deliberately set, by changing the override of a generated value which did not
+	// change
+	Accepted []string `json:"accepted,omitempty"`
+	// Conflicts are the conflicts reported by the last merge
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+func recordGeneratedOption(namespace, who, option string, value *string) {
+	generatedOptionsMux.Lock()
+	defer generatedOptionsMux.Unlock()
+	if _, ok := generatedOptions[namespace]; !ok {
+		generatedOptions[namespace] = map[string]*string{}
+	}
+	generatedOptions[namespace][who+"/"+normalizeKey(option)] = value
+}
+
+// ForgetGeneratedOptions forgets the options generated for a deleted cluster
+func ForgetGeneratedOptions(namespace string) {
+	generatedOptionsMux.Lock()
+	defer generatedOptionsMux.Unlock()
+	delete(generatedOptions, namespace)
+}
+
+// currentGeneratedOptions returns the options generated by the operator for a cluster: the options of the base of
+// the last merge updated with the options set and deleted since the operator started
+func currentGeneratedOptions(namespace string, base map[string]string) map[string]string {
+	generatedOptionsMux.Lock()
+	defer generatedOptionsMux.Unlock()
+	options := map[string]string{}
+	for key, value := range base {
+		options[key] = value
+	}
+	for key, value := range generatedOptions[namespace] {
+		if value == nil {
+			delete(options, key)
+		} else {
+			options[key] = *value
+		}
+	}
+	return options
+}
+
+// flagOptions returns the options set by the operator on the command line of all the daemons, by normalized name.
+// The value of the options set with an environment variable is empty.
+func flagOptions() map[string]string {
+	options := map[string]string{}
+	for _, flag := range DefaultFlags("", "") {
+		keyValue := strings.SplitN(strings.TrimPrefix(flag, "--"), "=", 2)
+		value := keyValue[1]
+		if strings.HasPrefix(value, "$(") {
+			value = ""
+		}
+		options[normalizeKey(keyValue[0])] = value
+	}
+	return options
+}
+
+// parseConfigOverride returns the options of the config override, by "<section>/<option>"
+func parseConfigOverride(override string) (map[string]string, error) {
+	options := map[string]string{}
+	if strings.TrimSpace(override) == "" {
+		return options, nil
+	}
+	file, err := ini.Load([]byte(override))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the config override")
+	}
+	for _, section := range file.Sections() {
+		for _, key := range section.Keys() {
+			options[section.Name()+"/"+normalizeKey(key.Name())] = key.Value()
+		}
+	}
+	return options, nil
+}
+
+// sectionsOverlap returns whether the options of a section of the config override apply to the daemons of the
+// entity of a generated option. The options of the config file take precedence over the options of the centralized
+// database whatever their section.
+func sectionsOverlap(section, who string) bool {
+	daemonType := func(name string) string {
+		return strings.SplitN(name, ".", 2)[0]
+	}
+	return section == who || section == ini.DefaultSection || section == "global" || who == "global" ||
+		daemonType(section) == who || daemonType(who) == section
+}
+
+func copyOptions(options map[string]string) map[string]string {
+	c := make(map[string]string, len(options))
+	for key, value := range options {
+		c[key] = value
+	}
+	return c
+}
+
+func splitOptionKey(key string) (string, string) {
+	i := strings.LastIndex(key, "/")
+	return key[:i], key[i+1:]
+}
+
+// mergeConfigOverride merges the options of the config override with the options generated by the operator and
+// returns the conflicting options and the base of the next merge. An option of the override conflicts with a
+// generated option of another value when the operator sets it on the command line, or when the generated value
+// changed since the base while the user did not deliberately update the override after it. The user accepted the
+// override when it changed while the generated value did not.
+func mergeConfigOverride(override, generated map[string]string, base *overrideBase) ([]OverrideConflict, *overrideBase) {
+	flags := flagOptions()
+	accepted := map[string]bool{}
+	if base != nil {
+		for _, id := range base.Accepted {
+			accepted[id] = true
+		}
+	}
+
+	conflicts := []OverrideConflict{}
+	newBase := &overrideBase{Generated: copyOptions(generated), Override: copyOptions(override)}
+	for overrideKey, value := range override {
+		section, option := splitOptionKey(overrideKey)
+		if flagValue, ok := flags[option]; ok && flagValue != value {
+			conflicts = append(conflicts, OverrideConflict{Section: section, Option: option, Value: value, Who: "global", GeneratedValue: flagValue, Ignored: true})
+			continue
+		}
+		for generatedKey, generatedValue := range generated {
+			who, generatedOption := splitOptionKey(generatedKey)
+			if generatedOption != option || generatedValue == value || !sectionsOverlap(section, who) {
+				continue
+			}
+			conflict := OverrideConflict{Section: section, Option: option, Value: value, Who: who, GeneratedValue: generatedValue}
+			if base != nil {
+				baseGenerated, hadGenerated := base.Generated[generatedKey]
+				baseOverride, hadOverride := base.Override[overrideKey]
+				generatedChanged := !hadGenerated || baseGenerated != generatedValue
+				overrideChanged := !hadOverride || baseOverride != value
+				if !generatedChanged && (overrideChanged || accepted[conflict.id()]) {
+					newBase.Accepted = append(newBase.Accepted, conflict.id())
+					continue
+				}
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].id() < conflicts[j].id() })
+	for _, conflict := range conflicts {
+		newBase.Conflicts = append(newBase.Conflicts, conflict.id())
+	}
+	sort.Strings(newBase.Accepted)
+	return conflicts, newBase
+}
+
+// MergeConfigOverride merges the rook-config-override config map of a cluster with the options generated by the
+// operator, and returns the conflicting options and whether they changed since the last merge. The base of the merge
+// is kept in the rook-config-override-base config map.
+func MergeConfigOverride(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) ([]OverrideConflict, bool, error) {
+	cm, err := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Get(k8sutil.ConfigOverrideName, metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, false, errors.Wrapf(err, "failed to get config map %q", k8sutil.ConfigOverrideName)
+	}
+	overrideConfig := ""
+	if err == nil {
+		overrideConfig = cm.Data[k8sutil.ConfigOverrideVal]
+	}
+	override, err := parseConfigOverride(overrideConfig)
+	if err != nil {
+		return nil, false, err
+	}
+
+	kv := k8sutil.NewConfigMapKVStore(clusterInfo.Namespace, context.Clientset, clusterInfo.OwnerRef)
+	var base *overrideBase
+	data, err := kv.GetValue(overrideBaseConfigMapName, overrideBaseKey)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, false, errors.Wrapf(err, "failed to get config map %q", overrideBaseConfigMapName)
+	}
+	if err == nil {
+		base = &overrideBase{}
+		if err := json.Unmarshal([]byte(data), base); err != nil {
+			logger.Warningf("failed to parse the base of the config override merge, starting a new one. %v", err)
+			base = nil
+		}
+	}
+	var baseGenerated map[string]string
+	var baseConflicts []string
+	if base != nil {
+		baseGenerated = base.Generated
+		baseConflicts = base.Conflicts
+	}
+
+	conflicts, newBase := mergeConfigOverride(override, currentGeneratedOptions(clusterInfo.Namespace, baseGenerated), base)
+	changed := !reflect.DeepEqual(baseConflicts, newBase.Conflicts)
+	newData, err := json.Marshal(newBase)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to marshal the base of the config override merge")
+	}
+	if err := kv.SetValue(overrideBaseConfigMapName, overrideBaseKey, string(newData)); err != nil {
+		return nil, false, errors.Wrap(err, "failed to save the base of the config override merge")
+	}
+	return conflicts, changed, nil
+}
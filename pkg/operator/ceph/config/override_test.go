@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseConfigOverride(t *testing.T) {
+	options, err := parseConfigOverride("")
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+
+	options, err = parseConfigOverride("[global]\nosd pool default size = 1\n[osd.0]\ndebug-osd = 20\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"global/osd_pool_default_size": "1", "osd.0/debug_osd": "20"}, options)
+}
+
+func TestSectionsOverlap(t *testing.T) {
+	assert.True(t, sectionsOverlap("global", "osd"))
+	assert.True(t, sectionsOverlap("osd", "global"))
+	assert.True(t, sectionsOverlap("osd", "osd.1"))
+	assert.True(t, sectionsOverlap("mds.a", "mds"))
+	assert.True(t, sectionsOverlap("client.rgw.a", "client.rgw.a"))
+	assert.False(t, sectionsOverlap("mon", "osd"))
+	assert.False(t, sectionsOverlap("osd.0", "osd.1"))
+}
+
+func TestMergeConfigOverride(t *testing.T) {
+	generated := map[string]string{"global/osd_pool_default_size": "3", "osd/osd_memory_target": "4096"}
+
+	// the options set on the command line are ignored
+	override := map[string]string{"global/log_to_stderr": "false", "global/err_to_stderr": "true"}
+	conflicts, base := mergeConfigOverride(override, generated, nil)
+	assert.Equal(t, []OverrideConflict{{Section: "global", Option: "log_to_stderr", Value: "false", Who: "global", GeneratedValue: "true", Ignored: true}}, conflicts)
+	assert.Empty(t, base.Accepted)
+
+	// without base, the overridden generated options conflict
+	override = map[string]string{"global/osd_pool_default_size": "1", "osd.0/osd_memory_target": "8192", "mon/osd_memory_target": "1024"}
+	conflicts, base = mergeConfigOverride(override, generated, nil)
+	assert.Equal(t, []OverrideConflict{
+		{Section: "global", Option: "osd_pool_default_size", Value: "1", Who: "global", GeneratedValue: "3"},
+		{Section: "osd.0", Option: "osd_memory_target", Value: "8192", Who: "osd", GeneratedValue: "4096"},
+	}, conflicts)
+	assert.Equal(t, []string{"global/osd_pool_default_size:global", "osd.0/osd_memory_target:osd"}, base.Conflicts)
+
+	// the conflicts stay until the override is deliberately changed
+	conflicts, base = mergeConfigOverride(override, generated, base)
+	assert.Len(t, conflicts, 2)
+	override["global/osd_pool_default_size"] = "2"
+	conflicts, base = mergeConfigOverride(override, generated, base)
+	assert.Equal(t, []string{"osd.0/osd_memory_target:osd"}, base.Conflicts)
+	assert.Equal(t, []string{"global/osd_pool_default_size:global"}, base.Accepted)
+	assert.Len(t, conflicts, 1)
+
+	// the accepted override stays accepted while the generated option does not change
+	conflicts, base = mergeConfigOverride(override, generated, base)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, []string{"global/osd_pool_default_size:global"}, base.Accepted)
+
+	// the accepted override conflicts again when the operator changes the generated option
+	generated = map[string]string{"global/osd_pool_default_size": "4", "osd/osd_memory_target": "4096"}
+	conflicts, base = mergeConfigOverride(override, generated, base)
+	assert.Len(t, conflicts, 2)
+	assert.Empty(t, base.Accepted)
+
+	// the override of the generated value does not conflict
+	override = map[string]string{"global/osd_pool_default_size": "4"}
+	conflicts, base = mergeConfigOverride(override, generated, base)
+	assert.Empty(t, conflicts)
+	assert.Empty(t, base.Conflicts)
+}
+
+func TestMergeConfigOverrideConfigMaps(t *testing.T) {
+	ForgetGeneratedOptions("ns")
+	defer ForgetGeneratedOptions("ns")
+	clientset := testop.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	clusterInfo := &client.ClusterInfo{Namespace: "ns"}
+
+	// no conflict without override
+	conflicts, changed, err := MergeConfigOverride(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.False(t, changed)
+
+	value := "3"
+	recordGeneratedOption("ns", "global", "osd pool default size", &value)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: k8sutil.ConfigOverrideName, Namespace: "ns"},
+		Data:       map[string]string{k8sutil.ConfigOverrideVal: "[global]\nosd_pool_default_size = 1\n"},
+	}
+	_, err = clientset.CoreV1().ConfigMaps("ns").Create(cm)
+	assert.NoError(t, err)
+	conflicts, changed, err = MergeConfigOverride(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+	assert.True(t, changed)
+
+	// the conflict is only reported as changed once
+	conflicts, changed, err = MergeConfigOverride(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+	assert.False(t, changed)
+
+	// the generated options are kept in the base after a restart of the operator
+	ForgetGeneratedOptions("ns")
+	conflicts, changed, err = MergeConfigOverride(context, clusterInfo)
+	assert.NoError(t, err)
+	assert.Len(t, conflicts, 1)
+	assert.False(t, changed)
+	_, err = clientset.CoreV1().ConfigMaps("ns").Get(overrideBaseConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
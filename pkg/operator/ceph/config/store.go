@@ -94,6 +94,7 @@ func (s *Store) createOrUpdateMonHostSecrets(clusterInfo *cephclient.ClusterInfo
 	}
 	clientset := s.context.Clientset
 	k8sutil.SetOwnerRef(&secret.ObjectMeta, s.ownerRef)
+	k8sutil.ApplyInheritedMetadata(s.namespace, &secret.ObjectMeta)
 
 	_, err := clientset.CoreV1().Secrets(s.namespace).Get(StoreName, metav1.GetOptions{})
 	if err != nil {
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// CacheLabelSelector restricts the objects of the kinds in narrowedCacheResources cached by the controllers to the
+// objects matching the selector, all the objects are cached if empty. The memory of the operator then does not
+// depend on the number of unrelated secrets, config maps and deployments in the watched namespaces.
+var CacheLabelSelector = ""
+
+// the resources listed and watched by the cache with the label selector, by api path prefix
+var narrowedCacheResources = map[string][]string{
+	"/api/v1":       {"secrets", "configmaps"},
+	"/apis/apps/v1": {"deployments"},
+}
+
+// NewCacheFunc returns the function creating the cache of the controller-runtime manager. The cache only lists and
+// watches the namespaced objects of the namespaces when several namespaces are given, and only the secrets, config
+// maps and deployments matching the selector when a selector is given. The objects not matching the selector are not
+// found in the cache, the controllers must read them with the clientset.
+func NewCacheFunc(selector string, namespaces []string) (cache.NewCacheFunc, error) {
+	newCache := cache.New
+	if len(namespaces) > 1 {
+		newCache = namespacesCacheBuilder(namespaces)
+	}
+	if selector == "" {
+		return newCache, nil
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, errors.Wrapf(err, "invalid cache label selector %q", selector)
+	}
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		// the config is copied since the manager creates its client with the same config
+		cacheConfig := rest.CopyConfig(config)
+		cacheConfig.WrapTransport = transport.Wrappers(config.WrapTransport, func(rt http.RoundTripper) http.RoundTripper {
+			return &selectorRoundTripper{selector: selector, delegate: rt}
+		})
+		return newCache(cacheConfig, opts)
+	}, nil
+}
+
+// selectorRoundTripper adds the label selector to the list and watch requests of the narrowed resources
+type selectorRoundTripper struct {
+	selector string
+	delegate http.RoundTripper
+}
+
+func (rt *selectorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || !isNarrowedCacheResource(req.URL.Path) {
+		return rt.delegate.RoundTrip(req)
+	}
+	// the request is cloned since a round tripper must not modify the request
+	narrowed := req.Clone(req.Context())
+	query := narrowed.URL.Query()
+	if existing := query.Get("labelSelector"); existing != "" {
+		query.Set("labelSelector", existing+","+rt.selector)
+	} else {
+		query.Set("labelSelector", rt.selector)
+	}
+	narrowed.URL.RawQuery = query.Encode()
+	return rt.delegate.RoundTrip(narrowed)
+}
+
+// isNarrowedCacheResource returns whether the path is the path of a collection of a narrowed resource, in all the
+// namespaces or in a namespace
+func isNarrowedCacheResource(path string) bool {
+	for prefix, resources := range narrowedCacheResources {
+		if !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(path, prefix+"/"), "/")
+		if len(segments) == 3 && segments[0] == "namespaces" {
+			segments = segments[2:]
+		}
+		if len(segments) != 1 {
+			continue
+		}
+		for _, resource := range resources {
+			if segments[0] == resource {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingRoundTripper struct {
+	requests []*http.Request
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestSelectorRoundTripper(t *testing.T) {
+	recorder := &recordingRoundTripper{}
+	rt := &selectorRoundTripper{selector: "app", delegate: recorder}
+	selector := func(method, url string) string {
+		req := httptest.NewRequest(method, url, nil)
+		_, err := rt.RoundTrip(req)
+		assert.NoError(t, err)
+		return recorder.requests[len(recorder.requests)-1].URL.Query().Get("labelSelector")
+	}
+
+	// the lists and watches of the narrowed resources have the selector
+	assert.Equal(t, "app", selector(http.MethodGet, "https://k8s/api/v1/secrets?watch=true"))
+	assert.Equal(t, "app", selector(http.MethodGet, "https://k8s/api/v1/namespaces/rook-ceph/configmaps"))
+	assert.Equal(t, "app", selector(http.MethodGet, "https://k8s/apis/apps/v1/namespaces/rook-ceph/deployments"))
+	assert.Equal(t, "rook_cluster=rook-ceph,app", selector(http.MethodGet, "https://k8s/api/v1/secrets?labelSelector=rook_cluster%3Drook-ceph"))
+
+	// the other requests are not changed
+	assert.Equal(t, "", selector(http.MethodGet, "https://k8s/api/v1/namespaces/rook-ceph/secrets/rook-ceph-mon"))
+	assert.Equal(t, "", selector(http.MethodGet, "https://k8s/api/v1/namespaces/rook-ceph/pods"))
+	assert.Equal(t, "", selector(http.MethodGet, "https://k8s/apis/apps/v1/namespaces/rook-ceph/daemonsets"))
+	assert.Equal(t, "", selector(http.MethodPost, "https://k8s/api/v1/namespaces/rook-ceph/secrets"))
+
+	// the request of the caller is not modified
+	req := httptest.NewRequest(http.MethodGet, "https://k8s/api/v1/secrets", nil)
+	_, err := rt.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "", req.URL.RawQuery)
+}
+
+func TestNewCacheFunc(t *testing.T) {
+	newCache, err := NewCacheFunc("", nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, newCache)
+
+	newCache, err = NewCacheFunc("app in (rook-ceph-mon, rook-ceph-osd)", []string{"ns-a", "ns-b"})
+	assert.NoError(t, err)
+	assert.NotNil(t, newCache)
+
+	_, err = NewCacheFunc("app in (", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	// MaxConcurrentReconciles is the number of CRs each controller reconciles in parallel. The CRs of a kind are
+	// reconciled one at a time by default, a slow reconcile of a cluster delaying the reconciles of the other clusters.
+	MaxConcurrentReconciles = 1
+
+	// ControllerMaxConcurrentReconciles overrides MaxConcurrentReconciles for the controllers, by controller name
+	ControllerMaxConcurrentReconciles = map[string]int{}
+)
+
+// ControllerOptions returns the options of a controller with the number of its parallel reconciles. The same CR is
+// never reconciled by two workers at once, the reconciles in parallel are the reconciles of different CRs.
+func ControllerOptions(controllerName string, r reconcile.Reconciler) controller.Options {
+	return controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: maxConcurrentReconciles(controllerName),
+	}
+}
+
+func maxConcurrentReconciles(controllerName string) int {
+	if count, ok := ControllerMaxConcurrentReconciles[controllerName]; ok && count > 0 {
+		return count
+	}
+	if MaxConcurrentReconciles > 0 {
+		return MaxConcurrentReconciles
+	}
+	return 1
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxConcurrentReconciles(t *testing.T) {
+	defer func(count int, counts map[string]int) {
+		MaxConcurrentReconciles = count
+		ControllerMaxConcurrentReconciles = counts
+	}(MaxConcurrentReconciles, ControllerMaxConcurrentReconciles)
+
+	// one reconcile at a time by default
+	assert.Equal(t, 1, ControllerOptions("ceph-cluster-controller", nil).MaxConcurrentReconciles)
+
+	// the default of all the controllers and the override of a controller
+	MaxConcurrentReconciles = 3
+	ControllerMaxConcurrentReconciles = map[string]int{"ceph-block-pool-controller": 5, "ceph-nfs-controller": 0}
+	assert.Equal(t, 3, ControllerOptions("ceph-cluster-controller", nil).MaxConcurrentReconciles)
+	assert.Equal(t, 5, ControllerOptions("ceph-block-pool-controller", nil).MaxConcurrentReconciles)
+	assert.Equal(t, 3, ControllerOptions("ceph-nfs-controller", nil).MaxConcurrentReconciles)
+
+	// invalid counts
+	MaxConcurrentReconciles = 0
+	assert.Equal(t, 1, ControllerOptions("ceph-cluster-controller", nil).MaxConcurrentReconciles)
+}
@@ -44,6 +44,9 @@ var (
 	// WaitForRequeueIfFinalizerBlocked waits for resources to be cleaned up before the finalizer can be removed
 	WaitForRequeueIfFinalizerBlocked = reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}
 
+	// WaitForRequeueIfRestartInProgress waits before restarting the next daemon of a rolling restart
+	WaitForRequeueIfRestartInProgress = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+
 	// OperatorCephBaseImageVersion is the ceph version in the operator image
 	OperatorCephBaseImageVersion string
 )
@@ -66,7 +69,11 @@ func IsReadyToReconcile(c client.Client, clustercontext *clusterd.Context, names
 		return cephCluster, false, cephClusterExists, WaitForRequeueIfCephClusterNotReady
 	}
 	cephClusterExists = true
-	cephCluster = clusterList.Items[0]
+	cephCluster = *NamespaceCephCluster(clusterList.Items)
+	// the child controllers may reconcile before the cluster controller after a restart of the operator
+	if err := k8sutil.SetInheritedMetadata(cephCluster.Namespace, cephCluster.Spec.InheritedMetadata.Labels, cephCluster.Spec.InheritedMetadata.Annotations); err != nil {
+		logger.Warningf("%q: %v", controllerName, err)
+	}
 
 	logger.Debugf("%q: CephCluster resource %q found in namespace %q", controllerName, cephCluster.Name, namespacedName.Namespace)
 
@@ -76,12 +83,27 @@ func IsReadyToReconcile(c client.Client, clustercontext *clusterd.Context, names
 			logger.Debugf("%q: ceph status is %q, operator is ready to run ceph command, reconciling", controllerName, cephCluster.Status.CephStatus.Health)
 			return cephCluster, true, cephClusterExists, WaitForRequeueIfCephClusterNotReady
 		}
-		logger.Infof("%s: CephCluster %q found but skipping reconcile since ceph health is %q", controllerName, cephCluster.Name, cephCluster.Status.CephStatus)
+		logger.Infof("%s: CephCluster %q found but skipping reconcile since ceph health is %q", controllerName, cephCluster.Name, cephCluster.Status.CephStatus.Health)
 	}
 
 	return cephCluster, false, cephClusterExists, WaitForRequeueIfCephClusterNotReady
 }
 
+// NamespaceCephCluster returns the CephCluster which owns the namespace, or nil if there is no cluster. Only one
+// CephCluster is supported in a namespace since the resources of the clusters would have the same names, the oldest
+// cluster owns the namespace and the others are rejected by the cluster controller.
+func NamespaceCephCluster(clusters []cephv1.CephCluster) *cephv1.CephCluster {
+	var owner *cephv1.CephCluster
+	for i := range clusters {
+		cluster := &clusters[i]
+		if owner == nil || cluster.CreationTimestamp.Before(&owner.CreationTimestamp) ||
+			(cluster.CreationTimestamp.Equal(&owner.CreationTimestamp) && cluster.Name < owner.Name) {
+			owner = cluster
+		}
+	}
+	return owner
+}
+
 // ClusterOwnerRef represents the owner reference of the CephCluster CR
 func ClusterOwnerRef(clusterName, clusterID string) metav1.OwnerReference {
 	blockOwner := true
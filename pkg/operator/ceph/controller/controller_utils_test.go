@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceCephCluster(t *testing.T) {
+	assert.Nil(t, NamespaceCephCluster([]cephv1.CephCluster{}))
+
+	now := time.Now()
+	clusters := []cephv1.CephCluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", CreationTimestamp: metav1.NewTime(now)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", CreationTimestamp: metav1.NewTime(now)}},
+	}
+	// the oldest cluster owns the namespace
+	assert.Equal(t, "c", NamespaceCephCluster(clusters).Name)
+
+	// the name breaks the ties
+	clusters[1].CreationTimestamp = metav1.NewTime(now)
+	assert.Equal(t, "a", NamespaceCephCluster(clusters).Name)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DeprecatedField is a deprecated field set in the spec of a CR
+type DeprecatedField struct {
+	// Path is the path of the field in the CR, for example "spec.network.hostNetwork"
+	Path string
+	// Replacement is the setting replacing the field
+	Replacement string
+}
+
+func (f DeprecatedField) String() string {
+	return fmt.Sprintf("%s is deprecated, use %s instead", f.Path, f.Replacement)
+}
+
+var (
+	deprecatedFieldUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_deprecated_field_usage",
+		Help: "Deprecated fields set in the spec of the Rook CRs, 1 for each CR and field",
+	}, []string{"kind", "namespace", "name", "field"})
+
+	// reportedFields are the deprecated fields last reported for each CR
+	reportedFields      = map[string][]string{}
+	reportedFieldsMutex sync.Mutex
+)
+
+func init() {
+	// the operator metrics are served by the metrics endpoint of the controller-runtime manager
+	metrics.Registry.MustRegister(deprecatedFieldUsage)
+}
+
+// ReportDeprecatedFields exports the metrics of the deprecated fields of a CR, replacing the fields previously
+// reported for the CR. It returns whether the fields changed since the last report.
+func ReportDeprecatedFields(kind, namespace, name string, fields []DeprecatedField) bool {
+	paths := []string{}
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	sort.Strings(paths)
+
+	reportedFieldsMutex.Lock()
+	defer reportedFieldsMutex.Unlock()
+	key := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+	previous, reported := reportedFields[key]
+	for _, path := range previous {
+		deprecatedFieldUsage.DeleteLabelValues(kind, namespace, name, path)
+	}
+	for _, path := range paths {
+		deprecatedFieldUsage.WithLabelValues(kind, namespace, name, path).Set(1)
+	}
+	if len(paths) == 0 {
+		delete(reportedFields, key)
+	} else {
+		reportedFields[key] = paths
+	}
+	return !reported && len(paths) > 0 || reported && strings.Join(previous, ",") != strings.Join(paths, ",")
+}
+
+// ClearDeprecatedFields removes the metrics of the deprecated fields of a deleted CR
+func ClearDeprecatedFields(kind, namespace, name string) {
+	ReportDeprecatedFields(kind, namespace, name, nil)
+}
+
+// DeprecatedFieldsMessage returns the message listing the deprecated fields of a CR
+func DeprecatedFieldsMessage(fields []DeprecatedField) string {
+	messages := []string{}
+	for _, f := range fields {
+		messages = append(messages, f.String())
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
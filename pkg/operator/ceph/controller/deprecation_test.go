@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportDeprecatedFields(t *testing.T) {
+	hostNetwork := DeprecatedField{Path: "spec.network.hostNetwork", Replacement: `spec.network.provider "host"`}
+	directories := DeprecatedField{Path: "spec.storage.directories", Replacement: "spec.storage.devices"}
+
+	assert.False(t, ReportDeprecatedFields("CephCluster", "ns", "rook-ceph", nil))
+	assert.True(t, ReportDeprecatedFields("CephCluster", "ns", "rook-ceph", []DeprecatedField{hostNetwork, directories}))
+	assert.Equal(t, 1.0, testutil.ToFloat64(deprecatedFieldUsage.WithLabelValues("CephCluster", "ns", "rook-ceph", hostNetwork.Path)))
+	assert.Equal(t, 2, testutil.CollectAndCount(deprecatedFieldUsage))
+
+	// the same fields in another order are not a change
+	assert.False(t, ReportDeprecatedFields("CephCluster", "ns", "rook-ceph", []DeprecatedField{directories, hostNetwork}))
+
+	// the fields which are not set anymore are removed
+	assert.True(t, ReportDeprecatedFields("CephCluster", "ns", "rook-ceph", []DeprecatedField{directories}))
+	assert.Equal(t, 1, testutil.CollectAndCount(deprecatedFieldUsage))
+	ClearDeprecatedFields("CephCluster", "ns", "rook-ceph")
+	assert.Equal(t, 0, testutil.CollectAndCount(deprecatedFieldUsage))
+
+	assert.Equal(t, `spec.network.hostNetwork is deprecated, use spec.network.provider "host" instead; spec.storage.directories is deprecated, use spec.storage.devices instead`,
+		DeprecatedFieldsMessage([]DeprecatedField{directories, hostNetwork}))
+}
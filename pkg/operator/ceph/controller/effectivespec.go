@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EffectiveSpecAnnotation is the annotation of the CRs with the spec applied by the operator, once the defaults of
+// the operator and of the cluster are set, as normalized JSON. GitOps tools can compare it with their manifests.
+const EffectiveSpecAnnotation = "ceph.rook.io/effective-spec"
+
+// ExportEffectiveSpecs enables the EffectiveSpecAnnotation of the CRs reconciled successfully
+var ExportEffectiveSpecs = false
+
+// EffectiveSpec returns the normalized JSON of a spec: the keys are sorted and the zero values are removed since they
+// are not distinguished from the unset settings, so that the rendering only changes when the applied settings change
+func EffectiveSpec(spec interface{}) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal spec")
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal spec")
+	}
+	// the maps are marshaled with the keys sorted
+	data, err = json.Marshal(withoutEmptyValues(value))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal normalized spec")
+	}
+	return string(data), nil
+}
+
+// ExportEffectiveSpec sets the EffectiveSpecAnnotation of the CR to the effective spec when it changed. The CR is
+// patched so that the update does not conflict with the changes of the CR, and is not modified. Failures are only
+// logged since they must not fail the reconcile.
+func ExportEffectiveSpec(c client.Client, obj runtime.Object, spec interface{}) {
+	if !ExportEffectiveSpecs {
+		return
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		logger.Errorf("failed to get meta information of object. %v", err)
+		return
+	}
+	effectiveSpec, err := EffectiveSpec(spec)
+	if err != nil {
+		logger.Errorf("failed to render the effective spec of %q. %v", accessor.GetName(), err)
+		return
+	}
+	if accessor.GetAnnotations()[EffectiveSpecAnnotation] == effectiveSpec {
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{EffectiveSpecAnnotation: effectiveSpec},
+		},
+	})
+	if err != nil {
+		logger.Errorf("failed to marshal the effective spec patch of %q. %v", accessor.GetName(), err)
+		return
+	}
+	if err := c.Patch(context.TODO(), obj.DeepCopyObject(), client.RawPatch(types.MergePatchType, patch)); err != nil {
+		logger.Warningf("failed to export the effective spec of %q. %v", accessor.GetName(), err)
+		return
+	}
+	logger.Debugf("effective spec of %q exported", accessor.GetName())
+}
+
+// withoutEmptyValues removes the zero values and the empty maps and lists, recursively
+func withoutEmptyValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, element := range v {
+			if element = withoutEmptyValues(element); !isEmptyValue(element) {
+				result[key] = element
+			}
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for _, element := range v {
+			// the elements are kept to keep the indexes of the lists
+			result = append(result, withoutEmptyValues(element))
+		}
+		return result
+	}
+	return value
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
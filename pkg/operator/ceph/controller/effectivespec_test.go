@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEffectiveSpec(t *testing.T) {
+	spec := cephv1.PoolSpec{
+		FailureDomain: "host",
+		Replicated:    cephv1.ReplicatedSpec{Size: 3},
+		Parameters:    map[string]string{"target_size_ratio": "0.5", "compression_mode": "none"},
+	}
+	effectiveSpec, err := EffectiveSpec(spec)
+	assert.NoError(t, err)
+	// the keys are sorted and the zero values removed
+	assert.Equal(t, `{"failureDomain":"host","parameters":{"compression_mode":"none","target_size_ratio":"0.5"},"replicated":{"size":3}}`, effectiveSpec)
+
+	// the rendering only depends on the settings
+	other, err := EffectiveSpec(&spec)
+	assert.NoError(t, err)
+	assert.Equal(t, effectiveSpec, other)
+
+	assert.Equal(t, map[string]interface{}{"list": []interface{}{map[string]interface{}{}, "a"}},
+		withoutEmptyValues(map[string]interface{}{"list": []interface{}{map[string]interface{}{"empty": nil}, "a"}, "empty": []interface{}{}, "zero": 0.0, "unset": false}))
+}
+
+func TestExportEffectiveSpec(t *testing.T) {
+	pool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "replicapool",
+			Namespace:   "rook-ceph",
+			Annotations: map[string]string{"user": "value"},
+		},
+		Spec: cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 3}},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, pool)
+	cl := fake.NewFakeClientWithScheme(s, pool)
+	name := types.NamespacedName{Name: "replicapool", Namespace: "rook-ceph"}
+	defer func() { ExportEffectiveSpecs = false }()
+
+	// the annotation is not set by default
+	ExportEffectiveSpec(cl, pool, pool.Spec)
+	result := &cephv1.CephBlockPool{}
+	assert.NoError(t, cl.Get(context.TODO(), name, result))
+	assert.NotContains(t, result.Annotations, EffectiveSpecAnnotation)
+
+	ExportEffectiveSpecs = true
+	effectiveSpec := pool.Spec
+	effectiveSpec.FailureDomain = "host"
+	ExportEffectiveSpec(cl, pool, effectiveSpec)
+	assert.NoError(t, cl.Get(context.TODO(), name, result))
+	assert.Equal(t, `{"failureDomain":"host","replicated":{"size":3}}`, result.Annotations[EffectiveSpecAnnotation])
+	assert.Equal(t, "value", result.Annotations["user"])
+	// the CR is not modified
+	assert.NotContains(t, pool.Annotations, EffectiveSpecAnnotation)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// The reasons of the events emitted on the CRs
+const (
+	// EventReasonReconcileSucceeded is the reason of the event of a reconcile that completed
+	EventReasonReconcileSucceeded = "ReconcileSucceeded"
+	// EventReasonReconcileFailed is the reason of the event of a reconcile that returned an error
+	EventReasonReconcileFailed = "ReconcileFailed"
+	// EventReasonUpgradeStarted is the reason of the event of the start of an upgrade of Ceph
+	EventReasonUpgradeStarted = "UpgradeStarted"
+	// EventReasonUpgradeCompleted is the reason of the event of the completion of an upgrade of Ceph
+	EventReasonUpgradeCompleted = "UpgradeCompleted"
+	// EventReasonUpgradeFailed is the reason of the event of an upgrade of Ceph that could not be started or completed
+	EventReasonUpgradeFailed = "UpgradeFailed"
+	// EventReasonUpgradeCancelled is the reason of the event of an upgrade of Ceph stopped by a change of the image
+	EventReasonUpgradeCancelled = "UpgradeCancelled"
+	// EventReasonOSDDown is the reason of the event of an OSD marked down
+	EventReasonOSDDown = "OSDDown"
+	// EventReasonOSDRemoved is the reason of the event of the removal of an OSD that was out and safe to destroy
+	EventReasonOSDRemoved = "OSDRemoved"
+	// EventReasonOSDMarkedOut is the reason of the event of an OSD marked out by the operator after it stayed down
+	EventReasonOSDMarkedOut = "OSDMarkedOut"
+	// EventReasonOSDMarkedIn is the reason of the event of an OSD marked in again by the operator once it is back up
+	EventReasonOSDMarkedIn = "OSDMarkedIn"
+	// EventReasonHealthDegraded is the reason of the event of the Ceph health leaving HEALTH_OK or getting worse
+	EventReasonHealthDegraded = "HealthDegraded"
+	// EventReasonHealthRecovered is the reason of the event of the Ceph health back to HEALTH_OK
+	EventReasonHealthRecovered = "HealthRecovered"
+	// EventReasonDaemonCrashed is the reason of the event of a new crash report of a daemon
+	EventReasonDaemonCrashed = "DaemonCrashed"
+	// EventReasonMgrModuleFailed is the reason of the event of a mgr module which failed or whose dependencies are missing
+	EventReasonMgrModuleFailed = "MgrModuleFailed"
+	// EventReasonPoolQuotaNearFull is the reason of the event of the usage of a quota of a pool crossing its warning
+	// threshold
+	EventReasonPoolQuotaNearFull = "PoolQuotaNearFull"
+	// EventReasonNewDevicesAdded is the reason of the event of the provisioning of OSDs on new devices of a node
+	EventReasonNewDevicesAdded = "NewDevicesAdded"
+	// EventReasonConfigOverrideConflict is the reason of the events of the options of the config override conflicting
+	// with the options generated by the operator
+	EventReasonConfigOverrideConflict = "ConfigOverrideConflict"
+	// EventReasonMonQuorumRestored is the reason of the event of the restore of the mon quorum from a surviving mon
+	EventReasonMonQuorumRestored = "MonQuorumRestored"
+	// EventReasonMonQuorumRestoreRefused is the reason of the event of a restore of the mon quorum which was refused
+	EventReasonMonQuorumRestoreRefused = "MonQuorumRestoreRefused"
+	// EventReasonDaemonsRestarted is the reason of the event of the completion of a rolling restart of the daemons
+	EventReasonDaemonsRestarted = "DaemonsRestarted"
+	// EventReasonOSDsReweighted is the reason of the event of the weights of the OSDs lowered by their utilization
+	EventReasonOSDsReweighted = "OSDsReweighted"
+	// EventReasonProgressStarted is the reason of the events of the long-running operations of ceph starting
+	EventReasonProgressStarted = "ProgressStarted"
+	// EventReasonProgressCompleted is the reason of the events of the long-running operations of ceph completing
+	EventReasonProgressCompleted = "ProgressCompleted"
+	// EventReasonProgressFailed is the reason of the events of the long-running operations of ceph failing
+	EventReasonProgressFailed = "ProgressFailed"
+	// EventReasonCrushMovePending is the reason of the event of a new CRUSH location of a node waiting for confirmation
+	EventReasonCrushMovePending = "CrushMovePending"
+	// EventReasonCrushMoved is the reason of the event of the move of the OSDs of a node to a confirmed CRUSH location
+	EventReasonCrushMoved = "CrushMoved"
+
+	// the message of an event is truncated like the error of a reconcile outcome
+	maxEventMessageLength = maxReconcileErrorLength
+)
+
+// ReportReconcileResult emits an event on the CR with the outcome of a reconcile, the requeued reconciles are not
+// reported. The obj is an empty object of the CR type, no event is emitted if the CR was deleted.
+func ReportReconcileResult(c client.Client, recorder record.EventRecorder, obj runtime.Object, name types.NamespacedName, result reconcile.Result, reconcileErr error) {
+	if recorder == nil {
+		return
+	}
+	if reconcileErr == nil && (result.Requeue || result.RequeueAfter > 0) {
+		return
+	}
+	if err := c.Get(context.TODO(), name, obj); err != nil {
+		logger.Debugf("failed to get %q to report its reconcile result. %v", name.String(), err)
+		return
+	}
+
+	if reconcileErr != nil {
+		recorder.Event(obj, corev1.EventTypeWarning, EventReasonReconcileFailed, truncateEventMessage(reconcileErr.Error()))
+		return
+	}
+	recorder.Event(obj, corev1.EventTypeNormal, EventReasonReconcileSucceeded, "successfully reconciled")
+}
+
+// RecordOwnerEvent emits an event on the CR of the owner reference. It is used by the goroutines which only know the
+// owner reference of the cluster, like the health checkers.
+func RecordOwnerEvent(recorder record.EventRecorder, namespace string, ownerRef metav1.OwnerReference, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil || ownerRef.Name == "" {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		APIVersion: ownerRef.APIVersion,
+		Kind:       ownerRef.Kind,
+		Name:       ownerRef.Name,
+		Namespace:  namespace,
+		UID:        ownerRef.UID,
+	}
+	recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
+func truncateEventMessage(message string) string {
+	if len(message) > maxEventMessageLength {
+		return message[:maxEventMessageLength] + "..."
+	}
+	return message
+}
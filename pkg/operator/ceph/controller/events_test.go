@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReportReconcileResult(t *testing.T) {
+	pool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: "rook-ceph",
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, pool)
+	cl := fake.NewFakeClientWithScheme(s, pool)
+	name := types.NamespacedName{Name: "replicapool", Namespace: "rook-ceph"}
+	recorder := record.NewFakeRecorder(10)
+
+	ReportReconcileResult(cl, recorder, &cephv1.CephBlockPool{}, name, reconcile.Result{}, nil)
+	assert.Equal(t, "Normal ReconcileSucceeded successfully reconciled", <-recorder.Events)
+
+	// the error is truncated
+	ReportReconcileResult(cl, recorder, &cephv1.CephBlockPool{}, name, reconcile.Result{}, errors.New(strings.Repeat("x", 1000)))
+	event := <-recorder.Events
+	assert.True(t, strings.HasPrefix(event, "Warning ReconcileFailed xxx"))
+	assert.Equal(t, len("Warning ReconcileFailed ")+maxEventMessageLength+3, len(event))
+
+	// no event for the requeued reconciles, the deleted CRs or without a recorder
+	ReportReconcileResult(cl, recorder, &cephv1.CephBlockPool{}, name, reconcile.Result{Requeue: true}, nil)
+	ReportReconcileResult(cl, recorder, &cephv1.CephBlockPool{}, types.NamespacedName{Name: "deleted", Namespace: "rook-ceph"}, reconcile.Result{}, nil)
+	ReportReconcileResult(cl, nil, &cephv1.CephBlockPool{}, name, reconcile.Result{}, nil)
+	assert.Equal(t, 0, len(recorder.Events))
+}
+
+func TestRecordOwnerEvent(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	ownerRef := metav1.OwnerReference{APIVersion: "ceph.rook.io/v1", Kind: "CephCluster", Name: "rook-ceph", UID: "uid"}
+
+	RecordOwnerEvent(recorder, "rook-ceph", ownerRef, corev1.EventTypeWarning, EventReasonOSDDown, "osd.%d is down", 3)
+	assert.Equal(t, "Warning OSDDown osd.3 is down", <-recorder.Events)
+
+	// no event without the owner
+	RecordOwnerEvent(recorder, "rook-ceph", metav1.OwnerReference{}, corev1.EventTypeWarning, EventReasonOSDDown, "osd.%d is down", 3)
+	RecordOwnerEvent(nil, "rook-ceph", ownerRef, corev1.EventTypeWarning, EventReasonOSDDown, "osd.%d is down", 3)
+	assert.Equal(t, 0, len(recorder.Events))
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ReconcileHistoryConfigMapName is the name of the config map holding the last reconcile outcomes
+	// of the CRs in a namespace. Each CR has a key "<kind>.<name>" with the outcomes as a json list.
+	ReconcileHistoryConfigMapName = "rook-ceph-reconcile-history"
+	// ReconcileHistorySize is the number of outcomes kept per CR
+	ReconcileHistorySize = 10
+	// the error of an outcome is truncated to keep the history compact
+	maxReconcileErrorLength = 256
+
+	// ReconcileSucceeded is the result of a reconcile that completed
+	ReconcileSucceeded = "Succeeded"
+	// ReconcileRequeued is the result of a reconcile that completed but will run again
+	ReconcileRequeued = "Requeued"
+	// ReconcileFailed is the result of a reconcile that returned an error
+	ReconcileFailed = "Failed"
+)
+
+// all the controllers run in the operator process and share the history config map
+var reconcileHistoryMutex sync.Mutex
+
+// ReconcileOutcome is the outcome of a single reconcile of a CR
+type ReconcileOutcome struct {
+	Time     metav1.Time `json:"time"`
+	Duration string      `json:"duration"`
+	Result   string      `json:"result"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// RecordReconcileOutcome adds the outcome of a reconcile to the history of the CR. The obj is an empty
+// object of the CR type used to check whether the CR still exists, the history of deleted CRs is removed.
+// Failures to record the history are only logged since they must not fail the reconcile.
+func RecordReconcileOutcome(c client.Client, obj runtime.Object, name types.NamespacedName, start time.Time, result reconcile.Result, reconcileErr error) {
+	duration := time.Since(start)
+	outcome := ReconcileOutcome{
+		Time:     metav1.NewTime(start),
+		Duration: duration.Round(time.Millisecond).String(),
+		Result:   ReconcileSucceeded,
+	}
+	if reconcileErr != nil {
+		outcome.Result = ReconcileFailed
+		outcome.Error = reconcileErr.Error()
+		if len(outcome.Error) > maxReconcileErrorLength {
+			outcome.Error = outcome.Error[:maxReconcileErrorLength] + "..."
+		}
+	} else if result.Requeue || result.RequeueAfter > 0 {
+		outcome.Result = ReconcileRequeued
+	}
+
+	key := reconcileHistoryKey(obj, name.Name)
+	exists := true
+	if err := c.Get(context.TODO(), name, obj); err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Debugf("failed to get %q to record its reconcile history. %v", key, err)
+			return
+		}
+		exists = false
+	}
+	if exists {
+		recordReconcileMetrics(obj, name, duration, outcome.Result)
+	} else {
+		deleteReconcileMetrics(obj, name)
+	}
+
+	reconcileHistoryMutex.Lock()
+	defer reconcileHistoryMutex.Unlock()
+	if err := updateReconcileHistory(c, name.Namespace, key, outcome, exists); err != nil {
+		logger.Debugf("failed to record reconcile history of %q. %v", key, err)
+	}
+}
+
+// GetReconcileHistory returns the last reconcile outcomes of the CR, the most recent last
+func GetReconcileHistory(c client.Client, obj runtime.Object, name types.NamespacedName) ([]ReconcileOutcome, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: name.Namespace, Name: ReconcileHistoryConfigMapName}, cm)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return []ReconcileOutcome{}, nil
+		}
+		return nil, errors.Wrap(err, "failed to get reconcile history config map")
+	}
+	return decodeReconcileHistory(cm.Data[reconcileHistoryKey(obj, name.Name)])
+}
+
+func reconcileHistoryKey(obj runtime.Object, name string) string {
+	kind := reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	return fmt.Sprintf("%s.%s", strings.ToLower(kind), name)
+}
+
+func decodeReconcileHistory(data string) ([]ReconcileOutcome, error) {
+	history := []ReconcileOutcome{}
+	if data == "" {
+		return history, nil
+	}
+	if err := json.Unmarshal([]byte(data), &history); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal reconcile history")
+	}
+	return history, nil
+}
+
+func updateReconcileHistory(c client.Client, namespace, key string, outcome ReconcileOutcome, exists bool) error {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ReconcileHistoryConfigMapName}, cm)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get reconcile history config map")
+		}
+		if !exists {
+			return nil
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ReconcileHistoryConfigMapName,
+				Namespace: namespace,
+				// the label matches the cache label selectors on the app label
+				Labels: map[string]string{k8sutil.AppAttr: ReconcileHistoryConfigMapName},
+			},
+		}
+		if err := addReconcileOutcome(cm, key, outcome); err != nil {
+			return err
+		}
+		err := c.Create(context.TODO(), cm)
+		if kerrors.IsAlreadyExists(err) {
+			// the config map created before the label is not in the cache narrowed by a label selector
+			return labelReconcileHistory(c, cm)
+		}
+		return err
+	}
+
+	if !exists {
+		if _, ok := cm.Data[key]; !ok {
+			return nil
+		}
+		delete(cm.Data, key)
+	} else if err := addReconcileOutcome(cm, key, outcome); err != nil {
+		return err
+	}
+	return c.Update(context.TODO(), cm)
+}
+
+// labelReconcileHistory adds the app label to an existing history config map so that it is cached, the outcome is
+// recorded by the next reconcile
+func labelReconcileHistory(c client.Client, cm *corev1.ConfigMap) error {
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, k8sutil.AppAttr, ReconcileHistoryConfigMapName)
+	if err := c.Patch(context.TODO(), cm, client.RawPatch(types.MergePatchType, []byte(patch))); err != nil {
+		return errors.Wrap(err, "failed to label reconcile history config map")
+	}
+	return nil
+}
+
+// addReconcileOutcome appends the outcome to the history of the key, keeping only the last outcomes
+func addReconcileOutcome(cm *corev1.ConfigMap, key string, outcome ReconcileOutcome) error {
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	history, err := decodeReconcileHistory(cm.Data[key])
+	if err != nil {
+		// start a new history rather than failing forever on a corrupted entry
+		logger.Warningf("resetting reconcile history of %q. %v", key, err)
+		history = []ReconcileOutcome{}
+	}
+
+	history = append(history, outcome)
+	if len(history) > ReconcileHistorySize {
+		history = history[len(history)-ReconcileHistorySize:]
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal reconcile history")
+	}
+	cm.Data[key] = string(data)
+	return nil
+}
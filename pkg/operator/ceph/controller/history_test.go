@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRecordReconcileOutcome(t *testing.T) {
+	pool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: "rook-ceph",
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, pool)
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.ConfigMap{})
+	cl := fake.NewFakeClientWithScheme(s, pool)
+	name := types.NamespacedName{Name: "replicapool", Namespace: "rook-ceph"}
+
+	// the config map is created with the first outcome
+	RecordReconcileOutcome(cl, &cephv1.CephBlockPool{}, name, time.Now(), reconcile.Result{}, nil)
+	history, err := GetReconcileHistory(cl, &cephv1.CephBlockPool{}, name)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, ReconcileSucceeded, history[0].Result)
+	assert.Equal(t, "", history[0].Error)
+	RecordReconcileOutcome(cl, &cephv1.CephBlockPool{}, name, time.Now(), reconcile.Result{Requeue: true}, nil)
+	RecordReconcileOutcome(cl, &cephv1.CephBlockPool{}, name, time.Now(), reconcile.Result{}, errors.New(strings.Repeat("x", 1000)))
+	history, err = GetReconcileHistory(cl, &cephv1.CephBlockPool{}, name)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, ReconcileRequeued, history[1].Result)
+	assert.Equal(t, ReconcileFailed, history[2].Result)
+	assert.Equal(t, maxReconcileErrorLength+3, len(history[2].Error))
+
+	// only the last outcomes are kept
+	for i := 0; i < ReconcileHistorySize; i++ {
+		RecordReconcileOutcome(cl, &cephv1.CephBlockPool{}, name, time.Now(), reconcile.Result{}, nil)
+	}
+	history, err = GetReconcileHistory(cl, &cephv1.CephBlockPool{}, name)
+	assert.NoError(t, err)
+	assert.Equal(t, ReconcileHistorySize, len(history))
+	assert.Equal(t, ReconcileSucceeded, history[0].Result)
+
+	cm := &corev1.ConfigMap{}
+	err = cl.Get(context.TODO(), types.NamespacedName{Name: ReconcileHistoryConfigMapName, Namespace: "rook-ceph"}, cm)
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Data, "cephblockpool.replicapool")
+	assert.Equal(t, ReconcileHistoryConfigMapName, cm.Labels["app"])
+
+	// the history is removed with the cr
+	assert.NoError(t, cl.Delete(context.TODO(), pool))
+	RecordReconcileOutcome(cl, &cephv1.CephBlockPool{}, name, time.Now(), reconcile.Result{}, nil)
+	history, err = GetReconcileHistory(cl, &cephv1.CephBlockPool{}, name)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(history))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	logCollectorContainerName = "log-collector"
+	logShipperContainerName   = "log-shipper"
+	defaultLogShipperImage    = "fluent/fluent-bit:1.5"
+	defaultLogPeriodicity     = "daily"
+	defaultLogMaxSize         = "500M"
+	defaultLogRotateCount     = 7
+)
+
+var logPeriodicities = map[string]bool{"hourly": true, "daily": true, "weekly": true, "monthly": true}
+
+// the log file is copied and truncated since the daemon keeps it open, the state of the rotation is kept in the log
+// dir of the host to survive the restarts of the pod
+var logRotateScript = `
+set -o errexit
+cat > /tmp/logrotate.conf <<CONF
+$ROOK_LOG_FILE {
+  $ROOK_LOG_PERIODICITY
+  rotate $ROOK_LOG_ROTATE_COUNT
+  maxsize $ROOK_LOG_MAX_SIZE
+  compress
+  missingok
+  notifempty
+  copytruncate
+}
+CONF
+while true; do
+  logrotate --state "$ROOK_LOG_STATE_FILE" /tmp/logrotate.conf
+  sleep 15m
+done
+`
+
+// ApplyLogCollector makes the daemon of a pod, its first container, log to a file of the log dir of the host along
+// with its logs to stderr. The file is rotated by a logrotate sidecar and shipped by an optional fluent-bit sidecar.
+// The daemons which do not persist their logs to the host are left unchanged.
+func ApplyLogCollector(podSpec *v1.PodSpec, daemonType, cephEntity string, clusterSpec *cephv1.ClusterSpec, dataPaths *config.DataPathMap) {
+	if !clusterSpec.LogCollector.Enabled || dataPaths.HostLogAndCrashDir == "" || len(podSpec.Containers) == 0 {
+		return
+	}
+
+	logFile := path.Join(dataPaths.ContainerLogDir(), fmt.Sprintf("ceph-%s.log", cephEntity))
+	podSpec.Containers[0].Args = append(podSpec.Containers[0].Args,
+		config.NewFlag("log-to-file", "true"),
+		config.NewFlag("log-file", logFile),
+	)
+
+	logMount := StoredLogAndCrashVolumeMount(dataPaths.ContainerLogDir(), dataPaths.ContainerCrashDir())[0]
+	podSpec.Containers = append(podSpec.Containers, logCollectorContainer(daemonType, cephEntity, logFile, clusterSpec, logMount))
+	if shipper := clusterSpec.LogCollector.Shipper; shipper != nil {
+		if shipper.Output["name"] == "" {
+			logger.Warningf("the logs of %q are not shipped since the log shipper has no output name", cephEntity)
+			return
+		}
+		podSpec.Containers = append(podSpec.Containers, logShipperContainer(cephEntity, logFile, clusterSpec, logMount))
+	}
+}
+
+func logCollectorContainer(daemonType, cephEntity, logFile string, clusterSpec *cephv1.ClusterSpec, logMount v1.VolumeMount) v1.Container {
+	rotation := LogRotation(&clusterSpec.LogCollector, daemonType)
+	return v1.Container{
+		Name:    logCollectorContainerName,
+		Command: []string{"/bin/bash", "-c", logRotateScript},
+		Image:   clusterSpec.CephVersion.Image,
+		Env: []v1.EnvVar{
+			{Name: "ROOK_LOG_FILE", Value: logFile},
+			{Name: "ROOK_LOG_STATE_FILE", Value: path.Join(logMount.MountPath, fmt.Sprintf(".logrotate-%s.state", cephEntity))},
+			{Name: "ROOK_LOG_PERIODICITY", Value: rotation.Periodicity},
+			{Name: "ROOK_LOG_MAX_SIZE", Value: rotation.MaxLogSize},
+			{Name: "ROOK_LOG_ROTATE_COUNT", Value: strconv.Itoa(rotation.RotateCount)},
+		},
+		VolumeMounts: []v1.VolumeMount{logMount},
+		Resources:    cephv1.GetLogCollectorResources(clusterSpec.Resources),
+	}
+}
+
+func logShipperContainer(cephEntity, logFile string, clusterSpec *cephv1.ClusterSpec, logMount v1.VolumeMount) v1.Container {
+	shipper := clusterSpec.LogCollector.Shipper
+	image := shipper.Image
+	if image == "" {
+		image = defaultLogShipperImage
+	}
+
+	// the position of the tail in the log file is kept in the log dir of the host
+	args := []string{
+		"-i", "tail",
+		"-p", "path=" + logFile,
+		"-p", "tag=" + cephEntity,
+		"-p", "db=" + path.Join(logMount.MountPath, fmt.Sprintf(".fluent-bit-%s.db", cephEntity)),
+		"-o", shipper.Output["name"],
+		"-m", "*",
+	}
+	properties := []string{}
+	for property := range shipper.Output {
+		if property != "name" {
+			properties = append(properties, property)
+		}
+	}
+	sort.Strings(properties)
+	for _, property := range properties {
+		args = append(args, "-p", fmt.Sprintf("%s=%s", property, shipper.Output[property]))
+	}
+
+	return v1.Container{
+		Name:         logShipperContainerName,
+		Command:      []string{"/fluent-bit/bin/fluent-bit"},
+		Args:         args,
+		Image:        image,
+		VolumeMounts: []v1.VolumeMount{logMount},
+		Resources:    cephv1.GetLogShipperResources(clusterSpec.Resources),
+	}
+}
+
+// LogRotation returns the rotation of the log files of a daemon type, the settings of the daemon type override the
+// settings of all the daemons and the invalid settings are replaced by the defaults. The max size is in bytes.
+func LogRotation(spec *cephv1.LogCollectorSpec, daemonType string) cephv1.LogRotationSpec {
+	rotation := cephv1.LogRotationSpec{Periodicity: defaultLogPeriodicity, MaxLogSize: defaultLogMaxSize, RotateCount: defaultLogRotateCount}
+	for _, override := range []cephv1.LogRotationSpec{spec.LogRotationSpec, spec.Daemons[daemonType]} {
+		if override.Periodicity != "" {
+			rotation.Periodicity = override.Periodicity
+		}
+		if override.MaxLogSize != "" {
+			rotation.MaxLogSize = override.MaxLogSize
+		}
+		if override.RotateCount > 0 {
+			rotation.RotateCount = override.RotateCount
+		}
+	}
+
+	rotation.Periodicity = strings.ToLower(rotation.Periodicity)
+	if !logPeriodicities[rotation.Periodicity] {
+		logger.Warningf("invalid log periodicity %q of the %s daemons, using %q", rotation.Periodicity, daemonType, defaultLogPeriodicity)
+		rotation.Periodicity = defaultLogPeriodicity
+	}
+	maxSize, err := resource.ParseQuantity(rotation.MaxLogSize)
+	if err != nil {
+		logger.Warningf("invalid max log size %q of the %s daemons, using %q. %v", rotation.MaxLogSize, daemonType, defaultLogMaxSize, err)
+		maxSize = resource.MustParse(defaultLogMaxSize)
+	}
+	rotation.MaxLogSize = strconv.FormatInt(maxSize.Value(), 10)
+	return rotation
+}
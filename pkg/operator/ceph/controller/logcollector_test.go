@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestApplyLogCollector(t *testing.T) {
+	dataPaths := config.NewStatelessDaemonDataPathMap(config.MgrType, "a", "rook-ceph", "/var/lib/rook")
+	podSpec := v1.PodSpec{Containers: []v1.Container{{Name: "mgr", Args: []string{"--id=a"}}}}
+	clusterSpec := &cephv1.ClusterSpec{}
+
+	// the pod is unchanged when the log collector is disabled
+	ApplyLogCollector(&podSpec, config.MgrType, "mgr.a", clusterSpec, dataPaths)
+	assert.Equal(t, 1, len(podSpec.Containers))
+
+	clusterSpec.LogCollector.Enabled = true
+	ApplyLogCollector(&podSpec, config.MgrType, "mgr.a", clusterSpec, dataPaths)
+	assert.Equal(t, []string{"--id=a", "--log-to-file=true", "--log-file=/var/log/ceph/ceph-mgr.a.log"}, podSpec.Containers[0].Args)
+	assert.Equal(t, 2, len(podSpec.Containers))
+	collector := podSpec.Containers[1]
+	assert.Equal(t, "log-collector", collector.Name)
+	assert.Equal(t, "/var/log/ceph", collector.VolumeMounts[0].MountPath)
+	assert.Contains(t, collector.Env, v1.EnvVar{Name: "ROOK_LOG_FILE", Value: "/var/log/ceph/ceph-mgr.a.log"})
+	assert.Contains(t, collector.Env, v1.EnvVar{Name: "ROOK_LOG_STATE_FILE", Value: "/var/log/ceph/.logrotate-mgr.a.state"})
+
+	// the shipper tails the log file of the daemon
+	clusterSpec.LogCollector.Shipper = &cephv1.LogShipperSpec{Output: map[string]string{"name": "forward", "port": "24224", "host": "fluentd"}}
+	podSpec = v1.PodSpec{Containers: []v1.Container{{Name: "mgr"}}}
+	ApplyLogCollector(&podSpec, config.MgrType, "mgr.a", clusterSpec, dataPaths)
+	assert.Equal(t, 3, len(podSpec.Containers))
+	shipper := podSpec.Containers[2]
+	assert.Equal(t, "fluent/fluent-bit:1.5", shipper.Image)
+	assert.Equal(t, []string{
+		"-i", "tail", "-p", "path=/var/log/ceph/ceph-mgr.a.log", "-p", "tag=mgr.a", "-p", "db=/var/log/ceph/.fluent-bit-mgr.a.db",
+		"-o", "forward", "-m", "*", "-p", "host=fluentd", "-p", "port=24224",
+	}, shipper.Args)
+
+	// the daemons which do not persist their logs are unchanged
+	podSpec = v1.PodSpec{Containers: []v1.Container{{Name: "nfs"}}}
+	ApplyLogCollector(&podSpec, "nfs", "client.nfs", clusterSpec, &config.DataPathMap{})
+	assert.Equal(t, 1, len(podSpec.Containers))
+}
+
+func TestLogRotation(t *testing.T) {
+	spec := &cephv1.LogCollectorSpec{}
+	assert.Equal(t, cephv1.LogRotationSpec{Periodicity: "daily", MaxLogSize: "500000000", RotateCount: 7}, LogRotation(spec, config.OsdType))
+
+	// the settings of a daemon type override the settings of all the daemons
+	spec.LogRotationSpec = cephv1.LogRotationSpec{Periodicity: "Weekly", RotateCount: 4}
+	spec.Daemons = map[string]cephv1.LogRotationSpec{config.OsdType: {MaxLogSize: "1Gi", RotateCount: 2}}
+	assert.Equal(t, cephv1.LogRotationSpec{Periodicity: "weekly", MaxLogSize: "1073741824", RotateCount: 2}, LogRotation(spec, config.OsdType))
+	assert.Equal(t, cephv1.LogRotationSpec{Periodicity: "weekly", MaxLogSize: "500000000", RotateCount: 4}, LogRotation(spec, config.MonType))
+
+	// the invalid settings are replaced by the defaults
+	spec.LogRotationSpec = cephv1.LogRotationSpec{Periodicity: "yearly", MaxLogSize: "big"}
+	assert.Equal(t, cephv1.LogRotationSpec{Periodicity: "daily", MaxLogSize: "500000000", RotateCount: 7}, LogRotation(spec, config.MonType))
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// the controller-runtime metrics have the reconcile durations and the queue depths of each controller, these
+	// metrics have the durations and the retries of each CR
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rook_ceph_reconcile_duration_seconds",
+		Help:    "Duration of the reconciles of the Rook CRs by kind, namespace, name and result",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1800},
+	}, []string{"kind", "namespace", "name", "result"})
+
+	orchestrationRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rook_ceph_orchestration_retries_total",
+		Help: "Reconciles of the Rook CRs that failed or were requeued and will be retried",
+	}, []string{"kind", "namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, orchestrationRetries)
+}
+
+// recordReconcileMetrics exports the duration of a reconcile and counts the retries
+func recordReconcileMetrics(obj runtime.Object, name types.NamespacedName, duration time.Duration, result string) {
+	kind := reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	reconcileDuration.WithLabelValues(kind, name.Namespace, name.Name, result).Observe(duration.Seconds())
+	if result != ReconcileSucceeded {
+		orchestrationRetries.WithLabelValues(kind, name.Namespace, name.Name).Inc()
+	}
+}
+
+// deleteReconcileMetrics removes the metrics of a deleted CR
+func deleteReconcileMetrics(obj runtime.Object, name types.NamespacedName) {
+	kind := reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	for _, result := range []string{ReconcileSucceeded, ReconcileRequeued, ReconcileFailed} {
+		reconcileDuration.DeleteLabelValues(kind, name.Namespace, name.Name, result)
+	}
+	orchestrationRetries.DeleteLabelValues(kind, name.Namespace, name.Name)
+}
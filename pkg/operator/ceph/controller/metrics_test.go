@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRecordReconcileMetrics(t *testing.T) {
+	name := types.NamespacedName{Name: "myfs", Namespace: "rook-ceph"}
+	reconcileDuration.Reset()
+	orchestrationRetries.Reset()
+
+	recordReconcileMetrics(&cephv1.CephFilesystem{}, name, time.Second, ReconcileSucceeded)
+	recordReconcileMetrics(&cephv1.CephFilesystem{}, name, time.Second, ReconcileRequeued)
+	recordReconcileMetrics(&cephv1.CephFilesystem{}, name, time.Second, ReconcileFailed)
+	assert.Equal(t, float64(2), testutil.ToFloat64(orchestrationRetries.WithLabelValues("CephFilesystem", "rook-ceph", "myfs")))
+	assert.Equal(t, 3, testutil.CollectAndCount(reconcileDuration))
+
+	// the metrics of a deleted cr are removed
+	deleteReconcileMetrics(&cephv1.CephFilesystem{}, name)
+	assert.Equal(t, 0, testutil.CollectAndCount(reconcileDuration))
+	assert.Equal(t, 0, testutil.CollectAndCount(orchestrationRetries))
+}
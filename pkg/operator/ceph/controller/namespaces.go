@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// WatchNamespaces are the namespaces watched by the operator, all the namespaces are watched if empty. The operator
+// then only needs the RBAC of the namespaced resources in these namespaces.
+var WatchNamespaces []string
+
+// ParseWatchNamespaces returns the namespaces of a comma-separated list, with the operator namespace which holds the
+// operator settings and the canary deployments
+func ParseWatchNamespaces(list, operatorNamespace string) []string {
+	namespaces := []string{}
+	seen := map[string]bool{}
+	for _, namespace := range append(strings.Split(list, ","), operatorNamespace) {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+// namespacesCacheBuilder returns the function creating a cache of the namespaced objects of the namespaces and of the
+// cluster scoped objects, like the nodes. The multi-namespace cache of controller-runtime lists the cluster scoped
+// objects in each namespace and cannot get them since they have no namespace.
+func namespacesCacheBuilder(namespaces []string) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		namespacedCache, err := cache.MultiNamespacedCacheBuilder(namespaces)(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.Namespace = ""
+		clusterCache, err := cache.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &namespacesCache{Cache: namespacedCache, clusterCache: clusterCache, scheme: opts.Scheme, mapper: opts.Mapper}, nil
+	}
+}
+
+// namespacesCache routes the cluster scoped objects to the cluster cache, and the other objects to the cache of the
+// namespaces
+type namespacesCache struct {
+	cache.Cache
+	clusterCache cache.Cache
+	scheme       *runtime.Scheme
+	mapper       meta.RESTMapper
+}
+
+var _ cache.Cache = &namespacesCache{}
+
+func (c *namespacesCache) cacheFor(obj runtime.Object) cache.Cache {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		// the namespaced cache returns the error
+		return c.Cache
+	}
+	// the lists have the scope of their items
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	return c.cacheForKind(gvk)
+}
+
+func (c *namespacesCache) cacheForKind(gvk schema.GroupVersionKind) cache.Cache {
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil || mapping.Scope.Name() != meta.RESTScopeNameRoot {
+		return c.Cache
+	}
+	return c.clusterCache
+}
+
+// Get implements client.Reader
+func (c *namespacesCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return c.cacheFor(obj).Get(ctx, key, obj)
+}
+
+// List implements client.Reader
+func (c *namespacesCache) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return c.cacheFor(list).List(ctx, list, opts...)
+}
+
+// GetInformer implements cache.Informers
+func (c *namespacesCache) GetInformer(obj runtime.Object) (cache.Informer, error) {
+	return c.cacheFor(obj).GetInformer(obj)
+}
+
+// GetInformerForKind implements cache.Informers
+func (c *namespacesCache) GetInformerForKind(gvk schema.GroupVersionKind) (cache.Informer, error) {
+	return c.cacheForKind(gvk).GetInformerForKind(gvk)
+}
+
+// IndexField implements client.FieldIndexer
+func (c *namespacesCache) IndexField(obj runtime.Object, field string, extractValue client.IndexerFunc) error {
+	return c.cacheFor(obj).IndexField(obj, field, extractValue)
+}
+
+// Start implements cache.Informers, blocking until the stop channel is closed like the caches
+func (c *namespacesCache) Start(stopCh <-chan struct{}) error {
+	go func() {
+		if err := c.clusterCache.Start(stopCh); err != nil {
+			logger.Errorf("failed to start the cache of the cluster scoped objects. %v", err)
+		}
+	}()
+	return c.Cache.Start(stopCh)
+}
+
+// WaitForCacheSync implements cache.Informers
+func (c *namespacesCache) WaitForCacheSync(stop <-chan struct{}) bool {
+	return c.clusterCache.WaitForCacheSync(stop) && c.Cache.WaitForCacheSync(stop)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+)
+
+func TestParseWatchNamespaces(t *testing.T) {
+	assert.Equal(t, []string{"ns-a", "ns-b", "rook-ceph"}, ParseWatchNamespaces("ns-a, ns-b,,ns-a", "rook-ceph"))
+	assert.Equal(t, []string{"rook-ceph", "ns-a"}, ParseWatchNamespaces("rook-ceph,ns-a", "rook-ceph"))
+	assert.Equal(t, []string{"rook-ceph"}, ParseWatchNamespaces("", "rook-ceph"))
+}
+
+func TestNamespacesCache(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Node"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	namespacedCache := &informertest.FakeInformers{Scheme: scheme.Scheme}
+	clusterCache := &informertest.FakeInformers{Scheme: scheme.Scheme}
+	c := &namespacesCache{Cache: namespacedCache, clusterCache: clusterCache, scheme: scheme.Scheme, mapper: mapper}
+
+	// the cluster scoped objects and their lists are in the cluster cache
+	assert.Equal(t, clusterCache, c.cacheFor(&corev1.Node{}))
+	assert.Equal(t, clusterCache, c.cacheFor(&corev1.NodeList{}))
+	_, err := c.GetInformer(&corev1.Node{})
+	assert.NoError(t, err)
+	assert.Contains(t, clusterCache.InformersByGVK, corev1.SchemeGroupVersion.WithKind("Node"))
+	assert.Empty(t, namespacedCache.InformersByGVK)
+
+	// the namespaced objects and the unknown kinds are in the cache of the namespaces
+	assert.Equal(t, namespacedCache, c.cacheFor(&corev1.Secret{}))
+	assert.Equal(t, namespacedCache, c.cacheFor(&corev1.SecretList{}))
+	assert.Equal(t, namespacedCache, c.cacheFor(&corev1.ConfigMap{}))
+	_, err = c.GetInformerForKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	assert.NoError(t, err)
+	assert.Contains(t, namespacedCache.InformersByGVK, corev1.SchemeGroupVersion.WithKind("Secret"))
+	assert.Len(t, clusterCache.InformersByGVK, 1)
+}
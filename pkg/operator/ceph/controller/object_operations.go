@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 
 	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +34,8 @@ func CreateOrUpdateObject(client client.Client, obj runtime.Object) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to get meta information of object")
 	}
+	// the resources created from the CRs in the namespace of a cluster inherit its metadata
+	k8sutil.ApplyInheritedMetadata(accessor.GetNamespace(), accessor)
 
 	err = client.Create(context.TODO(), obj)
 	if err != nil {
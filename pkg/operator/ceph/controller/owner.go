@@ -64,6 +64,12 @@ func (e *OwnerMatcher) Match(object runtime.Object) (bool, metav1.Object, error)
 		return false, o, errors.Wrapf(err, "could not access object meta kind %q", object.GetObjectKind())
 	}
 
+	// The owner references are namespaced, the children of an owner with a namespace are in the namespace of the
+	// owner. The caches of the watched namespaces send the events of the objects of all these namespaces.
+	if e.ownerMeta != nil && e.ownerMeta.GetNamespace() != "" && o.GetNamespace() != e.ownerMeta.GetNamespace() {
+		return false, o, nil
+	}
+
 	// Iterate over owner reference of the child object
 	for _, owner := range e.getOwnersReferences(o) {
 		groupVersion, err := schema.ParseGroupVersion(owner.APIVersion)
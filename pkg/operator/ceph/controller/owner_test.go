@@ -87,4 +87,10 @@ func TestMatch(t *testing.T) {
 	match, _, err = ownerMatcher.Match(fakeChildObject)
 	assert.NoError(t, err)
 	assert.True(t, match)
+
+	// A child in another namespace of the watched namespaces
+	fakeChildObject.Namespace = "other-namespace"
+	match, _, err = ownerMatcher.Match(fakeChildObject)
+	assert.NoError(t, err)
+	assert.False(t, match)
 }
@@ -38,10 +38,25 @@ import (
 const (
 	cephVersionLabelKey = "ceph_version"
 	// Unfortunately this is a duplicate of the const EndpointConfigMapName in the mon package, but done to avoid import cycle
-	endpointConfigMapName   = "rook-ceph-mon-endpoints"
+	endpointConfigMapName = "rook-ceph-mon-endpoints"
+	// Unfortunately this is a duplicate of the const RestoreQuorumAnnotation in the mon package, but done to avoid import cycle
+	restoreQuorumAnnotation = "ceph.rook.io/restore-quorum"
 	doNotReconcileLabelName = "do_not_reconcile"
 )
 
+// operatorIgnoredNamePrefixes are the name prefixes of the objects in the operator namespace that the operator
+// itself updates frequently. Their updates never trigger a reconcile of any controller.
+var operatorIgnoredNamePrefixes = []string{
+	// Unfortunately this is a duplicate of the const detectCSIVersionName in the csi package, but done to avoid import cycle
+	"rook-ceph-csi-detect-version",
+}
+
+// ReconcileIgnorePaths are the paths of the fields of the objects owned by the CRs whose changes never trigger a
+// reconcile, like the annotations or the sidecars injected by a service mesh or a mutating webhook. The paths are JSON
+// pointers in the objects, where the elements of a list are matched by name and "*" matches all the keys or elements,
+// e.g. "/spec/template/metadata/annotations/sidecar.istio.io~1status" or "/spec/template/spec/containers/istio-proxy".
+var ReconcileIgnorePaths = []string{}
+
 // WatchControllerPredicate is a special update filter for update events
 // do not reconcile if the the status changes, this avoids a reconcile storm loop
 //
@@ -87,6 +102,11 @@ func WatchControllerPredicate() predicate.Funcs {
 				if isUpgrade {
 					return true
 				}
+				// Handling the restart requests
+				if isRestartRequest(objOld.GetAnnotations(), objNew.GetAnnotations()) {
+					logger.Infof("restart of the daemons of %q requested", objNew.Name)
+					return true
+				}
 
 			case *cephv1.CephObjectStoreUser:
 				objNew := e.ObjectNew.(*cephv1.CephObjectStoreUser)
@@ -212,6 +232,11 @@ func WatchControllerPredicate() predicate.Funcs {
 				if isUpgrade {
 					return true
 				}
+				// Handling the restart requests
+				if isRestartRequest(objOld.GetAnnotations(), objNew.GetAnnotations()) {
+					logger.Infof("restart of the daemons of %q requested", objNew.Name)
+					return true
+				}
 
 			case *cephv1.CephNFS:
 				objNew := e.ObjectNew.(*cephv1.CephNFS)
@@ -282,6 +307,16 @@ func WatchControllerPredicate() predicate.Funcs {
 				} else if objOld.GetGeneration() != objNew.GetGeneration() {
 					logger.Debugf("skipping resource %q update with unchanged spec", objNew.Name)
 				}
+				// Handling the restart requests
+				if isRestartRequest(objOld.GetAnnotations(), objNew.GetAnnotations()) {
+					logger.Infof("restart of the daemons of %q requested", objNew.Name)
+					return true
+				}
+				// Handling the restore of the mon quorum
+				if isRestoreQuorumRequest(objOld.GetAnnotations(), objNew.GetAnnotations()) {
+					logger.Infof("restore of the mon quorum of %q requested", objNew.Name)
+					return true
+				}
 			}
 
 			return false
@@ -326,7 +361,12 @@ func objectChanged(oldObj, newObj runtime.Object, objectName string) (bool, erro
 //
 // We return 'false' on a create event so we don't overstep with the main watcher on cephv1.CephBlockPool{}
 // This avoids a double reconcile when the secret gets deleted.
-func WatchPredicateForNonCRDObject(owner runtime.Object, scheme *runtime.Scheme) predicate.Funcs {
+//
+// The ignoredNamePrefixes are name prefixes of objects updated frequently that the controller never needs to
+// reconcile on update. They are checked before the more expensive owner matching and diff of the objects.
+func WatchPredicateForNonCRDObject(owner runtime.Object, scheme *runtime.Scheme, ignoredNamePrefixes ...string) predicate.Funcs {
+	ignoredNamePrefixes = append(ignoredNamePrefixes, operatorIgnoredNamePrefixes...)
+
 	// Initialize the Owner Matcher, which is the main controller object: e.g. cephv1.CephBlockPool{}
 	ownerMatcher, err := NewOwnerReferenceMatcher(owner, scheme)
 	if err != nil {
@@ -366,6 +406,11 @@ func WatchPredicateForNonCRDObject(owner runtime.Object, scheme *runtime.Scheme)
 		},
 
 		UpdateFunc: func(e event.UpdateEvent) bool {
+			// cheap early exit for the objects updated frequently
+			if e.MetaNew != nil && hasIgnoredNamePrefix(e.MetaNew.GetName(), ignoredNamePrefixes) {
+				return false
+			}
+
 			match, object, err := ownerMatcher.Match(e.ObjectNew)
 			if err != nil {
 				logger.Errorf("failed to check if object matched. %v", err)
@@ -436,8 +481,13 @@ func isValidEvent(patch []byte, objectName string) bool {
 	// Do not reconcile on metadata change since managedFields are often updated by the server
 	delete(p, "metadata")
 
+	// nor on the changes of the fields managed by others
+	for _, path := range ReconcileIgnorePaths {
+		removePatchPath(p, parsePatchPath(path))
+	}
+
 	// If the patch is now empty, we don't reconcile, nothing changed
-	if len(p) == 0 {
+	if isEmptyPatch(p) {
 		return false
 	}
 
@@ -453,6 +503,105 @@ func isValidEvent(patch []byte, objectName string) bool {
 	return true
 }
 
+// parsePatchPath returns the segments of a JSON pointer
+func parsePatchPath(path string) []string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+	}
+	return segments
+}
+
+// removePatchPath removes the fields of the path from the patch, and the maps and lists left empty by the removal. It
+// returns the remaining value and whether the path was found.
+func removePatchPath(value interface{}, segments []string) (interface{}, bool) {
+	if len(segments) == 0 {
+		return nil, true
+	}
+	removed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if segments[0] != "*" && key != segments[0] {
+				continue
+			}
+			remaining, found := removePatchPath(child, segments[1:])
+			if !found {
+				continue
+			}
+			removed = true
+			if isEmptyPatch(remaining) {
+				delete(v, key)
+			} else {
+				v[key] = remaining
+			}
+		}
+	case []interface{}:
+		items := []interface{}{}
+		for _, item := range v {
+			element, ok := item.(map[string]interface{})
+			if !ok || (segments[0] != "*" && element["name"] != segments[0]) {
+				items = append(items, item)
+				continue
+			}
+			remaining, found := removePatchPath(element, segments[1:])
+			if !found {
+				items = append(items, item)
+				continue
+			}
+			removed = true
+			if remaining != nil && !isEmptyPatch(withoutMergeKey(remaining.(map[string]interface{}))) {
+				items = append(items, remaining)
+			}
+		}
+		value = items
+	}
+	return value, removed
+}
+
+// withoutMergeKey returns the fields of an element of a list without its name, the merge key of the list which is
+// not a change
+func withoutMergeKey(element map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for key, value := range element {
+		if key != "name" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// isEmptyPatch returns whether a patch has no change, the directives of the strategic merge patches like
+// "$setElementOrder/containers" only ordering the elements of the lists
+func isEmptyPatch(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		for key := range v {
+			if !strings.HasPrefix(key, "$") {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// isRestartRequest returns whether a new restart of the daemons was requested with the restart annotation
+func isRestartRequest(oldAnnotations, newAnnotations map[string]string) bool {
+	request := newAnnotations[RestartAnnotation]
+	return request != "" && request != oldAnnotations[RestartAnnotation]
+}
+
+// isRestoreQuorumRequest returns whether the restore of the mon quorum was requested with the restore annotation
+func isRestoreQuorumRequest(oldAnnotations, newAnnotations map[string]string) bool {
+	request := newAnnotations[restoreQuorumAnnotation]
+	return request != "" && request != oldAnnotations[restoreQuorumAnnotation]
+}
+
 func isUpgrade(oldLabels, newLabels map[string]string) bool {
 	oldLabelVal, oldLabelKeyExist := oldLabels[cephVersionLabelKey]
 	newLabelVal, newLabelKeyExist := newLabels[cephVersionLabelKey]
@@ -543,6 +692,17 @@ func isSecretToIgnoreOnUpdate(obj runtime.Object) bool {
 	return false
 }
 
+// hasIgnoredNamePrefix returns whether the object name starts with one of the ignored prefixes
+func hasIgnoredNamePrefix(objectName string, ignoredNamePrefixes []string) bool {
+	for _, prefix := range ignoredNamePrefixes {
+		if strings.HasPrefix(objectName, prefix) {
+			logger.Debugf("do not reconcile on %q changes, it matches the ignored prefix %q", objectName, prefix)
+			return true
+		}
+	}
+	return false
+}
+
 func isDoNotReconcile(labels map[string]string) bool {
 	value, ok := labels[doNotReconcileLabelName]
 
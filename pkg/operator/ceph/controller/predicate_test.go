@@ -21,11 +21,16 @@ import (
 	"testing"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
 	"github.com/rook/rook/pkg/operator/ceph/config"
+	optest "github.com/rook/rook/pkg/operator/ceph/controller/test"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var (
@@ -47,7 +52,7 @@ func TestObjectChanged(t *testing.T) {
 				Size: oldReplicas,
 			},
 		},
-		Status: &cephv1.Status{
+		Status: &cephv1.CephBlockPoolStatus{
 			Phase: "",
 		},
 	}
@@ -62,7 +67,7 @@ func TestObjectChanged(t *testing.T) {
 				Size: oldReplicas,
 			},
 		},
-		Status: &cephv1.Status{
+		Status: &cephv1.CephBlockPoolStatus{
 			Phase: "",
 		},
 	}
@@ -106,6 +111,15 @@ func TestIsUpgrade(t *testing.T) {
 	assert.True(t, b, fmt.Sprintf("%v,%v", oldLabel, newLabel))
 }
 
+func TestIsRestoreQuorumRequest(t *testing.T) {
+	assert.False(t, isRestoreQuorumRequest(map[string]string{}, map[string]string{"foo": "bar"}))
+	assert.True(t, isRestoreQuorumRequest(map[string]string{}, map[string]string{restoreQuorumAnnotation: "a"}))
+	assert.True(t, isRestoreQuorumRequest(map[string]string{restoreQuorumAnnotation: "a"}, map[string]string{restoreQuorumAnnotation: "b"}))
+	assert.False(t, isRestoreQuorumRequest(map[string]string{restoreQuorumAnnotation: "a"}, map[string]string{restoreQuorumAnnotation: "a"}))
+	// the removal of the annotation by the operator does not trigger another reconcile
+	assert.False(t, isRestoreQuorumRequest(map[string]string{restoreQuorumAnnotation: "a"}, map[string]string{}))
+}
+
 func TestIsValidEvent(t *testing.T) {
 	obj := "rook-ceph-mon-a"
 	valid := []byte(`{
@@ -137,6 +151,35 @@ func TestIsValidEvent(t *testing.T) {
 	assert.False(t, b)
 }
 
+func TestIsValidEventIgnorePaths(t *testing.T) {
+	ReconcileIgnorePaths = []string{
+		"/spec/template/metadata/annotations/sidecar.istio.io~1status",
+		"/spec/template/spec/containers/istio-proxy",
+		"/spec/template/spec/initContainers/*/resources",
+	}
+	defer func() { ReconcileIgnorePaths = []string{} }()
+	obj := "rook-ceph-rgw-my-store-a"
+
+	// the injected annotation and sidecar are ignored, with the order of the containers
+	sidecar := []byte(`{"spec":{"template":{
+		"metadata":{"annotations":{"sidecar.istio.io/status":"{}"}},
+		"spec":{"$setElementOrder/containers":[{"name":"rgw"},{"name":"istio-proxy"}],
+		"containers":[{"name":"istio-proxy","image":"istio/proxyv2"}],
+		"initContainers":[{"name":"chown","resources":{"limits":{"cpu":"100m"}}}]}}}}`)
+	assert.False(t, isValidEvent(sidecar, obj))
+
+	// the other changes of the same objects are reconciled
+	image := []byte(`{"spec":{"template":{
+		"metadata":{"annotations":{"sidecar.istio.io/status":"{}"}},
+		"spec":{"containers":[{"name":"rgw","image":"ceph/ceph:v15.2.5"},{"name":"istio-proxy","image":"istio/proxyv2"}]}}}}`)
+	assert.True(t, isValidEvent(image, obj))
+	annotation := []byte(`{"spec":{"template":{"metadata":{"annotations":{"foo":"bar"}}}}}`)
+	assert.True(t, isValidEvent(annotation, obj))
+
+	// the empty fields outside of the paths are still changes
+	assert.True(t, isValidEvent([]byte(`{"spec":{}}`), obj))
+}
+
 func TestIsCanary(t *testing.T) {
 	dum := &cephv1.CephBlockPool{}
 
@@ -234,3 +277,88 @@ func TestIsDoNotReconcile(t *testing.T) {
 	b = isDoNotReconcile(l)
 	assert.True(t, b)
 }
+
+func TestHasIgnoredNamePrefix(t *testing.T) {
+	prefixes := []string{"rook-ceph-detect-version", "rook-ceph-csi-detect-version"}
+
+	assert.False(t, hasIgnoredNamePrefix("rook-config-override", prefixes))
+	assert.False(t, hasIgnoredNamePrefix("rook-ceph-detect-version", nil))
+	assert.True(t, hasIgnoredNamePrefix("rook-ceph-detect-version", prefixes))
+	assert.True(t, hasIgnoredNamePrefix("rook-ceph-csi-detect-version-xyz", prefixes))
+}
+
+func TestWatchPredicateForNonCRDObjectIgnoredPrefix(t *testing.T) {
+	isController := true
+	owner := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-cluster",
+			Namespace: namespace,
+			UID:       "ce6807a0-7270-4874-9e9f-ae493d48b814",
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CephCluster",
+			APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+		},
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      k8sutil.ConfigOverrideName,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: owner.APIVersion,
+					Kind:       owner.Kind,
+					Name:       owner.Name,
+					UID:        owner.UID,
+					Controller: &isController,
+				},
+			},
+		},
+	}
+	newCM := cm.DeepCopy()
+	newCM.Data = map[string]string{"config": "[global]"}
+	e := event.UpdateEvent{MetaOld: cm, ObjectOld: cm, MetaNew: newCM, ObjectNew: newCM}
+
+	// the owned config map changed
+	p := WatchPredicateForNonCRDObject(owner, scheme.Scheme)
+	assert.True(t, p.Update(e))
+
+	// the config map name matches an ignored prefix
+	p = WatchPredicateForNonCRDObject(owner, scheme.Scheme, "rook-config")
+	assert.False(t, p.Update(e))
+}
+
+func TestPredicatesWithEvents(t *testing.T) {
+	store := &cephv1.CephObjectStore{}
+	optest.InitObject(t, store, "my-store", "rook-ceph")
+	p := WatchControllerPredicate()
+	assert.True(t, p.Create(optest.CreateEvent(t, store)))
+	assert.True(t, p.Delete(optest.DeleteEvent(t, store)))
+
+	// the changes of the spec and the deletions are reconciled, not the status updates
+	assert.True(t, p.Update(optest.UpdateEvent(t, store, func(newObj runtime.Object) {
+		newObj.(*cephv1.CephObjectStore).Spec.Gateway.Instances = 2
+	})))
+	assert.False(t, p.Update(optest.UpdateEvent(t, store, func(newObj runtime.Object) {
+		newObj.(*cephv1.CephObjectStore).Status = &cephv1.ObjectStoreStatus{Phase: "Ready"}
+	})))
+	assert.True(t, p.Update(optest.DeletionUpdateEvent(t, store)))
+
+	// the deletion of the resources owned by another store are not reconciled
+	secret := &corev1.Secret{}
+	optest.InitObject(t, secret, "rook-ceph-rgw-my-store-keyring", "rook-ceph")
+	optest.SetControllerOwner(t, secret, store)
+	p = WatchPredicateForNonCRDObject(store, scheme.Scheme)
+	assert.False(t, p.Create(optest.CreateEvent(t, secret)))
+	assert.True(t, p.Delete(optest.DeleteEvent(t, secret)))
+	other := &cephv1.CephObjectStore{}
+	optest.InitObject(t, other, "other-store", "rook-ceph")
+	otherSecret := &corev1.Secret{}
+	optest.InitObject(t, otherSecret, "rook-ceph-rgw-other-store-keyring", "rook-ceph")
+	optest.SetControllerOwner(t, otherSecret, other)
+	assert.False(t, p.Delete(optest.DeleteEvent(t, otherSecret)))
+}
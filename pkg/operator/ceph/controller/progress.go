@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ProgressStatus converts the operations in progress of the progress mgr module to the status of a CR
+func ProgressStatus(progress *cephclient.ProgressEvents) []cephv1.ProgressEventStatus {
+	if progress == nil || len(progress.Events) == 0 {
+		return nil
+	}
+	status := make([]cephv1.ProgressEventStatus, 0, len(progress.Events))
+	for _, e := range progress.Events {
+		status = append(status, cephv1.ProgressEventStatus{
+			ID:       e.ID,
+			Message:  e.Message,
+			Progress: fmt.Sprintf("%.0f%%", 100*e.Progress),
+		})
+	}
+	return status
+}
+
+// ReportProgress emits an event on the CR for each operation started since the previous status of the CR, and for
+// each operation of the previous status which is no longer in progress. The previous status is kept in the CR so
+// that the operations are reported once, even across the restarts of the operator.
+func ReportProgress(recorder record.EventRecorder, obj runtime.Object, previous []cephv1.ProgressEventStatus, progress *cephclient.ProgressEvents) {
+	if recorder == nil || progress == nil {
+		return
+	}
+	reported := map[string]bool{}
+	for _, e := range previous {
+		reported[e.ID] = true
+	}
+	active := map[string]bool{}
+	for _, e := range progress.Events {
+		active[e.ID] = true
+		if !reported[e.ID] {
+			recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonProgressStarted, "started: %s", e.Message)
+		}
+	}
+
+	completed := map[string]cephclient.ProgressEvent{}
+	for _, e := range progress.Completed {
+		completed[e.ID] = e
+	}
+	for _, e := range previous {
+		if active[e.ID] {
+			continue
+		}
+		// the completed operations are forgotten by the module after a while, the last known message is reported then
+		c, ok := completed[e.ID]
+		switch {
+		case ok && c.Failed:
+			recorder.Eventf(obj, corev1.EventTypeWarning, EventReasonProgressFailed, "failed: %s. %s", c.Message, c.FailureMessage)
+		case ok:
+			recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonProgressCompleted, "completed: %s", c.Message)
+		default:
+			recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonProgressCompleted, "completed: %s", e.Message)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReportProgress(t *testing.T) {
+	cluster := &cephv1.CephCluster{}
+	recorder := record.NewFakeRecorder(5)
+	progress := &cephclient.ProgressEvents{
+		Events: []cephclient.ProgressEvent{{ID: "a", Message: "PG autoscaler increasing pool 2 PGs from 32 to 128", Progress: 0.254}},
+	}
+
+	// a new operation is reported
+	status := ProgressStatus(progress)
+	assert.Equal(t, []cephv1.ProgressEventStatus{{ID: "a", Message: "PG autoscaler increasing pool 2 PGs from 32 to 128", Progress: "25%"}}, status)
+	ReportProgress(recorder, cluster, nil, progress)
+	assert.Equal(t, "Normal ProgressStarted started: PG autoscaler increasing pool 2 PGs from 32 to 128", <-recorder.Events)
+
+	// the operation is reported once
+	ReportProgress(recorder, cluster, status, progress)
+	assert.Empty(t, recorder.Events)
+
+	// the completed and failed operations are reported
+	previous := append(status, cephv1.ProgressEventStatus{ID: "b", Message: "Rebalancing after osd.1 marked out"}, cephv1.ProgressEventStatus{ID: "c", Message: "old"})
+	progress = &cephclient.ProgressEvents{Completed: []cephclient.ProgressEvent{
+		{ID: "a", Message: "PG autoscaler increasing pool 2 PGs from 32 to 128", Progress: 1},
+		{ID: "b", Message: "Rebalancing after osd.1 marked out", Failed: true, FailureMessage: "osd.1 marked in"},
+	}}
+	assert.Nil(t, ProgressStatus(progress))
+	ReportProgress(recorder, cluster, previous, progress)
+	assert.Equal(t, "Normal ProgressCompleted completed: PG autoscaler increasing pool 2 PGs from 32 to 128", <-recorder.Events)
+	assert.Equal(t, "Warning ProgressFailed failed: Rebalancing after osd.1 marked out. osd.1 marked in", <-recorder.Events)
+	assert.Equal(t, "Normal ProgressCompleted completed: old", <-recorder.Events)
+
+	// nothing is reported when the progress is unknown
+	ReportProgress(recorder, cluster, previous, nil)
+	assert.Empty(t, recorder.Events)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RestartAnnotation requests a rolling restart of daemons of a CR, as "<daemon types>@<time>" such as
+	// "osd@2020-10-14T10:00:00Z" or "mon,mgr@2020-10-14T10:00:00Z". The time identifies the request, another restart
+	// of the same daemons needs another time.
+	RestartAnnotation = "ceph.rook.io/restart"
+	// RestartedAnnotation is the last restart request of the RestartAnnotation completed by the operator
+	RestartedAnnotation = "ceph.rook.io/restarted"
+	// RestartProgressAnnotation is the last deployment restarted for the RestartAnnotation in progress, as
+	// "<request>/<daemon type>/<deployment>"
+	RestartProgressAnnotation = "ceph.rook.io/restart-progress"
+	// RestartAllDaemons selects all the daemons of the CR in the RestartAnnotation
+	RestartAllDaemons = "all"
+)
+
+// RestartDaemon is a daemon type of the rolling restarts
+type RestartDaemon struct {
+	// Type is the daemon type of the restart requests and of the ok-to-stop checks
+	Type string
+	// Selector is the label selector of the deployments of the daemons
+	Selector string
+	// IDLabel is the label of the deployments with the id of their daemon
+	IDLabel string
+}
+
+// PendingRestart returns the daemons selected by the restart request of the CR, in the order of the given daemons,
+// or nil when no restart is requested or the request was already completed
+func PendingRestart(obj runtime.Object, daemons []RestartDaemon) ([]RestartDaemon, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get meta information of object")
+	}
+	request := accessor.GetAnnotations()[RestartAnnotation]
+	if request == "" || request == accessor.GetAnnotations()[RestartedAnnotation] {
+		return nil, nil
+	}
+	sep := strings.LastIndex(request, "@")
+	if sep <= 0 || sep == len(request)-1 {
+		return nil, errors.Errorf("invalid restart request %q, expected <daemon types>@<time>", request)
+	}
+
+	selected := map[string]bool{}
+	for _, daemonType := range strings.Split(request[:sep], ",") {
+		daemonType = strings.TrimSpace(daemonType)
+		if daemonType == RestartAllDaemons {
+			return daemons, nil
+		}
+		found := false
+		for _, daemon := range daemons {
+			found = found || daemon.Type == daemonType
+		}
+		if !found {
+			return nil, errors.Errorf("invalid daemon type %q in restart request %q", daemonType, request)
+		}
+		selected[daemonType] = true
+	}
+	pending := []RestartDaemon{}
+	for _, daemon := range daemons {
+		if selected[daemon.Type] {
+			pending = append(pending, daemon)
+		}
+	}
+	return pending, nil
+}
+
+// RestartNextDaemon restarts the next deployment of the daemons of the restart request of the CR, in the order of the
+// daemons, and returns whether all the daemons were restarted. A single deployment is restarted at each call so that
+// the reconcile of the CR is not blocked for the whole restart, the caller requeues the reconcile until all the daemons
+// are restarted. The last restarted deployment is kept in the RestartProgressAnnotation of the CR. The next deployment
+// waits until the PGs are clean or the filesystem is active again after the previous one, and is only restarted when
+// Ceph allows to stop it and its pods can be evicted without breaking their disruption budgets.
+func RestartNextDaemon(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, c client.Client, obj runtime.Object, daemons []RestartDaemon) (bool, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get meta information of object")
+	}
+	request := accessor.GetAnnotations()[RestartAnnotation]
+	lastType, lastDeployment := parseRestartProgress(accessor.GetAnnotations()[RestartProgressAnnotation], request)
+	lastTypeIndex := -1
+	for i, daemon := range daemons {
+		if daemon.Type == lastType {
+			lastTypeIndex = i
+		}
+	}
+	if lastDeployment != "" {
+		if err := cephclient.OkToContinueNoWait(context, clusterInfo, lastDeployment, lastType); err != nil {
+			logger.Infof("waiting for restarted deployment %q to settle before the next daemon. %v", lastDeployment, err)
+			return false, nil
+		}
+	}
+
+	for i, daemon := range daemons {
+		if i < lastTypeIndex {
+			continue
+		}
+		deployments, err := k8sutil.GetDeployments(context.Clientset, clusterInfo.Namespace, daemon.Selector)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to list the deployments of the %s daemons", daemon.Type)
+		}
+		sort.Slice(deployments.Items, func(i, j int) bool { return deployments.Items[i].Name < deployments.Items[j].Name })
+		for j := range deployments.Items {
+			d := &deployments.Items[j]
+			if i == lastTypeIndex && d.Name <= lastDeployment {
+				continue
+			}
+			daemonID := d.Labels[daemon.IDLabel]
+			if err := cephclient.OkToStop(context, clusterInfo, d.Name, daemon.Type, daemonID); err != nil {
+				logger.Infof("waiting to restart deployment %q. %v", d.Name, err)
+				return false, nil
+			}
+			if err := k8sutil.EvictDeploymentAndWait(context.Clientset, d); err != nil {
+				if kerrors.IsTooManyRequests(err) {
+					logger.Infof("waiting to restart deployment %q, the eviction of its pods was refused by their disruption budget", d.Name)
+					return false, nil
+				}
+				return false, errors.Wrapf(err, "failed to restart deployment %q", d.Name)
+			}
+			if err := setRestartProgress(c, obj, fmt.Sprintf("%s/%s/%s", request, daemon.Type, d.Name)); err != nil {
+				return false, err
+			}
+			logger.Infof("restarted deployment %q for restart request %q", d.Name, request)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseRestartProgress returns the daemon type and the deployment of the RestartProgressAnnotation of the given restart
+// request, empty for another request
+func parseRestartProgress(progress, request string) (string, string) {
+	if !strings.HasPrefix(progress, request+"/") {
+		return "", ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(progress, request+"/"), "/", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// setRestartProgress patches the RestartProgressAnnotation of the CR
+func setRestartProgress(c client.Client, obj runtime.Object, progress string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{RestartProgressAnnotation: progress},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the restart progress patch")
+	}
+	if err := c.Patch(context.TODO(), obj.DeepCopyObject(), client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return errors.Wrapf(err, "failed to record the restart progress %q", progress)
+	}
+	return nil
+}
+
+// CompleteRestart sets the RestartedAnnotation of the CR to its restart request so that the request is not repeated,
+// removes the RestartProgressAnnotation and emits an event on the CR. The CR is patched so that the request is kept by
+// the GitOps tools applying it.
+func CompleteRestart(c client.Client, recorder record.EventRecorder, obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to get meta information of object")
+	}
+	request := accessor.GetAnnotations()[RestartAnnotation]
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{RestartedAnnotation: request, RestartProgressAnnotation: nil},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the restart patch")
+	}
+	if err := c.Patch(context.TODO(), obj.DeepCopyObject(), client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return errors.Wrapf(err, "failed to complete the restart request %q of %q", request, accessor.GetName())
+	}
+	if recorder != nil {
+		recorder.Eventf(obj, corev1.EventTypeNormal, EventReasonDaemonsRestarted, "completed restart request %q", request)
+	}
+	return nil
+}
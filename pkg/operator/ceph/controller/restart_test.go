@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	ctx "context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func restartTypes(daemons []RestartDaemon) []string {
+	types := []string{}
+	for _, daemon := range daemons {
+		types = append(types, daemon.Type)
+	}
+	return types
+}
+
+func TestPendingRestart(t *testing.T) {
+	daemons := []RestartDaemon{{Type: "mon"}, {Type: "osd"}, {Type: "rgw"}}
+	cluster := &cephv1.CephCluster{}
+	pending := func(request string) ([]string, error) {
+		cluster.Annotations = map[string]string{RestartAnnotation: request}
+		p, err := PendingRestart(cluster, daemons)
+		if p == nil {
+			return nil, err
+		}
+		return restartTypes(p), err
+	}
+
+	// no request
+	p, err := PendingRestart(cluster, daemons)
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	// the daemons are restarted in their order
+	types, err := pending("rgw,mon@2020-10-14T10:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mon", "rgw"}, types)
+	types, err = pending("all@1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mon", "osd", "rgw"}, types)
+
+	// the completed request is not repeated
+	cluster.Annotations[RestartedAnnotation] = "all@1"
+	p, err = PendingRestart(cluster, daemons)
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+	types, err = pending("all@2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mon", "osd", "rgw"}, types)
+
+	// invalid requests
+	for _, request := range []string{"osd", "osd@", "@1", "mds@1", "osd,,mon@1"} {
+		_, err = pending(request)
+		assert.Error(t, err, request)
+	}
+}
+
+func TestCompleteRestart(t *testing.T) {
+	fs := &cephv1.CephFilesystem{
+		TypeMeta: metav1.TypeMeta{Kind: "CephFilesystem", APIVersion: cephv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "myfs",
+			Namespace:   "rook-ceph",
+			Annotations: map[string]string{RestartAnnotation: "mds@1"},
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, fs)
+	cl := fake.NewFakeClientWithScheme(s, fs)
+	recorder := record.NewFakeRecorder(10)
+
+	assert.NoError(t, CompleteRestart(cl, recorder, fs))
+	result := &cephv1.CephFilesystem{}
+	assert.NoError(t, cl.Get(ctx.TODO(), types.NamespacedName{Name: "myfs", Namespace: "rook-ceph"}, result))
+	assert.Equal(t, "mds@1", result.Annotations[RestartedAnnotation])
+	assert.Equal(t, "mds@1", result.Annotations[RestartAnnotation])
+	assert.Equal(t, `Normal DaemonsRestarted completed restart request "mds@1"`, <-recorder.Events)
+
+	// a new request triggers a reconcile
+	assert.True(t, isRestartRequest(map[string]string{}, map[string]string{RestartAnnotation: "mds@1"}))
+	assert.True(t, isRestartRequest(map[string]string{RestartAnnotation: "mds@1"}, map[string]string{RestartAnnotation: "mds@2"}))
+	assert.False(t, isRestartRequest(map[string]string{RestartAnnotation: "mds@1"}, map[string]string{RestartAnnotation: "mds@1", RestartedAnnotation: "mds@1"}))
+	assert.False(t, isRestartRequest(map[string]string{RestartAnnotation: "mds@1"}, map[string]string{}))
+}
+
+func TestRestartNextDaemon(t *testing.T) {
+	store := &cephv1.CephObjectStore{
+		TypeMeta: metav1.TypeMeta{Kind: "CephObjectStore", APIVersion: cephv1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "store",
+			Namespace:   "rook-ceph",
+			Annotations: map[string]string{RestartAnnotation: "rgw@1"},
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, store)
+	cl := fake.NewFakeClientWithScheme(s, store)
+
+	objects := []runtime.Object{}
+	for _, name := range []string{"b", "a"} {
+		labels := map[string]string{"app": "rook-ceph-rgw", "rgw": name}
+		objects = append(objects,
+			&apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-rgw-" + name, Namespace: "rook-ceph", Labels: labels},
+				Spec:       apps.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+				Status:     apps.DeploymentStatus{ReadyReplicas: 1},
+			},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-rgw-" + name + "-1234", Namespace: "rook-ceph", Labels: labels}})
+	}
+	clientset := k8sfake.NewSimpleClientset(objects...)
+	refused := false
+	evicted := []string{}
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if refused {
+			return true, nil, kerrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		evicted = append(evicted, eviction.Name)
+		return true, nil, clientset.Tracker().Delete(action.GetResource(), eviction.Namespace, eviction.Name)
+	})
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			return "{}", nil
+		},
+	}
+	context := &clusterd.Context{Clientset: clientset, Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	daemons := []RestartDaemon{{Type: "rgw", Selector: "app=rook-ceph-rgw", IDLabel: "rgw"}}
+	restartNext := func() bool {
+		assert.NoError(t, cl.Get(ctx.TODO(), types.NamespacedName{Name: "store", Namespace: "rook-ceph"}, store))
+		done, err := RestartNextDaemon(context, clusterInfo, cl, store, daemons)
+		assert.NoError(t, err)
+		return done
+	}
+
+	// the eviction refused by the disruption budget is tried again at the next reconcile
+	refused = true
+	assert.False(t, restartNext())
+	assert.Empty(t, evicted)
+	assert.Empty(t, store.Annotations[RestartProgressAnnotation])
+
+	// a single daemon is restarted at each reconcile, in the order of the deployments
+	refused = false
+	assert.False(t, restartNext())
+	assert.Equal(t, []string{"rook-ceph-rgw-a-1234"}, evicted)
+	assert.False(t, restartNext())
+	assert.Equal(t, "rgw@1/rgw/rook-ceph-rgw-a", store.Annotations[RestartProgressAnnotation])
+	assert.Equal(t, []string{"rook-ceph-rgw-a-1234", "rook-ceph-rgw-b-1234"}, evicted)
+	assert.True(t, restartNext())
+	assert.Equal(t, "rgw@1/rgw/rook-ceph-rgw-b", store.Annotations[RestartProgressAnnotation])
+
+	// the progress is removed once the request is completed
+	assert.NoError(t, CompleteRestart(cl, nil, store))
+	assert.NoError(t, cl.Get(ctx.TODO(), types.NamespacedName{Name: "store", Namespace: "rook-ceph"}, store))
+	assert.Equal(t, "rgw@1", store.Annotations[RestartedAnnotation])
+	_, ok := store.Annotations[RestartProgressAnnotation]
+	assert.False(t, ok)
+
+	// the progress of another request is ignored
+	daemonType, deployment := parseRestartProgress("rgw@1/rgw/rook-ceph-rgw-b", "rgw@2")
+	assert.Empty(t, daemonType)
+	assert.Empty(t, deployment)
+}
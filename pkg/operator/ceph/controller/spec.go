@@ -38,6 +38,7 @@ const (
 	logVolumeName                         = "rook-ceph-log"
 	volumeMountSubPath                    = "data"
 	crashVolumeName                       = "rook-ceph-crash"
+	socketVolumeName                      = "rook-ceph-exporter"
 	daemonSocketDir                       = "/run/ceph"
 	initialDelaySecondsNonOSDDaemon int32 = 10
 	initialDelaySecondsOSDDaemon    int32 = 45
@@ -116,6 +117,9 @@ func PodVolumes(dataPaths *config.DataPathMap, dataDirHostPath string, confGener
 		configVolume,
 	}
 	v = append(v, StoredLogAndCrashVolume(dataPaths.HostLogDir(), dataPaths.HostCrashDir())...)
+	if dataPaths.HostSocketDir != "" {
+		v = append(v, StoredSocketVolume(dataPaths.HostSocketDir))
+	}
 
 	return v
 }
@@ -134,6 +138,9 @@ func CephVolumeMounts(dataPaths *config.DataPathMap, confGeneratedInPod bool) []
 		// Rook doesn't run in ceph containers, so it doesn't need the config override mounted
 	}
 	v = append(v, StoredLogAndCrashVolumeMount(dataPaths.ContainerLogDir(), dataPaths.ContainerCrashDir())...)
+	if dataPaths.HostSocketDir != "" {
+		v = append(v, StoredSocketVolumeMount())
+	}
 
 	return v
 }
@@ -159,6 +166,9 @@ func DaemonVolumesBase(dataPaths *config.DataPathMap, keyringResourceName string
 		// logs are not persisted to host
 		vols = append(vols, StoredLogAndCrashVolume(dataPaths.HostLogDir(), dataPaths.HostCrashDir())...)
 	}
+	if dataPaths.HostSocketDir != "" {
+		vols = append(vols, StoredSocketVolume(dataPaths.HostSocketDir))
+	}
 	return vols
 }
 
@@ -225,6 +235,9 @@ func DaemonVolumeMounts(dataPaths *config.DataPathMap, keyringResourceName strin
 		// logs are not persisted to host, so no mount is needed
 		mounts = append(mounts, StoredLogAndCrashVolumeMount(dataPaths.ContainerLogDir(), dataPaths.ContainerCrashDir())...)
 	}
+	if dataPaths.HostSocketDir != "" {
+		mounts = append(mounts, StoredSocketVolumeMount())
+	}
 	if dataPaths.ContainerDataDir == "" {
 		// no data is stored in container, so there are no more mounts
 		return mounts
@@ -366,7 +379,7 @@ func ChownCephDataDirsInitContainer(
 	resources v1.ResourceRequirements,
 	securityContext *v1.SecurityContext,
 ) v1.Container {
-	args := make([]string, 0, 5)
+	args := make([]string, 0, 6)
 	args = append(args,
 		"--verbose",
 		"--recursive",
@@ -374,6 +387,9 @@ func ChownCephDataDirsInitContainer(
 		config.VarLogCephDir,
 		config.VarLibCephCrashDir,
 	)
+	if dpm.HostSocketDir != "" {
+		args = append(args, daemonSocketDir)
+	}
 	if dpm.ContainerDataDir != "" {
 		args = append(args, dpm.ContainerDataDir)
 	}
@@ -465,6 +481,24 @@ func StoredLogAndCrashVolumeMount(varLogCephDir, varLibCephCrashDir string) []v1
 	}
 }
 
+// StoredSocketVolume returns a pod volume sourced from the directory of the admin sockets of the daemons on the host.
+func StoredSocketVolume(hostSocketDir string) v1.Volume {
+	return v1.Volume{
+		Name: socketVolumeName,
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{Path: hostSocketDir},
+		},
+	}
+}
+
+// StoredSocketVolumeMount returns the mount of the directory of the admin sockets of the daemons.
+func StoredSocketVolumeMount() v1.VolumeMount {
+	return v1.VolumeMount{
+		Name:      socketVolumeName,
+		MountPath: daemonSocketDir,
+	}
+}
+
 // GenerateLivenessProbeExecDaemon makes sure a daemon has a socket and that it can be called and returns 0
 func GenerateLivenessProbeExecDaemon(daemonType, daemonID string) *v1.Probe {
 	confDaemon := getDaemonConfig(daemonType, daemonID)
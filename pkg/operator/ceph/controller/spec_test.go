@@ -40,6 +40,28 @@ func TestPodVolumes(t *testing.T) {
 	}
 }
 
+func TestSocketVolume(t *testing.T) {
+	hasSocketVolume := func(vols []v1.Volume) bool {
+		for _, vol := range vols {
+			if vol.Name == socketVolumeName {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the sockets are not shared when the exporter is disabled
+	dataPathMap := opconfig.NewStatelessDaemonDataPathMap(opconfig.MgrType, "a", "rook-ceph", "/var/lib/rook").WithHostSocketDir(false)
+	assert.False(t, hasSocketVolume(DaemonVolumes(dataPathMap, "")))
+	assert.NotContains(t, ChownCephDataDirsInitContainer(*dataPathMap, "image", nil, v1.ResourceRequirements{}, nil).Args, daemonSocketDir)
+
+	dataPathMap.WithHostSocketDir(true)
+	assert.Equal(t, "/var/lib/rook/rook-ceph/exporter", dataPathMap.HostSocketDir)
+	assert.True(t, hasSocketVolume(DaemonVolumes(dataPathMap, "")))
+	assert.Contains(t, DaemonVolumeMounts(dataPathMap, ""), StoredSocketVolumeMount())
+	assert.Contains(t, ChownCephDataDirsInitContainer(*dataPathMap, "image", nil, v1.ResourceRequirements{}, nil).Args, daemonSocketDir)
+}
+
 func TestMountsMatchVolumes(t *testing.T) {
 
 	dataPathMap := opconfig.NewDatalessDaemonDataPathMap("rook-ceph", "/var/lib/rook")
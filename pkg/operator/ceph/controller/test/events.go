@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package test builds the objects and the events of the tests of the controller predicates
+package test
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	rookscheme "github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// InitObject sets the type and the metadata of a ceph CR or of a child resource as the API server does when the
+// object is created, with a random UID and the first resource version and generation
+func InitObject(t testing.TB, obj runtime.Object, name, namespace string) {
+	gvk, err := apiutil.GVKForObject(obj, rookscheme.Scheme)
+	if err != nil {
+		if gvk, err = apiutil.GVKForObject(obj, kubescheme.Scheme); err != nil {
+			t.Fatalf("unknown type %T. %v", obj, err)
+		}
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	objectMeta := accessor(t, obj)
+	objectMeta.SetName(name)
+	objectMeta.SetNamespace(namespace)
+	objectMeta.SetUID(uuid.NewUUID())
+	objectMeta.SetResourceVersion("1")
+	objectMeta.SetGeneration(1)
+	objectMeta.SetCreationTimestamp(metav1.NewTime(time.Now()))
+}
+
+// SetControllerOwner sets the owner as the controller of the child, like the controllers do for the resources of the
+// CRs. The owner must be initialized with InitObject.
+func SetControllerOwner(t testing.TB, child, owner runtime.Object) {
+	ownerMeta := accessor(t, owner)
+	ownerRef := metav1.NewControllerRef(ownerMeta, owner.GetObjectKind().GroupVersionKind())
+	childMeta := accessor(t, child)
+	childMeta.SetOwnerReferences(append(childMeta.GetOwnerReferences(), *ownerRef))
+}
+
+// CreateEvent returns the event of the creation of the object
+func CreateEvent(t testing.TB, obj runtime.Object) event.CreateEvent {
+	return event.CreateEvent{Meta: accessor(t, obj), Object: obj}
+}
+
+// UpdateEvent returns the event of an update of the object by the function. The resource version of the updated
+// object is incremented, and its generation too when its spec changed, like the API server does.
+func UpdateEvent(t testing.TB, obj runtime.Object, update func(newObj runtime.Object)) event.UpdateEvent {
+	newObj := obj.DeepCopyObject()
+	update(newObj)
+
+	oldMeta := accessor(t, obj)
+	newMeta := accessor(t, newObj)
+	version, err := strconv.Atoi(oldMeta.GetResourceVersion())
+	if err != nil {
+		version = 0
+	}
+	newMeta.SetResourceVersion(strconv.Itoa(version + 1))
+	oldSpec, hasSpec := spec(t, obj)
+	newSpec, _ := spec(t, newObj)
+	if hasSpec && !reflect.DeepEqual(oldSpec, newSpec) {
+		newMeta.SetGeneration(oldMeta.GetGeneration() + 1)
+	}
+	return event.UpdateEvent{MetaOld: oldMeta, ObjectOld: obj, MetaNew: newMeta, ObjectNew: newObj}
+}
+
+// DeletionUpdateEvent returns the event of the update of the object when its deletion is requested, before its
+// finalizers are removed
+func DeletionUpdateEvent(t testing.TB, obj runtime.Object) event.UpdateEvent {
+	return UpdateEvent(t, obj, func(newObj runtime.Object) {
+		now := metav1.NewTime(time.Now())
+		accessor(t, newObj).SetDeletionTimestamp(&now)
+	})
+}
+
+// DeleteEvent returns the event of the deletion of the object
+func DeleteEvent(t testing.TB, obj runtime.Object) event.DeleteEvent {
+	return event.DeleteEvent{Meta: accessor(t, obj), Object: obj}
+}
+
+// GenericEvent returns a generic event of the object, like the events of the channel sources
+func GenericEvent(t testing.TB, obj runtime.Object) event.GenericEvent {
+	return event.GenericEvent{Meta: accessor(t, obj), Object: obj}
+}
+
+func accessor(t testing.TB, obj runtime.Object) metav1.Object {
+	objectMeta, err := meta.Accessor(obj)
+	if err != nil {
+		t.Fatalf("failed to access the metadata of %T. %v", obj, err)
+	}
+	return objectMeta
+}
+
+// spec returns the spec of the object, the resources without spec like the config maps have no generation
+func spec(t testing.TB, obj runtime.Object) (interface{}, bool) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatalf("failed to convert %T. %v", obj, err)
+	}
+	s, ok := content["spec"]
+	return s, ok
+}
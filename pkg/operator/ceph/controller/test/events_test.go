@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestEvents(t *testing.T) {
+	pool := &cephv1.CephBlockPool{}
+	InitObject(t, pool, "replicapool", "rook-ceph")
+	assert.Equal(t, "CephBlockPool", pool.Kind)
+	assert.Equal(t, "ceph.rook.io/v1", pool.APIVersion)
+	assert.NotEmpty(t, pool.UID)
+	assert.Equal(t, int64(1), pool.Generation)
+
+	secret := &corev1.Secret{}
+	InitObject(t, secret, "pool-peer-token", "rook-ceph")
+	assert.Equal(t, "Secret", secret.Kind)
+	SetControllerOwner(t, secret, pool)
+	assert.Equal(t, pool.UID, secret.OwnerReferences[0].UID)
+	assert.Equal(t, "CephBlockPool", secret.OwnerReferences[0].Kind)
+	assert.True(t, *secret.OwnerReferences[0].Controller)
+
+	// the generation changes with the spec
+	e := UpdateEvent(t, pool, func(newObj runtime.Object) {
+		newObj.(*cephv1.CephBlockPool).Spec.Replicated.Size = 3
+	})
+	assert.Equal(t, "2", e.MetaNew.GetResourceVersion())
+	assert.Equal(t, int64(2), e.MetaNew.GetGeneration())
+	assert.Equal(t, uint(0), e.ObjectOld.(*cephv1.CephBlockPool).Spec.Replicated.Size)
+	e = UpdateEvent(t, pool, func(newObj runtime.Object) {
+		newObj.(*cephv1.CephBlockPool).Status = &cephv1.CephBlockPoolStatus{Phase: "Ready"}
+	})
+	assert.Equal(t, int64(1), e.MetaNew.GetGeneration())
+
+	// the resources without spec have no generation
+	e = UpdateEvent(t, secret, func(newObj runtime.Object) {
+		newObj.(*corev1.Secret).Data = map[string][]byte{"token": []byte("abc")}
+	})
+	assert.Equal(t, int64(1), e.MetaNew.GetGeneration())
+
+	e = DeletionUpdateEvent(t, pool)
+	assert.Nil(t, e.MetaOld.GetDeletionTimestamp())
+	assert.NotNil(t, e.MetaNew.GetDeletionTimestamp())
+	assert.Equal(t, "replicapool", CreateEvent(t, pool).Meta.GetName())
+	assert.Equal(t, pool, DeleteEvent(t, pool).Object)
+	assert.Equal(t, pool, GenericEvent(t, pool).Object)
+}
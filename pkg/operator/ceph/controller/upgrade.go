@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrUpgradeCancelled is returned when the image of the cluster changed while its daemons were upgraded
+	ErrUpgradeCancelled = errors.New("upgrade cancelled")
+
+	// the apps of the daemons which are updated one at a time during an upgrade
+	upgradedApps = []string{"rook-ceph-mon", "rook-ceph-mgr", "rook-ceph-osd", "rook-ceph-mds", "rook-ceph-rgw", "rook-ceph-rbd-mirror", "rook-ceph-nfs"}
+)
+
+// CheckUpgradeCancelled returns ErrUpgradeCancelled when the image of the CephCluster changed since its upgrade
+// started. It is checked before each daemon is updated so that the upgrade stops between two daemons, the next
+// orchestration rolls out the new image.
+func CheckUpgradeCancelled(context *clusterd.Context, namespace string) error {
+	cephCluster := upgradingCephCluster(context, namespace)
+	if cephCluster == nil || cephCluster.Spec.CephVersion.Image == cephCluster.Status.Upgrade.Image {
+		return nil
+	}
+	return errors.Wrapf(ErrUpgradeCancelled, "the ceph image changed from %q to %q during the upgrade", cephCluster.Status.Upgrade.Image, cephCluster.Spec.CephVersion.Image)
+}
+
+// ReportUpgradeProgress counts the daemons of the namespace running the image of the upgrade in progress and updates
+// the upgrade status of the CephCluster after a daemon was updated. The upgrade is completed when no daemon remains.
+func ReportUpgradeProgress(context *clusterd.Context, namespace, daemonType string, updateErr error) {
+	cephCluster := upgradingCephCluster(context, namespace)
+	if cephCluster == nil {
+		return
+	}
+
+	upgrade := cephCluster.Status.Upgrade
+	done, remaining, err := UpgradeProgress(context.Clientset, namespace, upgrade.Image)
+	if err != nil {
+		logger.Warningf("failed to count the upgraded daemons in namespace %q. %v", namespace, err)
+		return
+	}
+	upgrade.DaemonsDone = done
+	upgrade.DaemonsRemaining = remaining
+	if daemonType != "" {
+		upgrade.DaemonType = daemonType
+	}
+	upgrade.LastError = ""
+	if updateErr != nil {
+		upgrade.LastError = updateErr.Error()
+	}
+	if remaining == 0 && updateErr == nil {
+		upgrade.State = cephv1.UpgradeCompleted
+		upgrade.DaemonType = ""
+	}
+	upgrade.LastUpdated = time.Now().UTC().Format(time.RFC3339)
+	if err := UpdateStatus(context.Client, cephCluster); err != nil {
+		logger.Warningf("failed to update the upgrade status of cluster %q. %v", cephCluster.Name, err)
+	}
+}
+
+// UpgradeProgress returns the number of daemons of the namespace which run the image and the number of daemons which
+// remain to be upgraded
+func UpgradeProgress(clientset kubernetes.Interface, namespace, image string) (int, int, error) {
+	selector := fmt.Sprintf("%s=%s,%s in (%s)", k8sutil.ClusterAttr, namespace, k8sutil.AppAttr, strings.Join(upgradedApps, ","))
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to list the daemon deployments")
+	}
+	done, remaining := 0, 0
+	for _, d := range deployments.Items {
+		if isDeploymentUpgraded(d, image) {
+			done++
+		} else {
+			remaining++
+		}
+	}
+	return done, remaining, nil
+}
+
+// isDeploymentUpgraded returns whether the deployment runs the image and finished its rollout
+func isDeploymentUpgraded(d apps.Deployment, image string) bool {
+	containers := d.Spec.Template.Spec.Containers
+	if len(containers) == 0 || containers[0].Image != image {
+		return false
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation && d.Status.UpdatedReplicas >= replicas
+}
+
+// upgradingCephCluster returns the CephCluster of the namespace if its upgrade is in progress. The upgrade status is
+// best effort, nil is also returned when the cluster cannot be read.
+func upgradingCephCluster(clusterdContext *clusterd.Context, namespace string) *cephv1.CephCluster {
+	if clusterdContext.Client == nil {
+		return nil
+	}
+	clusters := &cephv1.CephClusterList{}
+	if err := clusterdContext.Client.List(context.TODO(), clusters, client.InNamespace(namespace)); err != nil {
+		logger.Debugf("failed to list the ceph clusters in namespace %q. %v", namespace, err)
+		return nil
+	}
+	cephCluster := NamespaceCephCluster(clusters.Items)
+	if cephCluster == nil || cephCluster.Status.Upgrade == nil || cephCluster.Status.Upgrade.State != cephv1.UpgradeInProgress {
+		return nil
+	}
+	return cephCluster
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func daemonDeployment(name, app, image string, upgraded bool) *apps.Deployment {
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "rook-ceph",
+			Generation: 2,
+			Labels:     map[string]string{"app": app, "rook_cluster": "rook-ceph"},
+		},
+		Spec: apps.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "daemon", Image: image}},
+		}}},
+	}
+	if upgraded {
+		d.Status = apps.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1}
+	}
+	return d
+}
+
+func TestUpgradeProgress(t *testing.T) {
+	clientset := k8sfake.NewSimpleClientset(
+		daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "ceph/ceph:v15", true),
+		// the rollout of the new image is not finished
+		daemonDeployment("rook-ceph-mon-b", "rook-ceph-mon", "ceph/ceph:v15", false),
+		daemonDeployment("rook-ceph-osd-0", "rook-ceph-osd", "ceph/ceph:v14", true),
+		// the other deployments are not counted
+		daemonDeployment("rook-ceph-crashcollector-node1", "rook-ceph-crashcollector", "ceph/ceph:v14", true),
+	)
+
+	done, remaining, err := UpgradeProgress(clientset, "rook-ceph", "ceph/ceph:v15")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, done)
+	assert.Equal(t, 2, remaining)
+}
+
+func TestUpgradeStatusLifecycle(t *testing.T) {
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"},
+		Spec:       cephv1.ClusterSpec{CephVersion: cephv1.CephVersionSpec{Image: "ceph/ceph:v15"}},
+		Status: cephv1.ClusterStatus{Upgrade: &cephv1.UpgradeStatus{
+			State: cephv1.UpgradeInProgress, Image: "ceph/ceph:v15", PreviousImage: "ceph/ceph:v14",
+		}},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCluster{}, &cephv1.CephClusterList{})
+	cl := fake.NewFakeClientWithScheme(s, cephCluster)
+	clientset := k8sfake.NewSimpleClientset(
+		daemonDeployment("rook-ceph-mon-a", "rook-ceph-mon", "ceph/ceph:v15", true),
+		daemonDeployment("rook-ceph-osd-0", "rook-ceph-osd", "ceph/ceph:v14", true),
+	)
+	clusterdContext := &clusterd.Context{Client: cl, Clientset: clientset}
+	name := types.NamespacedName{Name: "my-cluster", Namespace: "rook-ceph"}
+	getUpgrade := func() *cephv1.UpgradeStatus {
+		c := &cephv1.CephCluster{}
+		assert.NoError(t, cl.Get(context.TODO(), name, c))
+		return c.Status.Upgrade
+	}
+
+	// the progress and the last error of the daemons are reported
+	ReportUpgradeProgress(clusterdContext, "rook-ceph", "osd", errors.New("osd.0 is not ok to stop"))
+	upgrade := getUpgrade()
+	assert.Equal(t, cephv1.UpgradeInProgress, upgrade.State)
+	assert.Equal(t, "osd", upgrade.DaemonType)
+	assert.Equal(t, 1, upgrade.DaemonsDone)
+	assert.Equal(t, 1, upgrade.DaemonsRemaining)
+	assert.Equal(t, "osd.0 is not ok to stop", upgrade.LastError)
+
+	// the upgrade goes on while the image is unchanged
+	assert.NoError(t, CheckUpgradeCancelled(clusterdContext, "rook-ceph"))
+
+	// the upgrade is cancelled when the image changes
+	c := &cephv1.CephCluster{}
+	assert.NoError(t, cl.Get(context.TODO(), name, c))
+	c.Spec.CephVersion.Image = "ceph/ceph:v14"
+	assert.NoError(t, cl.Update(context.TODO(), c))
+	err := CheckUpgradeCancelled(clusterdContext, "rook-ceph")
+	assert.Equal(t, ErrUpgradeCancelled, errors.Cause(err))
+
+	// the upgrade completes when no daemon remains
+	clientset.AppsV1().Deployments("rook-ceph").Update(daemonDeployment("rook-ceph-osd-0", "rook-ceph-osd", "ceph/ceph:v15", true))
+	ReportUpgradeProgress(clusterdContext, "rook-ceph", "osd", nil)
+	upgrade = getUpgrade()
+	assert.Equal(t, cephv1.UpgradeCompleted, upgrade.State)
+	assert.Equal(t, 2, upgrade.DaemonsDone)
+	assert.Equal(t, 0, upgrade.DaemonsRemaining)
+	assert.Empty(t, upgrade.LastError)
+
+	// nothing is checked once the upgrade is completed or without a client
+	assert.NoError(t, CheckUpgradeCancelled(clusterdContext, "rook-ceph"))
+	assert.NoError(t, CheckUpgradeCancelled(&clusterd.Context{}, "rook-ceph"))
+}
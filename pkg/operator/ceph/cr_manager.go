@@ -19,19 +19,31 @@ package operator
 import (
 	"github.com/pkg/errors"
 	"github.com/rook/rook/pkg/operator/ceph/cluster"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
 
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
-func (o *Operator) startManager(namespaceToWatch string, stopCh <-chan struct{},
+func (o *Operator) startManager(namespacesToWatch []string, stopCh <-chan struct{},
 	mgrErrorCh chan error) {
 	// Set up a manager
 	mgrOpts := manager.Options{
-		LeaderElection: false,
-		Namespace:      namespaceToWatch,
+		LeaderElection:     false,
+		MetricsBindAddress: MetricsBindAddress,
 	}
+	// a single namespace is watched by the cache of the manager, several namespaces by a cache per namespace
+	if len(namespacesToWatch) == 1 {
+		mgrOpts.Namespace = namespacesToWatch[0]
+	}
+
+	newCache, err := opcontroller.NewCacheFunc(opcontroller.CacheLabelSelector, namespacesToWatch)
+	if err != nil {
+		mgrErrorCh <- err
+		return
+	}
+	mgrOpts.NewCache = newCache
 
 	logger.Info("setting up the controller-runtime manager")
 	kubeConfig, err := config.GetConfig()
@@ -23,6 +23,7 @@ import (
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
@@ -33,11 +34,43 @@ import (
 
 var (
 	logger = capnslog.NewPackageLogger("github.com/rook/rook", "ceph-csi")
+
+	// defaultCrushLocationLabels are the node labels Rook uses to build the CRUSH location of the OSDs,
+	// see the topology labels in the osd package
+	defaultCrushLocationLabels = []string{
+		v1.LabelHostname,
+		"topology.kubernetes.io/region",
+		"topology.kubernetes.io/zone",
+		k8sutil.TopologyLabelPrefix + "chassis",
+		k8sutil.TopologyLabelPrefix + "rack",
+		k8sutil.TopologyLabelPrefix + "row",
+		k8sutil.TopologyLabelPrefix + "pdu",
+		k8sutil.TopologyLabelPrefix + "pod",
+		k8sutil.TopologyLabelPrefix + "room",
+		k8sutil.TopologyLabelPrefix + "datacenter",
+	}
+
+	// ConfigMutex serializes the updates of the csi config map by the controllers of the clusters and of the
+	// rados namespaces
+	ConfigMutex = &sync.Mutex{}
 )
 
 type csiClusterConfigEntry struct {
-	ClusterID string   `json:"clusterID"`
-	Monitors  []string `json:"monitors"`
+	ClusterID    string           `json:"clusterID"`
+	Monitors     []string         `json:"monitors"`
+	ReadAffinity *csiReadAffinity `json:"readAffinity,omitempty"`
+	// RadosNamespace is the RADOS namespace of the RBD images of the clusterID
+	RadosNamespace string `json:"radosNamespace,omitempty"`
+	// Namespace is the namespace of the CephCluster of the entries of the rados namespaces, their monitors are
+	// updated with the monitors of the cluster
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// csiReadAffinity configures the nodeplugin to read RBD volumes from the closest OSD. The nodeplugin
+// builds the CRUSH location of its node from the values of the given labels on the node.
+type csiReadAffinity struct {
+	Enabled             bool     `json:"enabled"`
+	CrushLocationLabels []string `json:"crushLocationLabels,omitempty"`
 }
 
 type csiClusterConfig []csiClusterConfigEntry
@@ -86,10 +119,21 @@ func monEndpoints(mons map[string]*cephclient.MonInfo) []string {
 	return endpoints
 }
 
+func readAffinity(csiSpec *cephv1.CSIDriverSpec) *csiReadAffinity {
+	if csiSpec == nil || !csiSpec.ReadAffinity.Enabled {
+		return nil
+	}
+	labels := csiSpec.ReadAffinity.CrushLocationLabels
+	if len(labels) == 0 {
+		labels = defaultCrushLocationLabels
+	}
+	return &csiReadAffinity{Enabled: true, CrushLocationLabels: labels}
+}
+
 // UpdateCsiClusterConfig returns a json-formatted string containing
 // the cluster-to-mon mapping required to configure ceph csi.
 func UpdateCsiClusterConfig(
-	curr, clusterKey string, mons map[string]*cephclient.MonInfo) (string, error) {
+	curr, clusterKey string, mons map[string]*cephclient.MonInfo, csiSpec *cephv1.CSIDriverSpec) (string, error) {
 
 	var (
 		cc     csiClusterConfig
@@ -104,14 +148,20 @@ func UpdateCsiClusterConfig(
 	for i, centry := range cc {
 		if centry.ClusterID == clusterKey {
 			centry.Monitors = monEndpoints(mons)
+			centry.ReadAffinity = readAffinity(csiSpec)
 			found = true
 			cc[i] = centry
-			break
+		} else if centry.Namespace == clusterKey {
+			// the rados namespaces of the cluster
+			centry.Monitors = monEndpoints(mons)
+			centry.ReadAffinity = readAffinity(csiSpec)
+			cc[i] = centry
 		}
 	}
 	if !found {
 		centry.ClusterID = clusterKey
 		centry.Monitors = monEndpoints(mons)
+		centry.ReadAffinity = readAffinity(csiSpec)
 		cc = append(cc, centry)
 	}
 	return formatCsiClusterConfig(cc)
@@ -148,15 +198,89 @@ func CreateCsiConfigMap(namespace string, clientset kubernetes.Interface, ownerR
 // used to determine what "cluster" in the config map will be updated and
 // and the clusterNamespace value is expected to match the clusterID
 // value that is provided to ceph-csi uses in the storage class.
+// The csiSpec holds the CSI settings of the cluster such as the read affinity.
 // The locker l is typically a mutex and is used to prevent the config
 // map from being updated for multiple clusters simultaneously.
 func SaveClusterConfig(
 	clientset kubernetes.Interface, clusterNamespace string,
-	clusterInfo *cephclient.ClusterInfo, l sync.Locker) error {
+	clusterInfo *cephclient.ClusterInfo, csiSpec *cephv1.CSIDriverSpec, l sync.Locker) error {
 
 	if !CSIEnabled() {
 		return nil
 	}
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		return UpdateCsiClusterConfig(currData, clusterNamespace, clusterInfo.Monitors, csiSpec)
+	})
+}
+
+// UpdateCsiRadosNamespaceConfig returns the json-formatted csi cluster config with the entry of the clusterID of a
+// RADOS namespace of the cluster in clusterNamespace
+func UpdateCsiRadosNamespaceConfig(
+	curr, clusterID, clusterNamespace, radosNamespace string, mons map[string]*cephclient.MonInfo, csiSpec *cephv1.CSIDriverSpec) (string, error) {
+
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	centry := csiClusterConfigEntry{
+		ClusterID:      clusterID,
+		Monitors:       monEndpoints(mons),
+		ReadAffinity:   readAffinity(csiSpec),
+		RadosNamespace: radosNamespace,
+		Namespace:      clusterNamespace,
+	}
+	for i := range cc {
+		if cc[i].ClusterID == clusterID {
+			cc[i] = centry
+			return formatCsiClusterConfig(cc)
+		}
+	}
+	return formatCsiClusterConfig(append(cc, centry))
+}
+
+// RemoveCsiClusterConfig returns the json-formatted csi cluster config without the entry of the clusterID
+func RemoveCsiClusterConfig(curr, clusterID string) (string, error) {
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	entries := csiClusterConfig{}
+	for _, centry := range cc {
+		if centry.ClusterID != clusterID {
+			entries = append(entries, centry)
+		}
+	}
+	return formatCsiClusterConfig(entries)
+}
+
+// SaveRadosNamespaceConfig adds or updates the entry of the clusterID of a RADOS namespace in the config map of
+// ceph-csi. The monitors of the entry are the monitors of the cluster in clusterNamespace.
+func SaveRadosNamespaceConfig(
+	clientset kubernetes.Interface, clusterID, clusterNamespace, radosNamespace string,
+	clusterInfo *cephclient.ClusterInfo, csiSpec *cephv1.CSIDriverSpec, l sync.Locker) error {
+
+	if !CSIEnabled() {
+		return nil
+	}
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		return UpdateCsiRadosNamespaceConfig(currData, clusterID, clusterNamespace, radosNamespace, clusterInfo.Monitors, csiSpec)
+	})
+}
+
+// RemoveRadosNamespaceConfig removes the entry of the clusterID of a RADOS namespace from the config map of ceph-csi
+func RemoveRadosNamespaceConfig(clientset kubernetes.Interface, clusterID string, l sync.Locker) error {
+	if !CSIEnabled() {
+		return nil
+	}
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		return RemoveCsiClusterConfig(currData, clusterID)
+	})
+}
+
+// updateCsiConfigMap updates the contents of the config map of ceph-csi with the update function
+func updateCsiConfigMap(clientset kubernetes.Interface, l sync.Locker, update func(currData string) (string, error)) error {
 	l.Lock()
 	defer l.Unlock()
 	// csi is deployed into the same namespace as the operator
@@ -178,17 +302,17 @@ func SaveClusterConfig(
 	if currData == "" {
 		currData = "[]"
 	}
-	newData, err := UpdateCsiClusterConfig(
-		currData, clusterNamespace, clusterInfo.Monitors)
+	newData, err := update(currData)
 	if err != nil {
 		return errors.Wrap(err, "failed to update csi config map data")
 	}
 	configMap.Data[ConfigKey] = newData
 
 	// update ConfigMap with new contents
-	if _, err := clientset.CoreV1().ConfigMaps(csiNamespace).Update(configMap); err != nil {
+	if _, err := k8sutil.UpdateConfigMap(clientset, configMap); err != nil {
 		return errors.Wrapf(err, "failed to update csi config map")
 	}
 
-	return nil
+	// the nodeplugin builds the crush location of its node for the clusters with read affinity
+	return updatePluginReadAffinity(clientset, csiNamespace, newData)
 }
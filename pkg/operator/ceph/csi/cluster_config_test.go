@@ -19,6 +19,7 @@ package csi
 import (
 	"testing"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/stretchr/testify/assert"
 )
@@ -28,7 +29,7 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 	mons := map[string]*cephclient.MonInfo{
 		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
 	}
-	s, err := UpdateCsiClusterConfig("[]", "alpha", mons)
+	s, err := UpdateCsiClusterConfig("[]", "alpha", mons, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, s,
 		`[{"clusterID":"alpha","monitors":["1.2.3.4:5000"]}]`)
@@ -36,7 +37,7 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 	// add a 2nd mon to the current cluster
 	mons["bar"] = &cephclient.MonInfo{
 		Name: "bar", Endpoint: "10.11.12.13:5000"}
-	s, err = UpdateCsiClusterConfig(s, "alpha", mons)
+	s, err = UpdateCsiClusterConfig(s, "alpha", mons, nil)
 	assert.NoError(t, err)
 	cc, err := parseCsiClusterConfig(s)
 	assert.NoError(t, err)
@@ -52,7 +53,7 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 		"flam": {Name: "flam", Endpoint: "20.1.1.2:5000"},
 		"blam": {Name: "blam", Endpoint: "20.1.1.3:5000"},
 	}
-	s, err = UpdateCsiClusterConfig(s, "beta", mons2)
+	s, err = UpdateCsiClusterConfig(s, "beta", mons2, nil)
 	assert.NoError(t, err)
 	cc, err = parseCsiClusterConfig(s)
 	assert.NoError(t, err)
@@ -69,7 +70,7 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 
 	// remove a mon from the 2nd cluster
 	delete(mons2, "blam")
-	s, err = UpdateCsiClusterConfig(s, "beta", mons2)
+	s, err = UpdateCsiClusterConfig(s, "beta", mons2, nil)
 	assert.NoError(t, err)
 	cc, err = parseCsiClusterConfig(s)
 	assert.NoError(t, err)
@@ -84,6 +85,73 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 	assert.Equal(t, len(cc[1].Monitors), 2)
 
 	// does it return error on garbage input?
-	_, err = UpdateCsiClusterConfig("qqq", "beta", mons2)
+	_, err = UpdateCsiClusterConfig("qqq", "beta", mons2, nil)
 	assert.Error(t, err)
 }
+
+func TestUpdateCsiClusterConfigReadAffinity(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
+	}
+
+	// read affinity with the default labels
+	csiSpec := &cephv1.CSIDriverSpec{ReadAffinity: cephv1.ReadAffinitySpec{Enabled: true}}
+	s, err := UpdateCsiClusterConfig("[]", "alpha", mons, csiSpec)
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.True(t, cc[0].ReadAffinity.Enabled)
+	assert.Equal(t, defaultCrushLocationLabels, cc[0].ReadAffinity.CrushLocationLabels)
+
+	// custom labels
+	csiSpec.ReadAffinity.CrushLocationLabels = []string{"topology.kubernetes.io/zone"}
+	s, err = UpdateCsiClusterConfig(s, "alpha", mons, csiSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, s,
+		`[{"clusterID":"alpha","monitors":["1.2.3.4:5000"],"readAffinity":{"enabled":true,"crushLocationLabels":["topology.kubernetes.io/zone"]}}]`)
+
+	// disabling read affinity removes the setting
+	s, err = UpdateCsiClusterConfig(s, "alpha", mons, &cephv1.CSIDriverSpec{})
+	assert.NoError(t, err)
+	assert.Equal(t, s, `[{"clusterID":"alpha","monitors":["1.2.3.4:5000"]}]`)
+}
+
+func TestUpdateCsiRadosNamespaceConfig(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
+	}
+	s, err := UpdateCsiClusterConfig("[]", "rook-ceph", mons, nil)
+	assert.NoError(t, err)
+
+	// the namespace has its own clusterID with the monitors of the cluster
+	s, err = UpdateCsiRadosNamespaceConfig(s, "ns-id", "rook-ceph", "tenant-a", mons, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, s,
+		`[{"clusterID":"rook-ceph","monitors":["1.2.3.4:5000"]},{"clusterID":"ns-id","monitors":["1.2.3.4:5000"],"radosNamespace":"tenant-a","namespace":"rook-ceph"}]`)
+
+	// the monitors of the namespace are updated with the monitors of the cluster
+	mons["bar"] = &cephclient.MonInfo{Name: "bar", Endpoint: "10.11.12.13:5000"}
+	s, err = UpdateCsiClusterConfig(s, "rook-ceph", mons, nil)
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, len(cc), 2)
+	assert.ElementsMatch(t, cc[1].Monitors, []string{"1.2.3.4:5000", "10.11.12.13:5000"})
+	assert.Equal(t, cc[1].RadosNamespace, "tenant-a")
+
+	// the namespaces of the other clusters are not updated
+	s, err = UpdateCsiClusterConfig(s, "beta", map[string]*cephclient.MonInfo{"flim": {Name: "flim", Endpoint: "20.1.1.1:5000"}}, nil)
+	assert.NoError(t, err)
+	cc, err = parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, cc[1].Monitors, []string{"1.2.3.4:5000", "10.11.12.13:5000"})
+
+	// removing the namespace keeps the clusters
+	s, err = RemoveCsiClusterConfig(s, "ns-id")
+	assert.NoError(t, err)
+	cc, err = parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, len(cc), 2)
+	assert.Equal(t, cc[0].ClusterID, "rook-ceph")
+	assert.Equal(t, cc[1].ClusterID, "beta")
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"os"
+	"path"
+
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	monitoringPath        = "/etc/ceph-monitoring/"
+	serviceMonitorFile    = "csi-metrics-service-monitor.yaml"
+	grpcMetricsPortName   = "csi-grpc-metrics"
+	rbdMetricsService     = "csi-rbdplugin-metrics"
+	cephFSMetricsService  = "csi-cephfsplugin-metrics"
+	serviceMonitorAppName = "csi-metrics"
+)
+
+// EnableServiceMonitor creates or updates the servicemonitor that allows prometheus to scrape the
+// liveness and grpc metrics of the csi drivers. The servicemonitor is owned by the metrics service
+// of a csi driver so it is removed with the drivers.
+func EnableServiceMonitor(clientset kubernetes.Interface) error {
+	if !CSIEnabled() {
+		return nil
+	}
+	// csi is deployed into the same namespace as the operator
+	namespace := os.Getenv(k8sutil.PodNamespaceEnvVar)
+	if namespace == "" {
+		return errors.Errorf("namespace value missing for %s", k8sutil.PodNamespaceEnvVar)
+	}
+
+	serviceName := rbdMetricsService
+	if !EnableRBD {
+		serviceName = cephFSMetricsService
+	}
+	service, err := clientset.CoreV1().Services(namespace).Get(serviceName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get csi metrics service %q", serviceName)
+	}
+
+	serviceMonitor, err := makeServiceMonitor(path.Join(monitoringPath, serviceMonitorFile), namespace, EnableCSIGRPCMetrics)
+	if err != nil {
+		return err
+	}
+	k8sutil.SetOwnerRef(&serviceMonitor.ObjectMeta, &metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Name:       service.Name,
+		UID:        service.UID,
+	})
+	if _, err := k8sutil.CreateOrUpdateServiceMonitor(serviceMonitor); err != nil {
+		return errors.Wrap(err, "csi service monitor could not be enabled")
+	}
+	return nil
+}
+
+func makeServiceMonitor(filePath, namespace string, grpcMetrics bool) (*monitoringv1.ServiceMonitor, error) {
+	serviceMonitor, err := k8sutil.GetServiceMonitor(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "csi service monitor could not be loaded")
+	}
+	serviceMonitor.SetNamespace(namespace)
+	serviceMonitor.Spec.NamespaceSelector.MatchNames = []string{namespace}
+	serviceMonitor.Spec.Selector.MatchLabels = map[string]string{"app": serviceMonitorAppName}
+
+	// the grpc metrics are only served when enabled
+	if !grpcMetrics {
+		endpoints := []monitoringv1.Endpoint{}
+		for _, endpoint := range serviceMonitor.Spec.Endpoints {
+			if endpoint.Port != grpcMetricsPortName {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		serviceMonitor.Spec.Endpoints = endpoints
+	}
+	return serviceMonitor, nil
+}
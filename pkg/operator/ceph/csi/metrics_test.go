@@ -0,0 +1,44 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testServiceMonitorPath = "../../../../cluster/examples/kubernetes/ceph/monitoring/csi-metrics-service-monitor.yaml"
+
+func TestMakeServiceMonitor(t *testing.T) {
+	sm, err := makeServiceMonitor(testServiceMonitorPath, "operator-ns", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "csi-metrics", sm.Name)
+	assert.Equal(t, "operator-ns", sm.Namespace)
+	assert.Equal(t, []string{"operator-ns"}, sm.Spec.NamespaceSelector.MatchNames)
+	assert.Equal(t, "csi-metrics", sm.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, 2, len(sm.Spec.Endpoints))
+
+	// the grpc endpoint is removed when grpc metrics are disabled
+	sm, err = makeServiceMonitor(testServiceMonitorPath, "operator-ns", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sm.Spec.Endpoints))
+	assert.Equal(t, "csi-http-metrics", sm.Spec.Endpoints[0].Port)
+
+	_, err = makeServiceMonitor("does-not-exist.yaml", "operator-ns", true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// the multus selector of the ceph public network the plugins attach to, e.g. "rook-ceph/public-net@net1"
+	multusPublicNetworkSetting = "CSI_MULTUS_PUBLIC_NETWORK"
+)
+
+// applyMultusToPlugin attaches the plugin pods directly to the multus public network of the ceph
+// cluster. The pods cannot be on the host network for multus to attach them to another network.
+// The volumes are then mapped from the network namespace of the pods, which is lost when a pod
+// restarts, so the pods are only updated once they are deleted.
+func applyMultusToPlugin(plugin *apps.DaemonSet, selector string) error {
+	network := rookv1.NetworkSpec{
+		Provider:  "multus",
+		Selectors: map[string]string{"public": selector},
+	}
+	template := &plugin.Spec.Template
+	if err := k8sutil.ApplyMultus(network, &template.ObjectMeta); err != nil {
+		return errors.Wrapf(err, "failed to apply multus network %q", selector)
+	}
+	template.Spec.HostNetwork = false
+	template.Spec.DNSPolicy = corev1.DNSClusterFirst
+	if plugin.Spec.UpdateStrategy.Type != apps.OnDeleteDaemonSetStrategyType {
+		logger.Infof("updating %q on delete since its pods are attached to the multus public network", plugin.Name)
+		plugin.Spec.UpdateStrategy = apps.DaemonSetUpdateStrategy{Type: apps.OnDeleteDaemonSetStrategyType}
+	}
+	return nil
+}
+
+// checkPluginsMultusNetwork reports the running plugin pods that multus did not attach to the public network
+func checkPluginsMultusNetwork(clientset kubernetes.Interface, namespace, app, selector string) error {
+	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, app)})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %q pods", app)
+	}
+
+	detached := []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Spec.HostNetwork {
+			// the pods not updated yet are still on the host network
+			continue
+		}
+		ips, err := k8sutil.GetMultusNetworkIPs(pod.ObjectMeta, selector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the network status of pod %q", pod.Name)
+		}
+		if len(ips) == 0 {
+			detached = append(detached, pod.Name)
+			continue
+		}
+		logger.Debugf("pod %q is attached to multus network %q with ips %v", pod.Name, selector, ips)
+	}
+	if len(detached) > 0 {
+		return errors.Errorf("pods %v are not attached to multus network %q", detached, selector)
+	}
+	return nil
+}
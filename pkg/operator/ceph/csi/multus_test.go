@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"testing"
+
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyMultusToPlugin(t *testing.T) {
+	plugin := &apps.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: csiRBDPlugin},
+		Spec: apps.DaemonSetSpec{
+			UpdateStrategy: apps.DaemonSetUpdateStrategy{Type: apps.RollingUpdateDaemonSetStrategyType},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					HostNetwork: true,
+					DNSPolicy:   corev1.DNSClusterFirstWithHostNet,
+				},
+			},
+		},
+	}
+	err := applyMultusToPlugin(plugin, "rook-ceph/public-net@net1")
+	assert.NoError(t, err)
+	template := plugin.Spec.Template
+	assert.False(t, template.Spec.HostNetwork)
+	assert.Equal(t, corev1.DNSClusterFirst, template.Spec.DNSPolicy)
+	assert.Equal(t, "rook-ceph/public-net@net1", template.Annotations[k8sutil.MultusNetworksAnnotation])
+	// the pods are not restarted with the volumes mapped from their network namespace
+	assert.Equal(t, apps.OnDeleteDaemonSetStrategyType, plugin.Spec.UpdateStrategy.Type)
+	assert.Nil(t, plugin.Spec.UpdateStrategy.RollingUpdate)
+}
+
+func TestCheckPluginsMultusNetwork(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	newPod := func(name string, hostNetwork bool, status string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "rook-ceph",
+				Labels:      map[string]string{k8sutil.AppAttr: csiRBDPlugin},
+				Annotations: map[string]string{k8sutil.MultusNetworkStatusAnnotation: status},
+			},
+			Spec:   corev1.PodSpec{HostNetwork: hostNetwork},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	// attached pod and a pod not updated yet
+	_, err := clientset.CoreV1().Pods("rook-ceph").Create(newPod("attached", false, `[{"name":"rook-ceph/public-net","ips":["192.168.20.5"]}]`))
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Pods("rook-ceph").Create(newPod("host", true, ""))
+	assert.NoError(t, err)
+	assert.NoError(t, checkPluginsMultusNetwork(clientset, "rook-ceph", csiRBDPlugin, "public-net@net1"))
+
+	// pod without an ip on the public network
+	_, err = clientset.CoreV1().Pods("rook-ceph").Create(newPod("detached", false, `[{"name":"rook-ceph/other-net","ips":["192.168.30.5"]}]`))
+	assert.NoError(t, err)
+	err = checkPluginsMultusNetwork(clientset, "rook-ceph", csiRBDPlugin, "public-net@net1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "detached")
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// the arguments of the rbd nodeplugin building the CRUSH location of its node from the node labels, to map the
+	// volumes with read_from_replica=localize
+	enableReadAffinityArg  = "--enable-read-affinity"
+	crushLocationLabelsArg = "--crush-location-labels"
+)
+
+// pluginReadAffinity returns the read affinity of the rbd nodeplugin, enabled when a cluster of the csi config
+// enables it, with the labels of all these clusters since the nodeplugin is shared by the clusters
+func pluginReadAffinity(cc csiClusterConfig) *csiReadAffinity {
+	var affinity *csiReadAffinity
+	seen := map[string]bool{}
+	for _, centry := range cc {
+		if centry.ReadAffinity == nil || !centry.ReadAffinity.Enabled {
+			continue
+		}
+		if affinity == nil {
+			affinity = &csiReadAffinity{Enabled: true}
+		}
+		for _, label := range centry.ReadAffinity.CrushLocationLabels {
+			if !seen[label] {
+				seen[label] = true
+				affinity.CrushLocationLabels = append(affinity.CrushLocationLabels, label)
+			}
+		}
+	}
+	return affinity
+}
+
+// applyReadAffinityToPlugin sets the read affinity arguments of the rbd nodeplugin container, and returns whether they
+// changed
+func applyReadAffinityToPlugin(spec *corev1.PodSpec, affinity *csiReadAffinity) bool {
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if container.Name != csiRBDPlugin {
+			continue
+		}
+		args := []string{}
+		for _, arg := range container.Args {
+			if !strings.HasPrefix(arg, enableReadAffinityArg) && !strings.HasPrefix(arg, crushLocationLabelsArg) {
+				args = append(args, arg)
+			}
+		}
+		if affinity != nil {
+			args = append(args, fmt.Sprintf("%s=true", enableReadAffinityArg))
+			if len(affinity.CrushLocationLabels) > 0 {
+				args = append(args, fmt.Sprintf("%s=%s", crushLocationLabelsArg, strings.Join(affinity.CrushLocationLabels, ",")))
+			}
+		}
+		if reflect.DeepEqual(args, container.Args) {
+			return false
+		}
+		container.Args = args
+		return true
+	}
+	return false
+}
+
+// updatePluginReadAffinity updates the read affinity arguments of the rbd nodeplugin daemonset from the csi config.
+// The daemonset is created by the operator, it is not updated before it exists.
+func updatePluginReadAffinity(clientset kubernetes.Interface, namespace, configData string) error {
+	if !EnableRBD {
+		return nil
+	}
+	cc, err := parseCsiClusterConfig(configData)
+	if err != nil {
+		return err
+	}
+	daemonset, err := clientset.AppsV1().DaemonSets(namespace).Get(csiRBDPlugin, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get daemonset %q", csiRBDPlugin)
+	}
+	if !applyReadAffinityToPlugin(&daemonset.Spec.Template.Spec, pluginReadAffinity(cc)) {
+		return nil
+	}
+	if _, err := clientset.AppsV1().DaemonSets(namespace).Update(daemonset); err != nil {
+		return errors.Wrapf(err, "failed to update the read affinity of daemonset %q", csiRBDPlugin)
+	}
+	logger.Infof("updated the read affinity of daemonset %q", csiRBDPlugin)
+	return nil
+}
+
+// currentPluginReadAffinity returns the read affinity of the rbd nodeplugin from the current csi config, so the
+// daemonset created at the start of the operator keeps the read affinity of the clusters
+func currentPluginReadAffinity(clientset kubernetes.Interface, namespace string) *csiReadAffinity {
+	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(ConfigName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Warningf("failed to get csi config map to configure the read affinity. %v", err)
+		}
+		return nil
+	}
+	data := configMap.Data[ConfigKey]
+	if data == "" {
+		return nil
+	}
+	cc, err := parseCsiClusterConfig(data)
+	if err != nil {
+		logger.Warningf("failed to configure the read affinity. %v", err)
+		return nil
+	}
+	return pluginReadAffinity(cc)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPluginReadAffinity(t *testing.T) {
+	assert.Nil(t, pluginReadAffinity(csiClusterConfig{{ClusterID: "a"}}))
+
+	cc := csiClusterConfig{
+		{ClusterID: "a", ReadAffinity: &csiReadAffinity{Enabled: true, CrushLocationLabels: []string{"topology.kubernetes.io/zone"}}},
+		{ClusterID: "b"},
+		{ClusterID: "c", ReadAffinity: &csiReadAffinity{Enabled: true, CrushLocationLabels: []string{"topology.kubernetes.io/zone", "topology.rook.io/rack"}}},
+	}
+	assert.Equal(t, &csiReadAffinity{Enabled: true, CrushLocationLabels: []string{"topology.kubernetes.io/zone", "topology.rook.io/rack"}}, pluginReadAffinity(cc))
+}
+
+func TestUpdatePluginReadAffinity(t *testing.T) {
+	EnableRBD = true
+	defer func() { EnableRBD = false }()
+	clientset := fake.NewSimpleClientset()
+	config := `[{"clusterID":"rook-ceph","monitors":["1.2.3.4:6789"],"readAffinity":{"enabled":true,"crushLocationLabels":["topology.kubernetes.io/zone"]}}]`
+
+	// the daemonset is not created yet
+	assert.NoError(t, updatePluginReadAffinity(clientset, "rook-ceph", config))
+
+	daemonset := &apps.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: csiRBDPlugin, Namespace: "rook-ceph"},
+		Spec: apps.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "driver-registrar", Args: []string{"--v=0"}},
+			{Name: csiRBDPlugin, Args: []string{"--type=rbd"}},
+		}}}},
+	}
+	_, err := clientset.AppsV1().DaemonSets("rook-ceph").Create(daemonset)
+	assert.NoError(t, err)
+	args := func() []string {
+		d, err := clientset.AppsV1().DaemonSets("rook-ceph").Get(csiRBDPlugin, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"--v=0"}, d.Spec.Template.Spec.Containers[0].Args)
+		return d.Spec.Template.Spec.Containers[1].Args
+	}
+
+	assert.NoError(t, updatePluginReadAffinity(clientset, "rook-ceph", config))
+	assert.Equal(t, []string{"--type=rbd", "--enable-read-affinity=true", "--crush-location-labels=topology.kubernetes.io/zone"}, args())
+
+	// the arguments are removed when no cluster enables the read affinity
+	assert.NoError(t, updatePluginReadAffinity(clientset, "rook-ceph", `[{"clusterID":"rook-ceph","monitors":["1.2.3.4:6789"]}]`))
+	assert.Equal(t, []string{"--type=rbd"}, args())
+}
@@ -164,36 +164,93 @@ func createOrUpdateCSISecret(clusterInfo *client.ClusterInfo, csiRBDProvisionerS
 	return nil
 }
 
+// CSIUserSecrets returns the names of the Kubernetes secrets holding the keys of the CSI users by user name
+func CSIUserSecrets() map[string]string {
+	return map[string]string{
+		csiKeyringRBDProvisionerUsername:    CsiRBDProvisionerSecret,
+		csiKeyringRBDNodeUsername:           CsiRBDNodeSecret,
+		csiKeyringCephFSProvisionerUsername: CsiCephFSProvisionerSecret,
+		csiKeyringCephFSNodeUsername:        CsiCephFSNodeSecret,
+	}
+}
+
 // CreateCSISecrets creates all the Kubernetes CSI Secrets
 func CreateCSISecrets(context *clusterd.Context, clusterInfo *client.ClusterInfo) error {
+	_, err := CreateCSIKeys(context, clusterInfo)
+	return err
+}
+
+// CreateCSIKeys creates the CSI users and their Kubernetes Secrets, it returns the keys of the users by secret name
+func CreateCSIKeys(context *clusterd.Context, clusterInfo *client.ClusterInfo) (map[string]string, error) {
 	k := keyring.GetSecretStore(context, clusterInfo, &clusterInfo.OwnerRef)
 
 	// Create CSI RBD Provisioner Ceph key
 	csiRBDProvisionerSecretKey, err := createCSIKeyringRBDProvisioner(k)
 	if err != nil {
-		return errors.Wrap(err, "failed to create csi rbd provisioner ceph keyring")
+		return nil, errors.Wrap(err, "failed to create csi rbd provisioner ceph keyring")
 	}
 
 	// Create CSI RBD Node Ceph key
 	csiRBDNodeSecretKey, err := createCSIKeyringRBDNode(k)
 	if err != nil {
-		return errors.Wrap(err, "failed to create csi rbd node ceph keyring")
+		return nil, errors.Wrap(err, "failed to create csi rbd node ceph keyring")
 	}
 
 	// Create CSI Cephfs provisioner Ceph key
 	csiCephFSProvisionerSecretKey, err := createCSIKeyringCephFSProvisioner(k)
 	if err != nil {
-		return errors.Wrap(err, "failed to create csi cephfs provisioner ceph keyring")
+		return nil, errors.Wrap(err, "failed to create csi cephfs provisioner ceph keyring")
 	}
 
 	// Create CSI Cephfs node Ceph key
 	csiCephFSNodeSecretKey, err := createCSIKeyringCephFSNode(k)
 	if err != nil {
-		return errors.Wrap(err, "failed to create csi cephfs node ceph keyring")
+		return nil, errors.Wrap(err, "failed to create csi cephfs node ceph keyring")
 	}
 
 	// Create or update Kubernetes CSI secret
 	if err := createOrUpdateCSISecret(clusterInfo, csiRBDProvisionerSecretKey, csiRBDNodeSecretKey, csiCephFSProvisionerSecretKey, csiCephFSNodeSecretKey, k); err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes csi secret")
+	}
+
+	return map[string]string{
+		CsiRBDProvisionerSecret:    csiRBDProvisionerSecretKey,
+		CsiRBDNodeSecret:           csiRBDNodeSecretKey,
+		CsiCephFSProvisionerSecret: csiCephFSProvisionerSecretKey,
+		CsiCephFSNodeSecret:        csiCephFSNodeSecretKey,
+	}, nil
+}
+
+// ImportCSISecrets creates the Kubernetes CSI Secrets from the existing CSI users of an external cluster.
+// This is used when the operator is not allowed to create the users, they are created by the
+// create-external-cluster-resources script instead. The keys are given by secret name, the missing
+// keys are read from the external cluster.
+func ImportCSISecrets(context *clusterd.Context, clusterInfo *client.ClusterInfo, keys map[string]string) error {
+	k := keyring.GetSecretStore(context, clusterInfo, &clusterInfo.OwnerRef)
+
+	usernames := map[string]string{
+		CsiRBDProvisionerSecret:    csiKeyringRBDProvisionerUsername,
+		CsiRBDNodeSecret:           csiKeyringRBDNodeUsername,
+		CsiCephFSProvisionerSecret: csiKeyringCephFSProvisionerUsername,
+		CsiCephFSNodeSecret:        csiKeyringCephFSNodeUsername,
+	}
+	imported := map[string]string{}
+	for secretName, username := range usernames {
+		if key, ok := keys[secretName]; ok {
+			if !client.IsKeyringBase64Encoded(key) {
+				return errors.Errorf("invalid key for csi user %q", username)
+			}
+			imported[secretName] = key
+			continue
+		}
+		key, err := client.AuthGetKey(context, clusterInfo, username)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the key of csi user %q", username)
+		}
+		imported[secretName] = key
+	}
+
+	if err := createOrUpdateCSISecret(clusterInfo, imported[CsiRBDProvisionerSecret], imported[CsiRBDNodeSecret], imported[CsiCephFSProvisionerSecret], imported[CsiCephFSNodeSecret], k); err != nil {
 		return errors.Wrap(err, "failed to create kubernetes csi secret")
 	}
 
@@ -370,6 +370,12 @@ func startDrivers(clientset kubernetes.Interface, namespace string, ver *version
 		logger.Info("successfully started CSI CephFS driver")
 	}
 
+	// attach the plugins directly to the multus public network if configured
+	multusPublicNetwork, err := k8sutil.GetOperatorSetting(clientset, controllerutil.OperatorSettingConfigMapName, multusPublicNetworkSetting, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to load %s setting", multusPublicNetworkSetting)
+	}
+
 	// get provisioner toleration and node affinity
 	provisionerTolerations := getToleration(clientset, true)
 	provisionerNodeAffinity := getNodeAffinity(clientset, true)
@@ -380,6 +386,12 @@ func startDrivers(clientset kubernetes.Interface, namespace string, ver *version
 		applyToPodSpec(&rbdPlugin.Spec.Template.Spec, pluginNodeAffinity, pluginTolerations)
 		// apply resource request and limit to rbdplugin containers
 		applyResourcesToContainers(clientset, rbdPluginResource, &rbdPlugin.Spec.Template.Spec)
+		applyReadAffinityToPlugin(&rbdPlugin.Spec.Template.Spec, currentPluginReadAffinity(clientset, namespace))
+		if multusPublicNetwork != "" {
+			if err := applyMultusToPlugin(rbdPlugin, multusPublicNetwork); err != nil {
+				return errors.Wrap(err, "failed to attach rbdplugin to the multus public network")
+			}
+		}
 		k8sutil.SetOwnerRef(&rbdPlugin.ObjectMeta, ownerRef)
 		err = k8sutil.CreateDaemonSet(csiRBDPlugin, namespace, clientset, rbdPlugin)
 		if err != nil {
@@ -428,6 +440,11 @@ func startDrivers(clientset kubernetes.Interface, namespace string, ver *version
 		applyToPodSpec(&cephfsPlugin.Spec.Template.Spec, pluginNodeAffinity, pluginTolerations)
 		// apply resource request and limit to cephfs plugin containers
 		applyResourcesToContainers(clientset, cephFSPluginResource, &cephfsPlugin.Spec.Template.Spec)
+		if multusPublicNetwork != "" {
+			if err := applyMultusToPlugin(cephfsPlugin, multusPublicNetwork); err != nil {
+				return errors.Wrap(err, "failed to attach cephfs plugin to the multus public network")
+			}
+		}
 		k8sutil.SetOwnerRef(&cephfsPlugin.ObjectMeta, ownerRef)
 		err = k8sutil.CreateDaemonSet(csiCephFSPlugin, namespace, clientset, cephfsPlugin)
 		if err != nil {
@@ -472,6 +489,14 @@ func startDrivers(clientset kubernetes.Interface, namespace string, ver *version
 		}
 	}
 
+	if multusPublicNetwork != "" {
+		for _, app := range []string{csiRBDPlugin, csiCephFSPlugin} {
+			if err := checkPluginsMultusNetwork(clientset, namespace, app, multusPublicNetwork); err != nil {
+				logger.Warningf("csi plugins may not reach the ceph public network. %v", err)
+			}
+		}
+	}
+
 	if ver.Major > KubeMinMajor || (ver.Major == KubeMinMajor && ver.Minor >= provDeploymentSuppVersion) {
 		if EnableRBD {
 			err = createCSIDriverInfo(clientset, RBDDriverName, ownerRef)
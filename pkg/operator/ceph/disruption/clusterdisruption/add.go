@@ -17,19 +17,23 @@ limitations under the License.
 package clusterdisruption
 
 import (
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/nodedrain"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -151,6 +155,38 @@ func Add(mgr manager.Manager, context *controllerconfig.Context) error {
 		return err
 	}
 
+	// Watch for the nodes entering or leaving maintenance and enqueue all CephClusters
+	err = c.Watch(
+		&source.Kind{Type: &corev1.Node{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				reqs := make([]reconcile.Request, 0)
+				for _, namespace := range sharedClusterMap.GetClusterNamespaces() {
+					// The name will be populated in the reconcile
+					reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace}})
+				}
+				return reqs
+			}),
+		},
+		predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return e.Meta.GetAnnotations()[osd.NodeMaintenanceAnnotation] != ""
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return e.MetaOld.GetAnnotations()[osd.NodeMaintenanceAnnotation] != e.MetaNew.GetAnnotations()[osd.NodeMaintenanceAnnotation]
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return e.Meta.GetAnnotations()[osd.NodeMaintenanceAnnotation] != ""
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
 	// Watch for CephBlockPools and enqueue the CephCluster in the namespace
 	err = c.Watch(&source.Kind{Type: &cephv1.CephBlockPool{}}, enqueueByNamespace)
 	if err != nil {
@@ -169,5 +205,11 @@ func Add(mgr manager.Manager, context *controllerconfig.Context) error {
 		return err
 	}
 
+	// Watch for CephNFSes and enqueue the CephCluster in the namespace
+	err = c.Watch(&source.Kind{Type: &cephv1.CephNFS{}}, enqueueByNamespace)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
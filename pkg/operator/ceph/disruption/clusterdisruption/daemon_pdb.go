@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdisruption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// daemonMinAvailable returns how many of the daemons the pdb keeps available, no pdb is needed when it is 0
+func daemonMinAvailable(spec cephv1.DaemonPodBudgetSpec, count, defaultMaxUnavailable int32) int32 {
+	if spec.Disabled {
+		return 0
+	}
+	maxUnavailable := defaultMaxUnavailable
+	if spec.MaxUnavailable != nil {
+		maxUnavailable = *spec.MaxUnavailable
+	}
+	// a pdb allowing no eviction would block the drains of the nodes
+	if maxUnavailable < 1 {
+		logger.Warningf("maxUnavailable %d of the daemon pdbs is invalid, allowing one daemon to be evicted", maxUnavailable)
+		maxUnavailable = 1
+	}
+	minAvailable := count - maxUnavailable
+	if minAvailable < 1 {
+		return 0
+	}
+	return minAvailable
+}
+
+func daemonPDB(name, namespace, kind string, owner metav1.ObjectMeta, selector map[string]string, minAvailable int32) *policyv1beta1.PodDisruptionBudget {
+	blockOwnerDeletion := false
+	minAvailableVal := intstr.FromInt(int(minAvailable))
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         cephv1.SchemeGroupVersion.String(),
+					Kind:               kind,
+					Name:               owner.Name,
+					UID:                owner.UID,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector:     &metav1.LabelSelector{MatchLabels: selector},
+			MinAvailable: &minAvailableVal,
+		},
+	}
+}
+
+// reconcileDaemonPDB creates the pdb of the daemons or recreates it when the number of daemons to keep available
+// changed. The pdb is deleted when minAvailable is 0.
+func (r *ReconcileClusterDisruption) reconcileDaemonPDB(pdb *policyv1beta1.PodDisruptionBudget, minAvailable int32) error {
+	request := types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}
+	existing := &policyv1beta1.PodDisruptionBudget{}
+	err := r.client.Get(context.TODO(), request, existing)
+	if kerrors.IsNotFound(err) {
+		if minAvailable == 0 {
+			return nil
+		}
+		logger.Infof("creating pdb %q with minAvailable %d", request.String(), minAvailable)
+		return r.createStaticPDB(request, pdb)
+	} else if err != nil {
+		return err
+	}
+
+	if minAvailable == 0 {
+		logger.Infof("deleting pdb %q which is not needed", request.String())
+		return r.client.Delete(context.TODO(), existing)
+	}
+	if existing.Spec.MinAvailable == nil || existing.Spec.MinAvailable.IntValue() != int(minAvailable) {
+		logger.Infof("updating pdb %q with minAvailable %d", request.String(), minAvailable)
+		return r.updateStaticPDB(request, pdb)
+	}
+	return nil
+}
+
+// reconcileCephObjectStore keeps all the gateways of each object store but maxUnavailable available
+func (r *ReconcileClusterDisruption) reconcileCephObjectStore(cephObjectStoreList *cephv1.CephObjectStoreList, spec cephv1.DaemonPodBudgetSpec) error {
+	for _, objectStore := range cephObjectStoreList.Items {
+		minAvailable := daemonMinAvailable(spec, objectStore.Spec.Gateway.Instances, 1)
+		// the gateways of an external store are not running in the cluster
+		if len(objectStore.Spec.Gateway.ExternalRgwEndpoints) > 0 {
+			minAvailable = 0
+		}
+		pdbName := fmt.Sprintf("rook-ceph-rgw-%s", objectStore.Name)
+		pdb := daemonPDB(pdbName, objectStore.Namespace, "CephObjectStore", objectStore.ObjectMeta, map[string]string{"rgw": objectStore.Name}, minAvailable)
+		if err := r.reconcileDaemonPDB(pdb, minAvailable); err != nil {
+			return errors.Wrapf(err, "could not reconcile cephobjectstore pdb %q", pdbName)
+		}
+	}
+	return nil
+}
+
+// reconcileCephFilesystem keeps the activeCount mds daemons of each filesystem available by default, each active
+// mds has a standby so that max_mds ranks stay active while the standby daemons are evicted
+func (r *ReconcileClusterDisruption) reconcileCephFilesystem(cephFilesystemList *cephv1.CephFilesystemList, spec cephv1.DaemonPodBudgetSpec) error {
+	for _, filesystem := range cephFilesystemList.Items {
+		activeCount := filesystem.Spec.MetadataServer.ActiveCount
+		minAvailable := daemonMinAvailable(spec, activeCount*2, activeCount)
+		pdbName := fmt.Sprintf("rook-ceph-mds-%s", filesystem.Name)
+		pdb := daemonPDB(pdbName, filesystem.Namespace, "CephFilesystem", filesystem.ObjectMeta, map[string]string{"rook_file_system": filesystem.Name}, minAvailable)
+		if err := r.reconcileDaemonPDB(pdb, minAvailable); err != nil {
+			return errors.Wrapf(err, "could not reconcile cephfs pdb %q", pdbName)
+		}
+	}
+	return nil
+}
+
+// reconcileCephNFS keeps all the ganesha servers of each CephNFS but maxUnavailable available
+func (r *ReconcileClusterDisruption) reconcileCephNFS(request reconcile.Request, spec cephv1.DaemonPodBudgetSpec) error {
+	cephNFSList := &cephv1.CephNFSList{}
+	if err := r.client.List(context.TODO(), cephNFSList, client.InNamespace(request.Namespace)); err != nil {
+		return errors.Wrapf(err, "could not list the CephNFSes %v", request.NamespacedName)
+	}
+	for _, nfs := range cephNFSList.Items {
+		minAvailable := daemonMinAvailable(spec, int32(nfs.Spec.Server.Active), 1)
+		pdbName := fmt.Sprintf("rook-ceph-nfs-%s", nfs.Name)
+		pdb := daemonPDB(pdbName, nfs.Namespace, "CephNFS", nfs.ObjectMeta, map[string]string{"ceph_nfs": nfs.Name}, minAvailable)
+		if err := r.reconcileDaemonPDB(pdb, minAvailable); err != nil {
+			return errors.Wrapf(err, "could not reconcile cephnfs pdb %q", pdbName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdisruption
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDaemonMinAvailable(t *testing.T) {
+	two := int32(2)
+	zero := int32(0)
+	assert.Equal(t, int32(2), daemonMinAvailable(cephv1.DaemonPodBudgetSpec{}, 3, 1))
+	assert.Equal(t, int32(1), daemonMinAvailable(cephv1.DaemonPodBudgetSpec{MaxUnavailable: &two}, 3, 1))
+	// a single daemon does not need a pdb
+	assert.Equal(t, int32(0), daemonMinAvailable(cephv1.DaemonPodBudgetSpec{}, 1, 1))
+	assert.Equal(t, int32(0), daemonMinAvailable(cephv1.DaemonPodBudgetSpec{Disabled: true}, 3, 1))
+	// at least one daemon can always be evicted
+	assert.Equal(t, int32(2), daemonMinAvailable(cephv1.DaemonPodBudgetSpec{MaxUnavailable: &zero}, 3, 1))
+}
+
+func TestReconcileDaemonPDBs(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFS{}, &cephv1.CephNFSList{})
+	nfs := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs1", Namespace: "rook-ceph"},
+		Spec:       cephv1.NFSGaneshaSpec{Server: cephv1.GaneshaServerSpec{Active: 3}},
+	}
+	r := &ReconcileClusterDisruption{client: fake.NewFakeClientWithScheme(s, nfs)}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "rook-ceph"}}
+	getPDB := func(name string) (*policyv1beta1.PodDisruptionBudget, error) {
+		pdb := &policyv1beta1.PodDisruptionBudget{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "rook-ceph"}, pdb)
+		return pdb, err
+	}
+
+	// the pdb of the nfs servers allows one server to be evicted
+	assert.NoError(t, r.reconcileCephNFS(request, cephv1.DaemonPodBudgetSpec{}))
+	pdb, err := getPDB("rook-ceph-nfs-nfs1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pdb.Spec.MinAvailable.IntValue())
+	assert.Equal(t, "nfs1", pdb.Spec.Selector.MatchLabels["ceph_nfs"])
+	assert.Equal(t, "CephNFS", pdb.OwnerReferences[0].Kind)
+
+	// the pdb is updated when the tuning changes
+	two := int32(2)
+	assert.NoError(t, r.reconcileCephNFS(request, cephv1.DaemonPodBudgetSpec{MaxUnavailable: &two}))
+	pdb, err = getPDB("rook-ceph-nfs-nfs1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pdb.Spec.MinAvailable.IntValue())
+
+	// the pdb is deleted when disabled
+	assert.NoError(t, r.reconcileCephNFS(request, cephv1.DaemonPodBudgetSpec{Disabled: true}))
+	_, err = getPDB("rook-ceph-nfs-nfs1")
+	assert.True(t, kerrors.IsNotFound(err))
+
+	// the active mds daemons stay available
+	filesystems := &cephv1.CephFilesystemList{Items: []cephv1.CephFilesystem{{
+		ObjectMeta: metav1.ObjectMeta{Name: "fs1", Namespace: "rook-ceph"},
+		Spec:       cephv1.FilesystemSpec{MetadataServer: cephv1.MetadataServerSpec{ActiveCount: 2}},
+	}}}
+	assert.NoError(t, r.reconcileCephFilesystem(filesystems, cephv1.DaemonPodBudgetSpec{}))
+	pdb, err = getPDB("rook-ceph-mds-fs1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pdb.Spec.MinAvailable.IntValue())
+
+	// two gateways keep one available, a single gateway has no pdb
+	stores := &cephv1.CephObjectStoreList{Items: []cephv1.CephObjectStore{
+		{ObjectMeta: metav1.ObjectMeta{Name: "store1", Namespace: "rook-ceph"}, Spec: cephv1.ObjectStoreSpec{Gateway: cephv1.GatewaySpec{Instances: 2}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "store2", Namespace: "rook-ceph"}, Spec: cephv1.ObjectStoreSpec{Gateway: cephv1.GatewaySpec{Instances: 1}}},
+	}}
+	assert.NoError(t, r.reconcileCephObjectStore(stores, cephv1.DaemonPodBudgetSpec{}))
+	pdb, err = getPDB("rook-ceph-rgw-store1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pdb.Spec.MinAvailable.IntValue())
+	_, err = getPDB("rook-ceph-rgw-store2")
+	assert.True(t, kerrors.IsNotFound(err))
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephClient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
@@ -162,14 +163,14 @@ func (o *OSDCrushLocationMap) get(clusterInfo *cephClient.ClusterInfo, id int) (
 	return osdResult, nil
 }
 
-func getOSDsForNodes(osdDataList []OsdData, nodeList []*corev1.Node, failureDomainType string) ([]OsdData, error) {
+func getOSDsForNodes(osdDataList []OsdData, nodeList []*corev1.Node, failureDomainType string, topologyLabels []cephv1.CrushTopologyLabelSpec) ([]OsdData, error) {
 	nodeOsdDataList := make([]OsdData, 0)
 	for _, node := range nodeList {
 		if node == nil {
 			logger.Warningf("node in nodelist was nil")
 			continue
 		}
-		nodeTopologyMap := osd.ExtractOSDTopologyFromLabels(node.GetLabels())
+		nodeTopologyMap := osd.ExtractCrushTopologyFromLabels(node.GetLabels(), topologyLabels)
 
 		for _, osdData := range osdDataList {
 			// get the crush location of the osd
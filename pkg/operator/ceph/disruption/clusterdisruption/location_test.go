@@ -141,7 +141,7 @@ func TestGetOSDNodeAssociation(t *testing.T) {
 	// osds from hostNames[2] - (4)
 
 	// hosts from nodeList (0,2)
-	osdDataSubset, err := getOSDsForNodes(osdDataList, nodeList, "host")
+	osdDataSubset, err := getOSDsForNodes(osdDataList, nodeList, "host", nil)
 	assert.Nil(t, err)
 	assert.Len(t, osdDataSubset, 2)
 
@@ -155,7 +155,7 @@ func TestGetOSDNodeAssociation(t *testing.T) {
 	// osds from zone[2] - (2,3)
 
 	// zones from nodeList (0,2)
-	osdDataSubset, err = getOSDsForNodes(osdDataList, nodeList, "zone")
+	osdDataSubset, err = getOSDsForNodes(osdDataList, nodeList, "zone", nil)
 	assert.Nil(t, err)
 	assert.Len(t, osdDataSubset, 3)
 
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdisruption
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	cephClient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reconcileMaintenance stops the OSDs of the nodes in maintenance once the noout flag is set on their host and they
+// are ok to stop, and starts them again and unsets the flag when the maintenance ends. It returns the hosts in
+// maintenance, whose noout flag is kept while the drains of the other failure domains are reconciled. The other
+// daemons of the nodes are not stopped, they are moved by the drain of the node within their pod disruption budgets.
+func (r *ReconcileClusterDisruption) reconcileMaintenance(clusterInfo *cephClient.ClusterInfo, osdDataList []OsdData) ([]string, error) {
+	nodeList := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodeList); err != nil {
+		return nil, errors.Wrap(err, "could not list the nodes")
+	}
+	// the osds of a node are scheduled with the hostname label of the node
+	maintenanceNodes := map[string]string{}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if osd.IsNodeInMaintenance(node) {
+			hostname, ok := node.Labels[corev1.LabelHostname]
+			if !ok {
+				hostname = node.Name
+			}
+			maintenanceNodes[node.Name] = hostname
+		}
+	}
+
+	osdDump, err := cephClient.GetOSDDump(r.context.ClusterdContext, clusterInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get osddump to reconcile the node maintenance")
+	}
+
+	maintenanceHosts := []string{}
+	for nodeName, hostname := range maintenanceNodes {
+		nodeOSDs, running := osdsOnNode(osdDataList, hostname)
+		if len(nodeOSDs) == 0 {
+			continue
+		}
+		host := nodeOSDs[0].CrushMeta.Location["host"]
+		maintenanceHosts = append(maintenanceHosts, host)
+		if _, err := osdDump.UpdateFlagOnCrushUnit(r.context.ClusterdContext, clusterInfo, true, host, nooutFlag); err != nil {
+			return nil, errors.Wrapf(err, "failed to set noout on host %q in maintenance", host)
+		}
+		if len(running) == 0 {
+			continue
+		}
+		osdIDs := []int{}
+		for _, osdData := range running {
+			osdIDs = append(osdIDs, osdData.CrushMeta.ID)
+		}
+		// the osds are stopped together once the pgs remain active without them, the next reconcile tries again
+		if err := cephClient.OSDOkToStop(r.context.ClusterdContext, clusterInfo, osdIDs); err != nil {
+			logger.Warningf("waiting to stop the osds of node %q in maintenance. %v", nodeName, err)
+			continue
+		}
+		for _, osdData := range running {
+			if err := r.scaleOSDForMaintenance(osdData, nodeName, true); err != nil {
+				return nil, err
+			}
+		}
+		logger.Infof("stopped osds %v for the maintenance of node %q", osdIDs, nodeName)
+	}
+
+	// the osds of the nodes whose maintenance ended are started again before the flag is unset
+	for _, osdData := range osdDataList {
+		nodeName, ok := osdData.Deployment.GetAnnotations()[osd.MaintenanceScaledDownAnnotation]
+		if _, inMaintenance := maintenanceNodes[nodeName]; !ok || inMaintenance {
+			continue
+		}
+		if err := r.scaleOSDForMaintenance(osdData, nodeName, false); err != nil {
+			return nil, err
+		}
+		host := osdData.CrushMeta.Location["host"]
+		if _, err := osdDump.UpdateFlagOnCrushUnit(r.context.ClusterdContext, clusterInfo, false, host, nooutFlag); err != nil {
+			return nil, errors.Wrapf(err, "failed to unset noout on host %q after its maintenance", host)
+		}
+		logger.Infof("started osd %d after the maintenance of node %q", osdData.CrushMeta.ID, nodeName)
+	}
+	return maintenanceHosts, nil
+}
+
+// osdsOnNode returns the OSDs scheduled on the node with the hostname and the ones which are not stopped for
+// maintenance. The portable OSDs are not scheduled on a node, they are drained with their node.
+func osdsOnNode(osdDataList []OsdData, hostname string) ([]OsdData, []OsdData) {
+	nodeOSDs, running := []OsdData{}, []OsdData{}
+	for _, osdData := range osdDataList {
+		if osdData.Deployment.Spec.Template.Spec.NodeSelector[corev1.LabelHostname] != hostname {
+			continue
+		}
+		nodeOSDs = append(nodeOSDs, osdData)
+		if _, ok := osdData.Deployment.GetAnnotations()[osd.MaintenanceScaledDownAnnotation]; !ok {
+			running = append(running, osdData)
+		}
+	}
+	return nodeOSDs, running
+}
+
+// scaleOSDForMaintenance stops the deployment of the OSD during the maintenance of its node or starts it again
+func (r *ReconcileClusterDisruption) scaleOSDForMaintenance(osdData OsdData, nodeName string, stop bool) error {
+	deployment := osdData.Deployment.DeepCopy()
+	replicas := int32(1)
+	if stop {
+		replicas = 0
+		if deployment.Annotations == nil {
+			deployment.Annotations = map[string]string{}
+		}
+		deployment.Annotations[osd.MaintenanceScaledDownAnnotation] = nodeName
+	} else {
+		delete(deployment.Annotations, osd.MaintenanceScaledDownAnnotation)
+	}
+	deployment.Spec.Replicas = &replicas
+	if err := r.client.Update(context.TODO(), deployment); err != nil {
+		return errors.Wrapf(err, "failed to scale osd deployment %q for the maintenance of node %q", deployment.Name, nodeName)
+	}
+	return nil
+}
+
+// isMaintenanceHost returns whether the crush unit is a host in maintenance
+func isMaintenanceHost(maintenanceHosts []string, crushUnit string) bool {
+	for _, host := range maintenanceHosts {
+		if host == crushUnit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterdisruption
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	cephClient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
+	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func maintenanceOSD(id int, name, hostname string) OsdData {
+	replicas := int32(1)
+	return OsdData{
+		Deployment: appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "rook-ceph"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{NodeSelector: map[string]string{corev1.LabelHostname: hostname}}},
+			},
+		},
+		CrushMeta: &cephClient.CrushFindResult{ID: id, Location: map[string]string{"host": hostname}},
+	}
+}
+
+func TestReconcileMaintenance(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "node1",
+		Labels:      map[string]string{corev1.LabelHostname: "node1"},
+		Annotations: map[string]string{osd.NodeMaintenanceAnnotation: "true"},
+	}}
+	osd0 := maintenanceOSD(0, "rook-ceph-osd-0", "node1")
+	osd1 := maintenanceOSD(1, "rook-ceph-osd-1", "node2")
+	// the other daemons of the node are left to the drain of the node
+	mon := maintenanceOSD(0, "rook-ceph-mon-a", "node1").Deployment
+	crashCollector := maintenanceOSD(0, "rook-ceph-crashcollector-node1", "node1").Deployment
+	cl := fake.NewFakeClient(node, &osd0.Deployment, &osd1.Deployment, &mon, &crashCollector)
+
+	commands := []string{}
+	okToStop := false
+	osdDump := `{"crush_node_flags": {}}`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfileArg string, args ...string) (string, error) {
+			commands = append(commands, strings.Join(args[:3], " "))
+			if args[0] == "osd" && args[1] == "dump" {
+				return osdDump, nil
+			}
+			if args[1] == "ok-to-stop" && !okToStop {
+				return "", errors.New("pgs would become inactive")
+			}
+			return "", nil
+		},
+	}
+	r := &ReconcileClusterDisruption{
+		client:  cl,
+		context: &controllerconfig.Context{ClusterdContext: &clusterd.Context{Executor: executor}},
+	}
+	clusterInfo := cephClient.AdminClusterInfo("rook-ceph")
+	getOSD := func(name string) OsdData {
+		d := appsv1.Deployment{}
+		assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "rook-ceph"}, &d))
+		data := maintenanceOSD(0, name, "node1")
+		data.Deployment = d
+		return data
+	}
+
+	// noout is set on the host but the osds are not stopped until they are ok to stop
+	hosts, err := r.reconcileMaintenance(clusterInfo, []OsdData{osd0, osd1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node1"}, hosts)
+	assert.Contains(t, commands, "osd set-group noout")
+	assert.Equal(t, int32(1), *getOSD("rook-ceph-osd-0").Deployment.Spec.Replicas)
+
+	// the osds of the node are stopped once they are ok to stop
+	okToStop = true
+	_, err = r.reconcileMaintenance(clusterInfo, []OsdData{osd0, osd1})
+	assert.NoError(t, err)
+	stopped := getOSD("rook-ceph-osd-0")
+	assert.Equal(t, int32(0), *stopped.Deployment.Spec.Replicas)
+	assert.Equal(t, "node1", stopped.Deployment.Annotations[osd.MaintenanceScaledDownAnnotation])
+	assert.Equal(t, int32(1), *getOSD("rook-ceph-osd-1").Deployment.Spec.Replicas)
+	for _, name := range []string{"rook-ceph-mon-a", "rook-ceph-crashcollector-node1"} {
+		daemon := getOSD(name).Deployment
+		assert.Equal(t, int32(1), *daemon.Spec.Replicas)
+		assert.NotContains(t, daemon.Annotations, osd.MaintenanceScaledDownAnnotation)
+	}
+
+	// the osds are started again and noout is unset when the maintenance ends
+	node.Annotations = nil
+	assert.NoError(t, cl.Update(context.TODO(), node))
+	commands = []string{}
+	osdDump = `{"crush_node_flags": {"node1": ["noout"]}}`
+	hosts, err = r.reconcileMaintenance(clusterInfo, []OsdData{stopped, osd1})
+	assert.NoError(t, err)
+	assert.Empty(t, hosts)
+	started := getOSD("rook-ceph-osd-0")
+	assert.Equal(t, int32(1), *started.Deployment.Spec.Replicas)
+	assert.NotContains(t, started.Deployment.Annotations, osd.MaintenanceScaledDownAnnotation)
+	assert.Contains(t, commands, "osd unset-group noout")
+}
@@ -86,6 +86,7 @@ func (r *ReconcileClusterDisruption) createPDBForOSD(deployment appsv1.Deploymen
 		},
 	}
 
+	r.applyInheritedMetadata(pdb)
 	err := r.client.Create(context.TODO(), pdb)
 	if err != nil && !kerrors.IsAlreadyExists(err) {
 		return errors.Wrapf(err, "could not create pdb for osd: %s in namespace %s", osdIDLabel, namespace)
@@ -157,6 +158,7 @@ func (r *ReconcileClusterDisruption) reconcilePDBsForOSDs(
 	poolFailureDomain string,
 	allFailureDomainsMap,
 	drainingFailureDomainsMap map[string][]OsdData,
+	maintenanceHosts []string,
 ) error {
 	drainingFailureDomains := getSortedOSDMapKeys(drainingFailureDomainsMap)
 
@@ -203,7 +205,7 @@ func (r *ReconcileClusterDisruption) reconcilePDBsForOSDs(
 		}
 	}
 
-	err = r.updateNoout(clusterInfo, pdbStateMap, allFailureDomainsMap)
+	err = r.updateNoout(clusterInfo, pdbStateMap, allFailureDomainsMap, maintenanceHosts)
 	if err != nil {
 		logger.Errorf("could not update maintenance noout in cluster %q with ceph image. %v", request, err)
 	}
@@ -251,7 +253,7 @@ func (r *ReconcileClusterDisruption) reconcilePDBsForOSDs(
 	return nil
 }
 
-func (r *ReconcileClusterDisruption) updateNoout(clusterInfo *cephclient.ClusterInfo, pdbStateMap *corev1.ConfigMap, allFailureDomainsMap map[string][]OsdData) error {
+func (r *ReconcileClusterDisruption) updateNoout(clusterInfo *cephclient.ClusterInfo, pdbStateMap *corev1.ConfigMap, allFailureDomainsMap map[string][]OsdData, maintenanceHosts []string) error {
 	disabledFailureDomain := pdbStateMap.Data[disabledPDBKey]
 	osdDump, err := cephclient.GetOSDDump(r.context.ClusterdContext, clusterInfo)
 	if err != nil {
@@ -284,8 +286,8 @@ func (r *ReconcileClusterDisruption) updateNoout(clusterInfo *cephclient.Cluster
 				}
 			}
 
-		} else {
-			// ensure noout unset
+		} else if !isMaintenanceHost(maintenanceHosts, failureDomain) {
+			// ensure noout unset, the hosts in maintenance keep their noout flag
 			if _, err := osdDump.UpdateFlagOnCrushUnit(r.context.ClusterdContext, clusterInfo, false, failureDomain, nooutFlag); err != nil {
 				return errors.Wrapf(err, "failed to update flag on crush unit when ensuring noout is unset.")
 			}
@@ -18,7 +18,6 @@ package clusterdisruption
 
 import (
 	"context"
-	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -31,10 +30,6 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	policyv1beta1 "k8s.io/api/policy/v1beta1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func (r *ReconcileClusterDisruption) processPools(request reconcile.Request) (*cephv1.CephObjectStoreList, *cephv1.CephFilesystemList, string, int, error) {
@@ -145,97 +140,3 @@ func (r *ReconcileClusterDisruption) getOngoingDrains(request reconcile.Request)
 	}
 	return ongoingDrains, nil
 }
-
-// Setting naive minAvailable for RGW at: n - 1
-func (r *ReconcileClusterDisruption) reconcileCephObjectStore(cephObjectStoreList *cephv1.CephObjectStoreList) error {
-	for _, objectStore := range cephObjectStoreList.Items {
-		storeName := objectStore.ObjectMeta.Name
-		namespace := objectStore.ObjectMeta.Namespace
-		pdbName := fmt.Sprintf("rook-ceph-rgw-%s", storeName)
-		labelSelector := &metav1.LabelSelector{
-			MatchLabels: map[string]string{"rgw": storeName},
-		}
-
-		rgwCount := objectStore.Spec.Gateway.Instances
-		minAvailable := &intstr.IntOrString{IntVal: rgwCount - 1}
-		if minAvailable.IntVal <= 1 {
-			break
-		}
-		blockOwnerDeletion := false
-		pdb := &policyv1beta1.PodDisruptionBudget{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      pdbName,
-				Namespace: namespace,
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion:         objectStore.APIVersion,
-						Kind:               objectStore.Kind,
-						Name:               objectStore.ObjectMeta.Name,
-						UID:                objectStore.UID,
-						BlockOwnerDeletion: &blockOwnerDeletion,
-					},
-				},
-			},
-			Spec: policyv1beta1.PodDisruptionBudgetSpec{
-				Selector:     labelSelector,
-				MinAvailable: minAvailable,
-			},
-		}
-
-		request := types.NamespacedName{Name: pdbName, Namespace: namespace}
-		err := r.reconcileStaticPDB(request, pdb)
-		if err != nil {
-			return errors.Wrapf(err, "could not reconcile cephobjectstore pdb %v", request)
-		}
-	}
-	return nil
-}
-
-// Setting naive minAvailable for MDS at: n -1
-// getting n from the cephfilesystem.spec.metadataserver.activecount
-func (r *ReconcileClusterDisruption) reconcileCephFilesystem(cephFilesystemList *cephv1.CephFilesystemList) error {
-	for _, filesystem := range cephFilesystemList.Items {
-		fsName := filesystem.ObjectMeta.Name
-		namespace := filesystem.ObjectMeta.Namespace
-		pdbName := fmt.Sprintf("rook-ceph-mds-%s", fsName)
-		labelSelector := &metav1.LabelSelector{
-			MatchLabels: map[string]string{"rook_file_system": fsName},
-		}
-
-		activeCount := filesystem.Spec.MetadataServer.ActiveCount
-		minAvailable := &intstr.IntOrString{IntVal: activeCount - 1}
-		if filesystem.Spec.MetadataServer.ActiveStandby {
-			minAvailable.IntVal++
-		}
-		if minAvailable.IntVal < 1 {
-			break
-		}
-		blockOwnerDeletion := false
-		pdb := &policyv1beta1.PodDisruptionBudget{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      pdbName,
-				Namespace: namespace,
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion:         filesystem.APIVersion,
-						Kind:               filesystem.Kind,
-						Name:               filesystem.ObjectMeta.Name,
-						UID:                filesystem.UID,
-						BlockOwnerDeletion: &blockOwnerDeletion,
-					},
-				},
-			},
-			Spec: policyv1beta1.PodDisruptionBudgetSpec{
-				Selector:     labelSelector,
-				MinAvailable: minAvailable,
-			},
-		}
-
-		request := types.NamespacedName{Name: pdbName, Namespace: namespace}
-		err := r.reconcileStaticPDB(request, pdb)
-		if err != nil {
-			return errors.Wrapf(err, "could not reconcile cephfs pdb %v", request)
-		}
-	}
-	return nil
-}
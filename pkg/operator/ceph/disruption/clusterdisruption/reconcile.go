@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
@@ -91,7 +92,7 @@ func (r *ReconcileClusterDisruption) reconcile(request reconcile.Request) (recon
 		return reconcile.Result{Requeue: false}, nil
 	}
 
-	cephCluster := cephClusters.Items[0]
+	cephCluster := *opcontroller.NamespaceCephCluster(cephClusters.Items)
 
 	// update the clustermap with the cluster's name so that
 	// events on resources associated with the cluster can trigger reconciliation by namespace
@@ -134,13 +135,19 @@ func (r *ReconcileClusterDisruption) reconcile(request reconcile.Request) (recon
 	}
 
 	// reconcile the pdbs for objectstores
-	err = r.reconcileCephObjectStore(cephObjectStoreList)
+	err = r.reconcileCephObjectStore(cephObjectStoreList, cephCluster.Spec.DisruptionManagement.RGW)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	// reconcile the pdbs for filesystems
-	err = r.reconcileCephFilesystem(cephFilesystemList)
+	err = r.reconcileCephFilesystem(cephFilesystemList, cephCluster.Spec.DisruptionManagement.MDS)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// reconcile the pdbs for nfs servers
+	err = r.reconcileCephNFS(request, cephCluster.Spec.DisruptionManagement.NFS)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -156,13 +163,19 @@ func (r *ReconcileClusterDisruption) reconcile(request reconcile.Request) (recon
 		return reconcile.Result{}, err
 	}
 
+	// stop the osds of the nodes in maintenance and restart them when the maintenance ends
+	maintenanceHosts, err := r.reconcileMaintenance(clusterInfo, osdDataList)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile the node maintenance")
+	}
+
 	// get the list of nodes with ongoing drains
 	drainingNodes, err := r.getOngoingDrains(request)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	drainingOSDs, err := getOSDsForNodes(osdDataList, drainingNodes, poolFailureDomain)
+	drainingOSDs, err := getOSDsForNodes(osdDataList, drainingNodes, poolFailureDomain, cephCluster.Spec.CrushTopology.Labels)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -182,12 +195,12 @@ func (r *ReconcileClusterDisruption) reconcile(request reconcile.Request) (recon
 		return reconcile.Result{}, err
 	}
 
-	err = r.reconcilePDBsForOSDs(clusterInfo, request, pdbStateMap, poolFailureDomain, allFailureDomainsMap, drainingFailureDomainsMap)
+	err = r.reconcilePDBsForOSDs(clusterInfo, request, pdbStateMap, poolFailureDomain, allFailureDomainsMap, drainingFailureDomainsMap, maintenanceHosts)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 	disabledPDB, ok := pdbStateMap.Data[disabledPDBKey]
-	if ok && len(disabledPDB) > 0 {
+	if (ok && len(disabledPDB) > 0) || len(maintenanceHosts) > 0 {
 		return reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
 	}
 	return reconcile.Result{}, nil
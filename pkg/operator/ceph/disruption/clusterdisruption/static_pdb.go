@@ -19,6 +19,8 @@ package clusterdisruption
 import (
 	"context"
 
+	"github.com/rook/rook/pkg/operator/k8sutil"
+
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -30,6 +32,7 @@ const (
 )
 
 func (r *ReconcileClusterDisruption) createStaticPDB(request types.NamespacedName, pdb *policyv1beta1.PodDisruptionBudget) error {
+	r.applyInheritedMetadata(pdb)
 	err := r.client.Create(context.TODO(), pdb)
 	if err != nil {
 		return err
@@ -56,3 +59,12 @@ func (r *ReconcileClusterDisruption) reconcileStaticPDB(request types.Namespaced
 	}
 	return nil
 }
+
+// applyInheritedMetadata adds the labels and annotations inherited from the cluster in the namespace of the pdb
+func (r *ReconcileClusterDisruption) applyInheritedMetadata(pdb *policyv1beta1.PodDisruptionBudget) {
+	cephCluster, ok := r.clusterMap.GetCluster(pdb.Namespace)
+	if !ok {
+		return
+	}
+	k8sutil.ApplyClusterInheritedMetadata(cephCluster.Spec.InheritedMetadata.Labels, cephCluster.Spec.InheritedMetadata.Annotations, pdb)
+}
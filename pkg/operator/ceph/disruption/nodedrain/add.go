@@ -20,6 +20,8 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
 	"github.com/rook/rook/pkg/operator/k8sutil"
@@ -47,6 +49,11 @@ const (
 
 // Add adds a new Controller based on nodedrain.ReconcileNode and registers the relevant watches and handlers
 func Add(mgr manager.Manager, context *controllerconfig.Context) error {
+	// Add the cephv1 scheme to the manager scheme to read the topology labels of the clusters
+	if err := cephv1.AddToScheme(mgr.GetScheme()); err != nil {
+		return errors.Wrap(err, "failed to add ceph scheme to manager scheme")
+	}
+
 	reconcileNode := &ReconcileNode{
 		client:  mgr.GetClient(),
 		scheme:  mgr.GetScheme(),
@@ -27,6 +27,7 @@ import (
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	"github.com/rook/rook/pkg/operator/ceph/disruption/controllerconfig"
 	"github.com/rook/rook/pkg/operator/k8sutil"
@@ -117,8 +118,11 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 	uniqueTolerations := controllerconfig.TolerationSet{}
 
 	occupiedByOSD := false
+	// the namespaces of the clusters with osds on the node
+	clusterNamespaces := map[string]struct{}{}
 	for _, osdPod := range osdPodList.Items {
 		if osdPod.Spec.NodeName == request.Name {
+			clusterNamespaces[osdPod.GetNamespace()] = struct{}{}
 			labels := osdPod.GetLabels()
 			deploymentList := &appsv1.DeploymentList{}
 			labelSelector := map[string]string{
@@ -152,6 +156,11 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 		}
 	}
 
+	topologyLabels, err := r.crushTopologyLabels(clusterNamespaces)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// CreateOrUpdate the deployment
 	mutateFunc := func() error {
 
@@ -193,7 +202,7 @@ func (r *ReconcileNode) reconcile(request reconcile.Request) (reconcile.Result,
 		deploy.ObjectMeta.OwnerReferences = ownerReferences
 
 		// update the deployment labels
-		topology := osd.ExtractOSDTopologyFromLabels(node.GetLabels())
+		topology := osd.ExtractCrushTopologyFromLabels(node.GetLabels(), topologyLabels)
 		for key, value := range topology {
 			selectorLabels[key] = value
 		}
@@ -289,3 +298,19 @@ func getDeploymentForPod(client client.Client, podKey types.NamespacedName) (*ap
 	}
 	return nil, errors.Errorf("could not get deployment for pod %+v", podKey)
 }
+
+// crushTopologyLabels returns the custom topology labels of the clusters in the namespaces, so the canary of a node
+// has the same CRUSH location as its osds
+func (r *ReconcileNode) crushTopologyLabels(namespaces map[string]struct{}) ([]cephv1.CrushTopologyLabelSpec, error) {
+	topologyLabels := []cephv1.CrushTopologyLabelSpec{}
+	for namespace := range namespaces {
+		cephClusters := &cephv1.CephClusterList{}
+		if err := r.client.List(context.TODO(), cephClusters, client.InNamespace(namespace)); err != nil {
+			return nil, errors.Wrapf(err, "could not list the ceph clusters in namespace %q", namespace)
+		}
+		for _, cephCluster := range cephClusters.Items {
+			topologyLabels = append(topologyLabels, cephCluster.Spec.CrushTopology.Labels...)
+		}
+	}
+	return topologyLabels, nil
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/file/mds"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -38,6 +40,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -69,10 +72,12 @@ var controllerTypeMeta = metav1.TypeMeta{
 // ReconcileCephFilesystem reconciles a CephFilesystem object
 type ReconcileCephFilesystem struct {
 	client          client.Client
+	recorder        record.EventRecorder
 	scheme          *runtime.Scheme
 	context         *clusterd.Context
 	cephClusterSpec *cephv1.ClusterSpec
 	clusterInfo     *cephclient.ClusterInfo
+	mirrors         *mirrorMonitors
 }
 
 // Add creates a new CephFilesystem Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -89,15 +94,17 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 		panic(err)
 	}
 	return &ReconcileCephFilesystem{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
+		mirrors:  &mirrorMonitors{channels: make(map[string]*mirrorHealth)},
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -129,7 +136,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileCephFilesystem) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephFilesystem{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephFilesystem{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -202,6 +214,7 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 	// DELETE: the CR was deleted
 	if !cephFilesystem.GetDeletionTimestamp().IsZero() {
 		logger.Debugf("deleting filesystem %q", cephFilesystem.Name)
+		r.stopMonitoring(request.NamespacedName)
 		err = r.reconcileDeleteFilesystem(cephFilesystem)
 		if err != nil {
 			return reconcile.Result{}, errors.Wrapf(err, "failed to delete filesystem %q. ", cephFilesystem.Name)
@@ -233,6 +246,30 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
 
+	effectiveSpec := cephFilesystem.Spec.DeepCopy()
+	effectiveSpec.MetadataServer = effectiveSpec.MetadataServer.WithClusterDefaults(r.cephClusterSpec)
+	opcontroller.ExportEffectiveSpec(r.client, cephFilesystem, effectiveSpec)
+
+	// Report the snapshot mirroring of the filesystem in its status
+	healthCheck := cephCluster.Spec.HealthCheck.DaemonHealth.FilesystemMirror
+	if !mirroringEnabled(cephFilesystem) || healthCheck.Disabled {
+		r.stopMonitoring(request.NamespacedName)
+		if !mirroringEnabled(cephFilesystem) && cephFilesystem.Status != nil && cephFilesystem.Status.Mirroring != nil {
+			updateMirroringStatus(r.client, request.NamespacedName, nil)
+		}
+	} else {
+		r.startMonitoring(request.NamespacedName, healthCheck)
+	}
+
+	// Restart the metadata servers when requested
+	restarting, err := r.restartMetadataServers(cephFilesystem)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to restart the metadata servers of filesystem %q", cephFilesystem.Name)
+	}
+	if restarting {
+		return opcontroller.WaitForRequeueIfRestartInProgress, nil
+	}
+
 	// Return and do not requeue
 	logger.Debug("done reconciling")
 	return reconcile.Result{}, nil
@@ -292,7 +329,7 @@ func updateStatus(client client.Client, name types.NamespacedName, status string
 	}
 
 	if fs.Status == nil {
-		fs.Status = &cephv1.Status{}
+		fs.Status = &cephv1.CephFilesystemStatus{}
 	}
 
 	fs.Status.Phase = status
@@ -302,3 +339,22 @@ func updateStatus(client client.Client, name types.NamespacedName, status string
 	}
 	logger.Debugf("filesystem %q status updated to %q", name, status)
 }
+
+// restartMetadataServers continues the rolling restart of the metadata servers of the filesystem requested with the
+// annotation of the filesystem, and returns whether the restart is still in progress
+func (r *ReconcileCephFilesystem) restartMetadataServers(cephFilesystem *cephv1.CephFilesystem) (bool, error) {
+	daemons := []opcontroller.RestartDaemon{{
+		Type:     "mds",
+		Selector: fmt.Sprintf("%s=%s,rook_file_system=%s", k8sutil.AppAttr, mds.AppName, cephFilesystem.Name),
+		IDLabel:  "mds",
+	}}
+	pending, err := opcontroller.PendingRestart(cephFilesystem, daemons)
+	if err != nil || pending == nil {
+		return false, err
+	}
+	done, err := opcontroller.RestartNextDaemon(r.context, r.clusterInfo, r.client, cephFilesystem, pending)
+	if err != nil || !done {
+		return !done, err
+	}
+	return false, opcontroller.CompleteRestart(r.client, r.recorder, cephFilesystem)
+}
@@ -87,6 +87,10 @@ func createFilesystem(
 		return err
 	}
 
+	if err := reconcileMirroring(context, clusterInfo, fs); err != nil {
+		return errors.Wrapf(err, "failed to configure the snapshot mirroring of filesystem %q", fs.Name)
+	}
+
 	return nil
 }
 
@@ -148,6 +152,9 @@ func validateFilesystem(context *clusterd.Context, clusterInfo *client.ClusterIn
 	if f.Spec.MetadataServer.ActiveCount < 1 {
 		return errors.New("MetadataServer.ActiveCount must be at least 1")
 	}
+	if err := validateMirroring(clusterInfo, f); err != nil {
+		return err
+	}
 	// No data pool means that we expect the fs to exist already
 	if len(f.Spec.DataPools) == 0 {
 		return nil
@@ -254,16 +261,12 @@ func (f *Filesystem) doFilesystemCreate(context *clusterd.Context, clusterInfo *
 		poolName := dataPoolNames[i]
 		if _, poolFound := reversedPoolMap[poolName]; !poolFound {
 			poolsCreated = true
+			// An erasure coded data pool used for a filesystem must allow overwrites, which is set by the creation
+			// of the pool
 			err = client.CreatePoolWithProfile(context, clusterInfo, poolName, pool, "")
 			if err != nil {
 				return errors.Wrapf(err, "failed to create data pool %q", poolName)
 			}
-			if pool.IsErasureCoded() {
-				// An erasure coded data pool used for a filesystem must allow overwrites
-				if err := client.SetPoolProperty(context, clusterInfo, poolName, "allow_ec_overwrites", "true"); err != nil {
-					logger.Warningf("failed to set ec pool property. %v", err)
-				}
-			}
 		}
 	}
 
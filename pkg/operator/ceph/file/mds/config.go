@@ -60,11 +60,11 @@ func (c *Cluster) generateKeyring(m *mdsConfig) (string, error) {
 	}
 
 	keyring := fmt.Sprintf(keyringTemplate, m.DaemonID, key)
-	return keyring, s.CreateOrUpdate(m.ResourceName, keyring)
+	return keyring, s.CreateOrUpdateWithMeta(m.ResourceName, keyring, c.fs.Spec.MetadataServer.Annotations, c.fs.Spec.MetadataServer.Labels)
 }
 
 func (c *Cluster) setDefaultFlagsMonConfigStore(mdsID string) error {
-	monStore := config.GetMonStore(c.context, c.clusterInfo)
+	configStore := config.GetDaemonConfigStore(c.context, c.clusterInfo)
 	who := fmt.Sprintf("mds.%s", mdsID)
 	configOptions := make(map[string]string)
 
@@ -79,12 +79,9 @@ func (c *Cluster) setDefaultFlagsMonConfigStore(mdsID string) error {
 		configOptions["mds_join_fs"] = c.fs.Name
 	}
 
-	for flag, val := range configOptions {
-		err := monStore.Set(who, flag, val)
-		if err != nil {
-			return errors.Wrapf(err, "failed to set %q to %q on %q", flag, val, who)
-		}
+	// the cache memory limit is removed with the memory limit of the daemons
+	if _, err := configStore.Apply(who, configOptions, "mds_cache_memory_limit", "mds_join_fs"); err != nil {
+		return errors.Wrapf(err, "failed to configure %q", who)
 	}
-
 	return nil
 }
@@ -84,6 +84,7 @@ func NewCluster(
 	dataDirHostPath string,
 	scheme *runtime.Scheme,
 ) *Cluster {
+	fs.Spec.MetadataServer = fs.Spec.MetadataServer.WithClusterDefaults(clusterSpec)
 	return &Cluster{
 		clusterInfo:     clusterInfo,
 		context:         context,
@@ -135,7 +136,7 @@ func (c *Cluster) Start() error {
 		mdsConfig := &mdsConfig{
 			ResourceName: resourceName,
 			DaemonID:     daemonName,
-			DataPathMap:  config.NewStatelessDaemonDataPathMap(config.MdsType, daemonName, c.fs.Namespace, c.dataDirHostPath),
+			DataPathMap:  config.NewStatelessDaemonDataPathMap(config.MdsType, daemonName, c.fs.Namespace, c.dataDirHostPath).WithHostSocketDir(c.clusterSpec.Monitoring.IsExporterEnabled()),
 		}
 
 		// create unique key for each mds saved to k8s secret
@@ -188,7 +189,7 @@ func (c *Cluster) Start() error {
 		}
 
 		if createErr != nil && kerrors.IsAlreadyExists(createErr) {
-			if err = UpdateDeploymentAndWait(c.context, c.clusterInfo, d, config.MdsType, daemonLetterID, c.clusterSpec.SkipUpgradeChecks, c.clusterSpec.ContinueUpgradeAfterChecksEvenIfNotHealthy); err != nil {
+			if err = UpdateDeploymentAndWait(c.context, c.clusterInfo, d, config.MdsType, daemonLetterID, cephclient.DaemonUpgradeChecks(c.clusterSpec, config.MdsType)); err != nil {
 				return errors.Wrapf(err, "failed to update mds deployment %s", d.Name)
 			}
 		}
@@ -259,9 +260,9 @@ func (c *Cluster) scaleDownDeployments(replicas int32, desiredDeployments map[st
 }
 
 func (c *Cluster) DeleteMdsCephObjects(mdsID string) error {
-	monStore := config.GetMonStore(c.context, c.clusterInfo)
+	configStore := config.GetDaemonConfigStore(c.context, c.clusterInfo)
 	who := fmt.Sprintf("mds.%s", mdsID)
-	err := monStore.DeleteDaemon(who)
+	_, err := configStore.Remove(who)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete mds config for %q in mon configuration database", who)
 	}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/config"
@@ -55,12 +56,16 @@ func (c *Cluster) makeDeployment(mdsConfig *mdsConfig) (*apps.Deployment, error)
 			Volumes:           controller.DaemonVolumes(mdsConfig.DataPathMap, mdsConfig.ResourceName),
 			HostNetwork:       c.clusterSpec.Network.IsHost(),
 			PriorityClassName: c.fs.Spec.MetadataServer.PriorityClassName,
+			SecurityContext:   cephv1.GetPodSecurityContext(c.clusterSpec.Security.PodSecurityContexts, cephv1.KeyMDS),
 		},
 	}
+	controller.ApplyLogCollector(&podSpec.Spec, config.MdsType, "mds."+mdsConfig.DaemonID, c.clusterSpec, mdsConfig.DataPathMap)
+
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec.Spec)
 
 	c.fs.Spec.MetadataServer.Annotations.ApplyToObjectMeta(&podSpec.ObjectMeta)
+	config.ApplyConnectionsAnnotation(c.clusterSpec.Network, &podSpec.ObjectMeta)
 	c.fs.Spec.MetadataServer.Placement.ApplyToPodSpec(&podSpec.Spec)
 
 	replicas := int32(1)
@@ -90,9 +95,13 @@ func (c *Cluster) makeDeployment(mdsConfig *mdsConfig) (*apps.Deployment, error)
 		}
 	}
 
+	// the labels are not added to the selector of the deployment which is immutable
+	c.fs.Spec.MetadataServer.Labels.ApplyToObjectMeta(&d.Spec.Template.ObjectMeta)
 	k8sutil.AddRookVersionLabelToDeployment(d)
 	c.fs.Spec.MetadataServer.Annotations.ApplyToObjectMeta(&d.ObjectMeta)
+	c.fs.Spec.MetadataServer.Labels.ApplyToObjectMeta(&d.ObjectMeta)
 	controller.AddCephVersionLabelToDeployment(c.clusterInfo.CephVersion, d)
+	k8sutil.ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
 
 	return d, nil
 }
@@ -129,7 +138,7 @@ func (c *Cluster) makeMdsDaemonContainer(mdsConfig *mdsConfig) v1.Container {
 		LivenessProbe:   controller.GenerateLivenessProbeExecDaemon(config.MdsType, mdsConfig.DaemonID),
 	}
 
-	return container
+	return config.ConfigureProbes(cephv1.KeyMDS, container, c.clusterSpec.HealthCheck)
 }
 
 func (c *Cluster) podLabels(mdsConfig *mdsConfig) map[string]string {
@@ -23,6 +23,7 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/config"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
@@ -55,6 +56,7 @@ func testDeploymentObject(t *testing.T, network cephv1.NetworkSpec) (*apps.Deplo
 					},
 				},
 				PriorityClassName: "my-priority-class",
+				Labels:            rookv1.Labels{"mesh": "enabled"},
 			},
 		},
 	}
@@ -100,6 +102,11 @@ func TestPodSpecs(t *testing.T) {
 	podTemplate.RunFullSuite(config.MdsType, "myfs-a", "rook-ceph-mds", "ns", "ceph/ceph:testversion",
 		"500", "250", "4337", "2169", /* resources */
 		"my-priority-class")
+
+	// the labels of the mds are not added to the immutable selector of the deployment
+	assert.Equal(t, "enabled", d.Labels["mesh"])
+	assert.Equal(t, "enabled", d.Spec.Template.Labels["mesh"])
+	assert.Equal(t, "", d.Spec.Selector.MatchLabels["mesh"])
 }
 
 func TestHostNetwork(t *testing.T) {
@@ -0,0 +1,284 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	mirroringModuleName = "mirroring"
+
+	defaultMirrorStatusCheckInterval = 60 * time.Second
+)
+
+type mirrorHealth struct {
+	stopChan chan struct{}
+}
+
+// mirrorMonitors are the channels of the mirroring status checkers by filesystem, shared by the copies of the
+// reconciler
+type mirrorMonitors struct {
+	channels map[string]*mirrorHealth
+	mutex    sync.Mutex
+}
+
+// mirroringEnabled returns whether the snapshot mirroring of the filesystem is enabled in its spec
+func mirroringEnabled(fs *cephv1.CephFilesystem) bool {
+	return fs.Spec.Mirroring != nil && fs.Spec.Mirroring.Enabled
+}
+
+// validateMirroring checks the snapshot mirroring settings of a filesystem
+func validateMirroring(clusterInfo *cephclient.ClusterInfo, fs *cephv1.CephFilesystem) error {
+	if !mirroringEnabled(fs) {
+		return nil
+	}
+	if !clusterInfo.CephVersion.IsAtLeastPacific() {
+		return errors.Errorf("the snapshot mirroring of filesystem %q requires ceph pacific, the ceph version is %q", fs.Name, clusterInfo.CephVersion.String())
+	}
+	for _, dir := range fs.Spec.Mirroring.Directories {
+		if !path.IsAbs(dir) {
+			return errors.Errorf("invalid mirrored directory %q of filesystem %q, the path must be absolute", dir, fs.Name)
+		}
+	}
+	return nil
+}
+
+// reconcileMirroring enables the snapshot mirroring of the filesystem and adds its directories to the mirroring, or
+// disables the mirroring when it is disabled in the spec. The mirroring is not changed when the spec has no mirroring
+// settings.
+func reconcileMirroring(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, fs cephv1.CephFilesystem) error {
+	if fs.Spec.Mirroring == nil {
+		return nil
+	}
+	if !fs.Spec.Mirroring.Enabled {
+		// the mirroring only exists from pacific, and the mirroring module may not be enabled
+		if clusterInfo.CephVersion.IsAtLeastPacific() {
+			if err := cephclient.DisableFilesystemMirroring(context, clusterInfo, fs.Name); err != nil {
+				logger.Warningf("failed to disable the snapshot mirroring of filesystem %q. %v", fs.Name, err)
+			}
+		}
+		return nil
+	}
+
+	if err := cephclient.MgrEnableModule(context, clusterInfo, mirroringModuleName, false); err != nil {
+		return errors.Wrapf(err, "failed to enable mgr module %q", mirroringModuleName)
+	}
+	if err := cephclient.EnableFilesystemMirroring(context, clusterInfo, fs.Name); err != nil {
+		return err
+	}
+	for _, dir := range fs.Spec.Mirroring.Directories {
+		if err := cephclient.AddFilesystemMirrorDirectory(context, clusterInfo, fs.Name, dir); err != nil {
+			return err
+		}
+	}
+	logger.Infof("snapshot mirroring of filesystem %q enabled for directories %v", fs.Name, fs.Spec.Mirroring.Directories)
+	return nil
+}
+
+// mirrorStatusChecker reports the snapshot mirroring of a filesystem in the status of the CR
+type mirrorStatusChecker struct {
+	context        *clusterd.Context
+	clusterInfo    *cephclient.ClusterInfo
+	client         client.Client
+	namespacedName types.NamespacedName
+	interval       time.Duration
+}
+
+// newMirrorStatusChecker creates a checker of the snapshot mirroring of a filesystem
+func newMirrorStatusChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, client client.Client, namespacedName types.NamespacedName, healthCheck cephv1.HealthCheckSpec) *mirrorStatusChecker {
+	c := &mirrorStatusChecker{
+		context:        context,
+		clusterInfo:    clusterInfo,
+		client:         client,
+		namespacedName: namespacedName,
+		interval:       defaultMirrorStatusCheckInterval,
+	}
+
+	// allow overriding the check interval
+	if healthCheck.Interval != "" {
+		if duration, err := time.ParseDuration(healthCheck.Interval); err == nil {
+			logger.Infof("filesystem %q mirroring status check interval %q", namespacedName.String(), healthCheck.Interval)
+			c.interval = duration
+		}
+	}
+	return c
+}
+
+// checkMirrorStatus periodically updates the mirroring status of the filesystem
+func (c *mirrorStatusChecker) checkMirrorStatus(stopCh chan struct{}) {
+	// check the status immediately before starting the loop
+	c.checkStatus()
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping monitoring of the mirroring of filesystem %q", c.namespacedName.String())
+			return
+
+		case <-time.After(c.interval):
+			logger.Debugf("checking mirroring status of filesystem %q", c.namespacedName.String())
+			c.checkStatus()
+		}
+	}
+}
+
+func (c *mirrorStatusChecker) checkStatus() {
+	fs := &cephv1.CephFilesystem{}
+	if err := c.client.Get(context.TODO(), c.namespacedName, fs); err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Warningf("failed to get filesystem %q to check its mirroring. %v", c.namespacedName.String(), err)
+		}
+		return
+	}
+	if !mirroringEnabled(fs) {
+		return
+	}
+	status := mirroringStatus(c.context, c.clusterInfo, fs.Name, fs.Spec.Mirroring.Directories)
+	updateMirroringStatus(c.client, c.namespacedName, status)
+}
+
+// mirroringStatus collects the sync status of the peers of the filesystem from the cephfs-mirror daemons, and the
+// mapping and the last scheduled snapshot of the mirrored directories. The errors are reported in the details of the
+// status.
+func mirroringStatus(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, fsName string, directories []string) *cephv1.FilesystemMirroringStatus {
+	status := &cephv1.FilesystemMirroringStatus{LastChecked: time.Now().UTC().Format(time.RFC3339)}
+	details := []string{}
+
+	daemons, err := cephclient.GetFilesystemMirrorDaemonStatus(context, clusterInfo)
+	if err != nil {
+		details = append(details, err.Error())
+	}
+	mirrored := false
+	for _, daemon := range daemons {
+		for _, fs := range daemon.Filesystems {
+			if fs.Name != fsName {
+				continue
+			}
+			mirrored = true
+			for _, peer := range fs.Peers {
+				status.Peers = append(status.Peers, cephv1.FilesystemMirrorPeerStatus{
+					DaemonID:      daemon.DaemonID,
+					UUID:          peer.UUID,
+					Remote:        fmt.Sprintf("%s@%s:%s", peer.Remote.ClientName, peer.Remote.ClusterName, peer.Remote.FSName),
+					FailureCount:  peer.Stats.FailureCount,
+					RecoveryCount: peer.Stats.RecoveryCount,
+				})
+			}
+		}
+	}
+	if err == nil && !mirrored {
+		details = append(details, "no cephfs-mirror daemon mirrors the filesystem")
+	}
+
+	for _, dir := range directories {
+		dirStatus := cephv1.FilesystemMirrorDirectoryStatus{Path: dir}
+		mapping, err := cephclient.GetFilesystemMirrorDirectory(context, clusterInfo, fsName, dir)
+		if err != nil {
+			details = append(details, err.Error())
+		} else {
+			dirStatus.State = mapping.State
+			dirStatus.InstanceID = mapping.InstanceID
+		}
+		schedules, err := cephclient.GetSnapshotSchedules(context, clusterInfo, fsName, dir)
+		if err != nil {
+			details = append(details, err.Error())
+		}
+		for _, schedule := range schedules {
+			dirStatus.Schedules = append(dirStatus.Schedules, schedule.Schedule)
+			// the times are formatted the same way, the most recent is the greatest
+			if schedule.Last > dirStatus.LastScheduledSnapshot {
+				dirStatus.LastScheduledSnapshot = schedule.Last
+			}
+		}
+		sort.Strings(dirStatus.Schedules)
+		status.Directories = append(status.Directories, dirStatus)
+	}
+	status.Details = strings.Join(details, "; ")
+	return status
+}
+
+// updateMirroringStatus sets the mirroring status of a filesystem CR, the status is removed when nil
+func updateMirroringStatus(client client.Client, name types.NamespacedName, status *cephv1.FilesystemMirroringStatus) {
+	fs := &cephv1.CephFilesystem{}
+	if err := client.Get(context.TODO(), name, fs); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephFilesystem resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve filesystem %q to update its mirroring status. %v", name, err)
+		return
+	}
+
+	if fs.Status == nil {
+		fs.Status = &cephv1.CephFilesystemStatus{}
+	}
+	fs.Status.Mirroring = status
+	if err := opcontroller.UpdateStatus(client, fs); err != nil {
+		logger.Warningf("failed to update the mirroring status of filesystem %q. %v", name, err)
+		return
+	}
+	logger.Debugf("filesystem %q mirroring status updated", name)
+}
+
+func (r *ReconcileCephFilesystem) startMonitoring(fsName types.NamespacedName, healthCheck cephv1.HealthCheckSpec) {
+	if r.mirrors == nil {
+		return
+	}
+	r.mirrors.mutex.Lock()
+	defer r.mirrors.mutex.Unlock()
+	key := fsName.String()
+	if _, ok := r.mirrors.channels[key]; ok {
+		logger.Debugf("mirroring status checker of filesystem %q already running", key)
+		return
+	}
+
+	r.mirrors.channels[key] = &mirrorHealth{stopChan: make(chan struct{})}
+	checker := newMirrorStatusChecker(r.context, r.clusterInfo, r.client, fsName, healthCheck)
+	logger.Infof("starting mirroring status checker of filesystem %q", key)
+	go checker.checkMirrorStatus(r.mirrors.channels[key].stopChan)
+}
+
+func (r *ReconcileCephFilesystem) stopMonitoring(fsName types.NamespacedName) {
+	if r.mirrors == nil {
+		return
+	}
+	r.mirrors.mutex.Lock()
+	defer r.mirrors.mutex.Unlock()
+	key := fsName.String()
+	health, ok := r.mirrors.channels[key]
+	if !ok {
+		return
+	}
+	close(health.stopChan)
+	delete(r.mirrors.channels, key)
+}
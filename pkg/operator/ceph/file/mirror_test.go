@@ -0,0 +1,133 @@
+/*
+Copyright 2016 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateMirroring(t *testing.T) {
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", CephVersion: cephver.Pacific}
+	fs := &cephv1.CephFilesystem{ObjectMeta: metav1.ObjectMeta{Name: "myfs", Namespace: "ns"}}
+	assert.NoError(t, validateMirroring(clusterInfo, fs))
+
+	fs.Spec.Mirroring = &cephv1.FilesystemMirroringSpec{Enabled: true, Directories: []string{"/volumes"}}
+	assert.NoError(t, validateMirroring(clusterInfo, fs))
+
+	// the directories are absolute paths
+	fs.Spec.Mirroring.Directories = []string{"volumes"}
+	assert.Error(t, validateMirroring(clusterInfo, fs))
+
+	// the mirroring requires pacific
+	fs.Spec.Mirroring.Directories = nil
+	clusterInfo.CephVersion = cephver.Octopus
+	assert.Error(t, validateMirroring(clusterInfo, fs))
+	fs.Spec.Mirroring.Enabled = false
+	assert.NoError(t, validateMirroring(clusterInfo, fs))
+}
+
+func TestReconcileMirroring(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			// the command without the connection args
+			cephCommand := []string{}
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "--") {
+					break
+				}
+				cephCommand = append(cephCommand, arg)
+			}
+			commands = append(commands, strings.Join(cephCommand, " "))
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns", CephVersion: cephver.Pacific}
+	fs := cephv1.CephFilesystem{ObjectMeta: metav1.ObjectMeta{Name: "myfs", Namespace: "ns"}}
+
+	// the mirroring is not changed without settings
+	assert.NoError(t, reconcileMirroring(context, clusterInfo, fs))
+	assert.Empty(t, commands)
+
+	fs.Spec.Mirroring = &cephv1.FilesystemMirroringSpec{Enabled: true, Directories: []string{"/volumes", "/archive"}}
+	assert.NoError(t, reconcileMirroring(context, clusterInfo, fs))
+	assert.Equal(t, []string{
+		"mgr module enable mirroring",
+		"fs snapshot mirror enable myfs",
+		"fs snapshot mirror add myfs /volumes",
+		"fs snapshot mirror add myfs /archive",
+	}, commands)
+
+	commands = []string{}
+	fs.Spec.Mirroring.Enabled = false
+	assert.NoError(t, reconcileMirroring(context, clusterInfo, fs))
+	assert.Equal(t, []string{"fs snapshot mirror disable myfs"}, commands)
+}
+
+func TestMirroringStatus(t *testing.T) {
+	daemonStatus := `[{"daemon_id":4115,"filesystems":[{"filesystem_id":1,"name":"myfs","directory_count":2,
+		"peers":[{"uuid":"a2dc7784","remote":{"client_name":"client.mirror","cluster_name":"site-b","fs_name":"backup"},
+		"stats":{"failure_count":1,"recovery_count":0}}]}]}]`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			switch {
+			case args[0] == "fs" && args[1] == "snapshot" && args[3] == "daemon":
+				return daemonStatus, nil
+			case args[0] == "fs" && args[1] == "snapshot" && args[3] == "dirmap":
+				if args[5] == "/archive" {
+					return "", errors.New("directory not tracked")
+				}
+				return `{"instance_id":"25184","last_shuffled":1618367512.0,"state":"mapped"}`, nil
+			case args[0] == "fs" && args[1] == "snap-schedule":
+				if args[3] == "/archive" {
+					return "[]", nil
+				}
+				return `[{"path":"/volumes","schedule":"1h","last":"2021-04-14T12:00:00","created_count":2,"active":true},
+					{"path":"/volumes","schedule":"1d","last":"2021-04-14T00:00:00","created_count":1,"active":true}]`, nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "ns"}
+
+	status := mirroringStatus(context, clusterInfo, "myfs", []string{"/volumes", "/archive"})
+	assert.Equal(t, []cephv1.FilesystemMirrorPeerStatus{{DaemonID: 4115, UUID: "a2dc7784", Remote: "client.mirror@site-b:backup", FailureCount: 1}}, status.Peers)
+	assert.Equal(t, []cephv1.FilesystemMirrorDirectoryStatus{
+		{Path: "/volumes", State: "mapped", InstanceID: "25184", Schedules: []string{"1d", "1h"}, LastScheduledSnapshot: "2021-04-14T12:00:00"},
+		{Path: "/archive"},
+	}, status.Directories)
+	assert.Contains(t, status.Details, "directory not tracked")
+	assert.NotEqual(t, "", status.LastChecked)
+
+	// the filesystem is not mirrored by any daemon
+	daemonStatus = `[{"daemon_id":4115,"filesystems":[]}]`
+	status = mirroringStatus(context, clusterInfo, "myfs", nil)
+	assert.Empty(t, status.Peers)
+	assert.Equal(t, "no cephfs-mirror daemon mirrors the filesystem", status.Details)
+}
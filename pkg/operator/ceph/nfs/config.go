@@ -18,15 +18,100 @@ package nfs
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 )
 
 const (
 	userID = "admin"
+
+	keyringTemplate = `
+[client.%s]
+	key = %s
+	caps mon = "allow r"
+	caps osd = "%s"
+	caps mds = "allow rw"
+`
 )
 
+// getGaneshaUserID returns the ceph user of the ganesha servers, the admin unless the admin key is restricted
+func (r *ReconcileCephNFS) getGaneshaUserID(n *cephv1.CephNFS) string {
+	if r.cephClusterSpec.Security.RestrictAdminKey {
+		return fmt.Sprintf("nfs-ganesha.%s", n.Name)
+	}
+	return userID
+}
+
+func getKeyringResourceName(n *cephv1.CephNFS) string {
+	return fmt.Sprintf("%s-%s", AppName, n.Name)
+}
+
+// getGaneshaOSDCaps returns the access to the recovery and config objects, to the data of the exported filesystems
+// and to the pools of the object stores of the exported buckets
+func getGaneshaOSDCaps(n *cephv1.CephNFS) string {
+	caps := []string{fmt.Sprintf("allow rw pool=%s namespace=%s", n.Spec.RADOS.Pool, n.Spec.RADOS.Namespace)}
+	filesystems := map[string]bool{}
+	objectStores := false
+	for _, export := range n.Spec.Exports {
+		if export.ObjectStore != nil {
+			objectStores = true
+			continue
+		}
+		if !filesystems[export.Filesystem] {
+			filesystems[export.Filesystem] = true
+			caps = append(caps, fmt.Sprintf("allow rw tag cephfs data=%s", export.Filesystem))
+		}
+	}
+	if len(n.Spec.Exports) == 0 {
+		// the exports written manually may be in any filesystem
+		caps = append(caps, "allow rw tag cephfs data=*")
+	}
+	if objectStores {
+		caps = append(caps, "allow rwx tag rgw *=*")
+	}
+	return strings.Join(caps, ", ")
+}
+
+// generateKeyring creates the key of the ganesha servers when the admin key is restricted
+func (r *ReconcileCephNFS) generateKeyring(n *cephv1.CephNFS) error {
+	user := r.getGaneshaUserID(n)
+	osdCaps := getGaneshaOSDCaps(n)
+	access := []string{"mon", "allow r", "osd", osdCaps, "mds", "allow rw"}
+
+	ownerRef, err := opcontroller.GetControllerObjectOwnerReference(n, r.scheme)
+	if err != nil || ownerRef == nil {
+		return errors.Wrapf(err, "failed to get controller %q owner reference", n.Name)
+	}
+	s := keyring.GetSecretStore(r.context, r.clusterInfo, ownerRef)
+
+	key, err := s.GenerateKey("client."+user, access)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate key for user %q", user)
+	}
+
+	return s.CreateOrUpdateWithMeta(getKeyringResourceName(n), fmt.Sprintf(keyringTemplate, user, key, osdCaps), n.Spec.Server.Annotations, n.Spec.Server.Labels)
+}
+
+// removeGaneshaConfig removes the options of the user of the ganesha servers from the centralized mon configuration
+// database when the admin key is restricted, the options of the admin are kept
+func (r *ReconcileCephNFS) removeGaneshaConfig(n *cephv1.CephNFS) error {
+	user := r.getGaneshaUserID(n)
+	if user == userID {
+		return nil
+	}
+	who := "client." + user
+	if _, err := cephconfig.GetDaemonConfigStore(r.context, r.clusterInfo).Remove(who); err != nil {
+		return errors.Wrapf(err, "failed to remove the config of %q", who)
+	}
+	return nil
+}
+
 func getNFSNodeID(n *cephv1.CephNFS, name string) string {
 	return fmt.Sprintf("%s.%s", n.Name, name)
 }
@@ -46,14 +131,18 @@ func getRadosURL(n *cephv1.CephNFS, nodeID string) string {
 	return url
 }
 
-func getGaneshaConfig(n *cephv1.CephNFS, name string) string {
+func getGaneshaConfig(n *cephv1.CephNFS, name, userID string, port int32) string {
 	nodeID := getNFSNodeID(n, name)
 	url := getRadosURL(n, nodeID)
+	portParam := ""
+	if port != nfsPort {
+		portParam = fmt.Sprintf("\n\tNFS_Port = %d;", port)
+	}
 	return `
 NFS_CORE_PARAM {
 	Enable_NLM = false;
 	Enable_RQUOTA = false;
-	Protocols = 4;
+	Protocols = 4;` + portParam + `
 }
 
 MDCACHE {
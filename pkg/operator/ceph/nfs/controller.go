@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
@@ -36,6 +37,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -68,6 +70,7 @@ var controllerTypeMeta = metav1.TypeMeta{
 // ReconcileCephNFS reconciles a cephNFS object
 type ReconcileCephNFS struct {
 	client          client.Client
+	recorder        record.EventRecorder
 	scheme          *runtime.Scheme
 	context         *clusterd.Context
 	cephClusterSpec *cephv1.ClusterSpec
@@ -89,15 +92,16 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 	}
 
 	return &ReconcileCephNFS{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -129,7 +133,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileCephNFS) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephNFS{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephNFS{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -205,6 +214,9 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 		if err != nil {
 			return reconcile.Result{}, errors.Wrapf(err, "failed to delete filesystem %q. ", cephNFS.Name)
 		}
+		if err := r.removeGaneshaConfig(cephNFS); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete the config of ceph nfs %q", cephNFS.Name)
+		}
 
 		// Remove finalizer
 		err = opcontroller.RemoveFinalizer(r.client, cephNFS)
@@ -223,14 +235,23 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 
 	// CREATE/UPDATE
 	logger.Debug("reconciling ceph nfs deployments")
-	reconcileResponse, err = r.reconcileCreateCephNFS(cephNFS)
+	// the servers are deployed with the defaults of the cluster, the CR itself is left unchanged
+	nfs := cephNFS.DeepCopy()
+	nfs.Spec.Server = nfs.Spec.Server.WithClusterDefaults(r.cephClusterSpec)
+	reconcileResponse, err = r.reconcileCreateCephNFS(nfs)
 	if err != nil {
 		updateStatus(r.client, request.NamespacedName, k8sutil.FailedStatus)
 		return reconcile.Result{}, errors.Wrap(err, "failed to create ceph nfs deployments")
 	}
 
+	if err := r.reconcileExports(cephNFS); err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.FailedStatus)
+		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile ceph nfs exports")
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephNFS, nfs.Spec)
 
 	// Return and do not requeue
 	logger.Debug("done reconciling ceph nfs")
@@ -248,6 +269,12 @@ func (r *ReconcileCephNFS) reconcileCreateCephNFS(cephNFS *cephv1.CephNFS) (reco
 		}
 	}
 
+	if r.cephClusterSpec.Security.RestrictAdminKey {
+		if err := r.generateKeyring(cephNFS); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to create ceph nfs keyring")
+		}
+	}
+
 	deployments, err := r.context.Clientset.AppsV1().Deployments(cephNFS.Namespace).List(metav1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", AppName)})
 	if err != nil {
 		if kerrors.IsNotFound(err) {
@@ -0,0 +1,423 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// prefix of the RADOS objects of the exports managed by the operator, it must not conflict with the
+	// "export-<id>" objects created by the ceph dashboard
+	exportObjectPrefix = "rook-export-"
+	defaultSquash      = "none"
+	defaultAccessType  = "RW"
+	// the ids of the exports are derived from their names so that they don't change when other exports are added,
+	// removed or reordered, which would give stale file handles to the clients. They are above the ids given by the
+	// ceph dashboard.
+	minExportID = 1000
+	maxExportID = 65535
+	// the includes of the exports of the operator are delimited in the config objects of the servers, the exports
+	// added by the dashboard or by hand are kept
+	includesBeginMarker = "# BEGIN rook exports"
+	includesEndMarker   = "# END rook exports"
+)
+
+var (
+	validSquash      = []string{"none", "root", "all", "rootid"}
+	validAccessTypes = []string{"RW", "RO"}
+)
+
+func getExportObject(export cephv1.NFSExportSpec) string {
+	return exportObjectPrefix + export.Name
+}
+
+// getExportID returns the id of an export, a hash of its name
+func getExportID(export cephv1.NFSExportSpec) int {
+	h := fnv.New32a()
+	h.Write([]byte(export.Name))
+	return minExportID + int(h.Sum32()%uint32(maxExportID-minExportID+1))
+}
+
+func getExportConfig(export cephv1.NFSExportSpec, path, fsal string) string {
+	squash := export.Squash
+	if squash == "" {
+		squash = defaultSquash
+	}
+	accessType := strings.ToUpper(export.AccessType)
+	if accessType == "" {
+		accessType = defaultAccessType
+	}
+
+	return fmt.Sprintf(`
+EXPORT {
+	Export_Id = %d;
+	Path = "%s";
+	Pseudo = "%s";
+	Access_Type = %s;
+	Squash = %s;
+	Protocols = 4;
+	Transports = TCP;
+%s}
+`, getExportID(export), path, export.PseudoPath, accessType, squash, fsal)
+}
+
+// getCephFSAL returns the FSAL of an export of a subvolume group
+func getCephFSAL(userID, filesystem string) string {
+	return fmt.Sprintf(`
+	FSAL {
+		Name = CEPH;
+		User_Id = "%s";
+		Filesystem = "%s";
+	}
+`, userID, filesystem)
+}
+
+// getRGWFSAL returns the FSAL of an export of the buckets of an object store user, accessed with the keys of the user
+func getRGWFSAL(user objectStoreUser) string {
+	return fmt.Sprintf(`
+	FSAL {
+		Name = RGW;
+		User_Id = "%s";
+		Access_Key_Id = "%s";
+		Secret_Access_Key = "%s";
+	}
+`, user.userID, user.accessKey, user.secretKey)
+}
+
+// getRGWConfig returns the config of the librgw instance of the servers, in the zone of the object store of the exports
+func getRGWConfig(userID, realm, zoneGroup, zone string) string {
+	return fmt.Sprintf(`
+RGW {
+	ceph_conf = '%s';
+	name = "client.%s";
+	cluster = "ceph";
+	init_args = "--rgw-realm=%s --rgw-zonegroup=%s --rgw-zone=%s";
+}
+`, cephclient.DefaultConfigFilePath(), userID, realm, zoneGroup, zone)
+}
+
+// getExportsIncludes returns the content of the config object of the servers including all the exports, with the
+// config of librgw when buckets are exported
+func getExportsIncludes(n *cephv1.CephNFS, rgwConfig string) string {
+	includes := ""
+	for _, export := range n.Spec.Exports {
+		url := fmt.Sprintf("rados://%s/", n.Spec.RADOS.Pool)
+		if n.Spec.RADOS.Namespace != "" {
+			url += n.Spec.RADOS.Namespace + "/"
+		}
+		includes += fmt.Sprintf("%%url \"%s%s\"\n", url, getExportObject(export))
+	}
+	return includes + rgwConfig
+}
+
+// mergeExportsIncludes returns the config object of a server with the includes of the operator replacing the previous
+// ones, keeping the exports added by the dashboard or by hand
+func mergeExportsIncludes(current, includes string) string {
+	kept := []string{}
+	managed := false
+	for _, line := range strings.Split(current, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == includesBeginMarker:
+			managed = true
+		case trimmed == includesEndMarker:
+			managed = false
+		case managed:
+		case strings.HasPrefix(trimmed, "%url") && strings.Contains(trimmed, "/"+exportObjectPrefix):
+			// an include of the operator out of the markers
+		default:
+			kept = append(kept, line)
+		}
+	}
+	merged := strings.TrimSpace(strings.Join(kept, "\n"))
+	if merged != "" {
+		merged += "\n"
+	}
+	if includes != "" {
+		merged += includesBeginMarker + "\n" + includes + includesEndMarker + "\n"
+	}
+	return merged
+}
+
+func validateExports(exports []cephv1.NFSExportSpec) error {
+	names := map[string]bool{}
+	exportIDs := map[int]string{}
+	pseudoPaths := map[string]bool{}
+	objectStore := ""
+	for _, export := range exports {
+		if export.Name == "" {
+			return errors.New("missing export name")
+		}
+		if names[export.Name] {
+			return errors.Errorf("duplicate export %q", export.Name)
+		}
+		names[export.Name] = true
+		if other, ok := exportIDs[getExportID(export)]; ok {
+			return errors.Errorf("exports %q and %q have the same export id %d, rename one of them", other, export.Name, getExportID(export))
+		}
+		exportIDs[getExportID(export)] = export.Name
+
+		if o := export.ObjectStore; o != nil {
+			if export.Filesystem != "" || export.SubvolumeGroup != "" {
+				return errors.Errorf("export %q cannot export both a subvolume group and an object store", export.Name)
+			}
+			if o.Store == "" || o.User == "" {
+				return errors.Errorf("missing object store or user of export %q", export.Name)
+			}
+			// the servers run a single librgw instance, in the zone of a single object store
+			if objectStore != "" && o.Store != objectStore {
+				return errors.Errorf("export %q is in object store %q, all the exported buckets must be in object store %q", export.Name, o.Store, objectStore)
+			}
+			objectStore = o.Store
+		} else {
+			if export.Filesystem == "" {
+				return errors.Errorf("missing filesystem of export %q", export.Name)
+			}
+			if export.SubvolumeGroup == "" {
+				return errors.Errorf("missing subvolume group of export %q", export.Name)
+			}
+		}
+
+		pseudoPath := path.Clean(export.PseudoPath)
+		if !path.IsAbs(export.PseudoPath) || pseudoPath == "/" {
+			return errors.Errorf("pseudo path %q of export %q must be an absolute path other than /", export.PseudoPath, export.Name)
+		}
+		if pseudoPaths[pseudoPath] {
+			return errors.Errorf("duplicate pseudo path %q of export %q", export.PseudoPath, export.Name)
+		}
+		pseudoPaths[pseudoPath] = true
+
+		if export.Squash != "" && !contains(validSquash, export.Squash) {
+			return errors.Errorf("invalid squash %q of export %q, expected one of %v", export.Squash, export.Name, validSquash)
+		}
+		if export.AccessType != "" && !contains(validAccessTypes, strings.ToUpper(export.AccessType)) {
+			return errors.Errorf("invalid access type %q of export %q, expected one of %v", export.AccessType, export.Name, validAccessTypes)
+		}
+	}
+	return nil
+}
+
+// reconcileExports writes the config of the exports in RADOS objects included by the config object of every
+// server. The servers watch their config object and reload the exports when it changes.
+func (r *ReconcileCephNFS) reconcileExports(n *cephv1.CephNFS) error {
+	allObjects, err := r.listRADOSObjects(n)
+	if err != nil {
+		return err
+	}
+	objects := []string{}
+	for _, object := range allObjects {
+		if strings.HasPrefix(object, exportObjectPrefix) {
+			objects = append(objects, object)
+		}
+	}
+	if len(n.Spec.Exports) == 0 && len(objects) == 0 {
+		// the config objects are left untouched when the exports are not managed by the operator
+		return nil
+	}
+
+	rgwConfig := ""
+	for _, export := range n.Spec.Exports {
+		if export.ObjectStore != nil {
+			user, err := r.getObjectStoreUser(n.Namespace, export.ObjectStore)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get the user of export %q", export.Name)
+			}
+			if rgwConfig == "" {
+				if rgwConfig, err = r.getObjectStoreRGWConfig(n, export.ObjectStore.Store); err != nil {
+					return errors.Wrapf(err, "failed to get the zone of export %q", export.Name)
+				}
+			}
+			bucketPath := "/"
+			if export.ObjectStore.Bucket != "" {
+				bucketPath = export.ObjectStore.Bucket
+			}
+			if err := r.putRADOSObject(n, getExportObject(export), getExportConfig(export, bucketPath, getRGWFSAL(user))); err != nil {
+				return errors.Wrapf(err, "failed to write the config of export %q", export.Name)
+			}
+			logger.Infof("exported the buckets of user %q of object store %q at %q", export.ObjectStore.User, export.ObjectStore.Store, export.PseudoPath)
+			continue
+		}
+
+		if err := cephclient.CreateSubvolumeGroup(r.context, r.clusterInfo, export.Filesystem, export.SubvolumeGroup); err != nil {
+			return errors.Wrapf(err, "failed to create the subvolume group of export %q", export.Name)
+		}
+		groupPath, err := cephclient.GetSubvolumeGroupPath(r.context, r.clusterInfo, export.Filesystem, export.SubvolumeGroup)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve the path of export %q", export.Name)
+		}
+
+		if err := r.putRADOSObject(n, getExportObject(export), getExportConfig(export, groupPath, getCephFSAL(r.getGaneshaUserID(n), export.Filesystem))); err != nil {
+			return errors.Wrapf(err, "failed to write the config of export %q", export.Name)
+		}
+		logger.Infof("exported subvolume group %q of filesystem %q at %q", export.SubvolumeGroup, export.Filesystem, export.PseudoPath)
+	}
+
+	includes := getExportsIncludes(n, rgwConfig)
+	for i := 0; i < n.Spec.Server.Active; i++ {
+		nodeID := getNFSNodeID(n, k8sutil.IndexToName(i))
+		configObject := getGaneshaConfigObject(nodeID)
+		current := ""
+		if contains(allObjects, configObject) {
+			if current, err = r.getRADOSObject(n, configObject); err != nil {
+				return errors.Wrapf(err, "failed to read the exports of server %q", nodeID)
+			}
+		}
+		merged := mergeExportsIncludes(current, includes)
+		if strings.TrimSpace(merged) == strings.TrimSpace(current) {
+			continue
+		}
+		if err := r.putRADOSObject(n, configObject, merged); err != nil {
+			return errors.Wrapf(err, "failed to write the exports of server %q", nodeID)
+		}
+	}
+
+	// remove the exports deleted from the spec once no server includes them anymore
+	for _, object := range objects {
+		found := false
+		for _, export := range n.Spec.Exports {
+			if getExportObject(export) == object {
+				found = true
+			}
+		}
+		if !found {
+			logger.Infof("removing export object %q", object)
+			if err := r.context.Executor.ExecuteCommand("rados", append(r.radosArgs(n), "rm", object)...); err != nil {
+				return errors.Wrapf(err, "failed to remove export object %q", object)
+			}
+		}
+	}
+
+	return nil
+}
+
+// objectStoreUser is the rgw user of the buckets of an export
+type objectStoreUser struct {
+	userID    string
+	accessKey string
+	secretKey string
+}
+
+// getObjectStoreUser returns the ID and the keys of the CephObjectStoreUser of an export, from the secret of the user
+func (r *ReconcileCephNFS) getObjectStoreUser(namespace string, export *cephv1.NFSObjectStoreExportSpec) (objectStoreUser, error) {
+	u, err := r.context.RookClientset.CephV1().CephObjectStoreUsers(namespace).Get(export.User, metav1.GetOptions{})
+	if err != nil {
+		return objectStoreUser{}, errors.Wrapf(err, "failed to get object store user %q", export.User)
+	}
+	if u.Spec.Store != export.Store {
+		return objectStoreUser{}, errors.Errorf("object store user %q is in object store %q, not %q", export.User, u.Spec.Store, export.Store)
+	}
+	if u.Status == nil || u.Status.Info["secretName"] == "" {
+		return objectStoreUser{}, errors.Errorf("object store user %q is not created yet", export.User)
+	}
+	secretName := u.Status.Info["secretName"]
+	secret, err := r.context.Clientset.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return objectStoreUser{}, errors.Wrapf(err, "failed to get the secret %q of object store user %q", secretName, export.User)
+	}
+	user := objectStoreUser{
+		userID:    u.Status.Info["userID"],
+		accessKey: string(secret.Data["AccessKey"]),
+		secretKey: string(secret.Data["SecretKey"]),
+	}
+	if user.userID == "" {
+		user.userID = object.TenantUserID(u.Spec.Tenant, u.Name)
+	}
+	if user.accessKey == "" || user.secretKey == "" {
+		return objectStoreUser{}, errors.Errorf("the secret %q of object store user %q has no keys", secretName, export.User)
+	}
+	return user, nil
+}
+
+// getObjectStoreRGWConfig returns the config of librgw in the realm, the zone group and the zone of an object store
+func (r *ReconcileCephNFS) getObjectStoreRGWConfig(n *cephv1.CephNFS, name string) (string, error) {
+	store, err := r.context.RookClientset.CephV1().CephObjectStores(n.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get object store %q", name)
+	}
+	objContext, err := object.NewMultisiteContext(r.context, r.clusterInfo, store)
+	if err != nil {
+		return "", err
+	}
+	return getRGWConfig(r.getGaneshaUserID(n), objContext.Realm, objContext.ZoneGroup, objContext.Zone), nil
+}
+
+func (r *ReconcileCephNFS) radosArgs(n *cephv1.CephNFS) []string {
+	return []string{
+		"--pool", n.Spec.RADOS.Pool,
+		"--namespace", n.Spec.RADOS.Namespace,
+		"--conf", cephclient.CephConfFilePath(r.context.ConfigDir, n.Namespace),
+	}
+}
+
+func (r *ReconcileCephNFS) listRADOSObjects(n *cephv1.CephNFS) ([]string, error) {
+	output, err := r.context.Executor.ExecuteCommandWithOutput("rados", append(r.radosArgs(n), "ls")...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list RADOS objects")
+	}
+
+	objects := []string{}
+	for _, object := range strings.Split(output, "\n") {
+		object = strings.TrimSpace(object)
+		if object != "" {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+func (r *ReconcileCephNFS) getRADOSObject(n *cephv1.CephNFS, object string) (string, error) {
+	// rados writes the content of the object to stdout
+	return r.context.Executor.ExecuteCommandWithOutput("rados", append(r.radosArgs(n), "get", object, "-")...)
+}
+
+func (r *ReconcileCephNFS) putRADOSObject(n *cephv1.CephNFS, object, content string) error {
+	// rados reads the content of the object from a file
+	file, err := ioutil.TempFile("", object)
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp file")
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to write temp file %q", file.Name())
+	}
+	file.Close()
+
+	return r.context.Executor.ExecuteCommand("rados", append(r.radosArgs(n), "put", object, file.Name())...)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateExports(t *testing.T) {
+	export := cephv1.NFSExportSpec{Name: "a", Filesystem: "myfs", SubvolumeGroup: "tenant-a", PseudoPath: "/tenant-a"}
+	assert.NoError(t, validateExports(nil))
+	assert.NoError(t, validateExports([]cephv1.NFSExportSpec{export}))
+
+	invalid := export
+	invalid.PseudoPath = "tenant-a"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+	invalid.PseudoPath = "/"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+
+	invalid = export
+	invalid.SubvolumeGroup = ""
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+
+	invalid = export
+	invalid.Squash = "no_root_squash"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+	invalid.Squash = "root"
+	invalid.AccessType = "ro"
+	assert.NoError(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+	invalid.AccessType = "none"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+
+	// the names and the pseudo paths are unique
+	other := export
+	other.PseudoPath = "/tenant-b"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{export, other}))
+	other.Name = "b"
+	other.PseudoPath = "/tenant-a/"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{export, other}))
+	other.PseudoPath = "/tenant-b"
+	assert.NoError(t, validateExports([]cephv1.NFSExportSpec{export, other}))
+
+	// the buckets are exported instead of a subvolume group, from a single object store
+	buckets := cephv1.NFSExportSpec{Name: "c", PseudoPath: "/buckets", ObjectStore: &cephv1.NFSObjectStoreExportSpec{Store: "my-store", User: "my-user"}}
+	assert.NoError(t, validateExports([]cephv1.NFSExportSpec{export, buckets}))
+	invalid = buckets
+	invalid.Filesystem = "myfs"
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+	invalid = buckets
+	invalid.ObjectStore = &cephv1.NFSObjectStoreExportSpec{Store: "my-store"}
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{invalid}))
+	other = buckets
+	other.Name = "d"
+	other.PseudoPath = "/other-buckets"
+	other.ObjectStore = &cephv1.NFSObjectStoreExportSpec{Store: "other-store", User: "my-user"}
+	assert.Error(t, validateExports([]cephv1.NFSExportSpec{buckets, other}))
+}
+
+func TestReconcileExports(t *testing.T) {
+	n := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: "ns"},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "myfs-data0", Namespace: "nfs-ns"},
+			Server: cephv1.GaneshaServerSpec{Active: 2},
+		},
+	}
+
+	objects := map[string]string{}
+	removed := []string{}
+	groups := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "fs" && args[1] == "subvolumegroup" {
+				if args[2] == "create" {
+					groups = append(groups, args[4])
+					return "", nil
+				}
+				if args[2] == "getpath" {
+					return "/volumes/" + args[4] + "\n", nil
+				}
+			}
+			return "", nil
+		},
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[6] == "get" {
+				assert.Equal(t, "conf-my-nfs.a", args[7])
+				return "%url \"rados://myfs-data0/nfs-ns/export-1\"\n%url \"rados://myfs-data0/nfs-ns/rook-export-old\"", nil
+			}
+			assert.Equal(t, "ls", args[6])
+			return "conf-my-nfs.a\nrook-export-old\nexport-1\n", nil
+		},
+		MockExecuteCommand: func(command string, args ...string) error {
+			assert.Equal(t, "rados", command)
+			switch args[6] {
+			case "put":
+				content, err := ioutil.ReadFile(args[8])
+				assert.NoError(t, err)
+				objects[args[7]] = string(content)
+			case "rm":
+				removed = append(removed, args[7])
+			}
+			return nil
+		},
+	}
+	r := &ReconcileCephNFS{
+		context:         &clusterd.Context{Executor: executor},
+		clusterInfo:     cephclient.AdminClusterInfo("ns"),
+		cephClusterSpec: &cephv1.ClusterSpec{},
+	}
+
+	// nothing is written without exports, only the stale export is removed
+	active := n.Spec.Server.Active
+	n.Spec.Server.Active = 0
+	assert.NoError(t, r.reconcileExports(n))
+	assert.Equal(t, 0, len(objects))
+	assert.Equal(t, []string{"rook-export-old"}, removed)
+
+	n.Spec.Server.Active = active
+	n.Spec.Exports = []cephv1.NFSExportSpec{
+		{Name: "a", Filesystem: "myfs", SubvolumeGroup: "tenant-a", PseudoPath: "/tenant-a"},
+		{Name: "b", Filesystem: "myfs", SubvolumeGroup: "tenant-b", PseudoPath: "/tenant-b", Squash: "root", AccessType: "ro"},
+	}
+	removed = []string{}
+	assert.NoError(t, r.reconcileExports(n))
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, groups)
+	assert.Equal(t, []string{"rook-export-old"}, removed)
+	assert.Equal(t, 4, len(objects))
+
+	a := objects["rook-export-a"]
+	assert.Contains(t, a, fmt.Sprintf("Export_Id = %d;", getExportID(n.Spec.Exports[0])))
+	assert.Contains(t, a, `Path = "/volumes/tenant-a";`)
+	assert.Contains(t, a, `Pseudo = "/tenant-a";`)
+	assert.Contains(t, a, "Access_Type = RW;")
+	assert.Contains(t, a, "Squash = none;")
+	assert.Contains(t, a, `Filesystem = "myfs";`)
+	assert.Contains(t, a, `User_Id = "admin";`)
+	b := objects["rook-export-b"]
+	assert.Contains(t, b, fmt.Sprintf("Export_Id = %d;", getExportID(n.Spec.Exports[1])))
+	assert.Contains(t, b, "Access_Type = RO;")
+	assert.Contains(t, b, "Squash = root;")
+
+	// the export of the dashboard is kept
+	includes := "# BEGIN rook exports\n%url \"rados://myfs-data0/nfs-ns/rook-export-a\"\n%url \"rados://myfs-data0/nfs-ns/rook-export-b\"\n# END rook exports\n"
+	assert.Equal(t, "%url \"rados://myfs-data0/nfs-ns/export-1\"\n"+includes, objects["conf-my-nfs.a"])
+	assert.Equal(t, includes, objects["conf-my-nfs.b"])
+}
+
+func TestGetExportID(t *testing.T) {
+	a := cephv1.NFSExportSpec{Name: "a"}
+	id := getExportID(a)
+	assert.True(t, id >= minExportID && id <= maxExportID)
+	// the id only depends on the name of the export
+	assert.Equal(t, id, getExportID(cephv1.NFSExportSpec{Name: "a", PseudoPath: "/other"}))
+	assert.NotEqual(t, id, getExportID(cephv1.NFSExportSpec{Name: "b"}))
+}
+
+func TestMergeExportsIncludes(t *testing.T) {
+	includes := "%url \"rados://pool/ns/rook-export-a\"\n"
+	managed := "# BEGIN rook exports\n" + includes + "# END rook exports\n"
+
+	assert.Equal(t, "", mergeExportsIncludes("", ""))
+	assert.Equal(t, managed, mergeExportsIncludes("", includes))
+
+	// the includes of the operator are replaced, the other exports are kept
+	dashboard := "%url \"rados://pool/ns/export-1\"\n"
+	current := dashboard + "# BEGIN rook exports\n%url \"rados://pool/ns/rook-export-old\"\nRGW {\n}\n# END rook exports\n"
+	assert.Equal(t, dashboard+managed, mergeExportsIncludes(current, includes))
+	assert.Equal(t, dashboard, mergeExportsIncludes(current, ""))
+
+	// the includes written without the markers are replaced
+	assert.Equal(t, dashboard+managed, mergeExportsIncludes(dashboard+"%url \"rados://pool/ns/rook-export-old\"\n", includes))
+}
+
+func TestReconcileObjectStoreExports(t *testing.T) {
+	n := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: "ns"},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "myfs-data0", Namespace: "nfs-ns"},
+			Server: cephv1.GaneshaServerSpec{Active: 1},
+			Exports: []cephv1.NFSExportSpec{
+				{Name: "buckets", PseudoPath: "/buckets", AccessType: "RO", ObjectStore: &cephv1.NFSObjectStoreExportSpec{Store: "my-store", User: "my-user"}},
+				{Name: "logs", PseudoPath: "/logs", ObjectStore: &cephv1.NFSObjectStoreExportSpec{Store: "my-store", User: "my-user", Bucket: "logs"}},
+			},
+		},
+	}
+
+	objects := map[string]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return "", nil
+		},
+		MockExecuteCommand: func(command string, args ...string) error {
+			if args[6] == "put" {
+				content, err := ioutil.ReadFile(args[8])
+				assert.NoError(t, err)
+				objects[args[7]] = string(content)
+			}
+			return nil
+		},
+	}
+	clientset := test.New(t, 1)
+	rookClientset := rookclient.NewSimpleClientset()
+	r := &ReconcileCephNFS{
+		context:         &clusterd.Context{Executor: executor, Clientset: clientset, RookClientset: rookClientset},
+		clusterInfo:     cephclient.AdminClusterInfo("ns"),
+		cephClusterSpec: &cephv1.ClusterSpec{},
+	}
+
+	// the user must be created first
+	assert.Error(t, r.reconcileExports(n))
+
+	_, err := rookClientset.CephV1().CephObjectStores("ns").Create(&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: "ns"}})
+	assert.NoError(t, err)
+	_, err = rookClientset.CephV1().CephObjectStoreUsers("ns").Create(&cephv1.CephObjectStoreUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-user", Namespace: "ns"},
+		Spec:       cephv1.ObjectStoreUserSpec{Store: "my-store", Tenant: "team_a"},
+		Status:     &cephv1.ObjectStoreUserStatus{Info: map[string]string{"secretName": "rook-ceph-object-user-my-store-my-user", "userID": "team_a$my-user"}},
+	})
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Secrets("ns").Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-object-user-my-store-my-user", Namespace: "ns"},
+		Data:       map[string][]byte{"AccessKey": []byte("ACCESS"), "SecretKey": []byte("secret")},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.reconcileExports(n))
+	buckets := objects["rook-export-buckets"]
+	assert.Contains(t, buckets, `Path = "/";`)
+	assert.Contains(t, buckets, "Access_Type = RO;")
+	assert.Contains(t, buckets, "Name = RGW;")
+	assert.Contains(t, buckets, `User_Id = "team_a$my-user";`)
+	assert.Contains(t, buckets, `Access_Key_Id = "ACCESS";`)
+	assert.Contains(t, buckets, `Secret_Access_Key = "secret";`)
+	assert.Contains(t, objects["rook-export-logs"], `Path = "logs";`)
+
+	// the servers run librgw in the zone of the store
+	conf := objects["conf-my-nfs.a"]
+	assert.Contains(t, conf, "%url \"rados://myfs-data0/nfs-ns/rook-export-buckets\"\n")
+	assert.Contains(t, conf, `name = "client.admin";`)
+	assert.Contains(t, conf, `init_args = "--rgw-realm=my-store --rgw-zonegroup=my-store --rgw-zone=my-store";`)
+}
@@ -96,7 +96,7 @@ func (r *ReconcileCephNFS) upCephNFS(n *cephv1.CephNFS, oldActive int) error {
 				return errors.Wrap(err, "failed to create ceph nfs deployment")
 			}
 			logger.Infof("ceph nfs deployment %q already exists. updating if needed", deployment.Name)
-			if err := updateDeploymentAndWait(r.context, r.clusterInfo, deployment, "nfs", id, r.cephClusterSpec.SkipUpgradeChecks, false); err != nil {
+			if err := updateDeploymentAndWait(r.context, r.clusterInfo, deployment, "nfs", id, cephclient.DaemonUpgradeChecks(r.cephClusterSpec, "nfs")); err != nil {
 				return errors.Wrapf(err, "failed to update ceph nfs deployment %q", deployment.Name)
 			}
 		} else {
@@ -124,11 +124,7 @@ func (r *ReconcileCephNFS) addRADOSConfigFile(n *cephv1.CephNFS, name string) er
 	nodeID := getNFSNodeID(n, name)
 	config := getGaneshaConfigObject(nodeID)
 	cmd := "rados"
-	args := []string{
-		"--pool", n.Spec.RADOS.Pool,
-		"--namespace", n.Spec.RADOS.Namespace,
-		"--conf", cephclient.CephConfFilePath(r.context.ConfigDir, n.Namespace),
-	}
+	args := r.radosArgs(n)
 	err := r.context.Executor.ExecuteCommand(cmd, append(args, "stat", config)...)
 	if err == nil {
 		// If stat works then we assume it's present already
@@ -169,7 +165,7 @@ func (r *ReconcileCephNFS) runGaneshaRadosGrace(nfs *cephv1.CephNFS, name, actio
 func (r *ReconcileCephNFS) generateConfigMap(n *cephv1.CephNFS, name string) *v1.ConfigMap {
 
 	data := map[string]string{
-		"config": getGaneshaConfig(n, name),
+		"config": getGaneshaConfig(n, name, r.getGaneshaUserID(n), r.nfsPort()),
 	}
 	configMap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -179,6 +175,10 @@ func (r *ReconcileCephNFS) generateConfigMap(n *cephv1.CephNFS, name string) *v1
 		},
 		Data: data,
 	}
+	if len(n.Spec.Server.Annotations) > 0 {
+		n.Spec.Server.Annotations.ApplyToObjectMeta(&configMap.ObjectMeta)
+	}
+	n.Spec.Server.Labels.ApplyToObjectMeta(&configMap.ObjectMeta)
 
 	return configMap
 }
@@ -199,7 +199,7 @@ func (r *ReconcileCephNFS) createConfigMap(n *cephv1.CephNFS, name string) (stri
 		}
 
 		logger.Debugf("updating config map %q that already exists", configMap.Name)
-		if _, err = r.context.Clientset.CoreV1().ConfigMaps(n.Namespace).Update(configMap); err != nil {
+		if _, err = k8sutil.UpdateConfigMap(r.context.Clientset, configMap); err != nil {
 			return "", errors.Wrap(err, "failed to update ganesha config map")
 		}
 	}
@@ -269,6 +269,10 @@ func validateGanesha(context *clusterd.Context, clusterInfo *cephclient.ClusterI
 		return errors.New("at least one active server required")
 	}
 
+	if err := validateExports(n.Spec.Exports); err != nil {
+		return errors.Wrap(err, "invalid exports")
+	}
+
 	// We cannot run an NFS server if no MDS is running
 	// The existence of the pool provided in n.Spec.RADOS.Pool is necessary otherwise addRADOSConfigFile() will fail
 	_, err := client.GetPoolDetails(context, clusterInfo, n.Spec.RADOS.Pool)
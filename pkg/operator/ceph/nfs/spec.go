@@ -21,12 +21,13 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/netpolicy"
+	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -54,21 +55,33 @@ func (r *ReconcileCephNFS) generateCephNFSService(nfs *cephv1.CephNFS, cfg daemo
 			Ports: []v1.ServicePort{
 				{
 					Name:       "nfs",
-					Port:       nfsPort,
-					TargetPort: intstr.FromInt(int(nfsPort)),
+					Port:       r.nfsPort(),
+					TargetPort: intstr.FromInt(int(r.nfsPort())),
 					Protocol:   v1.ProtocolTCP,
 				},
 			},
 		},
 	}
 
+	nfs.Spec.Server.Annotations.ApplyToObjectMeta(&svc.ObjectMeta)
+	nfs.Spec.Server.Labels.ApplyToObjectMeta(&svc.ObjectMeta)
+
 	if r.cephClusterSpec.Network.IsHost() {
 		svc.Spec.ClusterIP = v1.ClusterIPNone
 	}
+	config.ApplyIPFamily(r.cephClusterSpec.Network, svc)
 
 	return svc
 }
 
+// nfsPort returns the port of the nfs servers, which may be customized on the host network
+func (r *ReconcileCephNFS) nfsPort() int32 {
+	if port := r.cephClusterSpec.Network.GetNFSPort(); port != 0 {
+		return port
+	}
+	return nfsPort
+}
+
 func (r *ReconcileCephNFS) createCephNFSService(nfs *cephv1.CephNFS, cfg daemonConfig) error {
 	s := r.generateCephNFSService(nfs, cfg)
 
@@ -78,16 +91,15 @@ func (r *ReconcileCephNFS) createCephNFSService(nfs *cephv1.CephNFS, cfg daemonC
 		return errors.Wrap(err, "failed to set owner reference to ceph object store")
 	}
 
-	svc, err := r.context.Clientset.CoreV1().Services(nfs.Namespace).Create(s)
+	svc, err := k8sutil.CreateOrUpdateService(r.context.Clientset, nfs.Namespace, s)
 	if err != nil {
-		if !kerrors.IsAlreadyExists(err) {
-			return errors.Wrap(err, "failed to create ganesha service")
-		}
-		logger.Infof("ceph nfs service already created")
-		return nil
+		return errors.Wrap(err, "failed to create ganesha service")
+	}
+	if err := netpolicy.ReconcileServicePolicy(r.context, r.cephClusterSpec, s); err != nil {
+		return errors.Wrap(err, "failed to reconcile the network policy of the ganesha service")
 	}
 
-	logger.Infof("ceph nfs service running at %s:%d", svc.Spec.ClusterIP, nfsPort)
+	logger.Infof("ceph nfs service running at %s:%d", svc.Spec.ClusterIP, r.nfsPort())
 	return nil
 }
 
@@ -102,6 +114,7 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 	k8sutil.AddRookVersionLabelToDeployment(deployment)
 	controller.AddCephVersionLabelToDeployment(r.clusterInfo.CephVersion, deployment)
 	nfs.Spec.Server.Annotations.ApplyToObjectMeta(&deployment.ObjectMeta)
+	nfs.Spec.Server.Labels.ApplyToObjectMeta(&deployment.ObjectMeta)
 
 	cephConfigVol, _ := cephConfigVolumeAndMount()
 	nfsConfigVol, _ := nfsConfigVolumeAndMount(cfg.ConfigConfigMap)
@@ -121,12 +134,13 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 			// override configs, because nfs-ganesha is not a Ceph daemon; it wouldn't observe any
 			// overrides anyway
 			cephConfigVol,
-			keyring.Volume().Admin(),
+			r.keyringVolume(nfs),
 			nfsConfigVol,
 			dbusVol,
 		},
 		HostNetwork:       r.cephClusterSpec.Network.IsHost(),
 		PriorityClassName: nfs.Spec.Server.PriorityClassName,
+		SecurityContext:   cephv1.GetPodSecurityContext(r.cephClusterSpec.Security.PodSecurityContexts, cephv1.KeyNFS),
 	}
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec)
@@ -153,6 +167,8 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 	}
 
 	nfs.Spec.Server.Annotations.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
+	nfs.Spec.Server.Labels.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
+	config.ApplyConnectionsAnnotation(r.cephClusterSpec.Network, &podTemplateSpec.ObjectMeta)
 
 	// Multiple replicas of the nfs service would be handled by creating a service and a new deployment for each one, rather than increasing the pod count here
 	replicas := int32(1)
@@ -163,6 +179,7 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 		Template: podTemplateSpec,
 		Replicas: &replicas,
 	}
+	k8sutil.ApplyInheritedMetadataToPodTemplate(deployment.Namespace, &deployment.ObjectMeta, &deployment.Spec.Template)
 
 	return deployment, nil
 }
@@ -170,13 +187,18 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 func (r *ReconcileCephNFS) connectionConfigInitContainer(nfs *cephv1.CephNFS) v1.Container {
 	_, cephConfigMount := cephConfigVolumeAndMount()
 
+	keyringPath := keyring.VolumeMount().AdminKeyringFilePath()
+	if r.cephClusterSpec.Security.RestrictAdminKey {
+		keyringPath = keyring.VolumeMount().KeyringFilePath()
+	}
+
 	return controller.GenerateMinimalCephConfInitContainer(
-		"client.admin",
-		keyring.VolumeMount().AdminKeyringFilePath(),
+		"client."+r.getGaneshaUserID(nfs),
+		keyringPath,
 		r.cephClusterSpec.CephVersion.Image,
 		[]v1.VolumeMount{
 			cephConfigMount,
-			keyring.VolumeMount().Admin(),
+			r.keyringVolumeMount(nfs),
 		},
 		nfs.Spec.Server.Resources,
 		mon.PodSecurityContext(),
@@ -201,7 +223,7 @@ func (r *ReconcileCephNFS) daemonContainer(nfs *cephv1.CephNFS, cfg daemonConfig
 		Image: r.cephClusterSpec.CephVersion.Image,
 		VolumeMounts: []v1.VolumeMount{
 			cephConfigMount,
-			keyring.VolumeMount().Admin(),
+			r.keyringVolumeMount(nfs),
 			nfsConfigMount,
 			dbusMount,
 		},
@@ -239,6 +261,21 @@ func (r *ReconcileCephNFS) dbusContainer(nfs *cephv1.CephNFS) v1.Container {
 	}
 }
 
+// keyringVolume returns the volume of the keyring of the ganesha user, the admin keyring unless the admin key is restricted
+func (r *ReconcileCephNFS) keyringVolume(n *cephv1.CephNFS) v1.Volume {
+	if r.cephClusterSpec.Security.RestrictAdminKey {
+		return keyring.Volume().Resource(getKeyringResourceName(n))
+	}
+	return keyring.Volume().Admin()
+}
+
+func (r *ReconcileCephNFS) keyringVolumeMount(n *cephv1.CephNFS) v1.VolumeMount {
+	if r.cephClusterSpec.Security.RestrictAdminKey {
+		return keyring.VolumeMount().Resource(getKeyringResourceName(n))
+	}
+	return keyring.VolumeMount().Admin()
+}
+
 func getLabels(n *cephv1.CephNFS, name string) map[string]string {
 	labels := controller.AppLabels(AppName, n.Namespace)
 	labels["ceph_nfs"] = n.Name
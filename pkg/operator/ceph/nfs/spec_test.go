@@ -136,4 +136,34 @@ func TestDeploymentSpec(t *testing.T) {
 		},
 	)
 	assert.Equal(t, "my-priority-class", d.Spec.Template.Spec.PriorityClassName)
+	assert.Equal(t, "rook-ceph-admin-keyring", d.Spec.Template.Spec.Volumes[1].Secret.SecretName)
+
+	// the ganesha key is used when the admin key is restricted
+	r.cephClusterSpec.Security.RestrictAdminKey = true
+	d, err = r.makeDeployment(nfs, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "rook-ceph-nfs-my-nfs-keyring", d.Spec.Template.Spec.Volumes[1].Secret.SecretName)
+	assert.Contains(t, d.Spec.Template.Spec.InitContainers[0].Command[2], "[client.nfs-ganesha.my-nfs]")
+	assert.Contains(t, r.generateConfigMap(nfs, id).Data["config"], "userid = nfs-ganesha.my-nfs;")
+
+	// the nfs port is customized on the host network
+	assert.NotContains(t, r.generateConfigMap(nfs, id).Data["config"], "NFS_Port")
+	r.cephClusterSpec.Network.Provider = "host"
+	r.cephClusterSpec.Network.HostPorts = &cephv1.HostPortsSpec{NFSPort: 12049}
+	assert.Contains(t, r.generateConfigMap(nfs, id).Data["config"], "NFS_Port = 12049;")
+	svc := r.generateCephNFSService(nfs, cfg)
+	assert.Equal(t, int32(12049), svc.Spec.Ports[0].Port)
+	assert.Equal(t, 12049, svc.Spec.Ports[0].TargetPort.IntValue())
+}
+
+func TestGaneshaOSDCaps(t *testing.T) {
+	n := &cephv1.CephNFS{Spec: cephv1.NFSGaneshaSpec{RADOS: cephv1.GaneshaRADOSSpec{Pool: "myfs-data0", Namespace: "nfs-ns"}}}
+	assert.Equal(t, "allow rw pool=myfs-data0 namespace=nfs-ns, allow rw tag cephfs data=*", getGaneshaOSDCaps(n))
+
+	n.Spec.Exports = []cephv1.NFSExportSpec{{Filesystem: "myfs"}, {Filesystem: "otherfs"}, {Filesystem: "myfs"}}
+	assert.Equal(t, "allow rw pool=myfs-data0 namespace=nfs-ns, allow rw tag cephfs data=myfs, allow rw tag cephfs data=otherfs", getGaneshaOSDCaps(n))
+
+	// the buckets are in the pools of the object stores
+	n.Spec.Exports = []cephv1.NFSExportSpec{{Filesystem: "myfs"}, {ObjectStore: &cephv1.NFSObjectStoreExportSpec{Store: "my-store", User: "my-user"}}}
+	assert.Equal(t, "allow rw pool=myfs-data0 namespace=nfs-ns, allow rw tag cephfs data=myfs, allow rwx tag rgw *=*", getGaneshaOSDCaps(n))
 }
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notification sends selected operator warnings to an external channel such as a webhook or Slack
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// operatorSettingConfigMapName is a duplicate of the const in the controller package, done to avoid an import cycle
+	operatorSettingConfigMapName = "rook-ceph-operator-config"
+	webhookURLSetting            = "ROOK_NOTIFICATION_WEBHOOK_URL"
+	webhookFormatSetting         = "ROOK_NOTIFICATION_WEBHOOK_FORMAT"
+	reasonsSetting               = "ROOK_NOTIFICATION_REASONS"
+	intervalSetting              = "ROOK_NOTIFICATION_INTERVAL"
+	defaultInterval              = 10 * time.Minute
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", "op-notification")
+
+var (
+	dispatcher      *Dispatcher
+	dispatcherMutex sync.RWMutex
+)
+
+// Reason identifies the kind of warning being notified
+type Reason string
+
+const (
+	// ReasonUpgradeFailed is sent when a Ceph upgrade could not be started or did not complete
+	ReasonUpgradeFailed Reason = "UpgradeFailed"
+	// ReasonQuorumLost is sent when the operator cannot reach the mons in quorum
+	ReasonQuorumLost Reason = "QuorumLost"
+	// ReasonOSDRemoved is sent when the operator removed an OSD that was out and safe to destroy
+	ReasonOSDRemoved Reason = "OSDAutoRemoved"
+	// ReasonDeprecatedFields is sent when deprecated fields are set in the spec of a CR
+	ReasonDeprecatedFields Reason = "DeprecatedFields"
+)
+
+// Notification is a single warning sent to the notification channel
+type Notification struct {
+	Reason    Reason    `json:"reason"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Channel is the destination notifications are delivered to
+type Channel interface {
+	Send(n Notification) error
+}
+
+// Dispatcher filters and rate limits notifications before delivering them to a channel
+type Dispatcher struct {
+	channel  Channel
+	reasons  map[Reason]bool
+	interval time.Duration
+	lastSent map[string]time.Time
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+// NewDispatcher creates a dispatcher for the given channel. If no reasons are given, all reasons are sent.
+// The same reason for the same object is not sent more than once per interval.
+func NewDispatcher(channel Channel, reasons []Reason, interval time.Duration) *Dispatcher {
+	d := &Dispatcher{
+		channel:  channel,
+		reasons:  make(map[Reason]bool),
+		interval: interval,
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}
+	for _, r := range reasons {
+		d.reasons[r] = true
+	}
+	return d
+}
+
+func notificationKey(n Notification) string {
+	return fmt.Sprintf("%s/%s/%s", n.Reason, n.Namespace, n.Name)
+}
+
+// allow returns whether the notification passes the reason filter and the rate limit
+func (d *Dispatcher) allow(n Notification) bool {
+	if len(d.reasons) > 0 && !d.reasons[n.Reason] {
+		return false
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	key := notificationKey(n)
+	if last, ok := d.lastSent[key]; ok && d.now().Sub(last) < d.interval {
+		logger.Debugf("skipping notification %q, already sent at %s", key, last.Format(time.RFC3339))
+		return false
+	}
+	return true
+}
+
+// Notify sends the notification to the channel if it is not filtered or rate limited.
+// It returns whether the notification was sent. A notification which failed to be sent is not rate limited.
+func (d *Dispatcher) Notify(n Notification) (bool, error) {
+	if !d.allow(n) {
+		return false, nil
+	}
+	if err := d.channel.Send(n); err != nil {
+		return false, errors.Wrapf(err, "failed to send %q notification", n.Reason)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.lastSent[notificationKey(n)] = d.now()
+	return true, nil
+}
+
+// SetParams configures the notification channel from the operator settings. If no webhook url is
+// configured, notifications are disabled.
+func SetParams(clientset kubernetes.Interface) error {
+	url, err := k8sutil.GetOperatorSetting(clientset, operatorSettingConfigMapName, webhookURLSetting, "")
+	if err != nil {
+		return errors.Wrap(err, "unable to determine the notification webhook url")
+	}
+	if url == "" {
+		setDispatcher(nil)
+		return nil
+	}
+
+	format, err := k8sutil.GetOperatorSetting(clientset, operatorSettingConfigMapName, webhookFormatSetting, string(FormatJSON))
+	if err != nil {
+		return errors.Wrap(err, "unable to determine the notification webhook format")
+	}
+	channel, err := NewWebhookChannel(url, WebhookFormat(format))
+	if err != nil {
+		return errors.Wrap(err, "invalid notification webhook")
+	}
+
+	reasonsRaw, err := k8sutil.GetOperatorSetting(clientset, operatorSettingConfigMapName, reasonsSetting, "")
+	if err != nil {
+		return errors.Wrap(err, "unable to determine the notification reasons")
+	}
+
+	intervalRaw, err := k8sutil.GetOperatorSetting(clientset, operatorSettingConfigMapName, intervalSetting, defaultInterval.String())
+	if err != nil {
+		return errors.Wrap(err, "unable to determine the notification interval")
+	}
+	interval, err := time.ParseDuration(intervalRaw)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse value for %q", intervalSetting)
+	}
+
+	setDispatcher(NewDispatcher(channel, parseReasons(reasonsRaw), interval))
+	logger.Infof("notifications enabled with %q webhook format", format)
+	return nil
+}
+
+func parseReasons(raw string) []Reason {
+	reasons := []Reason{}
+	for _, r := range strings.Split(raw, ",") {
+		r = strings.TrimSpace(r)
+		if r != "" {
+			reasons = append(reasons, Reason(r))
+		}
+	}
+	return reasons
+}
+
+func setDispatcher(d *Dispatcher) {
+	dispatcherMutex.Lock()
+	defer dispatcherMutex.Unlock()
+	dispatcher = d
+}
+
+// Warning sends a warning notification in the background if notifications are enabled
+func Warning(reason Reason, namespace, name, format string, args ...interface{}) {
+	dispatcherMutex.RLock()
+	d := dispatcher
+	dispatcherMutex.RUnlock()
+	if d == nil {
+		return
+	}
+
+	n := Notification{
+		Reason:    reason,
+		Namespace: namespace,
+		Name:      name,
+		Message:   fmt.Sprintf(format, args...),
+		Timestamp: time.Now().UTC(),
+	}
+	go func() {
+		if _, err := d.Notify(n); err != nil {
+			logger.Errorf("%v", err)
+		}
+	}()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChannel struct {
+	sent []Notification
+	err  error
+}
+
+func (f *fakeChannel) Send(n Notification) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	channel := &fakeChannel{}
+	d := NewDispatcher(channel, nil, 10*time.Minute)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	n := Notification{Reason: ReasonQuorumLost, Namespace: "rook-ceph", Name: "my-cluster"}
+	sent, err := d.Notify(n)
+	assert.NoError(t, err)
+	assert.True(t, sent)
+
+	// the same notification is rate limited
+	sent, err = d.Notify(n)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+
+	// a different object is not rate limited
+	other := n
+	other.Namespace = "other"
+	sent, err = d.Notify(other)
+	assert.NoError(t, err)
+	assert.True(t, sent)
+
+	// after the interval the notification is sent again
+	now = now.Add(11 * time.Minute)
+	sent, err = d.Notify(n)
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, 3, len(channel.sent))
+}
+
+func TestDispatcherSendFailure(t *testing.T) {
+	channel := &fakeChannel{err: errors.New("connection refused")}
+	d := NewDispatcher(channel, nil, 10*time.Minute)
+
+	n := Notification{Reason: ReasonQuorumLost, Namespace: "rook-ceph", Name: "my-cluster"}
+	sent, err := d.Notify(n)
+	assert.Error(t, err)
+	assert.False(t, sent)
+
+	// the failed notification is not rate limited
+	channel.err = nil
+	sent, err = d.Notify(n)
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, 1, len(channel.sent))
+
+	sent, err = d.Notify(n)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestDispatcherReasonFilter(t *testing.T) {
+	channel := &fakeChannel{}
+	d := NewDispatcher(channel, parseReasons("UpgradeFailed, OSDAutoRemoved"), time.Minute)
+
+	sent, err := d.Notify(Notification{Reason: ReasonQuorumLost})
+	assert.NoError(t, err)
+	assert.False(t, sent)
+
+	sent, err = d.Notify(Notification{Reason: ReasonOSDRemoved})
+	assert.NoError(t, err)
+	assert.True(t, sent)
+}
+
+func TestWebhookChannel(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := ioutil.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(raw, &body))
+	}))
+	defer server.Close()
+
+	n := Notification{Reason: ReasonUpgradeFailed, Namespace: "rook-ceph", Name: "my-cluster", Message: "not healthy"}
+
+	// json format
+	w, err := NewWebhookChannel(server.URL, FormatJSON)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Send(n))
+	assert.Equal(t, "UpgradeFailed", body["reason"])
+	assert.Equal(t, "not healthy", body["message"])
+
+	// slack format
+	w, err = NewWebhookChannel(server.URL, FormatSlack)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Send(n))
+	assert.Equal(t, ":warning: *UpgradeFailed* in rook-ceph/my-cluster: not healthy", body["text"])
+
+	// invalid settings
+	_, err = NewWebhookChannel("not a url", FormatJSON)
+	assert.Error(t, err)
+	_, err = NewWebhookChannel(server.URL, "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebhookFormat is the payload format posted to the webhook
+type WebhookFormat string
+
+const (
+	// FormatJSON posts the notification as a JSON object
+	FormatJSON WebhookFormat = "json"
+	// FormatSlack posts a Slack-compatible message
+	FormatSlack WebhookFormat = "slack"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookChannel posts notifications to an http endpoint
+type WebhookChannel struct {
+	url    string
+	format WebhookFormat
+	client *http.Client
+}
+
+// NewWebhookChannel returns a channel posting to the given url with the given format
+func NewWebhookChannel(webhookURL string, format WebhookFormat) (*WebhookChannel, error) {
+	if _, err := url.ParseRequestURI(webhookURL); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse webhook url %q", webhookURL)
+	}
+	if format != FormatJSON && format != FormatSlack {
+		return nil, errors.Errorf("unsupported webhook format %q, must be %q or %q", format, FormatJSON, FormatSlack)
+	}
+	return &WebhookChannel{
+		url:    webhookURL,
+		format: format,
+		client: &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+// Send posts the notification to the webhook
+func (w *WebhookChannel) Send(n Notification) error {
+	body, err := w.payload(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to build webhook payload")
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to post to webhook")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook returned unexpected status %q", resp.Status)
+	}
+	return nil
+}
+
+func (w *WebhookChannel) payload(n Notification) ([]byte, error) {
+	if w.format == FormatSlack {
+		return json.Marshal(map[string]string{
+			"text": fmt.Sprintf(":warning: *%s* in %s/%s: %s", n.Reason, n.Namespace, n.Name, n.Message),
+		})
+	}
+	return json.Marshal(n)
+}
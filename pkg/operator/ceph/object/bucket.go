@@ -18,6 +18,7 @@ package object
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 	"time"
 
@@ -50,6 +51,7 @@ type ObjectBucket struct {
 
 type rgwBucketStats struct {
 	Bucket string `json:"bucket"`
+	Owner  string `json:"owner"`
 	Usage  map[string]struct {
 		Size            uint64 `json:"size"`
 		NumberOfObjects uint64 `json:"num_objects"`
@@ -232,3 +234,22 @@ func DeleteObjectBucket(c *Context, bucketName string, purge bool) (int, error)
 
 	return RGWErrorUnknown, errors.Wrap(err, "failed to delete bucket")
 }
+
+// SetBucketQuota sets and enables the quota of a bucket, the limits which are zero are not set
+func SetBucketQuota(c *Context, bucketName string, maxSize, maxObjects int64) error {
+	args := []string{"quota", "set", "--quota-scope", "bucket", "--bucket", bucketName}
+	if maxSize > 0 {
+		args = append(args, "--max-size", strconv.FormatInt(maxSize, 10))
+	}
+	if maxObjects > 0 {
+		args = append(args, "--max-objects", strconv.FormatInt(maxObjects, 10))
+	}
+	if result, err := runAdminCommand(c, args...); err != nil {
+		return errors.Wrapf(err, "failed to set the quota of bucket %q. %s", bucketName, result)
+	}
+	if result, err := runAdminCommand(c, "quota", "enable", "--quota-scope", "bucket", "--bucket", bucketName); err != nil {
+		return errors.Wrapf(err, "failed to enable the quota of bucket %q. %s", bucketName, result)
+	}
+	logger.Infof("set the quota of bucket %q to a max size of %d bytes and max %d objects", bucketName, maxSize, maxObjects)
+	return nil
+}
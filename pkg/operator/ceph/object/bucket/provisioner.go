@@ -24,13 +24,17 @@ import (
 	bktv1alpha1 "github.com/kube-object-storage/lib-bucket-provisioner/pkg/apis/objectbucket.io/v1alpha1"
 	claimClient "github.com/kube-object-storage/lib-bucket-provisioner/pkg/client/clientset/versioned"
 	apibkt "github.com/kube-object-storage/lib-bucket-provisioner/pkg/provisioner/api"
+	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pkg/errors"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	cephutil "github.com/rook/rook/pkg/daemon/ceph/util"
 	cephObject "github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 )
 
 type Provisioner struct {
@@ -42,6 +46,8 @@ type Provisioner struct {
 	storeDomainName string
 	storePort       int32
 	region          string
+	// the placement target of the buckets created by the provisioner, the default target of the zone group if empty
+	placementTarget string
 	// access keys for acct for the bucket *owner*
 	cephUserName    string
 	accessKeyID     string
@@ -74,6 +80,15 @@ func (p Provisioner) Provision(options *apibkt.BucketOptions) (*bktv1alpha1.Obje
 	}
 	logger.Infof("Provision: creating bucket %q for OBC %q", p.bucketName, options.ObjectBucketClaim.Name)
 
+	maxSize, maxObjects, err := getBucketQuota(p.additionalConfigData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid quota of OBC %q", options.ObjectBucketClaim.Name)
+	}
+	formats, err := getSecretFormats(p.additionalConfigData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid config of OBC %q", options.ObjectBucketClaim.Name)
+	}
+
 	// dynamically create a new ceph user
 	p.accessKeyID, p.secretAccessKey, err = p.createCephUser("")
 	if err != nil {
@@ -86,7 +101,7 @@ func (p Provisioner) Provision(options *apibkt.BucketOptions) (*bktv1alpha1.Obje
 	}
 
 	// create the bucket
-	err = s3svc.CreateBucket(p.bucketName)
+	err = s3svc.CreateBucketInPlacement(p.bucketName, p.placementTarget)
 	if err != nil {
 		err = errors.Wrapf(err, "error creating bucket %q", p.bucketName)
 		logger.Errorf(err.Error())
@@ -101,6 +116,18 @@ func (p Provisioner) Provision(options *apibkt.BucketOptions) (*bktv1alpha1.Obje
 	}
 	logger.Infof("set user %q bucket max to %d", p.cephUserName, maxBuckets)
 
+	if maxSize > 0 || maxObjects > 0 {
+		if err := cephObject.SetBucketQuota(p.objectContext, p.bucketName, maxSize, maxObjects); err != nil {
+			p.deleteOBCResourceLogError(p.bucketName)
+			return nil, err
+		}
+	}
+
+	if err := p.createConfigSecret(options.ObjectBucketClaim, formats); err != nil {
+		p.deleteOBCResourceLogError(p.bucketName)
+		return nil, err
+	}
+
 	return p.composeObjectBucket(), nil
 }
 
@@ -114,6 +141,15 @@ func (p Provisioner) Grant(options *apibkt.BucketOptions) (*bktv1alpha1.ObjectBu
 		return nil, err
 	}
 	logger.Infof("Grant: allowing access to bucket %q for OBC %q", p.bucketName, options.ObjectBucketClaim.Name)
+	formats, err := getSecretFormats(p.additionalConfigData)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid config of OBC %q", options.ObjectBucketClaim.Name)
+	}
+	_, hasMaxSize := p.additionalConfigData[maxSizeConfig]
+	_, hasMaxObjects := p.additionalConfigData[maxObjectsConfig]
+	if hasMaxSize || hasMaxObjects {
+		logger.Warningf("Grant: ignoring the quota of OBC %q, the quota of the existing bucket %q is not changed", options.ObjectBucketClaim.Name, p.bucketName)
+	}
 
 	// check and make sure the bucket exists
 	logger.Infof("Checking for existing bucket %q", p.bucketName)
@@ -181,6 +217,11 @@ func (p Provisioner) Grant(options *apibkt.BucketOptions) (*bktv1alpha1.ObjectBu
 		p.deleteOBCResourceLogError("")
 		return nil, err
 	}
+
+	if err := p.createConfigSecret(options.ObjectBucketClaim, formats); err != nil {
+		p.deleteOBCResourceLogError("")
+		return nil, err
+	}
 	// returned ob with connection info
 	return p.composeObjectBucket(), nil
 }
@@ -207,7 +248,9 @@ func (p Provisioner) Delete(ob *bktv1alpha1.ObjectBucket) error {
 	return nil
 }
 
-// Revoke removes a user and creds from an existing bucket.
+// Revoke removes a user and creds from an existing bucket, or from a new bucket when the reclaimPolicy of the storage
+// class is "Retain". The retained bucket keeps its data and its owner, whose access is denied by the bucket policy, so
+// that the bucket can be granted again to a new OBC with a storage class of the bucket.
 // Note: cleanup order below matters.
 func (p Provisioner) Revoke(ob *bktv1alpha1.ObjectBucket) error {
 
@@ -255,6 +298,7 @@ func (p Provisioner) Revoke(ob *bktv1alpha1.ObjectBucket) error {
 		}
 
 		if bucket.Owner == p.cephUserName {
+			logger.Infof("Revoke: retaining bucket %q and its data", p.bucketName)
 			statement := cephObject.NewPolicyStatement().
 				WithSID(p.cephUserName).
 				ForPrincipals(p.cephUserName).
@@ -324,6 +368,10 @@ func (p *Provisioner) initializeCreateOrGrant(options *apibkt.BucketOptions) err
 	if err != nil {
 		return err
 	}
+	err = p.setPlacementTarget(sc)
+	if err != nil {
+		return err
+	}
 
 	// If an endpoint is declared let's use it
 	err = p.populateDomainAndPort(sc)
@@ -460,6 +508,19 @@ func (p *Provisioner) setRegion(sc *storagev1.StorageClass) {
 	p.region = sc.Parameters[key]
 }
 
+// setPlacementTarget sets the placement target of the storage class, which must be a placement target of the store
+func (p *Provisioner) setPlacementTarget(sc *storagev1.StorageClass) error {
+	p.placementTarget = sc.Parameters[placementTarget]
+	if p.placementTarget == "" {
+		return nil
+	}
+	store, err := p.getObjectStore()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cephObjectStore")
+	}
+	return cephObject.ValidatePlacementTarget(store, p.placementTarget)
+}
+
 func (p Provisioner) getObjectStoreEndpoint() string {
 	return fmt.Sprintf("%s:%d", p.storeDomainName, p.storePort)
 }
@@ -489,6 +550,48 @@ func (p *Provisioner) populateDomainAndPort(sc *storagev1.StorageClass) error {
 	return nil
 }
 
+// createConfigSecret creates the config secret of the OBC with the keys of the bucket in the formats. The secret is
+// owned by the OBC, it is deleted with the OBC.
+func (p *Provisioner) createConfigSecret(obc *bktv1alpha1.ObjectBucketClaim, formats []string) error {
+	if len(formats) == 0 {
+		return nil
+	}
+	data, err := cephObject.FormattedSecretData(formats, obc.Name, p.getObjectStoreEndpoint(), p.accessKeyID, p.secretAccessKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to format the keys of OBC %q", obc.Name)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configSecretName(obc),
+			Namespace: obc.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: bktv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "ObjectBucketClaim",
+				Name:       obc.Name,
+				UID:        obc.UID,
+			}},
+		},
+		StringData: data,
+	}
+	// the secret is in the namespace of the OBC, it inherits the metadata of the cluster of the object store
+	k8sutil.ApplyInheritedMetadata(p.clusterInfo.Namespace, &secret.ObjectMeta)
+	secrets := p.context.Clientset.CoreV1().Secrets(obc.Namespace)
+	if _, err := secrets.Create(secret); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create config secret %q of OBC %q", secret.Name, obc.Name)
+		}
+		if _, err := secrets.Update(secret); err != nil {
+			return errors.Wrapf(err, "failed to update config secret %q of OBC %q", secret.Name, obc.Name)
+		}
+	}
+	logger.Infof("created config secret %q of OBC %q", secret.Name, obc.Name)
+	return nil
+}
+
+func configSecretName(obc *bktv1alpha1.ObjectBucketClaim) string {
+	return fmt.Sprintf("%s-config", obc.Name)
+}
+
 func (p *Provisioner) deleteOBCResourceLogError(name string) {
 	if err := p.deleteOBCResource(""); err != nil {
 		logger.Warningf("error deleting OBC resource. %v", err)
@@ -18,6 +18,7 @@ package bucket
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
@@ -26,6 +27,7 @@ import (
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/object"
 	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -102,3 +104,61 @@ func TestPopulateDomainAndPort(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "rook-ceph-rgw-test-store.ns", p.storeDomainName)
 }
+
+func TestGetBucketQuota(t *testing.T) {
+	maxSize, maxObjects, err := getBucketQuota(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), maxSize)
+	assert.Equal(t, int64(0), maxObjects)
+
+	maxSize, maxObjects, err = getBucketQuota(map[string]string{"maxSize": "2Gi", "maxObjects": "1000"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*1024*1024*1024), maxSize)
+	assert.Equal(t, int64(1000), maxObjects)
+
+	maxSize, _, err = getBucketQuota(map[string]string{"maxSize": "10G"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10000000000), maxSize)
+
+	for _, config := range []map[string]string{{"maxSize": "big"}, {"maxSize": "0"}, {"maxObjects": "-1"}, {"maxObjects": "1k"}} {
+		_, _, err = getBucketQuota(config)
+		assert.Error(t, err)
+	}
+}
+
+func TestGetSecretFormats(t *testing.T) {
+	formats, err := getSecretFormats(map[string]string{})
+	assert.NoError(t, err)
+	assert.Empty(t, formats)
+
+	formats, err = getSecretFormats(map[string]string{"secretFormats": "aws-credentials, rclone,"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aws-credentials", "rclone"}, formats)
+
+	_, err = getSecretFormats(map[string]string{"secretFormats": "aws-credentials,s3cmd"})
+	assert.Error(t, err)
+}
+
+func TestSetBucketQuota(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(_ string, args ...string) (string, error) {
+			// the command without the multisite and connection args
+			adminCommand := []string{}
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "--rgw-realm") {
+					break
+				}
+				adminCommand = append(adminCommand, arg)
+			}
+			commands = append(commands, strings.Join(adminCommand, " "))
+			return "", nil
+		},
+	}
+	c := object.NewContext(&clusterd.Context{Executor: executor}, client.AdminClusterInfo("ns"), "my-store")
+	assert.NoError(t, object.SetBucketQuota(c, "photos", 1024, 0))
+	assert.Equal(t, []string{
+		"quota set --quota-scope bucket --bucket photos --max-size 1024",
+		"quota enable --quota-scope bucket --bucket photos",
+	}, commands)
+}
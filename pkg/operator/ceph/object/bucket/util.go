@@ -18,6 +18,8 @@ package bucket
 
 import (
 	"crypto/rand"
+	"strconv"
+	"strings"
 
 	"github.com/coreos/pkg/capnslog"
 	bktv1alpha1 "github.com/kube-object-storage/lib-bucket-provisioner/pkg/apis/objectbucket.io/v1alpha1"
@@ -26,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -44,6 +47,13 @@ const (
 	objectStoreName      = "objectStoreName"
 	objectStoreNamespace = "objectStoreNamespace"
 	objectStoreEndpoint  = "endpoint"
+	placementTarget      = "placementTarget"
+
+	// the keys of the quota of the new buckets in the additional config of the OBCs
+	maxSizeConfig    = "maxSize"
+	maxObjectsConfig = "maxObjects"
+	// the comma separated formats of the keys of the config secret of the OBC
+	secretFormatsConfig = "secretFormats"
 )
 
 func NewBucketController(cfg *rest.Config, p *Provisioner) (*provisioner.Provisioner, error) {
@@ -94,6 +104,42 @@ func isStaticBucket(sc *storagev1.StorageClass) (string, bool) {
 	return val, ok
 }
 
+// getBucketQuota returns the max size in bytes and the max number of objects of the additional config of an OBC, zero
+// when not set. The max size is a quantity like "10Gi".
+func getBucketQuota(additionalConfig map[string]string) (int64, int64, error) {
+	var maxSize, maxObjects int64
+	if value, ok := additionalConfig[maxSizeConfig]; ok {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid %s %q", maxSizeConfig, value)
+		}
+		if maxSize = quantity.Value(); maxSize <= 0 {
+			return 0, 0, errors.Errorf("invalid %s %q, must be positive", maxSizeConfig, value)
+		}
+	}
+	if value, ok := additionalConfig[maxObjectsConfig]; ok {
+		var err error
+		if maxObjects, err = strconv.ParseInt(value, 10, 64); err != nil || maxObjects <= 0 {
+			return 0, 0, errors.Errorf("invalid %s %q, must be a positive integer", maxObjectsConfig, value)
+		}
+	}
+	return maxSize, maxObjects, nil
+}
+
+// getSecretFormats returns the formats of the keys of the config secret of an OBC, from its additional config
+func getSecretFormats(additionalConfig map[string]string) ([]string, error) {
+	formats := []string{}
+	for _, format := range strings.Split(additionalConfig[secretFormatsConfig], ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	if err := cephObject.ValidateSecretFormats(formats); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", secretFormatsConfig)
+	}
+	return formats, nil
+}
+
 func getCephUser(ob *bktv1alpha1.ObjectBucket) string {
 	return ob.Spec.AdditionalState[cephUser]
 }
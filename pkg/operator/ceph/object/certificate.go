@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/certificate"
+	v1 "k8s.io/api/core/v1"
+)
+
+// usesManagedCertificate returns whether the operator issues the certificate of the secure port of the store, which
+// is the case when the certificates are managed by the operator and no certificate is set for the gateway
+func usesManagedCertificate(clusterSpec *cephv1.ClusterSpec, store *cephv1.CephObjectStore) bool {
+	return clusterSpec.Security.TLS.IsEnabled() && !clusterSpec.External.Enable &&
+		store.Spec.Gateway.SecurePort != 0 && store.Spec.Gateway.SSLCertificateRef == ""
+}
+
+func (c *clusterConfig) managedCertificate() bool {
+	return usesManagedCertificate(c.clusterSpec, c.store)
+}
+
+// sslEnabled returns whether the gateway has a certificate for its secure port
+func (c *clusterConfig) sslEnabled() bool {
+	return c.store.Spec.Gateway.SSLCertificateRef != "" || c.managedCertificate()
+}
+
+func managedCertificateSecretName(storeName string) string {
+	return fmt.Sprintf("%s-%s-tls", AppName, storeName)
+}
+
+// issueCertificate issues or renews the certificate of the service of the store and returns its hash
+func (c *clusterConfig) issueCertificate() (string, error) {
+	domainName := BuildDomainName(c.store.Name, c.store.Namespace)
+	req := certificate.Request{
+		SecretName: managedCertificateSecretName(c.store.Name),
+		DNSNames:   []string{domainName + ".svc", instanceName(c.store.Name), domainName},
+		OwnerRef:   c.ownerRef,
+	}
+	cert, err := certificate.NewManager(c.context, c.clusterInfo, c.clusterSpec.Security.TLS).Issue(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to issue the certificate of object store %q", c.store.Name)
+	}
+	return cert.Hash(), nil
+}
+
+// managedCertificateVolume returns the volume of the managed certificate and its private key. Like the certificates
+// set for the gateway, they are readable by everyone since the secret mount is owned by root.
+func (c *clusterConfig) managedCertificateVolume() v1.Volume {
+	userReadOnly := int32(0444)
+	return v1.Volume{
+		Name: certVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: managedCertificateSecretName(c.store.Name),
+				Items: []v1.KeyToPath{
+					{Key: v1.TLSCertKey, Path: v1.TLSCertKey, Mode: &userReadOnly},
+					{Key: v1.TLSPrivateKeyKey, Path: v1.TLSPrivateKeyKey, Mode: &userReadOnly},
+				}}}}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	v1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -56,28 +57,34 @@ func (c *clusterConfig) portString() string {
 		}
 		portString = fmt.Sprintf("port=%s", strconv.Itoa(int(port)))
 	}
-	if c.store.Spec.Gateway.SecurePort != 0 && c.store.Spec.Gateway.SSLCertificateRef != "" {
-		certPath := path.Join(certDir, certFilename)
+	if c.store.Spec.Gateway.SecurePort != 0 && c.sslEnabled() {
+		certOptions := fmt.Sprintf("ssl_certificate=%s", path.Join(certDir, certFilename))
+		if c.managedCertificate() {
+			// the managed certificates have their private key in a separate file like the secrets of cert-manager
+			certOptions = fmt.Sprintf("ssl_certificate=%s ssl_private_key=%s",
+				path.Join(certDir, v1.TLSCertKey), path.Join(certDir, v1.TLSPrivateKeyKey))
+		}
 		// This is the beast backend
 		// Config is: http://docs.ceph.com/docs/master/radosgw/frontends/#id3
 		if port != 0 {
-			portString = fmt.Sprintf("%s ssl_port=%d ssl_certificate=%s",
-				portString, c.store.Spec.Gateway.SecurePort, certPath)
+			portString = fmt.Sprintf("%s ssl_port=%d %s",
+				portString, c.store.Spec.Gateway.SecurePort, certOptions)
 		} else {
-			portString = fmt.Sprintf("ssl_port=%d ssl_certificate=%s",
-				c.store.Spec.Gateway.SecurePort, certPath)
+			portString = fmt.Sprintf("ssl_port=%d %s",
+				c.store.Spec.Gateway.SecurePort, certOptions)
 		}
 	}
 	return portString
 }
 
-func generateCephXUser(name string) string {
+// GenerateCephXUser returns the cephx user of the rgw daemon of the given resource
+func GenerateCephXUser(name string) string {
 	user := strings.TrimPrefix(name, AppName)
 	return "client.rgw" + strings.Replace(user, "-", ".", -1)
 }
 
 func (c *clusterConfig) generateKeyring(rgwConfig *rgwConfig) (string, error) {
-	user := generateCephXUser(rgwConfig.ResourceName)
+	user := GenerateCephXUser(rgwConfig.ResourceName)
 	/* TODO: this says `osd allow rwx` while template says `osd allow *`; which is correct? */
 	access := []string{"osd", "allow rwx", "mon", "allow rw"}
 	s := keyring.GetSecretStore(c.context, c.clusterInfo, c.ownerRef)
@@ -88,12 +95,12 @@ func (c *clusterConfig) generateKeyring(rgwConfig *rgwConfig) (string, error) {
 	}
 
 	keyring := fmt.Sprintf(keyringTemplate, user, key)
-	return keyring, s.CreateOrUpdate(rgwConfig.ResourceName, keyring)
+	return keyring, s.CreateOrUpdateWithMeta(rgwConfig.ResourceName, keyring, c.store.Spec.Gateway.Annotations, c.store.Spec.Gateway.Labels)
 }
 
 func (c *clusterConfig) setDefaultFlagsMonConfigStore(rgwName string) error {
-	monStore := cephconfig.GetMonStore(c.context, c.clusterInfo)
-	who := generateCephXUser(rgwName)
+	configStore := cephconfig.GetDaemonConfigStore(c.context, c.clusterInfo)
+	who := GenerateCephXUser(rgwName)
 	configOptions := make(map[string]string)
 
 	configOptions["rgw_log_nonexistent_bucket"] = "true"
@@ -102,20 +109,16 @@ func (c *clusterConfig) setDefaultFlagsMonConfigStore(rgwName string) error {
 	configOptions["rgw_zone"] = c.store.Name
 	configOptions["rgw_zonegroup"] = c.store.Name
 
-	for flag, val := range configOptions {
-		err := monStore.Set(who, flag, val)
-		if err != nil {
-			return errors.Wrapf(err, "failed to set %q to %q on %q", flag, val, who)
-		}
+	if _, err := configStore.Apply(who, configOptions); err != nil {
+		return errors.Wrapf(err, "failed to configure %q", who)
 	}
-
 	return nil
 }
 
 func (c *clusterConfig) deleteFlagsMonConfigStore(rgwName string) error {
-	monStore := cephconfig.GetMonStore(c.context, c.clusterInfo)
-	who := generateCephXUser(rgwName)
-	err := monStore.DeleteDaemon(who)
+	configStore := cephconfig.GetDaemonConfigStore(c.context, c.clusterInfo)
+	who := GenerateCephXUser(rgwName)
+	_, err := configStore.Remove(who)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete rgw config for %q in mon configuration database", who)
 	}
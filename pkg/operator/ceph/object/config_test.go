@@ -81,6 +81,18 @@ func TestPortString(t *testing.T) {
 	result = cfg.portString()
 	assert.Equal(t, "", result)
 
+	// Secure port with the certificate managed by the operator
+	cfg = newConfig()
+	cfg.clusterSpec.Security.TLS.Issuer = cephv1.TLSIssuerSelfSigned
+	cfg.store.Spec.Gateway.SecurePort = 443
+	result = cfg.portString()
+	assert.Equal(t, "ssl_port=443 ssl_certificate=/etc/ceph/private/tls.crt ssl_private_key=/etc/ceph/private/tls.key", result)
+
+	// The certificate of the gateway takes precedence over the managed certificate
+	cfg.store.Spec.Gateway.SSLCertificateRef = "some-k8s-key-secret"
+	result = cfg.portString()
+	assert.Equal(t, "ssl_port=443 ssl_certificate=/etc/ceph/private/rgw-cert.pem", result)
+
 	// Using SDN, no host networking so the rgw port internal is not the same
 	cfg = newConfig()
 	cfg.store.Spec.Gateway.Port = 80
@@ -89,6 +101,6 @@ func TestPortString(t *testing.T) {
 }
 
 func TestGenerateCephXUser(t *testing.T) {
-	fakeUser := generateCephXUser("rook-ceph-rgw-fake-store-fake-user")
+	fakeUser := GenerateCephXUser("rook-ceph-rgw-fake-store-fake-user")
 	assert.Equal(t, "client.rgw.fake.store.fake.user", fakeUser)
 }
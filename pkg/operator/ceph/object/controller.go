@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -31,9 +32,11 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/certificate"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/util/exec"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -42,6 +45,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -75,19 +79,26 @@ var controllerTypeMeta = metav1.TypeMeta{
 
 // ReconcileCephObjectStore reconciles a cephObjectStore object
 type ReconcileCephObjectStore struct {
-	client                 client.Client
-	bktclient              bktclient.Interface
-	scheme                 *runtime.Scheme
-	context                *clusterd.Context
-	cephClusterSpec        *cephv1.ClusterSpec
-	clusterInfo            *cephclient.ClusterInfo
-	objectStoreChannels    map[string]*objectStoreHealth
-	clusterResourceDeleted bool
+	client              client.Client
+	recorder            record.EventRecorder
+	bktclient           bktclient.Interface
+	scheme              *runtime.Scheme
+	context             *clusterd.Context
+	cephClusterSpec     *cephv1.ClusterSpec
+	clusterInfo         *cephclient.ClusterInfo
+	objectStoreChannels map[string]*objectStoreHealth
+	// the stores whose resources were deleted, so that they are not deleted again when the removal of the finalizer
+	// fails
+	deletedStores map[string]bool
+	// the channels and the deleted stores are shared by the parallel reconciles
+	channelsMutex *sync.Mutex
 }
 
 type objectStoreHealth struct {
 	stopChan          chan struct{}
 	monitoringRunning bool
+	// the collector of the bucket usage, nil when the collection is disabled
+	usage *usageCollector
 }
 
 // Add creates a new cephObjectStore Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -105,16 +116,19 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 	}
 	return &ReconcileCephObjectStore{
 		client:              mgr.GetClient(),
+		recorder:            mgr.GetEventRecorderFor(controllerName),
 		scheme:              mgrScheme,
 		context:             context,
 		bktclient:           bktclient.NewForConfigOrDie(context.KubeConfig),
 		objectStoreChannels: make(map[string]*objectStoreHealth),
+		deletedStores:       make(map[string]bool),
+		channelsMutex:       &sync.Mutex{},
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -146,7 +160,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileCephObjectStore) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephObjectStore{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephObjectStore{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -199,6 +218,7 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 
 	// Initialize the channel for this object store
 	// This allows us to track multiple ObjectStores in the same namespace
+	r.channelsMutex.Lock()
 	_, ok := r.objectStoreChannels[cephObjectStore.Name]
 	if !ok {
 		r.objectStoreChannels[cephObjectStore.Name] = &objectStoreHealth{
@@ -206,6 +226,7 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 			monitoringRunning: false,
 		}
 	}
+	r.channelsMutex.Unlock()
 
 	// Populate clusterInfo during each reconcile
 	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
@@ -223,7 +244,11 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 	if !cephObjectStore.GetDeletionTimestamp().IsZero() {
 		logger.Debugf("deleting store %q", cephObjectStore.Name)
 
-		if !r.clusterResourceDeleted {
+		r.channelsMutex.Lock()
+		storeDeleted := r.deletedStores[cephObjectStore.Name]
+		r.channelsMutex.Unlock()
+
+		if !storeDeleted {
 			response, okToDelete := r.verifyObjectBucketCleanup(cephObjectStore)
 			if !okToDelete {
 				// If the object store cannot be deleted, requeue the request for deletion to see if the conditions
@@ -239,10 +264,15 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 			}
 
 			// Close the channel to stop the healthcheck of the endpoint
+			r.channelsMutex.Lock()
 			close(r.objectStoreChannels[cephObjectStore.Name].stopChan)
+			if usage := r.objectStoreChannels[cephObjectStore.Name].usage; usage != nil {
+				usage.stop()
+			}
 
 			// Remove object store from the map
 			delete(r.objectStoreChannels, cephObjectStore.Name)
+			r.channelsMutex.Unlock()
 
 			cfg := clusterConfig{
 				context:     r.context,
@@ -255,8 +285,10 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 				return reconcile.Result{}, errors.Wrapf(err, "failed to delete store %q", cephObjectStore.Name)
 			}
 
-			// Mark the ressource deletion as complete
-			r.clusterResourceDeleted = true
+			// Mark the resource deletion as complete
+			r.channelsMutex.Lock()
+			r.deletedStores[cephObjectStore.Name] = true
+			r.channelsMutex.Unlock()
 		}
 
 		// Remove finalizer
@@ -265,6 +297,13 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
 		}
 
+		// Forget the deleted store so that a new store with the same name is deleted again, along with the channel
+		// that the retries created again for it
+		r.channelsMutex.Lock()
+		delete(r.deletedStores, cephObjectStore.Name)
+		delete(r.objectStoreChannels, cephObjectStore.Name)
+		r.channelsMutex.Unlock()
+
 		// Return and do not requeue. Successful deletion.
 		return reconcile.Result{}, nil
 	}
@@ -283,19 +322,41 @@ func (r *ReconcileCephObjectStore) reconcile(request reconcile.Request) (reconci
 	// Set Progressing status, we are done reconciling, the health check go routine will update the status
 	updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing, buildStatusInfo(cephObjectStore))
 
+	effectiveSpec := cephObjectStore.Spec.DeepCopy()
+	effectiveSpec.Gateway = effectiveSpec.Gateway.WithClusterDefaults(r.cephClusterSpec)
+	opcontroller.ExportEffectiveSpec(r.client, cephObjectStore, effectiveSpec)
+
+	// Restart the gateways when requested
+	restarting, err := r.restartGateways(cephObjectStore)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to restart the gateways of object store %q", cephObjectStore.Name)
+	}
+	if restarting {
+		return opcontroller.WaitForRequeueIfRestartInProgress, nil
+	}
+
+	// Requeue to renew the certificate of the gateways when it is managed by the operator
+	if usesManagedCertificate(r.cephClusterSpec, cephObjectStore) {
+		logger.Debug("done reconciling, checking the certificate again later")
+		return reconcile.Result{RequeueAfter: certificate.CheckInterval}, nil
+	}
+
 	// Return and do not requeue
 	logger.Debug("done reconciling")
 	return reconcile.Result{}, nil
 }
 
 func (r *ReconcileCephObjectStore) reconcileCreateObjectStore(cephObjectStore *cephv1.CephObjectStore, namespacedName types.NamespacedName) (reconcile.Result, error) {
+	// the gateways are deployed with the defaults of the cluster, the CR itself is left unchanged
+	cephObjectStore = cephObjectStore.DeepCopy()
+	cephObjectStore.Spec.Gateway = cephObjectStore.Spec.Gateway.WithClusterDefaults(r.cephClusterSpec)
 	cfg := clusterConfig{
 		context:     r.context,
 		clusterInfo: r.clusterInfo,
 		store:       cephObjectStore,
 		rookVersion: r.cephClusterSpec.CephVersion.Image,
 		clusterSpec: r.cephClusterSpec,
-		DataPathMap: opconfig.NewStatelessDaemonDataPathMap(opconfig.RgwType, cephObjectStore.Name, cephObjectStore.Namespace, r.cephClusterSpec.DataDirHostPath),
+		DataPathMap: opconfig.NewStatelessDaemonDataPathMap(opconfig.RgwType, cephObjectStore.Name, cephObjectStore.Namespace, r.cephClusterSpec.DataDirHostPath).WithHostSocketDir(r.cephClusterSpec.Monitoring.IsExporterEnabled()),
 		client:      r.client,
 		scheme:      r.scheme,
 	}
@@ -368,21 +429,88 @@ func (r *ReconcileCephObjectStore) reconcileCreateObjectStore(cephObjectStore *c
 			return r.setFailedStatus(namespacedName, "failed to configure multisite for object store", err)
 		}
 
+		// Reconcile the placement targets in the zone of the store
+		if !cephObjectStore.Spec.IsMultisite() {
+			if err := reconcilePlacementTargets(objContext, cephObjectStore.Spec); err != nil {
+				return r.setFailedStatus(namespacedName, "failed to configure the placement targets of the object store", err)
+			}
+		}
+
 		// Create or Update Store
 		err = cfg.createOrUpdateStore(realmName, zoneGroupName, zoneName)
+		if errors.Cause(err) == certificate.ErrNotReady {
+			logger.Infof("waiting for the certificate of object store %q", cephObjectStore.Name)
+			return waitForRequeueIfObjectStoreNotReady, nil
+		}
 		if err != nil {
 			return reconcile.Result{}, errors.Wrapf(err, "failed to create object store %q", cephObjectStore.Name)
 		}
+
+		// Create the roles of the sts and register its oidc provider once the gateways serve the requests
+		if sts := cephObjectStore.Spec.Auth.STS; sts != nil {
+			if err := reconcileSTSRoles(objContext, sts); err != nil {
+				return r.setFailedStatus(namespacedName, "failed to configure the sts roles of the object store", err)
+			}
+			if sts.OIDCProvider != nil {
+				endpoint := fmt.Sprintf("%s:%d", BuildDomainName(cephObjectStore.Name, cephObjectStore.Namespace), cephObjectStore.Spec.Gateway.Port)
+				iamClient, err := newSTSAdminClient(objContext, endpoint)
+				if err != nil {
+					return r.setFailedStatus(namespacedName, "failed to configure the sts admin user of the object store", err)
+				}
+				if err := reconcileOIDCProvider(iamClient, sts.OIDCProvider); err != nil {
+					logger.Warningf("failed to register the oidc provider of object store %q, waiting for the gateways. %v", cephObjectStore.Name, err)
+					return waitForRequeueIfObjectStoreNotReady, nil
+				}
+			}
+		}
+
+		// Manage the store in the dashboard, the store itself is available in any case
+		dashboardStore, err := isDashboardObjectStore(r.client, r.cephClusterSpec, cephObjectStore)
+		if err != nil {
+			logger.Errorf("failed to check if object store %q is managed in the dashboard. %v", cephObjectStore.Name, err)
+		} else if dashboardStore {
+			if err := configureDashboardObjectStore(objContext, cephObjectStore); err != nil {
+				logger.Errorf("failed to configure object store %q in the dashboard. %v", cephObjectStore.Name, err)
+			}
+		}
 	}
 
 	// Start monitoring
 	if !cephObjectStore.Spec.HealthCheck.Bucket.Disabled {
 		r.startMonitoring(cephObjectStore, objContext, serviceIP, namespacedName)
 	}
+	r.reconcileUsageMetrics(cephObjectStore, objContext)
 
 	return reconcile.Result{}, nil
 }
 
+// reconcileUsageMetrics starts or stops the collection of the bucket usage of the store, the collection is restarted
+// when its settings change
+func (r *ReconcileCephObjectStore) reconcileUsageMetrics(objectstore *cephv1.CephObjectStore, objContext *Context) {
+	r.channelsMutex.Lock()
+	defer r.channelsMutex.Unlock()
+	health := r.objectStoreChannels[objectstore.Name]
+	spec := objectstore.Spec.UsageMetrics
+	if health.usage != nil {
+		if usageMetricsEnabled(spec) && reflect.DeepEqual(health.usage.spec, *spec) {
+			return
+		}
+		health.usage.stop()
+		health.usage = nil
+	}
+	if !usageMetricsEnabled(spec) {
+		return
+	}
+
+	ownerRef, err := opcontroller.GetControllerObjectOwnerReference(objectstore, r.scheme)
+	if err != nil || ownerRef == nil {
+		logger.Errorf("failed to get the owner reference of object store %q, not collecting the bucket usage. %v", objectstore.Name, err)
+		return
+	}
+	health.usage = newUsageCollector(objContext, objectstore.Namespace, *spec, *ownerRef)
+	go health.usage.run()
+}
+
 func (r *ReconcileCephObjectStore) reconcileCephZone(store *cephv1.CephObjectStore, zoneGroupName string, realmName string) (reconcile.Result, error) {
 	realmArg := fmt.Sprintf("--rgw-realm=%s", realmName)
 	zoneGroupArg := fmt.Sprintf("--rgw-zonegroup=%s", zoneGroupName)
@@ -464,6 +592,8 @@ func (r *ReconcileCephObjectStore) verifyObjectBucketCleanup(objectstore *cephv1
 
 func (r *ReconcileCephObjectStore) startMonitoring(objectstore *cephv1.CephObjectStore, objContext *Context, serviceIP string, namespacedName types.NamespacedName) {
 	// Start monitoring object store
+	r.channelsMutex.Lock()
+	defer r.channelsMutex.Unlock()
 	if r.objectStoreChannels[objectstore.Name].monitoringRunning {
 		logger.Debug("external rgw endpoint monitoring go routine already running!")
 		return
@@ -505,3 +635,22 @@ func (r *ReconcileCephObjectStore) verifyObjectUserCleanup(objectstore *cephv1.C
 	logger.Errorf("failed to delete object store. users for objectstore %q in namespace %q are not cleaned up. remaining users: %+v", objectstore.Name, objectstore.Namespace, userNames)
 	return opcontroller.WaitForRequeueIfFinalizerBlocked, false
 }
+
+// restartGateways continues the rolling restart of the gateways of the store requested with the annotation of the
+// store, and returns whether the restart is still in progress
+func (r *ReconcileCephObjectStore) restartGateways(cephObjectStore *cephv1.CephObjectStore) (bool, error) {
+	daemons := []opcontroller.RestartDaemon{{
+		Type:     "rgw",
+		Selector: fmt.Sprintf("%s=%s,rook_object_store=%s", k8sutil.AppAttr, AppName, cephObjectStore.Name),
+		IDLabel:  "rgw",
+	}}
+	pending, err := opcontroller.PendingRestart(cephObjectStore, daemons)
+	if err != nil || pending == nil {
+		return false, err
+	}
+	done, err := opcontroller.RestartNextDaemon(r.context, r.clusterInfo, r.client, cephObjectStore, pending)
+	if err != nil || !done {
+		return !done, err
+	}
+	return false, opcontroller.CompleteRestart(r.client, r.recorder, cephObjectStore)
+}
@@ -20,6 +20,7 @@ package object
 import (
 	"context"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/coreos/pkg/capnslog"
@@ -203,6 +204,8 @@ func TestCephObjectStoreController(t *testing.T) {
 		scheme:              s,
 		context:             c,
 		objectStoreChannels: make(map[string]*objectStoreHealth),
+		deletedStores:       make(map[string]bool),
+		channelsMutex:       &sync.Mutex{},
 	}
 
 	// Mock request to simulate Reconcile() being called on an event for a
@@ -245,6 +248,8 @@ func TestCephObjectStoreController(t *testing.T) {
 		scheme:              s,
 		context:             c,
 		objectStoreChannels: make(map[string]*objectStoreHealth),
+		deletedStores:       make(map[string]bool),
+		channelsMutex:       &sync.Mutex{},
 	}
 	logger.Info("STARTING PHASE 2")
 	res, err = r.Reconcile(req)
@@ -320,6 +325,8 @@ func TestCephObjectStoreController(t *testing.T) {
 		scheme:              s,
 		context:             c,
 		objectStoreChannels: make(map[string]*objectStoreHealth),
+		deletedStores:       make(map[string]bool),
+		channelsMutex:       &sync.Mutex{},
 	}
 
 	logger.Info("STARTING PHASE 3")
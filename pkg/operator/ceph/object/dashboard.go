@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DashboardUserID is the system user of the object store managed in the dashboard
+	DashboardUserID   = "dashboard-admin"
+	dashboardUserName = "Ceph dashboard"
+)
+
+// isDashboardObjectStore returns whether the store is the object store managed in the dashboard of the cluster, the
+// store of the spec of the dashboard or the first store of the namespace by name
+func isDashboardObjectStore(c client.Client, clusterSpec *cephv1.ClusterSpec, store *cephv1.CephObjectStore) (bool, error) {
+	if !clusterSpec.Dashboard.Enabled || clusterSpec.External.Enable {
+		return false, nil
+	}
+	if clusterSpec.Dashboard.ObjectStore != "" {
+		return clusterSpec.Dashboard.ObjectStore == store.Name, nil
+	}
+	stores := &cephv1.CephObjectStoreList{}
+	if err := c.List(context.TODO(), stores, client.InNamespace(store.Namespace)); err != nil {
+		return false, errors.Wrap(err, "failed to list the object stores")
+	}
+	names := []string{}
+	for _, s := range stores.Items {
+		if s.DeletionTimestamp.IsZero() {
+			names = append(names, s.Name)
+		}
+	}
+	sort.Strings(names)
+	return len(names) > 0 && names[0] == store.Name, nil
+}
+
+// configureDashboardObjectStore sets the gateway endpoint and the credentials of the system user of the object store
+// in the dashboard, so that the buckets and the users of the store are managed in the dashboard
+func configureDashboardObjectStore(objContext *Context, store *cephv1.CephObjectStore) error {
+	user, err := getOrCreateDashboardUser(objContext)
+	if err != nil {
+		return err
+	}
+
+	secure := store.Spec.Gateway.SecurePort != 0
+	port := store.Spec.Gateway.Port
+	scheme := "http"
+	if secure {
+		port = store.Spec.Gateway.SecurePort
+		scheme = "https"
+	}
+	settings := []struct{ name, value string }{
+		{"rgw-api-host", fmt.Sprintf("%s.svc", BuildDomainName(store.Name, store.Namespace))},
+		{"rgw-api-port", strconv.Itoa(int(port))},
+		{"rgw-api-scheme", scheme},
+		{"rgw-api-user-id", DashboardUserID},
+	}
+	if secure {
+		// the mgr does not have the CA of the certificates of the gateways
+		settings = append(settings, struct{ name, value string }{"rgw-api-ssl-verify", "False"})
+	}
+	changed := false
+	for _, setting := range settings {
+		settingChanged, err := cephclient.SetDashboardSetting(objContext.Context, objContext.clusterInfo, setting.name, setting.value)
+		if err != nil {
+			return err
+		}
+		changed = changed || settingChanged
+	}
+
+	// the keys are read from files so that they are not in the arguments of the commands
+	accessKey, err := cephclient.GetDashboardSetting(objContext.Context, objContext.clusterInfo, "rgw-api-access-key")
+	if err != nil || accessKey != *user.AccessKey {
+		if err := cephclient.SetDashboardSettingFromFile(objContext.Context, objContext.clusterInfo, "set-rgw-api-access-key", []byte(*user.AccessKey)); err != nil {
+			return err
+		}
+		if err := cephclient.SetDashboardSettingFromFile(objContext.Context, objContext.clusterInfo, "set-rgw-api-secret-key", []byte(*user.SecretKey)); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if changed {
+		logger.Infof("object store %q configured in the dashboard", store.Name)
+	}
+	return nil
+}
+
+// getOrCreateDashboardUser returns the system user of the dashboard, created with its keys if it does not exist
+func getOrCreateDashboardUser(objContext *Context) (*ObjectUser, error) {
+	user, code, err := GetUser(objContext, DashboardUserID)
+	if code == RGWErrorNotFound {
+		logger.Infof("creating the system user %q of the dashboard in object store %q", DashboardUserID, objContext.Name)
+		output, err := runAdminCommand(objContext, "user", "create", "--uid", DashboardUserID, "--display-name", dashboardUserName, "--system")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the dashboard user %q. %s", DashboardUserID, output)
+		}
+		user, _, err = decodeUser(output)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the dashboard user %q", DashboardUserID)
+	}
+	if user.AccessKey == nil || user.SecretKey == nil {
+		return nil, errors.Errorf("dashboard user %q has no keys", DashboardUserID)
+	}
+	return user, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsDashboardObjectStore(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephObjectStore{}, &cephv1.CephObjectStoreList{})
+	now := metav1.Now()
+	objects := []runtime.Object{
+		&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "b-store", Namespace: "ns"}},
+		&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "c-store", Namespace: "ns"}},
+		&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "a-store", Namespace: "ns", DeletionTimestamp: &now}},
+		&cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: "a-store", Namespace: "other-ns"}},
+	}
+	cl := fake.NewFakeClientWithScheme(s, objects...)
+	store := func(name string) *cephv1.CephObjectStore {
+		return &cephv1.CephObjectStore{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	}
+	isDashboardStore := func(spec *cephv1.ClusterSpec, name string) bool {
+		result, err := isDashboardObjectStore(cl, spec, store(name))
+		assert.NoError(t, err)
+		return result
+	}
+
+	// no store is managed without dashboard
+	spec := &cephv1.ClusterSpec{}
+	assert.False(t, isDashboardStore(spec, "b-store"))
+
+	// the first store of the namespace not being deleted
+	spec.Dashboard.Enabled = true
+	assert.True(t, isDashboardStore(spec, "b-store"))
+	assert.False(t, isDashboardStore(spec, "c-store"))
+
+	// the store of the spec
+	spec.Dashboard.ObjectStore = "c-store"
+	assert.False(t, isDashboardStore(spec, "b-store"))
+	assert.True(t, isDashboardStore(spec, "c-store"))
+}
+
+func TestConfigureDashboardObjectStore(t *testing.T) {
+	userExists := false
+	accessKey := ""
+	adminCommands := []string{}
+	dashboardCommands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			adminCommands = append(adminCommands, strings.Join(args[:2], " "))
+			if args[0] == "user" && args[1] == "info" && !userExists {
+				return "could not fetch user info: no user info saved", nil
+			}
+			userExists = true
+			return `{"user_id":"dashboard-admin","keys":[{"access_key":"access","secret_key":"secret"}]}`, nil
+		},
+		MockExecuteCommandWithOutputFileTimeout: func(timeout time.Duration, command, outfileArg string, args ...string) (string, error) {
+			if args[1] == "get-rgw-api-access-key" {
+				return accessKey, nil
+			}
+			if strings.HasPrefix(args[1], "get-") {
+				return "", nil
+			}
+			dashboardCommands = append(dashboardCommands, args[1])
+			return "", nil
+		},
+	}
+	objContext := &Context{
+		Context:     &clusterd.Context{Executor: executor},
+		Name:        "my-store",
+		clusterInfo: client.AdminClusterInfo("ns"),
+	}
+	store := &cephv1.CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: "ns"},
+		Spec:       cephv1.ObjectStoreSpec{Gateway: cephv1.GatewaySpec{Port: 80, SecurePort: 443}},
+	}
+
+	// the system user is created and its keys are set
+	assert.NoError(t, configureDashboardObjectStore(objContext, store))
+	assert.Equal(t, []string{"user info", "user create"}, adminCommands)
+	assert.Equal(t, []string{"set-rgw-api-host", "set-rgw-api-port", "set-rgw-api-scheme", "set-rgw-api-user-id",
+		"set-rgw-api-ssl-verify", "set-rgw-api-access-key", "set-rgw-api-secret-key"}, dashboardCommands)
+
+	// the keys are not set again
+	adminCommands = []string{}
+	dashboardCommands = []string{}
+	accessKey = "access"
+	store.Spec.Gateway.SecurePort = 0
+	assert.NoError(t, configureDashboardObjectStore(objContext, store))
+	assert.Equal(t, []string{"user info"}, adminCommands)
+	assert.Equal(t, []string{"set-rgw-api-host", "set-rgw-api-port", "set-rgw-api-scheme", "set-rgw-api-user-id"}, dashboardCommands)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+)
+
+// ExternalRealm is the master zone of a realm living in an external cluster
+type ExternalRealm struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+type periodType struct {
+	MasterZoneGroup string `json:"master_zonegroup"`
+	MasterZone      string `json:"master_zone"`
+	PeriodMap       struct {
+		ZoneGroups []struct {
+			ID    string `json:"id"`
+			Zones []struct {
+				ID        string   `json:"id"`
+				Endpoints []string `json:"endpoints"`
+			} `json:"zones"`
+		} `json:"zonegroups"`
+	} `json:"period_map"`
+}
+
+// GetExternalRealm reads the endpoint of the master zone and the keys of the system user of a realm from the
+// external cluster hosting the master zone
+func GetExternalRealm(context *clusterd.Context, externalClusterInfo *client.ClusterInfo, realm *cephv1.CephObjectRealm) (*ExternalRealm, error) {
+	objContext := NewContext(context, externalClusterInfo, realm.Name)
+	realmArg := fmt.Sprintf("--rgw-realm=%s", realm.Name)
+
+	output, err := RunAdminCommandNoMultisite(objContext, "period", "get", realmArg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the period of realm %q from the external cluster. %s", realm.Name, output)
+	}
+	endpoint, err := decodeMasterZoneEndpoint(output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the master zone of realm %q", realm.Name)
+	}
+
+	uid := realm.Spec.Pull.External.SystemUserID
+	if uid == "" {
+		uid = realm.Name + "-system-user"
+	}
+	output, err = RunAdminCommandNoMultisite(objContext, "user", "info", realmArg, fmt.Sprintf("--uid=%s", uid))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the system user %q of realm %q from the external cluster. %s", uid, realm.Name, output)
+	}
+	user, _, err := decodeUser(output)
+	if err != nil {
+		return nil, err
+	}
+	if user.AccessKey == nil || user.SecretKey == nil {
+		return nil, errors.Errorf("system user %q of realm %q has no keys", uid, realm.Name)
+	}
+
+	return &ExternalRealm{Endpoint: endpoint, AccessKey: *user.AccessKey, SecretKey: *user.SecretKey}, nil
+}
+
+// decodeMasterZoneEndpoint returns the first endpoint of the master zone of the master zone group of a period
+func decodeMasterZoneEndpoint(data string) (string, error) {
+	var period periodType
+	if err := json.Unmarshal([]byte(data), &period); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal json")
+	}
+
+	for _, zoneGroup := range period.PeriodMap.ZoneGroups {
+		if zoneGroup.ID != period.MasterZoneGroup {
+			continue
+		}
+		for _, zone := range zoneGroup.Zones {
+			if zone.ID != period.MasterZone {
+				continue
+			}
+			if len(zone.Endpoints) == 0 {
+				return "", errors.Errorf("master zone %q has no endpoints", zone.ID)
+			}
+			return zone.Endpoints[0], nil
+		}
+	}
+	return "", errors.Errorf("master zone %q of master zone group %q not found in the period", period.MasterZone, period.MasterZoneGroup)
+}
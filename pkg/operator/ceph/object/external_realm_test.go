@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const periodOutput = `{
+	"id": "9f5c4c32-4e7d-4b4a-9d4f-3b9a2f0c7a11",
+	"master_zonegroup": "zg1",
+	"master_zone": "z1",
+	"period_map": {
+		"zonegroups": [
+			{"id": "zg2", "zones": [{"id": "z3", "endpoints": ["http://10.0.0.3:80"]}]},
+			{"id": "zg1", "zones": [
+				{"id": "z2", "endpoints": ["http://10.0.0.2:80"]},
+				{"id": "z1", "endpoints": ["http://10.0.0.1:80", "http://10.0.0.4:80"]}
+			]}
+		]
+	}
+}`
+
+func TestGetExternalRealm(t *testing.T) {
+	uid := ""
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(t, "--rgw-realm=realm-a", args[2])
+			if args[0] == "user" {
+				uid = args[3]
+				return `{"user_id":"realm-a-system-user","keys":[{"access_key":"access","secret_key":"secret"}]}`, nil
+			}
+			return periodOutput, nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	realm := &cephv1.CephObjectRealm{
+		ObjectMeta: metav1.ObjectMeta{Name: "realm-a", Namespace: "ns"},
+		Spec:       cephv1.ObjectRealmSpec{Pull: cephv1.PullSpec{External: &cephv1.ExternalRealmSpec{ClusterNamespace: "external"}}},
+	}
+
+	externalRealm, err := GetExternalRealm(context, client.AdminClusterInfo("external"), realm)
+	assert.NoError(t, err)
+	assert.Equal(t, "--uid=realm-a-system-user", uid)
+	assert.Equal(t, &ExternalRealm{Endpoint: "http://10.0.0.1:80", AccessKey: "access", SecretKey: "secret"}, externalRealm)
+
+	realm.Spec.Pull.External.SystemUserID = "sync-user"
+	_, err = GetExternalRealm(context, client.AdminClusterInfo("external"), realm)
+	assert.NoError(t, err)
+	assert.Equal(t, "--uid=sync-user", uid)
+}
+
+func TestDecodeMasterZoneEndpoint(t *testing.T) {
+	endpoint, err := decodeMasterZoneEndpoint(periodOutput)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.1:80", endpoint)
+
+	_, err = decodeMasterZoneEndpoint(`{"master_zonegroup":"zg1","master_zone":"z1","period_map":{"zonegroups":[{"id":"zg1","zones":[{"id":"z1","endpoints":[]}]}]}}`)
+	assert.Error(t, err)
+	_, err = decodeMasterZoneEndpoint(`{"master_zonegroup":"zg1","master_zone":"","period_map":{"zonegroups":[]}}`)
+	assert.Error(t, err)
+	_, err = decodeMasterZoneEndpoint("not json")
+	assert.Error(t, err)
+}
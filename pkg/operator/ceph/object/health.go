@@ -18,6 +18,7 @@ package object
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +37,9 @@ const (
 	s3HealthCheckObjectBodyMD5 = "5f286306a2227a156ba770800e71b796"
 	s3HealthCheckObjectKey     = "rookHealthCheckTestObject"
 	contentType                = "plain/text"
+
+	// the timeout of the probe of the endpoint, like the timeout of the s3 client
+	defaultEndpointProbeTimeout = 15 * time.Second
 )
 
 // bucketChecker aggregates the mon/cluster info needed to check the health of the monitors
@@ -49,6 +53,11 @@ type bucketChecker struct {
 	namespacedName  types.NamespacedName
 	healthCheckSpec *cephv1.BucketHealthCheckSpec
 	isExternal      bool
+	// the timeout of the probe of the endpoint
+	timeout time.Duration
+	// the interval of the synthetic transactions of the data path check, run at the next check once elapsed
+	dataPathInterval  time.Duration
+	lastDataPathCheck time.Time
 }
 
 // newbucketChecker creates a new HealthChecker object
@@ -63,6 +72,7 @@ func newBucketChecker(context *clusterd.Context, objContext *Context, serviceIP,
 		client:          client,
 		healthCheckSpec: healthCheckSpec,
 		isExternal:      isExternal,
+		timeout:         defaultEndpointProbeTimeout,
 	}
 
 	// allow overriding the check interval
@@ -73,6 +83,18 @@ func newBucketChecker(context *clusterd.Context, objContext *Context, serviceIP,
 			c.interval = duration
 		}
 	}
+	if timeout := healthCheckSpec.Bucket.Timeout; timeout != "" {
+		if duration, err := time.ParseDuration(timeout); err == nil {
+			c.timeout = duration
+		}
+	}
+	c.dataPathInterval = c.interval
+	if dataPathInterval := healthCheckSpec.DataPath.Interval; dataPathInterval != "" {
+		if duration, err := time.ParseDuration(dataPathInterval); err == nil {
+			logger.Infof("ceph rgw data path check interval for object store %q is %q", namespacedName.Name, dataPathInterval)
+			c.dataPathInterval = duration
+		}
+	}
 
 	return c
 }
@@ -82,7 +104,7 @@ func (c *bucketChecker) checkObjectStore(stopCh chan struct{}) {
 	// check the object store health immediately before starting the loop
 	err := c.checkObjectStoreHealth()
 	if err != nil {
-		updateStatusBucket(c.client, c.namespacedName, cephv1.ConditionFailure, err.Error())
+		updateStatusBucket(c.client, c.namespacedName, cephv1.ConditionFailure, err.Error(), "", nil)
 		logger.Debugf("failed to check rgw health for object store %q. %v", c.namespacedName.Name, err)
 	}
 
@@ -99,14 +121,71 @@ func (c *bucketChecker) checkObjectStore(stopCh chan struct{}) {
 			logger.Debugf("checking rgw health of object store %q", c.namespacedName.Name)
 			err := c.checkObjectStoreHealth()
 			if err != nil {
-				updateStatusBucket(c.client, c.namespacedName, cephv1.ConditionFailure, err.Error())
+				updateStatusBucket(c.client, c.namespacedName, cephv1.ConditionFailure, err.Error(), "", nil)
 				logger.Debugf("failed to check rgw health for object store %q. %v", c.namespacedName.Name, err)
 			}
 		}
 	}
 }
 
+// checkObjectStoreHealth probes the endpoint of the object store, and runs the synthetic transaction of the data path
+// check when it is due
 func (c *bucketChecker) checkObjectStoreHealth() error {
+	s3endpoint := fmt.Sprintf("%s:%s", BuildDomainName(c.objContext.Name, c.namespacedName.Namespace), c.port)
+	latency, err := probeEndpoint(s3endpoint, c.timeout)
+	if err != nil {
+		return errors.Wrapf(err, "failed to probe the endpoint of object store %q", c.namespacedName.Name)
+	}
+
+	var dataPath *cephv1.BucketDataPathStatus
+	now := time.Now()
+	if c.dataPathDue(now) {
+		dataPath, err = c.checkDataPath(s3endpoint)
+		if err != nil {
+			return err
+		}
+		c.lastDataPathCheck = now
+	}
+
+	logger.Debugf("successfully checked object store endpoint for object store %q", c.namespacedName.Name)
+
+	// Update the EndpointStatus in the CR to reflect the healthyness
+	updateStatusBucket(c.client, c.namespacedName, cephv1.ConditionConnected, "", formatLatency(latency), dataPath)
+	return nil
+}
+
+// probeEndpoint returns the latency of a request to the endpoint of the object store. Any response but a server
+// error means that the rgw daemons serve the requests.
+func probeEndpoint(endpoint string, timeout time.Duration) (time.Duration, error) {
+	httpClient := &http.Client{Timeout: timeout}
+	start := time.Now()
+	resp, err := httpClient.Get("http://" + endpoint)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return 0, errors.Errorf("endpoint %q answered %q", endpoint, resp.Status)
+	}
+	return latency, nil
+}
+
+// dataPathDue returns whether the synthetic transaction of the data path check must run at the time of the check
+func (c *bucketChecker) dataPathDue(now time.Time) bool {
+	if c.healthCheckSpec.DataPath.Disabled {
+		return false
+	}
+	return c.lastDataPathCheck.IsZero() || now.Sub(c.lastDataPathCheck) >= c.dataPathInterval
+}
+
+// formatLatency formats a latency to the microsecond
+func formatLatency(latency time.Duration) string {
+	return latency.Round(time.Microsecond).String()
+}
+
+// checkDataPath runs the synthetic transaction of the data path check and returns its latency
+func (c *bucketChecker) checkDataPath(s3endpoint string) (*cephv1.BucketDataPathStatus, error) {
 	/*
 		0. purge the s3 object by default
 		1. create an S3 user
@@ -126,7 +205,6 @@ func (c *bucketChecker) checkObjectStoreHealth() error {
 
 	var s3AccessKey string
 	var s3SecretKey string
-	s3endpoint := fmt.Sprintf("%s:%s", BuildDomainName(c.objContext.Name, c.namespacedName.Namespace), c.port)
 
 	// Generate unique user and bucket name
 	bucketName := genUniqueBucketName(c.objContext.UID)
@@ -139,10 +217,10 @@ func (c *bucketChecker) checkObjectStoreHealth() error {
 		if rgwerr == ErrorCodeFileExists {
 			user, _, err = GetUser(c.objContext, userConfig.UserID)
 			if err != nil {
-				return errors.Wrapf(err, "failed to get details from ceph object user %q for object store %q", user.UserID, c.namespacedName.Name)
+				return nil, errors.Wrapf(err, "failed to get details from ceph object user %q for object store %q", user.UserID, c.namespacedName.Name)
 			}
 		} else {
-			return errors.Wrapf(err, "failed to create object user %q. error code %d for object store %q", userConfig.UserID, rgwerr, c.namespacedName.Name)
+			return nil, errors.Wrapf(err, "failed to create object user %q. error code %d for object store %q", userConfig.UserID, rgwerr, c.namespacedName.Name)
 		}
 	}
 	// Set access and secret key
@@ -153,27 +231,21 @@ func (c *bucketChecker) checkObjectStoreHealth() error {
 	logger.Debugf("initializing s3 connection for object store %q", c.namespacedName.Name)
 	s3client, err := NewS3Agent(s3AccessKey, s3SecretKey, s3endpoint)
 	if err != nil {
-		return errors.Wrap(err, "failed to initialize s3 connection")
+		return nil, errors.Wrap(err, "failed to initialize s3 connection")
 	}
 
 	// Force purge the s3 object before starting anything
 	err = cleanupObjectHealthCheck(s3client, c.objContext.UID)
 	if err != nil {
-		return errors.Wrapf(err, "failed to perform object cleanup for object store %q", c.namespacedName.Name)
+		return nil, errors.Wrapf(err, "failed to perform object cleanup for object store %q", c.namespacedName.Name)
 	}
 
 	// Bucket health test
-	err = c.testBucketHealth(s3client, bucketName)
+	dataPath, err := c.testBucketHealth(s3client, bucketName)
 	if err != nil {
-		return errors.Wrapf(err, "failed to run bucket health checks for object store %q", c.namespacedName.Name)
+		return nil, errors.Wrapf(err, "failed to run bucket health checks for object store %q", c.namespacedName.Name)
 	}
-
-	logger.Debug("successfully checked object store endpoint for object store %q", c.namespacedName.Name)
-
-	// Update the EndpointStatus in the CR to reflect the healthyness
-	updateStatusBucket(c.client, c.namespacedName, cephv1.ConditionConnected, "")
-
-	return nil
+	return dataPath, nil
 }
 
 func cleanupObjectHealthCheck(s3client *S3Agent, objectStoreUID string) error {
@@ -202,11 +274,15 @@ func (c *bucketChecker) cleanupHealthCheck() {
 	}
 }
 
-func toCustomResourceStatus(currentStatus *cephv1.BucketStatus, details string, health cephv1.ConditionType) *cephv1.BucketStatus {
+// toCustomResourceStatus converts the result of a health check to the bucket status of the object store. The result
+// of the last synthetic transaction is kept when the data path was not checked.
+func toCustomResourceStatus(currentStatus *cephv1.BucketStatus, details string, health cephv1.ConditionType, endpointLatency string, dataPath *cephv1.BucketDataPathStatus) *cephv1.BucketStatus {
 	s := &cephv1.BucketStatus{
-		Health:      health,
-		LastChecked: time.Now().UTC().Format(time.RFC3339),
-		Details:     details,
+		Health:          health,
+		LastChecked:     time.Now().UTC().Format(time.RFC3339),
+		Details:         details,
+		EndpointLatency: endpointLatency,
+		DataPath:        dataPath,
 	}
 
 	if currentStatus != nil {
@@ -214,6 +290,9 @@ func toCustomResourceStatus(currentStatus *cephv1.BucketStatus, details string,
 		if currentStatus.Details != s.Details {
 			s.LastChanged = s.LastChecked
 		}
+		if s.DataPath == nil {
+			s.DataPath = currentStatus.DataPath
+		}
 	}
 	return s
 }
@@ -231,7 +310,7 @@ func (c *bucketChecker) genUserConfig() ObjectUser {
 	}
 }
 
-func (c *bucketChecker) testBucketHealth(s3client *S3Agent, bucket string) error {
+func (c *bucketChecker) testBucketHealth(s3client *S3Agent, bucket string) (*cephv1.BucketDataPathStatus, error) {
 	// Purge on exit
 	defer cleanupObjectHealthCheck(s3client, c.objContext.UID)
 
@@ -239,30 +318,47 @@ func (c *bucketChecker) testBucketHealth(s3client *S3Agent, bucket string) error
 	logger.Debugf("creating bucket %q", bucket)
 	err := s3client.CreateBucketNoInfoLogging(bucket)
 	if err != nil {
-		return errors.Wrapf(err, "failed to create bucket %q for object store %q", bucket, c.namespacedName.Name)
+		return nil, errors.Wrapf(err, "failed to create bucket %q for object store %q", bucket, c.namespacedName.Name)
 	}
 
 	// Put an object into the bucket
 	logger.Debugf("putting object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
+	start := time.Now()
 	_, err = s3client.PutObjectInBucket(bucket, string(s3HealthCheckObjectBody), s3HealthCheckObjectKey, contentType)
 	if err != nil {
-		return errors.Wrapf(err, "failed to put object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
+		return nil, errors.Wrapf(err, "failed to put object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
 	}
+	putLatency := time.Since(start)
 
 	// Get the object from the bucket
 	logger.Debugf("getting object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
+	start = time.Now()
 	read, err := s3client.GetObjectInBucket(bucket, s3HealthCheckObjectKey)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
+		return nil, errors.Wrapf(err, "failed to get object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
 	}
+	getLatency := time.Since(start)
 
 	// Compare the old and the existing object
 	logger.Debugf("comparing objects hash for object store %q", c.namespacedName.Name)
 	oldHash := k8sutil.Hash(s3HealthCheckObjectBody)
 	currentHash := k8sutil.Hash(read)
 	if currentHash != oldHash {
-		return errors.Wrapf(err, "wrong file content, old file hash is %q and new one is %q for object store %q", oldHash, currentHash, c.namespacedName.Name)
+		return nil, errors.Errorf("wrong file content, old file hash is %q and new one is %q for object store %q", oldHash, currentHash, c.namespacedName.Name)
 	}
 
-	return nil
+	// Delete the object from the bucket
+	logger.Debugf("deleting object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
+	start = time.Now()
+	if _, err := s3client.DeleteObjectInBucket(bucket, s3HealthCheckObjectKey); err != nil {
+		return nil, errors.Wrapf(err, "failed to delete object %q in bucket %q for object store %q", s3HealthCheckObjectKey, bucket, c.namespacedName.Name)
+	}
+	deleteLatency := time.Since(start)
+
+	return &cephv1.BucketDataPathStatus{
+		LastChecked:   time.Now().UTC().Format(time.RFC3339),
+		PutLatency:    formatLatency(putLatency),
+		GetLatency:    formatLatency(getLatency),
+		DeleteLatency: formatLatency(deleteLatency),
+	}, nil
 }
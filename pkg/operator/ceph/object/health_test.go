@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestProbeEndpoint(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+
+	_, err := probeEndpoint(endpoint, time.Second)
+	assert.NoError(t, err)
+
+	// the anonymous requests may be denied
+	status = http.StatusForbidden
+	_, err = probeEndpoint(endpoint, time.Second)
+	assert.NoError(t, err)
+
+	status = http.StatusServiceUnavailable
+	_, err = probeEndpoint(endpoint, time.Second)
+	assert.Error(t, err)
+}
+
+func TestDataPathCheckInterval(t *testing.T) {
+	name := types.NamespacedName{Name: "my-store", Namespace: "ns"}
+	spec := &cephv1.BucketHealthCheckSpec{Bucket: cephv1.HealthCheckSpec{Interval: "30s"}}
+	c := newBucketChecker(nil, &Context{}, "", "80", nil, name, spec, false)
+	assert.Equal(t, 30*time.Second, c.dataPathInterval)
+	assert.Equal(t, defaultEndpointProbeTimeout, c.timeout)
+
+	// the data path is checked at its own interval
+	spec.DataPath.Interval = "5m"
+	spec.Bucket.Timeout = "3s"
+	c = newBucketChecker(nil, &Context{}, "", "80", nil, name, spec, false)
+	assert.Equal(t, 5*time.Minute, c.dataPathInterval)
+	assert.Equal(t, 3*time.Second, c.timeout)
+	now := time.Now()
+	assert.True(t, c.dataPathDue(now))
+	c.lastDataPathCheck = now
+	assert.False(t, c.dataPathDue(now.Add(time.Minute)))
+	assert.True(t, c.dataPathDue(now.Add(5*time.Minute)))
+
+	// only the endpoint is probed when the data path check is disabled
+	spec.DataPath.Disabled = true
+	assert.False(t, c.dataPathDue(now.Add(5*time.Minute)))
+}
+
+func TestBucketStatusDataPath(t *testing.T) {
+	dataPath := &cephv1.BucketDataPathStatus{LastChecked: "2020-10-14T10:00:00Z", PutLatency: "3.1ms", GetLatency: "1.2ms", DeleteLatency: "2ms"}
+	s := toCustomResourceStatus(nil, "", cephv1.ConditionConnected, "1.5ms", dataPath)
+	assert.Equal(t, "1.5ms", s.EndpointLatency)
+	assert.Equal(t, dataPath, s.DataPath)
+
+	// the last transaction is kept until the data path is checked again
+	s = toCustomResourceStatus(s, "", cephv1.ConditionConnected, "1.1ms", nil)
+	assert.Equal(t, "1.1ms", s.EndpointLatency)
+	assert.Equal(t, dataPath, s.DataPath)
+	s = toCustomResourceStatus(s, "failed to probe the endpoint", cephv1.ConditionFailure, "", nil)
+	assert.Equal(t, "", s.EndpointLatency)
+	assert.Equal(t, dataPath, s.DataPath)
+}
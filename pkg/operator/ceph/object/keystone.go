@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the keys of the credentials of the keystone service user, named like the variables of the openstack CLI
+	keystoneUsernameKey   = "OS_USERNAME"
+	keystonePasswordKey   = "OS_PASSWORD"
+	keystoneProjectKey    = "OS_PROJECT_NAME"
+	keystoneUserDomainKey = "OS_USER_DOMAIN_NAME"
+
+	keystoneVolumeName  = "rgw-keystone-service-user"
+	keystonePasswordDir = "/etc/ceph/keystone"
+
+	// the env vars of the credentials of the service user which are not secret, expanded in the flags of the rgw
+	keystoneUsernameEnvVar   = "ROOK_KEYSTONE_USERNAME"
+	keystoneProjectEnvVar    = "ROOK_KEYSTONE_PROJECT_NAME"
+	keystoneUserDomainEnvVar = "ROOK_KEYSTONE_USER_DOMAIN_NAME"
+)
+
+var keystoneImplicitTenants = []string{"true", "false", "swift", "s3"}
+
+// keystoneFlags returns the rgw options authenticating the requests with keystone. The password of the service user
+// is read from a file so that it is not in the args of the rgw.
+func keystoneFlags(keystone *cephv1.KeystoneSpec) []string {
+	flags := []string{
+		cephconfig.NewFlag("rgw keystone url", keystone.URL),
+		cephconfig.NewFlag("rgw keystone api version", "3"),
+		cephconfig.NewFlag("rgw keystone admin user", controller.ContainerEnvVarReference(keystoneUsernameEnvVar)),
+		cephconfig.NewFlag("rgw keystone admin password path", path.Join(keystonePasswordDir, keystonePasswordKey)),
+		cephconfig.NewFlag("rgw keystone admin project", controller.ContainerEnvVarReference(keystoneProjectEnvVar)),
+		cephconfig.NewFlag("rgw keystone admin domain", controller.ContainerEnvVarReference(keystoneUserDomainEnvVar)),
+		cephconfig.NewFlag("rgw keystone accepted roles", strings.Join(keystone.AcceptedRoles, ",")),
+	}
+	if keystone.ImplicitTenants != "" {
+		flags = append(flags, cephconfig.NewFlag("rgw keystone implicit tenants", keystone.ImplicitTenants))
+	}
+	if keystone.TokenCacheSize != nil {
+		flags = append(flags, cephconfig.NewFlag("rgw keystone token cache size", strconv.Itoa(*keystone.TokenCacheSize)))
+	}
+	return flags
+}
+
+// keystoneEnvVars returns the env vars of the credentials of the service user expanded in the flags
+func keystoneEnvVars(keystone *cephv1.KeystoneSpec) []v1.EnvVar {
+	envVar := func(name, key string) v1.EnvVar {
+		return v1.EnvVar{Name: name, ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: keystone.ServiceUserSecretName},
+			Key:                  key,
+		}}}
+	}
+	return []v1.EnvVar{
+		envVar(keystoneUsernameEnvVar, keystoneUsernameKey),
+		envVar(keystoneProjectEnvVar, keystoneProjectKey),
+		envVar(keystoneUserDomainEnvVar, keystoneUserDomainKey),
+	}
+}
+
+// keystoneVolume returns the volume of the password of the service user, readable by everyone like the vault tokens
+func keystoneVolume(keystone *cephv1.KeystoneSpec) v1.Volume {
+	userReadOnly := int32(0444)
+	return v1.Volume{
+		Name: keystoneVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: keystone.ServiceUserSecretName,
+				Items: []v1.KeyToPath{
+					{Key: keystonePasswordKey, Path: keystonePasswordKey, Mode: &userReadOnly},
+				}}}}
+}
+
+func keystoneVolumeMount() v1.VolumeMount {
+	return v1.VolumeMount{Name: keystoneVolumeName, MountPath: keystonePasswordDir, ReadOnly: true}
+}
+
+// protocolFlags returns the rgw options of the S3 and Swift APIs
+func protocolFlags(protocols cephv1.ProtocolSpec) []string {
+	flags := []string{}
+	if protocols.S3 != nil && protocols.S3.AuthUseKeystone != nil {
+		flags = append(flags, cephconfig.NewFlag("rgw s3 auth use keystone", strconv.FormatBool(*protocols.S3.AuthUseKeystone)))
+	}
+	if swift := protocols.Swift; swift != nil {
+		if swift.AccountInURL != nil {
+			flags = append(flags, cephconfig.NewFlag("rgw swift account in url", strconv.FormatBool(*swift.AccountInURL)))
+		}
+		if swift.URLPrefix != nil {
+			flags = append(flags, cephconfig.NewFlag("rgw swift url prefix", *swift.URLPrefix))
+		}
+		if swift.VersioningEnabled != nil {
+			flags = append(flags, cephconfig.NewFlag("rgw swift versioning enabled", strconv.FormatBool(*swift.VersioningEnabled)))
+		}
+	}
+	return flags
+}
+
+// validateAuth checks the keystone settings of a store and that the secret of the service user holds its credentials
+func validateAuth(context *clusterd.Context, s *cephv1.CephObjectStore) error {
+	keystone := s.Spec.Auth.Keystone
+	if keystone == nil {
+		if s.Spec.Protocols.S3 != nil && s.Spec.Protocols.S3.AuthUseKeystone != nil && *s.Spec.Protocols.S3.AuthUseKeystone {
+			return errors.New("s3 authUseKeystone requires the keystone auth settings")
+		}
+		return nil
+	}
+	if u, err := url.Parse(keystone.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.Errorf("invalid keystone url %q", keystone.URL)
+	}
+	if len(keystone.AcceptedRoles) == 0 {
+		return errors.New("missing keystone acceptedRoles")
+	}
+	if keystone.ImplicitTenants != "" {
+		valid := false
+		for _, value := range keystoneImplicitTenants {
+			valid = valid || keystone.ImplicitTenants == value
+		}
+		if !valid {
+			return errors.Errorf("invalid keystone implicitTenants %q, must be one of %v", keystone.ImplicitTenants, keystoneImplicitTenants)
+		}
+	}
+	if keystone.ServiceUserSecretName == "" {
+		return errors.New("missing keystone serviceUserSecretName")
+	}
+	secret, err := context.Clientset.CoreV1().Secrets(s.Namespace).Get(keystone.ServiceUserSecretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get keystone service user secret %q", keystone.ServiceUserSecretName)
+	}
+	for _, key := range []string{keystoneUsernameKey, keystonePasswordKey, keystoneProjectKey, keystoneUserDomainKey} {
+		if len(secret.Data[key]) == 0 && secret.StringData[key] == "" {
+			return errors.Errorf("keystone service user secret %q has no %q key", keystone.ServiceUserSecretName, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func keystoneSpec() *cephv1.KeystoneSpec {
+	return &cephv1.KeystoneSpec{
+		URL:                   "https://keystone.openstack.svc:5000",
+		ServiceUserSecretName: "rgw-keystone",
+		AcceptedRoles:         []string{"admin", "member"},
+	}
+}
+
+func TestKeystonePodSpec(t *testing.T) {
+	store := simpleStore()
+	store.Spec.Auth.Keystone = keystoneSpec()
+	store.Spec.Auth.Keystone.ImplicitTenants = "swift"
+	accountInURL := true
+	prefix := "swift"
+	store.Spec.Protocols.Swift = &cephv1.SwiftSpec{AccountInURL: &accountInURL, URLPrefix: &prefix}
+	c := &clusterConfig{
+		clusterInfo: clienttest.CreateTestClusterInfo(1),
+		store:       store,
+		clusterSpec: &cephv1.ClusterSpec{CephVersion: cephv1.CephVersionSpec{Image: "ceph/ceph:v15"}},
+		DataPathMap: cephconfig.NewStatelessDaemonDataPathMap(cephconfig.RgwType, "default", "rook-ceph", "/var/lib/rook/"),
+	}
+	c.clusterInfo.CephVersion = cephver.Octopus
+
+	s, err := c.makeRGWPodSpec(&rgwConfig{ResourceName: "rook-ceph-rgw-default"})
+	assert.NoError(t, err)
+	container := s.Spec.Containers[0]
+	assert.Contains(t, container.Args, "--rgw-keystone-url=https://keystone.openstack.svc:5000")
+	assert.Contains(t, container.Args, "--rgw-keystone-api-version=3")
+	assert.Contains(t, container.Args, "--rgw-keystone-admin-user=$(ROOK_KEYSTONE_USERNAME)")
+	assert.Contains(t, container.Args, "--rgw-keystone-admin-password-path=/etc/ceph/keystone/OS_PASSWORD")
+	assert.Contains(t, container.Args, "--rgw-keystone-accepted-roles=admin,member")
+	assert.Contains(t, container.Args, "--rgw-keystone-implicit-tenants=swift")
+	assert.Contains(t, container.Args, "--rgw-swift-account-in-url=true")
+	assert.Contains(t, container.Args, "--rgw-swift-url-prefix=swift")
+
+	// the password is mounted, the other credentials are env vars
+	envVars := map[string]string{}
+	for _, env := range container.Env {
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			envVars[env.Name] = env.ValueFrom.SecretKeyRef.Key
+		}
+	}
+	assert.Equal(t, keystoneUsernameKey, envVars[keystoneUsernameEnvVar])
+	assert.Equal(t, keystoneUserDomainKey, envVars[keystoneUserDomainEnvVar])
+	assert.NotContains(t, envVars, keystonePasswordKey)
+	mounted := false
+	for _, mount := range container.VolumeMounts {
+		mounted = mounted || (mount.Name == keystoneVolumeName && mount.MountPath == keystonePasswordDir)
+	}
+	assert.True(t, mounted)
+	volumes := map[string]string{}
+	for _, volume := range s.Spec.Volumes {
+		if volume.Secret != nil {
+			volumes[volume.Name] = volume.Secret.SecretName
+		}
+	}
+	assert.Equal(t, "rgw-keystone", volumes[keystoneVolumeName])
+}
+
+func TestValidateAuth(t *testing.T) {
+	clientset := testop.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	s := simpleStore()
+
+	// no keystone settings
+	assert.NoError(t, validateAuth(context, s))
+	useKeystone := true
+	s.Spec.Protocols.S3 = &cephv1.S3Spec{AuthUseKeystone: &useKeystone}
+	assert.Error(t, validateAuth(context, s))
+
+	// the secret must hold all the credentials of the service user
+	s.Spec.Auth.Keystone = keystoneSpec()
+	assert.Error(t, validateAuth(context, s))
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rgw-keystone", Namespace: s.Namespace},
+		Data: map[string][]byte{
+			keystoneUsernameKey: []byte("rgw"),
+			keystonePasswordKey: []byte("password"),
+			keystoneProjectKey:  []byte("service"),
+		},
+	}
+	_, err := clientset.CoreV1().Secrets(s.Namespace).Create(secret)
+	assert.NoError(t, err)
+	assert.Error(t, validateAuth(context, s))
+	secret.Data[keystoneUserDomainKey] = []byte("Default")
+	_, err = clientset.CoreV1().Secrets(s.Namespace).Update(secret)
+	assert.NoError(t, err)
+	assert.NoError(t, validateAuth(context, s))
+
+	// the url and the roles are required, the implicit tenants are validated
+	s.Spec.Auth.Keystone.ImplicitTenants = "all"
+	assert.Error(t, validateAuth(context, s))
+	s.Spec.Auth.Keystone = keystoneSpec()
+	s.Spec.Auth.Keystone.URL = "keystone:5000"
+	assert.Error(t, validateAuth(context, s))
+	s.Spec.Auth.Keystone = keystoneSpec()
+	s.Spec.Auth.Keystone.AcceptedRoles = nil
+	assert.Error(t, validateAuth(context, s))
+}
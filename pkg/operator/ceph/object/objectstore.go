@@ -19,6 +19,7 @@ package object
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"syscall"
@@ -468,9 +469,9 @@ func createSystemUser(objContext *Context, namespace string) error {
 
 func setMultisite(objContext *Context, store *cephv1.CephObjectStore, serviceIP string) error {
 	logger.Debugf("setting multisite configuration for object-store %v", store.Name)
-	serviceEndpoint := fmt.Sprintf("http://%s:%d", serviceIP, store.Spec.Gateway.Port)
+	serviceEndpoint := fmt.Sprintf("http://%s", net.JoinHostPort(serviceIP, strconv.Itoa(int(store.Spec.Gateway.Port))))
 	if store.Spec.Gateway.SecurePort != 0 {
-		serviceEndpoint = fmt.Sprintf("https://%s:%d", serviceIP, store.Spec.Gateway.SecurePort)
+		serviceEndpoint = fmt.Sprintf("https://%s", net.JoinHostPort(serviceIP, strconv.Itoa(int(store.Spec.Gateway.SecurePort))))
 	}
 
 	if store.Spec.IsMultisite() {
@@ -570,6 +571,7 @@ func deletePools(context *Context, spec cephv1.ObjectStoreSpec, lastStore bool)
 	if lastStore {
 		pools = append(pools, rootPool)
 	}
+	pools = append(pools, placementPools(spec.PlacementTargets)...)
 
 	for _, pool := range pools {
 		name := poolName(context.Name, pool)
@@ -584,13 +586,19 @@ func deletePools(context *Context, spec cephv1.ObjectStoreSpec, lastStore bool)
 		return errors.Wrapf(err, "failed to list erasure code profiles for cluster %s", context.clusterInfo.Namespace)
 	}
 	// cleans up the EC profile for the data pool only. Metadata pools don't support EC (only replication is supported).
-	ecProfileName := client.GetErasureCodeProfileForPool(context.Name)
-	for i := range erasureCodes {
-		if erasureCodes[i] == ecProfileName {
-			if err := ceph.DeleteErasureCodeProfile(context.Context, context.clusterInfo, ecProfileName); err != nil {
-				return errors.Wrapf(err, "failed to delete erasure code profile %s for object store %s", ecProfileName, context.Name)
+	ecProfileNames := []string{client.GetErasureCodeProfileForPool(context.Name)}
+	// and the profiles of the data pools of the placement targets
+	for _, pool := range placementDataPools(spec.PlacementTargets) {
+		ecProfileNames = append(ecProfileNames, client.GetErasureCodeProfileForPool(poolName(context.Name, pool)))
+	}
+	for _, ecProfileName := range ecProfileNames {
+		for i := range erasureCodes {
+			if erasureCodes[i] == ecProfileName {
+				if err := ceph.DeleteErasureCodeProfile(context.Context, context.clusterInfo, ecProfileName); err != nil {
+					return errors.Wrapf(err, "failed to delete erasure code profile %s for object store %s", ecProfileName, context.Name)
+				}
+				break
 			}
-			break
 		}
 	}
 
@@ -616,13 +624,7 @@ func CreatePools(context *Context, metadataPool, dataPool cephv1.PoolSpec) error
 		}
 	}
 
-	// get the default PG count for rgw metadata pools
-	metadataPoolPGs, err := config.GetMonStore(context.Context, context.clusterInfo).Get("mon.", "rgw_rados_pool_pg_num_min")
-	if err != nil {
-		logger.Warningf("failed to adjust the PG count for rgw metadata pools. using the general default. %v", err)
-		metadataPoolPGs = ceph.DefaultPGCount
-	}
-
+	metadataPoolPGs := metadataPoolPGCount(context)
 	if err := createSimilarPools(context, append(metadataPools, rootPool), metadataPool, metadataPoolPGs, ""); err != nil {
 		return errors.Wrap(err, "failed to create metadata pools")
 	}
@@ -643,6 +645,16 @@ func CreatePools(context *Context, metadataPool, dataPool cephv1.PoolSpec) error
 	return nil
 }
 
+// metadataPoolPGCount returns the default PG count of the rgw metadata pools
+func metadataPoolPGCount(context *Context) string {
+	metadataPoolPGs, err := config.GetMonStore(context.Context, context.clusterInfo).Get("mon.", "rgw_rados_pool_pg_num_min")
+	if err != nil {
+		logger.Warningf("failed to adjust the PG count for rgw metadata pools. using the general default. %v", err)
+		return ceph.DefaultPGCount
+	}
+	return metadataPoolPGs
+}
+
 func createSimilarPools(context *Context, pools []string, poolSpec cephv1.PoolSpec, pgCount, ecProfileName string) error {
 	for _, pool := range pools {
 		// create the pool if it doesn't exist yet
@@ -672,6 +684,10 @@ func createSimilarPools(context *Context, pools []string, poolSpec cephv1.PoolSp
 					}
 				}
 			}
+			// reset the compression, the pg autoscale mode and the target size ratio if changed out-of-band
+			if _, err := ceph.UpdatePoolProperties(context.Context, context.clusterInfo, name, poolSpec); err != nil {
+				return errors.Wrapf(err, "failed to update the properties of pool %q", name)
+			}
 		}
 		// Set the pg_num_min if not the default so the autoscaler won't immediately increase the pg count
 		if pgCount != ceph.DefaultPGCount {
@@ -0,0 +1,309 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/pool"
+)
+
+const (
+	// DefaultPlacementTarget is the placement target of the buckets created without location constraint
+	DefaultPlacementTarget = "default-placement"
+	standardStorageClass   = "STANDARD"
+)
+
+var (
+	placementTargetNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	storageClassNameRegex    = regexp.MustCompile(`^[A-Z0-9_]+$`)
+)
+
+type zonePlacementType struct {
+	PlacementPools []struct {
+		Key string                 `json:"key"`
+		Val zonePlacementPoolsType `json:"val"`
+	} `json:"placement_pools"`
+}
+
+type zonePlacementPoolsType struct {
+	IndexPool      string `json:"index_pool"`
+	DataExtraPool  string `json:"data_extra_pool"`
+	StorageClasses map[string]struct {
+		DataPool string `json:"data_pool"`
+	} `json:"storage_classes"`
+}
+
+type zoneGroupPlacementType struct {
+	PlacementTargets []struct {
+		Name           string   `json:"name"`
+		StorageClasses []string `json:"storage_classes"`
+	} `json:"placement_targets"`
+}
+
+// the pools of the default placement target are the pools of the store, the pools of the other targets are named
+// after them
+func placementPoolPrefix(target string) string {
+	if target == DefaultPlacementTarget {
+		return "rgw.buckets"
+	}
+	return "rgw." + target
+}
+
+func placementIndexPool(target string) string {
+	return placementPoolPrefix(target) + ".index"
+}
+
+func placementDataPool(target string) string {
+	return placementPoolPrefix(target) + ".data"
+}
+
+func placementExtraPool(target string) string {
+	return placementPoolPrefix(target) + ".non-ec"
+}
+
+func storageClassDataPool(target, storageClass string) string {
+	return fmt.Sprintf("%s.%s.data", placementPoolPrefix(target), strings.ToLower(storageClass))
+}
+
+// placementDataPools returns the data pools of the placement targets and their storage classes
+func placementDataPools(targets []cephv1.PlacementTargetSpec) []string {
+	pools := []string{}
+	for _, target := range targets {
+		if target.Name != DefaultPlacementTarget {
+			pools = append(pools, placementDataPool(target.Name))
+		}
+		for _, storageClass := range target.StorageClasses {
+			pools = append(pools, storageClassDataPool(target.Name, storageClass.Name))
+		}
+	}
+	return pools
+}
+
+// placementPools returns all the pools created for the placement targets
+func placementPools(targets []cephv1.PlacementTargetSpec) []string {
+	pools := placementDataPools(targets)
+	for _, target := range targets {
+		if target.Name != DefaultPlacementTarget {
+			pools = append(pools, placementIndexPool(target.Name), placementExtraPool(target.Name))
+		}
+	}
+	return pools
+}
+
+// validatePlacementTargets checks the names and the pools of the placement targets of a store
+func validatePlacementTargets(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, s *cephv1.CephObjectStore) error {
+	if len(s.Spec.PlacementTargets) == 0 {
+		return nil
+	}
+	if s.Spec.IsMultisite() {
+		return errors.New("the placement targets of the stores in a zone must be configured in the zone")
+	}
+	targets := map[string]bool{}
+	for _, target := range s.Spec.PlacementTargets {
+		if !placementTargetNameRegex.MatchString(target.Name) || target.Name == "buckets" {
+			return errors.Errorf("invalid placement target name %q", target.Name)
+		}
+		if targets[target.Name] {
+			return errors.Errorf("duplicate placement target %q", target.Name)
+		}
+		targets[target.Name] = true
+
+		if target.Name == DefaultPlacementTarget {
+			if !emptyPool(target.DataPool) {
+				return errors.Errorf("the data pool of the %q placement target is the data pool of the store", DefaultPlacementTarget)
+			}
+		} else {
+			if emptyPool(target.DataPool) || emptyPool(s.Spec.MetadataPool) {
+				return errors.Errorf("placement target %q requires a data pool and the metadata pool of the store", target.Name)
+			}
+			if err := pool.ValidatePoolSpec(context, clusterInfo, &target.DataPool); err != nil {
+				return errors.Wrapf(err, "invalid data pool of placement target %q", target.Name)
+			}
+		}
+
+		storageClasses := map[string]bool{}
+		for _, storageClass := range target.StorageClasses {
+			if !storageClassNameRegex.MatchString(storageClass.Name) || storageClass.Name == standardStorageClass {
+				return errors.Errorf("invalid storage class name %q of placement target %q", storageClass.Name, target.Name)
+			}
+			if storageClasses[storageClass.Name] {
+				return errors.Errorf("duplicate storage class %q of placement target %q", storageClass.Name, target.Name)
+			}
+			storageClasses[storageClass.Name] = true
+			if err := pool.ValidatePoolSpec(context, clusterInfo, &storageClass.DataPool); err != nil {
+				return errors.Wrapf(err, "invalid data pool of storage class %q of placement target %q", storageClass.Name, target.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// reconcilePlacementTargets creates the pools of the placement targets and adds the targets and their storage
+// classes to the zone group and the zone of the store. The placement targets removed from the spec are not removed
+// from the zone since the existing buckets still refer to them.
+func reconcilePlacementTargets(context *Context, spec cephv1.ObjectStoreSpec) error {
+	if len(spec.PlacementTargets) == 0 {
+		return nil
+	}
+	if err := createPlacementPools(context, spec); err != nil {
+		return err
+	}
+
+	output, err := runAdminCommand(context, "zonegroup", "get")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get zone group %q", context.ZoneGroup)
+	}
+	var zoneGroup zoneGroupPlacementType
+	if err := json.Unmarshal([]byte(output), &zoneGroup); err != nil {
+		return errors.Wrap(err, "failed to parse the placement targets of the zone group")
+	}
+	output, err = runAdminCommand(context, "zone", "get")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get zone %q", context.Zone)
+	}
+	var zone zonePlacementType
+	if err := json.Unmarshal([]byte(output), &zone); err != nil {
+		return errors.Wrap(err, "failed to parse the placement pools of the zone")
+	}
+
+	updatePeriod := false
+	for _, target := range spec.PlacementTargets {
+		zoneGroupClasses := map[string]bool{}
+		for _, t := range zoneGroup.PlacementTargets {
+			if t.Name == target.Name {
+				for _, storageClass := range t.StorageClasses {
+					zoneGroupClasses[storageClass] = true
+				}
+			}
+		}
+		var zonePools *zonePlacementPoolsType
+		for i, p := range zone.PlacementPools {
+			if p.Key == target.Name {
+				zonePools = &zone.PlacementPools[i].Val
+			}
+		}
+		zoneDataPool := func(storageClass string) string {
+			if zonePools == nil {
+				return ""
+			}
+			return zonePools.StorageClasses[storageClass].DataPool
+		}
+		placementArg := fmt.Sprintf("--placement-id=%s", target.Name)
+
+		if target.Name != DefaultPlacementTarget {
+			if !zoneGroupClasses[standardStorageClass] {
+				if output, err := runAdminCommand(context, "zonegroup", "placement", "add", placementArg); err != nil {
+					return errors.Wrapf(err, "failed to add placement target %q to the zone group, for reason %q", target.Name, output)
+				}
+				updatePeriod = true
+			}
+			indexPool := poolName(context.Name, placementIndexPool(target.Name))
+			dataPool := poolName(context.Name, placementDataPool(target.Name))
+			extraPool := poolName(context.Name, placementExtraPool(target.Name))
+			if zonePools == nil || zonePools.IndexPool != indexPool || zonePools.DataExtraPool != extraPool || zoneDataPool(standardStorageClass) != dataPool {
+				output, err := runAdminCommand(context, "zone", "placement", "add", placementArg,
+					fmt.Sprintf("--index-pool=%s", indexPool),
+					fmt.Sprintf("--data-pool=%s", dataPool),
+					fmt.Sprintf("--data-extra-pool=%s", extraPool))
+				if err != nil {
+					return errors.Wrapf(err, "failed to add the pools of placement target %q to the zone, for reason %q", target.Name, output)
+				}
+				updatePeriod = true
+			}
+		}
+
+		for _, storageClass := range target.StorageClasses {
+			storageClassArg := fmt.Sprintf("--storage-class=%s", storageClass.Name)
+			if !zoneGroupClasses[storageClass.Name] {
+				if output, err := runAdminCommand(context, "zonegroup", "placement", "add", placementArg, storageClassArg); err != nil {
+					return errors.Wrapf(err, "failed to add storage class %q of placement target %q to the zone group, for reason %q", storageClass.Name, target.Name, output)
+				}
+				updatePeriod = true
+			}
+			dataPool := poolName(context.Name, storageClassDataPool(target.Name, storageClass.Name))
+			if zoneDataPool(storageClass.Name) != dataPool {
+				output, err := runAdminCommand(context, "zone", "placement", "add", placementArg, storageClassArg, fmt.Sprintf("--data-pool=%s", dataPool))
+				if err != nil {
+					return errors.Wrapf(err, "failed to add the pool of storage class %q of placement target %q to the zone, for reason %q", storageClass.Name, target.Name, output)
+				}
+				updatePeriod = true
+			}
+		}
+	}
+
+	if updatePeriod {
+		if _, err := runAdminCommand(context, "period", "update", "--commit"); err != nil {
+			return errors.Wrap(err, "failed to update period")
+		}
+		logger.Infof("placement targets of object store %q updated", context.Name)
+	}
+	return nil
+}
+
+func createPlacementPools(context *Context, spec cephv1.ObjectStoreSpec) error {
+	metadataPoolPGs := metadataPoolPGCount(context)
+	for _, target := range spec.PlacementTargets {
+		if target.Name != DefaultPlacementTarget {
+			pools := []string{placementIndexPool(target.Name), placementExtraPool(target.Name)}
+			if err := createSimilarPools(context, pools, spec.MetadataPool, metadataPoolPGs, ""); err != nil {
+				return errors.Wrapf(err, "failed to create the index pools of placement target %q", target.Name)
+			}
+			if err := createPlacementDataPool(context, placementDataPool(target.Name), target.DataPool); err != nil {
+				return errors.Wrapf(err, "failed to create the data pool of placement target %q", target.Name)
+			}
+		}
+		for _, storageClass := range target.StorageClasses {
+			if err := createPlacementDataPool(context, storageClassDataPool(target.Name, storageClass.Name), storageClass.DataPool); err != nil {
+				return errors.Wrapf(err, "failed to create the data pool of storage class %q of placement target %q", storageClass.Name, target.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// createPlacementDataPool creates a data pool of a placement target, with its own erasure code profile
+func createPlacementDataPool(context *Context, pool string, poolSpec cephv1.PoolSpec) error {
+	ecProfileName := ""
+	if poolSpec.IsErasureCoded() {
+		ecProfileName = cephclient.GetErasureCodeProfileForPool(poolName(context.Name, pool))
+		if err := cephclient.CreateErasureCodeProfile(context.Context, context.clusterInfo, ecProfileName, poolSpec); err != nil {
+			return errors.Wrap(err, "failed to create erasure code profile")
+		}
+	}
+	return createSimilarPools(context, []string{pool}, poolSpec, cephclient.DefaultPGCount, ecProfileName)
+}
+
+// ValidatePlacementTarget returns an error if the placement target is not a placement target of the store
+func ValidatePlacementTarget(store *cephv1.CephObjectStore, target string) error {
+	if target == DefaultPlacementTarget {
+		return nil
+	}
+	for _, t := range store.Spec.PlacementTargets {
+		if t.Name == target {
+			return nil
+		}
+	}
+	return errors.Errorf("object store %q has no placement target %q", store.Name, target)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func placementTargets() []cephv1.PlacementTargetSpec {
+	replicated := cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 1}}
+	return []cephv1.PlacementTargetSpec{
+		{
+			Name:           DefaultPlacementTarget,
+			StorageClasses: []cephv1.PlacementStorageClassSpec{{Name: "COLD", DataPool: replicated}},
+		},
+		{
+			Name:     "fast",
+			DataPool: replicated,
+		},
+	}
+}
+
+func TestReconcilePlacementTargets(t *testing.T) {
+	createdPools := []string{}
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutputFile = func(command, outputFile string, args ...string) (string, error) {
+		if args[0] == "osd" && args[1] == "pool" {
+			if args[2] == "get" {
+				// the pools do not exist yet
+				return "", errors.New("pool not found")
+			}
+			if args[2] == "create" {
+				createdPools = append(createdPools, args[3])
+			}
+			return "", nil
+		}
+		if args[0] == "osd" && args[1] == "crush" {
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	zoneGroup := `{"placement_targets":[{"name":"default-placement","storage_classes":["STANDARD"]}]}`
+	zone := `{"placement_pools":[{"key":"default-placement","val":{"index_pool":"my-store.rgw.buckets.index",
+		"storage_classes":{"STANDARD":{"data_pool":"my-store.rgw.buckets.data"}},"data_extra_pool":"my-store.rgw.buckets.non-ec"}}]}`
+	commands := []string{}
+	executor.MockExecuteCommandWithOutput = func(_ string, args ...string) (string, error) {
+		switch {
+		case args[0] == "zonegroup" && args[1] == "get":
+			return zoneGroup, nil
+		case args[0] == "zone" && args[1] == "get":
+			return zone, nil
+		}
+		// the command without the multisite and connection args
+		command := []string{}
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "--rgw-realm") {
+				break
+			}
+			command = append(command, arg)
+		}
+		commands = append(commands, strings.Join(command, " "))
+		return "", nil
+	}
+	context := &Context{
+		Context:     &clusterd.Context{Executor: executor},
+		Name:        "my-store",
+		clusterInfo: client.AdminClusterInfo("ns"),
+		Realm:       "my-store",
+		ZoneGroup:   "my-store",
+		Zone:        "my-store",
+	}
+	spec := cephv1.ObjectStoreSpec{
+		MetadataPool:     cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 1}},
+		PlacementTargets: placementTargets(),
+	}
+
+	// the pools of the new target and of the storage classes are created and added to the zone
+	assert.NoError(t, reconcilePlacementTargets(context, spec))
+	assert.ElementsMatch(t, []string{"my-store.rgw.buckets.cold.data", "my-store.rgw.fast.index", "my-store.rgw.fast.non-ec", "my-store.rgw.fast.data"}, createdPools)
+	assert.ElementsMatch(t, placementPools(spec.PlacementTargets), []string{"rgw.buckets.cold.data", "rgw.fast.index", "rgw.fast.non-ec", "rgw.fast.data"})
+	assert.Equal(t, []string{
+		"zonegroup placement add --placement-id=default-placement --storage-class=COLD",
+		"zone placement add --placement-id=default-placement --storage-class=COLD --data-pool=my-store.rgw.buckets.cold.data",
+		"zonegroup placement add --placement-id=fast",
+		"zone placement add --placement-id=fast --index-pool=my-store.rgw.fast.index --data-pool=my-store.rgw.fast.data --data-extra-pool=my-store.rgw.fast.non-ec",
+		"period update --commit",
+	}, commands)
+
+	// the period is not updated when the zone has the targets
+	zoneGroup = `{"placement_targets":[{"name":"default-placement","storage_classes":["STANDARD","COLD"]},
+		{"name":"fast","storage_classes":["STANDARD"]}]}`
+	zone = `{"placement_pools":[{"key":"default-placement","val":{"index_pool":"my-store.rgw.buckets.index",
+		"storage_classes":{"STANDARD":{"data_pool":"my-store.rgw.buckets.data"},"COLD":{"data_pool":"my-store.rgw.buckets.cold.data"}}}},
+		{"key":"fast","val":{"index_pool":"my-store.rgw.fast.index","storage_classes":{"STANDARD":{"data_pool":"my-store.rgw.fast.data"}},
+		"data_extra_pool":"my-store.rgw.fast.non-ec"}}]}`
+	commands = []string{}
+	assert.NoError(t, reconcilePlacementTargets(context, spec))
+	assert.Empty(t, commands)
+}
+
+func TestValidatePlacementTargets(t *testing.T) {
+	context := &clusterd.Context{Executor: &exectest.MockExecutor{}}
+	clusterInfo := &client.ClusterInfo{Namespace: "ns"}
+	s := simpleStore()
+	s.Spec.MetadataPool = cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{Size: 1}}
+	s.Spec.PlacementTargets = placementTargets()
+	assert.NoError(t, validatePlacementTargets(context, clusterInfo, s))
+	assert.NoError(t, ValidatePlacementTarget(s, "fast"))
+	assert.NoError(t, ValidatePlacementTarget(s, DefaultPlacementTarget))
+	assert.Error(t, ValidatePlacementTarget(s, "slow"))
+
+	// the default target has the data pool of the store
+	s.Spec.PlacementTargets[0].DataPool = s.Spec.MetadataPool
+	assert.Error(t, validatePlacementTargets(context, clusterInfo, s))
+
+	// the other targets need a data pool
+	s.Spec.PlacementTargets = placementTargets()
+	s.Spec.PlacementTargets[1].DataPool = cephv1.PoolSpec{}
+	assert.Error(t, validatePlacementTargets(context, clusterInfo, s))
+
+	// the names are validated
+	s.Spec.PlacementTargets = placementTargets()
+	s.Spec.PlacementTargets[1].Name = "Fast"
+	assert.Error(t, validatePlacementTargets(context, clusterInfo, s))
+	s.Spec.PlacementTargets = placementTargets()
+	s.Spec.PlacementTargets[0].StorageClasses[0].Name = standardStorageClass
+	assert.Error(t, validatePlacementTargets(context, clusterInfo, s))
+	s.Spec.PlacementTargets = append(placementTargets(), placementTargets()[1])
+	assert.Error(t, validatePlacementTargets(context, clusterInfo, s))
+
+	// the targets of a zone are not supported
+	s.Spec.PlacementTargets = placementTargets()
+	s.Spec.Zone.Name = "zone-a"
+	assert.Error(t, validatePlacementTargets(context, clusterInfo, s))
+}
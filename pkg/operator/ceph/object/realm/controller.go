@@ -47,6 +47,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -71,6 +72,7 @@ var controllerTypeMeta = metav1.TypeMeta{
 // ReconcileObjectRealm reconciles a ObjectRealm object
 type ReconcileObjectRealm struct {
 	client      client.Client
+	recorder    record.EventRecorder
 	scheme      *runtime.Scheme
 	context     *clusterd.Context
 	clusterInfo *cephclient.ClusterInfo
@@ -91,15 +93,16 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 	}
 
 	return &ReconcileObjectRealm{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -120,7 +123,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileObjectRealm) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephObjectRealm{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephObjectRealm{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile: %v", err)
 	}
@@ -202,6 +210,7 @@ func (r *ReconcileObjectRealm) reconcile(request reconcile.Request) (reconcile.R
 
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephObjectRealm, cephObjectRealm.Spec)
 
 	// Return and do not requeue
 	logger.Debug("realm done reconciling")
@@ -210,7 +219,18 @@ func (r *ReconcileObjectRealm) reconcile(request reconcile.Request) (reconcile.R
 
 func (r *ReconcileObjectRealm) pullCephRealm(realm *cephv1.CephObjectRealm) (reconcile.Result, error) {
 	realmArg := fmt.Sprintf("--rgw-realm=%s", realm.Name)
-	urlArg := fmt.Sprintf("--url=%s", realm.Spec.Pull.Endpoint)
+	endpoint := realm.Spec.Pull.Endpoint
+	if realm.Spec.IsExternalRealm() {
+		externalEndpoint, err := r.importExternalRealm(realm)
+		if err != nil {
+			return waitForRequeueIfRealmNotReady, errors.Wrapf(err, "failed to import realm %q from the external cluster", realm.Name)
+		}
+		// an endpoint set in the spec may be needed when the endpoint of the master zone is not resolvable locally
+		if endpoint == "" {
+			endpoint = externalEndpoint
+		}
+	}
+	urlArg := fmt.Sprintf("--url=%s", endpoint)
 	logger.Debug("getting keys to pull realm")
 	accessKeyArg, secretKeyArg, err := object.GetRealmKeyArgs(r.context, realm.Name, realm.Namespace)
 	if err != nil {
@@ -219,7 +239,7 @@ func (r *ReconcileObjectRealm) pullCephRealm(realm *cephv1.CephObjectRealm) (rec
 		}
 		return waitForRequeueIfRealmNotReady, errors.Wrap(err, "failed to get keys for realm")
 	}
-	logger.Debugf("keys found to pull realm, getting ready to pull from endpoint %q", realm.Spec.Pull.Endpoint)
+	logger.Debugf("keys found to pull realm, getting ready to pull from endpoint %q", endpoint)
 
 	objContext := object.NewContext(r.context, r.clusterInfo, realm.Name)
 	output, err := object.RunAdminCommandNoMultisite(objContext, "realm", "pull", realmArg, urlArg, accessKeyArg, secretKeyArg)
@@ -227,11 +247,56 @@ func (r *ReconcileObjectRealm) pullCephRealm(realm *cephv1.CephObjectRealm) (rec
 	if err != nil {
 		return waitForRequeueIfRealmNotReady, errors.Wrapf(err, "realm pull failed for reason: %v", output)
 	}
-	logger.Debugf("realm pull for %q from endpoint %q succeeded", realm.Name, realm.Spec.Pull.Endpoint)
+	logger.Debugf("realm pull for %q from endpoint %q succeeded", realm.Name, endpoint)
 
 	return reconcile.Result{}, nil
 }
 
+// importExternalRealm saves the keys of the system user of a realm whose master zone lives in an external cluster in
+// the keys secret of the realm. It returns the endpoint of the master zone.
+func (r *ReconcileObjectRealm) importExternalRealm(realm *cephv1.CephObjectRealm) (string, error) {
+	externalNamespace := realm.Spec.Pull.External.ClusterNamespace
+	externalClusterInfo, _, _, err := mon.LoadClusterInfo(r.context, externalNamespace)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load the connection to the external cluster in namespace %q", externalNamespace)
+	}
+	externalRealm, err := object.GetExternalRealm(r.context, externalClusterInfo, realm)
+	if err != nil {
+		return "", err
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      realm.Name + "-keys",
+			Namespace: realm.Namespace,
+		},
+		Data: map[string][]byte{
+			object.AccessKeyName: []byte(externalRealm.AccessKey),
+			object.SecretKeyName: []byte(externalRealm.SecretKey),
+		},
+		Type: k8sutil.RookType,
+	}
+	ownerRef, err := opcontroller.GetControllerObjectOwnerReference(realm, r.scheme)
+	if err != nil || ownerRef == nil {
+		return "", errors.Wrapf(err, "failed to get controller %q owner reference", realm.Name)
+	}
+	k8sutil.SetOwnerRef(&secret.ObjectMeta, ownerRef)
+
+	// the keys are imported again on every reconcile in case they were rotated in the external cluster
+	_, err = r.context.Clientset.CoreV1().Secrets(realm.Namespace).Create(secret)
+	if err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return "", errors.Wrap(err, "failed to save the keys of the realm")
+		}
+		if _, err = r.context.Clientset.CoreV1().Secrets(realm.Namespace).Update(secret); err != nil {
+			return "", errors.Wrap(err, "failed to update the keys of the realm")
+		}
+	}
+	logger.Infof("imported the keys of realm %q from the external cluster in namespace %q", realm.Name, externalNamespace)
+
+	return externalRealm.Endpoint, nil
+}
+
 func (r *ReconcileObjectRealm) createCephRealm(realm *cephv1.CephObjectRealm) (reconcile.Result, error) {
 	realmArg := fmt.Sprintf("--rgw-realm=%s", realm.Name)
 	objContext := object.NewContext(r.context, r.clusterInfo, realm.Namespace)
@@ -309,6 +374,9 @@ func validateRealmCR(u *cephv1.CephObjectRealm) error {
 	if u.Namespace == "" {
 		return errors.New("missing namespace")
 	}
+	if u.Spec.IsExternalRealm() && u.Spec.Pull.External.ClusterNamespace == "" {
+		return errors.New("missing namespace of the external cluster")
+	}
 	return nil
 }
 
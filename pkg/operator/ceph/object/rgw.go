@@ -56,6 +56,8 @@ type rgwConfig struct {
 	Realm        string
 	ZoneGroup    string
 	Zone         string
+	// CertificateHash is the hash of the managed certificate of the secure port
+	CertificateHash string
 }
 
 const (
@@ -97,6 +99,18 @@ func (c *clusterConfig) startRGWPods(realmName, zoneGroupName, zoneName string)
 	}
 	c.ownerRef = ref
 
+	if err := c.reconcileSTSKey(); err != nil {
+		return err
+	}
+
+	var certificateHash string
+	if c.managedCertificate() {
+		certificateHash, err = c.issueCertificate()
+		if err != nil {
+			return err
+		}
+	}
+
 	// start a new deployment and scale up
 	desiredRgwInstances := int(c.store.Spec.Gateway.Instances)
 	for i := 0; i < desiredRgwInstances; i++ {
@@ -109,11 +123,12 @@ func (c *clusterConfig) startRGWPods(realmName, zoneGroupName, zoneName string)
 		resourceName := fmt.Sprintf("%s-%s-%s", AppName, c.store.Name, daemonLetterID)
 
 		rgwConfig := &rgwConfig{
-			ResourceName: resourceName,
-			DaemonID:     daemonName,
-			Realm:        realmName,
-			ZoneGroup:    zoneGroupName,
-			Zone:         zoneName,
+			ResourceName:    resourceName,
+			DaemonID:        daemonName,
+			Realm:           realmName,
+			ZoneGroup:       zoneGroupName,
+			Zone:            zoneName,
+			CertificateHash: certificateHash,
 		}
 
 		// We set the owner reference of the Secret to the Object controller instead of the replicaset
@@ -162,7 +177,7 @@ func (c *clusterConfig) startRGWPods(realmName, zoneGroupName, zoneName string)
 				return errors.Wrap(createErr, "failed to create rgw deployment")
 			}
 			logger.Infof("object store %q deployment %q already exists. updating if needed", c.store.Name, deployment.Name)
-			if err := updateDeploymentAndWait(c.context, c.clusterInfo, deployment, config.RgwType, daemonLetterID, c.clusterSpec.SkipUpgradeChecks, c.clusterSpec.ContinueUpgradeAfterChecksEvenIfNotHealthy); err != nil {
+			if err := updateDeploymentAndWait(c.context, c.clusterInfo, deployment, config.RgwType, daemonLetterID, cephclient.DaemonUpgradeChecks(c.clusterSpec, config.RgwType)); err != nil {
 				return errors.Wrapf(err, "failed to update object store %q deployment %q", c.store.Name, deployment.Name)
 			}
 		}
@@ -306,7 +321,7 @@ func (c *clusterConfig) deleteRgwCephObjects(depNameToRemove string) error {
 		return err
 	}
 
-	err = cephclient.AuthDelete(c.context, c.clusterInfo, generateCephXUser(depNameToRemove))
+	err = cephclient.AuthDelete(c.context, c.clusterInfo, GenerateCephXUser(depNameToRemove))
 	if err != nil {
 		return err
 	}
@@ -335,6 +350,12 @@ func (r *ReconcileCephObjectStore) validateStore(s *cephv1.CephObjectStore) erro
 	if securePort < 0 || securePort > 65535 {
 		return errors.Errorf("securePort value of %d must be between 0 and 65535", securePort)
 	}
+	// on the host network the ports of the gateway must not conflict with the ports of the other daemons
+	for _, port := range []int32{s.Spec.Gateway.Port, securePort} {
+		if err := config.ValidateHostPort(r.cephClusterSpec.Network, port); err != nil {
+			return errors.Wrap(err, "invalid gateway port on the host network")
+		}
+	}
 
 	// Validate the pool settings, but allow for empty pools specs in case they have already been created
 	// such as by the ceph mgr
@@ -356,6 +377,26 @@ func (r *ReconcileCephObjectStore) validateStore(s *cephv1.CephObjectStore) erro
 		}
 	}
 
+	if err := validateSecurity(r.context, r.clusterInfo, s); err != nil {
+		return errors.Wrap(err, "invalid security settings")
+	}
+
+	if err := validateAuth(r.context, s); err != nil {
+		return errors.Wrap(err, "invalid auth settings")
+	}
+
+	if err := validateSTS(r.context, s); err != nil {
+		return errors.Wrap(err, "invalid sts settings")
+	}
+
+	if err := validatePlacementTargets(r.context, r.clusterInfo, s); err != nil {
+		return errors.Wrap(err, "invalid placement targets")
+	}
+
+	if err := validateUsageMetrics(s.Spec.UsageMetrics); err != nil {
+		return errors.Wrap(err, "invalid usage metrics settings")
+	}
+
 	return nil
 }
 
@@ -61,15 +61,20 @@ func NewS3Agent(accessKey, secretKey, endpoint string) (*S3Agent, error) {
 
 // CreateBucket creates a bucket with the given name
 func (s *S3Agent) CreateBucketNoInfoLogging(name string) error {
-	return s.createBucket(name, false)
+	return s.createBucket(name, "", false)
 }
 
 // CreateBucket creates a bucket with the given name
 func (s *S3Agent) CreateBucket(name string) error {
-	return s.createBucket(name, true)
+	return s.createBucket(name, "", true)
 }
 
-func (s *S3Agent) createBucket(name string, infoLogging bool) error {
+// CreateBucketInPlacement creates a bucket with the given name in a placement target of the zone group
+func (s *S3Agent) CreateBucketInPlacement(name, placementTarget string) error {
+	return s.createBucket(name, placementTarget, true)
+}
+
+func (s *S3Agent) createBucket(name, placementTarget string, infoLogging bool) error {
 	if infoLogging {
 		logger.Infof("creating bucket %q", name)
 	} else {
@@ -78,6 +83,12 @@ func (s *S3Agent) createBucket(name string, infoLogging bool) error {
 	bucketInput := &s3.CreateBucketInput{
 		Bucket: &name,
 	}
+	if placementTarget != "" {
+		// the location constraint of the rgw is <zone group>:<placement target>, the zone group of the rgw by default
+		bucketInput.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(":" + placementTarget),
+		}
+	}
 	_, err := s.Client.CreateBucket(bucketInput)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The formats of the S3 keys generated in the secrets of the users and of the OBCs in addition to the raw keys
+const (
+	// SecretFormatAWSCredentials is an AWS shared credentials file in the "credentials" key
+	SecretFormatAWSCredentials = "aws-credentials"
+	// SecretFormatRclone is an rclone remote of the object store in the "rclone.conf" key
+	SecretFormatRclone = "rclone"
+	// SecretFormatNetrc is a .netrc file with the keys as login and password of the endpoint in the ".netrc" key
+	SecretFormatNetrc = "netrc"
+)
+
+var secretFormatKeys = map[string]string{
+	SecretFormatAWSCredentials: "credentials",
+	SecretFormatRclone:         "rclone.conf",
+	SecretFormatNetrc:          ".netrc",
+}
+
+// ValidateSecretFormats returns an error if a format is not a format of the generated secrets
+func ValidateSecretFormats(formats []string) error {
+	for _, format := range formats {
+		if _, ok := secretFormatKeys[format]; !ok {
+			return errors.Errorf("invalid secret format %q, must be %q, %q or %q", format, SecretFormatAWSCredentials, SecretFormatRclone, SecretFormatNetrc)
+		}
+	}
+	return nil
+}
+
+// FormattedSecretData returns the data of the S3 keys in the formats, by secret key. The name is the name of the rclone
+// remote, and the endpoint is the URL of the object store, "http://" is added when it has no scheme.
+func FormattedSecretData(formats []string, name, endpoint, accessKey, secretKey string) (map[string]string, error) {
+	if err := ValidateSecretFormats(formats); err != nil {
+		return nil, err
+	}
+	if endpoint != "" && !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	data := map[string]string{}
+	for _, format := range formats {
+		switch format {
+		case SecretFormatAWSCredentials:
+			data[secretFormatKeys[format]] = fmt.Sprintf("[default]\naws_access_key_id = %s\naws_secret_access_key = %s\n", accessKey, secretKey)
+		case SecretFormatRclone:
+			data[secretFormatKeys[format]] = fmt.Sprintf("[%s]\ntype = s3\nprovider = Ceph\naccess_key_id = %s\nsecret_access_key = %s\nendpoint = %s\n",
+				name, accessKey, secretKey, endpoint)
+		case SecretFormatNetrc:
+			u, err := url.Parse(endpoint)
+			if err != nil || u.Hostname() == "" {
+				return nil, errors.Errorf("invalid endpoint %q for the %q secret format", endpoint, format)
+			}
+			data[secretFormatKeys[format]] = fmt.Sprintf("machine %s\nlogin %s\npassword %s\n", u.Hostname(), accessKey, secretKey)
+		}
+	}
+	return data, nil
+}
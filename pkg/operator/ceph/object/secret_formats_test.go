@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormattedSecretData(t *testing.T) {
+	data, err := FormattedSecretData(nil, "my-user", "rook-ceph-rgw-my-store.rook-ceph:80", "ACCESS", "secret")
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+
+	data, err = FormattedSecretData([]string{"aws-credentials", "rclone", "netrc"}, "my-user", "rook-ceph-rgw-my-store.rook-ceph:80", "ACCESS", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "[default]\naws_access_key_id = ACCESS\naws_secret_access_key = secret\n", data["credentials"])
+	assert.Equal(t, "[my-user]\ntype = s3\nprovider = Ceph\naccess_key_id = ACCESS\nsecret_access_key = secret\nendpoint = http://rook-ceph-rgw-my-store.rook-ceph:80\n", data["rclone.conf"])
+	assert.Equal(t, "machine rook-ceph-rgw-my-store.rook-ceph\nlogin ACCESS\npassword secret\n", data[".netrc"])
+
+	// the scheme of the endpoint is kept
+	data, err = FormattedSecretData([]string{"rclone"}, "my-user", "https://s3.example.com", "ACCESS", "secret")
+	assert.NoError(t, err)
+	assert.Contains(t, data["rclone.conf"], "endpoint = https://s3.example.com\n")
+
+	_, err = FormattedSecretData([]string{"netrc"}, "my-user", "", "ACCESS", "secret")
+	assert.Error(t, err)
+	_, err = FormattedSecretData([]string{"s3cmd"}, "my-user", "", "ACCESS", "secret")
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"path"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the connection details of the vault backends
+	kmsProviderKey       = "KMS_PROVIDER"
+	vaultProvider        = "vault"
+	vaultAddrKey         = "VAULT_ADDR"
+	vaultBackendPathKey  = "VAULT_BACKEND_PATH"
+	vaultSecretEngineKey = "VAULT_SECRET_ENGINE"
+	vaultNamespaceKey    = "VAULT_NAMESPACE"
+
+	vaultSecretEngineKV      = "kv"
+	vaultSecretEngineTransit = "transit"
+
+	// vaultTokenKey is the key of the token in the token secret of a vault backend
+	vaultTokenKey = "token"
+
+	kmsVolumeName   = "rgw-vault-kms-token"
+	kmsTokenDir     = "/etc/ceph/vault-kms"
+	sseS3VolumeName = "rgw-vault-sse-s3-token"
+	sseS3TokenDir   = "/etc/ceph/vault-sse-s3"
+)
+
+// sseS3MinimumVersion is the first Ceph release whose rgw manages the keys of the SSE-S3 requests with vault
+var sseS3MinimumVersion = cephver.CephVersion{Major: 17}
+
+// vaultBackend is a vault backend of the server-side encryption of the rgw
+type vaultBackend struct {
+	// name of the backend in the errors
+	name       string
+	volumeName string
+	tokenDir   string
+	// flagPrefix is the prefix of the rgw options of the backend
+	flagPrefix string
+	// backendFlag is the rgw option selecting vault as the backend
+	backendFlag string
+	// engines are the vault secret engines supported by the backend
+	engines []string
+}
+
+var (
+	kmsBackend = vaultBackend{
+		name:        "kms",
+		volumeName:  kmsVolumeName,
+		tokenDir:    kmsTokenDir,
+		flagPrefix:  "rgw crypt vault",
+		backendFlag: "rgw crypt s3 kms backend",
+		engines:     []string{vaultSecretEngineKV, vaultSecretEngineTransit},
+	}
+	sseS3Backend = vaultBackend{
+		name:        "s3",
+		volumeName:  sseS3VolumeName,
+		tokenDir:    sseS3TokenDir,
+		flagPrefix:  "rgw crypt sse s3 vault",
+		backendFlag: "rgw crypt sse s3 backend",
+		engines:     []string{vaultSecretEngineTransit},
+	}
+)
+
+// vaultBackendConfig is a vault backend configured in the security settings of a store
+type vaultBackendConfig struct {
+	vaultBackend
+	spec cephv1.KeyManagementServiceSpec
+}
+
+// vaultBackends returns the vault backends configured in the security settings of a store
+func vaultBackends(security *cephv1.ObjectStoreSecuritySpec) []vaultBackendConfig {
+	backends := []vaultBackendConfig{}
+	if security == nil {
+		return backends
+	}
+	if security.KeyManagementService.IsEnabled() {
+		backends = append(backends, vaultBackendConfig{kmsBackend, security.KeyManagementService})
+	}
+	if security.ServerSideEncryptionS3.IsEnabled() {
+		backends = append(backends, vaultBackendConfig{sseS3Backend, security.ServerSideEncryptionS3})
+	}
+	return backends
+}
+
+// secretEngine returns the vault secret engine of the backend, the kv engine by default
+func (b vaultBackend) secretEngine(spec cephv1.KeyManagementServiceSpec) string {
+	if engine := spec.ConnectionDetails[vaultSecretEngineKey]; engine != "" {
+		return engine
+	}
+	return b.engines[0]
+}
+
+// prefix returns the path of the vault api serving the keys of the backend
+func (b vaultBackend) prefix(spec cephv1.KeyManagementServiceSpec) string {
+	engine := b.secretEngine(spec)
+	backendPath := spec.ConnectionDetails[vaultBackendPathKey]
+	if backendPath == "" {
+		backendPath = engine
+		if engine == vaultSecretEngineKV {
+			backendPath = "secret"
+		}
+	}
+	if engine == vaultSecretEngineTransit {
+		return path.Join("/v1", backendPath, "export/encryption-key")
+	}
+	return path.Join("/v1", backendPath)
+}
+
+// flags returns the rgw options connecting the backend to vault
+func (b vaultBackend) flags(spec cephv1.KeyManagementServiceSpec) []string {
+	flags := []string{
+		cephconfig.NewFlag(b.backendFlag, vaultProvider),
+		cephconfig.NewFlag(b.flagPrefix+" auth", "token"),
+		cephconfig.NewFlag(b.flagPrefix+" addr", spec.ConnectionDetails[vaultAddrKey]),
+		cephconfig.NewFlag(b.flagPrefix+" token file", path.Join(b.tokenDir, vaultTokenKey)),
+		cephconfig.NewFlag(b.flagPrefix+" secret engine", b.secretEngine(spec)),
+		cephconfig.NewFlag(b.flagPrefix+" prefix", b.prefix(spec)),
+	}
+	if namespace := spec.ConnectionDetails[vaultNamespaceKey]; namespace != "" {
+		flags = append(flags, cephconfig.NewFlag(b.flagPrefix+" namespace", namespace))
+	}
+	return flags
+}
+
+// volume returns the volume of the vault token of the backend. Like the ssl certificate, the token is readable by
+// everyone since the secret mount is owned by root and the rgw runs as the ceph user.
+func (b vaultBackend) volume(spec cephv1.KeyManagementServiceSpec) v1.Volume {
+	userReadOnly := int32(0444)
+	return v1.Volume{
+		Name: b.volumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: spec.TokenSecretName,
+				Items: []v1.KeyToPath{
+					{Key: vaultTokenKey, Path: vaultTokenKey, Mode: &userReadOnly},
+				}}}}
+}
+
+func (b vaultBackend) volumeMount() v1.VolumeMount {
+	return v1.VolumeMount{Name: b.volumeName, MountPath: b.tokenDir, ReadOnly: true}
+}
+
+// validate checks the settings of the backend and that its token secret holds a token
+func (b vaultBackend) validate(context *clusterd.Context, namespace string, cephVersion *cephver.CephVersion, spec cephv1.KeyManagementServiceSpec) error {
+	if provider := spec.ConnectionDetails[kmsProviderKey]; provider != vaultProvider {
+		return errors.Errorf("invalid %s provider %q, only %q is supported", b.name, provider, vaultProvider)
+	}
+	if spec.ConnectionDetails[vaultAddrKey] == "" {
+		return errors.Errorf("missing %s %s", b.name, vaultAddrKey)
+	}
+	engine := b.secretEngine(spec)
+	supported := false
+	for _, e := range b.engines {
+		supported = supported || e == engine
+	}
+	if !supported {
+		return errors.Errorf("invalid %s %s %q, must be one of %v", b.name, vaultSecretEngineKey, engine, b.engines)
+	}
+	if b.name == sseS3Backend.name && cephVersion != nil && !cephVersion.IsAtLeast(sseS3MinimumVersion) {
+		return errors.Errorf("sse-s3 with vault requires ceph %d or newer, the cluster runs ceph %q", sseS3MinimumVersion.Major, cephVersion.String())
+	}
+	if spec.TokenSecretName == "" {
+		return errors.Errorf("missing %s tokenSecretName", b.name)
+	}
+	secret, err := context.Clientset.CoreV1().Secrets(namespace).Get(spec.TokenSecretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %s vault token secret %q", b.name, spec.TokenSecretName)
+	}
+	if len(secret.Data[vaultTokenKey]) == 0 && secret.StringData[vaultTokenKey] == "" {
+		return errors.Errorf("%s vault token secret %q has no %q key", b.name, spec.TokenSecretName, vaultTokenKey)
+	}
+	return nil
+}
+
+// validateSecurity checks the server-side encryption settings of a store
+func validateSecurity(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, s *cephv1.CephObjectStore) error {
+	if s.Spec.Security == nil {
+		return nil
+	}
+	var cephVersion *cephver.CephVersion
+	if clusterInfo != nil {
+		cephVersion = &clusterInfo.CephVersion
+	}
+	for _, b := range vaultBackends(s.Spec.Security) {
+		if err := b.validate(context, s.Namespace, cephVersion, b.spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
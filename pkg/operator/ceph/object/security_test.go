@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func vaultSpec(engine string) cephv1.KeyManagementServiceSpec {
+	return cephv1.KeyManagementServiceSpec{
+		ConnectionDetails: map[string]string{
+			kmsProviderKey:       vaultProvider,
+			vaultAddrKey:         "https://vault.default.svc:8200",
+			vaultSecretEngineKey: engine,
+		},
+		TokenSecretName: "rgw-vault-token",
+	}
+}
+
+func TestVaultFlags(t *testing.T) {
+	kv := vaultSpec(vaultSecretEngineKV)
+	assert.Equal(t, []string{
+		"--rgw-crypt-s3-kms-backend=vault",
+		"--rgw-crypt-vault-auth=token",
+		"--rgw-crypt-vault-addr=https://vault.default.svc:8200",
+		"--rgw-crypt-vault-token-file=/etc/ceph/vault-kms/token",
+		"--rgw-crypt-vault-secret-engine=kv",
+		"--rgw-crypt-vault-prefix=/v1/secret",
+	}, kmsBackend.flags(kv))
+
+	// the backend path and the namespace are optional
+	transit := vaultSpec(vaultSecretEngineTransit)
+	transit.ConnectionDetails[vaultBackendPathKey] = "rgw-transit"
+	transit.ConnectionDetails[vaultNamespaceKey] = "ns1"
+	flags := sseS3Backend.flags(transit)
+	assert.Contains(t, flags, "--rgw-crypt-sse-s3-backend=vault")
+	assert.Contains(t, flags, "--rgw-crypt-sse-s3-vault-token-file=/etc/ceph/vault-sse-s3/token")
+	assert.Contains(t, flags, "--rgw-crypt-sse-s3-vault-prefix=/v1/rgw-transit/export/encryption-key")
+	assert.Contains(t, flags, "--rgw-crypt-sse-s3-vault-namespace=ns1")
+
+	delete(transit.ConnectionDetails, vaultBackendPathKey)
+	assert.Equal(t, "/v1/transit/export/encryption-key", sseS3Backend.prefix(transit))
+	delete(transit.ConnectionDetails, vaultSecretEngineKey)
+	assert.Equal(t, vaultSecretEngineTransit, sseS3Backend.secretEngine(transit))
+	assert.Equal(t, vaultSecretEngineKV, kmsBackend.secretEngine(transit))
+}
+
+func TestSecurityPodSpec(t *testing.T) {
+	store := simpleStore()
+	store.Spec.Security = &cephv1.ObjectStoreSecuritySpec{
+		KeyManagementService:   vaultSpec(vaultSecretEngineKV),
+		ServerSideEncryptionS3: vaultSpec(vaultSecretEngineTransit),
+	}
+	info := clienttest.CreateTestClusterInfo(1)
+	info.CephVersion = cephver.Octopus
+	c := &clusterConfig{
+		clusterInfo: info,
+		store:       store,
+		clusterSpec: &cephv1.ClusterSpec{CephVersion: cephv1.CephVersionSpec{Image: "ceph/ceph:v15"}},
+		DataPathMap: cephconfig.NewStatelessDaemonDataPathMap(cephconfig.RgwType, "default", "rook-ceph", "/var/lib/rook/"),
+	}
+
+	s, err := c.makeRGWPodSpec(&rgwConfig{ResourceName: "rook-ceph-rgw-default"})
+	assert.NoError(t, err)
+	volumes := map[string]string{}
+	for _, volume := range s.Spec.Volumes {
+		if volume.Secret != nil {
+			volumes[volume.Name] = volume.Secret.SecretName
+		}
+	}
+	assert.Equal(t, "rgw-vault-token", volumes[kmsVolumeName])
+	assert.Equal(t, "rgw-vault-token", volumes[sseS3VolumeName])
+
+	container := s.Spec.Containers[0]
+	mounts := map[string]string{}
+	for _, mount := range container.VolumeMounts {
+		mounts[mount.Name] = mount.MountPath
+	}
+	assert.Equal(t, kmsTokenDir, mounts[kmsVolumeName])
+	assert.Equal(t, sseS3TokenDir, mounts[sseS3VolumeName])
+	assert.Contains(t, container.Args, "--rgw-crypt-vault-token-file=/etc/ceph/vault-kms/token")
+	assert.Contains(t, container.Args, "--rgw-crypt-sse-s3-vault-secret-engine=transit")
+}
+
+func TestValidateSecurity(t *testing.T) {
+	clientset := testop.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	info := clienttest.CreateTestClusterInfo(1)
+	info.CephVersion = cephver.CephVersion{Major: 17}
+	s := simpleStore()
+
+	// no security settings
+	assert.NoError(t, validateSecurity(context, info, s))
+
+	// the token secret must exist and hold a token
+	s.Spec.Security = &cephv1.ObjectStoreSecuritySpec{KeyManagementService: vaultSpec(vaultSecretEngineKV)}
+	assert.Error(t, validateSecurity(context, info, s))
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rgw-vault-token", Namespace: s.Namespace},
+		Data:       map[string][]byte{"other": []byte("value")},
+	}
+	_, err := clientset.CoreV1().Secrets(s.Namespace).Create(secret)
+	assert.NoError(t, err)
+	assert.Error(t, validateSecurity(context, info, s))
+	secret.Data[vaultTokenKey] = []byte("s.token")
+	_, err = clientset.CoreV1().Secrets(s.Namespace).Update(secret)
+	assert.NoError(t, err)
+	assert.NoError(t, validateSecurity(context, info, s))
+
+	// only vault is supported and its address is required
+	s.Spec.Security.KeyManagementService.ConnectionDetails[kmsProviderKey] = "aws"
+	assert.Error(t, validateSecurity(context, info, s))
+	s.Spec.Security.KeyManagementService.ConnectionDetails[kmsProviderKey] = vaultProvider
+	delete(s.Spec.Security.KeyManagementService.ConnectionDetails, vaultAddrKey)
+	assert.Error(t, validateSecurity(context, info, s))
+	s.Spec.Security.KeyManagementService = vaultSpec("kv2")
+	assert.Error(t, validateSecurity(context, info, s))
+	s.Spec.Security.KeyManagementService = vaultSpec(vaultSecretEngineTransit)
+	s.Spec.Security.KeyManagementService.TokenSecretName = ""
+	assert.Error(t, validateSecurity(context, info, s))
+
+	// sse-s3 requires the transit engine and a recent ceph
+	s.Spec.Security.KeyManagementService = cephv1.KeyManagementServiceSpec{}
+	s.Spec.Security.ServerSideEncryptionS3 = vaultSpec(vaultSecretEngineKV)
+	assert.Error(t, validateSecurity(context, info, s))
+	s.Spec.Security.ServerSideEncryptionS3 = vaultSpec(vaultSecretEngineTransit)
+	assert.NoError(t, validateSecurity(context, info, s))
+	info.CephVersion = cephver.Octopus
+	assert.Error(t, validateSecurity(context, info, s))
+}
@@ -22,7 +22,9 @@ import (
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/certificate"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/netpolicy"
 	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/k8sutil"
@@ -62,7 +64,9 @@ func (c *clusterConfig) createDeployment(rgwConfig *rgwConfig) (*apps.Deployment
 	}
 	k8sutil.AddRookVersionLabelToDeployment(d)
 	c.store.Spec.Gateway.Annotations.ApplyToObjectMeta(&d.ObjectMeta)
+	c.store.Spec.Gateway.Labels.ApplyToObjectMeta(&d.ObjectMeta)
 	controller.AddCephVersionLabelToDeployment(c.clusterInfo.CephVersion, d)
+	k8sutil.ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
 
 	return d, nil
 }
@@ -82,12 +86,17 @@ func (c *clusterConfig) makeRGWPodSpec(rgwConfig *rgwConfig) (v1.PodTemplateSpec
 		),
 		HostNetwork:       c.clusterSpec.Network.IsHost(),
 		PriorityClassName: c.store.Spec.Gateway.PriorityClassName,
+		SecurityContext:   cephv1.GetPodSecurityContext(c.clusterSpec.Security.PodSecurityContexts, cephv1.KeyRGW),
 	}
+	controller.ApplyLogCollector(&podSpec, cephconfig.RgwType, GenerateCephXUser(rgwConfig.ResourceName), c.clusterSpec, c.DataPathMap)
+
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec)
 
 	// Set the ssl cert if specified
-	if c.store.Spec.Gateway.SSLCertificateRef != "" {
+	if c.managedCertificate() {
+		podSpec.Volumes = append(podSpec.Volumes, c.managedCertificateVolume())
+	} else if c.store.Spec.Gateway.SSLCertificateRef != "" {
 		// Keep the SSL secret as secure as possible in the container. Give only user read perms.
 		// Because the Secret mount is owned by "root" and fsGroup breaks on OCP since we cannot predict it
 		// Also, we don't want to change the SCC for fsGroup to RunAsAny since it has a major broader impact
@@ -103,6 +112,14 @@ func (c *clusterConfig) makeRGWPodSpec(rgwConfig *rgwConfig) (v1.PodTemplateSpec
 					}}}}
 		podSpec.Volumes = append(podSpec.Volumes, certVol)
 	}
+	// Mount the tokens of the vault backends of the server-side encryption
+	for _, b := range vaultBackends(c.store.Spec.Security) {
+		podSpec.Volumes = append(podSpec.Volumes, b.volume(b.spec))
+	}
+	// Mount the password of the keystone service user
+	if keystone := c.store.Spec.Auth.Keystone; keystone != nil {
+		podSpec.Volumes = append(podSpec.Volumes, keystoneVolume(keystone))
+	}
 
 	// If host networking is not enabled, preferred pod anti-affinity is added to the rgw daemons
 	preferredDuringScheduling := true
@@ -117,6 +134,15 @@ func (c *clusterConfig) makeRGWPodSpec(rgwConfig *rgwConfig) (v1.PodTemplateSpec
 		Spec: podSpec,
 	}
 	c.store.Spec.Gateway.Annotations.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
+	c.store.Spec.Gateway.Labels.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
+	cephconfig.ApplyConnectionsAnnotation(c.clusterSpec.Network, &podTemplateSpec.ObjectMeta)
+	if rgwConfig.CertificateHash != "" {
+		// the pods are restarted with the renewed certificate when its hash changes
+		if podTemplateSpec.Annotations == nil {
+			podTemplateSpec.Annotations = map[string]string{}
+		}
+		podTemplateSpec.Annotations[certificate.HashAnnotation] = rgwConfig.CertificateHash
+	}
 
 	if c.clusterSpec.Network.IsHost() {
 		podTemplateSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
@@ -149,7 +175,7 @@ func (c *clusterConfig) makeDaemonContainer(rgwConfig *rgwConfig) v1.Container {
 		},
 		Args: append(
 			append(
-				controller.DaemonFlags(c.clusterInfo, strings.TrimPrefix(generateCephXUser(rgwConfig.ResourceName), "client.")),
+				controller.DaemonFlags(c.clusterInfo, strings.TrimPrefix(GenerateCephXUser(rgwConfig.ResourceName), "client.")),
 				"--foreground",
 				cephconfig.NewFlag("rgw frontends", fmt.Sprintf("%s %s", rgwFrontendName, c.portString())),
 				cephconfig.NewFlag("host", controller.ContainerEnvVarReference(k8sutil.PodNameEnvVar)),
@@ -171,28 +197,39 @@ func (c *clusterConfig) makeDaemonContainer(rgwConfig *rgwConfig) v1.Container {
 
 	// If the liveness probe is enabled
 	configureLivenessProbe(&container, c.store.Spec.HealthCheck)
-	if c.store.Spec.Gateway.SSLCertificateRef != "" {
+	if c.sslEnabled() {
 		// Add a volume mount for the ssl certificate
 		mount := v1.VolumeMount{Name: certVolumeName, MountPath: certDir, ReadOnly: true}
 		container.VolumeMounts = append(container.VolumeMounts, mount)
 	}
+	for _, b := range vaultBackends(c.store.Spec.Security) {
+		container.Args = append(container.Args, b.flags(b.spec)...)
+		container.VolumeMounts = append(container.VolumeMounts, b.volumeMount())
+	}
+	if keystone := c.store.Spec.Auth.Keystone; keystone != nil {
+		container.Args = append(container.Args, keystoneFlags(keystone)...)
+		container.Env = append(container.Env, keystoneEnvVars(keystone)...)
+		container.VolumeMounts = append(container.VolumeMounts, keystoneVolumeMount())
+	}
+	if c.store.Spec.Auth.STS != nil {
+		container.Args = append(container.Args, stsFlags()...)
+		container.Env = append(container.Env, stsEnvVars(c.store)...)
+	}
+	container.Args = append(container.Args, protocolFlags(c.store.Spec.Protocols)...)
 
 	return container
 }
 
-// configureLivenessProbe returns the desired liveness probe for a given daemon
+// configureLivenessProbe returns the desired liveness probe for a given daemon, and its startup and readiness probes
 func configureLivenessProbe(container *v1.Container, healthCheck cephv1.BucketHealthCheckSpec) {
-	if ok := healthCheck.LivenessProbe; ok != nil {
-		if !healthCheck.LivenessProbe.Disabled {
-			probe := healthCheck.LivenessProbe.Probe
-			// If the spec value is empty, let's use a default
-			if probe != nil {
-				container.LivenessProbe = probe
-			}
-		} else {
-			container.LivenessProbe = nil
-		}
+	defaultLiveness := container.LivenessProbe
+	container.LivenessProbe = cephconfig.ConfigureProbe(container.LivenessProbe, healthCheck.LivenessProbe)
+	liveness := container.LivenessProbe
+	if liveness == nil {
+		liveness = defaultLiveness
 	}
+	container.StartupProbe = cephconfig.ConfigureExtraProbe(container.StartupProbe, liveness, healthCheck.StartupProbe)
+	container.ReadinessProbe = cephconfig.ConfigureExtraProbe(container.ReadinessProbe, liveness, healthCheck.ReadinessProbe)
 }
 
 func (c *clusterConfig) generateLiveProbe() *v1.Probe {
@@ -214,7 +251,7 @@ func (c *clusterConfig) generateLiveProbeScheme() v1.URIScheme {
 
 	// If rgw is configured to use a secured port we need get on https://
 	// Only do this when the Non-SSL port is not used
-	if c.store.Spec.Gateway.Port == 0 && c.store.Spec.Gateway.SecurePort != 0 && c.store.Spec.Gateway.SSLCertificateRef != "" {
+	if c.store.Spec.Gateway.Port == 0 && c.store.Spec.Gateway.SecurePort != 0 && c.sslEnabled() {
 		uriScheme = v1.URISchemeHTTPS
 	}
 
@@ -228,7 +265,7 @@ func (c *clusterConfig) generateLiveProbePort() intstr.IntOrString {
 
 	// If Host Networking is enabled, the port from the spec must be reflected
 	if c.clusterSpec.Network.IsHost() {
-		if c.store.Spec.Gateway.Port == 0 && c.store.Spec.Gateway.SecurePort != 0 && c.store.Spec.Gateway.SSLCertificateRef != "" {
+		if c.store.Spec.Gateway.Port == 0 && c.store.Spec.Gateway.SecurePort != 0 && c.sslEnabled() {
 			port = intstr.FromInt(int(c.store.Spec.Gateway.SecurePort))
 		} else {
 			port = intstr.FromInt(int(c.store.Spec.Gateway.Port))
@@ -250,10 +287,13 @@ func (c *clusterConfig) generateService(cephObjectStore *cephv1.CephObjectStore)
 			Selector: labels,
 		},
 	}
+	cephObjectStore.Spec.Gateway.Annotations.ApplyToObjectMeta(&svc.ObjectMeta)
+	cephObjectStore.Spec.Gateway.Labels.ApplyToObjectMeta(&svc.ObjectMeta)
 
 	if c.clusterSpec.Network.IsHost() {
 		svc.Spec.ClusterIP = v1.ClusterIPNone
 	}
+	cephconfig.ApplyIPFamily(c.clusterSpec.Network, svc)
 
 	destPort := c.generateLiveProbePort()
 
@@ -319,6 +359,9 @@ func (c *clusterConfig) reconcileService(cephObjectStore *cephv1.CephObjectStore
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to create or update object store %q service", cephObjectStore.Name)
 	}
+	if err := netpolicy.ReconcileServicePolicy(c.context, c.clusterSpec, service); err != nil {
+		return "", errors.Wrapf(err, "failed to reconcile the network policy of object store %q service", cephObjectStore.Name)
+	}
 
 	logger.Infof("ceph object store gateway service running at %s:%d", svc.Spec.ClusterIP, cephObjectStore.Spec.Gateway.Port)
 	return svc.Spec.ClusterIP, nil
@@ -23,6 +23,7 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	"github.com/rook/rook/pkg/operator/ceph/certificate"
 	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	cephtest "github.com/rook/rook/pkg/operator/ceph/test"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
@@ -133,10 +134,57 @@ func TestSSLPodSpec(t *testing.T) {
 
 }
 
+func TestManagedCertificatePodSpec(t *testing.T) {
+	store := simpleStore()
+	store.Spec.Gateway.Port = 0
+	store.Spec.Gateway.SecurePort = 443
+	info := clienttest.CreateTestClusterInfo(1)
+	info.CephVersion = cephver.Nautilus
+	c := &clusterConfig{
+		clusterInfo: info,
+		store:       store,
+		rookVersion: "rook/rook:myversion",
+		clusterSpec: &cephv1.ClusterSpec{
+			CephVersion: cephv1.CephVersionSpec{Image: "ceph/ceph:v15"},
+			Security:    cephv1.SecuritySpec{TLS: cephv1.TLSSpec{Issuer: cephv1.TLSIssuerSelfSigned}},
+		},
+		DataPathMap: cephconfig.NewStatelessDaemonDataPathMap(cephconfig.RgwType, "default", "rook-ceph", "/var/lib/rook/"),
+	}
+
+	s, err := c.makeRGWPodSpec(&rgwConfig{ResourceName: fmt.Sprintf("%s-%s", AppName, c.store.Name), CertificateHash: "0123456789abcdef"})
+	assert.NoError(t, err)
+
+	// the certificate secret of the store is mounted and the pods restart when the certificate is renewed
+	found := false
+	for _, volume := range s.Spec.Volumes {
+		if volume.Name == certVolumeName {
+			found = true
+			assert.Equal(t, "rook-ceph-rgw-default-tls", volume.Secret.SecretName)
+			assert.Equal(t, 2, len(volume.Secret.Items))
+		}
+	}
+	assert.True(t, found)
+	assert.Equal(t, "0123456789abcdef", s.Annotations[certificate.HashAnnotation])
+	assert.Equal(t, v1.URISchemeHTTPS, s.Spec.Containers[0].LivenessProbe.Handler.HTTPGet.Scheme)
+
+	// external clusters do not manage the certificates of the gateways
+	c.clusterSpec.External.Enable = true
+	assert.False(t, c.managedCertificate())
+}
+
 func TestValidateSpec(t *testing.T) {
-	context := &clusterd.Context{Executor: &exectest.MockExecutor{}}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "crush" && args[2] == "dump" {
+				return clienttest.CrushDumpResponse(3), nil
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
 	r := &ReconcileCephObjectStore{
-		context: context,
+		context:     context,
+		clusterInfo: clienttest.CreateTestClusterInfo(1),
 		cephClusterSpec: &cephv1.ClusterSpec{
 			External: cephv1.ExternalSpec{
 				Enable: false,
@@ -173,6 +221,17 @@ func TestValidateSpec(t *testing.T) {
 	err = r.validateStore(s)
 	assert.Nil(t, err)
 
+	// the gateway ports on the host network must not conflict with the ports of the other daemons
+	r.cephClusterSpec.Network.Provider = "host"
+	s.Spec.Gateway.Port = 6800
+	assert.Error(t, r.validateStore(s))
+	r.cephClusterSpec.Network.HostPorts = &cephv1.HostPortsSpec{MsgrPortRange: &cephv1.PortRangeSpec{Min: 7000, Max: 7500}}
+	assert.NoError(t, r.validateStore(s))
+	s.Spec.Gateway.SecurePort = 3300
+	assert.Error(t, r.validateStore(s))
+	s.Spec.Gateway.SecurePort = 0
+	r.cephClusterSpec.Network = cephv1.NetworkSpec{}
+
 	// external with no endpoints, failure
 	r.cephClusterSpec.External.Enable = true
 	err = r.validateStore(s)
@@ -58,8 +58,9 @@ func updateStatus(client client.Client, namespacedName types.NamespacedName, sta
 	logger.Debugf("object store %q status updated to %q", namespacedName, status)
 }
 
-// updateStatusBucket updates an object with a given status
-func updateStatusBucket(client client.Client, name types.NamespacedName, phase cephv1.ConditionType, details string) {
+// updateStatusBucket updates an object with a given status, the latency of the probe of the endpoint and the result of
+// the synthetic transaction of the data path check when it ran
+func updateStatusBucket(client client.Client, name types.NamespacedName, phase cephv1.ConditionType, details, endpointLatency string, dataPath *cephv1.BucketDataPathStatus) {
 	objectStore := &cephv1.CephObjectStore{}
 	if err := client.Get(context.TODO(), name, objectStore); err != nil {
 		if kerrors.IsNotFound(err) {
@@ -70,7 +71,7 @@ func updateStatusBucket(client client.Client, name types.NamespacedName, phase c
 		return
 	}
 
-	objectStore.Status.BucketStatus = toCustomResourceStatus(objectStore.Status.BucketStatus, details, phase)
+	objectStore.Status.BucketStatus = toCustomResourceStatus(objectStore.Status.BucketStatus, details, phase, endpointLatency, dataPath)
 	objectStore.Status.Phase = phase
 	if err := opcontroller.UpdateStatus(client, objectStore); err != nil {
 		logger.Errorf("failed to set object store %q status to %v. %v", name, phase, err)
@@ -0,0 +1,389 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// stsKeyKey is the key of the sts key in its secret
+	stsKeyKey = "key"
+	// stsKeyLength is the length of the sts key expected by the rgw
+	stsKeyLength = 16
+	// the env var of the sts key expanded in the flags of the rgw, the rgw has no option to read it from a file
+	stsKeyEnvVar = "ROOK_STS_KEY"
+
+	// stsAdminUserName is the prefix of the user registering the oidc provider of a store
+	stsAdminUserName = "rook-ceph-internal-sts-admin"
+	stsAdminCaps     = "oidc-provider=*"
+)
+
+// stsKeySecretName returns the name of the secret of the sts key of a store, the secret generated by the operator
+// unless the spec names one
+func stsKeySecretName(store *cephv1.CephObjectStore) string {
+	if name := store.Spec.Auth.STS.KeySecretName; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s-sts-key", AppName, store.Name)
+}
+
+// stsFlags returns the rgw options enabling the sts
+func stsFlags() []string {
+	return []string{
+		cephconfig.NewFlag("rgw s3 auth use sts", "true"),
+		cephconfig.NewFlag("rgw sts key", controller.ContainerEnvVarReference(stsKeyEnvVar)),
+	}
+}
+
+// stsEnvVars returns the env var of the sts key expanded in the flags
+func stsEnvVars(store *cephv1.CephObjectStore) []v1.EnvVar {
+	return []v1.EnvVar{{Name: stsKeyEnvVar, ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+		LocalObjectReference: v1.LocalObjectReference{Name: stsKeySecretName(store)},
+		Key:                  stsKeyKey,
+	}}}}
+}
+
+// reconcileSTSKey generates the secret of the sts key of the store, unless the spec names a secret or the key was
+// already generated. The secret is owned by the store.
+func (c *clusterConfig) reconcileSTSKey() error {
+	if c.store.Spec.Auth.STS == nil || c.store.Spec.Auth.STS.KeySecretName != "" {
+		return nil
+	}
+	name := stsKeySecretName(c.store)
+	secrets := c.context.Clientset.CoreV1().Secrets(c.store.Namespace)
+	_, err := secrets.Get(name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !kerrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get sts key secret %q", name)
+	}
+
+	key, err := generateSTSKey()
+	if err != nil {
+		return err
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.store.Namespace,
+		},
+		Data: map[string][]byte{stsKeyKey: []byte(key)},
+		Type: k8sutil.RookType,
+	}
+	k8sutil.SetOwnerRef(&secret.ObjectMeta, c.ownerRef)
+	if _, err := secrets.Create(secret); err != nil {
+		return errors.Wrapf(err, "failed to create sts key secret %q", name)
+	}
+	logger.Infof("generated the sts key of object store %q in secret %q", c.store.Name, name)
+	return nil
+}
+
+// generateSTSKey returns a random alphanumeric sts key
+func generateSTSKey() (string, error) {
+	const keyChars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	key := make([]byte, stsKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.Wrap(err, "failed to generate the sts key")
+	}
+	for i, b := range key {
+		key[i] = keyChars[b%byte(len(keyChars))]
+	}
+	return string(key), nil
+}
+
+// validateSTS checks the sts settings of a store and that the secret of the sts key holds a key of the right length
+func validateSTS(context *clusterd.Context, s *cephv1.CephObjectStore) error {
+	sts := s.Spec.Auth.STS
+	if sts == nil {
+		return nil
+	}
+	roles := map[string]bool{}
+	for _, role := range sts.Roles {
+		if role.Name == "" {
+			return errors.New("missing sts role name")
+		}
+		if roles[role.Name] {
+			return errors.Errorf("duplicate sts role %q", role.Name)
+		}
+		roles[role.Name] = true
+		if role.Path != "" && (!strings.HasPrefix(role.Path, "/") || !strings.HasSuffix(role.Path, "/")) {
+			return errors.Errorf("invalid path %q of sts role %q, must start and end with /", role.Path, role.Name)
+		}
+		if !json.Valid([]byte(role.AssumeRolePolicy)) {
+			return errors.Errorf("invalid assumeRolePolicy of sts role %q, must be a JSON document", role.Name)
+		}
+		for name, policy := range role.Policies {
+			if !json.Valid([]byte(policy)) {
+				return errors.Errorf("invalid policy %q of sts role %q, must be a JSON document", name, role.Name)
+			}
+		}
+	}
+	if provider := sts.OIDCProvider; provider != nil {
+		if u, err := url.Parse(provider.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.Errorf("invalid oidc provider url %q, must be an https url", provider.URL)
+		}
+		if len(provider.ClientIDs) == 0 {
+			return errors.New("missing oidc provider clientIDs")
+		}
+		if len(provider.Thumbprints) == 0 {
+			return errors.New("missing oidc provider thumbprints")
+		}
+		if s.Spec.Gateway.Port == 0 {
+			return errors.New("the oidc provider is registered through the gateway port, which is not set")
+		}
+	}
+	if sts.KeySecretName == "" {
+		return nil
+	}
+	secret, err := context.Clientset.CoreV1().Secrets(s.Namespace).Get(sts.KeySecretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get sts key secret %q", sts.KeySecretName)
+	}
+	key := string(secret.Data[stsKeyKey])
+	if key == "" {
+		key = secret.StringData[stsKeyKey]
+	}
+	if len(key) != stsKeyLength {
+		return errors.Errorf("sts key secret %q must have a %q key of %d characters", sts.KeySecretName, stsKeyKey, stsKeyLength)
+	}
+	return nil
+}
+
+// stsRole is a role of the rgw reported by the role commands, in the snake case of octopus or in the camel case of
+// the later releases
+type stsRole struct {
+	Name                  string `json:"name"`
+	AssumeRolePolicy      string `json:"assume_role_policy_document"`
+	RoleName              string `json:"RoleName"`
+	AssumeRolePolicyCamel string `json:"AssumeRolePolicyDocument"`
+}
+
+func (r stsRole) name() string {
+	if r.RoleName != "" {
+		return r.RoleName
+	}
+	return r.Name
+}
+
+func (r stsRole) assumeRolePolicy() string {
+	if r.AssumeRolePolicyCamel != "" {
+		return r.AssumeRolePolicyCamel
+	}
+	return r.AssumeRolePolicy
+}
+
+// reconcileSTSRoles creates the roles of the spec and updates their trust and permission policies. The roles removed
+// from the spec are not deleted since the sessions of the users may still refer to them.
+func reconcileSTSRoles(context *Context, sts *cephv1.STSSpec) error {
+	if len(sts.Roles) == 0 {
+		return nil
+	}
+	output, err := runAdminCommand(context, "role", "list")
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the roles of object store %q", context.Name)
+	}
+	var roles []stsRole
+	if err := json.Unmarshal([]byte(output), &roles); err != nil {
+		return errors.Wrap(err, "failed to parse the roles")
+	}
+	existing := map[string]stsRole{}
+	for _, role := range roles {
+		existing[role.name()] = role
+	}
+
+	for _, role := range sts.Roles {
+		roleArg := fmt.Sprintf("--role-name=%s", role.Name)
+		policyArg := fmt.Sprintf("--assume-role-policy-doc=%s", role.AssumeRolePolicy)
+		if current, ok := existing[role.Name]; !ok {
+			args := []string{"role", "create", roleArg, policyArg}
+			if role.Path != "" {
+				args = append(args, fmt.Sprintf("--path=%s", role.Path))
+			}
+			if output, err := runAdminCommand(context, args...); err != nil {
+				return errors.Wrapf(err, "failed to create sts role %q, for reason %q", role.Name, output)
+			}
+			logger.Infof("created sts role %q of object store %q", role.Name, context.Name)
+		} else if !sameJSON(current.assumeRolePolicy(), role.AssumeRolePolicy) {
+			if output, err := runAdminCommand(context, "role", "modify", roleArg, policyArg); err != nil {
+				return errors.Wrapf(err, "failed to update the assume role policy of sts role %q, for reason %q", role.Name, output)
+			}
+			logger.Infof("updated the assume role policy of sts role %q of object store %q", role.Name, context.Name)
+		}
+
+		if err := reconcileSTSRolePolicies(context, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileSTSRolePolicies puts the permission policies of a role and deletes the policies not in the spec
+func reconcileSTSRolePolicies(context *Context, role cephv1.STSRoleSpec) error {
+	roleArg := fmt.Sprintf("--role-name=%s", role.Name)
+	output, err := runAdminCommand(context, "role-policy", "list", roleArg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the policies of sts role %q", role.Name)
+	}
+	var current []string
+	if err := json.Unmarshal([]byte(output), &current); err != nil {
+		return errors.Wrapf(err, "failed to parse the policies of sts role %q", role.Name)
+	}
+
+	names := make([]string, 0, len(role.Policies))
+	for name := range role.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		output, err := runAdminCommand(context, "role-policy", "put", roleArg,
+			fmt.Sprintf("--policy-name=%s", name),
+			fmt.Sprintf("--policy-doc=%s", role.Policies[name]))
+		if err != nil {
+			return errors.Wrapf(err, "failed to put policy %q of sts role %q, for reason %q", name, role.Name, output)
+		}
+	}
+	for _, name := range current {
+		if _, ok := role.Policies[name]; ok {
+			continue
+		}
+		if output, err := runAdminCommand(context, "role-policy", "delete", roleArg, fmt.Sprintf("--policy-name=%s", name)); err != nil {
+			return errors.Wrapf(err, "failed to delete policy %q of sts role %q, for reason %q", name, role.Name, output)
+		}
+		logger.Infof("deleted policy %q of sts role %q of object store %q", name, role.Name, context.Name)
+	}
+	return nil
+}
+
+// sameJSON returns whether two JSON documents have the same content
+func sameJSON(a, b string) bool {
+	var x, y interface{}
+	if json.Unmarshal([]byte(a), &x) != nil || json.Unmarshal([]byte(b), &y) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// newSTSAdminClient returns an iam client of the store with the keys of the user registering the oidc provider, the
+// user is created with the capabilities of the oidc providers
+func newSTSAdminClient(context *Context, endpoint string) (iamiface.IAMAPI, error) {
+	userID := fmt.Sprintf("%s-%s", stsAdminUserName, context.UID)
+	user, rgwerr, err := CreateUser(context, ObjectUser{UserID: userID, DisplayName: &userID})
+	if err != nil {
+		if rgwerr != ErrorCodeFileExists {
+			return nil, errors.Wrapf(err, "failed to create sts admin user %q. error code %d", userID, rgwerr)
+		}
+		if user, _, err = GetUser(context, userID); err != nil {
+			return nil, errors.Wrapf(err, "failed to get sts admin user %q", userID)
+		}
+	}
+	if output, err := runAdminCommand(context, "caps", "add", fmt.Sprintf("--uid=%s", userID), fmt.Sprintf("--caps=%s", stsAdminCaps)); err != nil {
+		return nil, errors.Wrapf(err, "failed to add the caps of sts admin user %q, for reason %q", userID, output)
+	}
+
+	const cephRegion = "us-east-1"
+	sess, err := session.NewSession(
+		aws.NewConfig().
+			WithRegion(cephRegion).
+			WithCredentials(credentials.NewStaticCredentials(*user.AccessKey, *user.SecretKey, "")).
+			WithEndpoint(endpoint).
+			WithDisableSSL(true).
+			WithHTTPClient(&http.Client{
+				Timeout: time.Second * 15,
+			}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the iam session")
+	}
+	return iam.New(sess), nil
+}
+
+// reconcileOIDCProvider registers the oidc provider of the spec in the rgw. The provider is registered again when its
+// client IDs or thumbprints change, since the rgw cannot update them.
+func reconcileOIDCProvider(client iamiface.IAMAPI, provider *cephv1.OIDCProviderSpec) error {
+	u, err := url.Parse(provider.URL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid oidc provider url %q", provider.URL)
+	}
+	arnSuffix := "oidc-provider/" + u.Host + strings.TrimSuffix(u.Path, "/")
+
+	providers, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list the oidc providers")
+	}
+	for _, p := range providers.OpenIDConnectProviderList {
+		if !strings.HasSuffix(aws.StringValue(p.Arn), arnSuffix) {
+			continue
+		}
+		current, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{OpenIDConnectProviderArn: p.Arn})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get oidc provider %q", aws.StringValue(p.Arn))
+		}
+		if sameStrings(aws.StringValueSlice(current.ClientIDList), provider.ClientIDs) &&
+			sameStrings(aws.StringValueSlice(current.ThumbprintList), provider.Thumbprints) {
+			return nil
+		}
+		if _, err := client.DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{OpenIDConnectProviderArn: p.Arn}); err != nil {
+			return errors.Wrapf(err, "failed to delete oidc provider %q", aws.StringValue(p.Arn))
+		}
+		logger.Infof("deleted oidc provider %q to register its new settings", aws.StringValue(p.Arn))
+	}
+
+	_, err = client.CreateOpenIDConnectProvider(&iam.CreateOpenIDConnectProviderInput{
+		Url:            aws.String(provider.URL),
+		ClientIDList:   aws.StringSlice(provider.ClientIDs),
+		ThumbprintList: aws.StringSlice(provider.Thumbprints),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to register oidc provider %q", provider.URL)
+	}
+	logger.Infof("registered oidc provider %q", provider.URL)
+	return nil
+}
+
+// sameStrings returns whether two lists have the same strings in any order
+func sameStrings(a, b []string) bool {
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return reflect.DeepEqual(x, y)
+}
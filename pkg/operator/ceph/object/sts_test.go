@@ -0,0 +1,263 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	cephconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func stsSpec() *cephv1.STSSpec {
+	return &cephv1.STSSpec{
+		Roles: []cephv1.STSRoleSpec{{
+			Name:             "reader",
+			AssumeRolePolicy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam:::user/app"]},"Action":["sts:AssumeRole"]}]}`,
+			Policies:         map[string]string{"read": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":"*"}]}`},
+		}},
+	}
+}
+
+func TestSTSPodSpec(t *testing.T) {
+	store := simpleStore()
+	store.Spec.Auth.STS = stsSpec()
+	c := &clusterConfig{
+		clusterInfo: clienttest.CreateTestClusterInfo(1),
+		store:       store,
+		clusterSpec: &cephv1.ClusterSpec{CephVersion: cephv1.CephVersionSpec{Image: "ceph/ceph:v15"}},
+		DataPathMap: cephconfig.NewStatelessDaemonDataPathMap(cephconfig.RgwType, "default", "rook-ceph", "/var/lib/rook/"),
+	}
+	c.clusterInfo.CephVersion = cephver.Octopus
+
+	s, err := c.makeRGWPodSpec(&rgwConfig{ResourceName: "rook-ceph-rgw-default"})
+	assert.NoError(t, err)
+	container := s.Spec.Containers[0]
+	assert.Contains(t, container.Args, "--rgw-s3-auth-use-sts=true")
+	assert.Contains(t, container.Args, "--rgw-sts-key=$(ROOK_STS_KEY)")
+	secrets := map[string]string{}
+	for _, env := range container.Env {
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			secrets[env.Name] = env.ValueFrom.SecretKeyRef.Name
+		}
+	}
+	assert.Equal(t, "rook-ceph-rgw-default-sts-key", secrets[stsKeyEnvVar])
+
+	// the key of the secret of the spec
+	store.Spec.Auth.STS.KeySecretName = "my-sts-key"
+	s, err = c.makeRGWPodSpec(&rgwConfig{ResourceName: "rook-ceph-rgw-default"})
+	assert.NoError(t, err)
+	for _, env := range s.Spec.Containers[0].Env {
+		if env.Name == stsKeyEnvVar {
+			assert.Equal(t, "my-sts-key", env.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+}
+
+func TestReconcileSTSKey(t *testing.T) {
+	clientset := testop.New(t, 1)
+	store := simpleStore()
+	store.Spec.Auth.STS = stsSpec()
+	c := &clusterConfig{context: &clusterd.Context{Clientset: clientset}, store: store, ownerRef: &metav1.OwnerReference{}}
+
+	assert.NoError(t, c.reconcileSTSKey())
+	secret, err := clientset.CoreV1().Secrets(store.Namespace).Get("rook-ceph-rgw-default-sts-key", metav1.GetOptions{})
+	assert.NoError(t, err)
+	key := string(secret.Data[stsKeyKey])
+	assert.Len(t, key, stsKeyLength)
+
+	// the key is kept
+	assert.NoError(t, c.reconcileSTSKey())
+	secret, err = clientset.CoreV1().Secrets(store.Namespace).Get("rook-ceph-rgw-default-sts-key", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, key, string(secret.Data[stsKeyKey]))
+}
+
+func TestValidateSTS(t *testing.T) {
+	clientset := testop.New(t, 1)
+	context := &clusterd.Context{Clientset: clientset}
+	s := simpleStore()
+
+	// no sts settings
+	assert.NoError(t, validateSTS(context, s))
+	s.Spec.Auth.STS = stsSpec()
+	assert.NoError(t, validateSTS(context, s))
+
+	// the policies are JSON documents
+	s.Spec.Auth.STS.Roles[0].AssumeRolePolicy = "allow all"
+	assert.Error(t, validateSTS(context, s))
+	s.Spec.Auth.STS = stsSpec()
+	s.Spec.Auth.STS.Roles[0].Policies["read"] = "{"
+	assert.Error(t, validateSTS(context, s))
+	s.Spec.Auth.STS = stsSpec()
+	s.Spec.Auth.STS.Roles[0].Path = "apps"
+	assert.Error(t, validateSTS(context, s))
+	s.Spec.Auth.STS = stsSpec()
+	s.Spec.Auth.STS.Roles = append(s.Spec.Auth.STS.Roles, s.Spec.Auth.STS.Roles[0])
+	assert.Error(t, validateSTS(context, s))
+
+	// the oidc provider needs an https url, the client IDs and the thumbprints
+	s.Spec.Auth.STS = stsSpec()
+	s.Spec.Auth.STS.OIDCProvider = &cephv1.OIDCProviderSpec{URL: "https://keycloak.example.com/auth/realms/demo", ClientIDs: []string{"app"}, Thumbprints: []string{"ABCDEF"}}
+	assert.NoError(t, validateSTS(context, s))
+	s.Spec.Auth.STS.OIDCProvider.URL = "keycloak.example.com"
+	assert.Error(t, validateSTS(context, s))
+	s.Spec.Auth.STS.OIDCProvider.URL = "https://keycloak.example.com/auth/realms/demo"
+	s.Spec.Auth.STS.OIDCProvider.Thumbprints = nil
+	assert.Error(t, validateSTS(context, s))
+	s.Spec.Auth.STS.OIDCProvider.Thumbprints = []string{"ABCDEF"}
+	s.Spec.Gateway.Port = 0
+	assert.Error(t, validateSTS(context, s))
+
+	// the key of the secret of the spec has 16 characters
+	s = simpleStore()
+	s.Spec.Auth.STS = &cephv1.STSSpec{KeySecretName: "my-sts-key"}
+	assert.Error(t, validateSTS(context, s))
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-sts-key", Namespace: s.Namespace},
+		Data:       map[string][]byte{stsKeyKey: []byte("short")},
+	}
+	_, err := clientset.CoreV1().Secrets(s.Namespace).Create(secret)
+	assert.NoError(t, err)
+	assert.Error(t, validateSTS(context, s))
+	secret.Data[stsKeyKey] = []byte("abcdefghijklmnop")
+	_, err = clientset.CoreV1().Secrets(s.Namespace).Update(secret)
+	assert.NoError(t, err)
+	assert.NoError(t, validateSTS(context, s))
+}
+
+func TestReconcileSTSRoles(t *testing.T) {
+	roles := `[]`
+	policies := `["write"]`
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(_ string, args ...string) (string, error) {
+			switch {
+			case args[0] == "role" && args[1] == "list":
+				return roles, nil
+			case args[0] == "role-policy" && args[1] == "list":
+				return policies, nil
+			}
+			// the command without the multisite and connection args
+			command := []string{}
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "--rgw-realm") {
+					break
+				}
+				command = append(command, arg)
+			}
+			commands = append(commands, strings.Join(command, " "))
+			return "", nil
+		},
+	}
+	context := &Context{
+		Context:     &clusterd.Context{Executor: executor},
+		Name:        "my-store",
+		clusterInfo: client.AdminClusterInfo("ns"),
+	}
+	sts := stsSpec()
+	trust := sts.Roles[0].AssumeRolePolicy
+	policy := sts.Roles[0].Policies["read"]
+
+	// the role is created with its policy, the policy not in the spec is deleted
+	assert.NoError(t, reconcileSTSRoles(context, sts))
+	assert.Equal(t, []string{
+		"role create --role-name=reader --assume-role-policy-doc=" + trust,
+		"role-policy put --role-name=reader --policy-name=read --policy-doc=" + policy,
+		"role-policy delete --role-name=reader --policy-name=write",
+	}, commands)
+
+	// the trust policy of the existing role is not updated when it has the same content
+	roles = `[{"RoleName":"reader","Path":"/","AssumeRolePolicyDocument":"{\"Statement\":[{\"Action\":[\"sts:AssumeRole\"],\"Effect\":\"Allow\",\"Principal\":{\"AWS\":[\"arn:aws:iam:::user/app\"]}}],\"Version\":\"2012-10-17\"}"}]`
+	policies = `["read"]`
+	commands = []string{}
+	assert.NoError(t, reconcileSTSRoles(context, sts))
+	assert.Equal(t, []string{"role-policy put --role-name=reader --policy-name=read --policy-doc=" + policy}, commands)
+
+	// the trust policy is updated when it changes
+	roles = `[{"name":"reader","path":"/","assume_role_policy_document":"{}"}]`
+	commands = []string{}
+	assert.NoError(t, reconcileSTSRoles(context, sts))
+	assert.Equal(t, "role modify --role-name=reader --assume-role-policy-doc="+trust, commands[0])
+}
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	providers map[string]*iam.GetOpenIDConnectProviderOutput
+	created   []string
+	deleted   []string
+}
+
+func (f *fakeIAM) ListOpenIDConnectProviders(*iam.ListOpenIDConnectProvidersInput) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	output := &iam.ListOpenIDConnectProvidersOutput{}
+	for arn := range f.providers {
+		output.OpenIDConnectProviderList = append(output.OpenIDConnectProviderList, &iam.OpenIDConnectProviderListEntry{Arn: aws.String(arn)})
+	}
+	return output, nil
+}
+
+func (f *fakeIAM) GetOpenIDConnectProvider(input *iam.GetOpenIDConnectProviderInput) (*iam.GetOpenIDConnectProviderOutput, error) {
+	return f.providers[*input.OpenIDConnectProviderArn], nil
+}
+
+func (f *fakeIAM) DeleteOpenIDConnectProvider(input *iam.DeleteOpenIDConnectProviderInput) (*iam.DeleteOpenIDConnectProviderOutput, error) {
+	f.deleted = append(f.deleted, *input.OpenIDConnectProviderArn)
+	return &iam.DeleteOpenIDConnectProviderOutput{}, nil
+}
+
+func (f *fakeIAM) CreateOpenIDConnectProvider(input *iam.CreateOpenIDConnectProviderInput) (*iam.CreateOpenIDConnectProviderOutput, error) {
+	f.created = append(f.created, *input.Url)
+	return &iam.CreateOpenIDConnectProviderOutput{}, nil
+}
+
+func TestReconcileOIDCProvider(t *testing.T) {
+	provider := &cephv1.OIDCProviderSpec{URL: "https://keycloak.example.com/auth/realms/demo", ClientIDs: []string{"app", "cli"}, Thumbprints: []string{"ABCDEF"}}
+	arn := "arn:aws:iam:::oidc-provider/keycloak.example.com/auth/realms/demo"
+
+	// the provider is registered
+	client := &fakeIAM{providers: map[string]*iam.GetOpenIDConnectProviderOutput{}}
+	assert.NoError(t, reconcileOIDCProvider(client, provider))
+	assert.Equal(t, []string{provider.URL}, client.created)
+
+	// the provider with the same settings is kept
+	client = &fakeIAM{providers: map[string]*iam.GetOpenIDConnectProviderOutput{arn: {
+		ClientIDList:   aws.StringSlice([]string{"cli", "app"}),
+		ThumbprintList: aws.StringSlice([]string{"ABCDEF"}),
+	}}}
+	assert.NoError(t, reconcileOIDCProvider(client, provider))
+	assert.Empty(t, client.created)
+	assert.Empty(t, client.deleted)
+
+	// the provider is registered again when its settings change
+	provider.Thumbprints = []string{"123456"}
+	assert.NoError(t, reconcileOIDCProvider(client, provider))
+	assert.Equal(t, []string{arn}, client.deleted)
+	assert.Equal(t, []string{provider.URL}, client.created)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	defaultUsageMetricsInterval = 5 * time.Minute
+	usageOutputPrometheus       = "prometheus"
+	usageOutputConfigMap        = "configmap"
+	bucketUsageKey              = "buckets.json"
+)
+
+var (
+	bucketSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_object_bucket_size_bytes",
+		Help: "Size of the objects of the buckets of the object stores, by namespace, object store, bucket and owner",
+	}, []string{"namespace", "object_store", "bucket", "owner"})
+
+	bucketObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_object_bucket_objects",
+		Help: "Number of objects of the buckets of the object stores, by namespace, object store, bucket and owner",
+	}, []string{"namespace", "object_store", "bucket", "owner"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(bucketSize, bucketObjects)
+}
+
+// bucketUsage is the usage of a bucket collected for the metrics and the config map
+type bucketUsage struct {
+	Name            string `json:"name"`
+	Owner           string `json:"owner"`
+	Size            uint64 `json:"size"`
+	NumberOfObjects uint64 `json:"numberOfObjects"`
+}
+
+// bucketUsageReport is the content of the config map of the usage of a store
+type bucketUsageReport struct {
+	LastUpdated string        `json:"lastUpdated"`
+	Buckets     []bucketUsage `json:"buckets"`
+}
+
+// validateUsageMetrics checks the interval, the output and the bucket patterns of the usage collection
+func validateUsageMetrics(spec *cephv1.BucketUsageMetricsSpec) error {
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+	if spec.Interval != "" {
+		if _, err := time.ParseDuration(spec.Interval); err != nil {
+			return errors.Wrapf(err, "invalid interval %q", spec.Interval)
+		}
+	}
+	if spec.Output != "" && spec.Output != usageOutputPrometheus && spec.Output != usageOutputConfigMap {
+		return errors.Errorf("invalid output %q, must be %q or %q", spec.Output, usageOutputPrometheus, usageOutputConfigMap)
+	}
+	for _, pattern := range append(append([]string{}, spec.IncludeBuckets...), spec.ExcludeBuckets...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid bucket pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// usageMetricsEnabled returns whether the usage of the buckets of the store is collected
+func usageMetricsEnabled(spec *cephv1.BucketUsageMetricsSpec) bool {
+	return spec != nil && spec.Enabled
+}
+
+// collectedBucket returns whether the bucket matches an included pattern, or all the buckets when there is none, and
+// no excluded pattern
+func collectedBucket(spec cephv1.BucketUsageMetricsSpec, bucket string) bool {
+	for _, pattern := range spec.ExcludeBuckets {
+		if match, _ := path.Match(pattern, bucket); match {
+			return false
+		}
+	}
+	if len(spec.IncludeBuckets) == 0 {
+		return true
+	}
+	for _, pattern := range spec.IncludeBuckets {
+		if match, _ := path.Match(pattern, bucket); match {
+			return true
+		}
+	}
+	return false
+}
+
+// getBucketsUsage returns the usage of all the buckets of the store, sorted by name
+func getBucketsUsage(c *Context) ([]bucketUsage, error) {
+	result, err := runAdminCommand(c, "bucket", "stats")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the stats of the buckets")
+	}
+	var rgwStats []rgwBucketStats
+	if err := json.Unmarshal([]byte(result), &rgwStats); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the stats of the buckets. %s", result)
+	}
+
+	usage := []bucketUsage{}
+	for _, rgwStat := range rgwStats {
+		stats := bucketStatsFromRGW(rgwStat)
+		usage = append(usage, bucketUsage{Name: rgwStat.Bucket, Owner: rgwStat.Owner, Size: stats.Size, NumberOfObjects: stats.NumberOfObjects})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Name < usage[j].Name })
+	return usage, nil
+}
+
+// usageCollector periodically collects the usage of the buckets of a store. The collection of the operator with the
+// admin keyring lets the users build the chargeback of the buckets without admin access to the rgw.
+type usageCollector struct {
+	objContext *Context
+	namespace  string
+	spec       cephv1.BucketUsageMetricsSpec
+	interval   time.Duration
+	ownerRef   metav1.OwnerReference
+	stopChan   chan struct{}
+	// the owners of the buckets with metrics, to remove the metrics of the deleted buckets
+	reported map[string]string
+}
+
+func newUsageCollector(objContext *Context, namespace string, spec cephv1.BucketUsageMetricsSpec, ownerRef metav1.OwnerReference) *usageCollector {
+	c := &usageCollector{
+		objContext: objContext,
+		namespace:  namespace,
+		spec:       spec,
+		interval:   defaultUsageMetricsInterval,
+		ownerRef:   ownerRef,
+		stopChan:   make(chan struct{}),
+		reported:   map[string]string{},
+	}
+	if spec.Interval != "" {
+		if duration, err := time.ParseDuration(spec.Interval); err == nil {
+			c.interval = duration
+		}
+	}
+	return c
+}
+
+func (c *usageCollector) output() string {
+	if c.spec.Output == "" {
+		return usageOutputPrometheus
+	}
+	return c.spec.Output
+}
+
+func (c *usageCollector) configMapName() string {
+	return fmt.Sprintf("%s-bucket-usage", instanceName(c.objContext.Name))
+}
+
+// run collects the usage immediately and then at each interval until the collector is stopped. The metrics of the
+// store are removed when it stops.
+func (c *usageCollector) run() {
+	logger.Infof("collecting the bucket usage of object store %q every %s", c.objContext.Name, c.interval.String())
+	for {
+		if err := c.collect(); err != nil {
+			logger.Errorf("failed to collect the bucket usage of object store %q. %v", c.objContext.Name, err)
+		}
+		select {
+		case <-c.stopChan:
+			c.clearMetrics()
+			logger.Infof("stopped collecting the bucket usage of object store %q", c.objContext.Name)
+			return
+		case <-time.After(c.interval):
+		}
+	}
+}
+
+func (c *usageCollector) stop() {
+	close(c.stopChan)
+}
+
+// collect exports the usage of the collected buckets
+func (c *usageCollector) collect() error {
+	usage, err := getBucketsUsage(c.objContext)
+	if err != nil {
+		return err
+	}
+	collected := []bucketUsage{}
+	for _, bucket := range usage {
+		if collectedBucket(c.spec, bucket.Name) {
+			collected = append(collected, bucket)
+		}
+	}
+
+	if c.output() == usageOutputConfigMap {
+		return c.writeConfigMap(collected)
+	}
+	c.exportMetrics(collected)
+	return nil
+}
+
+// exportMetrics sets the metrics of the buckets and removes the metrics of the buckets not collected anymore
+func (c *usageCollector) exportMetrics(usage []bucketUsage) {
+	reported := map[string]string{}
+	for _, bucket := range usage {
+		if owner, ok := c.reported[bucket.Name]; ok && owner != bucket.Owner {
+			c.deleteMetrics(bucket.Name, owner)
+		}
+		bucketSize.WithLabelValues(c.namespace, c.objContext.Name, bucket.Name, bucket.Owner).Set(float64(bucket.Size))
+		bucketObjects.WithLabelValues(c.namespace, c.objContext.Name, bucket.Name, bucket.Owner).Set(float64(bucket.NumberOfObjects))
+		reported[bucket.Name] = bucket.Owner
+	}
+	for bucket, owner := range c.reported {
+		if _, ok := reported[bucket]; !ok {
+			c.deleteMetrics(bucket, owner)
+		}
+	}
+	c.reported = reported
+}
+
+func (c *usageCollector) deleteMetrics(bucket, owner string) {
+	bucketSize.DeleteLabelValues(c.namespace, c.objContext.Name, bucket, owner)
+	bucketObjects.DeleteLabelValues(c.namespace, c.objContext.Name, bucket, owner)
+}
+
+func (c *usageCollector) clearMetrics() {
+	for bucket, owner := range c.reported {
+		c.deleteMetrics(bucket, owner)
+	}
+	c.reported = map[string]string{}
+}
+
+// writeConfigMap writes the usage of the buckets in the config map of the store, owned by the store
+func (c *usageCollector) writeConfigMap(usage []bucketUsage) error {
+	report := bucketUsageReport{LastUpdated: time.Now().UTC().Format(time.RFC3339), Buckets: usage}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the bucket usage")
+	}
+	k := k8sutil.NewConfigMapKVStore(c.namespace, c.objContext.Context.Clientset, c.ownerRef)
+	if err := k.SetValue(c.configMapName(), bucketUsageKey, string(data)); err != nil {
+		return errors.Wrapf(err, "failed to write the bucket usage in config map %q", c.configMapName())
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateUsageMetrics(t *testing.T) {
+	assert.NoError(t, validateUsageMetrics(nil))
+	spec := &cephv1.BucketUsageMetricsSpec{Enabled: true, Interval: "10m", Output: "configmap", IncludeBuckets: []string{"team-a-*"}}
+	assert.NoError(t, validateUsageMetrics(spec))
+
+	spec.Output = "csv"
+	assert.Error(t, validateUsageMetrics(spec))
+	spec.Output = ""
+	spec.Interval = "often"
+	assert.Error(t, validateUsageMetrics(spec))
+	spec.Interval = ""
+	spec.ExcludeBuckets = []string{"team-[a"}
+	assert.Error(t, validateUsageMetrics(spec))
+
+	// the settings of a disabled collection are not validated
+	spec.Enabled = false
+	assert.NoError(t, validateUsageMetrics(spec))
+}
+
+func TestCollectedBucket(t *testing.T) {
+	spec := cephv1.BucketUsageMetricsSpec{}
+	assert.True(t, collectedBucket(spec, "logs"))
+
+	spec.IncludeBuckets = []string{"team-a-*", "team-b-*"}
+	spec.ExcludeBuckets = []string{"*-tmp"}
+	assert.True(t, collectedBucket(spec, "team-a-logs"))
+	assert.True(t, collectedBucket(spec, "team-b-data"))
+	assert.False(t, collectedBucket(spec, "team-c-data"))
+	assert.False(t, collectedBucket(spec, "team-a-tmp"))
+}
+
+func TestUsageCollector(t *testing.T) {
+	stats := `[{"bucket":"team-a-logs","owner":"alice","usage":{"rgw.main":{"size":1024,"num_objects":3},"rgw.multimeta":{"size":0,"num_objects":1}}},
+		{"bucket":"team-b-data","owner":"bob","usage":{"rgw.main":{"size":2048,"num_objects":2}}}]`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(_ string, args ...string) (string, error) {
+			if args[0] == "bucket" && args[1] == "stats" {
+				return stats, nil
+			}
+			return "", errors.Errorf("unexpected radosgw-admin command %q", args)
+		},
+	}
+	clientset := fake.NewSimpleClientset()
+	objContext := &Context{
+		Context:     &clusterd.Context{Executor: executor, Clientset: clientset},
+		Name:        "my-store",
+		clusterInfo: client.AdminClusterInfo("ns"),
+	}
+	spec := cephv1.BucketUsageMetricsSpec{Enabled: true, ExcludeBuckets: []string{"team-b-*"}}
+	c := newUsageCollector(objContext, "ns", spec, metav1.OwnerReference{})
+	assert.Equal(t, defaultUsageMetricsInterval, c.interval)
+	bucketSize.Reset()
+	bucketObjects.Reset()
+
+	// the metrics of the collected buckets are exported
+	assert.NoError(t, c.collect())
+	assert.Equal(t, 1, testutil.CollectAndCount(bucketSize))
+	assert.Equal(t, float64(1024), testutil.ToFloat64(bucketSize.WithLabelValues("ns", "my-store", "team-a-logs", "alice")))
+	assert.Equal(t, float64(4), testutil.ToFloat64(bucketObjects.WithLabelValues("ns", "my-store", "team-a-logs", "alice")))
+
+	// the metrics of the deleted buckets are removed
+	stats = `[{"bucket":"team-b-data","owner":"bob","usage":{"rgw.main":{"size":2048,"num_objects":2}}}]`
+	assert.NoError(t, c.collect())
+	assert.Equal(t, 0, testutil.CollectAndCount(bucketSize))
+	assert.Equal(t, 0, testutil.CollectAndCount(bucketObjects))
+
+	// the usage is written in the config map of the store
+	c.spec = cephv1.BucketUsageMetricsSpec{Enabled: true, Output: "configmap"}
+	assert.NoError(t, c.collect())
+	cm, err := clientset.CoreV1().ConfigMaps("ns").Get("rook-ceph-rgw-my-store-bucket-usage", metav1.GetOptions{})
+	assert.NoError(t, err)
+	var report bucketUsageReport
+	assert.NoError(t, json.Unmarshal([]byte(cm.Data[bucketUsageKey]), &report))
+	assert.NotEmpty(t, report.LastUpdated)
+	assert.Equal(t, []bucketUsage{{Name: "team-b-data", Owner: "bob", Size: 2048, NumberOfObjects: 2}}, report.Buckets)
+	assert.Equal(t, 0, testutil.CollectAndCount(bucketSize))
+}
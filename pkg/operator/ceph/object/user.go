@@ -18,6 +18,7 @@ package object
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"syscall"
@@ -37,11 +38,81 @@ const (
 
 // An ObjectUser defines the details of an object store user.
 type ObjectUser struct {
-	UserID      string  `json:"userId"`
-	DisplayName *string `json:"displayName"`
-	Email       *string `json:"email"`
-	AccessKey   *string `json:"accessKey"`
-	SecretKey   *string `json:"secretKey"`
+	UserID      string    `json:"userId"`
+	DisplayName *string   `json:"displayName"`
+	Email       *string   `json:"email"`
+	AccessKey   *string   `json:"accessKey"`
+	SecretKey   *string   `json:"secretKey"`
+	Keys        []S3Key   `json:"keys,omitempty"`
+	SubUsers    []SubUser `json:"subUsers,omitempty"`
+}
+
+// An S3Key is an S3 key of an object store user
+type S3Key struct {
+	// User is the ID of the user or of the sub-user of the key
+	User      string `json:"user,omitempty"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+}
+
+// A SubUser is a sub-user of an object store user, with the ID "<user>:<name>"
+type SubUser struct {
+	ID          string `json:"id"`
+	Permissions string `json:"permissions"`
+}
+
+// subUserPermissions are the permissions of the sub-users reported by the rgw for each access of the sub-user commands
+var subUserPermissions = map[string]string{
+	"read":      "read",
+	"write":     "write",
+	"readwrite": "read-write",
+	"full":      "full-control",
+}
+
+// SubUserID returns the ID of a sub-user of the user
+func SubUserID(userID, name string) string {
+	return fmt.Sprintf("%s:%s", userID, name)
+}
+
+// TenantUserID returns the ID of a user of a tenant, the ID of the user if the tenant is empty
+func TenantUserID(tenant, name string) string {
+	if tenant == "" {
+		return name
+	}
+	return fmt.Sprintf("%s$%s", tenant, name)
+}
+
+// KeysOf returns the S3 keys of the user or of the sub-user
+func (u *ObjectUser) KeysOf(id string) []S3Key {
+	keys := []S3Key{}
+	for _, key := range u.Keys {
+		// the keys of the users without sub-users may be reported without user
+		if key.User == id || key.User == "" && id == u.UserID {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SubUser returns the sub-user with the ID, nil if the user has no such sub-user
+func (u *ObjectUser) SubUser(id string) *SubUser {
+	for i, subUser := range u.SubUsers {
+		if subUser.ID == id {
+			return &u.SubUsers[i]
+		}
+	}
+	return nil
+}
+
+// HasSubUserAccess returns whether the permissions of the sub-user are the permissions of the access
+func (s SubUser) HasSubUserAccess(access string) bool {
+	return subUserPermissions[access] == s.Permissions
+}
+
+// ValidSubUserAccess returns whether the access is an access of the sub-user commands
+func ValidSubUserAccess(access string) bool {
+	_, ok := subUserPermissions[access]
+	return ok
 }
 
 // ListUsers lists the object pool users.
@@ -63,10 +134,8 @@ type rgwUserInfo struct {
 	UserID      string `json:"user_id"`
 	DisplayName string `json:"display_name"`
 	Email       string `json:"email"`
-	Keys        []struct {
-		AccessKey string `json:"access_key"`
-		SecretKey string `json:"secret_key"`
-	}
+	Keys        []S3Key
+	SubUsers    []SubUser `json:"subusers"`
 }
 
 func decodeUser(data string) (*ObjectUser, int, error) {
@@ -76,11 +145,15 @@ func decodeUser(data string) (*ObjectUser, int, error) {
 		return nil, RGWErrorParse, errors.Wrapf(err, "failed to unmarshal json. %s", data)
 	}
 
-	rookUser := ObjectUser{UserID: user.UserID, DisplayName: &user.DisplayName, Email: &user.Email}
+	rookUser := ObjectUser{UserID: user.UserID, DisplayName: &user.DisplayName, Email: &user.Email, Keys: user.Keys, SubUsers: user.SubUsers}
 
-	if len(user.Keys) > 0 {
-		rookUser.AccessKey = &user.Keys[0].AccessKey
-		rookUser.SecretKey = &user.Keys[0].SecretKey
+	// the first key of the user itself, the keys of the sub-users are listed with the keys of the user
+	for i, key := range user.Keys {
+		if key.User == "" || key.User == user.UserID {
+			rookUser.AccessKey = &user.Keys[i].AccessKey
+			rookUser.SecretKey = &user.Keys[i].SecretKey
+			break
+		}
 	}
 
 	return &rookUser, RGWErrorNone, nil
@@ -183,6 +256,66 @@ func DeleteUser(c *Context, id string, opts ...string) (string, error) {
 	return result, errors.Wrap(err, "failed to delete s3 user")
 }
 
+// CreateUserKey generates a new S3 key for the user, the previous keys of the user remain valid
+func CreateUserKey(c *Context, id string) (*ObjectUser, int, error) {
+	logger.Infof("creating a new s3 key for user %q", id)
+	result, err := runAdminCommand(c, "key", "create", "--uid", id, "--key-type", "s3", "--gen-access-key", "--gen-secret")
+	if err != nil {
+		return nil, RGWErrorUnknown, errors.Wrapf(err, "failed to create s3 key for user %q. %s", id, result)
+	}
+	return decodeUser(result)
+}
+
+// DeleteUserKey removes an S3 key of the user
+func DeleteUserKey(c *Context, id, accessKey string) error {
+	logger.Infof("removing s3 key %q of user %q", accessKey, id)
+	result, err := runAdminCommand(c, "key", "rm", "--uid", id, "--key-type", "s3", "--access-key", accessKey)
+	if err != nil {
+		// the key was already removed
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to remove s3 key %q of user %q. %s", accessKey, id, result)
+	}
+	return nil
+}
+
+// CreateSubUser creates a sub-user of the user with the access and an S3 key
+func CreateSubUser(c *Context, id, name, access string) (*ObjectUser, error) {
+	logger.Infof("creating sub-user %q of s3 user %q", name, id)
+	result, err := runAdminCommand(c, "subuser", "create", "--uid", id, "--subuser", SubUserID(id, name), "--access", access,
+		"--key-type", "s3", "--gen-access-key", "--gen-secret")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create sub-user %q of s3 user %q. %s", name, id, result)
+	}
+	user, _, err := decodeUser(result)
+	return user, err
+}
+
+// ModifySubUser sets the access of a sub-user of the user
+func ModifySubUser(c *Context, id, name, access string) error {
+	logger.Infof("setting the access of sub-user %q of s3 user %q to %q", name, id, access)
+	result, err := runAdminCommand(c, "subuser", "modify", "--uid", id, "--subuser", SubUserID(id, name), "--access", access)
+	if err != nil {
+		return errors.Wrapf(err, "failed to modify sub-user %q of s3 user %q. %s", name, id, result)
+	}
+	return nil
+}
+
+// DeleteSubUser removes a sub-user of the user and its keys
+func DeleteSubUser(c *Context, id, name string) error {
+	logger.Infof("removing sub-user %q of s3 user %q", name, id)
+	result, err := runAdminCommand(c, "subuser", "rm", "--uid", id, "--subuser", SubUserID(id, name), "--purge-keys")
+	if err != nil {
+		// the sub-user was already removed
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to remove sub-user %q of s3 user %q. %s", name, id, result)
+	}
+	return nil
+}
+
 func SetQuotaUserBucketMax(c *Context, id string, max int) (string, int, error) {
 	logger.Infof("Setting user %q max buckets to %d", id, max)
 	args := []string{"--quota-scope", "user", "--max-buckets", strconv.Itoa(max)}
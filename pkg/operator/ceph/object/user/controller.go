@@ -21,6 +21,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
@@ -44,6 +47,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -55,6 +60,12 @@ const (
 
 var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
 
+var (
+	tenantNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	// the sub-user name is part of the name of its secret
+	subUserNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
 var cephObjectStoreUserKind = reflect.TypeOf(cephv1.CephObjectStoreUser{}).Name()
 
 // Sets the type meta for the controller main object
@@ -66,10 +77,12 @@ var controllerTypeMeta = metav1.TypeMeta{
 // ReconcileObjectStoreUser reconciles a ObjectStoreUser object
 type ReconcileObjectStoreUser struct {
 	client          client.Client
+	recorder        record.EventRecorder
 	scheme          *runtime.Scheme
 	context         *clusterd.Context
 	objContext      *object.Context
 	userConfig      object.ObjectUser
+	userCreated     bool
 	cephClusterSpec *cephv1.ClusterSpec
 	clusterInfo     *cephclient.ClusterInfo
 }
@@ -89,15 +102,16 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 	}
 
 	return &ReconcileObjectStoreUser{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -127,7 +141,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileObjectStoreUser) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephObjectStoreUser{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephObjectStoreUser{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -243,6 +262,13 @@ func (r *ReconcileObjectStoreUser) reconcile(request reconcile.Request) (reconci
 		return reconcileResponse, err
 	}
 
+	// ROTATE THE KEYS
+	keysResponse, err := r.reconcileKeys(cephObjectStoreUser)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.ReconcileFailedStatus)
+		return keysResponse, err
+	}
+
 	// CREATE/UPDATE KUBERNETES SECRET
 	reconcileResponse, err = r.reconcileCephUserSecret(cephObjectStoreUser)
 	if err != nil {
@@ -250,12 +276,20 @@ func (r *ReconcileObjectStoreUser) reconcile(request reconcile.Request) (reconci
 		return reconcileResponse, err
 	}
 
+	// CREATE/UPDATE THE ADDITIONAL KEYS AND THE SUB-USERS
+	err = r.reconcileAdditionalSecrets(cephObjectStoreUser)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.ReconcileFailedStatus)
+		return reconcile.Result{}, err
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephObjectStoreUser, cephObjectStoreUser.Spec)
 
-	// Return and do not requeue
+	// Requeue until the expiration of the previous keys
 	logger.Debug("done reconciling")
-	return reconcile.Result{}, nil
+	return keysResponse, nil
 }
 
 func (r *ReconcileObjectStoreUser) reconcileCephUser(cephObjectStoreUser *cephv1.CephObjectStoreUser) (reconcile.Result, error) {
@@ -269,6 +303,7 @@ func (r *ReconcileObjectStoreUser) reconcileCephUser(cephObjectStoreUser *cephv1
 
 func (r *ReconcileObjectStoreUser) createorUpdateCephUser(u *cephv1.CephObjectStoreUser) error {
 	logger.Infof("creating ceph object user %q in namespace %q", u.Name, u.Namespace)
+	r.userCreated = false
 	user, rgwerr, err := object.CreateUser(r.objContext, r.userConfig)
 	if err != nil {
 		if rgwerr == object.ErrorCodeFileExists {
@@ -277,9 +312,19 @@ func (r *ReconcileObjectStoreUser) createorUpdateCephUser(u *cephv1.CephObjectSt
 				return errors.Wrapf(err, "failed to get details from ceph object user %q", objectUser.UserID)
 			}
 
-			// Set access and secret key
+			// Set access and secret key, the keys replaced by a rotation are not the keys of the secret anymore
+			secrets, err := r.listUserSecrets(u)
+			if err != nil {
+				return err
+			}
 			r.userConfig.AccessKey = objectUser.AccessKey
 			r.userConfig.SecretKey = objectUser.SecretKey
+			if key := primaryKey(u, objectUser.KeysOf(r.userConfig.UserID), secrets); key != nil {
+				r.userConfig.AccessKey = &key.AccessKey
+				r.userConfig.SecretKey = &key.SecretKey
+			}
+			r.userConfig.Keys = objectUser.Keys
+			r.userConfig.SubUsers = objectUser.SubUsers
 			logger.Debugf("ceph object user %q updated with display name %q", u.Name, *objectUser.DisplayName)
 
 			return nil
@@ -290,6 +335,9 @@ func (r *ReconcileObjectStoreUser) createorUpdateCephUser(u *cephv1.CephObjectSt
 	// Set access and secret key
 	r.userConfig.AccessKey = user.AccessKey
 	r.userConfig.SecretKey = user.SecretKey
+	r.userConfig.Keys = user.Keys
+	r.userConfig.SubUsers = user.SubUsers
+	r.userCreated = true
 
 	logger.Infof("created ceph object user %q", u.Name)
 	return nil
@@ -325,7 +373,7 @@ func generateUserConfig(user *cephv1.CephObjectStoreUser) object.ObjectUser {
 
 	// create the user
 	userConfig := object.ObjectUser{
-		UserID:      user.Name,
+		UserID:      object.TenantUserID(user.Spec.Tenant, user.Name),
 		DisplayName: &displayName,
 	}
 
@@ -339,51 +387,91 @@ func generateCephUserSecretName(u *cephv1.CephObjectStoreUser) string {
 func generateStatusInfo(u *cephv1.CephObjectStoreUser) map[string]string {
 	m := make(map[string]string)
 	m["secretName"] = generateCephUserSecretName(u)
+	m["userID"] = object.TenantUserID(u.Spec.Tenant, u.Name)
 	return m
 }
 
 func (r *ReconcileObjectStoreUser) generateCephUserSecret(u *cephv1.CephObjectStoreUser) *v1.Secret {
+	return r.generateKeySecret(u, generateCephUserSecretName(u), *r.userConfig.AccessKey, *r.userConfig.SecretKey, nil)
+}
+
+// generateKeySecret generates a secret with an S3 key of the user, the labels are added to the labels of the user
+func (r *ReconcileObjectStoreUser) generateKeySecret(u *cephv1.CephObjectStoreUser, name, accessKey, secretKey string, labels map[string]string) *v1.Secret {
 	// Store the keys in a secret
 	secrets := map[string]string{
-		"AccessKey": *r.userConfig.AccessKey,
-		"SecretKey": *r.userConfig.SecretKey,
+		"AccessKey": accessKey,
+		"SecretKey": secretKey,
 		"Endpoint":  r.objContext.Endpoint,
 	}
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      generateCephUserSecretName(u),
+			Name:      name,
 			Namespace: u.Namespace,
-			Labels: map[string]string{
-				"app":               appName,
-				"user":              u.Name,
-				"rook_cluster":      u.Namespace,
-				"rook_object_store": u.Spec.Store,
-			},
+			Labels:    userSecretLabels(u),
 		},
 		StringData: secrets,
 		Type:       k8sutil.RookType,
 	}
+	for key, value := range labels {
+		secret.Labels[key] = value
+	}
 	return secret
 }
 
+func userSecretLabels(u *cephv1.CephObjectStoreUser) map[string]string {
+	return map[string]string{
+		"app":               appName,
+		"user":              u.Name,
+		"rook_cluster":      u.Namespace,
+		"rook_object_store": u.Spec.Store,
+	}
+}
+
 func (r *ReconcileObjectStoreUser) reconcileCephUserSecret(cephObjectStoreUser *cephv1.CephObjectStoreUser) (reconcile.Result, error) {
 	// Generate Kubernetes Secret
 	secret := r.generateCephUserSecret(cephObjectStoreUser)
+	if err := r.writeSecret(cephObjectStoreUser, secret); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// writeSecret creates or updates a secret of the user, owned by the user, with the keys in the secret formats of the
+// user
+func (r *ReconcileObjectStoreUser) writeSecret(u *cephv1.CephObjectStoreUser, secret *v1.Secret) error {
+	formatted, err := object.FormattedSecretData(u.Spec.SecretFormats, u.Name, secret.StringData["Endpoint"], secret.StringData["AccessKey"], secret.StringData["SecretKey"])
+	if err != nil {
+		return errors.Wrapf(err, "failed to format the keys of secret %q", secret.Name)
+	}
+	for key, value := range formatted {
+		secret.StringData[key] = value
+	}
 
 	// Set owner ref to the object store user object
-	err := controllerutil.SetControllerReference(cephObjectStoreUser, secret, r.scheme)
+	err = controllerutil.SetControllerReference(u, secret, r.scheme)
 	if err != nil {
-		return reconcile.Result{}, errors.Wrapf(err, "failed to set owner reference for ceph object user %q secret", secret.Name)
+		return errors.Wrapf(err, "failed to set owner reference for ceph object user %q secret", secret.Name)
 	}
 
 	// Create Kubernetes Secret
 	err = opcontroller.CreateOrUpdateObject(r.client, secret)
 	if err != nil {
-		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update ceph object user %q secret", secret.Name)
+		return errors.Wrapf(err, "failed to create or update ceph object user %q secret", secret.Name)
 	}
 
 	logger.Infof("created ceph object user secret %q", secret.Name)
-	return reconcile.Result{}, nil
+	return nil
+}
+
+// listUserSecrets lists the secrets of the keys of the user
+func (r *ReconcileObjectStoreUser) listUserSecrets(u *cephv1.CephObjectStoreUser) ([]v1.Secret, error) {
+	secrets := &v1.SecretList{}
+	selector := userSecretLabels(u)
+	err := r.client.List(context.TODO(), secrets, client.InNamespace(u.Namespace), client.MatchingLabels(selector))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the secrets of ceph object user %q", u.Name)
+	}
+	return secrets.Items, nil
 }
 
 func (r *ReconcileObjectStoreUser) objectStoreInitialized(cephObjectStoreUser *cephv1.CephObjectStoreUser) error {
@@ -459,7 +547,7 @@ func (r *ReconcileObjectStoreUser) getRgwPodList(cephObjectStoreUser *cephv1.Cep
 
 // Delete the user
 func (r *ReconcileObjectStoreUser) deleteUser(u *cephv1.CephObjectStoreUser) error {
-	output, err := object.DeleteUser(r.objContext, u.Name)
+	output, err := object.DeleteUser(r.objContext, r.userConfig.UserID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete ceph object user %q. %v", u.Name, output)
 	}
@@ -481,6 +569,35 @@ func (r *ReconcileObjectStoreUser) validateUser(u *cephv1.CephObjectStoreUser) e
 			return errors.New("missing store")
 		}
 	}
+	if u.Spec.Tenant != "" && !tenantNameRegex.MatchString(u.Spec.Tenant) {
+		return errors.Errorf("invalid tenant %q", u.Spec.Tenant)
+	}
+	// the user of the previous tenant would remain
+	if u.Status != nil && u.Status.Info["userID"] != "" && u.Status.Info["userID"] != r.userConfig.UserID {
+		return errors.Errorf("the tenant of user %q cannot be changed", u.Status.Info["userID"])
+	}
+	if err := object.ValidateSecretFormats(u.Spec.SecretFormats); err != nil {
+		return err
+	}
+	if u.Spec.KeyCount < 0 {
+		return errors.Errorf("invalid key count %d", u.Spec.KeyCount)
+	}
+	subUsers := map[string]bool{}
+	for _, subUser := range u.Spec.SubUsers {
+		if !subUserNameRegex.MatchString(subUser.Name) {
+			return errors.Errorf("invalid sub-user name %q, expected lowercase alphanumeric characters or '-'", subUser.Name)
+		}
+		if errs := validation.IsDNS1123Subdomain(subUserSecretName(u, subUser.Name)); len(errs) > 0 {
+			return errors.Errorf("invalid sub-user name %q. %s", subUser.Name, strings.Join(errs, ", "))
+		}
+		if subUsers[subUser.Name] {
+			return errors.Errorf("duplicate sub-user %q", subUser.Name)
+		}
+		subUsers[subUser.Name] = true
+		if subUser.Access != "" && !object.ValidSubUserAccess(subUser.Access) {
+			return errors.Errorf("invalid access %q of sub-user %q", subUser.Access, subUser.Name)
+		}
+	}
 	return nil
 }
 
@@ -19,7 +19,10 @@ package objectuser
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
@@ -28,6 +31,8 @@ import (
 	"github.com/rook/rook/pkg/operator/test"
 
 	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/object"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -310,3 +315,186 @@ func TestBuildUpdateStatusInfo(t *testing.T) {
 	assert.NotEmpty(t, statusInfo["secretName"])
 	assert.Equal(t, "rook-ceph-object-user-my-store-my-user", statusInfo["secretName"])
 }
+
+func TestRotateUserKeys(t *testing.T) {
+	userKeysJSON := `{"user_id":"my-user","display_name":"my-user","keys":[
+		{"user":"my-user","access_key":"OLDKEY","secret_key":"oldsecret"},
+		{"user":"my-user","access_key":"NEWKEY","secret_key":"newsecret"}]}`
+	removed := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "key" && args[1] == "create" {
+				return userKeysJSON, nil
+			}
+			if args[0] == "key" && args[1] == "rm" {
+				removed = append(removed, args[7])
+				return "", nil
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{Executor: executor, Clientset: test.New(t, 1)}
+	objectUser := &cephv1.CephObjectStoreUser{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{RotateKeysAnnotation: "true"},
+		},
+		Spec:     cephv1.ObjectStoreUserSpec{Store: store, PreviousKeyGracePeriodSeconds: 3600},
+		Status:   &cephv1.ObjectStoreUserStatus{Phase: k8sutil.ReadyStatus},
+		TypeMeta: metav1.TypeMeta{Kind: "CephObjectStoreUser"},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephObjectStoreUser{})
+	cl := fake.NewFakeClientWithScheme(s, objectUser.DeepCopy())
+	oldKey, oldSecret := "OLDKEY", "oldsecret"
+	r := &ReconcileObjectStoreUser{
+		client:     cl,
+		scheme:     s,
+		context:    c,
+		objContext: object.NewContext(c, cephclient.AdminClusterInfo(namespace), store),
+		userConfig: object.ObjectUser{UserID: name, AccessKey: &oldKey, SecretKey: &oldSecret, Keys: []object.S3Key{{AccessKey: oldKey, SecretKey: oldSecret}}},
+	}
+	getUser := func() *cephv1.CephObjectStoreUser {
+		user := &cephv1.CephObjectStoreUser{}
+		assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, user))
+		return user
+	}
+
+	// the secret has the new key and the previous key remains valid for the grace period
+	res, err := r.reconcileKeys(objectUser)
+	assert.NoError(t, err)
+	assert.True(t, res.RequeueAfter > 3500*time.Second)
+	secret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: generateCephUserSecretName(objectUser), Namespace: namespace}, secret))
+	assert.Equal(t, "NEWKEY", secret.StringData["AccessKey"])
+	assert.Equal(t, "newsecret", secret.StringData["SecretKey"])
+	user := getUser()
+	assert.Equal(t, 1, len(user.Status.PreviousKeys))
+	assert.Equal(t, "OLDKEY", user.Status.PreviousKeys[0].AccessKey)
+	assert.NotContains(t, user.Annotations, RotateKeysAnnotation)
+	assert.Empty(t, removed)
+
+	// the current key is the key which was not replaced
+	assert.Equal(t, "NEWKEY", currentKey(user, r.userConfig.Keys).AccessKey)
+
+	// the previous key is removed when it expires
+	user.Status.PreviousKeys[0].ExpirationTime = metav1.NewTime(time.Now().Add(-time.Second))
+	res, err = r.reconcileKeys(user)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, res)
+	assert.Equal(t, []string{"OLDKEY"}, removed)
+	assert.Empty(t, getUser().Status.PreviousKeys)
+
+	// the keys are rotated when the key generation is incremented, only once
+	user = getUser()
+	user.Spec.KeyGeneration = 1
+	assert.True(t, isKeyRotationRequested(user))
+	user.Status.ObservedKeyGeneration = 1
+	assert.False(t, isKeyRotationRequested(user))
+}
+
+func TestAdditionalKeysAndSubUsers(t *testing.T) {
+	userID := "acme$my-user"
+	keys := []object.S3Key{{User: userID, AccessKey: "KEY0", SecretKey: "secret0"}}
+	subUsers := []object.SubUser{}
+	userJSON := func() string {
+		data, err := json.Marshal(map[string]interface{}{"user_id": userID, "display_name": name, "keys": keys, "subusers": subUsers})
+		assert.NoError(t, err)
+		return string(data)
+	}
+	created, removed := 0, []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(_ string, args ...string) (string, error) {
+			switch {
+			case args[0] == "key" && args[1] == "create":
+				created++
+				keys = append(keys, object.S3Key{User: userID, AccessKey: fmt.Sprintf("KEY%d", created), SecretKey: "secret"})
+				return userJSON(), nil
+			case args[0] == "subuser" && args[1] == "create":
+				assert.Equal(t, []string{"--uid", userID, "--subuser", userID + ":reader", "--access", "read"}, args[2:8])
+				subUsers = append(subUsers, object.SubUser{ID: userID + ":reader", Permissions: "read"})
+				keys = append(keys, object.S3Key{User: userID + ":reader", AccessKey: "READERKEY", SecretKey: "secret"})
+				return userJSON(), nil
+			case args[0] == "key" && args[1] == "rm":
+				removed = append(removed, args[7])
+			case args[0] == "subuser" && args[1] == "rm":
+				removed = append(removed, args[5])
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{Executor: executor, Clientset: test.New(t, 1)}
+	objectUser := &cephv1.CephObjectStoreUser{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: cephv1.ObjectStoreUserSpec{
+			Store:    store,
+			Tenant:   "acme",
+			KeyCount: 3,
+			SubUsers: []cephv1.ObjectStoreSubUserSpec{{Name: "reader", Access: "read"}},
+		},
+		TypeMeta: metav1.TypeMeta{Kind: "CephObjectStoreUser"},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephObjectStoreUser{})
+	cl := fake.NewFakeClientWithScheme(s, objectUser.DeepCopy())
+	key0, secret0 := "KEY0", "secret0"
+	r := &ReconcileObjectStoreUser{
+		client:          cl,
+		scheme:          s,
+		context:         c,
+		objContext:      object.NewContext(c, cephclient.AdminClusterInfo(namespace), store),
+		userConfig:      generateUserConfig(objectUser),
+		cephClusterSpec: &cephv1.ClusterSpec{},
+	}
+	r.userConfig.AccessKey, r.userConfig.SecretKey, r.userConfig.Keys = &key0, &secret0, keys
+	assert.Equal(t, userID, r.userConfig.UserID)
+	assert.NoError(t, r.validateUser(objectUser))
+	_, err := r.reconcileCephUserSecret(objectUser)
+	assert.NoError(t, err)
+	secretKey := func(secretName string) string {
+		secret := &corev1.Secret{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+			return ""
+		}
+		return secretAccessKey(secret)
+	}
+
+	// each additional key and sub-user has its own secret
+	assert.NoError(t, r.reconcileAdditionalSecrets(objectUser))
+	assert.Equal(t, 2, created)
+	assert.Equal(t, "KEY0", secretKey("rook-ceph-object-user-my-store-my-user"))
+	assert.Equal(t, "KEY1", secretKey("rook-ceph-object-user-my-store-my-user-key-1"))
+	assert.Equal(t, "KEY2", secretKey("rook-ceph-object-user-my-store-my-user-key-2"))
+	assert.Equal(t, "READERKEY", secretKey("rook-ceph-object-user-my-store-my-user-subuser-reader"))
+
+	// the keys of the secrets are kept
+	assert.NoError(t, r.reconcileAdditionalSecrets(objectUser))
+	assert.Equal(t, 2, created)
+	secrets, err := r.listUserSecrets(objectUser)
+	assert.NoError(t, err)
+	assert.Equal(t, "KEY0", primaryKey(objectUser, r.userConfig.KeysOf(userID), secrets).AccessKey)
+
+	// the keys beyond the key count and the removed sub-users are removed with their secrets
+	objectUser.Spec.KeyCount = 2
+	objectUser.Spec.SubUsers = nil
+	assert.NoError(t, r.reconcileAdditionalSecrets(objectUser))
+	assert.ElementsMatch(t, []string{"KEY2", userID + ":reader"}, removed)
+	assert.Equal(t, "", secretKey("rook-ceph-object-user-my-store-my-user-key-2"))
+	assert.Equal(t, "", secretKey("rook-ceph-object-user-my-store-my-user-subuser-reader"))
+	assert.Equal(t, "KEY1", secretKey("rook-ceph-object-user-my-store-my-user-key-1"))
+
+	// the settings are validated
+	objectUser.Spec.SubUsers = []cephv1.ObjectStoreSubUserSpec{{Name: "reader", Access: "admin"}}
+	assert.Error(t, r.validateUser(objectUser))
+	objectUser.Spec.SubUsers = []cephv1.ObjectStoreSubUserSpec{{Name: "Read_Only", Access: "read"}}
+	assert.Error(t, r.validateUser(objectUser))
+	objectUser.Spec.SubUsers = []cephv1.ObjectStoreSubUserSpec{{Name: "read-only", Access: "read"}}
+	assert.NoError(t, r.validateUser(objectUser))
+	objectUser.Spec.SubUsers = nil
+	objectUser.Spec.Tenant = "acme-corp"
+	assert.Error(t, r.validateUser(objectUser))
+	objectUser.Spec.Tenant = ""
+	objectUser.Status = &cephv1.ObjectStoreUserStatus{Info: map[string]string{"userID": "other$my-user"}}
+	assert.Error(t, r.validateUser(objectUser))
+}
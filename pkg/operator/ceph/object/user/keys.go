@@ -0,0 +1,341 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectuser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// RotateKeysAnnotation rotates the S3 keys of the user once, the annotation is removed by the operator after the
+	// rotation
+	RotateKeysAnnotation = "ceph.rook.io/rotate-keys"
+
+	// the labels of the secrets of the additional keys and of the sub-users
+	keyIndexLabel = "key_index"
+	subUserLabel  = "subuser"
+)
+
+// isKeyRotationRequested returns whether the keys of the user must be rotated, with the annotation or when the key
+// generation of the spec is incremented
+func isKeyRotationRequested(u *cephv1.CephObjectStoreUser) bool {
+	if _, ok := u.Annotations[RotateKeysAnnotation]; ok {
+		return true
+	}
+	observed := int64(0)
+	if u.Status != nil {
+		observed = u.Status.ObservedKeyGeneration
+	}
+	return u.Spec.KeyGeneration > observed
+}
+
+// currentKey returns the key of the user which was not replaced by a rotation
+func currentKey(u *cephv1.CephObjectStoreUser, keys []object.S3Key) *object.S3Key {
+	for i, key := range keys {
+		if !isPreviousKey(u, key.AccessKey) {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// primaryKey returns the key of the secret of the user if it is a current key of the user, else the current key of the
+// user which is not the key of another secret
+func primaryKey(u *cephv1.CephObjectStoreUser, keys []object.S3Key, secrets []corev1.Secret) *object.S3Key {
+	primary := ""
+	otherKeys := map[string]bool{}
+	for i, secret := range secrets {
+		if secret.Name == generateCephUserSecretName(u) {
+			primary = secretAccessKey(&secrets[i])
+		} else {
+			otherKeys[secretAccessKey(&secrets[i])] = true
+		}
+	}
+	candidates := []object.S3Key{}
+	for _, key := range keys {
+		if !otherKeys[key.AccessKey] {
+			candidates = append(candidates, key)
+		}
+	}
+	for i, key := range candidates {
+		if key.AccessKey == primary && !isPreviousKey(u, primary) {
+			return &candidates[i]
+		}
+	}
+	return currentKey(u, candidates)
+}
+
+// secretAccessKey returns the access key of a secret of the user, the secrets created by the fake clients of the
+// tests only have the string data
+func secretAccessKey(secret *corev1.Secret) string {
+	if accessKey, ok := secret.Data["AccessKey"]; ok {
+		return string(accessKey)
+	}
+	return secret.StringData["AccessKey"]
+}
+
+func additionalKeySecretName(u *cephv1.CephObjectStoreUser, index int) string {
+	return fmt.Sprintf("%s-key-%d", generateCephUserSecretName(u), index)
+}
+
+// reconcileAdditionalSecrets creates the additional keys and the sub-users of the user with their secrets
+func (r *ReconcileObjectStoreUser) reconcileAdditionalSecrets(u *cephv1.CephObjectStoreUser) error {
+	secrets, err := r.listUserSecrets(u)
+	if err != nil {
+		return err
+	}
+	if err := r.reconcileAdditionalKeys(u, secrets); err != nil {
+		return errors.Wrapf(err, "failed to reconcile the keys of ceph object user %q", u.Name)
+	}
+	if err := r.reconcileSubUsers(u, secrets); err != nil {
+		return errors.Wrapf(err, "failed to reconcile the sub-users of ceph object user %q", u.Name)
+	}
+	return nil
+}
+
+// reconcileAdditionalKeys creates the keys of the user in addition to the key of the user secret, each in its own
+// secret, and removes the keys of the secrets beyond the key count. The keys of the user without secret, like the keys
+// created with radosgw-admin, are not changed.
+func (r *ReconcileObjectStoreUser) reconcileAdditionalKeys(u *cephv1.CephObjectStoreUser, secrets []corev1.Secret) error {
+	count := u.Spec.KeyCount
+	if count < 1 {
+		count = 1
+	}
+	existing := map[int]*corev1.Secret{}
+	for i, secret := range secrets {
+		if index, err := strconv.Atoi(secret.Labels[keyIndexLabel]); err == nil {
+			existing[index] = &secrets[i]
+		}
+	}
+
+	for index := 1; index < count; index++ {
+		var key *object.S3Key
+		if secret, ok := existing[index]; ok {
+			keys := r.userConfig.KeysOf(r.userConfig.UserID)
+			for i := range keys {
+				if keys[i].AccessKey == secretAccessKey(secret) && !isPreviousKey(u, keys[i].AccessKey) {
+					key = &keys[i]
+				}
+			}
+		}
+		if key == nil {
+			var err error
+			if key, err = r.createKey(); err != nil {
+				return err
+			}
+		}
+		secret := r.generateKeySecret(u, additionalKeySecretName(u, index), key.AccessKey, key.SecretKey, map[string]string{keyIndexLabel: strconv.Itoa(index)})
+		if err := r.writeSecret(u, secret); err != nil {
+			return err
+		}
+	}
+
+	for index, secret := range existing {
+		if index < count {
+			continue
+		}
+		accessKey := secretAccessKey(secret)
+		if accessKey != "" && accessKey != *r.userConfig.AccessKey {
+			if err := object.DeleteUserKey(r.objContext, r.userConfig.UserID, accessKey); err != nil {
+				return err
+			}
+		}
+		if err := r.client.Delete(context.TODO(), secret); err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete secret %q", secret.Name)
+		}
+		logger.Infof("removed key %d of ceph object user %q", index, u.Name)
+	}
+	return nil
+}
+
+func isPreviousKey(u *cephv1.CephObjectStoreUser, accessKey string) bool {
+	if u.Status == nil {
+		return false
+	}
+	for _, key := range u.Status.PreviousKeys {
+		if key.AccessKey == accessKey {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileKeys rotates the keys of the user when requested and removes the previous keys which expired. The status
+// records the previous key before the secret is updated with the new key, so that the new key is the current key of
+// the next reconcile if the operator restarts in between.
+func (r *ReconcileObjectStoreUser) reconcileKeys(u *cephv1.CephObjectStoreUser) (reconcile.Result, error) {
+	if r.userCreated {
+		// the keys of a new user are the keys of its key generation
+		if u.Spec.KeyGeneration != 0 {
+			if err := r.updateKeyStatus(u, u.Spec.KeyGeneration, nil); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if isKeyRotationRequested(u) {
+		if err := r.rotateKeys(u); err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to rotate the keys of ceph object user %q", u.Name)
+		}
+	}
+	return r.removeExpiredKeys(u)
+}
+
+func (r *ReconcileObjectStoreUser) rotateKeys(u *cephv1.CephObjectStoreUser) error {
+	if r.userConfig.AccessKey == nil {
+		return errors.New("the user has no key to rotate")
+	}
+	previousKey := *r.userConfig.AccessKey
+	newKey, err := r.createKey()
+	if err != nil {
+		return err
+	}
+
+	// the previous key expires after the grace period, at once by default
+	expiration := metav1.NewTime(time.Now().Add(time.Duration(u.Spec.PreviousKeyGracePeriodSeconds) * time.Second))
+	previousKeys := []cephv1.ObjectStoreUserKey{}
+	generation := u.Spec.KeyGeneration
+	if u.Status != nil {
+		previousKeys = append(previousKeys, u.Status.PreviousKeys...)
+		if u.Status.ObservedKeyGeneration > generation {
+			generation = u.Status.ObservedKeyGeneration
+		}
+	}
+	previousKeys = append(previousKeys, cephv1.ObjectStoreUserKey{AccessKey: previousKey, ExpirationTime: expiration})
+	if err := r.updateKeyStatus(u, generation, previousKeys); err != nil {
+		return err
+	}
+
+	// the secret is updated with both the new access and secret keys at once
+	r.userConfig.AccessKey = &newKey.AccessKey
+	r.userConfig.SecretKey = &newKey.SecretKey
+	if _, err := r.reconcileCephUserSecret(u); err != nil {
+		return err
+	}
+	logger.Infof("rotated the keys of ceph object user %q, the previous key %q expires at %s", u.Name, previousKey, expiration.String())
+
+	if _, ok := u.Annotations[RotateKeysAnnotation]; ok {
+		if err := r.removeRotateKeysAnnotation(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeExpiredKeys removes the previous keys of the user which expired, and requeues the reconcile until the next
+// expiration
+func (r *ReconcileObjectStoreUser) removeExpiredKeys(u *cephv1.CephObjectStoreUser) (reconcile.Result, error) {
+	if u.Status == nil || len(u.Status.PreviousKeys) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	now := time.Now()
+	remaining := []cephv1.ObjectStoreUserKey{}
+	var nextExpiration time.Duration
+	for _, key := range u.Status.PreviousKeys {
+		if wait := key.ExpirationTime.Sub(now); wait > 0 {
+			remaining = append(remaining, key)
+			if nextExpiration == 0 || wait < nextExpiration {
+				nextExpiration = wait
+			}
+			continue
+		}
+		if err := object.DeleteUserKey(r.objContext, r.userConfig.UserID, key.AccessKey); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if len(remaining) != len(u.Status.PreviousKeys) {
+		if err := r.updateKeyStatus(u, u.Status.ObservedKeyGeneration, remaining); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if nextExpiration > 0 {
+		logger.Debugf("the next previous key of ceph object user %q expires in %s", u.Name, nextExpiration.String())
+		return reconcile.Result{RequeueAfter: nextExpiration}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// createKey creates a new S3 key of the user, the previous keys of the user remain valid
+func (r *ReconcileObjectStoreUser) createKey() (*object.S3Key, error) {
+	user, _, err := object.CreateUserKey(r.objContext, r.userConfig.UserID)
+	if err != nil {
+		return nil, err
+	}
+	keys := user.KeysOf(r.userConfig.UserID)
+	for i, key := range keys {
+		if !hasKey(r.userConfig.Keys, key.AccessKey) {
+			r.userConfig.Keys = user.Keys
+			return &keys[i], nil
+		}
+	}
+	return nil, errors.New("failed to find the new key of the user")
+}
+
+func hasKey(keys []object.S3Key, accessKey string) bool {
+	for _, key := range keys {
+		if key.AccessKey == accessKey {
+			return true
+		}
+	}
+	return false
+}
+
+// updateKeyStatus updates the key rotation fields of the status of the user, and of the user being reconciled
+func (r *ReconcileObjectStoreUser) updateKeyStatus(u *cephv1.CephObjectStoreUser, generation int64, previousKeys []cephv1.ObjectStoreUserKey) error {
+	user := &cephv1.CephObjectStoreUser{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: u.Name, Namespace: u.Namespace}, user); err != nil {
+		return errors.Wrapf(err, "failed to get ceph object user %q", u.Name)
+	}
+	if user.Status == nil {
+		user.Status = &cephv1.ObjectStoreUserStatus{}
+	}
+	user.Status.ObservedKeyGeneration = generation
+	user.Status.PreviousKeys = previousKeys
+	if err := opcontroller.UpdateStatus(r.client, user); err != nil {
+		return errors.Wrapf(err, "failed to update the key status of ceph object user %q", u.Name)
+	}
+	u.Status = user.Status
+	return nil
+}
+
+func (r *ReconcileObjectStoreUser) removeRotateKeysAnnotation(u *cephv1.CephObjectStoreUser) error {
+	user := &cephv1.CephObjectStoreUser{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: u.Name, Namespace: u.Namespace}, user); err != nil {
+		return errors.Wrapf(err, "failed to get ceph object user %q", u.Name)
+	}
+	delete(user.Annotations, RotateKeysAnnotation)
+	if err := r.client.Update(context.TODO(), user); err != nil {
+		return errors.Wrapf(err, "failed to remove the %q annotation of ceph object user %q", RotateKeysAnnotation, u.Name)
+	}
+	delete(u.Annotations, RotateKeysAnnotation)
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectuser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const defaultSubUserAccess = "full"
+
+func subUserSecretName(u *cephv1.CephObjectStoreUser, name string) string {
+	return fmt.Sprintf("%s-subuser-%s", generateCephUserSecretName(u), name)
+}
+
+// reconcileSubUsers creates the sub-users of the user and their secrets, and removes the sub-users with a secret which
+// were removed from the spec. The sub-users without secret, like the sub-users created with radosgw-admin, are not
+// changed.
+func (r *ReconcileObjectStoreUser) reconcileSubUsers(u *cephv1.CephObjectStoreUser, secrets []corev1.Secret) error {
+	expected := map[string]bool{}
+	for _, subUser := range u.Spec.SubUsers {
+		expected[subUser.Name] = true
+		access := subUser.Access
+		if access == "" {
+			access = defaultSubUserAccess
+		}
+
+		id := object.SubUserID(r.userConfig.UserID, subUser.Name)
+		if existing := r.userConfig.SubUser(id); existing == nil {
+			user, err := object.CreateSubUser(r.objContext, r.userConfig.UserID, subUser.Name, access)
+			if err != nil {
+				return err
+			}
+			r.userConfig.Keys = user.Keys
+			r.userConfig.SubUsers = user.SubUsers
+		} else if !existing.HasSubUserAccess(access) {
+			if err := object.ModifySubUser(r.objContext, r.userConfig.UserID, subUser.Name, access); err != nil {
+				return err
+			}
+		}
+
+		keys := r.userConfig.KeysOf(id)
+		if len(keys) == 0 {
+			return errors.Errorf("sub-user %q has no s3 key", id)
+		}
+		secret := r.generateKeySecret(u, subUserSecretName(u, subUser.Name), keys[0].AccessKey, keys[0].SecretKey, map[string]string{subUserLabel: subUser.Name})
+		if err := r.writeSecret(u, secret); err != nil {
+			return err
+		}
+	}
+
+	for i, secret := range secrets {
+		name, ok := secret.Labels[subUserLabel]
+		if !ok || expected[name] {
+			continue
+		}
+		if err := object.DeleteSubUser(r.objContext, r.userConfig.UserID, name); err != nil {
+			return err
+		}
+		if err := r.client.Delete(context.TODO(), &secrets[i]); err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete secret %q", secret.Name)
+		}
+		logger.Infof("removed sub-user %q of ceph object user %q", name, u.Name)
+	}
+	return nil
+}
@@ -45,6 +45,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -67,6 +68,7 @@ var controllerTypeMeta = metav1.TypeMeta{
 // ReconcileObjectZone reconciles a ObjectZone object
 type ReconcileObjectZone struct {
 	client      client.Client
+	recorder    record.EventRecorder
 	scheme      *runtime.Scheme
 	context     *clusterd.Context
 	clusterInfo *cephclient.ClusterInfo
@@ -86,15 +88,16 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 		panic(err)
 	}
 	return &ReconcileObjectZone{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -115,7 +118,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileObjectZone) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephObjectZone{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephObjectZone{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile: %v", err)
 	}
@@ -197,6 +205,7 @@ func (r *ReconcileObjectZone) reconcile(request reconcile.Request) (reconcile.Re
 
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephObjectZone, cephObjectZone.Spec)
 
 	// Return and do not requeue
 	logger.Debug("zone done reconciling")
@@ -239,6 +248,14 @@ func (r *ReconcileObjectZone) createCephZone(zone *cephv1.CephObjectZone, realmN
 
 		zoneIsMaster := false
 		if zoneGroupJson.MasterZoneID == "" {
+			pulled, err := r.isPulledRealm(zone.Namespace, realmName)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if pulled {
+				// the master zone of a pulled realm lives in the cluster the realm was pulled from
+				return waitForRequeueIfObjectZoneGroupNotReady, errors.Errorf("master zone of zone group %q not found in pulled realm %q", zone.Spec.ZoneGroup, realmName)
+			}
 			zoneIsMaster = true
 		}
 
@@ -286,6 +303,18 @@ func (r *ReconcileObjectZone) createPoolsAndZone(objContext *object.Context, zon
 	return nil
 }
 
+// isPulledRealm returns true if the realm is pulled from another cluster
+func (r *ReconcileObjectZone) isPulledRealm(namespace, realmName string) (bool, error) {
+	realm, err := r.context.RookClientset.CephV1().CephObjectRealms(namespace).Get(realmName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get CephObjectRealm %q", realmName)
+	}
+	return realm.Spec.IsPullRealm(), nil
+}
+
 func (r *ReconcileObjectZone) reconcileObjectZoneGroup(zone *cephv1.CephObjectZone) (string, reconcile.Result, error) {
 	// Verify the object zone API object actually exists
 	zoneGroup, err := r.context.RookClientset.CephV1().CephObjectZoneGroups(zone.Namespace).Get(zone.Spec.ZoneGroup, metav1.GetOptions{})
@@ -44,6 +44,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -66,6 +67,7 @@ var controllerTypeMeta = metav1.TypeMeta{
 // ReconcileObjectZoneGroup reconciles a ObjectZoneGroup object
 type ReconcileObjectZoneGroup struct {
 	client      client.Client
+	recorder    record.EventRecorder
 	scheme      *runtime.Scheme
 	context     *clusterd.Context
 	clusterInfo *cephclient.ClusterInfo
@@ -85,15 +87,16 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 		panic(err)
 	}
 	return &ReconcileObjectZoneGroup{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -114,7 +117,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileObjectZoneGroup) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
-	reconcileResponse, err := r.reconcile(request)
+	start := time.Now()
+	// the parallel reconciles each run on a copy of the reconciler, which holds the state of the request
+	reconciler := *r
+	reconcileResponse, err := reconciler.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephObjectZoneGroup{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephObjectZoneGroup{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile: %v", err)
 	}
@@ -193,8 +201,15 @@ func (r *ReconcileObjectZoneGroup) reconcile(request reconcile.Request) (reconci
 		return r.setFailedStatus(request.NamespacedName, "failed to create ceph zone", err)
 	}
 
+	// Apply the sync policy groups, flows and pipes
+	err = r.reconcileSyncPolicy(cephObjectZoneGroup)
+	if err != nil {
+		return r.setFailedStatus(request.NamespacedName, "failed to reconcile zone group sync policy", err)
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephObjectZoneGroup, cephObjectZoneGroup.Spec)
 
 	// Return and do not requeue
 	logger.Debug("zone group done reconciling")
@@ -0,0 +1,428 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zonegroup
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+)
+
+const syncPolicyWildcard = "*"
+
+type syncPolicyType struct {
+	Groups []syncGroupType `json:"groups"`
+}
+
+type syncGroupType struct {
+	ID       string           `json:"id"`
+	Status   string           `json:"status"`
+	DataFlow syncDataFlowType `json:"data_flow"`
+	Pipes    []syncPipeType   `json:"pipes"`
+}
+
+type syncDataFlowType struct {
+	Symmetrical []syncSymmetricalFlowType `json:"symmetrical"`
+	Directional []syncDirectionalFlowType `json:"directional"`
+}
+
+type syncSymmetricalFlowType struct {
+	ID    string   `json:"id"`
+	Zones []string `json:"zones"`
+}
+
+type syncDirectionalFlowType struct {
+	// ID is only set on the desired flows to create them, radosgw-admin does not report the ids of the directional flows
+	ID         string `json:"-"`
+	SourceZone string `json:"source_zone"`
+	DestZone   string `json:"dest_zone"`
+}
+
+// zones returns the flow without its id, since the directional flows are identified by their source and destination
+// zones
+func (f syncDirectionalFlowType) zones() syncDirectionalFlowType {
+	return syncDirectionalFlowType{SourceZone: f.SourceZone, DestZone: f.DestZone}
+}
+
+type syncPipeType struct {
+	ID     string               `json:"id"`
+	Source syncPipeEndpointType `json:"source"`
+	Dest   syncPipeEndpointType `json:"dest"`
+}
+
+type syncPipeEndpointType struct {
+	Bucket string   `json:"bucket"`
+	Zones  []string `json:"zones"`
+}
+
+func decodeSyncPolicy(data string) (syncPolicyType, error) {
+	var policy syncPolicyType
+	err := json.Unmarshal([]byte(data), &policy)
+	if err != nil {
+		return policy, errors.Wrap(err, "failed to unmarshal json")
+	}
+	return policy, nil
+}
+
+// validateSyncPolicy validates the sync policy groups, flows and pipes
+func validateSyncPolicy(policy *cephv1.ObjectSyncPolicySpec) error {
+	if policy == nil {
+		return nil
+	}
+	groupIDs := map[string]bool{}
+	for _, group := range policy.Groups {
+		if group.ID == "" {
+			return errors.New("missing sync group id")
+		}
+		if groupIDs[group.ID] {
+			return errors.Errorf("duplicate sync group id %q", group.ID)
+		}
+		groupIDs[group.ID] = true
+
+		switch group.Status {
+		case cephv1.ObjectSyncGroupEnabled, cephv1.ObjectSyncGroupAllowed, cephv1.ObjectSyncGroupForbidden:
+		default:
+			return errors.Errorf("invalid status %q for sync group %q", group.Status, group.ID)
+		}
+
+		flowIDs := map[string]bool{}
+		for _, flow := range group.Flows {
+			if flow.ID == "" {
+				return errors.Errorf("missing flow id in sync group %q", group.ID)
+			}
+			if flowIDs[flow.ID] {
+				return errors.Errorf("duplicate flow id %q in sync group %q", flow.ID, group.ID)
+			}
+			flowIDs[flow.ID] = true
+
+			switch flow.Type {
+			case cephv1.ObjectSyncFlowSymmetrical:
+				if len(flow.Zones) < 2 {
+					return errors.Errorf("symmetrical flow %q in sync group %q must have at least two zones", flow.ID, group.ID)
+				}
+			case cephv1.ObjectSyncFlowDirectional:
+				if flow.SourceZone == "" || flow.DestZone == "" {
+					return errors.Errorf("directional flow %q in sync group %q must have a source and a destination zone", flow.ID, group.ID)
+				}
+			default:
+				return errors.Errorf("invalid type %q for flow %q in sync group %q", flow.Type, flow.ID, group.ID)
+			}
+		}
+
+		pipeIDs := map[string]bool{}
+		for _, pipe := range group.Pipes {
+			if pipe.ID == "" {
+				return errors.Errorf("missing pipe id in sync group %q", group.ID)
+			}
+			if pipeIDs[pipe.ID] {
+				return errors.Errorf("duplicate pipe id %q in sync group %q", pipe.ID, group.ID)
+			}
+			pipeIDs[pipe.ID] = true
+		}
+	}
+	return nil
+}
+
+// desiredSyncGroup converts the sync group spec to the format reported by radosgw-admin so they can be compared
+func desiredSyncGroup(group cephv1.ObjectSyncGroupSpec) syncGroupType {
+	desired := syncGroupType{ID: group.ID, Status: string(group.Status)}
+	for _, flow := range group.Flows {
+		if flow.Type == cephv1.ObjectSyncFlowSymmetrical {
+			desired.DataFlow.Symmetrical = append(desired.DataFlow.Symmetrical, syncSymmetricalFlowType{ID: flow.ID, Zones: sortedCopy(flow.Zones)})
+		} else {
+			desired.DataFlow.Directional = append(desired.DataFlow.Directional, syncDirectionalFlowType{ID: flow.ID, SourceZone: flow.SourceZone, DestZone: flow.DestZone})
+		}
+	}
+	for _, pipe := range group.Pipes {
+		desired.Pipes = append(desired.Pipes, syncPipeType{
+			ID:     pipe.ID,
+			Source: syncPipeEndpointType{Bucket: defaultWildcard(pipe.SourceBucket), Zones: defaultWildcardList(pipe.SourceZones)},
+			Dest:   syncPipeEndpointType{Bucket: defaultWildcard(pipe.DestBucket), Zones: defaultWildcardList(pipe.DestZones)},
+		})
+	}
+	return desired
+}
+
+func defaultWildcard(value string) string {
+	if value == "" {
+		return syncPolicyWildcard
+	}
+	return value
+}
+
+func defaultWildcardList(values []string) []string {
+	if len(values) == 0 {
+		return []string{syncPolicyWildcard}
+	}
+	return sortedCopy(values)
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func (r *ReconcileObjectZoneGroup) reconcileSyncPolicy(zoneGroup *cephv1.CephObjectZoneGroup) error {
+	if zoneGroup.Spec.SyncPolicy == nil {
+		return nil
+	}
+
+	objContext := object.NewContext(r.context, r.clusterInfo, zoneGroup.Name)
+	multisiteArgs := []string{
+		fmt.Sprintf("--rgw-realm=%s", zoneGroup.Spec.Realm),
+		fmt.Sprintf("--rgw-zonegroup=%s", zoneGroup.Name),
+	}
+	run := func(args ...string) (string, error) {
+		return object.RunAdminCommandNoMultisite(objContext, append(args, multisiteArgs...)...)
+	}
+
+	output, err := run("sync", "policy", "get")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get sync policy of zone group %q for reason %q", zoneGroup.Name, output)
+	}
+	current, err := decodeSyncPolicy(output)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse `radosgw-admin sync policy get` output")
+	}
+
+	changed, err := applySyncPolicy(run, current, zoneGroup.Spec.SyncPolicy)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply sync policy of zone group %q", zoneGroup.Name)
+	}
+	if !changed {
+		logger.Debugf("sync policy of zone group %q is up to date", zoneGroup.Name)
+		return nil
+	}
+
+	output, err = run("period", "update", "--commit")
+	if err != nil {
+		return errors.Wrapf(err, "failed to commit the period after updating the sync policy of zone group %q for reason %q", zoneGroup.Name, output)
+	}
+	logger.Infof("updated sync policy of zone group %q", zoneGroup.Name)
+	return nil
+}
+
+// applySyncPolicy runs the radosgw-admin commands to converge the current sync policy to the desired one.
+// It returns whether any change was made.
+func applySyncPolicy(run func(args ...string) (string, error), current syncPolicyType, desired *cephv1.ObjectSyncPolicySpec) (bool, error) {
+	changed := false
+	currentGroups := map[string]syncGroupType{}
+	for _, group := range current.Groups {
+		currentGroups[group.ID] = group
+	}
+
+	for _, groupSpec := range desired.Groups {
+		want := desiredSyncGroup(groupSpec)
+		have, exists := currentGroups[groupSpec.ID]
+		delete(currentGroups, groupSpec.ID)
+		if exists && syncGroupsEqual(have, want) {
+			continue
+		}
+		changed = true
+
+		groupArg := fmt.Sprintf("--group-id=%s", want.ID)
+		statusArg := fmt.Sprintf("--status=%s", want.Status)
+		if !exists {
+			logger.Infof("creating sync group %q", want.ID)
+			if output, err := run("sync", "group", "create", groupArg, statusArg); err != nil {
+				return changed, errors.Wrapf(err, "failed to create sync group %q for reason %q", want.ID, output)
+			}
+		} else if have.Status != want.Status {
+			logger.Infof("setting status of sync group %q to %q", want.ID, want.Status)
+			if output, err := run("sync", "group", "modify", groupArg, statusArg); err != nil {
+				return changed, errors.Wrapf(err, "failed to modify sync group %q for reason %q", want.ID, output)
+			}
+		}
+
+		if err := applySyncFlows(run, groupArg, have.DataFlow, want.DataFlow); err != nil {
+			return changed, errors.Wrapf(err, "failed to update flows of sync group %q", want.ID)
+		}
+		if err := applySyncPipes(run, groupArg, have.Pipes, want.Pipes); err != nil {
+			return changed, errors.Wrapf(err, "failed to update pipes of sync group %q", want.ID)
+		}
+	}
+
+	// remove the groups that are not in the spec anymore
+	for id := range currentGroups {
+		changed = true
+		logger.Infof("removing sync group %q", id)
+		if output, err := run("sync", "group", "remove", fmt.Sprintf("--group-id=%s", id)); err != nil {
+			return changed, errors.Wrapf(err, "failed to remove sync group %q for reason %q", id, output)
+		}
+	}
+
+	return changed, nil
+}
+
+func applySyncFlows(run func(args ...string) (string, error), groupArg string, have, want syncDataFlowType) error {
+	haveSymmetrical := map[string]syncSymmetricalFlowType{}
+	for _, flow := range have.Symmetrical {
+		flow.Zones = sortedCopy(flow.Zones)
+		haveSymmetrical[flow.ID] = flow
+	}
+	for _, flow := range want.Symmetrical {
+		existing, exists := haveSymmetrical[flow.ID]
+		delete(haveSymmetrical, flow.ID)
+		if exists && reflect.DeepEqual(existing, flow) {
+			continue
+		}
+		// creating an existing flow only adds zones to it, so remove it first to drop the zones not wanted anymore
+		if exists {
+			if err := removeSymmetricalFlow(run, groupArg, existing); err != nil {
+				return err
+			}
+		}
+		args := []string{"sync", "group", "flow", "create", groupArg, fmt.Sprintf("--flow-id=%s", flow.ID), "--flow-type=symmetrical", fmt.Sprintf("--zones=%s", strings.Join(flow.Zones, ","))}
+		if output, err := run(args...); err != nil {
+			return errors.Wrapf(err, "failed to create flow %q for reason %q", flow.ID, output)
+		}
+	}
+	for _, flow := range haveSymmetrical {
+		if err := removeSymmetricalFlow(run, groupArg, flow); err != nil {
+			return err
+		}
+	}
+
+	// directional flows are identified by their source and destination zones
+	haveDirectional := map[syncDirectionalFlowType]bool{}
+	for _, flow := range have.Directional {
+		haveDirectional[flow.zones()] = true
+	}
+	for _, flow := range want.Directional {
+		if haveDirectional[flow.zones()] {
+			delete(haveDirectional, flow.zones())
+			continue
+		}
+		args := []string{"sync", "group", "flow", "create", groupArg, fmt.Sprintf("--flow-id=%s", flow.ID), "--flow-type=directional", fmt.Sprintf("--source-zone=%s", flow.SourceZone), fmt.Sprintf("--dest-zone=%s", flow.DestZone)}
+		if output, err := run(args...); err != nil {
+			return errors.Wrapf(err, "failed to create directional flow %q from %q to %q for reason %q", flow.ID, flow.SourceZone, flow.DestZone, output)
+		}
+	}
+	for flow := range haveDirectional {
+		// the id of the removed flow is unknown, radosgw-admin requires one but removes the flow of the zones
+		args := []string{"sync", "group", "flow", "remove", groupArg, fmt.Sprintf("--flow-id=%s-%s", flow.SourceZone, flow.DestZone), "--flow-type=directional", fmt.Sprintf("--source-zone=%s", flow.SourceZone), fmt.Sprintf("--dest-zone=%s", flow.DestZone)}
+		if output, err := run(args...); err != nil {
+			return errors.Wrapf(err, "failed to remove directional flow from %q to %q for reason %q", flow.SourceZone, flow.DestZone, output)
+		}
+	}
+	return nil
+}
+
+func removeSymmetricalFlow(run func(args ...string) (string, error), groupArg string, flow syncSymmetricalFlowType) error {
+	args := []string{"sync", "group", "flow", "remove", groupArg, fmt.Sprintf("--flow-id=%s", flow.ID), "--flow-type=symmetrical"}
+	if output, err := run(args...); err != nil {
+		return errors.Wrapf(err, "failed to remove flow %q for reason %q", flow.ID, output)
+	}
+	return nil
+}
+
+func applySyncPipes(run func(args ...string) (string, error), groupArg string, have, want []syncPipeType) error {
+	havePipes := map[string]syncPipeType{}
+	for _, pipe := range have {
+		pipe.Source.Zones = sortedCopy(pipe.Source.Zones)
+		pipe.Dest.Zones = sortedCopy(pipe.Dest.Zones)
+		havePipes[pipe.ID] = pipe
+	}
+	for _, pipe := range want {
+		existing, exists := havePipes[pipe.ID]
+		delete(havePipes, pipe.ID)
+		if exists && reflect.DeepEqual(existing, pipe) {
+			continue
+		}
+		// creating an existing pipe only adds zones and buckets to it, so remove it first to drop the ones not wanted
+		// anymore
+		if exists {
+			if err := removeSyncPipe(run, groupArg, existing.ID); err != nil {
+				return err
+			}
+		}
+		args := []string{"sync", "group", "pipe", "create", groupArg, fmt.Sprintf("--pipe-id=%s", pipe.ID),
+			fmt.Sprintf("--source-zones=%s", strings.Join(pipe.Source.Zones, ",")),
+			fmt.Sprintf("--source-bucket=%s", pipe.Source.Bucket),
+			fmt.Sprintf("--dest-zones=%s", strings.Join(pipe.Dest.Zones, ",")),
+			fmt.Sprintf("--dest-bucket=%s", pipe.Dest.Bucket),
+		}
+		if output, err := run(args...); err != nil {
+			return errors.Wrapf(err, "failed to create pipe %q for reason %q", pipe.ID, output)
+		}
+	}
+	for id := range havePipes {
+		if err := removeSyncPipe(run, groupArg, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeSyncPipe(run func(args ...string) (string, error), groupArg, id string) error {
+	if output, err := run("sync", "group", "pipe", "remove", groupArg, fmt.Sprintf("--pipe-id=%s", id)); err != nil {
+		return errors.Wrapf(err, "failed to remove pipe %q for reason %q", id, output)
+	}
+	return nil
+}
+
+// syncGroupsEqual returns whether the groups have the same status, flows and pipes regardless of their order
+func syncGroupsEqual(have, want syncGroupType) bool {
+	if have.Status != want.Status {
+		return false
+	}
+	if len(have.DataFlow.Symmetrical) != len(want.DataFlow.Symmetrical) ||
+		len(have.DataFlow.Directional) != len(want.DataFlow.Directional) ||
+		len(have.Pipes) != len(want.Pipes) {
+		return false
+	}
+
+	symmetrical := map[string][]string{}
+	for _, flow := range have.DataFlow.Symmetrical {
+		symmetrical[flow.ID] = sortedCopy(flow.Zones)
+	}
+	for _, flow := range want.DataFlow.Symmetrical {
+		if zones, ok := symmetrical[flow.ID]; !ok || !reflect.DeepEqual(zones, flow.Zones) {
+			return false
+		}
+	}
+
+	directional := map[syncDirectionalFlowType]bool{}
+	for _, flow := range have.DataFlow.Directional {
+		directional[flow.zones()] = true
+	}
+	for _, flow := range want.DataFlow.Directional {
+		if !directional[flow.zones()] {
+			return false
+		}
+	}
+
+	pipes := map[string]syncPipeType{}
+	for _, pipe := range have.Pipes {
+		pipe.Source.Zones = sortedCopy(pipe.Source.Zones)
+		pipe.Dest.Zones = sortedCopy(pipe.Dest.Zones)
+		pipes[pipe.ID] = pipe
+	}
+	for _, pipe := range want.Pipes {
+		if existing, ok := pipes[pipe.ID]; !ok || !reflect.DeepEqual(existing, pipe) {
+			return false
+		}
+	}
+	return true
+}
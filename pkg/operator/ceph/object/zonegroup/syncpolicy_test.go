@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zonegroup
+
+import (
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const currentSyncPolicy = `{
+    "groups": [
+        {
+            "id": "group1",
+            "data_flow": {
+                "symmetrical": [
+                    {
+                        "id": "mirror",
+                        "zones": ["zone-b", "zone-a"]
+                    }
+                ]
+            },
+            "pipes": [
+                {
+                    "id": "pipe1",
+                    "source": {"bucket": "photos", "zones": ["*"]},
+                    "dest": {"bucket": "photos", "zones": ["*"]}
+                }
+            ],
+            "status": "enabled"
+        },
+        {
+            "id": "old-group",
+            "data_flow": {},
+            "pipes": [],
+            "status": "allowed"
+        }
+    ]
+}`
+
+type commandRecorder struct {
+	commands []string
+}
+
+func (c *commandRecorder) run(args ...string) (string, error) {
+	c.commands = append(c.commands, strings.Join(args, " "))
+	return "", nil
+}
+
+func group1Spec() cephv1.ObjectSyncGroupSpec {
+	return cephv1.ObjectSyncGroupSpec{
+		ID:     "group1",
+		Status: cephv1.ObjectSyncGroupEnabled,
+		Flows: []cephv1.ObjectSyncFlowSpec{
+			{ID: "mirror", Type: cephv1.ObjectSyncFlowSymmetrical, Zones: []string{"zone-a", "zone-b"}},
+		},
+		Pipes: []cephv1.ObjectSyncPipeSpec{
+			{ID: "pipe1", SourceBucket: "photos", DestBucket: "photos"},
+		},
+	}
+}
+
+func TestApplySyncPolicy(t *testing.T) {
+	current, err := decodeSyncPolicy(currentSyncPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(current.Groups))
+
+	// up to date policy only removes the group not in the spec
+	recorder := &commandRecorder{}
+	changed, err := applySyncPolicy(recorder.run, current, &cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group1Spec()}})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"sync group remove --group-id=old-group"}, recorder.commands)
+
+	// nothing to do when the policy matches
+	current.Groups = current.Groups[:1]
+	recorder = &commandRecorder{}
+	changed, err = applySyncPolicy(recorder.run, current, &cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group1Spec()}})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 0, len(recorder.commands))
+
+	// update the status, add a directional flow and change the pipe
+	group := group1Spec()
+	group.Status = cephv1.ObjectSyncGroupAllowed
+	group.Flows = append(group.Flows, cephv1.ObjectSyncFlowSpec{ID: "backup", Type: cephv1.ObjectSyncFlowDirectional, SourceZone: "zone-a", DestZone: "zone-c"})
+	group.Pipes[0].DestZones = []string{"zone-c"}
+	recorder = &commandRecorder{}
+	changed, err = applySyncPolicy(recorder.run, current, &cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group}})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{
+		"sync group modify --group-id=group1 --status=allowed",
+		"sync group flow create --group-id=group1 --flow-id=backup --flow-type=directional --source-zone=zone-a --dest-zone=zone-c",
+		"sync group pipe remove --group-id=group1 --pipe-id=pipe1",
+		"sync group pipe create --group-id=group1 --pipe-id=pipe1 --source-zones=* --source-bucket=photos --dest-zones=zone-c --dest-bucket=photos",
+	}, recorder.commands)
+
+	// the directional flow reported without its id is up to date
+	current.Groups[0].DataFlow.Directional = []syncDirectionalFlowType{{SourceZone: "zone-a", DestZone: "zone-c"}}
+	current.Groups[0].Status = string(cephv1.ObjectSyncGroupAllowed)
+	current.Groups[0].Pipes[0].Dest.Zones = []string{"zone-c"}
+	recorder = &commandRecorder{}
+	changed, err = applySyncPolicy(recorder.run, current, &cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group}})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 0, len(recorder.commands))
+
+	// the pipe is recreated without the zone removed from the spec
+	current.Groups[0].Pipes[0].Dest.Zones = []string{"zone-d", "zone-c"}
+	recorder = &commandRecorder{}
+	changed, err = applySyncPolicy(recorder.run, current, &cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group}})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{
+		"sync group pipe remove --group-id=group1 --pipe-id=pipe1",
+		"sync group pipe create --group-id=group1 --pipe-id=pipe1 --source-zones=* --source-bucket=photos --dest-zones=zone-c --dest-bucket=photos",
+	}, recorder.commands)
+
+	// create a new group
+	recorder = &commandRecorder{}
+	changed, err = applySyncPolicy(recorder.run, syncPolicyType{}, &cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group1Spec()}})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{
+		"sync group create --group-id=group1 --status=enabled",
+		"sync group flow create --group-id=group1 --flow-id=mirror --flow-type=symmetrical --zones=zone-a,zone-b",
+		"sync group pipe create --group-id=group1 --pipe-id=pipe1 --source-zones=* --source-bucket=photos --dest-zones=* --dest-bucket=photos",
+	}, recorder.commands)
+}
+
+func TestValidateSyncPolicy(t *testing.T) {
+	assert.NoError(t, validateSyncPolicy(nil))
+	assert.NoError(t, validateSyncPolicy(&cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group1Spec()}}))
+
+	// invalid status
+	group := group1Spec()
+	group.Status = "on"
+	assert.Error(t, validateSyncPolicy(&cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group}}))
+
+	// duplicate group
+	assert.Error(t, validateSyncPolicy(&cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group1Spec(), group1Spec()}}))
+
+	// symmetrical flow with a single zone
+	group = group1Spec()
+	group.Flows[0].Zones = []string{"zone-a"}
+	assert.Error(t, validateSyncPolicy(&cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group}}))
+
+	// directional flow without a destination
+	group = group1Spec()
+	group.Flows = []cephv1.ObjectSyncFlowSpec{{ID: "backup", Type: cephv1.ObjectSyncFlowDirectional, SourceZone: "zone-a"}}
+	assert.Error(t, validateSyncPolicy(&cephv1.ObjectSyncPolicySpec{Groups: []cephv1.ObjectSyncGroupSpec{group}}))
+}
@@ -47,5 +47,8 @@ func validateZoneGroup(u *cephv1.CephObjectZoneGroup) error {
 	if u.Spec.Realm == "" {
 		return errors.New("missing realm")
 	}
+	if err := validateSyncPolicy(u.Spec.SyncPolicy); err != nil {
+		return errors.Wrap(err, "invalid sync policy")
+	}
 	return nil
 }
@@ -18,9 +18,11 @@ limitations under the License.
 package operator
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
@@ -31,12 +33,14 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/cluster"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
 	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/notification"
 	"github.com/rook/rook/pkg/operator/ceph/provisioner"
 	"github.com/rook/rook/pkg/operator/discover"
 	"github.com/rook/rook/pkg/operator/k8sutil"
-	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/sig-storage-lib-external-provisioner/controller"
 )
@@ -45,6 +49,12 @@ import (
 const (
 	provisionerName       = "ceph.rook.io/block"
 	provisionerNameLegacy = "rook.io/block"
+
+	// the name of the lease of the leader of the operator replicas
+	leaderElectionLeaseName = "rook-ceph-operator"
+
+	// the comma-separated namespaces of the ceph cluster CRs managed by the operator
+	watchNamespacesEnvVar = "ROOK_WATCH_NAMESPACES"
 )
 
 var logger = capnslog.NewPackageLogger("github.com/rook/rook", "operator")
@@ -62,6 +72,21 @@ var (
 	// EnableDiscoveryDaemon Whether to enable the daemon for device discovery. If true, the rook-ceph-discover daemonset will be started.
 	EnableDiscoveryDaemon = true
 
+	// MetricsBindAddress is the address of the metrics endpoint of the operator, "0" disables the endpoint
+	MetricsBindAddress = ":8080"
+
+	// LeaderElection Whether the operator replicas elect a leader with a lease, only the leader manages the clusters
+	LeaderElection = false
+
+	// LeaderElectionLeaseDuration is the duration the standby replicas wait before taking over the lease of the leader
+	LeaderElectionLeaseDuration = 15 * time.Second
+
+	// LeaderElectionRenewDeadline is the duration the leader retries to renew its lease before giving up leading
+	LeaderElectionRenewDeadline = 10 * time.Second
+
+	// LeaderElectionRetryPeriod is the duration the replicas wait between their attempts to acquire or renew the lease
+	LeaderElectionRetryPeriod = 2 * time.Second
+
 	// ImmediateRetryResult Return this for a immediate retry of the reconciliation loop with the same request object.
 	ImmediateRetryResult = reconcile.Result{Requeue: true}
 )
@@ -93,6 +118,7 @@ func New(context *clusterd.Context, volumeAttachmentWrapper attachment.Attachmen
 	}
 	operatorConfigCallbacks := []func() error{
 		o.updateDrivers,
+		o.updateNotifications,
 	}
 	addCallbacks := []func() error{
 		o.startDrivers,
@@ -113,6 +139,13 @@ func (o *Operator) Run() error {
 		return errors.Errorf("rook operator namespace is not provided. expose it via downward API in the rook operator manifest file using environment variable %q", k8sutil.PodNamespaceEnvVar)
 	}
 
+	if !LeaderElection {
+		return o.run()
+	}
+	return o.runWithLeaderElection()
+}
+
+func (o *Operator) run() error {
 	if EnableDiscoveryDaemon {
 		rookDiscover := discover.New(o.context.Clientset)
 		if err := rookDiscover.Start(o.operatorNamespace, o.rookImage, o.securityAccount, true); err != nil {
@@ -125,6 +158,10 @@ func (o *Operator) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "failed to start webhook")
 	}
+	if err := o.updateNotifications(); err != nil {
+		logger.Errorf("failed to configure notifications. %v", err)
+	}
+
 	serverVersion, err := o.context.Clientset.Discovery().ServerVersion()
 	if err != nil {
 		return errors.Wrap(err, "failed to get server version")
@@ -150,21 +187,15 @@ func (o *Operator) Run() error {
 		}
 	}
 
-	var namespaceToWatch string
-	if os.Getenv("ROOK_CURRENT_NAMESPACE_ONLY") == "true" {
-		logger.Infof("watching the current namespace for a ceph cluster CR")
-		namespaceToWatch = o.operatorNamespace
-	} else {
-		logger.Infof("watching all namespaces for ceph cluster CRs")
-		namespaceToWatch = v1.NamespaceAll
-	}
+	namespacesToWatch := o.namespacesToWatch()
+	opcontroller.WatchNamespaces = namespacesToWatch
 
 	// Start the controller-runtime Manager.
 	mgrErrorChan := make(chan error)
-	go o.startManager(namespaceToWatch, stopChan, mgrErrorChan)
+	go o.startManager(namespacesToWatch, stopChan, mgrErrorChan)
 
 	// Start the operator setting watcher
-	go o.clusterController.StartOperatorSettingsWatch(namespaceToWatch, stopChan)
+	go o.clusterController.StartOperatorSettingsWatch(o.operatorNamespace, stopChan)
 
 	// Signal handler to stop the operator
 	for {
@@ -181,6 +212,22 @@ func (o *Operator) Run() error {
 	}
 }
 
+// namespacesToWatch returns the namespaces of the ceph cluster CRs managed by the operator, all the namespaces if
+// empty
+func (o *Operator) namespacesToWatch() []string {
+	if os.Getenv("ROOK_CURRENT_NAMESPACE_ONLY") == "true" {
+		logger.Infof("watching the current namespace for a ceph cluster CR")
+		return []string{o.operatorNamespace}
+	}
+	if list := os.Getenv(watchNamespacesEnvVar); list != "" {
+		namespaces := opcontroller.ParseWatchNamespaces(list, o.operatorNamespace)
+		logger.Infof("watching namespaces %v for ceph cluster CRs", namespaces)
+		return namespaces
+	}
+	logger.Infof("watching all namespaces for ceph cluster CRs")
+	return nil
+}
+
 func (o *Operator) startDrivers() error {
 	if o.delayedDaemonsStarted {
 		return nil
@@ -255,6 +302,11 @@ func (o *Operator) updateDrivers() error {
 	return nil
 }
 
+// updateNotifications configures the notification channel from the operator settings
+func (o *Operator) updateNotifications() error {
+	return notification.SetParams(o.context.Clientset)
+}
+
 // getDeploymentOwnerReference returns an OwnerReference to the rook-ceph-operator deployment
 func getDeploymentOwnerReference(clientset kubernetes.Interface, namespace string) (*metav1.OwnerReference, error) {
 	var deploymentRef *metav1.OwnerReference
@@ -282,3 +334,61 @@ func getDeploymentOwnerReference(clientset kubernetes.Interface, namespace strin
 	}
 	return deploymentRef, nil
 }
+
+// runWithLeaderElection runs the operator once the replica acquired the lease of the leader. The operator exits when it
+// loses the lease so that it does not manage the clusters along with the new leader.
+func (o *Operator) runWithLeaderElection() error {
+	identity := os.Getenv(k8sutil.PodNameEnvVar)
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return errors.Wrap(err, "failed to get the identity of the operator for the leader election")
+		}
+		identity = hostname
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, o.operatorNamespace, leaderElectionLeaseName,
+		o.context.Clientset.CoreV1(), o.context.Clientset.CoordinationV1(), resourcelock.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		return errors.Wrap(err, "failed to create the lock of the leader election")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   LeaderElectionLeaseDuration,
+		RenewDeadline:   LeaderElectionRenewDeadline,
+		RetryPeriod:     LeaderElectionRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leaderElectionLeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				logger.Infof("operator %q acquired the lease %q, starting the operator", identity, leaderElectionLeaseName)
+				runErr <- o.run()
+				// release the lease at once for the standby replicas
+				cancel()
+			},
+			OnStoppedLeading: func() {
+				logger.Infof("operator %q stopped leading", identity)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					logger.Infof("operator %q is the leader, waiting for its lease to expire", leader)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "invalid leader election settings")
+	}
+
+	logger.Infof("operator %q waiting for the lease %q", identity, leaderElectionLeaseName)
+	elector.Run(ctx)
+	select {
+	case err := <-runErr:
+		return err
+	default:
+		return errors.Errorf("operator %q lost the lease %q", identity, leaderElectionLeaseName)
+	}
+}
@@ -19,6 +19,7 @@ package operator
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/agent/flexvolume/attachment"
@@ -43,3 +44,16 @@ func TestOperator(t *testing.T) {
 		}
 	}
 }
+
+func TestLeaderElectionSettings(t *testing.T) {
+	clientset := test.New(t, 1)
+	o := New(&clusterd.Context{Clientset: clientset}, &attachment.MockAttachment{}, "", "")
+	o.operatorNamespace = "rook-ceph"
+	defer func(renewDeadline time.Duration) { LeaderElectionRenewDeadline = renewDeadline }(LeaderElectionRenewDeadline)
+
+	// the leader must renew its lease before the standby replicas take it over
+	LeaderElectionRenewDeadline = LeaderElectionLeaseDuration
+	err := o.runWithLeaderElection()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid leader election settings")
+}
@@ -21,6 +21,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/coreos/pkg/capnslog"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
@@ -36,6 +38,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -65,9 +68,13 @@ var _ reconcile.Reconciler = &ReconcileCephBlockPool{}
 
 // ReconcileCephBlockPool reconciles a CephBlockPool object
 type ReconcileCephBlockPool struct {
-	client  client.Client
-	scheme  *runtime.Scheme
-	context *clusterd.Context
+	client   client.Client
+	recorder record.EventRecorder
+	scheme   *runtime.Scheme
+	context  *clusterd.Context
+	// the status checkers of the pools, by namespace and name, shared by the parallel reconciles of the pools
+	poolChannels      map[string]*poolHealth
+	poolChannelsMutex sync.Mutex
 }
 
 // Add creates a new CephBlockPool Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -84,15 +91,17 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 		panic(err)
 	}
 	return &ReconcileCephBlockPool{
-		client:  mgr.GetClient(),
-		scheme:  mgrScheme,
-		context: context,
+		client:       mgr.GetClient(),
+		recorder:     mgr.GetEventRecorderFor(controllerName),
+		scheme:       mgrScheme,
+		context:      context,
+		poolChannels: make(map[string]*poolHealth),
 	}
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
 	if err != nil {
 		return err
 	}
@@ -113,7 +122,10 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileCephBlockPool) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
+	start := time.Now()
 	reconcileResponse, err := r.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephBlockPool{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephBlockPool{}, request.NamespacedName, reconcileResponse, err)
 	if err != nil {
 		logger.Errorf("failed to reconcile %v", err)
 	}
@@ -176,10 +188,15 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 	// DELETE: the CR was deleted
 	if !cephBlockPool.GetDeletionTimestamp().IsZero() {
 		logger.Debugf("deleting pool %q", cephBlockPool.Name)
-		err := deletePool(r.context, clusterInfo, cephBlockPool)
+		r.stopMonitoring(request.NamespacedName)
+		removed, err := r.deletePoolProgressively(clusterInfo, cephBlockPool)
 		if err != nil {
 			return reconcile.Result{}, errors.Wrapf(err, "failed to delete pool %q. ", cephBlockPool.Name)
 		}
+		if !removed {
+			// Keep the finalizer until the OSDs removed the data of the pool
+			return reconcile.Result{RequeueAfter: poolDeletionCheckInterval}, nil
+		}
 
 		// Remove finalizer
 		err = opcontroller.RemoveFinalizer(r.client, cephBlockPool)
@@ -209,11 +226,8 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 	_, propertyExists := cephBlockPool.Spec.Parameters[cephclient.PgAutoscaleModeProperty]
 	if mgr.IsModuleInSpec(cephCluster.Spec.Mgr.Modules, mgr.PgautoscalerModuleName) &&
 		!cephVersion.IsAtLeastOctopus() &&
-		!propertyExists {
-		if len(cephBlockPool.Spec.Parameters) == 0 {
-			cephBlockPool.Spec.Parameters = make(map[string]string)
-		}
-		cephBlockPool.Spec.Parameters[cephclient.PgAutoscaleModeProperty] = cephclient.PgAutoscaleModeOn
+		!propertyExists && cephBlockPool.Spec.PgAutoscaleMode == "" {
+		cephBlockPool.Spec.PgAutoscaleMode = cephclient.PgAutoscaleModeOn
 	}
 
 	// CREATE/UPDATE
@@ -223,8 +237,23 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 		return reconcileResponse, errors.Wrapf(err, "failed to create pool %q.", cephBlockPool.GetName())
 	}
 
+	// Set the snapshot and trash purge schedules of the images of the pool
+	if err := reconcileRBDSchedules(r.context, clusterInfo, cephVersion, cephBlockPool); err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.ReconcileFailedStatus)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to set the rbd schedules of pool %q", cephBlockPool.GetName())
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
+	opcontroller.ExportEffectiveSpec(r.client, cephBlockPool, cephBlockPool.Spec)
+
+	// Report the usage of the pool in its status
+	healthCheck := cephCluster.Spec.HealthCheck.DaemonHealth.Pool
+	if healthCheck.Disabled {
+		r.stopMonitoring(request.NamespacedName)
+	} else {
+		r.startMonitoring(clusterInfo, request.NamespacedName, healthCheck)
+	}
 
 	// Return and do not requeue
 	logger.Debug("done reconciling")
@@ -286,7 +315,7 @@ func updateStatus(client client.Client, poolName types.NamespacedName, status st
 	}
 
 	if pool.Status == nil {
-		pool.Status = &cephv1.Status{}
+		pool.Status = &cephv1.CephBlockPoolStatus{}
 	}
 
 	pool.Status.Phase = status
@@ -296,3 +325,33 @@ func updateStatus(client client.Client, poolName types.NamespacedName, status st
 	}
 	logger.Debugf("pool %q status updated to %q", poolName, status)
 }
+
+func (r *ReconcileCephBlockPool) startMonitoring(clusterInfo *cephclient.ClusterInfo, poolName types.NamespacedName, healthCheck cephv1.HealthCheckSpec) {
+	r.poolChannelsMutex.Lock()
+	defer r.poolChannelsMutex.Unlock()
+	if r.poolChannels == nil {
+		r.poolChannels = make(map[string]*poolHealth)
+	}
+	key := poolName.String()
+	if _, ok := r.poolChannels[key]; ok {
+		logger.Debugf("status checker of pool %q already running", key)
+		return
+	}
+
+	r.poolChannels[key] = &poolHealth{stopChan: make(chan struct{})}
+	checker := newPoolStatusChecker(r.context, clusterInfo, r.client, r.recorder, poolName, healthCheck)
+	logger.Infof("starting status checker of pool %q", key)
+	go checker.checkPoolStatus(r.poolChannels[key].stopChan)
+}
+
+func (r *ReconcileCephBlockPool) stopMonitoring(poolName types.NamespacedName) {
+	r.poolChannelsMutex.Lock()
+	defer r.poolChannelsMutex.Unlock()
+	key := poolName.String()
+	health, ok := r.poolChannels[key]
+	if !ok {
+		return
+	}
+	close(health.stopChan)
+	delete(r.poolChannels, key)
+}
@@ -25,6 +25,7 @@ import (
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	testop "github.com/rook/rook/pkg/operator/test"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
@@ -39,7 +40,15 @@ import (
 )
 
 func TestValidatePool(t *testing.T) {
-	context := &clusterd.Context{Executor: &exectest.MockExecutor{}}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "crush" && args[2] == "dump" {
+				return clienttest.CrushDumpResponse(3), nil
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
 	clusterInfo := &client.ClusterInfo{Namespace: "myns"}
 
 	// not specifying some replication or EC settings is fine
@@ -121,6 +130,78 @@ func TestValidatePool(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestValidateErasureCodeCrush(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "crush" && args[2] == "dump" {
+				return clienttest.CrushDumpResponse(3), nil
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := &client.ClusterInfo{Namespace: "myns"}
+
+	// the chunks are placed on the 3 hosts
+	p := &cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}
+	assert.NoError(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.ErasureCoded.CodingChunks = 2
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+
+	// the failure domain of the erasure code overrides the failure domain of the pool
+	p.FailureDomain = "host"
+	p.ErasureCoded.FailureDomain = "osd"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.ErasureCoded.CodingChunks = 1
+	assert.NoError(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.ErasureCoded.FailureDomain = "rack"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.ErasureCoded.FailureDomain = "zone"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+
+	// the device class and the crush root must exist
+	p.ErasureCoded.FailureDomain = ""
+	p.ErasureCoded.DeviceClass = "hdd"
+	assert.NoError(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.ErasureCoded.DeviceClass = "ssd"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.ErasureCoded.DeviceClass = ""
+	p.ErasureCoded.CrushRoot = "fast"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+}
+
+func TestValidateHybridStorage(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "osd" && args[1] == "crush" && args[2] == "dump" {
+				return `{"devices":[{"id":0,"name":"osd.0","class":"ssd"},{"id":1,"name":"osd.1","class":"hdd"}]}`, nil
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := &client.ClusterInfo{Namespace: "myns"}
+
+	p := &cephv1.PoolSpec{Replicated: cephv1.ReplicatedSpec{
+		Size:          3,
+		HybridStorage: &cephv1.HybridStorageSpec{PrimaryDeviceClass: "ssd", SecondaryDeviceClass: "hdd"},
+	}}
+	assert.NoError(t, ValidatePoolSpec(context, clusterInfo, p))
+
+	// the device classes must exist and differ
+	p.Replicated.HybridStorage.SecondaryDeviceClass = "nvme"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.Replicated.HybridStorage.SecondaryDeviceClass = "ssd"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+	p.Replicated.HybridStorage.SecondaryDeviceClass = ""
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+
+	// the device class of the pool cannot be set
+	p.Replicated.HybridStorage.SecondaryDeviceClass = "hdd"
+	p.DeviceClass = "ssd"
+	assert.Error(t, ValidatePoolSpec(context, clusterInfo, p))
+}
+
 func TestValidateCrushProperties(t *testing.T) {
 	executor := &exectest.MockExecutor{}
 	context := &clusterd.Context{Executor: executor}
@@ -266,7 +347,7 @@ func TestCephBlockPoolController(t *testing.T) {
 				Size: replicas,
 			},
 		},
-		Status: &cephv1.Status{
+		Status: &cephv1.CephBlockPoolStatus{
 			Phase: "",
 		},
 	}
@@ -0,0 +1,228 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Time in seconds the OSDs sleep between the removal transactions of the PGs of a deleted pool
+	poolDeleteSleep = "2"
+	// the removal of the data of a deleted pool is checked at this interval
+	poolDeletionCheckInterval = 30 * time.Second
+	// the removal is considered complete when the OSDs have not released space for this long, the space released
+	// by the removal may be hidden by the writes to the other pools
+	poolDeletionStallTimeout = 10 * time.Minute
+	// the configmap of the pools being deleted and of the values of the pool deletion flags before the deletions
+	poolDeletionConfigMapName = "rook-ceph-pool-deletion"
+)
+
+// the settings applied while the data of a deleted pool is removed, their previous values are restored afterwards
+var poolDeletionFlags = []opconfig.Option{
+	{Who: "mon", Option: "mon_allow_pool_delete", Value: "true"},
+	{Who: "osd", Option: "osd_delete_sleep", Value: poolDeleteSleep},
+}
+
+// poolDeletionMutex serializes the updates of the pool deletion configmap by the parallel reconciles of the pools
+var poolDeletionMutex sync.Mutex
+
+// deletePoolProgressively deletes the pool and tracks the removal of its data by the OSDs in the status of the CR.
+// The removal is throttled to avoid stalling the OSDs. It returns true once the data is removed.
+func (r *ReconcileCephBlockPool) deletePoolProgressively(clusterInfo *cephclient.ClusterInfo, p *cephv1.CephBlockPool) (bool, error) {
+	stats, err := cephclient.GetPoolStats(r.context, clusterInfo)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get pool stats")
+	}
+	clusterUsed := uint64(stats.Stats.TotalUsedRawBytes)
+	now := metav1.Now()
+
+	for _, pool := range stats.Pools {
+		if pool.Name != p.Name {
+			continue
+		}
+		if err := startPoolDeletion(r.context, clusterInfo, p.Name); err != nil {
+			return false, err
+		}
+		if err := deletePool(r.context, clusterInfo, p); err != nil {
+			return false, err
+		}
+		deletion := &cephv1.PoolDeletionStatus{
+			StartTime:           now,
+			LastProgressTime:    now,
+			ClusterRawBytesUsed: clusterUsed,
+			RawBytesToRemove:    uint64(pool.Stats.BytesUsed),
+		}
+		logger.Infof("pool %q deleted, waiting for the OSDs to remove %d bytes", p.Name, deletion.RawBytesToRemove)
+		updateDeletionStatus(r.client, p, deletion)
+		return false, nil
+	}
+
+	if p.Status == nil || p.Status.Deletion == nil {
+		// the pool does not exist or was deleted before its removal was tracked
+		return true, completePoolDeletion(r.context, clusterInfo, p.Name)
+	}
+
+	deletion := p.Status.Deletion.DeepCopy()
+	removed := uint64(0)
+	if clusterUsed < deletion.ClusterRawBytesUsed {
+		removed = deletion.ClusterRawBytesUsed - clusterUsed
+	}
+	if removed > deletion.RawBytesToRemove {
+		removed = deletion.RawBytesToRemove
+	}
+	if removed > deletion.RawBytesRemoved {
+		deletion.LastProgressTime = now
+	}
+	deletion.RawBytesRemoved = removed
+
+	if removed < deletion.RawBytesToRemove && now.Sub(deletion.LastProgressTime.Time) < poolDeletionStallTimeout {
+		logger.Infof("OSDs removed %d/%d bytes of deleted pool %q", removed, deletion.RawBytesToRemove, p.Name)
+		updateDeletionStatus(r.client, p, deletion)
+		return false, nil
+	}
+
+	logger.Infof("OSDs removed the data of deleted pool %q in %s", p.Name, now.Sub(deletion.StartTime.Time).Round(time.Second))
+	return true, completePoolDeletion(r.context, clusterInfo, p.Name)
+}
+
+func poolDeletionFlagKey(flag opconfig.Option) string {
+	return fmt.Sprintf("flag.%s.%s", flag.Who, flag.Option)
+}
+
+func poolDeletionPoolKey(poolName string) string {
+	return "pool." + poolName
+}
+
+// startPoolDeletion records the pool in the pool deletion configmap and sets the pool deletion flags. The values of the
+// flags in the mon store are saved by the first deletion, when no other pool is being deleted.
+func startPoolDeletion(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolName string) error {
+	poolDeletionMutex.Lock()
+	defer poolDeletionMutex.Unlock()
+
+	monStore := opconfig.GetMonStore(context, clusterInfo)
+	configMaps := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace)
+	cm, err := configMaps.Get(poolDeletionConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get the pool deletion configmap")
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: poolDeletionConfigMapName, Namespace: clusterInfo.Namespace},
+			Data:       map[string]string{poolDeletionPoolKey(poolName): ""},
+		}
+		k8sutil.SetOwnerRef(&cm.ObjectMeta, &clusterInfo.OwnerRef)
+		for _, flag := range poolDeletionFlags {
+			options, err := monStore.GetDaemon(flag.Who)
+			if err != nil {
+				return errors.Wrapf(err, "failed to save %q on %q", flag.Option, flag.Who)
+			}
+			// the flags not set in the mon store are removed afterwards
+			for _, option := range options {
+				if option.Option == flag.Option {
+					cm.Data[poolDeletionFlagKey(flag)] = option.Value
+				}
+			}
+		}
+		if _, err := configMaps.Create(cm); err != nil {
+			return errors.Wrap(err, "failed to create the pool deletion configmap")
+		}
+	} else if _, ok := cm.Data[poolDeletionPoolKey(poolName)]; !ok {
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[poolDeletionPoolKey(poolName)] = ""
+		if _, err := configMaps.Update(cm); err != nil {
+			return errors.Wrap(err, "failed to update the pool deletion configmap")
+		}
+	}
+
+	for _, flag := range poolDeletionFlags {
+		if err := monStore.Set(flag.Who, flag.Option, flag.Value); err != nil {
+			return errors.Wrapf(err, "failed to set %q to %q on %q", flag.Option, flag.Value, flag.Who)
+		}
+	}
+	return nil
+}
+
+// completePoolDeletion removes the pool from the pool deletion configmap, and restores the saved values of the pool
+// deletion flags once no other pool is being deleted
+func completePoolDeletion(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolName string) error {
+	poolDeletionMutex.Lock()
+	defer poolDeletionMutex.Unlock()
+
+	configMaps := context.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace)
+	cm, err := configMaps.Get(poolDeletionConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// no pool deletion is in progress
+			return nil
+		}
+		return errors.Wrap(err, "failed to get the pool deletion configmap")
+	}
+	delete(cm.Data, poolDeletionPoolKey(poolName))
+	for key := range cm.Data {
+		if strings.HasPrefix(key, poolDeletionPoolKey("")) {
+			logger.Infof("keeping the pool deletion flags while other pools are being deleted")
+			if _, err := configMaps.Update(cm); err != nil {
+				return errors.Wrap(err, "failed to update the pool deletion configmap")
+			}
+			return nil
+		}
+	}
+
+	monStore := opconfig.GetMonStore(context, clusterInfo)
+	for _, flag := range poolDeletionFlags {
+		if value, ok := cm.Data[poolDeletionFlagKey(flag)]; ok {
+			if err := monStore.Set(flag.Who, flag.Option, value); err != nil {
+				return errors.Wrapf(err, "failed to restore %q to %q on %q", flag.Option, value, flag.Who)
+			}
+		} else if err := monStore.Delete(flag.Who, flag.Option); err != nil {
+			return errors.Wrapf(err, "failed to restore %q on %q", flag.Option, flag.Who)
+		}
+	}
+	if err := configMaps.Delete(poolDeletionConfigMapName, &metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete the pool deletion configmap")
+	}
+	return nil
+}
+
+// updateDeletionStatus updates the deletion progress in the status of a pool CR
+func updateDeletionStatus(client client.Client, p *cephv1.CephBlockPool, deletion *cephv1.PoolDeletionStatus) {
+	if p.Status == nil {
+		p.Status = &cephv1.CephBlockPoolStatus{}
+	}
+	p.Status.Deletion = deletion
+	if err := opcontroller.UpdateStatus(client, p); err != nil {
+		logger.Warningf("failed to update the deletion status of pool %q. %v", p.Name, err)
+	}
+}
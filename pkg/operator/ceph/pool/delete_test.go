@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeletePoolProgressively(t *testing.T) {
+	clusterInfo := &client.ClusterInfo{Namespace: "myns"}
+	poolExists := true
+	clusterUsed := 5000
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			switch {
+			case args[0] == "df":
+				pools := ""
+				if poolExists {
+					pools = `{"name":"mypool","id":1,"stats":{"bytes_used":1000}}`
+				}
+				return fmt.Sprintf(`{"stats":{"total_used_raw_bytes":%d},"pools":[%s]}`, clusterUsed, pools), nil
+			case args[0] == "osd" && args[1] == "lspools":
+				return `[{"poolnum":1,"poolname":"mypool"}]`, nil
+			case args[0] == "osd" && args[1] == "pool" && args[2] == "get":
+				return `{"pool": "mypool","pool_id": 1,"size":1}`, nil
+			case args[0] == "config" && args[1] == "get":
+				return monStoreOptions(args[2]), nil
+			case args[0] == "config" && args[1] == "set":
+				commands = append(commands, strings.Join(args[:5], " "))
+			case args[0] == "config" || (args[0] == "osd" && args[2] == "delete"):
+				commands = append(commands, strings.Join(args[:4], " "))
+			}
+			return "", nil
+		},
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			return `{"images":{"count":0,"provisioned_bytes":0,"snap_count":0},"trash":{"count":0,"provisioned_bytes":0,"snap_count":0}}`, nil
+		},
+	}
+	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, p)
+	cl := fake.NewFakeClient(p)
+	r := &ReconcileCephBlockPool{client: cl, scheme: s, context: &clusterd.Context{Executor: executor, Clientset: k8sfake.NewSimpleClientset()}}
+	getPool := func() *cephv1.CephBlockPool {
+		pool := &cephv1.CephBlockPool{}
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: "mypool", Namespace: "myns"}, pool)
+		assert.NoError(t, err)
+		return pool
+	}
+
+	// the pool is deleted with the deletion flags
+	removed, err := r.deletePoolProgressively(clusterInfo, getPool())
+	assert.NoError(t, err)
+	assert.False(t, removed)
+	assert.Equal(t, []string{"config set mon mon_allow_pool_delete true", "config set osd osd_delete_sleep 2", "osd pool delete mypool"}, commands)
+	deletion := getPool().Status.Deletion
+	assert.Equal(t, uint64(5000), deletion.ClusterRawBytesUsed)
+	assert.Equal(t, uint64(1000), deletion.RawBytesToRemove)
+
+	// the OSDs are removing the data
+	poolExists = false
+	clusterUsed = 4600
+	commands = []string{}
+	removed, err = r.deletePoolProgressively(clusterInfo, getPool())
+	assert.NoError(t, err)
+	assert.False(t, removed)
+	assert.Equal(t, uint64(400), getPool().Status.Deletion.RawBytesRemoved)
+	assert.Equal(t, 0, len(commands))
+
+	// the flags are restored to their previous values once the data is removed
+	clusterUsed = 3900
+	removed, err = r.deletePoolProgressively(clusterInfo, getPool())
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, []string{"config set mon mon_allow_pool_delete false", "config rm osd osd_delete_sleep"}, commands)
+
+	// the removal is complete when the OSDs do not release space anymore
+	pool := getPool()
+	pool.Status.Deletion.RawBytesRemoved = 400
+	pool.Status.Deletion.LastProgressTime = metav1.NewTime(time.Now().Add(-poolDeletionStallTimeout))
+	clusterUsed = 4600
+	removed, err = r.deletePoolProgressively(clusterInfo, pool)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	// nothing to wait for if the removal was not tracked
+	removed, err = r.deletePoolProgressively(clusterInfo, &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "otherpool", Namespace: "myns"}})
+	assert.NoError(t, err)
+	assert.True(t, removed)
+}
+
+// monStoreOptions returns the options of the mon store of a daemon type, with mon_allow_pool_delete set to false
+func monStoreOptions(who string) string {
+	if who == "mon" {
+		return `{"mon_allow_pool_delete":{"value":"false","section":"mon"}}`
+	}
+	return "{}"
+}
+
+func TestPoolDeletionFlags(t *testing.T) {
+	clusterInfo := &client.ClusterInfo{Namespace: "myns"}
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			switch {
+			case args[1] == "get":
+				return monStoreOptions(args[2]), nil
+			case args[1] == "set":
+				commands = append(commands, strings.Join(args[:5], " "))
+			case args[1] == "rm":
+				commands = append(commands, strings.Join(args[:4], " "))
+			}
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor, Clientset: k8sfake.NewSimpleClientset()}
+
+	// the flags are kept while another pool is being deleted
+	assert.NoError(t, startPoolDeletion(context, clusterInfo, "a"))
+	assert.NoError(t, startPoolDeletion(context, clusterInfo, "b"))
+	commands = []string{}
+	assert.NoError(t, completePoolDeletion(context, clusterInfo, "a"))
+	assert.Empty(t, commands)
+
+	// the values before the first deletion are restored by the last one
+	assert.NoError(t, completePoolDeletion(context, clusterInfo, "b"))
+	assert.Equal(t, []string{"config set mon mon_allow_pool_delete false", "config rm osd osd_delete_sleep"}, commands)
+	_, err := context.Clientset.CoreV1().ConfigMaps("myns").Get(poolDeletionConfigMapName, metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+
+	// nothing to restore without a deletion
+	commands = []string{}
+	assert.NoError(t, completePoolDeletion(context, clusterInfo, "c"))
+	assert.Empty(t, commands)
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultStatusCheckInterval = 60 * time.Second
+
+	defaultQuotaWarningThresholdPercent = 80
+)
+
+type poolHealth struct {
+	stopChan chan struct{}
+}
+
+// poolStatusChecker reports the usage and the placement groups of a pool in the status of the CR
+type poolStatusChecker struct {
+	context        *clusterd.Context
+	clusterInfo    *cephclient.ClusterInfo
+	client         client.Client
+	recorder       record.EventRecorder
+	namespacedName types.NamespacedName
+	interval       time.Duration
+}
+
+// newPoolStatusChecker creates a checker of the status of a pool
+func newPoolStatusChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, client client.Client, recorder record.EventRecorder, namespacedName types.NamespacedName, healthCheck cephv1.HealthCheckSpec) *poolStatusChecker {
+	c := &poolStatusChecker{
+		context:        context,
+		clusterInfo:    clusterInfo,
+		client:         client,
+		recorder:       recorder,
+		namespacedName: namespacedName,
+		interval:       defaultStatusCheckInterval,
+	}
+
+	// allow overriding the check interval
+	if healthCheck.Interval != "" {
+		if duration, err := time.ParseDuration(healthCheck.Interval); err == nil {
+			logger.Infof("pool %q status check interval %q", namespacedName.String(), healthCheck.Interval)
+			c.interval = duration
+		}
+	}
+	return c
+}
+
+// checkPoolStatus periodically updates the status of the pool
+func (c *poolStatusChecker) checkPoolStatus(stopCh chan struct{}) {
+	// check the status immediately before starting the loop
+	c.checkStatus()
+
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping monitoring of pool %q", c.namespacedName.String())
+			return
+
+		case <-time.After(c.interval):
+			logger.Debugf("checking status of pool %q", c.namespacedName.String())
+			c.checkStatus()
+		}
+	}
+}
+
+func (c *poolStatusChecker) checkStatus() {
+	c.resetPoolProperties()
+	usage, poolID, err := c.poolUsage()
+	if err != nil {
+		logger.Warningf("failed to check the status of pool %q. %v", c.namespacedName.String(), err)
+		return
+	}
+	// the operations in progress are not refreshed if they cannot be retrieved
+	progress, err := cephclient.GetProgressEvents(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Warningf("failed to get the operations in progress of pool %q. %v", c.namespacedName.String(), err)
+	} else {
+		progress = progress.PoolEvents(poolID)
+	}
+	updateStatusUsage(c.client, c.recorder, c.namespacedName, usage, progress)
+}
+
+// resetPoolProperties resets the properties of the pool changed out-of-band since the reconcile of the CR
+func (c *poolStatusChecker) resetPoolProperties() {
+	pool := &cephv1.CephBlockPool{}
+	if err := c.client.Get(context.TODO(), c.namespacedName, pool); err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Warningf("failed to get pool %q to check its properties. %v", c.namespacedName.String(), err)
+		}
+		return
+	}
+	updated, err := cephclient.UpdatePoolProperties(c.context, c.clusterInfo, pool.Name, pool.Spec)
+	if err != nil {
+		logger.Warningf("failed to reset the properties of pool %q. %v", c.namespacedName.String(), err)
+		return
+	}
+	if len(updated) > 0 {
+		logger.Infof("reset the properties %v of pool %q changed out-of-band", updated, c.namespacedName.String())
+	}
+}
+
+// poolUsage collects the usage, the placement groups and the data protection of the pool, and returns them with the
+// id of the pool
+func (c *poolStatusChecker) poolUsage() (*cephv1.PoolUsageStatus, int, error) {
+	name := c.namespacedName.Name
+	usage := &cephv1.PoolUsageStatus{LastUpdated: time.Now().UTC().Format(time.RFC3339)}
+
+	stats, err := cephclient.GetPoolStats(c.context, c.clusterInfo)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, pool := range stats.Pools {
+		if pool.Name == name {
+			usage.BytesStored = uint64(pool.Stats.Stored)
+			usage.BytesUsed = uint64(pool.Stats.BytesUsed)
+			usage.BytesAvailable = uint64(pool.Stats.MaxAvail)
+			usage.Objects = uint64(pool.Stats.Objects)
+		}
+	}
+
+	details, err := cephclient.GetPoolDetails(c.context, c.clusterInfo, name)
+	if err != nil {
+		return nil, 0, err
+	}
+	usage.PGCount = details.PgNum
+	usage.CrushRule = details.CrushRule
+	if details.ErasureCodeProfile != "" {
+		profile, err := cephclient.GetErasureCodeProfileDetails(c.context, c.clusterInfo, details.ErasureCodeProfile)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to get erasure code profile %q", details.ErasureCodeProfile)
+		}
+		usage.ErasureCoded = &cephv1.PoolErasureCodeStatus{
+			Profile:      details.ErasureCodeProfile,
+			DataChunks:   profile.DataChunkCount,
+			CodingChunks: profile.CodingChunkCount,
+			Plugin:       profile.Plugin,
+		}
+	} else {
+		usage.Replicated = &cephv1.PoolReplicationStatus{Size: details.Size, MinSize: details.MinSize}
+	}
+
+	usage.PGStates, err = cephclient.GetPoolPGStates(c.context, c.clusterInfo, name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return usage, details.Number, nil
+}
+
+// updateStatusUsage sets the usage, the quota condition and the operations in progress in the status of a pool CR,
+// and emits an event when the usage of a quota crosses its warning threshold and when an operation of the pool starts
+// or completes. The operations in progress are kept when the progress is nil.
+func updateStatusUsage(client client.Client, recorder record.EventRecorder, poolName types.NamespacedName, usage *cephv1.PoolUsageStatus, progress *cephclient.ProgressEvents) {
+	pool := &cephv1.CephBlockPool{}
+	if err := client.Get(context.TODO(), poolName, pool); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPool resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve pool %q to update its usage. %v", poolName, err)
+		return
+	}
+
+	if pool.Status == nil {
+		pool.Status = &cephv1.CephBlockPoolStatus{}
+	}
+	pool.Status.Usage = usage
+	previousProgress := pool.Status.Progress
+	if progress != nil {
+		pool.Status.Progress = opcontroller.ProgressStatus(progress)
+	}
+	condition := quotaCondition(pool.Spec.Quotas, usage)
+	nearFull := setQuotaCondition(pool.Status, condition)
+	if err := opcontroller.UpdateStatus(client, pool); err != nil {
+		logger.Warningf("failed to update the usage of pool %q. %v", pool.Name, err)
+		return
+	}
+	logger.Debugf("pool %q usage updated", poolName)
+
+	if nearFull {
+		logger.Warningf("pool %q is near full. %s", poolName.String(), condition.Message)
+		if recorder != nil {
+			recorder.Event(pool, v1.EventTypeWarning, opcontroller.EventReasonPoolQuotaNearFull, condition.Message)
+		}
+	}
+	opcontroller.ReportProgress(recorder, pool, previousProgress, progress)
+}
+
+// quotaCondition returns the condition of the usage of the quotas of a pool, nil if the pool has no quota
+func quotaCondition(quotas cephv1.QuotaSpec, usage *cephv1.PoolUsageStatus) *cephv1.Condition {
+	hasQuota := func(quota *uint64) bool { return quota != nil && *quota > 0 }
+	if !hasQuota(quotas.MaxBytes) && !hasQuota(quotas.MaxObjects) {
+		return nil
+	}
+	threshold := defaultQuotaWarningThresholdPercent
+	if quotas.WarningThresholdPercent != nil {
+		threshold = *quotas.WarningThresholdPercent
+	}
+
+	// the stored bytes are not reported before nautilus 14.2.2, the used bytes are the stored bytes then
+	stored := usage.BytesStored
+	if stored == 0 {
+		stored = usage.BytesUsed
+	}
+	nearFull := []string{}
+	if hasQuota(quotas.MaxBytes) {
+		if percent := 100 * float64(stored) / float64(*quotas.MaxBytes); percent >= float64(threshold) {
+			nearFull = append(nearFull, fmt.Sprintf("%.0f%% of its quota of %d bytes", percent, *quotas.MaxBytes))
+		}
+	}
+	if hasQuota(quotas.MaxObjects) {
+		if percent := 100 * float64(usage.Objects) / float64(*quotas.MaxObjects); percent >= float64(threshold) {
+			nearFull = append(nearFull, fmt.Sprintf("%.0f%% of its quota of %d objects", percent, *quotas.MaxObjects))
+		}
+	}
+
+	if len(nearFull) == 0 {
+		return &cephv1.Condition{
+			Type:    cephv1.ConditionQuotaNearFull,
+			Status:  v1.ConditionFalse,
+			Reason:  "QuotaBelowThreshold",
+			Message: fmt.Sprintf("the usage of the quotas is below %d%%", threshold),
+		}
+	}
+	return &cephv1.Condition{
+		Type:    cephv1.ConditionQuotaNearFull,
+		Status:  v1.ConditionTrue,
+		Reason:  "QuotaAboveThreshold",
+		Message: fmt.Sprintf("the pool uses %s", strings.Join(nearFull, " and ")),
+	}
+}
+
+// setQuotaCondition sets the quota condition in the status of a pool, or removes it if the condition is nil, and
+// returns whether the pool became near full
+func setQuotaCondition(status *cephv1.CephBlockPoolStatus, condition *cephv1.Condition) bool {
+	conditions := []cephv1.Condition{}
+	var existing *cephv1.Condition
+	for i, c := range status.Conditions {
+		if c.Type == cephv1.ConditionQuotaNearFull {
+			existing = &status.Conditions[i]
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	if condition == nil {
+		status.Conditions = conditions
+		return false
+	}
+
+	now := metav1.NewTime(time.Now())
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+	if existing != nil && existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+	status.Conditions = append(conditions, *condition)
+	return condition.Status == v1.ConditionTrue && (existing == nil || existing.Status != v1.ConditionTrue)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPoolStatusChecker(t *testing.T) {
+	erasureCoded := false
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			switch {
+			case args[0] == "df":
+				return `{"stats":{"total_used_raw_bytes":5000},"pools":[{"name":"mypool","id":1,"stats":{"bytes_used":1000,"max_avail":9000,"objects":42}}]}`, nil
+			case args[0] == "osd" && args[1] == "pool" && args[2] == "get":
+				if erasureCoded {
+					return `{"pool":"mypool","pool_id":1,"size":3}{"pool":"mypool","min_size":2}{"pool":"mypool","pg_num":8}{"pool":"mypool","crush_rule":"mypool"}{"pool":"mypool","erasure_code_profile":"mypool_ecprofile"}`, nil
+				}
+				return `{"pool":"mypool","pool_id":1,"size":3}{"pool":"mypool","min_size":2}{"pool":"mypool","pg_num":8}{"pool":"mypool","crush_rule":"replicated_rule"}`, nil
+			case args[0] == "osd" && args[1] == "erasure-code-profile":
+				return `{"k":"2","m":"1","plugin":"jerasure","technique":"reed_sol_van"}`, nil
+			case args[0] == "pg" && args[1] == "ls-by-pool":
+				return `{"pg_ready":true,"pg_stats":[{"pgid":"1.0","state":"active+clean"},{"pgid":"1.1","state":"active+clean"},{"pgid":"1.2","state":"active+undersized"}]}`, nil
+			case args[0] == "progress" && args[1] == "json":
+				return `{"events":[{"id":"a","message":"PG autoscaler increasing pool 1 PGs from 8 to 32","refs":[["pool",1]],"progress":0.5},
+					{"id":"b","message":"PG autoscaler increasing pool 2 PGs from 8 to 32","refs":[["pool",2]],"progress":0.5}],"completed":[]}`, nil
+			}
+			return "", nil
+		},
+	}
+	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: "myns"}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, p)
+	cl := fake.NewFakeClient(p)
+	name := types.NamespacedName{Name: "mypool", Namespace: "myns"}
+	c := newPoolStatusChecker(&clusterd.Context{Executor: executor}, &client.ClusterInfo{Namespace: "myns"}, cl, nil, name, cephv1.HealthCheckSpec{Interval: "10s"})
+	assert.Equal(t, 10*time.Second, c.interval)
+
+	// the usage of a replicated pool is reported in the status
+	c.checkStatus()
+	pool := &cephv1.CephBlockPool{}
+	assert.NoError(t, cl.Get(context.TODO(), name, pool))
+	usage := pool.Status.Usage
+	assert.Equal(t, uint64(1000), usage.BytesUsed)
+	assert.Equal(t, uint64(9000), usage.BytesAvailable)
+	assert.Equal(t, uint64(42), usage.Objects)
+	assert.Equal(t, 8, usage.PGCount)
+	assert.Equal(t, map[string]int{"active+clean": 2, "active+undersized": 1}, usage.PGStates)
+	assert.Equal(t, "replicated_rule", usage.CrushRule)
+	assert.Equal(t, &cephv1.PoolReplicationStatus{Size: 3, MinSize: 2}, usage.Replicated)
+	assert.Nil(t, usage.ErasureCoded)
+	assert.NotEqual(t, "", usage.LastUpdated)
+	assert.Equal(t, []cephv1.ProgressEventStatus{{ID: "a", Message: "PG autoscaler increasing pool 1 PGs from 8 to 32", Progress: "50%"}}, pool.Status.Progress)
+
+	// the effective erasure code profile of an erasure coded pool
+	erasureCoded = true
+	usage, poolID, err := c.poolUsage()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, poolID)
+	assert.Nil(t, usage.Replicated)
+	assert.Equal(t, &cephv1.PoolErasureCodeStatus{Profile: "mypool_ecprofile", DataChunks: 2, CodingChunks: 1, Plugin: "jerasure"}, usage.ErasureCoded)
+}
+
+func TestPoolQuotaCondition(t *testing.T) {
+	maxBytes := uint64(1000)
+	maxObjects := uint64(100)
+	usage := &cephv1.PoolUsageStatus{BytesStored: 500, BytesUsed: 1500, Objects: 90}
+
+	// a pool without quotas has no quota condition
+	assert.Nil(t, quotaCondition(cephv1.QuotaSpec{}, usage))
+	zero := uint64(0)
+	assert.Nil(t, quotaCondition(cephv1.QuotaSpec{MaxBytes: &zero}, usage))
+
+	// the stored bytes are compared to the quota
+	condition := quotaCondition(cephv1.QuotaSpec{MaxBytes: &maxBytes}, usage)
+	assert.Equal(t, v1.ConditionFalse, condition.Status)
+	threshold := 50
+	condition = quotaCondition(cephv1.QuotaSpec{MaxBytes: &maxBytes, WarningThresholdPercent: &threshold}, usage)
+	assert.Equal(t, v1.ConditionTrue, condition.Status)
+	assert.Equal(t, "the pool uses 50% of its quota of 1000 bytes", condition.Message)
+
+	// the objects are above the default threshold
+	condition = quotaCondition(cephv1.QuotaSpec{MaxBytes: &maxBytes, MaxObjects: &maxObjects}, usage)
+	assert.Equal(t, v1.ConditionTrue, condition.Status)
+	assert.Equal(t, "the pool uses 90% of its quota of 100 objects", condition.Message)
+
+	// an event is emitted when the pool becomes near full
+	status := &cephv1.CephBlockPoolStatus{Conditions: []cephv1.Condition{{Type: cephv1.ConditionReady, Status: v1.ConditionTrue}}}
+	assert.True(t, setQuotaCondition(status, condition))
+	assert.Equal(t, 2, len(status.Conditions))
+	condition = quotaCondition(cephv1.QuotaSpec{MaxBytes: &maxBytes, MaxObjects: &maxObjects}, usage)
+	assert.False(t, setQuotaCondition(status, condition))
+	assert.Equal(t, 2, len(status.Conditions))
+	condition = quotaCondition(cephv1.QuotaSpec{MaxBytes: &maxBytes}, usage)
+	assert.False(t, setQuotaCondition(status, condition))
+	assert.Equal(t, v1.ConditionFalse, status.Conditions[1].Status)
+
+	// the condition is removed with the quotas
+	assert.False(t, setQuotaCondition(status, nil))
+	assert.Equal(t, []cephv1.Condition{{Type: cephv1.ConditionReady, Status: v1.ConditionTrue}}, status.Conditions)
+}
+
+func TestPoolQuotaEvent(t *testing.T) {
+	maxObjects := uint64(50)
+	p := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: "myns"},
+		Spec:       cephv1.PoolSpec{Quotas: cephv1.QuotaSpec{MaxObjects: &maxObjects}},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, p)
+	cl := fake.NewFakeClient(p)
+	recorder := record.NewFakeRecorder(5)
+	name := types.NamespacedName{Name: "mypool", Namespace: "myns"}
+
+	updateStatusUsage(cl, recorder, name, &cephv1.PoolUsageStatus{Objects: 42}, nil)
+	pool := &cephv1.CephBlockPool{}
+	assert.NoError(t, cl.Get(context.TODO(), name, pool))
+	assert.Equal(t, cephv1.ConditionQuotaNearFull, pool.Status.Conditions[0].Type)
+	assert.Equal(t, v1.ConditionTrue, pool.Status.Conditions[0].Status)
+	assert.Equal(t, "Warning PoolQuotaNearFull the pool uses 84% of its quota of 50 objects", <-recorder.Events)
+
+	// the event is emitted once
+	updateStatusUsage(cl, recorder, name, &cephv1.PoolUsageStatus{Objects: 45}, nil)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestPoolProgress(t *testing.T) {
+	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: "myns"}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, p)
+	cl := fake.NewFakeClient(p)
+	recorder := record.NewFakeRecorder(5)
+	name := types.NamespacedName{Name: "mypool", Namespace: "myns"}
+	events := &client.ProgressEvents{Events: []client.ProgressEvent{
+		{ID: "a", Message: "PG autoscaler increasing pool 2 PGs from 32 to 128", Progress: 0.5, Refs: [][]interface{}{{"pool", float64(2)}}},
+		{ID: "b", Message: "Rebalancing after osd.1 marked out", Progress: 0.1, Refs: [][]interface{}{{"osd", float64(1)}}},
+	}}
+
+	// the operations of the pool are in the status
+	updateStatusUsage(cl, recorder, name, &cephv1.PoolUsageStatus{}, events.PoolEvents(2))
+	pool := &cephv1.CephBlockPool{}
+	assert.NoError(t, cl.Get(context.TODO(), name, pool))
+	assert.Equal(t, []cephv1.ProgressEventStatus{{ID: "a", Message: "PG autoscaler increasing pool 2 PGs from 32 to 128", Progress: "50%"}}, pool.Status.Progress)
+	assert.Equal(t, "Normal ProgressStarted started: PG autoscaler increasing pool 2 PGs from 32 to 128", <-recorder.Events)
+
+	// the operations are kept when the progress is unknown
+	updateStatusUsage(cl, recorder, name, &cephv1.PoolUsageStatus{}, nil)
+	assert.NoError(t, cl.Get(context.TODO(), name, pool))
+	assert.Equal(t, 1, len(pool.Status.Progress))
+	assert.Empty(t, recorder.Events)
+
+	// the completion is reported
+	events = &client.ProgressEvents{Completed: []client.ProgressEvent{events.Events[0]}}
+	updateStatusUsage(cl, recorder, name, &cephv1.PoolUsageStatus{}, events.PoolEvents(2))
+	pool = &cephv1.CephBlockPool{}
+	assert.NoError(t, cl.Get(context.TODO(), name, pool))
+	assert.Empty(t, pool.Status.Progress)
+	assert.Equal(t, "Normal ProgressCompleted completed: PG autoscaler increasing pool 2 PGs from 32 to 128", <-recorder.Events)
+}
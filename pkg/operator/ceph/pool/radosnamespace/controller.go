@@ -0,0 +1,329 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package radosnamespace to manage the RADOS namespaces of the block pools.
+package radosnamespace
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-block-pool-rados-namespace-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephBlockPoolRadosNamespaceKind = reflect.TypeOf(cephv1.CephBlockPoolRadosNamespace{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephBlockPoolRadosNamespaceKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+var _ reconcile.Reconciler = &ReconcileCephBlockPoolRadosNamespace{}
+
+// ReconcileCephBlockPoolRadosNamespace reconciles a CephBlockPoolRadosNamespace object
+type ReconcileCephBlockPoolRadosNamespace struct {
+	client   client.Client
+	recorder record.EventRecorder
+	scheme   *runtime.Scheme
+	context  *clusterd.Context
+}
+
+// Add creates a new CephBlockPoolRadosNamespace Controller and adds it to the Manager. The Manager will set fields
+// on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	// Add the cephv1 scheme to the manager scheme so that the controller knows about it
+	mgrScheme := mgr.GetScheme()
+	if err := cephv1.AddToScheme(mgr.GetScheme()); err != nil {
+		panic(err)
+	}
+	return &ReconcileCephBlockPoolRadosNamespace{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		scheme:   mgrScheme,
+		context:  context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, opcontroller.ControllerOptions(controllerName, r))
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephBlockPoolRadosNamespace CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephBlockPoolRadosNamespace{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephBlockPoolRadosNamespace object and makes changes based on the
+// state read and what is in the CephBlockPoolRadosNamespace.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephBlockPoolRadosNamespace) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime loggin interface
+	start := time.Now()
+	reconcileResponse, err := r.reconcile(request)
+	opcontroller.RecordReconcileOutcome(r.client, &cephv1.CephBlockPoolRadosNamespace{}, request.NamespacedName, start, reconcileResponse, err)
+	opcontroller.ReportReconcileResult(r.client, r.recorder, &cephv1.CephBlockPoolRadosNamespace{}, request.NamespacedName, reconcileResponse, err)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephBlockPoolRadosNamespace) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephBlockPoolRadosNamespace instance
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, radosNamespace)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get CephBlockPoolRadosNamespace")
+	}
+	clusterID := buildClusterID(radosNamespace)
+
+	// The CR was just created, initializing status fields
+	if radosNamespace.Status == nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.Created, "")
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deletion of the namespace since everything is gone already
+		if !radosNamespace.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			if err := csi.RemoveRadosNamespaceConfig(r.context.Clientset, clusterID, csi.ConfigMutex); err != nil {
+				return reconcile.Result{}, errors.Wrap(err, "failed to remove the rados namespace from the csi config")
+			}
+			err = opcontroller.RemoveFinalizer(r.client, radosNamespace)
+			if err != nil {
+				return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	clusterInfo, _, _, err := mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, radosNamespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// the pool of the namespace
+	pool := &cephv1.CephBlockPool{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: radosNamespace.Spec.BlockPoolName, Namespace: radosNamespace.Namespace}, pool)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get CephBlockPool %q", radosNamespace.Spec.BlockPoolName)
+	}
+	poolExists := err == nil
+
+	// DELETE: the CR was deleted
+	if !radosNamespace.GetDeletionTimestamp().IsZero() {
+		// the namespace was removed with its pool
+		if poolExists {
+			logger.Debugf("deleting rados namespace %q of pool %q", radosNamespace.Name, radosNamespace.Spec.BlockPoolName)
+			images, err := cephclient.ListRadosNamespaceImages(r.context, clusterInfo, radosNamespace.Spec.BlockPoolName, radosNamespace.Name)
+			if err != nil {
+				return reconcile.Result{}, errors.Wrapf(err, "failed to list the images of rados namespace %q", radosNamespace.Name)
+			}
+			if len(images) > 0 {
+				// Keep the finalizer until the images of the namespace are deleted
+				logger.Infof("rados namespace %q of pool %q has %d images, waiting for their deletion", radosNamespace.Name, radosNamespace.Spec.BlockPoolName, len(images))
+				return opcontroller.WaitForRequeueIfFinalizerBlocked, nil
+			}
+			if err := cephclient.DeleteRadosNamespace(r.context, clusterInfo, radosNamespace.Spec.BlockPoolName, radosNamespace.Name); err != nil {
+				return reconcile.Result{}, errors.Wrapf(err, "failed to delete rados namespace %q", radosNamespace.Name)
+			}
+		}
+		if err := csi.RemoveRadosNamespaceConfig(r.context.Clientset, clusterID, csi.ConfigMutex); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove the rados namespace from the csi config")
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, radosNamespace)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// Get CephCluster version
+	cephVersion, err := opcontroller.GetImageVersion(cephCluster)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to fetch ceph version from cephcluster %q", cephCluster.Name)
+	}
+
+	// validate the namespace settings
+	if err := validateRadosNamespace(radosNamespace, *cephVersion); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "invalid rados namespace CR %q spec", radosNamespace.Name)
+	}
+
+	// the namespace is created once the pool is ready
+	if !poolExists || pool.Status == nil || pool.Status.Phase != k8sutil.ReadyStatus {
+		logger.Infof("waiting for CephBlockPool %q of rados namespace %q to be ready", radosNamespace.Spec.BlockPoolName, radosNamespace.Name)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+
+	updateStatus(r.client, request.NamespacedName, k8sutil.ReconcilingStatus, "")
+
+	// CREATE/UPDATE
+	if err := r.reconcileRadosNamespace(clusterInfo, radosNamespace, cephVersion); err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.ReconcileFailedStatus, "")
+		return reconcile.Result{}, err
+	}
+
+	// the images of the namespace are provisioned with the clusterID of the namespace
+	err = csi.SaveRadosNamespaceConfig(r.context.Clientset, clusterID, radosNamespace.Namespace, radosNamespace.Name, clusterInfo, &cephCluster.Spec.CSI, csi.ConfigMutex)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.ReconcileFailedStatus, "")
+		return reconcile.Result{}, errors.Wrap(err, "failed to add the rados namespace to the csi config")
+	}
+
+	// Set Ready status, we are done reconciling
+	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus, clusterID)
+	opcontroller.ExportEffectiveSpec(r.client, radosNamespace, radosNamespace.Spec)
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// reconcileRadosNamespace creates the namespace in the pool and configures its mirroring
+func (r *ReconcileCephBlockPoolRadosNamespace) reconcileRadosNamespace(clusterInfo *cephclient.ClusterInfo, radosNamespace *cephv1.CephBlockPoolRadosNamespace, cephVersion *cephver.CephVersion) error {
+	poolName := radosNamespace.Spec.BlockPoolName
+	if err := cephclient.CreateRadosNamespace(r.context, clusterInfo, poolName, radosNamespace.Name); err != nil {
+		return errors.Wrapf(err, "failed to create rados namespace %q", radosNamespace.Name)
+	}
+
+	// the namespaces are mirrored since octopus, no mirroring is configured before
+	if radosNamespace.Spec.Mirroring == nil && !cephVersion.IsAtLeastOctopus() {
+		return nil
+	}
+	mode := cephclient.RBDMirroringModeDisabled
+	if radosNamespace.Spec.Mirroring != nil {
+		mode = radosNamespace.Spec.Mirroring.Mode
+	}
+	if err := cephclient.SetRadosNamespaceMirroringMode(r.context, clusterInfo, poolName, radosNamespace.Name, mode); err != nil {
+		return errors.Wrapf(err, "failed to configure the mirroring of rados namespace %q", radosNamespace.Name)
+	}
+	return nil
+}
+
+// validateRadosNamespace checks the pool and the mirroring settings of a namespace
+func validateRadosNamespace(radosNamespace *cephv1.CephBlockPoolRadosNamespace, cephVersion cephver.CephVersion) error {
+	if radosNamespace.Spec.BlockPoolName == "" {
+		return errors.New("missing blockPoolName")
+	}
+	if mirroring := radosNamespace.Spec.Mirroring; mirroring != nil {
+		if !cephVersion.IsAtLeastOctopus() {
+			return errors.New("the mirroring of rados namespaces requires ceph octopus or newer")
+		}
+		if mirroring.Mode != cephclient.RBDMirroringModeImage && mirroring.Mode != cephclient.RBDMirroringModePool {
+			return errors.Errorf("invalid mirroring mode %q, must be %q or %q", mirroring.Mode, cephclient.RBDMirroringModeImage, cephclient.RBDMirroringModePool)
+		}
+	}
+	return nil
+}
+
+// buildClusterID returns the clusterID of the namespace in the csi config, unique among the namespaces of all the
+// pools of all the clusters
+func buildClusterID(radosNamespace *cephv1.CephBlockPoolRadosNamespace) string {
+	return k8sutil.Hash(fmt.Sprintf("%s-%s-block-%s", radosNamespace.Namespace, radosNamespace.Spec.BlockPoolName, radosNamespace.Name))
+}
+
+// updateStatus updates a rados namespace CR with the given status and clusterID
+func updateStatus(client client.Client, name types.NamespacedName, status, clusterID string) {
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	err := client.Get(context.TODO(), name, radosNamespace)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve rados namespace %q to update status to %q. %v", name, status, err)
+		return
+	}
+
+	if radosNamespace.Status == nil {
+		radosNamespace.Status = &cephv1.RadosNamespaceStatus{}
+	}
+
+	radosNamespace.Status.Phase = status
+	if clusterID != "" {
+		radosNamespace.Status.ClusterID = clusterID
+	}
+	if err := opcontroller.UpdateStatus(client, radosNamespace); err != nil {
+		logger.Warningf("failed to set rados namespace %q status to %q. %v", radosNamespace.Name, status, err)
+		return
+	}
+	logger.Debugf("rados namespace %q status updated to %q", name, status)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radosnamespace
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestCephBlockPoolRadosNamespaceController(t *testing.T) {
+	namespace := "rook-ceph"
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: namespace},
+		Spec:       cephv1.RadosNamespaceSpec{BlockPoolName: "replicapool"},
+	}
+	pool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: namespace},
+		Status:     &cephv1.CephBlockPoolStatus{Phase: ""},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace, Namespace: namespace},
+		Status: cephv1.ClusterStatus{
+			Phase:       k8sutil.ReadyStatus,
+			CephVersion: &cephv1.ClusterVersion{Version: "15.2.4-0"},
+			CephStatus:  &cephv1.CephStatus{Health: "HEALTH_OK"},
+		},
+	}
+
+	namespaces := "[]"
+	images := "[]"
+	mirroringMode := "disabled"
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutputFile: func(command, outfile string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			return "", nil
+		},
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			assert.Equal(t, "rbd", command)
+			switch {
+			case args[0] == "namespace" && args[1] == "ls":
+				return namespaces, nil
+			case args[0] == "ls":
+				return images, nil
+			case args[0] == "mirror" && args[2] == "info":
+				return `{"mode":"` + mirroringMode + `"}`, nil
+			}
+			// the command without the connection args
+			rbdCommand := []string{}
+			for _, arg := range args {
+				if strings.HasPrefix(arg, "--cluster") {
+					break
+				}
+				rbdCommand = append(rbdCommand, arg)
+			}
+			commands = append(commands, strings.Join(rbdCommand, " "))
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mon", Namespace: namespace},
+		Data: map[string][]byte{
+			"fsid":         []byte("fsid"),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(secret)
+	assert.NoError(t, err)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPoolRadosNamespace{}, &cephv1.CephBlockPoolRadosNamespaceList{},
+		&cephv1.CephBlockPool{}, &cephv1.CephBlockPoolList{}, &cephv1.CephCluster{}, &cephv1.CephClusterList{})
+	cl := fake.NewFakeClient([]runtime.Object{radosNamespace, pool, cephCluster}...)
+	r := &ReconcileCephBlockPoolRadosNamespace{client: cl, scheme: s, context: c}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: radosNamespace.Name, Namespace: namespace}}
+
+	// the namespace waits for the pool to be ready
+	res, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.True(t, res.Requeue)
+	assert.Empty(t, commands)
+
+	// the namespace is created in the pool and its mirroring is disabled
+	pool.Status.Phase = k8sutil.ReadyStatus
+	assert.NoError(t, cl.Update(context.TODO(), pool))
+	mirroringMode = "image"
+	res, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	assert.Equal(t, []string{
+		"namespace create --pool=replicapool --namespace=tenant-a",
+		"mirror pool disable --pool=replicapool --namespace=tenant-a",
+	}, commands)
+	assert.NoError(t, cl.Get(context.TODO(), req.NamespacedName, radosNamespace))
+	assert.Equal(t, k8sutil.ReadyStatus, radosNamespace.Status.Phase)
+	assert.Equal(t, buildClusterID(radosNamespace), radosNamespace.Status.ClusterID)
+
+	// the existing namespace is mirrored
+	namespaces = `[{"name":"tenant-a"}]`
+	mirroringMode = "disabled"
+	commands = []string{}
+	radosNamespace.Spec.Mirroring = &cephv1.RadosNamespaceMirroringSpec{Mode: "image"}
+	assert.NoError(t, cl.Update(context.TODO(), radosNamespace))
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mirror pool enable --pool=replicapool --namespace=tenant-a image"}, commands)
+
+	// the namespace is not removed while it has images
+	now := metav1.Now()
+	radosNamespace.DeletionTimestamp = &now
+	assert.NoError(t, cl.Update(context.TODO(), radosNamespace))
+	images = `[{"image":"csi-vol-1","size":1048576,"format":2}]`
+	commands = []string{}
+	res, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.True(t, res.Requeue)
+	assert.Empty(t, commands)
+
+	images = "[]"
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"namespace remove --pool=replicapool --namespace=tenant-a"}, commands)
+}
+
+func TestValidateRadosNamespace(t *testing.T) {
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "rook-ceph"},
+		Spec:       cephv1.RadosNamespaceSpec{BlockPoolName: "replicapool"},
+	}
+	assert.NoError(t, validateRadosNamespace(radosNamespace, cephver.Nautilus))
+
+	// the mirroring requires octopus and a valid mode
+	radosNamespace.Spec.Mirroring = &cephv1.RadosNamespaceMirroringSpec{Mode: "pool"}
+	assert.Error(t, validateRadosNamespace(radosNamespace, cephver.Nautilus))
+	assert.NoError(t, validateRadosNamespace(radosNamespace, cephver.Octopus))
+	radosNamespace.Spec.Mirroring.Mode = "journal"
+	assert.Error(t, validateRadosNamespace(radosNamespace, cephver.Octopus))
+
+	// the pool is required
+	radosNamespace.Spec = cephv1.RadosNamespaceSpec{}
+	assert.Error(t, validateRadosNamespace(radosNamespace, cephver.Octopus))
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+)
+
+var rbdScheduleIntervalRegex = regexp.MustCompile(`^[1-9][0-9]*[mhd]$`)
+
+// validateRBDSchedules checks the intervals of the RBD schedules of a pool
+func validateRBDSchedules(p *cephv1.PoolSpec) error {
+	for _, schedule := range append(append([]cephv1.RBDScheduleSpec{}, p.SnapshotSchedules...), p.TrashPurgeSchedules...) {
+		if !rbdScheduleIntervalRegex.MatchString(schedule.Interval) {
+			return errors.Errorf("invalid schedule interval %q, must be a number of minutes, hours or days like 12h", schedule.Interval)
+		}
+	}
+	return nil
+}
+
+// reconcileRBDSchedules sets the mirror snapshot and trash purge schedules of a block pool with the rbd_support mgr
+// module. The schedules set from the toolbox are not changed as long as the lists are not set in the spec, an empty
+// list removes them.
+func reconcileRBDSchedules(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, cephVersion *cephver.CephVersion, p *cephv1.CephBlockPool) error {
+	scheduleTypes := []cephclient.RBDScheduleType{cephclient.RBDMirrorSnapshotSchedule, cephclient.RBDTrashPurgeSchedule}
+	for i, schedules := range [][]cephv1.RBDScheduleSpec{p.Spec.SnapshotSchedules, p.Spec.TrashPurgeSchedules} {
+		if schedules == nil {
+			continue
+		}
+		if !cephVersion.IsAtLeastOctopus() {
+			return errors.Errorf("the %s schedules of pool %q require ceph octopus", scheduleTypes[i], p.Name)
+		}
+		if err := cephclient.SetRBDSchedules(context, clusterInfo, scheduleTypes[i], p.Name, schedules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateRBDSchedules(t *testing.T) {
+	p := &cephv1.PoolSpec{
+		SnapshotSchedules:   []cephv1.RBDScheduleSpec{{Interval: "30m"}, {Interval: "1d", StartTime: "02:00"}},
+		TrashPurgeSchedules: []cephv1.RBDScheduleSpec{{Interval: "12h"}},
+	}
+	assert.NoError(t, validateRBDSchedules(p))
+	p.TrashPurgeSchedules[0].Interval = "12"
+	assert.Error(t, validateRBDSchedules(p))
+	p.TrashPurgeSchedules[0].Interval = "0h"
+	assert.Error(t, validateRBDSchedules(p))
+	p.TrashPurgeSchedules[0].Interval = "1w"
+	assert.Error(t, validateRBDSchedules(p))
+}
+
+func TestReconcileRBDSchedules(t *testing.T) {
+	rbdCommands := 0
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			rbdCommands++
+			return "[]", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := &client.ClusterInfo{Namespace: "myns"}
+	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: "myns"}}
+
+	// the schedules are not changed when the lists are not set
+	assert.NoError(t, reconcileRBDSchedules(context, clusterInfo, &cephver.Nautilus, p))
+	assert.Equal(t, 0, rbdCommands)
+
+	// an empty list removes the schedules
+	p.Spec.TrashPurgeSchedules = []cephv1.RBDScheduleSpec{}
+	assert.NoError(t, reconcileRBDSchedules(context, clusterInfo, &cephver.Octopus, p))
+	assert.Equal(t, 1, rbdCommands)
+
+	// the schedules require octopus
+	p.Spec.SnapshotSchedules = []cephv1.RBDScheduleSpec{{Interval: "1h"}}
+	assert.Error(t, reconcileRBDSchedules(context, clusterInfo, &cephver.Nautilus, p))
+	rbdCommands = 0
+	assert.NoError(t, reconcileRBDSchedules(context, clusterInfo, &cephver.Octopus, p))
+	assert.Equal(t, 3, rbdCommands)
+}
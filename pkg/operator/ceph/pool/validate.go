@@ -37,6 +37,9 @@ func ValidatePool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo
 	if err := ValidatePoolSpec(context, clusterInfo, &p.Spec); err != nil {
 		return err
 	}
+	if err := validateRBDSchedules(&p.Spec); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -48,7 +51,7 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *client.ClusterInfo
 
 	var crush cephclient.CrushMap
 	var err error
-	if p.FailureDomain != "" || p.CrushRoot != "" {
+	if p.FailureDomain != "" || p.CrushRoot != "" || p.IsErasureCoded() || p.Replicated.IsHybridStoragePool() {
 		crush, err = cephclient.GetCrushMap(context, clusterInfo)
 		if err != nil {
 			return errors.Wrap(err, "failed to get crush map")
@@ -83,6 +86,20 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *client.ClusterInfo
 		}
 	}
 
+	// validate the crush settings of the erasure code profile
+	if p.IsErasureCoded() {
+		if err := validateErasureCodeCrush(crush, p); err != nil {
+			return err
+		}
+	}
+
+	// validate the device classes of a hybrid pool
+	if p.Replicated.IsHybridStoragePool() {
+		if err := validateHybridStorage(crush, p); err != nil {
+			return err
+		}
+	}
+
 	// validate pool replica size
 	if p.Replicated.Size == 1 && p.Replicated.RequireSafeReplicaSize {
 		return errors.Errorf("error pool size is %d and requireSafeReplicaSize is %t, must be false", p.Replicated.Size, p.Replicated.RequireSafeReplicaSize)
@@ -98,5 +115,115 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *client.ClusterInfo
 		}
 	}
 
+	// validate pool compression algorithm if specified
+	if p.CompressionAlgorithm != "" {
+		switch p.CompressionAlgorithm {
+		case "snappy", "zlib", "zstd", "lz4":
+			break
+		default:
+			return errors.Errorf("unrecognized compression algorithm %q", p.CompressionAlgorithm)
+		}
+	}
+
+	// validate the pg autoscale mode if specified
+	if p.PgAutoscaleMode != "" {
+		switch p.PgAutoscaleMode {
+		case "on", "off", "warn":
+			break
+		default:
+			return errors.Errorf("unrecognized pg autoscale mode %q", p.PgAutoscaleMode)
+		}
+	}
+
+	if p.TargetSizeRatio < 0 || p.Replicated.TargetSizeRatio < 0 {
+		return errors.New("the target size ratio of the pool must not be negative")
+	}
+
+	// validate the warning threshold of the quotas if specified
+	if threshold := p.Quotas.WarningThresholdPercent; threshold != nil && (*threshold < 1 || *threshold > 100) {
+		return errors.Errorf("the quota warning threshold of the pool must be between 1 and 100 percent, found %d", *threshold)
+	}
+
+	return nil
+}
+
+// validateErasureCodeCrush checks the crush settings of the erasure code profile of a pool and that the chunks of
+// the objects of the pool can be placed in distinct failure domains
+func validateErasureCodeCrush(crush cephclient.CrushMap, p *cephv1.PoolSpec) error {
+	failureDomain := p.ErasureCodeFailureDomain()
+	if failureDomain == "" {
+		failureDomain = cephv1.DefaultFailureDomain
+	}
+	crushRoot := p.ErasureCodeCrushRoot()
+	if crushRoot == "" {
+		crushRoot = cephv1.DefaultCRUSHRoot
+	}
+	deviceClass := p.ErasureCodeDeviceClass()
+
+	if p.ErasureCoded.FailureDomain != "" && !hasCrushType(crush, failureDomain) {
+		return errors.Errorf("unrecognized erasure code failure domain %s", failureDomain)
+	}
+	if p.ErasureCoded.CrushRoot != "" && !hasCrushBucket(crush, crushRoot) {
+		return errors.Errorf("unrecognized erasure code crush root %s", crushRoot)
+	}
+	if deviceClass != "" && !hasDeviceClass(crush, deviceClass) {
+		return errors.Errorf("no osd with device class %s", deviceClass)
+	}
+
+	chunks := int(p.ErasureCoded.DataChunks + p.ErasureCoded.CodingChunks)
+	if domains := crush.CountFailureDomains(crushRoot, failureDomain, deviceClass); domains < chunks {
+		return errors.Errorf("the %d data and %d coding chunks of the erasure code require %d failure domains of type %q under crush root %q, found %d",
+			p.ErasureCoded.DataChunks, p.ErasureCoded.CodingChunks, chunks, failureDomain, crushRoot, domains)
+	}
 	return nil
 }
+
+// validateHybridStorage checks the device classes of a hybrid pool
+func validateHybridStorage(crush cephclient.CrushMap, p *cephv1.PoolSpec) error {
+	hybrid := p.Replicated.HybridStorage
+	if !p.IsReplicated() {
+		return errors.New("hybrid storage requires a replicated pool")
+	}
+	if hybrid.PrimaryDeviceClass == "" || hybrid.SecondaryDeviceClass == "" {
+		return errors.New("hybrid storage requires a primary and a secondary device class")
+	}
+	if hybrid.PrimaryDeviceClass == hybrid.SecondaryDeviceClass {
+		return errors.Errorf("the primary and secondary device classes of the hybrid storage must differ, both are %s", hybrid.PrimaryDeviceClass)
+	}
+	if p.DeviceClass != "" {
+		return errors.New("the device class of the pool cannot be set with hybrid storage")
+	}
+	for _, deviceClass := range []string{hybrid.PrimaryDeviceClass, hybrid.SecondaryDeviceClass} {
+		if !hasDeviceClass(crush, deviceClass) {
+			return errors.Errorf("no osd with device class %s", deviceClass)
+		}
+	}
+	return nil
+}
+
+func hasDeviceClass(crush cephclient.CrushMap, deviceClass string) bool {
+	for _, device := range crush.Devices {
+		if device.Class == deviceClass {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCrushType(crush cephclient.CrushMap, name string) bool {
+	for _, t := range crush.Types {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCrushBucket(crush cephclient.CrushMap, name string) bool {
+	for _, b := range crush.Buckets {
+		if b.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -50,6 +50,7 @@ const (
 	deviceInUseAppName                    = "rook-claimed-devices"
 	deviceInUseClusterAttr                = "rook.io/cluster"
 	discoverIntervalEnv                   = "ROOK_DISCOVER_DEVICES_INTERVAL"
+	discoverSmartDataEnv                  = "ROOK_DISCOVER_SMART_DATA"
 	defaultDiscoverInterval               = "60m"
 )
 
@@ -84,6 +85,9 @@ func (d *Discover) createDiscoverDaemonSet(namespace, discoverImage, securityAcc
 	if useCephVolume {
 		discovery_parameters = append(discovery_parameters, "--use-ceph-volume")
 	}
+	if getEnvVar(discoverSmartDataEnv, "false") == "true" {
+		discovery_parameters = append(discovery_parameters, "--collect-smart-data")
+	}
 
 	ds := &apps.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -248,7 +252,6 @@ func ListDevices(context *clusterd.Context, namespace, nodeName string) (map[str
 	}
 
 	var devices map[string][]sys.LocalDisk
-	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, discoverDaemon.AppName)}
 	// wait for device discovery configmaps
 	retryCount := 0
 	retryMax := 30
@@ -264,41 +267,57 @@ func ListDevices(context *clusterd.Context, namespace, nodeName string) (map[str
 			<-time.After(time.Duration(sleepTime) * time.Second)
 		}
 
-		cms, err := context.Clientset.CoreV1().ConfigMaps(namespace).List(listOpts)
+		var found bool
+		var err error
+		devices, found, err = listDiscoveredDevices(context, namespace, nodeName)
 		if err != nil {
-			logger.Warningf("failed to list device configmaps: %v", err)
-			return devices, fmt.Errorf("failed to list device configmaps: %+v", err)
+			return devices, err
 		}
-		if len(cms.Items) == 0 {
+		if !found {
 			logger.Infof("no configmap match, retry #%d", retryCount)
 			continue
 		}
-		devices = make(map[string][]sys.LocalDisk, len(cms.Items))
-		for _, cm := range cms.Items {
-			node := cm.ObjectMeta.Labels[discoverDaemon.NodeAttr]
-			if len(nodeName) > 0 && node != nodeName {
-				continue
-			}
-			deviceJson := cm.Data[discoverDaemon.LocalDiskCMData]
-			logger.Debugf("node %s, device %s", node, deviceJson)
-
-			if len(node) == 0 || len(deviceJson) == 0 {
-				continue
-			}
-			var d []sys.LocalDisk
-			err = json.Unmarshal([]byte(deviceJson), &d)
-			if err != nil {
-				logger.Warningf("failed to unmarshal %s", deviceJson)
-				continue
-			}
-			devices[node] = d
-		}
 		break
 	}
 	logger.Debugf("discovery found the following devices %+v", devices)
 	return devices, nil
 }
 
+// listDiscoveredDevices reads the devices from the configmaps of the discover daemons. It returns false if there
+// are no configmaps yet.
+func listDiscoveredDevices(context *clusterd.Context, namespace, nodeName string) (map[string][]sys.LocalDisk, bool, error) {
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, discoverDaemon.AppName)}
+	cms, err := context.Clientset.CoreV1().ConfigMaps(namespace).List(listOpts)
+	if err != nil {
+		logger.Warningf("failed to list device configmaps: %v", err)
+		return nil, false, fmt.Errorf("failed to list device configmaps: %+v", err)
+	}
+	if len(cms.Items) == 0 {
+		return nil, false, nil
+	}
+	devices := make(map[string][]sys.LocalDisk, len(cms.Items))
+	for _, cm := range cms.Items {
+		node := cm.ObjectMeta.Labels[discoverDaemon.NodeAttr]
+		if len(nodeName) > 0 && node != nodeName {
+			continue
+		}
+		deviceJson := cm.Data[discoverDaemon.LocalDiskCMData]
+		logger.Debugf("node %s, device %s", node, deviceJson)
+
+		if len(node) == 0 || len(deviceJson) == 0 {
+			continue
+		}
+		var d []sys.LocalDisk
+		err = json.Unmarshal([]byte(deviceJson), &d)
+		if err != nil {
+			logger.Warningf("failed to unmarshal %s", deviceJson)
+			continue
+		}
+		devices[node] = d
+	}
+	return devices, true, nil
+}
+
 // ListDevicesInUse lists all devices on a node that are already used by existing clusters.
 func ListDevicesInUse(context *clusterd.Context, namespace, nodeName string) ([]sys.LocalDisk, error) {
 	var devices []sys.LocalDisk
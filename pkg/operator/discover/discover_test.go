@@ -86,6 +86,16 @@ func TestStartDiscoveryDaemonset(t *testing.T) {
 	image := agentDS.Spec.Template.Spec.Containers[0].Image
 	assert.Equal(t, "rook/rook:myversion", image)
 	assert.Nil(t, agentDS.Spec.Template.Spec.Tolerations)
+	assert.NotContains(t, agentDS.Spec.Template.Spec.Containers[0].Args, "--collect-smart-data")
+
+	// the discover daemons collect the SMART data when enabled
+	os.Setenv(discoverSmartDataEnv, "true")
+	defer os.Unsetenv(discoverSmartDataEnv)
+	err = a.Start(namespace, "rook/rook:myversion", "mysa", false)
+	assert.Nil(t, err)
+	agentDS, err = clientset.AppsV1().DaemonSets(namespace).Get("rook-discover", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Contains(t, agentDS.Spec.Template.Spec.Containers[0].Args, "--collect-smart-data")
 }
 
 func TestGetAvailableDevices(t *testing.T) {
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	discoverDaemon "github.com/rook/rook/pkg/daemon/discover"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/display"
+	"github.com/rook/rook/pkg/util/sys"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ceph-volume rejects the devices smaller than 5GB
+const minDeviceSize = 5 * 1024 * 1024 * 1024
+
+// InventoryHost is the inventory of the devices of a node, in the format of `ceph orch device ls --format json`
+type InventoryHost struct {
+	Name    string            `json:"name"`
+	Addr    string            `json:"addr"`
+	Devices []InventoryDevice `json:"devices"`
+	Labels  []string          `json:"labels"`
+}
+
+// InventoryDevice is a device of a node, in the format of the `ceph-volume inventory` of the orchestrators
+type InventoryDevice struct {
+	Path              string          `json:"path"`
+	SysAPI            json.RawMessage `json:"sys_api"`
+	Available         bool            `json:"available"`
+	RejectedReasons   []string        `json:"rejected_reasons"`
+	DeviceID          string          `json:"device_id"`
+	LVs               json.RawMessage `json:"lvs"`
+	HumanReadableType string          `json:"human_readable_type"`
+	// Health is the SMART health of the device, collected when the discover daemons collect the SMART data
+	Health *discoverDaemon.DeviceHealth `json:"health,omitempty"`
+}
+
+// DeviceReport returns the inventory of the devices found by the discover daemons on all the nodes or on the given
+// node, sorted by node and device path
+func DeviceReport(context *clusterd.Context, namespace, nodeName string) ([]InventoryHost, error) {
+	devices, _, err := listDiscoveredDevices(context, namespace, nodeName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the discovered devices")
+	}
+	health, err := listDevicesHealth(context, namespace, nodeName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the health of the discovered devices")
+	}
+
+	hosts := []InventoryHost{}
+	for node, disks := range devices {
+		host := InventoryHost{Name: node, Addr: node, Devices: []InventoryDevice{}, Labels: []string{}}
+		for i := range disks {
+			if disks[i].Type == sys.PartType {
+				continue
+			}
+			device, err := newInventoryDevice(&disks[i])
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to report device %q of node %q", disks[i].Name, node)
+			}
+			if h, ok := health[node][disks[i].Name]; ok {
+				device.Health = &h
+			}
+			host.Devices = append(host.Devices, device)
+		}
+		sort.Slice(host.Devices, func(i, j int) bool { return host.Devices[i].Path < host.Devices[j].Path })
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts, nil
+}
+
+// listDevicesHealth reads the SMART health of the devices from the configmaps of the discover daemons, by node and
+// device name
+func listDevicesHealth(context *clusterd.Context, namespace, nodeName string) (map[string]map[string]discoverDaemon.DeviceHealth, error) {
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", k8sutil.AppAttr, discoverDaemon.AppName)}
+	cms, err := context.Clientset.CoreV1().ConfigMaps(namespace).List(listOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list device configmaps")
+	}
+	health := map[string]map[string]discoverDaemon.DeviceHealth{}
+	for _, cm := range cms.Items {
+		node := cm.Labels[discoverDaemon.NodeAttr]
+		data := cm.Data[discoverDaemon.LocalDiskCMHealth]
+		if node == "" || data == "" || (nodeName != "" && node != nodeName) {
+			continue
+		}
+		var h map[string]discoverDaemon.DeviceHealth
+		if err := json.Unmarshal([]byte(data), &h); err != nil {
+			logger.Warningf("failed to unmarshal the health of the devices of node %q. %v", node, err)
+			continue
+		}
+		health[node] = h
+	}
+	return health, nil
+}
+
+// newInventoryDevice reports a device with the data of ceph-volume if the discover daemon collected it, or with the
+// data of the discover daemon otherwise
+func newInventoryDevice(disk *sys.LocalDisk) (InventoryDevice, error) {
+	device := InventoryDevice{
+		Path:              path.Join("/dev", disk.Name),
+		DeviceID:          deviceID(disk),
+		HumanReadableType: "ssd",
+		LVs:               json.RawMessage("[]"),
+		RejectedReasons:   []string{},
+	}
+	if disk.Rotational {
+		device.HumanReadableType = "hdd"
+	}
+
+	if disk.CephVolumeData != "" {
+		var cv discoverDaemon.CephVolumeInventory
+		if err := json.Unmarshal([]byte(disk.CephVolumeData), &cv); err != nil {
+			return device, errors.Wrap(err, "failed to unmarshal ceph-volume data")
+		}
+		device.Available = cv.Available
+		device.SysAPI = cv.SysAPI
+		if len(cv.LVS) > 0 {
+			device.LVs = cv.LVS
+		}
+		if len(cv.RejectedReasons) > 0 {
+			if err := json.Unmarshal(cv.RejectedReasons, &device.RejectedReasons); err != nil {
+				return device, errors.Wrap(err, "failed to unmarshal ceph-volume rejected reasons")
+			}
+		}
+		return device, nil
+	}
+
+	device.RejectedReasons = rejectedReasons(disk)
+	device.Available = len(device.RejectedReasons) == 0
+	rotational := "0"
+	if disk.Rotational {
+		rotational = "1"
+	}
+	sysAPI, err := json.Marshal(map[string]interface{}{
+		"rotational":          rotational,
+		"ro":                  disk.Readonly,
+		"vendor":              disk.Vendor,
+		"model":               disk.Model,
+		"size":                disk.Size,
+		"human_readable_size": display.BytesToString(disk.Size),
+		"partitions":          partitionsSysAPI(disk.Partitions),
+	})
+	if err != nil {
+		return device, errors.Wrap(err, "failed to marshal sys_api")
+	}
+	device.SysAPI = sysAPI
+	return device, nil
+}
+
+// rejectedReasons returns why ceph-volume would not use a device, with the reasons of ceph-volume
+func rejectedReasons(disk *sys.LocalDisk) []string {
+	reasons := []string{}
+	if disk.Size < minDeviceSize {
+		reasons = append(reasons, "Insufficient space (<5GB)")
+	}
+	if disk.Readonly {
+		reasons = append(reasons, "Device is read-only")
+	}
+	if disk.Filesystem != "" {
+		reasons = append(reasons, "Has a FileSystem")
+	}
+	if len(disk.Partitions) > 0 || disk.HasChildren {
+		reasons = append(reasons, "Has partitions")
+	}
+	if disk.Type == sys.LVMType || disk.Type == sys.CryptType {
+		reasons = append(reasons, "Device type is not acceptable. It should be raw device or partition")
+	}
+	return reasons
+}
+
+func partitionsSysAPI(partitions []sys.Partition) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, p := range partitions {
+		result[p.Name] = map[string]interface{}{"size": display.BytesToString(p.Size)}
+	}
+	return result
+}
+
+// deviceID builds the id of a device like ceph-volume from its vendor, model and serial
+func deviceID(disk *sys.LocalDisk) string {
+	parts := []string{}
+	for _, part := range []string{disk.Vendor, disk.Model, disk.Serial} {
+		if part != "" {
+			parts = append(parts, strings.Replace(strings.TrimSpace(part), " ", "_", -1))
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// FormatDeviceReport formats the inventory as a table like `ceph orch device ls`
+func FormatDeviceReport(hosts []InventoryHost) string {
+	lines := []string{fmt.Sprintf("%-20s %-14s %-5s %-40s %-10s %-9s %-8s %s", "HOST", "PATH", "TYPE", "DEVICE ID", "SIZE", "AVAILABLE", "HEALTH", "REJECT REASONS")}
+	for _, host := range hosts {
+		for _, device := range host.Devices {
+			var sysAPI struct {
+				Size float64 `json:"size"`
+			}
+			_ = json.Unmarshal(device.SysAPI, &sysAPI)
+			health := discoverDaemon.DeviceHealth{}
+			if device.Health != nil {
+				health = *device.Health
+			}
+			lines = append(lines, strings.TrimSpace(fmt.Sprintf("%-20s %-14s %-5s %-40s %-10s %-9s %-8s %s", host.Name, device.Path, device.HumanReadableType,
+				device.DeviceID, display.BytesToString(uint64(sysAPI.Size)), yesNo(device.Available), health.Status(), strings.Join(device.RejectedReasons, ", "))))
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
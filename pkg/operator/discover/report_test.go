@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discover
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rook/rook/pkg/clusterd"
+	discoverDaemon "github.com/rook/rook/pkg/daemon/discover"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/rook/rook/pkg/util/sys"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createDeviceConfigMap(t *testing.T, context *clusterd.Context, node string, disks []sys.LocalDisk) {
+	data, err := json.Marshal(disks)
+	assert.NoError(t, err)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "local-device-" + node,
+			Namespace: "rook-system",
+			Labels:    map[string]string{k8sutil.AppAttr: discoverDaemon.AppName, discoverDaemon.NodeAttr: node},
+		},
+		Data: map[string]string{discoverDaemon.LocalDiskCMData: string(data)},
+	}
+	_, err = context.Clientset.CoreV1().ConfigMaps("rook-system").Create(cm)
+	assert.NoError(t, err)
+}
+
+func TestDeviceReport(t *testing.T) {
+	context := &clusterd.Context{Clientset: test.New(t, 1)}
+
+	// no discover daemon has reported yet
+	hosts, err := DeviceReport(context, "rook-system", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hosts))
+
+	cvData := `{"path":"/dev/sdc","available":false,"rejected_reasons":["locked","Has BlueStore device label"],"sys_api":{"size":10737418240.0},"lvs":[{"name":"osd-block"}]}`
+	createDeviceConfigMap(t, context, "node2", []sys.LocalDisk{
+		{Name: "sdc", Type: sys.DiskType, Size: 10737418240, Rotational: true, Serial: "S1", CephVolumeData: cvData},
+	})
+	createDeviceConfigMap(t, context, "node1", []sys.LocalDisk{
+		{Name: "sdb", Type: sys.DiskType, Size: 10737418240, Vendor: "ATA", Model: "Samsung SSD", Serial: "S2"},
+		{Name: "sda", Type: sys.DiskType, Size: 10737418240, Partitions: []sys.Partition{{Name: "sda1", Size: 1024}}, Filesystem: "ext4"},
+		{Name: "sda1", Type: sys.PartType, Size: 1024},
+		{Name: "sdd", Type: sys.DiskType, Size: 1024, Readonly: true},
+	})
+
+	hosts, err = DeviceReport(context, "rook-system", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(hosts))
+	assert.Equal(t, "node1", hosts[0].Name)
+	assert.Equal(t, 3, len(hosts[0].Devices))
+
+	sda := hosts[0].Devices[0]
+	assert.Equal(t, "/dev/sda", sda.Path)
+	assert.False(t, sda.Available)
+	assert.Equal(t, []string{"Has a FileSystem", "Has partitions"}, sda.RejectedReasons)
+
+	sdb := hosts[0].Devices[1]
+	assert.True(t, sdb.Available)
+	assert.Equal(t, 0, len(sdb.RejectedReasons))
+	assert.Equal(t, "ATA_Samsung_SSD_S2", sdb.DeviceID)
+	assert.Equal(t, "ssd", sdb.HumanReadableType)
+	var sysAPI map[string]interface{}
+	assert.NoError(t, json.Unmarshal(sdb.SysAPI, &sysAPI))
+	assert.Equal(t, "0", sysAPI["rotational"])
+	assert.Equal(t, float64(10737418240), sysAPI["size"])
+
+	sdd := hosts[0].Devices[2]
+	assert.Equal(t, []string{"Insufficient space (<5GB)", "Device is read-only"}, sdd.RejectedReasons)
+
+	// the data of ceph-volume is reported as is
+	sdc := hosts[1].Devices[0]
+	assert.Equal(t, "node2", hosts[1].Name)
+	assert.False(t, sdc.Available)
+	assert.Equal(t, []string{"locked", "Has BlueStore device label"}, sdc.RejectedReasons)
+	assert.Equal(t, "hdd", sdc.HumanReadableType)
+	assert.Equal(t, `[{"name":"osd-block"}]`, string(sdc.LVs))
+
+	// the json output has the keys of `ceph orch device ls --format json`
+	output, err := json.Marshal(hosts)
+	assert.NoError(t, err)
+	var parsed []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(output, &parsed))
+	device := parsed[0]["devices"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"path", "sys_api", "available", "rejected_reasons", "device_id", "lvs", "human_readable_type"} {
+		assert.Contains(t, device, key)
+	}
+
+	hosts, err = DeviceReport(context, "rook-system", "node2")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hosts))
+
+	table := FormatDeviceReport(hosts)
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[1], "node2"))
+	assert.Contains(t, lines[1], "locked, Has BlueStore device label")
+	assert.Contains(t, lines[1], "Unknown")
+
+	// the SMART health collected by the discover daemon is reported
+	cm, err := context.Clientset.CoreV1().ConfigMaps("rook-system").Get("local-device-node2", metav1.GetOptions{})
+	assert.NoError(t, err)
+	cm.Data[discoverDaemon.LocalDiskCMHealth] = `{"sdc":{"passed":false,"reallocatedSectors":120}}`
+	_, err = context.Clientset.CoreV1().ConfigMaps("rook-system").Update(cm)
+	assert.NoError(t, err)
+	hosts, err = DeviceReport(context, "rook-system", "node2")
+	assert.NoError(t, err)
+	assert.Equal(t, "Failing", hosts[0].Devices[0].Health.Status())
+	assert.Equal(t, 120, *hosts[0].Devices[0].Health.ReallocatedSectors)
+	assert.Contains(t, FormatDeviceReport(hosts), "Failing")
+}
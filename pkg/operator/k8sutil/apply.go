@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// FieldManager is the manager of the fields applied by the operator
+	FieldManager = "rook-ceph-operator"
+	// ApplyConflictReason is the reason of the events of the fields applied by the operator and changed by other
+	// managers
+	ApplyConflictReason = "ApplyConflict"
+)
+
+// ServerSideApply updates the deployments, services and config maps generated by the operator with server-side
+// apply, so that only the fields set by the operator are updated. The fields added by the users or by other
+// controllers, like the labels or the injected sidecars, are kept. The objects are replaced by default.
+var ServerSideApply = false
+
+// ApplyDeployment applies the fields of a deployment generated by the operator
+func ApplyDeployment(clientset kubernetes.Interface, d *apps.Deployment) (*apps.Deployment, error) {
+	d.TypeMeta = metav1.TypeMeta{Kind: "Deployment", APIVersion: apps.SchemeGroupVersion.String()}
+	ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
+	result := &apps.Deployment{}
+	err := apply(clientset, clientset.AppsV1().RESTClient(), "deployments", &d.ObjectMeta, d, result)
+	return result, err
+}
+
+// ApplyService applies the fields of a service generated by the operator
+func ApplyService(clientset kubernetes.Interface, s *v1.Service) (*v1.Service, error) {
+	s.TypeMeta = metav1.TypeMeta{Kind: "Service", APIVersion: v1.SchemeGroupVersion.String()}
+	ApplyInheritedMetadata(s.Namespace, &s.ObjectMeta)
+	result := &v1.Service{}
+	err := apply(clientset, clientset.CoreV1().RESTClient(), "services", &s.ObjectMeta, s, result)
+	return result, err
+}
+
+// ApplyConfigMap applies the fields of a config map generated by the operator
+func ApplyConfigMap(clientset kubernetes.Interface, cm *v1.ConfigMap) (*v1.ConfigMap, error) {
+	cm.TypeMeta = metav1.TypeMeta{Kind: "ConfigMap", APIVersion: v1.SchemeGroupVersion.String()}
+	ApplyInheritedMetadata(cm.Namespace, &cm.ObjectMeta)
+	result := &v1.ConfigMap{}
+	err := apply(clientset, clientset.CoreV1().RESTClient(), "configmaps", &cm.ObjectMeta, cm, result)
+	return result, err
+}
+
+// UpdateConfigMap updates a config map generated by the operator, with server-side apply if enabled
+func UpdateConfigMap(clientset kubernetes.Interface, cm *v1.ConfigMap) (*v1.ConfigMap, error) {
+	if ServerSideApply {
+		return ApplyConfigMap(clientset, cm)
+	}
+	ApplyInheritedMetadata(cm.Namespace, &cm.ObjectMeta)
+	return clientset.CoreV1().ConfigMaps(cm.Namespace).Update(cm)
+}
+
+// apply applies the object. The fields of the object managed by other managers are reported with an event, and are
+// then applied anyway since the operator owns the fields it generates.
+func apply(clientset kubernetes.Interface, restClient rest.Interface, resource string, objectMeta *metav1.ObjectMeta, obj, result runtime.Object) error {
+	// the apply must not be refused because of the version of the object, and must not claim the managed fields
+	objectMeta.ResourceVersion = ""
+	objectMeta.ManagedFields = nil
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s %q", resource, objectMeta.Name)
+	}
+
+	patch := func(force bool) error {
+		return restClient.Patch(types.ApplyPatchType).
+			Namespace(objectMeta.Namespace).
+			Resource(resource).
+			Name(objectMeta.Name).
+			VersionedParams(&metav1.PatchOptions{FieldManager: FieldManager, Force: &force}, metav1.ParameterCodec).
+			Body(data).
+			Do().
+			Into(result)
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	err = patch(false)
+	if kerrors.IsConflict(err) {
+		logger.Warningf("fields of %s %q are managed by another manager, applying them anyway. %v", resource, objectMeta.Name, err)
+		conflict := err
+		err = patch(true)
+		if err == nil {
+			// the event refers to the applied object, the generated object has no uid
+			if applied, accessorErr := meta.Accessor(result); accessorErr == nil {
+				reportApplyConflict(clientset, gvk.GroupVersion().String(), gvk.Kind, applied, conflict)
+			}
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply %s %q", resource, objectMeta.Name)
+	}
+	return nil
+}
+
+// reportApplyConflict creates a warning event on the object with the conflicting fields
+func reportApplyConflict(clientset kubernetes.Interface, apiVersion, kind string, object metav1.Object, conflict error) {
+	now := metav1.NewTime(time.Now())
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s.", object.GetName()),
+			Namespace:    object.GetNamespace(),
+		},
+		InvolvedObject: v1.ObjectReference{
+			APIVersion:      apiVersion,
+			Kind:            kind,
+			Namespace:       object.GetNamespace(),
+			Name:            object.GetName(),
+			UID:             object.GetUID(),
+			ResourceVersion: object.GetResourceVersion(),
+		},
+		Reason:         ApplyConflictReason,
+		Message:        conflict.Error(),
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: FieldManager},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := clientset.CoreV1().Events(object.GetNamespace()).Create(event); err != nil {
+		logger.Warningf("failed to report the apply conflict of %s %q. %v", kind, object.GetName(), err)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyConfigMap(t *testing.T) {
+	conflict := true
+	forced := []string{}
+	events := []v1.Event{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/rook-ceph/events" {
+			event := v1.Event{}
+			assert.NoError(t, json.Unmarshal(body, &event))
+			events = append(events, event)
+			_, _ = w.Write(body)
+			return
+		}
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/rook-ceph/configmaps/rook-ceph-mon-endpoints", r.URL.Path)
+		assert.Equal(t, string(types.ApplyPatchType), r.Header.Get("Content-Type"))
+		assert.Equal(t, FieldManager, r.URL.Query().Get("fieldManager"))
+		forced = append(forced, r.URL.Query().Get("force"))
+
+		// the version and the managed fields are not applied
+		cm := v1.ConfigMap{}
+		assert.NoError(t, json.Unmarshal(body, &cm))
+		assert.Equal(t, "ConfigMap", cm.Kind)
+		assert.Equal(t, "", cm.ResourceVersion)
+		assert.Empty(t, cm.ManagedFields)
+		if conflict {
+			conflict = false
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusFailure, Reason: metav1.StatusReasonConflict, Code: http.StatusConflict,
+				Message: `Apply failed with 1 conflict: conflict with "kubectl": .data.data`})
+			return
+		}
+		// the applied object is returned with its uid
+		cm.UID = "cm-uid"
+		_ = json.NewEncoder(w).Encode(cm)
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mon-endpoints", Namespace: "rook-ceph", ResourceVersion: "42",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}},
+		Data: map[string]string{"data": "a=1.2.3.4:6789"},
+	}
+
+	// the conflict is reported with an event and the fields are applied with force
+	result, err := ApplyConfigMap(clientset, cm)
+	assert.NoError(t, err)
+	assert.Equal(t, "a=1.2.3.4:6789", result.Data["data"])
+	assert.Equal(t, []string{"false", "true"}, forced)
+	assert.Len(t, events, 1)
+	assert.Equal(t, ApplyConflictReason, events[0].Reason)
+	assert.Equal(t, v1.EventTypeWarning, events[0].Type)
+	assert.Equal(t, "rook-ceph-mon-endpoints", events[0].InvolvedObject.Name)
+	assert.Equal(t, "ConfigMap", events[0].InvolvedObject.Kind)
+	assert.Equal(t, "v1", events[0].InvolvedObject.APIVersion)
+	assert.Equal(t, types.UID("cm-uid"), events[0].InvolvedObject.UID)
+
+	// the fields are applied without force when they are not managed by others
+	forced = []string{}
+	ServerSideApply = true
+	defer func() { ServerSideApply = false }()
+	_, err = UpdateConfigMap(clientset, cm)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"false"}, forced)
+	assert.Len(t, events, 1)
+}
@@ -28,6 +28,7 @@ import (
 
 // CreateDaemonSet creates
 func CreateDaemonSet(name, namespace string, clientset kubernetes.Interface, ds *apps.DaemonSet) error {
+	ApplyInheritedMetadataToPodTemplate(namespace, &ds.ObjectMeta, &ds.Spec.Template)
 	_, err := clientset.AppsV1().DaemonSets(namespace).Create(ds)
 	if err != nil {
 		if k8serrors.IsAlreadyExists(err) {
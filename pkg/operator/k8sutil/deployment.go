@@ -28,6 +28,7 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -61,6 +62,7 @@ func GetDeploymentSpecImage(clientset kubernetes.Interface, d apps.Deployment, c
 // Basically, we go one resource by one and check if we can stop and then if the resource has been successfully updated
 // we check if we can go ahead and move to the next one.
 func UpdateDeploymentAndWait(context *clusterd.Context, modifiedDeployment *apps.Deployment, namespace string, verifyCallback func(action string) error) (*v1.Deployment, error) {
+	ApplyInheritedMetadataToPodTemplate(namespace, &modifiedDeployment.ObjectMeta, &modifiedDeployment.Spec.Template)
 	currentDeployment, err := context.Clientset.AppsV1().Deployments(namespace).Get(modifiedDeployment.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get deployment %s. %+v", modifiedDeployment.Name, err)
@@ -91,7 +93,12 @@ func UpdateDeploymentAndWait(context *clusterd.Context, modifiedDeployment *apps
 			return nil, fmt.Errorf("failed to set hash annotation on deployment %q. %v", modifiedDeployment.Name, err)
 		}
 
-		if _, err := context.Clientset.AppsV1().Deployments(namespace).Update(modifiedDeployment); err != nil {
+		if ServerSideApply {
+			modifiedDeployment.Namespace = namespace
+			if _, err := ApplyDeployment(context.Clientset, modifiedDeployment); err != nil {
+				return nil, fmt.Errorf("failed to update deployment %q. %v", modifiedDeployment.Name, err)
+			}
+		} else if _, err := context.Clientset.AppsV1().Deployments(namespace).Update(modifiedDeployment); err != nil {
 			return nil, fmt.Errorf("failed to update deployment %q. %v", modifiedDeployment.Name, err)
 		}
 
@@ -138,6 +145,86 @@ func UpdateDeploymentAndWait(context *clusterd.Context, modifiedDeployment *apps
 	return nil, nil
 }
 
+// RestartDeploymentAndWait deletes the pods of a deployment and waits for the pods recreated by the deployment to be
+// ready. Unlike an update of the deployment, the pod template is not changed.
+func RestartDeploymentAndWait(clientset kubernetes.Interface, deployment *apps.Deployment) error {
+	return restartDeploymentAndWait(clientset, deployment, func(pod *corev1.Pod) error {
+		return clientset.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{})
+	})
+}
+
+// EvictDeploymentAndWait restarts the pods of a deployment like RestartDeploymentAndWait, but the pods are evicted so
+// that their pod disruption budgets are respected. An eviction refused by a budget is not retried, the error is a
+// TooManyRequests error.
+func EvictDeploymentAndWait(clientset kubernetes.Interface, deployment *apps.Deployment) error {
+	var refused error
+	err := restartDeploymentAndWait(clientset, deployment, func(pod *corev1.Pod) error {
+		eviction := &policyv1beta1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+		err := clientset.CoreV1().Pods(pod.Namespace).Evict(eviction)
+		if k8serrors.IsTooManyRequests(err) {
+			refused = err
+		}
+		return err
+	})
+	if refused != nil {
+		return refused
+	}
+	return err
+}
+
+func restartDeploymentAndWait(clientset kubernetes.Interface, deployment *apps.Deployment, stopPod func(pod *corev1.Pod) error) error {
+	namespace := deployment.Namespace
+	selector := metav1.FormatLabelSelector(deployment.Spec.Selector)
+	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list pods of deployment %q. %v", deployment.Name, err)
+	}
+	restarted := map[string]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		logger.Infof("restarting pod %q of deployment %q", pod.Name, deployment.Name)
+		if err := stopPod(pod); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to stop pod %q. %v", pod.Name, err)
+		}
+		restarted[pod.Name] = true
+	}
+
+	sleepTime := 2
+	attempts := 30
+	if deployment.Spec.ProgressDeadlineSeconds != nil {
+		// make the attempts double the progress deadline since the pod is both stopping and starting
+		attempts = 2 * (int(*deployment.Spec.ProgressDeadlineSeconds) / sleepTime)
+	}
+	for i := 0; i < attempts; i++ {
+		d, err := clientset.AppsV1().Deployments(namespace).Get(deployment.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get deployment %q. %v", deployment.Name, err)
+		}
+		pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return fmt.Errorf("failed to list pods of deployment %q. %v", deployment.Name, err)
+		}
+		oldPods := 0
+		for _, pod := range pods.Items {
+			if restarted[pod.Name] {
+				oldPods++
+			}
+		}
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		if oldPods == 0 && d.Status.ReadyReplicas >= replicas {
+			logger.Infof("finished waiting for restarted deployment %q", d.Name)
+			return nil
+		}
+
+		logger.Debugf("deployment %q status=%+v", d.Name, d.Status)
+		time.Sleep(time.Duration(sleepTime) * time.Second)
+	}
+	return fmt.Errorf("gave up waiting for deployment %q to restart", deployment.Name)
+}
+
 // GetDeployments returns a list of deployment names labels matching a given selector
 // example of a label selector might be "app=rook-ceph-mon, mon!=b"
 // more: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/
@@ -256,10 +343,16 @@ func addLabel(key, value string, labels map[string]string) {
 }
 
 func CreateDeployment(clientset kubernetes.Interface, name, namespace string, dep *apps.Deployment) error {
+	ApplyInheritedMetadataToPodTemplate(namespace, &dep.ObjectMeta, &dep.Spec.Template)
 	_, err := clientset.AppsV1().Deployments(namespace).Create(dep)
 	if err != nil {
 		if k8serrors.IsAlreadyExists(err) {
-			_, err = clientset.AppsV1().Deployments(namespace).Update(dep)
+			if ServerSideApply {
+				dep.Namespace = namespace
+				_, err = ApplyDeployment(clientset, dep)
+			} else {
+				_, err = clientset.AppsV1().Deployments(namespace).Update(dep)
+			}
 		}
 		if err != nil {
 			return fmt.Errorf("failed to start %s deployment: %+v\n%+v", name, err, dep)
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestEvictDeploymentAndWait(t *testing.T) {
+	labels := map[string]string{"app": "rook-ceph-mds", "mds": "myfs-a"}
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mds-myfs-a", Namespace: "ns"},
+		Spec:       apps.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		Status:     apps.DeploymentStatus{ReadyReplicas: 1},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mds-myfs-a-1234", Namespace: "ns", Labels: labels}}
+	clientset := fake.NewSimpleClientset(d, pod)
+
+	// the first eviction is refused by the disruption budget, the second one deletes the pod
+	evictions := 0
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		evictions++
+		if evictions == 1 {
+			return true, nil, k8serrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		return true, nil, clientset.Tracker().Delete(action.GetResource(), eviction.Namespace, eviction.Name)
+	})
+
+	err := EvictDeploymentAndWait(clientset, d)
+	assert.True(t, k8serrors.IsTooManyRequests(err))
+	assert.Equal(t, 1, evictions)
+	assert.NoError(t, EvictDeploymentAndWait(clientset, d))
+	assert.Equal(t, 2, evictions)
+	pods, err := clientset.CoreV1().Pods("ns").List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, pods.Items)
+}
@@ -28,6 +28,7 @@ import (
 func CreateOrUpdateEndpoint(clientset kubernetes.Interface, namespace string, endpointDefinition *v1.Endpoints) (*v1.Endpoints, error) {
 	name := endpointDefinition.Name
 	logger.Debugf("creating endpoint %q. %v", name, endpointDefinition.Subsets)
+	ApplyInheritedMetadata(namespace, &endpointDefinition.ObjectMeta)
 	ep, err := clientset.CoreV1().Endpoints(namespace).Create(endpointDefinition)
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
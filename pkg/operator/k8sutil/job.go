@@ -53,6 +53,7 @@ func RunReplaceableJob(clientset kubernetes.Interface, job *batch.Job, deleteIfF
 		}
 	}
 
+	ApplyInheritedMetadataToPodTemplate(job.Namespace, &job.ObjectMeta, &job.Spec.Template)
 	_, err = clientset.BatchV1().Jobs(job.Namespace).Create(job)
 	return err
 }
@@ -76,6 +76,7 @@ func (kv *ConfigMapKVStore) SetValueWithLabels(storeName, key, value string, lab
 			cm.Labels = labels
 		}
 		SetOwnerRef(&cm.ObjectMeta, &kv.ownerRef)
+		ApplyInheritedMetadata(kv.namespace, &cm.ObjectMeta)
 
 		_, err = kv.clientset.CoreV1().ConfigMaps(kv.namespace).Create(cm)
 		return err
@@ -92,6 +93,24 @@ func (kv *ConfigMapKVStore) SetValueWithLabels(storeName, key, value string, lab
 	return nil
 }
 
+// DeleteValue removes a key from the store, removing a key that doesn't exist succeeds
+func (kv *ConfigMapKVStore) DeleteValue(storeName, key string) error {
+	cm, err := kv.clientset.CoreV1().ConfigMaps(kv.namespace).Get(storeName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, ok := cm.Data[key]; !ok {
+		return nil
+	}
+
+	delete(cm.Data, key)
+	_, err = kv.clientset.CoreV1().ConfigMaps(kv.namespace).Update(cm)
+	return err
+}
+
 func (kv *ConfigMapKVStore) GetStore(storeName string) (map[string]string, error) {
 	cm, err := kv.clientset.CoreV1().ConfigMaps(kv.namespace).Get(storeName, metav1.GetOptions{})
 	if err != nil {
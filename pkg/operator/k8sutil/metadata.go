@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+type inheritedMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+var (
+	inheritedMetadataMutex sync.RWMutex
+	// the labels and annotations inherited by the resources, by namespace
+	inheritedMetadataByNamespace = map[string]inheritedMetadata{}
+)
+
+// SetInheritedMetadata sets the labels and annotations inherited by all the resources generated by the operator in the
+// namespace. They are set from the cluster CR, and are added by the helpers creating and updating the resources. The
+// previous labels and annotations are kept if the new ones are not valid.
+func SetInheritedMetadata(namespace string, labels, annotations map[string]string) error {
+	if err := validateInheritedMetadata(labels, annotations); err != nil {
+		return err
+	}
+	inheritedMetadataMutex.Lock()
+	defer inheritedMetadataMutex.Unlock()
+	if len(labels) == 0 && len(annotations) == 0 {
+		delete(inheritedMetadataByNamespace, namespace)
+		return nil
+	}
+	inheritedMetadataByNamespace[namespace] = inheritedMetadata{labels: copyStringMap(labels), annotations: copyStringMap(annotations)}
+	return nil
+}
+
+// ForgetInheritedMetadata forgets the labels and annotations inherited in the namespace when the cluster is deleted
+func ForgetInheritedMetadata(namespace string) {
+	inheritedMetadataMutex.Lock()
+	defer inheritedMetadataMutex.Unlock()
+	delete(inheritedMetadataByNamespace, namespace)
+}
+
+// validateInheritedMetadata checks the labels and annotations before they are added to all the resources, since an
+// invalid key or value would fail the creation of every resource of the cluster
+func validateInheritedMetadata(labels, annotations map[string]string) error {
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.Errorf("invalid inherited label key %q. %v", key, errs)
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.Errorf("invalid inherited label value %q for key %q. %v", value, key, errs)
+		}
+	}
+	for key := range annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.Errorf("invalid inherited annotation key %q. %v", key, errs)
+		}
+	}
+	return nil
+}
+
+// ApplyInheritedMetadata adds the labels and annotations inherited in the namespace to the metadata of a resource. The
+// labels and annotations already set on the resource are kept since the operator and the specs of the CRs take
+// precedence over the inherited ones.
+func ApplyInheritedMetadata(namespace string, meta metav1.Object) {
+	inheritedMetadataMutex.RLock()
+	inherited, ok := inheritedMetadataByNamespace[namespace]
+	inheritedMetadataMutex.RUnlock()
+	if !ok {
+		return
+	}
+	mergeInheritedMetadata(meta, inherited.labels, inherited.annotations)
+}
+
+// ApplyClusterInheritedMetadata adds the labels and annotations inherited from the cluster CR to the metadata of a
+// resource and of its templates. It is used by the controllers which read the cluster CR themselves, so their resources
+// do not depend on the metadata set by the reconcile of another controller. Invalid labels and annotations are not
+// added, they are reported by the cluster controller.
+func ApplyClusterInheritedMetadata(labels, annotations map[string]string, metas ...metav1.Object) {
+	if err := validateInheritedMetadata(labels, annotations); err != nil {
+		return
+	}
+	for _, meta := range metas {
+		mergeInheritedMetadata(meta, labels, annotations)
+	}
+}
+
+func mergeInheritedMetadata(meta metav1.Object, labels, annotations map[string]string) {
+	meta.SetLabels(mergeInheritedMap(meta.GetLabels(), labels))
+	meta.SetAnnotations(mergeInheritedMap(meta.GetAnnotations(), annotations))
+}
+
+// ApplyInheritedMetadataToPodTemplate adds the labels and annotations inherited in the namespace to the metadata of a
+// resource and of the template of its pods
+func ApplyInheritedMetadataToPodTemplate(namespace string, meta *metav1.ObjectMeta, template *v1.PodTemplateSpec) {
+	ApplyInheritedMetadata(namespace, meta)
+	ApplyInheritedMetadata(namespace, &template.ObjectMeta)
+}
+
+// mergeInheritedMap returns a copy of the current map with the inherited keys which are not set yet. The current map is
+// not modified since the labels of the pod templates are often shared with the immutable selectors.
+func mergeInheritedMap(current, inherited map[string]string) map[string]string {
+	if len(inherited) == 0 {
+		return current
+	}
+	result := copyStringMap(current)
+	if result == nil {
+		result = map[string]string{}
+	}
+	for key, value := range inherited {
+		if _, ok := result[key]; !ok {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
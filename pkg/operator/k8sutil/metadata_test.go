@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInheritedMetadata(t *testing.T) {
+	namespace := "inherited-ns"
+	defer ForgetInheritedMetadata(namespace)
+
+	// nothing is inherited by default
+	meta := metav1.ObjectMeta{Name: "svc"}
+	ApplyInheritedMetadata(namespace, &meta)
+	assert.Nil(t, meta.Labels)
+	assert.Nil(t, meta.Annotations)
+
+	err := SetInheritedMetadata(namespace,
+		map[string]string{"velero.io/exclude-from-backup": "true", "app": "inherited"},
+		map[string]string{"sidecar.istio.io/inject": "false"})
+	assert.NoError(t, err)
+
+	// the labels of the operator are kept and the selector sharing them is not modified
+	labels := map[string]string{"app": "rook-ceph-mgr"}
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "mgr", Namespace: namespace, Labels: labels},
+		Spec: apps.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+		},
+	}
+	ApplyInheritedMetadataToPodTemplate(d.Namespace, &d.ObjectMeta, &d.Spec.Template)
+	assert.Equal(t, map[string]string{"app": "rook-ceph-mgr", "velero.io/exclude-from-backup": "true"}, d.Labels)
+	assert.Equal(t, map[string]string{"app": "rook-ceph-mgr", "velero.io/exclude-from-backup": "true"}, d.Spec.Template.Labels)
+	assert.Equal(t, "false", d.Spec.Template.Annotations["sidecar.istio.io/inject"])
+	assert.Equal(t, map[string]string{"app": "rook-ceph-mgr"}, d.Spec.Selector.MatchLabels)
+
+	// the resources of the other namespaces do not inherit the metadata
+	other := metav1.ObjectMeta{Name: "svc"}
+	ApplyInheritedMetadata("other-ns", &other)
+	assert.Nil(t, other.Labels)
+
+	// invalid metadata is refused and the previous metadata is kept
+	err = SetInheritedMetadata(namespace, map[string]string{"backup": "not valid"}, nil)
+	assert.Error(t, err)
+	err = SetInheritedMetadata(namespace, nil, map[string]string{"-invalid": "true"})
+	assert.Error(t, err)
+	meta = metav1.ObjectMeta{Name: "svc"}
+	ApplyInheritedMetadata(namespace, &meta)
+	assert.Equal(t, "true", meta.Labels["velero.io/exclude-from-backup"])
+
+	// the resources stop inheriting the metadata once it is forgotten
+	ForgetInheritedMetadata(namespace)
+	meta = metav1.ObjectMeta{Name: "svc"}
+	ApplyInheritedMetadata(namespace, &meta)
+	assert.Nil(t, meta.Labels)
+}
+
+func TestApplyClusterInheritedMetadata(t *testing.T) {
+	labels := map[string]string{"velero.io/exclude-from-backup": "true", "app": "inherited"}
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Labels: map[string]string{"app": "rook-ceph-rgw"}}}
+	ApplyClusterInheritedMetadata(labels, nil, secret)
+	assert.Equal(t, map[string]string{"app": "rook-ceph-rgw", "velero.io/exclude-from-backup": "true"}, secret.Labels)
+	assert.Nil(t, secret.Annotations)
+
+	// invalid metadata is not added
+	secret = &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret"}}
+	ApplyClusterInheritedMetadata(map[string]string{"backup": "not valid"}, nil, secret)
+	assert.Nil(t, secret.Labels)
+}
+
+func TestCreateOrUpdateServiceInheritsMetadata(t *testing.T) {
+	namespace := "inherited-svc-ns"
+	defer ForgetInheritedMetadata(namespace)
+	assert.NoError(t, SetInheritedMetadata(namespace, map[string]string{"team": "storage"}, nil))
+
+	clientset := fake.NewSimpleClientset()
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mgr", Namespace: namespace}}
+	_, err := CreateOrUpdateService(clientset, namespace, svc)
+	assert.NoError(t, err)
+	created, err := clientset.CoreV1().Services(namespace).Get("rook-ceph-mgr", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "storage", created.Labels["team"])
+}
@@ -37,13 +37,55 @@ type NetworkAttachmentConfig struct {
 		Subnet     string `json:"subnet"`
 		RangeStart string `json:"rangeStart"`
 		RangeEnd   string `json:"rangeEnd"`
-		Routes     []struct {
+		// Range is the subnet of the whereabouts ipam
+		Range string `json:"range"`
+		// Ranges are the range sets of the host-local ipam, each with the subnets of the range
+		Ranges [][]struct {
+			Subnet string `json:"subnet"`
+		} `json:"ranges"`
+		Routes []struct {
 			Dst string `json:"dst"`
 		} `json:"routes"`
 		Gateway string `json:"gateway"`
 	} `json:"ipam"`
 }
 
+// Subnets returns the subnets the ipam of the network attachment assigns the addresses from
+func (c NetworkAttachmentConfig) Subnets() []string {
+	subnets := []string{}
+	if c.Ipam.Subnet != "" {
+		subnets = append(subnets, c.Ipam.Subnet)
+	}
+	if c.Ipam.Range != "" {
+		subnets = append(subnets, c.Ipam.Range)
+	}
+	for _, rangeSet := range c.Ipam.Ranges {
+		for _, r := range rangeSet {
+			if r.Subnet != "" {
+				subnets = append(subnets, r.Subnet)
+			}
+		}
+	}
+	return subnets
+}
+
+const (
+	// MultusNetworksAnnotation is the annotation requesting the multus networks of a pod
+	MultusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+	// MultusNetworkStatusAnnotation is the annotation set by multus with the networks attached to a pod
+	MultusNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+	// legacyMultusNetworkStatusAnnotation is the network status annotation set by older versions of multus
+	legacyMultusNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/networks-status"
+)
+
+// NetworkStatus is a network attached to a pod as reported by multus in the network status annotation
+type NetworkStatus struct {
+	Name      string   `json:"name"`
+	Interface string   `json:"interface,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+	Default   bool     `json:"default,omitempty"`
+}
+
 // parseMultusSelector will parse short and JSON form of individual multus
 // network attachment selection annotation. Valid JSON will be unmarshalled and
 // return as is, while invalid JSON will be tried using
@@ -71,6 +113,9 @@ func parseMultusSelector(selector string) (map[string]string, error) {
 			rawMap["name"] = selector[:ifStartIndex]
 		} else if nsEndIndex != -1 && ifStartIndex == -1 {
 			rawMap["name"] = selector[nsEndIndex+1:]
+		} else if nsEndIndex == -1 && ifStartIndex == -1 {
+			// the selector is only the name of the network attachment
+			rawMap["name"] = selector
 		}
 	}
 
@@ -81,6 +126,20 @@ func parseMultusSelector(selector string) (map[string]string, error) {
 	return rawMap, nil
 }
 
+// GetMultusNetworkName returns the namespace and the name of the network attachment of a multus selector. The
+// network attachment is assumed to be in the default namespace if the selector has no namespace.
+func GetMultusNetworkName(selector, defaultNamespace string) (string, string, error) {
+	multusMap, err := parseMultusSelector(selector)
+	if err != nil {
+		return "", "", err
+	}
+	namespace, ok := multusMap["namespace"]
+	if !ok || namespace == "" {
+		namespace = defaultNamespace
+	}
+	return namespace, multusMap["name"], nil
+}
+
 // GetMultusIfName return a network interface name that multus will assign when
 // connected to the multus network.
 func GetMultusIfName(selector string) (string, error) {
@@ -132,7 +191,7 @@ func ApplyMultus(net rookv1.NetworkSpec, objectMeta *metav1.ObjectMeta) error {
 	}
 
 	t := rookv1.Annotations{
-		"k8s.v1.cni.cncf.io/networks": networks,
+		MultusNetworksAnnotation: networks,
 	}
 	t.ApplyToObjectMeta(objectMeta)
 
@@ -151,3 +210,41 @@ func GetNetworkAttachmentConfig(n netapi.NetworkAttachmentDefinition) (NetworkAt
 
 	return netConfig, nil
 }
+
+// GetNetworkStatus returns the networks attached to a pod from its multus network status annotation.
+// It returns an empty list if multus did not report the status yet.
+func GetNetworkStatus(objectMeta metav1.ObjectMeta) ([]NetworkStatus, error) {
+	status, ok := objectMeta.Annotations[MultusNetworkStatusAnnotation]
+	if !ok {
+		status, ok = objectMeta.Annotations[legacyMultusNetworkStatusAnnotation]
+	}
+	networks := []NetworkStatus{}
+	if !ok || status == "" {
+		return networks, nil
+	}
+	if err := json.Unmarshal([]byte(status), &networks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal network status %q. %v", status, err)
+	}
+	return networks, nil
+}
+
+// GetMultusNetworkIPs returns the IPs of the pod on the network of the multus selector. The
+// network attachment is assumed to be in the pod namespace if the selector has no namespace.
+func GetMultusNetworkIPs(objectMeta metav1.ObjectMeta, selector string) ([]string, error) {
+	namespace, name, err := GetMultusNetworkName(selector, objectMeta.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	networkName := fmt.Sprintf("%s/%s", namespace, name)
+
+	networks, err := GetNetworkStatus(objectMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		if network.Name == networkName {
+			return network.IPs, nil
+		}
+	}
+	return []string{}, nil
+}
@@ -153,4 +153,75 @@ func TestGetNetworkAttachmentConfig(t *testing.T) {
 	config, err := GetNetworkAttachmentConfig(dummyNetAttachDef)
 	assert.NoError(t, err)
 	assert.Equal(t, "172.18.8.0/24", config.Ipam.Subnet)
+	assert.Equal(t, []string{"172.18.8.0/24"}, config.Subnets())
+
+	// the subnets of the whereabouts and the host-local range sets
+	dummyNetAttachDef.Spec.Config = `{"type": "macvlan", "ipam": {"type": "whereabouts", "range": "192.168.20.0/24"}}`
+	config, err = GetNetworkAttachmentConfig(dummyNetAttachDef)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.20.0/24"}, config.Subnets())
+	dummyNetAttachDef.Spec.Config = `{"type": "macvlan", "ipam": {"type": "host-local",
+		"ranges": [[{"subnet": "192.168.20.0/24"}], [{"subnet": "fd00:20::/64"}]]}}`
+	config, err = GetNetworkAttachmentConfig(dummyNetAttachDef)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.20.0/24", "fd00:20::/64"}, config.Subnets())
+}
+
+func TestGetMultusNetworkName(t *testing.T) {
+	namespace, name, err := GetMultusNetworkName("public-net@net1", "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, "rook-ceph", namespace)
+	assert.Equal(t, "public-net", name)
+
+	namespace, name, err = GetMultusNetworkName(`{"name": "cluster-net", "namespace": "other"}`, "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", namespace)
+	assert.Equal(t, "cluster-net", name)
+
+	namespace, name, err = GetMultusNetworkName("public-net", "rook-ceph")
+	assert.NoError(t, err)
+	assert.Equal(t, "rook-ceph", namespace)
+	assert.Equal(t, "public-net", name)
+
+	_, _, err = GetMultusNetworkName("@net1", "rook-ceph")
+	assert.Error(t, err)
+}
+
+func TestGetMultusNetworkIPs(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{Namespace: "rook-ceph"}
+
+	// no status yet
+	ips, err := GetMultusNetworkIPs(objectMeta, "public-net@net1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(ips))
+
+	objectMeta.Annotations = map[string]string{
+		MultusNetworkStatusAnnotation: `[{"name":"","interface":"eth0","ips":["10.244.1.5"],"default":true},
+			{"name":"rook-ceph/public-net","interface":"net1","ips":["192.168.20.5"]}]`,
+	}
+	ips, err = GetMultusNetworkIPs(objectMeta, "public-net@net1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.20.5"}, ips)
+
+	ips, err = GetMultusNetworkIPs(objectMeta, `{"name": "public-net", "namespace": "rook-ceph", "interface": "net1"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.20.5"}, ips)
+
+	// the network attachment is in another namespace
+	ips, err = GetMultusNetworkIPs(objectMeta, "other/public-net@net1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(ips))
+
+	// legacy annotation
+	objectMeta.Annotations = map[string]string{
+		"k8s.v1.cni.cncf.io/networks-status": `[{"name":"rook-ceph/public-net","ips":["192.168.20.6"]}]`,
+	}
+	ips, err = GetMultusNetworkIPs(objectMeta, "rook-ceph/public-net")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.20.6"}, ips)
+
+	// invalid status
+	objectMeta.Annotations = map[string]string{MultusNetworkStatusAnnotation: "not json"}
+	_, err = GetMultusNetworkIPs(objectMeta, "public-net@net1")
+	assert.Error(t, err)
 }
@@ -129,3 +129,16 @@ func CreateOrUpdatePrometheusRule(prometheusRule *monitoringv1.PrometheusRule) (
 	}
 	return promRule, nil
 }
+
+// DeletePrometheusRule deletes a prometheusRule, it succeeds if the prometheusRule does not exist
+func DeletePrometheusRule(namespace, name string) error {
+	client, err := getMonitoringClient()
+	if err != nil {
+		return fmt.Errorf("failed to get monitoring client. %v", err)
+	}
+	err = client.MonitoringV1().PrometheusRules(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete prometheusRule %q. %v", name, err)
+	}
+	return nil
+}
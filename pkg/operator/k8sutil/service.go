@@ -31,6 +31,7 @@ func CreateOrUpdateService(
 ) (*v1.Service, error) {
 	name := serviceDefinition.Name
 	logger.Debugf("creating service %s", name)
+	ApplyInheritedMetadata(namespace, &serviceDefinition.ObjectMeta)
 	s, err := clientset.CoreV1().Services(namespace).Create(serviceDefinition)
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
@@ -51,12 +52,23 @@ func UpdateService(
 ) (*v1.Service, error) {
 	name := serviceDefinition.Name
 	logger.Debugf("updating service %s", name)
+	ApplyInheritedMetadata(namespace, &serviceDefinition.ObjectMeta)
 	existing, err := clientset.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("could not get existing service %s in order to update. %+v", name, err)
 	}
 	// ClusterIP is immutable for k8s services and cannot be left empty in k8s v1 API
 	serviceDefinition.Spec.ClusterIP = existing.Spec.ClusterIP
+	// the IP family of the cluster IP is immutable too
+	if existing.Spec.IPFamily != nil {
+		if serviceDefinition.Spec.IPFamily != nil && *serviceDefinition.Spec.IPFamily != *existing.Spec.IPFamily {
+			logger.Warningf("the ip family of service %q cannot change from %q to %q, the service must be recreated", name, *existing.Spec.IPFamily, *serviceDefinition.Spec.IPFamily)
+		}
+		serviceDefinition.Spec.IPFamily = existing.Spec.IPFamily
+	}
+	if ServerSideApply {
+		return ApplyService(clientset, serviceDefinition)
+	}
 	// ResourceVersion required to update services in k8s v1 API to prevent race conditions
 	serviceDefinition.ResourceVersion = existing.ResourceVersion
 	return clientset.CoreV1().Services(namespace).Update(serviceDefinition)
@@ -22,6 +22,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestParseServiceType(t *testing.T) {
@@ -35,3 +37,22 @@ func TestParseServiceType(t *testing.T) {
 		assert.Equal(t, v1.ServiceType(""), ParseServiceType(serviceType))
 	}
 }
+
+func TestUpdateServiceKeepsIPFamily(t *testing.T) {
+	ipv4 := v1.IPv4Protocol
+	existing := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "ns"},
+		Spec:       v1.ServiceSpec{ClusterIP: "10.0.0.1", IPFamily: &ipv4},
+	}
+	clientset := fake.NewSimpleClientset(existing)
+
+	// the cluster ip and its family are immutable
+	ipv6 := v1.IPv6Protocol
+	updated, err := UpdateService(clientset, "ns", &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "ns"},
+		Spec:       v1.ServiceSpec{IPFamily: &ipv6},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", updated.Spec.ClusterIP)
+	assert.Equal(t, v1.IPv4Protocol, *updated.Spec.IPFamily)
+}
@@ -27,6 +27,7 @@ import (
 
 // create a apps.statefulset
 func CreateStatefulSet(clientset kubernetes.Interface, name, namespace string, ss *apps.StatefulSet) error {
+	ApplyInheritedMetadataToPodTemplate(namespace, &ss.ObjectMeta, &ss.Spec.Template)
 	_, err := clientset.AppsV1().StatefulSets(namespace).Create(ss)
 	if err != nil {
 		if k8serrors.IsAlreadyExists(err) {
@@ -592,7 +592,10 @@ func (h *CephInstaller) UninstallRookFromMultipleNS(systemNamespace string, name
 		"volumes.rook.io",
 		"objectbuckets.objectbucket.io",
 		"objectbucketclaims.objectbucket.io",
-		"cephrbdmirrors.ceph.rook.io")
+		"cephrbdmirrors.ceph.rook.io",
+		"cephcommandjobs.ceph.rook.io",
+		"cephblockpoolradosnamespaces.ceph.rook.io",
+		"cephdeviceinventories.ceph.rook.io")
 	checkError(h.T(), err, "cannot delete CRDs")
 
 	if h.useHelm {
@@ -109,6 +109,7 @@ spec:
         spec:
           properties:
             annotations: {}
+            labels: {}
             cephVersion:
               properties:
                 allowUnsupported:
@@ -127,6 +128,40 @@ spec:
                   maximum: 65535
                 ssl:
                   type: boolean
+                externalURL:
+                  type: string
+                sso:
+                  properties:
+                    saml2:
+                      properties:
+                        idpMetadata:
+                          type: string
+                        usernameAttribute:
+                          type: string
+                        entityID:
+                          type: string
+                      required:
+                      - idpMetadata
+                ingress:
+                  properties:
+                    enabled:
+                      type: boolean
+                    host:
+                      type: string
+                    className:
+                      type: string
+                    tlsSecretName:
+                      type: string
+                    annotations:
+                      type: object
+                      additionalProperties:
+                        type: string
+                objectStore:
+                  type: string
+                grafanaURL:
+                  type: string
+                prometheusURL:
+                  type: string
             dataDirHostPath:
               pattern: ^/(\S+)
               type: string
@@ -140,8 +175,43 @@ spec:
                   type: integer
                 manageMachineDisruptionBudgets:
                   type: boolean
+                mds:
+                  properties:
+                    disabled:
+                      type: boolean
+                    maxUnavailable:
+                      type: integer
+                      minimum: 1
+                rgw:
+                  properties:
+                    disabled:
+                      type: boolean
+                    maxUnavailable:
+                      type: integer
+                      minimum: 1
+                nfs:
+                  properties:
+                    disabled:
+                      type: boolean
+                    maxUnavailable:
+                      type: integer
+                      minimum: 1
             skipUpgradeChecks:
               type: boolean
+            upgradeChecks:
+              properties:
+                waitTimeout:
+                  type: string
+                daemons:
+                  type: object
+                  additionalProperties:
+                    properties:
+                      skipUpgradeChecks:
+                        type: boolean
+                      continueUpgradeAfterChecksEvenIfNotHealthy:
+                        type: boolean
+                      waitTimeout:
+                        type: string
             mon:
               properties:
                 allowMultiplePerNode:
@@ -151,6 +221,29 @@ spec:
                   minimum: 0
                   type: integer
                 volumeClaimTemplate: {}
+                backup:
+                  properties:
+                    schedule:
+                      type: string
+                    persistentVolumeClaim:
+                      type: string
+                    keep:
+                      type: integer
+                      minimum: 0
+                    s3:
+                      properties:
+                        endpoint:
+                          type: string
+                        bucket:
+                          type: string
+                        region:
+                          type: string
+                        credentialsSecret:
+                          type: string
+                      required:
+                      - endpoint
+                      - bucket
+                      - credentialsSecret
             mgr:
               properties:
                 modules:
@@ -167,6 +260,51 @@ spec:
                 provider:
                   type: string
                 selectors: {}
+                connections:
+                  properties:
+                    encryption:
+                      properties:
+                        enabled:
+                          type: boolean
+                    compression:
+                      properties:
+                        enabled:
+                          type: boolean
+                ipFamily:
+                  type: string
+                  enum:
+                  - IPv4
+                  - IPv6
+                dualStack:
+                  type: boolean
+                hostPorts:
+                  properties:
+                    msgrPortRange:
+                      properties:
+                        min:
+                          type: integer
+                          minimum: 1024
+                          maximum: 65535
+                        max:
+                          type: integer
+                          minimum: 1024
+                          maximum: 65535
+                    nfsPort:
+                      type: integer
+                      minimum: 1
+                      maximum: 65535
+                networkPolicies:
+                  properties:
+                    enabled:
+                      type: boolean
+                    allowedNamespaces:
+                      type: array
+                      items:
+                        type: string
+                    allowedCIDRs:
+                      type: array
+                      items:
+                        type: string
             storage:
               properties:
                 disruptionManagement:
@@ -221,6 +359,22 @@ spec:
                 deviceFilter: {}
                 config: {}
                 storageClassDeviceSets: {}
+                store:
+                  properties:
+                    type:
+                      type: string
+                      enum:
+                        - bluestore
+                        - bluestore-rdr
+                    settings:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    updateStore:
+                      type: string
+                      pattern: ^$|^yes-really-update-store$
+                    failureDomain:
+                      type: string
             driveGroups:
               type: array
               nullable: true
@@ -239,6 +393,48 @@ spec:
                   type: boolean
                 rulesNamespace:
                   type: string
+                exporter:
+                  properties:
+                    enabled:
+                      type: boolean
+                    perfCountersPrioLimit:
+                      type: integer
+                      minimum: 0
+                    statsPeriodSeconds:
+                      type: integer
+                      minimum: 0
+                rules:
+                  properties:
+                    disabled:
+                      type: boolean
+                    labels:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    overrides:
+                      type: array
+                      items:
+                        properties:
+                          alert:
+                            type: string
+                          expr:
+                            type: string
+                          for:
+                            type: string
+                          labels:
+                            type: object
+                            additionalProperties:
+                              type: string
+                          annotations:
+                            type: object
+                            additionalProperties:
+                              type: string
+                        required:
+                        - alert
+                    suppressed:
+                      type: array
+                      items:
+                        type: string
             rbdMirroring:
               properties:
                 workers:
@@ -249,6 +445,202 @@ spec:
               properties:
                 enable:
                   type: boolean
+                discovery:
+                  properties:
+                    enabled:
+                      type: boolean
+                    secretName:
+                      type: string
+                restrictUsers:
+                  type: boolean
+            resourceAutoscaling:
+              properties:
+                osd:
+                  properties:
+                    enabled:
+                      type: boolean
+                    interval:
+                      type: string
+                    minAllowed: {}
+                    maxAllowed: {}
+                    headroom:
+                      type: integer
+                      minimum: 0
+                    tolerance:
+                      type: integer
+                      minimum: 0
+                    batchSize:
+                      type: integer
+                      minimum: 0
+            osdPerformance:
+              properties:
+                staticCPUs:
+                  type: boolean
+                numaNode:
+                  type: integer
+                  minimum: 0
+                bluestoreCacheSize: {}
+            scrubbing:
+              properties:
+                beginHour:
+                  type: integer
+                  minimum: 0
+                  maximum: 23
+                endHour:
+                  type: integer
+                  minimum: 0
+                  maximum: 23
+                beginWeekDay:
+                  type: integer
+                  minimum: 0
+                  maximum: 6
+                endWeekDay:
+                  type: integer
+                  minimum: 0
+                  maximum: 6
+                maxScrubsPerOSD:
+                  type: integer
+                  minimum: 1
+                sleep:
+                  type: string
+                deepScrubInterval:
+                  type: string
+            capacity:
+              properties:
+                nearFullRatio:
+                  type: number
+                  minimum: 0
+                  maximum: 1
+                backfillFullRatio:
+                  type: number
+                  minimum: 0
+                  maximum: 1
+                fullRatio:
+                  type: number
+                  minimum: 0
+                  maximum: 1
+                reweightByUtilization:
+                  properties:
+                    enabled:
+                      type: boolean
+                    interval:
+                      type: string
+                    threshold:
+                      type: integer
+                      minimum: 110
+                      maximum: 200
+                    maxChange:
+                      type: number
+                      minimum: 0.01
+                      maximum: 0.1
+                    maxOSDs:
+                      type: integer
+                      minimum: 1
+                      maximum: 10
+            balancer:
+              properties:
+                enabled:
+                  type: boolean
+                mode:
+                  type: string
+                  enum:
+                  - upmap
+                  - crush-compat
+                interval:
+                  type: string
+            newDevices:
+              properties:
+                quarantinePeriod:
+                  type: string
+                requireApproval:
+                  type: boolean
+                deviceFilter:
+                  type: string
+            security:
+              properties:
+                restrictAdminKey:
+                  type: boolean
+                kms:
+                  properties:
+                    connectionDetails:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    tokenSecretName:
+                      type: string
+                keyRotation:
+                  properties:
+                    enabled:
+                      type: boolean
+                    interval:
+                      type: string
+                cephxKeyRotation:
+                  properties:
+                    enabled:
+                      type: boolean
+                    interval:
+                      type: string
+                    keyGeneration:
+                      type: integer
+                      minimum: 0
+                    daemons:
+                      type: array
+                      items:
+                        type: string
+                        enum:
+                        - mgr
+                        - osd
+                        - mds
+                        - rgw
+                        - csi
+                podSecurityContexts:
+                  type: object
+                  additionalProperties:
+                    type: object
+                    properties:
+                      runAsUser:
+                        type: integer
+                      runAsGroup:
+                        type: integer
+                      runAsNonRoot:
+                        type: boolean
+                      fsGroup:
+                        type: integer
+                      supplementalGroups:
+                        type: array
+                        items:
+                          type: integer
+                tls:
+                  properties:
+                    issuer:
+                      type: string
+                      enum:
+                      - ""
+                      - self-signed
+                      - cert-manager
+                    certManagerIssuerRef:
+                      properties:
+                        name:
+                          type: string
+                        kind:
+                          type: string
+                          enum:
+                          - Issuer
+                          - ClusterIssuer
+                    duration:
+                      type: string
+                    renewBefore:
+                      type: string
+            csi:
+              properties:
+                readAffinity:
+                  properties:
+                    enabled:
+                      type: boolean
+                    crushLocationLabels:
+                      type: array
+                      items:
+                        type: string
             cleanupPolicy:
               properties:
                 confirmation:
@@ -265,6 +657,29 @@ spec:
                     iteration:
                       type: integer
                       format: int32
+            toolbox:
+              properties:
+                enabled:
+                  type: boolean
+                image:
+                  type: string
+            logCollector:
+              properties:
+                enabled:
+                  type: boolean
+                periodicity:
+                  type: string
+                  pattern: ^$|^(hourly|daily|weekly|monthly)$
+                maxLogSize:
+                  type: string
+                rotateCount:
+                  type: integer
+                daemons: {}
+                shipper:
+                  properties:
+                    image:
+                      type: string
+                    output: {}
             placement: {}
             resources: {}
   additionalPrinterColumns:
@@ -287,6 +702,18 @@ spec:
       type: string
       description: Ceph Health
       JSONPath: .status.ceph.health
+    - name: Version
+      type: string
+      description: Ceph version
+      JSONPath: .status.version.version
+    - name: Used
+      type: string
+      description: Used raw capacity
+      JSONPath: .status.ceph.capacity.usage
+    - name: Resources
+      type: string
+      description: Ready CRs of the cluster namespace
+      JSONPath: .status.resources.ready
   subresources:
     status: {}
 ---
@@ -308,6 +735,14 @@ spec:
       properties:
         spec:
           properties:
+            mirroring:
+              properties:
+                enabled:
+                  type: boolean
+                directories:
+                  type: array
+                  items:
+                    type: string
             metadataServer:
               properties:
                 activeCount:
@@ -317,12 +752,23 @@ spec:
                 activeStandby:
                   type: boolean
                 annotations: {}
+                labels: {}
                 placement: {}
                 resources: {}
             metadataPool:
               properties:
                 failureDomain:
                   type: string
+                quotas:
+                  properties:
+                    maxBytes:
+                      type: integer
+                    maxObjects:
+                      type: integer
+                    warningThresholdPercent:
+                      type: integer
+                      minimum: 1
+                      maximum: 100
                 replicated:
                   properties:
                     size:
@@ -331,6 +777,12 @@ spec:
                       type: integer
                     requireSafeReplicaSize:
                       type: boolean
+                    hybridStorage:
+                      properties:
+                        primaryDeviceClass:
+                          type: string
+                        secondaryDeviceClass:
+                          type: string
                 erasureCoded:
                   properties:
                     dataChunks:
@@ -341,6 +793,12 @@ spec:
                       minimum: 0
                       maximum: 10
                       type: integer
+                    failureDomain:
+                      type: string
+                    crushRoot:
+                      type: string
+                    deviceClass:
+                      type: string
                 compressionMode:
                   type: string
                   enum:
@@ -349,12 +807,40 @@ spec:
                   - passive
                   - aggressive
                   - force
+                compressionAlgorithm:
+                  type: string
+                  enum:
+                  - ""
+                  - snappy
+                  - zlib
+                  - zstd
+                  - lz4
+                pgAutoscaleMode:
+                  type: string
+                  enum:
+                  - ""
+                  - "on"
+                  - "off"
+                  - warn
+                targetSizeRatio:
+                  type: number
+                  minimum: 0
             dataPools:
               type: array
               items:
                 properties:
                   failureDomain:
                     type: string
+                  quotas:
+                    properties:
+                      maxBytes:
+                        type: integer
+                      maxObjects:
+                        type: integer
+                      warningThresholdPercent:
+                        type: integer
+                        minimum: 1
+                        maximum: 100
                   replicated:
                     properties:
                       size:
@@ -363,6 +849,12 @@ spec:
                         type: integer
                       requireSafeReplicaSize:
                         type: boolean
+                      hybridStorage:
+                        properties:
+                          primaryDeviceClass:
+                            type: string
+                          secondaryDeviceClass:
+                            type: string
                   erasureCoded:
                     properties:
                       dataChunks:
@@ -373,6 +865,12 @@ spec:
                         minimum: 0
                         maximum: 10
                         type: integer
+                      failureDomain:
+                        type: string
+                      crushRoot:
+                        type: string
+                      deviceClass:
+                        type: string
                   compressionMode:
                     type: string
                     enum:
@@ -381,6 +879,24 @@ spec:
                     - passive
                     - aggressive
                     - force
+                  compressionAlgorithm:
+                    type: string
+                    enum:
+                    - ""
+                    - snappy
+                    - zlib
+                    - zstd
+                    - lz4
+                  pgAutoscaleMode:
+                    type: string
+                    enum:
+                    - ""
+                    - "on"
+                    - "off"
+                    - warn
+                  targetSizeRatio:
+                    type: number
+                    minimum: 0
                   parameters:
                     type: object
             preservePoolsOnDelete:
@@ -427,8 +943,35 @@ spec:
                 active:
                   type: integer
                 annotations: {}
+                labels: {}
                 placement: {}
                 resources: {}
+            exports:
+              type: array
+              items:
+                properties:
+                  name:
+                    type: string
+                  filesystem:
+                    type: string
+                  subvolumeGroup:
+                    type: string
+                  pseudoPath:
+                    type: string
+                  squash:
+                    type: string
+                    enum:
+                    - none
+                    - root
+                    - all
+                    - rootid
+                  accessType:
+                    type: string
+                required:
+                - name
+                - filesystem
+                - subvolumeGroup
+                - pseudoPath
   subresources:
     status: {}
 ---
@@ -463,24 +1006,47 @@ spec:
                 instances:
                   type: integer
                 annotations: {}
+                labels: {}
                 placement: {}
                 resources: {}
             metadataPool:
               properties:
                 failureDomain:
                   type: string
+                quotas:
+                  properties:
+                    maxBytes:
+                      type: integer
+                    maxObjects:
+                      type: integer
+                    warningThresholdPercent:
+                      type: integer
+                      minimum: 1
+                      maximum: 100
                 replicated:
                   properties:
                     size:
                       type: integer
                     requireSafeReplicaSize:
                       type: boolean
+                    hybridStorage:
+                      properties:
+                        primaryDeviceClass:
+                          type: string
+                        secondaryDeviceClass:
+                          type: string
                 erasureCoded:
                   properties:
                     dataChunks:
                       type: integer
                     codingChunks:
                       type: integer
+                    failureDomain:
+                      type: string
+                    crushRoot:
+                      type: string
+                    deviceClass:
+                      type: string
                 compressionMode:
                   type: string
                   enum:
@@ -489,24 +1055,64 @@ spec:
                   - passive
                   - aggressive
                   - force
+                compressionAlgorithm:
+                  type: string
+                  enum:
+                  - ""
+                  - snappy
+                  - zlib
+                  - zstd
+                  - lz4
+                pgAutoscaleMode:
+                  type: string
+                  enum:
+                  - ""
+                  - "on"
+                  - "off"
+                  - warn
+                targetSizeRatio:
+                  type: number
+                  minimum: 0
                 parameters:
                   type: object
             dataPool:
               properties:
                 failureDomain:
                   type: string
+                quotas:
+                  properties:
+                    maxBytes:
+                      type: integer
+                    maxObjects:
+                      type: integer
+                    warningThresholdPercent:
+                      type: integer
+                      minimum: 1
+                      maximum: 100
                 replicated:
                   properties:
                     size:
                       type: integer
                     requireSafeReplicaSize:
                       type: boolean
+                    hybridStorage:
+                      properties:
+                        primaryDeviceClass:
+                          type: string
+                        secondaryDeviceClass:
+                          type: string
                 erasureCoded:
                   properties:
                     dataChunks:
                       type: integer
                     codingChunks:
                       type: integer
+                    failureDomain:
+                      type: string
+                    crushRoot:
+                      type: string
+                    deviceClass:
+                      type: string
                 compressionMode:
                   type: string
                   enum:
@@ -515,6 +1121,24 @@ spec:
                   - passive
                   - aggressive
                   - force
+                compressionAlgorithm:
+                  type: string
+                  enum:
+                  - ""
+                  - snappy
+                  - zlib
+                  - zstd
+                  - lz4
+                pgAutoscaleMode:
+                  type: string
+                  enum:
+                  - ""
+                  - "on"
+                  - "off"
+                  - warn
+                targetSizeRatio:
+                  type: number
+                  minimum: 0
                 parameters:
                   type: object
             preservePoolsOnDelete:
@@ -527,6 +1151,109 @@ spec:
                       type: boolean
                     interval:
                       type: string
+                dataPath:
+                  properties:
+                    disabled:
+                      type: boolean
+                    interval:
+                      type: string
+            security:
+              properties:
+                kms:
+                  properties:
+                    connectionDetails:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    tokenSecretName:
+                      type: string
+                s3:
+                  properties:
+                    connectionDetails:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    tokenSecretName:
+                      type: string
+            auth:
+              properties:
+                keystone:
+                  properties:
+                    url:
+                      type: string
+                    serviceUserSecretName:
+                      type: string
+                    acceptedRoles:
+                      type: array
+                      items:
+                        type: string
+                    implicitTenants:
+                      type: string
+                      enum:
+                      - "true"
+                      - "false"
+                      - swift
+                      - s3
+                    tokenCacheSize:
+                      type: integer
+                  required:
+                  - url
+                  - serviceUserSecretName
+                  - acceptedRoles
+            protocols:
+              properties:
+                s3:
+                  properties:
+                    authUseKeystone:
+                      type: boolean
+                swift:
+                  properties:
+                    accountInUrl:
+                      type: boolean
+                    urlPrefix:
+                      type: string
+                    versioningEnabled:
+                      type: boolean
+            placementTargets:
+              type: array
+              items:
+                properties:
+                  name:
+                    type: string
+                    pattern: ^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+                  dataPool: {}
+                  storageClasses:
+                    type: array
+                    items:
+                      properties:
+                        name:
+                          type: string
+                          pattern: ^[A-Z0-9_]+$
+                        dataPool: {}
+                      required:
+                      - name
+                      - dataPool
+                required:
+                - name
+            usageMetrics:
+              properties:
+                enabled:
+                  type: boolean
+                interval:
+                  type: string
+                output:
+                  type: string
+                  enum:
+                  - prometheus
+                  - configmap
+                includeBuckets:
+                  type: array
+                  items:
+                    type: string
+                excludeBuckets:
+                  type: array
+                  items:
+                    type: string
   subresources:
   subresources:
     status: {}
@@ -621,6 +1348,34 @@ spec:
           properties:
             failureDomain:
                 type: string
+            snapshotSchedules:
+              type: array
+              items:
+                properties:
+                  interval:
+                    type: string
+                    pattern: ^[1-9][0-9]*[mhd]$
+                  startTime:
+                    type: string
+            trashPurgeSchedules:
+              type: array
+              items:
+                properties:
+                  interval:
+                    type: string
+                    pattern: ^[1-9][0-9]*[mhd]$
+                  startTime:
+                    type: string
+            quotas:
+              properties:
+                maxBytes:
+                  type: integer
+                maxObjects:
+                  type: integer
+                warningThresholdPercent:
+                  type: integer
+                  minimum: 1
+                  maximum: 100
             replicated:
               properties:
                 size:
@@ -631,6 +1386,12 @@ spec:
                   type: number
                 requireSafeReplicaSize:
                   type: boolean
+                hybridStorage:
+                  properties:
+                    primaryDeviceClass:
+                      type: string
+                    secondaryDeviceClass:
+                      type: string
             erasureCoded:
               properties:
                 dataChunks:
@@ -641,6 +1402,12 @@ spec:
                   type: integer
                   minimum: 0
                   maximum: 9
+                failureDomain:
+                  type: string
+                crushRoot:
+                  type: string
+                deviceClass:
+                  type: string
             compressionMode:
                 type: string
                 enum:
@@ -649,6 +1416,24 @@ spec:
                 - passive
                 - aggressive
                 - force
+            compressionAlgorithm:
+              type: string
+              enum:
+              - ""
+              - snappy
+              - zlib
+              - zstd
+              - lz4
+            pgAutoscaleMode:
+              type: string
+              enum:
+              - ""
+              - "on"
+              - "off"
+              - warn
+            targetSizeRatio:
+              type: number
+              minimum: 0
             parameters:
               type: object
   subresources:
@@ -768,7 +1553,173 @@ spec:
               minimum: 1
               maximum: 100
   subresources:
-    status: {}`
+    status: {}
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: cephcommandjobs.ceph.rook.io
+spec:
+  group: ceph.rook.io
+  names:
+    kind: CephCommandJob
+    listKind: CephCommandJobList
+    plural: cephcommandjobs
+    singular: cephcommandjob
+  scope: Namespaced
+  version: v1
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          properties:
+            command:
+              type: string
+              enum:
+                - ceph
+                - radosgw-admin
+                - rados
+                - rbd
+            args:
+              type: array
+              minItems: 1
+              items:
+                type: string
+            timeoutSeconds:
+              type: integer
+              minimum: 1
+          required:
+            - command
+            - args
+  additionalPrinterColumns:
+    - name: Command
+      type: string
+      JSONPath: .spec.command
+    - name: Phase
+      type: string
+      JSONPath: .status.phase
+    - name: ExitCode
+      type: integer
+      JSONPath: .status.exitCode
+    - name: Age
+      type: date
+      JSONPath: .metadata.creationTimestamp
+  subresources:
+    status: {}
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: cephblockpoolradosnamespaces.ceph.rook.io
+spec:
+  group: ceph.rook.io
+  names:
+    kind: CephBlockPoolRadosNamespace
+    listKind: CephBlockPoolRadosNamespaceList
+    plural: cephblockpoolradosnamespaces
+    singular: cephblockpoolradosnamespace
+  scope: Namespaced
+  version: v1
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          properties:
+            blockPoolName:
+              type: string
+              minLength: 1
+            mirroring:
+              properties:
+                mode:
+                  type: string
+                  enum:
+                    - image
+                    - pool
+              required:
+                - mode
+          required:
+            - blockPoolName
+  additionalPrinterColumns:
+    - name: BlockPool
+      type: string
+      JSONPath: .spec.blockPoolName
+    - name: Phase
+      type: string
+      JSONPath: .status.phase
+    - name: ClusterID
+      type: string
+      JSONPath: .status.clusterID
+    - name: Age
+      type: date
+      JSONPath: .metadata.creationTimestamp
+  subresources:
+    status: {}
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: cephdeviceinventories.ceph.rook.io
+spec:
+  group: ceph.rook.io
+  names:
+    kind: CephDeviceInventory
+    listKind: CephDeviceInventoryList
+    plural: cephdeviceinventories
+    singular: cephdeviceinventory
+  scope: Namespaced
+  version: v1
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          properties:
+            nodeName:
+              type: string
+        status:
+          properties:
+            devices:
+              type: array
+              items:
+                properties:
+                  name:
+                    type: string
+                  path:
+                    type: string
+                  devLinks:
+                    type: array
+                    items:
+                      type: string
+                  type:
+                    type: string
+                  wwn:
+                    type: string
+                  serial:
+                    type: string
+                  vendor:
+                    type: string
+                  model:
+                    type: string
+                  size:
+                    type: integer
+                  rotational:
+                    type: boolean
+                  empty:
+                    type: boolean
+                  inUseByOSD:
+                    type: boolean
+                  osdIDs:
+                    type: array
+                    items:
+                      type: integer
+                  health:
+                    type: string
+  additionalPrinterColumns:
+    - name: Node
+      type: string
+      JSONPath: .spec.nodeName
+    - name: Age
+      type: date
+      JSONPath: .metadata.creationTimestamp`
 }
 
 // GetRookOperator returns rook Operator manifest
@@ -802,6 +1753,17 @@ rules:
   - create
   - update
   - delete
+- apiGroups:
+  - coordination.k8s.io
+  resources:
+  - leases
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - delete
 - apiGroups:
   - k8s.cni.cncf.io
   resources:
@@ -860,6 +1822,36 @@ rules:
   - create
   - update
   - delete
+- apiGroups:
+  - ""
+  resources:
+  # The pods are evicted by the rolling restarts of the daemons to respect their disruption budgets
+  - pods/eviction
+  verbs:
+  - create
+- apiGroups:
+  - cert-manager.io
+  resources:
+  - certificates
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - delete
+- apiGroups:
+  - networking.k8s.io
+  resources:
+  - networkpolicies
+  - ingresses
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - delete
 ---
 apiVersion: rbac.authorization.k8s.io/v1beta1
 kind: ClusterRole
@@ -920,10 +1912,20 @@ rules:
   - get
   - list
   - watch
+- apiGroups:
+  - metrics.k8s.io
+  resources:
+  # The pod metrics are needed for the resource autoscaling
+  - pods
+  verbs:
+  - get
+  - list
 - apiGroups:
   - batch
   resources:
   - jobs
+  # The crash reports are pruned and the mon store is backed up by CronJobs
+  - cronjobs
   verbs:
   - get
   - list